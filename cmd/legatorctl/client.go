@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -21,6 +23,14 @@ type FleetSummary struct {
 	Counts           map[string]int `json:"counts"`
 	Connected        int            `json:"connected"`
 	PendingApprovals int            `json:"pending_approvals"`
+	Versions         []VersionCount `json:"versions,omitempty"`
+	ByOS             map[string]int `json:"by_os,omitempty"`
+	ByPolicyLevel    map[string]int `json:"by_policy_level,omitempty"`
+}
+
+type VersionCount struct {
+	Version string `json:"version"`
+	Count   int    `json:"count"`
 }
 
 type Probe struct {
@@ -58,10 +68,21 @@ type APIError struct {
 }
 
 type RegistrationToken struct {
-	Value   string    `json:"token"`
-	Created time.Time `json:"created"`
-	Expires time.Time `json:"expires"`
-	Used    bool      `json:"used"`
+	Value         string    `json:"token"`
+	Created       time.Time `json:"created"`
+	Expires       time.Time `json:"expires"`
+	Used          bool      `json:"used"`
+	MultiUse      bool      `json:"multi_use,omitempty"`
+	MaxUses       int       `json:"max_uses,omitempty"`
+	UseCount      int       `json:"use_count"`
+	RemainingUses *int      `json:"remaining_uses,omitempty"`
+}
+
+// TokenCreateOptions are the optional registration token settings.
+type TokenCreateOptions struct {
+	MultiUse   bool
+	MaxUses    int
+	ValidUntil time.Time
 }
 
 type APIKey struct {
@@ -73,11 +94,13 @@ type APIKey struct {
 	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
 	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
 	Enabled     bool       `json:"enabled"`
+	RateLimit   int        `json:"rate_limit,omitempty"`
 }
 
 type KeyCreatePayload struct {
 	Name        string   `json:"name"`
 	Permissions []string `json:"permissions"`
+	RateLimit   int      `json:"rate_limit,omitempty"`
 }
 
 type KeyCreateResponse struct {
@@ -146,9 +169,38 @@ func (c *APIClient) SendCommand(ctx context.Context, id, command string, args []
 	return out, nil
 }
 
-func (c *APIClient) CreateToken(ctx context.Context) (*RegistrationToken, error) {
+// SendTemplateCommand dispatches a stored command template; the server
+// renders params into the command and applies the usual approval and policy.
+func (c *APIClient) SendTemplateCommand(ctx context.Context, id, templateID string, params map[string]string) (map[string]any, error) {
+	payload := map[string]any{
+		"template": templateID,
+		"params":   params,
+	}
+	var out map[string]any
+	err := c.doJSON(ctx, http.MethodPost, "/api/v1/probes/"+id+"/command", payload, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *APIClient) CreateToken(ctx context.Context, opts TokenCreateOptions) (*RegistrationToken, error) {
+	query := url.Values{}
+	if opts.MultiUse {
+		query.Set("multi_use", "true")
+	}
+	if opts.MaxUses > 0 {
+		query.Set("max_uses", strconv.Itoa(opts.MaxUses))
+	}
+	if !opts.ValidUntil.IsZero() {
+		query.Set("valid_until", opts.ValidUntil.UTC().Format(time.RFC3339))
+	}
+	path := "/api/v1/tokens"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
 	var out RegistrationToken
-	err := c.doJSON(ctx, http.MethodPost, "/api/v1/tokens", nil, &out)
+	err := c.doJSON(ctx, http.MethodPost, path, nil, &out)
 	if err != nil {
 		return nil, err
 	}
@@ -173,6 +225,229 @@ func (c *APIClient) CreateKey(ctx context.Context, req KeyCreatePayload) (*KeyCr
 	return &out, nil
 }
 
+// ExportInventory streams the fleet inventory export into w. The export is
+// not size-bounded, so it is not subject to the client's request timeout;
+// cancel ctx to abort.
+func (c *APIClient) ExportInventory(ctx context.Context, format, tag, status string, w io.Writer) error {
+	query := url.Values{}
+	query.Set("format", format)
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+	if status != "" {
+		query.Set("status", status)
+	}
+
+	return c.streamGet(ctx, "/api/v1/fleet/inventory/export?"+query.Encode(), w)
+}
+
+// RunArtifact describes one artifact attached to a runner run.
+type RunArtifact struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+type RunArtifactList struct {
+	RunID      string        `json:"run_id"`
+	Artifacts  []RunArtifact `json:"artifacts"`
+	Count      int           `json:"count"`
+	TotalBytes int64         `json:"total_bytes"`
+	MaxBytes   int64         `json:"max_bytes"`
+}
+
+func (c *APIClient) RunArtifacts(ctx context.Context, runID string) (*RunArtifactList, error) {
+	var out RunArtifactList
+	err := c.doJSON(ctx, http.MethodGet, "/api/v1/runs/"+url.PathEscape(runID)+"/artifacts", nil, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type JobConcurrency struct {
+	MaxInFlight int `json:"max_in_flight"`
+	MaxPerProbe int `json:"max_per_probe"`
+	Running     int `json:"running"`
+	Queued      int `json:"queued"`
+	Probes      []struct {
+		ProbeID  string `json:"probe_id"`
+		Running  int    `json:"running"`
+		Deferred int    `json:"deferred"`
+		Limit    int    `json:"limit"`
+	} `json:"probes"`
+	Deferred []struct {
+		JobID         string    `json:"job_id"`
+		ProbeID       string    `json:"probe_id"`
+		RequestID     string    `json:"request_id"`
+		Reason        string    `json:"reason"`
+		QueuedAt      time.Time `json:"queued_at"`
+		DeferredSince time.Time `json:"deferred_since"`
+	} `json:"deferred"`
+	RecentThrottles []struct {
+		JobID   string    `json:"job_id"`
+		ProbeID string    `json:"probe_id"`
+		Reason  string    `json:"reason"`
+		At      time.Time `json:"at"`
+	} `json:"recent_throttles"`
+}
+
+type Approval struct {
+	ID      string `json:"id"`
+	ProbeID string `json:"probe_id"`
+	Command *struct {
+		Command string   `json:"command"`
+		Args    []string `json:"args,omitempty"`
+	} `json:"command,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	RiskLevel   string    `json:"risk_level"`
+	Requester   string    `json:"requester"`
+	Decision    string    `json:"decision"`
+	DecidedBy   string    `json:"decided_by,omitempty"`
+	DecidedAt   time.Time `json:"decided_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	EscalatedAt time.Time `json:"escalated_at"`
+}
+
+type ApprovalList struct {
+	Approvals    []Approval `json:"approvals"`
+	PendingCount int        `json:"pending_count"`
+}
+
+// Approvals lists approval requests, only pending ones when pendingOnly.
+func (c *APIClient) Approvals(ctx context.Context, pendingOnly bool) (*ApprovalList, error) {
+	path := "/api/v1/approvals"
+	if pendingOnly {
+		path += "?status=pending"
+	}
+	var out ApprovalList
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *APIClient) JobConcurrency(ctx context.Context) (*JobConcurrency, error) {
+	var out JobConcurrency
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/jobs/concurrency", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DownloadRunArtifact streams one run artifact into w. Like ExportInventory
+// it is not subject to the client's request timeout.
+func (c *APIClient) DownloadRunArtifact(ctx context.Context, runID, artifactPath string, w io.Writer) error {
+	segments := strings.Split(strings.Trim(artifactPath, "/"), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return c.streamGet(ctx, "/api/v1/runs/"+url.PathEscape(runID)+"/artifacts/"+strings.Join(segments, "/"), w)
+}
+
+// ExportPolicies streams the policy template export document into w.
+func (c *APIClient) ExportPolicies(ctx context.Context, w io.Writer) error {
+	return c.streamGet(ctx, "/api/v1/policies/export", w)
+}
+
+// PolicyImportResult is the outcome for one imported template.
+type PolicyImportResult struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type PolicyImportResponse struct {
+	Created int                  `json:"created"`
+	Updated int                  `json:"updated"`
+	Invalid int                  `json:"invalid"`
+	Results []PolicyImportResult `json:"results"`
+}
+
+// ImportPolicies posts an export document, as produced by ExportPolicies,
+// to create or update its templates.
+func (c *APIClient) ImportPolicies(ctx context.Context, doc []byte) (*PolicyImportResponse, error) {
+	if !json.Valid(doc) {
+		return nil, fmt.Errorf("policy import file is not valid JSON")
+	}
+	var out PolicyImportResponse
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v1/policies/import", json.RawMessage(doc), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ConnectivityEndpoint is a host:port to check from the control plane.
+type ConnectivityEndpoint struct {
+	Name     string `json:"name,omitempty"`
+	Address  string `json:"address"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// ConnectivityResult is the reachability of one endpoint.
+type ConnectivityResult struct {
+	Name      string `json:"name,omitempty"`
+	Address   string `json:"address"`
+	Required  bool   `json:"required,omitempty"`
+	Reachable bool   `json:"reachable"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ConnectivityReport struct {
+	CheckedAt   time.Time            `json:"checked_at"`
+	DurationMS  int64                `json:"duration_ms"`
+	Reachable   int                  `json:"reachable"`
+	Unreachable int                  `json:"unreachable"`
+	Cached      bool                 `json:"cached"`
+	Endpoints   []ConnectivityResult `json:"endpoints"`
+}
+
+// CheckConnectivity asks the control plane to dial each endpoint.
+func (c *APIClient) CheckConnectivity(ctx context.Context, endpoints []ConnectivityEndpoint) (*ConnectivityReport, error) {
+	var out ConnectivityReport
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v1/connectivity/check", map[string]any{"endpoints": endpoints}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// streamGet copies the body of a GET request into w without a timeout.
+func (c *APIClient) streamGet(ctx context.Context, path string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.server+path, nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	streamClient := *c.http
+	streamClient.Timeout = 0
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		var apiErr APIError
+		if err := json.Unmarshal(resBody, &apiErr); err == nil && apiErr.Error != "" {
+			return fmt.Errorf("request failed (status %d): %s", resp.StatusCode, apiErr.Error)
+		}
+		return fmt.Errorf("request failed (status %d): %s", resp.StatusCode, strings.TrimSpace(string(resBody)))
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	return nil
+}
+
 func (c *APIClient) doJSON(ctx context.Context, method, path string, body any, out any) error {
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportInventoryStreamsBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/fleet/inventory/export" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("format"); got != "csv" {
+			t.Errorf("expected format=csv, got %q", got)
+		}
+		if got := r.URL.Query().Get("tag"); got != "prod" {
+			t.Errorf("expected tag=prod, got %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer lgk_test" {
+			t.Errorf("unexpected auth header %q", got)
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("id,hostname\np1,web-01\n"))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	client := NewAPIClient(srv.URL, "lgk_test")
+	if err := client.ExportInventory(context.Background(), "csv", "prod", "", &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if buf.String() != "id,hostname\np1,web-01\n" {
+		t.Fatalf("unexpected body %q", buf.String())
+	}
+}
+
+func TestExportInventoryReportsAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"format must be csv or json"}`))
+	}))
+	defer srv.Close()
+
+	err := NewAPIClient(srv.URL, "").ExportInventory(context.Background(), "xml", "", "", &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "format must be csv or json") {
+		t.Fatalf("expected api error, got %v", err)
+	}
+}
+
+func TestSendTemplateCommandPostsTemplateAndParams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/probes/p1/command" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Template string            `json:"template"`
+			Params   map[string]string `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		if body.Template != "restart-service" || body.Params["service"] != "nginx" {
+			t.Errorf("unexpected body %+v", body)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status":"pending_approval"}`))
+	}))
+	defer srv.Close()
+
+	templateID, params, err := parseTemplateFlags([]string{"--template", "restart-service", "--param", "service=nginx"})
+	if err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	out, err := NewAPIClient(srv.URL, "").SendTemplateCommand(context.Background(), "p1", templateID, params)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if out["status"] != "pending_approval" {
+		t.Fatalf("unexpected response %v", out)
+	}
+
+	if _, _, err := parseTemplateFlags([]string{"--template", "x", "--param", "novalue"}); err == nil {
+		t.Fatal("expected malformed --param to be rejected")
+	}
+}
+
+func TestRunArtifactsListAndDownload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v1/runs/run-1/artifacts":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"run_id":"run-1","artifacts":[{"path":"logs/out.log","size":5}],"count":1,"total_bytes":5,"max_bytes":100}`))
+		case "/api/v1/runs/run-1/artifacts/logs/out%20file.log":
+			_, _ = w.Write([]byte("hello"))
+		default:
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewAPIClient(srv.URL, "")
+	list, err := client.RunArtifacts(context.Background(), "run-1")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if list.Count != 1 || list.Artifacts[0].Path != "logs/out.log" || list.MaxBytes != 100 {
+		t.Fatalf("unexpected listing %+v", list)
+	}
+
+	var buf bytes.Buffer
+	if err := client.DownloadRunArtifact(context.Background(), "run-1", "logs/out file.log", &buf); err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	if buf.String() != "hello" {
+		t.Fatalf("unexpected body %q", buf.String())
+	}
+}
+
+func TestJobConcurrencyDecodesSnapshot(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v1/jobs/concurrency" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"max_in_flight":8,"max_per_probe":1,"running":2,"queued":3,
+			"deferred":[{"job_id":"j1","probe_id":"p1","reason":"probe_limit"}],
+			"recent_throttles":[{"job_id":"j1","probe_id":"p1","reason":"probe_limit"}]}`))
+	}))
+	defer srv.Close()
+
+	conc, err := NewAPIClient(srv.URL, "").JobConcurrency(context.Background())
+	if err != nil {
+		t.Fatalf("job concurrency: %v", err)
+	}
+	if conc.MaxInFlight != 8 || conc.Running != 2 || conc.Queued != 3 {
+		t.Fatalf("unexpected usage %+v", conc)
+	}
+	if len(conc.Deferred) != 1 || conc.Deferred[0].Reason != "probe_limit" || len(conc.RecentThrottles) != 1 {
+		t.Fatalf("unexpected deferrals %+v", conc)
+	}
+}
+
+func TestApprovalsListsPendingOnlyByDefault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/approvals" || r.URL.Query().Get("status") != "pending" {
+			t.Errorf("unexpected request %s", r.URL.String())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"approvals":[{"id":"a1","probe_id":"p1","command":{"command":"reboot"},"risk_level":"critical","decision":"pending"}],"pending_count":1}`))
+	}))
+	defer srv.Close()
+
+	list, err := NewAPIClient(srv.URL, "").Approvals(context.Background(), true)
+	if err != nil {
+		t.Fatalf("approvals: %v", err)
+	}
+	if list.PendingCount != 1 || len(list.Approvals) != 1 || list.Approvals[0].Command.Command != "reboot" {
+		t.Fatalf("unexpected approvals %+v", list)
+	}
+}
+
+func TestPrintYAMLMatchesJSONShape(t *testing.T) {
+	summary := FleetSummary{Counts: map[string]int{"online": 2}, Connected: 2, PendingApprovals: 1}
+
+	var out bytes.Buffer
+	if err := PrintStructured(&out, OutputYAML, summary); err != nil {
+		t.Fatal(err)
+	}
+	want := "connected: 2\ncounts:\n  online: 2\npending_approvals: 1\n"
+	if out.String() != want {
+		t.Fatalf("unexpected yaml:\n%s", out.String())
+	}
+
+	if _, err := ParseOutputFormat("xml"); err == nil {
+		t.Fatal("expected unknown output format to be rejected")
+	}
+	if format, err := ParseOutputFormat("WIDE"); err != nil || format != OutputWide {
+		t.Fatalf("expected wide, got %q (%v)", format, err)
+	}
+}
+
+func TestPoliciesExportAndImport(t *testing.T) {
+	const doc = `{"version":1,"templates":[{"id":"pol-101","name":"App","level":"diagnose"}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/policies/export":
+			_, _ = w.Write([]byte(doc))
+		case "POST /api/v1/policies/import":
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != doc {
+				t.Errorf("import should post the document unchanged, got %s", body)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"created":1,"updated":0,"invalid":0,"results":[{"index":0,"id":"pol-101","name":"App","status":"created"}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewAPIClient(srv.URL, "")
+	var buf bytes.Buffer
+	if err := client.ExportPolicies(context.Background(), &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	resp, err := client.ImportPolicies(context.Background(), buf.Bytes())
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if resp.Created != 1 || len(resp.Results) != 1 || resp.Results[0].Status != "created" {
+		t.Fatalf("unexpected import response %+v", resp)
+	}
+	if _, err := client.ImportPolicies(context.Background(), []byte("{not json")); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+}
+
+func TestCheckConnectivityPostsEndpoints(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/connectivity/check" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Endpoints []ConnectivityEndpoint `json:"endpoints"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Endpoints) != 1 || body.Endpoints[0].Name != "db-01" {
+			t.Errorf("unexpected body %+v (%v)", body, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"reachable":0,"unreachable":1,"cached":true,"endpoints":[{"name":"db-01","address":"db-01:5432","reachable":false,"latency_ms":3,"error":"connection refused"}]}`))
+	}))
+	defer srv.Close()
+
+	report, err := NewAPIClient(srv.URL, "").CheckConnectivity(context.Background(), []ConnectivityEndpoint{{Name: "db-01", Address: "db-01:5432"}})
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if !report.Cached || report.Unreachable != 1 || report.Endpoints[0].Error != "connection refused" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestCreateTokenSendsUsageCap(t *testing.T) {
+	until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/tokens" || q.Get("max_uses") != "25" || q.Get("valid_until") != "2030-01-02T03:04:05Z" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.String())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"prb_x","multi_use":true,"max_uses":25,"use_count":0,"remaining_uses":25}`))
+	}))
+	defer srv.Close()
+
+	tok, err := NewAPIClient(srv.URL, "").CreateToken(context.Background(), TokenCreateOptions{MaxUses: 25, ValidUntil: until})
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+	if tok.RemainingUses == nil || *tok.RemainingUses != 25 || !tok.MultiUse {
+		t.Fatalf("unexpected token %+v", tok)
+	}
+}
@@ -6,8 +6,29 @@ import (
 	"io"
 	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats selected with the global --output flag. The default is a
+// human-readable table; wide adds columns and stops truncating.
+const (
+	OutputTable = "table"
+	OutputWide  = "wide"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
 )
 
+// ParseOutputFormat validates an --output value.
+func ParseOutputFormat(raw string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(raw)); format {
+	case OutputTable, OutputWide, OutputJSON, OutputYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("--output must be one of table, wide, json, yaml; got %q", raw)
+	}
+}
+
 const (
 	ansiReset  = "\x1b[0m"
 	ansiGreen  = "\x1b[32m"
@@ -113,6 +134,33 @@ func PrintJSON(out io.Writer, v any) error {
 	return enc.Encode(v)
 }
 
+// PrintYAML writes v as YAML with the same field names and shape as its
+// JSON encoding, so scripts can switch formats without changing queries.
+func PrintYAML(out io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// PrintStructured writes v in a machine-readable format (json or yaml).
+func PrintStructured(out io.Writer, format string, v any) error {
+	if format == OutputYAML {
+		return PrintYAML(out, v)
+	}
+	return PrintJSON(out, v)
+}
+
 func Truncate(s string, max int) string {
 	if max <= 0 {
 		return ""
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -44,9 +46,24 @@ const (
 )
 
 type cliConfig struct {
-	server     string
-	apiKey     string
-	jsonOutput bool
+	server string
+	apiKey string
+	output string
+}
+
+// structured reports whether output is machine-readable (json or yaml).
+func (c cliConfig) structured() bool {
+	return c.output == OutputJSON || c.output == OutputYAML
+}
+
+// wide reports whether tables should show every column untruncated.
+func (c cliConfig) wide() bool {
+	return c.output == OutputWide
+}
+
+// print writes v in the structured output format.
+func (c cliConfig) print(v any) error {
+	return PrintStructured(os.Stdout, c.output, v)
 }
 
 func main() {
@@ -81,10 +98,20 @@ func main() {
 		err = runProbe(ctx, client, cfg, args)
 	case "command":
 		err = runCommand(ctx, client, cfg, args)
+	case "runs":
+		err = runRuns(ctx, client, cfg, args)
+	case "status":
+		err = runStatus(ctx, client, cfg, args)
+	case "approvals":
+		err = runApprovals(ctx, client, cfg, args)
 	case "tokens":
 		err = runTokens(ctx, client, cfg, args)
 	case "keys":
 		err = runKeys(ctx, client, cfg, args)
+	case "policies":
+		err = runPolicies(ctx, client, cfg, args)
+	case "connectivity":
+		err = runConnectivity(ctx, client, cfg, args)
 	case "version":
 		fmt.Printf("legatorctl %s (commit: %s, built: %s)\n", version, commit, date)
 		return
@@ -104,9 +131,9 @@ var errShowUsage = errors.New("show usage")
 
 func parseArgs(args []string) (cliConfig, string, []string, error) {
 	cfg := cliConfig{
-		server:     defaultServer,
-		apiKey:     os.Getenv("LEGATOR_API_KEY"),
-		jsonOutput: false,
+		server: defaultServer,
+		apiKey: os.Getenv("LEGATOR_API_KEY"),
+		output: OutputTable,
 	}
 
 	idx := 0
@@ -131,8 +158,18 @@ func parseArgs(args []string) (cliConfig, string, []string, error) {
 			cfg.apiKey = args[idx+1]
 			idx += 2
 		case "--json":
-			cfg.jsonOutput = true
+			cfg.output = OutputJSON
 			idx++
+		case "--output", "-o":
+			if idx+1 >= len(args) {
+				return cfg, "", nil, fmt.Errorf("--output requires a value")
+			}
+			format, err := ParseOutputFormat(args[idx+1])
+			if err != nil {
+				return cfg, "", nil, err
+			}
+			cfg.output = format
+			idx += 2
 		default:
 			return cfg, "", nil, fmt.Errorf("unknown flag: %s", arg)
 		}
@@ -146,21 +183,44 @@ func parseArgs(args []string) (cliConfig, string, []string, error) {
 }
 
 func printUsage() {
-	fmt.Print(`Usage: legatorctl [--server <url>] [--api-key <key>] [--json] <command>
+	fmt.Print(`Usage: legatorctl [--server <url>] [--api-key <key>] [--output table|wide|json|yaml] <command>
+
+Global flags:
+  --output, -o <format>     Output format: table (default), wide (extra columns,
+                            untruncated), json or yaml. --json is short for -o json.
 
 Commands:
   fleet                     Show fleet summary
+  fleet export [--format csv|json] [--output <file>] [--tag <tag>] [--status <status>]
+                            Export the full fleet inventory to a file
   probes                    List all probes
   probe <id>                Show probe details
   command <id> <cmd> ...    Send command to a probe
-  tokens create             Generate a registration token
+  command <id> --template <name> [--param key=value ...]
+                            Send a command template with parameters
+  status                    Show job concurrency and runs held back by limits
+  approvals [--all]         List pending approvals (--all includes decided ones)
+  runs artifacts <run-id>   List artifacts attached to a runner run
+  runs artifacts <run-id> <path> [--output <file>]
+                            Download one run artifact
+  tokens create [--multi-use] [--max-uses <n>] [--valid-until <time|duration>]
+                            Generate a registration token; --max-uses caps
+                            registrations (implies multi-use)
   keys list                 List API keys
-  keys create --name <name> --perms <perms>
-                            Create a new API key
+  keys create --name <name> --perms <perms> [--rate-limit <n>]
+                            Create a new API key (n requests/minute)
+  policies export [--output <file>]
+                            Export all policy templates as JSON
+  policies import <file>    Create or update policy templates from an export
+  connectivity check [name=]<host:port> ...
+                            Check TCP reachability from the control plane
 `)
 }
 
 func runFleet(ctx context.Context, client *APIClient, cfg cliConfig, args []string) error {
+	if len(args) > 0 && args[0] == "export" {
+		return runFleetExport(ctx, client, args[1:])
+	}
 	if len(args) != 0 {
 		return fmt.Errorf("usage: legatorctl fleet")
 	}
@@ -169,8 +229,8 @@ func runFleet(ctx context.Context, client *APIClient, cfg cliConfig, args []stri
 	if err != nil {
 		return err
 	}
-	if cfg.jsonOutput {
-		return PrintJSON(os.Stdout, summary)
+	if cfg.structured() {
+		return cfg.print(summary)
 	}
 
 	online := 0
@@ -199,6 +259,226 @@ func runFleet(ctx context.Context, client *APIClient, cfg cliConfig, args []stri
 		}
 	}
 	RenderTable(os.Stdout, headers, rows)
+
+	if len(summary.Versions) > 0 {
+		versionRows := make([][]string, 0, len(summary.Versions))
+		for _, v := range summary.Versions {
+			versionRows = append(versionRows, []string{v.Version, strconv.Itoa(v.Count)})
+		}
+		fmt.Println()
+		RenderTable(os.Stdout, []string{"VERSION", "PROBES"}, versionRows)
+	}
+	return nil
+}
+
+func runFleetExport(ctx context.Context, client *APIClient, args []string) error {
+	format := "csv"
+	output := ""
+	tag := ""
+	status := ""
+	for i := 0; i < len(args); i++ {
+		var dest *string
+		switch args[i] {
+		case "--format":
+			dest = &format
+		case "--output", "-o":
+			dest = &output
+		case "--tag":
+			dest = &tag
+		case "--status":
+			dest = &status
+		default:
+			return fmt.Errorf("unknown flag: %s", args[i])
+		}
+		if i+1 >= len(args) {
+			return fmt.Errorf("%s requires a value", args[i])
+		}
+		*dest = args[i+1]
+		i++
+	}
+	format = strings.ToLower(format)
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("--format must be csv or json")
+	}
+	if output == "" {
+		output = fmt.Sprintf("legator-inventory-%s.%s", time.Now().UTC().Format("20060102"), format)
+	}
+
+	if output == "-" {
+		return client.ExportInventory(ctx, format, tag, status, os.Stdout)
+	}
+
+	if err := writeOutputFile(output, func(w io.Writer) error {
+		return client.ExportInventory(ctx, format, tag, status, w)
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Fleet inventory written to %s\n", output)
+	return nil
+}
+
+// writeOutputFile downloads to a temp file so a failed download never leaves
+// a partial file under the requested name.
+func writeOutputFile(output string, download func(io.Writer) error) error {
+	tmp := output + ".partial"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create output: %w", err)
+	}
+	if err := download(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write output: %w", err)
+	}
+	if err := os.Rename(tmp, output); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// runStatus shows async job concurrency so operators can see why queued
+// runs aren't starting.
+func runStatus(ctx context.Context, client *APIClient, cfg cliConfig, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: legatorctl status")
+	}
+	conc, err := client.JobConcurrency(ctx)
+	if err != nil {
+		return err
+	}
+	if cfg.structured() {
+		return cfg.print(conc)
+	}
+
+	perProbe := "none"
+	if conc.MaxPerProbe > 0 {
+		perProbe = strconv.Itoa(conc.MaxPerProbe)
+	}
+	fmt.Fprintf(os.Stdout, "Jobs running: %d/%d (per-probe limit: %s)\n", conc.Running, conc.MaxInFlight, perProbe)
+	fmt.Fprintf(os.Stdout, "Jobs queued:  %d (%d held back by limits)\n", conc.Queued, len(conc.Deferred))
+
+	if len(conc.Deferred) > 0 {
+		fmt.Fprintln(os.Stdout)
+		headers := []string{"JOB", "PROBE", "REASON", "QUEUED", "DEFERRED SINCE"}
+		rows := make([][]string, 0, len(conc.Deferred))
+		for _, d := range conc.Deferred {
+			rows = append(rows, []string{d.JobID, d.ProbeID, d.Reason, FormatTimeOrDash(d.QueuedAt), FormatTimeOrDash(d.DeferredSince)})
+		}
+		RenderTable(os.Stdout, headers, rows)
+	}
+	if len(conc.RecentThrottles) > 0 {
+		latest := conc.RecentThrottles[0]
+		fmt.Fprintf(os.Stdout, "\nRecent throttle events: %d (latest: %s on %s at %s)\n",
+			len(conc.RecentThrottles), latest.Reason, latest.ProbeID, FormatTimeOrDash(latest.At))
+	}
+	return nil
+}
+
+func runApprovals(ctx context.Context, client *APIClient, cfg cliConfig, args []string) error {
+	pendingOnly := true
+	for _, arg := range args {
+		switch arg {
+		case "--all":
+			pendingOnly = false
+		default:
+			return fmt.Errorf("usage: legatorctl approvals [--all]")
+		}
+	}
+
+	list, err := client.Approvals(ctx, pendingOnly)
+	if err != nil {
+		return err
+	}
+	if cfg.structured() {
+		return cfg.print(list)
+	}
+
+	headers := []string{"ID", "PROBE", "COMMAND", "RISK", "DECISION", "EXPIRES"}
+	if cfg.wide() {
+		headers = append(headers, "REQUESTER", "CREATED", "ESCALATED", "DECIDED BY")
+	}
+	rows := make([][]string, 0, len(list.Approvals))
+	for _, a := range list.Approvals {
+		command := "-"
+		if a.Command != nil {
+			command = strings.TrimSpace(a.Command.Command + " " + strings.Join(a.Command.Args, " "))
+		}
+		id := a.ID
+		if !cfg.wide() {
+			id, command = Truncate(id, 12), Truncate(command, 40)
+		}
+		row := []string{id, a.ProbeID, command, a.RiskLevel, a.Decision, FormatTimeOrDash(a.ExpiresAt)}
+		if cfg.wide() {
+			decidedBy := a.DecidedBy
+			if decidedBy == "" {
+				decidedBy = "-"
+			}
+			row = append(row, a.Requester, FormatTimeOrDash(a.CreatedAt), FormatTimeOrDash(a.EscalatedAt), decidedBy)
+		}
+		rows = append(rows, row)
+	}
+	RenderTable(os.Stdout, headers, rows)
+	fmt.Fprintf(os.Stdout, "\nPending: %d\n", list.PendingCount)
+	return nil
+}
+
+func runRuns(ctx context.Context, client *APIClient, cfg cliConfig, args []string) error {
+	if len(args) < 2 || args[0] != "artifacts" {
+		return fmt.Errorf("usage: legatorctl runs artifacts <run-id> [<path> [--output <file>]]")
+	}
+	runID := args[1]
+	if len(args) > 2 {
+		return runRunArtifactDownload(ctx, client, runID, args[2:])
+	}
+
+	list, err := client.RunArtifacts(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if cfg.structured() {
+		return cfg.print(list)
+	}
+
+	headers := []string{"PATH", "SIZE", "MODIFIED"}
+	rows := make([][]string, 0, len(list.Artifacts))
+	for _, a := range list.Artifacts {
+		rows = append(rows, []string{a.Path, strconv.FormatInt(a.Size, 10), FormatTimeOrDash(a.ModifiedAt)})
+	}
+	RenderTable(os.Stdout, headers, rows)
+	fmt.Fprintf(os.Stdout, "\nTotal: %d artifacts, %d of %d bytes\n", list.Count, list.TotalBytes, list.MaxBytes)
+	return nil
+}
+
+func runRunArtifactDownload(ctx context.Context, client *APIClient, runID string, args []string) error {
+	artifactPath := args[0]
+	output := path.Base(artifactPath)
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--output", "-o":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", args[i])
+			}
+			output = args[i+1]
+			i++
+		default:
+			return fmt.Errorf("unknown flag: %s", args[i])
+		}
+	}
+
+	if output == "-" {
+		return client.DownloadRunArtifact(ctx, runID, artifactPath, os.Stdout)
+	}
+	if err := writeOutputFile(output, func(w io.Writer) error {
+		return client.DownloadRunArtifact(ctx, runID, artifactPath, w)
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Artifact written to %s\n", output)
 	return nil
 }
 
@@ -212,16 +492,22 @@ func runProbes(ctx context.Context, client *APIClient, cfg cliConfig, args []str
 		return err
 	}
 
-	if cfg.jsonOutput {
-		return PrintJSON(os.Stdout, probes)
+	if cfg.structured() {
+		return cfg.print(probes)
 	}
 
 	headers := []string{"ID", "HOSTNAME", "STATUS", "POLICY", "LAST SEEN", "OS/ARCH", "TAGS"}
+	if cfg.wide() {
+		headers = append(headers, "HEALTH", "REGISTERED")
+	}
 	rows := make([][]string, 0, len(probes))
 
 	for _, p := range probes {
 		status := ColorStatus(p.Status)
-		host := Truncate(p.Hostname, 18)
+		id, host, tags := p.ID, p.Hostname, strings.Join(p.Tags, ",")
+		if !cfg.wide() {
+			id, host, tags = Truncate(id, 18), Truncate(host, 18), Truncate(tags, 24)
+		}
 		policy := p.PolicyLevel
 		if policy == "" {
 			policy = "-"
@@ -231,15 +517,15 @@ func runProbes(ctx context.Context, client *APIClient, cfg cliConfig, args []str
 		if osArch == "/" {
 			osArch = "-"
 		}
-		rows = append(rows, []string{
-			Truncate(p.ID, 18),
-			host,
-			status,
-			policy,
-			lastSeen,
-			osArch,
-			Truncate(strings.Join(p.Tags, ","), 24),
-		})
+		row := []string{id, host, status, policy, lastSeen, osArch, tags}
+		if cfg.wide() {
+			health := "-"
+			if p.Health != nil {
+				health = fmt.Sprintf("%s (%d)", p.Health.Status, p.Health.Score)
+			}
+			row = append(row, health, FormatTimeOrDash(p.Registered))
+		}
+		rows = append(rows, row)
 	}
 
 	RenderTable(os.Stdout, headers, rows)
@@ -258,8 +544,8 @@ func runProbe(ctx context.Context, client *APIClient, cfg cliConfig, args []stri
 		return err
 	}
 
-	if cfg.jsonOutput {
-		return PrintJSON(os.Stdout, probe)
+	if cfg.structured() {
+		return cfg.print(probe)
 	}
 
 	osValue := probe.OS
@@ -314,19 +600,29 @@ func runProbe(ctx context.Context, client *APIClient, cfg cliConfig, args []stri
 
 func runCommand(ctx context.Context, client *APIClient, cfg cliConfig, args []string) error {
 	if len(args) < 2 {
-		return fmt.Errorf("usage: legatorctl command <id> <cmd> [args...]")
+		return fmt.Errorf("usage: legatorctl command <id> <cmd> [args...] | <id> --template <name> [--param key=value ...]")
 	}
 	probeID := args[0]
-	command := args[1]
-	cmdArgs := args[2:]
 
-	result, err := client.SendCommand(ctx, probeID, command, cmdArgs)
+	var (
+		result map[string]any
+		err    error
+	)
+	if args[1] == "--template" {
+		templateID, params, perr := parseTemplateFlags(args[1:])
+		if perr != nil {
+			return perr
+		}
+		result, err = client.SendTemplateCommand(ctx, probeID, templateID, params)
+	} else {
+		result, err = client.SendCommand(ctx, probeID, args[1], args[2:])
+	}
 	if err != nil {
 		return err
 	}
 
-	if cfg.jsonOutput {
-		return PrintJSON(os.Stdout, result)
+	if cfg.structured() {
+		return cfg.print(result)
 	}
 
 	if result == nil {
@@ -350,31 +646,106 @@ func runCommand(ctx context.Context, client *APIClient, cfg cliConfig, args []st
 	return nil
 }
 
+// parseTemplateFlags reads --template <id> and repeated --param key=value.
+func parseTemplateFlags(args []string) (string, map[string]string, error) {
+	templateID := ""
+	params := map[string]string{}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--template":
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("--template requires a value")
+			}
+			templateID = args[i+1]
+			i++
+		case "--param":
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("--param requires a value")
+			}
+			key, value, ok := strings.Cut(args[i+1], "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return "", nil, fmt.Errorf("--param must be key=value, got %q", args[i+1])
+			}
+			params[strings.TrimSpace(key)] = value
+			i++
+		default:
+			return "", nil, fmt.Errorf("unknown flag: %s", args[i])
+		}
+	}
+	if templateID == "" {
+		return "", nil, fmt.Errorf("--template is required")
+	}
+	return templateID, params, nil
+}
+
 func runTokens(ctx context.Context, client *APIClient, cfg cliConfig, args []string) error {
 	if len(args) == 0 {
-		return fmt.Errorf("usage: legatorctl tokens create")
+		return fmt.Errorf("usage: legatorctl tokens create [--multi-use] [--max-uses <n>] [--valid-until <time|duration>]")
 	}
 	if args[0] != "create" {
 		return fmt.Errorf("unknown tokens command: %s", args[0])
 	}
-	if len(args) != 1 {
-		return fmt.Errorf("usage: legatorctl tokens create")
+	var opts TokenCreateOptions
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--multi-use":
+			opts.MultiUse = true
+		case "--max-uses", "--valid-until":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", args[i])
+			}
+			value := args[i+1]
+			if args[i] == "--max-uses" {
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 1 {
+					return fmt.Errorf("--max-uses must be a positive integer")
+				}
+				opts.MaxUses = n
+			} else {
+				until, err := parseValidUntil(value, time.Now())
+				if err != nil {
+					return err
+				}
+				opts.ValidUntil = until
+			}
+			i++
+		default:
+			return fmt.Errorf("unknown flag: %s", args[i])
+		}
 	}
-	tok, err := client.CreateToken(ctx)
+	tok, err := client.CreateToken(ctx, opts)
 	if err != nil {
 		return err
 	}
-	if cfg.jsonOutput {
-		return PrintJSON(os.Stdout, tok)
+	if cfg.structured() {
+		return cfg.print(tok)
 	}
 
 	fmt.Printf("Token: %s\n", tok.Value)
 	fmt.Printf("Created: %s\n", tok.Created.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Expires: %s\n", tok.Expires.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Used: %t\n", tok.Used)
+	if tok.RemainingUses != nil {
+		fmt.Printf("Remaining uses: %d of %d\n", *tok.RemainingUses, tok.MaxUses)
+	} else if tok.MultiUse {
+		fmt.Println("Remaining uses: unlimited")
+	}
 	return nil
 }
 
+// parseValidUntil accepts an RFC 3339 timestamp or a duration from now
+// such as 72h.
+func parseValidUntil(raw string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+		return now.Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--valid-until must be an RFC 3339 time or a duration such as 72h")
+	}
+	return t, nil
+}
+
 func runKeys(ctx context.Context, client *APIClient, cfg cliConfig, args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("usage: legatorctl keys list|create")
@@ -389,25 +760,39 @@ func runKeys(ctx context.Context, client *APIClient, cfg cliConfig, args []strin
 		if err != nil {
 			return err
 		}
-		if cfg.jsonOutput {
-			return PrintJSON(os.Stdout, resp)
+		if cfg.structured() {
+			return cfg.print(resp)
 		}
 
 		headers := []string{"ID", "NAME", "PREFIX", "PERMISSIONS", "ENABLED", "EXPIRES"}
+		if cfg.wide() {
+			headers = append(headers, "CREATED", "LAST USED", "RATE LIMIT")
+		}
 		rows := make([][]string, 0, len(resp.Keys))
 		for _, k := range resp.Keys {
 			expires := "-"
 			if k.ExpiresAt != nil {
 				expires = k.ExpiresAt.Format("2006-01-02 15:04:05")
 			}
-			rows = append(rows, []string{
+			row := []string{
 				k.ID,
 				k.Name,
 				k.KeyPrefix,
 				strings.Join(k.Permissions, ","),
 				strconv.FormatBool(k.Enabled),
 				expires,
-			})
+			}
+			if cfg.wide() {
+				lastUsed, rateLimit := "-", "-"
+				if k.LastUsedAt != nil {
+					lastUsed = FormatTimeOrDash(*k.LastUsedAt)
+				}
+				if k.RateLimit > 0 {
+					rateLimit = fmt.Sprintf("%d/min", k.RateLimit)
+				}
+				row = append(row, FormatTimeOrDash(k.CreatedAt), lastUsed, rateLimit)
+			}
+			rows = append(rows, row)
 		}
 		RenderTable(os.Stdout, headers, rows)
 		fmt.Fprintf(os.Stdout, "\nTotal: %d keys\n", resp.Total)
@@ -415,6 +800,7 @@ func runKeys(ctx context.Context, client *APIClient, cfg cliConfig, args []strin
 	case "create":
 		name := ""
 		permsArg := ""
+		rateLimit := 0
 		for i := 1; i < len(args); i++ {
 			switch args[i] {
 			case "--name":
@@ -429,6 +815,16 @@ func runKeys(ctx context.Context, client *APIClient, cfg cliConfig, args []strin
 				}
 				permsArg = args[i+1]
 				i++
+			case "--rate-limit":
+				if i+1 >= len(args) {
+					return fmt.Errorf("--rate-limit requires a value")
+				}
+				n, err := strconv.Atoi(args[i+1])
+				if err != nil || n < 0 {
+					return fmt.Errorf("--rate-limit must be a non-negative integer")
+				}
+				rateLimit = n
+				i++
 			default:
 				return fmt.Errorf("unknown flag: %s", args[i])
 			}
@@ -445,12 +841,12 @@ func runKeys(ctx context.Context, client *APIClient, cfg cliConfig, args []strin
 			return fmt.Errorf("--perms must contain at least one permission")
 		}
 
-		resp, err := client.CreateKey(ctx, KeyCreatePayload{Name: name, Permissions: perms})
+		resp, err := client.CreateKey(ctx, KeyCreatePayload{Name: name, Permissions: perms, RateLimit: rateLimit})
 		if err != nil {
 			return err
 		}
-		if cfg.jsonOutput {
-			return PrintJSON(os.Stdout, resp)
+		if cfg.structured() {
+			return cfg.print(resp)
 		}
 
 		fmt.Printf("Plain Key: %s\n", resp.PlainKey)
@@ -459,6 +855,9 @@ func runKeys(ctx context.Context, client *APIClient, cfg cliConfig, args []strin
 		fmt.Printf("Prefix: %s\n", resp.Key.KeyPrefix)
 		fmt.Printf("Permissions: %s\n", strings.Join(resp.Key.Permissions, ","))
 		fmt.Printf("Enabled: %t\n", resp.Key.Enabled)
+		if resp.Key.RateLimit > 0 {
+			fmt.Printf("Rate Limit: %d/min\n", resp.Key.RateLimit)
+		}
 		if resp.Warning != "" {
 			fmt.Printf("Warning: %s\n", resp.Warning)
 		}
@@ -468,6 +867,119 @@ func runKeys(ctx context.Context, client *APIClient, cfg cliConfig, args []strin
 	}
 }
 
+func runPolicies(ctx context.Context, client *APIClient, cfg cliConfig, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: legatorctl policies export|import")
+	}
+
+	switch args[0] {
+	case "export":
+		output := ""
+		for i := 1; i < len(args); i++ {
+			switch args[i] {
+			case "--output", "-o":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a value", args[i])
+				}
+				output = args[i+1]
+				i++
+			default:
+				return fmt.Errorf("unknown flag: %s", args[i])
+			}
+		}
+		if output == "" {
+			output = fmt.Sprintf("legator-policies-%s.json", time.Now().UTC().Format("20060102"))
+		}
+		if output == "-" {
+			return client.ExportPolicies(ctx, os.Stdout)
+		}
+		if err := writeOutputFile(output, func(w io.Writer) error {
+			return client.ExportPolicies(ctx, w)
+		}); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Policy templates written to %s\n", output)
+		return nil
+	case "import":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: legatorctl policies import <file>")
+		}
+		var (
+			doc []byte
+			err error
+		)
+		if args[1] == "-" {
+			doc, err = io.ReadAll(os.Stdin)
+		} else {
+			doc, err = os.ReadFile(args[1])
+		}
+		if err != nil {
+			return fmt.Errorf("read policy import: %w", err)
+		}
+		resp, err := client.ImportPolicies(ctx, doc)
+		if err != nil {
+			return err
+		}
+		if cfg.structured() {
+			return cfg.print(resp)
+		}
+
+		rows := make([][]string, 0, len(resp.Results))
+		for _, res := range resp.Results {
+			rows = append(rows, []string{strconv.Itoa(res.Index), res.ID, res.Name, res.Status, res.Error})
+		}
+		RenderTable(os.Stdout, []string{"#", "ID", "NAME", "STATUS", "ERROR"}, rows)
+		fmt.Fprintf(os.Stdout, "\nCreated: %d  Updated: %d  Invalid: %d\n", resp.Created, resp.Updated, resp.Invalid)
+		if resp.Invalid > 0 {
+			return fmt.Errorf("%d policy template(s) were not imported", resp.Invalid)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown policies command: %s", args[0])
+	}
+}
+
+func runConnectivity(ctx context.Context, client *APIClient, cfg cliConfig, args []string) error {
+	if len(args) < 2 || args[0] != "check" {
+		return fmt.Errorf("usage: legatorctl connectivity check [name=]<host:port> ...")
+	}
+	endpoints := make([]ConnectivityEndpoint, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		ep := ConnectivityEndpoint{Address: arg}
+		if name, addr, ok := strings.Cut(arg, "="); ok {
+			ep = ConnectivityEndpoint{Name: name, Address: addr}
+		}
+		endpoints = append(endpoints, ep)
+	}
+
+	report, err := client.CheckConnectivity(ctx, endpoints)
+	if err != nil {
+		return err
+	}
+	if cfg.structured() {
+		return cfg.print(report)
+	}
+
+	rows := make([][]string, 0, len(report.Endpoints))
+	for _, ep := range report.Endpoints {
+		state := "reachable"
+		if !ep.Reachable {
+			state = "unreachable"
+		}
+		rows = append(rows, []string{ep.Name, ep.Address, state, fmt.Sprintf("%dms", ep.LatencyMS), ep.Error})
+	}
+	RenderTable(os.Stdout, []string{"NAME", "ADDRESS", "STATUS", "LATENCY", "ERROR"}, rows)
+	note := ""
+	if report.Cached {
+		note = fmt.Sprintf(" (cached result from %s)", report.CheckedAt.Local().Format(time.TimeOnly))
+	}
+	fmt.Fprintf(os.Stdout, "\nReachable: %d  Unreachable: %d%s\n", report.Reachable, report.Unreachable, note)
+	if report.Unreachable > 0 {
+		return fmt.Errorf("%d endpoint(s) unreachable", report.Unreachable)
+	}
+	return nil
+}
+
 func parsePerms(raw string) []string {
 	parts := strings.Split(raw, ",")
 	seen := map[string]struct{}{}
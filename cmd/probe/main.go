@@ -23,6 +23,7 @@ func init() {
 	if version == "" {
 		version = "dev"
 	}
+	agent.Version = version
 	if commit == "" {
 		commit = "unknown"
 	}
@@ -92,6 +93,7 @@ Commands:
   init       Register with control plane (requires --server and --token)
   run        Start the agent loop (foreground; service manager optional)
   service    Manage the probe service (install|start|stop|remove|status)
+             --init-system auto|systemd|openrc|sysv (default auto-detect)
   status     Show local probe status
   list       List all probes in the fleet (--url, --format json)
   info       Show detailed probe info (probe info <id>)
@@ -125,6 +127,25 @@ func parseConfigDir(args []string) (string, []string) {
 	return configDir, remaining
 }
 
+// parseInitSystem extracts --init-system from args; absent means auto.
+func parseInitSystem(args []string) (agent.InitSystem, []string, error) {
+	raw := ""
+	var remaining []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--init-system" && i+1 < len(args):
+			raw = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--init-system="):
+			raw = strings.TrimPrefix(args[i], "--init-system=")
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	initSystem, err := agent.ParseInitSystem(raw)
+	return initSystem, remaining, err
+}
+
 func parseProbeTags(raw string) []string {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -251,20 +272,24 @@ func cmdRun(ctx context.Context, args []string) error {
 
 func cmdService(args []string) error {
 	configDir, args := parseConfigDir(args)
+	initSystem, args, err := parseInitSystem(args)
+	if err != nil {
+		return err
+	}
 	if len(args) == 0 {
-		return fmt.Errorf("usage: probe service <install|start|stop|remove|status>")
+		return fmt.Errorf("usage: probe service <install|start|stop|remove|status> [--init-system auto|systemd|openrc|sysv]")
 	}
 	switch args[0] {
 	case "install":
-		return agent.ServiceInstall(configDir)
+		return agent.ServiceInstall(configDir, initSystem)
 	case "start":
-		return agent.ServiceStart()
+		return agent.ServiceStart(initSystem)
 	case "stop":
-		return agent.ServiceStop()
+		return agent.ServiceStop(initSystem)
 	case "remove", "uninstall":
-		return agent.ServiceRemove()
+		return agent.ServiceRemove(initSystem)
 	case "status":
-		return agent.ServiceStatus()
+		return agent.ServiceStatus(initSystem)
 	default:
 		return fmt.Errorf("unknown service command: %s", args[0])
 	}
@@ -284,7 +309,7 @@ func cmdStatus(args []string) error {
 
 func cmdUninstall(ctx context.Context) error {
 	// Stop and remove service first
-	_ = agent.ServiceRemove()
+	_ = agent.ServiceRemove(agent.InitAuto)
 
 	// Remove config and data
 	for _, dir := range []string{agent.DefaultConfigDir, agent.DefaultDataDir, agent.DefaultLogDir} {
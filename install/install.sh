@@ -16,6 +16,10 @@ ARCH=""
 NO_START="false"
 USE_GITHUB_RELEASE="false"
 
+# Filled in by the control plane when it serves this script:
+# space-separated "binary-name:sha256" pairs for the binaries it hosts.
+EMBEDDED_CHECKSUMS=""
+
 TMP_DIR="$(mktemp -d)"
 cleanup() { rm -rf "$TMP_DIR"; }
 trap cleanup EXIT
@@ -141,7 +145,6 @@ if [[ "$USE_GITHUB_RELEASE" == "true" ]]; then
   CHECKSUMS_URL="${RELEASE_BASE}/checksums.txt"
 else
   DOWNLOAD_URL="${SERVER%/}/download/legator-probe-${OS}-${ARCH}"
-  CHECKSUMS_URL="${SERVER%/}/download/checksums.txt"
 fi
 
 echo "→ Downloading probe binary from $DOWNLOAD_URL"
@@ -150,25 +153,42 @@ if [[ ! -s "$BIN_PATH" ]]; then
   echo "Error: downloaded binary is empty or missing" >&2; exit 1
 fi
 
-# Verify checksum if available
+# Verify checksum before anything runs the binary
 echo "→ Verifying checksum"
-CHECKSUMS_PATH="$TMP_DIR/checksums.txt"
-if download_file "$CHECKSUMS_URL" "$CHECKSUMS_PATH" 2>/dev/null && [[ -s "$CHECKSUMS_PATH" ]]; then
-  EXPECTED_SHA="$(grep "${BINARY}$" "$CHECKSUMS_PATH" | awk '{print $1}')"
-  if [[ -n "$EXPECTED_SHA" ]]; then
-    ACTUAL_SHA="$(sha256sum "$BIN_PATH" | awk '{print $1}')"
-    if [[ "$ACTUAL_SHA" != "$EXPECTED_SHA" ]]; then
-      echo "Error: checksum mismatch!" >&2
-      echo "  expected: $EXPECTED_SHA" >&2
-      echo "  actual:   $ACTUAL_SHA" >&2
-      exit 1
+ACTUAL_SHA="$(sha256sum "$BIN_PATH" | awk '{print $1}')"
+EXPECTED_SHA=""
+if [[ "$USE_GITHUB_RELEASE" == "true" ]]; then
+  CHECKSUMS_PATH="$TMP_DIR/checksums.txt"
+  if download_file "$CHECKSUMS_URL" "$CHECKSUMS_PATH" 2>/dev/null && [[ -s "$CHECKSUMS_PATH" ]]; then
+    EXPECTED_SHA="$(grep "${BINARY}$" "$CHECKSUMS_PATH" | awk '{print $1}')"
+  fi
+else
+  for entry in $EMBEDDED_CHECKSUMS; do
+    if [[ "${entry%%:*}" == "$BINARY" ]]; then
+      EXPECTED_SHA="${entry#*:}"
     fi
-    echo "  ✅ SHA256 verified"
+  done
+  if [[ -z "$EXPECTED_SHA" ]]; then
+    CHECKSUM_PATH="$TMP_DIR/${BINARY}.sha256"
+    if download_file "${DOWNLOAD_URL}.sha256" "$CHECKSUM_PATH" 2>/dev/null && [[ -s "$CHECKSUM_PATH" ]]; then
+      EXPECTED_SHA="$(awk '{print $1}' "$CHECKSUM_PATH")"
+    fi
+  fi
+fi
+
+if [[ -z "$EXPECTED_SHA" ]]; then
+  if [[ "$USE_GITHUB_RELEASE" == "true" ]]; then
+    echo "  ⚠ No checksum published for ${BINARY}, skipping verification"
   else
-    echo "  ⚠ Binary not found in checksums file, skipping verification"
+    echo "Error: control plane did not provide a checksum for ${BINARY}" >&2; exit 1
   fi
+elif [[ "$ACTUAL_SHA" != "$EXPECTED_SHA" ]]; then
+  echo "Error: checksum mismatch!" >&2
+  echo "  expected: $EXPECTED_SHA" >&2
+  echo "  actual:   $ACTUAL_SHA" >&2
+  exit 1
 else
-  echo "  ⚠ No checksums available, skipping verification"
+  echo "  ✅ SHA256 verified"
 fi
 
 # Install binary
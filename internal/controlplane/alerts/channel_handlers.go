@@ -62,6 +62,8 @@ type notificationMessage struct {
 	ProbeID   string
 	RuleID    string
 	RuleName  string
+	Severity  string // rule severity hint: critical, warning, info
+	Status    string // firing, resolved
 	Detail    any
 }
 
@@ -250,6 +252,7 @@ func (e *Engine) HandleTestChannel(w http.ResponseWriter, r *http.Request) {
 		Summary:   fmt.Sprintf("[TEST] Legator notification channel %s", channel.Name),
 		RuleID:    "test",
 		RuleName:  "test",
+		Severity:  "info",
 		Detail: map[string]any{
 			"channel_id": channel.ID,
 			"channel":    channel.Name,
@@ -385,6 +388,8 @@ func (e *Engine) deliverNotificationChannels(rule AlertRule, evt AlertEvent, evt
 			ProbeID:   evt.ProbeID,
 			RuleID:    rule.ID,
 			RuleName:  rule.Name,
+			Severity:  rule.Condition.Severity,
+			Status:    evt.Status,
 			Detail:    evt,
 		}
 
@@ -410,6 +415,46 @@ func (e *Engine) deliverNotificationChannels(rule AlertRule, evt AlertEvent, evt
 	}
 }
 
+// NotifyChannel sends a notification that is not tied to an alert rule,
+// such as an approval escalation, to one channel and records the outcome
+// like a rule delivery. It blocks until the send completes.
+func (e *Engine) NotifyChannel(channelID, eventType, probeID, summary, severity string, detail any) error {
+	if e.store == nil {
+		return fmt.Errorf("alerts store unavailable")
+	}
+	record := NotificationAuditRecord{
+		Kind:      NotificationAuditDelivery,
+		ChannelID: channelID,
+		ProbeID:   probeID,
+		EventType: eventType,
+	}
+	err := func() error {
+		channel, err := e.store.GetChannel(channelID)
+		if err != nil {
+			return err
+		}
+		record.ChannelName = channel.Name
+		record.ChannelType = channel.Type
+		if !channel.Enabled {
+			return fmt.Errorf("channel disabled")
+		}
+		return e.sendToChannel(*channel, notificationMessage{
+			EventType: eventType,
+			Summary:   summary,
+			ProbeID:   probeID,
+			Severity:  severity,
+			Status:    "firing",
+			Detail:    detail,
+		})
+	}()
+	record.Success = err == nil
+	if err != nil {
+		record.Error = err.Error()
+	}
+	e.recordNotificationAudit(record)
+	return err
+}
+
 func (e *Engine) sendToChannel(channel NotificationChannel, msg notificationMessage) error {
 	switch channel.Type {
 	case ChannelTypeSlack:
@@ -418,6 +463,8 @@ func (e *Engine) sendToChannel(channel NotificationChannel, msg notificationMess
 		return e.sendEmail(channel, msg)
 	case ChannelTypePagerDuty:
 		return e.sendPagerDuty(channel, msg)
+	case ChannelTypeTeams:
+		return e.sendTeams(channel, msg)
 	default:
 		return fmt.Errorf("unsupported channel type: %s", channel.Type)
 	}
@@ -16,6 +16,7 @@ const (
 	ChannelTypeSlack     = "slack"
 	ChannelTypeEmail     = "email"
 	ChannelTypePagerDuty = "pagerduty"
+	ChannelTypeTeams     = "teams"
 
 	defaultPagerDutyEventsAPIURL = "https://events.pagerduty.com/v2/enqueue"
 )
@@ -29,6 +30,7 @@ type NotificationChannel struct {
 	Slack     *SlackChannelConfig     `json:"slack,omitempty"`
 	Email     *EmailChannelConfig     `json:"email,omitempty"`
 	PagerDuty *PagerDutyChannelConfig `json:"pagerduty,omitempty"`
+	Teams     *TeamsChannelConfig     `json:"teams,omitempty"`
 	CreatedAt time.Time               `json:"created_at"`
 	UpdatedAt time.Time               `json:"updated_at"`
 }
@@ -59,6 +61,7 @@ type channelConfigEnvelope struct {
 	Slack     *SlackChannelConfig     `json:"slack,omitempty"`
 	Email     *EmailChannelConfig     `json:"email,omitempty"`
 	PagerDuty *PagerDutyChannelConfig `json:"pagerduty,omitempty"`
+	Teams     *TeamsChannelConfig     `json:"teams,omitempty"`
 }
 
 func normalizeChannelInput(channel NotificationChannel) (NotificationChannel, error) {
@@ -81,6 +84,7 @@ func normalizeChannelInput(channel NotificationChannel) (NotificationChannel, er
 		}
 		channel.Email = nil
 		channel.PagerDuty = nil
+		channel.Teams = nil
 	case ChannelTypeEmail:
 		if channel.Email == nil {
 			channel.Email = &EmailChannelConfig{}
@@ -119,6 +123,7 @@ func normalizeChannelInput(channel NotificationChannel) (NotificationChannel, er
 		}
 		channel.Slack = nil
 		channel.PagerDuty = nil
+		channel.Teams = nil
 	case ChannelTypePagerDuty:
 		if channel.PagerDuty == nil {
 			channel.PagerDuty = &PagerDutyChannelConfig{}
@@ -135,6 +140,18 @@ func normalizeChannelInput(channel NotificationChannel) (NotificationChannel, er
 		}
 		channel.Slack = nil
 		channel.Email = nil
+		channel.Teams = nil
+	case ChannelTypeTeams:
+		if channel.Teams == nil {
+			channel.Teams = &TeamsChannelConfig{}
+		}
+		channel.Teams.WebhookURL = strings.TrimSpace(channel.Teams.WebhookURL)
+		if err := validateWebhookURL(channel.Teams.WebhookURL); err != nil {
+			return channel, fmt.Errorf("invalid teams webhook_url: %w", err)
+		}
+		channel.Slack = nil
+		channel.Email = nil
+		channel.PagerDuty = nil
 	default:
 		return channel, fmt.Errorf("unsupported channel type: %s", channel.Type)
 	}
@@ -162,6 +179,7 @@ func marshalChannelConfig(channel NotificationChannel) (string, error) {
 		Slack:     channel.Slack,
 		Email:     channel.Email,
 		PagerDuty: channel.PagerDuty,
+		Teams:     channel.Teams,
 	}
 	blob, err := json.Marshal(payload)
 	if err != nil {
@@ -314,6 +332,7 @@ func scanChannel(s scanner) (*NotificationChannel, error) {
 			channel.Slack = payload.Slack
 			channel.Email = payload.Email
 			channel.PagerDuty = payload.PagerDuty
+			channel.Teams = payload.Teams
 		}
 	}
 
@@ -22,6 +22,13 @@ type Notifier interface {
 	List() []webhook.WebhookConfig
 }
 
+// HealthHistory supplies recorded probe health scores for degradation rules.
+type HealthHistory interface {
+	HealthScoresSince(probeID string, since time.Time) ([]fleet.HealthSample, error)
+}
+
+const defaultHealthDropWindow = time.Hour
+
 // Engine evaluates alert rules and delivers notifications.
 type Engine struct {
 	store         *Store
@@ -32,11 +39,15 @@ type Engine struct {
 	logger        *zap.Logger
 	httpClient    *http.Client
 	auditRecorder NotificationAuditRecorder
+	healthHistory HealthHistory
+	inventory     InventoryFreshness
+	externalURL   string // public base URL for notification deep links
 
 	evalMu sync.Mutex
 
-	firing  map[FiringKey]*AlertEvent
-	pending map[FiringKey]time.Time
+	firing      map[FiringKey]*AlertEvent
+	pending     map[FiringKey]time.Time
+	probeAlerts map[probeAlertKey]protocol.AlertPayload // conditions pushed by probes, guarded by evalMu
 
 	runMu  sync.Mutex
 	ticker *time.Ticker
@@ -79,6 +90,12 @@ func (e *Engine) SetRoutingStore(rs *RoutingStore) {
 	e.routingStore = rs
 }
 
+// SetHealthHistory attaches the health score history used by
+// health_score_drop rules. Without it those rules never fire.
+func (e *Engine) SetHealthHistory(h HealthHistory) {
+	e.healthHistory = h
+}
+
 // Start begins periodic rule evaluation.
 func (e *Engine) Start() {
 	e.runMu.Lock()
@@ -179,6 +196,13 @@ func (e *Engine) Evaluate() error {
 			continue
 		}
 
+		if rule.Condition.Type == ConditionInventorySourceStale {
+			for _, target := range e.staleSourceTargets(rule) {
+				e.trackMatch(rule, target.key, target.ok, target.message, dur, now, matched)
+			}
+			continue
+		}
+
 		for _, probe := range probes {
 			if probe == nil {
 				continue
@@ -189,45 +213,7 @@ func (e *Engine) Evaluate() error {
 
 			key := FiringKey{RuleID: rule.ID, ProbeID: probe.ID}
 			ok, message := e.conditionMet(rule, probe, now)
-			if !ok {
-				delete(e.pending, key)
-				continue
-			}
-
-			if rule.Condition.Type != "probe_offline" && dur > 0 {
-				since, exists := e.pending[key]
-				if !exists {
-					e.pending[key] = now
-					continue
-				}
-				if now.Sub(since) < dur {
-					continue
-				}
-			}
-
-			matched[key] = ruleMatch{rule: rule, message: message}
-			delete(e.pending, key)
-
-			if _, already := e.firing[key]; already {
-				continue
-			}
-
-			evt := AlertEvent{
-				ID:       uuid.NewString(),
-				RuleID:   rule.ID,
-				RuleName: rule.Name,
-				ProbeID:  probe.ID,
-				Status:   "firing",
-				Message:  message,
-				FiredAt:  now,
-			}
-			if err := e.store.RecordEvent(evt); err != nil {
-				e.logger.Warn("failed to persist firing alert event", zap.String("rule_id", rule.ID), zap.String("probe_id", probe.ID), zap.Error(err))
-				continue
-			}
-			evtCopy := evt
-			e.firing[key] = &evtCopy
-			e.deliver(rule, evtCopy, events.AlertFired)
+			e.trackMatch(rule, key, ok, message, dur, now, matched)
 		}
 	}
 
@@ -240,7 +226,7 @@ func (e *Engine) Evaluate() error {
 		resolved := *evt
 		resolved.Status = "resolved"
 		resolved.ResolvedAt = &resolvedAt
-		resolved.Message = fmt.Sprintf("Alert resolved for probe %s", key.ProbeID)
+		resolved.Message = resolvedMessage(key.ProbeID)
 
 		if err := e.store.RecordEvent(resolved); err != nil {
 			e.logger.Warn("failed to persist resolved alert event", zap.String("rule_id", key.RuleID), zap.String("probe_id", key.ProbeID), zap.Error(err))
@@ -270,6 +256,50 @@ type ruleMatch struct {
 	message string
 }
 
+// trackMatch records one evaluated rule target, holding it pending until the
+// rule duration has elapsed and firing it once. Caller holds evalMu.
+func (e *Engine) trackMatch(rule AlertRule, key FiringKey, ok bool, message string, dur time.Duration, now time.Time, matched map[FiringKey]ruleMatch) {
+	if !ok {
+		delete(e.pending, key)
+		return
+	}
+
+	if rule.Condition.Type != "probe_offline" && dur > 0 {
+		since, exists := e.pending[key]
+		if !exists {
+			e.pending[key] = now
+			return
+		}
+		if now.Sub(since) < dur {
+			return
+		}
+	}
+
+	matched[key] = ruleMatch{rule: rule, message: message}
+	delete(e.pending, key)
+
+	if _, already := e.firing[key]; already {
+		return
+	}
+
+	evt := AlertEvent{
+		ID:       uuid.NewString(),
+		RuleID:   rule.ID,
+		RuleName: rule.Name,
+		ProbeID:  key.ProbeID,
+		Status:   "firing",
+		Message:  message,
+		FiredAt:  now,
+	}
+	if err := e.store.RecordEvent(evt); err != nil {
+		e.logger.Warn("failed to persist firing alert event", zap.String("rule_id", rule.ID), zap.String("probe_id", key.ProbeID), zap.Error(err))
+		return
+	}
+	evtCopy := evt
+	e.firing[key] = &evtCopy
+	e.deliver(rule, evtCopy, events.AlertFired)
+}
+
 func (e *Engine) conditionMet(rule AlertRule, probe *fleet.ProbeState, now time.Time) (bool, string) {
 	switch rule.Condition.Type {
 	case "probe_offline":
@@ -309,11 +339,59 @@ func (e *Engine) conditionMet(rule AlertRule, probe *fleet.ProbeState, now time.
 			return false, ""
 		}
 		return true, fmt.Sprintf("Probe %s CPU usage %.1f%% exceeds %.1f%%", probe.ID, usage, rule.Condition.Threshold)
+	case "health_score_below":
+		if probe.Health == nil || probe.Health.Status == "unknown" {
+			return false, ""
+		}
+		if float64(probe.Health.Score) >= rule.Condition.Threshold {
+			return false, ""
+		}
+		return true, fmt.Sprintf("Probe %s health score %d is below %.0f", probe.ID, probe.Health.Score, rule.Condition.Threshold)
+	case "health_score_drop":
+		return e.healthScoreDropped(rule, probe, now)
+	case ConditionProbeAlert:
+		return e.probeAlertMet(rule, probe.ID)
 	default:
 		return false, ""
 	}
 }
 
+// healthScoreDropped compares the current score with the best score recorded
+// within the rule window.
+func (e *Engine) healthScoreDropped(rule AlertRule, probe *fleet.ProbeState, now time.Time) (bool, string) {
+	if e.healthHistory == nil || probe.Health == nil || probe.Health.Status == "unknown" {
+		return false, ""
+	}
+	window, err := parseRuleDuration(rule.Condition.Window)
+	if err != nil {
+		return false, ""
+	}
+	if window <= 0 {
+		window = defaultHealthDropWindow
+	}
+
+	samples, err := e.healthHistory.HealthScoresSince(probe.ID, now.Add(-window))
+	if err != nil {
+		e.logger.Warn("health history lookup failed", zap.String("probe_id", probe.ID), zap.Error(err))
+		return false, ""
+	}
+	peak := -1
+	for _, sample := range samples {
+		if sample.Status != "unknown" && sample.Score > peak {
+			peak = sample.Score
+		}
+	}
+	if peak < 0 {
+		return false, ""
+	}
+
+	drop := peak - probe.Health.Score
+	if float64(drop) < rule.Condition.Threshold {
+		return false, ""
+	}
+	return true, fmt.Sprintf("Probe %s health score dropped %d points (from %d to %d) within %s", probe.ID, drop, peak, probe.Health.Score, window)
+}
+
 func (e *Engine) deliver(rule AlertRule, evt AlertEvent, evtType events.EventType) {
 	summary := fmt.Sprintf("[%s] %s", strings.ToUpper(evt.Status), evt.Message)
 
@@ -180,3 +180,216 @@ func TestEvaluate_ResolvesWhenConditionClears(t *testing.T) {
 		t.Fatal("expected resolved_at to be set")
 	}
 }
+
+type stubHealthHistory []fleet.HealthSample
+
+func (s stubHealthHistory) HealthScoresSince(probeID string, since time.Time) ([]fleet.HealthSample, error) {
+	var out []fleet.HealthSample
+	for _, sample := range s {
+		if sample.ProbeID == probeID && !sample.RecordedAt.Before(since) {
+			out = append(out, sample)
+		}
+	}
+	return out, nil
+}
+
+func TestConditionMet_HealthScoreBelow(t *testing.T) {
+	engine := NewEngine(nil, nil, nil, nil, zap.NewNop())
+	rule := AlertRule{Condition: AlertCondition{Type: "health_score_below", Threshold: 60}}
+	probe := &fleet.ProbeState{ID: "probe-1"}
+
+	if ok, _ := engine.conditionMet(rule, probe, time.Now()); ok {
+		t.Fatal("expected no match without a health score")
+	}
+	probe.Health = &fleet.HealthScore{Score: 55, Status: "warning"}
+	if ok, msg := engine.conditionMet(rule, probe, time.Now()); !ok {
+		t.Fatal("expected score 55 to be below 60")
+	} else if msg != "Probe probe-1 health score 55 is below 60" {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+	probe.Health.Score = 60
+	if ok, _ := engine.conditionMet(rule, probe, time.Now()); ok {
+		t.Fatal("expected score at threshold not to match")
+	}
+}
+
+func TestConditionMet_HealthScoreDropWithinWindow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	engine := NewEngine(nil, nil, nil, nil, zap.NewNop())
+	rule := AlertRule{Condition: AlertCondition{Type: "health_score_drop", Threshold: 20, Window: "2h"}}
+	probe := &fleet.ProbeState{ID: "probe-1", Health: &fleet.HealthScore{Score: 70, Status: "warning"}}
+
+	if ok, _ := engine.conditionMet(rule, probe, now); ok {
+		t.Fatal("expected no match without health history")
+	}
+
+	engine.SetHealthHistory(stubHealthHistory{
+		{ProbeID: "probe-1", Score: 100, Status: "healthy", RecordedAt: now.Add(-3 * time.Hour)},
+		{ProbeID: "probe-1", Score: 85, Status: "healthy", RecordedAt: now.Add(-90 * time.Minute)},
+		{ProbeID: "probe-1", Score: 75, Status: "warning", RecordedAt: now.Add(-30 * time.Minute)},
+	})
+	if ok, _ := engine.conditionMet(rule, probe, now); ok {
+		t.Fatal("expected 15-point drop within window not to match a 20-point rule")
+	}
+
+	probe.Health.Score = 65
+	ok, msg := engine.conditionMet(rule, probe, now)
+	if !ok {
+		t.Fatal("expected 20-point drop to match")
+	}
+	if msg != "Probe probe-1 health score dropped 20 points (from 85 to 65) within 2h0m0s" {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+}
+
+func TestValidateRule_HealthConditions(t *testing.T) {
+	engine := NewEngine(nil, nil, nil, nil, zap.NewNop())
+	valid := AlertRule{Name: "degrading", Condition: AlertCondition{Type: "health_score_drop", Threshold: 15, Window: "6h"}}
+	if err := engine.validateRule(valid); err != nil {
+		t.Fatalf("expected valid rule, got %v", err)
+	}
+
+	for _, cond := range []AlertCondition{
+		{Type: "health_score_below", Threshold: 0},
+		{Type: "health_score_below", Threshold: 150},
+		{Type: "health_score_drop", Threshold: 10, Window: "soon"},
+	} {
+		if err := engine.validateRule(AlertRule{Name: "bad", Condition: cond}); err == nil {
+			t.Fatalf("expected validation error for %+v", cond)
+		}
+	}
+}
+
+func TestIngestProbeAlert_FiresDedupsAndResolves(t *testing.T) {
+	engine, store, mgr := newTestEngine(t)
+	defer func() { _ = store.Close() }()
+
+	rule, err := store.CreateRule(AlertRule{
+		Name:    "unit failed",
+		Enabled: true,
+		Condition: AlertCondition{
+			Type:           ConditionProbeAlert,
+			ProbeCondition: protocol.AlertUnitFailed,
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateRule error: %v", err)
+	}
+	mgr.Register("probe-1", "host-1", "linux", "amd64")
+
+	alert := protocol.AlertPayload{
+		Condition: protocol.AlertUnitFailed,
+		Subject:   "nginx.service",
+		Status:    "firing",
+		Message:   "unit nginx.service failed",
+	}
+	result, err := engine.IngestProbeAlert("probe-1", alert)
+	if err != nil {
+		t.Fatalf("IngestProbeAlert error: %v", err)
+	}
+	if result.Duplicate || result.Active != 1 {
+		t.Fatalf("unexpected first ingest result %+v", result)
+	}
+	active := store.ActiveAlerts()
+	if len(active) != 1 || active[0].RuleID != rule.ID || active[0].ProbeID != "probe-1" {
+		t.Fatalf("expected probe_alert rule to fire, got %+v", active)
+	}
+
+	result, err = engine.IngestProbeAlert("probe-1", alert)
+	if err != nil {
+		t.Fatalf("IngestProbeAlert duplicate error: %v", err)
+	}
+	if !result.Duplicate {
+		t.Fatalf("expected identical alert to be deduplicated, got %+v", result)
+	}
+	if events := store.ListEvents(rule.ID, 10); len(events) != 1 {
+		t.Fatalf("expected a single alert event after duplicate, got %d", len(events))
+	}
+
+	// Other conditions don't match the rule.
+	if _, err := engine.IngestProbeAlert("probe-1", protocol.AlertPayload{Condition: protocol.AlertOOMKill, Subject: "java", Message: "oom"}); err != nil {
+		t.Fatalf("IngestProbeAlert oom error: %v", err)
+	}
+
+	// Periodic evaluation keeps the pushed condition firing.
+	if err := engine.Evaluate(); err != nil {
+		t.Fatalf("Evaluate error: %v", err)
+	}
+	if len(store.ActiveAlerts()) != 1 {
+		t.Fatalf("expected alert to stay firing across evaluation")
+	}
+
+	alert.Status = "resolved"
+	if _, err := engine.IngestProbeAlert("probe-1", alert); err != nil {
+		t.Fatalf("IngestProbeAlert resolve error: %v", err)
+	}
+	if active := store.ActiveAlerts(); len(active) != 0 {
+		t.Fatalf("expected alert resolved, got %+v", active)
+	}
+	if got := engine.ProbeAlerts("probe-1"); len(got) != 1 || got[0].Condition != protocol.AlertOOMKill {
+		t.Fatalf("expected only the oom condition to remain, got %+v", got)
+	}
+
+	if result, _ := engine.IngestProbeAlert("probe-1", alert); !result.Duplicate {
+		t.Fatalf("expected resolve of inactive condition to be a duplicate")
+	}
+	if _, err := engine.IngestProbeAlert("probe-1", protocol.AlertPayload{Condition: "x", Status: "bogus"}); err == nil {
+		t.Fatalf("expected invalid status to be rejected")
+	}
+}
+
+type stubInventoryFreshness struct {
+	sources []fleet.FederationSourceFreshness
+}
+
+func (s *stubInventoryFreshness) SourceFreshness() []fleet.FederationSourceFreshness {
+	return s.sources
+}
+
+func TestEvaluate_InventorySourceStaleFiresAndResolves(t *testing.T) {
+	engine, store, _ := newTestEngine(t)
+	defer func() { _ = store.Close() }()
+
+	if _, err := store.CreateRule(AlertRule{
+		Name:      "netbox stale",
+		Enabled:   true,
+		Condition: AlertCondition{Type: ConditionInventorySourceStale, Source: "netbox"},
+	}); err != nil {
+		t.Fatalf("CreateRule error: %v", err)
+	}
+
+	inventory := &stubInventoryFreshness{sources: []fleet.FederationSourceFreshness{
+		{
+			Source:            fleet.FederationSourceDescriptor{ID: "netbox"},
+			StaleAfterSeconds: 600,
+			AgeSeconds:        900,
+			Stale:             true,
+			Sync:              fleet.FederationSourceSync{LastSuccessAt: time.Now().UTC().Add(-15 * time.Minute), LastError: "502 bad gateway"},
+		},
+		{Source: fleet.FederationSourceDescriptor{ID: "tailscale"}, StaleAfterSeconds: 600, AgeSeconds: 900, Stale: true},
+	}}
+	engine.SetInventoryFreshness(inventory)
+
+	if err := engine.Evaluate(); err != nil {
+		t.Fatalf("Evaluate error: %v", err)
+	}
+	active := store.ActiveAlerts()
+	if len(active) != 1 || active[0].ProbeID != InventorySourceSubject("netbox") {
+		t.Fatalf("expected one firing alert for netbox only, got %+v", active)
+	}
+	if want := "Inventory source netbox last synced 15m0s ago (threshold 10m0s): 502 bad gateway"; active[0].Message != want {
+		t.Fatalf("message = %q, want %q", active[0].Message, want)
+	}
+
+	inventory.sources[0].Stale = false
+	if err := engine.Evaluate(); err != nil {
+		t.Fatalf("Evaluate error: %v", err)
+	}
+	if active := store.ActiveAlerts(); len(active) != 0 {
+		t.Fatalf("expected alert to resolve, got %+v", active)
+	}
+	events := store.ListEvents("", 10)
+	if len(events) == 0 || events[0].Message != "Alert resolved for inventory source netbox" {
+		t.Fatalf("expected resolved event for netbox, got %+v", events)
+	}
+}
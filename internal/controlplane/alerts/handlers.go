@@ -220,7 +220,7 @@ func (e *Engine) validateRule(rule AlertRule) error {
 	}
 
 	switch rule.Condition.Type {
-	case "probe_offline", "disk_threshold", "cpu_threshold":
+	case "probe_offline", "disk_threshold", "cpu_threshold", "health_score_below", "health_score_drop", ConditionProbeAlert, ConditionInventorySourceStale:
 	default:
 		return fmt.Errorf("unsupported condition type: %s", rule.Condition.Type)
 	}
@@ -235,6 +235,16 @@ func (e *Engine) validateRule(rule AlertRule) error {
 		}
 	}
 
+	if rule.Condition.Type == "health_score_below" || rule.Condition.Type == "health_score_drop" {
+		if rule.Condition.Threshold <= 0 || rule.Condition.Threshold > 100 {
+			return fmt.Errorf("threshold must be between 1 and 100 health points")
+		}
+	}
+
+	if _, err := parseRuleDuration(rule.Condition.Window); err != nil {
+		return fmt.Errorf("invalid window: %w", err)
+	}
+
 	if len(rule.Actions) == 0 {
 		return nil
 	}
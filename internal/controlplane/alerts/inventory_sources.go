@@ -0,0 +1,83 @@
+package alerts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+)
+
+// ConditionInventorySourceStale fires while a self-syncing inventory source
+// (NetBox, Tailscale) has gone longer than its staleness threshold without a
+// successful sync.
+const ConditionInventorySourceStale = "inventory_source_stale"
+
+// inventorySubjectPrefix marks alert events raised for an inventory source
+// rather than a probe; the event's probe_id is "inventory:<source-id>".
+const inventorySubjectPrefix = "inventory:"
+
+// InventoryFreshness supplies per-source sync freshness for
+// inventory_source_stale rules.
+type InventoryFreshness interface {
+	SourceFreshness() []fleet.FederationSourceFreshness
+}
+
+// SetInventoryFreshness attaches the federation source freshness used by
+// inventory_source_stale rules. Without it those rules never fire.
+func (e *Engine) SetInventoryFreshness(f InventoryFreshness) {
+	e.inventory = f
+}
+
+// InventorySourceSubject is the probe_id recorded on alerts for sourceID.
+func InventorySourceSubject(sourceID string) string {
+	return inventorySubjectPrefix + sourceID
+}
+
+type sourceTarget struct {
+	key     FiringKey
+	ok      bool
+	message string
+}
+
+// staleSourceTargets evaluates an inventory_source_stale rule against every
+// self-syncing source it covers.
+func (e *Engine) staleSourceTargets(rule AlertRule) []sourceTarget {
+	if e.inventory == nil {
+		return nil
+	}
+	want := strings.TrimSpace(rule.Condition.Source)
+	var targets []sourceTarget
+	for _, src := range e.inventory.SourceFreshness() {
+		if want != "" && src.Source.ID != want {
+			continue
+		}
+		target := sourceTarget{key: FiringKey{RuleID: rule.ID, ProbeID: InventorySourceSubject(src.Source.ID)}}
+		if src.Stale {
+			target.ok = true
+			target.message = staleSourceMessage(src)
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+func staleSourceMessage(src fleet.FederationSourceFreshness) string {
+	age := (time.Duration(src.AgeSeconds) * time.Second).String()
+	threshold := (time.Duration(src.StaleAfterSeconds) * time.Second).String()
+	msg := fmt.Sprintf("Inventory source %s last synced %s ago (threshold %s)", src.Source.ID, age, threshold)
+	if src.Sync.LastSuccessAt.IsZero() {
+		msg = fmt.Sprintf("Inventory source %s has not synced in %s (threshold %s)", src.Source.ID, age, threshold)
+	}
+	if src.Sync.LastError != "" {
+		msg += ": " + src.Sync.LastError
+	}
+	return msg
+}
+
+func resolvedMessage(subject string) string {
+	if sourceID, ok := strings.CutPrefix(subject, inventorySubjectPrefix); ok {
+		return fmt.Sprintf("Alert resolved for inventory source %s", sourceID)
+	}
+	return fmt.Sprintf("Alert resolved for probe %s", subject)
+}
@@ -0,0 +1,124 @@
+package alerts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// ConditionProbeAlert matches conditions pushed by probes (failed units, OOM
+// kills, local thresholds) instead of evaluating fleet state.
+const ConditionProbeAlert = "probe_alert"
+
+// probeAlertKey identifies one probe-pushed condition. A probe re-sending the
+// same key while it is active is a duplicate.
+type probeAlertKey struct {
+	ProbeID   string
+	Condition string
+	Subject   string
+}
+
+// ProbeAlertResult reports what the engine did with a probe-pushed alert.
+type ProbeAlertResult struct {
+	Duplicate bool `json:"duplicate"`
+	// Active is the number of conditions the probe currently has firing.
+	Active int `json:"active"`
+}
+
+// IngestProbeAlert records a condition pushed by a probe and re-evaluates
+// rules so probe_alert rules fire or resolve without waiting for the next
+// tick. A firing alert identical to the one already active, or a resolve
+// for a condition that is not active, is reported as a duplicate and
+// changes nothing.
+func (e *Engine) IngestProbeAlert(probeID string, alert protocol.AlertPayload) (ProbeAlertResult, error) {
+	alert.Condition = strings.TrimSpace(alert.Condition)
+	alert.Subject = strings.TrimSpace(alert.Subject)
+	if strings.TrimSpace(probeID) == "" {
+		return ProbeAlertResult{}, fmt.Errorf("probe id is required")
+	}
+	if alert.Condition == "" {
+		return ProbeAlertResult{}, fmt.Errorf("condition is required")
+	}
+	if alert.Status == "" {
+		alert.Status = "firing"
+	}
+	if alert.Status != "firing" && alert.Status != "resolved" {
+		return ProbeAlertResult{}, fmt.Errorf("invalid alert status %q", alert.Status)
+	}
+	if alert.ObservedAt.IsZero() {
+		alert.ObservedAt = time.Now().UTC()
+	}
+
+	key := probeAlertKey{ProbeID: probeID, Condition: alert.Condition, Subject: alert.Subject}
+
+	e.evalMu.Lock()
+	if e.probeAlerts == nil {
+		e.probeAlerts = make(map[probeAlertKey]protocol.AlertPayload)
+	}
+	existing, active := e.probeAlerts[key]
+	duplicate := false
+	switch alert.Status {
+	case "firing":
+		duplicate = active && existing.Severity == alert.Severity && existing.Message == alert.Message
+		if !duplicate {
+			e.probeAlerts[key] = alert
+		}
+	case "resolved":
+		duplicate = !active
+		delete(e.probeAlerts, key)
+	}
+	result := ProbeAlertResult{Duplicate: duplicate, Active: len(e.activeProbeAlertsLocked(probeID))}
+	e.evalMu.Unlock()
+
+	if duplicate {
+		return result, nil
+	}
+	return result, e.Evaluate()
+}
+
+// ProbeAlerts returns the conditions a probe currently has firing.
+func (e *Engine) ProbeAlerts(probeID string) []protocol.AlertPayload {
+	e.evalMu.Lock()
+	defer e.evalMu.Unlock()
+	return e.activeProbeAlertsLocked(probeID)
+}
+
+func (e *Engine) activeProbeAlertsLocked(probeID string) []protocol.AlertPayload {
+	var out []protocol.AlertPayload
+	for key, alert := range e.probeAlerts {
+		if key.ProbeID == probeID {
+			out = append(out, alert)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Condition == out[j].Condition {
+			return out[i].Subject < out[j].Subject
+		}
+		return out[i].Condition < out[j].Condition
+	})
+	return out
+}
+
+// probeAlertMet reports whether the probe has an active pushed condition
+// matching the rule. An empty ProbeCondition matches any condition.
+func (e *Engine) probeAlertMet(rule AlertRule, probeID string) (bool, string) {
+	want := strings.TrimSpace(rule.Condition.ProbeCondition)
+	var matched []string
+	for _, alert := range e.activeProbeAlertsLocked(probeID) {
+		if want != "" && alert.Condition != want {
+			continue
+		}
+		msg := alert.Message
+		if msg == "" {
+			msg = strings.TrimSpace(alert.Condition + " " + alert.Subject)
+		}
+		matched = append(matched, msg)
+	}
+	if len(matched) == 0 {
+		return false, ""
+	}
+	return true, fmt.Sprintf("Probe %s reported: %s", probeID, strings.Join(matched, "; "))
+}
@@ -0,0 +1,221 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// teamsMaxPayloadBytes keeps cards under the ~28 KB incoming-webhook limit
+	// with headroom for the envelope.
+	teamsMaxPayloadBytes = 25 * 1024
+	teamsCardVersion     = "1.4"
+	teamsCardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	teamsTruncatedSuffix = "… (truncated — use \"View full report\" for details)"
+)
+
+// TeamsChannelConfig stores Microsoft Teams incoming-webhook settings.
+type TeamsChannelConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+// SetExternalURL sets the public control-plane URL used for deep links in
+// notifications (e.g. Teams "View probe" actions). Empty disables links.
+func (e *Engine) SetExternalURL(externalURL string) {
+	e.externalURL = strings.TrimRight(strings.TrimSpace(externalURL), "/")
+}
+
+// notificationLink returns a deep link back to the probe (or the alerts page).
+func (e *Engine) notificationLink(msg notificationMessage) string {
+	if e.externalURL == "" {
+		return ""
+	}
+	if msg.ProbeID != "" {
+		return e.externalURL + "/probe/" + url.PathEscape(msg.ProbeID)
+	}
+	return e.externalURL + "/alerts"
+}
+
+func (e *Engine) sendTeams(channel NotificationChannel, msg notificationMessage) error {
+	if channel.Teams == nil {
+		return fmt.Errorf("teams config missing")
+	}
+
+	payload, err := renderTeamsCard(msg, e.notificationLink(msg))
+	if err != nil {
+		return fmt.Errorf("marshal teams payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, channel.Teams.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("build teams request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send teams webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("teams webhook rate limited (retry after %s)", coalesce(resp.Header.Get("Retry-After"), "unknown"))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("teams webhook returned status %d", resp.StatusCode)
+	}
+	// Legacy connectors answer 200 with an error string in the body when the
+	// card is rejected downstream (e.g. "... returned HTTP error 413").
+	if text := strings.TrimSpace(string(body)); strings.Contains(strings.ToLower(text), "error") {
+		return fmt.Errorf("teams webhook rejected card: %s", text)
+	}
+	return nil
+}
+
+// renderTeamsCard builds an incoming-webhook message carrying an Adaptive Card
+// (schema 1.4). The detail block is truncated so the payload stays within the
+// Teams size limit.
+func renderTeamsCard(msg notificationMessage, link string) ([]byte, error) {
+	detail := ""
+	if msg.Detail != nil {
+		if blob, err := json.MarshalIndent(msg.Detail, "", "  "); err == nil {
+			detail = string(blob)
+		}
+	}
+
+	build := func(detail string, truncated bool) ([]byte, error) {
+		card := teamsAdaptiveCard(msg, detail, link, truncated)
+		return json.Marshal(map[string]any{
+			"type": "message",
+			"attachments": []any{map[string]any{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				// Teams rejects cards without an explicit (null) contentUrl.
+				"contentUrl": nil,
+				"content":    card,
+			}},
+		})
+	}
+
+	payload, err := build(detail, false)
+	if err != nil || len(payload) <= teamsMaxPayloadBytes {
+		return payload, err
+	}
+
+	// Shrink the detail block by the overflow (plus suffix) and retry; JSON
+	// escaping can expand characters, so loop until it fits.
+	for len(payload) > teamsMaxPayloadBytes && detail != "" {
+		over := len(payload) - teamsMaxPayloadBytes + len(teamsTruncatedSuffix)
+		keep := len(detail) - over
+		if keep < 0 {
+			keep = 0
+		}
+		detail = truncateUTF8(detail, keep)
+		payload, err = build(detail+teamsTruncatedSuffix, true)
+		if err != nil {
+			return nil, err
+		}
+		if keep == 0 {
+			break
+		}
+	}
+	return payload, nil
+}
+
+func teamsAdaptiveCard(msg notificationMessage, detail, link string, truncated bool) map[string]any {
+	color, style := teamsSeverityStyle(msg.Severity, msg.Status)
+
+	facts := []map[string]string{}
+	addFact := func(title, value string) {
+		if strings.TrimSpace(value) != "" {
+			facts = append(facts, map[string]string{"title": title, "value": value})
+		}
+	}
+	addFact("Event", msg.EventType)
+	addFact("Rule", msg.RuleName)
+	addFact("Probe", msg.ProbeID)
+	addFact("Severity", msg.Severity)
+	addFact("Status", msg.Status)
+
+	body := []any{
+		map[string]any{
+			"type":  "Container",
+			"style": style,
+			"bleed": true,
+			"items": []any{map[string]any{
+				"type":   "TextBlock",
+				"text":   msg.Summary,
+				"weight": "Bolder",
+				"size":   "Medium",
+				"color":  color,
+				"wrap":   true,
+			}},
+		},
+		map[string]any{"type": "FactSet", "facts": facts},
+	}
+	if detail != "" {
+		body = append(body, map[string]any{
+			"type":     "TextBlock",
+			"text":     detail,
+			"fontType": "Monospace",
+			"size":     "Small",
+			"wrap":     true,
+			"isSubtle": true,
+		})
+	}
+
+	card := map[string]any{
+		"$schema": teamsCardSchema,
+		"type":    "AdaptiveCard",
+		"version": teamsCardVersion,
+		"body":    body,
+		// Without this Teams renders cards at a narrow fixed width.
+		"msteams": map[string]any{"width": "Full"},
+	}
+	if link != "" {
+		title := "View in Legator"
+		if truncated {
+			title = "View full report"
+		}
+		card["actions"] = []any{map[string]any{
+			"type":  "Action.OpenUrl",
+			"title": title,
+			"url":   link,
+		}}
+	}
+	return card
+}
+
+// teamsSeverityStyle maps alert severity/status to an Adaptive Card text
+// color and container style.
+func teamsSeverityStyle(severity, status string) (color, style string) {
+	if strings.EqualFold(status, "resolved") {
+		return "Good", "good"
+	}
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "critical":
+		return "Attention", "attention"
+	case "warning":
+		return "Warning", "warning"
+	case "info":
+		return "Accent", "accent"
+	default:
+		return "Default", "emphasis"
+	}
+}
+
+func truncateUTF8(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,137 @@
+package alerts
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestNormalizeTeamsChannelInput(t *testing.T) {
+	if _, err := normalizeChannelInput(NotificationChannel{Name: "ops-teams", Type: ChannelTypeTeams}); err == nil {
+		t.Fatal("expected teams webhook_url validation error")
+	}
+
+	channel, err := normalizeChannelInput(NotificationChannel{
+		Name:  "ops-teams",
+		Type:  "Teams",
+		Teams: &TeamsChannelConfig{WebhookURL: " https://example.webhook.office.com/webhookb2/abc "},
+		Slack: &SlackChannelConfig{WebhookURL: "https://hooks.slack.com/services/T/B/C"},
+	})
+	if err != nil {
+		t.Fatalf("expected valid teams channel: %v", err)
+	}
+	if channel.Type != ChannelTypeTeams || channel.Teams.WebhookURL != "https://example.webhook.office.com/webhookb2/abc" {
+		t.Fatalf("unexpected normalized channel: %+v", channel)
+	}
+	if channel.Slack != nil {
+		t.Fatal("expected foreign channel config to be cleared")
+	}
+}
+
+func TestRenderTeamsCardSeverityAndLink(t *testing.T) {
+	payload, err := renderTeamsCard(notificationMessage{
+		EventType: "alert.fired",
+		Summary:   "[FIRING] disk 95% on web-1",
+		ProbeID:   "prb-1",
+		RuleName:  "disk-high",
+		Severity:  "critical",
+		Status:    "firing",
+		Detail:    map[string]any{"usage": 95},
+	}, "https://legator.example.com/probe/prb-1")
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	var envelope struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			ContentType string  `json:"contentType"`
+			ContentURL  *string `json:"contentUrl"`
+			Content     struct {
+				Version string           `json:"version"`
+				Body    []map[string]any `json:"body"`
+				Actions []map[string]any `json:"actions"`
+				MSTeams map[string]any   `json:"msteams"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if envelope.Type != "message" || len(envelope.Attachments) != 1 {
+		t.Fatalf("unexpected envelope: %s", payload)
+	}
+	att := envelope.Attachments[0]
+	if att.ContentType != "application/vnd.microsoft.card.adaptive" || att.ContentURL != nil {
+		t.Fatalf("unexpected attachment header: %s", payload)
+	}
+	if !strings.Contains(string(payload), `"contentUrl":null`) {
+		t.Fatalf("expected explicit null contentUrl: %s", payload)
+	}
+	if att.Content.Version != "1.4" || att.Content.MSTeams["width"] != "Full" {
+		t.Fatalf("unexpected card version/width: %s", payload)
+	}
+	if att.Content.Body[0]["style"] != "attention" {
+		t.Fatalf("expected attention style for critical, got %v", att.Content.Body[0]["style"])
+	}
+	if len(att.Content.Actions) != 1 || att.Content.Actions[0]["url"] != "https://legator.example.com/probe/prb-1" {
+		t.Fatalf("expected deep link action, got %v", att.Content.Actions)
+	}
+
+	if color, _ := teamsSeverityStyle("critical", "resolved"); color != "Good" {
+		t.Fatalf("expected resolved alerts to render good, got %s", color)
+	}
+}
+
+func TestRenderTeamsCardTruncatesOversizedDetail(t *testing.T) {
+	payload, err := renderTeamsCard(notificationMessage{
+		Summary: "[FIRING] huge",
+		Detail:  map[string]any{"output": strings.Repeat("é\"x", 20000)},
+	}, "https://legator.example.com/alerts")
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if len(payload) > teamsMaxPayloadBytes {
+		t.Fatalf("expected payload <= %d bytes, got %d", teamsMaxPayloadBytes, len(payload))
+	}
+	if !json.Valid(payload) {
+		t.Fatal("expected valid JSON after truncation")
+	}
+	text := string(payload)
+	if !strings.Contains(text, "truncated") || !strings.Contains(text, "View full report") {
+		t.Fatalf("expected truncation marker and full report link")
+	}
+}
+
+func TestSendTeamsDetectsRejectedCard(t *testing.T) {
+	var body string
+	reply := "1"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blob, _ := io.ReadAll(r.Body)
+		body = string(blob)
+		_, _ = io.WriteString(w, reply)
+	}))
+	defer srv.Close()
+
+	e := NewEngine(nil, nil, nil, nil, zap.NewNop())
+	e.SetExternalURL("https://legator.example.com/")
+	channel := NotificationChannel{Name: "teams", Type: ChannelTypeTeams, Teams: &TeamsChannelConfig{WebhookURL: srv.URL}}
+	msg := notificationMessage{Summary: "[TEST] teams", ProbeID: "prb 1"}
+
+	if err := e.sendTeams(channel, msg); err != nil {
+		t.Fatalf("expected delivery success: %v", err)
+	}
+	if !strings.Contains(body, "https://legator.example.com/probe/prb%201") {
+		t.Fatalf("expected escaped deep link in card: %s", body)
+	}
+
+	reply = "Webhook message delivery failed with error: Microsoft Teams endpoint returned HTTP error 413"
+	if err := e.sendTeams(channel, msg); err == nil {
+		t.Fatal("expected error when Teams rejects the card in a 200 body")
+	}
+}
@@ -16,15 +16,22 @@ type AlertRule struct {
 
 // AlertCondition defines what to evaluate.
 type AlertCondition struct {
-	Type      string   `json:"type"`      // "probe_offline", "disk_threshold", "cpu_threshold"
-	Threshold float64  `json:"threshold"` // e.g., 90.0 for 90% disk
-	Duration  string   `json:"duration"`  // e.g., "2m" — condition must persist
+	Type      string   `json:"type"`             // "probe_offline", "disk_threshold", "cpu_threshold", "health_score_below", "health_score_drop", "probe_alert", "inventory_source_stale"
+	Threshold float64  `json:"threshold"`        // e.g., 90.0 for 90% disk; score or points for health rules
+	Duration  string   `json:"duration"`         // e.g., "2m" — condition must persist
+	Window    string   `json:"window,omitempty"` // look-back for health_score_drop, default "1h"
 	Tags      []string `json:"tags,omitempty"`
 	// Severity is an optional routing hint consumed by alert routing policies.
 	// Valid values: "critical", "warning", "info". Omitting it leaves routing
 	// to condition-type and tag matchers. Backward-compatible: old rules without
 	// this field deserialise with Severity == "".
 	Severity string `json:"severity,omitempty"`
+	// ProbeCondition narrows a probe_alert rule to one pushed condition
+	// (e.g. "unit_failed", "oom_kill"). Empty matches any pushed condition.
+	ProbeCondition string `json:"probe_condition,omitempty"`
+	// Source narrows an inventory_source_stale rule to one federation source
+	// ID (e.g. "netbox"). Empty matches every self-syncing source.
+	Source string `json:"source,omitempty"`
 }
 
 // AlertAction defines what to do when a rule fires.
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -66,9 +67,45 @@ func tokenWithInstallCommand(token *Token, fallbackBaseURL string) Token {
 	if copy.InstallCommand == "" {
 		copy.InstallCommand = installCommand(fallbackBaseURL, copy.Value)
 	}
+	if copy.MaxUses > 0 {
+		remaining := max(copy.MaxUses-copy.UseCount, 0)
+		copy.RemainingUses = &remaining
+	}
 	return copy
 }
 
+// generateOptionsFromQuery reads token options from the query string:
+// multi_use, no_expiry, tenant_id, max_uses and valid_until (RFC 3339).
+func generateOptionsFromQuery(r *http.Request) (GenerateOptions, error) {
+	q := r.URL.Query()
+	opts := GenerateOptions{
+		MultiUse: strings.EqualFold(strings.TrimSpace(q.Get("multi_use")), "true"),
+		NoExpiry: strings.EqualFold(strings.TrimSpace(q.Get("no_expiry")), "true"),
+		TenantID: strings.TrimSpace(q.Get("tenant_id")),
+	}
+	if raw := strings.TrimSpace(q.Get("max_uses")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > MaxTokenUses {
+			return opts, fmt.Errorf("max_uses must be between 1 and %d", MaxTokenUses)
+		}
+		opts.MaxUses = n
+	}
+	if raw := strings.TrimSpace(q.Get("valid_until")); raw != "" {
+		if opts.NoExpiry {
+			return opts, fmt.Errorf("valid_until and no_expiry are mutually exclusive")
+		}
+		until, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return opts, fmt.Errorf("valid_until must be an RFC 3339 timestamp")
+		}
+		if !until.After(time.Now()) {
+			return opts, fmt.Errorf("valid_until must be in the future")
+		}
+		opts.ValidUntil = until
+	}
+	return opts, nil
+}
+
 // RegisterRequest is the probe registration request.
 type RegisterRequest struct {
 	Token    string   `json:"token"`
@@ -77,6 +114,9 @@ type RegisterRequest struct {
 	Arch     string   `json:"arch"`
 	Version  string   `json:"version"`
 	Tags     []string `json:"tags,omitempty"`
+	// HeartbeatIntervalSec is how often the probe heartbeats; the control
+	// plane derives the probe's offline threshold from it.
+	HeartbeatIntervalSec int `json:"heartbeat_interval_sec,omitempty"`
 }
 
 // RegisterResponse is returned on successful registration.
@@ -118,6 +158,9 @@ func registerProbe(fm fleet.Fleet, req RegisterRequest) (*registerProbeResult, e
 	fm.Register(probeID, req.Hostname, req.OS, req.Arch)
 	_ = fm.SetAPIKey(probeID, apiKey)
 	_ = fm.SetTags(probeID, req.Tags)
+	if req.HeartbeatIntervalSec > 0 {
+		_ = fm.SetHeartbeatInterval(probeID, time.Duration(req.HeartbeatIntervalSec)*time.Second)
+	}
 	cleaned := cleanupStaleHostnameDuplicates(fm, probeID, req.Hostname)
 
 	return &registerProbeResult{
@@ -210,7 +253,7 @@ func HandleRegister(ts *TokenStore, fm fleet.Fleet, logger *zap.Logger) http.Han
 		resp := RegisterResponse{
 			ProbeID:  result.probeID,
 			APIKey:   result.apiKey,
-			PolicyID: "default-observe",
+			PolicyID: defaultPolicyID,
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -222,15 +265,18 @@ func HandleRegister(ts *TokenStore, fm fleet.Fleet, logger *zap.Logger) http.Han
 // HandleGenerateToken returns an HTTP handler for creating registration tokens.
 func HandleGenerateToken(ts *TokenStore, logger *zap.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		multiUse := strings.EqualFold(strings.TrimSpace(r.URL.Query().Get("multi_use")), "true")
-		noExpiry := strings.EqualFold(strings.TrimSpace(r.URL.Query().Get("no_expiry")), "true")
-		tenantID := strings.TrimSpace(r.URL.Query().Get("tenant_id"))
-		token := ts.GenerateWithOptions(GenerateOptions{MultiUse: multiUse, NoExpiry: noExpiry, TenantID: tenantID})
+		opts, err := generateOptionsFromQuery(r)
+		if err != nil {
+			writeTokenOptionsError(w, err)
+			return
+		}
+		token := ts.GenerateWithOptions(opts)
 		out := tokenWithInstallCommand(token, requestBaseURL(r))
 		logger.Info("token generated",
 			zap.String("expires", out.Expires.Format(time.RFC3339)),
 			zap.Bool("multi_use", out.MultiUse),
-			zap.Bool("no_expiry", noExpiry),
+			zap.Bool("no_expiry", opts.NoExpiry),
+			zap.Int("max_uses", out.MaxUses),
 		)
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(out)
@@ -256,8 +302,21 @@ func HandleListTokens(ts *TokenStore) http.HandlerFunc {
 	}
 }
 
+// defaultPolicyID is the policy a probe lands on when no tag rule matches.
+const defaultPolicyID = "default-observe"
+
+// PolicyAssigner applies a policy to a newly registered probe based on its
+// tags and returns the applied policy ID, or "" to keep the default.
+type PolicyAssigner func(probeID string, tags []string) string
+
 // HandleRegisterWithAudit wraps HandleRegister with audit logging.
 func HandleRegisterWithAudit(ts *TokenStore, fm fleet.Fleet, al AuditRecorder, logger *zap.Logger) http.HandlerFunc {
+	return HandleRegisterWithPolicy(ts, fm, al, nil, logger)
+}
+
+// HandleRegisterWithPolicy is HandleRegisterWithAudit with tag-based policy
+// assignment: assign, when set, runs after the probe is registered.
+func HandleRegisterWithPolicy(ts *TokenStore, fm fleet.Fleet, al AuditRecorder, assign PolicyAssigner, logger *zap.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req RegisterRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -307,7 +366,14 @@ func HandleRegisterWithAudit(ts *TokenStore, fm fleet.Fleet, al AuditRecorder, l
 			)
 		}
 
-		resp := RegisterResponse{ProbeID: result.probeID, APIKey: result.apiKey, PolicyID: "default-observe"}
+		policyID := defaultPolicyID
+		if assign != nil {
+			if assigned := assign(result.probeID, req.Tags); assigned != "" {
+				policyID = assigned
+			}
+		}
+
+		resp := RegisterResponse{ProbeID: result.probeID, APIKey: result.apiKey, PolicyID: policyID}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
 		_ = json.NewEncoder(w).Encode(resp)
@@ -317,17 +383,26 @@ func HandleRegisterWithAudit(ts *TokenStore, fm fleet.Fleet, al AuditRecorder, l
 // HandleGenerateTokenWithAudit wraps HandleGenerateToken with audit logging.
 func HandleGenerateTokenWithAudit(ts *TokenStore, al AuditRecorder, logger *zap.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		multiUse := strings.EqualFold(strings.TrimSpace(r.URL.Query().Get("multi_use")), "true")
-		noExpiry := strings.EqualFold(strings.TrimSpace(r.URL.Query().Get("no_expiry")), "true")
-		tenantID := strings.TrimSpace(r.URL.Query().Get("tenant_id"))
-		token := ts.GenerateWithOptions(GenerateOptions{MultiUse: multiUse, NoExpiry: noExpiry, TenantID: tenantID})
+		opts, err := generateOptionsFromQuery(r)
+		if err != nil {
+			writeTokenOptionsError(w, err)
+			return
+		}
+		token := ts.GenerateWithOptions(opts)
 		out := tokenWithInstallCommand(token, requestBaseURL(r))
 		al.Emit(audit.EventTokenGenerated, "", "api", "Registration token generated")
 		logger.Info("token generated",
 			zap.String("expires", out.Expires.Format("2006-01-02T15:04:05Z")),
 			zap.Bool("multi_use", out.MultiUse),
+			zap.Int("max_uses", out.MaxUses),
 		)
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(out)
 	}
 }
+
+func writeTokenOptionsError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
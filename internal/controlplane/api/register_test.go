@@ -599,6 +599,80 @@ func TestTokenStoreMultiUseAcrossReopen(t *testing.T) {
 	}
 }
 
+func TestTokenStoreMaxUsesCapAcrossReopen(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tokens.db")
+
+	ts, err := NewTokenStore(dbPath)
+	if err != nil {
+		t.Fatalf("new token store: %v", err)
+	}
+	token := ts.GenerateWithOptions(GenerateOptions{MaxUses: 3})
+	if !token.MultiUse {
+		t.Fatal("expected max_uses to imply multi-use")
+	}
+	if !ts.Consume(token.Value) || !ts.Consume(token.Value) {
+		t.Fatal("expected the first two registrations to succeed")
+	}
+	if err := ts.Close(); err != nil {
+		t.Fatalf("close token store: %v", err)
+	}
+
+	reopened, err := NewTokenStore(dbPath)
+	if err != nil {
+		t.Fatalf("reopen token store: %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+
+	active := reopened.ListActive()
+	if len(active) != 1 {
+		t.Fatalf("expected capped token still active, got %d", len(active))
+	}
+	if out := tokenWithInstallCommand(active[0], ""); out.RemainingUses == nil || *out.RemainingUses != 1 || out.UseCount != 2 {
+		t.Fatalf("expected 1 remaining use after reopen, got %+v", out)
+	}
+	if !reopened.Consume(token.Value) {
+		t.Fatal("expected the last allowed registration to succeed")
+	}
+	if reopened.Consume(token.Value) {
+		t.Fatal("expected an exhausted token to be rejected")
+	}
+	if len(reopened.ListActive()) != 0 {
+		t.Fatal("expected an exhausted token to drop out of the active list")
+	}
+}
+
+func TestGenerateTokenHandler_UsageCapAndValidUntil(t *testing.T) {
+	ts := newTestTokenStore(t)
+	handler := HandleGenerateTokenWithAudit(ts, auditRecorderStub{}, testLogger())
+
+	until := time.Now().UTC().Add(72 * time.Hour).Truncate(time.Second)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tokens?max_uses=50&valid_until="+until.Format(time.RFC3339), nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", w.Code, w.Body.String())
+	}
+	var tok Token
+	if err := json.NewDecoder(w.Body).Decode(&tok); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !tok.MultiUse || tok.MaxUses != 50 || tok.RemainingUses == nil || *tok.RemainingUses != 50 || !tok.Expires.Equal(until) {
+		t.Fatalf("unexpected token: %+v", tok)
+	}
+
+	past := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	for _, query := range []string{"max_uses=0", "max_uses=lots", "valid_until=tomorrow", "valid_until=" + past, "no_expiry=true&valid_until=" + until.Format(time.RFC3339)} {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/api/v1/tokens?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+	if ts.Count() != 1 {
+		t.Fatalf("expected rejected requests to create no tokens, got %d", ts.Count())
+	}
+}
+
 func TestTokenStoreListActiveSkipsExpiredAfterReopen(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "tokens.db")
 
@@ -25,6 +25,12 @@ type Token struct {
 	MultiUse       bool      `json:"multi_use,omitempty"`
 	TenantID       string    `json:"tenant_id,omitempty"`
 	InstallCommand string    `json:"install_command,omitempty"`
+	// MaxUses caps registrations with a multi-use token; 0 is unlimited.
+	MaxUses int `json:"max_uses,omitempty"`
+	// UseCount is how many registrations the token has accepted.
+	UseCount int `json:"use_count"`
+	// RemainingUses is MaxUses less UseCount, set only on capped tokens.
+	RemainingUses *int `json:"remaining_uses,omitempty"`
 }
 
 // TokenStore manages registration tokens.
@@ -36,11 +42,18 @@ type TokenStore struct {
 	mu        sync.RWMutex
 }
 
+// MaxTokenUses bounds the max_uses a token may be created with.
+const MaxTokenUses = 100000
+
 // GenerateOptions controls token generation behavior.
 type GenerateOptions struct {
 	MultiUse bool
 	NoExpiry bool
 	TenantID string // optional: tenant assigned to probes registered with this token
+	// MaxUses caps registrations and implies MultiUse; 0 is unlimited.
+	MaxUses int
+	// ValidUntil overrides the default 30 minute expiry when set.
+	ValidUntil time.Time
 }
 
 // NewTokenStore opens (or creates) a SQLite-backed token store.
@@ -86,6 +99,21 @@ func NewTokenStore(dbPath string) (*TokenStore, error) {
 				return err
 			},
 		},
+		{
+			Version:     3,
+			Description: "add max_uses and use_count to tokens",
+			Up: func(tx *sql.Tx) error {
+				for _, stmt := range []string{
+					`ALTER TABLE tokens ADD COLUMN max_uses INTEGER NOT NULL DEFAULT 0`,
+					`ALTER TABLE tokens ADD COLUMN use_count INTEGER NOT NULL DEFAULT 0`,
+				} {
+					if _, err := tx.Exec(stmt); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+						return err
+					}
+				}
+				return nil
+			},
+		},
 	})
 	if err := runner.Migrate(db); err != nil {
 		_ = db.Close()
@@ -134,7 +162,10 @@ func (ts *TokenStore) GenerateWithOptions(opts GenerateOptions) *Token {
 	sig := hex.EncodeToString(mac.Sum(nil))[:16]
 
 	expiry := now.Add(30 * time.Minute)
-	if opts.NoExpiry {
+	switch {
+	case !opts.ValidUntil.IsZero():
+		expiry = opts.ValidUntil.UTC()
+	case opts.NoExpiry:
 		expiry = now.Add(100 * 365 * 24 * time.Hour)
 	}
 
@@ -142,8 +173,9 @@ func (ts *TokenStore) GenerateWithOptions(opts GenerateOptions) *Token {
 		Value:    fmt.Sprintf("prb_%s_%d_%s", id, now.Unix(), sig),
 		Created:  now,
 		Expires:  expiry,
-		MultiUse: opts.MultiUse,
+		MultiUse: opts.MultiUse || opts.MaxUses > 0,
 		TenantID: opts.TenantID,
+		MaxUses:  opts.MaxUses,
 	}
 
 	if ts.serverURL != "" {
@@ -178,10 +210,11 @@ func (ts *TokenStore) ConsumeGetTenant(value string) (valid bool, tenantID strin
 	if t.Used {
 		return false, ""
 	}
-	if !t.MultiUse {
+	t.UseCount++
+	if !t.MultiUse || (t.MaxUses > 0 && t.UseCount >= t.MaxUses) {
 		t.Used = true
-		_ = ts.updateUsed(t.Value, true)
 	}
+	_ = ts.updateUsed(t.Value, t.Used, t.UseCount)
 	return true, t.TenantID
 }
 
@@ -216,15 +249,17 @@ func (ts *TokenStore) Close() error {
 }
 
 func (ts *TokenStore) upsertToken(token *Token) error {
-	_, err := ts.db.Exec(`INSERT INTO tokens (value, created_at, expires_at, used, multi_use, install_command, tenant_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+	_, err := ts.db.Exec(`INSERT INTO tokens (value, created_at, expires_at, used, multi_use, install_command, tenant_id, max_uses, use_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(value) DO UPDATE SET
 			created_at = excluded.created_at,
 			expires_at = excluded.expires_at,
 			used = excluded.used,
 			multi_use = excluded.multi_use,
 			install_command = excluded.install_command,
-			tenant_id = excluded.tenant_id`,
+			tenant_id = excluded.tenant_id,
+			max_uses = excluded.max_uses,
+			use_count = excluded.use_count`,
 		token.Value,
 		token.Created.Format(time.RFC3339Nano),
 		token.Expires.Format(time.RFC3339Nano),
@@ -232,17 +267,19 @@ func (ts *TokenStore) upsertToken(token *Token) error {
 		boolToInt(token.MultiUse),
 		nullableString(token.InstallCommand),
 		token.TenantID,
+		token.MaxUses,
+		token.UseCount,
 	)
 	return err
 }
 
-func (ts *TokenStore) updateUsed(value string, used bool) error {
-	_, err := ts.db.Exec(`UPDATE tokens SET used = ? WHERE value = ?`, boolToInt(used), value)
+func (ts *TokenStore) updateUsed(value string, used bool, useCount int) error {
+	_, err := ts.db.Exec(`UPDATE tokens SET used = ?, use_count = ? WHERE value = ?`, boolToInt(used), useCount, value)
 	return err
 }
 
 func (ts *TokenStore) loadAll() error {
-	rows, err := ts.db.Query(`SELECT value, created_at, expires_at, used, multi_use, install_command, tenant_id FROM tokens`)
+	rows, err := ts.db.Query(`SELECT value, created_at, expires_at, used, multi_use, install_command, tenant_id, max_uses, use_count FROM tokens`)
 	if err != nil {
 		return err
 	}
@@ -254,8 +291,9 @@ func (ts *TokenStore) loadAll() error {
 			used, multiUse              int
 			installCommand              sql.NullString
 			tenantID                    string
+			maxUses, useCount           int
 		)
-		if err := rows.Scan(&value, &createdAt, &expiresAt, &used, &multiUse, &installCommand, &tenantID); err != nil {
+		if err := rows.Scan(&value, &createdAt, &expiresAt, &used, &multiUse, &installCommand, &tenantID, &maxUses, &useCount); err != nil {
 			continue
 		}
 
@@ -275,6 +313,8 @@ func (ts *TokenStore) loadAll() error {
 			Used:     used == 1,
 			MultiUse: multiUse == 1,
 			TenantID: tenantID,
+			MaxUses:  maxUses,
+			UseCount: useCount,
 		}
 		if installCommand.Valid {
 			t.InstallCommand = installCommand.String
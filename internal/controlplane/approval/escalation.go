@@ -0,0 +1,50 @@
+package approval
+
+import "time"
+
+// SetEscalation escalates pending requests once they have waited fraction
+// of their TTL, calling notify with a snapshot of each. A fraction outside
+// (0, 1) disables escalation.
+func (q *Queue) SetEscalation(fraction float64, notify func(Request)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if fraction <= 0 || fraction >= 1 {
+		fraction = 0
+	}
+	q.escalateAfter = fraction
+	q.onEscalate = notify
+}
+
+// EscalateDue marks pending requests that have reached the escalation point
+// as escalated, notifies the escalation handler outside the queue lock, and
+// returns snapshots of the requests escalated by this call.
+func (q *Queue) EscalateDue(now time.Time) []Request {
+	q.mu.Lock()
+	var escalated []Request
+	if q.escalateAfter > 0 {
+		for _, req := range q.requests {
+			if q.escalationDueLocked(req, now) {
+				req.EscalatedAt = now
+				escalated = append(escalated, *req)
+			}
+		}
+	}
+	notify := q.onEscalate
+	q.mu.Unlock()
+
+	if notify != nil {
+		for _, req := range escalated {
+			notify(req)
+		}
+	}
+	return escalated
+}
+
+func (q *Queue) escalationDueLocked(req *Request, now time.Time) bool {
+	if req.Decision != DecisionPending || !req.EscalatedAt.IsZero() || expireIfDueLocked(req, now) {
+		return false
+	}
+	ttl := req.ExpiresAt.Sub(req.CreatedAt)
+	due := req.CreatedAt.Add(time.Duration(float64(ttl) * q.escalateAfter))
+	return !now.Before(due)
+}
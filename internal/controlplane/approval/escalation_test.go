@@ -0,0 +1,52 @@
+package approval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestEscalateDueNotifiesOnceAfterTTLFraction(t *testing.T) {
+	q := NewQueue(time.Hour, 100)
+	var notified []Request
+	q.SetEscalation(0.5, func(req Request) { notified = append(notified, req) })
+
+	cmd := makeCmd("systemctl restart db", protocol.CapRemediate)
+	req, _ := q.Submit("probe-esc", cmd, "deploy", "high", "api")
+	decided, _ := q.Submit("probe-esc", cmd, "deploy", "high", "api")
+	if _, err := q.Decide(decided.ID, DecisionApproved, "keith"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := q.EscalateDue(req.CreatedAt.Add(29 * time.Minute)); len(got) != 0 {
+		t.Fatalf("expected no escalation before half the ttl, got %d", len(got))
+	}
+
+	at := req.CreatedAt.Add(31 * time.Minute)
+	got := q.EscalateDue(at)
+	if len(got) != 1 || got[0].ID != req.ID {
+		t.Fatalf("expected pending request to escalate, got %+v", got)
+	}
+	if len(notified) != 1 || !notified[0].EscalatedAt.Equal(at) {
+		t.Fatalf("expected one notification stamped with escalation time, got %+v", notified)
+	}
+	if stored, _ := q.Get(req.ID); !stored.EscalatedAt.Equal(at) {
+		t.Fatalf("expected escalation tracked on request, got %s", stored.EscalatedAt)
+	}
+
+	if got := q.EscalateDue(req.CreatedAt.Add(45 * time.Minute)); len(got) != 0 || len(notified) != 1 {
+		t.Fatalf("expected no repeat escalation, got %d (notified %d)", len(got), len(notified))
+	}
+}
+
+func TestEscalationDisabledOutsideFractionRange(t *testing.T) {
+	for _, fraction := range []float64{0, -0.5, 1, 1.5} {
+		q := NewQueue(time.Hour, 100)
+		q.SetEscalation(fraction, func(Request) { t.Errorf("fraction %v: unexpected escalation", fraction) })
+		req, _ := q.Submit("probe-esc", makeCmd("reboot", protocol.CapRemediate), "", "critical", "api")
+		if got := q.EscalateDue(req.CreatedAt.Add(59 * time.Minute)); len(got) != 0 {
+			t.Fatalf("fraction %v: expected escalation disabled, got %d", fraction, len(got))
+		}
+	}
+}
@@ -5,6 +5,7 @@
 package approval
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -24,15 +25,68 @@ const (
 	DecisionExpired  Decision = "expired"
 )
 
+// Decision sources record which surface an operator decided through.
+const (
+	DecisionSourceAPI     = "api"
+	DecisionSourceUI      = "ui"
+	DecisionSourceChatOps = "chatops"
+	DecisionSourceMCP     = "mcp"
+	// DecisionSourceSystem marks decisions the control plane made itself,
+	// such as approval timeouts. Clients cannot claim it.
+	DecisionSourceSystem = "system"
+)
+
+// ValidDecisionSource reports whether source is one a client may claim.
+func ValidDecisionSource(source string) bool {
+	switch source {
+	case DecisionSourceAPI, DecisionSourceUI, DecisionSourceChatOps, DecisionSourceMCP:
+		return true
+	}
+	return false
+}
+
+// ReasonRequirement controls when a decision must carry a reason.
+type ReasonRequirement string
+
+const (
+	ReasonOptional   ReasonRequirement = "none"
+	ReasonOnDeny     ReasonRequirement = "deny"
+	ReasonOnDecision ReasonRequirement = "all"
+)
+
+// ValidReasonRequirement reports whether r is a known requirement.
+func ValidReasonRequirement(r ReasonRequirement) bool {
+	switch r {
+	case ReasonOptional, ReasonOnDeny, ReasonOnDecision:
+		return true
+	}
+	return false
+}
+
+// ErrDecisionReasonRequired is returned when a decision omits a reason the
+// request's risk level requires.
+var ErrDecisionReasonRequired = errors.New("decision reason is required")
+
+// DecisionDetails is optional metadata recorded alongside a decision.
+type DecisionDetails struct {
+	Reason string
+	Source string
+}
+
 // ApprovalRecord captures one distinct approval actor and timestamp.
 type ApprovalRecord struct {
 	Actor     string    `json:"actor"`
 	Timestamp time.Time `json:"timestamp"`
+	Reason    string    `json:"reason,omitempty"`
+	Source    string    `json:"source,omitempty"`
 }
 
-// SubmissionOptions controls quorum behavior for submitted approvals.
+// SubmissionOptions controls quorum and expiry behavior for submitted approvals.
 type SubmissionOptions struct {
 	RequireSecondApprover bool
+	// TTL overrides the queue default expiry for this request. Zero uses the
+	// default; values above the queue's max TTL are clamped.
+	TTL time.Duration
 }
 
 // Request is a pending approval item.
@@ -52,8 +106,13 @@ type Request struct {
 	Decision              Decision                 `json:"decision"`
 	DecidedBy             string                   `json:"decided_by,omitempty"`
 	DecidedAt             time.Time                `json:"decided_at,omitempty"`
+	DecisionReason        string                   `json:"decision_reason,omitempty"`
+	DecisionSource        string                   `json:"decision_source,omitempty"`
 	CreatedAt             time.Time                `json:"created_at"`
 	ExpiresAt             time.Time                `json:"expires_at"`
+	// EscalatedAt is when the request was re-notified for sitting pending
+	// too long; zero until then. Requests escalate at most once.
+	EscalatedAt time.Time `json:"escalated_at,omitempty"`
 }
 
 // RequiredApprovalCount returns the approval quorum for this request.
@@ -100,7 +159,15 @@ type Queue struct {
 	mu       sync.RWMutex
 	requests map[string]*Request // id → request
 	ttl      time.Duration
+	maxTTL   time.Duration
 	maxSize  int
+	// reasons maps a risk level to when decisions on it need a reason.
+	reasons map[string]ReasonRequirement
+
+	// escalateAfter is the fraction of a request's TTL after which it is
+	// escalated; zero disables escalation.
+	escalateAfter float64
+	onEscalate    func(Request)
 }
 
 // NewQueue creates a new approval queue.
@@ -114,6 +181,56 @@ func NewQueue(ttl time.Duration, maxSize int) *Queue {
 	return q
 }
 
+// SetMaxTTL bounds per-request TTL overrides. Zero or negative disables
+// overrides beyond the default TTL.
+func (q *Queue) SetMaxTTL(maxTTL time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.maxTTL = maxTTL
+}
+
+// MaxTTL returns the upper bound for per-request TTL overrides.
+func (q *Queue) MaxTTL() time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.maxTTL < q.ttl {
+		return q.ttl
+	}
+	return q.maxTTL
+}
+
+// SetReasonRequirements sets, per risk level, when a decision must include a
+// reason. Levels not in the map never require one.
+func (q *Queue) SetReasonRequirements(reasons map[string]ReasonRequirement) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.reasons = make(map[string]ReasonRequirement, len(reasons))
+	for level, requirement := range reasons {
+		q.reasons[strings.ToLower(strings.TrimSpace(level))] = requirement
+	}
+}
+
+// ReasonRequired reports whether deciding the request with decision needs a
+// reason. Unknown requests report false.
+func (q *Queue) ReasonRequired(id string, decision Decision) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	req, ok := q.requests[id]
+	return ok && q.reasonRequiredLocked(req, decision)
+}
+
+// reasonRequiredLocked reports whether deciding req with decision needs a
+// reason under the configured requirements.
+func (q *Queue) reasonRequiredLocked(req *Request, decision Decision) bool {
+	switch q.reasons[strings.ToLower(req.RiskLevel)] {
+	case ReasonOnDecision:
+		return true
+	case ReasonOnDeny:
+		return decision == DecisionDenied
+	}
+	return false
+}
+
 // Submit adds a new approval request without policy explainability metadata.
 func (q *Queue) Submit(probeID string, cmd *protocol.CommandPayload, reason, riskLevel, requester string) (*Request, error) {
 	return q.SubmitWithPolicyDetails(probeID, cmd, reason, riskLevel, requester, "", nil)
@@ -162,6 +279,14 @@ func (q *Queue) SubmitWithPolicyDetailsAndOptions(probeID string, cmd *protocol.
 		requiredApprovals = 2
 	}
 
+	ttl := q.ttl
+	if options.TTL > 0 {
+		ttl = options.TTL
+		if limit := max(q.maxTTL, q.ttl); ttl > limit {
+			ttl = limit
+		}
+	}
+
 	now := time.Now().UTC()
 	req := &Request{
 		ID:                    uuid.New().String(),
@@ -176,7 +301,7 @@ func (q *Queue) SubmitWithPolicyDetailsAndOptions(probeID string, cmd *protocol.
 		RequiredApprovals:     requiredApprovals,
 		Decision:              DecisionPending,
 		CreatedAt:             now,
-		ExpiresAt:             now.Add(q.ttl),
+		ExpiresAt:             now.Add(ttl),
 	}
 
 	q.requests[req.ID] = req
@@ -185,9 +310,19 @@ func (q *Queue) SubmitWithPolicyDetailsAndOptions(probeID string, cmd *protocol.
 
 // Decide records an approval or denial.
 func (q *Queue) Decide(id string, decision Decision, decidedBy string) (*Request, error) {
+	return q.DecideWithDetails(id, decision, decidedBy, DecisionDetails{})
+}
+
+// DecideWithDetails records an approval or denial along with the operator's
+// reason and the surface it came from. It fails with
+// ErrDecisionReasonRequired when the request's risk level requires a reason
+// and none is given.
+func (q *Queue) DecideWithDetails(id string, decision Decision, decidedBy string, details DecisionDetails) (*Request, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
+	details.Reason = strings.TrimSpace(details.Reason)
+	details.Source = strings.TrimSpace(details.Source)
 	decidedBy = strings.TrimSpace(decidedBy)
 	if decidedBy == "" {
 		return nil, fmt.Errorf("decided_by is required")
@@ -202,8 +337,7 @@ func (q *Queue) Decide(id string, decision Decision, decidedBy string) (*Request
 		return nil, fmt.Errorf("request %s already decided: %s", id, req.Decision)
 	}
 
-	if time.Now().UTC().After(req.ExpiresAt) {
-		req.Decision = DecisionExpired
+	if expireIfDueLocked(req, time.Now().UTC()) {
 		return nil, fmt.Errorf("request %s expired at %s", id, req.ExpiresAt.Format(time.RFC3339))
 	}
 
@@ -211,11 +345,17 @@ func (q *Queue) Decide(id string, decision Decision, decidedBy string) (*Request
 		return nil, fmt.Errorf("invalid decision %q: must be approved or denied", decision)
 	}
 
+	if details.Reason == "" && q.reasonRequiredLocked(req, decision) {
+		return nil, fmt.Errorf("%w for %s-risk %s decisions", ErrDecisionReasonRequired, req.RiskLevel, decision)
+	}
+
 	now := time.Now().UTC()
 	if decision == DecisionDenied {
 		req.Decision = decision
 		req.DecidedBy = decidedBy
 		req.DecidedAt = now
+		req.DecisionReason = details.Reason
+		req.DecisionSource = details.Source
 		return req, nil
 	}
 
@@ -225,7 +365,7 @@ func (q *Queue) Decide(id string, decision Decision, decidedBy string) (*Request
 		}
 	}
 
-	req.Approvals = append(req.Approvals, ApprovalRecord{Actor: decidedBy, Timestamp: now})
+	req.Approvals = append(req.Approvals, ApprovalRecord{Actor: decidedBy, Timestamp: now, Reason: details.Reason, Source: details.Source})
 	if len(req.Approvals) < req.RequiredApprovalCount() {
 		req.Decision = DecisionPending
 		return req, nil
@@ -234,15 +374,21 @@ func (q *Queue) Decide(id string, decision Decision, decidedBy string) (*Request
 	req.Decision = DecisionApproved
 	req.DecidedBy = decidedBy
 	req.DecidedAt = now
+	req.DecisionReason = details.Reason
+	req.DecisionSource = details.Source
 
 	return req, nil
 }
 
-// Get returns a specific request.
+// Get returns a specific request. A pending request past its expiry is
+// transitioned to expired before it is returned.
 func (q *Queue) Get(id string) (*Request, bool) {
-	q.mu.RLock()
-	defer q.mu.RUnlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	req, ok := q.requests[id]
+	if ok {
+		expireIfDueLocked(req, time.Now().UTC())
+	}
 	return req, ok
 }
 
@@ -311,6 +457,7 @@ func (q *Queue) StartReaper(interval time.Duration, stop <-chan struct{}) {
 				q.mu.Lock()
 				q.evictExpiredLocked()
 				q.mu.Unlock()
+				q.EscalateDue(time.Now().UTC())
 			}
 		}
 	}()
@@ -342,23 +489,36 @@ func (q *Queue) WaitForDecision(id string, timeout time.Duration) (*Request, err
 
 func (q *Queue) evictExpiredLocked() {
 	now := time.Now().UTC()
-	for id, req := range q.requests {
-		if req.Decision == DecisionPending && now.After(req.ExpiresAt) {
-			req.Decision = DecisionExpired
-			// Keep expired requests for audit trail; purge old decided
-			_ = id
-		}
+	// Keep expired requests for audit trail; purge old decided below.
+	for _, req := range q.requests {
+		expireIfDueLocked(req, now)
 	}
 
-	// Purge decided requests older than 24h to prevent unbounded growth
+	// Purge requests decided (or expired) more than 24h ago to prevent
+	// unbounded growth. Keyed on the decision time rather than CreatedAt so
+	// long-TTL requests remain observable after they expire.
 	cutoff := now.Add(-24 * time.Hour)
 	for id, req := range q.requests {
-		if req.Decision != DecisionPending && req.CreatedAt.Before(cutoff) {
+		if req.Decision != DecisionPending && req.DecidedAt.Before(cutoff) {
 			delete(q.requests, id)
 		}
 	}
 }
 
+// expireIfDueLocked marks a pending request past its deadline as expired.
+// Returns true when the request is (now) expired.
+func expireIfDueLocked(req *Request, now time.Time) bool {
+	if req.Decision == DecisionExpired {
+		return true
+	}
+	if req.Decision != DecisionPending || !now.After(req.ExpiresAt) {
+		return false
+	}
+	req.Decision = DecisionExpired
+	req.DecidedAt = req.ExpiresAt
+	return true
+}
+
 // sortRequestsByTime sorts newest first. Uses sort inline to avoid import.
 func sortRequestsByTime(reqs []*Request) {
 	// Simple insertion sort — queue is small
@@ -424,7 +584,21 @@ func (q *Queue) GetCheckWorkspace(id, expectedWorkspace string) (*Request, bool)
 // ClassifyRisk returns a risk level based on command intent.
 // Heuristic rules are intentionally conservative: anything that mutates system
 // state is high (approval required); clearly destructive actions are critical.
+// A command that sets env or work_dir is at least high, since either can
+// change what an otherwise harmless command does.
 func ClassifyRisk(cmd *protocol.CommandPayload) string {
+	return processContextRisk(cmd, classifyCommandRisk(cmd))
+}
+
+// processContextRisk raises level to high when cmd sets env or work_dir.
+func processContextRisk(cmd *protocol.CommandPayload, level string) string {
+	if HasProcessContext(cmd) && (level == "low" || level == "medium") {
+		return "high"
+	}
+	return level
+}
+
+func classifyCommandRisk(cmd *protocol.CommandPayload) string {
 	line := strings.TrimSpace(strings.ToLower(strings.Join(append([]string{cmd.Command}, cmd.Args...), " ")))
 	if line == "" {
 		return "medium"
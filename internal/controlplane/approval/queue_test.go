@@ -1,6 +1,7 @@
 package approval
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -100,6 +101,44 @@ func TestDeny(t *testing.T) {
 	}
 }
 
+func TestDecideEnforcesReasonRequirementPerRiskLevel(t *testing.T) {
+	q := NewQueue(5*time.Minute, 100)
+	q.SetReasonRequirements(map[string]ReasonRequirement{"high": ReasonOnDeny, "Critical": ReasonOnDecision})
+
+	high, _ := q.Submit("probe-1", makeCmd("systemctl stop nginx", protocol.CapRemediate), "maint", "high", "api")
+	if _, err := q.Decide(high.ID, DecisionDenied, "keith"); !errors.Is(err, ErrDecisionReasonRequired) {
+		t.Fatalf("expected reason required for high-risk denial, got %v", err)
+	}
+	if got, _ := q.Get(high.ID); got.Decision != DecisionPending {
+		t.Fatalf("rejected decision should leave request pending, got %s", got.Decision)
+	}
+	decided, err := q.DecideWithDetails(high.ID, DecisionDenied, "keith", DecisionDetails{Reason: "  change freeze  ", Source: DecisionSourceUI})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decided.DecisionReason != "change freeze" || decided.DecisionSource != DecisionSourceUI {
+		t.Fatalf("expected trimmed reason and ui source, got %q/%q", decided.DecisionReason, decided.DecisionSource)
+	}
+
+	highApprove, _ := q.Submit("probe-1", makeCmd("systemctl stop nginx", protocol.CapRemediate), "maint", "high", "api")
+	if _, err := q.Decide(highApprove.ID, DecisionApproved, "keith"); err != nil {
+		t.Fatalf("deny-only requirement should not block approval: %v", err)
+	}
+
+	critical, _ := q.Submit("probe-1", makeCmd("rm -rf /var/lib/app", protocol.CapRemediate), "cleanup", "critical", "api")
+	if !q.ReasonRequired(critical.ID, DecisionApproved) {
+		t.Fatal("expected critical approvals to require a reason")
+	}
+	if _, err := q.DecideWithDetails(critical.ID, DecisionApproved, "keith", DecisionDetails{Reason: "   "}); !errors.Is(err, ErrDecisionReasonRequired) {
+		t.Fatalf("expected blank reason to be rejected, got %v", err)
+	}
+
+	low, _ := q.Submit("probe-1", makeCmd("uptime", protocol.CapObserve), "check", "low", "api")
+	if _, err := q.Decide(low.ID, DecisionDenied, "keith"); err != nil {
+		t.Fatalf("levels without a requirement should not need a reason: %v", err)
+	}
+}
+
 func TestExpiry(t *testing.T) {
 	q := NewQueue(50*time.Millisecond, 100)
 	cmd := makeCmd("reboot", protocol.CapRemediate)
@@ -123,6 +162,72 @@ func TestExpiry(t *testing.T) {
 	}
 }
 
+func TestSubmissionTTLOverrideIsBoundedByMaxTTL(t *testing.T) {
+	q := NewQueue(15*time.Minute, 100)
+	q.SetMaxTTL(8 * time.Hour)
+	cmd := makeCmd("systemctl restart db", protocol.CapRemediate)
+
+	req, err := q.SubmitWithPolicyDetailsAndOptions("probe-ttl", cmd, "CAB window", "high", "api", "", nil, SubmissionOptions{TTL: 4 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.ExpiresAt.Sub(req.CreatedAt); got != 4*time.Hour {
+		t.Fatalf("expected 4h ttl, got %s", got)
+	}
+
+	req, err = q.SubmitWithPolicyDetailsAndOptions("probe-ttl", cmd, "too long", "high", "api", "", nil, SubmissionOptions{TTL: 48 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.ExpiresAt.Sub(req.CreatedAt); got != 8*time.Hour {
+		t.Fatalf("expected ttl clamped to 8h, got %s", got)
+	}
+	if q.MaxTTL() != 8*time.Hour {
+		t.Fatalf("expected max ttl 8h, got %s", q.MaxTTL())
+	}
+}
+
+func TestGetTransitionsPastDeadlineToExpired(t *testing.T) {
+	q := NewQueue(5*time.Minute, 100)
+	cmd := makeCmd("systemctl stop app", protocol.CapRemediate)
+
+	req, _ := q.SubmitWithPolicyDetailsAndOptions("probe-ttl", cmd, "short window", "high", "api", "", nil, SubmissionOptions{TTL: 20 * time.Millisecond})
+	time.Sleep(50 * time.Millisecond)
+
+	// No reaper tick has run; Get must still observe the expiry.
+	got, ok := q.Get(req.ID)
+	if !ok {
+		t.Fatal("expected expired request to remain observable")
+	}
+	if got.Decision != DecisionExpired {
+		t.Fatalf("expected expired, got %s", got.Decision)
+	}
+	if !got.DecidedAt.Equal(got.ExpiresAt) {
+		t.Fatalf("expected decided_at to record the expiry time, got %s", got.DecidedAt)
+	}
+}
+
+func TestLongTTLExpiredRequestRetainedAfterCreatedAtWindow(t *testing.T) {
+	q := NewQueue(5*time.Minute, 100)
+	cmd := makeCmd("systemctl restart db", protocol.CapRemediate)
+	req, _ := q.Submit("probe-ttl", cmd, "CAB window", "high", "api")
+
+	// Simulate a request created 30h ago with a 26h TTL (expired 4h ago).
+	q.mu.Lock()
+	req.CreatedAt = time.Now().UTC().Add(-30 * time.Hour)
+	req.ExpiresAt = req.CreatedAt.Add(26 * time.Hour)
+	q.evictExpiredLocked()
+	q.mu.Unlock()
+
+	got, ok := q.Get(req.ID)
+	if !ok {
+		t.Fatal("expected recently expired request to be retained")
+	}
+	if got.Decision != DecisionExpired {
+		t.Fatalf("expected expired, got %s", got.Decision)
+	}
+}
+
 func TestDoubleDecide(t *testing.T) {
 	q := NewQueue(5*time.Minute, 100)
 	cmd := makeCmd("systemctl start app", protocol.CapRemediate)
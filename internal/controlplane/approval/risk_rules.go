@@ -0,0 +1,166 @@
+package approval
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// RiskRule assigns a fixed risk level to commands matching a glob, ahead of
+// the built-in ClassifyRisk heuristics. Rules are evaluated lowest priority
+// first, then oldest first; the first match wins.
+type RiskRule struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	CommandGlob string    `json:"command_glob"` // glob over the full command line (* and ? wildcards)
+	RiskLevel   string    `json:"risk_level"`   // low/medium/high/critical
+	Priority    int       `json:"priority"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RiskRuleManager is the interface used by handlers for risk rule CRUD.
+type RiskRuleManager interface {
+	ListRiskRules() []*RiskRule
+	GetRiskRule(id string) (*RiskRule, bool)
+	AddRiskRule(rule RiskRule) (*RiskRule, error)
+	DeleteRiskRule(id string) error
+	MatchRiskRule(cmd *protocol.CommandPayload) (*RiskRule, bool)
+}
+
+// RiskRuleMatcher resolves the risk rule (if any) for a command.
+type RiskRuleMatcher interface {
+	MatchRiskRule(cmd *protocol.CommandPayload) (*RiskRule, bool)
+}
+
+// riskRuleSet is the in-memory rule set shared by RuleSet and
+// PersistentRuleSet.
+type riskRuleSet struct {
+	mu       sync.RWMutex
+	rules    map[string]*RiskRule
+	compiled map[string]*regexp.Regexp
+}
+
+// ValidRiskLevel reports whether level is one ClassifyRisk can return.
+func ValidRiskLevel(level string) bool {
+	switch level {
+	case "low", "medium", "high", "critical":
+		return true
+	}
+	return false
+}
+
+// ClassifyRiskWithRules returns the risk level of the first matching rule,
+// falling back to ClassifyRisk. The rule is nil when the fallback decided.
+// Either way a command that sets env or work_dir is at least high.
+func ClassifyRiskWithRules(cmd *protocol.CommandPayload, rules RiskRuleMatcher) (string, *RiskRule) {
+	if rules != nil {
+		if rule, ok := rules.MatchRiskRule(cmd); ok {
+			return processContextRisk(cmd, rule.RiskLevel), rule
+		}
+	}
+	return ClassifyRisk(cmd), nil
+}
+
+// AddRiskRule validates and stores a risk rule, assigning an ID and creation
+// time if missing.
+func (rs *RuleSet) AddRiskRule(rule RiskRule) (*RiskRule, error) {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.CommandGlob = strings.TrimSpace(rule.CommandGlob)
+	rule.RiskLevel = strings.ToLower(strings.TrimSpace(rule.RiskLevel))
+
+	if rule.CommandGlob == "" {
+		return nil, fmt.Errorf("command_glob is required")
+	}
+	if !ValidRiskLevel(rule.RiskLevel) {
+		return nil, fmt.Errorf("risk_level must be one of low, medium, high, critical")
+	}
+	re, err := compileCommandGlob(rule.CommandGlob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command_glob: %w", err)
+	}
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	if rule.Name == "" {
+		rule.Name = rule.CommandGlob
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = time.Now().UTC()
+	}
+
+	rs.risk.mu.Lock()
+	defer rs.risk.mu.Unlock()
+	if rs.risk.rules == nil {
+		rs.risk.rules = make(map[string]*RiskRule)
+		rs.risk.compiled = make(map[string]*regexp.Regexp)
+	}
+	stored := rule
+	rs.risk.rules[rule.ID] = &stored
+	rs.risk.compiled[rule.ID] = re
+	return &stored, nil
+}
+
+// DeleteRiskRule removes a risk rule by ID.
+func (rs *RuleSet) DeleteRiskRule(id string) error {
+	rs.risk.mu.Lock()
+	defer rs.risk.mu.Unlock()
+	if _, ok := rs.risk.rules[id]; !ok {
+		return fmt.Errorf("risk rule %s not found", id)
+	}
+	delete(rs.risk.rules, id)
+	delete(rs.risk.compiled, id)
+	return nil
+}
+
+// GetRiskRule returns a risk rule by ID.
+func (rs *RuleSet) GetRiskRule(id string) (*RiskRule, bool) {
+	rs.risk.mu.RLock()
+	defer rs.risk.mu.RUnlock()
+	rule, ok := rs.risk.rules[id]
+	return rule, ok
+}
+
+// ListRiskRules returns all risk rules in evaluation order.
+func (rs *RuleSet) ListRiskRules() []*RiskRule {
+	rs.risk.mu.RLock()
+	defer rs.risk.mu.RUnlock()
+	out := make([]*RiskRule, 0, len(rs.risk.rules))
+	for _, rule := range rs.risk.rules {
+		out = append(out, rule)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Priority != out[j].Priority {
+			return out[i].Priority < out[j].Priority
+		}
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out
+}
+
+// MatchRiskRule returns the first risk rule, in evaluation order, whose glob
+// matches cmd's full command line.
+func (rs *RuleSet) MatchRiskRule(cmd *protocol.CommandPayload) (*RiskRule, bool) {
+	if rs == nil || cmd == nil {
+		return nil, false
+	}
+	line := argvLine(cmd)
+	for _, rule := range rs.ListRiskRules() {
+		rs.risk.mu.RLock()
+		re := rs.risk.compiled[rule.ID]
+		rs.risk.mu.RUnlock()
+		if re != nil && re.MatchString(line) {
+			return rule, true
+		}
+	}
+	return nil, false
+}
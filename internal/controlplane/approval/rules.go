@@ -0,0 +1,356 @@
+package approval
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// AutoApproveRule lets trusted automation skip manual approval for a defined
+// command allowlist. Every populated matcher must match for the rule to fire;
+// empty matchers match anything. Commands classified as critical are never
+// auto-approved unless AllowCritical is set on the rule, and commands that set
+// env or work_dir never unless AllowEnvWorkDir is. Automated requesters such
+// as LLM tasks only match rules that name them as Actor.
+type AutoApproveRule struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Actor           string    `json:"actor,omitempty"`     // API key name/ID or username; empty = any actor
+	ProbeTag        string    `json:"probe_tag,omitempty"` // probe must carry this tag; empty = any probe
+	CommandGlob     string    `json:"command_glob"`        // one glob word per command word (* and ? stay within a path segment)
+	AllowCritical   bool      `json:"allow_critical,omitempty"`
+	AllowEnvWorkDir bool      `json:"allow_env_work_dir,omitempty"`
+	CreatedBy       string    `json:"created_by,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// RequesterLLMTask is the requester recorded for commands an LLM task runs.
+const RequesterLLMTask = "llm-task"
+
+// IsAutomatedRequester reports whether requester is one the control plane
+// uses for commands no person asked for.
+func IsAutomatedRequester(requester string) bool {
+	return strings.EqualFold(strings.TrimSpace(requester), RequesterLLMTask)
+}
+
+// RuleMatchInput describes a command that would otherwise be queued.
+type RuleMatchInput struct {
+	Actor     string
+	ProbeTags []string
+	Command   *protocol.CommandPayload
+	RiskLevel string
+}
+
+// RuleManager is the interface used by handlers for auto-approve rule CRUD.
+type RuleManager interface {
+	List() []*AutoApproveRule
+	Get(id string) (*AutoApproveRule, bool)
+	Add(rule AutoApproveRule) (*AutoApproveRule, error)
+	Delete(id string) error
+	Match(in RuleMatchInput) (*AutoApproveRule, bool)
+}
+
+// RuleSet is an in-memory, concurrency-safe collection of auto-approve rules
+// and risk rules.
+type RuleSet struct {
+	mu       sync.RWMutex
+	rules    map[string]*AutoApproveRule
+	compiled map[string]commandGlob
+
+	risk riskRuleSet
+}
+
+// NewRuleSet creates an empty rule set.
+func NewRuleSet() *RuleSet {
+	return &RuleSet{
+		rules:    make(map[string]*AutoApproveRule),
+		compiled: make(map[string]commandGlob),
+	}
+}
+
+// Add validates and stores a rule, assigning an ID and creation time if missing.
+func (rs *RuleSet) Add(rule AutoApproveRule) (*AutoApproveRule, error) {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Actor = strings.TrimSpace(rule.Actor)
+	rule.ProbeTag = strings.TrimSpace(rule.ProbeTag)
+	rule.CommandGlob = strings.TrimSpace(rule.CommandGlob)
+
+	if rule.CommandGlob == "" {
+		return nil, fmt.Errorf("command_glob is required")
+	}
+	if rule.Actor == "" && rule.ProbeTag == "" && strings.Trim(rule.CommandGlob, "* ") == "" {
+		return nil, fmt.Errorf("rule must constrain at least one of actor, probe_tag or command_glob")
+	}
+	glob, err := compileArgGlob(rule.CommandGlob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command_glob: %w", err)
+	}
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	if rule.Name == "" {
+		rule.Name = rule.CommandGlob
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = time.Now().UTC()
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	stored := rule
+	rs.rules[rule.ID] = &stored
+	rs.compiled[rule.ID] = glob
+	return &stored, nil
+}
+
+// Delete removes a rule by ID.
+func (rs *RuleSet) Delete(id string) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if _, ok := rs.rules[id]; !ok {
+		return fmt.Errorf("auto-approve rule %s not found", id)
+	}
+	delete(rs.rules, id)
+	delete(rs.compiled, id)
+	return nil
+}
+
+// Get returns a rule by ID.
+func (rs *RuleSet) Get(id string) (*AutoApproveRule, bool) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	rule, ok := rs.rules[id]
+	return rule, ok
+}
+
+// List returns all rules, oldest first.
+func (rs *RuleSet) List() []*AutoApproveRule {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	out := make([]*AutoApproveRule, 0, len(rs.rules))
+	for _, rule := range rs.rules {
+		out = append(out, rule)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out
+}
+
+// Match returns the first rule (oldest first) that auto-approves the input.
+func (rs *RuleSet) Match(in RuleMatchInput) (*AutoApproveRule, bool) {
+	if rs == nil || in.Command == nil {
+		return nil, false
+	}
+	critical := in.RiskLevel == "critical"
+	hasContext := HasProcessContext(in.Command)
+	automated := IsAutomatedRequester(in.Actor)
+
+	for _, rule := range rs.List() {
+		if critical && !rule.AllowCritical {
+			continue
+		}
+		if hasContext && !rule.AllowEnvWorkDir {
+			continue
+		}
+		if automated && rule.Actor == "" {
+			continue
+		}
+		if rule.Actor != "" && !strings.EqualFold(rule.Actor, strings.TrimSpace(in.Actor)) {
+			continue
+		}
+		if rule.ProbeTag != "" && !containsFold(in.ProbeTags, rule.ProbeTag) {
+			continue
+		}
+		rs.mu.RLock()
+		glob := rs.compiled[rule.ID]
+		rs.mu.RUnlock()
+		if !glob.match(in.Command) {
+			continue
+		}
+		return rule, true
+	}
+	return nil, false
+}
+
+// CommandLine renders a command for display: its args joined by spaces,
+// preceded by any env assignments and a "cd" into its work_dir, so approvers
+// see everything the probe will run.
+func CommandLine(cmd *protocol.CommandPayload) string {
+	if cmd == nil {
+		return ""
+	}
+	line := argvLine(cmd)
+	if len(cmd.Env) > 0 {
+		keys := make([]string, 0, len(cmd.Env))
+		for key := range cmd.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		assignments := make([]string, 0, len(keys))
+		for _, key := range keys {
+			assignments = append(assignments, key+"="+displayValue(cmd.Env[key]))
+		}
+		line = strings.Join(assignments, " ") + " " + line
+	}
+	if cmd.WorkDir != "" {
+		line = "cd " + displayValue(cmd.WorkDir) + " && " + line
+	}
+	return line
+}
+
+// HasProcessContext reports whether cmd sets env or work_dir.
+func HasProcessContext(cmd *protocol.CommandPayload) bool {
+	return cmd != nil && (cmd.WorkDir != "" || len(cmd.Env) > 0)
+}
+
+// argvLine joins a command and its args; globs match against it.
+func argvLine(cmd *protocol.CommandPayload) string {
+	return strings.TrimSpace(strings.Join(append([]string{cmd.Command}, cmd.Args...), " "))
+}
+
+// displayValue quotes values that would otherwise read ambiguously.
+func displayValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n\"'\\$`;&|<>") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// MatchCommandGlob reports whether cmd's command and args match glob, using
+// the same word-by-word rules as auto-approve rules.
+func MatchCommandGlob(glob string, cmd *protocol.CommandPayload) (bool, error) {
+	compiled, err := compileArgGlob(glob)
+	if err != nil {
+		return false, err
+	}
+	return compiled.match(cmd), nil
+}
+
+// shellMetachars are refused in commands matched by an approving glob: a
+// shell on the probe, such as the one Windows probes join args into, would
+// read them as more than a single word.
+const shellMetachars = " \t\r\n;&|<>`$(){}!%^\"'"
+
+// commandGlob matches a command word by word. Each element matches one word
+// of the command and its args, so "cat /var/log/*" covers "cat
+// /var/log/syslog" but not a second argument or a deeper path.
+type commandGlob []*regexp.Regexp
+
+// compileArgGlob compiles each whitespace-separated word of glob. '*' and '?'
+// match within a single path segment.
+func compileArgGlob(glob string) (commandGlob, error) {
+	words := strings.Fields(glob)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("glob is empty")
+	}
+	out := make(commandGlob, 0, len(words))
+	for _, word := range words {
+		if err := checkCommandWord(word); err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		b.WriteString("^")
+		for _, r := range word {
+			switch r {
+			case '*':
+				b.WriteString(`[^/\\]*`)
+			case '?':
+				b.WriteString(`[^/\\]`)
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		b.WriteString("$")
+		re, err := regexp.Compile(b.String())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+func (g commandGlob) match(cmd *protocol.CommandPayload) bool {
+	words, ok := commandWords(cmd)
+	if !ok || len(g) == 0 || len(words) != len(g) {
+		return false
+	}
+	for i, re := range g {
+		if !re.MatchString(words[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// commandWords splits cmd into the words a glob matches. ok is false when
+// any word carries a shell metacharacter or a ".." path segment; such
+// commands are never approved by pattern.
+func commandWords(cmd *protocol.CommandPayload) ([]string, bool) {
+	if cmd == nil {
+		return nil, false
+	}
+	words := strings.Fields(cmd.Command)
+	if len(words) == 0 {
+		return nil, false
+	}
+	words = append(words, cmd.Args...)
+	for _, word := range words {
+		if checkCommandWord(word) != nil {
+			return nil, false
+		}
+	}
+	return words, true
+}
+
+func checkCommandWord(word string) error {
+	if i := strings.IndexAny(word, shellMetachars); i >= 0 {
+		return fmt.Errorf("%q contains shell metacharacter %q", word, word[i])
+	}
+	for _, segment := range strings.FieldsFunc(word, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return fmt.Errorf("%q contains \"..\"", word)
+		}
+	}
+	return nil
+}
+
+// compileCommandGlob turns a shell-style glob over the whole command line
+// into an anchored regexp. Risk rules use it: '*' spans args and '/', so
+// "wipe-cache*" classifies every form of the command.
+func compileCommandGlob(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func containsFold(values []string, needle string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), needle) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package approval
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/migration"
+	_ "modernc.org/sqlite"
+)
+
+// PersistentRuleSet wraps RuleSet with SQLite persistence.
+type PersistentRuleSet struct {
+	*RuleSet
+	db *sql.DB
+}
+
+// NewPersistentRuleSet opens (or creates) a SQLite-backed auto-approve rule set.
+func NewPersistentRuleSet(dbPath string) (*PersistentRuleSet, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open approval rules db: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec("PRAGMA busy_timeout=5000"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("set busy_timeout: %w", err)
+	}
+
+	runner := migration.NewRunner("approval_rules", []migration.Migration{
+		{
+			Version:     1,
+			Description: "initial auto-approve rule schema",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS auto_approve_rules (
+					id             TEXT PRIMARY KEY,
+					name           TEXT NOT NULL,
+					actor          TEXT NOT NULL DEFAULT '',
+					probe_tag      TEXT NOT NULL DEFAULT '',
+					command_glob   TEXT NOT NULL,
+					allow_critical INTEGER NOT NULL DEFAULT 0,
+					created_by     TEXT NOT NULL DEFAULT '',
+					created_at     TEXT NOT NULL
+				)`)
+				return err
+			},
+		},
+		{
+			Version:     2,
+			Description: "add risk classification rules",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS risk_rules (
+					id           TEXT PRIMARY KEY,
+					name         TEXT NOT NULL,
+					command_glob TEXT NOT NULL,
+					risk_level   TEXT NOT NULL,
+					priority     INTEGER NOT NULL DEFAULT 0,
+					created_by   TEXT NOT NULL DEFAULT '',
+					created_at   TEXT NOT NULL
+				)`)
+				return err
+			},
+		},
+		{
+			Version:     3,
+			Description: "add auto-approve env and work_dir opt-in",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE auto_approve_rules ADD COLUMN allow_env_work_dir INTEGER NOT NULL DEFAULT 0`)
+				return err
+			},
+		},
+	})
+	if err := runner.Migrate(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("migrate approval rules db: %w", err)
+	}
+
+	prs := &PersistentRuleSet{RuleSet: NewRuleSet(), db: db}
+	if err := prs.loadFromDB(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := prs.loadRiskRules(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return prs, nil
+}
+
+// Add validates a rule, stores it in memory and persists it.
+func (prs *PersistentRuleSet) Add(rule AutoApproveRule) (*AutoApproveRule, error) {
+	stored, err := prs.RuleSet.Add(rule)
+	if err != nil {
+		return nil, err
+	}
+	allowCritical := 0
+	if stored.AllowCritical {
+		allowCritical = 1
+	}
+	allowEnvWorkDir := 0
+	if stored.AllowEnvWorkDir {
+		allowEnvWorkDir = 1
+	}
+	if _, err := prs.db.Exec(`INSERT OR REPLACE INTO auto_approve_rules
+		(id, name, actor, probe_tag, command_glob, allow_critical, allow_env_work_dir, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		stored.ID, stored.Name, stored.Actor, stored.ProbeTag, stored.CommandGlob,
+		allowCritical, allowEnvWorkDir, stored.CreatedBy, stored.CreatedAt.Format(time.RFC3339Nano),
+	); err != nil {
+		_ = prs.RuleSet.Delete(stored.ID)
+		return nil, fmt.Errorf("persist auto-approve rule: %w", err)
+	}
+	return stored, nil
+}
+
+// Delete removes a rule from both memory and disk.
+func (prs *PersistentRuleSet) Delete(id string) error {
+	if err := prs.RuleSet.Delete(id); err != nil {
+		return err
+	}
+	_, _ = prs.db.Exec(`DELETE FROM auto_approve_rules WHERE id = ?`, id)
+	return nil
+}
+
+// AddRiskRule validates a risk rule, stores it in memory and persists it.
+func (prs *PersistentRuleSet) AddRiskRule(rule RiskRule) (*RiskRule, error) {
+	stored, err := prs.RuleSet.AddRiskRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := prs.db.Exec(`INSERT OR REPLACE INTO risk_rules
+		(id, name, command_glob, risk_level, priority, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		stored.ID, stored.Name, stored.CommandGlob, stored.RiskLevel, stored.Priority,
+		stored.CreatedBy, stored.CreatedAt.Format(time.RFC3339Nano),
+	); err != nil {
+		_ = prs.RuleSet.DeleteRiskRule(stored.ID)
+		return nil, fmt.Errorf("persist risk rule: %w", err)
+	}
+	return stored, nil
+}
+
+// DeleteRiskRule removes a risk rule from both memory and disk.
+func (prs *PersistentRuleSet) DeleteRiskRule(id string) error {
+	if err := prs.RuleSet.DeleteRiskRule(id); err != nil {
+		return err
+	}
+	_, _ = prs.db.Exec(`DELETE FROM risk_rules WHERE id = ?`, id)
+	return nil
+}
+
+// Close shuts down the database.
+func (prs *PersistentRuleSet) Close() error {
+	return prs.db.Close()
+}
+
+func (prs *PersistentRuleSet) loadFromDB() error {
+	rows, err := prs.db.Query(`SELECT id, name, actor, probe_tag, command_glob, allow_critical, allow_env_work_dir, created_by, created_at
+		FROM auto_approve_rules`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			rule            AutoApproveRule
+			allowCritical   int
+			allowEnvWorkDir int
+			createdStr      string
+		)
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.Actor, &rule.ProbeTag, &rule.CommandGlob,
+			&allowCritical, &allowEnvWorkDir, &rule.CreatedBy, &createdStr); err != nil {
+			return err
+		}
+		rule.AllowCritical = allowCritical != 0
+		rule.AllowEnvWorkDir = allowEnvWorkDir != 0
+		rule.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdStr)
+		if _, err := prs.RuleSet.Add(rule); err != nil {
+			return fmt.Errorf("load auto-approve rule %s: %w", rule.ID, err)
+		}
+	}
+	return rows.Err()
+}
+
+func (prs *PersistentRuleSet) loadRiskRules() error {
+	rows, err := prs.db.Query(`SELECT id, name, command_glob, risk_level, priority, created_by, created_at
+		FROM risk_rules`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			rule       RiskRule
+			createdStr string
+		)
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.CommandGlob, &rule.RiskLevel, &rule.Priority,
+			&rule.CreatedBy, &createdStr); err != nil {
+			return err
+		}
+		rule.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdStr)
+		if _, err := prs.RuleSet.AddRiskRule(rule); err != nil {
+			return fmt.Errorf("load risk rule %s: %w", rule.ID, err)
+		}
+	}
+	return rows.Err()
+}
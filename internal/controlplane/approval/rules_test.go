@@ -0,0 +1,287 @@
+package approval
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestRuleSetMatchesActorTagAndGlob(t *testing.T) {
+	rs := NewRuleSet()
+	rule, err := rs.Add(AutoApproveRule{Name: "ci restarts", Actor: "ci-bot", ProbeTag: "staging", CommandGlob: "systemctl restart *"})
+	if err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+
+	cmd := makeCmd("systemctl restart nginx", protocol.CapRemediate)
+	got, ok := rs.Match(RuleMatchInput{Actor: "ci-bot", ProbeTags: []string{"Staging"}, Command: cmd, RiskLevel: "high"})
+	if !ok || got.ID != rule.ID {
+		t.Fatalf("expected rule %s to match, got %+v", rule.ID, got)
+	}
+
+	cases := []RuleMatchInput{
+		{Actor: "alice", ProbeTags: []string{"staging"}, Command: cmd, RiskLevel: "high"},
+		{Actor: "ci-bot", ProbeTags: []string{"prod"}, Command: cmd, RiskLevel: "high"},
+		{Actor: "ci-bot", ProbeTags: []string{"staging"}, Command: makeCmd("systemctl stop nginx", protocol.CapRemediate), RiskLevel: "high"},
+	}
+	for i, in := range cases {
+		if _, ok := rs.Match(in); ok {
+			t.Fatalf("case %d: expected no match", i)
+		}
+	}
+}
+
+func TestRuleSetGlobMatchesArgByArg(t *testing.T) {
+	rs := NewRuleSet()
+	if _, err := rs.Add(AutoApproveRule{CommandGlob: "cat /var/log/*"}); err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	match := func(command string, args ...string) bool {
+		_, ok := rs.Match(RuleMatchInput{Command: &protocol.CommandPayload{Command: command, Args: args}, RiskLevel: "low"})
+		return ok
+	}
+	if !match("cat", "/var/log/syslog") || !match("cat /var/log/syslog") {
+		t.Fatal("expected glob to match a single file under /var/log")
+	}
+	for _, args := range [][]string{
+		{"/var/log/app/debug.log"},
+		{"/var/log/../../etc/shadow"},
+		{"/var/log/x;", "rm", "-rf", "/"},
+		{"/var/log/x", "/etc/shadow"},
+		{"/var/log/x /etc/shadow"},
+		{"/var/log/$(id)"},
+	} {
+		if match("cat", args...) {
+			t.Errorf("glob must not match cat %q", args)
+		}
+	}
+	if match("cat /var/log/x; rm -rf /") {
+		t.Error("glob must not match a command carrying a shell separator")
+	}
+
+	for _, glob := range []string{"cat /var/log/../*", "cat /var/log/* | sh", "cat $HOME/*"} {
+		if _, err := rs.Add(AutoApproveRule{CommandGlob: glob}); err == nil {
+			t.Errorf("expected %q to be rejected", glob)
+		}
+	}
+}
+
+func TestRuleSetSkipsAutomatedRequesterUnlessNamed(t *testing.T) {
+	rs := NewRuleSet()
+	if _, err := rs.Add(AutoApproveRule{ProbeTag: "staging", CommandGlob: "systemctl restart nginx"}); err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	in := RuleMatchInput{Actor: RequesterLLMTask, ProbeTags: []string{"staging"}, Command: makeCmd("systemctl restart nginx", protocol.CapRemediate), RiskLevel: "high"}
+	if _, ok := rs.Match(in); ok {
+		t.Fatal("LLM task commands must not match a rule that does not name the actor")
+	}
+	in.Actor = "alice"
+	if _, ok := rs.Match(in); !ok {
+		t.Fatal("expected rule to match a person")
+	}
+
+	named, err := rs.Add(AutoApproveRule{Actor: RequesterLLMTask, CommandGlob: "systemctl restart nginx"})
+	if err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	in.Actor = RequesterLLMTask
+	if got, ok := rs.Match(in); !ok || got.ID != named.ID {
+		t.Fatalf("expected rule naming %s to match, got %+v", RequesterLLMTask, got)
+	}
+}
+
+func TestRuleSetNeverAutoApprovesCriticalWithoutOptIn(t *testing.T) {
+	rs := NewRuleSet()
+	if _, err := rs.Add(AutoApproveRule{Actor: "ops-bot", CommandGlob: "reboot*"}); err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	in := RuleMatchInput{Actor: "ops-bot", Command: makeCmd("reboot", protocol.CapRemediate), RiskLevel: "critical"}
+	if _, ok := rs.Match(in); ok {
+		t.Fatal("critical command must not be auto-approved without allow_critical")
+	}
+
+	if _, err := rs.Add(AutoApproveRule{Actor: "ops-bot", CommandGlob: "reboot", AllowCritical: true}); err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	if got, ok := rs.Match(in); !ok || !got.AllowCritical {
+		t.Fatalf("expected opted-in rule to match, got %+v", got)
+	}
+}
+
+func TestRuleSetRefusesEnvAndWorkDirWithoutOptIn(t *testing.T) {
+	rs := NewRuleSet()
+	if _, err := rs.Add(AutoApproveRule{Actor: "ci-bot", CommandGlob: "make *"}); err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	for _, cmd := range []*protocol.CommandPayload{
+		{Command: "make", Args: []string{"deploy"}, Env: map[string]string{"APP_ENV": "prod"}},
+		{Command: "make", Args: []string{"deploy"}, WorkDir: "/opt/app"},
+	} {
+		if _, ok := rs.Match(RuleMatchInput{Actor: "ci-bot", Command: cmd, RiskLevel: "high"}); ok {
+			t.Fatalf("%s must not be auto-approved without allow_env_work_dir", CommandLine(cmd))
+		}
+	}
+
+	if _, err := rs.Add(AutoApproveRule{Actor: "ci-bot", CommandGlob: "make deploy", AllowEnvWorkDir: true}); err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	cmd := &protocol.CommandPayload{Command: "make", Args: []string{"deploy"}, Env: map[string]string{"APP_ENV": "prod"}, WorkDir: "/opt/app"}
+	if got, ok := rs.Match(RuleMatchInput{Actor: "ci-bot", Command: cmd, RiskLevel: "high"}); !ok || !got.AllowEnvWorkDir {
+		t.Fatalf("expected opted-in rule to match, got %+v", got)
+	}
+}
+
+func TestCommandLineShowsEnvAndWorkDir(t *testing.T) {
+	cmd := &protocol.CommandPayload{
+		Command: "make",
+		Args:    []string{"deploy"},
+		Env:     map[string]string{"RELEASE": "v1 rc", "APP_ENV": "prod"},
+		WorkDir: "/opt/app",
+	}
+	want := `cd /opt/app && APP_ENV=prod RELEASE="v1 rc" make deploy`
+	if got := CommandLine(cmd); got != want {
+		t.Fatalf("CommandLine = %q, want %q", got, want)
+	}
+	if got := CommandLine(makeCmd("ls /tmp", protocol.CapObserve)); got != "ls /tmp" {
+		t.Fatalf("CommandLine = %q, want plain command line", got)
+	}
+}
+
+func TestClassifyRiskRaisesEnvAndWorkDir(t *testing.T) {
+	rs := NewRuleSet()
+	if _, err := rs.AddRiskRule(RiskRule{CommandGlob: "cat *", RiskLevel: "low"}); err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	plain := makeCmd("cat /etc/hostname", protocol.CapObserve)
+	if risk, _ := ClassifyRiskWithRules(plain, rs); risk != "low" {
+		t.Fatalf("expected low for plain command, got %s", risk)
+	}
+	withEnv := makeCmd("cat /etc/hostname", protocol.CapObserve)
+	withEnv.Env = map[string]string{"LANG": "C"}
+	if risk, rule := ClassifyRiskWithRules(withEnv, rs); risk != "high" || rule == nil {
+		t.Fatalf("expected rule match raised to high, got %s (%+v)", risk, rule)
+	}
+	withDir := makeCmd("ls", protocol.CapObserve)
+	withDir.WorkDir = "/opt/app"
+	if risk := ClassifyRisk(withDir); risk != "high" {
+		t.Fatalf("expected high for work_dir, got %s", risk)
+	}
+	critical := makeCmd("reboot", protocol.CapRemediate)
+	critical.WorkDir = "/opt/app"
+	if risk := ClassifyRisk(critical); risk != "critical" {
+		t.Fatalf("expected critical to stay critical, got %s", risk)
+	}
+}
+
+func TestRuleSetValidationAndDelete(t *testing.T) {
+	rs := NewRuleSet()
+	if _, err := rs.Add(AutoApproveRule{}); err == nil {
+		t.Fatal("expected error for missing command_glob")
+	}
+	if _, err := rs.Add(AutoApproveRule{CommandGlob: "*"}); err == nil {
+		t.Fatal("expected error for unconstrained rule")
+	}
+
+	rule, err := rs.Add(AutoApproveRule{ProbeTag: "lab", CommandGlob: "*"})
+	if err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	if len(rs.List()) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rs.List()))
+	}
+	if err := rs.Delete(rule.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := rs.Delete(rule.ID); err == nil {
+		t.Fatal("expected error deleting missing rule")
+	}
+	if len(rs.List()) != 0 {
+		t.Fatal("expected empty rule set")
+	}
+}
+
+func TestRiskRulesClassifyBeforeHeuristics(t *testing.T) {
+	rs := NewRuleSet()
+	cmd := makeCmd("wipe-cache --all", protocol.CapRemediate)
+	if risk, rule := ClassifyRiskWithRules(cmd, rs); risk != "high" || rule != nil {
+		t.Fatalf("expected heuristic fallback high, got %s (%+v)", risk, rule)
+	}
+
+	low, err := rs.AddRiskRule(RiskRule{CommandGlob: "wipe-cache --dry-run*", RiskLevel: "low", Priority: -1})
+	if err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	critical, err := rs.AddRiskRule(RiskRule{Name: "cache wipe", CommandGlob: "wipe-cache*", RiskLevel: "CRITICAL"})
+	if err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+
+	if risk, rule := ClassifyRiskWithRules(cmd, rs); risk != "critical" || rule == nil || rule.ID != critical.ID {
+		t.Fatalf("expected critical from rule %s, got %s (%+v)", critical.ID, risk, rule)
+	}
+	if risk, rule := ClassifyRiskWithRules(makeCmd("wipe-cache --dry-run", protocol.CapObserve), rs); risk != "low" || rule.ID != low.ID {
+		t.Fatalf("expected lower-priority rule to win, got %s (%+v)", risk, rule)
+	}
+	if risk, rule := ClassifyRiskWithRules(makeCmd("ls /tmp", protocol.CapObserve), rs); risk != "low" || rule != nil {
+		t.Fatalf("expected heuristic for unmatched command, got %s (%+v)", risk, rule)
+	}
+
+	if _, err := rs.AddRiskRule(RiskRule{CommandGlob: "x", RiskLevel: "severe"}); err == nil {
+		t.Fatal("expected error for unknown risk level")
+	}
+	if err := rs.DeleteRiskRule(critical.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if risk, _ := ClassifyRiskWithRules(cmd, rs); risk != "high" {
+		t.Fatalf("expected fallback after delete, got %s", risk)
+	}
+}
+
+func TestPersistentRuleSetReloadsRiskRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approval_rules.db")
+	prs, err := NewPersistentRuleSet(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	rule, err := prs.AddRiskRule(RiskRule{CommandGlob: "wipe-cache*", RiskLevel: "critical", Priority: 5})
+	if err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	_ = prs.Close()
+
+	reopened, err := NewPersistentRuleSet(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	got, ok := reopened.GetRiskRule(rule.ID)
+	if !ok || got.RiskLevel != "critical" || got.Priority != 5 {
+		t.Fatalf("expected persisted rule, got %+v", got)
+	}
+	if _, ok := reopened.MatchRiskRule(makeCmd("wipe-cache", protocol.CapRemediate)); !ok {
+		t.Fatal("expected reloaded rule to match")
+	}
+}
+
+func TestPersistentRuleSetReloadsEnvWorkDirOptIn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approval_rules.db")
+	prs, err := NewPersistentRuleSet(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	rule, err := prs.Add(AutoApproveRule{Actor: "ci-bot", CommandGlob: "make deploy", AllowEnvWorkDir: true})
+	if err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	_ = prs.Close()
+
+	reopened, err := NewPersistentRuleSet(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	if got, ok := reopened.Get(rule.ID); !ok || !got.AllowEnvWorkDir {
+		t.Fatalf("expected persisted allow_env_work_dir, got %+v", got)
+	}
+}
@@ -0,0 +1,312 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxRunBytes caps the total size of all artifacts attached to one run.
+const DefaultMaxRunBytes int64 = 100 << 20
+
+// tempArtifactMarker names in-flight uploads so listings and size checks skip
+// them.
+const tempArtifactMarker = ".upload-"
+
+var (
+	ErrRunIDInvalid     = errors.New("run_id contains invalid characters")
+	ErrArtifactNotFound = errors.New("artifact not found")
+	ErrRunQuotaExceeded = errors.New("run artifact quota exceeded")
+)
+
+// Info describes one stored artifact.
+type Info struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// Store keeps run artifacts on disk under root/<run-id>/<path>.
+type Store struct {
+	root        string
+	maxRunBytes int64
+
+	mu      sync.Mutex
+	runLock map[string]*sync.Mutex
+}
+
+// NewStore creates a disk store rooted at root. maxRunBytes <= 0 uses
+// DefaultMaxRunBytes.
+func NewStore(root string, maxRunBytes int64) *Store {
+	if maxRunBytes <= 0 {
+		maxRunBytes = DefaultMaxRunBytes
+	}
+	return &Store{root: root, maxRunBytes: maxRunBytes, runLock: make(map[string]*sync.Mutex)}
+}
+
+// Root returns the store's base directory.
+func (s *Store) Root() string { return s.root }
+
+// MaxRunBytes returns the per-run size cap.
+func (s *Store) MaxRunBytes() int64 { return s.maxRunBytes }
+
+// Path resolves an artifact to its on-disk location, rejecting run IDs and
+// paths that would escape the run's directory.
+func (s *Store) Path(runID, artifactPath string) (string, error) {
+	runRoot, err := s.runDir(runID)
+	if err != nil {
+		return "", err
+	}
+	artifactPath = strings.TrimSpace(strings.ReplaceAll(artifactPath, "\\", "/"))
+	if artifactPath == "" {
+		return "", ErrPathRequired
+	}
+	for _, part := range strings.Split(artifactPath, "/") {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "." {
+			continue
+		}
+		if part == ".." {
+			return "", ErrPathInvalid
+		}
+	}
+
+	target := filepath.Clean(filepath.Join(runRoot, filepath.FromSlash(artifactPath)))
+	if target == runRoot || !strings.HasPrefix(target, runRoot+string(os.PathSeparator)) {
+		return "", ErrPathInvalid
+	}
+	return target, nil
+}
+
+func (s *Store) runDir(runID string) (string, error) {
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return "", ErrRunIDRequired
+	}
+	if strings.Contains(runID, "/") || strings.Contains(runID, "\\") || strings.Contains(runID, "..") {
+		return "", ErrRunIDInvalid
+	}
+	return filepath.Clean(filepath.Join(s.root, runID)), nil
+}
+
+func (s *Store) lockRun(runID string) func() {
+	s.mu.Lock()
+	l, ok := s.runLock[runID]
+	if !ok {
+		l = &sync.Mutex{}
+		s.runLock[runID] = l
+	}
+	s.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// Write stores an artifact, replacing any existing one at the same path.
+// The upload is staged in a temporary file and only moved into place if the
+// run's total stays within the cap; otherwise ErrRunQuotaExceeded is returned
+// and the previous artifact (if any) is left untouched.
+func (s *Store) Write(runID, artifactPath string, r io.Reader) (int64, error) {
+	target, err := s.Path(runID, artifactPath)
+	if err != nil {
+		return 0, err
+	}
+	unlock := s.lockRun(strings.TrimSpace(runID))
+	defer unlock()
+
+	used, err := s.RunSize(runID)
+	if err != nil {
+		return 0, err
+	}
+	if info, statErr := os.Stat(target); statErr == nil {
+		used -= info.Size()
+	}
+	remaining := s.maxRunBytes - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return 0, fmt.Errorf("create artifact directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), filepath.Base(target)+tempArtifactMarker+"*")
+	if err != nil {
+		return 0, fmt.Errorf("open artifact target: %w", err)
+	}
+	tmpName := tmp.Name()
+	cleanup := func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+	}
+
+	written, err := io.Copy(tmp, io.LimitReader(r, remaining+1))
+	if err != nil {
+		cleanup()
+		return 0, fmt.Errorf("store artifact: %w", err)
+	}
+	if written > remaining {
+		cleanup()
+		return 0, fmt.Errorf("%w: run %s is limited to %d bytes", ErrRunQuotaExceeded, runID, s.maxRunBytes)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return 0, fmt.Errorf("store artifact: %w", err)
+	}
+	if err := os.Chmod(tmpName, 0o640); err != nil {
+		_ = os.Remove(tmpName)
+		return 0, fmt.Errorf("store artifact: %w", err)
+	}
+	if err := os.Rename(tmpName, target); err != nil {
+		_ = os.Remove(tmpName)
+		return 0, fmt.Errorf("store artifact: %w", err)
+	}
+	return written, nil
+}
+
+// Open opens a stored artifact for reading.
+func (s *Store) Open(runID, artifactPath string) (*os.File, os.FileInfo, error) {
+	target, err := s.Path(runID, artifactPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(target)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrArtifactNotFound
+		}
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		_ = f.Close()
+		return nil, nil, ErrArtifactNotFound
+	}
+	return f, info, nil
+}
+
+// List returns a run's artifacts sorted by path. A run without artifacts
+// returns an empty list.
+func (s *Store) List(runID string) ([]Info, error) {
+	runRoot, err := s.runDir(runID)
+	if err != nil {
+		return nil, err
+	}
+	out := []Info{}
+	err = filepath.WalkDir(runRoot, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if errors.Is(walkErr, os.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return walkErr
+		}
+		if d.IsDir() || isTempArtifact(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(runRoot, path)
+		if err != nil {
+			return err
+		}
+		out = append(out, Info{Path: filepath.ToSlash(rel), Size: info.Size(), ModifiedAt: info.ModTime().UTC()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// RunSize returns the total size of a run's artifacts.
+func (s *Store) RunSize(runID string) (int64, error) {
+	items, err := s.List(runID)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, it := range items {
+		total += it.Size
+	}
+	return total, nil
+}
+
+// Purge deletes every run whose newest artifact is older than cutoff and
+// returns the purged run IDs.
+func (s *Store) Purge(cutoff time.Time) ([]string, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var purged []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		runID := entry.Name()
+		unlock := s.lockRun(runID)
+		items, err := s.List(runID)
+		if err == nil && newestModTime(items).Before(cutoff) {
+			if err = os.RemoveAll(filepath.Join(s.root, runID)); err == nil {
+				purged = append(purged, runID)
+			}
+		}
+		unlock()
+		if err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+// PurgeLoop runs Purge every interval, removing runs older than retention,
+// until ctx is canceled. onPurge (optional) is called with each non-empty
+// batch of purged run IDs.
+func (s *Store) PurgeLoop(ctx context.Context, retention, interval time.Duration, onPurge func(runIDs []string, err error)) {
+	if retention <= 0 || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.Purge(time.Now().Add(-retention))
+			if onPurge != nil && (len(purged) > 0 || err != nil) {
+				onPurge(purged, err)
+			}
+		}
+	}
+}
+
+func newestModTime(items []Info) time.Time {
+	var newest time.Time
+	for _, it := range items {
+		if it.ModifiedAt.After(newest) {
+			newest = it.ModifiedAt
+		}
+	}
+	return newest
+}
+
+func isTempArtifact(name string) bool {
+	return strings.Contains(name, tempArtifactMarker)
+}
@@ -0,0 +1,106 @@
+package artifacts
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreWriteListOpen(t *testing.T) {
+	store := NewStore(t.TempDir(), 1024)
+
+	if _, err := store.Write("run-1", "reports/summary.md", strings.NewReader("# ok")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := store.Write("run-1", "config.yaml", strings.NewReader("a: 1")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	items, err := store.List("run-1")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 2 || items[0].Path != "config.yaml" || items[1].Path != "reports/summary.md" || items[1].Size != 4 {
+		t.Fatalf("unexpected listing %+v", items)
+	}
+
+	f, _, err := store.Open("run-1", "reports/summary.md")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "# ok" {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	if _, _, err := store.Open("run-1", "missing.txt"); !errors.Is(err, ErrArtifactNotFound) {
+		t.Fatalf("expected ErrArtifactNotFound, got %v", err)
+	}
+	if _, err := store.Write("run-1", "../escape", strings.NewReader("x")); !errors.Is(err, ErrPathInvalid) {
+		t.Fatalf("expected ErrPathInvalid, got %v", err)
+	}
+	if _, err := store.Write("../run", "a.txt", strings.NewReader("x")); !errors.Is(err, ErrRunIDInvalid) {
+		t.Fatalf("expected ErrRunIDInvalid, got %v", err)
+	}
+	if items, err := store.List("run-none"); err != nil || len(items) != 0 {
+		t.Fatalf("expected empty listing for unknown run, got %+v, %v", items, err)
+	}
+}
+
+func TestStoreEnforcesRunQuota(t *testing.T) {
+	store := NewStore(t.TempDir(), 10)
+
+	if _, err := store.Write("run-1", "a.txt", strings.NewReader("123456")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := store.Write("run-1", "b.txt", strings.NewReader("12345")); !errors.Is(err, ErrRunQuotaExceeded) {
+		t.Fatalf("expected ErrRunQuotaExceeded, got %v", err)
+	}
+	// Replacing a file only counts the new size.
+	if _, err := store.Write("run-1", "a.txt", strings.NewReader("1234567890")); err != nil {
+		t.Fatalf("replace within quota: %v", err)
+	}
+	// Other runs have their own budget.
+	if _, err := store.Write("run-2", "b.txt", strings.NewReader("12345")); err != nil {
+		t.Fatalf("write other run: %v", err)
+	}
+
+	items, _ := store.List("run-1")
+	if len(items) != 1 || items[0].Size != 10 {
+		t.Fatalf("rejected upload must not leave files behind: %+v", items)
+	}
+}
+
+func TestStorePurge(t *testing.T) {
+	root := t.TempDir()
+	store := NewStore(root, 0)
+	if _, err := store.Write("old-run", "a.txt", strings.NewReader("old")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := store.Write("new-run", "a.txt", strings.NewReader("new")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "old-run", "a.txt"), old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	purged, err := store.Purge(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if len(purged) != 1 || purged[0] != "old-run" {
+		t.Fatalf("unexpected purge result %v", purged)
+	}
+	if _, err := os.Stat(filepath.Join(root, "old-run")); !os.IsNotExist(err) {
+		t.Fatalf("expected old run directory removed, got %v", err)
+	}
+	if items, _ := store.List("new-run"); len(items) != 1 {
+		t.Fatalf("expected new run kept, got %+v", items)
+	}
+}
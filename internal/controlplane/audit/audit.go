@@ -19,13 +19,31 @@ const (
 	EventCommandSent                   EventType = "command.sent"
 	EventCommandResult                 EventType = "command.result"
 	EventPolicyChanged                 EventType = "policy.changed"
+	EventPolicyTagRuleChanged          EventType = "policy.tag_rule_changed"
 	EventApprovalRequest               EventType = "approval.requested"
 	EventApprovalDecided               EventType = "approval.decided"
+	EventApprovalAutoApproved          EventType = "approval.auto_approved"
+	EventApprovalRuleChanged           EventType = "approval.rule_changed"
+	EventRiskRuleChanged               EventType = "approval.risk_rule_changed"
+	EventApprovalEscalated             EventType = "approval.escalated"
+	EventCommandTemplateChanged        EventType = "command.template_changed"
+	EventLocalScheduleRun              EventType = "command.local_run"
+	EventLocalScheduleChanged          EventType = "probe.local_schedule_changed"
 	EventTokenGenerated                EventType = "token.generated"
 	EventInventoryUpdate               EventType = "inventory.updated"
 	EventFederationRead                EventType = "federation.read"
 	EventProbeKeyRotated               EventType = "probe.key_rotated"
 	EventProbeDeregistered             EventType = "probe.deregistered"
+	EventProbeDrained                  EventType = "probe.drained"
+	EventProbeUndrained                EventType = "probe.undrained"
+	EventProbeAnnotated                EventType = "probe.annotated"
+	EventProbeHostKeyTrusted           EventType = "probe.host_key_trusted"
+	EventProbeHostKeyRejected          EventType = "probe.host_key_rejected"
+	EventProbeAlert                    EventType = "probe.alert"
+	EventProbeRolloutStarted           EventType = "probe.rollout_started"
+	EventProbeRolloutCanaryPassed      EventType = "probe.rollout_canary_passed"
+	EventProbeRolloutCompleted         EventType = "probe.rollout_completed"
+	EventProbeRolloutFailed            EventType = "probe.rollout_failed"
 	EventProbeCertificateAuthSucceeded EventType = "probe.certificate_auth_succeeded"
 	EventProbeCertificateAuthFailed    EventType = "probe.certificate_auth_failed"
 	EventProbeCertificateError         EventType = "probe.certificate_error"
@@ -44,6 +62,11 @@ const (
 	EventJobRunFailed                  EventType = "job.run.failed"
 	EventJobRunCanceled                EventType = "job.run.canceled"
 	EventJobRunDenied                  EventType = "job.run.denied"
+	EventJobRunBlocked                 EventType = "job.run.blocked"
+	EventJobRunUnblocked               EventType = "job.run.unblocked"
+	EventJobRunDependencyTimeout       EventType = "job.run.dependency_timeout"
+	EventJobRunTimedOut                EventType = "job.run.timed_out"
+	EventJobRunSkipped                 EventType = "job.run.skipped"
 	EventRunnerCreated                 EventType = "runner.created"
 	EventRunnerStarted                 EventType = "runner.started"
 	EventRunnerStopped                 EventType = "runner.stopped"
@@ -54,6 +77,7 @@ const (
 	EventRunnerArtifactDownloaded      EventType = "runner.artifact_downloaded"
 	EventRunnerArtifactAccessDenied    EventType = "runner.artifact_access_denied"
 	EventRunnerProviderProxy           EventType = "runner.provider_proxy"
+	EventRunnerProviderBudget          EventType = "runner.provider_budget_threshold"
 	EventRunnerTeardown                EventType = "runner.teardown"
 	EventRunnerError                   EventType = "runner.error"
 	EventBreakglassCommand             EventType = "breakglass.command"
@@ -79,11 +103,18 @@ type Event struct {
 	EntryHash   string    `json:"entry_hash,omitempty"`
 }
 
+// Sink receives a copy of every recorded event (for example to forward it to
+// a SIEM). Forward is called on the recording goroutine and must not block.
+type Sink interface {
+	Forward(evt Event)
+}
+
 // Log is an append-only audit log.
 type Log struct {
 	events []Event
 	mu     sync.RWMutex
-	maxLen int // ring buffer size (0 = unbounded)
+	maxLen int  // ring buffer size (0 = unbounded)
+	sink   Sink // optional forwarder
 }
 
 // NewLog creates a new audit log. maxLen=0 means unbounded.
@@ -104,14 +135,25 @@ func (l *Log) Record(evt Event) {
 	}
 
 	l.mu.Lock()
-	defer l.mu.Unlock()
-
 	l.events = append(l.events, evt)
 
 	// Ring buffer: drop oldest if over capacity
 	if l.maxLen > 0 && len(l.events) > l.maxLen {
 		l.events = l.events[len(l.events)-l.maxLen:]
 	}
+	sink := l.sink
+	l.mu.Unlock()
+
+	if sink != nil {
+		sink.Forward(evt)
+	}
+}
+
+// SetSink installs a forwarder that receives every subsequently recorded event.
+func (l *Log) SetSink(sink Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sink = sink
 }
 
 // Emit is a convenience for recording a new event with minimal args.
@@ -131,6 +173,7 @@ type Filter struct {
 	Since       time.Time
 	Until       time.Time
 	WorkspaceID string
+	Query       string // free-text search over summary and detail
 	Cursor      string
 	Limit       int
 }
@@ -141,6 +184,7 @@ func (l *Log) Query(f Filter) []Event {
 	defer l.mu.RUnlock()
 
 	var result []Event
+	terms := searchTerms(f.Query)
 
 	// Walk backwards (newest first)
 	for i := len(l.events) - 1; i >= 0; i-- {
@@ -161,6 +205,9 @@ func (l *Log) Query(f Filter) []Event {
 		if !f.Until.IsZero() && evt.Timestamp.After(f.Until) {
 			continue
 		}
+		if !matchesText(evt, terms) {
+			continue
+		}
 
 		result = append(result, evt)
 
@@ -200,9 +247,18 @@ const (
 	EventMCPSandboxDenied EventType = "mcp.sandbox_denied"
 )
 
-// Login audit event types.
+// Authentication audit event types. Every authentication outcome (login,
+// API key, probe handshake) is recorded as EventAuthnSuccess or
+// EventAuthnFailure, with the method in the event detail.
 const (
+	EventAuthnSuccess EventType = "auth.authn_success"
+	EventAuthnFailure EventType = "auth.authn_failure"
+	// EventLoginSuccess and EventLoginFailed were recorded for logins before
+	// the authn events; they remain so older entries can still be queried.
 	EventLoginSuccess        EventType = "auth.login"
 	EventLoginFailed         EventType = "auth.login_failed"
 	EventAuthorizationDenied EventType = "auth.authorization_denied"
+	EventAPIKeyRateLimited   EventType = "auth.rate_limited"
+	EventLoginLockout        EventType = "auth.lockout"
+	EventLoginLockoutCleared EventType = "auth.lockout_cleared"
 )
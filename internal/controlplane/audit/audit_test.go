@@ -88,3 +88,23 @@ func TestBeforeAfterState(t *testing.T) {
 		t.Error("before/after state should be preserved")
 	}
 }
+
+func TestQueryText(t *testing.T) {
+	log := NewLog(0)
+	log.Record(Event{Type: EventCommandSent, ProbeID: "prb-001", Summary: "Command dispatched: systemctl restart nginx"})
+	log.Record(Event{Type: EventCommandSent, ProbeID: "prb-002", Summary: "Command dispatched", Detail: map[string]any{"command": "df -h", "request_id": "req-42"}})
+	log.Record(Event{Type: EventPolicyChanged, ProbeID: "prb-001", Summary: "observe → diagnose"})
+
+	if events := log.Query(Filter{Query: "NGINX"}); len(events) != 1 || events[0].ProbeID != "prb-001" {
+		t.Errorf("expected case-insensitive summary match, got %+v", events)
+	}
+	if events := log.Query(Filter{Query: "req-42"}); len(events) != 1 || events[0].ProbeID != "prb-002" {
+		t.Errorf("expected detail match, got %+v", events)
+	}
+	if events := log.Query(Filter{Query: "dispatched df"}); len(events) != 1 {
+		t.Errorf("expected all terms to be required, got %d events", len(events))
+	}
+	if events := log.Query(Filter{Query: "dispatched", ProbeID: "prb-001"}); len(events) != 1 {
+		t.Errorf("expected text search combined with probe filter, got %d events", len(events))
+	}
+}
@@ -0,0 +1,172 @@
+package audit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CEFSignature describes how an audit event type is presented to a SIEM in
+// ArcSight Common Event Format.
+type CEFSignature struct {
+	ID       string // Device Event Class ID
+	Name     string
+	Severity int // 0 (lowest) – 10 (highest)
+}
+
+// cefSignatures maps every audit event type to a stable CEF signature ID.
+// IDs are grouped by domain and must never be renumbered once shipped:
+//
+//	1xx probe lifecycle    2xx commands       3xx policy
+//	4xx approvals          5xx auth/tokens    6xx inventory/federation
+//	7xx jobs               8xx runners        9xx sandbox/breakglass
+//	10xx notifications/audit
+var cefSignatures = map[EventType]CEFSignature{
+	EventProbeRegistered:               {ID: "100", Name: "Probe registered", Severity: 3},
+	EventProbeOffline:                  {ID: "101", Name: "Probe offline", Severity: 5},
+	EventProbeKeyRotated:               {ID: "102", Name: "Probe key rotated", Severity: 5},
+	EventProbeDeregistered:             {ID: "103", Name: "Probe deregistered", Severity: 5},
+	EventProbeDrained:                  {ID: "104", Name: "Probe drained", Severity: 4},
+	EventProbeUndrained:                {ID: "105", Name: "Probe undrained", Severity: 3},
+	EventProbeAnnotated:                {ID: "106", Name: "Probe annotations changed", Severity: 2},
+	EventProbeHostKeyTrusted:           {ID: "107", Name: "Remote probe host key trusted on first use", Severity: 4},
+	EventProbeHostKeyRejected:          {ID: "108", Name: "Remote probe host key rejected", Severity: 8},
+	EventProbeAlert:                    {ID: "109", Name: "Probe pushed alert", Severity: 5},
+	EventProbeRolloutStarted:           {ID: "120", Name: "Probe update rollout started", Severity: 5},
+	EventProbeRolloutCanaryPassed:      {ID: "121", Name: "Probe update rollout canary passed", Severity: 4},
+	EventProbeRolloutCompleted:         {ID: "122", Name: "Probe update rollout completed", Severity: 4},
+	EventProbeRolloutFailed:            {ID: "123", Name: "Probe update rollout failed", Severity: 7},
+	EventProbeCertificateAuthSucceeded: {ID: "110", Name: "Probe certificate auth succeeded", Severity: 2},
+	EventProbeCertificateAuthFailed:    {ID: "111", Name: "Probe certificate auth failed", Severity: 7},
+	EventProbeCertificateError:         {ID: "112", Name: "Probe certificate error", Severity: 6},
+	EventProbeCertificateIssued:        {ID: "113", Name: "Probe certificate issued", Severity: 4},
+	EventProbeCertificateRegistered:    {ID: "114", Name: "Probe certificate registered", Severity: 4},
+	EventLocalScheduleChanged:          {ID: "115", Name: "Probe local schedule changed", Severity: 5},
+
+	EventCommandSent:            {ID: "200", Name: "Command sent", Severity: 4},
+	EventCommandResult:          {ID: "201", Name: "Command result", Severity: 3},
+	EventLocalScheduleRun:       {ID: "202", Name: "Local scheduled command run", Severity: 3},
+	EventCommandTemplateChanged: {ID: "210", Name: "Command template changed", Severity: 5},
+
+	EventPolicyChanged:        {ID: "300", Name: "Policy changed", Severity: 6},
+	EventPolicyTagRuleChanged: {ID: "301", Name: "Policy tag rule changed", Severity: 6},
+
+	EventApprovalRequest:      {ID: "400", Name: "Approval requested", Severity: 4},
+	EventApprovalDecided:      {ID: "401", Name: "Approval decided", Severity: 5},
+	EventApprovalAutoApproved: {ID: "402", Name: "Approval auto-approved by rule", Severity: 5},
+	EventApprovalRuleChanged:  {ID: "403", Name: "Auto-approve rule changed", Severity: 6},
+	EventApprovalEscalated:    {ID: "404", Name: "Approval escalated", Severity: 6},
+	EventRiskRuleChanged:      {ID: "405", Name: "Risk rule changed", Severity: 6},
+
+	EventTokenGenerated:      {ID: "500", Name: "Token generated", Severity: 5},
+	EventLoginSuccess:        {ID: "510", Name: "Login succeeded", Severity: 3},
+	EventLoginFailed:         {ID: "511", Name: "Login failed", Severity: 7},
+	EventAuthorizationDenied: {ID: "512", Name: "Authorization denied", Severity: 7},
+	EventAPIKeyRateLimited:   {ID: "513", Name: "API key rate limited", Severity: 5},
+	EventAuthnSuccess:        {ID: "514", Name: "Authentication succeeded", Severity: 3},
+	EventAuthnFailure:        {ID: "515", Name: "Authentication failed", Severity: 7},
+	EventLoginLockout:        {ID: "516", Name: "Login locked out", Severity: 8},
+	EventLoginLockoutCleared: {ID: "517", Name: "Login lockout cleared", Severity: 5},
+
+	EventInventoryUpdate: {ID: "600", Name: "Inventory updated", Severity: 1},
+	EventFederationRead:  {ID: "610", Name: "Federation read", Severity: 2},
+
+	EventJobCreated:              {ID: "700", Name: "Job created", Severity: 4},
+	EventJobUpdated:              {ID: "701", Name: "Job updated", Severity: 4},
+	EventJobDeleted:              {ID: "702", Name: "Job deleted", Severity: 5},
+	EventJobRunAdmissionAllowed:  {ID: "710", Name: "Job run admission allowed", Severity: 2},
+	EventJobRunAdmissionQueued:   {ID: "711", Name: "Job run admission queued", Severity: 2},
+	EventJobRunAdmissionDenied:   {ID: "712", Name: "Job run admission denied", Severity: 5},
+	EventJobRunQueued:            {ID: "720", Name: "Job run queued", Severity: 2},
+	EventJobRunStarted:           {ID: "721", Name: "Job run started", Severity: 3},
+	EventJobRunRetryScheduled:    {ID: "722", Name: "Job run retry scheduled", Severity: 4},
+	EventJobRunSucceeded:         {ID: "723", Name: "Job run succeeded", Severity: 2},
+	EventJobRunFailed:            {ID: "724", Name: "Job run failed", Severity: 6},
+	EventJobRunCanceled:          {ID: "725", Name: "Job run canceled", Severity: 4},
+	EventJobRunDenied:            {ID: "726", Name: "Job run denied", Severity: 6},
+	EventJobRunBlocked:           {ID: "727", Name: "Job run blocked on dependencies", Severity: 3},
+	EventJobRunUnblocked:         {ID: "728", Name: "Job run unblocked", Severity: 2},
+	EventJobRunDependencyTimeout: {ID: "729", Name: "Job run dependency timeout", Severity: 5},
+	EventJobRunTimedOut:          {ID: "730", Name: "Job run timed out", Severity: 6},
+	EventJobRunSkipped:           {ID: "731", Name: "Job run skipped while previous run active", Severity: 4},
+
+	EventRunnerCreated:              {ID: "800", Name: "Runner created", Severity: 3},
+	EventRunnerStarted:              {ID: "801", Name: "Runner started", Severity: 3},
+	EventRunnerStopped:              {ID: "802", Name: "Runner stopped", Severity: 3},
+	EventRunnerDestroyed:            {ID: "803", Name: "Runner destroyed", Severity: 3},
+	EventRunnerRunTokenIssued:       {ID: "810", Name: "Runner run token issued", Severity: 4},
+	EventRunnerArtifactURLIssued:    {ID: "820", Name: "Runner artifact URL issued", Severity: 3},
+	EventRunnerArtifactUploaded:     {ID: "821", Name: "Runner artifact uploaded", Severity: 3},
+	EventRunnerArtifactDownloaded:   {ID: "822", Name: "Runner artifact downloaded", Severity: 3},
+	EventRunnerArtifactAccessDenied: {ID: "823", Name: "Runner artifact access denied", Severity: 7},
+	EventRunnerProviderProxy:        {ID: "830", Name: "Runner provider proxy call", Severity: 3},
+	EventRunnerProviderBudget:       {ID: "831", Name: "Runner provider budget threshold", Severity: 6},
+	EventRunnerTeardown:             {ID: "840", Name: "Runner teardown", Severity: 3},
+	EventRunnerError:                {ID: "841", Name: "Runner error", Severity: 6},
+
+	EventSandboxCreated:    {ID: "900", Name: "Sandbox created", Severity: 3},
+	EventSandboxDestroyed:  {ID: "901", Name: "Sandbox destroyed", Severity: 3},
+	EventSandboxTaskRun:    {ID: "902", Name: "Sandbox task run", Severity: 4},
+	EventSandboxArtifact:   {ID: "903", Name: "Sandbox artifact read", Severity: 3},
+	EventMCPSandboxDenied:  {ID: "910", Name: "MCP sandbox denied", Severity: 7},
+	EventBreakglassCommand: {ID: "950", Name: "Breakglass command", Severity: 9},
+
+	EventNotificationDeliverySucceeded: {ID: "1000", Name: "Notification delivered", Severity: 1},
+	EventNotificationDeliveryFailed:    {ID: "1001", Name: "Notification delivery failed", Severity: 5},
+	EventNotificationTestSent:          {ID: "1002", Name: "Notification test sent", Severity: 2},
+	EventAuditEvidenceBundleExport:     {ID: "1010", Name: "Audit evidence bundle export", Severity: 5},
+}
+
+// CEFSignatureFor returns the CEF signature for an event type. Unmapped types
+// get signature ID "0" so new events are still forwarded.
+func CEFSignatureFor(t EventType) CEFSignature {
+	if sig, ok := cefSignatures[t]; ok {
+		return sig
+	}
+	return CEFSignature{ID: "0", Name: string(t), Severity: 3}
+}
+
+// FormatCEF renders an audit event as a single-line CEF record.
+func FormatCEF(evt Event, productVersion string) string {
+	sig := CEFSignatureFor(evt.Type)
+	if productVersion == "" {
+		productVersion = "dev"
+	}
+
+	ext := []string{
+		"rt=" + strconv.FormatInt(evt.Timestamp.UnixMilli(), 10),
+		"externalId=" + cefEscapeExt(evt.ID),
+		"act=" + cefEscapeExt(string(evt.Type)),
+	}
+	if evt.Actor != "" {
+		ext = append(ext, "suser="+cefEscapeExt(evt.Actor))
+	}
+	if evt.ProbeID != "" {
+		ext = append(ext, "dhost="+cefEscapeExt(evt.ProbeID))
+	}
+	if evt.WorkspaceID != "" {
+		ext = append(ext, "cs1Label=workspace", "cs1="+cefEscapeExt(evt.WorkspaceID))
+	}
+	if evt.EntryHash != "" {
+		ext = append(ext, "cs2Label=entryHash", "cs2="+cefEscapeExt(evt.EntryHash))
+	}
+	if evt.Summary != "" {
+		ext = append(ext, "msg="+cefEscapeExt(evt.Summary))
+	}
+
+	return fmt.Sprintf("CEF:0|Legator|Control Plane|%s|%s|%s|%d|%s",
+		cefEscapeHeader(productVersion),
+		cefEscapeHeader(sig.ID),
+		cefEscapeHeader(sig.Name),
+		sig.Severity,
+		strings.Join(ext, " "),
+	)
+}
+
+var (
+	cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtEscaper    = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+func cefEscapeHeader(v string) string { return cefHeaderEscaper.Replace(v) }
+func cefEscapeExt(v string) string    { return cefExtEscaper.Replace(v) }
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+)
+
+// searchTerms splits a free-text query into lower-cased terms. An event
+// matches when every term is found in its summary or serialized detail.
+func searchTerms(q string) []string {
+	return strings.Fields(strings.ToLower(q))
+}
+
+// matchesText is the in-memory fallback for Filter.Query: a case-insensitive
+// substring scan, the equivalent of a LIKE over summary and detail.
+func matchesText(evt Event, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	text := strings.ToLower(evt.Summary)
+	if evt.Detail != nil {
+		if detail, err := json.Marshal(evt.Detail); err == nil {
+			text += "\n" + strings.ToLower(string(detail))
+		}
+	}
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// ftsMatchExpr turns terms into an FTS5 query: each term is quoted, so
+// operators and punctuation in user input are matched literally, and
+// prefix-matched. Terms are implicitly ANDed.
+func ftsMatchExpr(terms []string) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		parts = append(parts, `"`+strings.ReplaceAll(term, `"`, `""`)+`"*`)
+	}
+	return strings.Join(parts, " ")
+}
+
+// likePattern escapes LIKE wildcards in term for use with ESCAPE '\'.
+func likePattern(term string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(term) + "%"
+}
+
+// ensureSearchIndex creates the FTS5 index over summary and detail and
+// backfills it the first time. It reports false when the SQLite build has no
+// FTS5, in which case text search falls back to LIKE.
+func ensureSearchIndex(db *sql.DB) bool {
+	var existing string
+	err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'audit_events_fts'`).Scan(&existing)
+	if err == nil {
+		return true
+	}
+	if _, err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS audit_events_fts USING fts5(event_id UNINDEXED, summary, detail)`); err != nil {
+		return false
+	}
+	if _, err := db.Exec(`INSERT INTO audit_events_fts (event_id, summary, detail)
+		SELECT id, COALESCE(summary, ''), COALESCE(NULLIF(detail, 'null'), '') FROM audit_events`); err != nil {
+		_, _ = db.Exec(`DROP TABLE IF EXISTS audit_events_fts`)
+		return false
+	}
+	return true
+}
@@ -42,6 +42,12 @@ type Store struct {
 	chainMode     bool
 	chainKey      []byte
 	lastEntryHash string
+
+	fts bool // audit_events_fts is available for text search
+
+	writes *migration.WriteHealth
+
+	sink Sink // optional forwarder, guarded by mu
 }
 
 func migrateAuditStore(db *sql.DB) error {
@@ -135,6 +141,8 @@ func NewStoreWithOptions(dbPath string, memoryLimit int, opts StoreOptions) (*St
 		log:         NewLog(memoryLimit),
 		memoryLimit: memoryLimit,
 		chainMode:   opts.ChainMode,
+		fts:         ensureSearchIndex(db),
+		writes:      migration.NewWriteHealth("audit"),
 	}
 
 	if s.chainMode {
@@ -215,7 +223,20 @@ func (s *Store) Record(evt Event) {
 
 	s.mu.RLock()
 	s.log.Record(evt)
+	sink := s.sink
 	s.mu.RUnlock()
+
+	if sink != nil {
+		sink.Forward(evt)
+	}
+}
+
+// SetSink installs a forwarder that receives every subsequently recorded event,
+// including chain hashes when chain mode is enabled.
+func (s *Store) SetSink(sink Sink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sink = sink
 }
 
 // Emit is a convenience for recording a new event with minimal args.
@@ -228,8 +249,15 @@ func (s *Store) Emit(typ EventType, probeID, actor, summary string) {
 	})
 }
 
-// Query delegates to the in-memory cache for fast reads.
+// Query delegates to the in-memory cache for fast reads. Text searches go to
+// the persisted index so they cover the full history, falling back to the
+// cache if that fails.
 func (s *Store) Query(f Filter) []Event {
+	if strings.TrimSpace(f.Query) != "" {
+		if events, err := s.QueryPersisted(f); err == nil {
+			return events
+		}
+	}
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.log.Query(f)
@@ -449,6 +477,11 @@ func (s *Store) Purge(olderThan time.Duration) (int64, error) {
 	}
 
 	cutoff := time.Now().UTC().Add(-olderThan).Format(time.RFC3339Nano)
+	if s.fts {
+		if _, err := s.db.Exec("DELETE FROM audit_events_fts WHERE event_id IN (SELECT id FROM audit_events WHERE timestamp < ?)", cutoff); err != nil {
+			return 0, err
+		}
+	}
 	res, err := s.db.Exec("DELETE FROM audit_events WHERE timestamp < ?", cutoff)
 	if err != nil {
 		return 0, err
@@ -503,12 +536,18 @@ func (s *Store) Close() error {
 	return s.db.Close()
 }
 
+// WriteStatus reports whether recent writes to audit.db succeeded. Events
+// whose write failed are still held in memory but are lost on restart.
+func (s *Store) WriteStatus() migration.WriteStatus {
+	return s.writes.Status()
+}
+
 func (s *Store) persist(evt Event) (bool, error) {
 	detail, _ := json.Marshal(evt.Detail)
 	before, _ := json.Marshal(evt.Before)
 	after, _ := json.Marshal(evt.After)
 
-	res, err := s.db.Exec(`INSERT OR IGNORE INTO audit_events (
+	res, err := s.writes.Exec(s.db, `INSERT OR IGNORE INTO audit_events (
 		id, timestamp, type, probe_id, workspace_id, actor, summary, detail, before_val, after_val, prev_hash, entry_hash
 	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		evt.ID,
@@ -531,6 +570,13 @@ func (s *Store) persist(evt Event) (bool, error) {
 	if err != nil {
 		return false, nil
 	}
+	if rows > 0 && s.fts {
+		indexed := string(detail)
+		if indexed == "null" {
+			indexed = ""
+		}
+		_, _ = s.writes.Exec(s.db, `INSERT INTO audit_events_fts (event_id, summary, detail) VALUES (?, ?, ?)`, evt.ID, evt.Summary, indexed)
+	}
 	return rows > 0, nil
 }
 
@@ -595,6 +641,18 @@ func (s *Store) buildPersistedQuery(f Filter, includeLimit bool, csvMode bool) (
 		query += " AND timestamp <= ?"
 		args = append(args, f.Until.UTC().Format(time.RFC3339Nano))
 	}
+	if terms := searchTerms(f.Query); len(terms) > 0 {
+		if s.fts {
+			query += " AND id IN (SELECT event_id FROM audit_events_fts WHERE audit_events_fts MATCH ?)"
+			args = append(args, ftsMatchExpr(terms))
+		} else {
+			for _, term := range terms {
+				pattern := likePattern(term)
+				query += ` AND (LOWER(summary) LIKE ? ESCAPE '\' OR LOWER(detail) LIKE ? ESCAPE '\')`
+				args = append(args, pattern, pattern)
+			}
+		}
+	}
 	if f.Cursor != "" {
 		var cursorTS string
 		err := s.db.QueryRow("SELECT timestamp FROM audit_events WHERE id = ?", f.Cursor).Scan(&cursorTS)
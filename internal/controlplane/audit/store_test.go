@@ -358,3 +358,66 @@ func TestStoreChainModeDisabledKeepsHashesEmpty(t *testing.T) {
 		t.Fatalf("expected empty hashes when chain mode disabled, got prev=%q entry=%q", events[0].PrevHash, events[0].EntryHash)
 	}
 }
+
+func TestStoreQueryText(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "audit.db")
+	store, err := NewStore(dbPath, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	store.Record(Event{ID: "evt-1", Timestamp: now.Add(-3 * time.Hour), Type: EventCommandSent, Summary: "Command dispatched: systemctl restart nginx"})
+	store.Record(Event{ID: "evt-2", Timestamp: now.Add(-2 * time.Hour), Type: EventCommandSent, Summary: "Command dispatched", Detail: map[string]any{"command": "df -h", "request_id": "req-42"}})
+	store.Record(Event{ID: "evt-3", Timestamp: now.Add(-1 * time.Hour), Type: EventPolicyChanged, Summary: "observe → diagnose"})
+
+	// The memory cache only holds evt-3, so matches must come from the index.
+	if events := store.Query(Filter{Query: "nginx"}); len(events) != 1 || events[0].ID != "evt-1" {
+		t.Fatalf("expected summary match from persisted index, got %+v", events)
+	}
+	if events := store.Query(Filter{Query: "req-42"}); len(events) != 1 || events[0].ID != "evt-2" {
+		t.Fatalf("expected detail match, got %+v", events)
+	}
+	if events := store.Query(Filter{Query: `dispatch "OR`}); len(events) != 0 {
+		t.Fatalf("expected quoted operators to match literally, got %+v", events)
+	}
+
+	var buf strings.Builder
+	if err := store.StreamCSV(context.Background(), &buf, Filter{Query: "dispatched"}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(strings.TrimSpace(buf.String()), "\n"); lines != 2 {
+		t.Fatalf("expected header plus 2 rows in filtered export, got:\n%s", buf.String())
+	}
+
+	if _, err := store.Purge(150 * time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if events := store.Query(Filter{Query: "nginx"}); len(events) != 0 {
+		t.Fatalf("expected purged event gone from search, got %+v", events)
+	}
+	store.Close()
+
+	// Events written before the index existed are backfilled on open.
+	store2, err := NewStore(dbPath, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store2.Close()
+	if _, err := store2.db.Exec(`DROP TABLE audit_events_fts`); err != nil {
+		t.Fatal(err)
+	}
+	if !ensureSearchIndex(store2.db) {
+		t.Fatal("expected FTS5 index to be created")
+	}
+	if events, err := store2.QueryPersisted(Filter{Query: "df"}); err != nil || len(events) != 1 {
+		t.Fatalf("expected backfilled match, got %+v, %v", events, err)
+	}
+
+	// Without FTS5 the same search runs as a LIKE scan.
+	store2.fts = false
+	if events, err := store2.QueryPersisted(Filter{Query: "REQ-42"}); err != nil || len(events) != 1 {
+		t.Fatalf("expected LIKE fallback match, got %+v, %v", events, err)
+	}
+}
@@ -0,0 +1,257 @@
+package audit
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSyslogBufferSize = 1024
+	defaultSyslogAppName    = "legator"
+
+	syslogDialTimeout    = 5 * time.Second
+	syslogWriteTimeout   = 5 * time.Second
+	syslogInitialBackoff = time.Second
+	syslogMaxBackoff     = 30 * time.Second
+
+	// syslogFacilityLogAudit is RFC 5424 facility 13 ("log audit").
+	syslogFacilityLogAudit = 13
+)
+
+// SyslogConfig configures a SyslogForwarder.
+type SyslogConfig struct {
+	Address    string      // host:port of the syslog collector
+	Protocol   string      // "tcp" (default) or "tls"
+	TLSConfig  *tls.Config // used when Protocol is "tls"
+	BufferSize int         // events held while the collector is unreachable
+	AppName    string      // RFC 5424 APP-NAME
+	Hostname   string      // RFC 5424 HOSTNAME; defaults to os.Hostname()
+	Version    string      // product version reported in the CEF header
+}
+
+// SyslogForwarder streams audit events to a syslog collector as CEF records
+// framed per RFC 5424 / RFC 6587 octet counting. Events are queued in a
+// bounded buffer; when the buffer is full (collector down for too long) new
+// events are dropped and counted rather than blocking the recorder.
+type SyslogForwarder struct {
+	cfg    SyslogConfig
+	dial   func() (net.Conn, error)
+	queue  chan Event
+	logger *zap.Logger
+
+	dropped   atomic.Uint64
+	forwarded atomic.Uint64
+
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewSyslogForwarder validates cfg and starts the background delivery loop.
+func NewSyslogForwarder(cfg SyslogConfig, logger *zap.Logger) (*SyslogForwarder, error) {
+	cfg.Address = strings.TrimSpace(cfg.Address)
+	if cfg.Address == "" {
+		return nil, errors.New("syslog address is required")
+	}
+	if _, _, err := net.SplitHostPort(cfg.Address); err != nil {
+		return nil, fmt.Errorf("invalid syslog address %q: %w", cfg.Address, err)
+	}
+	cfg.Protocol = strings.ToLower(strings.TrimSpace(cfg.Protocol))
+	if cfg.Protocol == "" {
+		cfg.Protocol = "tcp"
+	}
+	if cfg.Protocol != "tcp" && cfg.Protocol != "tls" {
+		return nil, fmt.Errorf("unsupported syslog protocol %q (want tcp or tls)", cfg.Protocol)
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultSyslogBufferSize
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = defaultSyslogAppName
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	f := &SyslogForwarder{
+		cfg:     cfg,
+		queue:   make(chan Event, cfg.BufferSize),
+		logger:  logger,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	f.dial = f.dialCollector
+	go f.run()
+	return f, nil
+}
+
+// Forward queues an event for delivery. It never blocks; if the buffer is
+// full the event is dropped and counted.
+func (f *SyslogForwarder) Forward(evt Event) {
+	select {
+	case <-f.done:
+		f.dropped.Add(1)
+		return
+	default:
+	}
+	select {
+	case f.queue <- evt:
+	default:
+		f.dropped.Add(1)
+	}
+}
+
+// DroppedCount returns the number of events dropped because the buffer was full.
+func (f *SyslogForwarder) DroppedCount() uint64 {
+	return f.dropped.Load()
+}
+
+// ForwardedCount returns the number of events written to the collector.
+func (f *SyslogForwarder) ForwardedCount() uint64 {
+	return f.forwarded.Load()
+}
+
+// Close stops the delivery loop. Events still buffered are discarded.
+func (f *SyslogForwarder) Close() {
+	f.closeOnce.Do(func() {
+		close(f.done)
+	})
+	<-f.stopped
+}
+
+func (f *SyslogForwarder) dialCollector() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: syslogDialTimeout}
+	if f.cfg.Protocol == "tls" {
+		tlsCfg := f.cfg.TLSConfig
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		return tls.DialWithDialer(dialer, "tcp", f.cfg.Address, tlsCfg)
+	}
+	return dialer.Dial("tcp", f.cfg.Address)
+}
+
+func (f *SyslogForwarder) run() {
+	defer close(f.stopped)
+
+	var (
+		conn    net.Conn
+		pending *Event
+		backoff = syslogInitialBackoff
+	)
+	defer func() {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}()
+
+	for {
+		if pending == nil {
+			select {
+			case <-f.done:
+				return
+			case evt := <-f.queue:
+				pending = &evt
+			}
+		}
+
+		if conn == nil {
+			c, err := f.dial()
+			if err != nil {
+				f.logger.Warn("audit syslog collector unreachable, retrying",
+					zap.String("address", f.cfg.Address),
+					zap.Duration("backoff", backoff),
+					zap.Error(err),
+				)
+				select {
+				case <-f.done:
+					return
+				case <-time.After(backoff):
+				}
+				backoff = min(backoff*2, syslogMaxBackoff)
+				continue
+			}
+			conn = c
+			backoff = syslogInitialBackoff
+		}
+
+		_ = conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+		if _, err := conn.Write(f.frame(*pending)); err != nil {
+			f.logger.Warn("audit syslog write failed, reconnecting",
+				zap.String("address", f.cfg.Address),
+				zap.Error(err),
+			)
+			_ = conn.Close()
+			conn = nil
+			continue
+		}
+		f.forwarded.Add(1)
+		pending = nil
+	}
+}
+
+// frame renders evt as an octet-counted RFC 5424 message carrying a CEF body.
+func (f *SyslogForwarder) frame(evt Event) []byte {
+	sig := CEFSignatureFor(evt.Type)
+	pri := syslogFacilityLogAudit*8 + syslogSeverity(sig.Severity)
+	ts := evt.Timestamp
+	if ts.IsZero() {
+		ts = time.Now().UTC()
+	}
+
+	msg := fmt.Sprintf("<%d>1 %s %s %s - %s - %s",
+		pri,
+		ts.UTC().Format(time.RFC3339Nano),
+		syslogHeaderField(f.cfg.Hostname, 255),
+		syslogHeaderField(f.cfg.AppName, 48),
+		syslogHeaderField(string(evt.Type), 32),
+		FormatCEF(evt, f.cfg.Version),
+	)
+	return []byte(fmt.Sprintf("%d %s", len(msg), msg))
+}
+
+// syslogSeverity maps CEF severity (0–10) onto RFC 5424 severity (0–7).
+func syslogSeverity(cefSeverity int) int {
+	switch {
+	case cefSeverity >= 9:
+		return 2 // critical
+	case cefSeverity >= 7:
+		return 3 // error
+	case cefSeverity >= 5:
+		return 4 // warning
+	case cefSeverity >= 3:
+		return 5 // notice
+	default:
+		return 6 // informational
+	}
+}
+
+// syslogHeaderField returns an RFC 5424 header token: printable ASCII without
+// spaces, truncated to maxLen, or the NILVALUE "-" when empty.
+func syslogHeaderField(v string, maxLen int) string {
+	var b strings.Builder
+	for _, r := range v {
+		if r > 32 && r < 127 {
+			b.WriteRune(r)
+		}
+		if b.Len() >= maxLen {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
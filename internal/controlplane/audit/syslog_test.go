@@ -0,0 +1,182 @@
+package audit
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatCEFMapsAndEscapes(t *testing.T) {
+	evt := Event{
+		ID:          "evt-1",
+		Timestamp:   time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Type:        EventLoginFailed,
+		Actor:       "alice",
+		ProbeID:     "prb-1",
+		WorkspaceID: "ws-a",
+		Summary:     "bad password a=b\nsecond line \\ end",
+	}
+
+	got := FormatCEF(evt, "1.2.3")
+	wantPrefix := "CEF:0|Legator|Control Plane|1.2.3|511|Login failed|7|"
+	if !strings.HasPrefix(got, wantPrefix) {
+		t.Fatalf("unexpected CEF header: %s", got)
+	}
+	for _, want := range []string{
+		"rt=1772366400000",
+		"externalId=evt-1",
+		"act=auth.login_failed",
+		"suser=alice",
+		"dhost=prb-1",
+		"cs1Label=workspace cs1=ws-a",
+		`msg=bad password a\=b\nsecond line \\ end`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %s", want, got)
+		}
+	}
+}
+
+func TestCEFSignatureForUnmappedType(t *testing.T) {
+	sig := CEFSignatureFor(EventType("custom.event"))
+	if sig.ID != "0" || sig.Name != "custom.event" {
+		t.Fatalf("unexpected fallback signature: %+v", sig)
+	}
+
+	seen := map[string]EventType{}
+	for typ, sig := range cefSignatures {
+		if prev, dup := seen[sig.ID]; dup {
+			t.Fatalf("signature id %s shared by %s and %s", sig.ID, prev, typ)
+		}
+		seen[sig.ID] = typ
+		if sig.Severity < 0 || sig.Severity > 10 {
+			t.Fatalf("severity out of range for %s: %d", typ, sig.Severity)
+		}
+	}
+}
+
+func TestSyslogForwarderDeliversRFC5424Frames(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	fwd, err := NewSyslogForwarder(SyslogConfig{Address: ln.Addr().String(), Hostname: "cp-1", Version: "1.0.0"}, nil)
+	if err != nil {
+		t.Fatalf("new forwarder: %v", err)
+	}
+	defer fwd.Close()
+
+	log := NewLog(0)
+	log.SetSink(fwd)
+	log.Emit(EventPolicyChanged, "prb-7", "keith", "observe → diagnose")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	msg := readOctetCountedFrame(t, bufio.NewReader(conn))
+	if !strings.HasPrefix(msg, "<108>1 ") { // facility 13, severity warning
+		t.Fatalf("unexpected PRI/version: %s", msg)
+	}
+	if !strings.Contains(msg, " cp-1 legator - policy.changed - CEF:0|Legator|Control Plane|1.0.0|300|Policy changed|6|") {
+		t.Fatalf("unexpected header: %s", msg)
+	}
+	if !strings.Contains(msg, "dhost=prb-7") {
+		t.Fatalf("expected probe id in extension: %s", msg)
+	}
+}
+
+func TestSyslogForwarderDropsWhenBufferFull(t *testing.T) {
+	fwd, err := NewSyslogForwarder(SyslogConfig{Address: "127.0.0.1:1", BufferSize: 1}, nil)
+	if err != nil {
+		t.Fatalf("new forwarder: %v", err)
+	}
+	fwd.dial = func() (net.Conn, error) { return nil, errors.New("collector down") }
+	defer fwd.Close()
+
+	for i := 0; i < 5; i++ {
+		fwd.Forward(Event{ID: strconv.Itoa(i), Type: EventCommandSent})
+	}
+
+	// One event may be held by the delivery loop, one sits in the buffer.
+	if dropped := fwd.DroppedCount(); dropped < 3 {
+		t.Fatalf("expected at least 3 dropped events, got %d", dropped)
+	}
+	if fwd.ForwardedCount() != 0 {
+		t.Fatalf("expected nothing forwarded, got %d", fwd.ForwardedCount())
+	}
+}
+
+func TestSyslogForwarderRetriesUntilCollectorAvailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	fwd, err := NewSyslogForwarder(SyslogConfig{Address: ln.Addr().String()}, nil)
+	if err != nil {
+		t.Fatalf("new forwarder: %v", err)
+	}
+	defer fwd.Close()
+
+	attempts := 0
+	realDial := fwd.dial
+	fwd.dial = func() (net.Conn, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("connection refused")
+		}
+		return realDial()
+	}
+
+	fwd.Forward(Event{ID: "evt-retry", Type: EventCommandSent, Timestamp: time.Now().UTC()})
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	msg := readOctetCountedFrame(t, bufio.NewReader(conn))
+	if !strings.Contains(msg, "externalId=evt-retry") {
+		t.Fatalf("expected retried event, got %s", msg)
+	}
+}
+
+func TestNewSyslogForwarderValidatesConfig(t *testing.T) {
+	if _, err := NewSyslogForwarder(SyslogConfig{}, nil); err == nil {
+		t.Fatal("expected error for empty address")
+	}
+	if _, err := NewSyslogForwarder(SyslogConfig{Address: "siem:514", Protocol: "udp"}, nil); err == nil {
+		t.Fatal("expected error for unsupported protocol")
+	}
+}
+
+func readOctetCountedFrame(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	lenStr, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatalf("read frame length: %v", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(lenStr))
+	if err != nil {
+		t.Fatalf("parse frame length %q: %v", lenStr, err)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("read frame body: %v", err)
+	}
+	return string(buf)
+}
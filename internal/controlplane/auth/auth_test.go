@@ -212,3 +212,29 @@ func TestFromContextNil(t *testing.T) {
 		t.Fatal("expected nil from empty context")
 	}
 }
+
+func TestCreateWithRateLimitPersists(t *testing.T) {
+	ks, err := NewKeyStore(tempDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ks.Close()
+
+	if _, _, err := ks.CreateWithRateLimit("bad", []Permission{PermFleetRead}, nil, -1); err == nil {
+		t.Fatal("expected negative rate limit to be rejected")
+	}
+	_, plain, err := ks.CreateWithRateLimit("bulk", []Permission{PermFleetRead}, nil, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validated, err := ks.Validate(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if validated.RateLimit != 600 {
+		t.Fatalf("expected rate limit 600, got %d", validated.RateLimit)
+	}
+	if keys := ks.List(); len(keys) != 1 || keys[0].RateLimit != 600 {
+		t.Fatalf("expected listed key to carry rate limit, got %+v", keys)
+	}
+}
@@ -0,0 +1,416 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+)
+
+// Authentication methods recorded on authn audit events.
+const (
+	AuthnMethodLocal  = "local"
+	AuthnMethodOIDC   = "oidc"
+	AuthnMethodAPIKey = "api_key"
+	AuthnMethodProbe  = "probe"
+)
+
+const (
+	// AuthnFailureWindow is the default for how long failed attempts keep
+	// counting against an identity after the latest one.
+	AuthnFailureWindow = 15 * time.Minute
+	// AuthnSuccessSampleInterval spaces success events for credentials sent
+	// on every request, such as API keys.
+	AuthnSuccessSampleInterval = 15 * time.Minute
+	// AuthnFailureSampleInterval spaces failure events from one source IP
+	// for credentials sent on every request; the next recorded event
+	// carries how many were suppressed.
+	AuthnFailureSampleInterval = time.Minute
+	// maxAuthnTracked bounds the failure and sampling maps, so a flood of
+	// made-up usernames cannot grow memory without bound. When a map is
+	// full the entry seen least recently makes room for the new one.
+	maxAuthnTracked = 10000
+)
+
+// AuthnAttempt describes one authentication outcome.
+type AuthnAttempt struct {
+	Method string
+	// Identity is the username, API key prefix or probe ID presented.
+	// Failures with no identity are audited but not counted.
+	Identity string
+	// Actor overrides the audit actor; it defaults to Identity.
+	Actor    string
+	ProbeID  string
+	SourceIP string
+	// Reason says why a failure was rejected.
+	Reason string
+	// Detail adds fields to the audit event.
+	Detail map[string]any
+}
+
+type authnFailures struct {
+	count int
+	last  time.Time
+}
+
+type authnSample struct {
+	last       time.Time
+	suppressed int
+}
+
+// AuthnAuditor records every authentication outcome as an authn audit
+// event and counts recent failed attempts per identity and per source IP.
+// Local and OIDC logins are also recorded as the older EventLoginSuccess and
+// EventLoginFailed events, so existing queries and alerts keep working.
+// A nil auditor records nothing.
+type AuthnAuditor struct {
+	recorder LoginAuditRecorder
+	now      func() time.Time
+	window   time.Duration
+
+	mu       sync.Mutex
+	failures map[string]*authnFailures
+	sampled  map[string]*authnSample
+}
+
+// NewAuthnAuditor creates an auditor writing to recorder.
+func NewAuthnAuditor(recorder LoginAuditRecorder) *AuthnAuditor {
+	return &AuthnAuditor{
+		recorder: recorder,
+		now:      time.Now,
+		window:   AuthnFailureWindow,
+		failures: make(map[string]*authnFailures),
+		sampled:  make(map[string]*authnSample),
+	}
+}
+
+// SetFailureWindow changes how long failed attempts keep counting. Zero or
+// negative values restore AuthnFailureWindow.
+func (a *AuthnAuditor) SetFailureWindow(d time.Duration) {
+	if a == nil {
+		return
+	}
+	if d <= 0 {
+		d = AuthnFailureWindow
+	}
+	a.mu.Lock()
+	a.window = d
+	a.mu.Unlock()
+}
+
+// Succeeded records a successful authentication and clears the identity's
+// failed attempts. Failures counted against the source IP are kept.
+func (a *AuthnAuditor) Succeeded(at AuthnAttempt) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	delete(a.failures, authnKey(at.Method, at.Identity))
+	a.mu.Unlock()
+	a.record(audit.EventAuthnSuccess, at, 0)
+}
+
+// SucceededSampled is Succeeded for credentials presented on every request:
+// it records at most one event per identity and source IP every
+// AuthnSuccessSampleInterval.
+func (a *AuthnAuditor) SucceededSampled(at AuthnAttempt) {
+	if a == nil {
+		return
+	}
+	key := authnKey(at.Method, at.Identity) + "|" + at.SourceIP
+	now := a.now()
+	a.mu.Lock()
+	delete(a.failures, authnKey(at.Method, at.Identity))
+	if s, ok := a.sampled[key]; ok && now.Sub(s.last) < AuthnSuccessSampleInterval {
+		a.mu.Unlock()
+		return
+	}
+	a.sampleLocked(key, now)
+	a.mu.Unlock()
+	a.record(audit.EventAuthnSuccess, at, 0)
+}
+
+// Failed records a failed authentication and returns the identity's failed
+// attempts within the failure window, including this one. It returns 0 when
+// the attempt carried no identity. The attempt also counts against its
+// source IP.
+func (a *AuthnAuditor) Failed(at AuthnAttempt) int {
+	if a == nil {
+		return 0
+	}
+	now := a.now()
+	a.mu.Lock()
+	count := a.countFailureLocked(authnKey(at.Method, at.Identity), now)
+	a.countFailureLocked(authnSourceKey(at.Method, at.SourceIP), now)
+	a.mu.Unlock()
+	a.record(audit.EventAuthnFailure, at, count)
+	return count
+}
+
+// FailedSampled is Failed for credentials presented on every request: every
+// failure is counted, but it records at most one event per source IP every
+// AuthnFailureSampleInterval, so a client retrying a bad key cannot flood the
+// audit log. The recorded event's suppressed_failures says how many events
+// were skipped since the previous one.
+func (a *AuthnAuditor) FailedSampled(at AuthnAttempt) int {
+	if a == nil {
+		return 0
+	}
+	key := "failed|" + at.Method + "|" + strings.TrimSpace(at.SourceIP)
+	now := a.now()
+	a.mu.Lock()
+	count := a.countFailureLocked(authnKey(at.Method, at.Identity), now)
+	a.countFailureLocked(authnSourceKey(at.Method, at.SourceIP), now)
+	if s, ok := a.sampled[key]; ok && now.Sub(s.last) < AuthnFailureSampleInterval {
+		s.suppressed++
+		a.mu.Unlock()
+		return count
+	}
+	suppressed := a.sampleLocked(key, now)
+	a.mu.Unlock()
+	if suppressed > 0 {
+		detail := make(map[string]any, len(at.Detail)+1)
+		for k, v := range at.Detail {
+			detail[k] = v
+		}
+		detail["suppressed_failures"] = suppressed
+		at.Detail = detail
+	}
+	a.record(audit.EventAuthnFailure, at, count)
+	return count
+}
+
+// Refused records a failed authentication that was turned away before its
+// credentials were checked, such as during a lockout. It is not counted.
+func (a *AuthnAuditor) Refused(at AuthnAttempt) {
+	if a == nil {
+		return
+	}
+	a.record(audit.EventAuthnFailure, at, 0)
+}
+
+// FailedAttempts returns the identity's failed attempts within the failure
+// window.
+func (a *AuthnAuditor) FailedAttempts(method, identity string) int {
+	if a == nil {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.failuresLocked(authnKey(method, identity))
+}
+
+// FailedAttemptsFrom returns the failed attempts from sourceIP within the
+// failure window, whatever identity they presented.
+func (a *AuthnAuditor) FailedAttemptsFrom(method, sourceIP string) int {
+	if a == nil {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.failuresLocked(authnSourceKey(method, sourceIP))
+}
+
+// ClearFailures forgets the failed attempts counted against identity and
+// sourceIP; either may be empty.
+func (a *AuthnAuditor) ClearFailures(method, identity, sourceIP string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.failures, authnKey(method, identity))
+	delete(a.failures, authnSourceKey(method, sourceIP))
+}
+
+func (a *AuthnAuditor) countFailureLocked(key string, now time.Time) int {
+	if key == "" {
+		return 0
+	}
+	f, ok := a.failures[key]
+	if ok && now.Sub(f.last) >= a.window {
+		ok = false
+	}
+	if !ok {
+		if _, tracked := a.failures[key]; !tracked {
+			a.pruneFailuresLocked(now)
+		}
+		f = &authnFailures{}
+		a.failures[key] = f
+	}
+	f.count++
+	f.last = now
+	return f.count
+}
+
+func (a *AuthnAuditor) failuresLocked(key string) int {
+	f, ok := a.failures[key]
+	if !ok || a.now().Sub(f.last) >= a.window {
+		return 0
+	}
+	return f.count
+}
+
+// pruneFailuresLocked makes room for one more failure count once the map is
+// full: expired counts go first, then the count seen least recently.
+func (a *AuthnAuditor) pruneFailuresLocked(now time.Time) {
+	if len(a.failures) < maxAuthnTracked {
+		return
+	}
+	oldestKey, oldest := "", now
+	for k, f := range a.failures {
+		if now.Sub(f.last) >= a.window {
+			delete(a.failures, k)
+			continue
+		}
+		if !f.last.After(oldest) {
+			oldestKey, oldest = k, f.last
+		}
+	}
+	if len(a.failures) >= maxAuthnTracked {
+		delete(a.failures, oldestKey)
+	}
+}
+
+// sampleLocked marks key as recorded at now and returns how many events it
+// suppressed since it was last recorded.
+func (a *AuthnAuditor) sampleLocked(key string, now time.Time) int {
+	s, ok := a.sampled[key]
+	if !ok {
+		a.pruneSampledLocked(now)
+		s = &authnSample{}
+		a.sampled[key] = s
+	}
+	suppressed := s.suppressed
+	s.last, s.suppressed = now, 0
+	return suppressed
+}
+
+// pruneSampledLocked is pruneFailuresLocked for sampled events. Entries
+// expire after the longer of the two sample intervals.
+func (a *AuthnAuditor) pruneSampledLocked(now time.Time) {
+	if len(a.sampled) < maxAuthnTracked {
+		return
+	}
+	oldestKey, oldest := "", now
+	for k, s := range a.sampled {
+		if now.Sub(s.last) >= AuthnSuccessSampleInterval {
+			delete(a.sampled, k)
+			continue
+		}
+		if !s.last.After(oldest) {
+			oldestKey, oldest = k, s.last
+		}
+	}
+	if len(a.sampled) >= maxAuthnTracked {
+		delete(a.sampled, oldestKey)
+	}
+}
+
+func (a *AuthnAuditor) record(typ audit.EventType, at AuthnAttempt, failures int) {
+	if a.recorder == nil {
+		return
+	}
+	identity := strings.TrimSpace(at.Identity)
+	if identity == "" {
+		identity = "unknown"
+	}
+	actor := at.Actor
+	if actor == "" {
+		actor = identity
+	}
+
+	detail := map[string]any{
+		"method":    at.Method,
+		"identity":  identity,
+		"source_ip": at.SourceIP,
+	}
+	for k, v := range at.Detail {
+		detail[k] = v
+	}
+	summary := "Authentication succeeded for " + identity + " (" + at.Method + ")"
+	if typ == audit.EventAuthnFailure {
+		summary = "Authentication failed for " + identity + " (" + at.Method + ")"
+		if at.Reason != "" {
+			summary += ": " + at.Reason
+			detail["reason"] = at.Reason
+		}
+		if failures > 0 {
+			detail["failed_attempts"] = failures
+		}
+	}
+
+	now := a.now().UTC()
+	a.recorder.Record(audit.Event{
+		Timestamp: now,
+		Type:      typ,
+		ProbeID:   at.ProbeID,
+		Actor:     actor,
+		Summary:   summary,
+		Detail:    detail,
+	})
+	if at.Method == AuthnMethodLocal || at.Method == AuthnMethodOIDC {
+		a.recordLegacyLogin(typ, at, identity, actor, now)
+	}
+}
+
+// recordLegacyLogin records a login outcome as EventLoginSuccess or
+// EventLoginFailed, in the shape those events had before the authn events.
+func (a *AuthnAuditor) recordLegacyLogin(typ audit.EventType, at AuthnAttempt, identity, actor string, now time.Time) {
+	detail := map[string]any{"method": at.Method}
+	for k, v := range at.Detail {
+		detail[k] = v
+	}
+	legacy := audit.Event{
+		Timestamp: now,
+		Type:      audit.EventLoginSuccess,
+		Actor:     actor,
+		Summary:   "Login succeeded for " + identity + " (" + at.Method + ")",
+		Detail:    detail,
+	}
+	if typ == audit.EventAuthnFailure {
+		legacy.Type = audit.EventLoginFailed
+		legacy.Summary = "Login failed for " + identity + " (" + at.Method + ")"
+		if at.Reason != "" {
+			legacy.Summary += ": " + at.Reason
+			detail["reason"] = at.Reason
+		}
+	}
+	a.recorder.Record(legacy)
+}
+
+// authnKey identifies an identity per method; usernames compare case
+// insensitively. It is empty when there is no identity to count.
+func authnKey(method, identity string) string {
+	identity = strings.ToLower(strings.TrimSpace(identity))
+	if identity == "" || identity == "unknown" {
+		return ""
+	}
+	return method + ":" + identity
+}
+
+// authnSourceKey identifies a source IP per method. The separator keeps it
+// apart from authnKey for any identity.
+func authnSourceKey(method, sourceIP string) string {
+	sourceIP = strings.TrimSpace(sourceIP)
+	if sourceIP == "" {
+		return ""
+	}
+	return method + "|ip|" + sourceIP
+}
+
+// SourceIP returns the client address of r without its port. Forwarded
+// headers are not trusted.
+func SourceIP(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+)
+
+type recordedEvents struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+func (r *recordedEvents) Record(evt audit.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, evt)
+}
+
+func (r *recordedEvents) list() []audit.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]audit.Event(nil), r.events...)
+}
+
+func (r *recordedEvents) ofType(typ audit.EventType) []audit.Event {
+	var out []audit.Event
+	for _, evt := range r.list() {
+		if evt.Type == typ {
+			out = append(out, evt)
+		}
+	}
+	return out
+}
+
+func TestAuthnAuditorCountsFailuresWithinWindow(t *testing.T) {
+	rec := &recordedEvents{}
+	a := NewAuthnAuditor(rec)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	attempt := AuthnAttempt{Method: AuthnMethodLocal, Identity: "Alice", SourceIP: "10.0.0.1", Reason: "invalid credentials"}
+	if got := a.Failed(attempt); got != 1 {
+		t.Fatalf("first failure count = %d, want 1", got)
+	}
+	now = now.Add(time.Minute)
+	attempt.Identity = "alice"
+	if got := a.Failed(attempt); got != 2 {
+		t.Fatalf("second failure count = %d, want 2 (identity is case insensitive)", got)
+	}
+	if got := a.FailedAttempts(AuthnMethodLocal, "ALICE"); got != 2 {
+		t.Fatalf("FailedAttempts = %d, want 2", got)
+	}
+	if got := a.FailedAttempts(AuthnMethodOIDC, "alice"); got != 0 {
+		t.Fatalf("FailedAttempts for another method = %d, want 0", got)
+	}
+
+	now = now.Add(AuthnFailureWindow)
+	if got := a.FailedAttempts(AuthnMethodLocal, "alice"); got != 0 {
+		t.Fatalf("FailedAttempts after window = %d, want 0", got)
+	}
+	if got := a.Failed(attempt); got != 1 {
+		t.Fatalf("failure after window = %d, want 1", got)
+	}
+
+	events := rec.ofType(audit.EventAuthnFailure)
+	if len(events) != 3 {
+		t.Fatalf("expected 3 authn failure events, got %d", len(events))
+	}
+	evt := events[1]
+	if evt.Type != audit.EventAuthnFailure {
+		t.Fatalf("type = %s, want %s", evt.Type, audit.EventAuthnFailure)
+	}
+	detail := evt.Detail.(map[string]any)
+	if detail["source_ip"] != "10.0.0.1" || detail["method"] != AuthnMethodLocal || detail["failed_attempts"] != 2 {
+		t.Fatalf("unexpected detail: %#v", detail)
+	}
+	if detail["reason"] != "invalid credentials" {
+		t.Fatalf("reason = %v", detail["reason"])
+	}
+}
+
+func TestAuthnAuditorEvictsOldestWhenFull(t *testing.T) {
+	rec := &recordedEvents{}
+	a := NewAuthnAuditor(nil)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	for i := 0; i < maxAuthnTracked; i++ {
+		a.Failed(AuthnAttempt{Method: AuthnMethodLocal, Identity: fmt.Sprintf("flood-%d", i)})
+		a.SucceededSampled(AuthnAttempt{Method: AuthnMethodAPIKey, Identity: fmt.Sprintf("lgk_%d", i)})
+		now = now.Add(time.Millisecond)
+	}
+
+	target := AuthnAttempt{Method: AuthnMethodLocal, Identity: "alice"}
+	a.Failed(target)
+	if got := a.Failed(target); got != 2 {
+		t.Fatalf("failure count with a full map = %d, want 2", got)
+	}
+	if got := a.FailedAttempts(AuthnMethodLocal, "flood-0"); got != 0 {
+		t.Fatalf("oldest identity still counted: %d", got)
+	}
+	if got := a.FailedAttempts(AuthnMethodLocal, "flood-1"); got != 1 {
+		t.Fatalf("recent identity count = %d, want 1", got)
+	}
+	if got := len(a.failures); got != maxAuthnTracked {
+		t.Fatalf("tracked failures = %d, want %d", got, maxAuthnTracked)
+	}
+
+	a.recorder = rec
+	sampled := AuthnAttempt{Method: AuthnMethodAPIKey, Identity: "lgk_new"}
+	a.SucceededSampled(sampled)
+	a.SucceededSampled(sampled)
+	if got := len(rec.list()); got != 1 {
+		t.Fatalf("sampled events with a full map = %d, want 1", got)
+	}
+}
+
+func TestAuthnAuditorSuccessClearsFailures(t *testing.T) {
+	rec := &recordedEvents{}
+	a := NewAuthnAuditor(rec)
+
+	a.Failed(AuthnAttempt{Method: AuthnMethodLocal, Identity: "bob"})
+	a.Failed(AuthnAttempt{Method: AuthnMethodLocal, Identity: "bob"})
+	a.Succeeded(AuthnAttempt{Method: AuthnMethodLocal, Identity: "bob", Actor: "user-1"})
+
+	if got := a.FailedAttempts(AuthnMethodLocal, "bob"); got != 0 {
+		t.Fatalf("FailedAttempts after success = %d, want 0", got)
+	}
+	events := rec.ofType(audit.EventAuthnSuccess)
+	last := events[len(events)-1]
+	if last.Type != audit.EventAuthnSuccess || last.Actor != "user-1" {
+		t.Fatalf("unexpected success event: %+v", last)
+	}
+}
+
+func TestAuthnAuditorFailureWithoutIdentityIsNotCounted(t *testing.T) {
+	rec := &recordedEvents{}
+	a := NewAuthnAuditor(rec)
+
+	if got := a.Failed(AuthnAttempt{Method: AuthnMethodOIDC, Reason: "state mismatch"}); got != 0 {
+		t.Fatalf("count = %d, want 0", got)
+	}
+	events := rec.ofType(audit.EventAuthnFailure)
+	if len(events) != 1 || events[0].Actor != "unknown" {
+		t.Fatalf("expected one event for unknown actor, got %+v", events)
+	}
+}
+
+func TestAuthnAuditorSamplesRepeatedSuccess(t *testing.T) {
+	rec := &recordedEvents{}
+	a := NewAuthnAuditor(rec)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	attempt := AuthnAttempt{Method: AuthnMethodAPIKey, Identity: "lgk_abcdefgh", SourceIP: "10.0.0.2"}
+	a.SucceededSampled(attempt)
+	now = now.Add(time.Minute)
+	a.SucceededSampled(attempt)
+	if got := len(rec.list()); got != 1 {
+		t.Fatalf("events after repeat = %d, want 1", got)
+	}
+
+	attempt.SourceIP = "10.0.0.3"
+	a.SucceededSampled(attempt)
+	if got := len(rec.list()); got != 2 {
+		t.Fatalf("events from new source = %d, want 2", got)
+	}
+
+	now = now.Add(AuthnSuccessSampleInterval)
+	attempt.SourceIP = "10.0.0.2"
+	a.SucceededSampled(attempt)
+	if got := len(rec.list()); got != 3 {
+		t.Fatalf("events after interval = %d, want 3", got)
+	}
+}
+
+func TestAuthnAuditorRecordsLegacyLoginEvents(t *testing.T) {
+	rec := &recordedEvents{}
+	a := NewAuthnAuditor(rec)
+
+	a.Failed(AuthnAttempt{Method: AuthnMethodLocal, Identity: "bob", Reason: "invalid credentials", Detail: map[string]any{"remote_addr": "10.0.0.1:5000"}})
+	a.Succeeded(AuthnAttempt{Method: AuthnMethodOIDC, Identity: "bob", Actor: "user-1"})
+	a.Failed(AuthnAttempt{Method: AuthnMethodAPIKey, Identity: "lgk_abcdefgh"})
+
+	failed := rec.ofType(audit.EventLoginFailed)
+	if len(failed) != 1 || failed[0].Actor != "bob" || failed[0].Summary != "Login failed for bob (local): invalid credentials" {
+		t.Fatalf("unexpected auth.login_failed events: %+v", failed)
+	}
+	detail := failed[0].Detail.(map[string]any)
+	if detail["method"] != AuthnMethodLocal || detail["remote_addr"] != "10.0.0.1:5000" || detail["reason"] != "invalid credentials" {
+		t.Fatalf("unexpected auth.login_failed detail: %#v", detail)
+	}
+	succeeded := rec.ofType(audit.EventLoginSuccess)
+	if len(succeeded) != 1 || succeeded[0].Actor != "user-1" || succeeded[0].Summary != "Login succeeded for bob (oidc)" {
+		t.Fatalf("unexpected auth.login events: %+v", succeeded)
+	}
+	if got := len(rec.ofType(audit.EventAuthnFailure)); got != 2 {
+		t.Fatalf("authn failure events = %d, want 2 (API keys get no legacy event)", got)
+	}
+}
+
+func TestAuthnAuditorSamplesRepeatedFailurePerSource(t *testing.T) {
+	rec := &recordedEvents{}
+	a := NewAuthnAuditor(rec)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		a.FailedSampled(AuthnAttempt{Method: AuthnMethodAPIKey, Identity: fmt.Sprintf("lgk_bad%05d", i), SourceIP: "10.0.0.9"})
+		now = now.Add(time.Second)
+	}
+	if got := len(rec.list()); got != 1 {
+		t.Fatalf("events for repeated failures = %d, want 1", got)
+	}
+	if got := a.FailedAttemptsFrom(AuthnMethodAPIKey, "10.0.0.9"); got != 5 {
+		t.Fatalf("failures counted from source = %d, want 5", got)
+	}
+
+	a.FailedSampled(AuthnAttempt{Method: AuthnMethodAPIKey, Identity: "lgk_other0000", SourceIP: "10.0.0.10"})
+	if got := len(rec.list()); got != 2 {
+		t.Fatalf("events from a new source = %d, want 2", got)
+	}
+
+	now = now.Add(AuthnFailureSampleInterval)
+	a.FailedSampled(AuthnAttempt{Method: AuthnMethodAPIKey, Identity: "lgk_bad00005", SourceIP: "10.0.0.9"})
+	events := rec.list()
+	if len(events) != 3 {
+		t.Fatalf("events after interval = %d, want 3", len(events))
+	}
+	detail := events[2].Detail.(map[string]any)
+	if detail["suppressed_failures"] != 4 || detail["failed_attempts"] != 1 {
+		t.Fatalf("unexpected detail after interval: %#v", detail)
+	}
+}
+
+func TestAuthnAuditorNilIsSafe(t *testing.T) {
+	var a *AuthnAuditor
+	a.Succeeded(AuthnAttempt{Identity: "x"})
+	a.SucceededSampled(AuthnAttempt{Identity: "x"})
+	if got := a.Failed(AuthnAttempt{Identity: "x"}); got != 0 {
+		t.Fatalf("nil auditor count = %d", got)
+	}
+	if got := a.FailedSampled(AuthnAttempt{Identity: "x"}); got != 0 {
+		t.Fatalf("nil auditor sampled count = %d", got)
+	}
+}
+
+func TestSourceIPStripsPort(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "192.0.2.10:51234"
+	r.Header.Set("X-Forwarded-For", "203.0.113.5")
+	if got := SourceIP(r); got != "192.0.2.10" {
+		t.Fatalf("SourceIP = %q, want 192.0.2.10", got)
+	}
+}
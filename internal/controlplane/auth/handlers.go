@@ -26,6 +26,7 @@ func HandleCreateKey(store *KeyStore) http.HandlerFunc {
 			Name        string       `json:"name"`
 			Permissions []Permission `json:"permissions"`
 			ExpiresIn   string       `json:"expires_in,omitempty"` // e.g. "720h" for 30 days
+			RateLimit   int          `json:"rate_limit,omitempty"` // requests per minute; 0 = server default
 		}
 		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 			http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
@@ -40,6 +41,11 @@ func HandleCreateKey(store *KeyStore) http.HandlerFunc {
 			return
 		}
 
+		if body.RateLimit < 0 {
+			http.Error(w, `{"error":"rate_limit must not be negative"}`, http.StatusBadRequest)
+			return
+		}
+
 		var expiresAt *time.Time
 		if body.ExpiresIn != "" {
 			d, err := time.ParseDuration(body.ExpiresIn)
@@ -51,7 +57,7 @@ func HandleCreateKey(store *KeyStore) http.HandlerFunc {
 			expiresAt = &t
 		}
 
-		key, plainKey, err := store.Create(body.Name, body.Permissions, expiresAt)
+		key, plainKey, err := store.CreateWithRateLimit(body.Name, body.Permissions, expiresAt, body.RateLimit)
 		if err != nil {
 			http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusInternalServerError)
 			return
@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -40,6 +41,9 @@ type APIKey struct {
 	LastUsedAt  *time.Time   `json:"last_used_at,omitempty"`
 	ExpiresAt   *time.Time   `json:"expires_at,omitempty"`
 	Enabled     bool         `json:"enabled"`
+	// RateLimit overrides the default per-key request limit (requests per
+	// minute). Zero uses the server default.
+	RateLimit int `json:"rate_limit,omitempty"`
 }
 
 // KeyStore manages API keys with SQLite backing.
@@ -79,6 +83,11 @@ func NewKeyStore(dbPath string) (*KeyStore, error) {
 		return nil, err
 	}
 
+	if _, err := db.Exec(`ALTER TABLE api_keys ADD COLUMN rate_limit INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		db.Close()
+		return nil, err
+	}
+
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_keys_prefix ON api_keys(key_prefix)`)
 
 	if err := migration.EnsureVersion(db, 1); err != nil {
@@ -90,6 +99,16 @@ func NewKeyStore(dbPath string) (*KeyStore, error) {
 
 // Create generates a new API key, stores the bcrypt hash, and returns the plaintext once.
 func (ks *KeyStore) Create(name string, permissions []Permission, expiresAt *time.Time) (*APIKey, string, error) {
+	return ks.CreateWithRateLimit(name, permissions, expiresAt, 0)
+}
+
+// CreateWithRateLimit is Create with a per-key request limit (requests per
+// minute); zero uses the server default.
+func (ks *KeyStore) CreateWithRateLimit(name string, permissions []Permission, expiresAt *time.Time, rateLimit int) (*APIKey, string, error) {
+	if rateLimit < 0 {
+		return nil, "", fmt.Errorf("rate limit must not be negative")
+	}
+
 	ks.mu.Lock()
 	defer ks.mu.Unlock()
 
@@ -116,6 +135,7 @@ func (ks *KeyStore) Create(name string, permissions []Permission, expiresAt *tim
 		CreatedAt:   now,
 		Enabled:     true,
 		ExpiresAt:   expiresAt,
+		RateLimit:   rateLimit,
 	}
 
 	permsJSON := permissionsToJSON(permissions)
@@ -124,10 +144,10 @@ func (ks *KeyStore) Create(name string, permissions []Permission, expiresAt *tim
 		expiresStr = sql.NullString{String: expiresAt.Format(time.RFC3339Nano), Valid: true}
 	}
 
-	_, err = ks.db.Exec(`INSERT INTO api_keys (id, name, key_hash, key_prefix, permissions, created_at, expires_at, enabled)
-		VALUES (?, ?, ?, ?, ?, ?, ?, 1)`,
+	_, err = ks.db.Exec(`INSERT INTO api_keys (id, name, key_hash, key_prefix, permissions, created_at, expires_at, enabled, rate_limit)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?)`,
 		key.ID, key.Name, key.KeyHash, key.KeyPrefix, permsJSON,
-		now.Format(time.RFC3339Nano), expiresStr)
+		now.Format(time.RFC3339Nano), expiresStr, key.RateLimit)
 	if err != nil {
 		return nil, "", fmt.Errorf("store key: %w", err)
 	}
@@ -152,10 +172,10 @@ func (ks *KeyStore) Validate(plainKey string) (*APIKey, error) {
 		enabled              int
 	)
 
-	err := ks.db.QueryRow(`SELECT id, name, key_hash, key_prefix, permissions, created_at, last_used, expires_at, enabled
+	err := ks.db.QueryRow(`SELECT id, name, key_hash, key_prefix, permissions, created_at, last_used, expires_at, enabled, rate_limit
 		FROM api_keys WHERE key_prefix = ?`, prefix).Scan(
 		&key.ID, &key.Name, &key.KeyHash, &key.KeyPrefix, &permsJSON,
-		&createdAt, &lastUsed, &expiresAt, &enabled)
+		&createdAt, &lastUsed, &expiresAt, &enabled, &key.RateLimit)
 	if err != nil {
 		return nil, fmt.Errorf("key not found")
 	}
@@ -205,7 +225,7 @@ func (ks *KeyStore) List() []APIKey {
 	ks.mu.RLock()
 	defer ks.mu.RUnlock()
 
-	rows, err := ks.db.Query(`SELECT id, name, key_prefix, permissions, created_at, last_used, expires_at, enabled FROM api_keys ORDER BY created_at DESC`)
+	rows, err := ks.db.Query(`SELECT id, name, key_prefix, permissions, created_at, last_used, expires_at, enabled, rate_limit FROM api_keys ORDER BY created_at DESC`)
 	if err != nil {
 		return nil
 	}
@@ -219,7 +239,7 @@ func (ks *KeyStore) List() []APIKey {
 			lastUsed, expiresAt  sql.NullString
 			enabled              int
 		)
-		if err := rows.Scan(&key.ID, &key.Name, &key.KeyPrefix, &permsJSON, &createdAt, &lastUsed, &expiresAt, &enabled); err != nil {
+		if err := rows.Scan(&key.ID, &key.Name, &key.KeyPrefix, &permsJSON, &createdAt, &lastUsed, &expiresAt, &enabled, &key.RateLimit); err != nil {
 			continue
 		}
 		key.Enabled = enabled == 1
@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+)
+
+// Login lockout kinds.
+const (
+	LockoutKindUser = "user"
+	LockoutKindIP   = "ip"
+)
+
+const (
+	DefaultLockoutMaxAttempts = 5
+	DefaultLockoutDuration    = 15 * time.Minute
+)
+
+// Lockout is a temporary block on password login for a username or a
+// source IP.
+type Lockout struct {
+	Key            string    `json:"key"`
+	Kind           string    `json:"kind"`
+	Subject        string    `json:"subject"`
+	FailedAttempts int       `json:"failed_attempts"`
+	LockedAt       time.Time `json:"locked_at"`
+	LockedUntil    time.Time `json:"locked_until"`
+}
+
+// LockoutStore persists lockouts so a restart does not lift them.
+type LockoutStore interface {
+	SaveLockout(l Lockout) error
+	DeleteLockout(key string) error
+	ListLockouts(now time.Time) ([]Lockout, error)
+}
+
+// LoginLockoutOptions sets when password login is locked and for how long.
+type LoginLockoutOptions struct {
+	// MaxAttempts is the failed logins for one username, within the authn
+	// failure window, that lock it. Zero uses the default; negative never
+	// locks usernames.
+	MaxAttempts int
+	// IPMaxAttempts is the failed logins from one source IP, whatever the
+	// username, that lock that IP. Zero or negative never locks IPs: the
+	// source IP is the peer address, so behind a reverse proxy every client
+	// shares it.
+	IPMaxAttempts int
+	// Duration is how long a lockout lasts.
+	Duration time.Duration
+}
+
+// LoginLockout tracks active password login lockouts. A nil LoginLockout
+// never locks.
+type LoginLockout struct {
+	opts     LoginLockoutOptions
+	store    LockoutStore
+	recorder LoginAuditRecorder
+	now      func() time.Time
+
+	mu    sync.Mutex
+	locks map[string]Lockout
+
+	guardMu sync.Mutex
+	guards  map[string]*lockoutGuard
+}
+
+// lockoutGuard serializes login attempts for one lockout key.
+type lockoutGuard struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewLoginLockout creates a lockout tracker. store and recorder may be nil.
+func NewLoginLockout(opts LoginLockoutOptions, store LockoutStore, recorder LoginAuditRecorder) *LoginLockout {
+	if opts.MaxAttempts == 0 {
+		opts.MaxAttempts = DefaultLockoutMaxAttempts
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = DefaultLockoutDuration
+	}
+	return &LoginLockout{
+		opts:     opts,
+		store:    store,
+		recorder: recorder,
+		now:      time.Now,
+		locks:    make(map[string]Lockout),
+		guards:   make(map[string]*lockoutGuard),
+	}
+}
+
+// Load restores unexpired lockouts from the store.
+func (l *LoginLockout) Load() error {
+	if l == nil || l.store == nil {
+		return nil
+	}
+	saved, err := l.store.ListLockouts(l.now())
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, lock := range saved {
+		l.locks[lock.Key] = lock
+	}
+	return nil
+}
+
+// LockoutKey returns the key of the lockout for kind and subject. Usernames
+// compare case insensitively.
+func LockoutKey(kind, subject string) string {
+	subject = strings.TrimSpace(subject)
+	if kind == LockoutKindUser {
+		subject = strings.ToLower(subject)
+	}
+	return kind + ":" + subject
+}
+
+// Locked returns the active lockout blocking a login as username from
+// sourceIP, preferring the username lockout, or nil.
+func (l *LoginLockout) Locked(username, sourceIP string) *Lockout {
+	if l == nil {
+		return nil
+	}
+	now := l.now()
+	for _, key := range []string{LockoutKey(LockoutKindUser, username), LockoutKey(LockoutKindIP, sourceIP)} {
+		if lock, ok := l.active(key, now); ok {
+			return &lock
+		}
+	}
+	return nil
+}
+
+// Guard holds the attempt locks for username and, when IPs can be locked,
+// sourceIP until release is called. Holding them across Locked, the
+// credential check and RecordFailure makes the check and the count one
+// critical section, so concurrent attempts cannot get past the limits.
+// Attempts for other usernames and IPs are not held up.
+func (l *LoginLockout) Guard(username, sourceIP string) (release func()) {
+	if l == nil {
+		return func() {}
+	}
+	// Keys are always taken user first, then IP, so two attempts cannot
+	// wait on each other.
+	var keys []string
+	if strings.TrimSpace(username) != "" && l.opts.MaxAttempts > 0 {
+		keys = append(keys, LockoutKey(LockoutKindUser, username))
+	}
+	if strings.TrimSpace(sourceIP) != "" && l.opts.IPMaxAttempts > 0 {
+		keys = append(keys, LockoutKey(LockoutKindIP, sourceIP))
+	}
+	held := make([]*lockoutGuard, 0, len(keys))
+	for _, key := range keys {
+		g := l.acquireGuard(key)
+		g.mu.Lock()
+		held = append(held, g)
+	}
+	return func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].mu.Unlock()
+			l.releaseGuard(keys[i], held[i])
+		}
+	}
+}
+
+func (l *LoginLockout) acquireGuard(key string) *lockoutGuard {
+	l.guardMu.Lock()
+	defer l.guardMu.Unlock()
+	g, ok := l.guards[key]
+	if !ok {
+		g = &lockoutGuard{}
+		l.guards[key] = g
+	}
+	g.refs++
+	return g
+}
+
+func (l *LoginLockout) releaseGuard(key string, g *lockoutGuard) {
+	l.guardMu.Lock()
+	defer l.guardMu.Unlock()
+	g.refs--
+	if g.refs == 0 {
+		delete(l.guards, key)
+	}
+}
+
+// RecordFailure locks the username or source IP whose failed attempts have
+// reached the configured limits, and returns the lockouts it created.
+func (l *LoginLockout) RecordFailure(username, sourceIP string, userFailures, ipFailures int) []Lockout {
+	if l == nil {
+		return nil
+	}
+	var created []Lockout
+	if strings.TrimSpace(username) != "" && l.opts.MaxAttempts > 0 && userFailures >= l.opts.MaxAttempts {
+		created = append(created, l.lock(LockoutKindUser, username, userFailures))
+	}
+	if strings.TrimSpace(sourceIP) != "" && l.opts.IPMaxAttempts > 0 && ipFailures >= l.opts.IPMaxAttempts {
+		created = append(created, l.lock(LockoutKindIP, sourceIP, ipFailures))
+	}
+	return created
+}
+
+// List returns the active lockouts, oldest first.
+func (l *LoginLockout) List() []Lockout {
+	out := []Lockout{}
+	if l == nil {
+		return out
+	}
+	now := l.now()
+	l.mu.Lock()
+	keys := make([]string, 0, len(l.locks))
+	for key := range l.locks {
+		keys = append(keys, key)
+	}
+	l.mu.Unlock()
+	for _, key := range keys {
+		if lock, ok := l.active(key, now); ok {
+			out = append(out, lock)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LockedAt.Before(out[j].LockedAt) })
+	return out
+}
+
+// Clear lifts the lockout with key before it expires and records who did.
+func (l *LoginLockout) Clear(key, actor string) (Lockout, bool, error) {
+	if l == nil {
+		return Lockout{}, false, nil
+	}
+	lock, ok := l.active(key, l.now())
+	if !ok {
+		return Lockout{}, false, nil
+	}
+	l.mu.Lock()
+	delete(l.locks, key)
+	l.mu.Unlock()
+
+	var err error
+	if l.store != nil {
+		err = l.store.DeleteLockout(key)
+	}
+	l.audit(audit.EventLoginLockoutCleared, actor,
+		fmt.Sprintf("Login lockout cleared for %s %s", lock.Kind, lock.Subject), lock)
+	return lock, true, err
+}
+
+func (l *LoginLockout) lock(kind, subject string, failures int) Lockout {
+	now := l.now().UTC()
+	lock := Lockout{
+		Key:            LockoutKey(kind, subject),
+		Kind:           kind,
+		Subject:        strings.TrimSpace(subject),
+		FailedAttempts: failures,
+		LockedAt:       now,
+		LockedUntil:    now.Add(l.opts.Duration),
+	}
+	l.mu.Lock()
+	l.locks[lock.Key] = lock
+	l.mu.Unlock()
+
+	if l.store != nil {
+		// The in-memory lock still applies if it cannot be persisted.
+		_ = l.store.SaveLockout(lock)
+	}
+	l.audit(audit.EventLoginLockout, lock.Subject,
+		fmt.Sprintf("Login locked for %s %s after %d failed attempts, until %s",
+			kind, lock.Subject, failures, lock.LockedUntil.Format(time.RFC3339)), lock)
+	return lock
+}
+
+// active returns the lockout with key unless it has expired, dropping
+// expired lockouts as they are seen.
+func (l *LoginLockout) active(key string, now time.Time) (Lockout, bool) {
+	l.mu.Lock()
+	lock, ok := l.locks[key]
+	expired := ok && !now.Before(lock.LockedUntil)
+	if expired {
+		delete(l.locks, key)
+	}
+	l.mu.Unlock()
+
+	if expired && l.store != nil {
+		_ = l.store.DeleteLockout(key)
+	}
+	return lock, ok && !expired
+}
+
+func (l *LoginLockout) audit(typ audit.EventType, actor, summary string, lock Lockout) {
+	if l.recorder == nil {
+		return
+	}
+	l.recorder.Record(audit.Event{
+		Timestamp: l.now().UTC(),
+		Type:      typ,
+		Actor:     actor,
+		Summary:   summary,
+		Detail: map[string]any{
+			"key":             lock.Key,
+			"kind":            lock.Kind,
+			"subject":         lock.Subject,
+			"failed_attempts": lock.FailedAttempts,
+			"locked_until":    lock.LockedUntil.Format(time.RFC3339),
+		},
+	})
+}
@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+)
+
+type memoryLockoutStore struct {
+	saved map[string]Lockout
+}
+
+func (m *memoryLockoutStore) SaveLockout(l Lockout) error {
+	if m.saved == nil {
+		m.saved = make(map[string]Lockout)
+	}
+	m.saved[l.Key] = l
+	return nil
+}
+
+func (m *memoryLockoutStore) DeleteLockout(key string) error {
+	delete(m.saved, key)
+	return nil
+}
+
+func (m *memoryLockoutStore) ListLockouts(now time.Time) ([]Lockout, error) {
+	var out []Lockout
+	for _, l := range m.saved {
+		if now.Before(l.LockedUntil) {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func TestLoginLockoutLocksAtThresholdAndExpires(t *testing.T) {
+	rec := &recordedEvents{}
+	store := &memoryLockoutStore{}
+	l := NewLoginLockout(LoginLockoutOptions{MaxAttempts: 3, IPMaxAttempts: 10, Duration: 10 * time.Minute}, store, rec)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	if got := l.RecordFailure("Alice", "10.0.0.1", 2, 2); len(got) != 0 {
+		t.Fatalf("locked below threshold: %+v", got)
+	}
+	got := l.RecordFailure("Alice", "10.0.0.1", 3, 3)
+	if len(got) != 1 || got[0].Key != "user:alice" || got[0].Kind != LockoutKindUser {
+		t.Fatalf("expected user lockout, got %+v", got)
+	}
+	if lock := l.Locked("ALICE", "10.0.0.9"); lock == nil || lock.Key != "user:alice" {
+		t.Fatalf("expected alice locked from any IP, got %+v", lock)
+	}
+	if lock := l.Locked("bob", "10.0.0.1"); lock != nil {
+		t.Fatalf("bob should not be locked: %+v", lock)
+	}
+	if _, ok := store.saved["user:alice"]; !ok {
+		t.Fatal("expected lockout to be persisted")
+	}
+	events := rec.list()
+	if len(events) != 1 || events[0].Type != audit.EventLoginLockout {
+		t.Fatalf("expected one lockout audit event, got %+v", events)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if lock := l.Locked("alice", ""); lock != nil {
+		t.Fatalf("lockout should have expired: %+v", lock)
+	}
+	if _, ok := store.saved["user:alice"]; ok {
+		t.Fatal("expected expired lockout to be deleted from the store")
+	}
+}
+
+func TestLoginLockoutLeavesSourceIPsUnlockedByDefault(t *testing.T) {
+	l := NewLoginLockout(LoginLockoutOptions{}, nil, nil)
+	if got := l.RecordFailure("", "10.0.0.1", 0, 1000); len(got) != 0 {
+		t.Fatalf("expected no IP lockout without ip_max_attempts, got %+v", got)
+	}
+}
+
+func TestLoginLockoutLocksSourceIP(t *testing.T) {
+	l := NewLoginLockout(LoginLockoutOptions{MaxAttempts: -1, IPMaxAttempts: 4}, nil, nil)
+
+	got := l.RecordFailure("alice", "10.0.0.1", 100, 4)
+	if len(got) != 1 || got[0].Key != "ip:10.0.0.1" {
+		t.Fatalf("expected only an IP lockout, got %+v", got)
+	}
+	if lock := l.Locked("carol", "10.0.0.1"); lock == nil || lock.Kind != LockoutKindIP {
+		t.Fatalf("expected any username locked from the IP, got %+v", lock)
+	}
+}
+
+func TestLoginLockoutLoadAndClear(t *testing.T) {
+	rec := &recordedEvents{}
+	now := time.Now().UTC()
+	store := &memoryLockoutStore{}
+	_ = store.SaveLockout(Lockout{Key: "user:alice", Kind: LockoutKindUser, Subject: "alice", FailedAttempts: 5, LockedAt: now, LockedUntil: now.Add(time.Hour)})
+
+	l := NewLoginLockout(LoginLockoutOptions{}, store, rec)
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.List(); len(got) != 1 || got[0].Subject != "alice" {
+		t.Fatalf("expected restored lockout, got %+v", got)
+	}
+
+	if _, found, _ := l.Clear("user:bob", "admin"); found {
+		t.Fatal("clearing a missing lockout should report not found")
+	}
+	lock, found, err := l.Clear("user:alice", "admin")
+	if err != nil || !found || lock.Subject != "alice" {
+		t.Fatalf("clear = %+v, %v, %v", lock, found, err)
+	}
+	if l.Locked("alice", "") != nil || len(store.saved) != 0 {
+		t.Fatal("expected lockout lifted and removed from the store")
+	}
+	events := rec.list()
+	if len(events) != 1 || events[0].Type != audit.EventLoginLockoutCleared || events[0].Actor != "admin" {
+		t.Fatalf("expected lockout cleared audit event by admin, got %+v", events)
+	}
+}
+
+func TestHandleLoginLocksAfterRepeatedFailures(t *testing.T) {
+	rec := &recordedEvents{}
+	auditor := NewAuthnAuditor(rec)
+	lockout := NewLoginLockout(LoginLockoutOptions{MaxAttempts: 2, Duration: 5 * time.Minute}, nil, rec)
+	userAuth := &stubUserAuthenticator{err: errors.New("invalid credentials")}
+	h := HandleLoginWithAudit(userAuth, &stubSessionCreator{token: "tok"}, auditor, lockout)
+
+	post := func(password string) *httptest.ResponseRecorder {
+		form := url.Values{"username": {"alice"}, "password": {password}}
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = "192.0.2.7:40000"
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("wrong"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("first failure: expected 401, got %d", w.Code)
+	}
+	w := post("wrong")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second failure: expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" || !strings.Contains(w.Body.String(), "Too many failed login attempts") {
+		t.Fatalf("expected lockout message and Retry-After, got headers=%v body=%s", w.Header(), w.Body.String())
+	}
+
+	// The correct password is not checked while locked.
+	userAuth.err = nil
+	userAuth.user = &UserInfo{ID: "u1", Username: "alice"}
+	userAuth.password = ""
+	if w := post("right"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("locked login: expected 429, got %d", w.Code)
+	}
+	if userAuth.password != "" {
+		t.Fatal("credentials were checked during lockout")
+	}
+
+	var lockouts, refused int
+	for _, evt := range rec.list() {
+		switch evt.Type {
+		case audit.EventLoginLockout:
+			lockouts++
+		case audit.EventAuthnFailure:
+			if detail, _ := evt.Detail.(map[string]any); detail["reason"] == "locked out" {
+				refused++
+			}
+		}
+	}
+	if lockouts != 1 || refused != 1 {
+		t.Fatalf("expected 1 lockout and 1 refused event, got %d and %d", lockouts, refused)
+	}
+	if got := auditor.FailedAttempts(AuthnMethodLocal, "alice"); got != 0 {
+		t.Fatalf("failures should reset when the lockout is set, got %d", got)
+	}
+}
+
+func TestHandleLoginLocksTargetWhenFailureMapIsFull(t *testing.T) {
+	auditor := NewAuthnAuditor(nil)
+	lockout := NewLoginLockout(LoginLockoutOptions{MaxAttempts: 3, Duration: 5 * time.Minute}, nil, nil)
+	h := HandleLoginWithAudit(&stubUserAuthenticator{err: errors.New("invalid credentials")}, &stubSessionCreator{token: "tok"}, auditor, lockout)
+
+	// A flood of made-up usernames from many addresses fills the failure map.
+	for i := 0; len(auditor.failures) < maxAuthnTracked; i++ {
+		auditor.Failed(AuthnAttempt{Method: AuthnMethodLocal, Identity: fmt.Sprintf("flood-%d", i), SourceIP: fmt.Sprintf("198.51.%d.%d", i/256, i%256)})
+	}
+
+	post := func() *httptest.ResponseRecorder {
+		form := url.Values{"username": {"alice"}, "password": {"wrong"}}
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = "192.0.2.7:40000"
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	for i := 1; i < 3; i++ {
+		if w := post(); w.Code != http.StatusUnauthorized {
+			t.Fatalf("failure %d: expected 401, got %d", i, w.Code)
+		}
+	}
+	if w := post(); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third failure: expected 429 with a full failure map, got %d", w.Code)
+	}
+	if lock := lockout.Locked("alice", ""); lock == nil {
+		t.Fatal("expected alice to be locked out")
+	}
+}
+
+// slowFailingAuthenticator rejects every password after a pause, counting
+// the checks it made.
+type slowFailingAuthenticator struct {
+	checks atomic.Int32
+}
+
+func (s *slowFailingAuthenticator) Authenticate(string, string) (*UserInfo, error) {
+	s.checks.Add(1)
+	time.Sleep(5 * time.Millisecond)
+	return nil, errors.New("invalid credentials")
+}
+
+func TestHandleLoginConcurrentFailuresStopAtMaxAttempts(t *testing.T) {
+	auditor := NewAuthnAuditor(nil)
+	lockout := NewLoginLockout(LoginLockoutOptions{MaxAttempts: 3, Duration: 5 * time.Minute}, nil, nil)
+	userAuth := &slowFailingAuthenticator{}
+	h := HandleLoginWithAudit(userAuth, &stubSessionCreator{token: "tok"}, auditor, lockout)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			form := url.Values{"username": {"alice"}, "password": {"wrong"}}
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.RemoteAddr = fmt.Sprintf("192.0.2.%d:40000", i+1)
+			h.ServeHTTP(httptest.NewRecorder(), req)
+		}(i)
+	}
+	wg.Wait()
+
+	if got := userAuth.checks.Load(); got != 3 {
+		t.Fatalf("expected credentials checked 3 times before the lockout, got %d", got)
+	}
+	if lock := lockout.Locked("alice", ""); lock == nil {
+		t.Fatal("expected alice to be locked out")
+	}
+}
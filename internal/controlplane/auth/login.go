@@ -2,10 +2,12 @@ package auth
 
 import (
 	"encoding/json"
+	"fmt"
 	"html/template"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -48,11 +50,13 @@ func HandleLoginPage(templateDir string, opts ...LoginPageOptions) http.HandlerF
 
 // HandleLogin processes a username/password login form (no audit).
 func HandleLogin(userAuth UserAuthenticator, sessionCreator SessionCreator, opts ...LoginPageOptions) http.HandlerFunc {
-	return HandleLoginWithAudit(userAuth, sessionCreator, nil, opts...)
+	return HandleLoginWithAudit(userAuth, sessionCreator, nil, nil, opts...)
 }
 
-// HandleLoginWithAudit processes a login form and records audit events.
-func HandleLoginWithAudit(userAuth UserAuthenticator, sessionCreator SessionCreator, auditor LoginAuditRecorder, opts ...LoginPageOptions) http.HandlerFunc {
+// HandleLoginWithAudit processes a login form and records an authn audit
+// event for every checked credential. With a lockout, usernames and source
+// IPs that fail too often are refused with 429 until the lockout expires.
+func HandleLoginWithAudit(userAuth UserAuthenticator, sessionCreator SessionCreator, auditor *AuthnAuditor, lockout *LoginLockout, opts ...LoginPageOptions) http.HandlerFunc {
 	options := resolveLoginOptions(opts...)
 	return func(w http.ResponseWriter, r *http.Request) {
 		if userAuth == nil || sessionCreator == nil {
@@ -85,20 +89,46 @@ func HandleLoginWithAudit(userAuth UserAuthenticator, sessionCreator SessionCrea
 			return
 		}
 
+		sourceIP := SourceIP(r)
+		release := lockout.Guard(username, sourceIP)
+		defer release()
+		if lock := lockout.Locked(username, sourceIP); lock != nil {
+			auditor.Refused(AuthnAttempt{
+				Method:   AuthnMethodLocal,
+				Identity: username,
+				SourceIP: sourceIP,
+				Reason:   "locked out",
+				Detail:   map[string]any{"remote_addr": r.RemoteAddr, "lockout": lock.Key},
+			})
+			renderLockedOut(w, templateDir, username, *lock, options)
+			return
+		}
+
 		user, err := userAuth.Authenticate(username, password)
 		if err != nil || user == nil {
 			errMsg := "Invalid username or password"
 			if err != nil && strings.TrimSpace(err.Error()) != "" {
 				errMsg = err.Error()
 			}
-			if auditor != nil {
-				auditor.Record(audit.Event{
-					Timestamp: time.Now().UTC(),
-					Type:      audit.EventLoginFailed,
-					Actor:     username,
-					Summary:   "Login failed for " + username + " (local)",
-					Detail:    map[string]string{"method": "local", "remote_addr": r.RemoteAddr},
-				})
+			failures := auditor.Failed(AuthnAttempt{
+				Method:   AuthnMethodLocal,
+				Identity: username,
+				SourceIP: sourceIP,
+				Reason:   "invalid credentials",
+				Detail:   map[string]any{"remote_addr": r.RemoteAddr},
+			})
+			locks := lockout.RecordFailure(username, sourceIP, failures, auditor.FailedAttemptsFrom(AuthnMethodLocal, sourceIP))
+			for _, lock := range locks {
+				// A lifted or expired lockout starts a fresh count.
+				if lock.Kind == LockoutKindUser {
+					auditor.ClearFailures(AuthnMethodLocal, username, "")
+				} else {
+					auditor.ClearFailures(AuthnMethodLocal, "", sourceIP)
+				}
+			}
+			if len(locks) > 0 {
+				renderLockedOut(w, templateDir, username, locks[0], options)
+				return
 			}
 			renderLoginPage(w, templateDir, LoginPageData{
 				Title:            "Legator Login",
@@ -122,15 +152,13 @@ func HandleLoginWithAudit(userAuth UserAuthenticator, sessionCreator SessionCrea
 			return
 		}
 
-		if auditor != nil {
-			auditor.Record(audit.Event{
-				Timestamp: time.Now().UTC(),
-				Type:      audit.EventLoginSuccess,
-				Actor:     user.ID,
-				Summary:   "Login succeeded for " + username + " (local)",
-				Detail:    map[string]string{"method": "local", "user_id": user.ID, "username": username, "remote_addr": r.RemoteAddr},
-			})
-		}
+		auditor.Succeeded(AuthnAttempt{
+			Method:   AuthnMethodLocal,
+			Identity: username,
+			Actor:    user.ID,
+			SourceIP: sourceIP,
+			Detail:   map[string]any{"user_id": user.ID, "username": username, "remote_addr": r.RemoteAddr},
+		})
 
 		http.SetCookie(w, &http.Cookie{
 			Name:     SessionCookieName,
@@ -192,6 +220,28 @@ func HandleMe() http.HandlerFunc {
 	}
 }
 
+// renderLockedOut answers a login refused under lock with 429 and a
+// Retry-After header.
+func renderLockedOut(w http.ResponseWriter, templateDir, username string, lock Lockout, options LoginPageOptions) {
+	wait := time.Until(lock.LockedUntil)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	minutes := int((wait + time.Minute - 1) / time.Minute)
+	unit := "minutes"
+	if minutes == 1 {
+		unit = "minute"
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+	renderLoginPage(w, templateDir, LoginPageData{
+		Title:            "Legator Login",
+		Username:         username,
+		Error:            fmt.Sprintf("Too many failed login attempts. Login is locked; try again in %d %s or ask an administrator to clear the lockout.", minutes, unit),
+		OIDCEnabled:      options.OIDCEnabled,
+		OIDCProviderName: options.OIDCProviderName,
+	}, http.StatusTooManyRequests)
+}
+
 func renderLoginPage(w http.ResponseWriter, templateDir string, data LoginPageData, status int) {
 	tmplPath := filepath.Join(templateDir, "login.html")
 	tmpl, err := template.ParseFiles(tmplPath)
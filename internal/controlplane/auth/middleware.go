@@ -82,6 +82,7 @@ type AuthMiddleware struct {
 
 	sessionValidator   SessionValidator
 	permissionResolver UserPermissionResolver
+	authn              *AuthnAuditor
 }
 
 // NewMiddleware builds auth middleware with optional skip paths.
@@ -109,6 +110,12 @@ func (m *AuthMiddleware) SetSessionAuth(validator SessionValidator, resolver Use
 	m.permissionResolver = resolver
 }
 
+// SetAuthnAuditor records API key authentication outcomes. Successes and
+// failures are sampled, since the key is sent on every request.
+func (m *AuthMiddleware) SetAuthnAuditor(auditor *AuthnAuditor) {
+	m.authn = auditor
+}
+
 // Wrap returns the wrapped HTTP handler.
 func (m *AuthMiddleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -193,13 +200,27 @@ func (m *AuthMiddleware) tryAPIKeyAuth(w http.ResponseWriter, r *http.Request, n
 		return false
 	}
 
+	attempt := AuthnAttempt{
+		Method:   AuthnMethodAPIKey,
+		SourceIP: SourceIP(r),
+		Detail:   map[string]any{"path": r.URL.Path},
+	}
+	if len(token) >= 12 {
+		// The prefix is stored in clear to look the key up; the rest is secret.
+		attempt.Identity = token[:12]
+	}
+
 	if m.store == nil {
+		attempt.Reason = "api keys not configured"
+		m.authn.FailedSampled(attempt)
 		http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
 		return true
 	}
 
 	key, err := m.store.Validate(token)
 	if err != nil {
+		attempt.Reason = err.Error()
+		m.authn.FailedSampled(attempt)
 		if strings.Contains(err.Error(), "expired") {
 			http.Error(w, `{"error":"api key expired"}`, http.StatusForbidden)
 			return true
@@ -207,6 +228,9 @@ func (m *AuthMiddleware) tryAPIKeyAuth(w http.ResponseWriter, r *http.Request, n
 		http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
 		return true
 	}
+	attempt.Actor = key.Name
+	attempt.Detail["key_id"] = key.ID
+	m.authn.SucceededSampled(attempt)
 
 	ctx := WithAPIKeyContext(r.Context(), key)
 	ctx = WithWorkspaceScopeContext(ctx, resolveWorkspaceScopeFromAuth(ctx))
@@ -36,6 +36,7 @@ type ProbeAuthenticator struct {
 	certVerifier  *ProbeClientCertVerifier
 	certRegistry  *ProbeCertificateRegistry
 	verifyAPIKey  func(probeID, token string) bool
+	verifyToken   func(probeID, token string) error
 	now           func() time.Time
 	allowFallback bool
 }
@@ -56,6 +57,7 @@ type ProbeAuthenticatorConfig struct {
 	CertVerifier     *ProbeClientCertVerifier
 	CertRegistry     *ProbeCertificateRegistry
 	VerifyAPIKey     func(probeID, token string) bool
+	VerifyToken      func(probeID, token string) error // nil: bearer credentials are API keys only
 	AllowAPIFallback bool
 }
 
@@ -73,6 +75,7 @@ func NewProbeAuthenticator(cfg ProbeAuthenticatorConfig) *ProbeAuthenticator {
 		certVerifier:  cfg.CertVerifier,
 		certRegistry:  cfg.CertRegistry,
 		verifyAPIKey:  cfg.VerifyAPIKey,
+		verifyToken:   cfg.VerifyToken,
 		now:           func() time.Time { return time.Now().UTC() },
 		allowFallback: allowFallback,
 	}
@@ -130,6 +133,18 @@ func (a *ProbeAuthenticator) Authenticate(probeID, bearerToken string, tlsState
 	if strings.TrimSpace(bearerToken) == "" {
 		return ProbeAuthOutcome{Allowed: false, StatusCode: 401, Method: ProbeAuthMethodAPIKey, Reason: "missing_authorization", Message: "missing authorization"}
 	}
+	if a.verifyToken != nil && IsProbeToken(bearerToken) {
+		switch err := a.verifyToken(probeID, bearerToken); {
+		case err == nil:
+			return ProbeAuthOutcome{Allowed: true, Method: ProbeAuthMethodJWT, Reason: "ok", Message: "probe authenticated with token"}
+		case errors.Is(err, ErrProbeTokenExpired):
+			return ProbeAuthOutcome{Allowed: false, StatusCode: 401, Method: ProbeAuthMethodJWT, Reason: "token_expired", Message: "probe token expired"}
+		case errors.Is(err, ErrProbeTokenRevoked):
+			return ProbeAuthOutcome{Allowed: false, StatusCode: 401, Method: ProbeAuthMethodJWT, Reason: "token_revoked", Message: "probe token was revoked by key rotation"}
+		default:
+			return ProbeAuthOutcome{Allowed: false, StatusCode: 403, Method: ProbeAuthMethodJWT, Reason: "invalid_token", Message: "invalid credentials"}
+		}
+	}
 	if a.verifyAPIKey == nil || !a.verifyAPIKey(probeID, bearerToken) {
 		return ProbeAuthOutcome{Allowed: false, StatusCode: 403, Method: ProbeAuthMethodAPIKey, Reason: "invalid_api_key", Message: "invalid credentials"}
 	}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ProbeAuthMethodJWT = "jwt"
+
+	// DefaultProbeTokenTTL is how long an exchanged probe WebSocket token is
+	// valid when no TTL is configured.
+	DefaultProbeTokenTTL = 15 * time.Minute
+
+	probeTokenIssuer   = "legator"
+	probeTokenAudience = "legator-probe-ws"
+)
+
+var (
+	ErrProbeTokenInvalid = errors.New("probe token is invalid")
+	ErrProbeTokenExpired = errors.New("probe token is expired")
+	ErrProbeTokenRevoked = errors.New("probe token was issued for a rotated key")
+)
+
+// ProbeTokenClaims are the JWT claims of a probe WebSocket token. KeyFP binds
+// the token to the API key it was exchanged for, so rotating the key revokes
+// every token issued before the rotation.
+type ProbeTokenClaims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Audience  string `json:"aud"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	ID        string `json:"jti"`
+	KeyFP     string `json:"kfp"`
+}
+
+// ProbeTokenSigner issues and verifies short-lived HS256 JWTs that probes use
+// instead of their long-lived API key on the WebSocket handshake.
+type ProbeTokenSigner struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewProbeTokenSigner derives the token key from the control-plane signing
+// key. A non-positive ttl uses DefaultProbeTokenTTL.
+func NewProbeTokenSigner(signingKey []byte, ttl time.Duration) *ProbeTokenSigner {
+	if ttl <= 0 {
+		ttl = DefaultProbeTokenTTL
+	}
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte("legator probe websocket token"))
+	return &ProbeTokenSigner{
+		key: mac.Sum(nil),
+		ttl: ttl,
+		now: func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// TTL returns the lifetime of issued tokens.
+func (s *ProbeTokenSigner) TTL() time.Duration {
+	return s.ttl
+}
+
+// Issue mints a token for probeID bound to the API key it was exchanged for.
+func (s *ProbeTokenSigner) Issue(probeID, apiKey string) (string, time.Time, error) {
+	now := s.now()
+	expires := now.Add(s.ttl)
+	claims := ProbeTokenClaims{
+		Issuer:    probeTokenIssuer,
+		Subject:   probeID,
+		Audience:  probeTokenAudience,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expires.Unix(),
+		ID:        uuid.NewString(),
+		KeyFP:     probeKeyFingerprint(apiKey),
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(s.sign(signingInput)), expires, nil
+}
+
+// Verify checks the token's signature, audience, subject and expiry, and that
+// it was issued for currentKey, the probe's current API key.
+func (s *ProbeTokenSigner) Verify(token, probeID, currentKey string) (ProbeTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ProbeTokenClaims{}, ErrProbeTokenInvalid
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	headerRaw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(headerRaw, &header) != nil || header.Alg != "HS256" {
+		return ProbeTokenClaims{}, ErrProbeTokenInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, s.sign(parts[0]+"."+parts[1])) {
+		return ProbeTokenClaims{}, ErrProbeTokenInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ProbeTokenClaims{}, ErrProbeTokenInvalid
+	}
+	var claims ProbeTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ProbeTokenClaims{}, ErrProbeTokenInvalid
+	}
+	if claims.Issuer != probeTokenIssuer || claims.Audience != probeTokenAudience || claims.Subject != probeID {
+		return ProbeTokenClaims{}, ErrProbeTokenInvalid
+	}
+	if s.now().Unix() >= claims.ExpiresAt {
+		return ProbeTokenClaims{}, ErrProbeTokenExpired
+	}
+	if currentKey == "" || !hmac.Equal([]byte(claims.KeyFP), []byte(probeKeyFingerprint(currentKey))) {
+		return ProbeTokenClaims{}, ErrProbeTokenRevoked
+	}
+	return claims, nil
+}
+
+func (s *ProbeTokenSigner) sign(signingInput string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+// IsProbeToken reports whether a bearer credential is a JWT rather than a
+// static probe API key.
+func IsProbeToken(bearer string) bool {
+	return strings.HasPrefix(bearer, "eyJ") && strings.Count(bearer, ".") == 2
+}
+
+func probeKeyFingerprint(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProbeTokenSignerIssueVerify(t *testing.T) {
+	signer := NewProbeTokenSigner([]byte(strings.Repeat("k", 32)), time.Minute)
+	token, expires, err := signer.Issue("probe-1", "lgk_current")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if !IsProbeToken(token) || IsProbeToken("lgk_current") {
+		t.Fatalf("IsProbeToken misclassified credentials")
+	}
+	if time.Until(expires) > time.Minute || time.Until(expires) < 50*time.Second {
+		t.Fatalf("unexpected expiry %s", expires)
+	}
+
+	claims, err := signer.Verify(token, "probe-1", "lgk_current")
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if claims.Subject != "probe-1" || claims.ID == "" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+
+	if _, err := signer.Verify(token, "probe-2", "lgk_current"); !errors.Is(err, ErrProbeTokenInvalid) {
+		t.Fatalf("expected invalid for another probe, got %v", err)
+	}
+	if _, err := signer.Verify(token, "probe-1", "lgk_rotated"); !errors.Is(err, ErrProbeTokenRevoked) {
+		t.Fatalf("expected rotation to revoke the token, got %v", err)
+	}
+	tampered := token[:len(token)-2] + "xx"
+	if _, err := signer.Verify(tampered, "probe-1", "lgk_current"); !errors.Is(err, ErrProbeTokenInvalid) {
+		t.Fatalf("expected invalid signature, got %v", err)
+	}
+	other := NewProbeTokenSigner([]byte(strings.Repeat("o", 32)), time.Minute)
+	if _, err := other.Verify(token, "probe-1", "lgk_current"); !errors.Is(err, ErrProbeTokenInvalid) {
+		t.Fatalf("expected token from another signing key to be rejected, got %v", err)
+	}
+
+	signer.now = func() time.Time { return time.Now().UTC().Add(2 * time.Minute) }
+	if _, err := signer.Verify(token, "probe-1", "lgk_current"); !errors.Is(err, ErrProbeTokenExpired) {
+		t.Fatalf("expected expired, got %v", err)
+	}
+}
+
+func TestProbeAuthenticatorAcceptsTokens(t *testing.T) {
+	signer := NewProbeTokenSigner([]byte(strings.Repeat("k", 32)), time.Minute)
+	a := NewProbeAuthenticator(ProbeAuthenticatorConfig{
+		Mode:         ProbeAuthModeOff,
+		VerifyAPIKey: func(_, token string) bool { return token == "lgk_current" },
+		VerifyToken: func(probeID, token string) error {
+			_, err := signer.Verify(token, probeID, "lgk_current")
+			return err
+		},
+	})
+
+	token, _, _ := signer.Issue("probe-1", "lgk_current")
+	if out := a.Authenticate("probe-1", token, nil); !out.Allowed || out.Method != ProbeAuthMethodJWT {
+		t.Fatalf("expected token auth, got %+v", out)
+	}
+	if out := a.Authenticate("probe-1", "lgk_current", nil); !out.Allowed || out.Method != ProbeAuthMethodAPIKey {
+		t.Fatalf("expected static key auth to keep working, got %+v", out)
+	}
+	stale, _, _ := signer.Issue("probe-1", "lgk_old")
+	if out := a.Authenticate("probe-1", stale, nil); out.Allowed || out.Reason != "token_revoked" || out.StatusCode != 401 {
+		t.Fatalf("expected revoked token rejection, got %+v", out)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/marcus-qen/legator/internal/shared/ratelimit"
 )
 
 // RateLimiter provides per-key request rate limiting using a sliding window.
@@ -65,6 +67,31 @@ func (rl *RateLimiter) Remaining(keyID string) int {
 	return rem
 }
 
+// KeyRateLimiter is a token-bucket limiter keyed by API key ID, built on the
+// shared ratelimit package. Each bucket holds up to perMinute tokens and
+// refills continuously at perMinute tokens per minute, so a key may burst its
+// full minute allowance and is then paced.
+type KeyRateLimiter struct {
+	buckets *ratelimit.TokenBuckets
+	now     func() time.Time
+}
+
+// KeyRateDecision is the outcome of a KeyRateLimiter check.
+type KeyRateDecision = ratelimit.BucketDecision
+
+// NewKeyRateLimiter creates an empty per-key token-bucket limiter.
+func NewKeyRateLimiter() *KeyRateLimiter {
+	l := &KeyRateLimiter{now: time.Now}
+	l.buckets = ratelimit.NewTokenBuckets(func() time.Time { return l.now() })
+	return l
+}
+
+// Allow takes a token from keyID's bucket. perMinute <= 0 disables limiting
+// for the call. Changing perMinute for a key resets its bucket to the new size.
+func (l *KeyRateLimiter) Allow(keyID string, perMinute int) KeyRateDecision {
+	return l.buckets.Take(keyID, perMinute, time.Minute)
+}
+
 // RateLimitMiddleware wraps the auth middleware with rate limiting.
 // Requires auth middleware to run first (key must be in context).
 func RateLimitMiddleware(rl *RateLimiter) func(http.Handler) http.Handler {
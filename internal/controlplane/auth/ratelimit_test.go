@@ -70,3 +70,40 @@ func TestRateLimiterRemaining(t *testing.T) {
 		t.Fatalf("expected 3, got %d", rl.Remaining("key1"))
 	}
 }
+
+func TestKeyRateLimiterTokenBucket(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl := NewKeyRateLimiter()
+	rl.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if d := rl.Allow("key1", 3); !d.Allowed {
+			t.Fatalf("request %d should be allowed within burst", i+1)
+		}
+	}
+	d := rl.Allow("key1", 3)
+	if d.Allowed || !d.FirstDenial {
+		t.Fatalf("expected first denial after burst, got %+v", d)
+	}
+	if d.RetryAfter <= 0 || d.RetryAfter > 20*time.Second {
+		t.Fatalf("expected retry-after of one refill interval, got %s", d.RetryAfter)
+	}
+	if d := rl.Allow("key1", 3); d.Allowed || d.FirstDenial {
+		t.Fatalf("expected repeat denial without FirstDenial, got %+v", d)
+	}
+	if d := rl.Allow("key2", 3); !d.Allowed {
+		t.Fatal("other keys should have their own bucket")
+	}
+
+	now = now.Add(20 * time.Second)
+	if d := rl.Allow("key1", 3); !d.Allowed {
+		t.Fatal("expected one token after refill interval")
+	}
+	if d := rl.Allow("key1", 3); d.Allowed || !d.FirstDenial {
+		t.Fatalf("expected a new throttling episode, got %+v", d)
+	}
+
+	if d := rl.Allow("key1", 0); !d.Allowed {
+		t.Fatal("zero limit should disable limiting")
+	}
+}
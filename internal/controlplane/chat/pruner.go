@@ -41,3 +41,26 @@ func (s *Store) startPruner(interval, maxAge time.Duration) {
 		}
 	}()
 }
+
+// trimProbe deletes all but the newest maxMessages rows for probeID from
+// SQLite and the in-memory session. Rows are ordered by rowid (insertion
+// order) since RFC3339Nano strings do not sort reliably. It returns the
+// number of rows deleted.
+func (s *Store) trimProbe(probeID string) (int, error) {
+	if s.maxMessages <= 0 {
+		return 0, nil
+	}
+
+	result, err := s.db.Exec(`DELETE FROM chat_messages
+		WHERE probe_id = ? AND id NOT IN (
+			SELECT id FROM chat_messages WHERE probe_id = ?
+			ORDER BY rowid DESC LIMIT ?
+		)`, probeID, probeID, s.maxMessages)
+	if err != nil {
+		return 0, err
+	}
+
+	n, _ := result.RowsAffected()
+	s.mgr.trimTo(probeID, s.maxMessages)
+	return int(n), nil
+}
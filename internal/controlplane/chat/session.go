@@ -229,6 +229,33 @@ func (m *Manager) PruneOlderThan(cutoff time.Time) {
 	}
 }
 
+// trimTo keeps only the newest max messages in probeID's session.
+func (m *Manager) trimTo(probeID string, max int) {
+	m.mu.RLock()
+	sess, ok := m.sessions[probeID]
+	m.mu.RUnlock()
+	if !ok || sess == nil || max <= 0 {
+		return
+	}
+
+	sess.mu.Lock()
+	if over := len(sess.Messages) - max; over > 0 {
+		sess.Messages = append([]Message(nil), sess.Messages[over:]...)
+	}
+	sess.mu.Unlock()
+}
+
+// probeIDs returns the IDs of all known sessions.
+func (m *Manager) probeIDs() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]string, 0, len(m.sessions))
+	for id := range m.sessions {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 // SetResponder sets the function used to generate assistant replies.
 func (m *Manager) SetResponder(fn ResponderFunc) {
 	m.mu.Lock()
@@ -15,16 +15,43 @@ import (
 	_ "modernc.org/sqlite"
 )
 
+const (
+	defaultMaxMessagesPerProbe = 500
+	defaultRetention           = 24 * time.Hour
+)
+
+// StoreOptions bounds persisted chat history. Zero values use defaults.
+type StoreOptions struct {
+	// MaxMessagesPerProbe caps each thread (probe or fleet); oldest first out.
+	MaxMessagesPerProbe int
+	// Retention purges messages older than this age.
+	Retention time.Duration
+}
+
 // Store provides persistent chat backed by SQLite.
 // Wraps the in-memory Manager — reads from memory, writes to both.
 type Store struct {
-	db   *sql.DB
-	mgr  *Manager
-	done chan struct{}
+	db          *sql.DB
+	mgr         *Manager
+	done        chan struct{}
+	maxMessages int
 }
 
-// NewStore opens (or creates) a SQLite-backed chat store.
+// NewStore opens (or creates) a SQLite-backed chat store with default limits.
 func NewStore(dbPath string, logger *zap.Logger) (*Store, error) {
+	return NewStoreWithOptions(dbPath, logger, StoreOptions{})
+}
+
+// NewStoreWithOptions opens (or creates) a SQLite-backed chat store with the
+// given history limits.
+func NewStoreWithOptions(dbPath string, logger *zap.Logger, opts StoreOptions) (*Store, error) {
+	if opts.MaxMessagesPerProbe <= 0 {
+		opts.MaxMessagesPerProbe = defaultMaxMessagesPerProbe
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = defaultRetention
+	}
+
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
 		return nil, err
@@ -54,8 +81,12 @@ func NewStore(dbPath string, logger *zap.Logger) (*Store, error) {
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_chat_probe ON chat_messages(probe_id)`)
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_chat_ts ON chat_messages(timestamp)`)
 
-	s := &Store{db: db, mgr: NewManager(logger), done: make(chan struct{})}
+	s := &Store{db: db, mgr: NewManager(logger), done: make(chan struct{}), maxMessages: opts.MaxMessagesPerProbe}
 
+	if _, err := s.PruneOlderThan(opts.Retention); err != nil {
+		db.Close()
+		return nil, err
+	}
 	if err := s.loadAll(); err != nil {
 		db.Close()
 		return nil, err
@@ -66,7 +97,7 @@ func NewStore(dbPath string, logger *zap.Logger) (*Store, error) {
 		return nil, fmt.Errorf("ensure schema version: %w", err)
 	}
 
-	s.startPruner(1*time.Hour, 24*time.Hour)
+	s.startPruner(1*time.Hour, opts.Retention)
 	return s, nil
 }
 
@@ -94,11 +125,14 @@ func (s *Store) ClearMessages(probeID string) error {
 
 // ── Mutations (memory + disk) ───────────────────────────────
 
-// AddMessage appends a message and persists it.
+// AddMessage appends a message and persists it, trimming the thread to the
+// per-probe cap.
 func (s *Store) AddMessage(probeID, role, content string) *Message {
 	msg := s.mgr.AddMessage(probeID, role, content)
 	if msg != nil {
-		_ = s.persist(probeID, msg)
+		if err := s.persist(probeID, msg); err == nil {
+			_, _ = s.trimProbe(probeID)
+		}
 	}
 	return msg
 }
@@ -135,7 +169,7 @@ func (s *Store) persist(probeID string, msg *Message) error {
 }
 
 func (s *Store) loadAll() error {
-	rows, err := s.db.Query(`SELECT id, probe_id, role, content, command_id, timestamp FROM chat_messages ORDER BY timestamp ASC`)
+	rows, err := s.db.Query(`SELECT id, probe_id, role, content, command_id, timestamp FROM chat_messages ORDER BY rowid ASC`)
 	if err != nil {
 		return err
 	}
@@ -166,8 +200,17 @@ func (s *Store) loadAll() error {
 		sess.UpdatedAt = msg.Timestamp
 		sess.mu.Unlock()
 	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
-	return rows.Err()
+	// Enforce the cap on history written before it was configured (or lowered).
+	for _, probeID := range s.mgr.probeIDs() {
+		if _, err := s.trimProbe(probeID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // MessageCount returns the total persisted message count.
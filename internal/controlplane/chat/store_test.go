@@ -145,3 +145,70 @@ func TestChatStoreSubscription(t *testing.T) {
 		t.Fatal("expected message on subscription channel")
 	}
 }
+
+func TestChatStoreCapsMessagesPerProbe(t *testing.T) {
+	dbPath := chatTempDB(t)
+
+	s1, err := NewStoreWithOptions(dbPath, chatLogger(), StoreOptions{MaxMessagesPerProbe: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"m1", "m2", "m3", "m4", "m5"} {
+		s1.AddMessage("probe-1", "user", content)
+	}
+	s1.AddMessage("probe-2", "user", "other")
+
+	msgs := s1.GetMessages("probe-1", 0)
+	if len(msgs) != 3 || msgs[0].Content != "m3" || msgs[2].Content != "m5" {
+		t.Fatalf("expected newest 3 messages in memory, got %+v", msgs)
+	}
+	if s1.MessageCount() != 4 {
+		t.Fatalf("expected 4 persisted messages, got %d", s1.MessageCount())
+	}
+	s1.Close()
+
+	// Reopening with a lower cap trims existing history on load.
+	s2, err := NewStoreWithOptions(dbPath, chatLogger(), StoreOptions{MaxMessagesPerProbe: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+
+	msgs = s2.GetMessages("probe-1", 0)
+	if len(msgs) != 2 || msgs[0].Content != "m4" || msgs[1].Content != "m5" {
+		t.Fatalf("expected newest 2 messages after reopen, got %+v", msgs)
+	}
+	if s2.MessageCount() != 3 {
+		t.Fatalf("expected 3 persisted messages after reopen, got %d", s2.MessageCount())
+	}
+}
+
+func TestChatStoreFleetHistoryReachesResponderAfterRestart(t *testing.T) {
+	dbPath := chatTempDB(t)
+
+	s1, err := NewStore(dbPath, chatLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1.AddMessage("fleet", "user", "which probes are degraded?")
+	s1.AddMessage("fleet", "assistant", "web-2 is degraded")
+	s1.Close()
+
+	s2, err := NewStore(dbPath, chatLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+
+	var got []Message
+	s2.SetResponder(func(probeID, userMessage string, history []Message) (string, error) {
+		got = history
+		return "ok", nil
+	})
+	s2.AddMessage("fleet", "user", "why?")
+	_ = s2.mgr.respond("fleet", "why?")
+
+	if len(got) != 3 || got[0].Content != "which probes are degraded?" || got[1].Content != "web-2 is degraded" {
+		t.Fatalf("expected persisted fleet history passed to responder, got %+v", got)
+	}
+}
@@ -50,6 +50,34 @@ func NewCLIAdapterWithRunner(runner CommandRunner) *CLIAdapter {
 	return &CLIAdapter{runner: runner}
 }
 
+// RoutingScanner sends AWS connectors using API auth (access_keys, iam_role)
+// to the AWS API adapter and everything else to the CLI adapter.
+type RoutingScanner struct {
+	CLI    Scanner
+	AWSAPI Scanner
+}
+
+// NewDefaultScanner returns the production scanner set.
+func NewDefaultScanner() *RoutingScanner {
+	return &RoutingScanner{CLI: NewCLIAdapter(), AWSAPI: NewAWSAPIAdapter(AWSAPIOptions{})}
+}
+
+func (r *RoutingScanner) Scan(ctx context.Context, connector Connector) ([]Asset, error) {
+	if normalizeProvider(connector.Provider) == ProviderAWS && usesAWSAPI(connector.AuthMode) {
+		return r.AWSAPI.Scan(ctx, connector)
+	}
+	return r.CLI.Scan(ctx, connector)
+}
+
+func usesAWSAPI(authMode string) bool {
+	switch normalizeAuthMode(authMode) {
+	case AuthModeAccessKeys, AuthModeIAMRole:
+		return true
+	default:
+		return false
+	}
+}
+
 func (a *CLIAdapter) Scan(ctx context.Context, connector Connector) ([]Asset, error) {
 	provider := normalizeProvider(connector.Provider)
 	connector.Provider = provider
@@ -0,0 +1,695 @@
+package cloudconnectors
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ec2APIVersion = "2016-11-15"
+	stsAPIVersion = "2011-06-15"
+
+	defaultAWSRegion      = "us-east-1"
+	defaultIMDSEndpoint   = "http://169.254.169.254"
+	ec2PageSize           = 100
+	ssmPageSize           = 50
+	maxAWSPages           = 1000
+	defaultAWSMaxRetries  = 5
+	defaultAWSBaseBackoff = 250 * time.Millisecond
+	maxAWSBackoff         = 10 * time.Second
+)
+
+// AWSAPIOptions configures an AWSAPIAdapter. Zero values use production defaults.
+type AWSAPIOptions struct {
+	HTTPClient *http.Client
+	// Endpoint returns the base URL for a service in a region. Tests point
+	// this at a mock server.
+	Endpoint     func(service, region string) string
+	IMDSEndpoint string
+	Getenv       func(string) string
+	MaxRetries   int
+	BaseBackoff  time.Duration
+	Now          func() time.Time
+}
+
+// AWSAPIAdapter enumerates EC2 (and optionally SSM-managed) instances through
+// the AWS APIs, signing requests with SigV4.
+type AWSAPIAdapter struct {
+	client       *http.Client
+	endpoint     func(service, region string) string
+	imdsEndpoint string
+	getenv       func(string) string
+	maxRetries   int
+	baseBackoff  time.Duration
+	now          func() time.Time
+}
+
+func NewAWSAPIAdapter(opts AWSAPIOptions) *AWSAPIAdapter {
+	a := &AWSAPIAdapter{
+		client:       opts.HTTPClient,
+		endpoint:     opts.Endpoint,
+		imdsEndpoint: strings.TrimRight(opts.IMDSEndpoint, "/"),
+		getenv:       opts.Getenv,
+		maxRetries:   opts.MaxRetries,
+		baseBackoff:  opts.BaseBackoff,
+		now:          opts.Now,
+	}
+	if a.client == nil {
+		a.client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if a.endpoint == nil {
+		a.endpoint = defaultAWSEndpoint
+	}
+	if a.imdsEndpoint == "" {
+		a.imdsEndpoint = defaultIMDSEndpoint
+	}
+	if a.getenv == nil {
+		a.getenv = os.Getenv
+	}
+	if a.maxRetries <= 0 {
+		a.maxRetries = defaultAWSMaxRetries
+	}
+	if a.baseBackoff <= 0 {
+		a.baseBackoff = defaultAWSBaseBackoff
+	}
+	if a.now == nil {
+		a.now = time.Now
+	}
+	return a
+}
+
+func defaultAWSEndpoint(service, region string) string {
+	if service == "sts" {
+		return "https://sts.amazonaws.com"
+	}
+	return fmt.Sprintf("https://%s.%s.amazonaws.com", service, region)
+}
+
+func (a *AWSAPIAdapter) Scan(ctx context.Context, connector Connector) ([]Asset, error) {
+	creds, err := a.resolveCredentials(ctx, connector)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := a.callerIdentity(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	now := a.now().UTC()
+
+	assets := make([]Asset, 0, 32)
+	assets = append(assets, Asset{
+		ConnectorID:  connector.ID,
+		Provider:     ProviderAWS,
+		ScopeID:      identity.Account,
+		Region:       "global",
+		AssetType:    "account",
+		AssetID:      identity.Account,
+		DisplayName:  firstNonEmpty(identity.Arn, identity.Account),
+		Status:       "active",
+		RawJSON:      mustMarshal(identity),
+		DiscoveredAt: now,
+	})
+
+	for _, region := range connectorRegions(connector) {
+		instances, err := a.describeInstances(ctx, creds, region)
+		if err != nil {
+			return nil, err
+		}
+
+		var managed map[string]ssmInstance
+		if connector.IncludeSSM {
+			managed, err = a.describeInstanceInformation(ctx, creds, region)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, instance := range instances {
+			info, isManaged := managed[instance.InstanceID]
+			delete(managed, instance.InstanceID)
+			assets = append(assets, ec2Asset(connector, identity.Account, region, instance, info, isManaged, now))
+		}
+		// Whatever is left is SSM-managed but not an EC2 instance in this
+		// region (hybrid activations, mi-*).
+		for _, id := range sortedKeys(managed) {
+			assets = append(assets, ssmAsset(connector, identity.Account, region, managed[id], now))
+		}
+	}
+
+	return assets, nil
+}
+
+func connectorRegions(connector Connector) []string {
+	regions := make([]string, 0, len(connector.Regions))
+	for _, region := range connector.Regions {
+		if region = strings.TrimSpace(region); region != "" {
+			regions = append(regions, region)
+		}
+	}
+	if len(regions) == 0 {
+		regions = append(regions, defaultAWSRegion)
+	}
+	return regions
+}
+
+func ec2Asset(connector Connector, account, region string, instance ec2Instance, info ssmInstance, managed bool, now time.Time) Asset {
+	tags := map[string]string{
+		"region":        region,
+		"instance_type": instance.InstanceType,
+		"state":         firstNonEmpty(instance.State.Name, "unknown"),
+	}
+	if zone := instance.Placement.AvailabilityZone; zone != "" {
+		tags["availability_zone"] = zone
+	}
+	if instance.PrivateIPAddress != "" {
+		tags["private_ip"] = instance.PrivateIPAddress
+	}
+	if instance.PublicIPAddress != "" {
+		tags["public_ip"] = instance.PublicIPAddress
+	}
+	name := ""
+	for _, tag := range instance.Tags {
+		if strings.EqualFold(tag.Key, "name") {
+			name = tag.Value
+		}
+		tags["tag:"+tag.Key] = tag.Value
+	}
+	if managed {
+		tags["ssm_managed"] = "true"
+		if info.PingStatus != "" {
+			tags["ssm_ping_status"] = info.PingStatus
+		}
+	}
+
+	raw := map[string]any{"instance": instance}
+	if managed {
+		raw["ssm"] = info
+	}
+	return Asset{
+		ConnectorID:  connector.ID,
+		Provider:     ProviderAWS,
+		ScopeID:      account,
+		Region:       region,
+		AssetType:    "instance",
+		AssetID:      instance.InstanceID,
+		DisplayName:  firstNonEmpty(name, instance.InstanceID),
+		Status:       firstNonEmpty(instance.State.Name, "unknown"),
+		RawJSON:      mustMarshal(raw),
+		Tags:         tags,
+		DiscoveredAt: now,
+	}
+}
+
+func ssmAsset(connector Connector, account, region string, info ssmInstance, now time.Time) Asset {
+	tags := map[string]string{
+		"region":      region,
+		"state":       firstNonEmpty(info.PingStatus, "unknown"),
+		"ssm_managed": "true",
+	}
+	if info.PingStatus != "" {
+		tags["ssm_ping_status"] = info.PingStatus
+	}
+	if info.IPAddress != "" {
+		tags["private_ip"] = info.IPAddress
+	}
+	if info.PlatformName != "" {
+		tags["platform"] = info.PlatformName
+	}
+	return Asset{
+		ConnectorID:  connector.ID,
+		Provider:     ProviderAWS,
+		ScopeID:      account,
+		Region:       region,
+		AssetType:    "managed_instance",
+		AssetID:      info.InstanceID,
+		DisplayName:  firstNonEmpty(info.ComputerName, info.InstanceID),
+		Status:       firstNonEmpty(info.PingStatus, "unknown"),
+		RawJSON:      mustMarshal(info),
+		Tags:         tags,
+		DiscoveredAt: now,
+	}
+}
+
+func sortedKeys(m map[string]ssmInstance) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ── API calls ───────────────────────────────────────────────
+
+type stsIdentity struct {
+	Account string `xml:"GetCallerIdentityResult>Account" json:"Account"`
+	Arn     string `xml:"GetCallerIdentityResult>Arn" json:"Arn"`
+	UserID  string `xml:"GetCallerIdentityResult>UserId" json:"UserId"`
+}
+
+type ec2Instance struct {
+	InstanceID   string `xml:"instanceId" json:"InstanceId"`
+	InstanceType string `xml:"instanceType" json:"InstanceType"`
+	State        struct {
+		Name string `xml:"name" json:"Name"`
+	} `xml:"instanceState" json:"State"`
+	Placement struct {
+		AvailabilityZone string `xml:"availabilityZone" json:"AvailabilityZone"`
+	} `xml:"placement" json:"Placement"`
+	PrivateIPAddress string   `xml:"privateIpAddress" json:"PrivateIpAddress,omitempty"`
+	PublicIPAddress  string   `xml:"ipAddress" json:"PublicIpAddress,omitempty"`
+	PrivateDNSName   string   `xml:"privateDnsName" json:"PrivateDnsName,omitempty"`
+	PlatformDetails  string   `xml:"platformDetails" json:"PlatformDetails,omitempty"`
+	LaunchTime       string   `xml:"launchTime" json:"LaunchTime,omitempty"`
+	Tags             []ec2Tag `xml:"tagSet>item" json:"Tags,omitempty"`
+}
+
+type ec2Tag struct {
+	Key   string `xml:"key" json:"Key"`
+	Value string `xml:"value" json:"Value"`
+}
+
+type ec2DescribeInstancesResponse struct {
+	Reservations []struct {
+		Instances []ec2Instance `xml:"instancesSet>item"`
+	} `xml:"reservationSet>item"`
+	NextToken string `xml:"nextToken"`
+}
+
+type ssmInstance struct {
+	InstanceID   string `json:"InstanceId"`
+	PingStatus   string `json:"PingStatus,omitempty"`
+	PlatformName string `json:"PlatformName,omitempty"`
+	PlatformType string `json:"PlatformType,omitempty"`
+	ComputerName string `json:"ComputerName,omitempty"`
+	IPAddress    string `json:"IPAddress,omitempty"`
+	AgentVersion string `json:"AgentVersion,omitempty"`
+}
+
+type ssmDescribeInstanceInformationResponse struct {
+	InstanceInformationList []ssmInstance `json:"InstanceInformationList"`
+	NextToken               string        `json:"NextToken"`
+}
+
+func (a *AWSAPIAdapter) callerIdentity(ctx context.Context, creds AWSCredentials) (stsIdentity, error) {
+	form := url.Values{"Action": {"GetCallerIdentity"}, "Version": {stsAPIVersion}}
+	body, err := a.call(ctx, creds, "sts", defaultAWSRegion, formRequest(form))
+	if err != nil {
+		return stsIdentity{}, err
+	}
+	var identity stsIdentity
+	if err := xml.Unmarshal(body, &identity); err != nil {
+		return stsIdentity{}, &ScanError{Code: "parse_error", Message: "failed to parse aws caller identity", Detail: err.Error()}
+	}
+	return identity, nil
+}
+
+func (a *AWSAPIAdapter) describeInstances(ctx context.Context, creds AWSCredentials, region string) ([]ec2Instance, error) {
+	var instances []ec2Instance
+	err := paginate(func(token string) (string, error) {
+		form := url.Values{
+			"Action":     {"DescribeInstances"},
+			"Version":    {ec2APIVersion},
+			"MaxResults": {fmt.Sprint(ec2PageSize)},
+		}
+		if token != "" {
+			form.Set("NextToken", token)
+		}
+		body, err := a.call(ctx, creds, "ec2", region, formRequest(form))
+		if err != nil {
+			return "", err
+		}
+		var page ec2DescribeInstancesResponse
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return "", &ScanError{Code: "parse_error", Message: "failed to parse aws instances", Detail: err.Error()}
+		}
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				if strings.TrimSpace(instance.InstanceID) != "" {
+					instances = append(instances, instance)
+				}
+			}
+		}
+		return page.NextToken, nil
+	})
+	return instances, err
+}
+
+func (a *AWSAPIAdapter) describeInstanceInformation(ctx context.Context, creds AWSCredentials, region string) (map[string]ssmInstance, error) {
+	managed := make(map[string]ssmInstance)
+	err := paginate(func(token string) (string, error) {
+		payload := map[string]any{"MaxResults": ssmPageSize}
+		if token != "" {
+			payload["NextToken"] = token
+		}
+		body, err := a.call(ctx, creds, "ssm", region, jsonRequest("AmazonSSM.DescribeInstanceInformation", payload))
+		if err != nil {
+			return "", err
+		}
+		var page ssmDescribeInstanceInformationResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			return "", &ScanError{Code: "parse_error", Message: "failed to parse aws ssm instances", Detail: err.Error()}
+		}
+		for _, info := range page.InstanceInformationList {
+			if strings.TrimSpace(info.InstanceID) != "" {
+				managed[info.InstanceID] = info
+			}
+		}
+		return page.NextToken, nil
+	})
+	return managed, err
+}
+
+// paginate calls fetch until it returns an empty token. A repeated token or an
+// implausible page count is treated as a provider bug rather than looping forever.
+func paginate(fetch func(token string) (string, error)) error {
+	token := ""
+	seen := make(map[string]struct{})
+	for page := 0; page < maxAWSPages; page++ {
+		next, err := fetch(token)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		if _, dup := seen[next]; dup {
+			return &ScanError{Code: "pagination_error", Message: "aws API returned a repeated page token"}
+		}
+		seen[next] = struct{}{}
+		token = next
+	}
+	return &ScanError{Code: "pagination_error", Message: fmt.Sprintf("aws API returned more than %d pages", maxAWSPages)}
+}
+
+// awsRequest describes a signed POST body; headers are added verbatim.
+type awsRequest struct {
+	body    []byte
+	headers map[string]string
+}
+
+func formRequest(form url.Values) awsRequest {
+	return awsRequest{
+		body:    []byte(form.Encode()),
+		headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded; charset=utf-8"},
+	}
+}
+
+func jsonRequest(target string, payload any) awsRequest {
+	body, _ := json.Marshal(payload)
+	return awsRequest{
+		body: body,
+		headers: map[string]string{
+			"Content-Type": "application/x-amz-json-1.1",
+			"X-Amz-Target": target,
+		},
+	}
+}
+
+// call sends a signed request, retrying throttling and transient server
+// errors with exponential backoff.
+func (a *AWSAPIAdapter) call(ctx context.Context, creds AWSCredentials, service, region string, spec awsRequest) ([]byte, error) {
+	endpoint := a.endpoint(service, region)
+	var lastErr *ScanError
+	for attempt := 0; attempt <= a.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, a.backoff(attempt)); err != nil {
+				return nil, &ScanError{Code: "scan_timeout", Message: "provider scan timed out", Detail: err.Error()}
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(spec.body))
+		if err != nil {
+			return nil, &ScanError{Code: "request_error", Message: "failed to build aws request", Detail: err.Error()}
+		}
+		for key, value := range spec.headers {
+			req.Header.Set(key, value)
+		}
+		signAWSRequest(req, spec.body, creds, region, service, a.now())
+
+		resp, err := a.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, &ScanError{Code: "scan_timeout", Message: "provider scan timed out", Detail: err.Error()}
+			}
+			lastErr = &ScanError{Code: "request_failed", Message: fmt.Sprintf("aws %s request failed", service), Detail: err.Error()}
+			continue
+		}
+		body, readErr := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+		resp.Body.Close()
+		if readErr != nil {
+			lastErr = &ScanError{Code: "request_failed", Message: fmt.Sprintf("aws %s response read failed", service), Detail: readErr.Error()}
+			continue
+		}
+		if resp.StatusCode < 300 {
+			return body, nil
+		}
+
+		code, message := parseAWSError(body)
+		scanErr := classifyAWSAPIError(service, resp.StatusCode, code, message)
+		if scanErr.Code != "rate_limited" && resp.StatusCode < 500 {
+			return nil, scanErr
+		}
+		lastErr = scanErr
+	}
+	return nil, lastErr
+}
+
+func (a *AWSAPIAdapter) backoff(attempt int) time.Duration {
+	d := a.baseBackoff << (attempt - 1)
+	if d <= 0 || d > maxAWSBackoff {
+		d = maxAWSBackoff
+	}
+	return d
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type awsErrorDetail struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// parseAWSError extracts the error code from EC2 (Response>Errors>Error),
+// query-protocol (ErrorResponse>Error) and JSON-protocol bodies.
+func parseAWSError(body []byte) (string, string) {
+	var xmlErr struct {
+		Errors []awsErrorDetail `xml:"Errors>Error"`
+		Error  awsErrorDetail   `xml:"Error"`
+	}
+	if xml.Unmarshal(body, &xmlErr) == nil {
+		if len(xmlErr.Errors) > 0 {
+			return xmlErr.Errors[0].Code, xmlErr.Errors[0].Message
+		}
+		if xmlErr.Error.Code != "" {
+			return xmlErr.Error.Code, xmlErr.Error.Message
+		}
+	}
+
+	var jsonErr struct {
+		Type         string `json:"__type"`
+		Message      string `json:"message"`
+		MessageUpper string `json:"Message"`
+	}
+	if json.Unmarshal(body, &jsonErr) == nil && jsonErr.Type != "" {
+		code := jsonErr.Type
+		if idx := strings.LastIndex(code, "#"); idx >= 0 {
+			code = code[idx+1:]
+		}
+		return code, firstNonEmpty(jsonErr.Message, jsonErr.MessageUpper)
+	}
+	return "", strings.TrimSpace(string(body))
+}
+
+func classifyAWSAPIError(service string, status int, code, message string) *ScanError {
+	detail := strings.TrimSpace(code + ": " + message)
+	switch code {
+	case "RequestLimitExceeded", "Throttling", "ThrottlingException", "TooManyRequestsException", "RequestThrottled", "RequestThrottledException":
+		return &ScanError{Code: "rate_limited", Message: fmt.Sprintf("aws %s API rate limit exceeded", service), Detail: detail}
+	case "AuthFailure", "UnauthorizedOperation", "InvalidClientTokenId", "SignatureDoesNotMatch",
+		"ExpiredToken", "ExpiredTokenException", "AccessDenied", "AccessDeniedException", "UnrecognizedClientException":
+		return &ScanError{Code: "auth_failed", Message: fmt.Sprintf("aws credentials rejected by %s", service), Detail: detail}
+	}
+	if status == http.StatusTooManyRequests {
+		return &ScanError{Code: "rate_limited", Message: fmt.Sprintf("aws %s API rate limit exceeded", service), Detail: detail}
+	}
+	return &ScanError{Code: "api_error", Message: fmt.Sprintf("aws %s API returned %d", service, status), Detail: detail}
+}
+
+// ── Credentials ─────────────────────────────────────────────
+
+func (a *AWSAPIAdapter) resolveCredentials(ctx context.Context, connector Connector) (AWSCredentials, error) {
+	switch normalizeAuthMode(connector.AuthMode) {
+	case AuthModeAccessKeys:
+		if connector.Credentials == nil || connector.Credentials.AccessKeyID == "" || connector.Credentials.SecretAccessKey == "" {
+			return AWSCredentials{}, &ScanError{Code: "auth_failed", Message: "aws connector has no stored access keys"}
+		}
+		return *connector.Credentials, nil
+	case AuthModeIAMRole:
+		if id, secret := a.getenv("AWS_ACCESS_KEY_ID"), a.getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+			return AWSCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: a.getenv("AWS_SESSION_TOKEN")}, nil
+		}
+		return a.instanceRoleCredentials(ctx)
+	default:
+		return AWSCredentials{}, &ScanError{Code: "unsupported_auth_mode", Message: fmt.Sprintf("auth_mode %s is not supported by the aws API adapter", connector.AuthMode)}
+	}
+}
+
+// instanceRoleCredentials fetches the instance profile credentials via IMDSv2.
+func (a *AWSAPIAdapter) instanceRoleCredentials(ctx context.Context) (AWSCredentials, error) {
+	fail := func(detail string) (AWSCredentials, error) {
+		return AWSCredentials{}, &ScanError{Code: "auth_failed", Message: "no aws instance role credentials available", Detail: detail}
+	}
+
+	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPut, a.imdsEndpoint+"/latest/api/token", nil)
+	if err != nil {
+		return fail(err.Error())
+	}
+	tokenReq.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "300")
+	token, err := a.imdsGet(tokenReq)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	get := func(path string) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.imdsEndpoint+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-aws-ec2-metadata-token", string(token))
+		return a.imdsGet(req)
+	}
+
+	roles, err := get("/latest/meta-data/iam/security-credentials/")
+	if err != nil {
+		return fail(err.Error())
+	}
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	if role == "" {
+		return fail("instance has no IAM role attached")
+	}
+	raw, err := get("/latest/meta-data/iam/security-credentials/" + url.PathEscape(role))
+	if err != nil {
+		return fail(err.Error())
+	}
+	var payload struct {
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		Token           string `json:"Token"`
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.AccessKeyID == "" {
+		return fail("invalid instance role credentials document")
+	}
+	return AWSCredentials{AccessKeyID: payload.AccessKeyID, SecretAccessKey: payload.SecretAccessKey, SessionToken: payload.Token}, nil
+}
+
+func (a *AWSAPIAdapter) imdsGet(req *http.Request) ([]byte, error) {
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("imds %s returned %d", req.URL.Path, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// ── SigV4 ───────────────────────────────────────────────────
+
+// signAWSRequest adds SigV4 headers to req. body must be the exact request body.
+func signAWSRequest(req *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		lower := strings.ToLower(key)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(values url.Values) string {
+	return strings.ReplaceAll(values.Encode(), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
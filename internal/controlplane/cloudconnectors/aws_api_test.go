@@ -0,0 +1,247 @@
+package cloudconnectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockAWS serves STS, EC2 and SSM from one server; the service is taken from
+// the SigV4 credential scope.
+type mockAWS struct {
+	mu         sync.Mutex
+	throttleN  int
+	ec2Calls   int
+	ssmCalls   int
+	authHeader string
+}
+
+func (m *mockAWS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	auth := r.Header.Get("Authorization")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.authHeader = auth
+
+	switch {
+	case strings.Contains(auth, "/sts/aws4_request"):
+		fmt.Fprint(w, `<GetCallerIdentityResponse><GetCallerIdentityResult>
+			<Arn>arn:aws:iam::123456789012:user/scanner</Arn><Account>123456789012</Account>
+		</GetCallerIdentityResult></GetCallerIdentityResponse>`)
+	case strings.Contains(auth, "/ec2/aws4_request"):
+		m.ec2Calls++
+		if m.throttleN > 0 {
+			m.throttleN--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `<Response><Errors><Error><Code>RequestLimitExceeded</Code><Message>Request limit exceeded.</Message></Error></Errors></Response>`)
+			return
+		}
+		form, _ := url.ParseQuery(string(body))
+		if form.Get("NextToken") == "" {
+			fmt.Fprint(w, `<DescribeInstancesResponse><reservationSet><item><instancesSet><item>
+				<instanceId>i-aaa</instanceId><instanceType>t3.micro</instanceType>
+				<instanceState><name>running</name></instanceState>
+				<placement><availabilityZone>eu-west-1a</availabilityZone></placement>
+				<privateIpAddress>10.0.0.5</privateIpAddress>
+				<tagSet><item><key>Name</key><value>web-1</value></item></tagSet>
+			</item></instancesSet></item></reservationSet><nextToken>page-2</nextToken></DescribeInstancesResponse>`)
+			return
+		}
+		fmt.Fprint(w, `<DescribeInstancesResponse><reservationSet><item><instancesSet><item>
+			<instanceId>i-bbb</instanceId><instanceType>m5.large</instanceType>
+			<instanceState><name>stopped</name></instanceState>
+			<placement><availabilityZone>eu-west-1b</availabilityZone></placement>
+		</item></instancesSet></item></reservationSet></DescribeInstancesResponse>`)
+	case strings.Contains(auth, "/ssm/aws4_request"):
+		m.ssmCalls++
+		if r.Header.Get("X-Amz-Target") != "AmazonSSM.DescribeInstanceInformation" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"InstanceInformationList":[
+			{"InstanceId":"i-aaa","PingStatus":"Online","PlatformName":"Ubuntu"},
+			{"InstanceId":"mi-0123","PingStatus":"ConnectionLost","ComputerName":"onprem-1","IPAddress":"192.168.1.9"}
+		]}`)
+	default:
+		w.WriteHeader(http.StatusForbidden)
+	}
+}
+
+func newMockAWSAdapter(t *testing.T, mock http.Handler) *AWSAPIAdapter {
+	t.Helper()
+	srv := httptest.NewServer(mock)
+	t.Cleanup(srv.Close)
+	return NewAWSAPIAdapter(AWSAPIOptions{
+		HTTPClient:  srv.Client(),
+		Endpoint:    func(service, region string) string { return srv.URL },
+		Getenv:      func(string) string { return "" },
+		BaseBackoff: time.Millisecond,
+	})
+}
+
+func TestAWSAPIAdapterPagesInstancesAndMergesSSM(t *testing.T) {
+	mock := &mockAWS{throttleN: 2}
+	adapter := newMockAWSAdapter(t, mock)
+
+	assets, err := adapter.Scan(context.Background(), Connector{
+		ID:          "c1",
+		Provider:    ProviderAWS,
+		AuthMode:    AuthModeAccessKeys,
+		Regions:     []string{"eu-west-1"},
+		IncludeSSM:  true,
+		Credentials: &AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if mock.ec2Calls != 4 {
+		t.Fatalf("expected 2 throttled + 2 paged ec2 calls, got %d", mock.ec2Calls)
+	}
+	if !strings.HasPrefix(mock.authHeader, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+		t.Fatalf("unexpected authorization header %q", mock.authHeader)
+	}
+
+	byID := make(map[string]Asset, len(assets))
+	for _, asset := range assets {
+		byID[asset.AssetID] = asset
+	}
+	if len(assets) != 4 {
+		t.Fatalf("expected account + 2 instances + 1 hybrid instance, got %d: %+v", len(assets), assets)
+	}
+	if byID["123456789012"].AssetType != "account" {
+		t.Fatalf("expected account asset, got %+v", byID["123456789012"])
+	}
+
+	web := byID["i-aaa"]
+	if web.DisplayName != "web-1" || web.Region != "eu-west-1" || web.Status != "running" {
+		t.Fatalf("unexpected instance asset: %+v", web)
+	}
+	for key, want := range map[string]string{
+		"region":          "eu-west-1",
+		"instance_type":   "t3.micro",
+		"state":           "running",
+		"ssm_managed":     "true",
+		"ssm_ping_status": "Online",
+		"tag:Name":        "web-1",
+	} {
+		if web.Tags[key] != want {
+			t.Fatalf("tag %s = %q, want %q (tags %v)", key, web.Tags[key], want, web.Tags)
+		}
+	}
+	if _, ok := byID["i-bbb"].Tags["ssm_managed"]; ok {
+		t.Fatal("expected i-bbb to be unmanaged")
+	}
+	if hybrid := byID["mi-0123"]; hybrid.AssetType != "managed_instance" || hybrid.DisplayName != "onprem-1" {
+		t.Fatalf("unexpected hybrid asset: %+v", hybrid)
+	}
+}
+
+func TestAWSAPIAdapterGivesUpAfterPersistentThrottling(t *testing.T) {
+	adapter := newMockAWSAdapter(t, &mockAWS{throttleN: 100})
+	adapter.maxRetries = 2
+
+	_, err := adapter.Scan(context.Background(), Connector{
+		ID:          "c1",
+		Provider:    ProviderAWS,
+		AuthMode:    AuthModeAccessKeys,
+		Credentials: &AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+	})
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) || scanErr.Code != "rate_limited" {
+		t.Fatalf("expected rate_limited scan error, got %v", err)
+	}
+}
+
+func TestAWSAPIAdapterReportsAuthFailure(t *testing.T) {
+	adapter := newMockAWSAdapter(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<ErrorResponse><Error><Type>Sender</Type><Code>InvalidClientTokenId</Code><Message>bad key</Message></Error></ErrorResponse>`)
+	}))
+
+	_, err := adapter.Scan(context.Background(), Connector{
+		ID:          "c1",
+		Provider:    ProviderAWS,
+		AuthMode:    AuthModeAccessKeys,
+		Credentials: &AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+	})
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) || scanErr.Code != "auth_failed" {
+		t.Fatalf("expected auth_failed scan error, got %v", err)
+	}
+
+	_, err = adapter.Scan(context.Background(), Connector{ID: "c2", Provider: ProviderAWS, AuthMode: AuthModeAccessKeys})
+	if !errors.As(err, &scanErr) || scanErr.Code != "auth_failed" {
+		t.Fatalf("expected auth_failed without stored keys, got %v", err)
+	}
+}
+
+func TestAWSAPIAdapterUsesInstanceRoleCredentials(t *testing.T) {
+	imds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+			fmt.Fprint(w, "imds-token")
+		case r.Header.Get("X-aws-ec2-metadata-token") != "imds-token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/":
+			fmt.Fprint(w, "legator-role\n")
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/legator-role":
+			fmt.Fprint(w, `{"AccessKeyId":"ASIAROLE","SecretAccessKey":"s","Token":"session"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer imds.Close()
+
+	adapter := NewAWSAPIAdapter(AWSAPIOptions{IMDSEndpoint: imds.URL, Getenv: func(string) string { return "" }})
+	creds, err := adapter.resolveCredentials(context.Background(), Connector{Provider: ProviderAWS, AuthMode: AuthModeIAMRole})
+	if err != nil {
+		t.Fatalf("resolve credentials: %v", err)
+	}
+	if creds.AccessKeyID != "ASIAROLE" || creds.SessionToken != "session" {
+		t.Fatalf("unexpected credentials: %+v", creds)
+	}
+}
+
+func TestSignAWSRequestMatchesReferenceVector(t *testing.T) {
+	// Reference request from the AWS SigV4 documentation (IAM ListUsers).
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	signAWSRequest(req, nil, creds, "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Fatalf("authorization mismatch\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestRoutingScannerSelectsAdapterByAuthMode(t *testing.T) {
+	var routed []string
+	router := &RoutingScanner{
+		CLI:    scannerFunc(func(Connector) ([]Asset, error) { routed = append(routed, "cli"); return nil, nil }),
+		AWSAPI: scannerFunc(func(Connector) ([]Asset, error) { routed = append(routed, "api"); return nil, nil }),
+	}
+	for _, c := range []Connector{
+		{Provider: ProviderAWS, AuthMode: AuthModeCLI},
+		{Provider: ProviderAWS, AuthMode: AuthModeIAMRole},
+		{Provider: ProviderAWS, AuthMode: AuthModeAccessKeys},
+		{Provider: ProviderGCP},
+	} {
+		_, _ = router.Scan(context.Background(), c)
+	}
+	if got := strings.Join(routed, ","); got != "cli,api,api,cli" {
+		t.Fatalf("unexpected routing: %s", got)
+	}
+}
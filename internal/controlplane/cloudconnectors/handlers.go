@@ -3,6 +3,7 @@ package cloudconnectors
 import (
 	"encoding/json"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -16,7 +17,7 @@ type Handler struct {
 
 func NewHandler(store *Store, scanner Scanner) *Handler {
 	if scanner == nil {
-		scanner = NewCLIAdapter()
+		scanner = NewDefaultScanner()
 	}
 	return &Handler{store: store, scanner: scanner}
 }
@@ -26,6 +27,10 @@ type connectorWriteRequest struct {
 	Provider  string `json:"provider"`
 	AuthMode  string `json:"auth_mode"`
 	IsEnabled *bool  `json:"is_enabled"`
+
+	Regions     *[]string       `json:"regions,omitempty"`
+	IncludeSSM  *bool           `json:"include_ssm,omitempty"`
+	Credentials *AWSCredentials `json:"credentials,omitempty"`
 }
 
 func (h *Handler) HandleListConnectors(w http.ResponseWriter, r *http.Request) {
@@ -53,12 +58,25 @@ func (h *Handler) HandleCreateConnector(w http.ResponseWriter, r *http.Request)
 		isEnabled = *req.IsEnabled
 	}
 
-	connector, err := h.store.CreateConnector(Connector{
-		Name:      strings.TrimSpace(req.Name),
-		Provider:  normalizeProvider(req.Provider),
-		AuthMode:  normalizeAuthMode(req.AuthMode),
-		IsEnabled: isEnabled,
-	})
+	candidate := Connector{
+		Name:        strings.TrimSpace(req.Name),
+		Provider:    normalizeProvider(req.Provider),
+		AuthMode:    normalizeAuthMode(req.AuthMode),
+		IsEnabled:   isEnabled,
+		Credentials: normalizeCredentials(req.Credentials),
+	}
+	if req.Regions != nil {
+		candidate.Regions = normalizeRegions(*req.Regions)
+	}
+	if req.IncludeSSM != nil {
+		candidate.IncludeSSM = *req.IncludeSSM
+	}
+	if msg := validateConnectorAuth(candidate); msg != "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", msg)
+		return
+	}
+
+	connector, err := h.store.CreateConnector(candidate)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
 		return
@@ -107,17 +125,35 @@ func (h *Handler) HandleUpdateConnector(w http.ResponseWriter, r *http.Request)
 	if provider == "" {
 		provider = existing.Provider
 	}
-	authMode := normalizeAuthMode(req.AuthMode)
-	if authMode == "" {
-		authMode = existing.AuthMode
+	authMode := existing.AuthMode
+	if strings.TrimSpace(req.AuthMode) != "" {
+		authMode = normalizeAuthMode(req.AuthMode)
 	}
 
-	updated, err := h.store.UpdateConnector(id, Connector{
-		Name:      name,
-		Provider:  provider,
-		AuthMode:  authMode,
-		IsEnabled: isEnabled,
-	})
+	candidate := Connector{
+		Name:        name,
+		Provider:    provider,
+		AuthMode:    authMode,
+		IsEnabled:   isEnabled,
+		Regions:     existing.Regions,
+		IncludeSSM:  existing.IncludeSSM,
+		Credentials: existing.Credentials,
+	}
+	if req.Regions != nil {
+		candidate.Regions = normalizeRegions(*req.Regions)
+	}
+	if req.IncludeSSM != nil {
+		candidate.IncludeSSM = *req.IncludeSSM
+	}
+	if creds := normalizeCredentials(req.Credentials); creds != nil {
+		candidate.Credentials = creds
+	}
+	if msg := validateConnectorAuth(candidate); msg != "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", msg)
+		return
+	}
+
+	updated, err := h.store.UpdateConnector(id, candidate)
 	if err != nil {
 		if IsNotFound(err) {
 			writeError(w, http.StatusNotFound, "not_found", "connector not found")
@@ -240,12 +276,71 @@ func validateConnectorRequest(req connectorWriteRequest, requireFields bool) str
 	if provider != "" && !isSupportedProvider(provider) {
 		return "provider must be one of: aws, gcp, azure"
 	}
-	if authMode != "" && authMode != AuthModeCLI {
-		return "auth_mode must be cli"
+	switch authMode {
+	case "", AuthModeCLI, AuthModeAccessKeys, AuthModeIAMRole:
+	default:
+		return "auth_mode must be one of: cli, access_keys, iam_role"
+	}
+	if req.Regions != nil {
+		for _, region := range normalizeRegions(*req.Regions) {
+			if !awsRegionPattern.MatchString(region) {
+				return "invalid region: " + region
+			}
+		}
+	}
+	return ""
+}
+
+var awsRegionPattern = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+
+// validateConnectorAuth checks the merged connector: API auth modes are AWS
+// only, and access_keys needs a stored key pair.
+func validateConnectorAuth(connector Connector) string {
+	if !usesAWSAPI(connector.AuthMode) {
+		return ""
+	}
+	if connector.Provider != ProviderAWS {
+		return "auth_mode " + connector.AuthMode + " is only supported for provider aws"
+	}
+	if connector.AuthMode == AuthModeAccessKeys && connector.Credentials == nil {
+		return "credentials.access_key_id and credentials.secret_access_key are required for access_keys auth"
 	}
 	return ""
 }
 
+func normalizeRegions(regions []string) []string {
+	out := make([]string, 0, len(regions))
+	seen := make(map[string]struct{}, len(regions))
+	for _, region := range regions {
+		region = strings.ToLower(strings.TrimSpace(region))
+		if region == "" {
+			continue
+		}
+		if _, ok := seen[region]; ok {
+			continue
+		}
+		seen[region] = struct{}{}
+		out = append(out, region)
+	}
+	return out
+}
+
+// normalizeCredentials returns nil unless both key halves are present.
+func normalizeCredentials(creds *AWSCredentials) *AWSCredentials {
+	if creds == nil {
+		return nil
+	}
+	out := AWSCredentials{
+		AccessKeyID:     strings.TrimSpace(creds.AccessKeyID),
+		SecretAccessKey: strings.TrimSpace(creds.SecretAccessKey),
+		SessionToken:    strings.TrimSpace(creds.SessionToken),
+	}
+	if out.AccessKeyID == "" || out.SecretAccessKey == "" {
+		return nil
+	}
+	return &out
+}
+
 func isSupportedProvider(provider string) bool {
 	switch normalizeProvider(provider) {
 	case ProviderAWS, ProviderGCP, ProviderAzure:
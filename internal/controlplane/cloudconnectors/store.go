@@ -2,6 +2,7 @@ package cloudconnectors
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -70,11 +71,17 @@ func NewStore(dbPath string) (*Store, error) {
 		return nil, fmt.Errorf("create cloud_assets: %w", err)
 	}
 
+	if err := ensureCloudColumns(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_cloud_connectors_provider ON cloud_connectors(provider)`)
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_cloud_connectors_updated ON cloud_connectors(updated_at)`)
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_cloud_assets_connector ON cloud_assets(connector_id)`)
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_cloud_assets_provider ON cloud_assets(provider)`)
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_cloud_assets_discovered ON cloud_assets(discovered_at DESC)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_cloud_assets_identity ON cloud_assets(connector_id, asset_type, asset_id)`)
 
 	if err := migration.EnsureVersion(db, 1); err != nil {
 		_ = db.Close()
@@ -92,7 +99,8 @@ func (s *Store) Close() error {
 
 func (s *Store) ListConnectors() ([]Connector, error) {
 	rows, err := s.db.Query(`SELECT
-		id, name, provider, auth_mode, is_enabled, created_at, updated_at, last_scan_at, last_status, last_error
+		id, name, provider, auth_mode, is_enabled, created_at, updated_at, last_scan_at, last_status, last_error,
+		regions_json, include_ssm, credentials_json
 		FROM cloud_connectors
 		ORDER BY updated_at DESC`)
 	if err != nil {
@@ -114,7 +122,8 @@ func (s *Store) ListConnectors() ([]Connector, error) {
 
 func (s *Store) GetConnector(id string) (*Connector, error) {
 	row := s.db.QueryRow(`SELECT
-		id, name, provider, auth_mode, is_enabled, created_at, updated_at, last_scan_at, last_status, last_error
+		id, name, provider, auth_mode, is_enabled, created_at, updated_at, last_scan_at, last_status, last_error,
+		regions_json, include_ssm, credentials_json
 		FROM cloud_connectors
 		WHERE id = ?`, id)
 	return scanConnector(row)
@@ -138,8 +147,9 @@ func (s *Store) CreateConnector(connector Connector) (*Connector, error) {
 	}
 
 	if _, err := s.db.Exec(`INSERT INTO cloud_connectors
-		(id, name, provider, auth_mode, is_enabled, created_at, updated_at, last_scan_at, last_status, last_error)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		(id, name, provider, auth_mode, is_enabled, created_at, updated_at, last_scan_at, last_status, last_error,
+		regions_json, include_ssm, credentials_json)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		connector.ID,
 		connector.Name,
 		connector.Provider,
@@ -150,6 +160,9 @@ func (s *Store) CreateConnector(connector Connector) (*Connector, error) {
 		nullTime(connector.LastScanAt),
 		nullString(connector.LastStatus),
 		nullString(connector.LastError),
+		encodeRegions(connector.Regions),
+		boolToInt(connector.IncludeSSM),
+		encodeCredentials(connector.Credentials),
 	); err != nil {
 		return nil, fmt.Errorf("insert connector: %w", err)
 	}
@@ -177,15 +190,24 @@ func (s *Store) UpdateConnector(id string, connector Connector) (*Connector, err
 		authMode = existing.AuthMode
 	}
 	isEnabled := connector.IsEnabled
+	// Credentials are write-only; an update without them keeps the stored secret.
+	credentials := connector.Credentials
+	if credentials == nil {
+		credentials = existing.Credentials
+	}
 
 	result, err := s.db.Exec(`UPDATE cloud_connectors
-		SET name = ?, provider = ?, auth_mode = ?, is_enabled = ?, updated_at = ?
+		SET name = ?, provider = ?, auth_mode = ?, is_enabled = ?, updated_at = ?,
+		regions_json = ?, include_ssm = ?, credentials_json = ?
 		WHERE id = ?`,
 		name,
 		provider,
 		enableAuthMode(authMode),
 		boolToInt(isEnabled),
 		now.Format(time.RFC3339Nano),
+		encodeRegions(connector.Regions),
+		boolToInt(connector.IncludeSSM),
+		encodeCredentials(credentials),
 		id,
 	)
 	if err != nil {
@@ -235,6 +257,10 @@ func (s *Store) SetConnectorScanResult(connectorID string, status, scanErr strin
 	return nil
 }
 
+// ReplaceAssetsForConnector makes the connector's asset set match assets.
+// Assets are keyed by (asset_type, asset_id): existing rows keep their ID and
+// are updated in place, new ones are inserted, and rows no longer reported are
+// removed, so repeated scans of the same estate are idempotent.
 func (s *Store) ReplaceAssetsForConnector(connector Connector, assets []Asset) error {
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -242,19 +268,30 @@ func (s *Store) ReplaceAssetsForConnector(connector Connector, assets []Asset) e
 	}
 	defer tx.Rollback()
 
-	if _, err := tx.Exec(`DELETE FROM cloud_assets WHERE connector_id = ?`, connector.ID); err != nil {
-		return fmt.Errorf("delete old assets: %w", err)
+	existing := make(map[string]string)
+	rows, err := tx.Query(`SELECT id, asset_type, asset_id FROM cloud_assets WHERE connector_id = ?`, connector.ID)
+	if err != nil {
+		return fmt.Errorf("load existing assets: %w", err)
+	}
+	for rows.Next() {
+		var id, assetType, assetID string
+		if err := rows.Scan(&id, &assetType, &assetID); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan existing asset: %w", err)
+		}
+		existing[assetKey(assetType, assetID)] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load existing assets: %w", err)
 	}
 
 	now := time.Now().UTC()
+	seen := make(map[string]struct{}, len(assets))
 	for _, asset := range assets {
-		if strings.TrimSpace(asset.ID) == "" {
-			asset.ID = uuid.NewString()
-		}
 		if asset.DiscoveredAt.IsZero() {
 			asset.DiscoveredAt = now
 		}
-
 		if strings.TrimSpace(asset.ConnectorID) == "" {
 			asset.ConnectorID = connector.ID
 		}
@@ -262,9 +299,27 @@ func (s *Store) ReplaceAssetsForConnector(connector Connector, assets []Asset) e
 			asset.Provider = connector.Provider
 		}
 
+		key := assetKey(asset.AssetType, asset.AssetID)
+		if id, ok := existing[key]; ok {
+			asset.ID = id
+		} else if strings.TrimSpace(asset.ID) == "" {
+			asset.ID = uuid.NewString()
+		}
+		existing[key] = asset.ID
+		seen[asset.ID] = struct{}{}
+
 		if _, err := tx.Exec(`INSERT INTO cloud_assets
-			(id, connector_id, provider, scope_id, region, asset_type, asset_id, display_name, status, raw_json, discovered_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			(id, connector_id, provider, scope_id, region, asset_type, asset_id, display_name, status, raw_json, tags_json, discovered_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET
+				provider = excluded.provider,
+				scope_id = excluded.scope_id,
+				region = excluded.region,
+				display_name = excluded.display_name,
+				status = excluded.status,
+				raw_json = excluded.raw_json,
+				tags_json = excluded.tags_json,
+				discovered_at = excluded.discovered_at`,
 			asset.ID,
 			asset.ConnectorID,
 			normalizeProvider(asset.Provider),
@@ -275,9 +330,19 @@ func (s *Store) ReplaceAssetsForConnector(connector Connector, assets []Asset) e
 			strings.TrimSpace(asset.DisplayName),
 			strings.TrimSpace(asset.Status),
 			normalizeRawJSON(asset.RawJSON),
+			encodeTags(asset.Tags),
 			asset.DiscoveredAt.UTC().Format(time.RFC3339Nano),
 		); err != nil {
-			return fmt.Errorf("insert asset: %w", err)
+			return fmt.Errorf("upsert asset: %w", err)
+		}
+	}
+
+	for _, id := range existing {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM cloud_assets WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("delete stale asset: %w", err)
 		}
 	}
 
@@ -298,7 +363,7 @@ func (s *Store) ListAssets(filter AssetFilter) ([]Asset, error) {
 	}
 
 	query := `SELECT
-		id, connector_id, provider, scope_id, region, asset_type, asset_id, display_name, status, raw_json, discovered_at
+		id, connector_id, provider, scope_id, region, asset_type, asset_id, display_name, status, raw_json, tags_json, discovered_at
 		FROM cloud_assets`
 	if len(clauses) > 0 {
 		query += " WHERE " + strings.Join(clauses, " AND ")
@@ -374,6 +439,106 @@ func nullTime(v time.Time) any {
 	return v.UTC().Format(time.RFC3339Nano)
 }
 
+func assetKey(assetType, assetID string) string {
+	return strings.TrimSpace(assetType) + "/" + strings.TrimSpace(assetID)
+}
+
+func encodeRegions(regions []string) string {
+	if len(regions) == 0 {
+		return ""
+	}
+	return mustMarshal(regions)
+}
+
+func decodeRegions(raw string) []string {
+	var regions []string
+	if strings.TrimSpace(raw) == "" || json.Unmarshal([]byte(raw), &regions) != nil {
+		return nil
+	}
+	return regions
+}
+
+func encodeCredentials(creds *AWSCredentials) string {
+	if creds == nil {
+		return ""
+	}
+	return mustMarshal(creds)
+}
+
+func decodeCredentials(raw string) *AWSCredentials {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	var creds AWSCredentials
+	if err := json.Unmarshal([]byte(raw), &creds); err != nil {
+		return nil
+	}
+	return &creds
+}
+
+func encodeTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return mustMarshal(tags)
+}
+
+func decodeTags(raw string) map[string]string {
+	var tags map[string]string
+	if strings.TrimSpace(raw) == "" || json.Unmarshal([]byte(raw), &tags) != nil {
+		return nil
+	}
+	return tags
+}
+
+func ensureCloudColumns(db *sql.DB) error {
+	if err := ensureColumn(db, "cloud_connectors", "regions_json", "regions_json TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("add cloud_connectors.regions_json: %w", err)
+	}
+	if err := ensureColumn(db, "cloud_connectors", "include_ssm", "include_ssm INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return fmt.Errorf("add cloud_connectors.include_ssm: %w", err)
+	}
+	if err := ensureColumn(db, "cloud_connectors", "credentials_json", "credentials_json TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("add cloud_connectors.credentials_json: %w", err)
+	}
+	if err := ensureColumn(db, "cloud_assets", "tags_json", "tags_json TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("add cloud_assets.tags_json: %w", err)
+	}
+	return nil
+}
+
+func ensureColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			typeName  string
+			notNull   int
+			defaultV  sql.NullString
+			primaryKV int
+		)
+		if err := rows.Scan(&cid, &name, &typeName, &notNull, &defaultV, &primaryKV); err != nil {
+			return err
+		}
+		if strings.EqualFold(name, column) {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, definition))
+	return err
+}
+
 func normalizeRawJSON(raw string) string {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -392,6 +557,8 @@ func scanConnector(row scanner) (*Connector, error) {
 		enabled                          int
 		createdAtRaw, updatedAtRaw       string
 		lastScanRaw, lastStatus, lastErr sql.NullString
+		regionsRaw, credentialsRaw       string
+		includeSSM                       int
 	)
 
 	if err := row.Scan(
@@ -405,6 +572,9 @@ func scanConnector(row scanner) (*Connector, error) {
 		&lastScanRaw,
 		&lastStatus,
 		&lastErr,
+		&regionsRaw,
+		&includeSSM,
+		&credentialsRaw,
 	); err != nil {
 		return nil, err
 	}
@@ -423,14 +593,18 @@ func scanConnector(row scanner) (*Connector, error) {
 	if lastErr.Valid {
 		connector.LastError = lastErr.String
 	}
+	connector.Regions = decodeRegions(regionsRaw)
+	connector.IncludeSSM = includeSSM == 1
+	connector.Credentials = decodeCredentials(credentialsRaw)
+	connector.HasCredentials = connector.Credentials != nil
 
 	return &connector, nil
 }
 
 func scanAsset(row scanner) (*Asset, error) {
 	var (
-		asset           Asset
-		discoveredAtRaw string
+		asset                    Asset
+		tagsRaw, discoveredAtRaw string
 	)
 
 	if err := row.Scan(
@@ -444,12 +618,14 @@ func scanAsset(row scanner) (*Asset, error) {
 		&asset.DisplayName,
 		&asset.Status,
 		&asset.RawJSON,
+		&tagsRaw,
 		&discoveredAtRaw,
 	); err != nil {
 		return nil, err
 	}
 
 	asset.Provider = normalizeProvider(asset.Provider)
+	asset.Tags = decodeTags(tagsRaw)
 	asset.DiscoveredAt, _ = time.Parse(time.RFC3339Nano, discoveredAtRaw)
 	return &asset, nil
 }
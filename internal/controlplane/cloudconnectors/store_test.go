@@ -144,3 +144,69 @@ func TestStoreReplaceAssetsAndFilter(t *testing.T) {
 		t.Fatalf("expected cascading delete to keep 1 asset, got %d", len(remaining))
 	}
 }
+
+func TestStoreReplaceAssetsIsIdempotentAndKeepsCredentials(t *testing.T) {
+	store := newTestStore(t)
+
+	conn, err := store.CreateConnector(Connector{
+		Name:        "AWS API",
+		Provider:    ProviderAWS,
+		AuthMode:    AuthModeAccessKeys,
+		IsEnabled:   true,
+		Regions:     []string{"eu-west-1"},
+		IncludeSSM:  true,
+		Credentials: &AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("create connector: %v", err)
+	}
+	if !conn.HasCredentials || !conn.IncludeSSM || len(conn.Regions) != 1 {
+		t.Fatalf("unexpected connector: %+v", conn)
+	}
+
+	updated, err := store.UpdateConnector(conn.ID, Connector{Name: "AWS API 2", IsEnabled: true, Regions: conn.Regions})
+	if err != nil {
+		t.Fatalf("update connector: %v", err)
+	}
+	if updated.Credentials == nil || updated.Credentials.AccessKeyID != "AKID" {
+		t.Fatalf("expected credentials to survive update, got %+v", updated.Credentials)
+	}
+
+	first := []Asset{
+		{AssetType: "instance", AssetID: "i-1", Status: "running", Tags: map[string]string{"state": "running"}},
+		{AssetType: "instance", AssetID: "i-2", Status: "running"},
+	}
+	if err := store.ReplaceAssetsForConnector(*conn, first); err != nil {
+		t.Fatalf("first replace: %v", err)
+	}
+	before, _ := store.ListAssets(AssetFilter{ConnectorID: conn.ID})
+	ids := make(map[string]string, len(before))
+	for _, asset := range before {
+		ids[asset.AssetID] = asset.ID
+	}
+
+	second := []Asset{
+		{AssetType: "instance", AssetID: "i-1", Status: "stopped", Tags: map[string]string{"state": "stopped"}},
+		{AssetType: "instance", AssetID: "i-3", Status: "running"},
+	}
+	if err := store.ReplaceAssetsForConnector(*conn, second); err != nil {
+		t.Fatalf("second replace: %v", err)
+	}
+	after, _ := store.ListAssets(AssetFilter{ConnectorID: conn.ID})
+	if len(after) != 2 {
+		t.Fatalf("expected stale asset removed, got %d assets", len(after))
+	}
+	for _, asset := range after {
+		if asset.AssetID == "i-1" {
+			if asset.ID != ids["i-1"] {
+				t.Fatalf("expected stable id for i-1, got %s want %s", asset.ID, ids["i-1"])
+			}
+			if asset.Status != "stopped" || asset.Tags["state"] != "stopped" {
+				t.Fatalf("expected i-1 updated in place, got %+v", asset)
+			}
+		}
+		if asset.AssetID == "i-2" {
+			t.Fatal("expected i-2 to be removed")
+		}
+	}
+}
@@ -8,6 +8,12 @@ const (
 	ProviderAzure = "azure"
 
 	AuthModeCLI = "cli"
+	// AuthModeAccessKeys scans AWS through the EC2/SSM APIs using access keys
+	// stored on the connector.
+	AuthModeAccessKeys = "access_keys"
+	// AuthModeIAMRole scans AWS through the EC2/SSM APIs using the control
+	// plane's ambient credentials (environment or instance role).
+	AuthModeIAMRole = "iam_role"
 
 	ScanStatusSuccess = "success"
 	ScanStatusError   = "error"
@@ -25,21 +31,35 @@ type Connector struct {
 	LastScanAt time.Time `json:"last_scan_at,omitempty"`
 	LastStatus string    `json:"last_status,omitempty"`
 	LastError  string    `json:"last_error,omitempty"`
+
+	// Regions and IncludeSSM apply to AWS API connectors.
+	Regions        []string        `json:"regions,omitempty"`
+	IncludeSSM     bool            `json:"include_ssm,omitempty"`
+	HasCredentials bool            `json:"has_credentials"`
+	Credentials    *AWSCredentials `json:"-"`
+}
+
+// AWSCredentials is the connector secret for access_keys auth.
+type AWSCredentials struct {
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	SessionToken    string `json:"session_token,omitempty"`
 }
 
 // Asset is a normalized discovered resource.
 type Asset struct {
-	ID           string    `json:"id"`
-	ConnectorID  string    `json:"connector_id"`
-	Provider     string    `json:"provider"`
-	ScopeID      string    `json:"scope_id"`
-	Region       string    `json:"region"`
-	AssetType    string    `json:"asset_type"`
-	AssetID      string    `json:"asset_id"`
-	DisplayName  string    `json:"display_name"`
-	Status       string    `json:"status"`
-	RawJSON      string    `json:"raw_json"`
-	DiscoveredAt time.Time `json:"discovered_at"`
+	ID           string            `json:"id"`
+	ConnectorID  string            `json:"connector_id"`
+	Provider     string            `json:"provider"`
+	ScopeID      string            `json:"scope_id"`
+	Region       string            `json:"region"`
+	AssetType    string            `json:"asset_type"`
+	AssetID      string            `json:"asset_id"`
+	DisplayName  string            `json:"display_name"`
+	Status       string            `json:"status"`
+	RawJSON      string            `json:"raw_json"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	DiscoveredAt time.Time         `json:"discovered_at"`
 }
 
 // AssetFilter controls cloud asset queries.
@@ -0,0 +1,133 @@
+package cmdtracker
+
+import (
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// DefaultIdempotencyTTL is how long a dispatched command's idempotency key
+// is remembered.
+const DefaultIdempotencyTTL = 10 * time.Minute
+
+// IdempotentCommand is the command first dispatched under an idempotency
+// key. Result is filled in when the probe reports back.
+type IdempotentCommand struct {
+	Key        string                         `json:"idempotency_key"`
+	ProbeID    string                         `json:"probe_id"`
+	RequestID  string                         `json:"request_id"`
+	ApprovalID string                         `json:"approval_id,omitempty"`
+	Claimed    time.Time                      `json:"dispatched_at"`
+	Result     *protocol.CommandResultPayload `json:"result,omitempty"`
+
+	// Fingerprint identifies the request body the key was first used with.
+	Fingerprint string `json:"-"`
+}
+
+// IdempotencyScope identifies an idempotency key. Keys are scoped to a probe
+// and a caller, so callers that happen to pick the same key never receive
+// each other's commands.
+type IdempotencyScope struct {
+	ProbeID string
+	Actor   string
+	Key     string
+}
+
+// SetIdempotencyTTL sets how long idempotency keys are remembered. Values
+// <= 0 restore the default.
+func (t *Tracker) SetIdempotencyTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	t.mu.Lock()
+	t.idempotencyTTL = ttl
+	t.mu.Unlock()
+}
+
+// ClaimIdempotencyKey binds scope to requestID and the request body's
+// fingerprint. If the key is already bound and unexpired it returns the
+// original command and false, and the caller must not dispatch again; the
+// caller should reject the request if the fingerprints differ.
+func (t *Tracker) ClaimIdempotencyKey(scope IdempotencyScope, fingerprint, requestID string) (IdempotentCommand, bool) {
+	now := time.Now().UTC()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if existing, ok := t.idempotent[scope]; ok && now.Sub(existing.Claimed) < t.idempotencyTTLLocked() {
+		return existing.snapshot(), false
+	} else if ok {
+		delete(t.idempotentByRequest, existing.RequestID)
+	}
+	entry := &IdempotentCommand{Key: scope.Key, ProbeID: scope.ProbeID, RequestID: requestID, Claimed: now, Fingerprint: fingerprint}
+	t.idempotent[scope] = entry
+	t.idempotentByRequest[requestID] = scope
+	return entry.snapshot(), true
+}
+
+// SetIdempotentApproval records the approval a keyed command is waiting on.
+func (t *Tracker) SetIdempotentApproval(scope IdempotencyScope, approvalID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if entry, ok := t.idempotent[scope]; ok {
+		entry.ApprovalID = approvalID
+	}
+}
+
+// ReleaseIdempotencyKey forgets a key whose command was never dispatched, so
+// a retry is free to try again.
+func (t *Tracker) ReleaseIdempotencyKey(scope IdempotencyScope) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if entry, ok := t.idempotent[scope]; ok {
+		delete(t.idempotentByRequest, entry.RequestID)
+		delete(t.idempotent, scope)
+	}
+}
+
+// RecordIdempotentResult stores a result for a keyed command completed
+// outside the tracker, such as one run over a remote probe's SSH session.
+func (t *Tracker) RecordIdempotentResult(requestID string, result *protocol.CommandResultPayload) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recordIdempotentResultLocked(requestID, result)
+}
+
+// recordIdempotentResultLocked stores result against the keyed command with
+// the same request ID, if any.
+func (t *Tracker) recordIdempotentResultLocked(requestID string, result *protocol.CommandResultPayload) {
+	scope, ok := t.idempotentByRequest[requestID]
+	if !ok || result == nil {
+		return
+	}
+	if entry, ok := t.idempotent[scope]; ok {
+		stored := *result
+		entry.Result = &stored
+	}
+}
+
+// expireIdempotencyKeysLocked drops keys claimed before now minus the TTL.
+func (t *Tracker) expireIdempotencyKeysLocked(now time.Time) {
+	cutoff := now.Add(-t.idempotencyTTLLocked())
+	for scope, entry := range t.idempotent {
+		if entry.Claimed.Before(cutoff) {
+			delete(t.idempotentByRequest, entry.RequestID)
+			delete(t.idempotent, scope)
+		}
+	}
+}
+
+func (t *Tracker) idempotencyTTLLocked() time.Duration {
+	if t.idempotencyTTL <= 0 {
+		return DefaultIdempotencyTTL
+	}
+	return t.idempotencyTTL
+}
+
+func (c *IdempotentCommand) snapshot() IdempotentCommand {
+	out := *c
+	if c.Result != nil {
+		result := *c.Result
+		out.Result = &result
+	}
+	return out
+}
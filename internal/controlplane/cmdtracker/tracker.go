@@ -27,13 +27,22 @@ type Tracker struct {
 	pending map[string]*PendingCommand // keyed by request_id
 	mu      sync.Mutex
 	ttl     time.Duration // auto-expire after this
+
+	// Idempotency keys of dispatched commands, and the reverse index used
+	// to attach results to them.
+	idempotent          map[IdempotencyScope]*IdempotentCommand
+	idempotentByRequest map[string]IdempotencyScope
+	idempotencyTTL      time.Duration
 }
 
 // New creates a Tracker with a TTL for auto-expiry.
 func New(ttl time.Duration) *Tracker {
 	t := &Tracker{
-		pending: make(map[string]*PendingCommand),
-		ttl:     ttl,
+		pending:             make(map[string]*PendingCommand),
+		ttl:                 ttl,
+		idempotent:          make(map[IdempotencyScope]*IdempotentCommand),
+		idempotentByRequest: make(map[string]IdempotencyScope),
+		idempotencyTTL:      DefaultIdempotencyTTL,
 	}
 	go t.reaper()
 	return t
@@ -62,6 +71,7 @@ func (t *Tracker) Track(requestID, probeID, command string, level protocol.Capab
 // the request ID isn't tracked (already expired or unknown).
 func (t *Tracker) Complete(requestID string, result *protocol.CommandResultPayload) error {
 	t.mu.Lock()
+	t.recordIdempotentResultLocked(requestID, result)
 	pc, ok := t.pending[requestID]
 	if ok {
 		delete(t.pending, requestID)
@@ -128,7 +138,9 @@ func (t *Tracker) expire() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	cutoff := time.Now().UTC().Add(-t.ttl)
+	now := time.Now().UTC()
+	t.expireIdempotencyKeysLocked(now)
+	cutoff := now.Add(-t.ttl)
 	for id, pc := range t.pending {
 		if pc.Submitted.Before(cutoff) {
 			pc.Result <- &protocol.CommandResultPayload{
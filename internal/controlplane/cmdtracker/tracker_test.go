@@ -129,3 +129,50 @@ func TestReaperExpires(t *testing.T) {
 	}
 	tracker.mu.Unlock()
 }
+
+func TestIdempotencyKeyClaimReplayAndRelease(t *testing.T) {
+	tracker := New(30 * time.Second)
+	scope := IdempotencyScope{ProbeID: "probe-a", Actor: "alice", Key: "key-1"}
+
+	if _, claimed := tracker.ClaimIdempotencyKey(scope, "fp-1", "req-1"); !claimed {
+		t.Fatal("expected first claim to succeed")
+	}
+	if _, claimed := tracker.ClaimIdempotencyKey(IdempotencyScope{ProbeID: "probe-b", Actor: "alice", Key: "key-1"}, "fp-1", "req-b"); !claimed {
+		t.Fatal("expected keys to be scoped per probe")
+	}
+	if _, claimed := tracker.ClaimIdempotencyKey(IdempotencyScope{ProbeID: "probe-a", Actor: "bob", Key: "key-1"}, "fp-1", "req-bob"); !claimed {
+		t.Fatal("expected keys to be scoped per actor")
+	}
+
+	_ = tracker.Complete("req-1", &protocol.CommandResultPayload{RequestID: "req-1", ExitCode: 3, Stdout: "done"})
+	prior, claimed := tracker.ClaimIdempotencyKey(scope, "fp-2", "req-2")
+	if claimed {
+		t.Fatal("expected duplicate claim to be refused")
+	}
+	if prior.RequestID != "req-1" || prior.Result == nil || prior.Result.ExitCode != 3 {
+		t.Fatalf("expected original request and result, got %+v", prior)
+	}
+	if prior.Fingerprint != "fp-1" {
+		t.Fatalf("expected original fingerprint, got %q", prior.Fingerprint)
+	}
+
+	tracker.ReleaseIdempotencyKey(scope)
+	if _, claimed := tracker.ClaimIdempotencyKey(scope, "fp-3", "req-3"); !claimed {
+		t.Fatal("expected released key to be claimable")
+	}
+}
+
+func TestIdempotencyKeysExpire(t *testing.T) {
+	tracker := New(30 * time.Second)
+	tracker.SetIdempotencyTTL(time.Minute)
+	scope := IdempotencyScope{ProbeID: "probe-a", Key: "key-1"}
+	tracker.ClaimIdempotencyKey(scope, "fp", "req-1")
+
+	tracker.mu.Lock()
+	tracker.expireIdempotencyKeysLocked(time.Now().UTC().Add(2 * time.Minute))
+	tracker.mu.Unlock()
+
+	if _, claimed := tracker.ClaimIdempotencyKey(scope, "fp", "req-2"); !claimed {
+		t.Fatal("expected expired key to be claimable")
+	}
+}
@@ -0,0 +1,122 @@
+package commandtemplates
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/migration"
+	"github.com/marcus-qen/legator/internal/protocol"
+	_ "modernc.org/sqlite"
+)
+
+// PersistentLibrary wraps Library with SQLite persistence.
+type PersistentLibrary struct {
+	*Library
+	db *sql.DB
+}
+
+// NewPersistentLibrary opens (or creates) a SQLite-backed template library.
+func NewPersistentLibrary(dbPath string) (*PersistentLibrary, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open command templates db: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec("PRAGMA busy_timeout=5000"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("set busy_timeout: %w", err)
+	}
+
+	runner := migration.NewRunner("command_templates", []migration.Migration{
+		{
+			Version:     1,
+			Description: "initial command template schema",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS command_templates (
+					id          TEXT PRIMARY KEY,
+					name        TEXT NOT NULL,
+					description TEXT NOT NULL DEFAULT '',
+					command     TEXT NOT NULL,
+					min_level   TEXT NOT NULL,
+					created_by  TEXT NOT NULL DEFAULT '',
+					created_at  TEXT NOT NULL
+				)`)
+				return err
+			},
+		},
+	})
+	if err := runner.Migrate(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("migrate command templates db: %w", err)
+	}
+
+	pl := &PersistentLibrary{Library: NewLibrary(), db: db}
+	if err := pl.loadFromDB(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return pl, nil
+}
+
+// Add validates a template, stores it in memory and persists it.
+func (pl *PersistentLibrary) Add(tmpl Template) (*Template, error) {
+	stored, err := pl.Library.Add(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := pl.db.Exec(`INSERT INTO command_templates
+		(id, name, description, command, min_level, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		stored.ID, stored.Name, stored.Description, stored.Command, string(stored.MinLevel),
+		stored.CreatedBy, stored.CreatedAt.Format(time.RFC3339Nano),
+	); err != nil {
+		_ = pl.Library.Delete(stored.ID)
+		return nil, fmt.Errorf("persist command template: %w", err)
+	}
+	return stored, nil
+}
+
+// Delete removes a template from both memory and disk.
+func (pl *PersistentLibrary) Delete(id string) error {
+	if err := pl.Library.Delete(id); err != nil {
+		return err
+	}
+	_, _ = pl.db.Exec(`DELETE FROM command_templates WHERE id = ?`, id)
+	return nil
+}
+
+// Close shuts down the database.
+func (pl *PersistentLibrary) Close() error {
+	return pl.db.Close()
+}
+
+func (pl *PersistentLibrary) loadFromDB() error {
+	rows, err := pl.db.Query(`SELECT id, name, description, command, min_level, created_by, created_at
+		FROM command_templates`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			tmpl       Template
+			minLevel   string
+			createdStr string
+		)
+		if err := rows.Scan(&tmpl.ID, &tmpl.Name, &tmpl.Description, &tmpl.Command,
+			&minLevel, &tmpl.CreatedBy, &createdStr); err != nil {
+			return err
+		}
+		tmpl.MinLevel = protocol.CapabilityLevel(minLevel)
+		tmpl.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdStr)
+		if _, err := pl.Library.Add(tmpl); err != nil {
+			return fmt.Errorf("load command template %s: %w", tmpl.ID, err)
+		}
+	}
+	return rows.Err()
+}
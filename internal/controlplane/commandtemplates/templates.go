@@ -0,0 +1,207 @@
+// Package commandtemplates stores fleet-wide, parameterized command templates
+// that operators dispatch by ID instead of retyping raw command lines.
+package commandtemplates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+var (
+	// ErrNotFound is returned when a template ID does not exist.
+	ErrNotFound = errors.New("command template not found")
+	// ErrExists is returned when adding a template whose ID is already taken.
+	ErrExists = errors.New("command template already exists")
+)
+
+var (
+	templateIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+	placeholderRe     = regexp.MustCompile(`\{\{\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+)
+
+// Template is a named command line with {{.param}} placeholders. MinLevel is
+// the lowest probe policy level the rendered command may be dispatched to.
+type Template struct {
+	ID          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description,omitempty"`
+	Command     string                   `json:"command"`
+	Params      []string                 `json:"params"`
+	MinLevel    protocol.CapabilityLevel `json:"min_level"`
+	CreatedBy   string                   `json:"created_by,omitempty"`
+	CreatedAt   time.Time                `json:"created_at"`
+}
+
+// Manager is the interface used by handlers for template CRUD.
+type Manager interface {
+	List() []*Template
+	Get(id string) (*Template, bool)
+	Add(tmpl Template) (*Template, error)
+	Delete(id string) error
+}
+
+// Library is an in-memory, concurrency-safe collection of command templates.
+type Library struct {
+	mu        sync.RWMutex
+	templates map[string]*Template
+}
+
+// NewLibrary creates an empty template library.
+func NewLibrary() *Library {
+	return &Library{templates: make(map[string]*Template)}
+}
+
+// Add validates and stores a template, deriving its parameter list from the
+// command placeholders.
+func (l *Library) Add(tmpl Template) (*Template, error) {
+	tmpl.ID = strings.TrimSpace(tmpl.ID)
+	tmpl.Name = strings.TrimSpace(tmpl.Name)
+	tmpl.Description = strings.TrimSpace(tmpl.Description)
+	// Canonicalise "{{ .name }}" to "{{.name}}" so placeholders never span
+	// the whitespace Render splits on.
+	tmpl.Command = placeholderRe.ReplaceAllString(strings.TrimSpace(tmpl.Command), "{{.$1}}")
+
+	if !templateIDPattern.MatchString(tmpl.ID) {
+		return nil, fmt.Errorf("id must be 1-64 lowercase letters, digits, '.', '_' or '-'")
+	}
+	if tmpl.Command == "" {
+		return nil, fmt.Errorf("command is required")
+	}
+	params, err := parsePlaceholders(tmpl.Command)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(strings.Fields(tmpl.Command)[0], "{{") {
+		return nil, fmt.Errorf("the executable must be fixed, not a parameter")
+	}
+	switch tmpl.MinLevel {
+	case "":
+		tmpl.MinLevel = protocol.CapObserve
+	case protocol.CapObserve, protocol.CapDiagnose, protocol.CapRemediate:
+	default:
+		return nil, fmt.Errorf("min_level must be observe, diagnose or remediate")
+	}
+	tmpl.Params = params
+	if tmpl.Name == "" {
+		tmpl.Name = tmpl.ID
+	}
+	if tmpl.CreatedAt.IsZero() {
+		tmpl.CreatedAt = time.Now().UTC()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.templates[tmpl.ID]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrExists, tmpl.ID)
+	}
+	stored := tmpl
+	l.templates[tmpl.ID] = &stored
+	return &stored, nil
+}
+
+// Delete removes a template by ID.
+func (l *Library) Delete(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.templates[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	delete(l.templates, id)
+	return nil
+}
+
+// Get returns a template by ID.
+func (l *Library) Get(id string) (*Template, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	tmpl, ok := l.templates[id]
+	return tmpl, ok
+}
+
+// List returns all templates sorted by ID.
+func (l *Library) List() []*Template {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]*Template, 0, len(l.templates))
+	for _, tmpl := range l.templates {
+		out = append(out, tmpl)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Render substitutes params into the template and returns the executable and
+// its arguments. Substitution happens per whitespace-separated token, so a
+// value containing spaces stays a single argument and can never introduce
+// extra ones. Every declared parameter must be supplied and unknown
+// parameters are rejected.
+func (t *Template) Render(params map[string]string) (string, []string, error) {
+	declared := make(map[string]bool, len(t.Params))
+	for _, name := range t.Params {
+		declared[name] = true
+		value, ok := params[name]
+		if !ok || strings.TrimSpace(value) == "" {
+			return "", nil, fmt.Errorf("missing value for parameter %q", name)
+		}
+		if strings.IndexFunc(value, unicode.IsControl) >= 0 {
+			return "", nil, fmt.Errorf("parameter %q contains control characters", name)
+		}
+	}
+	for name := range params {
+		if !declared[name] {
+			return "", nil, fmt.Errorf("unknown parameter %q for template %s", name, t.ID)
+		}
+	}
+
+	tokens := strings.Fields(t.Command)
+	rendered := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		rendered = append(rendered, placeholderRe.ReplaceAllStringFunc(token, func(m string) string {
+			return params[placeholderRe.FindStringSubmatch(m)[1]]
+		}))
+	}
+	return rendered[0], rendered[1:], nil
+}
+
+// parsePlaceholders returns the distinct parameter names in command, in order
+// of first appearance. Template syntax other than {{.name}} is rejected so the
+// command string can't smuggle in pipelines or functions.
+func parsePlaceholders(command string) ([]string, error) {
+	if strings.Contains(placeholderRe.ReplaceAllString(command, ""), "{{") {
+		return nil, fmt.Errorf("command may only contain {{.name}} placeholders")
+	}
+	var params []string
+	seen := make(map[string]bool)
+	for _, m := range placeholderRe.FindAllStringSubmatch(command, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			params = append(params, m[1])
+		}
+	}
+	return params, nil
+}
+
+// LevelAllows reports whether a probe at policy level probe may run a
+// template that requires level required.
+func LevelAllows(probe, required protocol.CapabilityLevel) bool {
+	return levelRank(probe) >= levelRank(required)
+}
+
+func levelRank(level protocol.CapabilityLevel) int {
+	switch level {
+	case protocol.CapRemediate:
+		return 2
+	case protocol.CapDiagnose:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,127 @@
+package commandtemplates
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestLibraryAddDerivesParamsAndDefaults(t *testing.T) {
+	lib := NewLibrary()
+	tmpl, err := lib.Add(Template{ID: "restart-service", Command: "systemctl restart {{ .service }}", MinLevel: protocol.CapRemediate})
+	if err != nil {
+		t.Fatalf("add template: %v", err)
+	}
+	if tmpl.Command != "systemctl restart {{.service}}" {
+		t.Fatalf("expected canonical placeholder, got %q", tmpl.Command)
+	}
+	if len(tmpl.Params) != 1 || tmpl.Params[0] != "service" {
+		t.Fatalf("unexpected params %v", tmpl.Params)
+	}
+	if tmpl.Name != "restart-service" || tmpl.CreatedAt.IsZero() {
+		t.Fatalf("expected name and created_at defaults, got %+v", tmpl)
+	}
+
+	if _, err := lib.Add(Template{ID: "restart-service", Command: "true"}); !errors.Is(err, ErrExists) {
+		t.Fatalf("expected duplicate id to be rejected, got %v", err)
+	}
+	observe, err := lib.Add(Template{ID: "uptime", Command: "uptime"})
+	if err != nil {
+		t.Fatalf("add template: %v", err)
+	}
+	if observe.MinLevel != protocol.CapObserve {
+		t.Fatalf("expected observe default, got %q", observe.MinLevel)
+	}
+}
+
+func TestLibraryAddRejectsInvalidTemplates(t *testing.T) {
+	lib := NewLibrary()
+	for _, tmpl := range []Template{
+		{ID: "", Command: "uptime"},
+		{ID: "Bad ID", Command: "uptime"},
+		{ID: "empty", Command: "  "},
+		{ID: "exe-param", Command: "{{.bin}} --version"},
+		{ID: "pipeline", Command: "echo {{.x | printf}}"},
+		{ID: "func", Command: `echo {{printf "%s" .x}}`},
+		{ID: "level", Command: "uptime", MinLevel: "root"},
+	} {
+		if _, err := lib.Add(tmpl); err == nil {
+			t.Fatalf("expected %+v to be rejected", tmpl)
+		}
+	}
+}
+
+func TestTemplateRender(t *testing.T) {
+	lib := NewLibrary()
+	tmpl, err := lib.Add(Template{ID: "grep-log", Command: "grep -n {{.pattern}} /var/log/{{.file}}.log"})
+	if err != nil {
+		t.Fatalf("add template: %v", err)
+	}
+
+	command, args, err := tmpl.Render(map[string]string{"pattern": "out of memory", "file": "syslog"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if command != "grep" || strings.Join(args, "|") != "-n|out of memory|/var/log/syslog.log" {
+		t.Fatalf("unexpected render %q %q", command, args)
+	}
+
+	for _, params := range []map[string]string{
+		{"pattern": "x"},
+		{"pattern": "x", "file": ""},
+		{"pattern": "x\n; reboot", "file": "syslog"},
+		{"pattern": "x", "file": "syslog", "extra": "y"},
+	} {
+		if _, _, err := tmpl.Render(params); err == nil {
+			t.Fatalf("expected params %v to be rejected", params)
+		}
+	}
+}
+
+func TestLevelAllows(t *testing.T) {
+	if !LevelAllows(protocol.CapRemediate, protocol.CapDiagnose) {
+		t.Fatal("remediate probe should run diagnose templates")
+	}
+	if LevelAllows(protocol.CapObserve, protocol.CapDiagnose) {
+		t.Fatal("observe probe must not run diagnose templates")
+	}
+}
+
+func TestPersistentLibraryRoundTrip(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "command_templates.db")
+	lib, err := NewPersistentLibrary(dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := lib.Add(Template{ID: "restart-service", Command: "systemctl restart {{.service}}", MinLevel: protocol.CapRemediate, CreatedBy: "admin"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := lib.Add(Template{ID: "uptime", Command: "uptime"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := lib.Delete("uptime"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	_ = lib.Close()
+
+	reopened, err := NewPersistentLibrary(dbPath)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+
+	templates := reopened.List()
+	if len(templates) != 1 {
+		t.Fatalf("expected 1 template after reopen, got %d", len(templates))
+	}
+	got := templates[0]
+	if got.ID != "restart-service" || got.MinLevel != protocol.CapRemediate || got.CreatedBy != "admin" || len(got.Params) != 1 {
+		t.Fatalf("unexpected reloaded template %+v", got)
+	}
+	if err := reopened.Delete("uptime"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
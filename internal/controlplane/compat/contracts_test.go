@@ -175,7 +175,7 @@ func mustExtractAPIRoutes(t *testing.T, repoRoot string) []string {
 		if pattern == "" {
 			continue
 		}
-		if strings.Contains(pattern, "/api/v1/") || pattern == "GET /healthz" || pattern == "GET /version" || pattern == "GET /mcp" || pattern == "POST /mcp" {
+		if strings.Contains(pattern, "/api/v1/") || pattern == "GET /healthz" || pattern == "GET /readyz" || pattern == "GET /version" || pattern == "GET /mcp" || pattern == "POST /mcp" {
 			seen[pattern] = struct{}{}
 		}
 	}
@@ -23,7 +23,10 @@ func (m *mockFleet) Register(_, _, _, _ string) *fleet.ProbeState { return nil }
 func (m *mockFleet) RegisterRemote(_ fleet.RemoteProbeRegistration) (*fleet.ProbeState, error) {
 	return nil, nil
 }
-func (m *mockFleet) Heartbeat(_ string, _ *protocol.HeartbeatPayload) error       { return nil }
+func (m *mockFleet) Heartbeat(_ string, _ *protocol.HeartbeatPayload) error { return nil }
+func (m *mockFleet) RecordHealthCheck(_ string, _ *protocol.HeartbeatPayload, _ []string) (fleet.HealthScore, error) {
+	return fleet.HealthScore{}, nil
+}
 func (m *mockFleet) UpdateInventory(_ string, _ *protocol.InventoryPayload) error { return nil }
 func (m *mockFleet) Get(_ string) (*fleet.ProbeState, bool)                       { return nil, false }
 func (m *mockFleet) FindByHostname(_ string) (*fleet.ProbeState, bool)            { return nil, false }
@@ -31,6 +34,9 @@ func (m *mockFleet) ListRemote() []*fleet.ProbeState
 func (m *mockFleet) Inventory(_ fleet.InventoryFilter) fleet.FleetInventory {
 	return fleet.FleetInventory{}
 }
+func (m *mockFleet) Liveness(_ string) (fleet.ProbeLiveness, bool) {
+	return fleet.ProbeLiveness{}, false
+}
 func (m *mockFleet) SetPolicy(_ string, _ protocol.CapabilityLevel) error { return nil }
 func (m *mockFleet) SetAPIKey(_, _ string) error                          { return nil }
 func (m *mockFleet) SetStatus(_, _ string) error                          { return nil }
@@ -44,6 +50,14 @@ func (m *mockFleet) Delete(_ string) error                                { retu
 func (m *mockFleet) CleanupOffline(_ time.Duration) []string              { return nil }
 func (m *mockFleet) SetTenantID(_, _ string) error                        { return nil }
 func (m *mockFleet) ListByTenant(_ string) []*fleet.ProbeState            { return nil }
+func (m *mockFleet) SetDraining(_ string, _ bool) error                   { return nil }
+func (m *mockFleet) SetRateLimited(_ string, _ time.Time) error           { return nil }
+func (m *mockFleet) RotateAPIKey(_, _ string, _ time.Duration) error      { return nil }
+func (m *mockFleet) RevertAPIKeyRotation(_ string) error                  { return nil }
+func (m *mockFleet) SetAnnotations(_ string, _ map[string]string) error   { return nil }
+func (m *mockFleet) PinRemoteHostKey(_, _ string) error                   { return nil }
+func (m *mockFleet) SetOfflineThreshold(_ string, _ time.Duration) error  { return nil }
+func (m *mockFleet) SetHeartbeatInterval(_ string, _ time.Duration) error { return nil }
 
 // Compile-time check.
 var _ fleet.Fleet = (*mockFleet)(nil)
@@ -30,12 +30,30 @@ type Config struct {
 	// Auth
 	AuthEnabled bool `json:"auth_enabled"`
 
+	// LoginLockout temporarily locks password login for usernames and
+	// source IPs after repeated failures.
+	LoginLockout LoginLockoutConfig `json:"login_lockout,omitempty"`
+
 	// OIDC settings (optional)
 	OIDC oidc.Config `json:"oidc,omitempty"`
 
 	// Signing key for HMAC (hex-encoded, 64+ chars)
 	SigningKey string `json:"signing_key,omitempty"`
 
+	// ProbeKeyMaxAge enables scheduled probe API key rotation: connected
+	// probes whose key is older than this (e.g. "30d", "720h") are rotated.
+	// Empty disables scheduled rotation.
+	ProbeKeyMaxAge string `json:"probe_key_max_age,omitempty"`
+
+	// ProbeOfflineThreshold is how long a probe may go unseen before it is
+	// marked offline (e.g. "90s", "5m"), unless the probe has its own
+	// threshold or advertised a heartbeat interval. Default 90s.
+	ProbeOfflineThreshold string `json:"probe_offline_threshold,omitempty"`
+
+	// ProbeTokenTTL is the lifetime of the short-lived WebSocket tokens probes
+	// can exchange their API key for (e.g. "15m"). Default 15m.
+	ProbeTokenTTL string `json:"probe_token_ttl,omitempty"`
+
 	// LLM settings
 	LLM LLMConfig `json:"llm,omitempty"`
 
@@ -48,6 +66,12 @@ type Config struct {
 	// Grafana adapter settings (optional)
 	Grafana GrafanaConfig `json:"grafana,omitempty"`
 
+	// NetBox inventory source settings (optional)
+	Netbox NetboxConfig `json:"netbox,omitempty"`
+
+	// Tailscale inventory source settings (optional)
+	Tailscale TailscaleConfig `json:"tailscale,omitempty"`
+
 	// Scheduled jobs defaults
 	Jobs JobsConfig `json:"jobs,omitempty"`
 
@@ -70,6 +94,23 @@ type Config struct {
 	// Sandbox controls the sandbox session lifecycle API.
 	Sandbox SandboxConfig `json:"sandbox,omitempty"`
 
+	// Chat controls persisted probe/fleet chat history limits.
+	Chat ChatConfig `json:"chat,omitempty"`
+
+	// Webhooks controls retry of failed webhook deliveries.
+	Webhooks WebhookDeliveryConfig `json:"webhooks,omitempty"`
+
+	// Health configures the resource thresholds used to score probe health.
+	Health HealthConfig `json:"health,omitempty"`
+
+	// ResourceSeries sizes the in-memory short-term CPU/memory/disk samples
+	// kept per probe.
+	ResourceSeries ResourceSeriesConfig `json:"resource_series,omitempty"`
+
+	// SSE controls keepalives and the client limit of the server-sent
+	// event streams.
+	SSE SSEConfig `json:"sse,omitempty"`
+
 	// Log level (debug, info, warn, error)
 	LogLevel string `json:"log_level"`
 
@@ -96,6 +137,17 @@ type LLMConfig struct {
 	BaseURL  string `json:"base_url,omitempty"`
 	APIKey   string `json:"api_key,omitempty"`
 	Model    string `json:"model,omitempty"`
+
+	// Pricing sets USD prices per million tokens by model name (matched by
+	// substring) for Model Dock cost estimates. Unlisted models use built-in
+	// estimates.
+	Pricing map[string]LLMTokenPrice `json:"pricing,omitempty"`
+}
+
+// LLMTokenPrice is the USD price per million input and output tokens.
+type LLMTokenPrice struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
 }
 
 // RateLimitConfig configures per-key rate limiting.
@@ -125,6 +177,31 @@ type GrafanaConfig struct {
 	OrgID          int    `json:"org_id,omitempty"`
 }
 
+// NetboxConfig controls the NetBox federation inventory source.
+type NetboxConfig struct {
+	Enabled       bool   `json:"enabled"`
+	BaseURL       string `json:"base_url,omitempty"`
+	APIToken      string `json:"api_token,omitempty"`
+	SyncInterval  string `json:"sync_interval,omitempty"`
+	Timeout       string `json:"timeout,omitempty"`
+	TLSSkipVerify bool   `json:"tls_skip_verify,omitempty"`
+	// StaleAfter is how old the last successful sync may get before the
+	// source is reported stale. Empty means twice the sync interval.
+	StaleAfter string `json:"stale_after,omitempty"`
+}
+
+// TailscaleConfig controls the Tailscale federation inventory source.
+type TailscaleConfig struct {
+	Enabled      bool   `json:"enabled"`
+	BaseURL      string `json:"base_url,omitempty"`
+	APIKey       string `json:"api_key,omitempty"`
+	Tailnet      string `json:"tailnet,omitempty"`
+	SyncInterval string `json:"sync_interval,omitempty"`
+	Timeout      string `json:"timeout,omitempty"`
+	// StaleAfter works as for NetboxConfig.
+	StaleAfter string `json:"stale_after,omitempty"`
+}
+
 // JobsConfig controls scheduler defaults for retry behavior and async worker bounds.
 type JobsConfig struct {
 	RetryMaxAttempts    int     `json:"retry_max_attempts,omitempty"`
@@ -148,6 +225,10 @@ type JobsConfig struct {
 	RunnerSandboxRuntimeCommand string `json:"runner_sandbox_runtime_command,omitempty"`
 	RunnerSandboxImage          string `json:"runner_sandbox_image,omitempty"`
 	RunnerSandboxTimeout        string `json:"runner_sandbox_timeout,omitempty"`
+	DependencyWaitTimeout       string `json:"dependency_wait_timeout,omitempty"`
+	RunTimeout                  string `json:"run_timeout,omitempty"`
+	RunnerArtifactMaxRunBytes   int64  `json:"runner_artifact_max_run_bytes,omitempty"`
+	RunnerArtifactRetention     string `json:"runner_artifact_retention,omitempty"`
 }
 
 // TokenBrokerConfig controls scoped token defaults and scope bounds.
@@ -159,6 +240,10 @@ type TokenBrokerConfig struct {
 type ProviderProxyConfig struct {
 	MaxTokensPerRun int     `json:"max_tokens_per_run,omitempty"`
 	MaxCostPerRun   float64 `json:"max_cost_per_run,omitempty"`
+
+	// MonthlyBudgetUSD caps estimated provider spend per UTC calendar month
+	// across all runs. Zero disables the budget.
+	MonthlyBudgetUSD float64 `json:"monthly_budget_usd,omitempty"`
 }
 
 type WorkspaceIsolationConfig struct {
@@ -167,11 +252,294 @@ type WorkspaceIsolationConfig struct {
 
 type ApprovalConfig struct {
 	TwoPersonMode bool `json:"two_person_mode,omitempty"`
+
+	// MaxTTL bounds the per-request expires_in accepted on command dispatch.
+	MaxTTL string `json:"max_ttl,omitempty"`
+
+	// RequireReason maps a risk level (low/medium/high/critical) to when a
+	// decision on it must include a reason: "deny", "all" or "none".
+	RequireReason map[string]string `json:"require_reason,omitempty"`
+
+	// EscalateAfter is the fraction of an approval's TTL (e.g. 0.5) after
+	// which a still-pending request is re-notified. Zero disables escalation.
+	EscalateAfter float64 `json:"escalate_after,omitempty"`
+
+	// EscalationChannel is the ID of a notification channel that escalations
+	// are also sent to, typically a higher-severity one than the default.
+	EscalationChannel string `json:"escalation_channel,omitempty"`
+}
+
+// EscalationFraction returns EscalateAfter, or zero (disabled) when it is
+// outside (0, 1).
+func (a ApprovalConfig) EscalationFraction() float64 {
+	if a.EscalateAfter <= 0 || a.EscalateAfter >= 1 {
+		return 0
+	}
+	return a.EscalateAfter
+}
+
+// ReasonRequirements returns RequireReason with lower-cased, trimmed keys
+// and values. Entries with an empty level or value are dropped.
+func (a ApprovalConfig) ReasonRequirements() map[string]string {
+	out := make(map[string]string, len(a.RequireReason))
+	for level, requirement := range a.RequireReason {
+		level = strings.ToLower(strings.TrimSpace(level))
+		requirement = strings.ToLower(strings.TrimSpace(requirement))
+		if level == "" || requirement == "" {
+			continue
+		}
+		out[level] = requirement
+	}
+	return out
+}
+
+// MaxTTLDuration returns the upper bound for per-request approval expiry.
+func (a ApprovalConfig) MaxTTLDuration() time.Duration {
+	raw := strings.TrimSpace(a.MaxTTL)
+	if raw == "" {
+		return 24 * time.Hour
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 24 * time.Hour
+	}
+	return d
+}
+
+// ChatConfig bounds persisted chat history.
+type ChatConfig struct {
+	// MaxMessagesPerProbe caps stored messages per thread (probe or fleet);
+	// the oldest are purged first.
+	MaxMessagesPerProbe int `json:"max_messages_per_probe,omitempty"`
+
+	// Retention purges messages older than this duration (e.g. "72h").
+	Retention string `json:"retention,omitempty"`
+}
+
+// MaxMessages returns the per-thread chat history cap.
+func (c ChatConfig) MaxMessages() int {
+	if c.MaxMessagesPerProbe <= 0 {
+		return 500
+	}
+	return c.MaxMessagesPerProbe
+}
+
+// RetentionDuration returns the chat history retention window.
+func (c ChatConfig) RetentionDuration() time.Duration {
+	raw := strings.TrimSpace(c.Retention)
+	if raw == "" {
+		return 24 * time.Hour
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 24 * time.Hour
+	}
+	return d
+}
+
+// HealthConfig sets the resource-pressure thresholds that lower a probe's
+// health score. Zero values use the built-in defaults (load 4/8, memory
+// 85/95%, disk 80/95%, 5% hysteresis).
+type HealthConfig struct {
+	LoadHigh          float64 `json:"load_high,omitempty"`
+	LoadCritical      float64 `json:"load_critical,omitempty"`
+	MemoryHighPct     float64 `json:"memory_high_pct,omitempty"`
+	MemoryCriticalPct float64 `json:"memory_critical_pct,omitempty"`
+	DiskHighPct       float64 `json:"disk_high_pct,omitempty"`
+	DiskCriticalPct   float64 `json:"disk_critical_pct,omitempty"`
+
+	// HysteresisPct is how far (as a percent of the threshold) usage must
+	// fall below a threshold before the pressure it raised clears.
+	HysteresisPct float64 `json:"hysteresis_pct,omitempty"`
+}
+
+// ResourceSeriesConfig bounds the rolling per-probe resource samples served
+// for probe detail graphs. Memory is at most Window/Interval samples (capped
+// at 1440) per probe.
+type ResourceSeriesConfig struct {
+	// Window is how far back samples are kept (e.g. "60m").
+	Window string `json:"window,omitempty"`
+
+	// Interval is the minimum spacing between kept samples (e.g. "30s").
+	Interval string `json:"interval,omitempty"`
+}
+
+// WindowDuration returns the resource series window.
+func (c ResourceSeriesConfig) WindowDuration() time.Duration {
+	raw := strings.TrimSpace(c.Window)
+	if raw == "" {
+		return time.Hour
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return time.Hour
+	}
+	return d
+}
+
+// IntervalDuration returns the resource series sample spacing.
+func (c ResourceSeriesConfig) IntervalDuration() time.Duration {
+	raw := strings.TrimSpace(c.Interval)
+	if raw == "" {
+		return 30 * time.Second
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 30 * time.Second
+	}
+	return d
+}
+
+// SSEConfig controls the event and command output SSE streams.
+type SSEConfig struct {
+	// KeepaliveInterval is how often an idle stream gets a comment line so
+	// proxies with idle timeouts keep it open (e.g. "15s"). "0" disables.
+	KeepaliveInterval string `json:"keepalive_interval,omitempty"`
+
+	// MaxClients caps concurrent SSE clients across the streams (default
+	// 1000). Negative is unlimited.
+	MaxClients int `json:"max_clients,omitempty"`
+}
+
+// KeepaliveDuration returns the keepalive interval, or zero when disabled.
+func (c SSEConfig) KeepaliveDuration() time.Duration {
+	raw := strings.TrimSpace(c.KeepaliveInterval)
+	if raw == "" {
+		return 15 * time.Second
+	}
+	if raw == "0" {
+		return 0
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 15 * time.Second
+	}
+	return d
+}
+
+// ClientLimit returns the SSE client cap, or zero for unlimited.
+func (c SSEConfig) ClientLimit() int {
+	switch {
+	case c.MaxClients < 0:
+		return 0
+	case c.MaxClients == 0:
+		return 1000
+	default:
+		return c.MaxClients
+	}
+}
+
+// LoginLockoutConfig controls temporary lockout of POST /login.
+type LoginLockoutConfig struct {
+	// Disabled turns lockout off; failures are still audited.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// MaxAttempts is the failed logins for one username within Window
+	// that lock it (default 5).
+	MaxAttempts int `json:"max_attempts,omitempty"`
+
+	// IPMaxAttempts is the failed logins from one source IP within Window,
+	// across usernames, that lock it (default 0, off). The source IP is the
+	// peer address, so leave it off behind a reverse proxy.
+	IPMaxAttempts int `json:"ip_max_attempts,omitempty"`
+
+	// Window is how long a failure keeps counting after the latest one
+	// (e.g. "15m").
+	Window string `json:"window,omitempty"`
+
+	// Duration is how long a lockout lasts before it clears (e.g. "15m").
+	Duration string `json:"duration,omitempty"`
+}
+
+// WindowDuration returns the failure counting window.
+func (c LoginLockoutConfig) WindowDuration() time.Duration {
+	raw := strings.TrimSpace(c.Window)
+	if raw == "" {
+		return 15 * time.Minute
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 15 * time.Minute
+	}
+	return d
+}
+
+// LockDuration returns how long a lockout lasts.
+func (c LoginLockoutConfig) LockDuration() time.Duration {
+	raw := strings.TrimSpace(c.Duration)
+	if raw == "" {
+		return 15 * time.Minute
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 15 * time.Minute
+	}
+	return d
+}
+
+// WebhookDeliveryConfig controls webhook delivery retries. Deliveries that
+// fail every attempt are moved to the dead-letter list.
+type WebhookDeliveryConfig struct {
+	// MaxAttempts is the total number of delivery attempts, including the first.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+
+	// RetryBackoff is the delay before the first retry (e.g. "1s"); it
+	// doubles on each further retry up to RetryMaxBackoff.
+	RetryBackoff    string `json:"retry_backoff,omitempty"`
+	RetryMaxBackoff string `json:"retry_max_backoff,omitempty"`
+}
+
+// MaxAttemptsOrDefault returns the configured attempt budget.
+func (w WebhookDeliveryConfig) MaxAttemptsOrDefault() int {
+	if w.MaxAttempts <= 0 {
+		return 5
+	}
+	return w.MaxAttempts
+}
+
+// RetryBackoffDuration returns the delay before the first retry.
+func (w WebhookDeliveryConfig) RetryBackoffDuration() time.Duration {
+	raw := strings.TrimSpace(w.RetryBackoff)
+	if raw == "" {
+		return time.Second
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return time.Second
+	}
+	return d
+}
+
+// RetryMaxBackoffDuration returns the cap on the delay between retries.
+func (w WebhookDeliveryConfig) RetryMaxBackoffDuration() time.Duration {
+	raw := strings.TrimSpace(w.RetryMaxBackoff)
+	if raw == "" {
+		return 5 * time.Minute
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 5 * time.Minute
+	}
+	return d
 }
 
 type AuditConfig struct {
 	ChainMode bool   `json:"chain_mode,omitempty"`
 	ChainKey  string `json:"chain_key,omitempty"`
+
+	// Syslog optionally forwards every audit event to a SIEM as CEF over
+	// RFC 5424 syslog. Disabled when Address is empty.
+	Syslog AuditSyslogConfig `json:"syslog,omitempty"`
+}
+
+// AuditSyslogConfig configures the syslog/CEF audit forwarder.
+type AuditSyslogConfig struct {
+	Address       string `json:"address,omitempty"`  // host:port
+	Protocol      string `json:"protocol,omitempty"` // tcp (default) or tls
+	CAPath        string `json:"ca_path,omitempty"`
+	TLSSkipVerify bool   `json:"tls_skip_verify,omitempty"`
+	BufferSize    int    `json:"buffer_size,omitempty"`
+	AppName       string `json:"app_name,omitempty"`
 }
 
 // SandboxConfig controls the sandbox session lifecycle API.
@@ -242,6 +610,78 @@ func (g GrafanaConfig) TimeoutDuration() time.Duration {
 	return d
 }
 
+func (n NetboxConfig) SyncIntervalDuration() time.Duration {
+	raw := strings.TrimSpace(n.SyncInterval)
+	if raw == "" {
+		return 5 * time.Minute
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 5 * time.Minute
+	}
+	return d
+}
+
+// StaleAfterDuration returns the configured staleness threshold, or 0 to use
+// twice the sync interval.
+func (n NetboxConfig) StaleAfterDuration() time.Duration {
+	return staleAfterDuration(n.StaleAfter)
+}
+
+func (n NetboxConfig) TimeoutDuration() time.Duration {
+	raw := strings.TrimSpace(n.Timeout)
+	if raw == "" {
+		return 15 * time.Second
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 15 * time.Second
+	}
+	return d
+}
+
+func (t TailscaleConfig) SyncIntervalDuration() time.Duration {
+	raw := strings.TrimSpace(t.SyncInterval)
+	if raw == "" {
+		return 5 * time.Minute
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 5 * time.Minute
+	}
+	return d
+}
+
+// StaleAfterDuration returns the configured staleness threshold, or 0 to use
+// twice the sync interval.
+func (t TailscaleConfig) StaleAfterDuration() time.Duration {
+	return staleAfterDuration(t.StaleAfter)
+}
+
+func (t TailscaleConfig) TimeoutDuration() time.Duration {
+	raw := strings.TrimSpace(t.Timeout)
+	if raw == "" {
+		return 15 * time.Second
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 15 * time.Second
+	}
+	return d
+}
+
+func staleAfterDuration(raw string) time.Duration {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
 func (g GrafanaConfig) DashboardLimitOrDefault() int {
 	if g.DashboardLimit <= 0 {
 		return 10
@@ -304,6 +744,34 @@ func (j JobsConfig) RunTokenTTLDuration() time.Duration {
 	return d
 }
 
+// RunTimeoutDuration is the per-attempt timeout for scheduled jobs that do not
+// set their own timeout.
+func (j JobsConfig) RunTimeoutDuration() time.Duration {
+	raw := strings.TrimSpace(j.RunTimeout)
+	if raw == "" {
+		return time.Minute
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return time.Minute
+	}
+	return d
+}
+
+// DependencyWaitTimeoutDuration is how long a due job waits for its
+// dependencies before the cycle is skipped, unless the job sets its own.
+func (j JobsConfig) DependencyWaitTimeoutDuration() time.Duration {
+	raw := strings.TrimSpace(j.DependencyWaitTimeout)
+	if raw == "" {
+		return time.Hour
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return time.Hour
+	}
+	return d
+}
+
 func (j JobsConfig) RunnerSandboxTimeoutDuration() time.Duration {
 	raw := strings.TrimSpace(j.RunnerSandboxTimeout)
 	if raw == "" {
@@ -316,6 +784,29 @@ func (j JobsConfig) RunnerSandboxTimeoutDuration() time.Duration {
 	return d
 }
 
+// RunnerArtifactMaxRunBytesOrDefault caps the total artifact size per run
+// (default 100 MiB).
+func (j JobsConfig) RunnerArtifactMaxRunBytesOrDefault() int64 {
+	if j.RunnerArtifactMaxRunBytes <= 0 {
+		return 100 << 20
+	}
+	return j.RunnerArtifactMaxRunBytes
+}
+
+// RunnerArtifactRetentionDuration is how long run artifacts are kept after
+// their last upload (default 30 days).
+func (j JobsConfig) RunnerArtifactRetentionDuration() time.Duration {
+	raw := strings.TrimSpace(j.RunnerArtifactRetention)
+	if raw == "" {
+		return 30 * 24 * time.Hour
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return d
+}
+
 func (p ProbeMTLSConfig) ModeOrDefault() string {
 	switch strings.ToLower(strings.TrimSpace(p.Mode)) {
 	case "optional", "required":
@@ -399,6 +890,18 @@ func Default() Config {
 			Timeout:        "10s",
 			DashboardLimit: 10,
 		},
+		Netbox: NetboxConfig{
+			Enabled:      false,
+			SyncInterval: "5m",
+			Timeout:      "15s",
+		},
+		Tailscale: TailscaleConfig{
+			Enabled:      false,
+			BaseURL:      "https://api.tailscale.com",
+			Tailnet:      "-",
+			SyncInterval: "5m",
+			Timeout:      "15s",
+		},
 		Jobs: JobsConfig{
 			AsyncMaxInFlight:            8,
 			AsyncMaxQueueDepth:          500,
@@ -495,6 +998,15 @@ func Load(path string) (Config, error) {
 	if v := os.Getenv("LEGATOR_SIGNING_KEY"); v != "" {
 		cfg.SigningKey = v
 	}
+	if v := os.Getenv("LEGATOR_PROBE_KEY_MAX_AGE"); v != "" {
+		cfg.ProbeKeyMaxAge = v
+	}
+	if v := os.Getenv("LEGATOR_PROBE_OFFLINE_THRESHOLD"); v != "" {
+		cfg.ProbeOfflineThreshold = v
+	}
+	if v := os.Getenv("LEGATOR_PROBE_TOKEN_TTL"); v != "" {
+		cfg.ProbeTokenTTL = v
+	}
 	if v := os.Getenv("LEGATOR_LLM_PROVIDER"); v != "" {
 		cfg.LLM.Provider = v
 	}
@@ -507,6 +1019,12 @@ func Load(path string) (Config, error) {
 	if v := os.Getenv("LEGATOR_LLM_MODEL"); v != "" {
 		cfg.LLM.Model = v
 	}
+	if v := os.Getenv("LEGATOR_LLM_PRICING"); v != "" {
+		var pricing map[string]LLMTokenPrice
+		if err := json.Unmarshal([]byte(v), &pricing); err == nil {
+			cfg.LLM.Pricing = pricing
+		}
+	}
 	if v := os.Getenv("LEGATOR_LOG_LEVEL"); v != "" {
 		cfg.LogLevel = v
 	}
@@ -519,6 +1037,26 @@ func Load(path string) (Config, error) {
 	if v := os.Getenv("LEGATOR_AUDIT_CHAIN_KEY"); v != "" {
 		cfg.Audit.ChainKey = v
 	}
+	if v := os.Getenv("LEGATOR_AUDIT_SYSLOG_ADDR"); v != "" {
+		cfg.Audit.Syslog.Address = v
+	}
+	if v := os.Getenv("LEGATOR_AUDIT_SYSLOG_PROTOCOL"); v != "" {
+		cfg.Audit.Syslog.Protocol = v
+	}
+	if v := os.Getenv("LEGATOR_AUDIT_SYSLOG_CA_PATH"); v != "" {
+		cfg.Audit.Syslog.CAPath = v
+	}
+	if v := os.Getenv("LEGATOR_AUDIT_SYSLOG_TLS_SKIP_VERIFY"); v != "" {
+		cfg.Audit.Syslog.TLSSkipVerify = v == "true" || v == "1"
+	}
+	if v := os.Getenv("LEGATOR_AUDIT_SYSLOG_BUFFER_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Audit.Syslog.BufferSize = n
+		}
+	}
+	if v := os.Getenv("LEGATOR_AUDIT_SYSLOG_APP_NAME"); v != "" {
+		cfg.Audit.Syslog.AppName = v
+	}
 	if v := os.Getenv("LEGATOR_RATE_LIMIT"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil {
 			cfg.RateLimit.RequestsPerMinute = n
@@ -578,6 +1116,48 @@ func Load(path string) (Config, error) {
 			cfg.Jobs.RetryMaxAttempts = n
 		}
 	}
+	if v := os.Getenv("LEGATOR_NETBOX_ENABLED"); v != "" {
+		cfg.Netbox.Enabled = v == "true" || v == "1"
+	}
+	if v := os.Getenv("LEGATOR_NETBOX_BASE_URL"); v != "" {
+		cfg.Netbox.BaseURL = v
+	}
+	if v := os.Getenv("LEGATOR_NETBOX_API_TOKEN"); v != "" {
+		cfg.Netbox.APIToken = v
+	}
+	if v := os.Getenv("LEGATOR_NETBOX_SYNC_INTERVAL"); v != "" {
+		cfg.Netbox.SyncInterval = v
+	}
+	if v := os.Getenv("LEGATOR_NETBOX_TIMEOUT"); v != "" {
+		cfg.Netbox.Timeout = v
+	}
+	if v := os.Getenv("LEGATOR_NETBOX_TLS_SKIP_VERIFY"); v != "" {
+		cfg.Netbox.TLSSkipVerify = v == "true" || v == "1"
+	}
+	if v := os.Getenv("LEGATOR_NETBOX_STALE_AFTER"); v != "" {
+		cfg.Netbox.StaleAfter = v
+	}
+	if v := os.Getenv("LEGATOR_TAILSCALE_ENABLED"); v != "" {
+		cfg.Tailscale.Enabled = v == "true" || v == "1"
+	}
+	if v := os.Getenv("LEGATOR_TAILSCALE_BASE_URL"); v != "" {
+		cfg.Tailscale.BaseURL = v
+	}
+	if v := os.Getenv("LEGATOR_TAILSCALE_API_KEY"); v != "" {
+		cfg.Tailscale.APIKey = v
+	}
+	if v := os.Getenv("LEGATOR_TAILSCALE_TAILNET"); v != "" {
+		cfg.Tailscale.Tailnet = v
+	}
+	if v := os.Getenv("LEGATOR_TAILSCALE_SYNC_INTERVAL"); v != "" {
+		cfg.Tailscale.SyncInterval = v
+	}
+	if v := os.Getenv("LEGATOR_TAILSCALE_TIMEOUT"); v != "" {
+		cfg.Tailscale.Timeout = v
+	}
+	if v := os.Getenv("LEGATOR_TAILSCALE_STALE_AFTER"); v != "" {
+		cfg.Tailscale.StaleAfter = v
+	}
 	if v := os.Getenv("LEGATOR_JOBS_RETRY_INITIAL_BACKOFF"); v != "" {
 		cfg.Jobs.RetryInitialBackoff = v
 	}
@@ -645,6 +1225,20 @@ func Load(path string) (Config, error) {
 	if v := os.Getenv("LEGATOR_JOBS_RUNNER_SANDBOX_TIMEOUT"); v != "" {
 		cfg.Jobs.RunnerSandboxTimeout = v
 	}
+	if v := os.Getenv("LEGATOR_JOBS_RUN_TIMEOUT"); v != "" {
+		cfg.Jobs.RunTimeout = v
+	}
+	if v := os.Getenv("LEGATOR_JOBS_RUNNER_ARTIFACT_MAX_RUN_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			cfg.Jobs.RunnerArtifactMaxRunBytes = n
+		}
+	}
+	if v := os.Getenv("LEGATOR_JOBS_RUNNER_ARTIFACT_RETENTION"); v != "" {
+		cfg.Jobs.RunnerArtifactRetention = v
+	}
+	if v := os.Getenv("LEGATOR_JOBS_DEPENDENCY_WAIT_TIMEOUT"); v != "" {
+		cfg.Jobs.DependencyWaitTimeout = v
+	}
 	if v := os.Getenv("LEGATOR_TOKEN_BROKER_DEFAULT_TTL"); v != "" {
 		cfg.TokenBroker.DefaultTTL = v
 	}
@@ -663,6 +1257,11 @@ func Load(path string) (Config, error) {
 			cfg.ProviderProxy.MaxCostPerRun = f
 		}
 	}
+	if v := os.Getenv("LEGATOR_PROVIDER_PROXY_MONTHLY_BUDGET_USD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.ProviderProxy.MonthlyBudgetUSD = f
+		}
+	}
 	if v := os.Getenv("LEGATOR_MCP_ENABLED"); v != "" {
 		cfg.MCPEnabled = v == "true" || v == "1"
 	}
@@ -675,6 +1274,98 @@ func Load(path string) (Config, error) {
 	if v := os.Getenv("LEGATOR_APPROVAL_TWO_PERSON_MODE"); v != "" {
 		cfg.Approval.TwoPersonMode = v == "true" || v == "1"
 	}
+	if v := os.Getenv("LEGATOR_APPROVAL_MAX_TTL"); v != "" {
+		cfg.Approval.MaxTTL = v
+	}
+	if v := os.Getenv("LEGATOR_APPROVAL_REQUIRE_REASON"); v != "" {
+		cfg.Approval.RequireReason = make(map[string]string)
+		for _, pair := range strings.Split(v, ",") {
+			if level, requirement, ok := strings.Cut(pair, "="); ok {
+				cfg.Approval.RequireReason[strings.TrimSpace(level)] = strings.TrimSpace(requirement)
+			}
+		}
+	}
+	if v := os.Getenv("LEGATOR_APPROVAL_ESCALATE_AFTER"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.Approval.EscalateAfter = f
+		}
+	}
+	if v := os.Getenv("LEGATOR_APPROVAL_ESCALATION_CHANNEL"); v != "" {
+		cfg.Approval.EscalationChannel = v
+	}
+
+	if v := os.Getenv("LEGATOR_CHAT_MAX_MESSAGES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Chat.MaxMessagesPerProbe = n
+		}
+	}
+	if v := os.Getenv("LEGATOR_CHAT_RETENTION"); v != "" {
+		cfg.Chat.Retention = v
+	}
+
+	if v := os.Getenv("LEGATOR_RESOURCE_SERIES_WINDOW"); v != "" {
+		cfg.ResourceSeries.Window = v
+	}
+	if v := os.Getenv("LEGATOR_RESOURCE_SERIES_INTERVAL"); v != "" {
+		cfg.ResourceSeries.Interval = v
+	}
+
+	if v := os.Getenv("LEGATOR_SSE_KEEPALIVE_INTERVAL"); v != "" {
+		cfg.SSE.KeepaliveInterval = v
+	}
+	if v := os.Getenv("LEGATOR_SSE_MAX_CLIENTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.SSE.MaxClients = n
+		}
+	}
+
+	if v := os.Getenv("LEGATOR_LOGIN_LOCKOUT_DISABLED"); v != "" {
+		cfg.LoginLockout.Disabled = v == "true" || v == "1"
+	}
+	if v := os.Getenv("LEGATOR_LOGIN_LOCKOUT_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.LoginLockout.MaxAttempts = n
+		}
+	}
+	if v := os.Getenv("LEGATOR_LOGIN_LOCKOUT_IP_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.LoginLockout.IPMaxAttempts = n
+		}
+	}
+	if v := os.Getenv("LEGATOR_LOGIN_LOCKOUT_WINDOW"); v != "" {
+		cfg.LoginLockout.Window = v
+	}
+	if v := os.Getenv("LEGATOR_LOGIN_LOCKOUT_DURATION"); v != "" {
+		cfg.LoginLockout.Duration = v
+	}
+
+	if v := os.Getenv("LEGATOR_WEBHOOK_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Webhooks.MaxAttempts = n
+		}
+	}
+	if v := os.Getenv("LEGATOR_WEBHOOK_RETRY_BACKOFF"); v != "" {
+		cfg.Webhooks.RetryBackoff = v
+	}
+	if v := os.Getenv("LEGATOR_WEBHOOK_RETRY_MAX_BACKOFF"); v != "" {
+		cfg.Webhooks.RetryMaxBackoff = v
+	}
+
+	for env, field := range map[string]*float64{
+		"LEGATOR_HEALTH_LOAD_HIGH":           &cfg.Health.LoadHigh,
+		"LEGATOR_HEALTH_LOAD_CRITICAL":       &cfg.Health.LoadCritical,
+		"LEGATOR_HEALTH_MEMORY_HIGH_PCT":     &cfg.Health.MemoryHighPct,
+		"LEGATOR_HEALTH_MEMORY_CRITICAL_PCT": &cfg.Health.MemoryCriticalPct,
+		"LEGATOR_HEALTH_DISK_HIGH_PCT":       &cfg.Health.DiskHighPct,
+		"LEGATOR_HEALTH_DISK_CRITICAL_PCT":   &cfg.Health.DiskCriticalPct,
+		"LEGATOR_HEALTH_HYSTERESIS_PCT":      &cfg.Health.HysteresisPct,
+	} {
+		if v := os.Getenv(env); v != "" {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				*field = f
+			}
+		}
+	}
 
 	if v := os.Getenv("LEGATOR_SANDBOX_MAX_CONCURRENT"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil {
@@ -728,6 +1419,10 @@ type MCPServerConfig struct {
 	Endpoint string `json:"endpoint,omitempty"`
 	// Timeout is the connect/call timeout (default "30s").
 	Timeout string `json:"timeout,omitempty"`
+	// ConnectTimeout overrides Timeout for the connection handshake.
+	ConnectTimeout string `json:"connect_timeout,omitempty"`
+	// ToolTimeouts overrides Timeout for individual tools, keyed by tool name.
+	ToolTimeouts map[string]string `json:"tool_timeouts,omitempty"`
 	// Enabled controls whether this server is active (nil == true by default).
 	Enabled *bool `json:"enabled,omitempty"`
 	// Env are extra environment variables for stdio transport.
@@ -755,6 +1450,28 @@ func (m MCPServerConfig) TimeoutDuration() time.Duration {
 	return d
 }
 
+// ConnectTimeoutDuration parses connect_timeout, falling back to TimeoutDuration.
+func (m MCPServerConfig) ConnectTimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(m.ConnectTimeout)); err == nil && d > 0 {
+		return d
+	}
+	return m.TimeoutDuration()
+}
+
+// ToolTimeoutDurations parses tool_timeouts, skipping invalid entries.
+func (m MCPServerConfig) ToolTimeoutDurations() map[string]time.Duration {
+	if len(m.ToolTimeouts) == 0 {
+		return nil
+	}
+	out := make(map[string]time.Duration, len(m.ToolTimeouts))
+	for tool, raw := range m.ToolTimeouts {
+		if d, err := time.ParseDuration(strings.TrimSpace(raw)); err == nil && d > 0 {
+			out[tool] = d
+		}
+	}
+	return out
+}
+
 // HasTLS returns true if TLS is configured.
 func (c Config) HasTLS() bool {
 	return c.TLSCert != "" && c.TLSKey != ""
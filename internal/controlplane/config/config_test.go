@@ -45,6 +45,15 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.Grafana.DashboardLimitOrDefault() != 10 {
 		t.Errorf("expected grafana dashboard limit default 10, got %d", cfg.Grafana.DashboardLimitOrDefault())
 	}
+	if cfg.Netbox.Enabled {
+		t.Error("expected netbox disabled by default")
+	}
+	if cfg.Netbox.SyncIntervalDuration() != 5*time.Minute {
+		t.Errorf("expected netbox sync interval default 5m, got %s", cfg.Netbox.SyncIntervalDuration())
+	}
+	if cfg.Tailscale.Enabled || cfg.Tailscale.Tailnet != "-" {
+		t.Errorf("expected tailscale disabled with default tailnet, got %+v", cfg.Tailscale)
+	}
 	if cfg.Jobs.StreamMaxEventsPerRequest != 2000 {
 		t.Fatalf("expected stream max events per request 2000, got %d", cfg.Jobs.StreamMaxEventsPerRequest)
 	}
@@ -90,6 +99,18 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.Jobs.RunnerSandboxTimeoutDuration() != 10*time.Minute {
 		t.Errorf("expected sandbox timeout 10m, got %s", cfg.Jobs.RunnerSandboxTimeoutDuration())
 	}
+	if cfg.Jobs.RunnerArtifactMaxRunBytesOrDefault() != 100<<20 {
+		t.Errorf("expected artifact cap default 100MiB, got %d", cfg.Jobs.RunnerArtifactMaxRunBytesOrDefault())
+	}
+	if cfg.Jobs.RunnerArtifactRetentionDuration() != 30*24*time.Hour {
+		t.Errorf("expected artifact retention default 30d, got %s", cfg.Jobs.RunnerArtifactRetentionDuration())
+	}
+	if cfg.Jobs.RunTimeoutDuration() != time.Minute {
+		t.Errorf("expected run timeout 1m, got %s", cfg.Jobs.RunTimeoutDuration())
+	}
+	if cfg.Jobs.DependencyWaitTimeoutDuration() != time.Hour {
+		t.Errorf("expected dependency wait timeout 1h, got %s", cfg.Jobs.DependencyWaitTimeoutDuration())
+	}
 	if cfg.Approval.TwoPersonMode {
 		t.Error("expected two-person approval mode disabled by default")
 	}
@@ -273,14 +294,26 @@ func TestLoadFromEnvOnly(t *testing.T) {
 	t.Setenv("LEGATOR_GRAFANA_DASHBOARD_LIMIT", "40")
 	t.Setenv("LEGATOR_GRAFANA_TLS_SKIP_VERIFY", "1")
 	t.Setenv("LEGATOR_GRAFANA_ORG_ID", "9")
+	t.Setenv("LEGATOR_NETBOX_ENABLED", "true")
+	t.Setenv("LEGATOR_NETBOX_BASE_URL", "https://netbox.example.com")
+	t.Setenv("LEGATOR_NETBOX_SYNC_INTERVAL", "90s")
+	t.Setenv("LEGATOR_TAILSCALE_ENABLED", "1")
+	t.Setenv("LEGATOR_TAILSCALE_API_KEY", "tskey-api-test")
+	t.Setenv("LEGATOR_TAILSCALE_TAILNET", "example.com")
 	t.Setenv("LEGATOR_JOBS_RUN_TOKEN_TTL", "45s")
 	t.Setenv("LEGATOR_JOBS_RUNNER_SANDBOX_RUNTIME_COMMAND", "podman")
 	t.Setenv("LEGATOR_JOBS_RUNNER_SANDBOX_IMAGE", "ghcr.io/example/sandbox:latest")
 	t.Setenv("LEGATOR_JOBS_RUNNER_SANDBOX_TIMEOUT", "95s")
+	t.Setenv("LEGATOR_JOBS_DEPENDENCY_WAIT_TIMEOUT", "20m")
+	t.Setenv("LEGATOR_JOBS_RUN_TIMEOUT", "5m")
+	t.Setenv("LEGATOR_JOBS_RUNNER_ARTIFACT_MAX_RUN_BYTES", "1048576")
+	t.Setenv("LEGATOR_JOBS_RUNNER_ARTIFACT_RETENTION", "168h")
 	t.Setenv("LEGATOR_TOKEN_BROKER_DEFAULT_TTL", "30s")
 	t.Setenv("LEGATOR_TOKEN_BROKER_MAX_SCOPE", "3")
 	t.Setenv("LEGATOR_PROVIDER_PROXY_MAX_TOKENS_PER_RUN", "12000")
 	t.Setenv("LEGATOR_PROVIDER_PROXY_MAX_COST_PER_RUN", "3.75")
+	t.Setenv("LEGATOR_PROVIDER_PROXY_MONTHLY_BUDGET_USD", "250")
+	t.Setenv("LEGATOR_LLM_PRICING", `{"gpt-4o":{"input_per_million":2.5,"output_per_million":10}}`)
 
 	cfg := LoadFromEnv()
 	if cfg.DataDir != "/tmp/env-test" {
@@ -331,6 +364,15 @@ func TestLoadFromEnvOnly(t *testing.T) {
 	if cfg.Grafana.OrgID != 9 {
 		t.Errorf("expected grafana org id 9, got %d", cfg.Grafana.OrgID)
 	}
+	if !cfg.Netbox.Enabled || cfg.Netbox.BaseURL != "https://netbox.example.com" {
+		t.Errorf("expected netbox enabled from env, got %+v", cfg.Netbox)
+	}
+	if cfg.Netbox.SyncIntervalDuration() != 90*time.Second {
+		t.Errorf("expected netbox sync interval 90s, got %s", cfg.Netbox.SyncIntervalDuration())
+	}
+	if !cfg.Tailscale.Enabled || cfg.Tailscale.APIKey != "tskey-api-test" || cfg.Tailscale.Tailnet != "example.com" {
+		t.Errorf("expected tailscale overrides from env, got %+v", cfg.Tailscale)
+	}
 	if cfg.Jobs.RunTokenTTLDuration() != 45*time.Second {
 		t.Errorf("expected run token ttl 45s, got %s", cfg.Jobs.RunTokenTTLDuration())
 	}
@@ -346,6 +388,12 @@ func TestLoadFromEnvOnly(t *testing.T) {
 	if cfg.ProviderProxy.MaxCostPerRun != 3.75 {
 		t.Errorf("expected provider proxy max cost 3.75, got %v", cfg.ProviderProxy.MaxCostPerRun)
 	}
+	if cfg.ProviderProxy.MonthlyBudgetUSD != 250 {
+		t.Errorf("expected provider proxy monthly budget 250, got %v", cfg.ProviderProxy.MonthlyBudgetUSD)
+	}
+	if price := cfg.LLM.Pricing["gpt-4o"]; price.InputPerMillion != 2.5 || price.OutputPerMillion != 10 {
+		t.Errorf("expected gpt-4o pricing 2.5/10, got %+v", price)
+	}
 	if cfg.Jobs.RunnerSandboxRuntimeCommand != "podman" {
 		t.Errorf("expected sandbox runtime podman, got %s", cfg.Jobs.RunnerSandboxRuntimeCommand)
 	}
@@ -355,6 +403,18 @@ func TestLoadFromEnvOnly(t *testing.T) {
 	if cfg.Jobs.RunnerSandboxTimeoutDuration() != 95*time.Second {
 		t.Errorf("expected sandbox timeout 95s, got %s", cfg.Jobs.RunnerSandboxTimeoutDuration())
 	}
+	if cfg.Jobs.RunTimeoutDuration() != 5*time.Minute {
+		t.Errorf("expected run timeout 5m, got %s", cfg.Jobs.RunTimeoutDuration())
+	}
+	if cfg.Jobs.RunnerArtifactMaxRunBytesOrDefault() != 1<<20 {
+		t.Errorf("expected artifact cap 1MiB, got %d", cfg.Jobs.RunnerArtifactMaxRunBytesOrDefault())
+	}
+	if cfg.Jobs.RunnerArtifactRetentionDuration() != 168*time.Hour {
+		t.Errorf("expected artifact retention 168h, got %s", cfg.Jobs.RunnerArtifactRetentionDuration())
+	}
+	if cfg.Jobs.DependencyWaitTimeoutDuration() != 20*time.Minute {
+		t.Errorf("expected dependency wait timeout 20m, got %s", cfg.Jobs.DependencyWaitTimeoutDuration())
+	}
 }
 
 func TestSaveAndReload(t *testing.T) {
@@ -519,6 +579,119 @@ func TestApprovalTwoPersonModeDefaultsAndEnvOverride(t *testing.T) {
 	}
 }
 
+func TestApprovalMaxTTLDefaultsAndEnvOverride(t *testing.T) {
+	cfg := Default()
+	if got := cfg.Approval.MaxTTLDuration(); got != 24*time.Hour {
+		t.Fatalf("expected default approval max ttl 24h, got %s", got)
+	}
+
+	t.Setenv("LEGATOR_APPROVAL_MAX_TTL", "72h")
+	loaded := LoadFromEnv()
+	if got := loaded.Approval.MaxTTLDuration(); got != 72*time.Hour {
+		t.Fatalf("expected approval max ttl 72h from env, got %s", got)
+	}
+
+	loaded.Approval.MaxTTL = "bogus"
+	if got := loaded.Approval.MaxTTLDuration(); got != 24*time.Hour {
+		t.Fatalf("expected invalid max ttl to fall back to 24h, got %s", got)
+	}
+}
+
+func TestChatLimitsDefaultsAndEnvOverride(t *testing.T) {
+	cfg := Default()
+	if got := cfg.Chat.MaxMessages(); got != 500 {
+		t.Fatalf("expected default chat max messages 500, got %d", got)
+	}
+	if got := cfg.Chat.RetentionDuration(); got != 24*time.Hour {
+		t.Fatalf("expected default chat retention 24h, got %s", got)
+	}
+
+	t.Setenv("LEGATOR_CHAT_MAX_MESSAGES", "50")
+	t.Setenv("LEGATOR_CHAT_RETENTION", "168h")
+	loaded := LoadFromEnv()
+	if got := loaded.Chat.MaxMessages(); got != 50 {
+		t.Fatalf("expected chat max messages 50 from env, got %d", got)
+	}
+	if got := loaded.Chat.RetentionDuration(); got != 168*time.Hour {
+		t.Fatalf("expected chat retention 168h from env, got %s", got)
+	}
+}
+
+func TestLoginLockoutDefaultsAndEnvOverride(t *testing.T) {
+	cfg := Default()
+	if cfg.LoginLockout.Disabled {
+		t.Fatal("expected login lockout enabled by default")
+	}
+	if cfg.LoginLockout.IPMaxAttempts != 0 {
+		t.Fatalf("expected source IP lockout off by default, got %d", cfg.LoginLockout.IPMaxAttempts)
+	}
+	if got := cfg.LoginLockout.WindowDuration(); got != 15*time.Minute {
+		t.Fatalf("expected default lockout window 15m, got %s", got)
+	}
+	if got := cfg.LoginLockout.LockDuration(); got != 15*time.Minute {
+		t.Fatalf("expected default lockout duration 15m, got %s", got)
+	}
+
+	t.Setenv("LEGATOR_LOGIN_LOCKOUT_MAX_ATTEMPTS", "3")
+	t.Setenv("LEGATOR_LOGIN_LOCKOUT_IP_MAX_ATTEMPTS", "-1")
+	t.Setenv("LEGATOR_LOGIN_LOCKOUT_WINDOW", "5m")
+	t.Setenv("LEGATOR_LOGIN_LOCKOUT_DURATION", "1h")
+	loaded := LoadFromEnv()
+	if loaded.LoginLockout.MaxAttempts != 3 || loaded.LoginLockout.IPMaxAttempts != -1 {
+		t.Fatalf("unexpected lockout attempts from env: %+v", loaded.LoginLockout)
+	}
+	if got := loaded.LoginLockout.WindowDuration(); got != 5*time.Minute {
+		t.Fatalf("expected lockout window 5m from env, got %s", got)
+	}
+	if got := loaded.LoginLockout.LockDuration(); got != time.Hour {
+		t.Fatalf("expected lockout duration 1h from env, got %s", got)
+	}
+}
+
+func TestSSEDefaultsAndEnvOverride(t *testing.T) {
+	cfg := Default()
+	if got := cfg.SSE.KeepaliveDuration(); got != 15*time.Second {
+		t.Fatalf("expected default SSE keepalive 15s, got %s", got)
+	}
+	if got := cfg.SSE.ClientLimit(); got != 1000 {
+		t.Fatalf("expected default SSE client limit 1000, got %d", got)
+	}
+
+	t.Setenv("LEGATOR_SSE_KEEPALIVE_INTERVAL", "0")
+	t.Setenv("LEGATOR_SSE_MAX_CLIENTS", "-1")
+	loaded := LoadFromEnv()
+	if got := loaded.SSE.KeepaliveDuration(); got != 0 {
+		t.Fatalf("expected keepalive disabled from env, got %s", got)
+	}
+	if got := loaded.SSE.ClientLimit(); got != 0 {
+		t.Fatalf("expected unlimited SSE clients from env, got %d", got)
+	}
+
+	t.Setenv("LEGATOR_SSE_KEEPALIVE_INTERVAL", "30s")
+	t.Setenv("LEGATOR_SSE_MAX_CLIENTS", "50")
+	loaded = LoadFromEnv()
+	if loaded.SSE.KeepaliveDuration() != 30*time.Second || loaded.SSE.ClientLimit() != 50 {
+		t.Fatalf("unexpected SSE config from env: %+v", loaded.SSE)
+	}
+}
+
+func TestHealthThresholdsEnvOverride(t *testing.T) {
+	if cfg := Default(); cfg.Health != (HealthConfig{}) {
+		t.Fatalf("expected zero health config by default, got %+v", cfg.Health)
+	}
+
+	t.Setenv("LEGATOR_HEALTH_DISK_HIGH_PCT", "90")
+	t.Setenv("LEGATOR_HEALTH_HYSTERESIS_PCT", "3.5")
+	t.Setenv("LEGATOR_HEALTH_LOAD_CRITICAL", "not-a-number")
+	loaded := LoadFromEnv()
+	if loaded.Health.DiskHighPct != 90 || loaded.Health.HysteresisPct != 3.5 {
+		t.Fatalf("expected health thresholds from env, got %+v", loaded.Health)
+	}
+	if loaded.Health.LoadCritical != 0 {
+		t.Fatalf("expected invalid value to be ignored, got %v", loaded.Health.LoadCritical)
+	}
+}
+
 func TestAuditChainConfigFromFile(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "config.json")
@@ -551,6 +724,24 @@ func TestAuditChainConfigFromEnv(t *testing.T) {
 	}
 }
 
+func TestAuditSyslogConfigFromEnv(t *testing.T) {
+	t.Setenv("LEGATOR_AUDIT_SYSLOG_ADDR", "siem.example.com:6514")
+	t.Setenv("LEGATOR_AUDIT_SYSLOG_PROTOCOL", "tls")
+	t.Setenv("LEGATOR_AUDIT_SYSLOG_CA_PATH", "/etc/legator/siem-ca.pem")
+	t.Setenv("LEGATOR_AUDIT_SYSLOG_TLS_SKIP_VERIFY", "true")
+	t.Setenv("LEGATOR_AUDIT_SYSLOG_BUFFER_SIZE", "2048")
+	t.Setenv("LEGATOR_AUDIT_SYSLOG_APP_NAME", "legator-prod")
+
+	cfg := LoadFromEnv()
+	got := cfg.Audit.Syslog
+	if got.Address != "siem.example.com:6514" || got.Protocol != "tls" || got.CAPath != "/etc/legator/siem-ca.pem" {
+		t.Fatalf("unexpected syslog endpoint config: %+v", got)
+	}
+	if !got.TLSSkipVerify || got.BufferSize != 2048 || got.AppName != "legator-prod" {
+		t.Fatalf("unexpected syslog tuning config: %+v", got)
+	}
+}
+
 func TestProbeMTLSConfigDefaultsAndEnvOverrides(t *testing.T) {
 	cfg := Default()
 	if cfg.ProbeMTLS.ModeOrDefault() != "off" {
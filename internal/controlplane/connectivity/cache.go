@@ -0,0 +1,90 @@
+package connectivity
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is how long an on-demand check result is reused.
+	DefaultCacheTTL = 30 * time.Second
+	// maxCachedReports bounds the cache; expired and then oldest reports
+	// are evicted first.
+	maxCachedReports = 256
+)
+
+// CachedChecker runs on-demand checks through a Manager and reuses a
+// report for the same endpoint list until it is TTL old, so repeated
+// diagnostics do not hammer the endpoints.
+type CachedChecker struct {
+	manager *Manager
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	reports map[string]*Report
+}
+
+// NewCachedChecker wraps m. A ttl of 0 or less uses DefaultCacheTTL.
+func NewCachedChecker(m *Manager, ttl time.Duration) *CachedChecker {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &CachedChecker{manager: m, ttl: ttl, now: time.Now, reports: make(map[string]*Report)}
+}
+
+// Check returns a report for endpoints and whether it came from the cache.
+// Endpoints must already have passed Validate.
+func (c *CachedChecker) Check(ctx context.Context, endpoints []Endpoint) (*Report, bool) {
+	key := cacheKey(endpoints)
+	c.mu.Lock()
+	if report, ok := c.reports[key]; ok && c.now().Sub(report.CheckedAt) < c.ttl {
+		c.mu.Unlock()
+		return report, true
+	}
+	c.mu.Unlock()
+
+	report := c.manager.PreRunCheck(ctx, endpoints)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictLocked()
+	c.reports[key] = report
+	return report, false
+}
+
+func (c *CachedChecker) evictLocked() {
+	now := c.now()
+	for key, report := range c.reports {
+		if now.Sub(report.CheckedAt) >= c.ttl {
+			delete(c.reports, key)
+		}
+	}
+	for len(c.reports) >= maxCachedReports {
+		var oldest string
+		for key, report := range c.reports {
+			if oldest == "" || report.CheckedAt.Before(c.reports[oldest].CheckedAt) {
+				oldest = key
+			}
+		}
+		delete(c.reports, oldest)
+	}
+}
+
+// cacheKey identifies an endpoint list in order, since reports follow
+// request order.
+func cacheKey(endpoints []Endpoint) string {
+	var b strings.Builder
+	for _, ep := range endpoints {
+		b.WriteString(strings.TrimSpace(ep.Name))
+		b.WriteByte(0)
+		b.WriteString(strings.TrimSpace(ep.Address))
+		if ep.Required {
+			b.WriteString("\x00r")
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
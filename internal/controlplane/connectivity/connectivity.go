@@ -0,0 +1,207 @@
+// Package connectivity checks that the endpoints a task depends on are
+// reachable before the task starts.
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultParallelism bounds how many endpoints are checked at once.
+	DefaultParallelism = 8
+	// DefaultEndpointTimeout bounds a single endpoint check.
+	DefaultEndpointTimeout = 5 * time.Second
+	// DefaultDeadline bounds the whole pre-run check.
+	DefaultDeadline = 15 * time.Second
+
+	// MaxEndpoints is the most endpoints a single check accepts.
+	MaxEndpoints = 64
+)
+
+var (
+	ErrEndpointAddressRequired = errors.New("endpoint address is required")
+	ErrTooManyEndpoints        = fmt.Errorf("at most %d endpoints may be checked", MaxEndpoints)
+)
+
+// Endpoint is a host:port a task needs to reach.
+type Endpoint struct {
+	Name     string `json:"name,omitempty"`
+	Address  string `json:"address"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// Label returns the endpoint name, falling back to its address.
+func (e Endpoint) Label() string {
+	if name := strings.TrimSpace(e.Name); name != "" {
+		return name
+	}
+	return strings.TrimSpace(e.Address)
+}
+
+// EndpointResult is the outcome of checking one endpoint.
+type EndpointResult struct {
+	Name      string `json:"name,omitempty"`
+	Address   string `json:"address"`
+	Required  bool   `json:"required,omitempty"`
+	Reachable bool   `json:"reachable"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is the result of a pre-run check. Endpoints are in request order.
+type Report struct {
+	CheckedAt  time.Time        `json:"checked_at"`
+	DurationMS int64            `json:"duration_ms"`
+	Endpoints  []EndpointResult `json:"endpoints"`
+}
+
+// Unreachable returns the endpoints that could not be reached.
+func (r *Report) Unreachable() []EndpointResult {
+	if r == nil {
+		return nil
+	}
+	var out []EndpointResult
+	for _, ep := range r.Endpoints {
+		if !ep.Reachable {
+			out = append(out, ep)
+		}
+	}
+	return out
+}
+
+// RequiredUnreachable returns the required endpoints that could not be
+// reached.
+func (r *Report) RequiredUnreachable() []EndpointResult {
+	var out []EndpointResult
+	for _, ep := range r.Unreachable() {
+		if ep.Required {
+			out = append(out, ep)
+		}
+	}
+	return out
+}
+
+// DialFunc opens a connection to address. It matches net.Dialer.DialContext.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// Manager runs pre-run connectivity checks over TCP.
+type Manager struct {
+	Parallelism     int
+	EndpointTimeout time.Duration
+	Deadline        time.Duration
+
+	dial DialFunc
+}
+
+// NewManager returns a Manager with default limits.
+func NewManager() *Manager {
+	return &Manager{
+		Parallelism:     DefaultParallelism,
+		EndpointTimeout: DefaultEndpointTimeout,
+		Deadline:        DefaultDeadline,
+	}
+}
+
+// SetDialer replaces the TCP dialer; used in tests.
+func (m *Manager) SetDialer(dial DialFunc) {
+	m.dial = dial
+}
+
+// Validate checks that endpoints can be passed to PreRunCheck.
+func Validate(endpoints []Endpoint) error {
+	if len(endpoints) > MaxEndpoints {
+		return ErrTooManyEndpoints
+	}
+	for i, ep := range endpoints {
+		addr := strings.TrimSpace(ep.Address)
+		if addr == "" {
+			return fmt.Errorf("endpoint %d: %w", i, ErrEndpointAddressRequired)
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("endpoint %d: address must be host:port: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// PreRunCheck dials every endpoint, at most Parallelism at a time, and
+// returns one result per endpoint. Endpoints still pending when Deadline
+// (or ctx) expires are reported unreachable. PreRunCheck never fails; the
+// caller decides what an unreachable endpoint means.
+func (m *Manager) PreRunCheck(ctx context.Context, endpoints []Endpoint) *Report {
+	started := time.Now()
+	report := &Report{
+		CheckedAt: started.UTC(),
+		Endpoints: make([]EndpointResult, len(endpoints)),
+	}
+	if len(endpoints) == 0 {
+		return report
+	}
+
+	parallelism := m.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+	deadline := m.Deadline
+	if deadline <= 0 {
+		deadline = DefaultDeadline
+	}
+	ctx, cancel := context.WithTimeout(ctx, deadline)
+	defer cancel()
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, ep := range endpoints {
+		report.Endpoints[i] = EndpointResult{
+			Name:     strings.TrimSpace(ep.Name),
+			Address:  strings.TrimSpace(ep.Address),
+			Required: ep.Required,
+		}
+		wg.Add(1)
+		go func(res *EndpointResult) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				res.Error = "check deadline exceeded before endpoint was dialed"
+				return
+			}
+			m.check(ctx, res)
+		}(&report.Endpoints[i])
+	}
+	wg.Wait()
+
+	report.DurationMS = time.Since(started).Milliseconds()
+	return report
+}
+
+func (m *Manager) check(ctx context.Context, res *EndpointResult) {
+	timeout := m.EndpointTimeout
+	if timeout <= 0 {
+		timeout = DefaultEndpointTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dial := m.dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	start := time.Now()
+	conn, err := dial(ctx, "tcp", res.Address)
+	res.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Error = err.Error()
+		return
+	}
+	_ = conn.Close()
+	res.Reachable = true
+}
@@ -0,0 +1,158 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPreRunCheckReportsPerEndpointResults(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	// Grab a port that nothing listens on.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	closedAddr := closed.Addr().String()
+	_ = closed.Close()
+
+	m := NewManager()
+	report := m.PreRunCheck(context.Background(), []Endpoint{
+		{Name: "api", Address: ln.Addr().String()},
+		{Name: "db-01", Address: closedAddr, Required: true},
+	})
+
+	if len(report.Endpoints) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(report.Endpoints))
+	}
+	if got := report.Endpoints[0]; !got.Reachable || got.Name != "api" || got.Error != "" {
+		t.Fatalf("expected api reachable, got %+v", got)
+	}
+	if got := report.Endpoints[1]; got.Reachable || got.Name != "db-01" || got.Error == "" || !got.Required {
+		t.Fatalf("expected db-01 unreachable with error, got %+v", got)
+	}
+	if down := report.RequiredUnreachable(); len(down) != 1 || down[0].Name != "db-01" {
+		t.Fatalf("expected db-01 as required unreachable, got %+v", down)
+	}
+}
+
+func TestPreRunCheckBoundsParallelism(t *testing.T) {
+	var inFlight, peak int32
+	m := NewManager()
+	m.Parallelism = 2
+	m.SetDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return nil, errors.New("refused")
+	})
+
+	endpoints := make([]Endpoint, 6)
+	for i := range endpoints {
+		endpoints[i] = Endpoint{Address: "10.0.0.1:22"}
+	}
+	report := m.PreRunCheck(context.Background(), endpoints)
+
+	if len(report.Unreachable()) != 6 {
+		t.Fatalf("expected all 6 unreachable, got %+v", report.Endpoints)
+	}
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent checks, saw %d", peak)
+	}
+}
+
+func TestPreRunCheckHonoursOverallDeadline(t *testing.T) {
+	m := NewManager()
+	m.Parallelism = 1
+	m.EndpointTimeout = time.Second
+	m.Deadline = 50 * time.Millisecond
+	m.SetDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	start := time.Now()
+	report := m.PreRunCheck(context.Background(), []Endpoint{
+		{Address: "10.0.0.1:22"},
+		{Address: "10.0.0.2:22"},
+		{Address: "10.0.0.3:22"},
+	})
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected check to stop at the deadline, took %s", elapsed)
+	}
+	for _, ep := range report.Endpoints {
+		if ep.Reachable || ep.Error == "" {
+			t.Fatalf("expected every endpoint to fail with an error, got %+v", ep)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate([]Endpoint{{Address: "db-01:5432"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Validate([]Endpoint{{Name: "db"}}); !errors.Is(err, ErrEndpointAddressRequired) {
+		t.Fatalf("expected ErrEndpointAddressRequired, got %v", err)
+	}
+	if err := Validate([]Endpoint{{Address: "db-01"}}); err == nil {
+		t.Fatal("expected error for address without port")
+	}
+	if err := Validate(make([]Endpoint, MaxEndpoints+1)); !errors.Is(err, ErrTooManyEndpoints) {
+		t.Fatalf("expected ErrTooManyEndpoints, got %v", err)
+	}
+}
+
+func TestCachedCheckerReusesRecentReports(t *testing.T) {
+	var dials int32
+	m := NewManager()
+	m.SetDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		return nil, errors.New("connection refused")
+	})
+	c := NewCachedChecker(m, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	endpoints := []Endpoint{{Name: "db-01", Address: "db-01:5432", Required: true}}
+	first, cached := c.Check(context.Background(), endpoints)
+	if cached || first.Endpoints[0].Reachable {
+		t.Fatalf("expected fresh unreachable report, cached=%v report=%+v", cached, first)
+	}
+	if again, cached := c.Check(context.Background(), endpoints); !cached || again != first {
+		t.Fatal("expected the cached report within the TTL")
+	}
+	if _, cached := c.Check(context.Background(), []Endpoint{{Name: "db-01", Address: "db-01:5432"}}); cached {
+		t.Fatal("expected a different endpoint list to be checked afresh")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, cached := c.Check(context.Background(), endpoints); cached {
+		t.Fatal("expected an expired report to be checked again")
+	}
+	if got := atomic.LoadInt32(&dials); got != 3 {
+		t.Fatalf("expected 3 dials, got %d", got)
+	}
+}
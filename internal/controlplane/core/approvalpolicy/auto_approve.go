@@ -0,0 +1,78 @@
+package approvalpolicy
+
+import (
+	"github.com/marcus-qen/legator/internal/controlplane/approval"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// AutoApproveMatcher resolves the auto-approve rule (if any) for a command.
+type AutoApproveMatcher interface {
+	Match(in approval.RuleMatchInput) (*approval.AutoApproveRule, bool)
+}
+
+// AutoApproveHook is invoked whenever an auto-approve rule lets a command skip
+// the approval queue, so callers can record an audit event.
+type AutoApproveHook func(probeID, actor string, cmd *protocol.CommandPayload, decision CommandPolicyDecision, rule *approval.AutoApproveRule)
+
+// WithAutoApproveRules enables auto-approve rule evaluation for queued commands.
+func WithAutoApproveRules(rules AutoApproveMatcher, onMatch AutoApproveHook) Option {
+	return func(s *Service) {
+		s.autoApprove = rules
+		s.onAutoApprove = onMatch
+	}
+}
+
+// ApplyAutoApproveRules converts a queued decision into an allow when an
+// auto-approve rule matches actor, probe tags and command. Breakglass lanes
+// are never auto-approved, and critical commands only when the matching rule
+// opts in. Returns the rule that fired, or nil.
+func (s *Service) ApplyAutoApproveRules(probeID, actor string, cmd *protocol.CommandPayload, decision *CommandPolicyDecision) *approval.AutoApproveRule {
+	if s == nil || s.autoApprove == nil || decision == nil || cmd == nil {
+		return nil
+	}
+	if decision.Outcome != CommandPolicyDecisionQueue || decision.Lane == protocol.ExecBreakglassDirect {
+		return nil
+	}
+
+	var tags []string
+	if s.fleet != nil && probeID != "" {
+		if ps, ok := s.fleet.Get(probeID); ok && ps != nil {
+			tags = ps.Tags
+		}
+	}
+	rule, ok := s.autoApprove.Match(approval.RuleMatchInput{
+		Actor:     actor,
+		ProbeTags: tags,
+		Command:   cmd,
+		RiskLevel: decision.RiskLevel,
+	})
+	if !ok || rule == nil {
+		return nil
+	}
+
+	decision.Outcome = CommandPolicyDecisionAllow
+	decision.GateOutcome = decisionGateFromOutcome(decision.Outcome)
+	decision.ReasonCode = "approval.auto_approved"
+	decision.Rationale.Lane.GateOutcome = decision.GateOutcome
+	decision.Rationale.Lane.ReasonCode = decision.ReasonCode
+	for i := range decision.Rationale.Indicators {
+		if decision.Rationale.Indicators[i].Effect == CommandPolicyDecisionQueue {
+			decision.Rationale.Indicators[i].DroveOutcome = false
+		}
+	}
+	decision.Rationale.Indicators = append(decision.Rationale.Indicators, CommandPolicyIndicator{
+		Name:         "auto_approve_rule",
+		Source:       "approval_rules",
+		Value:        rule.ID,
+		Severity:     "info",
+		Effect:       CommandPolicyDecisionAllow,
+		DroveOutcome: true,
+		Message:      "auto-approved by rule " + rule.Name,
+	})
+	decision.Rationale.Summary = summarizeDecision(decision.Outcome, decision.Rationale.Indicators)
+
+	if s.onAutoApprove != nil {
+		s.onAutoApprove(probeID, actor, cmd, *decision, rule)
+	}
+	return rule
+}
@@ -0,0 +1,153 @@
+package approvalpolicy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/approval"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"github.com/marcus-qen/legator/internal/controlplane/policy"
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+func TestSubmitCommandApproval_AutoApproveRuleSkipsQueue(t *testing.T) {
+	queue := approval.NewQueue(15*time.Minute, 16)
+	fleetMgr := fleet.NewManager(zap.NewNop())
+	fleetMgr.Register("probe-a", "web-1", "linux", "amd64")
+	if err := fleetMgr.SetTags("probe-a", []string{"staging"}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+	rules := approval.NewRuleSet()
+	if _, err := rules.Add(approval.AutoApproveRule{Name: "ci restarts", Actor: "ci-bot", ProbeTag: "staging", CommandGlob: "systemctl restart *"}); err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+
+	var fired *approval.AutoApproveRule
+	svc := NewService(queue, fleetMgr, policy.NewStore(), WithAutoApproveRules(rules, func(probeID, actor string, _ *protocol.CommandPayload, _ CommandPolicyDecision, rule *approval.AutoApproveRule) {
+		if probeID != "probe-a" || actor != "ci-bot" {
+			t.Fatalf("unexpected hook args probe=%q actor=%q", probeID, actor)
+		}
+		fired = rule
+	}))
+
+	cmd := &protocol.CommandPayload{RequestID: "req-auto", Command: "systemctl restart nginx", Level: protocol.CapRemediate}
+	req, needed, err := svc.SubmitCommandApproval("probe-a", cmd, protocol.CapRemediate, "deploy", "ci-bot")
+	if err != nil {
+		t.Fatalf("SubmitCommandApproval returned error: %v", err)
+	}
+	if needed || req != nil {
+		t.Fatalf("expected auto-approval to skip queue, needed=%v req=%+v", needed, req)
+	}
+	if fired == nil || fired.Name != "ci restarts" {
+		t.Fatalf("expected hook to fire with rule, got %+v", fired)
+	}
+	if queue.PendingCount() != 0 {
+		t.Fatalf("expected no pending approvals, got %d", queue.PendingCount())
+	}
+
+	// A different actor still needs approval.
+	_, needed, err = svc.SubmitCommandApproval("probe-a", cmd, protocol.CapRemediate, "deploy", "alice")
+	if err != nil {
+		t.Fatalf("SubmitCommandApproval returned error: %v", err)
+	}
+	if !needed {
+		t.Fatal("expected approval for actor without a matching rule")
+	}
+}
+
+func TestSubmitCommandApproval_LLMTaskNeedsRuleNamingIt(t *testing.T) {
+	queue := approval.NewQueue(15*time.Minute, 16)
+	fleetMgr := fleet.NewManager(zap.NewNop())
+	fleetMgr.Register("probe-a", "web-1", "linux", "amd64")
+	if err := fleetMgr.SetTags("probe-a", []string{"staging"}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+	rules := approval.NewRuleSet()
+	if _, err := rules.Add(approval.AutoApproveRule{Name: "staging restarts", ProbeTag: "staging", CommandGlob: "systemctl restart *"}); err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	svc := NewService(queue, fleetMgr, policy.NewStore(), WithAutoApproveRules(rules, nil))
+
+	cmd := &protocol.CommandPayload{RequestID: "req-llm", Command: "systemctl restart nginx", Level: protocol.CapRemediate}
+	req, needed, err := svc.SubmitCommandApproval("probe-a", cmd, protocol.CapRemediate, "LLM task command", approval.RequesterLLMTask)
+	if err != nil {
+		t.Fatalf("SubmitCommandApproval returned error: %v", err)
+	}
+	if !needed || req == nil {
+		t.Fatalf("expected LLM task command to be queued, needed=%v req=%+v", needed, req)
+	}
+}
+
+func TestApplyAutoApproveRules_DecisionRationale(t *testing.T) {
+	svc, _, _, _ := newServiceForTest()
+	rules := approval.NewRuleSet()
+	if _, err := rules.Add(approval.AutoApproveRule{Name: "restarts", CommandGlob: "systemctl restart nginx", Actor: "api"}); err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	WithAutoApproveRules(rules, nil)(svc)
+
+	cmd := &protocol.CommandPayload{Command: "systemctl restart nginx", Level: protocol.CapRemediate}
+	decision := svc.EvaluateCommandPolicy(t.Context(), cmd, protocol.CapRemediate)
+	if decision.Outcome != CommandPolicyDecisionQueue {
+		t.Fatalf("expected queue before rules, got %s", decision.Outcome)
+	}
+	if rule := svc.ApplyAutoApproveRules("", "api", cmd, &decision); rule == nil {
+		t.Fatal("expected rule to fire")
+	}
+	if decision.Outcome != CommandPolicyDecisionAllow || decision.GateOutcome != CommandPolicyGateAllowed {
+		t.Fatalf("expected allow/allowed, got %s/%s", decision.Outcome, decision.GateOutcome)
+	}
+	if decision.ReasonCode != "approval.auto_approved" {
+		t.Fatalf("unexpected reason code %q", decision.ReasonCode)
+	}
+	for _, ind := range decision.Rationale.Indicators {
+		if ind.DroveOutcome && ind.Name != "auto_approve_rule" {
+			t.Fatalf("indicator %s should no longer drive the outcome", ind.Name)
+		}
+	}
+}
+
+func TestSubmitCommandApproval_RiskRuleRecordedInRationale(t *testing.T) {
+	queue := approval.NewQueue(15*time.Minute, 16)
+	fleetMgr := fleet.NewManager(zap.NewNop())
+	fleetMgr.Register("probe-a", "web-1", "linux", "amd64")
+	rules := approval.NewRuleSet()
+	rule, err := rules.AddRiskRule(approval.RiskRule{Name: "cache wipe", CommandGlob: "bash */wipe-cache*", RiskLevel: "critical"})
+	if err != nil {
+		t.Fatalf("add rule: %v", err)
+	}
+	cmd := &protocol.CommandPayload{RequestID: "req-wipe", Command: "bash", Args: []string{"/opt/ops/wipe-cache.sh"}, Level: protocol.CapObserve}
+
+	// Without the rule the script runs as a low-risk observe command.
+	plain := NewService(queue, fleetMgr, policy.NewStore())
+	if _, needed, err := plain.SubmitCommandApproval("probe-a", cmd, protocol.CapRemediate, "cleanup", "api"); err != nil || needed {
+		t.Fatalf("expected no approval without rules, needed=%v err=%v", needed, err)
+	}
+
+	svc := NewService(queue, fleetMgr, policy.NewStore(), WithRiskRules(rules))
+	req, needed, err := svc.SubmitCommandApproval("probe-a", cmd, protocol.CapRemediate, "cleanup", "api")
+	if err != nil {
+		t.Fatalf("SubmitCommandApproval returned error: %v", err)
+	}
+	if !needed || req == nil {
+		t.Fatalf("expected queued approval, needed=%v req=%+v", needed, req)
+	}
+	if req.RiskLevel != "critical" {
+		t.Fatalf("expected rule risk critical, got %q", req.RiskLevel)
+	}
+	rationale, ok := req.PolicyRationale.(CommandPolicyRationale)
+	if !ok {
+		t.Fatalf("unexpected rationale type %T", req.PolicyRationale)
+	}
+	var found bool
+	for _, ind := range rationale.Indicators {
+		if ind.Name == "command_risk" && ind.Source == "risk_rules" && strings.Contains(ind.Message, rule.ID) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected risk rule in rationale indicators, got %+v", rationale.Indicators)
+	}
+}
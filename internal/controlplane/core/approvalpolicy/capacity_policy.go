@@ -126,6 +126,7 @@ type CommandPolicyRationale struct {
 	Capacity   *CapacitySignals           `json:"capacity,omitempty"`
 	Thresholds CapacityThresholdsSnapshot `json:"thresholds"`
 	Lane       CommandPolicyLaneRationale `json:"lane"`
+	Denial     *CommandPolicyDenial       `json:"denial,omitempty"`
 }
 
 // CommandPolicyLaneRationale surfaces lane-selection and gate rationale.
@@ -171,13 +172,19 @@ func (s *Service) EvaluateCommandPolicyPreview(ctx context.Context, probeID stri
 }
 
 func (s *Service) evaluateCommandPolicy(ctx context.Context, probeID string, cmd *protocol.CommandPayload, probeLevel protocol.CapabilityLevel, override *CommandPolicyProfile) CommandPolicyDecision {
+	decision := s.evaluateCommandPolicyOutcome(ctx, probeID, cmd, probeLevel, override)
+	decision.Rationale.Denial = denialForDecision(decision)
+	return decision
+}
+
+func (s *Service) evaluateCommandPolicyOutcome(ctx context.Context, probeID string, cmd *protocol.CommandPayload, probeLevel protocol.CapabilityLevel, override *CommandPolicyProfile) CommandPolicyDecision {
 	thresholds := s.capacityThresholds.normalized()
 	if cmd == nil {
 		cmd = &protocol.CommandPayload{}
 	}
 
 	classification := classifyCommandWithMetadata(cmd.Command, cmd.Args)
-	risk := approval.ClassifyRisk(cmd)
+	risk, riskRule := approval.ClassifyRiskWithRules(cmd, s.riskRules)
 	riskTier := riskTierForLevel(risk)
 
 	policyProfile := s.resolvePolicyProfile(probeID, probeLevel, override)
@@ -218,6 +225,10 @@ func (s *Service) evaluateCommandPolicy(ctx context.Context, probeID string, cmd
 		Severity: "info",
 		Message:  "command risk classification",
 	}
+	if riskRule != nil {
+		riskIndicator.Source = "risk_rules"
+		riskIndicator.Message = fmt.Sprintf("risk classified %s by rule %s (%s)", risk, riskRule.Name, riskRule.ID)
+	}
 	if (risk == "high" || risk == "critical") && decision.Outcome == CommandPolicyDecisionAllow {
 		decision.Outcome = CommandPolicyDecisionQueue
 		decision.GateOutcome = decisionGateFromOutcome(decision.Outcome)
@@ -226,6 +237,9 @@ func (s *Service) evaluateCommandPolicy(ctx context.Context, probeID string, cmd
 		riskIndicator.DroveOutcome = true
 		riskIndicator.Severity = "warn"
 		riskIndicator.Message = "high-risk command requires human approval"
+		if riskRule != nil {
+			riskIndicator.Message = fmt.Sprintf("%s-risk command requires human approval (rule %s, %s)", risk, riskRule.Name, riskRule.ID)
+		}
 	}
 	decision.Rationale.Indicators = append(decision.Rationale.Indicators, riskIndicator)
 
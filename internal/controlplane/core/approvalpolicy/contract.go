@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/marcus-qen/legator/internal/controlplane/approval"
 )
@@ -21,6 +22,19 @@ type HTTPErrorContract struct {
 type DecideApprovalRequest struct {
 	Decision  approval.Decision
 	DecidedBy string
+	// Reason is the operator's justification; some risk levels require it.
+	Reason string
+	// Source is the surface the decision came through. Empty lets the
+	// transport shell apply its own default.
+	Source string
+}
+
+// Details returns the decision metadata recorded on the approval.
+func (r *DecideApprovalRequest) Details() approval.DecisionDetails {
+	if r == nil {
+		return approval.DecisionDetails{}
+	}
+	return approval.DecisionDetails{Reason: r.Reason, Source: r.Source}
 }
 
 // DecideApprovalSuccess is the API-facing success envelope for approval decisions.
@@ -50,6 +64,8 @@ func DecodeDecideApprovalTransport(body io.Reader) *DecideApprovalTransportContr
 	var payload struct {
 		Decision  string `json:"decision"`
 		DecidedBy string `json:"decided_by"`
+		Reason    string `json:"reason"`
+		Source    string `json:"source"`
 	}
 	if err := json.NewDecoder(body).Decode(&payload); err != nil {
 		return &DecideApprovalTransportContract{
@@ -69,11 +85,23 @@ func DecodeDecideApprovalTransport(body io.Reader) *DecideApprovalTransportContr
 			},
 		}
 	}
+	source := strings.ToLower(strings.TrimSpace(payload.Source))
+	if source != "" && !approval.ValidDecisionSource(source) {
+		return &DecideApprovalTransportContract{
+			Err: &HTTPErrorContract{
+				Status:  http.StatusBadRequest,
+				Code:    "invalid_request",
+				Message: fmt.Sprintf("unknown decision source %q", payload.Source),
+			},
+		}
+	}
 
 	return &DecideApprovalTransportContract{
 		Request: &DecideApprovalRequest{
 			Decision:  approval.Decision(payload.Decision),
 			DecidedBy: payload.DecidedBy,
+			Reason:    strings.TrimSpace(payload.Reason),
+			Source:    source,
 		},
 	}
 }
@@ -135,6 +163,14 @@ func decideApprovalHTTPError(err error) (*HTTPErrorContract, bool) {
 		}, true
 	}
 
+	if errors.Is(err, approval.ErrDecisionReasonRequired) {
+		return &HTTPErrorContract{
+			Status:  http.StatusBadRequest,
+			Code:    "reason_required",
+			Message: err.Error(),
+		}, true
+	}
+
 	var hookErr *DecisionHookError
 	if errors.As(err, &hookErr) {
 		return &HTTPErrorContract{
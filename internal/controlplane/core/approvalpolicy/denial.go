@@ -0,0 +1,118 @@
+package approvalpolicy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommandPolicyDenial explains why a command was not allowed to run
+// immediately, in terms an operator can act on. It is set for deny and
+// queue outcomes and omitted when the command is allowed.
+type CommandPolicyDenial struct {
+	ReasonCode  string `json:"reason_code"`
+	MatchedRule string `json:"matched_rule"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation"`
+}
+
+const approveRemediation = "Approve the request with POST /api/v1/approvals/{id}/decide."
+
+// denialForDecision derives the structured denial for a decision from its
+// reason code, policy profile and the indicator that drove the outcome.
+func denialForDecision(decision CommandPolicyDecision) *CommandPolicyDenial {
+	if decision.Outcome == CommandPolicyDecisionAllow {
+		return nil
+	}
+
+	denial := &CommandPolicyDenial{
+		ReasonCode:  decision.ReasonCode,
+		MatchedRule: decision.ReasonCode,
+		Message:     decision.Rationale.Summary,
+		Remediation: "Review the indicators in policy_rationale to see which check failed.",
+	}
+	if indicator, ok := drivingIndicator(decision.Rationale.Indicators); ok && strings.TrimSpace(indicator.Message) != "" {
+		denial.Message = indicator.Message
+	}
+
+	policy := policyRef(decision.Policy.PolicyID)
+	switch decision.ReasonCode {
+	case "policy.lane_unmapped":
+		if !decision.Classification.SignatureKnown {
+			denial.MatchedRule = "mutating commands must match a known command signature"
+			denial.Message = "command is not a recognised mutation, so no execution lane could be selected"
+			denial.Remediation = "Use a recognised command, or run the change through an automation pack or runner job."
+		} else {
+			denial.MatchedRule = fmt.Sprintf("%s: execution_class_required=%s", policy, decision.Policy.ExecutionClassRequired)
+			denial.Message = "mutating command cannot run in the lane required by policy"
+			denial.Remediation = "Apply a policy template whose execution class allows remediation."
+		}
+	case "policy.breakglass_disabled":
+		denial.MatchedRule = fmt.Sprintf("%s: execution_class_required=breakglass_direct, breakglass.enabled=false", policy)
+		denial.Message = "policy requires the breakglass lane but breakglass is disabled"
+		denial.Remediation = "Enable breakglass on the policy template, or apply a policy with a sandboxed execution class."
+	case "policy.sandbox_required":
+		denial.MatchedRule = fmt.Sprintf("%s: sandbox_required=true", policy)
+		denial.Message = "policy requires a sandbox but the command would run directly on the host"
+		denial.Remediation = "Apply a policy with a sandboxed execution class, or turn off sandbox_required for this probe."
+	case "policy.breakglass_not_applicable":
+		denial.MatchedRule = fmt.Sprintf("%s: execution_class_required=breakglass_direct", policy)
+		denial.Message = "breakglass lane only runs mutating commands"
+		denial.Remediation = "Apply a policy with a direct or sandboxed execution class to run read-only commands."
+	case "capacity.availability_degraded":
+		denial.MatchedRule = indicatorRule(decision.Rationale.Indicators, "availability")
+		denial.Remediation = "Wait for Grafana capacity to recover, or check the datasources behind the capacity snapshot."
+	case "capacity.datasource_insufficient":
+		denial.MatchedRule = indicatorRule(decision.Rationale.Indicators, "datasource_count")
+		denial.Remediation = "Add Grafana datasources, or lower the minimum datasource count threshold."
+	case "capacity.availability_limited":
+		denial.MatchedRule = indicatorRule(decision.Rationale.Indicators, "availability")
+		denial.Remediation = approveRemediation
+	case "capacity.dashboard_coverage_low", "capacity.query_coverage_low":
+		denial.MatchedRule = indicatorRule(decision.Rationale.Indicators, strings.TrimSuffix(strings.TrimPrefix(decision.ReasonCode, "capacity."), "_low"))
+		denial.Remediation = approveRemediation + " Improving Grafana coverage lets such commands run without review."
+	case "approval.required.risk_high":
+		denial.MatchedRule = fmt.Sprintf("risk_level=%s requires approval", decision.RiskLevel)
+		denial.Remediation = approveRemediation
+	case "approval.required.mutation_gate", "approval.required.plan_first", "approval.required.every_action":
+		denial.MatchedRule = fmt.Sprintf("%s: approval_mode=%s", policy, decision.Policy.ApprovalMode)
+		denial.Remediation = approveRemediation
+	case "approval.required.two_person":
+		denial.MatchedRule = fmt.Sprintf("%s: approval_mode=%s", policy, decision.Policy.ApprovalMode)
+		denial.Remediation = "Two different approvers must approve the request with POST /api/v1/approvals/{id}/decide."
+	case "approval.breakglass_required":
+		denial.MatchedRule = fmt.Sprintf("%s: execution_class_required=breakglass_direct", policy)
+		denial.Remediation = "Confirm breakglass with an allowed reason and have the request approved."
+	}
+	return denial
+}
+
+func policyRef(policyID string) string {
+	if id := strings.TrimSpace(policyID); id != "" {
+		return "policy " + id
+	}
+	return "default policy"
+}
+
+func drivingIndicator(indicators []CommandPolicyIndicator) (CommandPolicyIndicator, bool) {
+	for _, indicator := range indicators {
+		if indicator.DroveOutcome {
+			return indicator, true
+		}
+	}
+	return CommandPolicyIndicator{}, false
+}
+
+// indicatorRule renders a capacity indicator as "name comparator threshold
+// (observed value)".
+func indicatorRule(indicators []CommandPolicyIndicator, name string) string {
+	for _, indicator := range indicators {
+		if indicator.Name != name {
+			continue
+		}
+		if indicator.Comparator == "" {
+			return fmt.Sprintf("%s (observed %v)", indicator.Name, indicator.Value)
+		}
+		return fmt.Sprintf("%s %s %v (observed %v)", indicator.Name, indicator.Comparator, indicator.Threshold, indicator.Value)
+	}
+	return name
+}
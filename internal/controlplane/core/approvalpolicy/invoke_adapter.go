@@ -24,7 +24,7 @@ func AssembleDecideApprovalInvokeHTTP(approvalID string, body io.Reader) *Decide
 
 // AssembleDecideApprovalInvokeMCP normalizes the MCP tool input into the
 // shared invoke adapter contract.
-func AssembleDecideApprovalInvokeMCP(approvalID, decision, decidedBy string) (*DecideApprovalInvokeInput, error) {
+func AssembleDecideApprovalInvokeMCP(approvalID, decision, decidedBy, reason string) (*DecideApprovalInvokeInput, error) {
 	normalizedApprovalID := strings.TrimSpace(approvalID)
 	if normalizedApprovalID == "" {
 		return nil, fmt.Errorf("approval_id is required")
@@ -33,9 +33,11 @@ func AssembleDecideApprovalInvokeMCP(approvalID, decision, decidedBy string) (*D
 	body, err := json.Marshal(struct {
 		Decision  string `json:"decision"`
 		DecidedBy string `json:"decided_by"`
+		Reason    string `json:"reason,omitempty"`
 	}{
 		Decision:  decision,
 		DecidedBy: decidedBy,
+		Reason:    reason,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("encode decide approval input: %w", err)
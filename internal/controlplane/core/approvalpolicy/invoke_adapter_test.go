@@ -9,7 +9,7 @@ import (
 )
 
 func TestAssembleDecideApprovalInvokeMCP_NormalizesApprovalIDAndBody(t *testing.T) {
-	invokeInput, err := AssembleDecideApprovalInvokeMCP("  req-mcp-normalized  ", "denied", "operator")
+	invokeInput, err := AssembleDecideApprovalInvokeMCP("  req-mcp-normalized  ", "denied", "operator", "")
 	if err != nil {
 		t.Fatalf("AssembleDecideApprovalInvokeMCP returned error: %v", err)
 	}
@@ -39,7 +39,7 @@ func TestAssembleDecideApprovalInvokeMCP_NormalizesApprovalIDAndBody(t *testing.
 }
 
 func TestAssembleDecideApprovalInvokeMCP_RequiresApprovalID(t *testing.T) {
-	invokeInput, err := AssembleDecideApprovalInvokeMCP("   ", "approved", "operator")
+	invokeInput, err := AssembleDecideApprovalInvokeMCP("   ", "approved", "operator", "")
 	if invokeInput != nil {
 		t.Fatalf("expected nil invoke input on missing approval id, got %+v", invokeInput)
 	}
@@ -50,7 +50,7 @@ func TestAssembleDecideApprovalInvokeMCP_RequiresApprovalID(t *testing.T) {
 
 func TestInvokeDecideApproval_ParityAcrossHTTPAndMCPInputs(t *testing.T) {
 	httpInput := AssembleDecideApprovalInvokeHTTP("req-invoke-parity", strings.NewReader(`{"decision":"approved","decided_by":"operator"}`))
-	mcpInput, err := AssembleDecideApprovalInvokeMCP("  req-invoke-parity  ", "approved", "operator")
+	mcpInput, err := AssembleDecideApprovalInvokeMCP("  req-invoke-parity  ", "approved", "operator", "")
 	if err != nil {
 		t.Fatalf("AssembleDecideApprovalInvokeMCP returned error: %v", err)
 	}
@@ -102,7 +102,7 @@ func TestInvokeDecideApproval_ParityAcrossHTTPAndMCPInputs(t *testing.T) {
 
 func TestInvokeDecideApproval_BodyDecodeParityAcrossHTTPAndMCPInputs(t *testing.T) {
 	httpInput := AssembleDecideApprovalInvokeHTTP("req-body-parity", strings.NewReader(`{"decision":"denied"}`))
-	mcpInput, err := AssembleDecideApprovalInvokeMCP("req-body-parity", "denied", "")
+	mcpInput, err := AssembleDecideApprovalInvokeMCP("req-body-parity", "denied", "", "")
 	if err != nil {
 		t.Fatalf("AssembleDecideApprovalInvokeMCP returned error: %v", err)
 	}
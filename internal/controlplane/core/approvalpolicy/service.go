@@ -21,7 +21,7 @@ type approvalQueue interface {
 	Submit(probeID string, cmd *protocol.CommandPayload, reason, riskLevel, requester string) (*approval.Request, error)
 	SubmitWithPolicyDetails(probeID string, cmd *protocol.CommandPayload, reason, riskLevel, requester, policyDecision string, policyRationale any) (*approval.Request, error)
 	SubmitWithPolicyDetailsAndOptions(probeID string, cmd *protocol.CommandPayload, reason, riskLevel, requester, policyDecision string, policyRationale any, options approval.SubmissionOptions) (*approval.Request, error)
-	Decide(id string, decision approval.Decision, decidedBy string) (*approval.Request, error)
+	DecideWithDetails(id string, decision approval.Decision, decidedBy string, details approval.DecisionDetails) (*approval.Request, error)
 	WaitForDecision(id string, timeout time.Duration) (*approval.Request, error)
 }
 
@@ -125,6 +125,9 @@ type Service struct {
 	capacitySignalSource CapacitySignalProvider
 	capacityThresholds   CapacityThresholds
 	twoPersonMode        bool
+	autoApprove          AutoApproveMatcher
+	onAutoApprove        AutoApproveHook
+	riskRules            approval.RiskRuleMatcher
 
 	appliedPolicyMu sync.RWMutex
 	appliedPolicy   map[string]appliedPolicyContext
@@ -158,6 +161,14 @@ func WithCapacityThresholds(thresholds CapacityThresholds) Option {
 	}
 }
 
+// WithRiskRules evaluates configured risk rules before the built-in risk
+// heuristics.
+func WithRiskRules(rules approval.RiskRuleMatcher) Option {
+	return func(s *Service) {
+		s.riskRules = rules
+	}
+}
+
 func NewService(approvals approvalQueue, fleet fleetStore, policies policyStore, opts ...Option) *Service {
 	svc := &Service{
 		approvals:          approvals,
@@ -193,6 +204,7 @@ func (s *Service) SubmitCommandApproval(probeID string, cmd *protocol.CommandPay
 
 func (s *Service) SubmitCommandApprovalWithContext(ctx context.Context, probeID string, cmd *protocol.CommandPayload, probeLevel protocol.CapabilityLevel, reason, requester string) (*SubmitCommandApprovalResult, error) {
 	decision := s.EvaluateCommandPolicyForProbe(ctx, probeID, cmd, probeLevel)
+	s.ApplyAutoApproveRules(probeID, requester, cmd, &decision)
 	result := &SubmitCommandApprovalResult{Decision: decision}
 	if decision.Outcome != CommandPolicyDecisionQueue {
 		return result, nil
@@ -222,7 +234,12 @@ type ApprovalDecisionResult struct {
 }
 
 func (s *Service) DecideApproval(id string, decision approval.Decision, decidedBy string) (*ApprovalDecisionResult, error) {
-	req, err := s.approvals.Decide(id, decision, decidedBy)
+	return s.DecideApprovalWithDetails(id, decision, decidedBy, approval.DecisionDetails{})
+}
+
+// DecideApprovalWithDetails records a decision with its reason and source.
+func (s *Service) DecideApprovalWithDetails(id string, decision approval.Decision, decidedBy string, details approval.DecisionDetails) (*ApprovalDecisionResult, error) {
+	req, err := s.approvals.DecideWithDetails(id, decision, decidedBy, details)
 	if err != nil {
 		return nil, err
 	}
@@ -245,7 +262,13 @@ func (s *Service) DispatchApprovedCommand(decision *ApprovalDecisionResult, disp
 //  2. approved dispatch (if required)
 //  3. approved-dispatch hook (if dispatch succeeded)
 func (s *Service) DecideAndDispatch(id string, decision approval.Decision, decidedBy string, dispatch func(probeID string, cmd protocol.CommandPayload) error) (*ApprovalDecisionResult, error) {
-	result, err := s.DecideApproval(id, decision, decidedBy)
+	return s.DecideAndDispatchWithDetails(id, decision, decidedBy, approval.DecisionDetails{}, dispatch)
+}
+
+// DecideAndDispatchWithDetails is DecideAndDispatch with the decision's
+// reason and source recorded on the approval.
+func (s *Service) DecideAndDispatchWithDetails(id string, decision approval.Decision, decidedBy string, details approval.DecisionDetails, dispatch func(probeID string, cmd protocol.CommandPayload) error) (*ApprovalDecisionResult, error) {
+	result, err := s.DecideApprovalWithDetails(id, decision, decidedBy, details)
 	if err != nil {
 		return nil, err
 	}
@@ -272,13 +295,19 @@ func (s *Service) WaitForDecision(id string, timeout time.Duration) (*approval.R
 	return s.approvals.WaitForDecision(id, timeout)
 }
 
+// PolicyApplyResult reports an applied template and the probe's policy state
+// immediately before it was applied. PreviousPolicyID is empty when no
+// template has been applied since the control plane started.
 type PolicyApplyResult struct {
-	Template *policy.Template
-	Pushed   bool
+	Template         *policy.Template
+	Pushed           bool
+	PreviousPolicyID string
+	PreviousLevel    protocol.CapabilityLevel
 }
 
 func (s *Service) ApplyPolicyTemplate(probeID, policyID string, push func(probeID string, pol *protocol.PolicyUpdatePayload) error) (*PolicyApplyResult, error) {
-	if _, ok := s.fleet.Get(probeID); !ok {
+	ps, ok := s.fleet.Get(probeID)
+	if !ok {
 		return nil, ErrProbeNotFound
 	}
 
@@ -287,16 +316,24 @@ func (s *Service) ApplyPolicyTemplate(probeID, policyID string, push func(probeI
 		return nil, ErrPolicyTemplateNotFound
 	}
 
+	// Snapshot the prior state before anything changes so callers can
+	// record it for history and rollback.
+	result := &PolicyApplyResult{Template: tpl, PreviousLevel: ps.PolicyLevel}
+	if prev, ok := s.appliedPolicyForProbe(probeID); ok {
+		result.PreviousPolicyID = prev.PolicyID
+	}
+
 	_ = s.fleet.SetPolicy(probeID, tpl.Level)
 	s.rememberAppliedPolicy(probeID, tpl)
 
 	if push != nil {
 		if err := push(probeID, tpl.ToPolicy()); err != nil {
-			return &PolicyApplyResult{Template: tpl, Pushed: false}, nil
+			return result, nil
 		}
 	}
 
-	return &PolicyApplyResult{Template: tpl, Pushed: true}, nil
+	result.Pushed = true
+	return result, nil
 }
 
 func (s *Service) rememberAppliedPolicy(probeID string, tpl *policy.Template) {
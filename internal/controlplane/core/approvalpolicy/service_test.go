@@ -206,6 +206,36 @@ func TestSubmitCommandApprovalWithContext_CapacityDenied(t *testing.T) {
 	if result.Decision.Rationale.Capacity == nil {
 		t.Fatal("expected capacity rationale payload")
 	}
+	denial := result.Decision.Rationale.Denial
+	if denial == nil || denial.ReasonCode != "capacity.availability_degraded" || denial.MatchedRule != "availability (observed degraded)" || denial.Remediation == "" {
+		t.Fatalf("expected structured capacity denial, got %+v", denial)
+	}
+}
+
+func TestEvaluateCommandPolicy_DenialNamesMatchedThreshold(t *testing.T) {
+	queue := approval.NewQueue(15*time.Minute, 16)
+	svc := NewService(queue, fleet.NewManager(zap.NewNop()), policy.NewStore(), WithCapacitySignalProvider(stubCapacitySignalProvider{signals: &CapacitySignals{
+		Availability:    "normal",
+		DatasourceCount: 0,
+	}}))
+
+	decision := svc.EvaluateCommandPolicy(context.Background(), &protocol.CommandPayload{Command: "ls"}, protocol.CapObserve)
+	denial := decision.Rationale.Denial
+	if decision.Outcome != CommandPolicyDecisionDeny || denial == nil {
+		t.Fatalf("expected deny with denial, got %s %+v", decision.Outcome, denial)
+	}
+	if denial.MatchedRule != "datasource_count >= 1 (observed 0)" {
+		t.Fatalf("unexpected matched rule %q", denial.MatchedRule)
+	}
+	if denial.Message != "insufficient datasource coverage; command denied" {
+		t.Fatalf("expected driving indicator message, got %q", denial.Message)
+	}
+
+	svc.capacitySignalSource = nil
+	allowed := svc.EvaluateCommandPolicy(context.Background(), &protocol.CommandPayload{Command: "ls"}, protocol.CapObserve)
+	if allowed.Outcome != CommandPolicyDecisionAllow || allowed.Rationale.Denial != nil {
+		t.Fatalf("expected no denial for allowed command, got %s %+v", allowed.Outcome, allowed.Rationale.Denial)
+	}
 }
 
 func TestSubmitCommandApprovalWithContext_CapacityLimitedQueuesLowRisk(t *testing.T) {
@@ -241,6 +271,9 @@ func TestSubmitCommandApprovalWithContext_CapacityLimitedQueuesLowRisk(t *testin
 	if rationale.Capacity == nil || rationale.Capacity.Availability != "limited" {
 		t.Fatalf("expected queued rationale capacity availability=limited, got %+v", rationale.Capacity)
 	}
+	if rationale.Denial == nil || rationale.Denial.ReasonCode != "capacity.availability_limited" {
+		t.Fatalf("expected queued rationale denial, got %+v", rationale.Denial)
+	}
 	if queue.PendingCount() != 1 {
 		t.Fatalf("expected 1 pending approval, got %d", queue.PendingCount())
 	}
@@ -286,6 +319,9 @@ func TestSubmitCommandApprovalWithContext_UnknownMutationBlocked(t *testing.T) {
 	if result.Decision.ReasonCode != "policy.lane_unmapped" {
 		t.Fatalf("expected policy.lane_unmapped reason, got %s", result.Decision.ReasonCode)
 	}
+	if denial := result.Decision.Rationale.Denial; denial == nil || denial.ReasonCode != "policy.lane_unmapped" || denial.MatchedRule != "mutating commands must match a known command signature" {
+		t.Fatalf("expected structured lane denial, got %+v", denial)
+	}
 	if queue.PendingCount() != 0 {
 		t.Fatalf("expected no pending approvals, got %d", queue.PendingCount())
 	}
@@ -698,4 +734,7 @@ func TestApplyPolicyTemplate(t *testing.T) {
 	if !result.Pushed {
 		t.Fatal("expected push=true")
 	}
+	if result.PreviousPolicyID != "observe-only" || result.PreviousLevel != protocol.CapObserve {
+		t.Fatalf("expected prior observe-only state snapshot, got %q/%q", result.PreviousPolicyID, result.PreviousLevel)
+	}
 }
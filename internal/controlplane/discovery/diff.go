@@ -0,0 +1,244 @@
+package discovery
+
+import (
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ManagedHost identifies a registered probe by the addresses and hostname it
+// is reachable under, so scan candidates can be matched against the fleet.
+type ManagedHost struct {
+	ProbeID  string
+	Hostname string
+	Addrs    []string
+}
+
+// CandidateChange describes a host present in both runs whose scan result differs.
+type CandidateChange struct {
+	IP      string    `json:"ip"`
+	Fields  []string  `json:"fields"`
+	Before  Candidate `json:"before"`
+	After   Candidate `json:"after"`
+	Managed bool      `json:"managed"`
+}
+
+// DiffSummary counts the hosts in each diff bucket. New counts added hosts
+// that do not correspond to a registered probe.
+type DiffSummary struct {
+	Added   int `json:"added"`
+	New     int `json:"new"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+// RunDiff is returned by GET /api/v1/discovery/runs/{id}/diff.
+type RunDiff struct {
+	Run     ScanRun           `json:"run"`
+	Against ScanRun           `json:"against"`
+	Added   []Candidate       `json:"added"`
+	Removed []Candidate       `json:"removed"`
+	Changed []CandidateChange `json:"changed"`
+	Summary DiffSummary       `json:"summary"`
+}
+
+// SetManagedHosts installs the lookup used to flag candidates that already
+// belong to a registered probe.
+func (h *Handler) SetManagedHosts(fn func() []ManagedHost) {
+	h.managedHosts = fn
+}
+
+// managedIndex maps normalized IPs and lower-cased hostnames to probe IDs.
+type managedIndex map[string]string
+
+func (h *Handler) loadManagedIndex() managedIndex {
+	if h.managedHosts == nil {
+		return nil
+	}
+	return newManagedIndex(h.managedHosts())
+}
+
+func newManagedIndex(hosts []ManagedHost) managedIndex {
+	index := make(managedIndex)
+	for _, host := range hosts {
+		for _, addr := range host.Addrs {
+			if ip := normalizeIP(addr); ip != "" {
+				index["ip:"+ip] = host.ProbeID
+			}
+		}
+		if name := normalizeHostname(host.Hostname); name != "" {
+			index["host:"+name] = host.ProbeID
+		}
+	}
+	return index
+}
+
+// match returns the probe ID a candidate corresponds to, matching by IP first
+// and hostname second.
+func (m managedIndex) match(c Candidate) (string, bool) {
+	if len(m) == 0 {
+		return "", false
+	}
+	if ip := normalizeIP(c.IP); ip != "" {
+		if id, ok := m["ip:"+ip]; ok {
+			return id, true
+		}
+	}
+	if name := normalizeHostname(c.Hostname); name != "" {
+		if id, ok := m["host:"+name]; ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+func (m managedIndex) annotate(candidates []Candidate) {
+	for i := range candidates {
+		candidates[i].ManagedProbeID, candidates[i].Managed = m.match(candidates[i])
+	}
+}
+
+// normalizeIP accepts a bare IP or an interface address in CIDR form.
+func normalizeIP(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if ip, _, err := net.ParseCIDR(raw); err == nil {
+		return ip.String()
+	}
+	if ip := net.ParseIP(raw); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// normalizeHostname lower-cases a hostname and strips any domain suffix so a
+// reverse-DNS FQDN matches a probe's short hostname.
+func normalizeHostname(raw string) string {
+	name := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(raw), "."))
+	if short, _, ok := strings.Cut(name, "."); ok {
+		name = short
+	}
+	return name
+}
+
+// diffCandidates compares two runs' candidates keyed by IP. Managed flags
+// must already be set on current and prior.
+func diffCandidates(current, prior []Candidate) ([]Candidate, []Candidate, []CandidateChange) {
+	priorByIP := make(map[string]Candidate, len(prior))
+	for _, c := range prior {
+		priorByIP[c.IP] = c
+	}
+	seen := make(map[string]struct{}, len(current))
+
+	added := make([]Candidate, 0)
+	changed := make([]CandidateChange, 0)
+	for _, c := range current {
+		seen[c.IP] = struct{}{}
+		before, ok := priorByIP[c.IP]
+		if !ok {
+			added = append(added, c)
+			continue
+		}
+		if fields := changedFields(before, c); len(fields) > 0 {
+			changed = append(changed, CandidateChange{
+				IP:      c.IP,
+				Fields:  fields,
+				Before:  before,
+				After:   c,
+				Managed: c.Managed,
+			})
+		}
+	}
+
+	removed := make([]Candidate, 0)
+	for _, c := range prior {
+		if _, ok := seen[c.IP]; !ok {
+			removed = append(removed, c)
+		}
+	}
+	return added, removed, changed
+}
+
+func changedFields(before, after Candidate) []string {
+	var fields []string
+	if !strings.EqualFold(before.Hostname, after.Hostname) {
+		fields = append(fields, "hostname")
+	}
+	beforePorts := slices.Clone(before.OpenPorts)
+	afterPorts := slices.Clone(after.OpenPorts)
+	slices.Sort(beforePorts)
+	slices.Sort(afterPorts)
+	if !slices.Equal(beforePorts, afterPorts) {
+		fields = append(fields, "open_ports")
+	}
+	if before.Confidence != after.Confidence {
+		fields = append(fields, "confidence")
+	}
+	return fields
+}
+
+func (h *Handler) HandleDiffRuns(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		writeError(w, http.StatusServiceUnavailable, "service_unavailable", "discovery store unavailable")
+		return
+	}
+
+	runID, err := strconv.ParseInt(strings.TrimSpace(r.PathValue("id")), 10, 64)
+	if err != nil || runID <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "run id must be a positive integer")
+		return
+	}
+	againstID, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("against")), 10, 64)
+	if err != nil || againstID <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "against must be a positive run id")
+		return
+	}
+	if againstID == runID {
+		writeError(w, http.StatusBadRequest, "invalid_request", "against must reference a different run")
+		return
+	}
+
+	current, err := h.store.GetRunWithCandidates(runID)
+	if err != nil {
+		if IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "not_found", "run not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal_error", "failed to load run")
+		return
+	}
+	prior, err := h.store.GetRunWithCandidates(againstID)
+	if err != nil {
+		if IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "not_found", "comparison run not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal_error", "failed to load comparison run")
+		return
+	}
+
+	index := h.loadManagedIndex()
+	index.annotate(current.Candidates)
+	index.annotate(prior.Candidates)
+
+	added, removed, changed := diffCandidates(current.Candidates, prior.Candidates)
+	summary := DiffSummary{Added: len(added), Removed: len(removed), Changed: len(changed)}
+	for _, c := range added {
+		if !c.Managed {
+			summary.New++
+		}
+	}
+
+	writeJSON(w, http.StatusOK, RunDiff{
+		Run:     current.Run,
+		Against: prior.Run,
+		Added:   added,
+		Removed: removed,
+		Changed: changed,
+		Summary: summary,
+	})
+}
@@ -0,0 +1,133 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManagedIndexMatchesByIPAndHostname(t *testing.T) {
+	index := newManagedIndex([]ManagedHost{
+		{ProbeID: "probe-a", Hostname: "web-01", Addrs: []string{"10.0.0.5/24", "fe80::1/64"}},
+		{ProbeID: "probe-b", Addrs: []string{"10.0.0.9"}},
+	})
+
+	cases := []struct {
+		candidate Candidate
+		want      string
+	}{
+		{Candidate{IP: "10.0.0.5"}, "probe-a"},
+		{Candidate{IP: "10.0.0.77", Hostname: "WEB-01.lab.example."}, "probe-a"},
+		{Candidate{IP: "10.0.0.9"}, "probe-b"},
+		{Candidate{IP: "10.0.0.10", Hostname: "db-01"}, ""},
+	}
+	for _, tc := range cases {
+		got, ok := index.match(tc.candidate)
+		if got != tc.want || ok != (tc.want != "") {
+			t.Fatalf("match(%+v) = %q, %v; want %q", tc.candidate, got, ok, tc.want)
+		}
+	}
+
+	var empty managedIndex
+	if _, ok := empty.match(Candidate{IP: "10.0.0.5"}); ok {
+		t.Fatal("expected nil index to match nothing")
+	}
+}
+
+func TestDiffCandidatesClassifiesHosts(t *testing.T) {
+	prior := []Candidate{
+		{IP: "10.0.0.1", Hostname: "a", OpenPorts: []int{22, 80}, Confidence: ConfidenceHigh},
+		{IP: "10.0.0.2", OpenPorts: []int{22}, Confidence: ConfidenceHigh},
+		{IP: "10.0.0.3", OpenPorts: []int{443}, Confidence: ConfidenceMedium},
+	}
+	current := []Candidate{
+		{IP: "10.0.0.1", Hostname: "A", OpenPorts: []int{80, 22}, Confidence: ConfidenceHigh},
+		{IP: "10.0.0.3", OpenPorts: []int{443, 8443}, Confidence: ConfidenceHigh},
+		{IP: "10.0.0.4", OpenPorts: []int{22}, Confidence: ConfidenceHigh, Managed: true},
+	}
+
+	added, removed, changed := diffCandidates(current, prior)
+	if len(added) != 1 || added[0].IP != "10.0.0.4" || !added[0].Managed {
+		t.Fatalf("unexpected added: %+v", added)
+	}
+	if len(removed) != 1 || removed[0].IP != "10.0.0.2" {
+		t.Fatalf("unexpected removed: %+v", removed)
+	}
+	if len(changed) != 1 || changed[0].IP != "10.0.0.3" {
+		t.Fatalf("unexpected changed: %+v", changed)
+	}
+	if got := changed[0].Fields; len(got) != 2 || got[0] != "open_ports" || got[1] != "confidence" {
+		t.Fatalf("unexpected changed fields: %v", got)
+	}
+}
+
+func TestHandleDiffRunsFlagsManagedHosts(t *testing.T) {
+	results := [][]Candidate{
+		{{IP: "192.168.1.10", OpenPorts: []int{22}, Confidence: ConfidenceHigh}},
+		{
+			{IP: "192.168.1.10", OpenPorts: []int{22}, Confidence: ConfidenceHigh},
+			{IP: "192.168.1.20", Hostname: "probe-host", OpenPorts: []int{22}, Confidence: ConfidenceHigh},
+			{IP: "192.168.1.30", OpenPorts: []int{22}, Confidence: ConfidenceHigh},
+		},
+	}
+	h := newTestHandler(t, scannerFunc(func(ctx context.Context, cidr string, timeout time.Duration) ([]Candidate, error) {
+		next := results[0]
+		results = results[1:]
+		return next, nil
+	}))
+	h.SetManagedHosts(func() []ManagedHost {
+		return []ManagedHost{{ProbeID: "probe-1", Hostname: "probe-host"}}
+	})
+
+	first := runScan(t, h, "192.168.1.0/24")
+	second := runScan(t, h, "192.168.1.0/24")
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/discovery/runs/x/diff?against="+strconv.FormatInt(first, 10), nil)
+	request.SetPathValue("id", strconv.FormatInt(second, 10))
+	recorder := httptest.NewRecorder()
+	h.HandleDiffRuns(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var diff RunDiff
+	if err := json.NewDecoder(recorder.Body).Decode(&diff); err != nil {
+		t.Fatalf("decode diff: %v", err)
+	}
+	if diff.Summary.Added != 2 || diff.Summary.New != 1 || diff.Summary.Removed != 0 || diff.Summary.Changed != 0 {
+		t.Fatalf("unexpected summary: %+v", diff.Summary)
+	}
+	for _, c := range diff.Added {
+		if want := c.IP == "192.168.1.20"; c.Managed != want {
+			t.Fatalf("candidate %s managed=%v, want %v", c.IP, c.Managed, want)
+		}
+	}
+
+	missing := httptest.NewRequest(http.MethodGet, "/api/v1/discovery/runs/x/diff", nil)
+	missing.SetPathValue("id", strconv.FormatInt(second, 10))
+	recorder = httptest.NewRecorder()
+	h.HandleDiffRuns(recorder, missing)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without against, got %d", recorder.Code)
+	}
+}
+
+func runScan(t *testing.T, h *Handler, cidr string) int64 {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/discovery/scan", strings.NewReader(`{"cidr":"`+cidr+`"}`))
+	recorder := httptest.NewRecorder()
+	h.HandleScan(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("scan: expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response ScanResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decode scan: %v", err)
+	}
+	return response.Run.ID
+}
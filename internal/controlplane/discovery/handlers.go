@@ -19,9 +19,10 @@ type ScannerAPI interface {
 
 // Handler serves discovery APIs.
 type Handler struct {
-	store      *Store
-	scanner    ScannerAPI
-	tokenStore *api.TokenStore
+	store        *Store
+	scanner      ScannerAPI
+	tokenStore   *api.TokenStore
+	managedHosts func() []ManagedHost
 }
 
 func NewHandler(store *Store, scanner ScannerAPI, tokenStore *api.TokenStore) *Handler {
@@ -84,6 +85,7 @@ func (h *Handler) HandleScan(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "internal_error", "failed to load scan result")
 		return
 	}
+	h.loadManagedIndex().annotate(resp.Candidates)
 
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -135,6 +137,7 @@ func (h *Handler) HandleGetRun(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "internal_error", "failed to load run")
 		return
 	}
+	h.loadManagedIndex().annotate(resp.Candidates)
 
 	writeJSON(w, http.StatusOK, resp)
 }
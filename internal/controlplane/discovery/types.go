@@ -33,6 +33,9 @@ type Candidate struct {
 	Hostname   string `json:"hostname,omitempty"`
 	OpenPorts  []int  `json:"open_ports"`
 	Confidence string `json:"confidence"`
+	// Managed is set when the host already corresponds to a registered probe.
+	Managed        bool   `json:"managed"`
+	ManagedProbeID string `json:"managed_probe_id,omitempty"`
 }
 
 // ScanResponse is returned by POST /api/v1/discovery/scan and run detail endpoint.
@@ -12,37 +12,53 @@ import (
 type EventType string
 
 const (
-	ProbeConnected         EventType = "probe.connected"
-	ProbeReconnected       EventType = "probe.reconnected"
-	ProbeDisconnected      EventType = "probe.disconnected"
-	ProbeRegistered        EventType = "probe.registered"
-	ProbeOffline           EventType = "probe.offline"
-	CommandDispatched      EventType = "command.dispatched"
-	CommandCompleted       EventType = "command.completed"
-	CommandFailed          EventType = "command.failed"
-	ApprovalNeeded         EventType = "approval.needed"
-	ApprovalDecided        EventType = "approval.decided"
-	PolicyChanged          EventType = "policy.changed"
-	ChatMessage            EventType = "chat.message"
-	AlertFired             EventType = "alert.fired"
-	AlertResolved          EventType = "alert.resolved"
-	JobCreated             EventType = "job.created"
-	JobUpdated             EventType = "job.updated"
-	JobDeleted             EventType = "job.deleted"
-	JobRunAdmissionAllowed EventType = "job.run.admission_allowed"
-	JobRunAdmissionQueued  EventType = "job.run.admission_queued"
-	JobRunAdmissionDenied  EventType = "job.run.admission_denied"
-	JobRunQueued           EventType = "job.run.queued"
-	JobRunStarted          EventType = "job.run.started"
-	JobRunRetryScheduled   EventType = "job.run.retry_scheduled"
-	JobRunSucceeded        EventType = "job.run.succeeded"
-	JobRunFailed           EventType = "job.run.failed"
-	JobRunCanceled         EventType = "job.run.canceled"
-	JobRunDenied           EventType = "job.run.denied"
+	ProbeConnected          EventType = "probe.connected"
+	ProbeReconnected        EventType = "probe.reconnected"
+	ProbeDisconnected       EventType = "probe.disconnected"
+	ProbeRegistered         EventType = "probe.registered"
+	ProbeOffline            EventType = "probe.offline"
+	ProbeAlert              EventType = "probe.alert"
+	RolloutStarted          EventType = "rollout.started"
+	RolloutCanaryPassed     EventType = "rollout.canary_passed"
+	RolloutCompleted        EventType = "rollout.completed"
+	RolloutFailed           EventType = "rollout.failed"
+	CommandDispatched       EventType = "command.dispatched"
+	CommandCompleted        EventType = "command.completed"
+	CommandFailed           EventType = "command.failed"
+	ApprovalNeeded          EventType = "approval.needed"
+	ApprovalDecided         EventType = "approval.decided"
+	ApprovalEscalated       EventType = "approval.escalated"
+	TaskProgress            EventType = "task.progress"
+	PolicyChanged           EventType = "policy.changed"
+	ChatMessage             EventType = "chat.message"
+	AlertFired              EventType = "alert.fired"
+	AlertResolved           EventType = "alert.resolved"
+	JobCreated              EventType = "job.created"
+	JobUpdated              EventType = "job.updated"
+	JobDeleted              EventType = "job.deleted"
+	JobRunAdmissionAllowed  EventType = "job.run.admission_allowed"
+	JobRunAdmissionQueued   EventType = "job.run.admission_queued"
+	JobRunAdmissionDenied   EventType = "job.run.admission_denied"
+	JobRunQueued            EventType = "job.run.queued"
+	JobRunStarted           EventType = "job.run.started"
+	JobRunRetryScheduled    EventType = "job.run.retry_scheduled"
+	JobRunSucceeded         EventType = "job.run.succeeded"
+	JobRunFailed            EventType = "job.run.failed"
+	JobRunCanceled          EventType = "job.run.canceled"
+	JobRunDenied            EventType = "job.run.denied"
+	JobRunBlocked           EventType = "job.run.blocked"
+	JobRunUnblocked         EventType = "job.run.unblocked"
+	JobRunDependencyTimeout EventType = "job.run.dependency_timeout"
+	JobRunTimedOut          EventType = "job.run.timed_out"
+	JobRunSkipped           EventType = "job.run.skipped"
+
+	ProviderBudgetThreshold EventType = "provider.budget_threshold"
 )
 
 // Event represents a fleet event.
 type Event struct {
+	// ID is assigned by Bus.Publish and increases by one per event.
+	ID        int64       `json:"id,omitempty"`
 	Type      EventType   `json:"type"`
 	ProbeID   string      `json:"probe_id,omitempty"`
 	Summary   string      `json:"summary"`
@@ -56,11 +72,20 @@ func (e Event) JSON() []byte {
 	return data
 }
 
-// Bus is a simple pub/sub event bus.
+// DefaultHistorySize is how many recent events a Bus keeps for replay.
+const DefaultHistorySize = 500
+
+// Bus is a simple pub/sub event bus. It keeps a bounded history of recent
+// events so reconnecting subscribers can replay what they missed.
 type Bus struct {
 	mu          sync.RWMutex
 	subscribers map[string]chan Event
 	bufferSize  int
+
+	lastID      int64
+	history     []Event // ring buffer, oldest at head once full
+	head        int
+	historySize int
 }
 
 // NewBus creates an event bus.
@@ -71,18 +96,42 @@ func NewBus(bufferSize int) *Bus {
 	return &Bus{
 		subscribers: make(map[string]chan Event),
 		bufferSize:  bufferSize,
+		historySize: DefaultHistorySize,
+	}
+}
+
+// SetHistorySize sets how many recent events are kept for replay. Zero
+// disables replay. Shrinking keeps the newest events.
+func (b *Bus) SetHistorySize(n int) {
+	if n < 0 {
+		n = 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	kept := b.historyLocked(0, time.Time{})
+	if len(kept) > n {
+		kept = kept[len(kept)-n:]
 	}
+	b.history = kept
+	b.head = 0
+	b.historySize = n
 }
 
-// Publish sends an event to all subscribers.
+// Publish assigns the event an ID, records it for replay and sends it to
+// all subscribers.
 // Non-blocking: drops events for slow subscribers.
 func (b *Bus) Publish(evt Event) {
 	if evt.Timestamp.IsZero() {
 		evt.Timestamp = time.Now().UTC()
 	}
 
-	b.mu.RLock()
-	defer b.mu.RUnlock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	evt.ID = b.lastID
+	b.record(evt)
 
 	for _, ch := range b.subscribers {
 		select {
@@ -103,6 +152,65 @@ func (b *Bus) Subscribe(id string) <-chan Event {
 	return ch
 }
 
+// ReplayQuery selects buffered events. Events must satisfy both fields that
+// are set.
+type ReplayQuery struct {
+	// AfterID returns events with a greater ID.
+	AfterID int64
+	// Since returns events at or after this time.
+	Since time.Time
+}
+
+// SubscribeWithReplay is Subscribe that also returns the buffered events
+// matching q, oldest first. Replay and subscription happen atomically, so no
+// event is missed or delivered twice between them.
+func (b *Bus) SubscribeWithReplay(id string, q ReplayQuery) ([]Event, <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, b.bufferSize)
+	b.subscribers[id] = ch
+	return b.historyLocked(q.AfterID, q.Since), ch
+}
+
+// Replay returns the buffered events matching q, oldest first.
+func (b *Bus) Replay(q ReplayQuery) []Event {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.historyLocked(q.AfterID, q.Since)
+}
+
+// LastID returns the ID of the most recently published event.
+func (b *Bus) LastID() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.lastID
+}
+
+func (b *Bus) record(evt Event) {
+	if b.historySize == 0 {
+		return
+	}
+	if len(b.history) < b.historySize {
+		b.history = append(b.history, evt)
+		return
+	}
+	b.history[b.head] = evt
+	b.head = (b.head + 1) % b.historySize
+}
+
+func (b *Bus) historyLocked(afterID int64, since time.Time) []Event {
+	out := make([]Event, 0, len(b.history))
+	for i := range b.history {
+		evt := b.history[(b.head+i)%len(b.history)]
+		if evt.ID <= afterID || (!since.IsZero() && evt.Timestamp.Before(since)) {
+			continue
+		}
+		out = append(out, evt)
+	}
+	return out
+}
+
 // Unsubscribe removes a subscriber.
 func (b *Bus) Unsubscribe(id string) {
 	b.mu.Lock()
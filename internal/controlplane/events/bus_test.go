@@ -86,3 +86,97 @@ func TestEventJSON(t *testing.T) {
 		t.Fatal("empty JSON")
 	}
 }
+
+func TestPublishAssignsMonotonicIDs(t *testing.T) {
+	bus := NewBus(16)
+	ch := bus.Subscribe("s1")
+	defer bus.Unsubscribe("s1")
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(Event{Type: ProbeConnected})
+	}
+	for want := int64(1); want <= 3; want++ {
+		if evt := <-ch; evt.ID != want {
+			t.Fatalf("expected id %d, got %d", want, evt.ID)
+		}
+	}
+	if bus.LastID() != 3 {
+		t.Fatalf("expected last id 3, got %d", bus.LastID())
+	}
+}
+
+func TestReplayAfterID(t *testing.T) {
+	bus := NewBus(16)
+	for i := 0; i < 5; i++ {
+		bus.Publish(Event{Type: ProbeConnected})
+	}
+
+	got := bus.Replay(ReplayQuery{AfterID: 3})
+	if len(got) != 2 || got[0].ID != 4 || got[1].ID != 5 {
+		t.Fatalf("expected events 4 and 5, got %+v", got)
+	}
+}
+
+func TestReplaySince(t *testing.T) {
+	bus := NewBus(16)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		bus.Publish(Event{Type: ProbeConnected, Timestamp: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	got := bus.Replay(ReplayQuery{Since: base.Add(time.Minute)})
+	if len(got) != 2 || got[0].ID != 2 {
+		t.Fatalf("expected events from id 2, got %+v", got)
+	}
+}
+
+func TestReplayBufferIsBounded(t *testing.T) {
+	bus := NewBus(16)
+	bus.SetHistorySize(3)
+	for i := 0; i < 10; i++ {
+		bus.Publish(Event{Type: ProbeConnected})
+	}
+
+	got := bus.Replay(ReplayQuery{})
+	if len(got) != 3 {
+		t.Fatalf("expected 3 buffered events, got %d", len(got))
+	}
+	for i, evt := range got {
+		if evt.ID != int64(8+i) {
+			t.Fatalf("expected ids 8-10 oldest first, got %+v", got)
+		}
+	}
+
+	bus.SetHistorySize(2)
+	if got := bus.Replay(ReplayQuery{}); len(got) != 2 || got[0].ID != 9 {
+		t.Fatalf("expected shrink to keep newest events, got %+v", got)
+	}
+
+	bus.SetHistorySize(0)
+	bus.Publish(Event{Type: ProbeConnected})
+	if got := bus.Replay(ReplayQuery{}); len(got) != 0 {
+		t.Fatalf("expected replay disabled, got %+v", got)
+	}
+}
+
+func TestSubscribeWithReplayHasNoGap(t *testing.T) {
+	bus := NewBus(16)
+	bus.Publish(Event{Type: ProbeConnected})
+	bus.Publish(Event{Type: ProbeDisconnected})
+
+	replay, ch := bus.SubscribeWithReplay("late", ReplayQuery{AfterID: 1})
+	defer bus.Unsubscribe("late")
+	bus.Publish(Event{Type: ProbeReconnected})
+
+	if len(replay) != 1 || replay[0].ID != 2 {
+		t.Fatalf("expected replay of event 2, got %+v", replay)
+	}
+	select {
+	case evt := <-ch:
+		if evt.ID != 3 {
+			t.Fatalf("expected live event 3, got %d", evt.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for live event")
+	}
+}
@@ -0,0 +1,60 @@
+package fleet
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Annotation limits keep operator notes small; they are stored inline on
+// every probe row and returned with every probe listing.
+const (
+	MaxAnnotations          = 32
+	MaxAnnotationKeyLength  = 63
+	MaxAnnotationValueBytes = 1024
+)
+
+// NormalizeAnnotations trims keys and values, drops entries with an empty
+// value and enforces the annotation limits. A nil or empty result clears all
+// annotations.
+func NormalizeAnnotations(in map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(in))
+	for rawKey, rawValue := range in {
+		key := strings.TrimSpace(rawKey)
+		value := strings.TrimSpace(rawValue)
+		if key == "" {
+			return nil, fmt.Errorf("annotation keys must not be empty")
+		}
+		if utf8.RuneCountInString(key) > MaxAnnotationKeyLength {
+			return nil, fmt.Errorf("annotation key %q exceeds %d characters", key, MaxAnnotationKeyLength)
+		}
+		if strings.ContainsAny(key, " \t\r\n") {
+			return nil, fmt.Errorf("annotation key %q must not contain whitespace", key)
+		}
+		if len(value) > MaxAnnotationValueBytes {
+			return nil, fmt.Errorf("annotation %q exceeds %d bytes", key, MaxAnnotationValueBytes)
+		}
+		if value == "" {
+			continue
+		}
+		out[key] = value
+	}
+	if len(out) > MaxAnnotations {
+		return nil, fmt.Errorf("at most %d annotations are allowed", MaxAnnotations)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func copyAnnotations(in map[string]string) map[string]string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package fleet
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeAnnotations(t *testing.T) {
+	got, err := NormalizeAnnotations(map[string]string{
+		" decommission ": " scheduled 2026-Q2 ",
+		"nic":            "flaky",
+		"cleared":        "  ",
+	})
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if len(got) != 2 || got["decommission"] != "scheduled 2026-Q2" || got["nic"] != "flaky" {
+		t.Fatalf("unexpected annotations: %#v", got)
+	}
+
+	if got, err := NormalizeAnnotations(map[string]string{"gone": ""}); err != nil || got != nil {
+		t.Fatalf("expected empty values to clear annotations, got %#v err=%v", got, err)
+	}
+
+	tooMany := map[string]string{}
+	for i := 0; i <= MaxAnnotations; i++ {
+		tooMany["k"+strconv.Itoa(i)] = "v"
+	}
+	invalid := []map[string]string{
+		{"": "value"},
+		{"has space": "value"},
+		{strings.Repeat("k", MaxAnnotationKeyLength+1): "value"},
+		{"big": strings.Repeat("x", MaxAnnotationValueBytes+1)},
+		tooMany,
+	}
+	for i, in := range invalid {
+		if _, err := NormalizeAnnotations(in); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+}
+
+func TestSetAnnotationsAndSearch(t *testing.T) {
+	m := NewManager(testLogger())
+	m.Register("probe-1", "web-01", "linux", "amd64")
+
+	notes := map[string]string{"nic": "Flaky NIC on eth1"}
+	if err := m.SetAnnotations("probe-1", notes); err != nil {
+		t.Fatalf("set annotations: %v", err)
+	}
+	notes["nic"] = "mutated"
+	ps, _ := m.Get("probe-1")
+	if ps.Annotations["nic"] != "Flaky NIC on eth1" {
+		t.Fatalf("expected annotations to be copied, got %#v", ps.Annotations)
+	}
+
+	inv := m.Inventory(InventoryFilter{})
+	if len(inv.Probes) != 1 || inv.Probes[0].Annotations["nic"] != "Flaky NIC on eth1" {
+		t.Fatalf("expected annotations in inventory, got %+v", inv.Probes)
+	}
+	if !matchesFederationSearchInProbe(inv.Probes[0], "flaky nic") {
+		t.Fatal("expected annotation value to match search")
+	}
+	if !matchesFederationSearchInProbe(inv.Probes[0], "nic") {
+		t.Fatal("expected annotation key to match search")
+	}
+	if matchesFederationSearchInProbe(inv.Probes[0], "decommission") {
+		t.Fatal("expected unrelated search not to match")
+	}
+
+	if err := m.SetAnnotations("probe-1", nil); err != nil {
+		t.Fatalf("clear annotations: %v", err)
+	}
+	ps, _ = m.Get("probe-1")
+	if ps.Annotations != nil {
+		t.Fatalf("expected annotations cleared, got %#v", ps.Annotations)
+	}
+	if err := m.SetAnnotations("missing", notes); err == nil {
+		t.Fatal("expected error for unknown probe")
+	}
+}
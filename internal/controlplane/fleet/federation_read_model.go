@@ -109,6 +109,28 @@ type FederationSourceAdapter interface {
 	Inventory(ctx context.Context, filter InventoryFilter) (FederationSourceResult, error)
 }
 
+// FederationSourceStaleness is implemented by adapters that sync on their own
+// schedule and need a staleness window other than the store default.
+type FederationSourceStaleness interface {
+	StaleAfter() time.Duration
+}
+
+// FederationSourceSync reports when a self-syncing source last pulled its
+// upstream inventory. StartedAt is when the source was created, so a sync
+// loop that never succeeds can still be judged stale.
+type FederationSourceSync struct {
+	StartedAt     time.Time `json:"started_at"`
+	LastAttemptAt time.Time `json:"last_attempt_at,omitempty"`
+	LastSuccessAt time.Time `json:"last_success_at,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+// FederationSourceSyncReporter is implemented by adapters that run their own
+// sync loop.
+type FederationSourceSyncReporter interface {
+	SyncStatus() FederationSourceSync
+}
+
 // FleetSourceAdapter wraps the existing Fleet inventory as a federation source.
 type FleetSourceAdapter struct {
 	source FederationSourceDescriptor
@@ -158,6 +180,7 @@ type FederatedSourceConsistency struct {
 	Degraded           bool                              `json:"degraded"`
 	FailoverMode       FederationFailoverMode            `json:"failover_mode"`
 	SnapshotAgeSeconds int64                             `json:"snapshot_age_seconds,omitempty"`
+	StaleAfterSeconds  int64                             `json:"stale_after_seconds,omitempty"`
 }
 
 // FederatedSourceSummary reports per-source inventory + health state.
@@ -169,6 +192,8 @@ type FederatedSourceSummary struct {
 	Error       string                     `json:"error,omitempty"`
 	CollectedAt time.Time                  `json:"collected_at,omitempty"`
 	Consistency FederatedSourceConsistency `json:"consistency"`
+	// Sync is set for sources that sync on their own loop.
+	Sync *FederationSourceSync `json:"sync,omitempty"`
 }
 
 // FederatedSourceHealth represents per-source health rollup entries.
@@ -343,6 +368,9 @@ func (s *FederationStore) Inventory(ctx context.Context, filter FederationFilter
 			},
 		}
 
+		summary.Sync = sourceSyncStatus(adapter)
+		staleAfter := sourceStaleAfter(adapter, s.staleAfter)
+
 		cacheKey := federationSnapshotCacheKey(source.ID, invFilter)
 		sourceResult, sourceErr := adapter.Inventory(ctx, invFilter)
 		failoverUsed := false
@@ -364,6 +392,7 @@ func (s *FederationStore) Inventory(ctx context.Context, filter FederationFilter
 					Degraded:     true,
 					FailoverMode: FederationFailoverUnavailable,
 				}
+				summary.Consistency.StaleAfterSeconds = int64(staleAfter / time.Second)
 				result.Sources = append(result.Sources, summary)
 				result.Health.Sources = append(result.Health.Sources, FederatedSourceHealth{
 					Source:      summary.Source,
@@ -391,7 +420,7 @@ func (s *FederationStore) Inventory(ctx context.Context, filter FederationFilter
 			summary.Error = strings.TrimSpace(sourceErr.Error())
 		}
 
-		sourceConsistency, sourceStatus, extraWarnings := classifyFederationSourceConsistency(sourceResult, observedAt, s.staleAfter, sourceErr, failoverUsed)
+		sourceConsistency, sourceStatus, extraWarnings := classifyFederationSourceConsistency(sourceResult, observedAt, staleAfter, sourceErr, failoverUsed)
 		summary.Consistency = sourceConsistency
 		summary.Source.Status = sourceStatus
 		if summary.Consistency.Completeness == FederationCompletenessPartial {
@@ -530,6 +559,7 @@ func classifyFederationSourceConsistency(result FederationSourceResult, observed
 		Completeness: FederationCompletenessComplete,
 		FailoverMode: FederationFailoverNone,
 	}
+	consistency.StaleAfterSeconds = int64(staleAfter / time.Second)
 	extraWarnings := []string{}
 
 	if sourceErr != nil && !failoverUsed {
@@ -794,6 +824,9 @@ func matchesFederationSearchInProbe(probe ProbeInventorySummary, needle string)
 		probe.Arch,
 		probe.Kernel,
 		string(probe.PolicyLevel),
+		probe.Site,
+		probe.Role,
+		probe.PrimaryIP,
 	}
 	for _, field := range fields {
 		if strings.Contains(strings.ToLower(strings.TrimSpace(field)), needle) {
@@ -807,6 +840,12 @@ func matchesFederationSearchInProbe(probe ProbeInventorySummary, needle string)
 		}
 	}
 
+	for key, value := range probe.Annotations {
+		if strings.Contains(strings.ToLower(key), needle) || strings.Contains(strings.ToLower(value), needle) {
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -977,6 +1016,30 @@ func (a *descriptorSourceAdapter) Source() FederationSourceDescriptor {
 	return a.source
 }
 
+func sourceStaleAfter(adapter FederationSourceAdapter, fallback time.Duration) time.Duration {
+	if wrapped, ok := adapter.(*descriptorSourceAdapter); ok {
+		adapter = wrapped.adapter
+	}
+	if staleness, ok := adapter.(FederationSourceStaleness); ok {
+		if d := staleness.StaleAfter(); d > 0 {
+			return d
+		}
+	}
+	return fallback
+}
+
+func sourceSyncStatus(adapter FederationSourceAdapter) *FederationSourceSync {
+	if wrapped, ok := adapter.(*descriptorSourceAdapter); ok {
+		adapter = wrapped.adapter
+	}
+	reporter, ok := adapter.(FederationSourceSyncReporter)
+	if !ok {
+		return nil
+	}
+	status := reporter.SyncStatus()
+	return &status
+}
+
 func (a *descriptorSourceAdapter) Inventory(ctx context.Context, filter InventoryFilter) (FederationSourceResult, error) {
 	if a.adapter == nil {
 		return FederationSourceResult{}, fmt.Errorf("source adapter unavailable")
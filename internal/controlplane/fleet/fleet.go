@@ -11,26 +11,36 @@ import (
 type Fleet interface {
 	Register(id, hostname, os_, arch string) *ProbeState
 	RegisterRemote(spec RemoteProbeRegistration) (*ProbeState, error)
+	PinRemoteHostKey(id, fingerprint string) error
 	Heartbeat(id string, hb *protocol.HeartbeatPayload) error
+	RecordHealthCheck(id string, hb *protocol.HeartbeatPayload, downServices []string) (HealthScore, error)
 	UpdateInventory(id string, inv *protocol.InventoryPayload) error
 	Get(id string) (*ProbeState, bool)
+	Liveness(id string) (ProbeLiveness, bool)
 	FindByHostname(hostname string) (*ProbeState, bool)
 	List() []*ProbeState
 	ListRemote() []*ProbeState
 	Inventory(filter InventoryFilter) FleetInventory
 	SetPolicy(id string, level protocol.CapabilityLevel) error
 	SetAPIKey(id, apiKey string) error
+	RotateAPIKey(id, newKey string, grace time.Duration) error
+	RevertAPIKeyRotation(id string) error
 	SetStatus(id, status string) error
 	MarkOffline(threshold time.Duration)
 	SetOnline(id string) error
 	Count() map[string]int
 	SetTags(id string, tags []string) error
+	SetAnnotations(id string, annotations map[string]string) error
 	ListByTag(tag string) []*ProbeState
 	TagCounts() map[string]int
 	Delete(id string) error
 	CleanupOffline(olderThan time.Duration) []string
 	SetTenantID(id, tenantID string) error
 	ListByTenant(tenantID string) []*ProbeState
+	SetDraining(id string, draining bool) error
+	SetRateLimited(id string, until time.Time) error
+	SetOfflineThreshold(id string, threshold time.Duration) error
+	SetHeartbeatInterval(id string, interval time.Duration) error
 }
 
 // compile-time interface checks
@@ -9,81 +9,181 @@ type HealthScore struct {
 	Score    int      `json:"score"`  // 0-100 (100 = perfect)
 	Status   string   `json:"status"` // healthy, warning, degraded, critical
 	Warnings []string `json:"warnings,omitempty"`
+	// Pressure records which resources ("load", "memory", "disk") are over
+	// a threshold and at which level ("high" or "critical"). It is carried
+	// into the next scoring pass to apply hysteresis.
+	Pressure map[string]string `json:"pressure,omitempty"`
 }
 
-// Thresholds for health scoring.
+// HealthThresholds configures resource-pressure scoring. Load thresholds are
+// 1-minute load averages; memory and disk thresholds are percent used.
+// HysteresisPct keeps a resource at its current pressure level until usage
+// drops this far (as a percent of the threshold) below the threshold that
+// raised it, so a probe hovering around a line doesn't flap between states.
+type HealthThresholds struct {
+	LoadHigh      float64
+	LoadCritical  float64
+	MemHighPct    float64
+	MemCritPct    float64
+	DiskHighPct   float64
+	DiskCritPct   float64
+	HysteresisPct float64
+}
+
+// Scoring weights. Each resource starts at zero penalty and subtracts
+// healthPenaltyHigh when over its high threshold or healthPenaltyCritical
+// when over its critical threshold, from a starting score of 100. The score
+// maps to a status: >=80 healthy, >=50 warning, >=20 degraded, else critical.
+const (
+	healthPenaltyHigh     = 15
+	healthPenaltyCritical = 30
+)
+
 const (
-	loadHighThreshold = 4.0 // 1-minute load average
-	loadCritThreshold = 8.0
-	memHighPct        = 85.0 // memory usage %
-	memCritPct        = 95.0
-	diskHighPct       = 80.0 // disk usage %
-	diskCritPct       = 95.0
+	pressureHigh     = "high"
+	pressureCritical = "critical"
 )
 
-// ScoreHealth computes a health score from heartbeat + inventory data.
+// DefaultHealthThresholds returns the built-in scoring thresholds.
+func DefaultHealthThresholds() HealthThresholds {
+	return HealthThresholds{
+		LoadHigh:      4.0,
+		LoadCritical:  8.0,
+		MemHighPct:    85.0,
+		MemCritPct:    95.0,
+		DiskHighPct:   80.0,
+		DiskCritPct:   95.0,
+		HysteresisPct: 5.0,
+	}
+}
+
+// withDefaults fills unset (zero or negative) fields from the defaults.
+func (t HealthThresholds) withDefaults() HealthThresholds {
+	d := DefaultHealthThresholds()
+	fill := func(v *float64, def float64) {
+		if *v <= 0 {
+			*v = def
+		}
+	}
+	fill(&t.LoadHigh, d.LoadHigh)
+	fill(&t.LoadCritical, d.LoadCritical)
+	fill(&t.MemHighPct, d.MemHighPct)
+	fill(&t.MemCritPct, d.MemCritPct)
+	fill(&t.DiskHighPct, d.DiskHighPct)
+	fill(&t.DiskCritPct, d.DiskCritPct)
+	fill(&t.HysteresisPct, d.HysteresisPct)
+	return t
+}
+
+// ScoreHealth computes a health score from heartbeat + inventory data using
+// the default thresholds and no hysteresis history.
 func ScoreHealth(hb *protocol.HeartbeatPayload, inv *protocol.InventoryPayload) HealthScore {
-	score := 100
-	var warnings []string
+	return ScoreHealthWith(hb, inv, DefaultHealthThresholds(), nil)
+}
 
+// ScoreHealthWith computes a health score with explicit thresholds. prev is
+// the probe's previous score (may be nil) and drives hysteresis. inv is
+// accepted for future per-CPU scoring; load thresholds are currently absolute.
+func ScoreHealthWith(hb *protocol.HeartbeatPayload, inv *protocol.InventoryPayload, t HealthThresholds, prev *HealthScore) HealthScore {
 	if hb == nil {
 		return HealthScore{Score: 0, Status: "unknown", Warnings: []string{"no heartbeat data"}}
 	}
+	t = t.withDefaults()
 
-	// Load average check (1-minute)
-	load := hb.Load[0]
-	cpus := 1
-	if inv != nil && inv.CPUs > 0 {
-		cpus = inv.CPUs
-	}
-	loadPerCPU := load / float64(cpus)
-	if loadPerCPU >= loadCritThreshold/float64(cpus) {
-		score -= 30
-		warnings = append(warnings, "critical load average")
-	} else if loadPerCPU >= loadHighThreshold/float64(cpus) {
-		score -= 15
-		warnings = append(warnings, "high load average")
+	score := 100
+	var warnings []string
+	pressure := map[string]string{}
+	apply := func(resource, label string, value, high, crit float64) {
+		var prevLevel string
+		if prev != nil {
+			prevLevel = prev.Pressure[resource]
+		}
+		switch pressureLevel(value, high, crit, prevLevel, t.HysteresisPct) {
+		case pressureCritical:
+			score -= healthPenaltyCritical
+			warnings = append(warnings, "critical "+label)
+			pressure[resource] = pressureCritical
+		case pressureHigh:
+			score -= healthPenaltyHigh
+			warnings = append(warnings, "high "+label)
+			pressure[resource] = pressureHigh
+		}
 	}
 
+	// Load average check (1-minute)
+	apply("load", "load average", hb.Load[0], t.LoadHigh, t.LoadCritical)
+
 	// Memory check
 	if hb.MemTotal > 0 {
-		memPct := float64(hb.MemUsed) / float64(hb.MemTotal) * 100
-		if memPct >= memCritPct {
-			score -= 30
-			warnings = append(warnings, "critical memory usage")
-		} else if memPct >= memHighPct {
-			score -= 15
-			warnings = append(warnings, "high memory usage")
-		}
+		apply("memory", "memory usage", float64(hb.MemUsed)/float64(hb.MemTotal)*100, t.MemHighPct, t.MemCritPct)
 	}
 
 	// Disk check
 	if hb.DiskTotal > 0 {
-		diskPct := float64(hb.DiskUsed) / float64(hb.DiskTotal) * 100
-		if diskPct >= diskCritPct {
-			score -= 30
-			warnings = append(warnings, "critical disk usage")
-		} else if diskPct >= diskHighPct {
-			score -= 15
-			warnings = append(warnings, "high disk usage")
-		}
+		apply("disk", "disk usage", float64(hb.DiskUsed)/float64(hb.DiskTotal)*100, t.DiskHighPct, t.DiskCritPct)
 	}
 
 	if score < 0 {
 		score = 0
 	}
 
-	var status string
+	if len(pressure) == 0 {
+		pressure = nil
+	}
+	return HealthScore{Score: score, Status: healthStatus(score), Warnings: warnings, Pressure: pressure}
+}
+
+// healthStatus maps a score to its status.
+func healthStatus(score int) string {
 	switch {
 	case score >= 80:
-		status = "healthy"
+		return "healthy"
 	case score >= 50:
-		status = "warning"
+		return "warning"
 	case score >= 20:
-		status = "degraded"
+		return "degraded"
 	default:
-		status = "critical"
+		return "critical"
 	}
+}
 
-	return HealthScore{Score: score, Status: status, Warnings: warnings}
+// withDownServices applies the critical penalty for each key service that is
+// not active, as found by an on-demand health check.
+func (h HealthScore) withDownServices(down []string) HealthScore {
+	if len(down) == 0 {
+		return h
+	}
+	warnings := append([]string(nil), h.Warnings...)
+	for _, name := range down {
+		h.Score -= healthPenaltyCritical
+		warnings = append(warnings, "service "+name+" not active")
+	}
+	if h.Score < 0 {
+		h.Score = 0
+	}
+	h.Status = healthStatus(h.Score)
+	h.Warnings = warnings
+	return h
+}
+
+// pressureLevel classifies value against high/crit. A resource already at a
+// level stays there until value falls hysteresisPct percent below that
+// level's threshold; rising into a level is never delayed.
+func pressureLevel(value, high, crit float64, prev string, hysteresisPct float64) string {
+	level := ""
+	switch {
+	case value >= crit:
+		level = pressureCritical
+	case value >= high:
+		level = pressureHigh
+	}
+
+	release := 1 - hysteresisPct/100
+	switch {
+	case prev == pressureCritical && level != pressureCritical && value >= crit*release:
+		return pressureCritical
+	case (prev == pressureCritical || prev == pressureHigh) && level == "" && value >= high*release:
+		return pressureHigh
+	}
+	return level
 }
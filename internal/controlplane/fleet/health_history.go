@@ -0,0 +1,234 @@
+package fleet
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	defaultHealthSampleInterval = 5 * time.Minute
+	defaultHealthRetention      = 7 * 24 * time.Hour
+	defaultHealthMaxPerProbe    = 2016 // 7 days at 5-minute samples
+	defaultHealthHistoryLimit   = 288
+	maxHealthHistoryLimit       = 5000
+	// healthHistoryTimeFormat is fixed-width so recorded_at sorts and compares
+	// correctly as text.
+	healthHistoryTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+)
+
+// HealthSample is one persisted health score for a probe.
+type HealthSample struct {
+	ProbeID    string    `json:"probe_id"`
+	Score      int       `json:"score"`
+	Status     string    `json:"status"`
+	Warnings   []string  `json:"warnings,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// HealthHistoryOptions bounds how often and how long health scores are kept.
+type HealthHistoryOptions struct {
+	// SampleInterval is the minimum spacing between stored samples per probe.
+	// A status change is always recorded.
+	SampleInterval time.Duration
+	Retention      time.Duration
+	MaxPerProbe    int
+}
+
+// HealthHistoryStore persists periodic per-probe health scores in SQLite.
+type HealthHistoryStore struct {
+	db   *sql.DB
+	opts HealthHistoryOptions
+
+	mu   sync.Mutex
+	last map[string]HealthSample
+}
+
+// NewHealthHistoryStore opens (or creates) a health history database.
+func NewHealthHistoryStore(dbPath string, opts HealthHistoryOptions) (*HealthHistoryStore, error) {
+	if opts.SampleInterval <= 0 {
+		opts.SampleInterval = defaultHealthSampleInterval
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = defaultHealthRetention
+	}
+	if opts.MaxPerProbe <= 0 {
+		opts.MaxPerProbe = defaultHealthMaxPerProbe
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open health history db: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("set WAL: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA busy_timeout=5000"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("set busy_timeout: %w", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS probe_health_history (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		probe_id    TEXT NOT NULL,
+		score       INTEGER NOT NULL,
+		status      TEXT NOT NULL,
+		warnings    TEXT NOT NULL DEFAULT '[]',
+		recorded_at TEXT NOT NULL
+	)`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create health history table: %w", err)
+	}
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_probe_health_history_probe_time ON probe_health_history(probe_id, recorded_at)`)
+
+	return &HealthHistoryStore{db: db, opts: opts, last: make(map[string]HealthSample)}, nil
+}
+
+// Record stores a health score for probeID unless one was stored within the
+// sample interval with the same status. It reports whether a row was written.
+func (s *HealthHistoryStore) Record(probeID string, health HealthScore, at time.Time) (bool, error) {
+	probeID = strings.TrimSpace(probeID)
+	if probeID == "" {
+		return false, nil
+	}
+	at = at.UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, ok := s.last[probeID]
+	if !ok {
+		prev, ok = s.latest(probeID)
+	}
+	if ok && prev.Status == health.Status && at.Sub(prev.RecordedAt) < s.opts.SampleInterval {
+		return false, nil
+	}
+
+	warnings, _ := json.Marshal(health.Warnings)
+	if _, err := s.db.Exec(`INSERT INTO probe_health_history (probe_id, score, status, warnings, recorded_at) VALUES (?, ?, ?, ?, ?)`,
+		probeID, health.Score, health.Status, string(warnings), at.Format(healthHistoryTimeFormat)); err != nil {
+		return false, fmt.Errorf("insert health sample: %w", err)
+	}
+	s.last[probeID] = HealthSample{ProbeID: probeID, Score: health.Score, Status: health.Status, RecordedAt: at}
+
+	// Keep each probe's history bounded regardless of retention.
+	_, _ = s.db.Exec(`DELETE FROM probe_health_history WHERE probe_id = ? AND id NOT IN (
+		SELECT id FROM probe_health_history WHERE probe_id = ? ORDER BY recorded_at DESC, id DESC LIMIT ?
+	)`, probeID, probeID, s.opts.MaxPerProbe)
+	return true, nil
+}
+
+func (s *HealthHistoryStore) latest(probeID string) (HealthSample, bool) {
+	samples, err := s.query(`SELECT probe_id, score, status, warnings, recorded_at FROM probe_health_history
+		WHERE probe_id = ? ORDER BY recorded_at DESC, id DESC LIMIT 1`, probeID)
+	if err != nil || len(samples) == 0 {
+		return HealthSample{}, false
+	}
+	return samples[0], true
+}
+
+// List returns up to limit samples for probeID recorded at or after since,
+// oldest first.
+func (s *HealthHistoryStore) List(probeID string, since time.Time, limit int) ([]HealthSample, error) {
+	if limit <= 0 {
+		limit = defaultHealthHistoryLimit
+	}
+	if limit > maxHealthHistoryLimit {
+		limit = maxHealthHistoryLimit
+	}
+	samples, err := s.query(`SELECT probe_id, score, status, warnings, recorded_at FROM probe_health_history
+		WHERE probe_id = ? AND recorded_at >= ? ORDER BY recorded_at DESC, id DESC LIMIT ?`,
+		probeID, since.UTC().Format(healthHistoryTimeFormat), limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(samples)-1; i < j; i, j = i+1, j-1 {
+		samples[i], samples[j] = samples[j], samples[i]
+	}
+	return samples, nil
+}
+
+// HealthScoresSince returns all samples for probeID since the given time,
+// oldest first. It satisfies the alert engine's health history contract.
+func (s *HealthHistoryStore) HealthScoresSince(probeID string, since time.Time) ([]HealthSample, error) {
+	return s.List(probeID, since, maxHealthHistoryLimit)
+}
+
+// DeleteProbe removes all history for a probe.
+func (s *HealthHistoryStore) DeleteProbe(probeID string) (int64, error) {
+	s.mu.Lock()
+	delete(s.last, probeID)
+	s.mu.Unlock()
+
+	res, err := s.db.Exec(`DELETE FROM probe_health_history WHERE probe_id = ?`, probeID)
+	if err != nil {
+		return 0, fmt.Errorf("delete health history: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Purge deletes samples older than the configured retention.
+func (s *HealthHistoryStore) Purge(now time.Time) (int64, error) {
+	cutoff := now.UTC().Add(-s.opts.Retention).Format(healthHistoryTimeFormat)
+	res, err := s.db.Exec(`DELETE FROM probe_health_history WHERE recorded_at < ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("purge health history: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// PurgeLoop purges expired samples on interval until ctx is done.
+func (s *HealthHistoryStore) PurgeLoop(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	_, _ = s.Purge(time.Now())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = s.Purge(time.Now())
+		}
+	}
+}
+
+// Close shuts down the store.
+func (s *HealthHistoryStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *HealthHistoryStore) query(query string, args ...any) ([]HealthSample, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query health history: %w", err)
+	}
+	defer rows.Close()
+
+	samples := make([]HealthSample, 0)
+	for rows.Next() {
+		var (
+			sample     HealthSample
+			warnings   string
+			recordedAt string
+		)
+		if err := rows.Scan(&sample.ProbeID, &sample.Score, &sample.Status, &warnings, &recordedAt); err != nil {
+			return nil, fmt.Errorf("scan health sample: %w", err)
+		}
+		_ = json.Unmarshal([]byte(warnings), &sample.Warnings)
+		sample.RecordedAt, _ = time.Parse(time.RFC3339Nano, recordedAt)
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
@@ -0,0 +1,83 @@
+package fleet
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestHealthHistory(t *testing.T, opts HealthHistoryOptions) *HealthHistoryStore {
+	t.Helper()
+	s, err := NewHealthHistoryStore(filepath.Join(t.TempDir(), "health_history.db"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestHealthHistoryThinsSamplesByInterval(t *testing.T) {
+	s := newTestHealthHistory(t, HealthHistoryOptions{SampleInterval: 5 * time.Minute})
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		offset time.Duration
+		health HealthScore
+		want   bool
+	}{
+		{0, HealthScore{Score: 100, Status: "healthy"}, true},
+		{time.Minute, HealthScore{Score: 95, Status: "healthy"}, false},
+		{2 * time.Minute, HealthScore{Score: 70, Status: "warning"}, true}, // status change
+		{7 * time.Minute, HealthScore{Score: 70, Status: "warning"}, true},
+	}
+	for i, step := range steps {
+		wrote, err := s.Record("p1", step.health, start.Add(step.offset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wrote != step.want {
+			t.Fatalf("step %d: wrote=%v, want %v", i, wrote, step.want)
+		}
+	}
+
+	samples, err := s.List("p1", start, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 3 || samples[0].Score != 100 || !samples[2].RecordedAt.Equal(start.Add(7*time.Minute)) {
+		t.Fatalf("unexpected samples: %+v", samples)
+	}
+}
+
+func TestHealthHistoryBoundedAndPurgeable(t *testing.T) {
+	s := newTestHealthHistory(t, HealthHistoryOptions{SampleInterval: time.Second, Retention: time.Hour, MaxPerProbe: 3})
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		if _, err := s.Record("p1", HealthScore{Score: 100 - i, Status: "healthy"}, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, _ = s.Record("p2", HealthScore{Score: 90, Status: "healthy"}, start)
+
+	samples, _ := s.List("p1", time.Time{}, 0)
+	if len(samples) != 3 || samples[0].Score != 98 {
+		t.Fatalf("expected newest 3 samples kept, got %+v", samples)
+	}
+
+	purged, err := s.Purge(start.Add(time.Hour + 4*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 3 {
+		t.Fatalf("expected 3 samples past retention purged, got %d", purged)
+	}
+
+	deleted, err := s.DeleteProbe("p1")
+	if err != nil || deleted != 1 {
+		t.Fatalf("expected 1 remaining p1 sample deleted, got %d (%v)", deleted, err)
+	}
+	if samples, _ := s.HealthScoresSince("p1", time.Time{}); len(samples) != 0 {
+		t.Fatalf("expected p1 history cleared, got %+v", samples)
+	}
+}
@@ -105,3 +105,56 @@ func TestHealthScoreZeroTotals(t *testing.T) {
 		t.Fatal("score should not be negative")
 	}
 }
+
+func TestHealthScoreConfigurableThresholds(t *testing.T) {
+	hb := &protocol.HeartbeatPayload{
+		Load:      [3]float64{0.5, 0.3, 0.2},
+		DiskUsed:  170 * 1024 * 1024 * 1024, // 85%
+		DiskTotal: 200 * 1024 * 1024 * 1024,
+	}
+
+	if h := ScoreHealth(hb, nil); h.Score != 85 || h.Pressure["disk"] != "high" {
+		t.Fatalf("expected default disk high at 85%%, got %d %v", h.Score, h.Pressure)
+	}
+
+	h := ScoreHealthWith(hb, nil, HealthThresholds{DiskHighPct: 90}, nil)
+	if h.Score != 100 || len(h.Warnings) != 0 || h.Pressure != nil {
+		t.Fatalf("expected no pressure below a 90%% threshold, got %d %v", h.Score, h.Warnings)
+	}
+}
+
+func TestHealthScoreHysteresis(t *testing.T) {
+	thresholds := HealthThresholds{DiskHighPct: 90, DiskCritPct: 98, HysteresisPct: 5}
+	disk := func(pct uint64) *protocol.HeartbeatPayload {
+		return &protocol.HeartbeatPayload{DiskUsed: pct, DiskTotal: 100}
+	}
+
+	h := ScoreHealthWith(disk(91), nil, thresholds, nil)
+	if h.Pressure["disk"] != "high" {
+		t.Fatalf("expected high disk pressure at 91%%, got %v", h.Pressure)
+	}
+
+	// 88% is under the 90% line but inside the 5% band (85.5%): stay high.
+	h = ScoreHealthWith(disk(88), nil, thresholds, &h)
+	if h.Pressure["disk"] != "high" || h.Score != 85 {
+		t.Fatalf("expected hysteresis to hold high pressure at 88%%, got %d %v", h.Score, h.Pressure)
+	}
+
+	// Without history the same reading is healthy.
+	if fresh := ScoreHealthWith(disk(88), nil, thresholds, nil); fresh.Pressure != nil {
+		t.Fatalf("expected no pressure without history, got %v", fresh.Pressure)
+	}
+
+	// Dropping below the band clears it.
+	h = ScoreHealthWith(disk(85), nil, thresholds, &h)
+	if h.Pressure != nil || h.Score != 100 {
+		t.Fatalf("expected pressure to clear at 85%%, got %d %v", h.Score, h.Pressure)
+	}
+
+	// Critical steps down to high, not straight to clear.
+	h = ScoreHealthWith(disk(99), nil, thresholds, nil)
+	h = ScoreHealthWith(disk(92), nil, thresholds, &h)
+	if h.Pressure["disk"] != "high" {
+		t.Fatalf("expected critical to step down to high at 92%%, got %v", h.Pressure)
+	}
+}
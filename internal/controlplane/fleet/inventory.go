@@ -29,6 +29,13 @@ type ProbeInventorySummary struct {
 	CPUs        int                      `json:"cpus"`
 	RAMBytes    uint64                   `json:"ram_bytes"`
 	DiskBytes   uint64                   `json:"disk_bytes"`
+
+	// Site, Role and PrimaryIP are set by external inventory sources (NetBox).
+	Site      string `json:"site,omitempty"`
+	Role      string `json:"role,omitempty"`
+	PrimaryIP string `json:"primary_ip,omitempty"`
+
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // FleetAggregates summarizes fleet totals across the selected probes.
@@ -117,6 +124,7 @@ func toInventorySummary(ps *ProbeState) ProbeInventorySummary {
 		PolicyLevel: ps.PolicyLevel,
 		Tags:        append([]string(nil), ps.Tags...),
 		LastSeen:    ps.LastSeen,
+		Annotations: copyAnnotations(ps.Annotations),
 	}
 
 	if ps.Inventory == nil {
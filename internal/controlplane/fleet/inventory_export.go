@@ -0,0 +1,135 @@
+package fleet
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InventoryExportColumns is the CSV header written by StreamInventoryCSV.
+var InventoryExportColumns = []string{
+	"id", "hostname", "status", "os", "arch", "kernel", "policy_level", "tags",
+	"last_seen", "collected_at", "cpus", "ram_bytes", "disk_bytes",
+	"site", "role", "primary_ip", "annotations",
+}
+
+// SelectInventoryProbes applies an inventory filter and orders the result by
+// hostname (falling back to ID), matching Inventory. Only pointers are
+// copied; summaries are built one at a time while streaming.
+func SelectInventoryProbes(probes []*ProbeState, filter InventoryFilter) []*ProbeState {
+	statusFilter := strings.ToLower(strings.TrimSpace(filter.Status))
+	tagFilter := strings.ToLower(strings.TrimSpace(filter.Tag))
+
+	selected := make([]*ProbeState, 0, len(probes))
+	for _, ps := range probes {
+		if statusFilter != "" && strings.ToLower(ps.Status) != statusFilter {
+			continue
+		}
+		if tagFilter != "" && !hasTag(ps.Tags, tagFilter) {
+			continue
+		}
+		selected = append(selected, ps)
+	}
+
+	sortKey := func(ps *ProbeState) string {
+		if key := strings.ToLower(strings.TrimSpace(ps.Hostname)); key != "" {
+			return key
+		}
+		return ps.ID
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return sortKey(selected[i]) < sortKey(selected[j])
+	})
+	return selected
+}
+
+// StreamInventoryCSV writes one CSV row per probe. Tags are joined with ";".
+func StreamInventoryCSV(ctx context.Context, w io.Writer, probes []*ProbeState) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(InventoryExportColumns); err != nil {
+		return err
+	}
+	for _, ps := range probes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s := toInventorySummary(ps)
+		if err := cw.Write([]string{
+			s.ID,
+			s.Hostname,
+			s.Status,
+			s.OS,
+			s.Arch,
+			s.Kernel,
+			string(s.PolicyLevel),
+			strings.Join(s.Tags, ";"),
+			formatExportTime(s.LastSeen),
+			formatExportTime(s.CollectedAt),
+			strconv.Itoa(s.CPUs),
+			strconv.FormatUint(s.RAMBytes, 10),
+			strconv.FormatUint(s.DiskBytes, 10),
+			s.Site,
+			s.Role,
+			s.PrimaryIP,
+			formatExportAnnotations(s.Annotations),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// StreamInventoryJSON writes {"probes":[...],"total":N}, encoding one probe
+// summary at a time rather than marshalling the whole inventory.
+func StreamInventoryJSON(ctx context.Context, w io.Writer, probes []*ProbeState) error {
+	if _, err := io.WriteString(w, `{"probes":[`); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for i, ps := range probes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(toInventorySummary(ps)); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, `],"total":`+strconv.Itoa(len(probes))+"}\n")
+	return err
+}
+
+// formatExportAnnotations renders annotations as key=value pairs sorted by
+// key and separated by ";".
+func formatExportAnnotations(annotations map[string]string) string {
+	if len(annotations) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+annotations[k])
+	}
+	return strings.Join(pairs, ";")
+}
+
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,115 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func exportTestProbes(t *testing.T) []*ProbeState {
+	t.Helper()
+	m := NewManager(testLogger())
+	m.Register("p-db", "db-01", "linux", "amd64")
+	m.Register("p-web", "web-01", "linux", "arm64")
+	m.Register("p-off", "app-01", "linux", "amd64")
+	_ = m.SetTags("p-db", []string{"db", "prod"})
+	_ = m.SetTags("p-web", []string{"web", "prod"})
+	_ = m.SetStatus("p-off", "offline")
+	_ = m.UpdateInventory("p-db", &protocol.InventoryPayload{
+		Hostname:    "db-01",
+		Kernel:      "6.1.0",
+		CPUs:        8,
+		MemTotal:    32 << 30,
+		DiskTotal:   500 << 30,
+		CollectedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	return m.List()
+}
+
+func TestSelectInventoryProbesFiltersAndSorts(t *testing.T) {
+	probes := exportTestProbes(t)
+
+	all := SelectInventoryProbes(probes, InventoryFilter{})
+	if len(all) != 3 || all[0].ID != "p-off" || all[1].ID != "p-db" || all[2].ID != "p-web" {
+		t.Fatalf("expected all probes sorted by hostname, got %v", probeIDs(all))
+	}
+	prod := SelectInventoryProbes(probes, InventoryFilter{Tag: "PROD", Status: "online"})
+	if len(prod) != 2 || prod[0].ID != "p-db" {
+		t.Fatalf("expected online prod probes, got %v", probeIDs(prod))
+	}
+}
+
+func TestStreamInventoryCSV(t *testing.T) {
+	probes := SelectInventoryProbes(exportTestProbes(t), InventoryFilter{})
+
+	var buf bytes.Buffer
+	if err := StreamInventoryCSV(context.Background(), &buf, probes); err != nil {
+		t.Fatalf("stream csv: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected header plus 3 rows, got %d", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(InventoryExportColumns, ",") {
+		t.Fatalf("unexpected header: %v", records[0])
+	}
+	db := records[2]
+	if db[0] != "p-db" || db[5] != "6.1.0" || db[7] != "db;prod" || db[9] != "2026-01-02T03:04:05Z" || db[10] != "8" {
+		t.Fatalf("unexpected db row: %v", db)
+	}
+	if records[1][2] != "offline" {
+		t.Fatalf("expected offline probe included, got %v", records[1])
+	}
+}
+
+func TestStreamInventoryJSON(t *testing.T) {
+	probes := SelectInventoryProbes(exportTestProbes(t), InventoryFilter{Tag: "prod"})
+
+	var buf bytes.Buffer
+	if err := StreamInventoryJSON(context.Background(), &buf, probes); err != nil {
+		t.Fatalf("stream json: %v", err)
+	}
+	var out struct {
+		Probes []ProbeInventorySummary `json:"probes"`
+		Total  int                     `json:"total"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("decode json: %v\n%s", err, buf.String())
+	}
+	if out.Total != 2 || len(out.Probes) != 2 || out.Probes[0].ID != "p-db" || out.Probes[0].RAMBytes != 32<<30 {
+		t.Fatalf("unexpected export: %+v", out)
+	}
+
+	buf.Reset()
+	if err := StreamInventoryJSON(context.Background(), &buf, nil); err != nil {
+		t.Fatalf("stream empty json: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != `{"probes":[],"total":0}` {
+		t.Fatalf("unexpected empty export: %s", buf.String())
+	}
+}
+
+func TestStreamInventoryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := StreamInventoryCSV(ctx, &bytes.Buffer{}, exportTestProbes(t)); err == nil {
+		t.Fatal("expected canceled export to fail")
+	}
+}
+
+func probeIDs(probes []*ProbeState) []string {
+	ids := make([]string, 0, len(probes))
+	for _, ps := range probes {
+		ids = append(ids, ps.ID)
+	}
+	return ids
+}
@@ -30,7 +30,61 @@ type ProbeState struct {
 	TenantID          string                     `json:"tenant_id,omitempty"`
 	Remote            *RemoteProbeConfig         `json:"remote,omitempty"`
 	RemoteCredentials *RemoteProbeCredentials    `json:"-"`
-	lastHB            *protocol.HeartbeatPayload
+	Draining          bool                       `json:"draining,omitempty"`
+	Annotations       map[string]string          `json:"annotations,omitempty"`
+	Version           string                     `json:"version,omitempty"`
+	KeyIssuedAt       *time.Time                 `json:"key_issued_at,omitempty"`
+	KeyRotatedAt      *time.Time                 `json:"key_rotated_at,omitempty"`
+	// OfflineThresholdSec overrides the fleet default offline threshold.
+	OfflineThresholdSec int `json:"offline_threshold_sec,omitempty"`
+	// InFlightCommands is the command count the probe reported on its last
+	// heartbeat.
+	InFlightCommands int `json:"in_flight_commands"`
+	// RateLimitedUntil is when the probe, on its last refusal under its
+	// policy rate limit, said it would accept commands again. It is not
+	// persisted and is in the past once the limit has lifted.
+	RateLimitedUntil *time.Time `json:"rate_limited_until,omitempty"`
+	// HeartbeatIntervalSec is the heartbeat interval the probe advertised at
+	// registration, used to derive a threshold when none is set.
+	HeartbeatIntervalSec int `json:"heartbeat_interval_sec,omitempty"`
+	lastHB               *protocol.HeartbeatPayload
+	graceKey             *apiKeyGrace
+}
+
+// apiKeyGrace keeps the key replaced by a rotation valid for a short window so
+// a probe that reconnects before applying the new key is not locked out. It
+// also holds the prior key metadata so a failed rotation can be reverted.
+type apiKeyGrace struct {
+	key       string
+	until     time.Time
+	issuedAt  *time.Time
+	rotatedAt *time.Time
+}
+
+// AcceptsAPIKey reports whether token is the probe's current API key, or the
+// key it replaced while that key is still within its rotation grace window.
+func (ps *ProbeState) AcceptsAPIKey(token string, now time.Time) bool {
+	if token == "" || ps.APIKey == "" {
+		return false
+	}
+	if ps.APIKey == token {
+		return true
+	}
+	g := ps.graceKey
+	return g != nil && g.key == token && now.Before(g.until)
+}
+
+// KeyAge returns how long the current API key has been in use, falling back
+// to the registration time for keys issued before issue times were recorded.
+func (ps *ProbeState) KeyAge(now time.Time) time.Duration {
+	issued := ps.Registered
+	if ps.KeyIssuedAt != nil {
+		issued = *ps.KeyIssuedAt
+	}
+	if issued.IsZero() {
+		return 0
+	}
+	return now.Sub(issued)
 }
 
 // Manager tracks all probes in the fleet.
@@ -38,6 +92,7 @@ type Manager struct {
 	probes map[string]*ProbeState
 	mu     sync.RWMutex
 	logger *zap.Logger
+	health HealthThresholds
 }
 
 // NewManager creates a fleet manager.
@@ -45,9 +100,18 @@ func NewManager(logger *zap.Logger) *Manager {
 	return &Manager{
 		probes: make(map[string]*ProbeState),
 		logger: logger,
+		health: DefaultHealthThresholds(),
 	}
 }
 
+// SetHealthThresholds replaces the resource-pressure thresholds used to
+// score heartbeats. Zero fields fall back to the defaults.
+func (m *Manager) SetHealthThresholds(t HealthThresholds) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health = t.withDefaults()
+}
+
 // Register adds a new probe to the fleet.
 func (m *Manager) Register(id, hostname, os, arch string) *ProbeState {
 	m.mu.Lock()
@@ -86,18 +150,44 @@ func (m *Manager) Heartbeat(id string, hb *protocol.HeartbeatPayload) error {
 	}
 	ps.LastSeen = time.Now().UTC()
 	ps.lastHB = hb
+	if hb != nil {
+		ps.InFlightCommands = hb.InFlightCommands
+	}
+	if hb != nil && hb.Version != "" {
+		ps.Version = hb.Version
+	}
 
 	// Compute health score
-	h := ScoreHealth(hb, ps.Inventory)
-	ps.Health = &h
+	h := ScoreHealthWith(hb, ps.Inventory, m.health, ps.Health)
+	setHealth(ps, h)
+	return nil
+}
+
+// RecordHealthCheck scores resource readings gathered on demand rather than
+// from a heartbeat, penalising each key service in downServices, and makes
+// the result the probe's current health.
+func (m *Manager) RecordHealthCheck(id string, hb *protocol.HeartbeatPayload, downServices []string) (HealthScore, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	// Auto-detect degraded status
+	ps, ok := m.probes[id]
+	if !ok {
+		return HealthScore{}, fmt.Errorf("unknown probe: %s", id)
+	}
+	h := ScoreHealthWith(hb, ps.Inventory, m.health, ps.Health).withDownServices(downServices)
+	setHealth(ps, h)
+	return h, nil
+}
+
+// setHealth stores a health score and derives the probe's status from it: a
+// probe that just answered is online, or degraded when badly unhealthy.
+func setHealth(ps *ProbeState, h HealthScore) {
+	ps.Health = &h
 	if h.Status == "critical" || h.Status == "degraded" {
 		ps.Status = "degraded"
 	} else {
 		ps.Status = "online"
 	}
-	return nil
 }
 
 // UpdateInventory stores a probe inventory report.
@@ -122,6 +212,25 @@ func (m *Manager) Get(id string) (*ProbeState, bool) {
 	return ps, ok
 }
 
+// ProbeLiveness is a copy of the probe state fields each heartbeat updates.
+type ProbeLiveness struct {
+	Version  string
+	Status   string
+	LastSeen time.Time
+}
+
+// Liveness returns a probe's version, status and last-seen time, copied
+// under the lock so it can be read while heartbeats arrive.
+func (m *Manager) Liveness(id string) (ProbeLiveness, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ps, ok := m.probes[id]
+	if !ok {
+		return ProbeLiveness{}, false
+	}
+	return ProbeLiveness{Version: ps.Version, Status: ps.Status, LastSeen: ps.LastSeen}, true
+}
+
 // FindByHostname returns the best matching probe for a hostname.
 // Preference order: online/degraded probes first, then most recently seen.
 func (m *Manager) FindByHostname(hostname string) (*ProbeState, bool) {
@@ -181,18 +290,66 @@ func (m *Manager) SetAPIKey(id, apiKey string) error {
 	if !ok {
 		return fmt.Errorf("unknown probe: %s", id)
 	}
+	now := time.Now().UTC()
 	ps.APIKey = apiKey
+	ps.KeyIssuedAt = &now
+	ps.graceKey = nil
 	return nil
 }
 
-// MarkOffline checks all probes and marks stale probes as offline.
+// RotateAPIKey replaces a probe API key, keeping the previous key valid for
+// grace and recording the rotation time.
+func (m *Manager) RotateAPIKey(id, newKey string, grace time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ps, ok := m.probes[id]
+	if !ok {
+		return fmt.Errorf("unknown probe: %s", id)
+	}
+	now := time.Now().UTC()
+	ps.graceKey = &apiKeyGrace{
+		key:       ps.APIKey,
+		until:     now.Add(grace),
+		issuedAt:  ps.KeyIssuedAt,
+		rotatedAt: ps.KeyRotatedAt,
+	}
+	ps.APIKey = newKey
+	ps.KeyIssuedAt = &now
+	ps.KeyRotatedAt = &now
+	return nil
+}
+
+// RevertAPIKeyRotation restores the key and metadata replaced by the last
+// RotateAPIKey, e.g. when the new key could not be delivered to the probe.
+func (m *Manager) RevertAPIKeyRotation(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ps, ok := m.probes[id]
+	if !ok {
+		return fmt.Errorf("unknown probe: %s", id)
+	}
+	if ps.graceKey == nil {
+		return fmt.Errorf("no key rotation to revert for probe: %s", id)
+	}
+	ps.APIKey = ps.graceKey.key
+	ps.KeyIssuedAt = ps.graceKey.issuedAt
+	ps.KeyRotatedAt = ps.graceKey.rotatedAt
+	ps.graceKey = nil
+	return nil
+}
+
+// MarkOffline checks all probes and marks stale probes as offline. threshold
+// is the fleet default; probes with their own threshold use that instead.
 func (m *Manager) MarkOffline(threshold time.Duration) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	cutoff := time.Now().UTC().Add(-threshold)
+	now := time.Now().UTC()
 	for _, ps := range m.probes {
-		if ps.Status != "offline" && ps.LastSeen.Before(cutoff) {
+		limit, _ := ps.OfflineThreshold(threshold)
+		if ps.Status != "offline" && ps.LastSeen.Before(now.Add(-limit)) {
 			previousStatus := ps.Status
 			ps.Status = "offline"
 			m.logger.Warn("probe marked offline",
@@ -231,7 +388,8 @@ func (m *Manager) Count() map[string]int {
 	return counts
 }
 
-// SetTags replaces probe tags with a normalized set.
+// SetTags replaces probe tags with a normalized set. Malformed key=value
+// tags are rejected with an error wrapping ErrInvalidTag.
 func (m *Manager) SetTags(id string, tags []string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -240,7 +398,25 @@ func (m *Manager) SetTags(id string, tags []string) error {
 	if !ok {
 		return fmt.Errorf("unknown probe: %s", id)
 	}
-	ps.Tags = normalizeTags(tags)
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	ps.Tags = normalized
+	return nil
+}
+
+// SetAnnotations replaces a probe's operator annotations. Callers should pass
+// the result of NormalizeAnnotations.
+func (m *Manager) SetAnnotations(id string, annotations map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ps, ok := m.probes[id]
+	if !ok {
+		return fmt.Errorf("unknown probe: %s", id)
+	}
+	ps.Annotations = copyAnnotations(annotations)
 	return nil
 }
 
@@ -371,6 +547,33 @@ func (m *Manager) SetTenantID(id, tenantID string) error {
 	return nil
 }
 
+// SetDraining marks a probe as draining (or clears it) in memory. Draining
+// probes stay connected but receive no new command dispatch.
+func (m *Manager) SetDraining(id string, draining bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ps, ok := m.probes[id]
+	if !ok {
+		return fmt.Errorf("unknown probe: %s", id)
+	}
+	ps.Draining = draining
+	return nil
+}
+
+// SetRateLimited records that the probe is refusing commands under its rate
+// limit until the given time. It is kept in memory only.
+func (m *Manager) SetRateLimited(id string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ps, ok := m.probes[id]
+	if !ok {
+		return fmt.Errorf("unknown probe: %s", id)
+	}
+	until = until.UTC()
+	ps.RateLimitedUntil = &until
+	return nil
+}
+
 // ListByTenant returns all probes belonging to tenantID.
 // An empty tenantID returns probes with no tenant assigned.
 func (m *Manager) ListByTenant(tenantID string) []*ProbeState {
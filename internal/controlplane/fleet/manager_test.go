@@ -121,6 +121,53 @@ func TestMarkOffline_TransitionsDegradedProbe(t *testing.T) {
 	}
 }
 
+func TestMarkOffline_UsesPerProbeThreshold(t *testing.T) {
+	m := NewManager(testLogger())
+	m.Register("satellite", "sat-01", "linux", "amd64")
+	m.Register("datacenter", "dc-01", "linux", "amd64")
+	m.Register("advertised", "edge-01", "linux", "amd64")
+	if err := m.SetOfflineThreshold("satellite", 10*time.Minute); err != nil {
+		t.Fatalf("set threshold: %v", err)
+	}
+	if err := m.SetOfflineThreshold("datacenter", 30*time.Second); err != nil {
+		t.Fatalf("set threshold: %v", err)
+	}
+	if err := m.SetHeartbeatInterval("advertised", 2*time.Minute); err != nil {
+		t.Fatalf("set heartbeat interval: %v", err)
+	}
+	if err := m.SetOfflineThreshold("satellite", time.Second); err == nil {
+		t.Fatal("expected threshold below minimum to be rejected")
+	}
+
+	m.mu.Lock()
+	for _, ps := range m.probes {
+		ps.Status = "online"
+		ps.LastSeen = time.Now().UTC().Add(-2 * time.Minute)
+	}
+	m.mu.Unlock()
+
+	m.MarkOffline(5 * time.Minute)
+
+	for id, want := range map[string]string{"satellite": "online", "datacenter": "offline", "advertised": "online"} {
+		ps, _ := m.Get(id)
+		if ps.Status != want {
+			t.Errorf("%s: expected %s, got %s", id, want, ps.Status)
+		}
+	}
+
+	ps, _ := m.Get("advertised")
+	if threshold, source := ps.OfflineThreshold(5 * time.Minute); threshold != 6*time.Minute || source != OfflineThresholdHeartbeat {
+		t.Fatalf("advertised threshold = %s (%s), want 6m0s (heartbeat)", threshold, source)
+	}
+	if err := m.SetOfflineThreshold("satellite", 0); err != nil {
+		t.Fatalf("clear threshold: %v", err)
+	}
+	ps, _ = m.Get("satellite")
+	if threshold, source := ps.OfflineThreshold(5 * time.Minute); threshold != 5*time.Minute || source != OfflineThresholdDefault {
+		t.Fatalf("cleared threshold = %s (%s), want fleet default", threshold, source)
+	}
+}
+
 func TestSetOnline(t *testing.T) {
 	m := NewManager(testLogger())
 	m.Register("probe-1", "web-01", "linux", "amd64")
@@ -244,6 +291,111 @@ func TestSetAPIKey(t *testing.T) {
 	}
 }
 
+func TestSetDraining(t *testing.T) {
+	m := NewManager(testLogger())
+	m.Register("probe-1", "web-01", "linux", "amd64")
+
+	if err := m.SetDraining("probe-1", true); err != nil {
+		t.Fatalf("set draining failed: %v", err)
+	}
+	ps, _ := m.Get("probe-1")
+	if !ps.Draining {
+		t.Fatal("expected probe to be draining")
+	}
+
+	if err := m.SetDraining("probe-1", false); err != nil {
+		t.Fatalf("clear draining failed: %v", err)
+	}
+	ps, _ = m.Get("probe-1")
+	if ps.Draining {
+		t.Fatal("expected draining to be cleared")
+	}
+
+	if err := m.SetDraining("missing", true); err == nil {
+		t.Fatal("expected error for unknown probe")
+	}
+}
+
+func TestHeartbeatTracksVersion(t *testing.T) {
+	m := NewManager(testLogger())
+	m.Register("probe-1", "web-01", "linux", "amd64")
+
+	if err := m.Heartbeat("probe-1", &protocol.HeartbeatPayload{ProbeID: "probe-1", Version: "1.4.0"}); err != nil {
+		t.Fatalf("heartbeat failed: %v", err)
+	}
+	ps, _ := m.Get("probe-1")
+	if ps.Version != "1.4.0" {
+		t.Fatalf("expected version 1.4.0, got %q", ps.Version)
+	}
+
+	// Older probes omit the version; the last reported value is kept.
+	if err := m.Heartbeat("probe-1", &protocol.HeartbeatPayload{ProbeID: "probe-1"}); err != nil {
+		t.Fatalf("heartbeat failed: %v", err)
+	}
+	ps, _ = m.Get("probe-1")
+	if ps.Version != "1.4.0" {
+		t.Fatalf("expected version to be retained, got %q", ps.Version)
+	}
+}
+
+func TestRotateAPIKeyKeepsPreviousKeyForGrace(t *testing.T) {
+	m := NewManager(testLogger())
+	m.Register("probe-1", "web-01", "linux", "amd64")
+	if err := m.SetAPIKey("probe-1", "old-key"); err != nil {
+		t.Fatalf("set api key: %v", err)
+	}
+	ps, _ := m.Get("probe-1")
+	if ps.KeyIssuedAt == nil || ps.KeyRotatedAt != nil {
+		t.Fatalf("expected issue time without rotation, got issued=%v rotated=%v", ps.KeyIssuedAt, ps.KeyRotatedAt)
+	}
+	issued := *ps.KeyIssuedAt
+
+	if err := m.RotateAPIKey("probe-1", "new-key", time.Minute); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	ps, _ = m.Get("probe-1")
+	now := time.Now()
+	if ps.APIKey != "new-key" || ps.KeyRotatedAt == nil || !ps.KeyIssuedAt.After(issued) {
+		t.Fatalf("expected rotated key with fresh timestamps, got %+v", ps)
+	}
+	if !ps.AcceptsAPIKey("new-key", now) || !ps.AcceptsAPIKey("old-key", now) {
+		t.Fatal("expected both keys accepted during grace window")
+	}
+	if ps.AcceptsAPIKey("old-key", now.Add(2*time.Minute)) {
+		t.Fatal("expected previous key rejected after grace window")
+	}
+	if ps.AcceptsAPIKey("", now) || ps.AcceptsAPIKey("other", now) {
+		t.Fatal("expected unknown keys rejected")
+	}
+
+	if err := m.RevertAPIKeyRotation("probe-1"); err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	ps, _ = m.Get("probe-1")
+	if ps.APIKey != "old-key" || ps.KeyRotatedAt != nil || !ps.KeyIssuedAt.Equal(issued) {
+		t.Fatalf("expected rotation reverted, got %+v", ps)
+	}
+	if ps.AcceptsAPIKey("new-key", now) {
+		t.Fatal("expected reverted key to be rejected")
+	}
+	if err := m.RevertAPIKeyRotation("probe-1"); err == nil {
+		t.Fatal("expected error reverting without a pending rotation")
+	}
+}
+
+func TestKeyAgeFallsBackToRegistration(t *testing.T) {
+	registered := time.Now().Add(-48 * time.Hour)
+	ps := &ProbeState{Registered: registered}
+	if age := ps.KeyAge(registered.Add(48 * time.Hour)); age != 48*time.Hour {
+		t.Fatalf("expected 48h key age from registration, got %s", age)
+	}
+	issued := registered.Add(24 * time.Hour)
+	ps.KeyIssuedAt = &issued
+	if age := ps.KeyAge(registered.Add(48 * time.Hour)); age != 24*time.Hour {
+		t.Fatalf("expected 24h key age from issue time, got %s", age)
+	}
+}
+
 func TestRegisterRemoteProbe(t *testing.T) {
 	m := NewManager(testLogger())
 	ps, err := m.RegisterRemote(RemoteProbeRegistration{
@@ -342,3 +494,51 @@ func TestInventoryAggregatesAndFilters(t *testing.T) {
 		t.Fatalf("expected no probes for prod+offline, got %#v", none)
 	}
 }
+
+func TestHeartbeatTracksInFlightCommands(t *testing.T) {
+	m := NewManager(testLogger())
+	m.Register("probe-1", "web-01", "linux", "amd64")
+
+	if err := m.Heartbeat("probe-1", &protocol.HeartbeatPayload{ProbeID: "probe-1", InFlightCommands: 3}); err != nil {
+		t.Fatalf("heartbeat failed: %v", err)
+	}
+	if ps, _ := m.Get("probe-1"); ps.InFlightCommands != 3 {
+		t.Fatalf("expected 3 in-flight commands, got %d", ps.InFlightCommands)
+	}
+
+	if err := m.Heartbeat("probe-1", &protocol.HeartbeatPayload{ProbeID: "probe-1"}); err != nil {
+		t.Fatalf("heartbeat failed: %v", err)
+	}
+	if ps, _ := m.Get("probe-1"); ps.InFlightCommands != 0 {
+		t.Fatalf("expected in-flight count cleared, got %d", ps.InFlightCommands)
+	}
+}
+
+func TestHeartbeatHealthHysteresis(t *testing.T) {
+	m := NewManager(testLogger())
+	m.SetHealthThresholds(HealthThresholds{MemHighPct: 70, MemCritPct: 80})
+	m.Register("probe-1", "web-01", "linux", "amd64")
+
+	beat := func(memPct uint64) *ProbeState {
+		t.Helper()
+		hb := &protocol.HeartbeatPayload{ProbeID: "probe-1", MemUsed: memPct, MemTotal: 100, Load: [3]float64{9, 9, 9}}
+		if err := m.Heartbeat("probe-1", hb); err != nil {
+			t.Fatalf("heartbeat failed: %v", err)
+		}
+		ps, _ := m.Get("probe-1")
+		return ps
+	}
+
+	// Critical load (-30) plus critical memory (-30) is degraded.
+	if ps := beat(81); ps.Status != "degraded" {
+		t.Fatalf("expected degraded, got %s (%+v)", ps.Status, ps.Health)
+	}
+	// 79% is just under the critical line; hysteresis keeps it degraded
+	// rather than flapping back to online.
+	if ps := beat(79); ps.Status != "degraded" || ps.Health.Pressure["memory"] != "critical" {
+		t.Fatalf("expected hysteresis to hold degraded, got %s (%+v)", ps.Status, ps.Health)
+	}
+	if ps := beat(60); ps.Status != "online" {
+		t.Fatalf("expected online once memory recovers, got %s (%+v)", ps.Status, ps.Health)
+	}
+}
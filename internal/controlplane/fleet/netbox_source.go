@@ -0,0 +1,284 @@
+package fleet
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultNetboxSyncInterval = 5 * time.Minute
+	defaultNetboxTimeout      = 15 * time.Second
+	netboxPageSize            = 200
+	maxNetboxPages            = 500
+)
+
+// NetboxSourceConfig configures a NetBox-backed federation inventory source.
+type NetboxSourceConfig struct {
+	BaseURL       string
+	APIToken      string
+	Interval      time.Duration
+	Timeout       time.Duration
+	TLSSkipVerify bool
+	Source        FederationSourceDescriptor
+	HTTPClient    *http.Client
+	Logger        *zap.Logger
+	// StaleAfter overrides the staleness threshold; 0 means twice Interval.
+	StaleAfter time.Duration
+}
+
+// NetboxSourceAdapter syncs DCIM devices and virtualization VMs from NetBox on
+// an interval and serves the last good snapshot as a federation source.
+type NetboxSourceAdapter struct {
+	*syncedSource
+	baseURL  string
+	apiToken string
+	client   *http.Client
+}
+
+// NewNetboxSourceAdapter builds a NetBox source. The source descriptor defaults
+// to id "netbox", kind "netbox".
+func NewNetboxSourceAdapter(cfg NetboxSourceConfig) *NetboxSourceAdapter {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultNetboxSyncInterval
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultNetboxTimeout
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.TLSSkipVerify {
+			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // explicit opt-in for self-hosted labs
+		}
+		client = &http.Client{Timeout: timeout, Transport: transport}
+	}
+
+	source := cfg.Source
+	if strings.TrimSpace(source.ID) == "" {
+		source.ID = "netbox"
+	}
+	if strings.TrimSpace(source.Name) == "" {
+		source.Name = "NetBox"
+	}
+	if strings.TrimSpace(source.Kind) == "" {
+		source.Kind = "netbox"
+	}
+
+	a := &NetboxSourceAdapter{
+		syncedSource: newSyncedSource(source, interval, cfg.Logger),
+		baseURL:      strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/"),
+		apiToken:     strings.TrimSpace(cfg.APIToken),
+		client:       client,
+	}
+	a.collect = a.collectInventory
+	a.staleAfter = cfg.StaleAfter
+	return a
+}
+
+// Run syncs immediately and then on every interval until ctx is done.
+func (a *NetboxSourceAdapter) Run(ctx context.Context) {
+	if a == nil || a.baseURL == "" {
+		return
+	}
+	a.syncedSource.Run(ctx)
+}
+
+func (a *NetboxSourceAdapter) collectInventory(ctx context.Context, collectedAt time.Time) ([]ProbeInventorySummary, error) {
+	devices, err := a.fetchAll(ctx, "/api/dcim/devices/")
+	if err != nil {
+		return nil, err
+	}
+	vms, err := a.fetchAll(ctx, "/api/virtualization/virtual-machines/")
+	if err != nil {
+		return nil, err
+	}
+
+	probes := make([]ProbeInventorySummary, 0, len(devices)+len(vms))
+	for _, device := range devices {
+		probes = append(probes, netboxProbeSummary("device", device, collectedAt))
+	}
+	for _, vm := range vms {
+		probes = append(probes, netboxProbeSummary("vm", vm, collectedAt))
+	}
+	sort.Slice(probes, func(i, j int) bool { return probes[i].ID < probes[j].ID })
+	return probes, nil
+}
+
+// netboxObject is the subset of NetBox device/VM fields Legator maps.
+type netboxObject struct {
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Status      netboxChoice   `json:"status"`
+	Site        *netboxNested  `json:"site"`
+	Role        *netboxNested  `json:"role"`
+	DeviceRole  *netboxNested  `json:"device_role"`
+	Platform    *netboxNested  `json:"platform"`
+	PrimaryIP   *netboxIP      `json:"primary_ip"`
+	Tags        []netboxNested `json:"tags"`
+	VCPUs       float64        `json:"vcpus"`
+	MemoryMB    uint64         `json:"memory"`
+	DiskGB      uint64         `json:"disk"`
+	LastUpdated time.Time      `json:"last_updated"`
+}
+
+type netboxChoice struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+type netboxNested struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type netboxIP struct {
+	Address string `json:"address"`
+}
+
+type netboxPage struct {
+	Next    string         `json:"next"`
+	Results []netboxObject `json:"results"`
+}
+
+func (a *NetboxSourceAdapter) fetchAll(ctx context.Context, path string) ([]netboxObject, error) {
+	next := fmt.Sprintf("%s%s?limit=%d", a.baseURL, path, netboxPageSize)
+	var out []netboxObject
+	for page := 0; next != ""; page++ {
+		if page >= maxNetboxPages {
+			return nil, fmt.Errorf("netbox %s: more than %d pages", path, maxNetboxPages)
+		}
+		var body netboxPage
+		if err := a.getJSON(ctx, next, &body); err != nil {
+			return nil, fmt.Errorf("netbox %s: %w", path, err)
+		}
+		out = append(out, body.Results...)
+		next = a.sameOrigin(body.Next)
+	}
+	return out, nil
+}
+
+// sameOrigin keeps pagination on the configured base URL. NetBox builds
+// "next" from its own view of the host, which is wrong behind a proxy.
+func (a *NetboxSourceAdapter) sameOrigin(next string) string {
+	if strings.TrimSpace(next) == "" {
+		return ""
+	}
+	parsed, err := url.Parse(next)
+	if err != nil {
+		return ""
+	}
+	base, err := url.Parse(a.baseURL)
+	if err != nil {
+		return next
+	}
+	parsed.Scheme = base.Scheme
+	parsed.Host = base.Host
+	return parsed.String()
+}
+
+func (a *NetboxSourceAdapter) getJSON(ctx context.Context, rawURL string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if a.apiToken != "" {
+		req.Header.Set("Authorization", "Token "+a.apiToken)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
+
+func netboxProbeSummary(kind string, obj netboxObject, collectedAt time.Time) ProbeInventorySummary {
+	summary := ProbeInventorySummary{
+		ID:          fmt.Sprintf("netbox-%s-%d", kind, obj.ID),
+		Hostname:    strings.TrimSpace(obj.Name),
+		Status:      netboxStatus(obj.Status.Value),
+		LastSeen:    obj.LastUpdated.UTC(),
+		CollectedAt: collectedAt,
+		CPUs:        int(obj.VCPUs),
+		RAMBytes:    obj.MemoryMB * 1024 * 1024,
+		DiskBytes:   obj.DiskGB * 1024 * 1024 * 1024,
+	}
+	if summary.Hostname == "" {
+		summary.Hostname = summary.ID
+	}
+	if obj.Platform != nil {
+		summary.OS = obj.Platform.Name
+	}
+	if obj.Site != nil {
+		summary.Site = firstNonEmpty(obj.Site.Slug, obj.Site.Name)
+	}
+	// NetBox 3.6 renamed device_role to role; accept either.
+	if role := firstNestedSlug(obj.Role, obj.DeviceRole); role != "" {
+		summary.Role = role
+	}
+	if obj.PrimaryIP != nil {
+		summary.PrimaryIP = stripPrefixLength(obj.PrimaryIP.Address)
+	}
+	for _, tag := range obj.Tags {
+		if name := strings.ToLower(firstNonEmpty(tag.Slug, tag.Name)); name != "" {
+			summary.Tags = append(summary.Tags, name)
+		}
+	}
+	return summary
+}
+
+// netboxStatus maps NetBox lifecycle states onto fleet status values so
+// aggregates count active hosts as online.
+func netboxStatus(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "active":
+		return "online"
+	case "offline", "failed", "decommissioning":
+		return "offline"
+	case "":
+		return "unknown"
+	default:
+		return strings.ToLower(strings.TrimSpace(value))
+	}
+}
+
+func firstNestedSlug(values ...*netboxNested) string {
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if s := firstNonEmpty(v.Slug, v.Name); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func stripPrefixLength(address string) string {
+	address = strings.TrimSpace(address)
+	if idx := strings.IndexByte(address, '/'); idx >= 0 {
+		return address[:idx]
+	}
+	return address
+}
@@ -0,0 +1,178 @@
+package fleet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newNetboxTestServer(t *testing.T, failing *atomic.Bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token nb-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if failing != nil && failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		switch {
+		case r.URL.Path == "/api/dcim/devices/" && r.URL.Query().Get("offset") == "":
+			// NetBox renders next from its own host; the adapter must rewrite it.
+			fmt.Fprint(w, `{"next":"http://netbox.internal/api/dcim/devices/?limit=200&offset=1","results":[
+				{"id":7,"name":"core-sw-01","status":{"value":"active"},"site":{"name":"DC East","slug":"dc-east"},
+				 "device_role":{"name":"Switch","slug":"switch"},"platform":{"name":"junos"},
+				 "primary_ip":{"address":"10.1.0.2/24"},"tags":[{"name":"Core","slug":"core"}]}]}`)
+		case r.URL.Path == "/api/dcim/devices/":
+			fmt.Fprint(w, `{"next":null,"results":[{"id":8,"name":"","status":{"value":"offline"}}]}`)
+		case r.URL.Path == "/api/virtualization/virtual-machines/":
+			fmt.Fprint(w, `{"next":null,"results":[
+				{"id":3,"name":"app-vm","status":{"value":"active"},"role":{"name":"App","slug":"app"},
+				 "site":{"name":"DC West","slug":"dc-west"},"vcpus":4,"memory":8192,"disk":100}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNetboxSourceAdapter_SyncMapsDevicesAndVMs(t *testing.T) {
+	srv := newNetboxTestServer(t, nil)
+	adapter := NewNetboxSourceAdapter(NetboxSourceConfig{BaseURL: srv.URL + "/", APIToken: "nb-token"})
+
+	if _, err := adapter.Inventory(context.Background(), InventoryFilter{}); err == nil {
+		t.Fatal("expected inventory error before first sync")
+	}
+	if err := adapter.Sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	result, err := adapter.Inventory(context.Background(), InventoryFilter{})
+	if err != nil {
+		t.Fatalf("inventory: %v", err)
+	}
+	probes := map[string]ProbeInventorySummary{}
+	for _, probe := range result.Inventory.Probes {
+		probes[probe.ID] = probe
+	}
+	if len(probes) != 3 {
+		t.Fatalf("expected 2 devices + 1 vm across pages, got %+v", result.Inventory.Probes)
+	}
+
+	sw := probes["netbox-device-7"]
+	if sw.Hostname != "core-sw-01" || sw.Status != "online" || sw.OS != "junos" {
+		t.Fatalf("unexpected device mapping: %+v", sw)
+	}
+	if sw.Site != "dc-east" || sw.Role != "switch" || sw.PrimaryIP != "10.1.0.2" {
+		t.Fatalf("unexpected device attributes: %+v", sw)
+	}
+	if len(sw.Tags) != 1 || sw.Tags[0] != "core" {
+		t.Fatalf("unexpected device tags: %v", sw.Tags)
+	}
+	if unnamed := probes["netbox-device-8"]; unnamed.Hostname != "netbox-device-8" || unnamed.Status != "offline" {
+		t.Fatalf("unexpected unnamed device mapping: %+v", unnamed)
+	}
+
+	vm := probes["netbox-vm-3"]
+	if vm.Role != "app" || vm.CPUs != 4 || vm.RAMBytes != 8192*1024*1024 || vm.DiskBytes != 100*1024*1024*1024 {
+		t.Fatalf("unexpected vm mapping: %+v", vm)
+	}
+	if result.Inventory.Aggregates.Online != 2 {
+		t.Fatalf("expected 2 online, got %+v", result.Inventory.Aggregates)
+	}
+}
+
+func TestNetboxSourceAdapter_FailedSyncKeepsSnapshotUntilStale(t *testing.T) {
+	var failing atomic.Bool
+	srv := newNetboxTestServer(t, &failing)
+	adapter := NewNetboxSourceAdapter(NetboxSourceConfig{BaseURL: srv.URL, APIToken: "nb-token", Interval: time.Minute})
+
+	syncedAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	adapter.now = func() time.Time { return syncedAt }
+	if err := adapter.Sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	failing.Store(true)
+	if err := adapter.Sync(context.Background()); err == nil {
+		t.Fatal("expected sync error from failing upstream")
+	}
+
+	store := NewFederationStore(adapter)
+	store.staleAfter = time.Hour // adapter's own 2x interval must win
+
+	store.now = func() time.Time { return syncedAt.Add(90 * time.Second) }
+	inv := store.Inventory(context.Background(), FederationFilter{})
+	if len(inv.Sources) != 1 || inv.Sources[0].Source.ID != "netbox" {
+		t.Fatalf("expected netbox source summary, got %+v", inv.Sources)
+	}
+	if got := inv.Sources[0].Consistency.Freshness; got != FederationFreshnessFresh {
+		t.Fatalf("expected fresh snapshot within 2x interval, got %s", got)
+	}
+	if len(inv.Probes) != 3 {
+		t.Fatalf("expected cached probes to be served, got %d", len(inv.Probes))
+	}
+	if !strings.Contains(strings.Join(inv.Sources[0].Warnings, " | "), "last sync failed") {
+		t.Fatalf("expected last sync warning, got %v", inv.Sources[0].Warnings)
+	}
+
+	store.now = func() time.Time { return syncedAt.Add(3 * time.Minute) }
+	inv = store.Inventory(context.Background(), FederationFilter{})
+	if got := inv.Sources[0].Consistency.Freshness; got != FederationFreshnessStale {
+		t.Fatalf("expected stale snapshot past 2x interval, got %s", got)
+	}
+
+	inv = store.Inventory(context.Background(), FederationFilter{Search: "dc-east"})
+	if len(inv.Probes) != 1 || inv.Probes[0].Probe.ID != "netbox-device-7" {
+		t.Fatalf("expected site search to match one device, got %+v", inv.Probes)
+	}
+}
+
+func TestFederationStoreSourceFreshness_UsesStaleAfterOverride(t *testing.T) {
+	var failing atomic.Bool
+	srv := newNetboxTestServer(t, &failing)
+	adapter := NewNetboxSourceAdapter(NetboxSourceConfig{BaseURL: srv.URL, APIToken: "nb-token", Interval: time.Minute, StaleAfter: 10 * time.Minute})
+
+	syncedAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	adapter.now = func() time.Time { return syncedAt }
+	if err := adapter.Sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	failing.Store(true)
+	adapter.now = func() time.Time { return syncedAt.Add(time.Minute) }
+	_ = adapter.Sync(context.Background())
+
+	store := NewFederationStore(NewFleetSourceAdapter(NewManager(nil), FederationSourceDescriptor{ID: "local"}), adapter)
+	store.now = func() time.Time { return syncedAt.Add(5 * time.Minute) }
+
+	freshness := store.SourceFreshness()
+	if len(freshness) != 1 || freshness[0].Source.ID != "netbox" {
+		t.Fatalf("expected only the synced netbox source, got %+v", freshness)
+	}
+	got := freshness[0]
+	if got.Stale || got.StaleAfterSeconds != 600 || got.AgeSeconds != 300 {
+		t.Fatalf("expected fresh source within override, got %+v", got)
+	}
+	if !got.Sync.LastSuccessAt.Equal(syncedAt) || !got.Sync.LastAttemptAt.Equal(syncedAt.Add(time.Minute)) || got.Sync.LastError == "" {
+		t.Fatalf("unexpected sync status: %+v", got.Sync)
+	}
+
+	store.now = func() time.Time { return syncedAt.Add(11 * time.Minute) }
+	if got := store.SourceFreshness()[0]; !got.Stale {
+		t.Fatalf("expected stale source past override, got %+v", got)
+	}
+	inv := store.Inventory(context.Background(), FederationFilter{Source: "netbox"})
+	if len(inv.Sources) != 1 || inv.Sources[0].Source.Status != FederationSourceDegraded || inv.Sources[0].Consistency.StaleAfterSeconds != 600 {
+		t.Fatalf("expected degraded netbox summary with threshold, got %+v", inv.Sources)
+	}
+	if inv.Sources[0].Sync == nil || !inv.Sources[0].Sync.LastSuccessAt.Equal(syncedAt) {
+		t.Fatalf("expected sync status on summary, got %+v", inv.Sources[0].Sync)
+	}
+}
@@ -0,0 +1,87 @@
+package fleet
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bounds for per-probe offline thresholds. Anything below a few heartbeats
+// flaps on ordinary jitter; anything above a day hides real outages.
+const (
+	MinOfflineThreshold = 10 * time.Second
+	MaxOfflineThreshold = 24 * time.Hour
+
+	// missedHeartbeatsBeforeOffline derives a threshold from an advertised
+	// heartbeat interval when the probe has no explicit threshold.
+	missedHeartbeatsBeforeOffline = 3
+)
+
+// Sources of a probe's effective offline threshold.
+const (
+	OfflineThresholdProbe     = "probe"
+	OfflineThresholdHeartbeat = "heartbeat"
+	OfflineThresholdDefault   = "default"
+)
+
+// ValidateOfflineThreshold checks a per-probe threshold. Zero clears it.
+func ValidateOfflineThreshold(d time.Duration) error {
+	if d == 0 {
+		return nil
+	}
+	if d < MinOfflineThreshold || d > MaxOfflineThreshold {
+		return fmt.Errorf("offline threshold must be between %s and %s", MinOfflineThreshold, MaxOfflineThreshold)
+	}
+	return nil
+}
+
+// OfflineThreshold returns how long the probe may go unseen before it is
+// marked offline, and where that value came from: the probe's own threshold,
+// three missed heartbeats at its advertised interval, or the fleet default.
+func (ps *ProbeState) OfflineThreshold(fleetDefault time.Duration) (time.Duration, string) {
+	if ps.OfflineThresholdSec > 0 {
+		return time.Duration(ps.OfflineThresholdSec) * time.Second, OfflineThresholdProbe
+	}
+	if ps.HeartbeatIntervalSec > 0 {
+		d := time.Duration(missedHeartbeatsBeforeOffline*ps.HeartbeatIntervalSec) * time.Second
+		if d < MinOfflineThreshold {
+			d = MinOfflineThreshold
+		}
+		if d > MaxOfflineThreshold {
+			d = MaxOfflineThreshold
+		}
+		return d, OfflineThresholdHeartbeat
+	}
+	return fleetDefault, OfflineThresholdDefault
+}
+
+// SetOfflineThreshold sets the probe's offline threshold. Zero clears it so
+// the derived or fleet default threshold applies again.
+func (m *Manager) SetOfflineThreshold(id string, threshold time.Duration) error {
+	if err := ValidateOfflineThreshold(threshold); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ps, ok := m.probes[id]
+	if !ok {
+		return fmt.Errorf("unknown probe: %s", id)
+	}
+	ps.OfflineThresholdSec = int(threshold / time.Second)
+	return nil
+}
+
+// SetHeartbeatInterval records the heartbeat interval a probe advertised.
+// Zero clears it.
+func (m *Manager) SetHeartbeatInterval(id string, interval time.Duration) error {
+	if interval < 0 {
+		return fmt.Errorf("heartbeat interval must not be negative")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ps, ok := m.probes[id]
+	if !ok {
+		return fmt.Errorf("unknown probe: %s", id)
+	}
+	ps.HeartbeatIntervalSec = int(interval / time.Second)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -23,6 +24,13 @@ const (
 	defaultRemoteOutputMaxBytes   = 128 * 1024
 )
 
+// Remote execution timeouts. A hung SSH handshake and a hung command are
+// reported separately so callers can tell an unreachable host from a slow one.
+var (
+	ErrRemoteConnectTimeout = errors.New("ssh dial timeout")
+	ErrRemoteCommandTimeout = errors.New("remote command timeout")
+)
+
 // RemoteExecutionTarget contains SSH connection details for a remote probe.
 type RemoteExecutionTarget struct {
 	Host       string
@@ -30,6 +38,14 @@ type RemoteExecutionTarget struct {
 	Username   string
 	Password   string
 	PrivateKey string
+	// HostKeyFingerprint is the pinned SHA256 host key fingerprint, if any.
+	HostKeyFingerprint string
+	// HostKeyPolicy is HostKeyPolicyStrict or HostKeyPolicyTOFU.
+	HostKeyPolicy string
+
+	// trustHostKey is called with the observed fingerprint when a TOFU
+	// target without a pin is first contacted.
+	trustHostKey func(fingerprint string)
 }
 
 // RemoteRunResult is the normalized command execution result from a runner.
@@ -58,6 +74,15 @@ type RemoteExecutor struct {
 	inventoryTimeout time.Duration
 	maxOutputBytes   int
 	now              func() time.Time
+
+	onHostKeyTrusted func(probeID, fingerprint string)
+}
+
+// RemoteTimeouts are the effective timeouts a RemoteExecutor applies.
+type RemoteTimeouts struct {
+	Connect   time.Duration `json:"connect"`
+	Command   time.Duration `json:"command"`
+	Inventory time.Duration `json:"inventory"`
 }
 
 func NewRemoteExecutor() *RemoteExecutor {
@@ -70,6 +95,44 @@ func NewRemoteExecutor() *RemoteExecutor {
 	}
 }
 
+// SetTimeouts overrides the SSH connect timeout and the default command
+// timeout. Zero values leave the current setting unchanged.
+func (e *RemoteExecutor) SetTimeouts(connect, command time.Duration) {
+	if command > 0 {
+		e.defaultTimeout = command
+	}
+	if r, ok := e.runner.(*sshRemoteCommandRunner); ok && connect > 0 {
+		r.dialTimeout = connect
+	}
+}
+
+// SetHostKeyTrustHandler registers fn to be called when a remote probe
+// under the TOFU policy presents its first host key. fn should pin the
+// fingerprint (see Fleet.PinRemoteHostKey) so later keys are verified.
+func (e *RemoteExecutor) SetHostKeyTrustHandler(fn func(probeID, fingerprint string)) {
+	e.onHostKeyTrusted = fn
+}
+
+func (e *RemoteExecutor) hostKeyTrustFor(probeID string) func(string) {
+	if e.onHostKeyTrusted == nil {
+		return nil
+	}
+	return func(fingerprint string) { e.onHostKeyTrusted(probeID, fingerprint) }
+}
+
+// Timeouts returns the effective connect, command and inventory timeouts.
+func (e *RemoteExecutor) Timeouts() RemoteTimeouts {
+	out := RemoteTimeouts{
+		Connect:   defaultRemoteDialTimeout,
+		Command:   e.defaultTimeout,
+		Inventory: e.inventoryTimeout,
+	}
+	if r, ok := e.runner.(*sshRemoteCommandRunner); ok && r.dialTimeout > 0 {
+		out.Connect = r.dialTimeout
+	}
+	return out
+}
+
 // Execute runs a command on a remote probe and optionally emits output chunks.
 func (e *RemoteExecutor) Execute(ctx context.Context, ps *ProbeState, cmd protocol.CommandPayload, onChunk func(protocol.OutputChunkPayload)) (*protocol.CommandResultPayload, error) {
 	if e == nil {
@@ -83,6 +146,7 @@ func (e *RemoteExecutor) Execute(ctx context.Context, ps *ProbeState, cmd protoc
 	if err != nil {
 		return nil, err
 	}
+	target.trustHostKey = e.hostKeyTrustFor(ps.ID)
 
 	command := buildRemoteCommand(cmd)
 	if command == "" {
@@ -154,6 +218,7 @@ func (e *RemoteExecutor) CollectInventory(ctx context.Context, ps *ProbeState) (
 	if err != nil {
 		return nil, err
 	}
+	target.trustHostKey = e.hostKeyTrustFor(ps.ID)
 
 	timeout := e.inventoryTimeout
 	if timeout <= 0 {
@@ -286,6 +351,9 @@ func remoteTargetFromProbe(ps *ProbeState) (RemoteExecutionTarget, error) {
 		Username:   strings.TrimSpace(ps.Remote.Username),
 		Password:   strings.TrimSpace(ps.RemoteCredentials.Password),
 		PrivateKey: strings.TrimSpace(ps.RemoteCredentials.PrivateKey),
+
+		HostKeyFingerprint: strings.TrimSpace(ps.Remote.HostKeyFingerprint),
+		HostKeyPolicy:      ps.Remote.HostKeyPolicy,
 	}
 	if target.Host == "" || target.Username == "" {
 		return RemoteExecutionTarget{}, fmt.Errorf("remote probe %s has incomplete host/username config", ps.ID)
@@ -492,7 +560,7 @@ func (r *sshRemoteCommandRunner) Run(ctx context.Context, target RemoteExecution
 	case <-timer.C:
 		_ = session.Close()
 		wg.Wait()
-		return nil, fmt.Errorf("remote command timeout")
+		return nil, fmt.Errorf("%w after %s", ErrRemoteCommandTimeout, timeout)
 	case waitErr = <-waitCh:
 	}
 
@@ -558,7 +626,7 @@ func remoteSSHClientConfig(target RemoteExecutionTarget, timeout time.Duration)
 	return &ssh.ClientConfig{
 		User:            target.Username,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // agentless probe bootstrap
+		HostKeyCallback: remoteHostKeyCallback(target),
 		Timeout:         timeout,
 	}, nil
 }
@@ -585,7 +653,7 @@ func sshDialContext(ctx context.Context, network, addr string, config *ssh.Clien
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-timer.C:
-		return nil, fmt.Errorf("ssh dial timeout")
+		return nil, fmt.Errorf("%w after %s", ErrRemoteConnectTimeout, timeout)
 	case res := <-ch:
 		return res.client, res.err
 	}
@@ -2,7 +2,9 @@ package fleet
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"strings"
 	"testing"
 	"time"
@@ -189,3 +191,52 @@ func TestRemoteTargetFromProbeValidation(t *testing.T) {
 		t.Fatalf("unexpected port: %d", target.Port)
 	}
 }
+
+func TestSSHDialContextConnectTimeout(t *testing.T) {
+	// Accept TCP connections but never speak SSH, like a wedged sshd.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	cfg, err := remoteSSHClientConfig(RemoteExecutionTarget{Username: "root", Password: "pw"}, time.Second)
+	if err != nil {
+		t.Fatalf("client config: %v", err)
+	}
+	_, err = sshDialContext(context.Background(), "tcp", ln.Addr().String(), cfg, 100*time.Millisecond)
+	if !errors.Is(err, ErrRemoteConnectTimeout) {
+		t.Fatalf("expected connect timeout, got %v", err)
+	}
+	if errors.Is(err, ErrRemoteCommandTimeout) {
+		t.Fatal("connect timeout must not look like a command timeout")
+	}
+}
+
+func TestRemoteExecutorTimeouts(t *testing.T) {
+	executor := NewRemoteExecutor()
+	got := executor.Timeouts()
+	if got.Connect != defaultRemoteDialTimeout || got.Command != defaultRemoteCommandTimeout || got.Inventory != defaultRemoteInventoryTimeout {
+		t.Fatalf("unexpected defaults: %+v", got)
+	}
+
+	executor.SetTimeouts(3*time.Second, 0)
+	got = executor.Timeouts()
+	if got.Connect != 3*time.Second || got.Command != defaultRemoteCommandTimeout {
+		t.Fatalf("expected only connect timeout to change, got %+v", got)
+	}
+
+	executor.SetTimeouts(0, 2*time.Minute)
+	if got := executor.Timeouts(); got.Connect != 3*time.Second || got.Command != 2*time.Minute {
+		t.Fatalf("expected command timeout to change, got %+v", got)
+	}
+}
@@ -0,0 +1,109 @@
+package fleet
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Host key policies for remote probes.
+const (
+	// HostKeyPolicyStrict only connects when the server key matches the
+	// configured fingerprint.
+	HostKeyPolicyStrict = "strict"
+	// HostKeyPolicyTOFU trusts the first key seen, pins it, and rejects
+	// any different key afterwards.
+	HostKeyPolicyTOFU = "tofu"
+)
+
+var (
+	// ErrHostKeyMismatch is returned when a remote host presents a key that
+	// does not match its pinned fingerprint.
+	ErrHostKeyMismatch = errors.New("ssh host key mismatch")
+	// ErrHostKeyUnknown is returned under the strict policy when no
+	// fingerprint is configured for the host.
+	ErrHostKeyUnknown = errors.New("ssh host key unknown")
+)
+
+// HostKeyError describes a rejected remote host key.
+type HostKeyError struct {
+	Host     string
+	Expected string
+	Actual   string
+	Err      error
+}
+
+func (e *HostKeyError) Error() string {
+	if errors.Is(e.Err, ErrHostKeyUnknown) {
+		return fmt.Sprintf("%s: %s presented %s but no fingerprint is pinned and the policy is strict", e.Err, e.Host, e.Actual)
+	}
+	return fmt.Sprintf("%s: %s presented %s, expected %s", e.Err, e.Host, e.Actual, e.Expected)
+}
+
+func (e *HostKeyError) Unwrap() error { return e.Err }
+
+// NormalizeHostKeyFingerprint validates a SHA256 host key fingerprint as
+// printed by ssh-keygen -lf ("SHA256:<base64>") and returns it in that form.
+// The "SHA256:" prefix is optional on input.
+func NormalizeHostKeyFingerprint(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	encoded := strings.TrimPrefix(raw, "SHA256:")
+	sum, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil || len(sum) != 32 {
+		return "", fmt.Errorf("host key fingerprint must be a SHA256 fingerprint like SHA256:<base64>")
+	}
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum), nil
+}
+
+// ResolveHostKeyPolicy returns the effective policy for a remote probe. An
+// empty policy defaults to strict when a fingerprint is given and TOFU when
+// it is not; strict without a fingerprint is rejected.
+func ResolveHostKeyPolicy(policy, fingerprint string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(policy)) {
+	case "":
+		if fingerprint != "" {
+			return HostKeyPolicyStrict, nil
+		}
+		return HostKeyPolicyTOFU, nil
+	case HostKeyPolicyStrict:
+		if fingerprint == "" {
+			return "", fmt.Errorf("host_key_policy strict requires host_key_fingerprint")
+		}
+		return HostKeyPolicyStrict, nil
+	case HostKeyPolicyTOFU:
+		return HostKeyPolicyTOFU, nil
+	default:
+		return "", fmt.Errorf("host_key_policy must be %q or %q", HostKeyPolicyStrict, HostKeyPolicyTOFU)
+	}
+}
+
+// remoteHostKeyCallback verifies the server key against target's pinned
+// fingerprint. With no pin under TOFU the key is accepted and reported to
+// target.trustHostKey so the caller can pin it.
+func remoteHostKeyCallback(target RemoteExecutionTarget) ssh.HostKeyCallback {
+	return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+		actual := ssh.FingerprintSHA256(key)
+		expected := target.HostKeyFingerprint
+		switch {
+		case expected != "":
+			if actual != expected {
+				return &HostKeyError{Host: hostname, Expected: expected, Actual: actual, Err: ErrHostKeyMismatch}
+			}
+			return nil
+		case target.HostKeyPolicy == HostKeyPolicyStrict:
+			return &HostKeyError{Host: hostname, Actual: actual, Err: ErrHostKeyUnknown}
+		default:
+			if target.trustHostKey != nil {
+				target.trustHostKey(actual)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package fleet
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestHostKey(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("signer: %v", err)
+	}
+	return signer
+}
+
+// startTestSSHServer accepts password "pw" and presents hostKey; it only
+// needs to complete the handshake.
+func startTestSSHServer(t *testing.T, hostKey ssh.Signer) string {
+	t.Helper()
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(ssh.ConnMetadata, []byte) (*ssh.Permissions, error) { return nil, nil },
+	}
+	cfg.AddHostKey(hostKey)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				sconn, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+				if err != nil {
+					_ = conn.Close()
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					_ = ch.Reject(ssh.Prohibited, "test server")
+				}
+				_ = sconn.Close()
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func dialTestTarget(t *testing.T, addr string, target RemoteExecutionTarget) error {
+	t.Helper()
+	target.Username, target.Password = "root", "pw"
+	cfg, err := remoteSSHClientConfig(target, 2*time.Second)
+	if err != nil {
+		t.Fatalf("client config: %v", err)
+	}
+	client, err := sshDialContext(t.Context(), "tcp", addr, cfg, 2*time.Second)
+	if client != nil {
+		_ = client.Close()
+	}
+	return err
+}
+
+func TestRemoteHostKeyVerification(t *testing.T) {
+	hostKey := newTestHostKey(t)
+	other := newTestHostKey(t)
+	addr := startTestSSHServer(t, hostKey)
+	fingerprint := ssh.FingerprintSHA256(hostKey.PublicKey())
+
+	t.Run("strict match", func(t *testing.T) {
+		err := dialTestTarget(t, addr, RemoteExecutionTarget{HostKeyFingerprint: fingerprint, HostKeyPolicy: HostKeyPolicyStrict})
+		if err != nil {
+			t.Fatalf("expected connection, got %v", err)
+		}
+	})
+
+	t.Run("strict mismatch", func(t *testing.T) {
+		err := dialTestTarget(t, addr, RemoteExecutionTarget{HostKeyFingerprint: ssh.FingerprintSHA256(other.PublicKey()), HostKeyPolicy: HostKeyPolicyStrict})
+		if !errors.Is(err, ErrHostKeyMismatch) {
+			t.Fatalf("expected host key mismatch, got %v", err)
+		}
+		var hkErr *HostKeyError
+		if !errors.As(err, &hkErr) || hkErr.Actual != fingerprint {
+			t.Fatalf("expected HostKeyError with actual fingerprint, got %v", err)
+		}
+	})
+
+	t.Run("strict without pin", func(t *testing.T) {
+		err := dialTestTarget(t, addr, RemoteExecutionTarget{HostKeyPolicy: HostKeyPolicyStrict})
+		if !errors.Is(err, ErrHostKeyUnknown) {
+			t.Fatalf("expected unknown host key, got %v", err)
+		}
+	})
+
+	t.Run("tofu trusts first key", func(t *testing.T) {
+		var trusted string
+		err := dialTestTarget(t, addr, RemoteExecutionTarget{
+			HostKeyPolicy: HostKeyPolicyTOFU,
+			trustHostKey:  func(fp string) { trusted = fp },
+		})
+		if err != nil {
+			t.Fatalf("expected connection, got %v", err)
+		}
+		if trusted != fingerprint {
+			t.Fatalf("expected trust callback with %s, got %q", fingerprint, trusted)
+		}
+	})
+
+	t.Run("tofu pinned mismatch", func(t *testing.T) {
+		err := dialTestTarget(t, addr, RemoteExecutionTarget{HostKeyFingerprint: ssh.FingerprintSHA256(other.PublicKey()), HostKeyPolicy: HostKeyPolicyTOFU})
+		if !errors.Is(err, ErrHostKeyMismatch) {
+			t.Fatalf("expected host key mismatch, got %v", err)
+		}
+	})
+}
+
+func TestResolveHostKeyPolicy(t *testing.T) {
+	cases := []struct {
+		policy, fingerprint, want string
+		wantErr                   bool
+	}{
+		{"", "SHA256:abc", HostKeyPolicyStrict, false},
+		{"", "", HostKeyPolicyTOFU, false},
+		{"TOFU", "SHA256:abc", HostKeyPolicyTOFU, false},
+		{"strict", "", "", true},
+		{"yolo", "", "", true},
+	}
+	for _, tc := range cases {
+		got, err := ResolveHostKeyPolicy(tc.policy, tc.fingerprint)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("ResolveHostKeyPolicy(%q, %q) = %q, %v; want %q, err=%v", tc.policy, tc.fingerprint, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestNormalizeHostKeyFingerprint(t *testing.T) {
+	fp := ssh.FingerprintSHA256(newTestHostKey(t).PublicKey())
+	if got, err := NormalizeHostKeyFingerprint(fp); err != nil || got != fp {
+		t.Fatalf("expected %s unchanged, got %q, %v", fp, got, err)
+	}
+	if got, err := NormalizeHostKeyFingerprint(fp[len("SHA256:"):] + "="); err != nil || got != fp {
+		t.Fatalf("expected bare padded fingerprint to normalize, got %q, %v", got, err)
+	}
+	if _, err := NormalizeHostKeyFingerprint("MD5:aa:bb"); err == nil {
+		t.Fatal("expected error for non-SHA256 fingerprint")
+	}
+}
+
+func TestPinRemoteHostKey(t *testing.T) {
+	mgr := NewManager(zap.NewNop())
+	ps, err := mgr.RegisterRemote(RemoteProbeRegistration{
+		ID:          "rpr-1",
+		Remote:      RemoteProbeConfig{Host: "10.0.0.9", Username: "root"},
+		Credentials: RemoteProbeCredentials{Password: "pw"},
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if ps.Remote.HostKeyPolicy != HostKeyPolicyTOFU {
+		t.Fatalf("expected TOFU default without fingerprint, got %q", ps.Remote.HostKeyPolicy)
+	}
+
+	first := ssh.FingerprintSHA256(newTestHostKey(t).PublicKey())
+	if err := mgr.PinRemoteHostKey("rpr-1", first); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+	if err := mgr.PinRemoteHostKey("rpr-1", first); err != nil {
+		t.Fatalf("re-pinning the same key should succeed: %v", err)
+	}
+	second := ssh.FingerprintSHA256(newTestHostKey(t).PublicKey())
+	if err := mgr.PinRemoteHostKey("rpr-1", second); !errors.Is(err, ErrHostKeyMismatch) {
+		t.Fatalf("expected mismatch when pinning a different key, got %v", err)
+	}
+	got, _ := mgr.Get("rpr-1")
+	if got.Remote.HostKeyFingerprint != first {
+		t.Fatalf("pinned fingerprint changed to %q", got.Remote.HostKeyFingerprint)
+	}
+
+	if _, err := mgr.RegisterRemote(RemoteProbeRegistration{
+		ID:          "rpr-2",
+		Remote:      RemoteProbeConfig{Host: "10.0.0.10", Username: "root", HostKeyPolicy: HostKeyPolicyStrict},
+		Credentials: RemoteProbeCredentials{Password: "pw"},
+	}); err == nil {
+		t.Fatal("expected strict policy without fingerprint to be rejected")
+	}
+	strict, err := mgr.RegisterRemote(RemoteProbeRegistration{
+		ID:          "rpr-3",
+		Remote:      RemoteProbeConfig{Host: "10.0.0.11", Username: "root", HostKeyFingerprint: first},
+		Credentials: RemoteProbeCredentials{Password: "pw"},
+	})
+	if err != nil || strict.Remote.HostKeyPolicy != HostKeyPolicyStrict {
+		t.Fatalf("expected strict default with fingerprint, got %+v, %v", strict, err)
+	}
+}
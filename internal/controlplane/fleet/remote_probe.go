@@ -22,6 +22,12 @@ type RemoteProbeConfig struct {
 	AuthMode      string `json:"auth_mode,omitempty"`
 	HasPassword   bool   `json:"has_password,omitempty"`
 	HasPrivateKey bool   `json:"has_private_key,omitempty"`
+	// HostKeyFingerprint pins the SSH host key ("SHA256:<base64>"). Under
+	// TOFU it is filled in from the first successful connection.
+	HostKeyFingerprint string `json:"host_key_fingerprint,omitempty"`
+	// HostKeyPolicy is "strict" or "tofu"; empty (probes registered before
+	// host key checking) behaves as TOFU.
+	HostKeyPolicy string `json:"host_key_policy,omitempty"`
 }
 
 // RemoteProbeCredentials stores SSH auth material for a remote probe.
@@ -80,13 +86,24 @@ func (m *Manager) RegisterRemote(spec RemoteProbeRegistration) (*ProbeState, err
 		return nil, fmt.Errorf("remote probe requires password or private key")
 	}
 
+	fingerprint, err := NormalizeHostKeyFingerprint(spec.Remote.HostKeyFingerprint)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := ResolveHostKeyPolicy(spec.Remote.HostKeyPolicy, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
 	remote := RemoteProbeConfig{
-		Host:          host,
-		Port:          normalizeRemotePort(spec.Remote.Port),
-		Username:      username,
-		AuthMode:      strings.TrimSpace(spec.Remote.AuthMode),
-		HasPassword:   password != "",
-		HasPrivateKey: privateKey != "",
+		Host:               host,
+		Port:               normalizeRemotePort(spec.Remote.Port),
+		Username:           username,
+		AuthMode:           strings.TrimSpace(spec.Remote.AuthMode),
+		HasPassword:        password != "",
+		HasPrivateKey:      privateKey != "",
+		HostKeyFingerprint: fingerprint,
+		HostKeyPolicy:      policy,
 	}
 
 	hostname := strings.TrimSpace(spec.Hostname)
@@ -131,6 +148,37 @@ func (m *Manager) RegisterRemote(spec RemoteProbeRegistration) (*ProbeState, err
 	return ps, nil
 }
 
+// PinRemoteHostKey records the host key fingerprint of a TOFU remote probe.
+// It fails if the probe already has a different fingerprint pinned.
+func (m *Manager) PinRemoteHostKey(id, fingerprint string) error {
+	fingerprint, err := NormalizeHostKeyFingerprint(fingerprint)
+	if err != nil {
+		return err
+	}
+	if fingerprint == "" {
+		return fmt.Errorf("host key fingerprint is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ps, ok := m.probes[id]
+	if !ok {
+		return fmt.Errorf("unknown probe: %s", id)
+	}
+	if ps.Remote == nil {
+		return fmt.Errorf("probe %s is not a remote probe", id)
+	}
+	if existing := ps.Remote.HostKeyFingerprint; existing != "" && existing != fingerprint {
+		return &HostKeyError{Host: ps.Remote.Host, Expected: existing, Actual: fingerprint, Err: ErrHostKeyMismatch}
+	}
+	ps.Remote.HostKeyFingerprint = fingerprint
+	if ps.Remote.HostKeyPolicy == "" {
+		ps.Remote.HostKeyPolicy = HostKeyPolicyTOFU
+	}
+	return nil
+}
+
 func (m *Manager) ListRemote() []*ProbeState {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
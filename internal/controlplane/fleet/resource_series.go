@@ -0,0 +1,245 @@
+package fleet
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+const (
+	defaultResourceSeriesWindow   = time.Hour
+	defaultResourceSeriesInterval = 30 * time.Second
+	// maxResourceSeriesSamples caps the ring per probe whatever the window
+	// and interval, so a long window with a short interval cannot grow
+	// memory without bound across a large fleet.
+	maxResourceSeriesSamples = 1440
+)
+
+// ResourcePoint is one resource reading in a probe's short-term series.
+// Percentages are omitted when the probe did not report the totals they
+// are derived from.
+type ResourcePoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	CPUPct    *float64  `json:"cpu_pct,omitempty"`
+	Load1     float64   `json:"load_1"`
+	MemPct    *float64  `json:"mem_pct,omitempty"`
+	DiskPct   *float64  `json:"disk_pct,omitempty"`
+}
+
+// resourcePoint is the compact stored form of a ResourcePoint; negative
+// values mark readings the probe did not report.
+type resourcePoint struct {
+	at   int64
+	cpu  float32
+	load float32
+	mem  float32
+	disk float32
+}
+
+// resourceRing is a fixed-capacity ring of one probe's samples, oldest first
+// from start.
+type resourceRing struct {
+	points []resourcePoint
+	start  int
+	count  int
+}
+
+func (r *resourceRing) push(p resourcePoint) {
+	if r.count < len(r.points) {
+		r.points[(r.start+r.count)%len(r.points)] = p
+		r.count++
+		return
+	}
+	r.points[r.start] = p
+	r.start = (r.start + 1) % len(r.points)
+}
+
+func (r *resourceRing) last() (resourcePoint, bool) {
+	if r.count == 0 {
+		return resourcePoint{}, false
+	}
+	return r.points[(r.start+r.count-1)%len(r.points)], true
+}
+
+// ResourceSeriesOptions sets how much short-term resource history is kept.
+type ResourceSeriesOptions struct {
+	// Window is how far back samples are kept.
+	Window time.Duration
+	// Interval is the minimum spacing between samples for one probe;
+	// heartbeats arriving sooner are dropped.
+	Interval time.Duration
+}
+
+// ResourceSeries keeps a rolling in-memory window of CPU, load, memory and
+// disk readings per probe. Each probe holds at most Window/Interval samples
+// (capped at 1440), allocated on its first sample.
+type ResourceSeries struct {
+	opts     ResourceSeriesOptions
+	capacity int
+
+	mu     sync.RWMutex
+	probes map[string]*resourceRing
+}
+
+// NewResourceSeries creates an empty series store. Zero options use a one
+// hour window at 30 second spacing.
+func NewResourceSeries(opts ResourceSeriesOptions) *ResourceSeries {
+	if opts.Window <= 0 {
+		opts.Window = defaultResourceSeriesWindow
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultResourceSeriesInterval
+	}
+	if opts.Interval > opts.Window {
+		opts.Interval = opts.Window
+	}
+	capacity := int(opts.Window / opts.Interval)
+	if capacity < 1 {
+		capacity = 1
+	}
+	if capacity > maxResourceSeriesSamples {
+		capacity = maxResourceSeriesSamples
+	}
+	return &ResourceSeries{opts: opts, capacity: capacity, probes: make(map[string]*resourceRing)}
+}
+
+// Window returns the configured retention window.
+func (s *ResourceSeries) Window() time.Duration { return s.opts.Window }
+
+// Interval returns the configured sample spacing.
+func (s *ResourceSeries) Interval() time.Duration { return s.opts.Interval }
+
+// Record appends the heartbeat's readings for probeID unless the previous
+// sample is closer than the interval. It reports whether a sample was kept.
+func (s *ResourceSeries) Record(probeID string, hb *protocol.HeartbeatPayload, cpus int, at time.Time) bool {
+	probeID = strings.TrimSpace(probeID)
+	if s == nil || probeID == "" || hb == nil {
+		return false
+	}
+	p := resourcePoint{
+		at:   at.UTC().UnixMilli(),
+		cpu:  -1,
+		load: float32(hb.Load[0]),
+		mem:  percentOf(hb.MemUsed, hb.MemTotal),
+		disk: percentOf(hb.DiskUsed, hb.DiskTotal),
+	}
+	if hb.CPUPct > 0 {
+		p.cpu = float32(min(hb.CPUPct, 100))
+	} else if cpus > 0 && hb.Load[0] > 0 {
+		// Agents that do not report CPU utilisation still report load;
+		// load per core is the closest stand-in.
+		p.cpu = float32(min(hb.Load[0]/float64(cpus)*100, 100))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ring, ok := s.probes[probeID]
+	if !ok {
+		ring = &resourceRing{points: make([]resourcePoint, s.capacity)}
+		s.probes[probeID] = ring
+	}
+	if prev, ok := ring.last(); ok && p.at-prev.at < s.opts.Interval.Milliseconds() {
+		return false
+	}
+	ring.push(p)
+	return true
+}
+
+// List returns probeID's samples recorded at or after since, oldest first.
+func (s *ResourceSeries) List(probeID string, since time.Time) []ResourcePoint {
+	out := []ResourcePoint{}
+	if s == nil {
+		return out
+	}
+	cutoff := since.UTC().UnixMilli()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ring, ok := s.probes[probeID]
+	if !ok {
+		return out
+	}
+	for i := 0; i < ring.count; i++ {
+		p := ring.points[(ring.start+i)%len(ring.points)]
+		if p.at < cutoff {
+			continue
+		}
+		out = append(out, ResourcePoint{
+			Timestamp: time.UnixMilli(p.at).UTC(),
+			CPUPct:    optionalPct(p.cpu),
+			Load1:     roundTenth(p.load),
+			MemPct:    optionalPct(p.mem),
+			DiskPct:   optionalPct(p.disk),
+		})
+	}
+	return out
+}
+
+// Delete drops probeID's series.
+func (s *ResourceSeries) Delete(probeID string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.probes, probeID)
+}
+
+// Prune drops the series of probes with no sample inside the window, so
+// probes that went away without being deleted release their ring.
+func (s *ResourceSeries) Prune(now time.Time) int {
+	if s == nil {
+		return 0
+	}
+	cutoff := now.Add(-s.opts.Window).UnixMilli()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pruned := 0
+	for id, ring := range s.probes {
+		if last, ok := ring.last(); !ok || last.at < cutoff {
+			delete(s.probes, id)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// PruneLoop prunes idle probe series every interval until ctx is done.
+func (s *ResourceSeries) PruneLoop(ctx context.Context, interval time.Duration) {
+	if s == nil || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Prune(time.Now())
+		}
+	}
+}
+
+func percentOf(used, total uint64) float32 {
+	if total == 0 {
+		return -1
+	}
+	return float32(min(float64(used)/float64(total)*100, 100))
+}
+
+func optionalPct(v float32) *float64 {
+	if v < 0 {
+		return nil
+	}
+	out := roundTenth(v)
+	return &out
+}
+
+func roundTenth(v float32) float64 {
+	return float64(int64(float64(v)*10+0.5)) / 10
+}
@@ -0,0 +1,57 @@
+package fleet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestResourceSeriesBoundsAndThins(t *testing.T) {
+	series := NewResourceSeries(ResourceSeriesOptions{Window: 5 * time.Minute, Interval: time.Minute})
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	hb := &protocol.HeartbeatPayload{Load: [3]float64{2}, MemUsed: 3, MemTotal: 4}
+
+	for i := 0; i < 20; i++ {
+		// Heartbeats every 30s; only every other one is a minute apart.
+		series.Record("probe-1", hb, 4, base.Add(time.Duration(i)*30*time.Second))
+	}
+	samples := series.List("probe-1", time.Time{})
+	if len(samples) != 5 {
+		t.Fatalf("expected ring capped at 5 samples, got %d", len(samples))
+	}
+	if want := base.Add(5 * time.Minute); !samples[0].Timestamp.Equal(want) {
+		t.Fatalf("expected oldest kept sample at %s, got %s", want, samples[0].Timestamp)
+	}
+	for i := 1; i < len(samples); i++ {
+		if !samples[i].Timestamp.After(samples[i-1].Timestamp) {
+			t.Fatalf("expected samples oldest first, got %v", samples)
+		}
+	}
+	first := samples[0]
+	if first.CPUPct == nil || *first.CPUPct != 50 {
+		t.Fatalf("expected CPU derived from load per core (50%%), got %v", first.CPUPct)
+	}
+	if first.MemPct == nil || *first.MemPct != 75 || first.DiskPct != nil {
+		t.Fatalf("expected mem 75%% and no disk reading, got mem=%v disk=%v", first.MemPct, first.DiskPct)
+	}
+
+	if got := series.List("probe-1", base.Add(8*time.Minute)); len(got) != 2 {
+		t.Fatalf("expected 2 samples since 12:08, got %d", len(got))
+	}
+
+	series.Record("probe-2", hb, 0, base)
+	if pruned := series.Prune(base.Add(10 * time.Minute)); pruned != 1 {
+		t.Fatalf("expected idle probe-2 pruned, got %d", pruned)
+	}
+	if got := series.List("probe-2", time.Time{}); len(got) != 0 {
+		t.Fatalf("expected probe-2 series gone, got %d", len(got))
+	}
+}
+
+func TestNewResourceSeriesCapsCapacity(t *testing.T) {
+	series := NewResourceSeries(ResourceSeriesOptions{Window: 24 * time.Hour, Interval: time.Second})
+	if series.capacity != maxResourceSeriesSamples {
+		t.Fatalf("expected capacity capped at %d, got %d", maxResourceSeriesSamples, series.capacity)
+	}
+}
@@ -0,0 +1,465 @@
+package fleet
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultRolloutConfirmTimeout = 10 * time.Minute
+	defaultRolloutPollInterval   = 5 * time.Second
+	maxRolloutHistory            = 100
+)
+
+// Rollout statuses.
+const (
+	RolloutStatusCanary    = "canary"    // canary probes updating, awaiting confirmation
+	RolloutStatusRolling   = "rolling"   // canary passed, remaining probes updating
+	RolloutStatusCompleted = "completed" // every probe confirmed the target version
+	RolloutStatusFailed    = "failed"    // canary passed but some remaining probes did not confirm
+	RolloutStatusAborted   = "aborted"   // canary failed; remaining probes were not touched
+)
+
+// Rollout stages and per-probe statuses.
+const (
+	RolloutStageCanary = "canary"
+	RolloutStageMain   = "main"
+
+	RolloutProbePending    = "pending"
+	RolloutProbeDispatched = "dispatched"
+	RolloutProbeUpdated    = "updated"
+	RolloutProbeFailed     = "failed"
+	RolloutProbeSkipped    = "skipped"
+)
+
+// RolloutSpec describes a staged binary update across a set of probes.
+type RolloutSpec struct {
+	Tag           string
+	Version       string
+	CanaryPercent int
+	// ConfirmTimeout bounds how long each stage waits for probes to reconnect
+	// healthy on the target version.
+	ConfirmTimeout time.Duration
+	Actor          string
+}
+
+// RolloutProbe is one probe's progress within a rollout.
+type RolloutProbe struct {
+	ProbeID         string     `json:"probe_id"`
+	Stage           string     `json:"stage"`
+	Status          string     `json:"status"`
+	RequestID       string     `json:"request_id,omitempty"`
+	ReportedVersion string     `json:"reported_version,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	DispatchedAt    *time.Time `json:"dispatched_at,omitempty"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+}
+
+// RolloutProgress counts probes by status.
+type RolloutProgress struct {
+	Total      int `json:"total"`
+	Canary     int `json:"canary"`
+	Pending    int `json:"pending"`
+	Dispatched int `json:"dispatched"`
+	Updated    int `json:"updated"`
+	Failed     int `json:"failed"`
+	Skipped    int `json:"skipped"`
+}
+
+// Rollout is the tracked state of a staged update.
+type Rollout struct {
+	ID             string          `json:"id"`
+	Tag            string          `json:"tag"`
+	Version        string          `json:"version"`
+	CanaryPercent  int             `json:"canary_percent"`
+	ConfirmTimeout string          `json:"confirm_timeout"`
+	Status         string          `json:"status"`
+	Error          string          `json:"error,omitempty"`
+	Actor          string          `json:"actor,omitempty"`
+	Probes         []RolloutProbe  `json:"probes"`
+	Progress       RolloutProgress `json:"progress"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+}
+
+// RolloutDispatchFunc sends the update to one probe and returns its request ID.
+type RolloutDispatchFunc func(probeID string) (requestID string, err error)
+
+// RolloutEventFunc is notified at each rollout stage transition. stage is one
+// of started, canary_passed, completed, failed or aborted.
+type RolloutEventFunc func(rollout Rollout, stage string)
+
+// RolloutManager drives canary-then-remaining update rollouts and keeps a
+// bounded in-memory history of them.
+type RolloutManager struct {
+	fleet        Fleet
+	onEvent      RolloutEventFunc
+	pollInterval time.Duration
+	now          func() time.Time
+
+	mu       sync.Mutex
+	rollouts map[string]*Rollout
+	order    []string
+}
+
+// NewRolloutManager creates a rollout manager reading probe state from f.
+func NewRolloutManager(f Fleet, onEvent RolloutEventFunc) *RolloutManager {
+	return &RolloutManager{
+		fleet:        f,
+		onEvent:      onEvent,
+		pollInterval: defaultRolloutPollInterval,
+		now:          func() time.Time { return time.Now().UTC() },
+		rollouts:     make(map[string]*Rollout),
+	}
+}
+
+// CanaryCount returns how many of total probes form the canary for percent.
+// Any positive percent selects at least one probe; 0 or 100 disables the
+// canary stage.
+func CanaryCount(total, percent int) int {
+	if total <= 0 || percent <= 0 || percent >= 100 {
+		return 0
+	}
+	n := int(math.Ceil(float64(total) * float64(percent) / 100))
+	if n >= total {
+		return 0
+	}
+	return n
+}
+
+// Start records a rollout over probeIDs and runs it in the background until
+// it finishes or ctx is done. The returned snapshot reflects the initial plan.
+func (m *RolloutManager) Start(ctx context.Context, spec RolloutSpec, probeIDs []string, dispatch RolloutDispatchFunc) (Rollout, error) {
+	spec.Version = strings.TrimSpace(spec.Version)
+	if spec.Version == "" {
+		return Rollout{}, fmt.Errorf("version is required")
+	}
+	if spec.CanaryPercent < 0 || spec.CanaryPercent > 100 {
+		return Rollout{}, fmt.Errorf("canary_percent must be between 0 and 100")
+	}
+	if spec.ConfirmTimeout <= 0 {
+		spec.ConfirmTimeout = defaultRolloutConfirmTimeout
+	}
+	ids := append([]string(nil), probeIDs...)
+	sort.Strings(ids)
+	if len(ids) == 0 {
+		return Rollout{}, fmt.Errorf("no probes to update")
+	}
+
+	now := m.now()
+	canary := CanaryCount(len(ids), spec.CanaryPercent)
+	r := &Rollout{
+		ID:             "rol-" + uuid.New().String()[:8],
+		Tag:            spec.Tag,
+		Version:        spec.Version,
+		CanaryPercent:  spec.CanaryPercent,
+		ConfirmTimeout: spec.ConfirmTimeout.String(),
+		Status:         RolloutStatusRolling,
+		Actor:          spec.Actor,
+		Probes:         make([]RolloutProbe, 0, len(ids)),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if canary > 0 {
+		r.Status = RolloutStatusCanary
+	}
+	for i, id := range ids {
+		stage := RolloutStageMain
+		if i < canary {
+			stage = RolloutStageCanary
+		}
+		r.Probes = append(r.Probes, RolloutProbe{ProbeID: id, Stage: stage, Status: RolloutProbePending})
+	}
+	r.Progress = rolloutProgress(r.Probes)
+
+	m.mu.Lock()
+	m.rollouts[r.ID] = r
+	m.order = append(m.order, r.ID)
+	m.trimLocked()
+	snapshot := cloneRollout(r)
+	m.mu.Unlock()
+
+	m.emit(snapshot, "started")
+	go m.run(ctx, r.ID, spec.ConfirmTimeout, dispatch)
+	return snapshot, nil
+}
+
+// Get returns a rollout snapshot by ID.
+func (m *RolloutManager) Get(id string) (Rollout, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.rollouts[id]
+	if !ok {
+		return Rollout{}, false
+	}
+	return cloneRollout(r), true
+}
+
+// List returns rollout snapshots, newest first.
+func (m *RolloutManager) List() []Rollout {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Rollout, 0, len(m.order))
+	for i := len(m.order) - 1; i >= 0; i-- {
+		if r, ok := m.rollouts[m.order[i]]; ok {
+			out = append(out, cloneRollout(r))
+		}
+	}
+	return out
+}
+
+func (m *RolloutManager) run(ctx context.Context, id string, timeout time.Duration, dispatch RolloutDispatchFunc) {
+	if m.hasStage(id, RolloutStageCanary) {
+		if ok := m.runStage(ctx, id, RolloutStageCanary, timeout, dispatch); !ok {
+			m.finish(id, RolloutStatusAborted, "canary probes did not confirm the target version; remaining probes were not updated")
+			return
+		}
+		m.update(id, func(r *Rollout) { r.Status = RolloutStatusRolling })
+		if snapshot, ok := m.Get(id); ok {
+			m.emit(snapshot, "canary_passed")
+		}
+	}
+
+	if ok := m.runStage(ctx, id, RolloutStageMain, timeout, dispatch); !ok {
+		m.finish(id, RolloutStatusFailed, "some probes did not confirm the target version")
+		return
+	}
+	m.finish(id, RolloutStatusCompleted, "")
+}
+
+// runStage dispatches to every pending probe in stage and polls fleet state
+// until each confirms or the stage times out. It reports whether all confirmed.
+func (m *RolloutManager) runStage(ctx context.Context, id, stage string, timeout time.Duration, dispatch RolloutDispatchFunc) bool {
+	for _, probeID := range m.stageProbes(id, stage) {
+		requestID, err := dispatch(probeID)
+		at := m.now()
+		m.setProbe(id, probeID, func(p *RolloutProbe) {
+			p.DispatchedAt = &at
+			p.RequestID = requestID
+			if err != nil {
+				p.Status = RolloutProbeFailed
+				p.Error = err.Error()
+				return
+			}
+			p.Status = RolloutProbeDispatched
+		})
+	}
+
+	deadline := m.now().Add(timeout)
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+	for {
+		if m.confirmStage(id, stage) {
+			return m.stageSucceeded(id, stage)
+		}
+		if !m.now().Before(deadline) {
+			m.failUnconfirmed(id, stage, fmt.Sprintf("not confirmed on target version within %s", timeout))
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			m.failUnconfirmed(id, stage, "rollout interrupted: control plane shutting down")
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// confirmStage marks dispatched probes that have reconnected online on the
+// target version and reports whether the stage has no probes left in flight.
+func (m *RolloutManager) confirmStage(id, stage string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.rollouts[id]
+	if !ok {
+		return true
+	}
+	done := true
+	for i := range r.Probes {
+		p := &r.Probes[i]
+		if p.Stage != stage || p.Status != RolloutProbeDispatched {
+			continue
+		}
+		live, ok := m.fleet.Liveness(p.ProbeID)
+		if ok {
+			p.ReportedVersion = live.Version
+		}
+		if ok && rolloutProbeConfirmed(live, r.Version, p.DispatchedAt) {
+			at := m.now()
+			p.Status = RolloutProbeUpdated
+			p.ConfirmedAt = &at
+			continue
+		}
+		done = false
+	}
+	m.touchLocked(r)
+	return done
+}
+
+// rolloutProbeConfirmed requires a heartbeat after dispatch reporting the
+// target version while the probe is online (not degraded).
+func rolloutProbeConfirmed(live ProbeLiveness, version string, dispatchedAt *time.Time) bool {
+	if live.Status != "online" {
+		return false
+	}
+	if dispatchedAt != nil && !live.LastSeen.After(*dispatchedAt) {
+		return false
+	}
+	return normalizeRolloutVersion(live.Version) == normalizeRolloutVersion(version)
+}
+
+func normalizeRolloutVersion(v string) string {
+	return strings.TrimPrefix(strings.TrimSpace(v), "v")
+}
+
+func (m *RolloutManager) stageSucceeded(id, stage string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.rollouts[id]
+	if !ok {
+		return false
+	}
+	for _, p := range r.Probes {
+		if p.Stage == stage && p.Status != RolloutProbeUpdated {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *RolloutManager) failUnconfirmed(id, stage, reason string) {
+	m.update(id, func(r *Rollout) {
+		for i := range r.Probes {
+			p := &r.Probes[i]
+			if p.Stage == stage && p.Status == RolloutProbeDispatched {
+				p.Status = RolloutProbeFailed
+				p.Error = reason
+			}
+		}
+	})
+}
+
+func (m *RolloutManager) finish(id, status, errMsg string) {
+	m.update(id, func(r *Rollout) {
+		at := m.now()
+		r.Status = status
+		r.Error = errMsg
+		r.CompletedAt = &at
+		if status == RolloutStatusAborted {
+			for i := range r.Probes {
+				if r.Probes[i].Status == RolloutProbePending {
+					r.Probes[i].Status = RolloutProbeSkipped
+				}
+			}
+		}
+	})
+	if snapshot, ok := m.Get(id); ok {
+		m.emit(snapshot, status)
+	}
+}
+
+func (m *RolloutManager) hasStage(id, stage string) bool {
+	return len(m.stageProbes(id, stage)) > 0
+}
+
+func (m *RolloutManager) stageProbes(id, stage string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.rollouts[id]
+	if !ok {
+		return nil
+	}
+	var ids []string
+	for _, p := range r.Probes {
+		if p.Stage == stage && p.Status == RolloutProbePending {
+			ids = append(ids, p.ProbeID)
+		}
+	}
+	return ids
+}
+
+func (m *RolloutManager) setProbe(id, probeID string, fn func(*RolloutProbe)) {
+	m.update(id, func(r *Rollout) {
+		for i := range r.Probes {
+			if r.Probes[i].ProbeID == probeID {
+				fn(&r.Probes[i])
+				return
+			}
+		}
+	})
+}
+
+func (m *RolloutManager) update(id string, fn func(*Rollout)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r, ok := m.rollouts[id]; ok {
+		fn(r)
+		m.touchLocked(r)
+	}
+}
+
+func (m *RolloutManager) touchLocked(r *Rollout) {
+	r.UpdatedAt = m.now()
+	r.Progress = rolloutProgress(r.Probes)
+}
+
+// trimLocked drops the oldest finished rollouts beyond the history bound.
+func (m *RolloutManager) trimLocked() {
+	for len(m.order) > maxRolloutHistory {
+		dropped := false
+		for i, id := range m.order {
+			if r := m.rollouts[id]; r != nil && r.CompletedAt == nil {
+				continue
+			}
+			delete(m.rollouts, id)
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			dropped = true
+			break
+		}
+		if !dropped {
+			return
+		}
+	}
+}
+
+func (m *RolloutManager) emit(r Rollout, stage string) {
+	if m.onEvent != nil {
+		m.onEvent(r, stage)
+	}
+}
+
+func rolloutProgress(probes []RolloutProbe) RolloutProgress {
+	progress := RolloutProgress{Total: len(probes)}
+	for _, p := range probes {
+		if p.Stage == RolloutStageCanary {
+			progress.Canary++
+		}
+		switch p.Status {
+		case RolloutProbePending:
+			progress.Pending++
+		case RolloutProbeDispatched:
+			progress.Dispatched++
+		case RolloutProbeUpdated:
+			progress.Updated++
+		case RolloutProbeFailed:
+			progress.Failed++
+		case RolloutProbeSkipped:
+			progress.Skipped++
+		}
+	}
+	return progress
+}
+
+func cloneRollout(r *Rollout) Rollout {
+	out := *r
+	out.Probes = append([]RolloutProbe(nil), r.Probes...)
+	return out
+}
@@ -0,0 +1,163 @@
+package fleet
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func newRolloutTestFleet(t *testing.T, ids ...string) *Manager {
+	t.Helper()
+	m := NewManager(testLogger())
+	for _, id := range ids {
+		m.Register(id, id, "linux", "amd64")
+		if err := m.Heartbeat(id, &protocol.HeartbeatPayload{ProbeID: id, Version: "1.0.0"}); err != nil {
+			t.Fatalf("heartbeat %s: %v", id, err)
+		}
+	}
+	return m
+}
+
+func waitRolloutDone(t *testing.T, rm *RolloutManager, id string) Rollout {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if r, ok := rm.Get(id); ok && r.CompletedAt != nil {
+			return r
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	r, _ := rm.Get(id)
+	t.Fatalf("rollout did not finish: %+v", r)
+	return Rollout{}
+}
+
+func TestCanaryCount(t *testing.T) {
+	cases := []struct{ total, percent, want int }{
+		{10, 0, 0},
+		{10, 100, 0},
+		{10, 10, 1},
+		{10, 25, 3},
+		{3, 1, 1},
+		{1, 50, 0},
+	}
+	for _, tc := range cases {
+		if got := CanaryCount(tc.total, tc.percent); got != tc.want {
+			t.Errorf("CanaryCount(%d, %d) = %d, want %d", tc.total, tc.percent, got, tc.want)
+		}
+	}
+}
+
+func TestRolloutCanaryThenRemaining(t *testing.T) {
+	m := newRolloutTestFleet(t, "p1", "p2", "p3", "p4")
+
+	var (
+		mu     sync.Mutex
+		stages []string
+	)
+	rm := NewRolloutManager(m, func(r Rollout, stage string) {
+		mu.Lock()
+		stages = append(stages, stage)
+		mu.Unlock()
+	})
+	rm.pollInterval = 5 * time.Millisecond
+
+	var dispatched []string
+	dispatch := func(probeID string) (string, error) {
+		mu.Lock()
+		dispatched = append(dispatched, probeID)
+		mu.Unlock()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = m.Heartbeat(probeID, &protocol.HeartbeatPayload{ProbeID: probeID, Version: "v1.2.0"})
+		}()
+		return "upd-" + probeID, nil
+	}
+
+	started, err := rm.Start(context.Background(), RolloutSpec{Tag: "web", Version: "1.2.0", CanaryPercent: 25, ConfirmTimeout: time.Second}, []string{"p4", "p2", "p3", "p1"}, dispatch)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if started.Status != RolloutStatusCanary || started.Progress.Canary != 1 || started.Probes[0].ProbeID != "p1" {
+		t.Fatalf("unexpected initial plan: %+v", started)
+	}
+
+	done := waitRolloutDone(t, rm, started.ID)
+	if done.Status != RolloutStatusCompleted || done.Progress.Updated != 4 {
+		t.Fatalf("expected completed rollout with 4 updated, got %+v", done)
+	}
+	for _, p := range done.Probes {
+		if p.ReportedVersion != "v1.2.0" || p.ConfirmedAt == nil {
+			t.Fatalf("expected confirmed probe on new version, got %+v", p)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dispatched) != 4 || dispatched[0] != "p1" {
+		t.Fatalf("expected canary p1 dispatched first, got %v", dispatched)
+	}
+	want := []string{"started", "canary_passed", RolloutStatusCompleted}
+	if len(stages) != len(want) {
+		t.Fatalf("expected stages %v, got %v", want, stages)
+	}
+	for i := range want {
+		if stages[i] != want[i] {
+			t.Fatalf("expected stages %v, got %v", want, stages)
+		}
+	}
+}
+
+func TestRolloutAbortsWhenCanaryFails(t *testing.T) {
+	m := newRolloutTestFleet(t, "p1", "p2", "p3")
+	rm := NewRolloutManager(m, nil)
+	rm.pollInterval = 5 * time.Millisecond
+
+	var calls int
+	dispatch := func(probeID string) (string, error) {
+		calls++
+		// Canary reconnects but still reports the old version.
+		go func() {
+			time.Sleep(5 * time.Millisecond)
+			_ = m.Heartbeat(probeID, &protocol.HeartbeatPayload{ProbeID: probeID, Version: "1.0.0"})
+		}()
+		return "upd-" + probeID, nil
+	}
+
+	started, err := rm.Start(context.Background(), RolloutSpec{Version: "1.2.0", CanaryPercent: 30, ConfirmTimeout: 60 * time.Millisecond}, []string{"p1", "p2", "p3"}, dispatch)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	done := waitRolloutDone(t, rm, started.ID)
+	if done.Status != RolloutStatusAborted {
+		t.Fatalf("expected aborted rollout, got %+v", done)
+	}
+	if calls != 1 {
+		t.Fatalf("expected only the canary to be dispatched, got %d dispatches", calls)
+	}
+	if done.Progress.Failed != 1 || done.Progress.Skipped != 2 {
+		t.Fatalf("expected 1 failed canary and 2 skipped, got %+v", done.Progress)
+	}
+	if done.Probes[0].ReportedVersion != "1.0.0" {
+		t.Fatalf("expected reported version to be tracked, got %+v", done.Probes[0])
+	}
+}
+
+func TestRolloutStartValidation(t *testing.T) {
+	rm := NewRolloutManager(NewManager(testLogger()), nil)
+	noop := func(string) (string, error) { return "", nil }
+
+	if _, err := rm.Start(context.Background(), RolloutSpec{}, []string{"p1"}, noop); err == nil {
+		t.Fatal("expected error without version")
+	}
+	if _, err := rm.Start(context.Background(), RolloutSpec{Version: "1.0.0", CanaryPercent: 101}, []string{"p1"}, noop); err == nil {
+		t.Fatal("expected error for canary_percent > 100")
+	}
+	if _, err := rm.Start(context.Background(), RolloutSpec{Version: "1.0.0"}, nil, noop); err == nil {
+		t.Fatal("expected error with no probes")
+	}
+}
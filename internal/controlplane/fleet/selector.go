@@ -0,0 +1,267 @@
+package fleet
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Tags are either plain labels ("prod", "k8s-host") or key=value pairs
+// ("env=prod", "role=web"). Both are stored lower-cased in ProbeState.Tags;
+// a probe carries at most one value per key.
+
+// ErrInvalidTag is returned (wrapped) when a tag or selector is malformed.
+var ErrInvalidTag = errors.New("invalid tag")
+
+var (
+	tagKeyPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]{0,62}$`)
+	tagValuePattern = regexp.MustCompile(`^[a-z0-9._:/-]{1,63}$`)
+)
+
+// SplitTag splits a key=value tag. ok is false for plain tags.
+func SplitTag(tag string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(tag, "=")
+	return key, value, ok
+}
+
+// NormalizeTags lower-cases, trims and de-duplicates tags like SetTags always
+// has, and additionally validates key=value tags: keys and values must be
+// simple identifiers and a key may only appear once.
+func NormalizeTags(tags []string) ([]string, error) {
+	out := normalizeTags(tags)
+	keys := make(map[string]string, len(out))
+	for i, t := range out {
+		key, value, ok := SplitTag(t)
+		if !ok {
+			if strings.ContainsAny(t, ",()!") {
+				return nil, fmt.Errorf("%w %q: plain tags must not contain , ( ) or !", ErrInvalidTag, t)
+			}
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !tagKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("%w %q: key must be 1-63 characters of a-z, 0-9, '.', '_', '/' or '-'", ErrInvalidTag, t)
+		}
+		if !tagValuePattern.MatchString(value) {
+			return nil, fmt.Errorf("%w %q: value must be 1-63 characters of a-z, 0-9, '.', '_', ':', '/' or '-'", ErrInvalidTag, t)
+		}
+		if prev, dup := keys[key]; dup && prev != value {
+			return nil, fmt.Errorf("%w: key %q has more than one value", ErrInvalidTag, key)
+		}
+		keys[key] = value
+		out[i] = key + "=" + value
+	}
+	return normalizeTags(out), nil
+}
+
+// selectorOp is a selector requirement operator.
+type selectorOp string
+
+const (
+	selectorEquals    selectorOp = "="
+	selectorNotEquals selectorOp = "!="
+	selectorIn        selectorOp = "in"
+	selectorNotIn     selectorOp = "notin"
+	selectorExists    selectorOp = "exists"
+	selectorNotExists selectorOp = "!"
+)
+
+type selectorRequirement struct {
+	key    string
+	op     selectorOp
+	values []string
+}
+
+// Selector matches probes by their tags. Requirements are ANDed.
+type Selector struct {
+	requirements []selectorRequirement
+}
+
+var setRequirementPattern = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+// ParseSelector parses a comma-separated list of requirements:
+//
+//	env=prod           key has value (== is accepted too)
+//	env!=prod          key is missing or has another value
+//	role in (web,api)  key has one of the values
+//	role notin (db)    key is missing or has none of the values
+//	web                plain tag "web" or any tag with key "web"
+//	!web               neither of the above
+//
+// An empty selector matches every probe.
+func ParseSelector(raw string) (Selector, error) {
+	var sel Selector
+	parts, err := splitSelector(strings.ToLower(raw))
+	if err != nil {
+		return Selector{}, err
+	}
+	for _, part := range parts {
+		req, err := parseRequirement(part)
+		if err != nil {
+			return Selector{}, err
+		}
+		sel.requirements = append(sel.requirements, req)
+	}
+	return sel, nil
+}
+
+// splitSelector splits on commas outside parentheses.
+func splitSelector(raw string) ([]string, error) {
+	var parts []string
+	depth, start := 0, 0
+	flush := func(end int) {
+		if p := strings.TrimSpace(raw[start:end]); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	for i, r := range raw {
+		switch r {
+		case '(':
+			depth++
+			if depth > 1 {
+				return nil, fmt.Errorf("%w selector: nested parentheses", ErrInvalidTag)
+			}
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("%w selector: unbalanced parentheses", ErrInvalidTag)
+			}
+		case ',':
+			if depth == 0 {
+				flush(i)
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("%w selector: unbalanced parentheses", ErrInvalidTag)
+	}
+	flush(len(raw))
+	return parts, nil
+}
+
+func parseRequirement(part string) (selectorRequirement, error) {
+	if m := setRequirementPattern.FindStringSubmatch(part); m != nil {
+		req := selectorRequirement{key: m[1], op: selectorOp(m[2])}
+		for _, v := range strings.Split(m[3], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				req.values = append(req.values, v)
+			}
+		}
+		if len(req.values) == 0 {
+			return selectorRequirement{}, fmt.Errorf("%w selector %q: empty value set", ErrInvalidTag, part)
+		}
+		return req, validateRequirement(part, req)
+	}
+
+	var req selectorRequirement
+	switch {
+	case strings.Contains(part, "!="):
+		key, value, _ := strings.Cut(part, "!=")
+		req = selectorRequirement{key: key, op: selectorNotEquals, values: []string{value}}
+	case strings.Contains(part, "=="):
+		key, value, _ := strings.Cut(part, "==")
+		req = selectorRequirement{key: key, op: selectorEquals, values: []string{value}}
+	case strings.Contains(part, "="):
+		key, value, _ := strings.Cut(part, "=")
+		req = selectorRequirement{key: key, op: selectorEquals, values: []string{value}}
+	case strings.HasPrefix(part, "!"):
+		req = selectorRequirement{key: strings.TrimPrefix(part, "!"), op: selectorNotExists}
+	default:
+		req = selectorRequirement{key: part, op: selectorExists}
+	}
+	req.key = strings.TrimSpace(req.key)
+	for i := range req.values {
+		req.values[i] = strings.TrimSpace(req.values[i])
+	}
+	return req, validateRequirement(part, req)
+}
+
+func validateRequirement(part string, req selectorRequirement) error {
+	if req.op == selectorExists || req.op == selectorNotExists {
+		if req.key == "" || strings.ContainsAny(req.key, " \t=!(),") {
+			return fmt.Errorf("%w selector %q: invalid tag name", ErrInvalidTag, part)
+		}
+		return nil
+	}
+	if !tagKeyPattern.MatchString(req.key) {
+		return fmt.Errorf("%w selector %q: invalid key", ErrInvalidTag, part)
+	}
+	for _, v := range req.values {
+		if !tagValuePattern.MatchString(v) {
+			return fmt.Errorf("%w selector %q: invalid value %q", ErrInvalidTag, part, v)
+		}
+	}
+	return nil
+}
+
+// Empty reports whether the selector has no requirements.
+func (s Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+// Matches reports whether tags satisfy every requirement.
+func (s Selector) Matches(tags []string) bool {
+	plain := make(map[string]bool, len(tags))
+	values := make(map[string]string, len(tags))
+	for _, t := range tags {
+		if key, value, ok := SplitTag(t); ok {
+			values[key] = value
+		} else {
+			plain[t] = true
+		}
+	}
+	for _, req := range s.requirements {
+		value, hasKey := values[req.key]
+		switch req.op {
+		case selectorExists:
+			if !hasKey && !plain[req.key] {
+				return false
+			}
+		case selectorNotExists:
+			if hasKey || plain[req.key] {
+				return false
+			}
+		case selectorEquals, selectorIn:
+			if !hasKey || !containsString(req.values, value) {
+				return false
+			}
+		case selectorNotEquals, selectorNotIn:
+			if hasKey && containsString(req.values, value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String renders the selector in canonical form.
+func (s Selector) String() string {
+	parts := make([]string, 0, len(s.requirements))
+	for _, req := range s.requirements {
+		switch req.op {
+		case selectorExists:
+			parts = append(parts, req.key)
+		case selectorNotExists:
+			parts = append(parts, "!"+req.key)
+		case selectorEquals, selectorNotEquals:
+			parts = append(parts, req.key+string(req.op)+req.values[0])
+		default:
+			values := append([]string(nil), req.values...)
+			sort.Strings(values)
+			parts = append(parts, fmt.Sprintf("%s %s (%s)", req.key, req.op, strings.Join(values, ",")))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func containsString(values []string, needle string) bool {
+	for _, v := range values {
+		if v == needle {
+			return true
+		}
+	}
+	return false
+}
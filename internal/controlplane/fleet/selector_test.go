@@ -0,0 +1,99 @@
+package fleet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := NormalizeTags([]string{"Prod", " env = Prod ", "role=web", "env=prod", ""})
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	want := []string{"prod", "env=prod", "role=web"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	for _, bad := range [][]string{
+		{"env="},
+		{"=prod"},
+		{"env=prod=eu"},
+		{"env=prod", "env=dev"},
+		{"role=web server"},
+		{"web,api"},
+	} {
+		if _, err := NormalizeTags(bad); !errors.Is(err, ErrInvalidTag) {
+			t.Fatalf("%v: expected ErrInvalidTag, got %v", bad, err)
+		}
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	web := []string{"env=prod", "role=web", "region=eu-west", "k8s-host"}
+	db := []string{"env=prod", "role=db"}
+	dev := []string{"env=dev", "role=web"}
+
+	cases := []struct {
+		selector string
+		want     [3]bool // web, db, dev
+	}{
+		{"", [3]bool{true, true, true}},
+		{"env=prod", [3]bool{true, true, false}},
+		{"env==prod,role in (web, api)", [3]bool{true, false, false}},
+		{"env=prod,role in (web,api),region=eu-west", [3]bool{true, false, false}},
+		{"role notin (db)", [3]bool{true, false, true}},
+		{"env!=prod", [3]bool{false, false, true}},
+		{"k8s-host", [3]bool{true, false, false}},
+		{"region", [3]bool{true, false, false}},
+		{"!region", [3]bool{false, true, true}},
+		{"ENV=PROD", [3]bool{true, true, false}},
+	}
+	for _, tc := range cases {
+		sel, err := ParseSelector(tc.selector)
+		if err != nil {
+			t.Fatalf("%q: parse: %v", tc.selector, err)
+		}
+		for i, tags := range [][]string{web, db, dev} {
+			if got := sel.Matches(tags); got != tc.want[i] {
+				t.Fatalf("%q on %v: got %v, want %v", tc.selector, tags, got, tc.want[i])
+			}
+		}
+	}
+}
+
+func TestParseSelectorErrorsAndString(t *testing.T) {
+	for _, bad := range []string{"role in ()", "role in (web", "role=", "env=prod)", "role in ((web))", "!"} {
+		if _, err := ParseSelector(bad); !errors.Is(err, ErrInvalidTag) {
+			t.Fatalf("%q: expected ErrInvalidTag, got %v", bad, err)
+		}
+	}
+
+	sel, err := ParseSelector(" env == prod , role in (api, web), !canary")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := sel.String(); got != "env=prod,role in (api,web),!canary" {
+		t.Fatalf("unexpected canonical form %q", got)
+	}
+}
+
+func TestSetTagsRejectsInvalidKeyValue(t *testing.T) {
+	m := NewManager(testLogger())
+	m.Register("probe-1", "host", "linux", "amd64")
+	if err := m.SetTags("probe-1", []string{"env=prod", "env=dev"}); !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("expected ErrInvalidTag, got %v", err)
+	}
+	if err := m.SetTags("probe-1", []string{"env=prod", "web"}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+	ps, _ := m.Get("probe-1")
+	if len(ps.Tags) != 2 {
+		t.Fatalf("unexpected tags %v", ps.Tags)
+	}
+}
@@ -0,0 +1,48 @@
+package fleet
+
+import "time"
+
+// FederationSourceFreshness is one self-syncing source measured against its
+// staleness threshold.
+type FederationSourceFreshness struct {
+	Source            FederationSourceDescriptor `json:"source"`
+	StaleAfterSeconds int64                      `json:"stale_after_seconds"`
+	AgeSeconds        int64                      `json:"age_seconds"`
+	Stale             bool                       `json:"stale"`
+	Sync              FederationSourceSync       `json:"sync"`
+}
+
+// SourceFreshness reports every registered source that runs its own sync
+// loop, sorted by ID. Age runs from the last successful sync, or from when
+// the source started if it has never synced, so a sync loop that dies
+// before its first pull still goes stale.
+func (s *FederationStore) SourceFreshness() []FederationSourceFreshness {
+	if s == nil {
+		return nil
+	}
+	now := s.now().UTC()
+	out := []FederationSourceFreshness{}
+	for _, adapter := range s.adaptersSnapshot() {
+		status := sourceSyncStatus(adapter)
+		if status == nil {
+			continue
+		}
+		staleAfter := sourceStaleAfter(adapter, s.staleAfter)
+		since := status.LastSuccessAt
+		if since.IsZero() {
+			since = status.StartedAt
+		}
+		age := now.Sub(since)
+		if age < 0 {
+			age = 0
+		}
+		out = append(out, FederationSourceFreshness{
+			Source:            normalizeFederationSourceDescriptor(adapter.Source()),
+			StaleAfterSeconds: int64(staleAfter / time.Second),
+			AgeSeconds:        int64(age / time.Second),
+			Stale:             staleAfter > 0 && age > staleAfter,
+			Sync:              *status,
+		})
+	}
+	return out
+}
@@ -17,8 +17,10 @@ import (
 // Reads are served from the in-memory Manager for speed; mutations are
 // written to both memory and disk.
 type Store struct {
-	db  *sql.DB
-	mgr *Manager
+	db     *sql.DB
+	mgr    *Manager
+	writes *migration.WriteHealth
+	logger *zap.Logger
 }
 
 // NewStore opens (or creates) a SQLite-backed fleet store.
@@ -90,13 +92,61 @@ func NewStore(dbPath string, logger *zap.Logger) (*Store, error) {
 				return nil
 			},
 		},
+		{
+			Version:     4,
+			Description: "add draining flag to probes",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE probes ADD COLUMN draining INTEGER NOT NULL DEFAULT 0`)
+				if err != nil && strings.Contains(err.Error(), "duplicate column name") {
+					return nil // idempotent
+				}
+				return err
+			},
+		},
+		{
+			Version:     5,
+			Description: "add api key issue and rotation times to probes",
+			Up: func(tx *sql.Tx) error {
+				if _, err := tx.Exec(`ALTER TABLE probes ADD COLUMN key_issued_at TEXT`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+					return err
+				}
+				if _, err := tx.Exec(`ALTER TABLE probes ADD COLUMN key_rotated_at TEXT`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+					return err
+				}
+				return nil
+			},
+		},
+		{
+			Version:     6,
+			Description: "add annotations to probes",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE probes ADD COLUMN annotations TEXT NOT NULL DEFAULT '{}'`)
+				if err != nil && strings.Contains(err.Error(), "duplicate column name") {
+					return nil // idempotent
+				}
+				return err
+			},
+		},
+		{
+			Version:     7,
+			Description: "add offline threshold and heartbeat interval to probes",
+			Up: func(tx *sql.Tx) error {
+				if _, err := tx.Exec(`ALTER TABLE probes ADD COLUMN offline_threshold_sec INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+					return err
+				}
+				if _, err := tx.Exec(`ALTER TABLE probes ADD COLUMN heartbeat_interval_sec INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+					return err
+				}
+				return nil
+			},
+		},
 	})
 	if err := runner.Migrate(db); err != nil {
 		_ = db.Close()
 		return nil, fmt.Errorf("migrate fleet db: %w", err)
 	}
 
-	s := &Store{db: db, mgr: NewManager(logger)}
+	s := &Store{db: db, mgr: NewManager(logger), writes: migration.NewWriteHealth("fleet"), logger: logger}
 
 	if err := s.loadAll(); err != nil {
 		db.Close()
@@ -115,6 +165,8 @@ func (s *Store) Manager() *Manager {
 
 func (s *Store) Get(id string) (*ProbeState, bool) { return s.mgr.Get(id) }
 
+func (s *Store) Liveness(id string) (ProbeLiveness, bool) { return s.mgr.Liveness(id) }
+
 func (s *Store) FindByHostname(hostname string) (*ProbeState, bool) {
 	if ps, ok := s.mgr.FindByHostname(hostname); ok {
 		return ps, true
@@ -182,6 +234,12 @@ func (s *Store) Heartbeat(id string, hb *protocol.HeartbeatPayload) error {
 	return nil
 }
 
+// RecordHealthCheck scores an on-demand health check. Health scores are
+// kept in memory only, like those computed from heartbeats.
+func (s *Store) RecordHealthCheck(id string, hb *protocol.HeartbeatPayload, downServices []string) (HealthScore, error) {
+	return s.mgr.RecordHealthCheck(id, hb, downServices)
+}
+
 // UpdateInventory stores a probe inventory.
 func (s *Store) UpdateInventory(id string, inv *protocol.InventoryPayload) error {
 	if err := s.mgr.UpdateInventory(id, inv); err != nil {
@@ -218,6 +276,55 @@ func (s *Store) SetAPIKey(id, apiKey string) error {
 	return nil
 }
 
+// RotateAPIKey replaces a probe API key and persists the rotation time. The
+// grace key is kept in memory only.
+func (s *Store) RotateAPIKey(id, newKey string, grace time.Duration) error {
+	if err := s.mgr.RotateAPIKey(id, newKey, grace); err != nil {
+		return err
+	}
+	ps, ok := s.mgr.Get(id)
+	if ok {
+		_ = s.upsertProbe(ps)
+	}
+	return nil
+}
+
+// RevertAPIKeyRotation restores the key replaced by the last rotation.
+func (s *Store) RevertAPIKeyRotation(id string) error {
+	if err := s.mgr.RevertAPIKeyRotation(id); err != nil {
+		return err
+	}
+	ps, ok := s.mgr.Get(id)
+	if ok {
+		_ = s.upsertProbe(ps)
+	}
+	return nil
+}
+
+// SetAnnotations replaces the probe annotations.
+func (s *Store) SetAnnotations(id string, annotations map[string]string) error {
+	if err := s.mgr.SetAnnotations(id, annotations); err != nil {
+		return err
+	}
+	ps, ok := s.mgr.Get(id)
+	if ok {
+		_ = s.upsertProbe(ps)
+	}
+	return nil
+}
+
+// PinRemoteHostKey records the host key fingerprint of a TOFU remote probe.
+func (s *Store) PinRemoteHostKey(id, fingerprint string) error {
+	if err := s.mgr.PinRemoteHostKey(id, fingerprint); err != nil {
+		return err
+	}
+	ps, ok := s.mgr.Get(id)
+	if ok {
+		_ = s.upsertProbe(ps)
+	}
+	return nil
+}
+
 // SetTags replaces the probe tags.
 func (s *Store) SetTags(id string, tags []string) error {
 	if err := s.mgr.SetTags(id, tags); err != nil {
@@ -235,7 +342,40 @@ func (s *Store) SetTenantID(id, tenantID string) error {
 	if err := s.mgr.SetTenantID(id, tenantID); err != nil {
 		return err
 	}
-	_, err := s.db.Exec(`UPDATE probes SET tenant_id = ? WHERE id = ?`, tenantID, id)
+	_, err := s.exec(`UPDATE probes SET tenant_id = ? WHERE id = ?`, tenantID, id)
+	return err
+}
+
+// SetDraining sets or clears a probe's draining flag, persisted to disk.
+func (s *Store) SetDraining(id string, draining bool) error {
+	if err := s.mgr.SetDraining(id, draining); err != nil {
+		return err
+	}
+	_, err := s.exec(`UPDATE probes SET draining = ? WHERE id = ?`, draining, id)
+	return err
+}
+
+// SetRateLimited records when a rate-limiting probe accepts commands again.
+// It is transient and not persisted.
+func (s *Store) SetRateLimited(id string, until time.Time) error {
+	return s.mgr.SetRateLimited(id, until)
+}
+
+// SetOfflineThreshold sets or clears a probe's offline threshold, persisted to disk.
+func (s *Store) SetOfflineThreshold(id string, threshold time.Duration) error {
+	if err := s.mgr.SetOfflineThreshold(id, threshold); err != nil {
+		return err
+	}
+	_, err := s.exec(`UPDATE probes SET offline_threshold_sec = ? WHERE id = ?`, int(threshold/time.Second), id)
+	return err
+}
+
+// SetHeartbeatInterval records a probe's advertised heartbeat interval, persisted to disk.
+func (s *Store) SetHeartbeatInterval(id string, interval time.Duration) error {
+	if err := s.mgr.SetHeartbeatInterval(id, interval); err != nil {
+		return err
+	}
+	_, err := s.exec(`UPDATE probes SET heartbeat_interval_sec = ? WHERE id = ?`, int(interval/time.Second), id)
 	return err
 }
 
@@ -278,10 +418,19 @@ func (s *Store) Close() error {
 	return s.db.Close()
 }
 
+// WriteStatus reports whether recent writes to fleet.db succeeded.
+func (s *Store) WriteStatus() migration.WriteStatus {
+	return s.writes.Status()
+}
+
 // ── Internal persistence ────────────────────────────────────
 
 func (s *Store) upsertProbe(ps *ProbeState) error {
 	labels, _ := json.Marshal(ps.Labels)
+	annotations, _ := json.Marshal(ps.Annotations)
+	if ps.Annotations == nil {
+		annotations = []byte("{}")
+	}
 	tags, _ := json.Marshal(ps.Tags)
 
 	probeType := normalizeProbeType(ps.Type)
@@ -311,8 +460,8 @@ func (s *Store) upsertProbe(ps *ProbeState) error {
 		credsJSON, _ = json.Marshal(cm)
 	}
 
-	_, err := s.db.Exec(`INSERT INTO probes (id, hostname, os, arch, status, probe_type, policy_level, api_key, registered, last_seen, labels, tags, inventory, tenant_id, remote, remote_credentials)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	_, err := s.exec(`INSERT INTO probes (id, hostname, os, arch, status, probe_type, policy_level, api_key, registered, last_seen, labels, tags, inventory, tenant_id, remote, remote_credentials, draining, key_issued_at, key_rotated_at, annotations, offline_threshold_sec, heartbeat_interval_sec)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			hostname           = excluded.hostname,
 			os                 = excluded.os,
@@ -327,7 +476,13 @@ func (s *Store) upsertProbe(ps *ProbeState) error {
 			inventory          = excluded.inventory,
 			tenant_id          = excluded.tenant_id,
 			remote             = excluded.remote,
-			remote_credentials = excluded.remote_credentials`,
+			remote_credentials = excluded.remote_credentials,
+			draining           = excluded.draining,
+			key_issued_at      = excluded.key_issued_at,
+			key_rotated_at     = excluded.key_rotated_at,
+			annotations        = excluded.annotations,
+			offline_threshold_sec  = excluded.offline_threshold_sec,
+			heartbeat_interval_sec = excluded.heartbeat_interval_sec`,
 		ps.ID,
 		ps.Hostname,
 		ps.OS,
@@ -344,23 +499,39 @@ func (s *Store) upsertProbe(ps *ProbeState) error {
 		ps.TenantID,
 		nullableJSON(remoteJSON),
 		nullableJSON(credsJSON),
+		ps.Draining,
+		nullableTime(ps.KeyIssuedAt),
+		nullableTime(ps.KeyRotatedAt),
+		string(annotations),
+		ps.OfflineThresholdSec,
+		ps.HeartbeatIntervalSec,
 	)
 	return err
 }
 
+// exec runs a write, retrying busy errors. Failures are logged here because
+// most mutations keep the in-memory change and discard the write error.
+func (s *Store) exec(query string, args ...any) (sql.Result, error) {
+	res, err := s.writes.Exec(s.db, query, args...)
+	if err != nil && s.logger != nil {
+		s.logger.Warn("fleet store write failed", zap.Error(err))
+	}
+	return res, err
+}
+
 func (s *Store) updateLastSeen(ps *ProbeState) error {
-	_, err := s.db.Exec(`UPDATE probes SET last_seen = ?, status = ? WHERE id = ?`,
+	_, err := s.exec(`UPDATE probes SET last_seen = ?, status = ? WHERE id = ?`,
 		ps.LastSeen.Format(time.RFC3339Nano), ps.Status, ps.ID)
 	return err
 }
 
 func (s *Store) updateStatus(id, status string) error {
-	_, err := s.db.Exec(`UPDATE probes SET status = ? WHERE id = ?`, status, id)
+	_, err := s.exec(`UPDATE probes SET status = ? WHERE id = ?`, status, id)
 	return err
 }
 
 func (s *Store) loadAll() error {
-	rows, err := s.db.Query(`SELECT id, hostname, os, arch, status, probe_type, policy_level, api_key, registered, last_seen, labels, tags, inventory, tenant_id, remote, remote_credentials FROM probes`)
+	rows, err := s.db.Query(`SELECT id, hostname, os, arch, status, probe_type, policy_level, api_key, registered, last_seen, labels, tags, inventory, tenant_id, remote, remote_credentials, draining, key_issued_at, key_rotated_at, annotations, offline_threshold_sec, heartbeat_interval_sec FROM probes`)
 	if err != nil {
 		return err
 	}
@@ -370,13 +541,16 @@ func (s *Store) loadAll() error {
 		var (
 			id, hostname, os_, arch, status, probeType, policyLevel, apiKey string
 			registered, lastSeen                                            string
-			labelsJSON, tagsJSON                                            string
+			labelsJSON, tagsJSON, annotationsJSON                           string
 			invJSON                                                         sql.NullString
 			tenantID                                                        string
 			remoteJSON                                                      sql.NullString
 			credsJSON                                                       sql.NullString
+			draining                                                        bool
+			keyIssuedAt, keyRotatedAt                                       sql.NullString
+			offlineThresholdSec, heartbeatIntervalSec                       int
 		)
-		if err := rows.Scan(&id, &hostname, &os_, &arch, &status, &probeType, &policyLevel, &apiKey, &registered, &lastSeen, &labelsJSON, &tagsJSON, &invJSON, &tenantID, &remoteJSON, &credsJSON); err != nil {
+		if err := rows.Scan(&id, &hostname, &os_, &arch, &status, &probeType, &policyLevel, &apiKey, &registered, &lastSeen, &labelsJSON, &tagsJSON, &invJSON, &tenantID, &remoteJSON, &credsJSON, &draining, &keyIssuedAt, &keyRotatedAt, &annotationsJSON, &offlineThresholdSec, &heartbeatIntervalSec); err != nil {
 			continue
 		}
 
@@ -390,11 +564,16 @@ func (s *Store) loadAll() error {
 			PolicyLevel: protocol.CapabilityLevel(policyLevel),
 			APIKey:      apiKey,
 			TenantID:    tenantID,
+			Draining:    draining,
 			Labels:      map[string]string{},
 			Tags:        []string{},
 		}
 		ps.Registered, _ = time.Parse(time.RFC3339Nano, registered)
 		ps.LastSeen, _ = time.Parse(time.RFC3339Nano, lastSeen)
+		ps.KeyIssuedAt = parseNullableTime(keyIssuedAt)
+		ps.KeyRotatedAt = parseNullableTime(keyRotatedAt)
+		ps.OfflineThresholdSec = offlineThresholdSec
+		ps.HeartbeatIntervalSec = heartbeatIntervalSec
 
 		if labelsJSON != "" && labelsJSON != "{}" {
 			_ = json.Unmarshal([]byte(labelsJSON), &ps.Labels)
@@ -402,6 +581,9 @@ func (s *Store) loadAll() error {
 		if tagsJSON != "" && tagsJSON != "[]" {
 			_ = json.Unmarshal([]byte(tagsJSON), &ps.Tags)
 		}
+		if annotationsJSON != "" && annotationsJSON != "{}" {
+			_ = json.Unmarshal([]byte(annotationsJSON), &ps.Annotations)
+		}
 		if invJSON.Valid && invJSON.String != "" {
 			var inv protocol.InventoryPayload
 			if err := json.Unmarshal([]byte(invJSON.String), &inv); err == nil {
@@ -433,6 +615,24 @@ func (s *Store) loadAll() error {
 	return rows.Err()
 }
 
+func nullableTime(t *time.Time) sql.NullString {
+	if t == nil || t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.UTC().Format(time.RFC3339Nano), Valid: true}
+}
+
+func parseNullableTime(v sql.NullString) *time.Time {
+	if !v.Valid || v.String == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, v.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
 func nullableJSON(data []byte) sql.NullString {
 	if data == nil {
 		return sql.NullString{}
@@ -445,7 +645,7 @@ func (s *Store) Delete(id string) error {
 	if err := s.mgr.Delete(id); err != nil {
 		return err
 	}
-	_, _ = s.db.Exec("DELETE FROM probes WHERE id = ?", id)
+	_, _ = s.exec("DELETE FROM probes WHERE id = ?", id)
 	return nil
 }
 
@@ -453,7 +653,7 @@ func (s *Store) Delete(id string) error {
 func (s *Store) CleanupOffline(olderThan time.Duration) []string {
 	removed := s.mgr.CleanupOffline(olderThan)
 	for _, id := range removed {
-		_, _ = s.db.Exec("DELETE FROM probes WHERE id = ?", id)
+		_, _ = s.exec("DELETE FROM probes WHERE id = ?", id)
 	}
 	return removed
 }
@@ -273,6 +273,72 @@ func TestStoreSetOnlinePersists(t *testing.T) {
 	}
 }
 
+func TestStoreDrainingPersists(t *testing.T) {
+	dbPath := tempDBPath(t)
+
+	s1, err := NewStore(dbPath, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1.Register("p1", "web-01", "linux", "amd64")
+	if err := s1.SetDraining("p1", true); err != nil {
+		t.Fatalf("set draining failed: %v", err)
+	}
+	// A later full upsert (e.g. heartbeat status change) must keep the flag.
+	if err := s1.SetStatus("p1", "degraded"); err != nil {
+		t.Fatalf("set status failed: %v", err)
+	}
+	s1.Close()
+
+	s2, err := NewStore(dbPath, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+
+	p1, ok := s2.Get("p1")
+	if !ok {
+		t.Fatal("expected p1 after reopen")
+	}
+	if !p1.Draining {
+		t.Fatal("expected draining flag to survive restart")
+	}
+}
+
+func TestStoreOfflineThresholdPersists(t *testing.T) {
+	dbPath := tempDBPath(t)
+
+	s1, err := NewStore(dbPath, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1.Register("p1", "web-01", "linux", "amd64")
+	if err := s1.SetOfflineThreshold("p1", 5*time.Minute); err != nil {
+		t.Fatalf("set offline threshold failed: %v", err)
+	}
+	if err := s1.SetHeartbeatInterval("p1", time.Minute); err != nil {
+		t.Fatalf("set heartbeat interval failed: %v", err)
+	}
+	if err := s1.SetStatus("p1", "degraded"); err != nil {
+		t.Fatalf("set status failed: %v", err)
+	}
+	s1.Close()
+
+	s2, err := NewStore(dbPath, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+
+	p1, ok := s2.Get("p1")
+	if !ok {
+		t.Fatal("expected p1 after reopen")
+	}
+	if p1.OfflineThresholdSec != 300 || p1.HeartbeatIntervalSec != 60 {
+		t.Fatalf("expected threshold 300s and heartbeat 60s after restart, got %d and %d", p1.OfflineThresholdSec, p1.HeartbeatIntervalSec)
+	}
+}
+
 func TestStoreDBFileCreated(t *testing.T) {
 	dbPath := tempDBPath(t)
 
@@ -367,3 +433,102 @@ func TestStoreSetAPIKeyPersists(t *testing.T) {
 		t.Fatalf("expected persisted api key, got %q", p1.APIKey)
 	}
 }
+
+func TestStoreKeyRotationPersists(t *testing.T) {
+	dbPath := tempDBPath(t)
+
+	s1, err := NewStore(dbPath, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1.Register("p1", "web-01", "linux", "amd64")
+	if err := s1.SetAPIKey("p1", "old-key"); err != nil {
+		t.Fatalf("set api key failed: %v", err)
+	}
+	if err := s1.RotateAPIKey("p1", "new-key", time.Minute); err != nil {
+		t.Fatalf("rotate failed: %v", err)
+	}
+	rotated, _ := s1.Get("p1")
+	rotatedAt := *rotated.KeyRotatedAt
+	s1.Close()
+
+	s2, err := NewStore(dbPath, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+
+	p1, ok := s2.Get("p1")
+	if !ok {
+		t.Fatal("expected p1 after reopen")
+	}
+	if p1.APIKey != "new-key" || p1.KeyRotatedAt == nil || !p1.KeyRotatedAt.Equal(rotatedAt) || p1.KeyIssuedAt == nil {
+		t.Fatalf("expected rotated key and times to survive restart, got key=%q issued=%v rotated=%v", p1.APIKey, p1.KeyIssuedAt, p1.KeyRotatedAt)
+	}
+	// The grace key is in-memory only.
+	if p1.AcceptsAPIKey("old-key", time.Now()) {
+		t.Fatal("expected previous key to be dropped on restart")
+	}
+}
+
+func TestStoreAnnotationsPersist(t *testing.T) {
+	dbPath := tempDBPath(t)
+
+	s1, err := NewStore(dbPath, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1.Register("p1", "web-01", "linux", "amd64")
+	if err := s1.SetAnnotations("p1", map[string]string{"decommission": "2026-Q2"}); err != nil {
+		t.Fatalf("set annotations failed: %v", err)
+	}
+	s1.Close()
+
+	s2, err := NewStore(dbPath, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+
+	p1, ok := s2.Get("p1")
+	if !ok {
+		t.Fatal("expected p1 after reopen")
+	}
+	if p1.Annotations["decommission"] != "2026-Q2" {
+		t.Fatalf("expected annotations to survive restart, got %#v", p1.Annotations)
+	}
+}
+
+func TestStoreWriteStatusTracksFailedWrites(t *testing.T) {
+	s, err := NewStore(tempDBPath(t), testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Register("p1", "web-01", "linux", "amd64")
+	if st := s.WriteStatus(); !st.Healthy || st.Store != "fleet" || st.LastSuccessAt == nil {
+		t.Fatalf("expected healthy fleet store, got %+v", st)
+	}
+
+	// Reject inserts the way a full disk would, without losing the in-memory copy.
+	if _, err := s.db.Exec(`CREATE TRIGGER fail_insert BEFORE INSERT ON probes BEGIN SELECT RAISE(ABORT, 'database or disk is full'); END`); err != nil {
+		t.Fatal(err)
+	}
+	s.Register("p2", "web-02", "linux", "amd64")
+	st := s.WriteStatus()
+	if st.Healthy || st.ConsecutiveFailures != 1 || st.LastError == "" {
+		t.Fatalf("expected failing write to mark store unhealthy, got %+v", st)
+	}
+	if _, ok := s.Get("p2"); !ok {
+		t.Fatal("expected probe to stay registered in memory")
+	}
+
+	if _, err := s.db.Exec(`DROP TRIGGER fail_insert`); err != nil {
+		t.Fatal(err)
+	}
+	s.Register("p3", "web-03", "linux", "amd64")
+	if st := s.WriteStatus(); !st.Healthy || st.TotalFailures != 1 {
+		t.Fatalf("expected recovery after successful write, got %+v", st)
+	}
+}
@@ -0,0 +1,68 @@
+package fleet
+
+import (
+	"sort"
+	"strings"
+)
+
+// VersionCount is the number of probes running one agent version.
+type VersionCount struct {
+	Version string `json:"version"`
+	Count   int    `json:"count"`
+}
+
+// FleetBreakdown counts probes by agent version, OS and policy level.
+type FleetBreakdown struct {
+	// Versions is ordered by count, largest first. Remote probes run no
+	// agent and are counted under "remote"; agents that have not reported a
+	// version yet under "unknown".
+	Versions      []VersionCount `json:"versions"`
+	ByOS          map[string]int `json:"by_os"`
+	ByPolicyLevel map[string]int `json:"by_policy_level"`
+}
+
+// Breakdown aggregates probes in a single pass.
+func Breakdown(probes []*ProbeState) FleetBreakdown {
+	out := FleetBreakdown{
+		ByOS:          map[string]int{},
+		ByPolicyLevel: map[string]int{},
+	}
+	versions := map[string]int{}
+	for _, ps := range probes {
+		if ps == nil {
+			continue
+		}
+		version := strings.TrimSpace(ps.Version)
+		switch {
+		case strings.EqualFold(ps.Type, ProbeTypeRemote):
+			version = ProbeTypeRemote
+		case version == "":
+			version = "unknown"
+		}
+		versions[version]++
+
+		osKey := strings.ToLower(strings.TrimSpace(ps.OS))
+		if osKey == "" {
+			osKey = "unknown"
+		}
+		out.ByOS[osKey]++
+
+		level := string(ps.PolicyLevel)
+		if level == "" {
+			level = "unknown"
+		}
+		out.ByPolicyLevel[level]++
+	}
+
+	out.Versions = make([]VersionCount, 0, len(versions))
+	for version, count := range versions {
+		out.Versions = append(out.Versions, VersionCount{Version: version, Count: count})
+	}
+	sort.Slice(out.Versions, func(i, j int) bool {
+		if out.Versions[i].Count != out.Versions[j].Count {
+			return out.Versions[i].Count > out.Versions[j].Count
+		}
+		return out.Versions[i].Version < out.Versions[j].Version
+	})
+	return out
+}
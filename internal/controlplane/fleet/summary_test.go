@@ -0,0 +1,35 @@
+package fleet
+
+import (
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestBreakdownBucketsRemoteAndUnknownVersions(t *testing.T) {
+	probes := []*ProbeState{
+		{ID: "a", OS: "Linux", Version: "v1.0.0", PolicyLevel: protocol.CapObserve},
+		{ID: "b", OS: "linux", Version: "v1.1.0", PolicyLevel: protocol.CapRemediate},
+		{ID: "c", OS: "linux", Version: "v1.0.0", PolicyLevel: protocol.CapObserve},
+		{ID: "d", OS: "linux", Type: ProbeTypeRemote, PolicyLevel: protocol.CapDiagnose},
+		{ID: "e"},
+		nil,
+	}
+
+	got := Breakdown(probes)
+	want := []VersionCount{{"v1.0.0", 2}, {"remote", 1}, {"unknown", 1}, {"v1.1.0", 1}}
+	if len(got.Versions) != len(want) {
+		t.Fatalf("expected %d version buckets, got %+v", len(want), got.Versions)
+	}
+	for i := range want {
+		if got.Versions[i] != want[i] {
+			t.Fatalf("versions[%d] = %+v, want %+v", i, got.Versions[i], want[i])
+		}
+	}
+	if got.ByOS["linux"] != 4 || got.ByOS["unknown"] != 1 {
+		t.Fatalf("unexpected OS breakdown: %+v", got.ByOS)
+	}
+	if got.ByPolicyLevel["observe"] != 2 || got.ByPolicyLevel["remediate"] != 1 || got.ByPolicyLevel["diagnose"] != 1 || got.ByPolicyLevel["unknown"] != 1 {
+		t.Fatalf("unexpected policy breakdown: %+v", got.ByPolicyLevel)
+	}
+}
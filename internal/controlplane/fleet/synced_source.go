@@ -0,0 +1,145 @@
+package fleet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// syncedSource is the shared scaffolding for federation sources that pull an
+// external inventory on an interval and serve the last good snapshot.
+type syncedSource struct {
+	source     FederationSourceDescriptor
+	interval   time.Duration
+	staleAfter time.Duration
+	logger     *zap.Logger
+	now        func() time.Time
+	collect    func(ctx context.Context, collectedAt time.Time) ([]ProbeInventorySummary, error)
+	startedAt  time.Time
+
+	mu          sync.RWMutex
+	probes      []ProbeInventorySummary
+	collectedAt time.Time
+	attemptedAt time.Time
+	lastErr     error
+}
+
+func newSyncedSource(source FederationSourceDescriptor, interval time.Duration, logger *zap.Logger) *syncedSource {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &syncedSource{
+		source:    normalizeFederationSourceDescriptor(source),
+		interval:  interval,
+		logger:    logger,
+		now:       time.Now,
+		startedAt: time.Now().UTC(),
+	}
+}
+
+// Source describes this adapter's source metadata.
+func (s *syncedSource) Source() FederationSourceDescriptor {
+	return s.source
+}
+
+// StaleAfter lets the federation store judge freshness against the
+// configured threshold, or twice the sync interval, rather than the default
+// snapshot window.
+func (s *syncedSource) StaleAfter() time.Duration {
+	if s.staleAfter > 0 {
+		return s.staleAfter
+	}
+	return 2 * s.interval
+}
+
+// SyncStatus reports the last sync attempt and the last successful one.
+func (s *syncedSource) SyncStatus() FederationSourceSync {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status := FederationSourceSync{
+		StartedAt:     s.startedAt,
+		LastAttemptAt: s.attemptedAt,
+		LastSuccessAt: s.collectedAt,
+	}
+	if s.lastErr != nil {
+		status.LastError = s.lastErr.Error()
+	}
+	return status
+}
+
+// Inventory returns the last synced snapshot. It fails until the first
+// successful sync; a failed sync after that keeps serving the previous
+// snapshot with a warning so freshness reflects the last good pull.
+func (s *syncedSource) Inventory(ctx context.Context, _ InventoryFilter) (FederationSourceResult, error) {
+	if err := ctx.Err(); err != nil {
+		return FederationSourceResult{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.collectedAt.IsZero() {
+		if s.lastErr != nil {
+			return FederationSourceResult{}, fmt.Errorf("%s sync failed: %w", s.source.ID, s.lastErr)
+		}
+		return FederationSourceResult{}, fmt.Errorf("%s source not yet synced", s.source.ID)
+	}
+
+	probes := make([]ProbeInventorySummary, 0, len(s.probes))
+	for _, probe := range s.probes {
+		probes = append(probes, cloneProbeInventorySummary(probe))
+	}
+	result := FederationSourceResult{
+		Inventory: FleetInventory{
+			Probes:     probes,
+			Aggregates: aggregateProbeSummaries(probes),
+		},
+		CollectedAt: s.collectedAt,
+	}
+	if s.lastErr != nil {
+		result.Warnings = []string{fmt.Sprintf("last sync failed: %v", s.lastErr)}
+	}
+	return result, nil
+}
+
+// Run syncs immediately and then on every interval until ctx is done.
+func (s *syncedSource) Run(ctx context.Context) {
+	s.syncAndLog(ctx)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncAndLog(ctx)
+		}
+	}
+}
+
+func (s *syncedSource) syncAndLog(ctx context.Context) {
+	if err := s.Sync(ctx); err != nil {
+		s.logger.Warn("inventory source sync failed", zap.String("source", s.source.ID), zap.Error(err))
+	}
+}
+
+// Sync pulls the upstream inventory and replaces the snapshot on success.
+func (s *syncedSource) Sync(ctx context.Context) error {
+	collectedAt := s.now().UTC()
+	probes, err := s.collect(ctx, collectedAt)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attemptedAt = collectedAt
+	s.lastErr = err
+	if err != nil {
+		return err
+	}
+	s.probes = probes
+	s.collectedAt = collectedAt
+	return nil
+}
@@ -0,0 +1,217 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTailscaleBaseURL      = "https://api.tailscale.com"
+	defaultTailscaleSyncInterval = 5 * time.Minute
+	defaultTailscaleTimeout      = 15 * time.Second
+	// tailscaleOnlineWindow is how recently a device must have been seen to
+	// count as online when the API omits connectedToControl.
+	tailscaleOnlineWindow = 5 * time.Minute
+)
+
+// TailscaleSourceConfig configures a Tailscale-backed federation inventory source.
+type TailscaleSourceConfig struct {
+	BaseURL    string
+	APIKey     string
+	Tailnet    string
+	Interval   time.Duration
+	Timeout    time.Duration
+	Source     FederationSourceDescriptor
+	HTTPClient *http.Client
+	Logger     *zap.Logger
+	// StaleAfter overrides the staleness threshold; 0 means twice Interval.
+	StaleAfter time.Duration
+}
+
+// TailscaleSourceAdapter syncs tailnet devices from the Tailscale API on an
+// interval and serves the last good snapshot as a federation source.
+type TailscaleSourceAdapter struct {
+	*syncedSource
+	baseURL string
+	apiKey  string
+	tailnet string
+	client  *http.Client
+}
+
+// NewTailscaleSourceAdapter builds a Tailscale source. The tailnet defaults to
+// "-" (the API key's own tailnet) and the source descriptor to id "tailscale".
+func NewTailscaleSourceAdapter(cfg TailscaleSourceConfig) *TailscaleSourceAdapter {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultTailscaleSyncInterval
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTailscaleTimeout
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+	if baseURL == "" {
+		baseURL = defaultTailscaleBaseURL
+	}
+	tailnet := strings.TrimSpace(cfg.Tailnet)
+	if tailnet == "" {
+		tailnet = "-"
+	}
+
+	source := cfg.Source
+	if strings.TrimSpace(source.ID) == "" {
+		source.ID = "tailscale"
+	}
+	if strings.TrimSpace(source.Name) == "" {
+		source.Name = "Tailscale"
+	}
+	if strings.TrimSpace(source.Kind) == "" {
+		source.Kind = "tailscale"
+	}
+
+	a := &TailscaleSourceAdapter{
+		syncedSource: newSyncedSource(source, interval, cfg.Logger),
+		baseURL:      baseURL,
+		apiKey:       strings.TrimSpace(cfg.APIKey),
+		tailnet:      tailnet,
+		client:       client,
+	}
+	a.collect = a.collectInventory
+	a.staleAfter = cfg.StaleAfter
+	return a
+}
+
+// Run syncs immediately and then on every interval until ctx is done.
+func (a *TailscaleSourceAdapter) Run(ctx context.Context) {
+	if a == nil || a.apiKey == "" {
+		return
+	}
+	a.syncedSource.Run(ctx)
+}
+
+// tailscaleDevice is the subset of the Tailscale device object Legator maps.
+type tailscaleDevice struct {
+	ID                 string    `json:"id"`
+	NodeID             string    `json:"nodeId"`
+	Name               string    `json:"name"`
+	Hostname           string    `json:"hostname"`
+	OS                 string    `json:"os"`
+	Addresses          []string  `json:"addresses"`
+	Tags               []string  `json:"tags"`
+	LastSeen           time.Time `json:"lastSeen"`
+	ConnectedToControl *bool     `json:"connectedToControl"`
+	Authorized         *bool     `json:"authorized"`
+}
+
+func (a *TailscaleSourceAdapter) collectInventory(ctx context.Context, collectedAt time.Time) ([]ProbeInventorySummary, error) {
+	endpoint := fmt.Sprintf("%s/api/v2/tailnet/%s/devices?fields=all", a.baseURL, url.PathEscape(a.tailnet))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+a.apiKey)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("tailscale devices: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, fmt.Errorf("tailscale devices: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tailscale devices: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Devices []tailscaleDevice `json:"devices"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("tailscale devices: %w", err)
+	}
+
+	probes := make([]ProbeInventorySummary, 0, len(payload.Devices))
+	for _, device := range payload.Devices {
+		probes = append(probes, tailscaleProbeSummary(device, collectedAt))
+	}
+	sort.Slice(probes, func(i, j int) bool { return probes[i].ID < probes[j].ID })
+	return probes, nil
+}
+
+func tailscaleProbeSummary(device tailscaleDevice, collectedAt time.Time) ProbeInventorySummary {
+	summary := ProbeInventorySummary{
+		ID:          "tailscale-" + firstNonEmpty(device.NodeID, device.ID),
+		Hostname:    firstNonEmpty(device.Hostname, tailscaleShortName(device.Name)),
+		Status:      tailscaleStatus(device, collectedAt),
+		OS:          strings.ToLower(strings.TrimSpace(device.OS)),
+		LastSeen:    device.LastSeen.UTC(),
+		CollectedAt: collectedAt,
+		PrimaryIP:   tailscalePrimaryIP(device.Addresses),
+	}
+	if summary.Hostname == "" {
+		summary.Hostname = summary.ID
+	}
+	for _, tag := range device.Tags {
+		if name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "tag:")); name != "" {
+			summary.Tags = append(summary.Tags, name)
+		}
+	}
+	return summary
+}
+
+// tailscaleStatus prefers the control-plane connection flag and falls back to
+// lastSeen recency. Devices awaiting admin approval are reported pending.
+func tailscaleStatus(device tailscaleDevice, now time.Time) string {
+	if device.Authorized != nil && !*device.Authorized {
+		return "pending"
+	}
+	if device.ConnectedToControl != nil {
+		if *device.ConnectedToControl {
+			return "online"
+		}
+		return "offline"
+	}
+	if !device.LastSeen.IsZero() && now.Sub(device.LastSeen) <= tailscaleOnlineWindow {
+		return "online"
+	}
+	return "offline"
+}
+
+// tailscaleShortName trims the MagicDNS suffix from a device name.
+func tailscaleShortName(name string) string {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".")
+	if short, _, ok := strings.Cut(name, "."); ok {
+		return short
+	}
+	return name
+}
+
+// tailscalePrimaryIP picks the first IPv4 tailnet address, falling back to
+// the first address of any family.
+func tailscalePrimaryIP(addresses []string) string {
+	for _, addr := range addresses {
+		if ip := net.ParseIP(strings.TrimSpace(addr)); ip != nil && ip.To4() != nil {
+			return ip.String()
+		}
+	}
+	if len(addresses) > 0 {
+		return strings.TrimSpace(addresses[0])
+	}
+	return ""
+}
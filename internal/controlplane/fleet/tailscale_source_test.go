@@ -0,0 +1,87 @@
+package fleet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTailscaleSourceAdapter_SyncMapsDevices(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tskey-api-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/v2/tailnet/example.com/devices" || r.URL.Query().Get("fields") != "all" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"devices":[
+			{"id":"1","nodeId":"nA","name":"web-1.tail1234.ts.net","hostname":"web-1","os":"linux",
+			 "addresses":["fd7a:115c:a1e0::1","100.64.0.1"],"tags":["tag:prod","tag:web"],
+			 "lastSeen":"2026-03-01T11:59:00Z","connectedToControl":true,"authorized":true},
+			{"id":"2","nodeId":"nB","name":"laptop.tail1234.ts.net","os":"macOS",
+			 "addresses":["100.64.0.2"],"lastSeen":"2026-03-01T11:58:00Z","authorized":true},
+			{"id":"3","nodeId":"nC","name":"old.tail1234.ts.net","os":"windows",
+			 "addresses":["100.64.0.3"],"lastSeen":"2026-02-01T00:00:00Z","authorized":true},
+			{"id":"4","nodeId":"nD","name":"new.tail1234.ts.net","os":"linux","authorized":false}
+		]}`)
+	}))
+	defer srv.Close()
+
+	adapter := NewTailscaleSourceAdapter(TailscaleSourceConfig{BaseURL: srv.URL, APIKey: "tskey-api-test", Tailnet: "example.com"})
+	adapter.now = func() time.Time { return time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC) }
+	if err := adapter.Sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	result, err := adapter.Inventory(context.Background(), InventoryFilter{})
+	if err != nil {
+		t.Fatalf("inventory: %v", err)
+	}
+
+	probes := map[string]ProbeInventorySummary{}
+	for _, probe := range result.Inventory.Probes {
+		probes[probe.ID] = probe
+	}
+	web := probes["tailscale-nA"]
+	if web.Hostname != "web-1" || web.Status != "online" || web.OS != "linux" || web.PrimaryIP != "100.64.0.1" {
+		t.Fatalf("unexpected device mapping: %+v", web)
+	}
+	if len(web.Tags) != 2 || web.Tags[0] != "prod" || web.Tags[1] != "web" {
+		t.Fatalf("unexpected tags: %v", web.Tags)
+	}
+	if laptop := probes["tailscale-nB"]; laptop.Hostname != "laptop" || laptop.Status != "online" {
+		t.Fatalf("expected recently seen device online by lastSeen, got %+v", laptop)
+	}
+	if old := probes["tailscale-nC"]; old.Status != "offline" {
+		t.Fatalf("expected long-unseen device offline, got %+v", old)
+	}
+	if pending := probes["tailscale-nD"]; pending.Status != "pending" {
+		t.Fatalf("expected unauthorized device pending, got %+v", pending)
+	}
+
+	store := NewFederationStore(adapter)
+	store.now = adapter.now
+	inv := store.Inventory(context.Background(), FederationFilter{Source: "tailscale"})
+	if len(inv.Sources) != 1 || inv.Sources[0].Consistency.Freshness != FederationFreshnessFresh {
+		t.Fatalf("expected fresh tailscale source, got %+v", inv.Sources)
+	}
+}
+
+func TestTailscaleSourceAdapter_SyncErrorBeforeFirstSnapshot(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	adapter := NewTailscaleSourceAdapter(TailscaleSourceConfig{BaseURL: srv.URL, APIKey: "bad"})
+	if err := adapter.Sync(context.Background()); err == nil {
+		t.Fatal("expected sync error")
+	}
+	if _, err := adapter.Inventory(context.Background(), InventoryFilter{}); err == nil {
+		t.Fatal("expected inventory error when no snapshot exists")
+	}
+}
@@ -78,7 +78,8 @@ type AsyncWorkerScheduler struct {
 	logger     *zap.Logger
 	cfg        AsyncWorkerConfig
 
-	metrics asyncSchedulerMetrics
+	metrics   asyncSchedulerMetrics
+	throttles *asyncThrottleTracker
 
 	runMu   sync.Mutex
 	schedMu sync.Mutex
@@ -97,6 +98,7 @@ func NewAsyncWorkerScheduler(store *Store, dispatcher AsyncJobDispatcher, logger
 		logger:     logger,
 		cfg:        cfg.normalized(),
 		metrics:    newAsyncSchedulerMetrics([]float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}),
+		throttles:  newAsyncThrottleTracker(),
 	}
 }
 
@@ -175,38 +177,51 @@ func (s *AsyncWorkerScheduler) drainQueued(ctx context.Context) {
 		runningTotal += count
 	}
 
-	available := s.cfg.MaxInFlight - runningTotal
-	if available <= 0 {
-		return
-	}
-
+	// Queued jobs are listed even when the global limit is reached so the
+	// ones held back by it are recorded as deferred rather than silently
+	// waiting.
 	queued, err := s.store.ListAsyncJobsByState(AsyncJobStateQueued, s.cfg.FetchBatchSize)
 	if err != nil {
 		s.logger.Debug("async scheduler: list queued jobs failed", zap.Error(err))
 		return
 	}
+	stillQueued := make(map[string]struct{}, len(queued))
+	for _, candidate := range queued {
+		stillQueued[candidate.ID] = struct{}{}
+	}
+	s.throttles.retain(stillQueued)
 
-	started := 0
 	for _, candidate := range queued {
-		if started >= available {
-			break
-		}
 		claimed, reason, err := s.claimQueuedJob(candidate, &runningTotal, runningByProbe)
 		if err != nil {
 			s.logger.Warn("async scheduler: claim queued job failed", zap.String("job_id", candidate.ID), zap.Error(err))
 			continue
 		}
 		if claimed == nil {
-			if reason != "" {
-				s.logger.Debug("async scheduler: deferred queued job", zap.String("job_id", candidate.ID), zap.String("reason", reason))
-			}
+			s.noteDeferred(candidate, reason)
 			continue
 		}
-		started++
 		s.dispatchClaimedAsync(ctx, *claimed)
 	}
 }
 
+// noteDeferred records a queued job held back by a concurrency limit. The
+// first deferral (or a change of reason) is logged; repeats on later polls
+// are not.
+func (s *AsyncWorkerScheduler) noteDeferred(job AsyncJob, reason string) {
+	if reason != AsyncThrottleGlobalLimit && reason != AsyncThrottleProbeLimit {
+		s.throttles.release(job.ID)
+		return
+	}
+	if s.throttles.record(job, reason, time.Now().UTC()) {
+		s.logger.Info("async scheduler: deferred queued job",
+			zap.String("job_id", job.ID),
+			zap.String("probe_id", job.ProbeID),
+			zap.String("reason", reason),
+		)
+	}
+}
+
 func (s *AsyncWorkerScheduler) DispatchNow(jobID string) (AsyncDispatchResult, error) {
 	if s == nil || s.store == nil || s.dispatcher == nil {
 		return AsyncDispatchResult{}, fmt.Errorf("async scheduler unavailable")
@@ -241,6 +256,7 @@ func (s *AsyncWorkerScheduler) DispatchNow(jobID string) (AsyncDispatchResult, e
 		return AsyncDispatchResult{}, err
 	}
 	if claimed == nil {
+		s.noteDeferred(*job, reason)
 		return AsyncDispatchResult{Outcome: AsyncDispatchOutcomeQueued, Reason: reason, Job: job}, nil
 	}
 
@@ -254,11 +270,13 @@ func (s *AsyncWorkerScheduler) claimQueuedJob(candidate AsyncJob, runningTotal *
 	if runningTotal == nil {
 		return nil, "", fmt.Errorf("running total pointer required")
 	}
-	if *runningTotal >= s.cfg.MaxInFlight {
-		return nil, "global_limit", nil
-	}
+	// The per-probe cap is checked first: a job blocked by both stays
+	// blocked by its probe after global capacity frees up.
 	if s.cfg.MaxPerProbe > 0 && runningByProbe[candidate.ProbeID] >= s.cfg.MaxPerProbe {
-		return nil, "probe_limit", nil
+		return nil, AsyncThrottleProbeLimit, nil
+	}
+	if *runningTotal >= s.cfg.MaxInFlight {
+		return nil, AsyncThrottleGlobalLimit, nil
 	}
 
 	claimed, err := s.store.TransitionAsyncJob(candidate.ID, AsyncJobStateRunning, AsyncJobTransitionOptions{})
@@ -271,6 +289,7 @@ func (s *AsyncWorkerScheduler) claimQueuedJob(candidate AsyncJob, runningTotal *
 
 	*runningTotal = *runningTotal + 1
 	runningByProbe[claimed.ProbeID] = runningByProbe[claimed.ProbeID] + 1
+	s.throttles.release(claimed.ID)
 	if claimed.StartedAt != nil {
 		s.metrics.observeQueueLatency(claimed.StartedAt.Sub(claimed.CreatedAt))
 	}
@@ -138,6 +138,74 @@ func TestAsyncWorkerSchedulerDrainsQueueWithPerProbeCap(t *testing.T) {
 	}
 }
 
+func TestAsyncWorkerSchedulerConcurrencySnapshotRecordsDeferrals(t *testing.T) {
+	store := newTestStore(t)
+	manager := NewAsyncManager(store)
+
+	running, err := manager.CreateJob(AsyncJob{ProbeID: "probe-1", RequestID: "req-running", Command: "sleep"})
+	if err != nil {
+		t.Fatalf("create running job: %v", err)
+	}
+	if _, err := store.TransitionAsyncJob(running.ID, AsyncJobStateRunning, AsyncJobTransitionOptions{}); err != nil {
+		t.Fatalf("seed running state: %v", err)
+	}
+	sameProbe, err := manager.CreateJob(AsyncJob{ProbeID: "probe-1", RequestID: "req-p1", Command: "echo p1"})
+	if err != nil {
+		t.Fatalf("create probe-1 queued: %v", err)
+	}
+	otherProbe, err := manager.CreateJob(AsyncJob{ProbeID: "probe-2", RequestID: "req-p2", Command: "echo p2"})
+	if err != nil {
+		t.Fatalf("create probe-2 queued: %v", err)
+	}
+
+	scheduler := NewAsyncWorkerScheduler(store, AsyncJobDispatcherFunc(func(ctx context.Context, job AsyncJob) error {
+		return nil
+	}), zap.NewNop(), AsyncWorkerConfig{MaxInFlight: 2, MaxPerProbe: 1})
+
+	// Two drain passes: the repeat deferral must not add a second event.
+	scheduler.drainQueued(context.Background())
+	scheduler.drainQueued(context.Background())
+	scheduler.wg.Wait()
+
+	snap, err := scheduler.ConcurrencySnapshot()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if snap.MaxInFlight != 2 || snap.MaxPerProbe != 1 || snap.Running != 2 || snap.Queued != 1 {
+		t.Fatalf("unexpected usage: %+v", snap)
+	}
+	if len(snap.Deferred) != 1 || snap.Deferred[0].JobID != sameProbe.ID || snap.Deferred[0].Reason != AsyncThrottleProbeLimit {
+		t.Fatalf("expected probe-1 job deferred by probe limit, got %+v", snap.Deferred)
+	}
+	if len(snap.RecentThrottles) != 1 {
+		t.Fatalf("expected one throttle event, got %+v", snap.RecentThrottles)
+	}
+	if len(snap.Probes) != 2 || snap.Probes[0].ProbeID != "probe-1" || snap.Probes[0].Running != 1 || snap.Probes[0].Deferred != 1 {
+		t.Fatalf("unexpected per-probe usage: %+v", snap.Probes)
+	}
+
+	// Once the blocking runs finish, the deferred job starts and is released.
+	if _, err := store.TransitionAsyncJob(otherProbe.ID, AsyncJobStateSucceeded, AsyncJobTransitionOptions{}); err != nil {
+		t.Fatalf("finish probe-2 job: %v", err)
+	}
+	if _, err := store.TransitionAsyncJob(running.ID, AsyncJobStateSucceeded, AsyncJobTransitionOptions{}); err != nil {
+		t.Fatalf("finish running job: %v", err)
+	}
+	scheduler.drainQueued(context.Background())
+	scheduler.wg.Wait()
+
+	snap, err = scheduler.ConcurrencySnapshot()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if len(snap.Deferred) != 0 || snap.Queued != 0 {
+		t.Fatalf("expected no deferred jobs after capacity freed, got %+v", snap)
+	}
+	if len(snap.RecentThrottles) != 1 {
+		t.Fatalf("throttle history should be kept, got %+v", snap.RecentThrottles)
+	}
+}
+
 func TestAsyncWorkerSchedulerDispatchFailureMarksJobFailed(t *testing.T) {
 	store := newTestStore(t)
 	manager := NewAsyncManager(store)
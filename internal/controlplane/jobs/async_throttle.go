@@ -0,0 +1,176 @@
+package jobs
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Reasons a queued async job is held back by the worker's concurrency limits.
+const (
+	AsyncThrottleGlobalLimit = "global_limit"
+	AsyncThrottleProbeLimit  = "probe_limit"
+)
+
+const asyncThrottleHistory = 100
+
+// AsyncDeferredJob is a queued job the worker last held back at a limit.
+type AsyncDeferredJob struct {
+	JobID         string    `json:"job_id"`
+	ProbeID       string    `json:"probe_id"`
+	RequestID     string    `json:"request_id"`
+	Reason        string    `json:"reason"`
+	QueuedAt      time.Time `json:"queued_at"`
+	DeferredSince time.Time `json:"deferred_since"`
+}
+
+// AsyncThrottleEvent records a job first being deferred, or its deferral
+// reason changing. Repeat deferrals on later polls are not recorded.
+type AsyncThrottleEvent struct {
+	JobID   string    `json:"job_id"`
+	ProbeID string    `json:"probe_id"`
+	Reason  string    `json:"reason"`
+	At      time.Time `json:"at"`
+}
+
+// AsyncProbeConcurrency is one probe's share of the worker's in-flight limit.
+type AsyncProbeConcurrency struct {
+	ProbeID  string `json:"probe_id"`
+	Running  int    `json:"running"`
+	Deferred int    `json:"deferred"`
+	// Limit is the per-probe cap; 0 means only the global limit applies.
+	Limit int `json:"limit"`
+}
+
+// AsyncConcurrencySnapshot shows why queued async jobs are or aren't starting.
+type AsyncConcurrencySnapshot struct {
+	MaxInFlight     int                     `json:"max_in_flight"`
+	MaxPerProbe     int                     `json:"max_per_probe"`
+	Running         int                     `json:"running"`
+	Queued          int                     `json:"queued"`
+	Probes          []AsyncProbeConcurrency `json:"probes"`
+	Deferred        []AsyncDeferredJob      `json:"deferred"`
+	RecentThrottles []AsyncThrottleEvent    `json:"recent_throttles"`
+}
+
+// asyncThrottleTracker remembers which queued jobs the worker deferred and
+// keeps a bounded history of throttle events.
+type asyncThrottleTracker struct {
+	mu       sync.Mutex
+	deferred map[string]AsyncDeferredJob
+	recent   []AsyncThrottleEvent
+}
+
+func newAsyncThrottleTracker() *asyncThrottleTracker {
+	return &asyncThrottleTracker{deferred: make(map[string]AsyncDeferredJob)}
+}
+
+// record marks job as deferred for reason and reports whether that is new,
+// either a first deferral or a changed reason.
+func (t *asyncThrottleTracker) record(job AsyncJob, reason string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if prev, ok := t.deferred[job.ID]; ok && prev.Reason == reason {
+		return false
+	}
+	t.deferred[job.ID] = AsyncDeferredJob{
+		JobID:         job.ID,
+		ProbeID:       job.ProbeID,
+		RequestID:     job.RequestID,
+		Reason:        reason,
+		QueuedAt:      job.CreatedAt,
+		DeferredSince: now,
+	}
+	t.recent = append(t.recent, AsyncThrottleEvent{JobID: job.ID, ProbeID: job.ProbeID, Reason: reason, At: now})
+	if len(t.recent) > asyncThrottleHistory {
+		t.recent = append([]AsyncThrottleEvent(nil), t.recent[len(t.recent)-asyncThrottleHistory:]...)
+	}
+	return true
+}
+
+// release forgets a job once it is claimed or has left the queue.
+func (t *asyncThrottleTracker) release(jobID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.deferred, jobID)
+}
+
+// retain drops deferred jobs that are no longer queued.
+func (t *asyncThrottleTracker) retain(queued map[string]struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id := range t.deferred {
+		if _, ok := queued[id]; !ok {
+			delete(t.deferred, id)
+		}
+	}
+}
+
+func (t *asyncThrottleTracker) snapshot() ([]AsyncDeferredJob, []AsyncThrottleEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	deferred := make([]AsyncDeferredJob, 0, len(t.deferred))
+	for _, job := range t.deferred {
+		deferred = append(deferred, job)
+	}
+	sort.Slice(deferred, func(i, j int) bool {
+		if deferred[i].QueuedAt.Equal(deferred[j].QueuedAt) {
+			return deferred[i].JobID < deferred[j].JobID
+		}
+		return deferred[i].QueuedAt.Before(deferred[j].QueuedAt)
+	})
+	recent := make([]AsyncThrottleEvent, 0, len(t.recent))
+	for i := len(t.recent) - 1; i >= 0; i-- {
+		recent = append(recent, t.recent[i])
+	}
+	return deferred, recent
+}
+
+// ConcurrencySnapshot reports current in-flight usage against the global and
+// per-probe limits, queued jobs held back by them, and recent throttle
+// events, newest first.
+func (s *AsyncWorkerScheduler) ConcurrencySnapshot() (AsyncConcurrencySnapshot, error) {
+	snap := AsyncConcurrencySnapshot{
+		Probes:          []AsyncProbeConcurrency{},
+		Deferred:        []AsyncDeferredJob{},
+		RecentThrottles: []AsyncThrottleEvent{},
+	}
+	if s == nil || s.store == nil {
+		return snap, nil
+	}
+	snap.MaxInFlight = s.cfg.MaxInFlight
+	snap.MaxPerProbe = s.cfg.MaxPerProbe
+
+	runningByProbe, err := s.store.RunningAsyncJobsByProbe()
+	if err != nil {
+		return snap, err
+	}
+	counts, err := s.store.AsyncJobStateCounts()
+	if err != nil {
+		return snap, err
+	}
+	snap.Queued = counts[AsyncJobStateQueued]
+	snap.Deferred, snap.RecentThrottles = s.throttles.snapshot()
+
+	probes := make(map[string]*AsyncProbeConcurrency)
+	entry := func(probeID string) *AsyncProbeConcurrency {
+		p, ok := probes[probeID]
+		if !ok {
+			p = &AsyncProbeConcurrency{ProbeID: probeID, Limit: s.cfg.MaxPerProbe}
+			probes[probeID] = p
+		}
+		return p
+	}
+	for probeID, count := range runningByProbe {
+		snap.Running += count
+		entry(probeID).Running = count
+	}
+	for _, job := range snap.Deferred {
+		entry(job.ProbeID).Deferred++
+	}
+	for _, p := range probes {
+		snap.Probes = append(snap.Probes, *p)
+	}
+	sort.Slice(snap.Probes, func(i, j int) bool { return snap.Probes[i].ProbeID < snap.Probes[j].ProbeID })
+	return snap, nil
+}
@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+// Concurrency policies follow Kubernetes CronJob semantics, applied per
+// probe: a trigger only conflicts with an active run of the same job on the
+// same probe.
+const (
+	ConcurrencyPolicyAllow   = "allow"
+	ConcurrencyPolicyForbid  = "forbid"
+	ConcurrencyPolicyReplace = "replace"
+)
+
+// JobStatusSkippedConcurrent is recorded as a job's last_status when a
+// scheduled cycle was skipped on every target because previous runs were
+// still active.
+const JobStatusSkippedConcurrent = "skipped_concurrent"
+
+// effectiveConcurrencyPolicy returns the job's policy, defaulting to forbid:
+// the scheduler never overlapped runs of a job on one probe before the
+// policy was configurable.
+func (j Job) effectiveConcurrencyPolicy() string {
+	if policy := normalizeConcurrencyPolicy(j.ConcurrencyPolicy); policy != "" {
+		return policy
+	}
+	return ConcurrencyPolicyForbid
+}
+
+func normalizeConcurrencyPolicy(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
+
+func validateConcurrencyPolicy(raw string) error {
+	switch normalizeConcurrencyPolicy(raw) {
+	case "", ConcurrencyPolicyAllow, ConcurrencyPolicyForbid, ConcurrencyPolicyReplace:
+		return nil
+	default:
+		return fmt.Errorf("invalid concurrency_policy: %s (want allow, forbid or replace)", raw)
+	}
+}
+
+// executionTargetKey keys an allow-policy run by execution so overlapping
+// runs on one probe don't contend for the same target slot. It keeps the
+// jobID:: prefix so job-wide retry cancellation still finds it.
+func executionTargetKey(jobID, probeID, executionID string) string {
+	return inFlightTargetKey(jobID, probeID) + "::" + strings.TrimSpace(executionID)
+}
+
+// replaceActiveRuns cancels the job's pending retry and active runs on probeID
+// and frees the target so a newer trigger can take its place. The command is
+// also canceled on the probe.
+func (s *Scheduler) replaceActiveRuns(job Job, probeID, targetKey string) {
+	s.cancelScheduledRetry(targetKey)
+
+	runs, err := s.store.ListActiveRunsByJob(job.ID)
+	if err != nil {
+		s.logger.Warn("list active runs for replace failed", zap.String("job_id", job.ID), zap.Error(err))
+		return
+	}
+	for _, run := range runs {
+		if run.ProbeID != probeID {
+			continue
+		}
+		if err := s.store.CancelRun(run.ID, "replaced by a newer trigger"); err != nil {
+			if !IsInvalidRunTransition(err) {
+				s.logger.Warn("cancel replaced run failed", zap.String("run_id", run.ID), zap.Error(err))
+			}
+			continue
+		}
+		s.emitLifecycleEvent(LifecycleEvent{
+			Type:        EventJobRunCanceled,
+			Actor:       "scheduler",
+			JobID:       run.JobID,
+			RunID:       run.ID,
+			ExecutionID: run.ExecutionID,
+			ProbeID:     run.ProbeID,
+			Attempt:     run.Attempt,
+			MaxAttempts: run.MaxAttempts,
+			RequestID:   run.RequestID,
+			Reason:      "replaced by a newer trigger",
+		})
+		requestID := s.requestIDForRun(run.ID)
+		if requestID == "" {
+			continue
+		}
+		// Drop the in-flight entry before canceling so the awaiting goroutine
+		// can't release the target the new run is about to claim.
+		s.clearInFlight(requestID, true)
+		s.tracker.Cancel(requestID)
+		if err := s.hub.SendTo(run.ProbeID, protocol.MsgCommandCancel, protocol.CommandCancelPayload{
+			RequestID: requestID,
+			Reason:    "job run replaced by a newer trigger",
+		}); err != nil {
+			s.logger.Debug("send command cancel failed", zap.String("run_id", run.ID), zap.String("probe_id", run.ProbeID), zap.Error(err))
+		}
+	}
+	s.releaseTarget(targetKey)
+}
+
+// recordSkippedTrigger emits a skipped event per probe and stores the reason
+// on the job. When every target was skipped the cycle is consumed, so the
+// schedule moves on instead of firing again on the next tick.
+func (s *Scheduler) recordSkippedTrigger(job Job, skipped []string, skippedAll bool, now time.Time) {
+	reason := fmt.Sprintf("previous run still active on %s", strings.Join(skipped, ", "))
+	for _, probeID := range skipped {
+		s.emitLifecycleEvent(LifecycleEvent{
+			Type:    EventJobRunSkipped,
+			Actor:   "scheduler",
+			JobID:   job.ID,
+			ProbeID: probeID,
+			Reason:  "previous run still active",
+		})
+	}
+	if err := s.store.RecordSkippedTrigger(job.ID, reason, now, skippedAll); err != nil {
+		s.logger.Warn("record skipped trigger failed", zap.String("job_id", job.ID), zap.Error(err))
+	}
+}
+
+// RecordSkippedTrigger stores why a trigger was skipped. With advanceCycle the
+// skip also consumes the scheduled cycle like SkipJobCycle, with
+// JobStatusSkippedConcurrent as last_status.
+func (s *Store) RecordSkippedTrigger(jobID, reason string, at time.Time, advanceCycle bool) error {
+	ts := at.UTC().Format(time.RFC3339Nano)
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	var (
+		res sql.Result
+		err error
+	)
+	if advanceCycle {
+		res, err = s.db.Exec(`UPDATE jobs SET last_run_at = ?, last_status = ?, last_skipped_at = ?, last_skip_reason = ?, updated_at = ? WHERE id = ?`,
+			ts, JobStatusSkippedConcurrent, ts, reason, now, jobID)
+	} else {
+		res, err = s.db.Exec(`UPDATE jobs SET last_skipped_at = ?, last_skip_reason = ?, updated_at = ? WHERE id = ?`,
+			ts, reason, now, jobID)
+	}
+	if err != nil {
+		return fmt.Errorf("record skipped trigger: %w", err)
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
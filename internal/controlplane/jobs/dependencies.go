@@ -0,0 +1,287 @@
+package jobs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// JobStatusDependencyTimeout is recorded as a job's last_status when a due
+// cycle was skipped because its dependencies did not succeed in time.
+const JobStatusDependencyTimeout = "dependency_timeout"
+
+const defaultDependencyWaitTimeout = time.Hour
+
+// DependencyState reports whether a due job is waiting on its dependencies.
+type DependencyState struct {
+	Blocked      bool       `json:"blocked"`
+	BlockedOn    []string   `json:"blocked_on,omitempty"`
+	BlockedSince *time.Time `json:"blocked_since,omitempty"`
+	WaitUntil    *time.Time `json:"wait_until,omitempty"`
+}
+
+type dependencyBlock struct {
+	since     time.Time
+	deadline  time.Time
+	blockedOn []string
+}
+
+// WithDependencyWaitTimeout sets how long a due job waits for its
+// dependencies when the job does not set dependency_timeout.
+func WithDependencyWaitTimeout(timeout time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		if timeout > 0 {
+			s.dependencyWaitTimeout = timeout
+		}
+	}
+}
+
+// DependencyState returns the live dependency gate for a job. Jobs without
+// dependencies return nil.
+func (s *Scheduler) DependencyState(job Job) *DependencyState {
+	if s == nil || len(job.DependsOn) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	block, ok := s.dependencyBlocks[job.ID]
+	s.mu.Unlock()
+	if !ok {
+		return &DependencyState{}
+	}
+	since := block.since
+	deadline := block.deadline
+	return &DependencyState{
+		Blocked:      true,
+		BlockedOn:    append([]string(nil), block.blockedOn...),
+		BlockedSince: &since,
+		WaitUntil:    &deadline,
+	}
+}
+
+// gateDependencies reports whether a due job may dispatch now. It tracks the
+// blocked state across ticks and emits blocked/unblocked/timeout events on
+// transitions. A timed-out cycle is recorded and skipped.
+func (s *Scheduler) gateDependencies(job Job, jobsByID map[string]Job, now time.Time) bool {
+	if len(job.DependsOn) == 0 {
+		return true
+	}
+
+	unmet := unmetDependencies(job, jobsByID)
+
+	s.mu.Lock()
+	block, wasBlocked := s.dependencyBlocks[job.ID]
+	switch {
+	case len(unmet) == 0:
+		delete(s.dependencyBlocks, job.ID)
+	case !wasBlocked:
+		block = dependencyBlock{
+			since:     now.UTC(),
+			deadline:  now.UTC().Add(s.dependencyTimeoutFor(job)),
+			blockedOn: unmet,
+		}
+		s.dependencyBlocks[job.ID] = block
+	case now.After(block.deadline):
+		delete(s.dependencyBlocks, job.ID)
+	default:
+		block.blockedOn = unmet
+		s.dependencyBlocks[job.ID] = block
+	}
+	s.mu.Unlock()
+
+	switch {
+	case len(unmet) == 0:
+		if wasBlocked {
+			s.emitLifecycleEvent(LifecycleEvent{
+				Type:  EventJobRunUnblocked,
+				Actor: "scheduler",
+				JobID: job.ID,
+			})
+		}
+		return true
+	case !wasBlocked:
+		s.emitLifecycleEvent(LifecycleEvent{
+			Type:      EventJobRunBlocked,
+			Actor:     "scheduler",
+			JobID:     job.ID,
+			BlockedOn: unmet,
+		})
+	case now.After(block.deadline):
+		s.emitLifecycleEvent(LifecycleEvent{
+			Type:      EventJobRunDependencyTimeout,
+			Actor:     "scheduler",
+			JobID:     job.ID,
+			BlockedOn: unmet,
+		})
+		if err := s.store.SkipJobCycle(job.ID, JobStatusDependencyTimeout, now); err != nil {
+			s.logger.Warn("record dependency timeout failed", zap.String("job_id", job.ID), zap.Error(err))
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) dependencyTimeoutFor(job Job) time.Duration {
+	if raw := strings.TrimSpace(job.DependencyTimeout); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			return d
+		}
+	}
+	return s.dependencyWaitTimeout
+}
+
+func (s *Scheduler) forgetDependencyBlock(jobID string) {
+	s.mu.Lock()
+	delete(s.dependencyBlocks, jobID)
+	s.mu.Unlock()
+}
+
+// unmetDependencies returns the dependency IDs whose most recent cycle has not
+// succeeded since this job last ran. Missing dependencies count as unmet.
+func unmetDependencies(job Job, jobsByID map[string]Job) []string {
+	var unmet []string
+	for _, depID := range job.DependsOn {
+		dep, ok := jobsByID[depID]
+		if !ok || dep.LastStatus != RunStatusSuccess || dep.LastRunAt == nil {
+			unmet = append(unmet, depID)
+			continue
+		}
+		if job.LastRunAt != nil && !dep.LastRunAt.After(*job.LastRunAt) {
+			unmet = append(unmet, depID)
+		}
+	}
+	return unmet
+}
+
+// SkipJobCycle records a scheduled cycle that was not dispatched, advancing
+// last_run_at so the schedule moves on to the next cycle.
+func (s *Store) SkipJobCycle(jobID, status string, at time.Time) error {
+	res, err := s.db.Exec(`UPDATE jobs SET last_run_at = ?, last_status = ?, updated_at = ? WHERE id = ?`,
+		at.UTC().Format(time.RFC3339Nano),
+		status,
+		time.Now().UTC().Format(time.RFC3339Nano),
+		jobID,
+	)
+	if err != nil {
+		return fmt.Errorf("skip job cycle: %w", err)
+	}
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ErrDependencyCycle is returned when depends_on would create a cycle.
+var ErrDependencyCycle = errors.New("job dependencies form a cycle")
+
+// ValidateDependencies checks that every dependency exists in the job's
+// workspace and that the dependency graph stays acyclic.
+func (s *Store) ValidateDependencies(job Job) error {
+	if len(job.DependsOn) == 0 {
+		return nil
+	}
+	all, err := s.ListJobsByWorkspace(job.WorkspaceID)
+	if err != nil {
+		return fmt.Errorf("list jobs: %w", err)
+	}
+	graph := make(map[string][]string, len(all)+1)
+	for _, other := range all {
+		graph[other.ID] = other.DependsOn
+	}
+	for _, depID := range job.DependsOn {
+		if _, ok := graph[depID]; !ok || depID == job.ID {
+			return fmt.Errorf("depends_on: unknown job %s", depID)
+		}
+	}
+	graph[job.ID] = job.DependsOn
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(graph))
+	var visit func(id string) bool
+	visit = func(id string) bool {
+		switch state[id] {
+		case visiting:
+			return false
+		case done:
+			return true
+		}
+		state[id] = visiting
+		for _, next := range graph[id] {
+			if !visit(next) {
+				return false
+			}
+		}
+		state[id] = done
+		return true
+	}
+	if !visit(job.ID) {
+		return ErrDependencyCycle
+	}
+	return nil
+}
+
+func validateDependsOn(job Job) error {
+	for _, depID := range job.DependsOn {
+		if strings.TrimSpace(depID) == "" {
+			return fmt.Errorf("depends_on entries must be non-empty")
+		}
+		if job.ID != "" && depID == job.ID {
+			return fmt.Errorf("job cannot depend on itself")
+		}
+	}
+	if raw := strings.TrimSpace(job.DependencyTimeout); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid dependency_timeout: %s", raw)
+		}
+	}
+	return nil
+}
+
+// normalizeDependsOn trims and de-duplicates dependency IDs, keeping order.
+func normalizeDependsOn(ids []string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+func encodeDependsOn(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	raw, err := json.Marshal(ids)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+func decodeDependsOn(raw string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	var ids []string
+	if err := json.Unmarshal([]byte(raw), &ids); err != nil {
+		return nil
+	}
+	return ids
+}
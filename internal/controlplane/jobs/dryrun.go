@@ -0,0 +1,86 @@
+package jobs
+
+import "fmt"
+
+// DryRunStatus marks every target in a job dry-run: nothing was dispatched.
+const DryRunStatus = "dry-run"
+
+// DryRunTarget is the predicted outcome of a job on one target probe.
+type DryRunTarget struct {
+	ProbeID   string              `json:"probe_id"`
+	Status    string              `json:"status"`
+	Online    bool                `json:"online"`
+	Admission JobAdmissionOutcome `json:"admission"`
+	Reason    string              `json:"reason,omitempty"`
+	Rationale any                 `json:"rationale,omitempty"`
+	// WouldRun is true when the command would be dispatched immediately.
+	WouldRun bool `json:"would_run"`
+}
+
+// DryRunSummary rolls up predicted outcomes across targets.
+type DryRunSummary struct {
+	WouldRun int `json:"would_run"`
+	Queued   int `json:"queued"`
+	Denied   int `json:"denied"`
+	Offline  int `json:"offline"`
+}
+
+// DryRunResult previews a job run. No commands are sent and no runs are
+// recorded; admission policy is evaluated exactly as a real run would.
+type DryRunResult struct {
+	Simulation bool           `json:"simulation"`
+	JobID      string         `json:"job_id"`
+	JobName    string         `json:"job_name"`
+	Command    string         `json:"command"`
+	Target     Target         `json:"target"`
+	Targets    []DryRunTarget `json:"targets"`
+	Summary    DryRunSummary  `json:"summary"`
+}
+
+// DryRun resolves a job's targets and evaluates admission for each without
+// dispatching anything.
+func (s *Scheduler) DryRun(jobID string) (*DryRunResult, error) {
+	job, err := s.store.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	probeIDs := s.resolveTargets(job.Target)
+	if len(probeIDs) == 0 {
+		return nil, fmt.Errorf("no probes resolved for target")
+	}
+
+	result := &DryRunResult{
+		Simulation: true,
+		JobID:      job.ID,
+		JobName:    job.Name,
+		Command:    job.Command,
+		Target:     job.Target,
+		Targets:    make([]DryRunTarget, 0, len(probeIDs)),
+	}
+	for _, probeID := range probeIDs {
+		decision := s.evaluateAdmission(*job, probeID)
+		target := DryRunTarget{
+			ProbeID:   probeID,
+			Status:    DryRunStatus,
+			Online:    s.probeOnline(probeID),
+			Admission: decision.Outcome,
+			Reason:    decision.Reason,
+			Rationale: decision.Rationale,
+		}
+		switch {
+		case decision.Outcome == AdmissionOutcomeDeny:
+			result.Summary.Denied++
+		case decision.Outcome == AdmissionOutcomeQueue:
+			result.Summary.Queued++
+		case !target.Online:
+			// A real run would be admitted and then fail as "probe offline".
+			result.Summary.Offline++
+		default:
+			target.WouldRun = true
+			result.Summary.WouldRun++
+		}
+		result.Targets = append(result.Targets, target)
+	}
+	return result, nil
+}
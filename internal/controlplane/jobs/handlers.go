@@ -102,6 +102,10 @@ func (h *Handler) HandleListJobs(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "internal_error", err.Error())
 		return
 	}
+	now := time.Now().UTC()
+	for i := range jobs {
+		jobs[i] = jobs[i].withNextRun(now)
+	}
 	writeJSON(w, http.StatusOK, jobs)
 }
 
@@ -109,12 +113,17 @@ func (h *Handler) HandleListJobs(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) HandleCreateJob(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		// scheduled-job payload
-		Name        string       `json:"name"`
-		Command     string       `json:"command"`
-		Schedule    string       `json:"schedule"`
-		Target      Target       `json:"target"`
-		RetryPolicy *RetryPolicy `json:"retry_policy"`
-		Enabled     *bool        `json:"enabled"`
+		Name              string       `json:"name"`
+		Command           string       `json:"command"`
+		Schedule          string       `json:"schedule"`
+		Target            Target       `json:"target"`
+		RetryPolicy       *RetryPolicy `json:"retry_policy"`
+		Enabled           *bool        `json:"enabled"`
+		Timezone          string       `json:"timezone"`
+		Timeout           string       `json:"timeout"`
+		DependsOn         []string     `json:"depends_on"`
+		DependencyTimeout string       `json:"dependency_timeout"`
+		ConcurrencyPolicy string       `json:"concurrency_policy"`
 
 		// async command-job payload
 		ProbeID   string   `json:"probe_id"`
@@ -159,6 +168,10 @@ func (h *Handler) HandleCreateJob(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid_schedule", err.Error())
 		return
 	}
+	if err := validateTimezone(req.Timezone); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_timezone", err.Error())
+		return
+	}
 
 	enabled := true
 	if req.Enabled != nil {
@@ -166,14 +179,23 @@ func (h *Handler) HandleCreateJob(w http.ResponseWriter, r *http.Request) {
 	}
 
 	job := Job{
-		WorkspaceID: strings.TrimSpace(wsID),
-		Name:        strings.TrimSpace(req.Name),
-		Command:     strings.TrimSpace(req.Command),
-		Schedule:    strings.TrimSpace(req.Schedule),
-		Target:      req.Target,
-		RetryPolicy: req.RetryPolicy,
-		Enabled:     enabled,
-		LastStatus:  "",
+		WorkspaceID:       strings.TrimSpace(wsID),
+		Name:              strings.TrimSpace(req.Name),
+		Command:           strings.TrimSpace(req.Command),
+		Schedule:          strings.TrimSpace(req.Schedule),
+		Timezone:          strings.TrimSpace(req.Timezone),
+		Target:            req.Target,
+		RetryPolicy:       req.RetryPolicy,
+		Timeout:           strings.TrimSpace(req.Timeout),
+		DependsOn:         normalizeDependsOn(req.DependsOn),
+		DependencyTimeout: strings.TrimSpace(req.DependencyTimeout),
+		ConcurrencyPolicy: strings.TrimSpace(req.ConcurrencyPolicy),
+		Enabled:           enabled,
+		LastStatus:        "",
+	}
+	if err := h.store.ValidateDependencies(job); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_dependencies", err.Error())
+		return
 	}
 	created, err := h.store.CreateJob(job)
 	if err != nil {
@@ -182,7 +204,7 @@ func (h *Handler) HandleCreateJob(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.emitLifecycleEvent(LifecycleEvent{Type: EventJobCreated, Actor: "api", JobID: created.ID})
-	writeJSON(w, http.StatusCreated, created)
+	writeJSON(w, http.StatusCreated, created.withNextRun(time.Now().UTC()))
 }
 
 // HandleGetJob serves GET /api/v1/jobs/{id}.
@@ -219,6 +241,10 @@ func (h *Handler) HandleGetJob(w http.ResponseWriter, r *http.Request) {
 	wsID := WorkspaceScopeFromContext(r.Context())
 	job, err := h.store.GetJobCheckWorkspace(id, wsID)
 	if err == nil {
+		*job = job.withNextRun(time.Now().UTC())
+		if h.scheduler != nil {
+			job.Dependency = h.scheduler.DependencyState(*job)
+		}
 		writeJSON(w, http.StatusOK, job)
 		return
 	}
@@ -275,12 +301,17 @@ func (h *Handler) HandleUpdateJob(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Name        string       `json:"name"`
-		Command     string       `json:"command"`
-		Schedule    string       `json:"schedule"`
-		Target      Target       `json:"target"`
-		RetryPolicy *RetryPolicy `json:"retry_policy"`
-		Enabled     *bool        `json:"enabled"`
+		Name              string       `json:"name"`
+		Command           string       `json:"command"`
+		Schedule          string       `json:"schedule"`
+		Target            Target       `json:"target"`
+		RetryPolicy       *RetryPolicy `json:"retry_policy"`
+		Enabled           *bool        `json:"enabled"`
+		Timezone          *string      `json:"timezone"`
+		Timeout           *string      `json:"timeout"`
+		DependsOn         *[]string    `json:"depends_on"`
+		DependencyTimeout *string      `json:"dependency_timeout"`
+		ConcurrencyPolicy *string      `json:"concurrency_policy"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_request", "invalid JSON body")
@@ -299,20 +330,54 @@ func (h *Handler) HandleUpdateJob(w http.ResponseWriter, r *http.Request) {
 	if req.RetryPolicy != nil {
 		retryPolicy = req.RetryPolicy
 	}
+	timezone := existing.Timezone
+	if req.Timezone != nil {
+		timezone = strings.TrimSpace(*req.Timezone)
+		if err := validateTimezone(timezone); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_timezone", err.Error())
+			return
+		}
+	}
+	timeout := existing.Timeout
+	if req.Timeout != nil {
+		timeout = strings.TrimSpace(*req.Timeout)
+	}
+	dependsOn := existing.DependsOn
+	if req.DependsOn != nil {
+		dependsOn = normalizeDependsOn(*req.DependsOn)
+	}
+	dependencyTimeout := existing.DependencyTimeout
+	if req.DependencyTimeout != nil {
+		dependencyTimeout = strings.TrimSpace(*req.DependencyTimeout)
+	}
+	concurrencyPolicy := existing.ConcurrencyPolicy
+	if req.ConcurrencyPolicy != nil {
+		concurrencyPolicy = strings.TrimSpace(*req.ConcurrencyPolicy)
+	}
 
-	updated, err := h.store.UpdateJob(Job{
-		ID:          id,
-		WorkspaceID: existing.WorkspaceID,
-		Name:        strings.TrimSpace(req.Name),
-		Command:     strings.TrimSpace(req.Command),
-		Schedule:    strings.TrimSpace(req.Schedule),
-		Target:      req.Target,
-		RetryPolicy: retryPolicy,
-		Enabled:     enabled,
-		CreatedAt:   existing.CreatedAt,
-		LastRunAt:   existing.LastRunAt,
-		LastStatus:  existing.LastStatus,
-	})
+	job := Job{
+		ID:                id,
+		WorkspaceID:       existing.WorkspaceID,
+		Name:              strings.TrimSpace(req.Name),
+		Command:           strings.TrimSpace(req.Command),
+		Schedule:          strings.TrimSpace(req.Schedule),
+		Timezone:          timezone,
+		Target:            req.Target,
+		RetryPolicy:       retryPolicy,
+		Timeout:           timeout,
+		DependsOn:         dependsOn,
+		DependencyTimeout: dependencyTimeout,
+		ConcurrencyPolicy: concurrencyPolicy,
+		Enabled:           enabled,
+		CreatedAt:         existing.CreatedAt,
+		LastRunAt:         existing.LastRunAt,
+		LastStatus:        existing.LastStatus,
+	}
+	if err := h.store.ValidateDependencies(job); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_dependencies", err.Error())
+		return
+	}
+	updated, err := h.store.UpdateJob(job)
 	if err != nil {
 		if IsNotFound(err) {
 			writeError(w, http.StatusNotFound, "not_found", "job not found")
@@ -323,7 +388,7 @@ func (h *Handler) HandleUpdateJob(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.emitLifecycleEvent(LifecycleEvent{Type: EventJobUpdated, Actor: "api", JobID: updated.ID})
-	writeJSON(w, http.StatusOK, updated)
+	writeJSON(w, http.StatusOK, updated.withNextRun(time.Now().UTC()))
 }
 
 // HandleDeleteJob serves DELETE /api/v1/jobs/{id}.
@@ -358,7 +423,8 @@ func (h *Handler) HandleDeleteJob(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// HandleRunJob serves POST /api/v1/jobs/{id}/run.
+// HandleRunJob serves POST /api/v1/jobs/{id}/run. With ?dry_run=true it
+// returns a per-target admission preview instead of dispatching.
 func (h *Handler) HandleRunJob(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimSpace(r.PathValue("id"))
 	if id == "" {
@@ -383,6 +449,16 @@ func (h *Handler) HandleRunJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if dryRun := strings.TrimSpace(r.URL.Query().Get("dry_run")); dryRun == "true" || dryRun == "1" {
+		result, err := h.scheduler.DryRun(id)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"dry_run": result})
+		return
+	}
+
 	if err := h.scheduler.TriggerNow(id); err != nil {
 		writeError(w, http.StatusBadGateway, "dispatch_failed", err.Error())
 		return
@@ -527,8 +603,8 @@ func (h *Handler) HandleRetryRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if run.Status != RunStatusFailed && run.Status != RunStatusCanceled && run.Status != RunStatusDenied {
-		writeError(w, http.StatusConflict, "invalid_transition", "only failed, canceled, or denied runs can be retried")
+	if run.Status != RunStatusFailed && run.Status != RunStatusCanceled && run.Status != RunStatusDenied && run.Status != RunStatusTimedOut {
+		writeError(w, http.StatusConflict, "invalid_transition", "only failed, canceled, denied, or timed_out runs can be retried")
 		return
 	}
 
@@ -580,16 +656,17 @@ func (h *Handler) HandleListRuns(w http.ResponseWriter, r *http.Request) {
 	}
 	summary := summarizeRuns(runs)
 	writeJSON(w, http.StatusOK, map[string]any{
-		"job_id":         id,
-		"runs":           runs,
-		"count":          len(runs),
-		"failed_count":   summary.Failed,
-		"success_count":  summary.Success,
-		"running_count":  summary.Running,
-		"pending_count":  summary.Pending,
-		"queued_count":   summary.Queued,
-		"canceled_count": summary.Canceled,
-		"denied_count":   summary.Denied,
+		"job_id":          id,
+		"runs":            runs,
+		"count":           len(runs),
+		"failed_count":    summary.Failed,
+		"success_count":   summary.Success,
+		"running_count":   summary.Running,
+		"pending_count":   summary.Pending,
+		"queued_count":    summary.Queued,
+		"canceled_count":  summary.Canceled,
+		"denied_count":    summary.Denied,
+		"timed_out_count": summary.TimedOut,
 	})
 }
 
@@ -625,15 +702,16 @@ func (h *Handler) HandleListAllRuns(w http.ResponseWriter, r *http.Request) {
 	}
 	summary := summarizeRuns(runs)
 	writeJSON(w, http.StatusOK, map[string]any{
-		"runs":           runs,
-		"count":          len(runs),
-		"failed_count":   summary.Failed,
-		"success_count":  summary.Success,
-		"running_count":  summary.Running,
-		"pending_count":  summary.Pending,
-		"queued_count":   summary.Queued,
-		"canceled_count": summary.Canceled,
-		"denied_count":   summary.Denied,
+		"runs":            runs,
+		"count":           len(runs),
+		"failed_count":    summary.Failed,
+		"success_count":   summary.Success,
+		"running_count":   summary.Running,
+		"pending_count":   summary.Pending,
+		"queued_count":    summary.Queued,
+		"canceled_count":  summary.Canceled,
+		"denied_count":    summary.Denied,
+		"timed_out_count": summary.TimedOut,
 	})
 }
 
@@ -731,6 +809,7 @@ type runSummary struct {
 	Failed   int
 	Canceled int
 	Denied   int
+	TimedOut int
 }
 
 func summarizeRuns(runs []JobRun) runSummary {
@@ -751,6 +830,8 @@ func summarizeRuns(runs []JobRun) runSummary {
 			summary.Canceled++
 		case RunStatusDenied:
 			summary.Denied++
+		case RunStatusTimedOut:
+			summary.TimedOut++
 		}
 	}
 	return summary
@@ -771,10 +852,10 @@ func parseRunQuery(r *http.Request) (RunQuery, error) {
 
 	if status := strings.TrimSpace(r.URL.Query().Get("status")); status != "" {
 		switch status {
-		case RunStatusQueued, RunStatusPending, RunStatusRunning, RunStatusSuccess, RunStatusFailed, RunStatusCanceled, RunStatusDenied:
+		case RunStatusQueued, RunStatusPending, RunStatusRunning, RunStatusSuccess, RunStatusFailed, RunStatusCanceled, RunStatusDenied, RunStatusTimedOut:
 			query.Status = status
 		default:
-			return RunQuery{}, fmt.Errorf("status must be one of: queued, pending, running, success, failed, canceled, denied")
+			return RunQuery{}, fmt.Errorf("status must be one of: queued, pending, running, success, failed, canceled, denied, timed_out")
 		}
 	}
 
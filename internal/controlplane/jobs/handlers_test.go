@@ -510,7 +510,7 @@ func TestHandleRetryRunRejectsNonFailedRun(t *testing.T) {
 	if rr.Code != http.StatusConflict {
 		t.Fatalf("expected 409 for retry on successful run, got %d body=%s", rr.Code, rr.Body.String())
 	}
-	if !strings.Contains(rr.Body.String(), "only failed, canceled, or denied") {
+	if !strings.Contains(rr.Body.String(), "only failed, canceled, denied, or timed_out") {
 		t.Fatalf("unexpected body: %s", rr.Body.String())
 	}
 }
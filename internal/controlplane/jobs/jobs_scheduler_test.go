@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -59,6 +60,94 @@ func TestIsScheduleDueCron(t *testing.T) {
 	}
 }
 
+func TestNextScheduledRunFollowsTimezoneAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		schedule string
+		last     time.Time
+		want     time.Time
+	}{
+		{
+			name:     "spring forward keeps 09:00 local",
+			schedule: "0 9 * * *",
+			last:     time.Date(2026, 3, 7, 14, 0, 0, 0, time.UTC), // 09:00 EST
+			want:     time.Date(2026, 3, 8, 13, 0, 0, 0, time.UTC), // 09:00 EDT
+		},
+		{
+			name:     "fall back keeps 09:00 local",
+			schedule: "0 9 * * *",
+			last:     time.Date(2026, 10, 31, 13, 0, 0, 0, time.UTC), // 09:00 EDT
+			want:     time.Date(2026, 11, 1, 14, 0, 0, 0, time.UTC),  // 09:00 EST
+		},
+		{
+			name:     "skipped wall-clock time moves to next day",
+			schedule: "30 2 * * *",
+			last:     time.Date(2026, 3, 7, 7, 30, 0, 0, time.UTC),
+			want:     time.Date(2026, 3, 9, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "repeated wall-clock time fires once",
+			schedule: "30 1 * * *",
+			last:     time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+			want:     time.Date(2026, 11, 2, 6, 30, 0, 0, time.UTC), // 01:30 EST next day
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			last := tc.last
+			got, err := nextScheduledRun(tc.schedule, loc, &last, last, last)
+			if err != nil {
+				t.Fatalf("nextScheduledRun: %v", err)
+			}
+			if !got.Equal(tc.want) {
+				t.Fatalf("expected %s, got %s (%s local)", tc.want, got, got.In(loc))
+			}
+		})
+	}
+
+	// Without a timezone the same spec is evaluated in UTC.
+	last := time.Date(2026, 3, 7, 14, 0, 0, 0, time.UTC)
+	got, err := nextScheduledRun("0 9 * * *", nil, &last, last, last)
+	if err != nil {
+		t.Fatalf("nextScheduledRun utc: %v", err)
+	}
+	if want := time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestJobWithNextRunAndTimezoneValidation(t *testing.T) {
+	if err := validateTimezone("Mars/Olympus_Mons"); err == nil {
+		t.Fatal("expected unknown timezone to be rejected")
+	}
+	if err := validateTimezone(""); err != nil {
+		t.Fatalf("expected empty timezone to mean UTC, got %v", err)
+	}
+	if err := validateTimezone("Europe/London"); err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	last := time.Date(2026, 7, 1, 7, 0, 0, 0, time.UTC)
+	job := Job{Schedule: "0 8 * * *", Timezone: "Europe/London", Enabled: true, LastRunAt: &last}
+	got := job.withNextRun(last)
+	if got.NextRunAt == nil || !got.NextRunAt.Equal(time.Date(2026, 7, 2, 7, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected next run 2026-07-02T07:00Z, got %v", got.NextRunAt)
+	}
+	if got.NextRunLocal != "2026-07-02T08:00:00+01:00" {
+		t.Fatalf("expected local next run in BST, got %q", got.NextRunLocal)
+	}
+
+	job.Enabled = false
+	if job.withNextRun(last).NextRunAt != nil {
+		t.Fatal("expected no next run for disabled job")
+	}
+}
+
 func TestSchedulerTriggerNowRecordsRun(t *testing.T) {
 	store, err := NewStore(filepath.Join(t.TempDir(), "jobs.db"))
 	if err != nil {
@@ -133,6 +222,52 @@ func TestSchedulerTriggerNowRecordsRun(t *testing.T) {
 	t.Fatalf("expected successful run to be recorded, got %#v", runs)
 }
 
+func TestSchedulerTriggerNowDeniesDrainingProbe(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer store.Close()
+
+	fleetMgr := fleet.NewManager(zap.NewNop())
+	fleetMgr.Register("probe-1", "probe-1", "linux", "amd64")
+	if err := fleetMgr.SetOnline("probe-1"); err != nil {
+		t.Fatalf("set online: %v", err)
+	}
+	if err := fleetMgr.SetDraining("probe-1", true); err != nil {
+		t.Fatalf("set draining: %v", err)
+	}
+
+	sender := &fakeSender{
+		sendFn: func(probeID string, msgType protocol.MessageType, payload any) error {
+			return fmt.Errorf("unexpected dispatch to draining probe %s", probeID)
+		},
+	}
+	scheduler := NewScheduler(store, sender, fleetMgr, newFakeTracker(), zap.NewNop())
+
+	job, err := store.CreateJob(Job{
+		Name:     "drained",
+		Command:  "echo hi",
+		Schedule: "1h",
+		Target:   Target{Kind: TargetKindProbe, Value: "probe-1"},
+		Enabled:  false,
+	})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if err := scheduler.TriggerNow(job.ID); err != nil {
+		t.Fatalf("trigger now: %v", err)
+	}
+
+	runs, err := store.ListRunsByJob(job.ID, 50)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Status != RunStatusDenied || runs[0].AdmissionReason != "probe draining" {
+		t.Fatalf("expected one denied run for draining probe, got %#v", runs)
+	}
+}
+
 func TestSchedulerEmitsRunLifecycleCorrelationMetadata(t *testing.T) {
 	store := newTestStore(t)
 
@@ -965,3 +1100,487 @@ func (f *fakeTracker) complete(requestID string, payload *protocol.CommandResult
 		pc.Result <- payload
 	}
 }
+
+func TestSchedulerDryRunEvaluatesAdmissionWithoutDispatch(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer store.Close()
+
+	fleetMgr := fleet.NewManager(zap.NewNop())
+	for _, id := range []string{"probe-1", "probe-2", "probe-3"} {
+		fleetMgr.Register(id, id, "linux", "amd64")
+		if err := fleetMgr.SetTags(id, []string{"web"}); err != nil {
+			t.Fatalf("set tags: %v", err)
+		}
+	}
+	for _, id := range []string{"probe-1", "probe-2"} {
+		if err := fleetMgr.SetOnline(id); err != nil {
+			t.Fatalf("set online: %v", err)
+		}
+	}
+	if err := fleetMgr.SetStatus("probe-3", "offline"); err != nil {
+		t.Fatalf("set offline: %v", err)
+	}
+
+	var sends int
+	sender := &fakeSender{
+		sendFn: func(string, protocol.MessageType, any) error {
+			sends++
+			return nil
+		},
+	}
+	scheduler := NewScheduler(store, sender, fleetMgr, newFakeTracker(), zap.NewNop(),
+		WithAdmissionEvaluator(JobAdmissionEvaluatorFunc(func(_ context.Context, _ Job, probeID string) JobAdmissionDecision {
+			if probeID == "probe-2" {
+				return JobAdmissionDecision{Outcome: AdmissionOutcomeQueue, Reason: "approval required"}
+			}
+			return JobAdmissionDecision{Outcome: AdmissionOutcomeAllow}
+		})),
+	)
+
+	job, err := store.CreateJob(Job{
+		Name:     "restart web",
+		Command:  "systemctl restart nginx",
+		Schedule: "1h",
+		Target:   Target{Kind: TargetKindTag, Value: "web"},
+	})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	result, err := scheduler.DryRun(job.ID)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !result.Simulation || len(result.Targets) != 3 {
+		t.Fatalf("unexpected dry run result: %+v", result)
+	}
+	want := DryRunSummary{WouldRun: 1, Queued: 1, Offline: 1}
+	if result.Summary != want {
+		t.Fatalf("expected summary %+v, got %+v", want, result.Summary)
+	}
+	for _, target := range result.Targets {
+		if target.Status != DryRunStatus {
+			t.Fatalf("expected dry-run status, got %+v", target)
+		}
+	}
+	if result.Targets[1].Admission != AdmissionOutcomeQueue || result.Targets[1].Reason != "approval required" {
+		t.Fatalf("expected probe-2 queued, got %+v", result.Targets[1])
+	}
+
+	if sends != 0 {
+		t.Fatalf("expected no commands dispatched, got %d", sends)
+	}
+	runs, err := store.ListRunsByJob(job.ID, 50)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("expected no runs recorded, got %d", len(runs))
+	}
+}
+
+func TestSchedulerGatesJobOnDependencies(t *testing.T) {
+	store := newTestStore(t)
+	createdAt := time.Now().UTC().Add(-time.Hour)
+
+	parent, err := store.CreateJob(Job{
+		Name:      "snapshot",
+		Command:   "echo snapshot",
+		Schedule:  "5m",
+		Target:    Target{Kind: TargetKindAll},
+		Enabled:   false,
+		CreatedAt: createdAt,
+	})
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	child, err := store.CreateJob(Job{
+		Name:      "report",
+		Command:   "echo report",
+		Schedule:  "5m",
+		Target:    Target{Kind: TargetKindAll},
+		DependsOn: []string{parent.ID},
+		Enabled:   true,
+		CreatedAt: createdAt,
+	})
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+
+	var (
+		mu     sync.Mutex
+		events []LifecycleEvent
+	)
+	scheduler := NewScheduler(store, &fakeSender{}, fleet.NewManager(zap.NewNop()), newFakeTracker(), zap.NewNop(),
+		WithDependencyWaitTimeout(10*time.Minute),
+		WithLifecycleObserver(LifecycleObserverFunc(func(evt LifecycleEvent) {
+			mu.Lock()
+			events = append(events, evt)
+			mu.Unlock()
+		})),
+	)
+
+	now := time.Now().UTC()
+	scheduler.runOnce(now)
+
+	blocked := findLifecycleEvent(events, EventJobRunBlocked)
+	if blocked == nil || blocked.JobID != child.ID {
+		t.Fatalf("expected blocked event for child, got %+v", events)
+	}
+	if len(blocked.BlockedOn) != 1 || blocked.BlockedOn[0] != parent.ID {
+		t.Fatalf("expected blocked_on [%s], got %v", parent.ID, blocked.BlockedOn)
+	}
+	state := scheduler.DependencyState(*child)
+	if state == nil || !state.Blocked || state.BlockedSince == nil {
+		t.Fatalf("expected blocked dependency state, got %+v", state)
+	}
+
+	// Still blocked on the next tick: no duplicate event.
+	scheduler.runOnce(now.Add(30 * time.Second))
+	if got := countLifecycleEvents(events, EventJobRunBlocked); got != 1 {
+		t.Fatalf("expected one blocked event, got %d", got)
+	}
+
+	if err := store.SkipJobCycle(parent.ID, RunStatusSuccess, now.Add(time.Minute)); err != nil {
+		t.Fatalf("mark parent success: %v", err)
+	}
+	scheduler.runOnce(now.Add(2 * time.Minute))
+	if findLifecycleEvent(events, EventJobRunUnblocked) == nil {
+		t.Fatalf("expected unblocked event, got %+v", events)
+	}
+	if state := scheduler.DependencyState(*child); state == nil || state.Blocked {
+		t.Fatalf("expected unblocked dependency state, got %+v", state)
+	}
+}
+
+func TestSchedulerSkipsCycleAfterDependencyTimeout(t *testing.T) {
+	store := newTestStore(t)
+	createdAt := time.Now().UTC().Add(-time.Hour)
+
+	parent := createTestJob(t, store)
+	child, err := store.CreateJob(Job{
+		Name:              "report",
+		Command:           "echo report",
+		Schedule:          "5m",
+		Target:            Target{Kind: TargetKindAll},
+		DependsOn:         []string{parent.ID},
+		DependencyTimeout: "1m",
+		Enabled:           true,
+		CreatedAt:         createdAt,
+	})
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	if _, err := store.SetEnabled(parent.ID, false); err != nil {
+		t.Fatalf("disable parent: %v", err)
+	}
+
+	var events []LifecycleEvent
+	scheduler := NewScheduler(store, &fakeSender{}, fleet.NewManager(zap.NewNop()), newFakeTracker(), zap.NewNop(),
+		WithLifecycleObserver(LifecycleObserverFunc(func(evt LifecycleEvent) {
+			events = append(events, evt)
+		})),
+	)
+
+	now := time.Now().UTC()
+	scheduler.runOnce(now)
+	scheduler.runOnce(now.Add(2 * time.Minute))
+
+	if findLifecycleEvent(events, EventJobRunDependencyTimeout) == nil {
+		t.Fatalf("expected dependency timeout event, got %+v", events)
+	}
+	got, err := store.GetJob(child.ID)
+	if err != nil {
+		t.Fatalf("get child: %v", err)
+	}
+	if got.LastStatus != JobStatusDependencyTimeout || got.LastRunAt == nil {
+		t.Fatalf("expected skipped cycle with dependency_timeout status, got %q (%v)", got.LastStatus, got.LastRunAt)
+	}
+	if state := scheduler.DependencyState(*got); state == nil || state.Blocked {
+		t.Fatalf("expected block cleared after timeout, got %+v", state)
+	}
+}
+
+func countLifecycleEvents(events []LifecycleEvent, want LifecycleEventType) int {
+	count := 0
+	for _, evt := range events {
+		if evt.Type == want {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSchedulerTimesOutRunAndDiscardsLateResult(t *testing.T) {
+	store := newTestStore(t)
+
+	fleetMgr := fleet.NewManager(zap.NewNop())
+	fleetMgr.Register("probe-1", "probe-1", "linux", "amd64")
+	if err := fleetMgr.SetOnline("probe-1"); err != nil {
+		t.Fatalf("set online: %v", err)
+	}
+
+	tracker := newFakeTracker()
+	var (
+		sendMu     sync.Mutex
+		requestID  string
+		cancelSent bool
+		timeoutArg time.Duration
+	)
+	sender := &fakeSender{
+		sendFn: func(probeID string, msgType protocol.MessageType, payload any) error {
+			sendMu.Lock()
+			defer sendMu.Unlock()
+			switch msgType {
+			case protocol.MsgCommand:
+				cmd := payload.(protocol.CommandPayload)
+				requestID = cmd.RequestID
+				timeoutArg = cmd.Timeout
+			case protocol.MsgCommandCancel:
+				cancel := payload.(protocol.CommandCancelPayload)
+				cancelSent = cancel.RequestID == requestID
+			}
+			// Probe never answers the command.
+			return nil
+		},
+	}
+
+	var (
+		mu     sync.Mutex
+		events []LifecycleEvent
+	)
+	scheduler := NewScheduler(store, sender, fleetMgr, tracker, zap.NewNop(),
+		WithLifecycleObserver(LifecycleObserverFunc(func(evt LifecycleEvent) {
+			mu.Lock()
+			events = append(events, evt)
+			mu.Unlock()
+		})),
+	)
+	scheduler.runTimeoutGrace = 0
+
+	job, err := store.CreateJob(Job{
+		Name:     "hangs",
+		Command:  "sleep 600",
+		Schedule: "1h",
+		Target:   Target{Kind: TargetKindProbe, Value: "probe-1"},
+		Timeout:  "100ms",
+		Enabled:  false,
+	})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	if err := scheduler.TriggerNow(job.ID); err != nil {
+		t.Fatalf("trigger now: %v", err)
+	}
+	waitForLifecycleEvent(t, &mu, &events, EventJobRunTimedOut, 2*time.Second)
+
+	sendMu.Lock()
+	if !cancelSent {
+		t.Fatal("expected command_cancel to be sent to the probe")
+	}
+	if timeoutArg != 100*time.Millisecond {
+		t.Fatalf("expected probe command timeout 100ms, got %s", timeoutArg)
+	}
+	lateID := requestID
+	sendMu.Unlock()
+
+	// Probe reconnects and reports a late result: it must not complete the run.
+	tracker.complete(lateID, &protocol.CommandResultPayload{RequestID: lateID, ExitCode: 0, Stdout: "late"})
+
+	runs, err := store.ListRunsByJob(job.ID, 10)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Status != RunStatusTimedOut {
+		t.Fatalf("expected one timed_out run, got %#v", runs)
+	}
+	if !strings.Contains(runs[0].Output, "timed out") {
+		t.Fatalf("expected timeout output, got %q", runs[0].Output)
+	}
+}
+
+func newConcurrencyTestScheduler(t *testing.T, store *Store) (*Scheduler, *fakeTracker, *[]string, *sync.Mutex, *[]LifecycleEvent) {
+	t.Helper()
+	fleetMgr := fleet.NewManager(zap.NewNop())
+	fleetMgr.Register("probe-1", "probe-1", "linux", "amd64")
+	if err := fleetMgr.SetOnline("probe-1"); err != nil {
+		t.Fatalf("set online: %v", err)
+	}
+
+	var (
+		mu       sync.Mutex
+		canceled []string
+		events   []LifecycleEvent
+	)
+	sender := &fakeSender{sendFn: func(probeID string, msgType protocol.MessageType, payload any) error {
+		if msgType == protocol.MsgCommandCancel {
+			mu.Lock()
+			canceled = append(canceled, payload.(protocol.CommandCancelPayload).RequestID)
+			mu.Unlock()
+		}
+		return nil
+	}}
+	tracker := newFakeTracker()
+	scheduler := NewScheduler(store, sender, fleetMgr, tracker, zap.NewNop(),
+		WithLifecycleObserver(LifecycleObserverFunc(func(evt LifecycleEvent) {
+			mu.Lock()
+			events = append(events, evt)
+			mu.Unlock()
+		})),
+	)
+	return scheduler, tracker, &canceled, &mu, &events
+}
+
+func completeActiveRuns(t *testing.T, store *Store, tracker *fakeTracker, jobID string) {
+	t.Helper()
+	runs, err := store.ListActiveRunsByJob(jobID)
+	if err != nil {
+		t.Fatalf("list active runs: %v", err)
+	}
+	for _, run := range runs {
+		tracker.complete(run.RequestID, &protocol.CommandResultPayload{RequestID: run.RequestID, ExitCode: 0})
+	}
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestSchedulerForbidSkipsCycleWhileRunActive(t *testing.T) {
+	store := newTestStore(t)
+	scheduler, tracker, _, mu, events := newConcurrencyTestScheduler(t, store)
+
+	now := time.Now().UTC()
+	job, err := store.CreateJob(Job{
+		Name:              "slow",
+		Command:           "sleep 300",
+		Schedule:          "1m",
+		Target:            Target{Kind: TargetKindProbe, Value: "probe-1"},
+		ConcurrencyPolicy: "Forbid",
+		Enabled:           true,
+		CreatedAt:         now.Add(-2 * time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	if job.ConcurrencyPolicy != ConcurrencyPolicyForbid {
+		t.Fatalf("expected normalized policy, got %q", job.ConcurrencyPolicy)
+	}
+	defer completeActiveRuns(t, store, tracker, job.ID)
+
+	scheduler.runOnce(now)
+	scheduler.runOnce(now.Add(2 * time.Minute))
+
+	runs, err := store.ListRunsByJob(job.ID, 10)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected the second cycle to be skipped, got %d runs", len(runs))
+	}
+	got, err := store.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if got.LastStatus != JobStatusSkippedConcurrent || got.LastSkippedAt == nil || !strings.Contains(got.LastSkipReason, "probe-1") {
+		t.Fatalf("expected skipped cycle recorded on job, got status=%q skipped_at=%v reason=%q", got.LastStatus, got.LastSkippedAt, got.LastSkipReason)
+	}
+	if !got.LastRunAt.Equal(now.Add(2 * time.Minute)) {
+		t.Fatalf("expected skipped cycle to advance last_run_at, got %v", got.LastRunAt)
+	}
+
+	mu.Lock()
+	skippedEvt := findLifecycleEvent(*events, EventJobRunSkipped)
+	mu.Unlock()
+	if skippedEvt == nil || skippedEvt.ProbeID != "probe-1" || skippedEvt.Reason == "" {
+		t.Fatalf("expected job.run.skipped event with probe and reason, got %+v", skippedEvt)
+	}
+}
+
+func TestSchedulerReplaceCancelsActiveRun(t *testing.T) {
+	store := newTestStore(t)
+	scheduler, tracker, canceled, mu, _ := newConcurrencyTestScheduler(t, store)
+
+	job, err := store.CreateJob(Job{
+		Name:              "replace",
+		Command:           "sleep 300",
+		Schedule:          "1h",
+		Target:            Target{Kind: TargetKindProbe, Value: "probe-1"},
+		ConcurrencyPolicy: ConcurrencyPolicyReplace,
+		Enabled:           true,
+	})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	defer completeActiveRuns(t, store, tracker, job.ID)
+
+	if err := scheduler.TriggerNow(job.ID); err != nil {
+		t.Fatalf("first trigger: %v", err)
+	}
+	first, err := store.ListActiveRunsByJob(job.ID)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("expected one active run, got %d (%v)", len(first), err)
+	}
+	if err := scheduler.TriggerNow(job.ID); err != nil {
+		t.Fatalf("second trigger: %v", err)
+	}
+
+	old, err := store.GetRun(first[0].ID)
+	if err != nil {
+		t.Fatalf("get run: %v", err)
+	}
+	if old.Status != RunStatusCanceled {
+		t.Fatalf("expected replaced run canceled, got %s", old.Status)
+	}
+	active, err := store.ListActiveRunsByJob(job.ID)
+	if err != nil || len(active) != 1 || active[0].ID == old.ID {
+		t.Fatalf("expected a new active run, got %+v (%v)", active, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(*canceled) != 1 || (*canceled)[0] != old.RequestID {
+		t.Fatalf("expected command_cancel for the replaced run, got %v", *canceled)
+	}
+}
+
+func TestSchedulerAllowRunsConcurrently(t *testing.T) {
+	store := newTestStore(t)
+	scheduler, tracker, _, _, _ := newConcurrencyTestScheduler(t, store)
+
+	if _, err := store.CreateJob(Job{
+		Name:              "bad",
+		Command:           "true",
+		Schedule:          "1h",
+		Target:            Target{Kind: TargetKindAll},
+		ConcurrencyPolicy: "sometimes",
+	}); err == nil || !strings.Contains(err.Error(), "concurrency_policy") {
+		t.Fatalf("expected invalid concurrency_policy error, got %v", err)
+	}
+
+	job, err := store.CreateJob(Job{
+		Name:              "allow",
+		Command:           "sleep 300",
+		Schedule:          "1h",
+		Target:            Target{Kind: TargetKindProbe, Value: "probe-1"},
+		ConcurrencyPolicy: ConcurrencyPolicyAllow,
+		Enabled:           true,
+	})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	defer completeActiveRuns(t, store, tracker, job.ID)
+
+	for i := 0; i < 2; i++ {
+		if err := scheduler.TriggerNow(job.ID); err != nil {
+			t.Fatalf("trigger %d: %v", i, err)
+		}
+	}
+	active, err := store.ListActiveRunsByJob(job.ID)
+	if err != nil {
+		t.Fatalf("list active runs: %v", err)
+	}
+	if len(active) != 2 {
+		t.Fatalf("expected two overlapping runs, got %d", len(active))
+	}
+}
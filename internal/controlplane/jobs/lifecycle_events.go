@@ -10,19 +10,24 @@ import (
 type LifecycleEventType string
 
 const (
-	EventJobCreated             LifecycleEventType = "job.created"
-	EventJobUpdated             LifecycleEventType = "job.updated"
-	EventJobDeleted             LifecycleEventType = "job.deleted"
-	EventJobRunAdmissionAllowed LifecycleEventType = "job.run.admission_allowed"
-	EventJobRunAdmissionQueued  LifecycleEventType = "job.run.admission_queued"
-	EventJobRunAdmissionDenied  LifecycleEventType = "job.run.admission_denied"
-	EventJobRunQueued           LifecycleEventType = "job.run.queued"
-	EventJobRunStarted          LifecycleEventType = "job.run.started"
-	EventJobRunRetryScheduled   LifecycleEventType = "job.run.retry_scheduled"
-	EventJobRunSucceeded        LifecycleEventType = "job.run.succeeded"
-	EventJobRunFailed           LifecycleEventType = "job.run.failed"
-	EventJobRunCanceled         LifecycleEventType = "job.run.canceled"
-	EventJobRunDenied           LifecycleEventType = "job.run.denied"
+	EventJobCreated              LifecycleEventType = "job.created"
+	EventJobUpdated              LifecycleEventType = "job.updated"
+	EventJobDeleted              LifecycleEventType = "job.deleted"
+	EventJobRunAdmissionAllowed  LifecycleEventType = "job.run.admission_allowed"
+	EventJobRunAdmissionQueued   LifecycleEventType = "job.run.admission_queued"
+	EventJobRunAdmissionDenied   LifecycleEventType = "job.run.admission_denied"
+	EventJobRunQueued            LifecycleEventType = "job.run.queued"
+	EventJobRunStarted           LifecycleEventType = "job.run.started"
+	EventJobRunRetryScheduled    LifecycleEventType = "job.run.retry_scheduled"
+	EventJobRunSucceeded         LifecycleEventType = "job.run.succeeded"
+	EventJobRunFailed            LifecycleEventType = "job.run.failed"
+	EventJobRunCanceled          LifecycleEventType = "job.run.canceled"
+	EventJobRunDenied            LifecycleEventType = "job.run.denied"
+	EventJobRunBlocked           LifecycleEventType = "job.run.blocked"
+	EventJobRunUnblocked         LifecycleEventType = "job.run.unblocked"
+	EventJobRunDependencyTimeout LifecycleEventType = "job.run.dependency_timeout"
+	EventJobRunTimedOut          LifecycleEventType = "job.run.timed_out"
+	EventJobRunSkipped           LifecycleEventType = "job.run.skipped"
 )
 
 // LifecycleEvent carries job/run correlation metadata for audit + SSE consumers.
@@ -41,6 +46,8 @@ type LifecycleEvent struct {
 	AdmissionReason    string             `json:"admission_reason,omitempty"`
 	AdmissionRationale any                `json:"admission_rationale,omitempty"`
 	DeferredUntil      *time.Time         `json:"deferred_until,omitempty"`
+	BlockedOn          []string           `json:"blocked_on,omitempty"`
+	Reason             string             `json:"reason,omitempty"`
 }
 
 // CorrelationMetadata exposes stable correlation keys for audit detail/event payloads.
@@ -79,6 +86,12 @@ func (e LifecycleEvent) CorrelationMetadata() map[string]any {
 	if e.DeferredUntil != nil && !e.DeferredUntil.IsZero() {
 		meta["deferred_until"] = e.DeferredUntil.UTC().Format(time.RFC3339Nano)
 	}
+	if len(e.BlockedOn) > 0 {
+		meta["blocked_on"] = append([]string(nil), e.BlockedOn...)
+	}
+	if reason := strings.TrimSpace(e.Reason); reason != "" {
+		meta["reason"] = reason
+	}
 	return meta
 }
 
@@ -116,6 +129,16 @@ func (e LifecycleEvent) Summary() string {
 		return fmt.Sprintf("Job run canceled: %s", target)
 	case EventJobRunDenied:
 		return fmt.Sprintf("Job run denied: %s", target)
+	case EventJobRunBlocked:
+		return fmt.Sprintf("Job run blocked on dependencies: %s", target)
+	case EventJobRunUnblocked:
+		return fmt.Sprintf("Job run unblocked: %s", target)
+	case EventJobRunDependencyTimeout:
+		return fmt.Sprintf("Job run dependency wait timed out: %s", target)
+	case EventJobRunTimedOut:
+		return fmt.Sprintf("Job run timed out: %s", target)
+	case EventJobRunSkipped:
+		return fmt.Sprintf("Job run skipped, previous run still active: %s", target)
 	default:
 		return fmt.Sprintf("Job event: %s", target)
 	}
@@ -18,6 +18,7 @@ import (
 
 const (
 	defaultCommandTimeout      = 60 * time.Second
+	defaultRunTimeoutGrace     = 15 * time.Second
 	defaultAdmissionRetryDelay = 30 * time.Second
 )
 
@@ -91,6 +92,16 @@ func WithAdmissionEvaluator(evaluator JobAdmissionEvaluator) SchedulerOption {
 	}
 }
 
+// WithDefaultRunTimeout sets the per-attempt timeout for jobs that do not set
+// their own timeout.
+func WithDefaultRunTimeout(timeout time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		if timeout > 0 {
+			s.defaultRunTimeout = timeout
+		}
+	}
+}
+
 // WithAdmissionRetryDelay overrides the default queue re-evaluation delay.
 func WithAdmissionRetryDelay(delay time.Duration) SchedulerOption {
 	return func(s *Scheduler) {
@@ -108,19 +119,23 @@ type Scheduler struct {
 	tracker trackable
 	logger  *zap.Logger
 
-	mu                  sync.Mutex
-	cancel              context.CancelFunc
-	ticker              *time.Ticker
-	inFlight            map[string]string // request_id -> run_id
-	runRequest          map[string]string // run_id -> request_id
-	requestTarget       map[string]string // request_id -> jobID::probeID
-	activeTargets       map[string]struct{}
-	pendingRetryCancel  map[string]context.CancelFunc // jobID::probeID -> retry/admission retry cancel
-	defaultRetryPolicy  RetryPolicy
-	lifecycleObserver   LifecycleObserver
-	admissionEvaluator  JobAdmissionEvaluator
-	admissionRetryDelay time.Duration
-	wg                  sync.WaitGroup
+	mu                    sync.Mutex
+	cancel                context.CancelFunc
+	ticker                *time.Ticker
+	inFlight              map[string]string // request_id -> run_id
+	runRequest            map[string]string // run_id -> request_id
+	requestTarget         map[string]string // request_id -> jobID::probeID
+	activeTargets         map[string]struct{}
+	pendingRetryCancel    map[string]context.CancelFunc // jobID::probeID -> retry/admission retry cancel
+	defaultRetryPolicy    RetryPolicy
+	lifecycleObserver     LifecycleObserver
+	admissionEvaluator    JobAdmissionEvaluator
+	admissionRetryDelay   time.Duration
+	defaultRunTimeout     time.Duration
+	runTimeoutGrace       time.Duration // lets the probe's own kill report first
+	dependencyWaitTimeout time.Duration
+	dependencyBlocks      map[string]dependencyBlock // job_id -> blocked-on-dependency state
+	wg                    sync.WaitGroup
 }
 
 // NewScheduler creates a recurring job scheduler.
@@ -129,20 +144,24 @@ func NewScheduler(store *Store, hub sender, fleetMgr fleet.Fleet, tracker tracka
 		logger = zap.NewNop()
 	}
 	s := &Scheduler{
-		store:               store,
-		hub:                 hub,
-		fleet:               fleetMgr,
-		tracker:             tracker,
-		logger:              logger,
-		inFlight:            make(map[string]string),
-		runRequest:          make(map[string]string),
-		requestTarget:       make(map[string]string),
-		activeTargets:       make(map[string]struct{}),
-		pendingRetryCancel:  make(map[string]context.CancelFunc),
-		defaultRetryPolicy:  RetryPolicy{},
-		lifecycleObserver:   noopLifecycleObserver{},
-		admissionEvaluator:  JobAdmissionEvaluatorFunc(nil),
-		admissionRetryDelay: defaultAdmissionRetryDelay,
+		store:                 store,
+		hub:                   hub,
+		fleet:                 fleetMgr,
+		tracker:               tracker,
+		logger:                logger,
+		inFlight:              make(map[string]string),
+		runRequest:            make(map[string]string),
+		requestTarget:         make(map[string]string),
+		activeTargets:         make(map[string]struct{}),
+		pendingRetryCancel:    make(map[string]context.CancelFunc),
+		defaultRetryPolicy:    RetryPolicy{},
+		lifecycleObserver:     noopLifecycleObserver{},
+		admissionEvaluator:    JobAdmissionEvaluatorFunc(nil),
+		admissionRetryDelay:   defaultAdmissionRetryDelay,
+		defaultRunTimeout:     defaultCommandTimeout,
+		runTimeoutGrace:       defaultRunTimeoutGrace,
+		dependencyWaitTimeout: defaultDependencyWaitTimeout,
+		dependencyBlocks:      make(map[string]dependencyBlock),
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -214,13 +233,20 @@ func (s *Scheduler) Stop() {
 	s.wg.Wait()
 }
 
-// TriggerNow executes a job immediately, regardless of schedule.
+// TriggerNow executes a job immediately, regardless of schedule or
+// dependencies. The job's concurrency policy still applies, but a skipped
+// manual trigger does not consume a scheduled cycle.
 func (s *Scheduler) TriggerNow(jobID string) error {
 	job, err := s.store.GetJob(jobID)
 	if err != nil {
 		return err
 	}
-	return s.dispatchJob(*job, time.Now().UTC())
+	now := time.Now().UTC()
+	_, skipped, err := s.dispatchJob(*job, now)
+	if len(skipped) > 0 {
+		s.recordSkippedTrigger(*job, skipped, false, now)
+	}
+	return err
 }
 
 type CancelJobSummary struct {
@@ -296,6 +322,7 @@ func (s *Scheduler) CancelRun(jobID, runID string) (*JobRun, error) {
 		s.tracker.Cancel(requestID)
 	}
 	s.cancelScheduledRetry(inFlightTargetKey(run.JobID, run.ProbeID))
+	s.cancelScheduledRetry(executionTargetKey(run.JobID, run.ProbeID, run.ExecutionID))
 	return s.store.GetRun(runID)
 }
 
@@ -310,11 +337,17 @@ func (s *Scheduler) runOnce(now time.Time) {
 		return
 	}
 
+	jobsByID := make(map[string]Job, len(jobs))
+	for _, job := range jobs {
+		jobsByID[job.ID] = job
+	}
+
 	for _, job := range jobs {
 		if !job.Enabled {
+			s.forgetDependencyBlock(job.ID)
 			continue
 		}
-		due, err := isScheduleDue(job.Schedule, job.LastRunAt, job.CreatedAt, now)
+		due, err := isScheduleDueIn(job.Schedule, job.location(), job.LastRunAt, job.CreatedAt, now)
 		if err != nil {
 			s.logger.Warn("invalid job schedule",
 				zap.String("job_id", job.ID),
@@ -326,39 +359,73 @@ func (s *Scheduler) runOnce(now time.Time) {
 		if !due {
 			continue
 		}
+		if !s.gateDependencies(job, jobsByID, now) {
+			continue
+		}
 
-		if err := s.dispatchJob(job, now); err != nil {
+		dispatched, skipped, err := s.dispatchJob(job, now)
+		if err != nil {
 			s.logger.Warn("dispatch scheduled job failed", zap.String("job_id", job.ID), zap.Error(err))
 		}
+		if len(skipped) > 0 {
+			s.recordSkippedTrigger(job, skipped, dispatched == 0, now)
+		}
 	}
 }
 
-func (s *Scheduler) dispatchJob(job Job, now time.Time) error {
+// dispatchJob starts a run on every target probe, applying the job's
+// concurrency policy where a previous run is still active. It returns how
+// many probes were dispatched and which were skipped.
+func (s *Scheduler) dispatchJob(job Job, now time.Time) (int, []string, error) {
 	probeIDs := s.resolveTargets(job.Target)
 	if len(probeIDs) == 0 {
-		return fmt.Errorf("no probes resolved for target")
+		return 0, nil, fmt.Errorf("no probes resolved for target")
 	}
 
 	policy, err := resolveRetryPolicy(job.RetryPolicy, s.defaultRetryPolicy)
 	if err != nil {
-		return fmt.Errorf("resolve retry policy: %w", err)
+		return 0, nil, fmt.Errorf("resolve retry policy: %w", err)
 	}
 
+	concurrency := job.effectiveConcurrencyPolicy()
+	dispatched := 0
+	var skipped []string
 	for _, probeID := range probeIDs {
+		executionID := fmt.Sprintf("jobexec-%s-%s-%d", job.ID, probeID, now.UnixNano())
 		targetKey := inFlightTargetKey(job.ID, probeID)
-		if !s.claimTarget(targetKey) {
+		if concurrency == ConcurrencyPolicyAllow {
+			targetKey = executionTargetKey(job.ID, probeID, executionID)
+		}
+
+		claimed := s.claimTarget(targetKey)
+		if !claimed && concurrency == ConcurrencyPolicyReplace {
+			s.replaceActiveRuns(job, probeID, targetKey)
+			claimed = s.claimTarget(targetKey)
+		}
+		if !claimed {
 			s.logger.Debug("skipping overlapping run for target", zap.String("job_id", job.ID), zap.String("probe_id", probeID))
+			skipped = append(skipped, probeID)
 			continue
 		}
 
-		executionID := fmt.Sprintf("jobexec-%s-%s-%d", job.ID, probeID, now.UnixNano())
+		dispatched++
 		s.dispatchAttempt(job, probeID, targetKey, executionID, 1, policy, now, "")
 	}
 
-	return nil
+	return dispatched, skipped, nil
 }
 
 func (s *Scheduler) dispatchAttempt(job Job, probeID, targetKey, executionID string, attempt int, policy resolvedRetryPolicy, now time.Time, queuedRunID string) {
+	// Draining probes accept no new work; record a denied run so the skip is
+	// visible in run history rather than silently dropped.
+	if s.probeDraining(probeID) {
+		s.handleDeniedAdmission(job, probeID, targetKey, executionID, attempt, policy, now, queuedRunID, JobAdmissionDecision{
+			Outcome: AdmissionOutcomeDeny,
+			Reason:  "probe draining",
+		})
+		return
+	}
+
 	decision := s.evaluateAdmission(job, probeID)
 	if decision.Outcome == "" {
 		decision.Outcome = AdmissionOutcomeAllow
@@ -453,7 +520,7 @@ func (s *Scheduler) handleAllowedAdmission(job Job, probeID, targetKey, executio
 		RequestID: requestID,
 		Command:   "/bin/sh",
 		Args:      []string{"-lc", job.Command},
-		Timeout:   defaultCommandTimeout,
+		Timeout:   s.attemptTimeoutFor(job),
 		Level:     protocol.CapObserve,
 		Stream:    true,
 	}
@@ -740,7 +807,20 @@ func (s *Scheduler) awaitRunResult(run JobRun, requestID string, pending *cmdtra
 		return
 	}
 
-	result, ok := <-pending.Result
+	timeout := s.attemptTimeoutFor(job)
+	timer := time.NewTimer(timeout + s.runTimeoutGrace)
+	defer timer.Stop()
+
+	var (
+		result *protocol.CommandResultPayload
+		ok     bool
+	)
+	select {
+	case result, ok = <-pending.Result:
+	case <-timer.C:
+		s.timeoutAttempt(run, requestID, job, policy, targetKey, timeout)
+		return
+	}
 	if !ok || result == nil {
 		if err := s.store.CancelRun(run.ID, "command canceled"); err != nil {
 			if !IsInvalidRunTransition(err) {
@@ -772,9 +852,38 @@ func (s *Scheduler) awaitRunResult(run JobRun, requestID string, pending *cmdtra
 	s.finishAttempt(run, job, policy, targetKey, requestID, true, status, &exitCode, output)
 }
 
+// timeoutAttempt kills a command that produced no result in time. The tracker
+// entry is dropped first so a late result from a reconnecting probe is
+// discarded rather than completing the run.
+func (s *Scheduler) timeoutAttempt(run JobRun, requestID string, job Job, policy resolvedRetryPolicy, targetKey string, timeout time.Duration) {
+	s.tracker.Cancel(requestID)
+	if err := s.hub.SendTo(run.ProbeID, protocol.MsgCommandCancel, protocol.CommandCancelPayload{
+		RequestID: requestID,
+		Reason:    "job run timed out",
+	}); err != nil {
+		s.logger.Debug("send command cancel failed", zap.String("run_id", run.ID), zap.String("probe_id", run.ProbeID), zap.Error(err))
+	}
+	s.logger.Warn("job run timed out",
+		zap.String("job_id", run.JobID),
+		zap.String("run_id", run.ID),
+		zap.String("probe_id", run.ProbeID),
+		zap.Duration("timeout", timeout),
+	)
+	s.finishAttempt(run, job, policy, targetKey, requestID, true, RunStatusTimedOut, nil, fmt.Sprintf("command timed out after %s", timeout))
+}
+
+func (s *Scheduler) attemptTimeoutFor(job Job) time.Duration {
+	if raw := strings.TrimSpace(job.Timeout); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			return d
+		}
+	}
+	return s.defaultRunTimeout
+}
+
 func (s *Scheduler) finishAttempt(run JobRun, job Job, policy resolvedRetryPolicy, targetKey, requestID string, hadInFlight bool, status string, exitCode *int, output string) {
 	var retryScheduledAt *time.Time
-	if status == RunStatusFailed && run.Attempt < policy.MaxAttempts {
+	if (status == RunStatusFailed || status == RunStatusTimedOut) && run.Attempt < policy.MaxAttempts {
 		delay := policy.nextRetryDelay(run.Attempt)
 		ts := time.Now().UTC().Add(delay)
 		retryScheduledAt = &ts
@@ -798,6 +907,8 @@ func (s *Scheduler) finishAttempt(run JobRun, job Job, policy resolvedRetryPolic
 		terminalType = EventJobRunSucceeded
 	case RunStatusCanceled:
 		terminalType = EventJobRunCanceled
+	case RunStatusTimedOut:
+		terminalType = EventJobRunTimedOut
 	}
 	s.emitLifecycleEvent(LifecycleEvent{
 		Type:        terminalType,
@@ -995,6 +1106,11 @@ func (s *Scheduler) probeOnline(probeID string) bool {
 	return strings.EqualFold(ps.Status, "online")
 }
 
+func (s *Scheduler) probeDraining(probeID string) bool {
+	ps, ok := s.fleet.Get(probeID)
+	return ok && ps != nil && ps.Draining
+}
+
 func (s *Scheduler) resolveTargets(target Target) []string {
 	switch target.Kind {
 	case TargetKindProbe:
@@ -1059,9 +1175,27 @@ func formatResultOutput(result *protocol.CommandResultPayload) string {
 }
 
 func isScheduleDue(schedule string, lastRunAt *time.Time, createdAt, now time.Time) (bool, error) {
+	return isScheduleDueIn(schedule, time.UTC, lastRunAt, createdAt, now)
+}
+
+func isScheduleDueIn(schedule string, loc *time.Location, lastRunAt *time.Time, createdAt, now time.Time) (bool, error) {
+	next, err := nextScheduledRun(schedule, loc, lastRunAt, createdAt, now)
+	if err != nil {
+		return false, err
+	}
+	return !next.After(now.UTC()), nil
+}
+
+// nextScheduledRun returns the next due time (UTC) after the last run, or after
+// creation when the job has never run. Cron specs are evaluated in loc so
+// wall-clock schedules follow that zone across DST changes.
+func nextScheduledRun(schedule string, loc *time.Location, lastRunAt *time.Time, createdAt, now time.Time) (time.Time, error) {
 	schedule = strings.TrimSpace(schedule)
 	if schedule == "" {
-		return false, fmt.Errorf("schedule is required")
+		return time.Time{}, fmt.Errorf("schedule is required")
+	}
+	if loc == nil {
+		loc = time.UTC
 	}
 
 	anchor := createdAt.UTC()
@@ -1074,15 +1208,25 @@ func isScheduleDue(schedule string, lastRunAt *time.Time, createdAt, now time.Ti
 
 	if interval, err := time.ParseDuration(schedule); err == nil {
 		if interval <= 0 {
-			return false, fmt.Errorf("interval must be > 0")
+			return time.Time{}, fmt.Errorf("interval must be > 0")
 		}
-		return !anchor.Add(interval).After(now.UTC()), nil
+		return anchor.Add(interval), nil
 	}
 
 	spec, err := cron.ParseStandard(schedule)
 	if err != nil {
-		return false, err
+		return time.Time{}, err
 	}
-	next := spec.Next(anchor)
-	return !next.After(now.UTC()), nil
+	next := spec.Next(anchor.In(loc))
+	// A repeated wall-clock time when DST ends would otherwise fire twice.
+	if sameWallClock(next, anchor.In(loc)) {
+		next = spec.Next(next)
+	}
+	return next.UTC(), nil
+}
+
+func sameWallClock(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd && a.Hour() == b.Hour() && a.Minute() == b.Minute()
 }
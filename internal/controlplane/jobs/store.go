@@ -159,6 +159,27 @@ func ensureJobColumns(db *sql.DB) error {
 	if err := ensureColumn(db, "jobs", "retry_max_backoff", "retry_max_backoff TEXT"); err != nil {
 		return fmt.Errorf("add jobs.retry_max_backoff: %w", err)
 	}
+	if err := ensureColumn(db, "jobs", "timezone", "timezone TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("add jobs.timezone: %w", err)
+	}
+	if err := ensureColumn(db, "jobs", "timeout", "timeout TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("add jobs.timeout: %w", err)
+	}
+	if err := ensureColumn(db, "jobs", "depends_on", "depends_on TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("add jobs.depends_on: %w", err)
+	}
+	if err := ensureColumn(db, "jobs", "dependency_timeout", "dependency_timeout TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("add jobs.dependency_timeout: %w", err)
+	}
+	if err := ensureColumn(db, "jobs", "concurrency_policy", "concurrency_policy TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("add jobs.concurrency_policy: %w", err)
+	}
+	if err := ensureColumn(db, "jobs", "last_skipped_at", "last_skipped_at TEXT"); err != nil {
+		return fmt.Errorf("add jobs.last_skipped_at: %w", err)
+	}
+	if err := ensureColumn(db, "jobs", "last_skip_reason", "last_skip_reason TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("add jobs.last_skip_reason: %w", err)
+	}
 	return nil
 }
 
@@ -241,6 +262,8 @@ func (s *Store) Close() error {
 
 // CreateJob inserts a new scheduled job.
 func (s *Store) CreateJob(job Job) (*Job, error) {
+	job.DependsOn = normalizeDependsOn(job.DependsOn)
+	job.ConcurrencyPolicy = normalizeConcurrencyPolicy(job.ConcurrencyPolicy)
 	if err := validateJob(job); err != nil {
 		return nil, err
 	}
@@ -259,8 +282,8 @@ func (s *Store) CreateJob(job Job) (*Job, error) {
 		enabled = 1
 	}
 
-	_, err := s.db.Exec(`INSERT INTO jobs (id, workspace_id, name, command, schedule, target_kind, target_value, retry_max_attempts, retry_initial_backoff, retry_multiplier, retry_max_backoff, enabled, created_at, updated_at, last_run_at, last_status)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	_, err := s.db.Exec(`INSERT INTO jobs (id, workspace_id, name, command, schedule, target_kind, target_value, retry_max_attempts, retry_initial_backoff, retry_multiplier, retry_max_backoff, timezone, timeout, depends_on, dependency_timeout, concurrency_policy, enabled, created_at, updated_at, last_run_at, last_status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		job.ID,
 		strings.TrimSpace(job.WorkspaceID),
 		strings.TrimSpace(job.Name),
@@ -272,6 +295,11 @@ func (s *Store) CreateJob(job Job) (*Job, error) {
 		nullableRetryDuration(job.RetryPolicy, func(p *RetryPolicy) string { return p.InitialBackoff }),
 		nullableRetryMultiplier(job.RetryPolicy),
 		nullableRetryDuration(job.RetryPolicy, func(p *RetryPolicy) string { return p.MaxBackoff }),
+		strings.TrimSpace(job.Timezone),
+		strings.TrimSpace(job.Timeout),
+		encodeDependsOn(job.DependsOn),
+		strings.TrimSpace(job.DependencyTimeout),
+		job.ConcurrencyPolicy,
 		enabled,
 		job.CreatedAt.Format(time.RFC3339Nano),
 		job.UpdatedAt.Format(time.RFC3339Nano),
@@ -291,6 +319,8 @@ func (s *Store) UpdateJob(job Job) (*Job, error) {
 	if strings.TrimSpace(job.ID) == "" {
 		return nil, fmt.Errorf("job id required")
 	}
+	job.DependsOn = normalizeDependsOn(job.DependsOn)
+	job.ConcurrencyPolicy = normalizeConcurrencyPolicy(job.ConcurrencyPolicy)
 	if err := validateJob(job); err != nil {
 		return nil, err
 	}
@@ -302,7 +332,7 @@ func (s *Store) UpdateJob(job Job) (*Job, error) {
 	}
 
 	res, err := s.db.Exec(`UPDATE jobs
-		SET name = ?, command = ?, schedule = ?, target_kind = ?, target_value = ?, retry_max_attempts = ?, retry_initial_backoff = ?, retry_multiplier = ?, retry_max_backoff = ?, enabled = ?, updated_at = ?, last_status = ?
+		SET name = ?, command = ?, schedule = ?, target_kind = ?, target_value = ?, retry_max_attempts = ?, retry_initial_backoff = ?, retry_multiplier = ?, retry_max_backoff = ?, timezone = ?, timeout = ?, depends_on = ?, dependency_timeout = ?, concurrency_policy = ?, enabled = ?, updated_at = ?, last_status = ?
 		WHERE id = ?`,
 		strings.TrimSpace(job.Name),
 		strings.TrimSpace(job.Command),
@@ -313,6 +343,11 @@ func (s *Store) UpdateJob(job Job) (*Job, error) {
 		nullableRetryDuration(job.RetryPolicy, func(p *RetryPolicy) string { return p.InitialBackoff }),
 		nullableRetryMultiplier(job.RetryPolicy),
 		nullableRetryDuration(job.RetryPolicy, func(p *RetryPolicy) string { return p.MaxBackoff }),
+		strings.TrimSpace(job.Timezone),
+		strings.TrimSpace(job.Timeout),
+		encodeDependsOn(job.DependsOn),
+		strings.TrimSpace(job.DependencyTimeout),
+		job.ConcurrencyPolicy,
 		enabled,
 		now.Format(time.RFC3339Nano),
 		strings.TrimSpace(job.LastStatus),
@@ -355,14 +390,14 @@ func (s *Store) SetEnabled(id string, enabled bool) (*Job, error) {
 
 // GetJob returns one job by id.
 func (s *Store) GetJob(id string) (*Job, error) {
-	row := s.db.QueryRow(`SELECT id, workspace_id, name, command, schedule, target_kind, target_value, retry_max_attempts, retry_initial_backoff, retry_multiplier, retry_max_backoff, enabled, created_at, updated_at, last_run_at, last_status
+	row := s.db.QueryRow(`SELECT id, workspace_id, name, command, schedule, target_kind, target_value, retry_max_attempts, retry_initial_backoff, retry_multiplier, retry_max_backoff, timezone, timeout, depends_on, dependency_timeout, concurrency_policy, enabled, created_at, updated_at, last_run_at, last_status, last_skipped_at, last_skip_reason
 		FROM jobs WHERE id = ?`, id)
 	return scanJob(row)
 }
 
 // ListJobs returns all jobs sorted by updated time (newest first).
 func (s *Store) ListJobs() ([]Job, error) {
-	rows, err := s.db.Query(`SELECT id, workspace_id, name, command, schedule, target_kind, target_value, retry_max_attempts, retry_initial_backoff, retry_multiplier, retry_max_backoff, enabled, created_at, updated_at, last_run_at, last_status
+	rows, err := s.db.Query(`SELECT id, workspace_id, name, command, schedule, target_kind, target_value, retry_max_attempts, retry_initial_backoff, retry_multiplier, retry_max_backoff, timezone, timeout, depends_on, dependency_timeout, concurrency_policy, enabled, created_at, updated_at, last_run_at, last_status, last_skipped_at, last_skip_reason
 		FROM jobs ORDER BY updated_at DESC`)
 	if err != nil {
 		return nil, err
@@ -546,8 +581,8 @@ func (s *Store) CompleteRunWithRetry(runID, status string, exitCode *int, output
 		return fmt.Errorf("run id required")
 	}
 	status = strings.TrimSpace(status)
-	if status != RunStatusSuccess && status != RunStatusFailed {
-		return fmt.Errorf("status must be success or failed")
+	if status != RunStatusSuccess && status != RunStatusFailed && status != RunStatusTimedOut {
+		return fmt.Errorf("status must be success, failed, or timed_out")
 	}
 
 	return s.transitionRun(runID, []string{RunStatusRunning}, status, exitCode, output, true, retryScheduledAt)
@@ -734,6 +769,7 @@ func (s *Store) updateJobStatusForLatestBatch(tx *sql.Tx, jobID, runStartedAt st
 		failedCount   int
 		deniedCount   int
 		canceledCount int
+		timedOutCount int
 	)
 	if err := tx.QueryRow(`SELECT
 		COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
@@ -741,6 +777,7 @@ func (s *Store) updateJobStatusForLatestBatch(tx *sql.Tx, jobID, runStartedAt st
 		COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
 		COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
 		COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
 		COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0)
 		FROM job_runs
 		WHERE job_id = ? AND started_at = ?`,
@@ -750,9 +787,10 @@ func (s *Store) updateJobStatusForLatestBatch(tx *sql.Tx, jobID, runStartedAt st
 		RunStatusFailed,
 		RunStatusDenied,
 		RunStatusCanceled,
+		RunStatusTimedOut,
 		jobID,
 		latestStartedAt,
-	).Scan(&queuedCount, &pendingCount, &runningCount, &failedCount, &deniedCount, &canceledCount); err != nil {
+	).Scan(&queuedCount, &pendingCount, &runningCount, &failedCount, &deniedCount, &canceledCount, &timedOutCount); err != nil {
 		return err
 	}
 
@@ -766,6 +804,8 @@ func (s *Store) updateJobStatusForLatestBatch(tx *sql.Tx, jobID, runStartedAt st
 		finalStatus = RunStatusQueued
 	case failedCount > 0:
 		finalStatus = RunStatusFailed
+	case timedOutCount > 0:
+		finalStatus = RunStatusTimedOut
 	case deniedCount > 0:
 		finalStatus = RunStatusDenied
 	case canceledCount > 0:
@@ -867,10 +907,12 @@ func scanJob(s scanner) (*Job, error) {
 		enabled              int
 		createdAt, updatedAt string
 		lastRunAt            sql.NullString
+		lastSkippedAt        sql.NullString
 		retryMaxAttempts     sql.NullInt64
 		retryInitialBackoff  sql.NullString
 		retryMultiplier      sql.NullFloat64
 		retryMaxBackoff      sql.NullString
+		dependsOn            string
 	)
 
 	if err := s.Scan(
@@ -885,11 +927,18 @@ func scanJob(s scanner) (*Job, error) {
 		&retryInitialBackoff,
 		&retryMultiplier,
 		&retryMaxBackoff,
+		&job.Timezone,
+		&job.Timeout,
+		&dependsOn,
+		&job.DependencyTimeout,
+		&job.ConcurrencyPolicy,
 		&enabled,
 		&createdAt,
 		&updatedAt,
 		&lastRunAt,
 		&job.LastStatus,
+		&lastSkippedAt,
+		&job.LastSkipReason,
 	); err != nil {
 		return nil, err
 	}
@@ -911,6 +960,7 @@ func scanJob(s scanner) (*Job, error) {
 		job.RetryPolicy = rp
 	}
 
+	job.DependsOn = decodeDependsOn(dependsOn)
 	job.Enabled = enabled == 1
 	job.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
 	job.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
@@ -920,6 +970,12 @@ func scanJob(s scanner) (*Job, error) {
 			job.LastRunAt = &ts
 		}
 	}
+	if lastSkippedAt.Valid && lastSkippedAt.String != "" {
+		ts, err := time.Parse(time.RFC3339Nano, lastSkippedAt.String)
+		if err == nil {
+			job.LastSkippedAt = &ts
+		}
+	}
 	return &job, nil
 }
 
@@ -1028,6 +1084,21 @@ func validateJob(job Job) error {
 	if err := validateRetryPolicy(job.RetryPolicy); err != nil {
 		return err
 	}
+	if raw := strings.TrimSpace(job.Timeout); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout: %s", raw)
+		}
+	}
+	if err := validateTimezone(job.Timezone); err != nil {
+		return err
+	}
+	if err := validateDependsOn(job); err != nil {
+		return err
+	}
+	if err := validateConcurrencyPolicy(job.ConcurrencyPolicy); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -1137,7 +1208,7 @@ func truncateOutput(output string) string {
 
 func isKnownRunStatus(status string) bool {
 	switch strings.TrimSpace(status) {
-	case RunStatusQueued, RunStatusPending, RunStatusRunning, RunStatusSuccess, RunStatusFailed, RunStatusCanceled, RunStatusDenied:
+	case RunStatusQueued, RunStatusPending, RunStatusRunning, RunStatusSuccess, RunStatusFailed, RunStatusCanceled, RunStatusDenied, RunStatusTimedOut:
 		return true
 	default:
 		return false
@@ -1161,7 +1232,7 @@ func (s *Store) ListJobsByWorkspace(workspaceID string) ([]Job, error) {
 	if workspaceID == "" {
 		return s.ListJobs()
 	}
-	rows, err := s.db.Query(`SELECT id, workspace_id, name, command, schedule, target_kind, target_value, retry_max_attempts, retry_initial_backoff, retry_multiplier, retry_max_backoff, enabled, created_at, updated_at, last_run_at, last_status
+	rows, err := s.db.Query(`SELECT id, workspace_id, name, command, schedule, target_kind, target_value, retry_max_attempts, retry_initial_backoff, retry_multiplier, retry_max_backoff, timezone, timeout, depends_on, dependency_timeout, concurrency_policy, enabled, created_at, updated_at, last_run_at, last_status, last_skipped_at, last_skip_reason
 		FROM jobs WHERE workspace_id = ? ORDER BY updated_at DESC`, workspaceID)
 	if err != nil {
 		return nil, err
@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"encoding/json"
+	"errors"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -544,4 +545,49 @@ func TestStoreMarkRunDeniedPersistsAdmissionReason(t *testing.T) {
 	}
 }
 
+func TestStorePersistsDependenciesAndRejectsCycles(t *testing.T) {
+	store := newTestStore(t)
+	parent := createTestJob(t, store)
+
+	child, err := store.CreateJob(Job{
+		Name:              "report",
+		Command:           "echo report",
+		Schedule:          "5m",
+		Target:            Target{Kind: TargetKindAll},
+		DependsOn:         []string{" " + parent.ID, parent.ID},
+		DependencyTimeout: "30m",
+		Enabled:           true,
+	})
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+
+	got, err := store.GetJob(child.ID)
+	if err != nil {
+		t.Fatalf("get child: %v", err)
+	}
+	if len(got.DependsOn) != 1 || got.DependsOn[0] != parent.ID {
+		t.Fatalf("expected normalized depends_on [%s], got %v", parent.ID, got.DependsOn)
+	}
+	if got.DependencyTimeout != "30m" {
+		t.Fatalf("expected dependency_timeout 30m, got %q", got.DependencyTimeout)
+	}
+
+	cyclic := *parent
+	cyclic.DependsOn = []string{child.ID}
+	if err := store.ValidateDependencies(cyclic); !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("expected dependency cycle error, got %v", err)
+	}
+	missing := *parent
+	missing.DependsOn = []string{"job-missing"}
+	if err := store.ValidateDependencies(missing); err == nil {
+		t.Fatal("expected unknown dependency to be rejected")
+	}
+	self := *parent
+	self.DependsOn = []string{parent.ID}
+	if _, err := store.UpdateJob(self); err == nil {
+		t.Fatal("expected self dependency to be rejected")
+	}
+}
+
 func intPtr(v int) *int { return &v }
@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// validateTimezone accepts an empty value (UTC) or a loadable IANA zone name.
+func validateTimezone(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("unknown timezone: %s", name)
+	}
+	return nil
+}
+
+// location returns the zone the job's cron schedule is evaluated in. Unknown
+// zones (only possible for rows written before validation) fall back to UTC.
+func (j Job) location() *time.Location {
+	name := strings.TrimSpace(j.Timezone)
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// withNextRun fills the computed next-run fields for enabled jobs.
+func (j Job) withNextRun(now time.Time) Job {
+	j.NextRunAt = nil
+	j.NextRunLocal = ""
+	if !j.Enabled {
+		return j
+	}
+	loc := j.location()
+	next, err := nextScheduledRun(j.Schedule, loc, j.LastRunAt, j.CreatedAt, now)
+	if err != nil {
+		return j
+	}
+	j.NextRunAt = &next
+	j.NextRunLocal = next.In(loc).Format(time.RFC3339)
+	return j
+}
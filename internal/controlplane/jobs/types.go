@@ -17,22 +17,49 @@ const (
 	RunStatusFailed   = "failed"
 	RunStatusCanceled = "canceled"
 	RunStatusDenied   = "denied"
+	RunStatusTimedOut = "timed_out"
 )
 
 // Job describes a scheduled command execution definition.
 type Job struct {
-	ID          string       `json:"id"`
-	WorkspaceID string       `json:"workspace_id,omitempty"`
-	Name        string       `json:"name"`
-	Command     string       `json:"command"`
-	Schedule    string       `json:"schedule"`
+	ID          string `json:"id"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	Name        string `json:"name"`
+	Command     string `json:"command"`
+	Schedule    string `json:"schedule"`
+	// Timezone is the IANA zone cron schedules are evaluated in (UTC when
+	// empty). Interval schedules ignore it.
+	Timezone    string       `json:"timezone,omitempty"`
 	Target      Target       `json:"target"`
 	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty"`
-	Enabled     bool         `json:"enabled"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
-	LastRunAt   *time.Time   `json:"last_run_at,omitempty"`
-	LastStatus  string       `json:"last_status"`
+	// Timeout bounds each attempt (Go duration). A timed-out attempt is
+	// killed on the probe and may be retried with a fresh timeout.
+	Timeout string `json:"timeout,omitempty"`
+	// DependsOn lists job IDs whose latest cycle must succeed before a
+	// scheduled cycle of this job is dispatched.
+	DependsOn []string `json:"depends_on,omitempty"`
+	// DependencyTimeout bounds how long a due cycle waits on DependsOn
+	// before it is skipped. Empty uses the scheduler default.
+	DependencyTimeout string `json:"dependency_timeout,omitempty"`
+	// ConcurrencyPolicy decides what a trigger does while the previous run
+	// on the same probe is still active: allow, forbid (default) or replace.
+	ConcurrencyPolicy string     `json:"concurrency_policy,omitempty"`
+	Enabled           bool       `json:"enabled"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	LastRunAt         *time.Time `json:"last_run_at,omitempty"`
+	LastStatus        string     `json:"last_status"`
+	// LastSkippedAt and LastSkipReason record the most recent trigger that
+	// the concurrency policy skipped.
+	LastSkippedAt  *time.Time `json:"last_skipped_at,omitempty"`
+	LastSkipReason string     `json:"last_skip_reason,omitempty"`
+	// NextRunAt and NextRunLocal are computed on read, in UTC and in the
+	// job's timezone respectively.
+	NextRunAt    *time.Time `json:"next_run_at,omitempty"`
+	NextRunLocal string     `json:"next_run_local,omitempty"`
+	// Dependency is the live blocked-on-dependency state. It is not
+	// persisted and is only populated on single-job reads.
+	Dependency *DependencyState `json:"dependency,omitempty"`
 }
 
 // RetryPolicy configures exponential retry behavior for job runs.
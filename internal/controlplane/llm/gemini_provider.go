@@ -0,0 +1,224 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultGeminiBaseURL is the Gemini API endpoint used when no base URL is set.
+// For Vertex AI, set the base URL to the publisher path, e.g.
+// https://us-central1-aiplatform.googleapis.com/v1/projects/P/locations/us-central1/publishers/google
+const DefaultGeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// ErrSafetyBlocked is returned (wrapped in a *SafetyBlockError) when a
+// provider refuses to answer because of its safety filters. Callers can
+// treat it as a finished run rather than a provider outage.
+var ErrSafetyBlocked = errors.New("response blocked by provider safety filters")
+
+// SafetyBlockError describes why a provider blocked a response.
+type SafetyBlockError struct {
+	Provider string
+	Reason   string // e.g. SAFETY, PROHIBITED_CONTENT, BLOCKLIST
+}
+
+func (e *SafetyBlockError) Error() string {
+	return fmt.Sprintf("%s blocked the response (%s)", e.Provider, e.Reason)
+}
+
+func (e *SafetyBlockError) Unwrap() error { return ErrSafetyBlocked }
+
+// NewProvider returns the Provider implementation for cfg.Name: "gemini" and
+// "vertex" use the Gemini API, everything else is treated as OpenAI-compatible.
+func NewProvider(cfg ProviderConfig) Provider {
+	switch strings.ToLower(strings.TrimSpace(cfg.Name)) {
+	case "gemini", "vertex":
+		return NewGeminiProvider(cfg)
+	default:
+		return NewOpenAIProvider(cfg)
+	}
+}
+
+// GeminiProvider implements Provider for Google Gemini (AI Studio and Vertex AI).
+type GeminiProvider struct {
+	config ProviderConfig
+	client *http.Client
+}
+
+// NewGeminiProvider creates a provider for the Gemini generateContent API.
+func NewGeminiProvider(cfg ProviderConfig) *GeminiProvider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultGeminiBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &GeminiProvider{
+		config: cfg,
+		client: &http.Client{Timeout: 120 * time.Second},
+	}
+}
+
+func (p *GeminiProvider) Name() string { return p.config.Name }
+
+func (p *GeminiProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	if req.Model == "" {
+		req.Model = p.config.Model
+	}
+
+	body, err := json.Marshal(toGeminiRequest(req))
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	endpoint := p.config.BaseURL + "/models/" + url.PathEscape(req.Model) + ":generateContent"
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.config.APIKey != "" {
+		// Vertex AI takes an OAuth access token; the Gemini API takes an API key.
+		if p.isVertex() {
+			httpReq.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+		} else {
+			httpReq.Header.Set("x-goog-api-key", p.config.APIKey)
+		}
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provider returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var gResp geminiResponse
+	if err := json.Unmarshal(respBody, &gResp); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+
+	if reason := gResp.PromptFeedback.BlockReason; reason != "" {
+		return nil, &SafetyBlockError{Provider: "gemini", Reason: reason}
+	}
+	if len(gResp.Candidates) == 0 {
+		return nil, fmt.Errorf("no candidates in response")
+	}
+
+	candidate := gResp.Candidates[0]
+	if geminiBlockedFinish(candidate.FinishReason) {
+		return nil, &SafetyBlockError{Provider: "gemini", Reason: candidate.FinishReason}
+	}
+
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		text.WriteString(part.Text)
+	}
+
+	model := gResp.ModelVersion
+	if model == "" {
+		model = req.Model
+	}
+	return &CompletionResponse{
+		Content:      text.String(),
+		Model:        model,
+		FinishReason: strings.ToLower(candidate.FinishReason),
+		PromptTokens: gResp.UsageMetadata.PromptTokenCount,
+		CompTokens:   gResp.UsageMetadata.CandidatesTokenCount,
+	}, nil
+}
+
+func (p *GeminiProvider) isVertex() bool {
+	if strings.EqualFold(p.config.Name, "vertex") {
+		return true
+	}
+	u, err := url.Parse(p.config.BaseURL)
+	return err == nil && strings.HasSuffix(u.Hostname(), "aiplatform.googleapis.com")
+}
+
+// geminiBlockedFinish reports whether a candidate finish reason means the
+// output was withheld by a content filter.
+func geminiBlockedFinish(reason string) bool {
+	switch reason {
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return true
+	}
+	return false
+}
+
+// toGeminiRequest maps chat messages onto Gemini contents. System messages
+// become the system instruction and assistant turns use the "model" role.
+func toGeminiRequest(req *CompletionRequest) geminiRequest {
+	out := geminiRequest{
+		GenerationConfig: geminiGenerationConfig{
+			Temperature:     req.Temperature,
+			MaxOutputTokens: req.MaxTokens,
+		},
+	}
+	var system []geminiPart
+	for _, msg := range req.Messages {
+		switch msg.Role {
+		case RoleSystem:
+			system = append(system, geminiPart{Text: msg.Content})
+		case RoleAssistant:
+			out.Contents = append(out.Contents, geminiContent{Role: "model", Parts: []geminiPart{{Text: msg.Content}}})
+		default:
+			out.Contents = append(out.Contents, geminiContent{Role: "user", Parts: []geminiPart{{Text: msg.Content}}})
+		}
+	}
+	if len(system) > 0 {
+		out.SystemInstruction = &geminiContent{Parts: system}
+	}
+	return out
+}
+
+// geminiRequest is the generateContent request body.
+type geminiRequest struct {
+	SystemInstruction *geminiContent         `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent        `json:"contents"`
+	GenerationConfig  geminiGenerationConfig `json:"generationConfig"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature     float64 `json:"temperature,omitempty"`
+	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
+}
+
+// geminiResponse is the raw generateContent response format.
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+}
@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestGeminiProviderComplete(t *testing.T) {
+	var gotPath, gotKey string
+	var gotReq geminiRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.Header.Get("x-goog-api-key")
+		_ = json.NewDecoder(r.Body).Decode(&gotReq)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"candidates": [{"content": {"role": "model", "parts": [{"text": "Hello"}, {"text": " there"}]}, "finishReason": "STOP"}],
+			"usageMetadata": {"promptTokenCount": 12, "candidatesTokenCount": 3},
+			"modelVersion": "gemini-2.0-flash-001"
+		}`))
+	}))
+	defer srv.Close()
+
+	provider := NewGeminiProvider(ProviderConfig{Name: "gemini", BaseURL: srv.URL, APIKey: "k-123", Model: "gemini-2.0-flash"})
+	resp, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{
+			{Role: RoleSystem, Content: "be brief"},
+			{Role: RoleUser, Content: "hi"},
+			{Role: RoleAssistant, Content: "hello"},
+			{Role: RoleUser, Content: "again"},
+		},
+		MaxTokens: 64,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotPath != "/models/gemini-2.0-flash:generateContent" {
+		t.Errorf("unexpected path %q", gotPath)
+	}
+	if gotKey != "k-123" {
+		t.Errorf("expected api key header, got %q", gotKey)
+	}
+	if gotReq.SystemInstruction == nil || gotReq.SystemInstruction.Parts[0].Text != "be brief" {
+		t.Errorf("expected system instruction, got %+v", gotReq.SystemInstruction)
+	}
+	if len(gotReq.Contents) != 3 || gotReq.Contents[1].Role != "model" || gotReq.Contents[2].Role != "user" {
+		t.Errorf("unexpected contents %+v", gotReq.Contents)
+	}
+	if gotReq.GenerationConfig.MaxOutputTokens != 64 {
+		t.Errorf("expected maxOutputTokens 64, got %d", gotReq.GenerationConfig.MaxOutputTokens)
+	}
+
+	if resp.Content != "Hello there" {
+		t.Errorf("unexpected content %q", resp.Content)
+	}
+	if resp.Model != "gemini-2.0-flash-001" || resp.PromptTokens != 12 || resp.CompTokens != 3 {
+		t.Errorf("unexpected response metadata %+v", resp)
+	}
+}
+
+func TestGeminiProviderVertexUsesBearerToken(t *testing.T) {
+	var gotAuth, gotKey string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotKey = r.Header.Get("x-goog-api-key")
+		_, _ = w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`))
+	}))
+	defer srv.Close()
+
+	provider := NewGeminiProvider(ProviderConfig{Name: "vertex", BaseURL: srv.URL, APIKey: "ya29.token", Model: "gemini-2.0-flash"})
+	if _, err := provider.Complete(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotAuth != "Bearer ya29.token" || gotKey != "" {
+		t.Errorf("expected bearer auth only, got Authorization=%q x-goog-api-key=%q", gotAuth, gotKey)
+	}
+}
+
+func TestGeminiProviderSafetyBlocks(t *testing.T) {
+	cases := map[string]string{
+		"prompt":    `{"promptFeedback": {"blockReason": "PROHIBITED_CONTENT"}}`,
+		"candidate": `{"candidates": [{"content": {"parts": []}, "finishReason": "SAFETY"}]}`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(body))
+			}))
+			defer srv.Close()
+
+			provider := NewGeminiProvider(ProviderConfig{Name: "gemini", BaseURL: srv.URL, Model: "gemini-2.0-flash"})
+			_, err := provider.Complete(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+			if !errors.Is(err, ErrSafetyBlocked) {
+				t.Fatalf("expected ErrSafetyBlocked, got %v", err)
+			}
+			var blockErr *SafetyBlockError
+			if !errors.As(err, &blockErr) || blockErr.Reason == "" {
+				t.Fatalf("expected SafetyBlockError with reason, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTaskRunnerReportsSafetyBlockCleanly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"promptFeedback": {"blockReason": "SAFETY"}}`))
+	}))
+	defer srv.Close()
+
+	provider := NewGeminiProvider(ProviderConfig{Name: "gemini", BaseURL: srv.URL, Model: "gemini-2.0-flash"})
+	runner := NewTaskRunner(provider, func(string, *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+		return nil, nil
+	}, noopLogger())
+
+	result, err := runner.Run(context.Background(), "probe-1", "do something", &protocol.InventoryPayload{Hostname: "test"}, protocol.CapObserve)
+	if err != nil {
+		t.Fatalf("expected safety block to be reported without error, got %v", err)
+	}
+	if result.Summary == "" || result.Error == "" {
+		t.Fatalf("expected summary and error on result, got %+v", result)
+	}
+}
+
+func TestNewProviderSelectsImplementation(t *testing.T) {
+	if _, ok := NewProvider(ProviderConfig{Name: "Gemini"}).(*GeminiProvider); !ok {
+		t.Error("expected gemini provider")
+	}
+	if _, ok := NewProvider(ProviderConfig{Name: "vertex"}).(*GeminiProvider); !ok {
+		t.Error("expected gemini provider for vertex")
+	}
+	if _, ok := NewProvider(ProviderConfig{Name: "openai"}).(*OpenAIProvider); !ok {
+		t.Error("expected openai provider")
+	}
+}
@@ -1,5 +1,6 @@
 // Package llm provides model provider abstraction for the control plane.
 // Supports OpenAI-compatible APIs (OpenAI, Anthropic via proxy, Ollama, etc.)
+// and Google Gemini / Vertex AI.
 package llm
 
 import (
@@ -44,7 +45,7 @@ type CompletionResponse struct {
 
 // ProviderConfig holds connection details for a model provider.
 type ProviderConfig struct {
-	Name    string `json:"name" yaml:"name"`         // e.g. "openai", "ollama", "anthropic"
+	Name    string `json:"name" yaml:"name"`         // e.g. "openai", "ollama", "anthropic", "gemini"
 	BaseURL string `json:"base_url" yaml:"base_url"` // e.g. "https://api.openai.com/v1"
 	APIKey  string `json:"api_key,omitempty" yaml:"api_key,omitempty"`
 	Model   string `json:"model" yaml:"model"` // e.g. "gpt-4o", "llama3.1"
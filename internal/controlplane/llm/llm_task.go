@@ -2,12 +2,17 @@ package llm
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/marcus-qen/legator/internal/controlplane/connectivity"
 	"github.com/marcus-qen/legator/internal/protocol"
+	"github.com/marcus-qen/legator/internal/shared/security"
 	"go.uber.org/zap"
 )
 
@@ -27,6 +32,39 @@ type TaskResult struct {
 	StartedAt  time.Time  `json:"started_at"`
 	FinishedAt time.Time  `json:"finished_at"`
 	Error      string     `json:"error,omitempty"`
+	// Guardrails lists the guardrails that stopped or altered the run.
+	Guardrails []TaskGuardrail `json:"guardrails,omitempty"`
+	// Connectivity is the pre-run endpoint check, when endpoints were given.
+	Connectivity *connectivity.Report `json:"connectivity,omitempty"`
+}
+
+// TaskOptions carries optional per-task settings for RunWithOptions.
+type TaskOptions struct {
+	// Endpoints are checked for reachability before the first model step.
+	Endpoints []connectivity.Endpoint
+	// FailOnUnreachable stops the task before it starts when a required
+	// endpoint is unreachable. Otherwise the task proceeds and the model is
+	// told which endpoints are down.
+	FailOnUnreachable bool
+}
+
+// GuardrailLoopDetected is recorded when the model repeats the same command
+// the loop threshold number of times in a row.
+const GuardrailLoopDetected = "LoopDetected"
+
+// GuardrailEndpointUnreachable is recorded when FailOnUnreachable stops a
+// task because a required endpoint failed the pre-run check.
+const GuardrailEndpointUnreachable = "EndpointUnreachable"
+
+// DefaultLoopThreshold is the number of consecutive identical commands that
+// aborts a task.
+const DefaultLoopThreshold = 3
+
+// TaskGuardrail records a guardrail that fired during a task.
+type TaskGuardrail struct {
+	Condition string `json:"condition"`
+	Step      int    `json:"step"`
+	Message   string `json:"message"`
 }
 
 // TaskStep records one command execution in the task.
@@ -43,21 +81,74 @@ type TaskStep struct {
 // CommandDispatcher sends a command to a probe and waits for the result.
 type CommandDispatcher func(probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error)
 
+// ContextCommandDispatcher is a CommandDispatcher that receives the task's
+// context, so it can report approval waits with EmitTaskEvent.
+type ContextCommandDispatcher func(ctx context.Context, probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error)
+
+// SecretSource returns the credential values resolved for a probe, which
+// the runner scrubs from everything it reports.
+type SecretSource func(probeID string) []string
+
 // TaskRunner executes natural-language tasks against probes using an LLM.
 type TaskRunner struct {
 	provider Provider
-	dispatch CommandDispatcher
+	dispatch ContextCommandDispatcher
 	logger   *zap.Logger
 	maxSteps int
+
+	loopThreshold int
+	connectivity  *connectivity.Manager
+	secrets       SecretSource
 }
 
 // NewTaskRunner creates a TaskRunner.
 func NewTaskRunner(provider Provider, dispatch CommandDispatcher, logger *zap.Logger) *TaskRunner {
+	return NewTaskRunnerWithContext(provider, func(_ context.Context, probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+		return dispatch(probeID, cmd)
+	}, logger)
+}
+
+// NewTaskRunnerWithContext creates a TaskRunner whose dispatcher receives the
+// task context.
+func NewTaskRunnerWithContext(provider Provider, dispatch ContextCommandDispatcher, logger *zap.Logger) *TaskRunner {
 	return &TaskRunner{
 		provider: provider,
 		dispatch: dispatch,
 		logger:   logger,
 		maxSteps: 10, // safety limit
+
+		loopThreshold: DefaultLoopThreshold,
+		connectivity:  connectivity.NewManager(),
+	}
+}
+
+// SetLoopThreshold sets how many consecutive identical commands (same
+// command and args) abort a task with a LoopDetected guardrail. Values
+// below 2 disable the check.
+func (tr *TaskRunner) SetLoopThreshold(n int) {
+	tr.loopThreshold = n
+}
+
+// SetSecretSource sets where the runner finds a probe's known secret
+// values. Command output, model responses, the final report and emitted
+// task events are redacted against them and common secret patterns before
+// they leave the runner; the model is also only shown redacted output.
+func (tr *TaskRunner) SetSecretSource(src SecretSource) {
+	tr.secrets = src
+}
+
+func (tr *TaskRunner) redactorFor(probeID string) *security.Redactor {
+	if tr.secrets == nil {
+		return security.NewRedactor()
+	}
+	return security.NewRedactor(tr.secrets(probeID)...)
+}
+
+// SetConnectivityManager replaces the manager used for pre-run endpoint
+// checks.
+func (tr *TaskRunner) SetConnectivityManager(m *connectivity.Manager) {
+	if m != nil {
+		tr.connectivity = m
 	}
 }
 
@@ -77,10 +168,19 @@ RULES:
 
 The target server's inventory will be provided as context.`
 
-// Run executes a task against a probe.
+// Run executes a task against a probe. Intermediate steps are reported to
+// the TaskObserver attached to ctx with WithTaskObserver, ending with a
+// TaskEventReport carrying the result.
 func (tr *TaskRunner) Run(ctx context.Context, probeID, task string, inventory *protocol.InventoryPayload, policyLevel protocol.CapabilityLevel) (*TaskResult, error) {
+	return tr.RunWithOptions(ctx, probeID, task, inventory, policyLevel, TaskOptions{})
+}
+
+// RunWithOptions is Run with a pre-run connectivity check over
+// opts.Endpoints.
+func (tr *TaskRunner) RunWithOptions(ctx context.Context, probeID, task string, inventory *protocol.InventoryPayload, policyLevel protocol.CapabilityLevel, opts TaskOptions) (*TaskResult, error) {
+	redact := tr.redactorFor(probeID).Redact
 	result := &TaskResult{
-		Task:      task,
+		Task:      redact(task),
 		ProbeID:   probeID,
 		StartedAt: time.Now().UTC(),
 		Steps:     []TaskStep{},
@@ -94,11 +194,40 @@ func (tr *TaskRunner) Run(ctx context.Context, probeID, task string, inventory *
 			inventory.CPUs, inventory.MemTotal/(1024*1024), policyLevel)
 	}
 
+	if len(opts.Endpoints) > 0 {
+		report := tr.connectivity.PreRunCheck(ctx, opts.Endpoints)
+		result.Connectivity = report
+		EmitTaskEvent(ctx, TaskEvent{Type: TaskEventConnectivity, Connectivity: report})
+
+		down := report.Unreachable()
+		if len(down) > 0 {
+			tr.logger.Warn("task pre-run connectivity check failed",
+				zap.String("probe", probeID),
+				zap.Strings("unreachable", endpointLabels(down)),
+			)
+		}
+		if required := report.RequiredUnreachable(); opts.FailOnUnreachable && len(required) > 0 {
+			msg := "required endpoints unreachable: " + strings.Join(endpointLabels(required), ", ")
+			result.Guardrails = append(result.Guardrails, TaskGuardrail{Condition: GuardrailEndpointUnreachable, Message: msg})
+			result.Summary = "Task not started: " + msg + "."
+			result.Error = msg
+			result.FinishedAt = time.Now().UTC()
+			EmitTaskEvent(ctx, TaskEvent{Type: TaskEventReport, Report: result, Error: result.Error})
+			return result, nil
+		}
+		if len(down) > 0 {
+			inventoryCtx += "\nUnreachable from the control plane: " + strings.Join(endpointLabels(down), ", ")
+		}
+	}
+
 	messages := []Message{
 		{Role: RoleSystem, Content: systemPrompt},
 		{Role: RoleUser, Content: fmt.Sprintf("[Context] %s\n\n[Task] %s", inventoryCtx, task)},
 	}
 
+	var lastCommand string
+	repeats := 0
+
 	for step := 0; step < tr.maxSteps; step++ {
 		tr.logger.Info("task step",
 			zap.String("probe", probeID),
@@ -113,24 +242,58 @@ func (tr *TaskRunner) Run(ctx context.Context, probeID, task string, inventory *
 			MaxTokens:   1024,
 		})
 		if err != nil {
-			result.Error = fmt.Sprintf("LLM error at step %d: %v", step+1, err)
+			result.Error = redact(fmt.Sprintf("LLM error at step %d: %v", step+1, err))
 			result.FinishedAt = time.Now().UTC()
+			if errors.Is(err, ErrSafetyBlocked) {
+				// The provider is healthy but refused to continue; report
+				// what was done so far instead of failing the request.
+				result.Summary = "Task stopped: the model provider blocked the response."
+				tr.logger.Warn("task blocked by provider safety filter",
+					zap.String("probe", probeID),
+					zap.Error(err),
+				)
+				EmitTaskEvent(ctx, TaskEvent{Type: TaskEventReport, Step: step + 1, Report: result, Error: result.Error})
+				return result, nil
+			}
+			EmitTaskEvent(ctx, TaskEvent{Type: TaskEventReport, Step: step + 1, Report: result, Error: result.Error})
 			return result, err
 		}
 
 		content := strings.TrimSpace(completion.Content)
 		messages = append(messages, Message{Role: RoleAssistant, Content: content})
+		EmitTaskEvent(ctx, TaskEvent{Type: TaskEventModelStep, Step: step + 1, Content: redact(content)})
 
 		// Try to parse as a command request
 		var cmdReq CommandRequest
 		if err := json.Unmarshal([]byte(content), &cmdReq); err != nil || cmdReq.Command == "" {
 			// Not a command — this is the final summary
-			result.Summary = content
+			result.Summary = redact(content)
 			result.FinishedAt = time.Now().UTC()
 			tr.logger.Info("task complete",
 				zap.String("probe", probeID),
 				zap.Int("steps", len(result.Steps)),
 			)
+			EmitTaskEvent(ctx, TaskEvent{Type: TaskEventReport, Step: step + 1, Report: result})
+			return result, nil
+		}
+
+		if key := commandKey(cmdReq); key == lastCommand {
+			repeats++
+		} else {
+			lastCommand, repeats = key, 1
+		}
+		if tr.loopThreshold > 1 && repeats >= tr.loopThreshold {
+			msg := fmt.Sprintf("model requested %q %d times in a row", redact(strings.TrimSpace(cmdReq.Command+" "+strings.Join(cmdReq.Args, " "))), repeats)
+			result.Guardrails = append(result.Guardrails, TaskGuardrail{Condition: GuardrailLoopDetected, Step: step + 1, Message: msg})
+			result.Summary = "Task stopped: the model kept repeating the same command without progress."
+			result.Error = "loop detected: " + msg
+			result.FinishedAt = time.Now().UTC()
+			tr.logger.Warn("task loop detected",
+				zap.String("probe", probeID),
+				zap.String("command", cmdReq.Command),
+				zap.Int("repeats", repeats),
+			)
+			EmitTaskEvent(ctx, TaskEvent{Type: TaskEventReport, Step: step + 1, Report: result, Error: result.Error})
 			return result, nil
 		}
 
@@ -150,36 +313,40 @@ func (tr *TaskRunner) Run(ctx context.Context, probeID, task string, inventory *
 			Timeout:   30 * time.Second,
 		}
 
-		cmdResult, err := tr.dispatch(probeID, cmd)
+		requested := redactCommandRequest(cmdReq, redact)
+		EmitTaskEvent(ctx, TaskEvent{Type: TaskEventCommandDispatched, Step: step + 1, RequestID: cmd.RequestID, Command: &requested})
+		cmdResult, err := tr.dispatch(ctx, probeID, cmd)
 
 		stepRecord := TaskStep{
-			Command: cmdReq.Command,
-			Args:    cmdReq.Args,
-			Reason:  cmdReq.Reason,
+			Command: requested.Command,
+			Args:    requested.Args,
+			Reason:  requested.Reason,
 		}
 
 		if err != nil {
 			stepRecord.ExitCode = -1
-			stepRecord.Stderr = err.Error()
+			stepRecord.Stderr = redact(err.Error())
 			result.Steps = append(result.Steps, stepRecord)
+			EmitTaskEvent(ctx, TaskEvent{Type: TaskEventCommandResult, Step: step + 1, RequestID: cmd.RequestID, Result: &stepRecord, Error: stepRecord.Stderr})
 
 			// Tell the LLM the command failed to dispatch
 			messages = append(messages, Message{
 				Role:    RoleUser,
-				Content: fmt.Sprintf("[Error] Command dispatch failed: %s", err.Error()),
+				Content: fmt.Sprintf("[Error] Command dispatch failed: %s", stepRecord.Stderr),
 			})
 			continue
 		}
 
 		stepRecord.ExitCode = cmdResult.ExitCode
-		stepRecord.Stdout = cmdResult.Stdout
-		stepRecord.Stderr = cmdResult.Stderr
+		stepRecord.Stdout = redact(cmdResult.Stdout)
+		stepRecord.Stderr = redact(cmdResult.Stderr)
 		stepRecord.Duration = cmdResult.Duration
 		result.Steps = append(result.Steps, stepRecord)
+		EmitTaskEvent(ctx, TaskEvent{Type: TaskEventCommandResult, Step: step + 1, RequestID: cmd.RequestID, Result: &stepRecord})
 
 		// Truncate long output for the LLM context
-		stdout := truncate(cmdResult.Stdout, 4000)
-		stderr := truncate(cmdResult.Stderr, 1000)
+		stdout := truncate(stepRecord.Stdout, 4000)
+		stderr := truncate(stepRecord.Stderr, 1000)
 
 		feedback := fmt.Sprintf("[Result] exit_code=%d duration=%dms\n", cmdResult.ExitCode, cmdResult.Duration)
 		if stdout != "" {
@@ -195,9 +362,43 @@ func (tr *TaskRunner) Run(ctx context.Context, probeID, task string, inventory *
 	result.Summary = "Task reached maximum step limit without completing."
 	result.Error = "max steps exceeded"
 	result.FinishedAt = time.Now().UTC()
+	EmitTaskEvent(ctx, TaskEvent{Type: TaskEventReport, Step: tr.maxSteps, Report: result, Error: result.Error})
 	return result, fmt.Errorf("task exceeded %d steps", tr.maxSteps)
 }
 
+// redactCommandRequest returns a copy of req safe to report.
+func redactCommandRequest(req CommandRequest, redact func(string) string) CommandRequest {
+	out := CommandRequest{Command: redact(req.Command), Reason: redact(req.Reason)}
+	if len(req.Args) > 0 {
+		out.Args = make([]string, len(req.Args))
+		for i, arg := range req.Args {
+			out.Args[i] = redact(arg)
+		}
+	}
+	return out
+}
+
+// commandKey identifies a command request for loop detection.
+func commandKey(req CommandRequest) string {
+	sum := sha256.Sum256([]byte(req.Command + "\x00" + strings.Join(req.Args, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// endpointLabels describes endpoint results for logs and summaries.
+func endpointLabels(results []connectivity.EndpointResult) []string {
+	out := make([]string, 0, len(results))
+	for _, res := range results {
+		label := res.Name
+		if label == "" {
+			label = res.Address
+		} else {
+			label += " (" + res.Address + ")"
+		}
+		out = append(out, label)
+	}
+	return out
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
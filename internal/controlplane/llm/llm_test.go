@@ -3,10 +3,14 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/marcus-qen/legator/internal/controlplane/connectivity"
 	"github.com/marcus-qen/legator/internal/protocol"
 	"go.uber.org/zap"
 )
@@ -190,6 +194,209 @@ func TestTaskRunnerImmediateSummary(t *testing.T) {
 	}
 }
 
+func TestTaskRunnerEmitsTaskEvents(t *testing.T) {
+	srv := mockOpenAIServer([]string{
+		`{"command": "uptime", "args": [], "reason": "Check load"}`,
+		"Load is normal.",
+	})
+	defer srv.Close()
+
+	provider := NewOpenAIProvider(ProviderConfig{
+		Name:    "test",
+		BaseURL: srv.URL,
+		Model:   "test-model",
+	})
+
+	dispatch := func(ctx context.Context, probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+		EmitTaskEvent(ctx, TaskEvent{Type: TaskEventApprovalPending, RequestID: cmd.RequestID, ApprovalID: "apr-1"})
+		return &protocol.CommandResultPayload{RequestID: cmd.RequestID, Stdout: "load average: 0.01"}, nil
+	}
+
+	runner := NewTaskRunnerWithContext(provider, dispatch, noopLogger())
+
+	var got []TaskEvent
+	ctx := WithTaskObserver(context.Background(), func(evt TaskEvent) {
+		got = append(got, evt)
+	})
+	result, err := runner.Run(ctx, "probe-1", "Is the load ok?", &protocol.InventoryPayload{Hostname: "test"}, protocol.CapObserve)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []TaskEventType{
+		TaskEventModelStep,
+		TaskEventCommandDispatched,
+		TaskEventApprovalPending,
+		TaskEventCommandResult,
+		TaskEventModelStep,
+		TaskEventReport,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(got), got)
+	}
+	for i, typ := range want {
+		if got[i].Type != typ {
+			t.Errorf("event %d: expected %s, got %s", i, typ, got[i].Type)
+		}
+		if got[i].Timestamp.IsZero() {
+			t.Errorf("event %d: expected timestamp", i)
+		}
+	}
+	if got[1].Command == nil || got[1].Command.Command != "uptime" {
+		t.Errorf("expected dispatched command uptime, got %+v", got[1].Command)
+	}
+	if got[2].ApprovalID != "apr-1" || got[2].RequestID != got[1].RequestID {
+		t.Errorf("unexpected approval event %+v", got[2])
+	}
+	if got[3].Result == nil || got[3].Result.Stdout != "load average: 0.01" {
+		t.Errorf("unexpected command result %+v", got[3].Result)
+	}
+	if got[5].Report != result {
+		t.Error("expected report event to carry the task result")
+	}
+}
+
+func TestTaskRunnerStopsRepeatedCommandLoop(t *testing.T) {
+	// The model keeps asking for the same command and never summarises.
+	loop := `{"command": "df", "args": ["-h"], "reason": "Check disk"}`
+	srv := mockOpenAIServer([]string{loop, loop, loop, loop, loop, loop})
+	defer srv.Close()
+
+	provider := NewOpenAIProvider(ProviderConfig{Name: "test", BaseURL: srv.URL, Model: "test-model"})
+
+	dispatched := 0
+	runner := NewTaskRunner(provider, func(probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+		dispatched++
+		return &protocol.CommandResultPayload{RequestID: cmd.RequestID, Stdout: "/dev/sda1 40%"}, nil
+	}, noopLogger())
+	runner.SetLoopThreshold(3)
+
+	result, err := runner.Run(context.Background(), "probe-1", "Check disk", &protocol.InventoryPayload{Hostname: "test"}, protocol.CapObserve)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dispatched != 2 {
+		t.Fatalf("expected the third identical command to be blocked, got %d dispatches", dispatched)
+	}
+	if len(result.Guardrails) != 1 || result.Guardrails[0].Condition != GuardrailLoopDetected {
+		t.Fatalf("expected LoopDetected guardrail, got %+v", result.Guardrails)
+	}
+	if result.Guardrails[0].Step != 3 {
+		t.Errorf("expected guardrail at step 3, got %d", result.Guardrails[0].Step)
+	}
+	if result.Error == "" {
+		t.Error("expected error on result")
+	}
+}
+
+func TestTaskRunnerLoopDetectionIgnoresDifferentArgs(t *testing.T) {
+	srv := mockOpenAIServer([]string{
+		`{"command": "ls", "args": ["/a"], "reason": "list"}`,
+		`{"command": "ls", "args": ["/b"], "reason": "list"}`,
+		`{"command": "ls", "args": ["/c"], "reason": "list"}`,
+		"Done.",
+	})
+	defer srv.Close()
+
+	provider := NewOpenAIProvider(ProviderConfig{Name: "test", BaseURL: srv.URL, Model: "test-model"})
+	runner := NewTaskRunner(provider, func(probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+		return &protocol.CommandResultPayload{RequestID: cmd.RequestID}, nil
+	}, noopLogger())
+	runner.SetLoopThreshold(2)
+
+	result, err := runner.Run(context.Background(), "probe-1", "List dirs", &protocol.InventoryPayload{Hostname: "test"}, protocol.CapObserve)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Guardrails) != 0 || len(result.Steps) != 3 {
+		t.Fatalf("expected 3 steps and no guardrails, got %d steps, %+v", len(result.Steps), result.Guardrails)
+	}
+}
+
+// refuseDB is a connectivity manager where only db-01 is unreachable.
+func refuseDB() *connectivity.Manager {
+	m := connectivity.NewManager()
+	m.SetDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+		if strings.HasPrefix(address, "db-01:") {
+			return nil, errors.New("connection refused")
+		}
+		client, server := net.Pipe()
+		_ = server.Close()
+		return client, nil
+	})
+	return m
+}
+
+func TestTaskRunnerFailsFastOnUnreachableRequiredEndpoint(t *testing.T) {
+	srv := mockOpenAIServer([]string{`{"command": "psql", "reason": "query"}`, "Done."})
+	defer srv.Close()
+
+	provider := NewOpenAIProvider(ProviderConfig{Name: "test", BaseURL: srv.URL, Model: "test-model"})
+	dispatched := 0
+	runner := NewTaskRunner(provider, func(probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+		dispatched++
+		return &protocol.CommandResultPayload{RequestID: cmd.RequestID}, nil
+	}, noopLogger())
+	runner.SetConnectivityManager(refuseDB())
+
+	var events []TaskEventType
+	ctx := WithTaskObserver(context.Background(), func(evt TaskEvent) { events = append(events, evt.Type) })
+	result, err := runner.RunWithOptions(ctx, "probe-1", "Check the database", nil, protocol.CapObserve, TaskOptions{
+		Endpoints: []connectivity.Endpoint{
+			{Name: "api", Address: "api-01:443"},
+			{Name: "db-01", Address: "db-01:5432", Required: true},
+		},
+		FailOnUnreachable: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dispatched != 0 || len(result.Steps) != 0 {
+		t.Fatalf("expected no commands to run, got %d dispatches", dispatched)
+	}
+	if len(result.Guardrails) != 1 || result.Guardrails[0].Condition != GuardrailEndpointUnreachable {
+		t.Fatalf("expected EndpointUnreachable guardrail, got %+v", result.Guardrails)
+	}
+	if !strings.Contains(result.Error, "db-01") {
+		t.Errorf("expected error to name db-01, got %q", result.Error)
+	}
+	if result.Connectivity == nil || len(result.Connectivity.Endpoints) != 2 || !result.Connectivity.Endpoints[0].Reachable {
+		t.Fatalf("expected connectivity report with api reachable, got %+v", result.Connectivity)
+	}
+	if len(events) != 2 || events[0] != TaskEventConnectivity || events[1] != TaskEventReport {
+		t.Fatalf("expected connectivity_check then report, got %v", events)
+	}
+}
+
+func TestTaskRunnerProceedsWhenEndpointUnreachableWithoutFailFast(t *testing.T) {
+	srv := mockOpenAIServer([]string{"The database is down."})
+	defer srv.Close()
+
+	provider := NewOpenAIProvider(ProviderConfig{Name: "test", BaseURL: srv.URL, Model: "test-model"})
+	runner := NewTaskRunner(provider, func(probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+		return &protocol.CommandResultPayload{RequestID: cmd.RequestID}, nil
+	}, noopLogger())
+	runner.SetConnectivityManager(refuseDB())
+
+	result, err := runner.RunWithOptions(context.Background(), "probe-1", "Check the database", nil, protocol.CapObserve, TaskOptions{
+		Endpoints: []connectivity.Endpoint{{Name: "db-01", Address: "db-01:5432", Required: true}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Summary != "The database is down." || len(result.Guardrails) != 0 {
+		t.Fatalf("expected task to run to completion, got %+v", result)
+	}
+	if down := result.Connectivity.Unreachable(); len(down) != 1 || down[0].Error == "" {
+		t.Fatalf("expected db-01 recorded as unreachable, got %+v", result.Connectivity)
+	}
+}
+
+func TestEmitTaskEventWithoutObserver(t *testing.T) {
+	// Must not panic when no observer is attached.
+	EmitTaskEvent(context.Background(), TaskEvent{Type: TaskEventReport})
+}
+
 func noopLogger() *zap.Logger {
 	cfg := zap.NewProductionConfig()
 	cfg.OutputPaths = []string{}
@@ -197,3 +404,53 @@ func noopLogger() *zap.Logger {
 	l, _ := cfg.Build()
 	return l
 }
+
+func TestTaskRunnerRedactsProbeSecrets(t *testing.T) {
+	const secret = "hunter2-db-password"
+	srv := mockOpenAIServer([]string{
+		`{"command": "cat", "args": ["/etc/app.conf"], "reason": "Read the app config"}`,
+		"The database password is " + secret + "; vault token hvs.abcdefghijklmnopqrstuvwx.",
+	})
+	defer srv.Close()
+
+	provider := NewOpenAIProvider(ProviderConfig{Name: "test", BaseURL: srv.URL, Model: "test-model"})
+	dispatch := func(probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+		return &protocol.CommandResultPayload{RequestID: cmd.RequestID, Stdout: "db_password=" + secret, Stderr: "warning: " + secret}, nil
+	}
+
+	runner := NewTaskRunner(provider, dispatch, nil)
+	runner.logger = noopLogger()
+	runner.SetSecretSource(func(probeID string) []string {
+		if probeID != "probe-1" {
+			t.Errorf("unexpected probe %q", probeID)
+		}
+		return []string{secret}
+	})
+
+	var events []TaskEvent
+	ctx := WithTaskObserver(context.Background(), func(evt TaskEvent) { events = append(events, evt) })
+	result, err := runner.Run(ctx, "probe-1", "What is in the app config?", nil, protocol.CapObserve)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Steps) != 1 {
+		t.Fatalf("expected 1 step, got %d", len(result.Steps))
+	}
+	for _, text := range []string{result.Steps[0].Stdout, result.Steps[0].Stderr, result.Summary} {
+		if strings.Contains(text, secret) || strings.Contains(text, "hvs.") {
+			t.Errorf("expected secret to be redacted, got %q", text)
+		}
+		if !strings.Contains(text, "[REDACTED]") {
+			t.Errorf("expected redaction placeholder in %q", text)
+		}
+	}
+
+	encoded, err := json.Marshal(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(encoded), secret) {
+		t.Errorf("expected task events to be redacted, got %s", encoded)
+	}
+}
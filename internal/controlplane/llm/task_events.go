@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"context"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/connectivity"
+)
+
+// TaskEventType identifies an intermediate step of a running task.
+type TaskEventType string
+
+const (
+	// TaskEventModelStep carries the model's raw response for a step.
+	TaskEventModelStep TaskEventType = "model_step"
+	// TaskEventCommandDispatched is emitted when the model's command is sent
+	// to the probe (after policy, before any approval wait).
+	TaskEventCommandDispatched TaskEventType = "command_dispatched"
+	// TaskEventApprovalPending is emitted when a command is queued for human
+	// approval; the task is paused until a decision is made.
+	TaskEventApprovalPending TaskEventType = "approval_pending"
+	// TaskEventApprovalDecided is emitted when a pending approval is decided.
+	TaskEventApprovalDecided TaskEventType = "approval_decided"
+	// TaskEventCommandResult carries the outcome of a dispatched command.
+	TaskEventCommandResult TaskEventType = "command_result"
+	// TaskEventConnectivity carries the pre-run endpoint check, before the
+	// first model step.
+	TaskEventConnectivity TaskEventType = "connectivity_check"
+	// TaskEventReport carries the final TaskResult.
+	TaskEventReport TaskEventType = "report"
+)
+
+// TaskEvent is one intermediate update from TaskRunner.Run.
+type TaskEvent struct {
+	Type       TaskEventType   `json:"type"`
+	Timestamp  time.Time       `json:"timestamp"`
+	Step       int             `json:"step,omitempty"`
+	Content    string          `json:"content,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Command    *CommandRequest `json:"command,omitempty"`
+	Result     *TaskStep       `json:"result,omitempty"`
+	ApprovalID string          `json:"approval_id,omitempty"`
+	RiskLevel  string          `json:"risk_level,omitempty"`
+	Decision   string          `json:"decision,omitempty"`
+	Report     *TaskResult     `json:"report,omitempty"`
+	Error      string          `json:"error,omitempty"`
+
+	Connectivity *connectivity.Report `json:"connectivity,omitempty"`
+}
+
+// TaskObserver receives task events. It is called synchronously from the
+// goroutine running the task, so it must not block for long.
+type TaskObserver func(TaskEvent)
+
+type taskObserverKey struct{}
+
+// WithTaskObserver returns a context that makes TaskRunner.Run (and a
+// ContextCommandDispatcher it calls) report intermediate steps to observer.
+func WithTaskObserver(ctx context.Context, observer TaskObserver) context.Context {
+	if observer == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, taskObserverKey{}, observer)
+}
+
+// EmitTaskEvent reports evt to the observer attached to ctx, if any.
+func EmitTaskEvent(ctx context.Context, evt TaskEvent) {
+	observer, _ := ctx.Value(taskObserverKey{}).(TaskObserver)
+	if observer == nil {
+		return
+	}
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = time.Now().UTC()
+	}
+	observer(evt)
+}
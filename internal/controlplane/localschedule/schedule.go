@@ -0,0 +1,126 @@
+// Package localschedule stores the per-probe local schedules the control
+// plane pushes to probes: commands a probe runs on its own cron schedule, so
+// they keep running while it is disconnected.
+package localschedule
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"github.com/robfig/cron/v3"
+)
+
+var entryIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// Schedule is a probe's local schedule. Version increases on every change so
+// results can be matched to the schedule that produced them.
+type Schedule struct {
+	ProbeID   string                        `json:"probe_id"`
+	Version   int64                         `json:"version"`
+	Entries   []protocol.LocalScheduleEntry `json:"entries"`
+	UpdatedBy string                        `json:"updated_by,omitempty"`
+	UpdatedAt time.Time                     `json:"updated_at"`
+}
+
+// Payload returns the message that pushes the schedule to its probe.
+func (s *Schedule) Payload() protocol.LocalSchedulePayload {
+	return protocol.LocalSchedulePayload{
+		Version: s.Version,
+		Entries: append([]protocol.LocalScheduleEntry{}, s.Entries...),
+	}
+}
+
+// Manager is the interface used by handlers to read and replace schedules.
+type Manager interface {
+	Get(probeID string) (*Schedule, bool)
+	// Set validates and replaces a probe's schedule. Empty entries clear it;
+	// the cleared schedule is kept so it is still pushed to the probe.
+	Set(probeID string, entries []protocol.LocalScheduleEntry, actor string) (*Schedule, error)
+}
+
+// Book is an in-memory, concurrency-safe collection of local schedules.
+type Book struct {
+	mu        sync.RWMutex
+	schedules map[string]*Schedule
+}
+
+// NewBook creates an empty schedule book.
+func NewBook() *Book {
+	return &Book{schedules: make(map[string]*Schedule)}
+}
+
+// Get returns a probe's schedule.
+func (b *Book) Get(probeID string) (*Schedule, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	sched, ok := b.schedules[probeID]
+	return sched, ok
+}
+
+// Set validates and replaces a probe's schedule, bumping its version.
+func (b *Book) Set(probeID string, entries []protocol.LocalScheduleEntry, actor string) (*Schedule, error) {
+	entries, err := Validate(entries)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var version int64 = 1
+	if prev, ok := b.schedules[probeID]; ok {
+		version = prev.Version + 1
+	}
+	sched := &Schedule{
+		ProbeID:   probeID,
+		Version:   version,
+		Entries:   entries,
+		UpdatedBy: actor,
+		UpdatedAt: time.Now().UTC(),
+	}
+	b.schedules[probeID] = sched
+	return sched, nil
+}
+
+func (b *Book) restore(sched *Schedule) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.schedules[sched.ProbeID] = sched
+}
+
+// Validate normalises schedule entries and rejects any the probe could not
+// run: bad IDs, duplicate IDs, unparseable cron expressions, empty commands
+// and out-of-range timeouts.
+func Validate(entries []protocol.LocalScheduleEntry) ([]protocol.LocalScheduleEntry, error) {
+	if len(entries) > protocol.MaxLocalScheduleEntries {
+		return nil, fmt.Errorf("at most %d entries are allowed", protocol.MaxLocalScheduleEntries)
+	}
+	out := make([]protocol.LocalScheduleEntry, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		e.ID = strings.TrimSpace(e.ID)
+		e.Schedule = strings.TrimSpace(e.Schedule)
+		e.Command = strings.TrimSpace(e.Command)
+		if !entryIDPattern.MatchString(e.ID) {
+			return nil, fmt.Errorf("entry id %q must be 1-64 lowercase letters, digits, '.', '_' or '-'", e.ID)
+		}
+		if seen[e.ID] {
+			return nil, fmt.Errorf("duplicate entry id %q", e.ID)
+		}
+		seen[e.ID] = true
+		if _, err := cron.ParseStandard(e.Schedule); err != nil {
+			return nil, fmt.Errorf("entry %s: invalid schedule %q: %v", e.ID, e.Schedule, err)
+		}
+		if e.Command == "" {
+			return nil, fmt.Errorf("entry %s: command is required", e.ID)
+		}
+		if e.TimeoutSec < 0 || e.TimeoutSec > protocol.MaxLocalScheduleTimeoutSec {
+			return nil, fmt.Errorf("entry %s: timeout_sec must be between 0 and %d", e.ID, protocol.MaxLocalScheduleTimeoutSec)
+		}
+		e.Args = append([]string(nil), e.Args...)
+		out = append(out, e)
+	}
+	return out, nil
+}
@@ -0,0 +1,85 @@
+package localschedule
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestBookSetBumpsVersion(t *testing.T) {
+	b := NewBook()
+	sched, err := b.Set("probe-1", []protocol.LocalScheduleEntry{
+		{ID: " disk ", Schedule: "*/5 * * * *", Command: "df", Args: []string{"-h"}},
+	}, "alice")
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if sched.Version != 1 || sched.Entries[0].ID != "disk" || sched.UpdatedBy != "alice" {
+		t.Fatalf("unexpected schedule %+v", sched)
+	}
+
+	cleared, err := b.Set("probe-1", nil, "bob")
+	if err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if cleared.Version != 2 || len(cleared.Entries) != 0 {
+		t.Fatalf("expected empty version 2, got %+v", cleared)
+	}
+	if got, ok := b.Get("probe-1"); !ok || got.Version != 2 {
+		t.Fatalf("cleared schedule should be kept, got %+v %v", got, ok)
+	}
+	if payload := cleared.Payload(); payload.Entries == nil {
+		t.Fatalf("payload entries should encode as an empty list")
+	}
+}
+
+func TestValidateRejectsInvalidEntries(t *testing.T) {
+	for _, e := range []protocol.LocalScheduleEntry{
+		{ID: "Bad ID", Schedule: "* * * * *", Command: "df"},
+		{ID: "cron", Schedule: "every minute", Command: "df"},
+		{ID: "seconds", Schedule: "0 * * * * *", Command: "df"},
+		{ID: "empty", Schedule: "* * * * *", Command: "  "},
+		{ID: "timeout", Schedule: "* * * * *", Command: "df", TimeoutSec: protocol.MaxLocalScheduleTimeoutSec + 1},
+	} {
+		if _, err := Validate([]protocol.LocalScheduleEntry{e}); err == nil {
+			t.Fatalf("expected %+v to be rejected", e)
+		}
+	}
+	dup := protocol.LocalScheduleEntry{ID: "disk", Schedule: "@hourly", Command: "df"}
+	if _, err := Validate([]protocol.LocalScheduleEntry{dup, dup}); err == nil {
+		t.Fatalf("expected duplicate ids to be rejected")
+	}
+	many := make([]protocol.LocalScheduleEntry, protocol.MaxLocalScheduleEntries+1)
+	if _, err := Validate(many); err == nil {
+		t.Fatalf("expected too many entries to be rejected")
+	}
+}
+
+func TestPersistentBookSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "local_schedules.db")
+	pb, err := NewPersistentBook(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := pb.Set("probe-1", []protocol.LocalScheduleEntry{{ID: "disk", Schedule: "@hourly", Command: "df", OfflineOnly: true}}, "alice"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if _, err := pb.Set("probe-1", []protocol.LocalScheduleEntry{{ID: "load", Schedule: "@hourly", Command: "uptime"}}, "alice"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	_ = pb.Close()
+
+	reopened, err := NewPersistentBook(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	sched, ok := reopened.Get("probe-1")
+	if !ok || sched.Version != 2 || len(sched.Entries) != 1 || sched.Entries[0].ID != "load" {
+		t.Fatalf("unexpected reloaded schedule %+v", sched)
+	}
+	if next, _ := reopened.Set("probe-1", nil, "bob"); next.Version != 3 {
+		t.Fatalf("version should continue after reopen, got %d", next.Version)
+	}
+}
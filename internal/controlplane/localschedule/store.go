@@ -0,0 +1,125 @@
+package localschedule
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/migration"
+	"github.com/marcus-qen/legator/internal/protocol"
+	_ "modernc.org/sqlite"
+)
+
+// PersistentBook wraps Book with SQLite persistence.
+type PersistentBook struct {
+	*Book
+	db *sql.DB
+}
+
+// NewPersistentBook opens (or creates) a SQLite-backed schedule book.
+func NewPersistentBook(dbPath string) (*PersistentBook, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open local schedules db: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec("PRAGMA busy_timeout=5000"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("set busy_timeout: %w", err)
+	}
+
+	runner := migration.NewRunner("local_schedules", []migration.Migration{
+		{
+			Version:     1,
+			Description: "initial local schedule schema",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS local_schedules (
+					probe_id     TEXT PRIMARY KEY,
+					version      INTEGER NOT NULL,
+					entries_json TEXT NOT NULL,
+					updated_by   TEXT NOT NULL DEFAULT '',
+					updated_at   TEXT NOT NULL
+				)`)
+				return err
+			},
+		},
+	})
+	if err := runner.Migrate(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("migrate local schedules db: %w", err)
+	}
+
+	pb := &PersistentBook{Book: NewBook(), db: db}
+	if err := pb.loadFromDB(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return pb, nil
+}
+
+// Set validates and replaces a probe's schedule, in memory and on disk.
+func (pb *PersistentBook) Set(probeID string, entries []protocol.LocalScheduleEntry, actor string) (*Schedule, error) {
+	prev, hadPrev := pb.Book.Get(probeID)
+	sched, err := pb.Book.Set(probeID, entries, actor)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(sched.Entries)
+	if err == nil {
+		_, err = pb.db.Exec(`INSERT INTO local_schedules
+			(probe_id, version, entries_json, updated_by, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(probe_id) DO UPDATE SET
+				version = excluded.version,
+				entries_json = excluded.entries_json,
+				updated_by = excluded.updated_by,
+				updated_at = excluded.updated_at`,
+			sched.ProbeID, sched.Version, string(data), sched.UpdatedBy, sched.UpdatedAt.Format(time.RFC3339Nano),
+		)
+	}
+	if err != nil {
+		pb.mu.Lock()
+		if hadPrev {
+			pb.schedules[probeID] = prev
+		} else {
+			delete(pb.schedules, probeID)
+		}
+		pb.mu.Unlock()
+		return nil, fmt.Errorf("persist local schedule: %w", err)
+	}
+	return sched, nil
+}
+
+// Close shuts down the database.
+func (pb *PersistentBook) Close() error {
+	return pb.db.Close()
+}
+
+func (pb *PersistentBook) loadFromDB() error {
+	rows, err := pb.db.Query(`SELECT probe_id, version, entries_json, updated_by, updated_at FROM local_schedules`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			sched      Schedule
+			entries    string
+			updatedStr string
+		)
+		if err := rows.Scan(&sched.ProbeID, &sched.Version, &entries, &sched.UpdatedBy, &updatedStr); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(entries), &sched.Entries); err != nil {
+			return fmt.Errorf("load local schedule for %s: %w", sched.ProbeID, err)
+		}
+		sched.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedStr)
+		pb.Book.restore(&sched)
+	}
+	return rows.Err()
+}
@@ -5,6 +5,7 @@ package mcpclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"time"
@@ -13,6 +14,21 @@ import (
 	"go.uber.org/zap"
 )
 
+// Default timeouts applied when ServerConfig leaves them unset.
+const (
+	DefaultConnectTimeout = 30 * time.Second
+	DefaultCallTimeout    = 60 * time.Second
+)
+
+// ErrConnectTimeout is returned when the initialization handshake with a
+// server does not finish within its connect timeout.
+var ErrConnectTimeout = errors.New("mcpclient: connect timed out")
+
+// ErrToolTimeout is returned when a tool call does not finish within its
+// effective timeout. The caller's own cancellation is not reported as a
+// tool timeout.
+var ErrToolTimeout = errors.New("mcpclient: tool call timed out")
+
 // TransportType identifies the transport mechanism for an external MCP server.
 type TransportType string
 
@@ -38,6 +54,9 @@ type ServerConfig struct {
 	ConnectTimeout time.Duration
 	// CallTimeout caps individual tool calls.
 	CallTimeout time.Duration
+	// ToolTimeouts overrides CallTimeout for specific tools, keyed by the
+	// tool's name on this server.
+	ToolTimeouts map[string]time.Duration
 	// Env holds extra environment variables for stdio transport.
 	Env []string
 }
@@ -58,10 +77,7 @@ func Connect(ctx context.Context, cfg ServerConfig, logger *zap.Logger) (*Server
 		logger = zap.NewNop()
 	}
 
-	timeout := cfg.ConnectTimeout
-	if timeout <= 0 {
-		timeout = 30 * time.Second
-	}
+	timeout := cfg.effectiveConnectTimeout()
 
 	connCtx, cancel := context.WithTimeout(ctx, timeout)
 
@@ -93,7 +109,11 @@ func Connect(ctx context.Context, cfg ServerConfig, logger *zap.Logger) (*Server
 
 	sess, err := mcpClient.Connect(connCtx, transport, nil)
 	if err != nil {
+		timedOut := errors.Is(connCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
 		cancel()
+		if timedOut {
+			return nil, fmt.Errorf("%w: %q after %s: %v", ErrConnectTimeout, cfg.Name, timeout, err)
+		}
 		return nil, fmt.Errorf("mcpclient: connect to %q: %w", cfg.Name, err)
 	}
 
@@ -124,20 +144,43 @@ func (sc *ServerClient) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
 }
 
 // CallTool invokes a named tool on the remote server with the given arguments.
+// The call is bounded by the tool's effective timeout (see ToolTimeout); when
+// it expires the returned error wraps ErrToolTimeout.
 func (sc *ServerClient) CallTool(ctx context.Context, toolName string, arguments map[string]any) (*mcp.CallToolResult, error) {
-	ctx, cancel := sc.callCtx(ctx)
+	return sc.CallToolWithTimeout(ctx, toolName, arguments, sc.ToolTimeout(toolName))
+}
+
+// CallToolWithTimeout is CallTool with an explicit timeout.
+func (sc *ServerClient) CallToolWithTimeout(ctx context.Context, toolName string, arguments map[string]any, timeout time.Duration) (*mcp.CallToolResult, error) {
+	if timeout <= 0 {
+		timeout = sc.ToolTimeout(toolName)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	res, err := sc.sess.CallTool(ctx, &mcp.CallToolParams{
+	res, err := sc.sess.CallTool(callCtx, &mcp.CallToolParams{
 		Name:      toolName,
 		Arguments: arguments,
 	})
 	if err != nil {
+		if errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("%w: %q on %q after %s", ErrToolTimeout, toolName, sc.cfg.Name, timeout)
+		}
 		return nil, fmt.Errorf("mcpclient: call tool %q on %q: %w", toolName, sc.cfg.Name, err)
 	}
 	return res, nil
 }
 
+// ToolTimeout returns the effective timeout for a tool on this server: its
+// ToolTimeouts entry if set, otherwise CallTimeout, otherwise
+// DefaultCallTimeout.
+func (sc *ServerClient) ToolTimeout(toolName string) time.Duration {
+	if d := sc.cfg.ToolTimeouts[toolName]; d > 0 {
+		return d
+	}
+	return sc.cfg.effectiveCallTimeout()
+}
+
 // Close tears down the session and underlying transport.
 func (sc *ServerClient) Close() error {
 	if sc.cancel != nil {
@@ -151,9 +194,19 @@ func (sc *ServerClient) Close() error {
 
 // callCtx returns a context capped by CallTimeout (if set).
 func (sc *ServerClient) callCtx(parent context.Context) (context.Context, context.CancelFunc) {
-	timeout := sc.cfg.CallTimeout
-	if timeout <= 0 {
-		timeout = 60 * time.Second
+	return context.WithTimeout(parent, sc.cfg.effectiveCallTimeout())
+}
+
+func (cfg ServerConfig) effectiveConnectTimeout() time.Duration {
+	if cfg.ConnectTimeout > 0 {
+		return cfg.ConnectTimeout
+	}
+	return DefaultConnectTimeout
+}
+
+func (cfg ServerConfig) effectiveCallTimeout() time.Duration {
+	if cfg.CallTimeout > 0 {
+		return cfg.CallTimeout
 	}
-	return context.WithTimeout(parent, timeout)
+	return DefaultCallTimeout
 }
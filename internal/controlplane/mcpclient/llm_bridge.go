@@ -3,6 +3,7 @@ package mcpclient
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -29,11 +30,22 @@ type LLMToolCall struct {
 	Arguments map[string]any
 }
 
+// Tool call outcomes reported in LLMToolResult.Status.
+const (
+	ToolStatusOK      = "ok"
+	ToolStatusError   = "error"
+	ToolStatusTimeout = "timeout"
+)
+
 // LLMToolResult is the result returned to the LLM after a tool call.
 type LLMToolResult struct {
 	QualifiedName string `json:"name"`
 	Content       string `json:"content"`
 	IsError       bool   `json:"is_error,omitempty"`
+	// Status is ok, error, or timeout when the call hit its time limit.
+	Status string `json:"status"`
+	// TimeoutMS is the effective timeout the call ran under.
+	TimeoutMS int64 `json:"timeout_ms"`
 }
 
 // Bridge converts between MCP tool definitions and LLM function-calling format
@@ -93,20 +105,33 @@ func (b *Bridge) Invoke(ctx context.Context, call LLMToolCall) (*LLMToolResult,
 		}
 	}
 
+	timeoutMS := b.registry.ToolTimeout(server, tool).Milliseconds()
 	res, err := b.registry.CallTool(ctx, server, tool, call.Arguments)
 	if err != nil {
+		status := ToolStatusError
+		if errors.Is(err, ErrToolTimeout) {
+			status = ToolStatusTimeout
+		}
 		return &LLMToolResult{
 			QualifiedName: qn,
 			Content:       err.Error(),
 			IsError:       true,
+			Status:        status,
+			TimeoutMS:     timeoutMS,
 		}, nil
 	}
 
 	content := contentToText(res)
+	status := ToolStatusOK
+	if res.IsError {
+		status = ToolStatusError
+	}
 	return &LLMToolResult{
 		QualifiedName: qn,
 		Content:       content,
 		IsError:       res.IsError,
+		Status:        status,
+		TimeoutMS:     timeoutMS,
 	}, nil
 }
 
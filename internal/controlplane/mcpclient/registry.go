@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,6 +30,11 @@ type ServerStatus struct {
 	ToolCount int       `json:"tool_count"`
 	LastSeen  time.Time `json:"last_seen,omitempty"`
 	Error     string    `json:"error,omitempty"`
+	// ConnectTimeout and CallTimeout are the effective server timeouts;
+	// ToolTimeouts lists per-tool overrides. Durations use Go syntax ("30s").
+	ConnectTimeout string            `json:"connect_timeout"`
+	CallTimeout    string            `json:"call_timeout"`
+	ToolTimeouts   map[string]string `json:"tool_timeouts,omitempty"`
 }
 
 // Registry manages connections to multiple external MCP servers and provides
@@ -37,7 +43,10 @@ type Registry struct {
 	mu       sync.RWMutex
 	clients  map[string]*ServerClient
 	statuses map[string]*ServerStatus
-	logger   *zap.Logger
+	// toolTimeouts holds overrides set with SetToolTimeout, keyed by
+	// qualified tool name. They take precedence over ServerConfig.
+	toolTimeouts map[string]time.Duration
+	logger       *zap.Logger
 }
 
 // NewRegistry creates an empty registry.
@@ -46,9 +55,10 @@ func NewRegistry(logger *zap.Logger) *Registry {
 		logger = zap.NewNop()
 	}
 	return &Registry{
-		clients:  make(map[string]*ServerClient),
-		statuses: make(map[string]*ServerStatus),
-		logger:   logger.Named("mcp.registry"),
+		clients:      make(map[string]*ServerClient),
+		statuses:     make(map[string]*ServerStatus),
+		toolTimeouts: make(map[string]time.Duration),
+		logger:       logger.Named("mcp.registry"),
 	}
 }
 
@@ -64,6 +74,7 @@ func (r *Registry) Add(ctx context.Context, cfg ServerConfig) error {
 			Transport: string(cfg.Transport),
 			Error:     err.Error(),
 		}
+		r.applyTimeoutsLocked(cfg)
 		r.mu.Unlock()
 		return err
 	}
@@ -93,9 +104,83 @@ func (r *Registry) Add(ctx context.Context, cfg ServerConfig) error {
 		ToolCount: count,
 		LastSeen:  time.Now().UTC(),
 	}
+	r.applyTimeoutsLocked(cfg)
 	return nil
 }
 
+// SetToolTimeout overrides the timeout for one tool, identified by
+// "<server>/<tool>". A zero or negative duration removes the override.
+func (r *Registry) SetToolTimeout(qualifiedName string, timeout time.Duration) error {
+	server, tool, err := splitQualifiedName(qualifiedName)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if timeout <= 0 {
+		delete(r.toolTimeouts, qualifiedName)
+	} else {
+		r.toolTimeouts[qualifiedName] = timeout
+	}
+	if sc, ok := r.clients[server]; ok {
+		r.applyTimeoutsLocked(sc.cfg)
+	} else if st, ok := r.statuses[server]; ok && timeout > 0 {
+		if st.ToolTimeouts == nil {
+			st.ToolTimeouts = make(map[string]string)
+		}
+		st.ToolTimeouts[tool] = timeout.String()
+	}
+	return nil
+}
+
+// ToolTimeout returns the effective timeout for a tool: a SetToolTimeout
+// override, then the server's ToolTimeouts entry, then its CallTimeout,
+// then DefaultCallTimeout.
+func (r *Registry) ToolTimeout(serverName, toolName string) time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.toolTimeoutLocked(serverName, toolName)
+}
+
+func (r *Registry) toolTimeoutLocked(serverName, toolName string) time.Duration {
+	if d, ok := r.toolTimeouts[serverName+"/"+toolName]; ok {
+		return d
+	}
+	if sc, ok := r.clients[serverName]; ok {
+		return sc.ToolTimeout(toolName)
+	}
+	return DefaultCallTimeout
+}
+
+// applyTimeoutsLocked records the effective timeouts for cfg on its status.
+// The caller must hold r.mu.
+func (r *Registry) applyTimeoutsLocked(cfg ServerConfig) {
+	st, ok := r.statuses[cfg.Name]
+	if !ok {
+		return
+	}
+	st.ConnectTimeout = cfg.effectiveConnectTimeout().String()
+	st.CallTimeout = cfg.effectiveCallTimeout().String()
+	st.ToolTimeouts = nil
+	set := func(tool string, d time.Duration) {
+		if st.ToolTimeouts == nil {
+			st.ToolTimeouts = make(map[string]string)
+		}
+		st.ToolTimeouts[tool] = d.String()
+	}
+	for tool, d := range cfg.ToolTimeouts {
+		if d > 0 {
+			set(tool, d)
+		}
+	}
+	prefix := cfg.Name + "/"
+	for qn, d := range r.toolTimeouts {
+		if strings.HasPrefix(qn, prefix) {
+			set(strings.TrimPrefix(qn, prefix), d)
+		}
+	}
+}
+
 // Remove disconnects and removes a named server.
 func (r *Registry) Remove(name string) {
 	r.mu.Lock()
@@ -113,7 +198,14 @@ func (r *Registry) ListServers() []ServerStatus {
 	defer r.mu.RUnlock()
 	out := make([]ServerStatus, 0, len(r.statuses))
 	for _, s := range r.statuses {
-		out = append(out, *s)
+		st := *s
+		if s.ToolTimeouts != nil {
+			st.ToolTimeouts = make(map[string]string, len(s.ToolTimeouts))
+			for k, v := range s.ToolTimeouts {
+				st.ToolTimeouts[k] = v
+			}
+		}
+		out = append(out, st)
 	}
 	return out
 }
@@ -157,15 +249,16 @@ func (r *Registry) ListTools(ctx context.Context) ([]ToolEntry, error) {
 }
 
 // CallTool invokes a tool identified by qualified name ("<server>/<tool>")
-// or by (serverName, toolName) pair.
+// or by (serverName, toolName) pair, bounded by the tool's effective timeout.
 func (r *Registry) CallTool(ctx context.Context, serverName, toolName string, arguments map[string]any) (*mcp.CallToolResult, error) {
 	r.mu.RLock()
 	sc, ok := r.clients[serverName]
+	timeout := r.toolTimeoutLocked(serverName, toolName)
 	r.mu.RUnlock()
 	if !ok {
 		return nil, fmt.Errorf("mcpclient: no connected server %q", serverName)
 	}
-	return sc.CallTool(ctx, toolName, arguments)
+	return sc.CallToolWithTimeout(ctx, toolName, arguments, timeout)
 }
 
 // CallToolByQualifiedName parses "<server>/<tool>" and calls the tool.
@@ -227,3 +227,75 @@ func TestRegistry_Add_ConnectionError(t *testing.T) {
 		t.Error("error field should be set")
 	}
 }
+
+func TestRegistry_ToolTimeout(t *testing.T) {
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1"}, nil)
+	srv.AddTool(&mcp.Tool{Name: "hang", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	srv.AddTool(&mcp.Tool{Name: "fast", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+	})
+	ts := httptest.NewServer(mcp.NewSSEHandler(func(_ *http.Request) *mcp.Server { return srv }, nil))
+	t.Cleanup(ts.Close)
+
+	reg := mcpclient.NewRegistry(nil)
+	defer reg.Close()
+	if err := reg.Add(context.Background(), mcpclient.ServerConfig{
+		Name:           "slow",
+		Transport:      mcpclient.TransportSSE,
+		Endpoint:       ts.URL,
+		ConnectTimeout: 10 * time.Second,
+		CallTimeout:    10 * time.Second,
+		ToolTimeouts:   map[string]time.Duration{"fast": 5 * time.Second},
+	}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := reg.SetToolTimeout("slow/hang", 100*time.Millisecond); err != nil {
+		t.Fatalf("SetToolTimeout: %v", err)
+	}
+
+	if got := reg.ToolTimeout("slow", "hang"); got != 100*time.Millisecond {
+		t.Errorf("hang timeout = %s, want 100ms", got)
+	}
+	if got := reg.ToolTimeout("slow", "fast"); got != 5*time.Second {
+		t.Errorf("fast timeout = %s, want 5s", got)
+	}
+	if got := reg.ToolTimeout("slow", "other"); got != 10*time.Second {
+		t.Errorf("default timeout = %s, want 10s", got)
+	}
+
+	bridge := mcpclient.NewBridge(reg)
+	started := time.Now()
+	res, err := bridge.Invoke(context.Background(), mcpclient.LLMToolCall{QualifiedName: "slow/hang"})
+	if err != nil {
+		t.Fatalf("Invoke: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("call was not cut short: %s", elapsed)
+	}
+	if res.Status != mcpclient.ToolStatusTimeout || !res.IsError || res.TimeoutMS != 100 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	res, err = bridge.Invoke(context.Background(), mcpclient.LLMToolCall{QualifiedName: "slow/fast"})
+	if err != nil {
+		t.Fatalf("Invoke fast: %v", err)
+	}
+	if res.Status != mcpclient.ToolStatusOK || res.TimeoutMS != 5000 {
+		t.Fatalf("unexpected fast result %+v", res)
+	}
+
+	servers := reg.ListServers()
+	if len(servers) != 1 {
+		t.Fatalf("expected 1 server, got %d", len(servers))
+	}
+	st := servers[0]
+	if st.ConnectTimeout != "10s" || st.CallTimeout != "10s" {
+		t.Errorf("unexpected server timeouts connect=%q call=%q", st.ConnectTimeout, st.CallTimeout)
+	}
+	if st.ToolTimeouts["hang"] != "100ms" || st.ToolTimeouts["fast"] != "5s" {
+		t.Errorf("unexpected tool timeouts %v", st.ToolTimeouts)
+	}
+}
@@ -55,6 +55,7 @@ type searchAuditInput struct {
 	ProbeID string `json:"probe_id,omitempty" jsonschema:"optional probe id filter"`
 	Type    string `json:"type,omitempty" jsonschema:"optional audit event type filter"`
 	Since   string `json:"since,omitempty" jsonschema:"optional ISO-8601 timestamp filter"`
+	Query   string `json:"query,omitempty" jsonschema:"optional free-text search over summary and detail"`
 	Limit   int    `json:"limit,omitempty" jsonschema:"optional limit (default 50)"`
 }
 
@@ -62,6 +63,7 @@ type decideApprovalInput struct {
 	ApprovalID string `json:"approval_id" jsonschema:"approval request identifier"`
 	Decision   string `json:"decision" jsonschema:"approval decision: approved or denied"`
 	DecidedBy  string `json:"decided_by" jsonschema:"operator identity recording the decision"`
+	Reason     string `json:"reason,omitempty" jsonschema:"justification for the decision; required for some risk levels"`
 }
 
 type kubeflowRunStatusInput struct {
@@ -139,6 +141,7 @@ type jobRunsSummaryPayload struct {
 	QueuedCount   int           `json:"queued_count"`
 	CanceledCount int           `json:"canceled_count"`
 	DeniedCount   int           `json:"denied_count"`
+	TimedOutCount int           `json:"timed_out_count"`
 }
 
 type mcpRunSummary struct {
@@ -149,6 +152,7 @@ type mcpRunSummary struct {
 	Failed   int
 	Canceled int
 	Denied   int
+	TimedOut int
 }
 
 type pollActiveJobStatusPayload struct {
@@ -414,7 +418,7 @@ func (s *MCPServer) handleDecideApproval(_ context.Context, _ *mcp.CallToolReque
 		return nil, nil, fmt.Errorf("approval service unavailable")
 	}
 
-	invokeInput, err := coreapprovalpolicy.AssembleDecideApprovalInvokeMCP(input.ApprovalID, input.Decision, input.DecidedBy)
+	invokeInput, err := coreapprovalpolicy.AssembleDecideApprovalInvokeMCP(input.ApprovalID, input.Decision, input.DecidedBy, input.Reason)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -656,6 +660,7 @@ func (s *MCPServer) handleSearchAudit(_ context.Context, _ *mcp.CallToolRequest,
 	filter := audit.Filter{
 		ProbeID: strings.TrimSpace(input.ProbeID),
 		Type:    audit.EventType(strings.TrimSpace(input.Type)),
+		Query:   strings.TrimSpace(input.Query),
 		Limit:   limit,
 	}
 
@@ -736,6 +741,7 @@ func (s *MCPServer) handleListJobRuns(_ context.Context, _ *mcp.CallToolRequest,
 		QueuedCount:   summary.Queued,
 		CanceledCount: summary.Canceled,
 		DeniedCount:   summary.Denied,
+		TimedOutCount: summary.TimedOut,
 	}
 	return jsonToolResult(payload)
 }
@@ -1028,10 +1034,10 @@ func parseMCPJobRunQuery(input listJobRunsInput) (jobs.RunQuery, error) {
 	status := strings.TrimSpace(input.Status)
 	if status != "" {
 		switch status {
-		case jobs.RunStatusQueued, jobs.RunStatusPending, jobs.RunStatusRunning, jobs.RunStatusSuccess, jobs.RunStatusFailed, jobs.RunStatusCanceled, jobs.RunStatusDenied:
+		case jobs.RunStatusQueued, jobs.RunStatusPending, jobs.RunStatusRunning, jobs.RunStatusSuccess, jobs.RunStatusFailed, jobs.RunStatusCanceled, jobs.RunStatusDenied, jobs.RunStatusTimedOut:
 			query.Status = status
 		default:
-			return jobs.RunQuery{}, fmt.Errorf("status must be one of: queued, pending, running, success, failed, canceled, denied")
+			return jobs.RunQuery{}, fmt.Errorf("status must be one of: queued, pending, running, success, failed, canceled, denied, timed_out")
 		}
 	}
 
@@ -1085,6 +1091,8 @@ func summarizeMCPRuns(runs []jobs.JobRun) mcpRunSummary {
 			summary.Canceled++
 		case jobs.RunStatusDenied:
 			summary.Denied++
+		case jobs.RunStatusTimedOut:
+			summary.TimedOut++
 		}
 	}
 	return summary
@@ -1092,7 +1100,7 @@ func summarizeMCPRuns(runs []jobs.JobRun) mcpRunSummary {
 
 func isTerminalRunStatus(status string) bool {
 	switch status {
-	case jobs.RunStatusSuccess, jobs.RunStatusFailed, jobs.RunStatusCanceled, jobs.RunStatusDenied:
+	case jobs.RunStatusSuccess, jobs.RunStatusFailed, jobs.RunStatusCanceled, jobs.RunStatusDenied, jobs.RunStatusTimedOut:
 		return true
 	default:
 		return false
@@ -1113,7 +1121,12 @@ func isJobLifecycleAuditEvent(typ audit.EventType) bool {
 		audit.EventJobRunSucceeded,
 		audit.EventJobRunFailed,
 		audit.EventJobRunCanceled,
-		audit.EventJobRunDenied:
+		audit.EventJobRunDenied,
+		audit.EventJobRunBlocked,
+		audit.EventJobRunUnblocked,
+		audit.EventJobRunDependencyTimeout,
+		audit.EventJobRunTimedOut,
+		audit.EventJobRunSkipped:
 		return true
 	default:
 		return false
@@ -1134,7 +1147,12 @@ func isJobLifecycleBusEvent(typ events.EventType) bool {
 		events.JobRunSucceeded,
 		events.JobRunFailed,
 		events.JobRunCanceled,
-		events.JobRunDenied:
+		events.JobRunDenied,
+		events.JobRunBlocked,
+		events.JobRunUnblocked,
+		events.JobRunDependencyTimeout,
+		events.JobRunTimedOut,
+		events.JobRunSkipped:
 		return true
 	default:
 		return false
@@ -36,6 +36,12 @@ type AuditCounter interface {
 	Count() int
 }
 
+// AuditForwarderStats provides audit forwarder delivery stats.
+type AuditForwarderStats interface {
+	DroppedCount() uint64
+	ForwardedCount() uint64
+}
+
 // AsyncJobMetricsSource provides async worker queue/state metrics.
 type AsyncJobMetricsSource interface {
 	AsyncJobStateCounts() map[string]int
@@ -56,11 +62,13 @@ type Collector struct {
 	approvals ApprovalCounter
 	audit     AuditCounter
 	asyncJobs AsyncJobMetricsSource
+	auditFwd  AuditForwarderStats
 	startTime time.Time
 
 	mu              sync.RWMutex
 	webhookSent     map[string]map[string]uint64
 	webhookErrors   map[string]map[string]uint64
+	webhookFailures map[string]map[string]uint64
 	webhookDuration map[string]*webhookHistogram
 }
 
@@ -75,12 +83,22 @@ func NewCollector(fleet FleetCounter, hub HubStats, approvals ApprovalCounter, a
 		startTime:       time.Now(),
 		webhookSent:     make(map[string]map[string]uint64),
 		webhookErrors:   make(map[string]map[string]uint64),
+		webhookFailures: make(map[string]map[string]uint64),
 		webhookDuration: make(map[string]*webhookHistogram),
 	}
 }
 
-// RecordWebhookDelivery records webhook delivery metrics for one dispatch attempt.
-func (c *Collector) RecordWebhookDelivery(eventType string, statusCode int, duration time.Duration, err error) {
+// SetAuditForwarder exposes delivery stats for an optional audit forwarder.
+func (c *Collector) SetAuditForwarder(src AuditForwarderStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.auditFwd = src
+}
+
+// RecordWebhookDelivery records webhook delivery metrics for one dispatch
+// attempt. Failed first attempts and deliveries whose retries are exhausted
+// are additionally counted by stage.
+func (c *Collector) RecordWebhookDelivery(eventType string, attempt, statusCode int, duration time.Duration, err error, exhausted bool) {
 	if eventType == "" {
 		eventType = "unknown"
 	}
@@ -119,6 +137,16 @@ func (c *Collector) RecordWebhookDelivery(eventType string, statusCode int, dura
 			c.webhookErrors[eventType] = make(map[string]uint64)
 		}
 		c.webhookErrors[eventType][errorType]++
+
+		if c.webhookFailures[eventType] == nil {
+			c.webhookFailures[eventType] = map[string]uint64{"first_attempt": 0, "exhausted": 0}
+		}
+		if attempt <= 1 {
+			c.webhookFailures[eventType]["first_attempt"]++
+		}
+		if exhausted {
+			c.webhookFailures[eventType]["exhausted"]++
+		}
 	}
 }
 
@@ -162,6 +190,7 @@ func (c *Collector) Handler() http.HandlerFunc {
 		b.WriteString("# HELP legator_audit_events_total Total audit events recorded.\n")
 		b.WriteString("# TYPE legator_audit_events_total counter\n")
 		fmt.Fprintf(&b, "legator_audit_events_total %d\n", c.audit.Count())
+		c.renderAuditForwarderMetrics(&b)
 
 		// Tag distribution
 		tags := c.fleet.TagCounts()
@@ -185,8 +214,24 @@ func (c *Collector) Handler() http.HandlerFunc {
 	}
 }
 
+func (c *Collector) renderAuditForwarderMetrics(b *strings.Builder) {
+	c.mu.RLock()
+	src := c.auditFwd
+	c.mu.RUnlock()
+	if src == nil {
+		return
+	}
+
+	b.WriteString("# HELP legator_audit_forwarded_total Audit events delivered to the external forwarder.\n")
+	b.WriteString("# TYPE legator_audit_forwarded_total counter\n")
+	fmt.Fprintf(b, "legator_audit_forwarded_total %d\n", src.ForwardedCount())
+	b.WriteString("# HELP legator_audit_forward_dropped_total Audit events dropped by the external forwarder because its buffer was full.\n")
+	b.WriteString("# TYPE legator_audit_forward_dropped_total counter\n")
+	fmt.Fprintf(b, "legator_audit_forward_dropped_total %d\n", src.DroppedCount())
+}
+
 func (c *Collector) renderWebhookMetrics(b *strings.Builder) {
-	sent, errs, durations := c.snapshotWebhookMetrics()
+	sent, errs, failures, durations := c.snapshotWebhookMetrics()
 
 	b.WriteString("# HELP legator_webhooks_sent_total Total webhook deliveries by event type and status.\n")
 	b.WriteString("# TYPE legator_webhooks_sent_total counter\n")
@@ -206,6 +251,15 @@ func (c *Collector) renderWebhookMetrics(b *strings.Builder) {
 		}
 	}
 
+	b.WriteString("# HELP legator_webhook_delivery_failures_total Failed webhook deliveries by stage (first_attempt, exhausted).\n")
+	b.WriteString("# TYPE legator_webhook_delivery_failures_total counter\n")
+	for _, eventType := range sortedKeysFromUint64Nested(failures) {
+		stages := failures[eventType]
+		for _, stage := range []string{"first_attempt", "exhausted"} {
+			fmt.Fprintf(b, "legator_webhook_delivery_failures_total{event_type=%q,stage=%q} %d\n", eventType, stage, stages[stage])
+		}
+	}
+
 	b.WriteString("# HELP legator_webhook_duration_seconds Webhook delivery duration in seconds.\n")
 	b.WriteString("# TYPE legator_webhook_duration_seconds histogram\n")
 	for _, eventType := range sortedKeysFromHistogramMap(durations) {
@@ -257,7 +311,7 @@ func renderDurationHistogramMetric(b *strings.Builder, metricName, help string,
 	fmt.Fprintf(b, "%s_count %d\n", metricName, count)
 }
 
-func (c *Collector) snapshotWebhookMetrics() (map[string]map[string]uint64, map[string]map[string]uint64, map[string]webhookHistogram) {
+func (c *Collector) snapshotWebhookMetrics() (map[string]map[string]uint64, map[string]map[string]uint64, map[string]map[string]uint64, map[string]webhookHistogram) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -277,6 +331,14 @@ func (c *Collector) snapshotWebhookMetrics() (map[string]map[string]uint64, map[
 		}
 	}
 
+	failures := make(map[string]map[string]uint64, len(c.webhookFailures))
+	for eventType, stages := range c.webhookFailures {
+		failures[eventType] = make(map[string]uint64, len(stages))
+		for stage, count := range stages {
+			failures[eventType][stage] = count
+		}
+	}
+
 	durations := make(map[string]webhookHistogram, len(c.webhookDuration))
 	for eventType, hist := range c.webhookDuration {
 		clone := webhookHistogram{
@@ -288,7 +350,7 @@ func (c *Collector) snapshotWebhookMetrics() (map[string]map[string]uint64, map[
 		durations[eventType] = clone
 	}
 
-	return sent, errs, durations
+	return sent, errs, failures, durations
 }
 
 func sortedKeysFromUint64Nested(m map[string]map[string]uint64) []string {
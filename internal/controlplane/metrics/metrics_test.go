@@ -1,10 +1,12 @@
 package metrics
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 type mockFleet struct{}
@@ -153,3 +155,61 @@ func TestMetricsAsyncJobSeries(t *testing.T) {
 		}
 	}
 }
+
+type mockAuditForwarder struct{}
+
+func (m *mockAuditForwarder) DroppedCount() uint64   { return 7 }
+func (m *mockAuditForwarder) ForwardedCount() uint64 { return 120 }
+
+func TestMetricsAuditForwarderSeries(t *testing.T) {
+	c := NewCollector(&mockFleet{}, &mockHub{}, &mockApprovals{}, &mockAudit{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil)
+	w := httptest.NewRecorder()
+	c.Handler().ServeHTTP(w, req)
+	if strings.Contains(w.Body.String(), "legator_audit_forward_dropped_total") {
+		t.Fatal("expected no audit forwarder series when forwarder is not configured")
+	}
+
+	c.SetAuditForwarder(&mockAuditForwarder{})
+	w = httptest.NewRecorder()
+	c.Handler().ServeHTTP(w, req)
+	body := w.Body.String()
+	for _, check := range []string{
+		`legator_audit_forwarded_total 120`,
+		`legator_audit_forward_dropped_total 7`,
+	} {
+		if !strings.Contains(body, check) {
+			t.Fatalf("missing metric %q in body:\n%s", check, body)
+		}
+	}
+}
+
+func TestMetricsWebhookFailureStages(t *testing.T) {
+	c := NewCollector(&mockFleet{}, &mockHub{}, &mockApprovals{}, &mockAudit{}, nil)
+	failed := errors.New("webhook returned status 503")
+
+	// One delivery recovers on retry; another exhausts three attempts.
+	c.RecordWebhookDelivery("probe.offline", 1, 503, 10*time.Millisecond, failed, false)
+	c.RecordWebhookDelivery("probe.offline", 2, 200, 10*time.Millisecond, nil, false)
+	c.RecordWebhookDelivery("probe.offline", 1, 503, 10*time.Millisecond, failed, false)
+	c.RecordWebhookDelivery("probe.offline", 2, 503, 10*time.Millisecond, failed, false)
+	c.RecordWebhookDelivery("probe.offline", 3, 503, 10*time.Millisecond, failed, true)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil)
+	w := httptest.NewRecorder()
+	c.Handler().ServeHTTP(w, req)
+	body := w.Body.String()
+
+	checks := []string{
+		`legator_webhooks_sent_total{event_type="probe.offline",status="success"} 1`,
+		`legator_webhooks_sent_total{event_type="probe.offline",status="failure"} 4`,
+		`legator_webhook_delivery_failures_total{event_type="probe.offline",stage="first_attempt"} 2`,
+		`legator_webhook_delivery_failures_total{event_type="probe.offline",stage="exhausted"} 1`,
+	}
+	for _, check := range checks {
+		if !strings.Contains(body, check) {
+			t.Fatalf("missing metric %q in body:\n%s", check, body)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package migration
+
+import (
+	"database/sql"
+	"strings"
+	"sync"
+	"time"
+)
+
+// writeRetryBackoff is the wait before each retry of a busy write. It is
+// added to the connection's busy_timeout, so it stays short.
+var writeRetryBackoff = []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond}
+
+// WriteStatus is a snapshot of a store's recent write outcomes.
+type WriteStatus struct {
+	Store               string     `json:"store"`
+	Healthy             bool       `json:"healthy"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	TotalFailures       int64      `json:"total_failures"`
+	Retries             int64      `json:"retries"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+}
+
+// WriteHealth runs a store's SQLite writes, retrying transient busy/locked
+// errors with backoff, and remembers whether the latest write succeeded so
+// callers that discard write errors still surface persistent failures.
+type WriteHealth struct {
+	store string
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	totalFailures       int64
+	retries             int64
+	lastErr             string
+	lastErrAt           time.Time
+	lastOKAt            time.Time
+}
+
+// NewWriteHealth creates a tracker for the named store.
+func NewWriteHealth(store string) *WriteHealth {
+	return &WriteHealth{store: store}
+}
+
+// Exec runs a write statement on db, retrying while SQLite reports the
+// database busy or locked.
+func (h *WriteHealth) Exec(db *sql.DB, query string, args ...any) (sql.Result, error) {
+	res, err := db.Exec(query, args...)
+	for attempt := 0; err != nil && IsBusy(err) && attempt < len(writeRetryBackoff); attempt++ {
+		h.mu.Lock()
+		h.retries++
+		h.mu.Unlock()
+		time.Sleep(writeRetryBackoff[attempt])
+		res, err = db.Exec(query, args...)
+	}
+	h.Observe(err)
+	return res, err
+}
+
+// Observe records the outcome of a write made outside Exec.
+func (h *WriteHealth) Observe(err error) {
+	now := time.Now().UTC()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		h.consecutiveFailures = 0
+		h.lastOKAt = now
+		return
+	}
+	h.consecutiveFailures++
+	h.totalFailures++
+	h.lastErr = err.Error()
+	h.lastErrAt = now
+}
+
+// Status returns a snapshot. A store is healthy until a write fails, and
+// again after the next successful write.
+func (h *WriteHealth) Status() WriteStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st := WriteStatus{
+		Store:               h.store,
+		Healthy:             h.consecutiveFailures == 0,
+		ConsecutiveFailures: h.consecutiveFailures,
+		TotalFailures:       h.totalFailures,
+		Retries:             h.retries,
+		LastError:           h.lastErr,
+	}
+	if !h.lastErrAt.IsZero() {
+		at := h.lastErrAt
+		st.LastErrorAt = &at
+	}
+	if !h.lastOKAt.IsZero() {
+		at := h.lastOKAt
+		st.LastSuccessAt = &at
+	}
+	return st
+}
+
+// IsBusy reports whether err is a transient SQLite busy or locked error.
+func IsBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "SQLITE_BUSY") ||
+		strings.Contains(msg, "SQLITE_LOCKED") ||
+		strings.Contains(msg, "database is locked") ||
+		strings.Contains(msg, "database table is locked")
+}
@@ -0,0 +1,80 @@
+package migration_test
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/migration"
+)
+
+func TestWriteHealthRetriesBusyWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "busy.db")
+	holder, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer holder.Close()
+	if _, err := holder.Exec(`CREATE TABLE t (v INTEGER)`); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	writer, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open writer: %v", err)
+	}
+	defer writer.Close()
+	writer.SetMaxOpenConns(1)
+	if _, err := writer.Exec("PRAGMA busy_timeout=0"); err != nil {
+		t.Fatalf("busy_timeout: %v", err)
+	}
+
+	// Hold the write lock briefly so the first attempt fails with SQLITE_BUSY.
+	tx, err := holder.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO t (v) VALUES (1)`); err != nil {
+		t.Fatalf("lock insert: %v", err)
+	}
+	go func() {
+		time.Sleep(80 * time.Millisecond)
+		_ = tx.Commit()
+	}()
+
+	h := migration.NewWriteHealth("test")
+	if _, err := h.Exec(writer, `INSERT INTO t (v) VALUES (2)`); err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	st := h.Status()
+	if !st.Healthy || st.Retries == 0 || st.LastSuccessAt == nil {
+		t.Fatalf("expected healthy status with retries, got %+v", st)
+	}
+}
+
+func TestWriteHealthTracksFailures(t *testing.T) {
+	h := migration.NewWriteHealth("fleet")
+	h.Observe(errors.New("disk I/O error"))
+	h.Observe(errors.New("database or disk is full"))
+
+	st := h.Status()
+	if st.Healthy || st.ConsecutiveFailures != 2 || st.TotalFailures != 2 || st.LastError != "database or disk is full" {
+		t.Fatalf("unexpected failing status: %+v", st)
+	}
+
+	h.Observe(nil)
+	st = h.Status()
+	if !st.Healthy || st.ConsecutiveFailures != 0 || st.TotalFailures != 2 || st.LastErrorAt == nil {
+		t.Fatalf("expected recovery to keep failure history, got %+v", st)
+	}
+}
+
+func TestIsBusy(t *testing.T) {
+	if !migration.IsBusy(errors.New("database is locked (5) (SQLITE_BUSY)")) {
+		t.Fatal("expected busy error to be transient")
+	}
+	if migration.IsBusy(errors.New("database disk image is malformed (11)")) || migration.IsBusy(nil) {
+		t.Fatal("expected corruption and nil to be non-transient")
+	}
+}
@@ -260,18 +260,31 @@ func (h *Handler) HandleGetUsage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	profiles, err := h.store.ProfileTelemetry(window)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "failed to aggregate usage telemetry")
+		return
+	}
+
 	env := h.resolveEnvProfile()
 	for idx := range items {
 		if items[idx].ProfileID == EnvProfileID && items[idx].ProfileName == "" && env != nil {
 			items[idx].ProfileName = env.Name
 		}
 	}
+	for idx := range profiles {
+		if profiles[idx].ProfileID == EnvProfileID && profiles[idx].ProfileName == "" && env != nil {
+			profiles[idx].ProfileName = env.Name
+		}
+	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
-		"window": window.String(),
-		"since":  since.Format(time.RFC3339),
-		"totals": totals,
-		"usage":  items,
+		"window":     window.String(),
+		"since":      since.Format(time.RFC3339),
+		"totals":     totals,
+		"usage":      items,
+		"profiles":   profiles,
+		"comparison": CompareProfiles(profiles),
 	})
 }
 
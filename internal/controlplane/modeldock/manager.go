@@ -36,6 +36,8 @@ type ProviderManager struct {
 	envCfg llm.ProviderConfig
 	hasEnv bool
 	active *runtimeProvider
+
+	pricing map[string]TokenPrice
 }
 
 func NewProviderManager(envCfg llm.ProviderConfig) *ProviderManager {
@@ -162,7 +164,7 @@ func (m *ProviderManager) getActive() (*runtimeProvider, error) {
 
 func (m *ProviderManager) runtimeFromConfig(profileID, source string, cfg llm.ProviderConfig) *runtimeProvider {
 	cfg = normalizeConfig(cfg)
-	provider := llm.NewOpenAIProvider(cfg)
+	provider := llm.NewProvider(cfg)
 	return &runtimeProvider{
 		snapshot: ProviderSnapshot{
 			ProfileID: profileID,
@@ -203,12 +205,18 @@ func (f *featureProvider) Complete(ctx context.Context, req *llm.CompletionReque
 		return nil, err
 	}
 
+	started := time.Now()
 	resp, err := runtime.provider.Complete(ctx, req)
+	latency := time.Since(started)
 	if err != nil {
 		return nil, err
 	}
 
 	if f.recorder != nil && IsValidFeature(f.feature) {
+		model := resp.Model
+		if model == "" {
+			model = runtime.snapshot.Model
+		}
 		_ = f.recorder.RecordUsage(UsageRecord{
 			TS:               time.Now().UTC(),
 			ProfileID:        runtime.snapshot.ProfileID,
@@ -216,6 +224,9 @@ func (f *featureProvider) Complete(ctx context.Context, req *llm.CompletionReque
 			PromptTokens:     resp.PromptTokens,
 			CompletionTokens: resp.CompTokens,
 			TotalTokens:      resp.PromptTokens + resp.CompTokens,
+			Model:            model,
+			LatencyMS:        max(latency.Milliseconds(), 1),
+			CostUSD:          f.manager.EstimateCostUSD(model, resp.PromptTokens, resp.CompTokens),
 		})
 	}
 
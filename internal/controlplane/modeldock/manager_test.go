@@ -65,4 +65,30 @@ func TestProviderManagerFeatureProviderCapturesUsage(t *testing.T) {
 	if recorder.records[0].TotalTokens != 18 {
 		t.Fatalf("expected total tokens 18, got %d", recorder.records[0].TotalTokens)
 	}
+	if recorder.records[0].LatencyMS <= 0 {
+		t.Fatalf("expected latency to be recorded, got %d", recorder.records[0].LatencyMS)
+	}
+	if recorder.records[0].Model != "gpt-test" {
+		t.Fatalf("expected model gpt-test, got %q", recorder.records[0].Model)
+	}
+}
+
+func TestProviderManagerEstimateCostUSDUsesConfiguredPricing(t *testing.T) {
+	mgr := NewProviderManager(llm.ProviderConfig{})
+	mgr.SetPricing(map[string]TokenPrice{
+		"GPT-4o":      {InputPerMillion: 2, OutputPerMillion: 8},
+		"gpt-4o-mini": {InputPerMillion: 0.1, OutputPerMillion: 0.4},
+	})
+
+	if got := mgr.EstimateCostUSD("gpt-4o-2024-08-06", 1_000_000, 1_000_000); got != 10 {
+		t.Fatalf("expected gpt-4o cost 10, got %v", got)
+	}
+	// The longest matching key wins.
+	if got := mgr.EstimateCostUSD("gpt-4o-mini", 1_000_000, 1_000_000); got < 0.4999 || got > 0.5001 {
+		t.Fatalf("expected gpt-4o-mini cost 0.5, got %v", got)
+	}
+	// Unpriced models fall back to the built-in estimate.
+	if got, want := mgr.EstimateCostUSD("claude-3-opus", 1000, 1000), EstimateCost("claude-3-opus", 1000, 1000); got != want {
+		t.Fatalf("expected fallback estimate %v, got %v", want, got)
+	}
 }
@@ -60,6 +60,17 @@ func NewStore(dbPath string) (*Store, error) {
 		return nil, fmt.Errorf("create model_usage: %w", err)
 	}
 
+	for _, col := range []string{
+		`ALTER TABLE model_usage ADD COLUMN model TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE model_usage ADD COLUMN latency_ms INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE model_usage ADD COLUMN cost_usd REAL NOT NULL DEFAULT 0`,
+	} {
+		if _, err := db.Exec(col); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+			_ = db.Close()
+			return nil, fmt.Errorf("migrate model_usage: %w", err)
+		}
+	}
+
 	_, _ = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_model_profiles_single_active ON model_profiles(is_active) WHERE is_active = 1`)
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_model_profiles_updated_at ON model_profiles(updated_at)`)
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_model_usage_ts ON model_usage(ts)`)
@@ -303,8 +314,8 @@ func (s *Store) RecordUsage(record UsageRecord) error {
 	if record.TotalTokens == 0 {
 		record.TotalTokens = record.PromptTokens + record.CompletionTokens
 	}
-	_, err := s.db.Exec(`INSERT INTO model_usage (id, ts, profile_id, feature, prompt_tokens, completion_tokens, total_tokens)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+	_, err := s.db.Exec(`INSERT INTO model_usage (id, ts, profile_id, feature, prompt_tokens, completion_tokens, total_tokens, model, latency_ms, cost_usd)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		record.ID,
 		record.TS.Format(time.RFC3339Nano),
 		record.ProfileID,
@@ -312,6 +323,9 @@ func (s *Store) RecordUsage(record UsageRecord) error {
 		record.PromptTokens,
 		record.CompletionTokens,
 		record.TotalTokens,
+		record.Model,
+		record.LatencyMS,
+		record.CostUSD,
 	)
 	return err
 }
@@ -329,7 +343,8 @@ func (s *Store) AggregateUsage(window time.Duration) ([]UsageAggregate, UsageAgg
 		COUNT(*) AS requests,
 		SUM(u.prompt_tokens) AS prompt_tokens,
 		SUM(u.completion_tokens) AS completion_tokens,
-		SUM(u.total_tokens) AS total_tokens
+		SUM(u.total_tokens) AS total_tokens,
+		SUM(u.cost_usd) AS cost_usd
 		FROM model_usage u
 		LEFT JOIN model_profiles p ON p.id = u.profile_id
 		WHERE u.ts >= ?
@@ -352,6 +367,7 @@ func (s *Store) AggregateUsage(window time.Duration) ([]UsageAggregate, UsageAgg
 			&item.PromptTokens,
 			&item.CompletionTokens,
 			&item.TotalTokens,
+			&item.CostUSD,
 		); err != nil {
 			continue
 		}
@@ -360,6 +376,7 @@ func (s *Store) AggregateUsage(window time.Duration) ([]UsageAggregate, UsageAgg
 		totals.PromptTokens += item.PromptTokens
 		totals.CompletionTokens += item.CompletionTokens
 		totals.TotalTokens += item.TotalTokens
+		totals.CostUSD += item.CostUSD
 	}
 
 	return items, totals, since, rows.Err()
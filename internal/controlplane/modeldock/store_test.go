@@ -95,6 +95,54 @@ func TestStoreCreateActivateAndAggregateUsage(t *testing.T) {
 	}
 }
 
+func TestStoreProfileTelemetrySurvivesProfileSwitch(t *testing.T) {
+	store := newTestStore(t)
+
+	first, err := store.CreateProfile(Profile{Name: "Fast", Provider: "openai", BaseURL: "https://api.openai.com/v1", Model: "gpt-4o-mini", APIKey: "sk-first", IsActive: true})
+	if err != nil {
+		t.Fatalf("create first profile: %v", err)
+	}
+	second, err := store.CreateProfile(Profile{Name: "Slow", Provider: "openai", BaseURL: "https://api.openai.com/v1", Model: "gpt-4o", APIKey: "sk-second"})
+	if err != nil {
+		t.Fatalf("create second profile: %v", err)
+	}
+
+	for _, latency := range []int64{100, 200, 300} {
+		if err := store.RecordUsage(UsageRecord{ProfileID: first.ID, Feature: FeatureTask, PromptTokens: 500, CompletionTokens: 500, LatencyMS: latency, CostUSD: 0.001, Model: "gpt-4o-mini"}); err != nil {
+			t.Fatalf("record usage: %v", err)
+		}
+	}
+	if _, err := store.ActivateProfile(second.ID); err != nil {
+		t.Fatalf("activate second profile: %v", err)
+	}
+	if err := store.RecordUsage(UsageRecord{ProfileID: second.ID, Feature: FeatureTask, PromptTokens: 500, CompletionTokens: 500, LatencyMS: 900, CostUSD: 0.02, Model: "gpt-4o"}); err != nil {
+		t.Fatalf("record usage: %v", err)
+	}
+
+	profiles, err := store.ProfileTelemetry(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("profile telemetry: %v", err)
+	}
+	byID := map[string]ProfileTelemetry{}
+	for _, p := range profiles {
+		byID[p.ProfileID] = p
+	}
+	if got := byID[first.ID]; got.Requests != 3 || got.LatencyP50MS != 200 || got.ProfileName != "Fast" {
+		t.Fatalf("expected first profile telemetry kept after switch, got %+v", got)
+	}
+	if got := byID[second.ID]; got.Requests != 1 || got.LatencyP95MS != 900 {
+		t.Fatalf("unexpected second profile telemetry: %+v", got)
+	}
+
+	_, totals, _, err := store.AggregateUsage(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("aggregate usage: %v", err)
+	}
+	if totals.CostUSD < 0.0229 || totals.CostUSD > 0.0231 {
+		t.Fatalf("expected total cost 0.023, got %v", totals.CostUSD)
+	}
+}
+
 func TestMaskAPIKey(t *testing.T) {
 	masked := MaskAPIKey("sk-abcdef123456")
 	if masked == "sk-abcdef123456" {
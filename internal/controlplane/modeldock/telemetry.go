@@ -0,0 +1,196 @@
+package modeldock
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// TokenPrice is the configured USD price per one million tokens for a model.
+type TokenPrice struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// ProfileTelemetry summarises latency and estimated cost for one profile
+// over a usage window.
+type ProfileTelemetry struct {
+	ProfileID   string  `json:"profile_id"`
+	ProfileName string  `json:"profile_name,omitempty"`
+	Model       string  `json:"model,omitempty"`
+	Requests    int     `json:"requests"`
+	TotalTokens int     `json:"total_tokens"`
+	CostUSD     float64 `json:"cost_usd"`
+	// CostPer1KTokens normalises cost so profiles with different traffic can
+	// be compared.
+	CostPer1KTokens float64 `json:"cost_per_1k_tokens"`
+	// Latency percentiles cover requests recorded with a latency; usage
+	// recorded before latency tracking is counted in Requests only.
+	LatencySamples int     `json:"latency_samples"`
+	LatencyAvgMS   float64 `json:"latency_avg_ms"`
+	LatencyP50MS   float64 `json:"latency_p50_ms"`
+	LatencyP95MS   float64 `json:"latency_p95_ms"`
+	LatencyP99MS   float64 `json:"latency_p99_ms"`
+}
+
+// usageSample is one usage row used to build ProfileTelemetry.
+type usageSample struct {
+	ProfileID   string
+	ProfileName string
+	Model       string
+	TotalTokens int
+	CostUSD     float64
+	LatencyMS   int64
+}
+
+// SetPricing replaces the per-model token prices used to estimate cost.
+// Keys match a model name case-insensitively by substring; the longest
+// matching key wins. Models with no match fall back to EstimateCost.
+func (m *ProviderManager) SetPricing(pricing map[string]TokenPrice) {
+	normalized := make(map[string]TokenPrice, len(pricing))
+	for model, price := range pricing {
+		if key := strings.ToLower(strings.TrimSpace(model)); key != "" {
+			normalized[key] = price
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pricing = normalized
+}
+
+// EstimateCostUSD estimates the USD cost of a completion using the
+// configured pricing.
+func (m *ProviderManager) EstimateCostUSD(model string, promptTokens, completionTokens int) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	lower := strings.ToLower(model)
+	matched := ""
+	for key := range m.pricing {
+		if strings.Contains(lower, key) && len(key) > len(matched) {
+			matched = key
+		}
+	}
+	if matched == "" {
+		return EstimateCost(model, promptTokens, completionTokens)
+	}
+	price := m.pricing[matched]
+	return float64(promptTokens)/1_000_000*price.InputPerMillion + float64(completionTokens)/1_000_000*price.OutputPerMillion
+}
+
+// buildProfileTelemetry groups samples by profile, ordered by profile ID.
+func buildProfileTelemetry(samples []usageSample) []ProfileTelemetry {
+	type group struct {
+		item      ProfileTelemetry
+		latencies []float64
+	}
+	groups := map[string]*group{}
+	for _, s := range samples {
+		g, ok := groups[s.ProfileID]
+		if !ok {
+			g = &group{item: ProfileTelemetry{ProfileID: s.ProfileID}}
+			groups[s.ProfileID] = g
+		}
+		if s.ProfileName != "" {
+			g.item.ProfileName = s.ProfileName
+		}
+		if s.Model != "" {
+			g.item.Model = s.Model
+		}
+		g.item.Requests++
+		g.item.TotalTokens += s.TotalTokens
+		g.item.CostUSD += s.CostUSD
+		if s.LatencyMS > 0 {
+			g.latencies = append(g.latencies, float64(s.LatencyMS))
+		}
+	}
+
+	out := make([]ProfileTelemetry, 0, len(groups))
+	for _, g := range groups {
+		item := g.item
+		if item.TotalTokens > 0 {
+			item.CostPer1KTokens = item.CostUSD / float64(item.TotalTokens) * 1000
+		}
+		item.LatencySamples = len(g.latencies)
+		item.LatencyAvgMS = mean(g.latencies)
+		item.LatencyP50MS = percentile(g.latencies, 50)
+		item.LatencyP95MS = percentile(g.latencies, 95)
+		item.LatencyP99MS = percentile(g.latencies, 99)
+		out = append(out, item)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ProfileID < out[j].ProfileID })
+	return out
+}
+
+// CompareProfiles ranks profiles by p95 latency and cost per 1K tokens,
+// lowest first. Profiles with no latency samples are left out of the
+// latency ranking.
+func CompareProfiles(items []ProfileTelemetry) map[string][]RankedModel {
+	rank := func(metric func(ProfileTelemetry) float64, include func(ProfileTelemetry) bool) []RankedModel {
+		ranked := make([]RankedModel, 0, len(items))
+		for _, item := range items {
+			if include(item) {
+				ranked = append(ranked, RankedModel{ProfileID: item.ProfileID, Label: item.ProfileName, Value: metric(item)})
+			}
+		}
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Value < ranked[j].Value })
+		for i := range ranked {
+			ranked[i].Rank = i + 1
+		}
+		return ranked
+	}
+	return map[string][]RankedModel{
+		"latency_p95": rank(
+			func(t ProfileTelemetry) float64 { return t.LatencyP95MS },
+			func(t ProfileTelemetry) bool { return t.LatencySamples > 0 },
+		),
+		"cost_per_1k_tokens": rank(
+			func(t ProfileTelemetry) float64 { return t.CostPer1KTokens },
+			func(t ProfileTelemetry) bool { return t.TotalTokens > 0 },
+		),
+	}
+}
+
+// ProfileTelemetry returns per-profile latency and cost over window.
+func (s *Store) ProfileTelemetry(window time.Duration) ([]ProfileTelemetry, error) {
+	if window <= 0 {
+		window = 24 * time.Hour
+	}
+	since := time.Now().UTC().Add(-window)
+
+	rows, err := s.db.Query(`SELECT
+		u.profile_id,
+		COALESCE(p.name, ''),
+		u.model,
+		u.total_tokens,
+		u.cost_usd,
+		u.latency_ms
+		FROM model_usage u
+		LEFT JOIN model_profiles p ON p.id = u.profile_id
+		WHERE u.ts >= ?
+		ORDER BY u.ts ASC`, since.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := make([]usageSample, 0)
+	for rows.Next() {
+		var sample usageSample
+		if err := rows.Scan(
+			&sample.ProfileID,
+			&sample.ProfileName,
+			&sample.Model,
+			&sample.TotalTokens,
+			&sample.CostUSD,
+			&sample.LatencyMS,
+		); err != nil {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return buildProfileTelemetry(samples), nil
+}
@@ -0,0 +1,46 @@
+package modeldock
+
+import "testing"
+
+func TestBuildProfileTelemetryPercentilesAndCost(t *testing.T) {
+	samples := []usageSample{
+		{ProfileID: "a", ProfileName: "Alpha", TotalTokens: 1000, CostUSD: 0.002, LatencyMS: 100},
+		{ProfileID: "a", TotalTokens: 1000, CostUSD: 0.002, LatencyMS: 300},
+		{ProfileID: "a", TotalTokens: 1000, CostUSD: 0.002, LatencyMS: 200},
+		// Recorded before latency tracking: counted, but not a latency sample.
+		{ProfileID: "a", TotalTokens: 1000, CostUSD: 0.002},
+		{ProfileID: "b", TotalTokens: 2000, CostUSD: 0.001, LatencyMS: 50},
+	}
+
+	items := buildProfileTelemetry(samples)
+	if len(items) != 2 || items[0].ProfileID != "a" || items[1].ProfileID != "b" {
+		t.Fatalf("expected profiles a and b, got %+v", items)
+	}
+	a := items[0]
+	if a.Requests != 4 || a.LatencySamples != 3 || a.ProfileName != "Alpha" {
+		t.Fatalf("unexpected counts for a: %+v", a)
+	}
+	if a.LatencyP50MS != 200 || a.LatencyP95MS != 300 || a.LatencyAvgMS != 200 {
+		t.Fatalf("unexpected latency for a: %+v", a)
+	}
+	if a.CostPer1KTokens < 0.00199 || a.CostPer1KTokens > 0.00201 {
+		t.Fatalf("expected a cost per 1K tokens 0.002, got %v", a.CostPer1KTokens)
+	}
+}
+
+func TestCompareProfilesRanksCheapestAndFastestFirst(t *testing.T) {
+	rankings := CompareProfiles([]ProfileTelemetry{
+		{ProfileID: "a", ProfileName: "Alpha", TotalTokens: 1000, CostPer1KTokens: 0.01, LatencySamples: 3, LatencyP95MS: 900},
+		{ProfileID: "b", ProfileName: "Beta", TotalTokens: 1000, CostPer1KTokens: 0.02, LatencySamples: 3, LatencyP95MS: 300},
+		{ProfileID: "c", ProfileName: "Legacy", TotalTokens: 1000, CostPer1KTokens: 0.005},
+	})
+
+	latency := rankings["latency_p95"]
+	if len(latency) != 2 || latency[0].ProfileID != "b" || latency[0].Rank != 1 {
+		t.Fatalf("expected b fastest and c excluded, got %+v", latency)
+	}
+	cost := rankings["cost_per_1k_tokens"]
+	if len(cost) != 3 || cost[0].ProfileID != "c" || cost[2].ProfileID != "b" {
+		t.Fatalf("expected c cheapest and b most expensive, got %+v", cost)
+	}
+}
@@ -272,7 +272,7 @@ func (h *Handler) buildProviderMap(models []TrialModel) (map[string]TrialLLMClie
 			APIKey:  profile.APIKey,
 			Model:   profile.Model,
 		}
-		out[m.ProfileID] = llm.NewOpenAIProvider(cfg)
+		out[m.ProfileID] = llm.NewProvider(cfg)
 	}
 	return out, nil
 }
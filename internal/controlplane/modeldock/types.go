@@ -53,17 +53,21 @@ type UsageRecord struct {
 	PromptTokens     int       `json:"prompt_tokens"`
 	CompletionTokens int       `json:"completion_tokens"`
 	TotalTokens      int       `json:"total_tokens"`
+	Model            string    `json:"model,omitempty"`
+	LatencyMS        int64     `json:"latency_ms,omitempty"`
+	CostUSD          float64   `json:"cost_usd,omitempty"`
 }
 
 // UsageAggregate is grouped usage totals.
 type UsageAggregate struct {
-	ProfileID        string `json:"profile_id"`
-	ProfileName      string `json:"profile_name,omitempty"`
-	Feature          string `json:"feature"`
-	Requests         int    `json:"requests"`
-	PromptTokens     int    `json:"prompt_tokens"`
-	CompletionTokens int    `json:"completion_tokens"`
-	TotalTokens      int    `json:"total_tokens"`
+	ProfileID        string  `json:"profile_id"`
+	ProfileName      string  `json:"profile_name,omitempty"`
+	Feature          string  `json:"feature"`
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
 }
 
 func (p Profile) ToResponse() ProfileResponse {
@@ -0,0 +1,140 @@
+package networkdevices
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Driver captures vendor-specific command handling for read-oriented
+// command execution on network devices.
+type Driver interface {
+	// Vendor returns the normalized vendor name this driver handles.
+	Vendor() string
+	// Prepare validates that the command is read-only for this vendor and
+	// rewrites it for non-interactive execution (e.g. disabling paging).
+	Prepare(command string) (string, error)
+	// Clean strips vendor banners and prompt noise from raw output.
+	Clean(output string) string
+}
+
+// DriverFor returns the command driver for a device vendor. Unknown vendors
+// fall back to the generic driver.
+func DriverFor(vendor string) Driver {
+	switch normalizeVendor(vendor) {
+	case VendorCisco:
+		return ciscoIOSDriver{}
+	case VendorJunos:
+		return junosDriver{}
+	case VendorFortinet:
+		return fortinetDriver{}
+	default:
+		return genericDriver{}
+	}
+}
+
+// ErrCommandNotReadOnly is returned by Driver.Prepare for commands that could
+// mutate device state.
+var ErrCommandNotReadOnly = errors.New("command is not read-only")
+
+func IsNotReadOnly(err error) bool {
+	return errors.Is(err, ErrCommandNotReadOnly)
+}
+
+type ciscoIOSDriver struct{}
+
+func (ciscoIOSDriver) Vendor() string { return VendorCisco }
+
+func (d ciscoIOSDriver) Prepare(command string) (string, error) {
+	return prepareReadOnly(d.Vendor(), command, []string{"show", "dir", "more", "ping", "traceroute"})
+}
+
+func (ciscoIOSDriver) Clean(output string) string {
+	return stripLines(output, func(line string) bool {
+		return line == "Building configuration..." || strings.HasPrefix(line, "Current configuration :")
+	})
+}
+
+type junosDriver struct{}
+
+func (junosDriver) Vendor() string { return VendorJunos }
+
+func (d junosDriver) Prepare(command string) (string, error) {
+	prepared, err := prepareReadOnly(d.Vendor(), command, []string{"show", "ping", "traceroute"})
+	if err != nil {
+		return "", err
+	}
+	// Junos pages output even on exec channels unless told otherwise.
+	if strings.HasPrefix(strings.ToLower(prepared), "show") && !strings.Contains(prepared, "no-more") {
+		prepared += " | no-more"
+	}
+	return prepared, nil
+}
+
+func (junosDriver) Clean(output string) string {
+	return stripLines(output, func(line string) bool {
+		return strings.HasPrefix(line, "{master") || strings.HasPrefix(line, "{backup") || line == "{primary:node0}"
+	})
+}
+
+type fortinetDriver struct{}
+
+func (fortinetDriver) Vendor() string { return VendorFortinet }
+
+func (d fortinetDriver) Prepare(command string) (string, error) {
+	return prepareReadOnly(d.Vendor(), command, []string{"get", "show"})
+}
+
+func (fortinetDriver) Clean(output string) string {
+	return stripLines(output, nil)
+}
+
+type genericDriver struct{}
+
+func (genericDriver) Vendor() string { return VendorGeneric }
+
+func (d genericDriver) Prepare(command string) (string, error) {
+	return prepareReadOnly(d.Vendor(), command, []string{"show", "get", "display", "cat", "uname", "hostname", "uptime", "ping", "traceroute"})
+}
+
+func (genericDriver) Clean(output string) string {
+	return stripLines(output, nil)
+}
+
+// prepareReadOnly trims the command and checks its verb against the vendor's
+// read-only allow list. Command chaining is rejected outright; pipes are
+// allowed because every supported vendor treats them as output filters.
+func prepareReadOnly(vendor, command string, verbs []string) (string, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return "", fmt.Errorf("command is required")
+	}
+	if strings.ContainsAny(command, ";\n\r`&>") || strings.Contains(command, "$(") {
+		return "", fmt.Errorf("%w: %q is not allowed on %s devices", ErrCommandNotReadOnly, command, vendor)
+	}
+	verb := strings.ToLower(strings.Fields(command)[0])
+	for _, allowed := range verbs {
+		if verb == allowed {
+			return command, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q is not allowed on %s devices", ErrCommandNotReadOnly, command, vendor)
+}
+
+// stripLines normalizes line endings, drops trailing blank lines and removes
+// any line matched by drop.
+func stripLines(output string, drop func(line string) bool) string {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		trimmed := strings.TrimRight(line, " \t\r")
+		if drop != nil && drop(strings.TrimSpace(trimmed)) {
+			continue
+		}
+		kept = append(kept, trimmed)
+	}
+	for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+		kept = kept[:len(kept)-1]
+	}
+	return strings.Join(kept, "\n")
+}
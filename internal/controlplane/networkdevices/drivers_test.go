@@ -0,0 +1,77 @@
+package networkdevices
+
+import "testing"
+
+func TestDriverForVendor(t *testing.T) {
+	cases := map[string]string{
+		"cisco":    VendorCisco,
+		" JUNOS ":  VendorJunos,
+		"fortinet": VendorFortinet,
+		"":         VendorGeneric,
+		"arista":   VendorGeneric,
+	}
+	for vendor, want := range cases {
+		if got := DriverFor(vendor).Vendor(); got != want {
+			t.Fatalf("DriverFor(%q) = %q, want %q", vendor, got, want)
+		}
+	}
+}
+
+func TestDriverPrepareRejectsMutatingCommands(t *testing.T) {
+	rejected := map[string][]string{
+		VendorCisco:    {"configure terminal", "reload", "write memory", "show run; reload", "copy run start"},
+		VendorJunos:    {"configure", "request system reboot", "show version\ncommit"},
+		VendorFortinet: {"config system global", "execute reboot"},
+		VendorGeneric:  {"rm -rf /", "show x && reboot", "cat $(id)"},
+	}
+	for vendor, commands := range rejected {
+		driver := DriverFor(vendor)
+		for _, command := range commands {
+			if _, err := driver.Prepare(command); !IsNotReadOnly(err) {
+				t.Fatalf("%s: expected %q to be rejected, got err=%v", vendor, command, err)
+			}
+		}
+	}
+
+	if _, err := DriverFor(VendorCisco).Prepare("   "); err == nil || IsNotReadOnly(err) {
+		t.Fatalf("expected required-command error for empty input, got %v", err)
+	}
+}
+
+func TestDriverPrepareVendorQuirks(t *testing.T) {
+	got, err := DriverFor(VendorCisco).Prepare("  show running-config | include hostname ")
+	if err != nil {
+		t.Fatalf("cisco prepare: %v", err)
+	}
+	if got != "show running-config | include hostname" {
+		t.Fatalf("unexpected cisco command %q", got)
+	}
+
+	got, err = DriverFor(VendorJunos).Prepare("show configuration")
+	if err != nil {
+		t.Fatalf("junos prepare: %v", err)
+	}
+	if got != "show configuration | no-more" {
+		t.Fatalf("expected junos paging disabled, got %q", got)
+	}
+
+	got, err = DriverFor(VendorJunos).Prepare("show route | no-more")
+	if err != nil {
+		t.Fatalf("junos prepare: %v", err)
+	}
+	if got != "show route | no-more" {
+		t.Fatalf("expected no duplicate no-more, got %q", got)
+	}
+}
+
+func TestDriverClean(t *testing.T) {
+	cisco := DriverFor(VendorCisco).Clean("Building configuration...\r\n\r\nCurrent configuration : 1234 bytes\r\nhostname sw1\r\n\r\n")
+	if cisco != "\nhostname sw1" {
+		t.Fatalf("unexpected cleaned cisco output %q", cisco)
+	}
+
+	junos := DriverFor(VendorJunos).Clean("{master:0}\nHostname: mx1\nModel: mx204\n")
+	if junos != "Hostname: mx1\nModel: mx204" {
+		t.Fatalf("unexpected cleaned junos output %q", junos)
+	}
+}
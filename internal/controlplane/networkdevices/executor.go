@@ -97,6 +97,27 @@ func (e *SSHExecutor) Execute(ctx context.Context, device Device, creds Credenti
 	return result, nil
 }
 
+// ExecuteReadOnly validates the command against the device vendor's driver,
+// runs the prepared form and returns cleaned, line-split output.
+func (e *SSHExecutor) ExecuteReadOnly(ctx context.Context, device Device, creds CredentialInput, command string) (*CommandResult, error) {
+	driver := DriverFor(device.Vendor)
+	prepared, err := driver.Prepare(command)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := e.Execute(ctx, device, creds, prepared)
+	if err != nil {
+		return nil, err
+	}
+	result.Vendor = driver.Vendor()
+	result.Output = driver.Clean(result.Output)
+	if result.Output != "" {
+		result.Lines = strings.Split(result.Output, "\n")
+	}
+	return result, nil
+}
+
 // resolveCredentials merges stored credentials if none are provided inline.
 func (e *SSHExecutor) resolveCredentials(deviceID string, provided CredentialInput) CredentialInput {
 	if strings.TrimSpace(provided.Password) != "" || strings.TrimSpace(provided.PrivateKey) != "" {
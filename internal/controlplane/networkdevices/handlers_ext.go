@@ -40,8 +40,12 @@ func (h *Handler) HandleCommandDevice(w http.ResponseWriter, r *http.Request) {
 	}
 
 	executor := NewSSHExecutor(h.store)
-	result, err := executor.Execute(r.Context(), *device, creds, req.Command)
+	result, err := executor.ExecuteReadOnly(r.Context(), *device, creds, req.Command)
 	if err != nil {
+		if IsNotReadOnly(err) {
+			writeError(w, http.StatusBadRequest, "command_not_allowed", err.Error())
+			return
+		}
 		writeError(w, http.StatusBadGateway, "execution_failed", err.Error())
 		return
 	}
@@ -67,8 +67,10 @@ type CommandRequest struct {
 // CommandResult holds the output of a single executed command.
 type CommandResult struct {
 	DeviceID   string    `json:"device_id"`
+	Vendor     string    `json:"vendor,omitempty"`
 	Command    string    `json:"command"`
 	Output     string    `json:"output"`
+	Lines      []string  `json:"lines,omitempty"`
 	Truncated  bool      `json:"truncated,omitempty"`
 	DurationMS int64     `json:"duration_ms"`
 	ExecutedAt time.Time `json:"executed_at"`
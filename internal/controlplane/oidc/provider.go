@@ -13,7 +13,6 @@ import (
 	"time"
 
 	gooidc "github.com/coreos/go-oidc/v3/oidc"
-	"github.com/marcus-qen/legator/internal/controlplane/audit"
 	"github.com/marcus-qen/legator/internal/controlplane/auth"
 	"github.com/marcus-qen/legator/internal/controlplane/users"
 	"go.uber.org/zap"
@@ -41,18 +40,13 @@ type SessionCreator interface {
 	Create(userID string) (token string, err error)
 }
 
-// AuditRecorder records audit events for OIDC logins.
-type AuditRecorder interface {
-	Record(evt audit.Event)
-}
-
 // Provider handles OIDC login + callback processing.
 type Provider struct {
 	config   Config
 	verifier *gooidc.IDTokenVerifier
 	oauth2   oauth2.Config
 	logger   *zap.Logger
-	Auditor  AuditRecorder // optional; set after construction
+	Auditor  *auth.AuthnAuditor // optional; set after construction
 }
 
 type callbackState struct {
@@ -208,33 +202,33 @@ func (p *Provider) HandleCallback(userStore UserStore, sessionCreator SessionCre
 			oauth2.SetAuthURLParam("code_verifier", stored.CodeVerifier),
 		)
 		if err != nil {
-			p.emitAuditFailed("unknown", "oidc", "token exchange failed", r.RemoteAddr)
+			p.emitAuditFailed("unknown", "token exchange failed", r)
 			http.Error(w, "oidc token exchange failed", http.StatusUnauthorized)
 			return
 		}
 
 		rawIDToken, _ := tok.Extra("id_token").(string)
 		if strings.TrimSpace(rawIDToken) == "" {
-			p.emitAuditFailed("unknown", "oidc", "no id_token in response", r.RemoteAddr)
+			p.emitAuditFailed("unknown", "no id_token in response", r)
 			http.Error(w, "oidc provider did not return id_token", http.StatusUnauthorized)
 			return
 		}
 
 		idToken, err := p.verifier.Verify(r.Context(), rawIDToken)
 		if err != nil {
-			p.emitAuditFailed("unknown", "oidc", "invalid id_token", r.RemoteAddr)
+			p.emitAuditFailed("unknown", "invalid id_token", r)
 			http.Error(w, "invalid oidc id_token", http.StatusUnauthorized)
 			return
 		}
 		if strings.TrimSpace(idToken.Nonce) == "" || idToken.Nonce != stored.Nonce {
-			p.emitAuditFailed("unknown", "oidc", "invalid nonce", r.RemoteAddr)
+			p.emitAuditFailed("unknown", "invalid nonce", r)
 			http.Error(w, "invalid oidc nonce", http.StatusUnauthorized)
 			return
 		}
 
 		claims := map[string]any{}
 		if err := idToken.Claims(&claims); err != nil {
-			p.emitAuditFailed("unknown", "oidc", "invalid claims", r.RemoteAddr)
+			p.emitAuditFailed("unknown", "invalid claims", r)
 			http.Error(w, "invalid oidc claims", http.StatusUnauthorized)
 			return
 		}
@@ -245,7 +239,7 @@ func (p *Provider) HandleCallback(userStore UserStore, sessionCreator SessionCre
 			if username == "" {
 				username = claimString(claims, "sub")
 			}
-			p.emitAuditFailed(username, "oidc", err.Error(), r.RemoteAddr)
+			p.emitAuditFailed(username, err.Error(), r)
 			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
 		}
@@ -256,22 +250,18 @@ func (p *Provider) HandleCallback(userStore UserStore, sessionCreator SessionCre
 			return
 		}
 
-		// Audit: successful OIDC login
-		if p.Auditor != nil {
-			p.Auditor.Record(audit.Event{
-				Timestamp: time.Now().UTC(),
-				Type:      audit.EventLoginSuccess,
-				Actor:     user.ID,
-				Summary:   "Login succeeded for " + user.Username + " (oidc)",
-				Detail: map[string]string{
-					"method":      "oidc",
-					"user_id":     user.ID,
-					"username":    user.Username,
-					"role":        user.Role,
-					"remote_addr": r.RemoteAddr,
-				},
-			})
-		}
+		p.Auditor.Succeeded(auth.AuthnAttempt{
+			Method:   auth.AuthnMethodOIDC,
+			Identity: user.Username,
+			Actor:    user.ID,
+			SourceIP: auth.SourceIP(r),
+			Detail: map[string]any{
+				"user_id":     user.ID,
+				"username":    user.Username,
+				"role":        user.Role,
+				"remote_addr": r.RemoteAddr,
+			},
+		})
 
 		http.SetCookie(w, &http.Cookie{
 			Name:     auth.SessionCookieName,
@@ -287,21 +277,14 @@ func (p *Provider) HandleCallback(userStore UserStore, sessionCreator SessionCre
 	}
 }
 
-// emitAuditFailed records a failed login attempt to the audit log.
-func (p *Provider) emitAuditFailed(username, method, reason, remoteAddr string) {
-	if p.Auditor == nil {
-		return
-	}
-	p.Auditor.Record(audit.Event{
-		Timestamp: time.Now().UTC(),
-		Type:      audit.EventLoginFailed,
-		Actor:     username,
-		Summary:   "Login failed for " + username + " (" + method + "): " + reason,
-		Detail: map[string]string{
-			"method":      method,
-			"reason":      reason,
-			"remote_addr": remoteAddr,
-		},
+// emitAuditFailed records a failed OIDC login attempt to the audit log.
+func (p *Provider) emitAuditFailed(username, reason string, r *http.Request) {
+	p.Auditor.Failed(auth.AuthnAttempt{
+		Method:   auth.AuthnMethodOIDC,
+		Identity: username,
+		SourceIP: auth.SourceIP(r),
+		Reason:   reason,
+		Detail:   map[string]any{"remote_addr": r.RemoteAddr},
 	})
 }
 
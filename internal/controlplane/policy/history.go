@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// maxAssignmentsPerProbe bounds the in-memory assignment history per probe.
+const maxAssignmentsPerProbe = 200
+
+// Assignment actions.
+const (
+	AssignmentApply    = "apply"
+	AssignmentRollback = "rollback"
+)
+
+// Assignment records one policy template being applied to a probe, with the
+// state it replaced so it can be rolled back.
+type Assignment struct {
+	ID               string                   `json:"id"`
+	ProbeID          string                   `json:"probe_id"`
+	Action           string                   `json:"action"` // apply or rollback
+	PolicyID         string                   `json:"policy_id"`
+	PolicyName       string                   `json:"policy_name,omitempty"`
+	Level            protocol.CapabilityLevel `json:"level"`
+	PreviousPolicyID string                   `json:"previous_policy_id,omitempty"`
+	PreviousLevel    protocol.CapabilityLevel `json:"previous_level,omitempty"`
+	Pushed           bool                     `json:"pushed"`
+	Actor            string                   `json:"actor,omitempty"`
+	AppliedAt        time.Time                `json:"applied_at"`
+}
+
+// AssignmentHistory records and lists per-probe policy assignments.
+type AssignmentHistory interface {
+	RecordAssignment(a Assignment) (*Assignment, error)
+	ListAssignments(probeID string, limit int) []*Assignment
+}
+
+// assignmentLog is the in-memory assignment history shared by Store and
+// PersistentStore.
+type assignmentLog struct {
+	mu      sync.RWMutex
+	byProbe map[string][]*Assignment // oldest first
+}
+
+// RecordAssignment stores an assignment, assigning an ID and timestamp if
+// missing.
+func (s *Store) RecordAssignment(a Assignment) (*Assignment, error) {
+	a.ProbeID = strings.TrimSpace(a.ProbeID)
+	if a.ProbeID == "" {
+		return nil, fmt.Errorf("probe_id is required")
+	}
+	if a.PolicyID == "" {
+		return nil, fmt.Errorf("policy_id is required")
+	}
+	if a.Action == "" {
+		a.Action = AssignmentApply
+	}
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	if a.AppliedAt.IsZero() {
+		a.AppliedAt = time.Now().UTC()
+	}
+
+	s.history.mu.Lock()
+	defer s.history.mu.Unlock()
+	if s.history.byProbe == nil {
+		s.history.byProbe = make(map[string][]*Assignment)
+	}
+	stored := a
+	entries := append(s.history.byProbe[a.ProbeID], &stored)
+	if len(entries) > maxAssignmentsPerProbe {
+		entries = entries[len(entries)-maxAssignmentsPerProbe:]
+	}
+	s.history.byProbe[a.ProbeID] = entries
+	return &stored, nil
+}
+
+// ListAssignments returns a probe's assignments, newest first. limit <= 0
+// returns all retained entries.
+func (s *Store) ListAssignments(probeID string, limit int) []*Assignment {
+	s.history.mu.RLock()
+	defer s.history.mu.RUnlock()
+	entries := s.history.byProbe[probeID]
+	out := make([]*Assignment, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		out = append(out, entries[i])
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out
+}
@@ -119,6 +119,90 @@ func NewPersistentStore(dbPath string) (*PersistentStore, error) {
 				return nil
 			},
 		},
+		{
+			Version:     4,
+			Description: "add per-probe policy assignment history",
+			Up: func(tx *sql.Tx) error {
+				if _, err := tx.Exec(`CREATE TABLE IF NOT EXISTS policy_assignments (
+					id                 TEXT PRIMARY KEY,
+					probe_id           TEXT NOT NULL,
+					action             TEXT NOT NULL,
+					policy_id          TEXT NOT NULL,
+					policy_name        TEXT NOT NULL DEFAULT '',
+					level              TEXT NOT NULL,
+					previous_policy_id TEXT NOT NULL DEFAULT '',
+					previous_level     TEXT NOT NULL DEFAULT '',
+					pushed             INTEGER NOT NULL DEFAULT 0,
+					actor              TEXT NOT NULL DEFAULT '',
+					applied_at         TEXT NOT NULL
+				)`); err != nil {
+					return err
+				}
+				_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_policy_assignments_probe ON policy_assignments(probe_id, applied_at)`)
+				return err
+			},
+		},
+		{
+			Version:     5,
+			Description: "add probe alert watcher config",
+			Up: func(tx *sql.Tx) error {
+				return addColumn(tx, `ALTER TABLE policy_templates ADD COLUMN alert_watch_json TEXT NOT NULL DEFAULT ''`)
+			},
+		},
+		{
+			Version:     6,
+			Description: "add probe command concurrency limit",
+			Up: func(tx *sql.Tx) error {
+				return addColumn(tx, `ALTER TABLE policy_templates ADD COLUMN max_concurrent_commands INTEGER NOT NULL DEFAULT 0`)
+			},
+		},
+		{
+			Version:     7,
+			Description: "add probe reporting cadence",
+			Up: func(tx *sql.Tx) error {
+				if err := addColumn(tx, `ALTER TABLE policy_templates ADD COLUMN heartbeat_interval_sec INTEGER NOT NULL DEFAULT 0`); err != nil {
+					return err
+				}
+				return addColumn(tx, `ALTER TABLE policy_templates ADD COLUMN inventory_interval_sec INTEGER NOT NULL DEFAULT 0`)
+			},
+		},
+		{
+			Version:     8,
+			Description: "add tag-based policy assignment rules",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS policy_tag_rules (
+					id         TEXT PRIMARY KEY,
+					name       TEXT NOT NULL,
+					tag        TEXT NOT NULL,
+					policy_id  TEXT NOT NULL,
+					priority   INTEGER NOT NULL DEFAULT 0,
+					created_by TEXT NOT NULL DEFAULT '',
+					created_at TEXT NOT NULL
+				)`)
+				return err
+			},
+		},
+		{
+			Version:     9,
+			Description: "add command work directory allowlist",
+			Up: func(tx *sql.Tx) error {
+				return addColumn(tx, `ALTER TABLE policy_templates ADD COLUMN work_dirs TEXT NOT NULL DEFAULT '[]'`)
+			},
+		},
+		{
+			Version:     10,
+			Description: "add probe command rate limit",
+			Up: func(tx *sql.Tx) error {
+				return addColumn(tx, `ALTER TABLE policy_templates ADD COLUMN rate_limit_json TEXT NOT NULL DEFAULT ''`)
+			},
+		},
+		{
+			Version:     11,
+			Description: "add command env key allowlist",
+			Up: func(tx *sql.Tx) error {
+				return addColumn(tx, `ALTER TABLE policy_templates ADD COLUMN env_keys TEXT NOT NULL DEFAULT '[]'`)
+			},
+		},
 	})
 	if err := runner.Migrate(db); err != nil {
 		_ = db.Close()
@@ -132,9 +216,135 @@ func NewPersistentStore(dbPath string) (*PersistentStore, error) {
 		db.Close()
 		return nil, err
 	}
+	if err := ps.loadAssignments(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := ps.loadTagRules(); err != nil {
+		db.Close()
+		return nil, err
+	}
 	return ps, nil
 }
 
+// assignmentTimeFormat is fixed-width so applied_at sorts correctly as text.
+const assignmentTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// RecordAssignment stores a policy assignment in memory and persists it.
+func (ps *PersistentStore) RecordAssignment(a Assignment) (*Assignment, error) {
+	stored, err := ps.Store.RecordAssignment(a)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ps.db.Exec(`INSERT INTO policy_assignments
+		(id, probe_id, action, policy_id, policy_name, level, previous_policy_id, previous_level, pushed, actor, applied_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		stored.ID, stored.ProbeID, stored.Action, stored.PolicyID, stored.PolicyName, string(stored.Level),
+		stored.PreviousPolicyID, string(stored.PreviousLevel), boolToInt(stored.Pushed), stored.Actor,
+		stored.AppliedAt.UTC().Format(assignmentTimeFormat),
+	); err != nil {
+		return stored, fmt.Errorf("persist policy assignment: %w", err)
+	}
+	// Keep each probe's history bounded on disk as well.
+	_, _ = ps.db.Exec(`DELETE FROM policy_assignments WHERE probe_id = ? AND id NOT IN (
+		SELECT id FROM policy_assignments WHERE probe_id = ? ORDER BY applied_at DESC LIMIT ?
+	)`, stored.ProbeID, stored.ProbeID, maxAssignmentsPerProbe)
+	return stored, nil
+}
+
+func (ps *PersistentStore) loadAssignments() error {
+	rows, err := ps.db.Query(`SELECT id, probe_id, action, policy_id, policy_name, level,
+		previous_policy_id, previous_level, pushed, actor, applied_at
+		FROM policy_assignments ORDER BY applied_at ASC`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			a                    Assignment
+			level, previousLevel string
+			pushed               int
+			appliedStr           string
+		)
+		if err := rows.Scan(&a.ID, &a.ProbeID, &a.Action, &a.PolicyID, &a.PolicyName, &level,
+			&a.PreviousPolicyID, &previousLevel, &pushed, &a.Actor, &appliedStr); err != nil {
+			return err
+		}
+		a.Level = protocol.CapabilityLevel(level)
+		a.PreviousLevel = protocol.CapabilityLevel(previousLevel)
+		a.Pushed = pushed != 0
+		a.AppliedAt, _ = time.Parse(time.RFC3339Nano, appliedStr)
+		if _, err := ps.Store.RecordAssignment(a); err != nil {
+			return fmt.Errorf("load policy assignment %s: %w", a.ID, err)
+		}
+	}
+	return rows.Err()
+}
+
+// AddTagRule validates a tag rule, stores it in memory and persists it.
+func (ps *PersistentStore) AddTagRule(rule TagRule) (*TagRule, error) {
+	stored, err := ps.Store.AddTagRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ps.db.Exec(`INSERT OR REPLACE INTO policy_tag_rules
+		(id, name, tag, policy_id, priority, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		stored.ID, stored.Name, stored.Tag, stored.PolicyID, stored.Priority,
+		stored.CreatedBy, stored.CreatedAt.Format(time.RFC3339Nano),
+	); err != nil {
+		_ = ps.Store.DeleteTagRule(stored.ID)
+		return nil, fmt.Errorf("persist tag rule: %w", err)
+	}
+	return stored, nil
+}
+
+// DeleteTagRule removes a tag rule from both memory and disk.
+func (ps *PersistentStore) DeleteTagRule(id string) error {
+	if err := ps.Store.DeleteTagRule(id); err != nil {
+		return err
+	}
+	_, _ = ps.db.Exec(`DELETE FROM policy_tag_rules WHERE id = ?`, id)
+	return nil
+}
+
+func (ps *PersistentStore) loadTagRules() error {
+	rows, err := ps.db.Query(`SELECT id, name, tag, policy_id, priority, created_by, created_at
+		FROM policy_tag_rules`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var rules []TagRule
+	for rows.Next() {
+		var (
+			rule       TagRule
+			createdStr string
+		)
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.Tag, &rule.PolicyID, &rule.Priority,
+			&rule.CreatedBy, &createdStr); err != nil {
+			return err
+		}
+		rule.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdStr)
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	// Rules outlive the templates they point at; keep them so the mapping
+	// is visible, and let registration fall back to the default policy.
+	ps.tagRules.mu.Lock()
+	defer ps.tagRules.mu.Unlock()
+	ps.tagRules.rules = make(map[string]*TagRule, len(rules))
+	for i := range rules {
+		ps.tagRules.rules[rules[i].ID] = &rules[i]
+	}
+	return nil
+}
+
 // Create adds a template and persists it.
 func (ps *PersistentStore) Create(name, description string, level protocol.CapabilityLevel, allowed, blocked, paths []string, opts TemplateOptions) *Template {
 	t := ps.Store.Create(name, description, level, allowed, blocked, paths, opts)
@@ -152,6 +362,13 @@ func (ps *PersistentStore) Update(id string, name, description string, level pro
 	return t, nil
 }
 
+// Upsert updates or creates a template under its ID and persists it.
+func (ps *PersistentStore) Upsert(id string, name, description string, level protocol.CapabilityLevel, allowed, blocked, paths []string, opts TemplateOptions) (*Template, bool) {
+	t, created := ps.Store.Upsert(id, name, description, level, allowed, blocked, paths, opts)
+	_ = ps.persist(t)
+	return t, created
+}
+
 // Delete removes a template from both memory and disk.
 func (ps *PersistentStore) Delete(id string) error {
 	if err := ps.Store.Delete(id); err != nil {
@@ -172,13 +389,25 @@ func (ps *PersistentStore) persist(t *Template) error {
 	pathsJSON, _ := json.Marshal(t.Paths)
 	breakglassJSON, _ := json.Marshal(t.Breakglass)
 	allowedScopesJSON, _ := json.Marshal(t.AllowedScopes)
+	workDirsJSON, _ := json.Marshal(t.WorkDirs)
+	envKeysJSON, _ := json.Marshal(t.EnvKeys)
+	alertWatchJSON := ""
+	if t.AlertWatch != nil {
+		data, _ := json.Marshal(t.AlertWatch)
+		alertWatchJSON = string(data)
+	}
+	rateLimitJSON := ""
+	if t.RateLimit != nil {
+		data, _ := json.Marshal(t.RateLimit)
+		rateLimitJSON = string(data)
+	}
 
 	_, err := ps.db.Exec(`INSERT INTO policy_templates (
 			id, name, description, level, allowed, blocked, paths,
 			execution_class_required, sandbox_required, approval_mode, require_second_approver, breakglass_json, max_runtime_sec, allowed_scopes,
-			created_at, updated_at
+			alert_watch_json, max_concurrent_commands, heartbeat_interval_sec, inventory_interval_sec, work_dirs, env_keys, rate_limit_json, created_at, updated_at
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			name = excluded.name,
 			description = excluded.description,
@@ -193,6 +422,13 @@ func (ps *PersistentStore) persist(t *Template) error {
 			breakglass_json = excluded.breakglass_json,
 			max_runtime_sec = excluded.max_runtime_sec,
 			allowed_scopes = excluded.allowed_scopes,
+			alert_watch_json = excluded.alert_watch_json,
+			max_concurrent_commands = excluded.max_concurrent_commands,
+			heartbeat_interval_sec = excluded.heartbeat_interval_sec,
+			inventory_interval_sec = excluded.inventory_interval_sec,
+			work_dirs = excluded.work_dirs,
+			env_keys = excluded.env_keys,
+			rate_limit_json = excluded.rate_limit_json,
 			updated_at = excluded.updated_at`,
 		t.ID,
 		t.Name,
@@ -208,6 +444,13 @@ func (ps *PersistentStore) persist(t *Template) error {
 		string(breakglassJSON),
 		t.MaxRuntimeSec,
 		string(allowedScopesJSON),
+		alertWatchJSON,
+		t.MaxConcurrentCommands,
+		t.HeartbeatIntervalSec,
+		t.InventoryIntervalSec,
+		string(workDirsJSON),
+		string(envKeysJSON),
+		rateLimitJSON,
 		t.CreatedAt.Format(time.RFC3339),
 		t.UpdatedAt.Format(time.RFC3339),
 	)
@@ -218,7 +461,7 @@ func (ps *PersistentStore) loadFromDB() error {
 	rows, err := ps.db.Query(`SELECT
 		id, name, description, level, allowed, blocked, paths,
 		execution_class_required, sandbox_required, approval_mode, require_second_approver, breakglass_json, max_runtime_sec, allowed_scopes,
-		created_at, updated_at
+		alert_watch_json, max_concurrent_commands, heartbeat_interval_sec, inventory_interval_sec, work_dirs, env_keys, rate_limit_json, created_at, updated_at
 		FROM policy_templates`)
 	if err != nil {
 		return err
@@ -235,14 +478,19 @@ func (ps *PersistentStore) loadFromDB() error {
 			executionClass, approvalMode           string
 			sandboxRequired, requireSecondApprover int
 			breakglassJSON, allowedScopesJSON      string
-			maxRuntimeSec                          int
+			alertWatchJSON, workDirsJSON           string
+			envKeysJSON                            string
+			rateLimitJSON                          string
+			maxRuntimeSec, maxConcurrentCommands   int
+			heartbeatIntervalSec                   int
+			inventoryIntervalSec                   int
 			createdStr, updatedStr                 string
 		)
 		if err := rows.Scan(
 			&id, &name, &desc, &level,
 			&allowedJSON, &blockedJSON, &pathsJSON,
 			&executionClass, &sandboxRequired, &approvalMode, &requireSecondApprover, &breakglassJSON, &maxRuntimeSec, &allowedScopesJSON,
-			&createdStr, &updatedStr,
+			&alertWatchJSON, &maxConcurrentCommands, &heartbeatIntervalSec, &inventoryIntervalSec, &workDirsJSON, &envKeysJSON, &rateLimitJSON, &createdStr, &updatedStr,
 		); err != nil {
 			continue
 		}
@@ -259,6 +507,9 @@ func (ps *PersistentStore) loadFromDB() error {
 			ApprovalMode:           protocol.ApprovalMode(strings.TrimSpace(approvalMode)),
 			RequireSecondApprover:  requireSecondApprover != 0,
 			MaxRuntimeSec:          maxRuntimeSec,
+			MaxConcurrentCommands:  maxConcurrentCommands,
+			HeartbeatIntervalSec:   heartbeatIntervalSec,
+			InventoryIntervalSec:   inventoryIntervalSec,
 		}
 		if opts.ExecutionClassRequired == "" {
 			opts.ExecutionClassRequired = defaults.ExecutionClassRequired
@@ -275,6 +526,24 @@ func (ps *PersistentStore) loadFromDB() error {
 		if strings.TrimSpace(allowedScopesJSON) != "" {
 			_ = json.Unmarshal([]byte(allowedScopesJSON), &opts.AllowedScopes)
 		}
+		if strings.TrimSpace(workDirsJSON) != "" {
+			_ = json.Unmarshal([]byte(workDirsJSON), &opts.WorkDirs)
+		}
+		if strings.TrimSpace(envKeysJSON) != "" {
+			_ = json.Unmarshal([]byte(envKeysJSON), &opts.EnvKeys)
+		}
+		if strings.TrimSpace(alertWatchJSON) != "" {
+			var watch protocol.AlertWatchConfig
+			if err := json.Unmarshal([]byte(alertWatchJSON), &watch); err == nil {
+				opts.AlertWatch = &watch
+			}
+		}
+		if strings.TrimSpace(rateLimitJSON) != "" {
+			var limit protocol.CommandRateLimit
+			if err := json.Unmarshal([]byte(rateLimitJSON), &limit); err == nil {
+				opts.RateLimit = &limit
+			}
+		}
 		opts = NormalizeTemplateOptions(opts)
 
 		created, _ := time.Parse(time.RFC3339, createdStr)
@@ -295,6 +564,13 @@ func (ps *PersistentStore) loadFromDB() error {
 			Breakglass:             opts.Breakglass,
 			MaxRuntimeSec:          opts.MaxRuntimeSec,
 			AllowedScopes:          opts.AllowedScopes,
+			AlertWatch:             opts.AlertWatch,
+			MaxConcurrentCommands:  opts.MaxConcurrentCommands,
+			RateLimit:              opts.RateLimit,
+			HeartbeatIntervalSec:   opts.HeartbeatIntervalSec,
+			InventoryIntervalSec:   opts.InventoryIntervalSec,
+			WorkDirs:               opts.WorkDirs,
+			EnvKeys:                opts.EnvKeys,
 			CreatedAt:              created,
 			UpdatedAt:              updated,
 		}
@@ -58,6 +58,20 @@ func TestPersistentStoreSurvivesRestartWithV2Fields(t *testing.T) {
 			},
 			MaxRuntimeSec: 300,
 			AllowedScopes: []string{"fleet.read", "command.exec"},
+			AlertWatch: &protocol.AlertWatchConfig{
+				Units:       []string{"nginx.service"},
+				OOMKills:    true,
+				DiskPercent: 90,
+			},
+			MaxConcurrentCommands: 4,
+			RateLimit: &protocol.CommandRateLimit{
+				PerMinute: 30,
+				Cooldowns: []protocol.CommandCooldown{{Prefix: " systemctl restart ", Seconds: 30}, {Prefix: "SYSTEMCTL RESTART", Seconds: 60}},
+			},
+			HeartbeatIntervalSec: 120,
+			InventoryIntervalSec: 3600,
+			WorkDirs:             []string{"/opt/app/", "/opt/app", "/srv/www"},
+			EnvKeys:              []string{" APP_ENV ", "APP_ENV", "RELEASE"},
 		})
 	if err := s1.Close(); err != nil {
 		t.Fatal(err)
@@ -91,6 +105,28 @@ func TestPersistentStoreSurvivesRestartWithV2Fields(t *testing.T) {
 	if len(got.AllowedScopes) != 2 || got.AllowedScopes[0] != "fleet.read" {
 		t.Fatalf("allowed_scopes not restored: %v", got.AllowedScopes)
 	}
+	if got.AlertWatch == nil || !got.AlertWatch.OOMKills || got.AlertWatch.DiskPercent != 90 || len(got.AlertWatch.Units) != 1 {
+		t.Fatalf("alert_watch not restored: %+v", got.AlertWatch)
+	}
+	if policy := got.ToPolicy(); policy.AlertWatch == nil || policy.AlertWatch.Units[0] != "nginx.service" {
+		t.Fatalf("alert_watch not pushed with policy: %+v", policy.AlertWatch)
+	}
+	if got.MaxConcurrentCommands != 4 || got.ToPolicy().MaxConcurrentCommands != 4 {
+		t.Fatalf("max_concurrent_commands not restored and pushed: %d", got.MaxConcurrentCommands)
+	}
+	if limit := got.ToPolicy().RateLimit; limit == nil || limit.PerMinute != 30 || len(limit.Cooldowns) != 1 ||
+		limit.Cooldowns[0].Prefix != "systemctl restart" || limit.Cooldowns[0].Seconds != 60 {
+		t.Fatalf("rate_limit not normalized, restored and pushed: %+v", limit)
+	}
+	if policy := got.ToPolicy(); policy.HeartbeatIntervalSec != 120 || policy.InventoryIntervalSec != 3600 {
+		t.Fatalf("reporting cadence not restored and pushed: %+v", policy)
+	}
+	if policy := got.ToPolicy(); len(policy.WorkDirs) != 2 || policy.WorkDirs[0] != "/opt/app" || policy.WorkDirs[1] != "/srv/www" {
+		t.Fatalf("work_dirs not normalized, restored and pushed: %v", policy.WorkDirs)
+	}
+	if policy := got.ToPolicy(); len(policy.EnvKeys) != 2 || policy.EnvKeys[0] != "APP_ENV" || policy.EnvKeys[1] != "RELEASE" {
+		t.Fatalf("env_keys not normalized, restored and pushed: %v", policy.EnvKeys)
+	}
 }
 
 func TestPersistentStoreDelete(t *testing.T) {
@@ -190,3 +226,83 @@ func TestPersistentStoreMigrationBackfillsV2FromLegacyLevel(t *testing.T) {
 		t.Fatalf("expected mutation_gate approval mode, got %q", tpl.ApprovalMode)
 	}
 }
+
+func TestPersistentStoreAssignmentHistorySurvivesRestart(t *testing.T) {
+	dbPath := policyTempDB(t)
+	s, err := NewPersistentStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := s.RecordAssignment(Assignment{ProbeID: "probe-1", PolicyID: "diagnose", Level: protocol.CapDiagnose, PreviousLevel: protocol.CapObserve, Pushed: true, Actor: "alice", AppliedAt: base}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if _, err := s.RecordAssignment(Assignment{ProbeID: "probe-1", Action: AssignmentRollback, PolicyID: "observe-only", Level: protocol.CapObserve, PreviousPolicyID: "diagnose", PreviousLevel: protocol.CapDiagnose, AppliedAt: base.Add(time.Second)}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if _, err := s.RecordAssignment(Assignment{ProbeID: "probe-2", PolicyID: "diagnose", Level: protocol.CapDiagnose}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	s.Close()
+
+	reopened, err := NewPersistentStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	history := reopened.ListAssignments("probe-1", 0)
+	if len(history) != 2 {
+		t.Fatalf("expected 2 assignments for probe-1, got %d", len(history))
+	}
+	if history[0].Action != AssignmentRollback || history[0].PreviousPolicyID != "diagnose" {
+		t.Fatalf("expected newest-first rollback entry, got %+v", history[0])
+	}
+	if history[1].Actor != "alice" || !history[1].Pushed || history[1].PreviousLevel != protocol.CapObserve || history[1].Action != AssignmentApply {
+		t.Fatalf("unexpected reloaded apply entry %+v", history[1])
+	}
+	if got := reopened.ListAssignments("probe-1", 1); len(got) != 1 {
+		t.Fatalf("expected limit to apply, got %d", len(got))
+	}
+}
+
+func TestPersistentStoreTagRulesSurviveRestartAndMatchInOrder(t *testing.T) {
+	dbPath := policyTempDB(t)
+	s, err := NewPersistentStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddTagRule(TagRule{Tag: "role=db", PolicyID: "nope"}); err == nil {
+		t.Fatal("expected error for unknown policy template")
+	}
+	late, err := s.AddTagRule(TagRule{Tag: "role=db", PolicyID: "full-remediate", Priority: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	early, err := s.AddTagRule(TagRule{Tag: "role=db", PolicyID: "diagnose", Priority: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s, err = NewPersistentStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got := s.ListTagRules(); len(got) != 2 || got[0].ID != early.ID || got[1].ID != late.ID {
+		t.Fatalf("expected rules in priority order after restart, got %+v", got)
+	}
+	if rule, ok := s.MatchTagRule([]string{"env=prod", "Role=DB"}); !ok || rule.ID != early.ID {
+		t.Fatalf("expected lowest-priority rule to match, got %+v", rule)
+	}
+	if _, ok := s.MatchTagRule([]string{"role=web"}); ok {
+		t.Fatal("expected no match for untagged role")
+	}
+	if err := s.DeleteTagRule(early.ID); err != nil {
+		t.Fatal(err)
+	}
+	if rule, ok := s.MatchTagRule([]string{"role=db"}); !ok || rule.ID != late.ID {
+		t.Fatalf("expected remaining rule to match after delete, got %+v", rule)
+	}
+}
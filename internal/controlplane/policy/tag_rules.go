@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TagRule maps a probe tag to the policy template applied when a probe
+// carrying that tag registers. Rules are evaluated lowest priority first,
+// then oldest first; the first rule whose tag the probe carries wins.
+type TagRule struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Tag       string    `json:"tag"` // e.g. "role=db"; matched case-insensitively
+	PolicyID  string    `json:"policy_id"`
+	Priority  int       `json:"priority"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagRuleManager manages tag→policy rules.
+type TagRuleManager interface {
+	ListTagRules() []*TagRule
+	GetTagRule(id string) (*TagRule, bool)
+	AddTagRule(rule TagRule) (*TagRule, error)
+	DeleteTagRule(id string) error
+	MatchTagRule(tags []string) (*TagRule, bool)
+}
+
+// tagRuleSet is the in-memory rule set shared by Store and PersistentStore.
+type tagRuleSet struct {
+	mu    sync.RWMutex
+	rules map[string]*TagRule
+}
+
+// AddTagRule validates and stores a rule, assigning an ID and creation time
+// if missing. The referenced template must exist.
+func (s *Store) AddTagRule(rule TagRule) (*TagRule, error) {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Tag = strings.TrimSpace(rule.Tag)
+	rule.PolicyID = strings.TrimSpace(rule.PolicyID)
+
+	if rule.Tag == "" {
+		return nil, fmt.Errorf("tag is required")
+	}
+	if rule.PolicyID == "" {
+		return nil, fmt.Errorf("policy_id is required")
+	}
+	if _, ok := s.Get(rule.PolicyID); !ok {
+		return nil, fmt.Errorf("policy template %s not found", rule.PolicyID)
+	}
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	if rule.Name == "" {
+		rule.Name = rule.Tag + " → " + rule.PolicyID
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = time.Now().UTC()
+	}
+
+	s.tagRules.mu.Lock()
+	defer s.tagRules.mu.Unlock()
+	if s.tagRules.rules == nil {
+		s.tagRules.rules = make(map[string]*TagRule)
+	}
+	stored := rule
+	s.tagRules.rules[rule.ID] = &stored
+	return &stored, nil
+}
+
+// DeleteTagRule removes a rule by ID.
+func (s *Store) DeleteTagRule(id string) error {
+	s.tagRules.mu.Lock()
+	defer s.tagRules.mu.Unlock()
+	if _, ok := s.tagRules.rules[id]; !ok {
+		return fmt.Errorf("tag rule %s not found", id)
+	}
+	delete(s.tagRules.rules, id)
+	return nil
+}
+
+// GetTagRule returns a rule by ID.
+func (s *Store) GetTagRule(id string) (*TagRule, bool) {
+	s.tagRules.mu.RLock()
+	defer s.tagRules.mu.RUnlock()
+	rule, ok := s.tagRules.rules[id]
+	return rule, ok
+}
+
+// ListTagRules returns all rules in evaluation order.
+func (s *Store) ListTagRules() []*TagRule {
+	s.tagRules.mu.RLock()
+	defer s.tagRules.mu.RUnlock()
+	out := make([]*TagRule, 0, len(s.tagRules.rules))
+	for _, rule := range s.tagRules.rules {
+		out = append(out, rule)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Priority != out[j].Priority {
+			return out[i].Priority < out[j].Priority
+		}
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out
+}
+
+// MatchTagRule returns the first rule, in evaluation order, whose tag is one
+// of tags.
+func (s *Store) MatchTagRule(tags []string) (*TagRule, bool) {
+	if len(tags) == 0 {
+		return nil, false
+	}
+	for _, rule := range s.ListTagRules() {
+		for _, tag := range tags {
+			if strings.EqualFold(strings.TrimSpace(tag), rule.Tag) {
+				return rule, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -3,6 +3,8 @@ package policy
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,6 +29,31 @@ type Template struct {
 	MaxRuntimeSec          int                       `json:"max_runtime_sec,omitempty"`
 	AllowedScopes          []string                  `json:"allowed_scopes,omitempty"`
 
+	// AlertWatch is pushed with the policy to drive the probe's local alert
+	// watcher.
+	AlertWatch *protocol.AlertWatchConfig `json:"alert_watch,omitempty"`
+
+	// MaxConcurrentCommands is pushed with the policy and caps how many
+	// commands the probe runs at once. 0 means no limit.
+	MaxConcurrentCommands int `json:"max_concurrent_commands,omitempty"`
+
+	// RateLimit is pushed with the policy and throttles how often the probe
+	// accepts commands. Nil means no limit.
+	RateLimit *protocol.CommandRateLimit `json:"rate_limit,omitempty"`
+
+	// HeartbeatIntervalSec and InventoryIntervalSec set the probe's
+	// reporting cadence. 0 keeps the probe defaults.
+	HeartbeatIntervalSec int `json:"heartbeat_interval_sec,omitempty"`
+	InventoryIntervalSec int `json:"inventory_interval_sec,omitempty"`
+
+	// WorkDirs are the directories, and their subdirectories, commands may
+	// set as work_dir. Empty refuses any work_dir.
+	WorkDirs []string `json:"work_dirs,omitempty"`
+
+	// EnvKeys are the variables commands may set in env. Empty refuses any
+	// env.
+	EnvKeys []string `json:"env_keys,omitempty"`
+
 	// WASM lane runtime configuration.
 	RuntimeClass        string   `json:"runtime_class,omitempty"`
 	CPUMillis           int      `json:"cpu_millis,omitempty"`
@@ -47,6 +74,13 @@ type TemplateOptions struct {
 	Breakglass               protocol.BreakglassPolicy
 	MaxRuntimeSec            int
 	AllowedScopes            []string
+	AlertWatch               *protocol.AlertWatchConfig
+	MaxConcurrentCommands    int
+	RateLimit                *protocol.CommandRateLimit
+	HeartbeatIntervalSec     int
+	InventoryIntervalSec     int
+	WorkDirs                 []string
+	EnvKeys                  []string
 
 	// WASM lane resource constraints.
 	RuntimeClass        string
@@ -61,6 +95,7 @@ type PolicyManager interface {
 	Get(id string) (*Template, bool)
 	Create(name, description string, level protocol.CapabilityLevel, allowed, blocked, paths []string, opts TemplateOptions) *Template
 	Update(id string, name, description string, level protocol.CapabilityLevel, allowed, blocked, paths []string, opts TemplateOptions) (*Template, error)
+	Upsert(id string, name, description string, level protocol.CapabilityLevel, allowed, blocked, paths []string, opts TemplateOptions) (*Template, bool)
 	Delete(id string) error
 }
 
@@ -69,6 +104,8 @@ type Store struct {
 	templates map[string]*Template // keyed by ID
 	mu        sync.RWMutex
 	nextID    int
+	history   assignmentLog
+	tagRules  tagRuleSet
 }
 
 // NewStore creates a policy template store with built-in defaults.
@@ -211,6 +248,39 @@ func (s *Store) Update(id string, name, description string, level protocol.Capab
 	return t, nil
 }
 
+// Upsert updates the template with the given ID, or creates it under that
+// ID, and reports whether it was created. An empty ID creates a template
+// with a generated ID. Used by import, so templates keep their IDs across
+// control planes.
+func (s *Store) Upsert(id string, name, description string, level protocol.CapabilityLevel, allowed, blocked, paths []string, opts TemplateOptions) (*Template, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if id == "" {
+		s.nextID++
+		id = fmt.Sprintf("pol-%d", s.nextID)
+	}
+	t, exists := s.templates[id]
+	if !exists {
+		t = &Template{ID: id, CreatedAt: now}
+		s.templates[id] = t
+		// Keep generated IDs from colliding with imported ones.
+		if n, err := strconv.Atoi(strings.TrimPrefix(id, "pol-")); err == nil && strings.HasPrefix(id, "pol-") && n > s.nextID {
+			s.nextID = n
+		}
+	}
+	t.Name = name
+	t.Description = description
+	t.Level = level
+	t.Allowed = allowed
+	t.Blocked = blocked
+	t.Paths = paths
+	s.applyOptions(t, opts)
+	t.UpdatedAt = now
+	return t, !exists
+}
+
 // Delete removes a template.
 func (s *Store) Delete(id string) error {
 	s.mu.Lock()
@@ -238,6 +308,13 @@ func (t *Template) ToPolicy() *protocol.PolicyUpdatePayload {
 		Breakglass:             t.Breakglass,
 		MaxRuntimeSec:          t.MaxRuntimeSec,
 		AllowedScopes:          append([]string(nil), t.AllowedScopes...),
+		AlertWatch:             cloneAlertWatch(t.AlertWatch),
+		MaxConcurrentCommands:  t.MaxConcurrentCommands,
+		RateLimit:              cloneRateLimit(t.RateLimit),
+		HeartbeatIntervalSec:   t.HeartbeatIntervalSec,
+		InventoryIntervalSec:   t.InventoryIntervalSec,
+		WorkDirs:               append([]string(nil), t.WorkDirs...),
+		EnvKeys:                append([]string(nil), t.EnvKeys...),
 	}
 }
 
@@ -255,6 +332,13 @@ func (s *Store) applyOptions(tpl *Template, opts TemplateOptions) {
 	tpl.Breakglass = opts.Breakglass
 	tpl.MaxRuntimeSec = opts.MaxRuntimeSec
 	tpl.AllowedScopes = append([]string(nil), opts.AllowedScopes...)
+	tpl.AlertWatch = cloneAlertWatch(opts.AlertWatch)
+	tpl.MaxConcurrentCommands = opts.MaxConcurrentCommands
+	tpl.RateLimit = cloneRateLimit(opts.RateLimit)
+	tpl.HeartbeatIntervalSec = opts.HeartbeatIntervalSec
+	tpl.InventoryIntervalSec = opts.InventoryIntervalSec
+	tpl.WorkDirs = append([]string(nil), opts.WorkDirs...)
+	tpl.EnvKeys = append([]string(nil), opts.EnvKeys...)
 	if opts.RuntimeClass != "" {
 		tpl.RuntimeClass = opts.RuntimeClass
 	}
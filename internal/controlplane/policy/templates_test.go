@@ -127,3 +127,66 @@ func TestToPolicy(t *testing.T) {
 		t.Fatalf("expected require_second_approver false by default: %+v", pol)
 	}
 }
+
+func TestValidateWorkDirs(t *testing.T) {
+	if err := ValidateWorkDirs([]string{"/opt/app", `C:\apps\web`, "D:/data"}); err != nil {
+		t.Fatalf("expected valid work_dirs, got %v", err)
+	}
+	for _, dirs := range [][]string{{""}, {"opt/app"}, {"/opt/../etc"}, {`C:\apps\..\Windows`}, {`\\server\share`}} {
+		if err := ValidateWorkDirs(dirs); err == nil {
+			t.Errorf("expected error for %q", dirs)
+		}
+	}
+}
+
+func TestValidateEnvKeys(t *testing.T) {
+	if err := ValidateEnvKeys([]string{"APP_ENV", "RELEASE_TAG"}); err != nil {
+		t.Fatalf("expected valid env_keys, got %v", err)
+	}
+	for _, keys := range [][]string{{""}, {"BAD-KEY"}, {"LD_PRELOAD"}, {"GIT_SSH_COMMAND"}, {"home"}} {
+		if err := ValidateEnvKeys(keys); err == nil {
+			t.Errorf("expected error for %q", keys)
+		}
+	}
+}
+
+func TestValidateRateLimit(t *testing.T) {
+	valid := &protocol.CommandRateLimit{PerMinute: 60, Cooldowns: []protocol.CommandCooldown{{Prefix: "systemctl restart", Seconds: 30}}}
+	if err := ValidateRateLimit(valid); err != nil {
+		t.Fatalf("expected valid rate_limit, got %v", err)
+	}
+	if err := ValidateRateLimit(nil); err != nil {
+		t.Fatalf("expected nil rate_limit valid, got %v", err)
+	}
+	for _, limit := range []*protocol.CommandRateLimit{
+		{PerMinute: -1},
+		{PerMinute: MaxPolicyCommandsPerMinute + 1},
+		{Cooldowns: []protocol.CommandCooldown{{Prefix: " ", Seconds: 30}}},
+		{Cooldowns: []protocol.CommandCooldown{{Prefix: "reboot", Seconds: 0}}},
+	} {
+		if err := ValidateRateLimit(limit); err == nil {
+			t.Errorf("expected error for %+v", limit)
+		}
+	}
+}
+
+func TestUpsertKeepsIDAndAdvancesGeneratedIDs(t *testing.T) {
+	s := NewStore()
+
+	tpl, created := s.Upsert("pol-500", "Imported", "from staging", protocol.CapDiagnose, []string{"ls"}, nil, nil, TemplateOptions{})
+	if !created || tpl.ID != "pol-500" || tpl.Level != protocol.CapDiagnose {
+		t.Fatalf("expected pol-500 created, got %+v created=%v", tpl, created)
+	}
+	if next := s.Create("Local", "", protocol.CapObserve, nil, nil, nil, TemplateOptions{}); next.ID != "pol-501" {
+		t.Fatalf("generated ID should follow imported IDs, got %s", next.ID)
+	}
+
+	createdAt := tpl.CreatedAt
+	tpl, created = s.Upsert("pol-500", "Imported v2", "", protocol.CapObserve, nil, []string{"rm"}, nil, TemplateOptions{})
+	if created || tpl.Name != "Imported v2" || tpl.Level != protocol.CapObserve || !tpl.CreatedAt.Equal(createdAt) {
+		t.Fatalf("expected pol-500 updated in place, got %+v created=%v", tpl, created)
+	}
+	if len(s.List()) != 6 {
+		t.Fatalf("expected 4 builtins + 2 templates, got %d", len(s.List()))
+	}
+}
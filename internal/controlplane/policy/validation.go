@@ -2,15 +2,34 @@ package policy
 
 import (
 	"fmt"
+	"path"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/marcus-qen/legator/internal/protocol"
 )
 
 const MaxPolicyRuntimeSec = 86400
 
+// MaxPolicyConcurrentCommands bounds the per-probe concurrent command limit.
+const MaxPolicyConcurrentCommands = 256
+
+// Bounds for the probe command rate limit.
+const (
+	MaxPolicyCommandsPerMinute = 6000
+	MaxPolicyCooldowns         = 32
+	MaxPolicyCooldownSec       = 86400
+	maxCooldownPrefixLen       = 256
+)
+
+// Bounds for the probe alert watcher interval.
+const (
+	MinAlertWatchIntervalSec = 5
+	MaxAlertWatchIntervalSec = 3600
+)
+
 var (
 	allowedBreakglassReasons = map[string]struct{}{
 		"incident_response":  {},
@@ -18,7 +37,10 @@ var (
 		"service_outage":     {},
 		"data_recovery":      {},
 	}
-	allowedScopePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9:_./\-*]{0,127}$`)
+	allowedScopePattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9:_./\-*]{0,127}$`)
+	alertWatchUnitPattern = regexp.MustCompile(`^[A-Za-z0-9@._:\-]{1,256}$`)
+	templateIDPattern     = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+	windowsAbsPathPattern = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
 )
 
 func AllowedBreakglassReasons() []string {
@@ -78,6 +100,27 @@ func MergeTemplateOptions(base, override TemplateOptions) TemplateOptions {
 	if override.AllowedScopes != nil {
 		out.AllowedScopes = append([]string(nil), override.AllowedScopes...)
 	}
+	if override.AlertWatch != nil {
+		out.AlertWatch = cloneAlertWatch(override.AlertWatch)
+	}
+	if override.MaxConcurrentCommands != 0 {
+		out.MaxConcurrentCommands = override.MaxConcurrentCommands
+	}
+	if override.RateLimit != nil {
+		out.RateLimit = cloneRateLimit(override.RateLimit)
+	}
+	if override.HeartbeatIntervalSec != 0 {
+		out.HeartbeatIntervalSec = override.HeartbeatIntervalSec
+	}
+	if override.InventoryIntervalSec != 0 {
+		out.InventoryIntervalSec = override.InventoryIntervalSec
+	}
+	if override.WorkDirs != nil {
+		out.WorkDirs = append([]string(nil), override.WorkDirs...)
+	}
+	if override.EnvKeys != nil {
+		out.EnvKeys = append([]string(nil), override.EnvKeys...)
+	}
 	return out
 }
 
@@ -90,9 +133,45 @@ func NormalizeTemplateOptions(opts TemplateOptions) TemplateOptions {
 	if opts.MaxRuntimeSec < 0 {
 		opts.MaxRuntimeSec = 0
 	}
+	if opts.MaxConcurrentCommands < 0 {
+		opts.MaxConcurrentCommands = 0
+	}
+	if opts.HeartbeatIntervalSec < 0 {
+		opts.HeartbeatIntervalSec = 0
+	}
+	if opts.InventoryIntervalSec < 0 {
+		opts.InventoryIntervalSec = 0
+	}
+	if opts.AlertWatch != nil {
+		opts.AlertWatch = cloneAlertWatch(opts.AlertWatch)
+		opts.AlertWatch.Units = normalizeUnitNames(opts.AlertWatch.Units)
+	}
+	opts.WorkDirs = normalizeWorkDirs(opts.WorkDirs)
+	// Env keys are case-sensitive on Unix probes, like unit names.
+	opts.EnvKeys = normalizeUnitNames(opts.EnvKeys)
+	opts.RateLimit = normalizeRateLimit(opts.RateLimit)
 	return opts
 }
 
+// ValidateLevel checks a template's capability level.
+func ValidateLevel(level protocol.CapabilityLevel) error {
+	switch level {
+	case protocol.CapObserve, protocol.CapDiagnose, protocol.CapRemediate:
+		return nil
+	default:
+		return fmt.Errorf("level must be one of observe, diagnose, remediate")
+	}
+}
+
+// ValidateTemplateID checks a caller-supplied template ID, as used by
+// import.
+func ValidateTemplateID(id string) error {
+	if !templateIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid template id %q", id)
+	}
+	return nil
+}
+
 func ValidateExecutionClass(class protocol.ExecutionClass) error {
 	switch class {
 	case "", protocol.ExecObserveDirect, protocol.ExecDiagnoseSandbox, protocol.ExecRemediateSandbox, protocol.ExecBreakglassDirect, protocol.ExecWasmSandbox:
@@ -143,6 +222,203 @@ func ValidateAllowedScopes(scopes []string) error {
 	return nil
 }
 
+// ValidateMaxConcurrentCommands checks the per-probe command concurrency
+// limit. 0 leaves commands unlimited.
+func ValidateMaxConcurrentCommands(limit int) error {
+	if limit < 0 || limit > MaxPolicyConcurrentCommands {
+		return fmt.Errorf("max_concurrent_commands must be between 0 and %d", MaxPolicyConcurrentCommands)
+	}
+	return nil
+}
+
+// ValidateRateLimit checks the probe command rate limit. A nil limit is
+// valid and leaves commands unthrottled.
+func ValidateRateLimit(limit *protocol.CommandRateLimit) error {
+	if limit == nil {
+		return nil
+	}
+	if limit.PerMinute < 0 || limit.PerMinute > MaxPolicyCommandsPerMinute {
+		return fmt.Errorf("rate_limit.per_minute must be between 0 and %d", MaxPolicyCommandsPerMinute)
+	}
+	if len(limit.Cooldowns) > MaxPolicyCooldowns {
+		return fmt.Errorf("rate_limit allows at most %d cooldowns", MaxPolicyCooldowns)
+	}
+	for _, cd := range limit.Cooldowns {
+		prefix := strings.TrimSpace(cd.Prefix)
+		if prefix == "" || len(prefix) > maxCooldownPrefixLen {
+			return fmt.Errorf("rate_limit cooldown prefix must be 1 to %d characters", maxCooldownPrefixLen)
+		}
+		if cd.Seconds < 1 || cd.Seconds > MaxPolicyCooldownSec {
+			return fmt.Errorf("rate_limit cooldown for %q must be between 1 and %d seconds", prefix, MaxPolicyCooldownSec)
+		}
+	}
+	return nil
+}
+
+// ValidateReportingCadence checks the probe heartbeat and inventory
+// intervals in seconds. 0 keeps the probe default.
+func ValidateReportingCadence(heartbeatSec, inventorySec int) error {
+	if heartbeatSec != 0 && !secondsWithin(heartbeatSec, protocol.MinHeartbeatInterval, protocol.MaxHeartbeatInterval) {
+		return fmt.Errorf("heartbeat_interval_sec must be 0 or between %d and %d",
+			int(protocol.MinHeartbeatInterval/time.Second), int(protocol.MaxHeartbeatInterval/time.Second))
+	}
+	if inventorySec != 0 && !secondsWithin(inventorySec, protocol.MinInventoryInterval, protocol.MaxInventoryInterval) {
+		return fmt.Errorf("inventory_interval_sec must be 0 or between %d and %d",
+			int(protocol.MinInventoryInterval/time.Second), int(protocol.MaxInventoryInterval/time.Second))
+	}
+	return nil
+}
+
+func secondsWithin(sec int, lo, hi time.Duration) bool {
+	d := time.Duration(sec) * time.Second
+	return d >= lo && d <= hi
+}
+
+// ValidateAlertWatch checks the probe alert watcher configuration. A nil
+// config is valid and leaves the watcher off.
+func ValidateAlertWatch(watch *protocol.AlertWatchConfig) error {
+	if watch == nil {
+		return nil
+	}
+	for _, unit := range watch.Units {
+		if !alertWatchUnitPattern.MatchString(strings.TrimSpace(unit)) {
+			return fmt.Errorf("invalid alert_watch unit %q", unit)
+		}
+	}
+	if watch.DiskPercent < 0 || watch.DiskPercent > 100 {
+		return fmt.Errorf("alert_watch.disk_percent must be between 0 and 100")
+	}
+	if watch.LoadPerCPU < 0 {
+		return fmt.Errorf("alert_watch.load_per_cpu must not be negative")
+	}
+	if watch.IntervalSec != 0 && (watch.IntervalSec < MinAlertWatchIntervalSec || watch.IntervalSec > MaxAlertWatchIntervalSec) {
+		return fmt.Errorf("alert_watch.interval_sec must be between %d and %d", MinAlertWatchIntervalSec, MaxAlertWatchIntervalSec)
+	}
+	return nil
+}
+
+// ValidateWorkDirs checks the directories commands may run in. Each must be
+// an absolute path, Unix or Windows, without ".." segments.
+func ValidateWorkDirs(dirs []string) error {
+	for _, dir := range dirs {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			return fmt.Errorf("work_dirs contains empty value")
+		}
+		if !strings.HasPrefix(dir, "/") && !windowsAbsPathPattern.MatchString(dir) {
+			return fmt.Errorf("work_dir %q must be an absolute path", dir)
+		}
+		for _, segment := range strings.FieldsFunc(dir, func(r rune) bool { return r == '/' || r == '\\' }) {
+			if segment == ".." {
+				return fmt.Errorf("work_dir %q must not contain \"..\"", dir)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateEnvKeys checks the variables commands may set. Each must be a
+// well-formed name that protocol.ValidateCommandEnv would not refuse as
+// protected.
+func ValidateEnvKeys(keys []string) error {
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return fmt.Errorf("env_keys contains empty value")
+		}
+		if err := protocol.ValidateCommandEnv(map[string]string{key: ""}); err != nil {
+			return fmt.Errorf("env_keys: %w", err)
+		}
+	}
+	return nil
+}
+
+func cloneAlertWatch(watch *protocol.AlertWatchConfig) *protocol.AlertWatchConfig {
+	if watch == nil {
+		return nil
+	}
+	out := *watch
+	out.Units = append([]string(nil), watch.Units...)
+	return &out
+}
+
+func cloneRateLimit(limit *protocol.CommandRateLimit) *protocol.CommandRateLimit {
+	if limit == nil {
+		return nil
+	}
+	out := *limit
+	out.Cooldowns = append([]protocol.CommandCooldown(nil), limit.Cooldowns...)
+	return &out
+}
+
+// normalizeRateLimit trims cooldown prefixes and keeps the longest cooldown
+// for a repeated prefix. A limit that throttles nothing becomes nil.
+func normalizeRateLimit(limit *protocol.CommandRateLimit) *protocol.CommandRateLimit {
+	if limit == nil {
+		return nil
+	}
+	out := &protocol.CommandRateLimit{PerMinute: max(limit.PerMinute, 0)}
+	index := map[string]int{}
+	for _, cd := range limit.Cooldowns {
+		cd.Prefix = strings.TrimSpace(cd.Prefix)
+		if cd.Prefix == "" || cd.Seconds <= 0 {
+			continue
+		}
+		key := strings.ToLower(cd.Prefix)
+		if i, ok := index[key]; ok {
+			out.Cooldowns[i].Seconds = max(out.Cooldowns[i].Seconds, cd.Seconds)
+			continue
+		}
+		index[key] = len(out.Cooldowns)
+		out.Cooldowns = append(out.Cooldowns, cd)
+	}
+	if out.PerMinute == 0 && len(out.Cooldowns) == 0 {
+		return nil
+	}
+	return out
+}
+
+// normalizeWorkDirs trims, cleans and deduplicates work directories. Paths
+// are case-sensitive on most probes, so case is kept.
+func normalizeWorkDirs(dirs []string) []string {
+	var out []string
+	seen := map[string]struct{}{}
+	for _, dir := range dirs {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+		if strings.HasPrefix(dir, "/") {
+			dir = path.Clean(dir)
+		}
+		if _, ok := seen[dir]; ok {
+			continue
+		}
+		seen[dir] = struct{}{}
+		out = append(out, dir)
+	}
+	return out
+}
+
+// normalizeUnitNames trims and deduplicates unit names. Unlike scopes, unit
+// names are case-sensitive.
+func normalizeUnitNames(units []string) []string {
+	var out []string
+	seen := map[string]struct{}{}
+	for _, unit := range units {
+		unit = strings.TrimSpace(unit)
+		if unit == "" {
+			continue
+		}
+		if _, ok := seen[unit]; ok {
+			continue
+		}
+		seen[unit] = struct{}{}
+		out = append(out, unit)
+	}
+	return out
+}
+
 func normalizeStringSlice(values []string) []string {
 	if len(values) == 0 {
 		return nil
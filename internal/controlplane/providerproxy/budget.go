@@ -0,0 +1,120 @@
+package providerproxy
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// budgetThresholds are the percentages of the monthly budget that trigger a
+// BudgetAlert when first crossed in a month.
+var budgetThresholds = []int{80, 100}
+
+// MonthlySpend aggregates spend for one calendar month (UTC).
+type MonthlySpend struct {
+	Month         string  `json:"month"` // YYYY-MM
+	InputTokens   int     `json:"input_tokens"`
+	OutputTokens  int     `json:"output_tokens"`
+	TotalTokens   int     `json:"total_tokens"`
+	EstimatedCost float64 `json:"estimated_cost"`
+}
+
+// BudgetStatus reports month-to-date spend against the monthly budget.
+type BudgetStatus struct {
+	MonthlySpend
+	BudgetUSD   float64 `json:"budget_usd"`
+	PercentUsed float64 `json:"percent_used"`
+	Exceeded    bool    `json:"exceeded"`
+}
+
+// BudgetAlert is emitted when month-to-date spend crosses a budget threshold.
+type BudgetAlert struct {
+	Month            string
+	ThresholdPercent int
+	SpendUSD         float64
+	BudgetUSD        float64
+	RunID            string
+}
+
+// BudgetSink receives monthly budget threshold alerts.
+type BudgetSink interface {
+	RecordBudgetThreshold(ctx context.Context, alert BudgetAlert)
+}
+
+// BudgetSinkFunc adapts a function to BudgetSink.
+type BudgetSinkFunc func(ctx context.Context, alert BudgetAlert)
+
+func (fn BudgetSinkFunc) RecordBudgetThreshold(ctx context.Context, alert BudgetAlert) {
+	fn(ctx, alert)
+}
+
+// MonthTotals aggregates spend for the UTC calendar month containing at.
+// Spend resets naturally at the month boundary.
+func (s *SpendStore) MonthTotals(ctx context.Context, at time.Time) (MonthlySpend, error) {
+	if s == nil || s.db == nil {
+		return MonthlySpend{}, fmt.Errorf("provider proxy spend store unavailable")
+	}
+
+	month := at.UTC().Format("2006-01")
+	totals := MonthlySpend{Month: month}
+	row := s.db.QueryRowContext(ctx, `SELECT
+		COALESCE(SUM(input_tokens), 0),
+		COALESCE(SUM(output_tokens), 0),
+		COALESCE(SUM(total_tokens), 0),
+		COALESCE(SUM(estimated_cost), 0)
+		FROM provider_proxy_spend
+		WHERE substr(created_at, 1, 7) = ?`, month)
+	if err := row.Scan(&totals.InputTokens, &totals.OutputTokens, &totals.TotalTokens, &totals.EstimatedCost); err != nil {
+		return MonthlySpend{}, fmt.Errorf("query provider proxy monthly spend: %w", err)
+	}
+	return totals, nil
+}
+
+// BudgetStatus returns month-to-date spend against the configured budget.
+// BudgetUSD is zero when no monthly budget is configured.
+func (p *Proxy) BudgetStatus(ctx context.Context) (BudgetStatus, error) {
+	if p == nil {
+		return BudgetStatus{}, fmt.Errorf("provider proxy unavailable")
+	}
+	spend, err := p.spend.MonthTotals(ctx, p.now())
+	if err != nil {
+		return BudgetStatus{}, err
+	}
+	status := BudgetStatus{MonthlySpend: spend, BudgetUSD: p.monthlyBudgetUSD}
+	if p.monthlyBudgetUSD > 0 {
+		status.PercentUsed = spend.EstimatedCost / p.monthlyBudgetUSD * 100
+		status.Exceeded = spend.EstimatedCost >= p.monthlyBudgetUSD
+	}
+	return status, nil
+}
+
+// notifyBudgetThresholds emits one alert per threshold crossed by the spend
+// moving from before to after.
+func (p *Proxy) notifyBudgetThresholds(ctx context.Context, runID, month string, before, after float64) {
+	if p.budgetSink == nil || p.monthlyBudgetUSD <= 0 {
+		return
+	}
+	for _, pct := range budgetThresholdsCrossed(before, after, p.monthlyBudgetUSD) {
+		p.budgetSink.RecordBudgetThreshold(ctx, BudgetAlert{
+			Month:            month,
+			ThresholdPercent: pct,
+			SpendUSD:         after,
+			BudgetUSD:        p.monthlyBudgetUSD,
+			RunID:            runID,
+		})
+	}
+}
+
+func budgetThresholdsCrossed(before, after, budget float64) []int {
+	if budget <= 0 || after <= before {
+		return nil
+	}
+	var crossed []int
+	for _, pct := range budgetThresholds {
+		limit := budget * float64(pct) / 100
+		if before < limit && after >= limit {
+			crossed = append(crossed, pct)
+		}
+	}
+	return crossed
+}
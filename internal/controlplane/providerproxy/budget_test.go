@@ -0,0 +1,124 @@
+package providerproxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type budgetCapture struct {
+	mu     sync.Mutex
+	alerts []BudgetAlert
+}
+
+func (b *budgetCapture) RecordBudgetThreshold(_ context.Context, alert BudgetAlert) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.alerts = append(b.alerts, alert)
+}
+
+func TestBudgetThresholdsCrossed(t *testing.T) {
+	cases := []struct {
+		before, after float64
+		want          []int
+	}{
+		{0, 5, nil},
+		{7, 8, []int{80}},
+		{8, 9, nil},
+		{9, 10, []int{100}},
+		{7, 12, []int{80, 100}},
+		{12, 13, nil},
+	}
+	for _, tc := range cases {
+		if got := budgetThresholdsCrossed(tc.before, tc.after, 10); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("crossed(%v -> %v) = %v, want %v", tc.before, tc.after, got, tc.want)
+		}
+	}
+	if got := budgetThresholdsCrossed(0, 100, 0); got != nil {
+		t.Fatalf("expected no thresholds without a budget, got %v", got)
+	}
+}
+
+func TestProxyEnforcesMonthlyBudget(t *testing.T) {
+	var providerCalls int
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		providerCalls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"gpt-4o","usage":{"prompt_tokens":1000000,"completion_tokens":0,"total_tokens":1000000}}`))
+	}))
+	defer provider.Close()
+
+	spend, err := NewSpendStore(t.TempDir() + "/spend.db")
+	if err != nil {
+		t.Fatalf("new spend store: %v", err)
+	}
+	defer spend.Close()
+
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	// Last month's spend must not count against this month's budget.
+	if _, err := spend.Record(context.Background(), SpendRecord{RunID: "run-old", EstimatedCost: 100, CreatedAt: now.AddDate(0, -1, 0)}); err != nil {
+		t.Fatalf("seed prior month spend: %v", err)
+	}
+	if _, err := spend.Record(context.Background(), SpendRecord{RunID: "run-old", EstimatedCost: 7.5, CreatedAt: now.Add(-time.Hour)}); err != nil {
+		t.Fatalf("seed month spend: %v", err)
+	}
+
+	alerts := &budgetCapture{}
+	proxy, err := New(ProxyConfig{
+		TokenValidator: validTokenValidator(),
+		Credentials: CredentialResolverFunc(func(context.Context, string, string) (ProviderCredentials, error) {
+			return ProviderCredentials{BaseURL: provider.URL, APIKey: "sk-test", Model: "gpt-4o"}, nil
+		}),
+		SpendStore:       spend,
+		MonthlyBudgetUSD: 10,
+		BudgetSink:       alerts,
+		Now:              func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("new proxy: %v", err)
+	}
+
+	call := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"run_token":"ok","session_id":"sess-1","messages":[{"role":"user","content":"hi"}]}`))
+		proxy.HandleHTTP(rr, req, "run-123")
+		return rr
+	}
+
+	if rr := call(); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	if len(alerts.alerts) != 2 || alerts.alerts[0].ThresholdPercent != 80 || alerts.alerts[1].ThresholdPercent != 100 {
+		t.Fatalf("expected 80%% and 100%% alerts, got %+v", alerts.alerts)
+	}
+	if alerts.alerts[1].Month != "2026-10" || alerts.alerts[1].SpendUSD != 10 {
+		t.Fatalf("unexpected alert payload: %+v", alerts.alerts[1])
+	}
+
+	status, err := proxy.BudgetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("budget status: %v", err)
+	}
+	if !status.Exceeded || status.PercentUsed != 100 || status.EstimatedCost != 10 {
+		t.Fatalf("unexpected budget status: %+v", status)
+	}
+
+	rr := call()
+	if rr.Code != http.StatusTooManyRequests || !strings.Contains(rr.Body.String(), "monthly_budget_exceeded") {
+		t.Fatalf("expected 429 monthly_budget_exceeded, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	if providerCalls != 1 {
+		t.Fatalf("expected provider called once, got %d", providerCalls)
+	}
+
+	// Spend resets at the month boundary.
+	now = time.Date(2026, 11, 1, 0, 0, 1, 0, time.UTC)
+	if rr := call(); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 after month rollover, got %d body=%s", rr.Code, rr.Body.String())
+	}
+}
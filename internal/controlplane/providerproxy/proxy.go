@@ -129,6 +129,14 @@ type ProxyConfig struct {
 	MaxTokensPerRun  int
 	MaxCostPerRun    float64
 	UpstreamEndpoint string
+
+	// MonthlyBudgetUSD rejects proxy calls once month-to-date spend reaches
+	// it. Zero disables the budget.
+	MonthlyBudgetUSD float64
+	// BudgetSink receives 80%/100% monthly budget alerts.
+	BudgetSink BudgetSink
+	// Now overrides the clock (tests).
+	Now func() time.Time
 }
 
 // Proxy forwards OpenAI-compatible requests through control-plane-managed credentials.
@@ -141,6 +149,9 @@ type Proxy struct {
 	maxTokensPerRun   int
 	maxCostPerRun     float64
 	upstreamChatRoute string
+	monthlyBudgetUSD  float64
+	budgetSink        BudgetSink
+	now               func() time.Time
 }
 
 func New(cfg ProxyConfig) (*Proxy, error) {
@@ -164,6 +175,10 @@ func New(cfg ProxyConfig) (*Proxy, error) {
 	if !strings.HasPrefix(route, "/") {
 		route = "/" + route
 	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
 
 	return &Proxy{
 		tokens:            cfg.TokenValidator,
@@ -174,6 +189,9 @@ func New(cfg ProxyConfig) (*Proxy, error) {
 		maxTokensPerRun:   cfg.MaxTokensPerRun,
 		maxCostPerRun:     cfg.MaxCostPerRun,
 		upstreamChatRoute: route,
+		monthlyBudgetUSD:  cfg.MonthlyBudgetUSD,
+		budgetSink:        cfg.BudgetSink,
+		now:               now,
 	}, nil
 }
 
@@ -268,6 +286,15 @@ func (p *Proxy) execute(ctx context.Context, runID string, req ProxyRequest) (*e
 	if p.maxCostPerRun > 0 && totals.EstimatedCost >= p.maxCostPerRun {
 		return nil, &statusError{Status: http.StatusTooManyRequests, Code: "spend_limit_exceeded", Message: "max cost per run exceeded"}
 	}
+	if p.monthlyBudgetUSD > 0 {
+		monthly, err := p.spend.MonthTotals(ctx, p.now())
+		if err != nil {
+			return nil, &statusError{Status: http.StatusInternalServerError, Code: "internal_error", Message: "failed to query spend"}
+		}
+		if monthly.EstimatedCost >= p.monthlyBudgetUSD {
+			return nil, &statusError{Status: http.StatusTooManyRequests, Code: "monthly_budget_exceeded", Message: "monthly provider budget exceeded"}
+		}
+	}
 
 	creds, err := p.credentials.ResolveProviderCredentials(ctx, runID, strings.TrimSpace(req.Model))
 	if err != nil {
@@ -343,10 +370,16 @@ func (p *Proxy) execute(ctx context.Context, runID string, req ProxyRequest) (*e
 		OutputTokens:  usage.OutputTokens,
 		TotalTokens:   usage.TotalTokens,
 		EstimatedCost: cost,
+		CreatedAt:     p.now().UTC(),
 	})
 	if err != nil {
 		return nil, &statusError{Status: http.StatusInternalServerError, Code: "internal_error", Message: "failed to record spend"}
 	}
+	if p.monthlyBudgetUSD > 0 && cost > 0 {
+		if monthly, err := p.spend.MonthTotals(ctx, p.now()); err == nil {
+			p.notifyBudgetThresholds(ctx, runID, monthly.Month, monthly.EstimatedCost-cost, monthly.EstimatedCost)
+		}
+	}
 
 	if p.maxTokensPerRun > 0 && totals.TotalTokens > p.maxTokensPerRun {
 		return nil, &statusError{Status: http.StatusTooManyRequests, Code: "spend_limit_exceeded", Message: "max tokens per run exceeded"}
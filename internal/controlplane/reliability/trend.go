@@ -0,0 +1,186 @@
+package reliability
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTrendBuckets = 12
+	maxTrendBuckets     = 288
+)
+
+// Trend is the API response for bucketed reliability indicator history.
+type Trend struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Window      ScorecardWindow `json:"window"`
+	BucketWidth string          `json:"bucket_width"`
+	Points      []TrendPoint    `json:"points"`
+}
+
+// TrendPoint is the scorecard outcome for one bucket, oldest first.
+type TrendPoint struct {
+	From       time.Time        `json:"from"`
+	To         time.Time        `json:"to"`
+	Score      int              `json:"score"`
+	Status     string           `json:"status"`
+	Indicators []TrendIndicator `json:"indicators"`
+}
+
+// TrendIndicator is the per-bucket value of one scorecard indicator.
+type TrendIndicator struct {
+	ID         string   `json:"id"`
+	Status     string   `json:"status"`
+	Score      int      `json:"score"`
+	Value      *float64 `json:"value,omitempty"`
+	Unit       string   `json:"unit"`
+	SampleSize int      `json:"sample_size"`
+}
+
+// TrendInputsFunc supplies scorecard inputs for the bucket [from, to).
+type TrendInputsFunc func(from, to time.Time) Inputs
+
+// NormalizeTrendBuckets clamps a requested bucket count to the supported range.
+func NormalizeTrendBuckets(buckets int) int {
+	if buckets <= 0 {
+		return defaultTrendBuckets
+	}
+	if buckets > maxTrendBuckets {
+		return maxTrendBuckets
+	}
+	return buckets
+}
+
+// BuildTrend splits window into equal buckets ending at now and scores each
+// with BuildScorecard, so trend points use the same indicator definitions as
+// the point-in-time scorecard.
+func BuildTrend(now time.Time, window time.Duration, buckets int, inputs TrendInputsFunc) Trend {
+	now = now.UTC()
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+	if window <= 0 {
+		window = defaultWindow
+	}
+	buckets = NormalizeTrendBuckets(buckets)
+	width := window / time.Duration(buckets)
+	if width <= 0 {
+		width = window
+		buckets = 1
+	}
+
+	start := now.Add(-width * time.Duration(buckets))
+	trend := Trend{
+		GeneratedAt: now,
+		Window: ScorecardWindow{
+			Duration: window.String(),
+			From:     start,
+			To:       now,
+		},
+		BucketWidth: width.String(),
+		Points:      make([]TrendPoint, 0, buckets),
+	}
+
+	for i := 0; i < buckets; i++ {
+		from := start.Add(width * time.Duration(i))
+		to := from.Add(width)
+		in := inputs(from, to)
+		in.Now = to
+		in.Window = width
+
+		scorecard := BuildScorecard(in)
+		point := TrendPoint{
+			From:       from,
+			To:         to,
+			Score:      scorecard.Overall.Score,
+			Status:     scorecard.Overall.Status,
+			Indicators: []TrendIndicator{},
+		}
+		for _, surface := range scorecard.Surfaces {
+			for _, indicator := range surface.Indicators {
+				point.Indicators = append(point.Indicators, TrendIndicator{
+					ID:         indicator.ID,
+					Status:     indicator.Status,
+					Score:      indicator.Score,
+					Value:      indicator.Metric.Value,
+					Unit:       indicator.Metric.Unit,
+					SampleSize: indicator.Metric.SampleSize,
+				})
+			}
+		}
+		trend.Points = append(trend.Points, point)
+	}
+	return trend
+}
+
+type connectivitySample struct {
+	Timestamp time.Time
+	Total     int
+	Connected int
+}
+
+// ConnectivityHistory keeps periodic probe connectivity samples so trend
+// buckets can report fleet connectivity for past windows.
+type ConnectivityHistory struct {
+	mu      sync.RWMutex
+	samples []connectivitySample
+	maxAge  time.Duration
+}
+
+// NewConnectivityHistory creates an in-memory connectivity sample ring.
+func NewConnectivityHistory(maxAge time.Duration) *ConnectivityHistory {
+	return &ConnectivityHistory{maxAge: maxAge}
+}
+
+// Record stores one connectivity sample and drops samples older than maxAge.
+func (h *ConnectivityHistory) Record(at time.Time, total, connected int) {
+	if h == nil {
+		return
+	}
+	at = at.UTC()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.samples = append(h.samples, connectivitySample{Timestamp: at, Total: total, Connected: connected})
+	if h.maxAge <= 0 {
+		return
+	}
+	cutoff := at.Add(-h.maxAge)
+	firstValid := 0
+	for firstValid < len(h.samples) && h.samples[firstValid].Timestamp.Before(cutoff) {
+		firstValid++
+	}
+	if firstValid > 0 {
+		h.samples = append([]connectivitySample(nil), h.samples[firstValid:]...)
+	}
+}
+
+// Average returns the mean fleet size and connected count over [from, to).
+// ok is false when no sample falls inside the range.
+func (h *ConnectivityHistory) Average(from, to time.Time) (ProbeFleetInputs, bool) {
+	if h == nil {
+		return ProbeFleetInputs{}, false
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var total, connected, n int
+	for _, sample := range h.samples {
+		if sample.Timestamp.Before(from) || !sample.Timestamp.Before(to) {
+			continue
+		}
+		total += sample.Total
+		connected += sample.Connected
+		n++
+	}
+	if n == 0 {
+		return ProbeFleetInputs{}, false
+	}
+	return ProbeFleetInputs{
+		TotalProbes:     int(math.Round(float64(total) / float64(n))),
+		ConnectedProbes: int(math.Round(float64(connected) / float64(n))),
+	}, true
+}
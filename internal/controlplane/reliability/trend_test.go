@@ -0,0 +1,76 @@
+package reliability
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildTrendBucketsWindowOldestFirst(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var calls [][2]time.Time
+	trend := BuildTrend(now, time.Hour, 4, func(from, to time.Time) Inputs {
+		calls = append(calls, [2]time.Time{from, to})
+		in := Inputs{ProbeFleet: ProbeFleetInputs{TotalProbes: 10, ConnectedProbes: 10}}
+		if len(calls) == 4 {
+			in.ProbeFleet.ConnectedProbes = 5
+		}
+		return in
+	})
+
+	if trend.BucketWidth != "15m0s" || len(trend.Points) != 4 {
+		t.Fatalf("expected 4 x 15m buckets, got %s x %d", trend.BucketWidth, len(trend.Points))
+	}
+	if !trend.Window.From.Equal(now.Add(-time.Hour)) || !trend.Points[3].To.Equal(now) {
+		t.Fatalf("unexpected bucket bounds: window=%+v last=%+v", trend.Window, trend.Points[3])
+	}
+	for i, call := range calls {
+		if !call[0].Equal(trend.Points[i].From) || call[1].Sub(call[0]) != 15*time.Minute {
+			t.Fatalf("bucket %d inputs requested for %v", i, call)
+		}
+	}
+
+	connectivity := func(p TrendPoint) TrendIndicator {
+		for _, indicator := range p.Indicators {
+			if indicator.ID == "probe_fleet.connectivity" {
+				return indicator
+			}
+		}
+		t.Fatalf("connectivity indicator missing from %+v", p)
+		return TrendIndicator{}
+	}
+	if got := connectivity(trend.Points[0]); got.Status != "pass" || *got.Value != 100 {
+		t.Fatalf("expected passing connectivity in first bucket, got %+v", got)
+	}
+	if got := connectivity(trend.Points[3]); got.Status != "fail" || *got.Value != 50 {
+		t.Fatalf("expected failing connectivity in last bucket, got %+v", got)
+	}
+}
+
+func TestNormalizeTrendBuckets(t *testing.T) {
+	for in, want := range map[int]int{0: 12, -3: 12, 6: 6, 10000: 288} {
+		if got := NormalizeTrendBuckets(in); got != want {
+			t.Fatalf("NormalizeTrendBuckets(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestConnectivityHistoryAverageAndPrune(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	history := NewConnectivityHistory(time.Hour)
+	history.Record(start, 10, 10)
+	history.Record(start.Add(time.Minute), 10, 8)
+	history.Record(start.Add(2*time.Minute), 12, 9)
+
+	avg, ok := history.Average(start, start.Add(2*time.Minute))
+	if !ok || avg.TotalProbes != 10 || avg.ConnectedProbes != 9 {
+		t.Fatalf("unexpected average: %+v ok=%v", avg, ok)
+	}
+	if _, ok := history.Average(start.Add(10*time.Minute), start.Add(20*time.Minute)); ok {
+		t.Fatal("expected no samples in empty range")
+	}
+
+	history.Record(start.Add(2*time.Hour), 4, 4)
+	if _, ok := history.Average(start, start.Add(time.Hour)); ok {
+		t.Fatal("expected samples older than max age to be pruned")
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+)
+
+// apiKeyRateLimit returns the requests-per-minute limit for an API key, or
+// zero when the key is not limited. An explicit per-key limit always applies;
+// otherwise admin keys are exempt and other keys use rate_limit.requests_per_minute.
+func (s *Server) apiKeyRateLimit(key *auth.APIKey) int {
+	if key.RateLimit > 0 {
+		return key.RateLimit
+	}
+	if auth.HasPermission(key, auth.PermAdmin) {
+		return 0
+	}
+	return s.cfg.RateLimit.RequestsPerMinute
+}
+
+// apiKeyRateLimitMiddleware throttles requests authenticated with an API key
+// using a token bucket per key. Session users are not rate limited.
+func (s *Server) apiKeyRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := auth.FromContext(r.Context())
+		if key == nil || s.keyLimiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limit := s.apiKeyRateLimit(key)
+		decision := s.keyLimiter.Allow(key.ID, limit)
+		if decision.Allowed {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		if decision.FirstDenial {
+			s.recordAudit(audit.Event{
+				Type:    audit.EventAPIKeyRateLimited,
+				Actor:   key.Name,
+				Summary: fmt.Sprintf("API key %s rate limited at %d requests/minute", key.KeyPrefix, limit),
+				Detail: map[string]any{
+					"key_id":              key.ID,
+					"key_prefix":          key.KeyPrefix,
+					"requests_per_minute": limit,
+					"method":              r.Method,
+					"path":                r.URL.Path,
+				},
+			})
+		}
+
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		writeJSONError(w, http.StatusTooManyRequests, "rate_limited", "rate limit exceeded")
+	})
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/controlplane/config"
+)
+
+func TestAPIKeyRateLimitMiddleware(t *testing.T) {
+	srv := &Server{
+		cfg:        config.Config{RateLimit: config.RateLimitConfig{RequestsPerMinute: 2}},
+		auditLog:   audit.NewLog(100),
+		keyLimiter: auth.NewKeyRateLimiter(),
+	}
+	handler := srv.apiKeyRateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(key *auth.APIKey) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/probes", nil)
+		req = req.WithContext(auth.WithAPIKeyContext(req.Context(), key))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	reader := &auth.APIKey{ID: "k1", Name: "ci", KeyPrefix: "lgk_aaaaaaaa", Permissions: []auth.Permission{auth.PermFleetRead}}
+	for i := 0; i < 2; i++ {
+		if rr := do(reader); rr.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i+1, rr.Code)
+		}
+	}
+	rr := do(reader)
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rr.Code)
+	}
+	if rr.Header().Get("Retry-After") == "" {
+		t.Fatal("expected Retry-After header")
+	}
+	do(reader)
+	if events := srv.auditLog.Query(audit.Filter{Type: audit.EventAPIKeyRateLimited}); len(events) != 1 {
+		t.Fatalf("expected one rate limit audit event per episode, got %d", len(events))
+	}
+
+	custom := &auth.APIKey{ID: "k2", Name: "bulk", Permissions: []auth.Permission{auth.PermFleetRead}, RateLimit: 5}
+	for i := 0; i < 5; i++ {
+		if rr := do(custom); rr.Code != http.StatusOK {
+			t.Fatalf("custom key request %d: expected 200, got %d", i+1, rr.Code)
+		}
+	}
+
+	admin := &auth.APIKey{ID: "k3", Name: "admin", Permissions: []auth.Permission{auth.PermAdmin}}
+	for i := 0; i < 10; i++ {
+		if rr := do(admin); rr.Code != http.StatusOK {
+			t.Fatalf("admin key request %d: expected 200, got %d", i+1, rr.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/probes", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("requests without an API key should pass, got %d", rr.Code)
+	}
+}
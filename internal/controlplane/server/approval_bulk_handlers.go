@@ -0,0 +1,212 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/marcus-qen/legator/internal/controlplane/approval"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	coreapprovalpolicy "github.com/marcus-qen/legator/internal/controlplane/core/approvalpolicy"
+)
+
+// maxBulkApprovalDecisions caps how many approvals one bulk request decides.
+const maxBulkApprovalDecisions = 200
+
+// bulkApprovalFilter selects pending approvals by probe, probe tag and/or a
+// glob over the full command line. Populated fields must all match.
+type bulkApprovalFilter struct {
+	ProbeID     string `json:"probe_id"`
+	ProbeTag    string `json:"probe_tag"`
+	CommandGlob string `json:"command_glob"`
+}
+
+func (f bulkApprovalFilter) empty() bool {
+	return strings.TrimSpace(f.ProbeID) == "" && strings.TrimSpace(f.ProbeTag) == "" && strings.TrimSpace(f.CommandGlob) == ""
+}
+
+type bulkApprovalResult struct {
+	ID      string `json:"id"`
+	ProbeID string `json:"probe_id,omitempty"`
+	Status  string `json:"status"` // resulting decision, or "error"
+	Code    string `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// handleDecideApprovalsBulk applies one decision to many approvals. Targets
+// are either explicit IDs or every pending approval matching a filter, and
+// confirm_count must equal the number of targets so a broad filter can't
+// approve more than the caller expected. dry_run returns the targets without
+// deciding anything. Each approval goes through the approval core on its own,
+// so audit entries, events and dispatch stay per approval.
+func (s *Server) handleDecideApprovalsBulk(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermApprovalWrite) {
+		return
+	}
+	var body struct {
+		IDs          []string            `json:"ids"`
+		Filter       *bulkApprovalFilter `json:"filter"`
+		Decision     string              `json:"decision"`
+		DecidedBy    string              `json:"decided_by"`
+		Reason       string              `json:"reason"`
+		Source       string              `json:"source"`
+		ConfirmCount *int                `json:"confirm_count"`
+		DryRun       bool                `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	decision := approval.Decision(strings.TrimSpace(body.Decision))
+	if decision != approval.DecisionApproved && decision != approval.DecisionDenied {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "decision must be approved or denied")
+		return
+	}
+	decidedBy := strings.TrimSpace(body.DecidedBy)
+	if decidedBy == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "decided_by is required")
+		return
+	}
+	details := approval.DecisionDetails{
+		Reason: strings.TrimSpace(body.Reason),
+		Source: strings.ToLower(strings.TrimSpace(body.Source)),
+	}
+	if details.Source == "" {
+		details.Source = approval.DecisionSourceAPI
+	}
+	if !approval.ValidDecisionSource(details.Source) {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("unknown decision source %q", body.Source))
+		return
+	}
+	hasFilter := body.Filter != nil && !body.Filter.empty()
+	if (len(body.IDs) > 0) == hasFilter {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "provide either ids or a non-empty filter")
+		return
+	}
+
+	wsID := s.workspaceJobFilter(r)
+	var targets []string
+	if hasFilter {
+		matched, err := s.matchPendingApprovals(wsID, *body.Filter)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		targets = matched
+	} else {
+		seen := make(map[string]bool, len(body.IDs))
+		for _, id := range body.IDs {
+			id = strings.TrimSpace(id)
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			targets = append(targets, id)
+		}
+	}
+	if len(targets) > maxBulkApprovalDecisions {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("bulk decisions are limited to %d approvals; %d matched", maxBulkApprovalDecisions, len(targets)))
+		return
+	}
+
+	if body.DryRun {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"dry_run":  true,
+			"decision": decision,
+			"ids":      targets,
+			"count":    len(targets),
+		})
+		return
+	}
+	if len(targets) == 0 {
+		writeJSONError(w, http.StatusNotFound, "not_found", "no approvals matched")
+		return
+	}
+	if body.ConfirmCount == nil || *body.ConfirmCount != len(targets) {
+		writeJSONError(w, http.StatusConflict, "confirm_count_mismatch", fmt.Sprintf("confirm_count must equal the number of targeted approvals (%d)", len(targets)))
+		return
+	}
+
+	results := make([]bulkApprovalResult, 0, len(targets))
+	succeeded := 0
+	for _, id := range targets {
+		result := bulkApprovalResult{ID: id, Status: "error"}
+		req, ok := s.approvalQueue.Get(id)
+		if wsID != "" {
+			req, ok = s.approvalQueue.GetCheckWorkspace(id, wsID)
+		}
+		if !ok {
+			result.Code = "not_found"
+			result.Error = "approval request not found"
+			results = append(results, result)
+			continue
+		}
+		result.ProbeID = req.ProbeID
+
+		decided, err := s.approvalCore.DecideAndDispatchWithDetails(id, decision, decidedBy, details, s.dispatchApprovedCommand)
+		if decided != nil && decided.Request != nil {
+			result.Status = string(decided.Request.Decision)
+		}
+		if httpErr, failed := coreapprovalpolicy.DecideApprovalHTTPError(err); failed {
+			result.Code = httpErr.Code
+			result.Error = httpErr.Message
+		} else {
+			succeeded++
+		}
+		results = append(results, result)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"decision":  decision,
+		"requested": len(targets),
+		"succeeded": succeeded,
+		"failed":    len(targets) - succeeded,
+		"results":   results,
+	})
+}
+
+// matchPendingApprovals returns the IDs of pending approvals in the workspace
+// that match the filter, newest first.
+func (s *Server) matchPendingApprovals(wsID string, filter bulkApprovalFilter) ([]string, error) {
+	probeID := strings.TrimSpace(filter.ProbeID)
+	tag := strings.TrimSpace(filter.ProbeTag)
+	glob := strings.TrimSpace(filter.CommandGlob)
+
+	var ids []string
+	for _, req := range s.approvalQueue.PendingByWorkspace(wsID) {
+		if probeID != "" && req.ProbeID != probeID {
+			continue
+		}
+		if tag != "" && !s.probeHasTag(req.ProbeID, tag) {
+			continue
+		}
+		if glob != "" {
+			ok, err := approval.MatchCommandGlob(glob, req.Command)
+			if err != nil {
+				return nil, fmt.Errorf("invalid command_glob: %w", err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		ids = append(ids, req.ID)
+	}
+	return ids, nil
+}
+
+func (s *Server) probeHasTag(probeID, tag string) bool {
+	ps, ok := s.fleetMgr.Get(probeID)
+	if !ok {
+		return false
+	}
+	for _, t := range ps.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
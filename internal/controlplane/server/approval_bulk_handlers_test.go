@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/approval"
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestHandleDecideApprovalsBulk(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-web", "web", "linux", "amd64")
+	srv.fleetMgr.Register("probe-db", "db", "linux", "amd64")
+	if err := srv.fleetMgr.SetTags("probe-web", []string{"incident"}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+
+	submit := func(probeID, requestID, command string) string {
+		t.Helper()
+		req, err := srv.approvalQueue.Submit(probeID, &protocol.CommandPayload{RequestID: requestID, Command: command}, "manual", "high", "api")
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+		return req.ID
+	}
+	restart1 := submit("probe-web", "req-1", "systemctl restart nginx")
+	restart2 := submit("probe-web", "req-2", "systemctl restart php-fpm")
+	other := submit("probe-db", "req-3", "systemctl restart postgres")
+
+	decide := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/approvals/decide-bulk", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		srv.handleDecideApprovalsBulk(rr, req)
+		return rr
+	}
+
+	filter := `"filter":{"probe_tag":"incident","command_glob":"systemctl restart *"}`
+	rr := decide(`{"decision":"denied","decided_by":"oncall",` + filter + `,"dry_run":true}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("dry run status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	var dry struct {
+		IDs   []string `json:"ids"`
+		Count int      `json:"count"`
+	}
+	_ = json.NewDecoder(rr.Body).Decode(&dry)
+	if dry.Count != 2 {
+		t.Fatalf("expected 2 matches, got %+v", dry)
+	}
+
+	for name, body := range map[string]string{
+		"missing decided_by": `{"decision":"denied",` + filter + `,"confirm_count":2}`,
+		"ids and filter":     `{"decision":"denied","decided_by":"oncall","ids":["x"],` + filter + `,"confirm_count":1}`,
+		"bad decision":       `{"decision":"maybe","decided_by":"oncall",` + filter + `,"confirm_count":2}`,
+	} {
+		if rr := decide(body); rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", name, rr.Code)
+		}
+	}
+	for _, body := range []string{
+		`{"decision":"denied","decided_by":"oncall",` + filter + `}`,
+		`{"decision":"denied","decided_by":"oncall",` + filter + `,"confirm_count":3}`,
+	} {
+		if rr := decide(body); rr.Code != http.StatusConflict {
+			t.Fatalf("expected 409 without matching confirm_count, got %d", rr.Code)
+		}
+	}
+	if req, _ := srv.approvalQueue.Get(restart1); req.Decision != approval.DecisionPending {
+		t.Fatal("rejected bulk request must not decide anything")
+	}
+
+	rr = decide(`{"decision":"denied","decided_by":"oncall",` + filter + `,"confirm_count":2}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("bulk decide status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	var got struct {
+		Succeeded int                  `json:"succeeded"`
+		Results   []bulkApprovalResult `json:"results"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Succeeded != 2 || len(got.Results) != 2 {
+		t.Fatalf("unexpected bulk result %+v", got)
+	}
+	for _, id := range []string{restart1, restart2} {
+		if req, _ := srv.approvalQueue.Get(id); req.Decision != approval.DecisionDenied {
+			t.Fatalf("expected %s denied, got %s", id, req.Decision)
+		}
+	}
+	if req, _ := srv.approvalQueue.Get(other); req.Decision != approval.DecisionPending {
+		t.Fatalf("untagged probe's approval should stay pending, got %s", req.Decision)
+	}
+
+	decidedAudits := 0
+	for _, evt := range srv.queryAudit(audit.Filter{ProbeID: "probe-web", Limit: 20}) {
+		if evt.Type == audit.EventApprovalDecided {
+			decidedAudits++
+		}
+	}
+	if decidedAudits != 2 {
+		t.Fatalf("expected one audit event per approval, got %d", decidedAudits)
+	}
+
+	// Explicit IDs report per-ID failures without aborting the batch.
+	rr = decide(`{"decision":"denied","decided_by":"oncall","ids":["` + other + `","missing","` + restart1 + `"],"confirm_count":3}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("ids decide status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	got.Results = nil
+	_ = json.NewDecoder(rr.Body).Decode(&got)
+	if got.Succeeded != 1 || len(got.Results) != 3 {
+		t.Fatalf("unexpected ids result %+v", got)
+	}
+	if got.Results[0].Status != string(approval.DecisionDenied) || got.Results[1].Code != "not_found" || got.Results[2].Status != "error" {
+		t.Fatalf("unexpected per-id results %+v", got.Results)
+	}
+}
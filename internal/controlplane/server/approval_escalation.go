@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/alerts"
+	"github.com/marcus-qen/legator/internal/controlplane/approval"
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/events"
+	"go.uber.org/zap"
+)
+
+// escalateApproval re-notifies an approval that has been pending for the
+// configured share of its TTL: it publishes approval.escalated to the event
+// bus (and so to subscribed webhooks), records it in the audit trail and, if
+// configured, sends it to the escalation notification channel.
+func (s *Server) escalateApproval(req approval.Request) {
+	command := approval.CommandLine(req.Command)
+	remaining := req.ExpiresAt.Sub(req.EscalatedAt).Round(time.Second)
+	summary := fmt.Sprintf("Approval %s still pending for %s (risk: %s), expires in %s", req.ID, command, req.RiskLevel, remaining)
+	detail := map[string]any{
+		"approval_id":  req.ID,
+		"workspace_id": req.WorkspaceID,
+		"command":      command,
+		"risk_level":   req.RiskLevel,
+		"requester":    req.Requester,
+		"created_at":   req.CreatedAt,
+		"escalated_at": req.EscalatedAt,
+		"expires_at":   req.ExpiresAt,
+	}
+
+	s.publishEvent(events.ApprovalEscalated, req.ProbeID, summary, detail)
+	s.recordAudit(audit.Event{
+		Type:        audit.EventApprovalEscalated,
+		ProbeID:     req.ProbeID,
+		WorkspaceID: req.WorkspaceID,
+		Actor:       approval.DecisionSourceSystem,
+		Summary:     summary,
+		Detail:      detail,
+	})
+
+	channelID := strings.TrimSpace(s.cfg.Approval.EscalationChannel)
+	if channelID == "" || s.alertEngine == nil {
+		return
+	}
+	severity := alerts.SeverityWarning
+	if req.RiskLevel == "high" || req.RiskLevel == "critical" {
+		severity = alerts.SeverityCritical
+	}
+	// Sent off the reaper goroutine so a slow channel cannot hold up expiry.
+	go func() {
+		if err := s.alertEngine.NotifyChannel(channelID, string(events.ApprovalEscalated), req.ProbeID, "[ESCALATED] "+summary, severity, detail); err != nil {
+			s.logger.Warn("approval escalation notification failed",
+				zap.String("approval_id", req.ID), zap.String("channel_id", channelID), zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/alerts"
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/config"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestApprovalEscalationAuditsAndNotifiesChannel(t *testing.T) {
+	received := make(chan string, 1)
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- string(body)
+	}))
+	defer slack.Close()
+
+	srv := newTestServerWithDataDir(t, t.TempDir(), func(cfg *config.Config) {
+		cfg.Approval.EscalateAfter = 0.5
+	})
+	if srv.alertStore == nil {
+		t.Skip("alerts store unavailable")
+	}
+	channel, err := srv.alertStore.CreateChannel(alerts.NotificationChannel{
+		Name:    "oncall",
+		Type:    alerts.ChannelTypeSlack,
+		Enabled: true,
+		Slack:   &alerts.SlackChannelConfig{WebhookURL: slack.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.cfg.Approval.EscalationChannel = channel.ID
+
+	req, err := srv.approvalQueue.Submit("probe-esc", &protocol.CommandPayload{Command: "systemctl", Args: []string{"restart", "db"}, WorkDir: "/srv/db"}, "deploy", "high", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	escalated := srv.approvalQueue.EscalateDue(req.CreatedAt.Add(10 * time.Minute))
+	if len(escalated) != 1 {
+		t.Fatalf("expected one escalation, got %d", len(escalated))
+	}
+
+	select {
+	case body := <-received:
+		if !strings.Contains(body, "[ESCALATED]") || !strings.Contains(body, req.ID) {
+			t.Fatalf("unexpected escalation notification %q", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected escalation notification on the configured channel")
+	}
+
+	evts := srv.queryAudit(audit.Filter{ProbeID: "probe-esc", Type: audit.EventApprovalEscalated, Limit: 10})
+	if len(evts) != 1 || !strings.Contains(evts[0].Summary, "cd /srv/db && systemctl restart db") {
+		t.Fatalf("expected approval.escalated audit event, got %+v", evts)
+	}
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/marcus-qen/legator/internal/controlplane/approval"
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	coreapprovalpolicy "github.com/marcus-qen/legator/internal/controlplane/core/approvalpolicy"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// ── Auto-approve rules ───────────────────────────────────────
+
+func (s *Server) handleListApprovalRules(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermApprovalRead) {
+		return
+	}
+	rules := s.approvalRules.List()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"rules": rules,
+		"total": len(rules),
+	})
+}
+
+func (s *Server) handleCreateApprovalRule(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermAdmin) {
+		return
+	}
+	var body struct {
+		Name            string `json:"name"`
+		Actor           string `json:"actor"`
+		ProbeTag        string `json:"probe_tag"`
+		CommandGlob     string `json:"command_glob"`
+		AllowCritical   bool   `json:"allow_critical"`
+		AllowEnvWorkDir bool   `json:"allow_env_work_dir"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+		return
+	}
+
+	actor := actorFromAuthContext(r.Context())
+	rule, err := s.approvalRules.Add(approval.AutoApproveRule{
+		Name:            body.Name,
+		Actor:           body.Actor,
+		ProbeTag:        body.ProbeTag,
+		CommandGlob:     body.CommandGlob,
+		AllowCritical:   body.AllowCritical,
+		AllowEnvWorkDir: body.AllowEnvWorkDir,
+		CreatedBy:       actor,
+	})
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	s.recordAudit(audit.Event{
+		Type:    audit.EventApprovalRuleChanged,
+		Actor:   actor,
+		Summary: fmt.Sprintf("Auto-approve rule created: %s", rule.Name),
+		Detail: map[string]any{
+			"action":             "created",
+			"rule_id":            rule.ID,
+			"actor":              rule.Actor,
+			"probe_tag":          rule.ProbeTag,
+			"command_glob":       rule.CommandGlob,
+			"allow_critical":     rule.AllowCritical,
+			"allow_env_work_dir": rule.AllowEnvWorkDir,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(rule)
+}
+
+func (s *Server) handleDeleteApprovalRule(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermAdmin) {
+		return
+	}
+	id := r.PathValue("id")
+	rule, ok := s.approvalRules.Get(id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "auto-approve rule not found")
+		return
+	}
+	if err := s.approvalRules.Delete(id); err != nil {
+		writeJSONError(w, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+
+	s.recordAudit(audit.Event{
+		Type:    audit.EventApprovalRuleChanged,
+		Actor:   actorFromAuthContext(r.Context()),
+		Summary: fmt.Sprintf("Auto-approve rule deleted: %s", rule.Name),
+		Detail: map[string]any{
+			"action":  "deleted",
+			"rule_id": rule.ID,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// recordAutoApproval is the approval-core hook fired when a rule lets a
+// command skip the approval queue.
+func (s *Server) recordAutoApproval(probeID, actor string, cmd *protocol.CommandPayload, decision coreapprovalpolicy.CommandPolicyDecision, rule *approval.AutoApproveRule) {
+	commandText := approval.CommandLine(cmd)
+	s.recordAudit(audit.Event{
+		Type:    audit.EventApprovalAutoApproved,
+		ProbeID: probeID,
+		Actor:   actor,
+		Summary: fmt.Sprintf("Command auto-approved by rule %s: %s", rule.Name, commandText),
+		Detail: map[string]any{
+			"rule_id":      rule.ID,
+			"rule_name":    rule.Name,
+			"request_id":   cmd.RequestID,
+			"command":      commandText,
+			"risk_level":   decision.RiskLevel,
+			"command_glob": rule.CommandGlob,
+		},
+	})
+}
+
+// ── Risk classification rules ───────────────────────────────
+
+func (s *Server) handleListRiskRules(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermApprovalRead) {
+		return
+	}
+	rules := s.riskRules.ListRiskRules()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"rules": rules,
+		"total": len(rules),
+	})
+}
+
+func (s *Server) handleCreateRiskRule(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermAdmin) {
+		return
+	}
+	var body struct {
+		Name        string `json:"name"`
+		CommandGlob string `json:"command_glob"`
+		RiskLevel   string `json:"risk_level"`
+		Priority    int    `json:"priority"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+		return
+	}
+
+	actor := actorFromAuthContext(r.Context())
+	rule, err := s.riskRules.AddRiskRule(approval.RiskRule{
+		Name:        body.Name,
+		CommandGlob: body.CommandGlob,
+		RiskLevel:   body.RiskLevel,
+		Priority:    body.Priority,
+		CreatedBy:   actor,
+	})
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	s.recordAudit(audit.Event{
+		Type:    audit.EventRiskRuleChanged,
+		Actor:   actor,
+		Summary: fmt.Sprintf("Risk rule created: %s (%s)", rule.Name, rule.RiskLevel),
+		Detail: map[string]any{
+			"action":       "created",
+			"rule_id":      rule.ID,
+			"command_glob": rule.CommandGlob,
+			"risk_level":   rule.RiskLevel,
+			"priority":     rule.Priority,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(rule)
+}
+
+func (s *Server) handleDeleteRiskRule(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermAdmin) {
+		return
+	}
+	id := r.PathValue("id")
+	rule, ok := s.riskRules.GetRiskRule(id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "risk rule not found")
+		return
+	}
+	if err := s.riskRules.DeleteRiskRule(id); err != nil {
+		writeJSONError(w, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+
+	s.recordAudit(audit.Event{
+		Type:    audit.EventRiskRuleChanged,
+		Actor:   actorFromAuthContext(r.Context()),
+		Summary: fmt.Sprintf("Risk rule deleted: %s", rule.Name),
+		Detail: map[string]any{
+			"action":  "deleted",
+			"rule_id": rule.ID,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
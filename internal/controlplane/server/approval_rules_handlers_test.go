@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+)
+
+func TestRiskRuleClassifiesSimulatedCommand(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-risk", "host", "linux", "amd64")
+	cmdBody := `{"command":"bash","args":["/opt/ops/wipe-cache.sh"],"level":"observe"}`
+
+	simulate := func() map[string]any {
+		t.Helper()
+		resp := makeRequest(t, srv, http.MethodPost, "/api/v1/probes/probe-risk/command/simulate", "", cmdBody)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("simulate: expected 200, got %d body=%s", resp.Code, resp.Body.String())
+		}
+		var payload struct {
+			Decision map[string]any `json:"decision"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("decode simulation: %v", err)
+		}
+		return payload.Decision
+	}
+
+	if decision := simulate(); decision["risk_level"] != "low" || decision["gate_outcome"] != "allowed" {
+		t.Fatalf("expected low/allowed before rules, got %v/%v", decision["risk_level"], decision["gate_outcome"])
+	}
+
+	if resp := makeRequest(t, srv, http.MethodPost, "/api/v1/risk-rules", "", `{"command_glob":"bash *","risk_level":"extreme"}`); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown risk level, got %d", resp.Code)
+	}
+	resp := makeRequest(t, srv, http.MethodPost, "/api/v1/risk-rules", "", `{"name":"cache wipe","command_glob":"bash */wipe-cache*","risk_level":"critical"}`)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("create rule: expected 201, got %d body=%s", resp.Code, resp.Body.String())
+	}
+	var rule struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(resp.Body.Bytes(), &rule)
+
+	decision := simulate()
+	if decision["risk_level"] != "critical" || decision["gate_outcome"] != "pending_approval" {
+		t.Fatalf("expected critical/pending_approval from rule, got %v/%v", decision["risk_level"], decision["gate_outcome"])
+	}
+	if rationale, _ := json.Marshal(decision["rationale"]); !strings.Contains(string(rationale), rule.ID) {
+		t.Fatalf("expected rule %s in rationale, got %s", rule.ID, rationale)
+	}
+
+	if resp := makeRequest(t, srv, http.MethodDelete, "/api/v1/risk-rules/"+rule.ID, "", ""); resp.Code != http.StatusOK {
+		t.Fatalf("delete rule: expected 200, got %d", resp.Code)
+	}
+	if decision := simulate(); decision["risk_level"] != "low" {
+		t.Fatalf("expected heuristic risk after delete, got %v", decision["risk_level"])
+	}
+	if evts := srv.queryAudit(audit.Filter{Type: audit.EventRiskRuleChanged, Limit: 10}); len(evts) != 2 {
+		t.Fatalf("expected 2 risk rule audit events, got %d", len(evts))
+	}
+}
@@ -69,8 +69,13 @@ func (s *Server) handleApproveAsyncJob(w http.ResponseWriter, r *http.Request) {
 		decidedBy = "api"
 	}
 
-	approvalReq, syncErr := s.syncApprovalDecision(current.ApprovalID, approval.DecisionApproved, decidedBy)
+	details := approval.DecisionDetails{Reason: strings.TrimSpace(req.Reason), Source: approval.DecisionSourceAPI}
+	approvalReq, syncErr := s.syncApprovalDecision(current.ApprovalID, approval.DecisionApproved, decidedBy, details)
 	if syncErr != nil {
+		if errors.Is(syncErr, approval.ErrDecisionReasonRequired) {
+			writeJSONError(w, http.StatusBadRequest, "reason_required", syncErr.Error())
+			return
+		}
 		if strings.Contains(syncErr.Error(), "already approved request") {
 			writeJSONError(w, http.StatusConflict, "duplicate_approver", syncErr.Error())
 			return
@@ -238,6 +243,11 @@ func (s *Server) handleRejectAsyncJob(w http.ResponseWriter, r *http.Request) {
 		decidedBy = "api"
 	}
 	reason := strings.TrimSpace(req.Reason)
+	if reason == "" && s.approvalQueue != nil && s.approvalQueue.ReasonRequired(current.ApprovalID, approval.DecisionDenied) {
+		writeJSONError(w, http.StatusBadRequest, "reason_required", "decision reason is required to reject this approval")
+		return
+	}
+	details := approval.DecisionDetails{Reason: reason, Source: approval.DecisionSourceAPI}
 	if reason == "" {
 		reason = "rejected by " + decidedBy
 	}
@@ -257,7 +267,7 @@ func (s *Server) handleRejectAsyncJob(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	_, _ = s.syncApprovalDecision(job.ApprovalID, approval.DecisionDenied, decidedBy)
+	_, _ = s.syncApprovalDecision(job.ApprovalID, approval.DecisionDenied, decidedBy, details)
 
 	s.appendCommandStreamMarker(job.RequestID, cmdtracker.StreamEventApproval, "job_rejected", map[string]any{
 		"job_id":       job.ID,
@@ -331,7 +341,10 @@ func (s *Server) processTimedOutApprovalJobs(now time.Time) (int, error) {
 					s.logger.Sugar().Warnf("reads_only timeout auto-approve failed: job=%s err=%v", job.ID, approveErr)
 					continue
 				}
-				_, _ = s.syncApprovalDecision(job.ApprovalID, approval.DecisionApproved, "system")
+				_, _ = s.syncApprovalDecision(job.ApprovalID, approval.DecisionApproved, "system", approval.DecisionDetails{
+					Reason: "reads_only auto-approved on timeout",
+					Source: approval.DecisionSourceSystem,
+				})
 				s.recordApprovalTimeoutAudit(*resumed, behavior, "auto_approved", "reads_only auto-approved on timeout", nil)
 				s.appendCommandStreamMarker(resumed.RequestID, cmdtracker.StreamEventApproval, "approval_timeout_reads_only_resumed", map[string]any{
 					"job_id":      resumed.ID,
@@ -353,7 +366,7 @@ func (s *Server) processTimedOutApprovalJobs(now time.Time) (int, error) {
 				s.logger.Sugar().Warnf("cancel timed-out job (reads_only) failed: job=%s err=%v", job.ID, cancelErr)
 				continue
 			}
-			_, _ = s.syncApprovalDecision(job.ApprovalID, approval.DecisionDenied, "system")
+			_, _ = s.syncApprovalDecision(job.ApprovalID, approval.DecisionDenied, "system", approval.DecisionDetails{Reason: reason, Source: approval.DecisionSourceSystem})
 			s.recordApprovalTimeoutAudit(job, behavior, "cancelled", reason, nil)
 			s.appendCommandStreamMarker(job.RequestID, cmdtracker.StreamEventApproval, "approval_timeout_reads_only_cancelled", map[string]any{
 				"job_id":      job.ID,
@@ -367,7 +380,7 @@ func (s *Server) processTimedOutApprovalJobs(now time.Time) (int, error) {
 				s.logger.Sugar().Warnf("cancel timed-out job failed: job=%s err=%v", job.ID, cancelErr)
 				continue
 			}
-			_, _ = s.syncApprovalDecision(job.ApprovalID, approval.DecisionDenied, "system")
+			_, _ = s.syncApprovalDecision(job.ApprovalID, approval.DecisionDenied, "system", approval.DecisionDetails{Reason: reason, Source: approval.DecisionSourceSystem})
 			s.recordApprovalTimeoutAudit(job, behavior, "cancelled", reason, nil)
 			s.appendCommandStreamMarker(job.RequestID, cmdtracker.StreamEventApproval, "approval_timeout_cancelled", map[string]any{
 				"job_id":      job.ID,
@@ -419,7 +432,7 @@ func (s *Server) recordApprovalTimeoutAudit(job jobs.AsyncJob, behavior, action,
 	})
 }
 
-func (s *Server) syncApprovalDecision(approvalID string, decision approval.Decision, decidedBy string) (*approval.Request, error) {
+func (s *Server) syncApprovalDecision(approvalID string, decision approval.Decision, decidedBy string, details approval.DecisionDetails) (*approval.Request, error) {
 	approvalID = strings.TrimSpace(approvalID)
 	decidedBy = strings.TrimSpace(decidedBy)
 	if s == nil || s.approvalQueue == nil || approvalID == "" {
@@ -431,7 +444,7 @@ func (s *Server) syncApprovalDecision(approvalID string, decision approval.Decis
 	if _, ok := s.approvalQueue.Get(approvalID); !ok {
 		return nil, nil
 	}
-	req, err := s.approvalQueue.Decide(approvalID, decision, decidedBy)
+	req, err := s.approvalQueue.DecideWithDetails(approvalID, decision, decidedBy, details)
 	if err != nil {
 		if strings.Contains(err.Error(), "already decided") || strings.Contains(err.Error(), "expired at") || strings.Contains(err.Error(), "not found") {
 			return nil, err
@@ -0,0 +1,311 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcus-qen/legator/internal/controlplane/approval"
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/controlplane/cmdtracker"
+	coreapprovalpolicy "github.com/marcus-qen/legator/internal/controlplane/core/approvalpolicy"
+	corecommanddispatch "github.com/marcus-qen/legator/internal/controlplane/core/commanddispatch"
+	"github.com/marcus-qen/legator/internal/controlplane/events"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	controlpolicy "github.com/marcus-qen/legator/internal/controlplane/policy"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+const (
+	maxBatchCommands = 20
+	// maxBatchWait bounds a whole batch; steps not started by then are
+	// reported not_run.
+	maxBatchWait = 15 * time.Minute
+)
+
+// commandBatchStep is one command's entry in a batch response.
+type commandBatchStep struct {
+	Index      int    `json:"index"`
+	Command    string `json:"command"`
+	RequestID  string `json:"request_id"`
+	Status     string `json:"status"` // success, failed, timeout, error, pending_approval, not_run; denied, approval_required, blocked, allowed when rejected
+	ReasonCode string `json:"reason_code,omitempty"`
+	ApprovalID string `json:"approval_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	Stdout     string `json:"stdout,omitempty"`
+	Stderr     string `json:"stderr,omitempty"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+}
+
+// handleCommandBatch runs an ordered list of commands on one probe, each
+// waiting for the previous one's result. Every command is checked against
+// policy before any is dispatched: if one would be denied or needs
+// breakglass, nothing runs. A command that needs approval is queued for
+// approval when its turn comes and, like a failed step, stops the batch
+// unless stop_on_error is false.
+func (s *Server) handleCommandBatch(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermCommandExec) {
+		return
+	}
+	id := r.PathValue("id")
+	ps, ok := s.probeForRequest(r, id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	if ps.Draining {
+		writeJSONError(w, http.StatusConflict, "probe_draining", "probe is draining; undrain it to dispatch commands")
+		return
+	}
+
+	var body struct {
+		Commands    []protocol.CommandPayload `json:"commands"`
+		StopOnError *bool                     `json:"stop_on_error,omitempty"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 256<<10)).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+		return
+	}
+	if len(body.Commands) == 0 || len(body.Commands) > maxBatchCommands {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("commands must list 1 to %d commands", maxBatchCommands))
+		return
+	}
+	stopOnError := body.StopOnError == nil || *body.StopOnError
+	remote := strings.EqualFold(ps.Type, fleet.ProbeTypeRemote)
+
+	steps := make([]commandBatchStep, len(body.Commands))
+	cmds := make([]protocol.CommandPayload, len(body.Commands))
+	for i, cmd := range body.Commands {
+		cmd.Command = strings.TrimSpace(cmd.Command)
+		if cmd.Command == "" {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("commands[%d]: command is required", i))
+			return
+		}
+		if cmd.MaxOutputBytes < 0 || cmd.MaxOutputBytes > protocol.MaxCommandOutputBytes {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("commands[%d]: max_output_bytes must be between 0 and %d", i, protocol.MaxCommandOutputBytes))
+			return
+		}
+		if cmd.WorkDir != "" || len(cmd.Env) > 0 {
+			if remote {
+				writeJSONError(w, http.StatusConflict, "unsupported_probe", "work_dir and env require an agent probe")
+				return
+			}
+			if err := protocol.ValidateCommandEnv(cmd.Env); err != nil {
+				writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("commands[%d]: %v", i, err))
+				return
+			}
+		}
+		cmd.RequestID = corecommanddispatch.NextCommandRequestID()
+		cmd.Stream = false
+		cmds[i] = cmd
+		steps[i] = commandBatchStep{Index: i, Command: fullCommandLine(cmd), RequestID: cmd.RequestID}
+	}
+
+	actor := actorFromAuthContext(r.Context())
+	decisions, allowed := s.preflightCommandBatch(r.Context(), id, actor, ps.PolicyLevel, cmds, steps)
+	if !allowed {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status":   "rejected",
+			"probe_id": id,
+			"steps":    steps,
+			"message":  "Batch not run: a command is denied by policy or needs breakglass. Dispatch commands that need breakglass individually.",
+		})
+		return
+	}
+
+	batchID := "batch-" + uuid.NewString()[:8]
+	requestIDs := make([]string, len(steps))
+	commands := make([]string, len(steps))
+	for i := range steps {
+		requestIDs[i] = steps[i].RequestID
+		commands[i] = steps[i].Command
+	}
+	s.recordAudit(audit.Event{
+		Type:    audit.EventCommandSent,
+		ProbeID: id,
+		Actor:   actor,
+		Summary: fmt.Sprintf("Command batch %s: %d commands (stop_on_error=%t)", batchID, len(steps), stopOnError),
+		Detail: map[string]any{
+			"batch_id":      batchID,
+			"request_ids":   requestIDs,
+			"commands":      commands,
+			"stop_on_error": stopOnError,
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(r.Context(), maxBatchWait)
+	defer cancel()
+	status := runCommandBatch(ctx, cmds, steps, stopOnError, func(ctx context.Context, cmd protocol.CommandPayload, step *commandBatchStep) *corecommanddispatch.CommandResultEnvelope {
+		if decision := decisions[step.Index]; decision.Outcome == coreapprovalpolicy.CommandPolicyDecisionQueue {
+			requireSecondApprover := s.cfg.Approval.TwoPersonMode && decision.RiskTier >= 3 && decision.Policy.RequireSecondApprover
+			req, err := s.approvalQueue.SubmitWithWorkspaceAndOptions(
+				s.workspaceJobFilter(r),
+				id,
+				&cmd,
+				fmt.Sprintf("Command batch %s, step %d", batchID, step.Index+1),
+				decision.RiskLevel,
+				actor,
+				string(decision.Outcome),
+				decision.Rationale,
+				approval.SubmissionOptions{RequireSecondApprover: requireSecondApprover},
+			)
+			if err != nil {
+				return &corecommanddispatch.CommandResultEnvelope{State: corecommanddispatch.ResultStateDispatchError, Err: fmt.Errorf("approval queue: %w", err)}
+			}
+			step.Status = "pending_approval"
+			step.ApprovalID = req.ID
+			s.appendCommandStreamMarker(cmd.RequestID, cmdtracker.StreamEventApproval, "pending_approval", map[string]any{
+				"approval_id": req.ID,
+				"risk_level":  req.RiskLevel,
+				"expires_at":  req.ExpiresAt,
+				"lane":        decision.Lane,
+				"batch_id":    batchID,
+			})
+			s.emitAudit(audit.EventApprovalRequest, id, actor,
+				fmt.Sprintf("Approval required for batch %s step %d: %s (risk: %s, lane: %s)", batchID, step.Index+1, approval.CommandLine(&cmd), req.RiskLevel, decision.Lane))
+			return nil
+		}
+
+		var env *corecommanddispatch.CommandResultEnvelope
+		if remote {
+			if projection := s.invokeRemoteCommand(ctx, ps, cmd, true, false); projection != nil {
+				env = projection.Envelope
+			}
+		} else {
+			env = s.dispatchCore.DispatchWithPolicy(ctx, id, cmd, corecommanddispatch.WaitPolicy(groupCommandWait(cmd.Timeout)))
+		}
+		if env != nil && env.Dispatched {
+			s.emitAudit(audit.EventCommandSent, id, actor, fmt.Sprintf("Command dispatched (batch %s): %s", batchID, cmd.Command))
+			s.publishEvent(events.CommandDispatched, id, fmt.Sprintf("Command dispatched: %s", cmd.Command),
+				map[string]string{"request_id": cmd.RequestID, "command": cmd.Command, "batch_id": batchID})
+			s.appendCommandStreamMarker(cmd.RequestID, cmdtracker.StreamEventDispatch, "command_dispatched", map[string]any{
+				"probe_id": id,
+				"command":  cmd.Command,
+				"batch_id": batchID,
+			})
+		}
+		return env
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"batch_id":      batchID,
+		"probe_id":      id,
+		"status":        status,
+		"stop_on_error": stopOnError,
+		"steps":         steps,
+	})
+}
+
+// preflightCommandBatch evaluates policy for every command, recording each
+// decision in steps. It returns the decisions and reports whether the batch
+// may start: none denied and none needing breakglass.
+func (s *Server) preflightCommandBatch(ctx context.Context, probeID, actor string, level protocol.CapabilityLevel, cmds []protocol.CommandPayload, steps []commandBatchStep) ([]coreapprovalpolicy.CommandPolicyDecision, bool) {
+	decisions := make([]coreapprovalpolicy.CommandPolicyDecision, len(cmds))
+	allowed := true
+	for i := range cmds {
+		decision := s.approvalCore.EvaluateCommandPolicyForProbe(ctx, probeID, &cmds[i], level)
+		s.approvalCore.ApplyAutoApproveRules(probeID, actor, &cmds[i], &decision)
+		decisions[i] = decision
+		steps[i].ReasonCode = decision.ReasonCode
+		switch {
+		case decision.Outcome == coreapprovalpolicy.CommandPolicyDecisionDeny:
+			steps[i].Status = "denied"
+			s.recordAudit(audit.Event{
+				Type:    audit.EventAuthorizationDenied,
+				ProbeID: probeID,
+				Actor:   actor,
+				Summary: fmt.Sprintf("Batch command denied by policy: %s (%s)", cmds[i].Command, decision.ReasonCode),
+				Detail: map[string]any{
+					"request_id":  cmds[i].RequestID,
+					"command":     cmds[i].Command,
+					"reason_code": decision.ReasonCode,
+					"denial":      decision.Rationale.Denial,
+				},
+			})
+		case s.cfg.SandboxEnforcement && controlpolicy.RequiresBreakglassConfirmation(decision.Classification.Category, decision.Lane):
+			steps[i].Status = "blocked"
+			steps[i].ReasonCode = "sandbox_enforcement.breakglass_required"
+		case decision.Outcome == coreapprovalpolicy.CommandPolicyDecisionQueue:
+			steps[i].Status = "approval_required"
+			continue
+		default:
+			steps[i].Status = "allowed"
+			continue
+		}
+		allowed = false
+	}
+	return decisions, allowed
+}
+
+// runCommandBatch runs cmds in order through run, filling in steps, and
+// returns the batch status: succeeded, failed (every step ran but some
+// failed or wait for approval) or stopped. run marks a step it queued for
+// approval as pending_approval and returns nil.
+func runCommandBatch(ctx context.Context, cmds []protocol.CommandPayload, steps []commandBatchStep, stopOnError bool, run func(context.Context, protocol.CommandPayload, *commandBatchStep) *corecommanddispatch.CommandResultEnvelope) string {
+	status := "succeeded"
+	for i, cmd := range cmds {
+		step := &steps[i]
+		if status == "stopped" || ctx.Err() != nil {
+			step.Status = "not_run"
+			if status != "stopped" {
+				step.Error = "batch deadline exceeded"
+				status = "stopped"
+			}
+			continue
+		}
+
+		env := run(ctx, cmd, step)
+		switch {
+		case step.Status == "pending_approval":
+		case env == nil:
+			step.Status = "error"
+			step.Error = "command dispatch failed"
+		case env.Result != nil:
+			exitCode := env.Result.ExitCode
+			step.ExitCode = &exitCode
+			step.DurationMS = env.Result.Duration
+			step.Stdout, step.Truncated = truncateGroupOutput(env.Result.Stdout, env.Result.Truncated)
+			step.Stderr, step.Truncated = truncateGroupOutput(env.Result.Stderr, step.Truncated)
+			step.Status = "success"
+			if exitCode != 0 {
+				step.Status = "failed"
+			}
+		case env.State == corecommanddispatch.ResultStateTimeout:
+			step.Status = "timeout"
+			step.Error = "no result before wait deadline"
+		default:
+			step.Status = "error"
+			step.Error = "command dispatch failed"
+			if env.Err != nil {
+				step.Error = env.Err.Error()
+			}
+		}
+
+		if step.Status != "success" {
+			if stopOnError {
+				status = "stopped"
+			} else {
+				status = "failed"
+			}
+		}
+	}
+	return status
+}
+
+// fullCommandLine joins a command and its arguments for display.
+func fullCommandLine(cmd protocol.CommandPayload) string {
+	if len(cmd.Args) == 0 {
+		return cmd.Command
+	}
+	return cmd.Command + " " + strings.Join(cmd.Args, " ")
+}
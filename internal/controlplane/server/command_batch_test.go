@@ -0,0 +1,163 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	corecommanddispatch "github.com/marcus-qen/legator/internal/controlplane/core/commanddispatch"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+type commandBatchResponse struct {
+	Status string             `json:"status"`
+	Steps  []commandBatchStep `json:"steps"`
+}
+
+func postCommandBatch(t *testing.T, srv *Server, id, body string) (*httptest.ResponseRecorder, commandBatchResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/probes/"+id+"/batch", bytes.NewBufferString(body))
+	req.SetPathValue("id", id)
+	rr := httptest.NewRecorder()
+	srv.handleCommandBatch(rr, req)
+	var resp commandBatchResponse
+	_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+	return rr, resp
+}
+
+func TestHandleCommandBatch_Validation(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-batch", "host", "linux", "amd64")
+
+	if rr, _ := postCommandBatch(t, srv, "probe-missing", `{"commands":[{"command":"uptime"}]}`); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown probe, got %d", rr.Code)
+	}
+	for _, body := range []string{
+		`{"commands":[]}`,
+		`{"commands":[{"command":"uptime"},{"command":"  "}]}`,
+		`{"commands":[{"command":"uptime","max_output_bytes":-1}]}`,
+		`not json`,
+	} {
+		if rr, _ := postCommandBatch(t, srv, "probe-batch", body); rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d: %s", body, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestHandleCommandBatch_QueuesStepNeedingApproval(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-batch", "host", "linux", "amd64")
+
+	rr, resp := postCommandBatch(t, srv, "probe-batch", `{"commands":[{"command":"systemctl","args":["restart","nginx"]},{"command":"uptime"}]}`)
+	if rr.Code != http.StatusOK || resp.Status != "stopped" {
+		t.Fatalf("expected 200 stopped, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(resp.Steps) != 2 || resp.Steps[0].Status != "pending_approval" || resp.Steps[1].Status != "not_run" {
+		t.Fatalf("unexpected steps %+v", resp.Steps)
+	}
+	pending := srv.approvalQueue.Pending()
+	if len(pending) != 1 || pending[0].ID != resp.Steps[0].ApprovalID || pending[0].Command.RequestID != resp.Steps[0].RequestID {
+		t.Fatalf("expected the step's command to be queued for approval, got %+v", pending)
+	}
+	if requested := srv.queryAudit(audit.Filter{Type: audit.EventApprovalRequest}); len(requested) != 1 {
+		t.Fatalf("expected one approval request audit event, got %d", len(requested))
+	}
+
+	// Without stop_on_error the batch carries on past the queued step.
+	rr, resp = postCommandBatch(t, srv, "probe-batch", `{"stop_on_error":false,"commands":[{"command":"systemctl","args":["restart","nginx"]},{"command":"uptime"}]}`)
+	if rr.Code != http.StatusOK || resp.Status != "failed" {
+		t.Fatalf("expected 200 failed, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if resp.Steps[0].Status != "pending_approval" || resp.Steps[1].Status != "error" {
+		t.Fatalf("unexpected steps %+v", resp.Steps)
+	}
+	if pending := srv.approvalQueue.Pending(); len(pending) != 2 {
+		t.Fatalf("expected two pending approvals, got %d", len(pending))
+	}
+}
+
+func TestHandleCommandBatch_StopsOnDispatchError(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-batch", "host", "linux", "amd64")
+
+	// The probe has no connection, so the first dispatch fails.
+	rr, resp := postCommandBatch(t, srv, "probe-batch", `{"commands":[{"command":"uptime"},{"command":"hostname"}]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if resp.Status != "stopped" || len(resp.Steps) != 2 {
+		t.Fatalf("unexpected batch %+v", resp)
+	}
+	if resp.Steps[0].Status != "error" || resp.Steps[1].Status != "not_run" {
+		t.Fatalf("unexpected steps %+v", resp.Steps)
+	}
+}
+
+func TestRunCommandBatch(t *testing.T) {
+	result := func(exitCode int) *corecommanddispatch.CommandResultEnvelope {
+		return &corecommanddispatch.CommandResultEnvelope{
+			State:      corecommanddispatch.ResultStateCompleted,
+			Dispatched: true,
+			Result:     &protocol.CommandResultPayload{ExitCode: exitCode, Stdout: "ok"},
+		}
+	}
+	outcomes := map[string]*corecommanddispatch.CommandResultEnvelope{
+		"a":    result(0),
+		"fail": result(2),
+		"lost": {State: corecommanddispatch.ResultStateDispatchError, Err: errors.New("probe not connected")},
+		"slow": {State: corecommanddispatch.ResultStateTimeout, Dispatched: true},
+	}
+	cmds := []protocol.CommandPayload{{Command: "a"}, {Command: "fail"}, {Command: "lost"}, {Command: "slow"}, {Command: "gated"}, {Command: "a"}}
+
+	tests := []struct {
+		name        string
+		stopOnError bool
+		wantStatus  string
+		wantSteps   []string
+		wantRuns    int
+	}{
+		{"stop on error", true, "stopped", []string{"success", "failed", "not_run", "not_run", "not_run", "not_run"}, 2},
+		{"continue on error", false, "failed", []string{"success", "failed", "error", "timeout", "pending_approval", "success"}, 6},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			steps := make([]commandBatchStep, len(cmds))
+			runs := 0
+			status := runCommandBatch(context.Background(), cmds, steps, tc.stopOnError, func(_ context.Context, cmd protocol.CommandPayload, step *commandBatchStep) *corecommanddispatch.CommandResultEnvelope {
+				runs++
+				if cmd.Command == "gated" {
+					step.Status = "pending_approval"
+					return nil
+				}
+				return outcomes[cmd.Command]
+			})
+			if status != tc.wantStatus || runs != tc.wantRuns {
+				t.Fatalf("status=%q runs=%d, want %q and %d", status, runs, tc.wantStatus, tc.wantRuns)
+			}
+			for i, want := range tc.wantSteps {
+				if steps[i].Status != want {
+					t.Fatalf("step %d: status %q, want %q", i, steps[i].Status, want)
+				}
+			}
+			if steps[1].ExitCode == nil || *steps[1].ExitCode != 2 {
+				t.Fatalf("expected exit code 2 on failed step, got %+v", steps[1])
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	steps := make([]commandBatchStep, 2)
+	status := runCommandBatch(ctx, cmds[:2], steps, false, func(context.Context, protocol.CommandPayload, *commandBatchStep) *corecommanddispatch.CommandResultEnvelope {
+		t.Fatal("no step should run after the deadline")
+		return nil
+	})
+	if status != "stopped" || steps[0].Status != "not_run" || steps[1].Status != "not_run" {
+		t.Fatalf("expected deadline to stop the batch, got %q %+v", status, steps)
+	}
+}
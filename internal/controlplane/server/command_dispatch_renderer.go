@@ -1,12 +1,20 @@
 package server
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 
+	"github.com/marcus-qen/legator/internal/controlplane/cmdtracker"
 	corecommanddispatch "github.com/marcus-qen/legator/internal/controlplane/core/commanddispatch"
 )
 
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
 func renderDispatchCommandHTTP(w http.ResponseWriter, projection *corecommanddispatch.CommandInvokeProjection) {
 	response := corecommanddispatch.EncodeCommandInvokeHTTPJSONResponse(projection)
 	if response.SuppressWrite || !response.HasBody {
@@ -19,3 +27,37 @@ func renderDispatchCommandHTTP(w http.ResponseWriter, projection *corecommanddis
 	}
 	_ = json.NewEncoder(w).Encode(response.Body)
 }
+
+// writeIdempotentReplay answers a retried dispatch with the command first
+// dispatched under the same idempotency key, and its result if one arrived.
+func writeIdempotentReplay(w http.ResponseWriter, prior cmdtracker.IdempotentCommand) {
+	status := "dispatched"
+	switch {
+	case prior.Result != nil:
+		status = "completed"
+	case prior.ApprovalID != "":
+		status = "pending_approval"
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status":          status,
+		"replayed":        true,
+		"idempotency_key": prior.Key,
+		"request_id":      prior.RequestID,
+		"approval_id":     prior.ApprovalID,
+		"dispatched_at":   prior.Claimed,
+		"result":          prior.Result,
+	})
+}
+
+// idempotencyFingerprint hashes a decoded dispatch body so a reused
+// Idempotency-Key can be checked against the request it was first used with.
+func idempotencyFingerprint(body any) string {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
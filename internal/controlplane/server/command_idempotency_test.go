@@ -0,0 +1,113 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestHandleDispatchCommand_IdempotencyKeyReplaysOriginal(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-idem", "host", "linux", "amd64")
+	if err := srv.fleetMgr.SetPolicy("probe-idem", protocol.CapRemediate); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+
+	dispatch := func(key, requestID, command string) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(map[string]any{"request_id": requestID, "command": command})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/probes/probe-idem/command", bytes.NewReader(data))
+		req.SetPathValue("id", "probe-idem")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		rr := httptest.NewRecorder()
+		srv.handleDispatchCommand(rr, req)
+		return rr
+	}
+
+	first := dispatch("retry-1", "req-first", "systemctl restart nginx")
+	if first.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 pending approval, got %d: %s", first.Code, first.Body.String())
+	}
+	var queued struct {
+		ApprovalID string `json:"approval_id"`
+	}
+	_ = json.NewDecoder(first.Body).Decode(&queued)
+
+	replay := dispatch("retry-1", "req-retry", "systemctl restart nginx")
+	if replay.Code != http.StatusOK || replay.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected replayed 200, got %d headers=%v body=%s", replay.Code, replay.Header(), replay.Body.String())
+	}
+	var replayed struct {
+		Status     string `json:"status"`
+		RequestID  string `json:"request_id"`
+		ApprovalID string `json:"approval_id"`
+	}
+	_ = json.NewDecoder(replay.Body).Decode(&replayed)
+	if replayed.Status != "pending_approval" || replayed.RequestID != "req-first" || replayed.ApprovalID != queued.ApprovalID {
+		t.Fatalf("expected original request in replay, got %+v", replayed)
+	}
+	if pending := srv.approvalQueue.Pending(); len(pending) != 1 {
+		t.Fatalf("expected retry not to queue a second approval, got %d", len(pending))
+	}
+
+	if rr := dispatch("retry-2", "req-other", "systemctl restart nginx"); rr.Code != http.StatusAccepted {
+		t.Fatalf("expected a new key to dispatch again, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if pending := srv.approvalQueue.Pending(); len(pending) != 2 {
+		t.Fatalf("expected two pending approvals, got %d", len(pending))
+	}
+}
+
+func TestHandleDispatchCommand_IdempotencyKeyScopedByCallerAndBody(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-idem", "host", "linux", "amd64")
+	if err := srv.fleetMgr.SetPolicy("probe-idem", protocol.CapRemediate); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+
+	dispatch := func(caller, command string) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(map[string]any{"command": command})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/probes/probe-idem/command", bytes.NewReader(data))
+		req.SetPathValue("id", "probe-idem")
+		req.Header.Set("Idempotency-Key", "shared-key")
+		req = req.WithContext(auth.WithAPIKeyContext(req.Context(), &auth.APIKey{ID: caller, Name: caller}))
+		rr := httptest.NewRecorder()
+		srv.handleDispatchCommand(rr, req)
+		return rr
+	}
+
+	if rr := dispatch("alice", "systemctl restart nginx"); rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 pending approval, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// Another caller picking the same key gets its own command.
+	if rr := dispatch("bob", "systemctl restart nginx"); rr.Code != http.StatusAccepted || rr.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("expected a separate dispatch for another caller, got %d headers=%v body=%s", rr.Code, rr.Header(), rr.Body.String())
+	}
+
+	// The same caller reusing the key for a different command is refused.
+	rr := dispatch("alice", "systemctl restart postgresql")
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a reused key with a different body, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var errBody struct {
+		Code string `json:"code"`
+	}
+	_ = json.NewDecoder(rr.Body).Decode(&errBody)
+	if errBody.Code != "idempotency_key_mismatch" {
+		t.Fatalf("expected idempotency_key_mismatch, got %q", errBody.Code)
+	}
+
+	if rr := dispatch("alice", "systemctl restart nginx"); rr.Code != http.StatusOK || rr.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected replay for the original body, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if pending := srv.approvalQueue.Pending(); len(pending) != 2 {
+		t.Fatalf("expected one pending approval per caller, got %d", len(pending))
+	}
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/marcus-qen/legator/internal/probe/agent"
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+// A command the hub signs must run on a probe configured with the same hex
+// signing key, and the same envelope delivered again must be rejected.
+func TestHubSignedCommandRunsOnAgentAndReplayIsRejected(t *testing.T) {
+	srv := newTestServer(t) // LEGATOR_SIGNING_KEY is 64 hex chars
+	const probeID = "probe-cmd-sig"
+	const apiKey = "probe-cmd-key"
+	srv.fleetMgr.Register(probeID, "host", "linux", "amd64")
+	_ = srv.fleetMgr.SetAPIKey(probeID, apiKey)
+
+	hub := httptest.NewServer(http.HandlerFunc(srv.hub.HandleProbeWS))
+	defer hub.Close()
+	relay := newRecordingRelay(t, hub.URL)
+	defer relay.Close()
+
+	a := agent.New(&agent.Config{
+		ServerURL:   relay.URL,
+		ProbeID:     probeID,
+		APIKey:      apiKey,
+		SigningKey:  strings.Repeat("a", 64),
+		ConfigDir:   t.TempDir(),
+		PolicyLevel: protocol.CapObserve,
+	}, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = a.Run(ctx) }()
+	waitFor(t, func() bool { return srv.hub.IsConnected(probeID) }, "probe to connect")
+
+	cmd := protocol.CommandPayload{RequestID: "req-signed", Command: "echo", Args: []string{"signed"}, Level: protocol.CapObserve, Timeout: 10 * time.Second}
+	pending := srv.cmdTracker.Track(cmd.RequestID, probeID, cmd.Command, cmd.Level)
+	if err := srv.hub.SendTo(probeID, protocol.MsgCommand, cmd); err != nil {
+		t.Fatalf("send command: %v", err)
+	}
+	result := awaitCommandResult(t, pending.Result)
+	if result.ExitCode != 0 || !strings.Contains(result.Stdout, "signed") {
+		t.Fatalf("expected signed command to run, got exit=%d stdout=%q stderr=%q", result.ExitCode, result.Stdout, result.Stderr)
+	}
+
+	pending = srv.cmdTracker.Track(cmd.RequestID, probeID, cmd.Command, cmd.Level)
+	relay.replayLastCommand(t)
+	result = awaitCommandResult(t, pending.Result)
+	if result.ExitCode != -1 || !strings.Contains(result.Stderr, "replayed nonce") {
+		t.Fatalf("expected replayed command to be rejected, got exit=%d stderr=%q", result.ExitCode, result.Stderr)
+	}
+}
+
+func awaitCommandResult(t *testing.T, ch <-chan *protocol.CommandResultPayload) *protocol.CommandResultPayload {
+	t.Helper()
+	select {
+	case result := <-ch:
+		return result
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for command result")
+		return nil
+	}
+}
+
+// recordingRelay sits between a probe and the hub, forwarding frames both
+// ways and keeping the last command envelope sent to the probe.
+type recordingRelay struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	probe   *websocket.Conn
+	lastCmd []byte
+}
+
+func newRecordingRelay(t *testing.T, hubURL string) *recordingRelay {
+	t.Helper()
+	relay := &recordingRelay{}
+	upgrader := websocket.Upgrader{}
+	relay.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := http.Header{"Authorization": r.Header.Values("Authorization")}
+		upstream, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(hubURL, "http")+r.URL.RequestURI(), header)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+		probe, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer probe.Close()
+		relay.mu.Lock()
+		relay.probe = probe
+		relay.mu.Unlock()
+
+		go func() {
+			for {
+				mt, msg, err := probe.ReadMessage()
+				if err != nil {
+					_ = upstream.Close()
+					return
+				}
+				_ = upstream.WriteMessage(mt, msg)
+			}
+		}()
+		for {
+			mt, msg, err := upstream.ReadMessage()
+			if err != nil {
+				return
+			}
+			relay.mu.Lock()
+			var env protocol.Envelope
+			if json.Unmarshal(msg, &env) == nil && env.Type == protocol.MsgCommand {
+				relay.lastCmd = msg
+			}
+			err = probe.WriteMessage(mt, msg)
+			relay.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}))
+	return relay
+}
+
+func (r *recordingRelay) replayLastCommand(t *testing.T) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.probe == nil || r.lastCmd == nil {
+		t.Fatal("no command to replay")
+	}
+	if err := r.probe.WriteMessage(websocket.TextMessage, r.lastCmd); err != nil {
+		t.Fatalf("replay command: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/controlplane/commandtemplates"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// ── Command templates ────────────────────────────────────────
+
+func (s *Server) handleListCommandTemplates(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermCommandExec) {
+		return
+	}
+	templates := s.commandTemplates.List()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"templates": templates,
+		"total":     len(templates),
+	})
+}
+
+func (s *Server) handleCreateCommandTemplate(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermAdmin) {
+		return
+	}
+	var body struct {
+		ID          string                   `json:"id"`
+		Name        string                   `json:"name"`
+		Description string                   `json:"description"`
+		Command     string                   `json:"command"`
+		MinLevel    protocol.CapabilityLevel `json:"min_level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+		return
+	}
+
+	actor := actorFromAuthContext(r.Context())
+	tmpl, err := s.commandTemplates.Add(commandtemplates.Template{
+		ID:          body.ID,
+		Name:        body.Name,
+		Description: body.Description,
+		Command:     body.Command,
+		MinLevel:    body.MinLevel,
+		CreatedBy:   actor,
+	})
+	if err != nil {
+		if errors.Is(err, commandtemplates.ErrExists) {
+			writeJSONError(w, http.StatusConflict, "conflict", err.Error())
+			return
+		}
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	s.recordAudit(audit.Event{
+		Type:    audit.EventCommandTemplateChanged,
+		Actor:   actor,
+		Summary: fmt.Sprintf("Command template created: %s", tmpl.ID),
+		Detail: map[string]any{
+			"action":      "created",
+			"template_id": tmpl.ID,
+			"command":     tmpl.Command,
+			"params":      tmpl.Params,
+			"min_level":   tmpl.MinLevel,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(tmpl)
+}
+
+func (s *Server) handleDeleteCommandTemplate(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermAdmin) {
+		return
+	}
+	id := r.PathValue("id")
+	tmpl, ok := s.commandTemplates.Get(id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "command template not found")
+		return
+	}
+	if err := s.commandTemplates.Delete(id); err != nil {
+		writeJSONError(w, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+
+	s.recordAudit(audit.Event{
+		Type:    audit.EventCommandTemplateChanged,
+		Actor:   actorFromAuthContext(r.Context()),
+		Summary: fmt.Sprintf("Command template deleted: %s", tmpl.ID),
+		Detail: map[string]any{
+			"action":      "deleted",
+			"template_id": tmpl.ID,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// renderCommandTemplate fills cmd from a stored template. The template's
+// minimum level is enforced against the probe's policy level and raises the
+// command's own level, so approval and policy see the real requirement.
+func (s *Server) renderCommandTemplate(w http.ResponseWriter, probeLevel protocol.CapabilityLevel, templateID string, params map[string]string, cmd *protocol.CommandPayload) bool {
+	tmpl, ok := s.commandTemplates.Get(templateID)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", fmt.Sprintf("command template %s not found", templateID))
+		return false
+	}
+	if !commandtemplates.LevelAllows(probeLevel, tmpl.MinLevel) {
+		writeJSONError(w, http.StatusForbidden, "policy_level_too_low",
+			fmt.Sprintf("template %s requires policy level %s; probe is at %s", tmpl.ID, tmpl.MinLevel, probeLevel))
+		return false
+	}
+	command, args, err := tmpl.Render(params)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return false
+	}
+	cmd.Command = command
+	cmd.Args = args
+	if !commandtemplates.LevelAllows(cmd.Level, tmpl.MinLevel) {
+		cmd.Level = tmpl.MinLevel
+	}
+	return true
+}
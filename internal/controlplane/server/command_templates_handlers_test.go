@@ -0,0 +1,120 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/approval"
+	"github.com/marcus-qen/legator/internal/controlplane/commandtemplates"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestCommandTemplateCRUD(t *testing.T) {
+	srv := newTestServer(t)
+
+	body := `{"id":"restart-service","command":"systemctl restart {{.service}}","min_level":"remediate"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/command-templates", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	srv.handleCreateCommandTemplate(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	var created commandtemplates.Template
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(created.Params) != 1 || created.Params[0] != "service" {
+		t.Fatalf("unexpected params %v", created.Params)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleCreateCommandTemplate(rr, httptest.NewRequest(http.MethodPost, "/api/v1/command-templates", strings.NewReader(body)))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("duplicate status=%d want 409", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleListCommandTemplates(rr, httptest.NewRequest(http.MethodGet, "/api/v1/command-templates", nil))
+	var listed struct {
+		Total int `json:"total"`
+	}
+	_ = json.NewDecoder(rr.Body).Decode(&listed)
+	if listed.Total != 1 {
+		t.Fatalf("expected 1 template, got %d", listed.Total)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/command-templates/restart-service", nil)
+	req.SetPathValue("id", "restart-service")
+	rr = httptest.NewRecorder()
+	srv.handleDeleteCommandTemplate(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("delete status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	if _, ok := srv.commandTemplates.Get("restart-service"); ok {
+		t.Fatal("expected template to be deleted")
+	}
+}
+
+func TestHandleDispatchCommand_Template(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-tmpl", "host", "linux", "amd64")
+	if _, err := srv.commandTemplates.Add(commandtemplates.Template{
+		ID:       "restart-service",
+		Command:  "systemctl restart {{.service}}",
+		MinLevel: protocol.CapRemediate,
+	}); err != nil {
+		t.Fatalf("add template: %v", err)
+	}
+
+	dispatch := func(body map[string]any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/probes/probe-tmpl/command", bytes.NewReader(data))
+		req.SetPathValue("id", "probe-tmpl")
+		rr := httptest.NewRecorder()
+		srv.handleDispatchCommand(rr, req)
+		return rr
+	}
+
+	// Observe-level probes can't run a remediate template.
+	rr := dispatch(map[string]any{"request_id": "req-low", "template": "restart-service", "params": map[string]string{"service": "nginx"}})
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 below min level, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if err := srv.fleetMgr.SetPolicy("probe-tmpl", protocol.CapRemediate); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+	for _, tc := range []struct {
+		name string
+		body map[string]any
+		want int
+	}{
+		{"unknown template", map[string]any{"template": "nope"}, http.StatusNotFound},
+		{"missing param", map[string]any{"template": "restart-service"}, http.StatusBadRequest},
+		{"command and template", map[string]any{"template": "restart-service", "command": "uptime", "params": map[string]string{"service": "nginx"}}, http.StatusBadRequest},
+	} {
+		if rr := dispatch(tc.body); rr.Code != tc.want {
+			t.Fatalf("%s: status=%d want %d body=%s", tc.name, rr.Code, tc.want, rr.Body.String())
+		}
+	}
+
+	// The rendered command still goes through approval.
+	rr = dispatch(map[string]any{"request_id": "req-tmpl", "template": "restart-service", "params": map[string]string{"service": "nginx"}})
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 pending approval, got %d: %s", rr.Code, rr.Body.String())
+	}
+	pending := srv.approvalQueue.Pending()
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending approval, got %d", len(pending))
+	}
+	if got := approval.CommandLine(pending[0].Command); got != "systemctl restart nginx" {
+		t.Fatalf("unexpected queued command %q", got)
+	}
+	if pending[0].Command.Level != protocol.CapRemediate {
+		t.Fatalf("expected template min level on queued command, got %q", pending[0].Command.Level)
+	}
+}
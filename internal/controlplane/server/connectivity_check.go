@@ -0,0 +1,49 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/controlplane/connectivity"
+)
+
+// handleConnectivityCheck runs the task pre-run reachability check on
+// demand and returns the per-endpoint matrix. Identical endpoint lists
+// share a result for a short while so repeated checks do not hammer the
+// endpoints. It dials from the control plane, so it needs the same
+// permission as running a task.
+func (s *Server) handleConnectivityCheck(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermCommandExec) {
+		return
+	}
+	var req struct {
+		Endpoints []connectivity.Endpoint `json:"endpoints"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+		return
+	}
+	if len(req.Endpoints) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "endpoints required")
+		return
+	}
+	if err := connectivity.Validate(req.Endpoints); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	report, cached := s.connectivity.Check(r.Context(), req.Endpoints)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		*connectivity.Report
+		Reachable   int  `json:"reachable"`
+		Unreachable int  `json:"unreachable"`
+		Cached      bool `json:"cached"`
+	}{
+		Report:      report,
+		Reachable:   len(report.Endpoints) - len(report.Unreachable()),
+		Unreachable: len(report.Unreachable()),
+		Cached:      cached,
+	})
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestConnectivityCheckReturnsMatrixAndCaches(t *testing.T) {
+	srv := newTestServer(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	closedAddr := closed.Addr().String()
+	_ = closed.Close()
+
+	body := fmt.Sprintf(`{"endpoints":[{"name":"api","address":%q},{"name":"db-01","address":%q,"required":true}]}`, ln.Addr().String(), closedAddr)
+	var got struct {
+		Reachable   int  `json:"reachable"`
+		Unreachable int  `json:"unreachable"`
+		Cached      bool `json:"cached"`
+		Endpoints   []struct {
+			Name      string `json:"name"`
+			Reachable bool   `json:"reachable"`
+			Error     string `json:"error"`
+		} `json:"endpoints"`
+	}
+	for i, wantCached := range []bool{false, true} {
+		resp := makeRequest(t, srv, http.MethodPost, "/api/v1/connectivity/check", "", body)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("check %d: expected 200, got %d body=%s", i, resp.Code, resp.Body.String())
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got.Cached != wantCached {
+			t.Fatalf("check %d: expected cached=%v", i, wantCached)
+		}
+	}
+	if got.Reachable != 1 || got.Unreachable != 1 || len(got.Endpoints) != 2 {
+		t.Fatalf("unexpected matrix: %+v", got)
+	}
+	if !got.Endpoints[0].Reachable || got.Endpoints[1].Reachable || got.Endpoints[1].Error == "" {
+		t.Fatalf("unexpected endpoint results: %+v", got.Endpoints)
+	}
+
+	for _, bad := range []string{`{}`, `{"endpoints":[{"address":"no-port"}]}`, `not json`} {
+		if resp := makeRequest(t, srv, http.MethodPost, "/api/v1/connectivity/check", "", bad); resp.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", bad, resp.Code)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	checksumSuffix = ".sha256"
+	// installChecksumsPlaceholder is the line in install.sh replaced with the
+	// checksums of the probe binaries being served.
+	installChecksumsPlaceholder = `EMBEDDED_CHECKSUMS=""`
+	probeBinaryPrefix           = "legator-probe-"
+)
+
+// releaseChecksumCache holds SHA-256 digests of release files, recomputed
+// only when a file's size or modification time changes.
+type releaseChecksumCache struct {
+	mu      sync.Mutex
+	entries map[string]releaseChecksum // path -> digest
+}
+
+type releaseChecksum struct {
+	size    int64
+	modTime time.Time
+	sum     string
+}
+
+// sum returns the hex SHA-256 of the regular file at path.
+func (c *releaseChecksumCache) sum(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", filepath.Base(path))
+	}
+
+	c.mu.Lock()
+	cached, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.sum, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]releaseChecksum)
+	}
+	c.entries[path] = releaseChecksum{size: info.Size(), modTime: info.ModTime(), sum: sum}
+	return sum, nil
+}
+
+func (s *Server) releasesDir() string {
+	return filepath.Join(s.cfg.DataDir, "releases")
+}
+
+// serveReleaseChecksum answers GET /download/{file}.sha256 for a release file
+// with no checksum file of its own, in sha256sum format.
+func (s *Server) serveReleaseChecksum(w http.ResponseWriter, r *http.Request, filename string) {
+	name := strings.TrimSuffix(filename, checksumSuffix)
+	sum, err := s.releaseChecksums.sum(filepath.Join(s.releasesDir(), name))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprintf(w, "%s  %s\n", sum, name)
+}
+
+// probeBinaryChecksums returns "name:sha256" pairs for the probe binaries in
+// the releases directory, sorted by name. Unreadable files are skipped.
+func (s *Server) probeBinaryChecksums() []string {
+	entries, err := os.ReadDir(s.releasesDir())
+	if err != nil {
+		return nil
+	}
+	var pairs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, probeBinaryPrefix) || strings.HasSuffix(name, checksumSuffix) || !safeReleaseName(name) {
+			continue
+		}
+		sum, err := s.releaseChecksums.sum(filepath.Join(s.releasesDir(), name))
+		if err != nil {
+			continue
+		}
+		pairs = append(pairs, name+":"+sum)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// safeReleaseName reports whether name can be embedded in a shell string
+// without quoting.
+func safeReleaseName(name string) bool {
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// embedInstallChecksums fills the install script's checksum placeholder so
+// the script can verify the binary without a second request.
+func (s *Server) embedInstallChecksums(script []byte) []byte {
+	pairs := s.probeBinaryChecksums()
+	if len(pairs) == 0 {
+		return script
+	}
+	line := `EMBEDDED_CHECKSUMS="` + strings.Join(pairs, " ") + `"`
+	return []byte(strings.Replace(string(script), installChecksumsPlaceholder, line, 1))
+}
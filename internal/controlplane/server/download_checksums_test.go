@@ -0,0 +1,60 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadChecksumServedAndRefreshedOnChange(t *testing.T) {
+	srv := newTestServer(t)
+	releases := filepath.Join(srv.cfg.DataDir, "releases")
+	if err := os.MkdirAll(releases, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(releases, "legator-probe-linux-amd64")
+	if err := os.WriteFile(binary, []byte("probe-v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+	want := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:]) + "  legator-probe-linux-amd64\n"
+	}
+
+	rr := get("/download/legator-probe-linux-amd64.sha256")
+	if rr.Code != http.StatusOK || rr.Body.String() != want("probe-v1") {
+		t.Fatalf("unexpected checksum response %d: %q", rr.Code, rr.Body.String())
+	}
+
+	if err := os.WriteFile(binary, []byte("probe-v2-rebuilt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(binary, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if rr := get("/download/legator-probe-linux-amd64.sha256"); rr.Body.String() != want("probe-v2-rebuilt") {
+		t.Fatalf("expected checksum of replaced binary, got %q", rr.Body.String())
+	}
+
+	if rr := get("/download/legator-probe-linux-arm64.sha256"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing artifact, got %d", rr.Code)
+	}
+
+	script := srv.embedInstallChecksums([]byte("#!/usr/bin/env bash\nEMBEDDED_CHECKSUMS=\"\"\n"))
+	if !strings.Contains(string(script), `EMBEDDED_CHECKSUMS="legator-probe-linux-amd64:`+strings.Fields(want("probe-v2-rebuilt"))[0]+`"`) {
+		t.Fatalf("expected embedded checksum in install script, got %q", script)
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/events"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"github.com/marcus-qen/legator/internal/controlplane/tenant"
+	"go.uber.org/zap"
+)
+
+// runEventsSSE serves req until timeout and returns the stream body.
+func runEventsSSE(t *testing.T, srv *Server, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(req.Context(), 100*time.Millisecond)
+	defer cancel()
+	rr := httptest.NewRecorder()
+	srv.handleEventsSSE(rr, req.WithContext(ctx))
+	return rr
+}
+
+func TestHandleEventsSSE_ReplaysAfterLastEventID(t *testing.T) {
+	srv := &Server{logger: zap.NewNop(), eventBus: events.NewBus(16), fleetMgr: fleet.NewManager(zap.NewNop())}
+	srv.eventBus.Publish(events.Event{Type: events.ProbeConnected, Summary: "first"})
+	srv.eventBus.Publish(events.Event{Type: events.ProbeOffline, Summary: "second"})
+	srv.eventBus.Publish(events.Event{Type: events.ProbeReconnected, Summary: "third"})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	body := runEventsSSE(t, srv, req).Body.String()
+
+	if strings.Contains(body, "first") {
+		t.Fatalf("expected event 1 not to be replayed:\n%s", body)
+	}
+	if !strings.Contains(body, "id: 2\nevent: probe.offline") || !strings.Contains(body, "id: 3\nevent: probe.reconnected") {
+		t.Fatalf("expected events 2 and 3 with ids:\n%s", body)
+	}
+}
+
+func TestHandleEventsSSE_NoReplayWithoutPosition(t *testing.T) {
+	srv := &Server{logger: zap.NewNop(), eventBus: events.NewBus(16), fleetMgr: fleet.NewManager(zap.NewNop())}
+	srv.eventBus.Publish(events.Event{Type: events.ProbeConnected, Summary: "earlier"})
+
+	body := runEventsSSE(t, srv, httptest.NewRequest(http.MethodGet, "/api/v1/events", nil)).Body.String()
+	if strings.Contains(body, "earlier") {
+		t.Fatalf("expected no replay without since or Last-Event-ID:\n%s", body)
+	}
+}
+
+func TestHandleEventsSSE_InvalidSince(t *testing.T) {
+	srv := &Server{logger: zap.NewNop(), eventBus: events.NewBus(16), fleetMgr: fleet.NewManager(zap.NewNop())}
+
+	rr := runEventsSSE(t, srv, httptest.NewRequest(http.MethodGet, "/api/v1/events?since=yesterday", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestEventVisibleFollowsProbeTenant(t *testing.T) {
+	mgr := fleet.NewManager(zap.NewNop())
+	mgr.Register("probe-a", "host-a", "linux", "amd64")
+	mgr.Register("probe-b", "host-b", "linux", "amd64")
+	if err := mgr.SetTenantID("probe-a", "tenant-a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.SetTenantID("probe-b", "tenant-b"); err != nil {
+		t.Fatal(err)
+	}
+	srv := &Server{logger: zap.NewNop(), fleetMgr: mgr}
+	scope := tenant.Scope{TenantIDs: []string{"tenant-a"}}
+
+	cases := []struct {
+		evt  events.Event
+		want bool
+	}{
+		{events.Event{ProbeID: "probe-a"}, true},
+		{events.Event{ProbeID: "probe-b"}, false},
+		{events.Event{ProbeID: "probe-gone"}, false},
+		{events.Event{Type: events.PolicyChanged}, true},
+	}
+	for _, tc := range cases {
+		if got := srv.eventVisible(scope, tc.evt); got != tc.want {
+			t.Errorf("eventVisible(%+v) = %v, want %v", tc.evt, got, tc.want)
+		}
+	}
+	if !srv.eventVisible(tenant.Scope{IsAdmin: true}, events.Event{ProbeID: "probe-b"}) {
+		t.Error("expected admin scope to see every event")
+	}
+}
+
+func TestHandleEventsSSE_SendsKeepalives(t *testing.T) {
+	srv := &Server{logger: zap.NewNop(), eventBus: events.NewBus(16), fleetMgr: fleet.NewManager(zap.NewNop())}
+	srv.cfg.SSE.KeepaliveInterval = "20ms"
+
+	body := runEventsSSE(t, srv, httptest.NewRequest(http.MethodGet, "/api/v1/events", nil)).Body.String()
+	if strings.Count(body, ": keepalive\n\n") < 2 {
+		t.Fatalf("expected periodic keepalives on an idle stream:\n%s", body)
+	}
+}
+
+func TestHandleEventsSSE_ClientLimit(t *testing.T) {
+	srv := &Server{logger: zap.NewNop(), eventBus: events.NewBus(16), fleetMgr: fleet.NewManager(zap.NewNop())}
+	srv.sseClients = newSSELimiter(1)
+	if !srv.sseClients.acquire() {
+		t.Fatal("expected the first client to be admitted")
+	}
+
+	rr := runEventsSSE(t, srv, httptest.NewRequest(http.MethodGet, "/api/v1/events", nil))
+	if rr.Code != http.StatusServiceUnavailable || !strings.Contains(rr.Body.String(), "limit 1") {
+		t.Fatalf("expected 503 at the client limit, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("Retry-After") == "" {
+		t.Fatal("expected Retry-After on 503")
+	}
+
+	// A finished stream frees its slot.
+	srv.sseClients.release()
+	if rr := runEventsSSE(t, srv, httptest.NewRequest(http.MethodGet, "/api/v1/events", nil)); rr.Code != http.StatusOK {
+		t.Fatalf("expected stream after slot freed, got %d", rr.Code)
+	}
+	if srv.sseClients.active != 0 {
+		t.Fatalf("expected slot released after the stream ended, active=%d", srv.sseClients.active)
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+)
+
+// probesBySelector returns the request-scoped probes matching the selector
+// query parameter, sorted by ID.
+func (s *Server) probesBySelector(r *http.Request) (fleet.Selector, []*fleet.ProbeState, error) {
+	sel, err := fleet.ParseSelector(r.URL.Query().Get("selector"))
+	if err != nil {
+		return fleet.Selector{}, nil, err
+	}
+	status := strings.TrimSpace(r.URL.Query().Get("status"))
+	out := make([]*fleet.ProbeState, 0)
+	for _, ps := range s.probesForRequest(r) {
+		if status != "" && !strings.EqualFold(ps.Status, status) {
+			continue
+		}
+		if sel.Matches(ps.Tags) {
+			out = append(out, ps)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return sel, out, nil
+}
+
+// handleFleetSearch lists probes matching a tag selector such as
+// "env=prod,role in (web,api)". An empty selector lists every probe.
+func (s *Server) handleFleetSearch(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+	sel, probes, err := s.probesBySelector(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_selector", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"selector": sel.String(),
+		"probes":   probes,
+		"count":    len(probes),
+	})
+}
+
+// handleSelectorGroupCommand sends a command to every probe matching the
+// selector query parameter. Unlike search, an empty selector is rejected so a
+// missing parameter can't target the whole fleet.
+func (s *Server) handleSelectorGroupCommand(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermCommandExec) {
+		return
+	}
+	sel, probes, err := s.probesBySelector(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_selector", err.Error())
+		return
+	}
+	if sel.Empty() {
+		writeJSONError(w, http.StatusBadRequest, "invalid_selector", "selector is required")
+		return
+	}
+	s.dispatchGroupCommand(w, r, "selector", sel.String(), probes)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+)
+
+func TestHandleFleetSearchSelector(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-web", "web-01", "linux", "amd64")
+	srv.fleetMgr.Register("probe-api", "api-01", "linux", "amd64")
+	srv.fleetMgr.Register("probe-db", "db-01", "linux", "amd64")
+	_ = srv.fleetMgr.SetTags("probe-web", []string{"env=prod", "role=web", "region=eu-west"})
+	_ = srv.fleetMgr.SetTags("probe-api", []string{"env=prod", "role=api", "region=us-east"})
+	_ = srv.fleetMgr.SetTags("probe-db", []string{"env=prod", "role=db", "region=eu-west"})
+
+	search := func(selector string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/fleet/search?selector="+url.QueryEscape(selector), nil)
+		rr := httptest.NewRecorder()
+		srv.handleFleetSearch(rr, req)
+		return rr
+	}
+
+	rr := search("env=prod,role in (web,api),region=eu-west")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	var got struct {
+		Selector string              `json:"selector"`
+		Probes   []*fleet.ProbeState `json:"probes"`
+		Count    int                 `json:"count"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Count != 1 || got.Probes[0].ID != "probe-web" {
+		t.Fatalf("unexpected search result %+v", got)
+	}
+
+	if rr := search("role in (web"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed selector, got %d", rr.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/fleet/command", strings.NewReader(`{"command":"uptime"}`))
+	rr = httptest.NewRecorder()
+	srv.handleSelectorGroupCommand(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for group command without selector, got %d", rr.Code)
+	}
+}
+
+func TestHandleSetTagsRejectsInvalidKeyValue(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-kv", "host", "linux", "amd64")
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/probes/probe-kv/tags", strings.NewReader(`{"tags":["env=prod","env=dev"]}`))
+	req.SetPathValue("id", "probe-kv")
+	rr := httptest.NewRecorder()
+	srv.handleSetTags(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for conflicting key=value tags, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
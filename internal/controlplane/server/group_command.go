@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/cmdtracker"
+)
+
+const (
+	defaultGroupCommandWait = 30 * time.Second
+	maxGroupCommandWait     = 5 * time.Minute
+	// groupCommandOutputLimit caps stdout/stderr per probe so a large cohort
+	// cannot produce an unbounded response.
+	groupCommandOutputLimit = 4096
+)
+
+// groupCommandResult is one probe's entry in a group command response.
+type groupCommandResult struct {
+	ProbeID    string `json:"probe_id"`
+	Status     string `json:"status"` // dispatched, error, skipped, success, failed, timeout
+	RequestID  string `json:"request_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	Stdout     string `json:"stdout,omitempty"`
+	Stderr     string `json:"stderr,omitempty"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+}
+
+// groupCommandSummary aggregates waited group command outcomes.
+type groupCommandSummary struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	TimedOut  int `json:"timed_out"`
+	Errors    int `json:"errors"`
+	Skipped   int `json:"skipped"`
+}
+
+// groupCommandWait mirrors single-probe ?wait=true: the command timeout plus a
+// grace period, or 30s by default, capped so a request cannot hang forever.
+func groupCommandWait(cmdTimeout time.Duration) time.Duration {
+	wait := defaultGroupCommandWait
+	if cmdTimeout > 0 {
+		wait = cmdTimeout + 5*time.Second
+	}
+	if wait > maxGroupCommandWait {
+		wait = maxGroupCommandWait
+	}
+	return wait
+}
+
+// awaitGroupResults waits for every tracked dispatch until the shared deadline
+// or ctx is done. Entries still pending at that point are marked timeout and
+// cancelled in the tracker; completed results are kept.
+func awaitGroupResults(ctx context.Context, results []groupCommandResult, pending map[int]*cmdtracker.PendingCommand, wait time.Duration, cancel func(requestID string)) {
+	if len(pending) == 0 {
+		return
+	}
+	ctx, stop := context.WithTimeout(ctx, wait)
+	defer stop()
+
+	var wg sync.WaitGroup
+	for idx, pc := range pending {
+		wg.Add(1)
+		go func(idx int, pc *cmdtracker.PendingCommand) {
+			defer wg.Done()
+			entry := &results[idx]
+			select {
+			case result, ok := <-pc.Result:
+				if !ok || result == nil {
+					entry.Status = "error"
+					entry.Error = "command canceled"
+					return
+				}
+				exitCode := result.ExitCode
+				entry.ExitCode = &exitCode
+				entry.DurationMS = result.Duration
+				entry.Stdout, entry.Truncated = truncateGroupOutput(result.Stdout, entry.Truncated)
+				entry.Stderr, entry.Truncated = truncateGroupOutput(result.Stderr, entry.Truncated)
+				entry.Truncated = entry.Truncated || result.Truncated
+				if exitCode == 0 {
+					entry.Status = "success"
+				} else {
+					entry.Status = "failed"
+				}
+			case <-ctx.Done():
+				if cancel != nil {
+					cancel(entry.RequestID)
+				}
+				entry.Status = "timeout"
+				entry.Error = "no result before group wait deadline"
+			}
+		}(idx, pc)
+	}
+	wg.Wait()
+}
+
+func truncateGroupOutput(output string, truncated bool) (string, bool) {
+	if len(output) <= groupCommandOutputLimit {
+		return output, truncated
+	}
+	return output[:groupCommandOutputLimit], true
+}
+
+func summarizeGroupResults(results []groupCommandResult) groupCommandSummary {
+	var summary groupCommandSummary
+	for _, result := range results {
+		switch result.Status {
+		case "success":
+			summary.Succeeded++
+		case "failed":
+			summary.Failed++
+		case "timeout":
+			summary.TimedOut++
+		case "error":
+			summary.Errors++
+		case "skipped":
+			summary.Skipped++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/cmdtracker"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestAwaitGroupResultsMarksStragglersTimeout(t *testing.T) {
+	tracker := cmdtracker.New(time.Minute)
+	results := []groupCommandResult{
+		{ProbeID: "probe-ok", Status: "dispatched", RequestID: "grp-ok"},
+		{ProbeID: "probe-bad", Status: "dispatched", RequestID: "grp-bad"},
+		{ProbeID: "probe-slow", Status: "dispatched", RequestID: "grp-slow"},
+		{ProbeID: "probe-down", Status: "error", Error: "probe offline"},
+	}
+	pending := map[int]*cmdtracker.PendingCommand{
+		0: tracker.Track("grp-ok", "probe-ok", "uptime", protocol.CapObserve),
+		1: tracker.Track("grp-bad", "probe-bad", "uptime", protocol.CapObserve),
+		2: tracker.Track("grp-slow", "probe-slow", "uptime", protocol.CapObserve),
+	}
+
+	_ = tracker.Complete("grp-ok", &protocol.CommandResultPayload{RequestID: "grp-ok", ExitCode: 0, Stdout: strings.Repeat("x", groupCommandOutputLimit+10), Duration: 12})
+	_ = tracker.Complete("grp-bad", &protocol.CommandResultPayload{RequestID: "grp-bad", ExitCode: 2, Stderr: "boom"})
+
+	awaitGroupResults(context.Background(), results, pending, 50*time.Millisecond, tracker.Cancel)
+
+	if results[0].Status != "success" || results[0].ExitCode == nil || *results[0].ExitCode != 0 {
+		t.Fatalf("expected success with exit 0, got %+v", results[0])
+	}
+	if len(results[0].Stdout) != groupCommandOutputLimit || !results[0].Truncated {
+		t.Fatalf("expected stdout truncated to %d bytes, got len=%d truncated=%v", groupCommandOutputLimit, len(results[0].Stdout), results[0].Truncated)
+	}
+	if results[1].Status != "failed" || results[1].Stderr != "boom" || *results[1].ExitCode != 2 {
+		t.Fatalf("expected failed result with stderr, got %+v", results[1])
+	}
+	if results[2].Status != "timeout" || results[2].ExitCode != nil {
+		t.Fatalf("expected straggler marked timeout, got %+v", results[2])
+	}
+	if tracker.InFlight() != 0 {
+		t.Fatalf("expected timed-out request to be cancelled in tracker, %d still in flight", tracker.InFlight())
+	}
+
+	summary := summarizeGroupResults(append(results, groupCommandResult{ProbeID: "probe-drain", Status: "skipped"}))
+	want := groupCommandSummary{Succeeded: 1, Failed: 1, TimedOut: 1, Errors: 1, Skipped: 1}
+	if summary != want {
+		t.Fatalf("unexpected summary: got %+v want %+v", summary, want)
+	}
+}
+
+func TestGroupCommandWaitBounds(t *testing.T) {
+	if got := groupCommandWait(0); got != defaultGroupCommandWait {
+		t.Fatalf("expected default wait, got %s", got)
+	}
+	if got := groupCommandWait(10 * time.Second); got != 15*time.Second {
+		t.Fatalf("expected command timeout plus grace, got %s", got)
+	}
+	if got := groupCommandWait(time.Hour); got != maxGroupCommandWait {
+		t.Fatalf("expected wait capped at %s, got %s", maxGroupCommandWait, got)
+	}
+}
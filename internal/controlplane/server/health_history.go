@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"go.uber.org/zap"
+)
+
+const defaultHealthHistoryWindow = 24 * time.Hour
+
+// recordHealthSample persists the probe's current health score; the store
+// thins samples to its configured interval.
+func (s *Server) recordHealthSample(probeID string) {
+	if s.healthHistory == nil {
+		return
+	}
+	ps, ok := s.fleetMgr.Get(probeID)
+	if !ok || ps.Health == nil {
+		return
+	}
+	if _, err := s.healthHistory.Record(probeID, *ps.Health, time.Now().UTC()); err != nil {
+		s.logger.Warn("failed to record health sample", zap.String("probe", probeID), zap.Error(err))
+	}
+}
+
+func (s *Server) handleProbeHealthHistory(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+	id := r.PathValue("id")
+	if _, ok := s.probeForRequest(r, id); !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	if s.healthHistory == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "health history unavailable")
+		return
+	}
+
+	window := defaultHealthHistoryWindow
+	if raw := strings.TrimSpace(r.URL.Query().Get("window")); raw != "" {
+		parsed, err := parseHumanDuration(raw)
+		if err != nil || parsed <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid window duration")
+			return
+		}
+		window = parsed
+	}
+	limit := 0
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	samples, err := s.healthHistory.List(id, time.Now().UTC().Add(-window), limit)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "internal_error", "failed to load health history")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"probe_id": id,
+		"window":   window.String(),
+		"samples":  samples,
+		"count":    len(samples),
+	})
+}
+
+func (s *Server) handlePurgeProbeHealthHistory(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetWrite) {
+		return
+	}
+	id := r.PathValue("id")
+	if _, ok := s.probeForRequest(r, id); !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	if s.healthHistory == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "health history unavailable")
+		return
+	}
+
+	deleted, err := s.healthHistory.DeleteProbe(id)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "internal_error", "failed to purge health history")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"probe_id": id, "deleted": deleted})
+}
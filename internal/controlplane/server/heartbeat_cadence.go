@@ -0,0 +1,38 @@
+package server
+
+import (
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+// trackPushedHeartbeatInterval records the heartbeat interval a policy push
+// just set, so the derived offline threshold widens before the first slower
+// heartbeat is due instead of marking the probe offline in the gap.
+// Zero means the probe falls back to its default cadence.
+func (s *Server) trackPushedHeartbeatInterval(probeID string, intervalSec int) {
+	interval := time.Duration(intervalSec) * time.Second
+	if interval <= 0 {
+		interval = protocol.DefaultHeartbeatInterval
+	}
+	if err := s.fleetMgr.SetHeartbeatInterval(probeID, interval); err != nil {
+		s.logger.Warn("record pushed heartbeat interval", zap.String("probe", probeID), zap.Error(err))
+	}
+}
+
+// trackReportedHeartbeatInterval keeps the recorded interval in step with
+// the cadence the probe reports on its heartbeats. Probes that predate
+// reporting send zero and keep their registration value.
+func (s *Server) trackReportedHeartbeatInterval(probeID string, intervalSec int) {
+	if intervalSec <= 0 {
+		return
+	}
+	ps, ok := s.fleetMgr.Get(probeID)
+	if !ok || ps.HeartbeatIntervalSec == intervalSec {
+		return
+	}
+	if err := s.fleetMgr.SetHeartbeatInterval(probeID, time.Duration(intervalSec)*time.Second); err != nil {
+		s.logger.Warn("record reported heartbeat interval", zap.String("probe", probeID), zap.Error(err))
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+)
+
+// handleJobConcurrency reports async job worker concurrency: in-flight usage
+// against the global and per-probe limits, queued jobs deferred by those
+// limits, and recent throttle events.
+func (s *Server) handleJobConcurrency(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+	if s.asyncJobsScheduler == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "async job scheduler unavailable")
+		return
+	}
+	snap, err := s.asyncJobsScheduler.ConcurrencySnapshot()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(snap)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/api"
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+const (
+	// probeKeyRotationGrace keeps the replaced key valid so a probe that
+	// reconnects before applying the new key is not locked out.
+	probeKeyRotationGrace = 5 * time.Minute
+	// probeKeyRotationMaxInterval bounds how often the rotator scans.
+	probeKeyRotationMaxInterval = time.Hour
+)
+
+// rotateProbeKey issues a new API key, pushes it to the connected probe and
+// audits the rotation. If the push fails the previous key is restored.
+func (s *Server) rotateProbeKey(id, actor, summary string) (string, error) {
+	newKey, err := api.GenerateAPIKey()
+	if err != nil {
+		return "", fmt.Errorf("generate api key: %w", err)
+	}
+	if err := s.fleetMgr.RotateAPIKey(id, newKey, probeKeyRotationGrace); err != nil {
+		return "", err
+	}
+	if err := s.hub.SendTo(id, protocol.MsgKeyRotation, protocol.KeyRotationPayload{NewKey: newKey}); err != nil {
+		_ = s.fleetMgr.RevertAPIKeyRotation(id)
+		return "", fmt.Errorf("push key rotation: %w", err)
+	}
+	s.emitAudit(audit.EventProbeKeyRotated, id, actor, summary)
+	return newKey, nil
+}
+
+// probeKeyDue reports whether a probe's API key has outlived maxAge.
+func probeKeyDue(ps *fleet.ProbeState, now time.Time, maxAge time.Duration) bool {
+	if ps == nil || ps.APIKey == "" || ps.Type == fleet.ProbeTypeRemote {
+		return false
+	}
+	return ps.KeyAge(now) >= maxAge
+}
+
+// rotateStaleProbeKeys rotates the key of every connected probe whose key is
+// older than maxAge. Offline probes are picked up once they reconnect.
+func (s *Server) rotateStaleProbeKeys(maxAge time.Duration) int {
+	now := time.Now().UTC()
+	rotated := 0
+	for _, id := range s.hub.Connected() {
+		ps, ok := s.fleetMgr.Get(id)
+		if !ok || !probeKeyDue(ps, now, maxAge) {
+			continue
+		}
+		summary := fmt.Sprintf("Probe API key rotated by schedule (key age %s exceeded max age %s)",
+			ps.KeyAge(now).Truncate(time.Minute), maxAge)
+		if _, err := s.rotateProbeKey(id, "key-rotator", summary); err != nil {
+			s.logger.Warn("scheduled probe key rotation failed", zap.String("probe", id), zap.Error(err))
+			continue
+		}
+		rotated++
+	}
+	return rotated
+}
+
+// probeKeyRotator periodically rotates probe API keys older than maxAge.
+func (s *Server) probeKeyRotator(ctx context.Context, maxAge time.Duration) {
+	interval := maxAge / 10
+	if interval > probeKeyRotationMaxInterval {
+		interval = probeKeyRotationMaxInterval
+	}
+	if interval < probeOfflineCheckInterval {
+		interval = probeOfflineCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := s.rotateStaleProbeKeys(maxAge); n > 0 {
+				s.logger.Info("rotated stale probe API keys", zap.Int("count", n))
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+)
+
+func TestProbeKeyDue(t *testing.T) {
+	now := time.Now().UTC()
+	old := now.Add(-31 * 24 * time.Hour)
+	fresh := now.Add(-time.Hour)
+	maxAge := 30 * 24 * time.Hour
+
+	cases := []struct {
+		name string
+		ps   *fleet.ProbeState
+		want bool
+	}{
+		{"stale key", &fleet.ProbeState{APIKey: "k", KeyIssuedAt: &old}, true},
+		{"fresh key", &fleet.ProbeState{APIKey: "k", KeyIssuedAt: &fresh}, false},
+		{"legacy key uses registration", &fleet.ProbeState{APIKey: "k", Registered: old}, true},
+		{"no key", &fleet.ProbeState{KeyIssuedAt: &old}, false},
+		{"remote probe", &fleet.ProbeState{APIKey: "k", Type: fleet.ProbeTypeRemote, KeyIssuedAt: &old}, false},
+		{"nil", nil, false},
+	}
+	for _, tc := range cases {
+		if got := probeKeyDue(tc.ps, now, maxAge); got != tc.want {
+			t.Errorf("%s: probeKeyDue = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -185,11 +185,18 @@ func (s *Server) evaluateKubeflowMutationPolicy(
 		"policy_decision":  decision.Outcome,
 		"risk_level":       decision.RiskLevel,
 		"policy_rationale": decision.Rationale,
+		"denial":           decision.Rationale.Denial,
 	}
 
 	switch decision.Outcome {
 	case approvalpolicy.CommandPolicyDecisionDeny:
-		s.emitAudit(audit.EventAuthorizationDenied, probeID, actor, fmt.Sprintf("Kubeflow %s denied by policy: %s", action, target))
+		s.recordAudit(audit.Event{
+			Type:    audit.EventAuthorizationDenied,
+			ProbeID: probeID,
+			Actor:   actor,
+			Summary: fmt.Sprintf("Kubeflow %s denied by policy: %s", action, target),
+			Detail:  map[string]any{"action": action, "target": target, "denial": decision.Rationale.Denial},
+		})
 		s.publishEvent(events.CommandFailed, probeID, fmt.Sprintf("Kubeflow %s denied: %s", action, target), map[string]any{"action": action, "target": target})
 		response["status"] = "denied"
 		response["message"] = "Kubeflow mutation denied by policy."
@@ -352,6 +359,9 @@ func (s *Server) dispatchApprovedCommand(probeID string, cmd protocol.CommandPay
 	if strings.HasPrefix(strings.TrimSpace(probeID), kubeflowApprovalProbePrefix) {
 		return s.dispatchApprovedKubeflowMutation(probeID, cmd)
 	}
+	if strings.HasPrefix(strings.TrimSpace(probeID), networkDeviceApprovalProbePrefix) {
+		return s.dispatchApprovedNetworkDeviceCommand(probeID, cmd)
+	}
 	return s.dispatchCore.Dispatch(probeID, cmd)
 }
 
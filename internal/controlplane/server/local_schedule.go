@@ -0,0 +1,204 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/localschedule"
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+// localRunAuditOutputBytes caps the stdout and stderr kept in the audit
+// event for each local scheduled run.
+const localRunAuditOutputBytes = 4 << 10
+
+// localScheduleView is the API shape of a probe's local schedule.
+type localScheduleView struct {
+	*localschedule.Schedule
+	Pushed bool `json:"pushed"`
+}
+
+func (s *Server) handleGetLocalSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.probeForRequest(r, id); !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	sched, ok := s.localSchedules.Get(id)
+	if !ok {
+		sched = &localschedule.Schedule{ProbeID: id, Entries: []protocol.LocalScheduleEntry{}}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(sched)
+}
+
+// handleSetLocalSchedule replaces a probe's local schedule and pushes it if
+// the probe is connected; otherwise it is pushed when the probe reconnects.
+func (s *Server) handleSetLocalSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.probeForRequest(r, id); !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	var body struct {
+		Entries []protocol.LocalScheduleEntry `json:"entries"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+	s.replaceLocalSchedule(w, r, id, body.Entries)
+}
+
+// handleDeleteLocalSchedule clears a probe's local schedule. The empty
+// schedule is still pushed so the probe stops running the old one.
+func (s *Server) handleDeleteLocalSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.probeForRequest(r, id); !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	if _, ok := s.localSchedules.Get(id); !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe has no local schedule")
+		return
+	}
+	s.replaceLocalSchedule(w, r, id, nil)
+}
+
+func (s *Server) replaceLocalSchedule(w http.ResponseWriter, r *http.Request, probeID string, entries []protocol.LocalScheduleEntry) {
+	var before []protocol.LocalScheduleEntry
+	if prev, ok := s.localSchedules.Get(probeID); ok {
+		before = prev.Entries
+	}
+	sched, err := s.localSchedules.Set(probeID, entries, actorFromAuthContext(r.Context()))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	summary := fmt.Sprintf("Local schedule set: %d entries (version %d)", len(sched.Entries), sched.Version)
+	if len(sched.Entries) == 0 {
+		summary = fmt.Sprintf("Local schedule cleared (version %d)", sched.Version)
+	}
+	s.recordAudit(audit.Event{
+		Type:    audit.EventLocalScheduleChanged,
+		ProbeID: probeID,
+		Actor:   sched.UpdatedBy,
+		Summary: summary,
+		Before:  before,
+		After:   sched.Entries,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(localScheduleView{Schedule: sched, Pushed: s.pushLocalSchedule(probeID, sched)})
+}
+
+// pushLocalSchedule sends a schedule to its probe if connected.
+func (s *Server) pushLocalSchedule(probeID string, sched *localschedule.Schedule) bool {
+	if !s.hub.IsConnected(probeID) {
+		return false
+	}
+	if err := s.hub.SendTo(probeID, protocol.MsgLocalSchedule, sched.Payload()); err != nil {
+		s.logger.Warn("failed to push local schedule", zap.String("probe", probeID), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// handleLocalScheduleResults records runs of a probe's local schedule in the
+// audit log at the time they ran, then acknowledges them so the probe can
+// drop them from its cache. Runs already recorded are acknowledged again
+// without a second audit event.
+func (s *Server) handleLocalScheduleResults(probeID string, payload protocol.LocalScheduleResultsPayload) {
+	ps, _ := s.fleetMgr.Get(probeID)
+	acked := make([]string, 0, len(payload.Results))
+	for _, result := range payload.Results {
+		if result.RunID == "" {
+			continue
+		}
+		acked = append(acked, result.RunID)
+		if !s.localRuns.add(probeID + "/" + result.RunID) {
+			continue
+		}
+
+		at := result.StartedAt.UTC()
+		if at.IsZero() {
+			at = time.Now().UTC()
+		}
+		stdout, stderr := result.Stdout, result.Stderr
+		if ps != nil {
+			stdout, stderr = redactForProbe(ps, stdout), redactForProbe(ps, stderr)
+		}
+		summary := fmt.Sprintf("Local scheduled command %s exit=%d", result.EntryID, result.ExitCode)
+		if result.Offline {
+			summary += " (offline)"
+		}
+		s.recordAudit(audit.Event{
+			ID:        probeID + "/" + result.RunID,
+			Timestamp: at,
+			Type:      audit.EventLocalScheduleRun,
+			ProbeID:   probeID,
+			Actor:     probeID,
+			Summary:   summary,
+			Detail: map[string]any{
+				"run_id":           result.RunID,
+				"entry_id":         result.EntryID,
+				"command":          result.Command,
+				"exit_code":        result.ExitCode,
+				"duration_ms":      result.DurationMs,
+				"offline":          result.Offline,
+				"schedule_version": payload.ScheduleVersion,
+				"stdout":           truncateOutput(stdout, localRunAuditOutputBytes),
+				"stderr":           truncateOutput(stderr, localRunAuditOutputBytes),
+				"truncated":        result.Truncated || len(stdout) > localRunAuditOutputBytes || len(stderr) > localRunAuditOutputBytes,
+			},
+		})
+	}
+	if len(acked) == 0 {
+		return
+	}
+	if err := s.hub.SendTo(probeID, protocol.MsgLocalScheduleAck, protocol.LocalScheduleAckPayload{RunIDs: acked}); err != nil {
+		s.logger.Debug("failed to acknowledge local schedule results", zap.String("probe", probeID), zap.Error(err))
+	}
+}
+
+func truncateOutput(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	return text[:limit]
+}
+
+// localRunSet remembers recently recorded local run IDs so a batch re-sent
+// after a lost acknowledgement is not audited twice.
+type localRunSet struct {
+	mu    sync.Mutex
+	seen  map[string]struct{}
+	order []string
+	limit int
+}
+
+func newLocalRunSet(limit int) *localRunSet {
+	return &localRunSet{seen: make(map[string]struct{}, limit), limit: limit}
+}
+
+// add reports whether key is new, evicting the oldest key past the limit.
+func (l *localRunSet) add(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[key]; ok {
+		return false
+	}
+	l.seen[key] = struct{}{}
+	l.order = append(l.order, key)
+	if len(l.order) > l.limit {
+		delete(l.seen, l.order[0])
+		l.order = l.order[1:]
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestLocalScheduleHandlers(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-ls", "host", "linux", "amd64")
+
+	call := func(method, id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/probes/"+id+"/local-schedule", bytes.NewBufferString(body))
+		req.SetPathValue("id", id)
+		rr := httptest.NewRecorder()
+		switch method {
+		case http.MethodGet:
+			srv.handleGetLocalSchedule(rr, req)
+		case http.MethodPut:
+			srv.handleSetLocalSchedule(rr, req)
+		case http.MethodDelete:
+			srv.handleDeleteLocalSchedule(rr, req)
+		}
+		return rr
+	}
+
+	if rr := call(http.MethodGet, "probe-missing", ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown probe, got %d", rr.Code)
+	}
+	if rr := call(http.MethodDelete, "probe-ls", ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 deleting a missing schedule, got %d", rr.Code)
+	}
+	if rr := call(http.MethodPut, "probe-ls", `{"entries":[{"id":"disk","schedule":"every minute","command":"df"}]}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid cron, got %d", rr.Code)
+	}
+
+	rr := call(http.MethodPut, "probe-ls", `{"entries":[{"id":"disk","schedule":"*/5 * * * *","command":"df","args":["-h"],"offline_only":true}]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("put: %d %s", rr.Code, rr.Body.String())
+	}
+	var set localScheduleView
+	if err := json.Unmarshal(rr.Body.Bytes(), &set); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if set.Version != 1 || len(set.Entries) != 1 || !set.Entries[0].OfflineOnly || set.Pushed {
+		t.Fatalf("unexpected schedule %+v (pushed=%v)", set.Schedule, set.Pushed)
+	}
+
+	rr = call(http.MethodGet, "probe-ls", "")
+	if rr.Code != http.StatusOK || !bytes.Contains(rr.Body.Bytes(), []byte(`"id":"disk"`)) {
+		t.Fatalf("get: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = call(http.MethodDelete, "probe-ls", "")
+	if rr.Code != http.StatusOK || !bytes.Contains(rr.Body.Bytes(), []byte(`"version":2`)) {
+		t.Fatalf("delete: %d %s", rr.Code, rr.Body.String())
+	}
+
+	changes := srv.queryAudit(audit.Filter{Type: audit.EventLocalScheduleChanged})
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 schedule change events, got %d", len(changes))
+	}
+}
+
+func TestLocalScheduleResultsAuditedOnceAtRunTime(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-ls", "host", "linux", "amd64")
+
+	ran := time.Date(2026, 3, 1, 4, 5, 0, 0, time.UTC)
+	payload := protocol.LocalScheduleResultsPayload{
+		ScheduleVersion: 4,
+		Results: []protocol.LocalScheduleResult{{
+			RunID:      "local-1",
+			EntryID:    "disk",
+			Command:    "df -h",
+			StartedAt:  ran,
+			DurationMs: 12,
+			ExitCode:   0,
+			Stdout:     "Filesystem Size",
+			Offline:    true,
+		}},
+	}
+	env := protocol.Envelope{Type: protocol.MsgLocalScheduleResults, Payload: payload}
+	srv.handleProbeMessage("probe-ls", env)
+	// A batch re-sent after a lost ack is not recorded twice.
+	srv.handleProbeMessage("probe-ls", env)
+
+	runs := srv.queryAudit(audit.Filter{Type: audit.EventLocalScheduleRun})
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 local run event, got %d", len(runs))
+	}
+	evt := runs[0]
+	if !evt.Timestamp.Equal(ran) || evt.ProbeID != "probe-ls" {
+		t.Fatalf("event should carry the run time and probe, got %+v", evt)
+	}
+	detail := evt.Detail.(map[string]any)
+	if detail["entry_id"] != "disk" || detail["offline"] != true || detail["schedule_version"] != int64(4) {
+		t.Fatalf("unexpected detail %#v", detail)
+	}
+}
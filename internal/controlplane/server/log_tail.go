@@ -0,0 +1,179 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	coreapprovalpolicy "github.com/marcus-qen/legator/internal/controlplane/core/approvalpolicy"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+const (
+	// logTailKeepalive is how often an idle tail stream checks the probe is
+	// still connected and writes a comment line to keep proxies from
+	// dropping the connection.
+	logTailKeepalive = 15 * time.Second
+	// logTailMaxDuration bounds a single tail; the probe enforces it too.
+	logTailMaxDuration = time.Hour
+	logTailMaxLines    = 1000
+)
+
+// logTailRegistry tracks active log tails by request ID so their output
+// chunks bypass command stream recording and go straight to subscribers.
+type logTailRegistry struct {
+	mu    sync.Mutex
+	tails map[string]string // request_id -> probe_id
+}
+
+func (r *logTailRegistry) add(requestID, probeID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tails == nil {
+		r.tails = make(map[string]string)
+	}
+	r.tails[requestID] = probeID
+}
+
+func (r *logTailRegistry) remove(requestID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tails, requestID)
+}
+
+func (r *logTailRegistry) has(requestID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.tails[requestID]
+	return ok
+}
+
+// handleProbeLogTail streams a probe's journal (unit) or log file (path) as
+// server-sent events until the client disconnects, the probe goes away or
+// the tail ends. The probe is always told to stop when the stream ends.
+func (s *Server) handleProbeLogTail(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermCommandExec) {
+		return
+	}
+	ps, ok := s.fleetMgr.Get(r.PathValue("id"))
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	if ps.Type == fleet.ProbeTypeRemote {
+		writeJSONError(w, http.StatusConflict, "unsupported_probe", "log tailing requires an agent probe")
+		return
+	}
+	if ps.Draining {
+		writeJSONError(w, http.StatusConflict, "probe_draining", "probe is draining; undrain it to tail logs")
+		return
+	}
+
+	query := r.URL.Query()
+	tail := protocol.LogTailPayload{
+		RequestID:   "logtail-" + uuid.New().String(),
+		Unit:        strings.TrimSpace(query.Get("unit")),
+		Path:        strings.TrimSpace(query.Get("path")),
+		MaxDuration: logTailMaxDuration,
+	}
+	if tail.Unit == "" && tail.Path == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "unit or path is required")
+		return
+	}
+	if raw := strings.TrimSpace(query.Get("lines")); raw != "" {
+		lines, err := strconv.Atoi(raw)
+		if err != nil || lines < 0 || lines > logTailMaxLines {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("lines must be between 0 and %d", logTailMaxLines))
+			return
+		}
+		tail.Lines = lines
+	}
+
+	// Evaluate the follow command against command policy the same way a
+	// dispatched command would be; tails cannot wait for approval, so
+	// anything other than allow is refused.
+	target := tail.Path
+	policyCmd := protocol.CommandPayload{RequestID: tail.RequestID, Command: "tail", Args: []string{"-F", tail.Path}, Level: protocol.CapObserve}
+	if tail.Unit != "" {
+		target = tail.Unit
+		policyCmd = protocol.CommandPayload{RequestID: tail.RequestID, Command: "journalctl", Args: []string{"-f", "-u", tail.Unit}, Level: protocol.CapObserve}
+	}
+	actor := actorFromAuthContext(r.Context())
+	if s.approvalCore != nil {
+		decision := s.approvalCore.EvaluateCommandPolicyForProbe(r.Context(), ps.ID, &policyCmd, ps.PolicyLevel)
+		if decision.Outcome != coreapprovalpolicy.CommandPolicyDecisionAllow {
+			s.emitAudit(audit.EventAuthorizationDenied, ps.ID, actor, fmt.Sprintf("Log tail of %s refused by policy (%s)", target, decision.Outcome))
+			writeJSONError(w, http.StatusForbidden, "policy_denied", fmt.Sprintf("log tail of %s is not allowed by command policy (%s)", target, decision.Outcome))
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "internal_error", "streaming not supported")
+		return
+	}
+
+	s.logTails.add(tail.RequestID, ps.ID)
+	defer s.logTails.remove(tail.RequestID)
+	sub, cleanup := s.hub.SubscribeStream(tail.RequestID, 256)
+	defer cleanup()
+
+	if err := s.hub.SendTo(ps.ID, protocol.MsgLogTailStart, tail); err != nil {
+		writeJSONError(w, http.StatusBadGateway, "probe_unreachable", err.Error())
+		return
+	}
+	s.emitAudit(audit.EventCommandSent, ps.ID, actor, fmt.Sprintf("Log tail started: %s", target))
+
+	stopReason := "subscriber disconnected"
+	defer func() {
+		if err := s.hub.SendTo(ps.ID, protocol.MsgLogTailStop, protocol.LogTailStopPayload{RequestID: tail.RequestID, Reason: stopReason}); err != nil {
+			s.logger.Debug("log tail stop not delivered", zap.String("probe", ps.ID), zap.String("request_id", tail.RequestID), zap.Error(err))
+		}
+		s.emitAudit(audit.EventCommandResult, ps.ID, actor, fmt.Sprintf("Log tail stopped: %s (%s)", target, stopReason))
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	started, _ := json.Marshal(map[string]any{"request_id": tail.RequestID, "probe_id": ps.ID, "unit": tail.Unit, "path": tail.Path})
+	fmt.Fprintf(w, "event: started\ndata: %s\n\n", started)
+	flusher.Flush()
+
+	ticker := time.NewTicker(logTailKeepalive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if !s.hub.IsConnected(ps.ID) {
+				stopReason = "probe disconnected"
+				fmt.Fprintf(w, "event: end\ndata: {\"reason\":\"probe disconnected\"}\n\n")
+				flusher.Flush()
+				return
+			}
+			fmt.Fprintf(w, ": keepalive\n\n")
+			flusher.Flush()
+		case chunk := <-sub.Ch:
+			data, _ := json.Marshal(chunk)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+			if chunk.Final {
+				stopReason = fmt.Sprintf("tail exited with code %d", chunk.ExitCode)
+				return
+			}
+		}
+	}
+}
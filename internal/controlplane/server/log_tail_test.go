@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/cmdtracker"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestHandleProbeLogTailValidation(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-tail", "host", "linux", "amd64")
+
+	cases := []struct {
+		name    string
+		probeID string
+		query   string
+		want    int
+	}{
+		{"unknown probe", "nope", "unit=nginx", http.StatusNotFound},
+		{"missing unit and path", "probe-tail", "", http.StatusBadRequest},
+		{"lines out of range", "probe-tail", "unit=nginx&lines=5000", http.StatusBadRequest},
+		{"lines not a number", "probe-tail", "unit=nginx&lines=lots", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/probes/"+tc.probeID+"/logs/tail?"+tc.query, nil)
+			req.SetPathValue("id", tc.probeID)
+			rr := httptest.NewRecorder()
+			srv.handleProbeLogTail(rr, req)
+			if rr.Code != tc.want {
+				t.Fatalf("status=%d want %d body=%s", rr.Code, tc.want, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestLogTailChunksBypassCommandStreams(t *testing.T) {
+	srv := newTestServer(t)
+	const requestID = "logtail-test"
+
+	srv.logTails.add(requestID, "probe-tail")
+	defer srv.logTails.remove(requestID)
+	sub, cleanup := srv.hub.SubscribeStream(requestID, 4)
+	defer cleanup()
+
+	srv.handleProbeMessage("probe-tail", protocol.Envelope{
+		Type:    protocol.MsgOutputChunk,
+		Payload: protocol.OutputChunkPayload{RequestID: requestID, Stream: "stdout", Data: "hello\n", Seq: 1},
+	})
+
+	select {
+	case chunk := <-sub.Ch:
+		if chunk.Data != "hello\n" {
+			t.Fatalf("unexpected chunk %+v", chunk)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected log tail chunk to reach the subscriber")
+	}
+
+	replay, err := srv.commandStreams.Replay(requestID, cmdtracker.StreamReplayQuery{})
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(replay.Events) != 0 {
+		t.Fatalf("expected log tail output not to be recorded, got %d events", len(replay.Events))
+	}
+}
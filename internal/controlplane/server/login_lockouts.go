@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"go.uber.org/zap"
+)
+
+// handleListLoginLockouts returns the active password login lockouts.
+func (s *Server) handleListLoginLockouts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"enabled":  s.loginLockout != nil,
+		"lockouts": s.loginLockout.List(),
+	})
+}
+
+// handleClearLoginLockout lifts a lockout before it expires. The key is
+// "user:<username>" or "ip:<address>", as listed; usernames match case
+// insensitively. The failures counted towards the lockout are forgotten too.
+func (s *Server) handleClearLoginLockout(w http.ResponseWriter, r *http.Request) {
+	if s.loginLockout == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "login lockout not enabled")
+		return
+	}
+	kind, subject, ok := strings.Cut(strings.TrimSpace(r.PathValue("key")), ":")
+	if !ok || strings.TrimSpace(subject) == "" || (kind != auth.LockoutKindUser && kind != auth.LockoutKindIP) {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", `lockout key must be "user:<username>" or "ip:<address>"`)
+		return
+	}
+
+	lock, found, err := s.loginLockout.Clear(auth.LockoutKey(kind, subject), actorFromAuthContext(r.Context()))
+	if !found {
+		writeJSONError(w, http.StatusNotFound, "not_found", "no active lockout for "+kind+" "+strings.TrimSpace(subject))
+		return
+	}
+	if err != nil {
+		s.logger.Warn("cannot delete persisted login lockout", zap.String("key", lock.Key), zap.Error(err))
+	}
+	if lock.Kind == auth.LockoutKindUser {
+		s.authnAudit.ClearFailures(auth.AuthnMethodLocal, lock.Subject, "")
+	} else {
+		s.authnAudit.ClearFailures(auth.AuthnMethodLocal, "", lock.Subject)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status":  "cleared",
+		"lockout": lock,
+	})
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+)
+
+func TestLoginLockoutListAndClear(t *testing.T) {
+	srv := newAuthTestServer(t)
+	if srv.loginLockout == nil {
+		t.Fatal("expected login lockout to be enabled by default")
+	}
+	admin := createAPIKey(t, srv, "admin", auth.PermAdmin)
+	reader := createAPIKey(t, srv, "reader", auth.PermFleetRead)
+
+	srv.loginLockout.RecordFailure("alice", "", auth.DefaultLockoutMaxAttempts, 0)
+
+	if rr := makeRequest(t, srv, http.MethodGet, "/api/v1/auth/lockouts", reader, ""); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for non-admin, got %d", rr.Code)
+	}
+
+	rr := makeRequest(t, srv, http.MethodGet, "/api/v1/auth/lockouts", admin, "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	var listed struct {
+		Enabled  bool           `json:"enabled"`
+		Lockouts []auth.Lockout `json:"lockouts"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil {
+		t.Fatal(err)
+	}
+	if !listed.Enabled || len(listed.Lockouts) != 1 || listed.Lockouts[0].Key != "user:alice" {
+		t.Fatalf("unexpected lockouts: %+v", listed)
+	}
+
+	if rr := makeRequest(t, srv, http.MethodDelete, "/api/v1/auth/lockouts/user:Alice", admin, ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 on clear, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	if srv.loginLockout.Locked("alice", "") != nil {
+		t.Fatal("expected lockout to be cleared")
+	}
+	if rr := makeRequest(t, srv, http.MethodDelete, "/api/v1/auth/lockouts/user:alice", admin, ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for cleared lockout, got %d", rr.Code)
+	}
+	if rr := makeRequest(t, srv, http.MethodDelete, "/api/v1/auth/lockouts/alice", admin, ""); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed key, got %d", rr.Code)
+	}
+
+	cleared := srv.queryAudit(audit.Filter{Type: audit.EventLoginLockoutCleared})
+	if len(cleared) != 1 || cleared[0].Actor != "admin" {
+		t.Fatalf("expected one lockout cleared event by admin, got %+v", cleared)
+	}
+	if got := srv.queryAudit(audit.Filter{Type: audit.EventLoginLockout}); len(got) != 1 {
+		t.Fatalf("expected one lockout event, got %d", len(got))
+	}
+}
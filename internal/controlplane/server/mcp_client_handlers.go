@@ -121,5 +121,7 @@ func (s *Server) handleInvokeMCPTool(w http.ResponseWriter, r *http.Request) {
 		"qualified_name": res.QualifiedName,
 		"content":        res.Content,
 		"is_error":       res.IsError,
+		"status":         res.Status,
+		"timeout_ms":     res.TimeoutMS,
 	})
 }
@@ -28,6 +28,9 @@ func (s *Server) handleProbeMessage(probeID string, env protocol.Envelope) {
 			_ = s.fleetMgr.Heartbeat(probeID, &hb)
 			s.emitAudit(audit.EventProbeRegistered, probeID, "system", "Auto-registered via heartbeat")
 		}
+		s.recordHealthSample(probeID)
+		s.recordResourceSample(probeID, &hb)
+		s.trackReportedHeartbeatInterval(probeID, hb.HeartbeatIntervalSec)
 
 		s.publishEvent(events.ProbeConnected, probeID, fmt.Sprintf("Probe %s heartbeat", probeID),
 			map[string]string{"status": "online", "last_seen": time.Now().UTC().Format(time.RFC3339)})
@@ -52,17 +55,28 @@ func (s *Server) handleProbeMessage(probeID string, env protocol.Envelope) {
 			s.logger.Warn("bad command result payload", zap.String("probe", probeID), zap.Error(err))
 			return
 		}
+		if err := result.DecompressOutput(); err != nil {
+			s.logger.Warn("cannot decompress command output", zap.String("probe", probeID), zap.String("request_id", result.RequestID), zap.Error(err))
+			result.Stdout, result.Compression = "", ""
+			result.Stderr = "control plane could not decompress command output: " + err.Error()
+		}
 		s.logger.Info("command result received",
 			zap.String("probe", probeID),
 			zap.String("request_id", result.RequestID),
 			zap.Int("exit_code", result.ExitCode),
 		)
+		detail := map[string]any{"exit_code": result.ExitCode, "duration_ms": result.Duration}
+		if result.RateLimited {
+			s.noteProbeRateLimited(probeID, result.RequestID, result.RetryAfterMs)
+			detail["rate_limited"] = true
+			detail["retry_after_ms"] = result.RetryAfterMs
+		}
 		s.recordAudit(audit.Event{
 			Type:    audit.EventCommandResult,
 			ProbeID: probeID,
 			Actor:   probeID,
 			Summary: "Command completed: " + result.RequestID,
-			Detail:  map[string]any{"exit_code": result.ExitCode, "duration_ms": result.Duration},
+			Detail:  detail,
 		})
 		if err := s.cmdTracker.Complete(result.RequestID, &result); err != nil {
 			s.logger.Debug("no waiting caller for result", zap.String("request_id", result.RequestID))
@@ -71,8 +85,12 @@ func (s *Server) handleProbeMessage(probeID string, env protocol.Envelope) {
 		if result.ExitCode != 0 {
 			evtType = events.CommandFailed
 		}
-		s.publishEvent(evtType, probeID, fmt.Sprintf("Command %s exit=%d", result.RequestID, result.ExitCode),
-			map[string]any{"request_id": result.RequestID, "exit_code": result.ExitCode})
+		evtDetail := map[string]any{"request_id": result.RequestID, "exit_code": result.ExitCode}
+		if result.RateLimited {
+			evtDetail["rate_limited"] = true
+			evtDetail["retry_after_ms"] = result.RetryAfterMs
+		}
+		s.publishEvent(evtType, probeID, fmt.Sprintf("Command %s exit=%d", result.RequestID, result.ExitCode), evtDetail)
 		s.appendCommandStreamMarker(result.RequestID, cmdtracker.StreamEventResult, "command_result", map[string]any{
 			"probe_id":    probeID,
 			"exit_code":   result.ExitCode,
@@ -93,6 +111,13 @@ func (s *Server) handleProbeMessage(probeID string, env protocol.Envelope) {
 			s.logger.Warn("bad output chunk", zap.String("probe", probeID), zap.Error(err))
 			return
 		}
+		if s.logTails.has(chunk.RequestID) {
+			// Log tail output is live-only: not recorded, never completes a command.
+			if s.hub != nil {
+				s.hub.DispatchChunk(chunk)
+			}
+			return
+		}
 		s.recordCommandOutputChunk(chunk, true)
 		if chunk.Final {
 			s.appendCommandStreamMarker(chunk.RequestID, cmdtracker.StreamEventResult, "stream_final", map[string]any{
@@ -104,9 +129,14 @@ func (s *Server) handleProbeMessage(probeID string, env protocol.Envelope) {
 				zap.String("request_id", chunk.RequestID),
 				zap.Int("exit_code", chunk.ExitCode),
 			)
+			if chunk.RateLimited {
+				s.noteProbeRateLimited(probeID, chunk.RequestID, chunk.RetryAfterMs)
+			}
 			_ = s.cmdTracker.Complete(chunk.RequestID, &protocol.CommandResultPayload{
-				RequestID: chunk.RequestID,
-				ExitCode:  chunk.ExitCode,
+				RequestID:    chunk.RequestID,
+				ExitCode:     chunk.ExitCode,
+				RateLimited:  chunk.RateLimited,
+				RetryAfterMs: chunk.RetryAfterMs,
 			})
 			s.completeAsyncJobByRequestID(chunk.RequestID, chunk.ExitCode, chunk.Data)
 		}
@@ -147,6 +177,38 @@ func (s *Server) handleProbeMessage(probeID string, env protocol.Envelope) {
 			zap.Bool("success", result.Success),
 		)
 
+	case protocol.MsgPolicyReport:
+		data, _ := json.Marshal(env.Payload)
+		var report protocol.PolicyReportPayload
+		if err := json.Unmarshal(data, &report); err != nil {
+			s.logger.Warn("bad policy report payload", zap.String("probe", probeID), zap.Error(err))
+			return
+		}
+		if !s.policyQueries.deliver(probeID, report) {
+			s.logger.Debug("unsolicited policy report ignored",
+				zap.String("probe", probeID),
+				zap.String("request_id", report.RequestID),
+			)
+		}
+
+	case protocol.MsgAlert:
+		data, _ := json.Marshal(env.Payload)
+		var alert protocol.AlertPayload
+		if err := json.Unmarshal(data, &alert); err != nil {
+			s.logger.Warn("bad alert payload", zap.String("probe", probeID), zap.Error(err))
+			return
+		}
+		s.handleProbeAlert(probeID, alert)
+
+	case protocol.MsgLocalScheduleResults:
+		data, _ := json.Marshal(env.Payload)
+		var results protocol.LocalScheduleResultsPayload
+		if err := json.Unmarshal(data, &results); err != nil {
+			s.logger.Warn("bad local schedule results payload", zap.String("probe", probeID), zap.Error(err))
+			return
+		}
+		s.handleLocalScheduleResults(probeID, results)
+
 	default:
 		s.logger.Debug("unhandled message type",
 			zap.String("probe", probeID),
@@ -154,3 +216,17 @@ func (s *Server) handleProbeMessage(probeID string, env protocol.Envelope) {
 		)
 	}
 }
+
+// noteProbeRateLimited records that a probe refused a command under its
+// policy rate limit, so operators can see it on the probe and back off.
+func (s *Server) noteProbeRateLimited(probeID, requestID string, retryAfterMs int64) {
+	until := time.Now().Add(time.Duration(retryAfterMs) * time.Millisecond)
+	if err := s.fleetMgr.SetRateLimited(probeID, until); err != nil {
+		s.logger.Debug("rate limit reported by unknown probe", zap.String("probe", probeID), zap.Error(err))
+	}
+	s.logger.Warn("probe is rate limiting commands",
+		zap.String("probe", probeID),
+		zap.String("request_id", requestID),
+		zap.Int64("retry_after_ms", retryAfterMs),
+	)
+}
@@ -1,10 +1,13 @@
 package server
 
 import (
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/marcus-qen/legator/internal/controlplane/alerts"
 	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
 	"github.com/marcus-qen/legator/internal/protocol"
 )
 
@@ -43,6 +46,35 @@ func TestHandleProbeMessage_HeartbeatAutoRegistersProbe(t *testing.T) {
 	}
 }
 
+func TestHandleProbeMessage_HeartbeatTracksReportedInterval(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-cadence", "host", "linux", "amd64")
+	_ = srv.fleetMgr.SetHeartbeatInterval("probe-cadence", 30*time.Second)
+
+	srv.handleProbeMessage("probe-cadence", protocol.Envelope{
+		Type:    protocol.MsgHeartbeat,
+		Payload: protocol.HeartbeatPayload{ProbeID: "probe-cadence", HeartbeatIntervalSec: 300},
+	})
+	ps, _ := srv.fleetMgr.Get("probe-cadence")
+	if threshold, source := ps.OfflineThreshold(90 * time.Second); threshold != 15*time.Minute || source != fleet.OfflineThresholdHeartbeat {
+		t.Fatalf("expected threshold derived from reported interval, got %s (%s)", threshold, source)
+	}
+
+	// Heartbeats without an interval keep the recorded value.
+	srv.handleProbeMessage("probe-cadence", protocol.Envelope{
+		Type:    protocol.MsgHeartbeat,
+		Payload: protocol.HeartbeatPayload{ProbeID: "probe-cadence"},
+	})
+	if ps, _ := srv.fleetMgr.Get("probe-cadence"); ps.HeartbeatIntervalSec != 300 {
+		t.Fatalf("expected interval kept at 300s, got %d", ps.HeartbeatIntervalSec)
+	}
+
+	srv.trackPushedHeartbeatInterval("probe-cadence", 0)
+	if ps, _ := srv.fleetMgr.Get("probe-cadence"); ps.HeartbeatIntervalSec != 30 {
+		t.Fatalf("expected pushed default interval 30s, got %d", ps.HeartbeatIntervalSec)
+	}
+}
+
 func TestHandleProbeMessage_InventoryUpdatesState(t *testing.T) {
 	srv := newTestServer(t)
 	srv.fleetMgr.Register("probe-inv", "host", "linux", "amd64")
@@ -76,6 +108,35 @@ func TestHandleProbeMessage_InventoryUpdatesState(t *testing.T) {
 	}
 }
 
+func TestHandleProbeMessage_AlertFiresRuleAndDedups(t *testing.T) {
+	srv := newTestServer(t)
+	if srv.alertStore == nil {
+		t.Skip("alerts store unavailable")
+	}
+	srv.fleetMgr.Register("probe-alert", "host", "linux", "amd64")
+	if _, err := srv.alertStore.CreateRule(alerts.AlertRule{
+		Name:      "probe pushed",
+		Enabled:   true,
+		Condition: alerts.AlertCondition{Type: alerts.ConditionProbeAlert, ProbeCondition: protocol.AlertOOMKill},
+	}); err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+
+	alert := protocol.AlertPayload{Condition: protocol.AlertOOMKill, Subject: "java", Status: "firing", Message: "Out of memory: Killed process 4242 (java)"}
+	for i := 0; i < 2; i++ {
+		srv.handleProbeMessage("probe-alert", protocol.Envelope{Type: protocol.MsgAlert, Payload: alert})
+	}
+
+	auditEvents := srv.queryAudit(audit.Filter{ProbeID: "probe-alert", Type: audit.EventProbeAlert, Limit: 5})
+	if len(auditEvents) != 1 {
+		t.Fatalf("expected one audit event for a repeated alert, got %d", len(auditEvents))
+	}
+	active := srv.alertStore.ActiveAlerts()
+	if len(active) != 1 || active[0].ProbeID != "probe-alert" {
+		t.Fatalf("expected probe_alert rule to fire, got %+v", active)
+	}
+}
+
 func TestHandleProbeMessage_CommandResultCompletesPendingCommand(t *testing.T) {
 	srv := newTestServer(t)
 	pending := srv.cmdTracker.Track("req-command-result", "probe-cmd", "ls", protocol.CapObserve)
@@ -108,6 +169,55 @@ func TestHandleProbeMessage_CommandResultCompletesPendingCommand(t *testing.T) {
 	}
 }
 
+func TestHandleProbeMessage_CommandResultDecompressesOutput(t *testing.T) {
+	srv := newTestServer(t)
+	pending := srv.cmdTracker.Track("req-compressed", "probe-cmd", "cat /etc/nginx/nginx.conf", protocol.CapObserve)
+
+	config := strings.Repeat("server_name example.com;\n", 4096)
+	result := protocol.CommandResultPayload{RequestID: "req-compressed", Stdout: config}
+	if err := result.CompressOutput(1024); err != nil || result.Compression == "" {
+		t.Fatalf("expected compressed result, got %q (%v)", result.Compression, err)
+	}
+	srv.handleProbeMessage("probe-cmd", protocol.Envelope{Type: protocol.MsgCommandResult, Payload: result})
+
+	select {
+	case got := <-pending.Result:
+		if got == nil || got.Stdout != config || got.Compression != "" {
+			t.Fatalf("expected decompressed output, got %+v", got)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out waiting for command result completion")
+	}
+}
+
+func TestHandleProbeMessage_RateLimitedResultMarksProbe(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-rate", "web-01", "linux", "amd64")
+	pending := srv.cmdTracker.Track("req-throttled", "probe-rate", "systemctl restart nginx", protocol.CapRemediate)
+
+	before := time.Now()
+	srv.handleProbeMessage("probe-rate", protocol.Envelope{
+		Type: protocol.MsgCommandResult,
+		Payload: protocol.CommandResultPayload{
+			RequestID: "req-throttled", ExitCode: -1, Stderr: "probe rate limited",
+			RateLimited: true, RetryAfterMs: 20000,
+		},
+	})
+
+	select {
+	case got := <-pending.Result:
+		if got == nil || !got.RateLimited || got.RetryAfterMs != 20000 {
+			t.Fatalf("expected rate_limited result passed to the caller, got %+v", got)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out waiting for command result completion")
+	}
+	ps, _ := srv.fleetMgr.Get("probe-rate")
+	if ps.RateLimitedUntil == nil || ps.RateLimitedUntil.Before(before.Add(19*time.Second)) {
+		t.Fatalf("expected probe marked rate limited for ~20s, got %v", ps.RateLimitedUntil)
+	}
+}
+
 func TestHandleProbeMessage_OutputChunkFinalCompletesPendingCommand(t *testing.T) {
 	srv := newTestServer(t)
 	pending := srv.cmdTracker.Track("req-stream-final", "probe-stream", "tail -f", protocol.CapObserve)
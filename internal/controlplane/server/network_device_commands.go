@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/core/approvalpolicy"
+	corecommanddispatch "github.com/marcus-qen/legator/internal/controlplane/core/commanddispatch"
+	"github.com/marcus-qen/legator/internal/controlplane/events"
+	"github.com/marcus-qen/legator/internal/controlplane/networkdevices"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+const (
+	networkDeviceApprovalProbePrefix = "network-device:"
+	networkDeviceCommandTimeout      = 30 * time.Second
+)
+
+// handleNetworkDeviceCommand runs a read-oriented command on a network device
+// after passing it through the same policy/approval gate as probe commands.
+func (s *Server) handleNetworkDeviceCommand(w http.ResponseWriter, r *http.Request) {
+	if s.networkDeviceStore == nil {
+		s.handleNetworkDevicesUnavailable(w, r)
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "device id required")
+		return
+	}
+	device, err := s.networkDeviceStore.GetDevice(id)
+	if err != nil {
+		if networkdevices.IsNotFound(err) {
+			writeJSONError(w, http.StatusNotFound, "not_found", "network device not found")
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "internal_error", "failed to load network device")
+		return
+	}
+
+	var req networkdevices.CommandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Command) == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "command is required")
+		return
+	}
+
+	actor := actorFromAuthContext(r.Context())
+	probeID := networkDeviceApprovalProbeID(device.ID)
+	prepared, err := networkdevices.DriverFor(device.Vendor).Prepare(req.Command)
+	if err != nil {
+		s.emitAudit(audit.EventAuthorizationDenied, probeID, actor, fmt.Sprintf("Network device command rejected on %s: %s", device.Name, strings.TrimSpace(req.Command)))
+		writeJSONError(w, http.StatusBadRequest, "command_not_allowed", err.Error())
+		return
+	}
+
+	cmd := protocol.CommandPayload{
+		RequestID: corecommanddispatch.NextCommandRequestID(),
+		Command:   prepared,
+		Timeout:   networkDeviceCommandTimeout,
+		Level:     protocol.CapObserve,
+	}
+
+	if s.approvalCore != nil {
+		policyResult, err := s.approvalCore.SubmitCommandApprovalWithContext(
+			r.Context(),
+			probeID,
+			&cmd,
+			protocol.CapObserve,
+			fmt.Sprintf("Network device command on %s", device.Name),
+			actor,
+		)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "internal_error", fmt.Sprintf("approval queue: %v", err))
+			return
+		}
+		if policyResult == nil {
+			writeJSONError(w, http.StatusInternalServerError, "internal_error", "policy decision unavailable")
+			return
+		}
+
+		decision := policyResult.Decision
+		response := map[string]any{
+			"policy_decision":  decision.Outcome,
+			"risk_level":       decision.RiskLevel,
+			"policy_rationale": decision.Rationale,
+			"denial":           decision.Rationale.Denial,
+		}
+		switch decision.Outcome {
+		case approvalpolicy.CommandPolicyDecisionDeny:
+			s.recordAudit(audit.Event{
+				Type:    audit.EventAuthorizationDenied,
+				ProbeID: probeID,
+				Actor:   actor,
+				Summary: fmt.Sprintf("Network device command denied by policy on %s: %s", device.Name, prepared),
+				Detail:  map[string]any{"device_id": device.ID, "command": prepared, "denial": decision.Rationale.Denial},
+			})
+			s.publishEvent(events.CommandFailed, probeID, fmt.Sprintf("Network device command denied on %s", device.Name), map[string]any{"device_id": device.ID, "command": prepared})
+			response["status"] = "denied"
+			response["message"] = "Network device command denied by policy."
+			writeNetworkDeviceJSON(w, http.StatusTooManyRequests, response)
+			return
+		case approvalpolicy.CommandPolicyDecisionQueue:
+			if policyResult.Request == nil {
+				writeJSONError(w, http.StatusInternalServerError, "internal_error", "approval queue unavailable")
+				return
+			}
+			pending := policyResult.Request
+			s.emitAudit(audit.EventApprovalRequest, probeID, actor, fmt.Sprintf("Network device command requires approval on %s: %s (risk: %s)", device.Name, prepared, pending.RiskLevel))
+			s.publishEvent(events.ApprovalNeeded, probeID, fmt.Sprintf("Network device command on %s queued for approval", device.Name), map[string]any{"approval_id": pending.ID, "device_id": device.ID, "command": prepared})
+			response["status"] = "pending_approval"
+			response["approval_id"] = pending.ID
+			response["expires_at"] = pending.ExpiresAt
+			response["message"] = "Network device command requires human approval. Approved commands run with the device's stored credentials."
+			writeNetworkDeviceJSON(w, http.StatusAccepted, response)
+			return
+		}
+	}
+
+	creds := networkdevices.CredentialInput{
+		Password:   strings.TrimSpace(req.Password),
+		PrivateKey: strings.TrimSpace(req.PrivateKey),
+	}
+	result, err := s.runNetworkDeviceCommand(r.Context(), *device, creds, req.Command, actor)
+	if err != nil {
+		writeJSONError(w, http.StatusBadGateway, "execution_failed", err.Error())
+		return
+	}
+	writeNetworkDeviceJSON(w, http.StatusOK, map[string]any{"result": result})
+}
+
+// runNetworkDeviceCommand executes an already-authorized command and records
+// the outcome in audit and the event bus.
+func (s *Server) runNetworkDeviceCommand(ctx context.Context, device networkdevices.Device, creds networkdevices.CredentialInput, command, actor string) (*networkdevices.CommandResult, error) {
+	probeID := networkDeviceApprovalProbeID(device.ID)
+	s.emitAudit(audit.EventCommandSent, probeID, actor, fmt.Sprintf("Network device command sent to %s: %s", device.Name, command))
+	s.publishEvent(events.CommandDispatched, probeID, fmt.Sprintf("Network device command dispatched to %s", device.Name), map[string]any{"device_id": device.ID, "command": command})
+
+	result, err := networkdevices.NewSSHExecutor(s.networkDeviceStore).ExecuteReadOnly(ctx, device, creds, command)
+	if err != nil {
+		s.emitAudit(audit.EventCommandResult, probeID, actor, fmt.Sprintf("Network device command failed on %s: %v", device.Name, err))
+		s.publishEvent(events.CommandFailed, probeID, fmt.Sprintf("Network device command failed on %s", device.Name), map[string]any{"device_id": device.ID, "error": err.Error()})
+		return nil, err
+	}
+	if result.Error != "" {
+		s.emitAudit(audit.EventCommandResult, probeID, actor, fmt.Sprintf("Network device command on %s exited with error: %s", device.Name, result.Error))
+		s.publishEvent(events.CommandFailed, probeID, fmt.Sprintf("Network device command failed on %s", device.Name), map[string]any{"device_id": device.ID, "error": result.Error})
+		return result, nil
+	}
+	s.emitAudit(audit.EventCommandResult, probeID, actor, fmt.Sprintf("Network device command completed on %s (%d bytes)", device.Name, len(result.Output)))
+	s.publishEvent(events.CommandCompleted, probeID, fmt.Sprintf("Network device command completed on %s", device.Name), map[string]any{"device_id": device.ID, "duration_ms": result.DurationMS})
+	return result, nil
+}
+
+// dispatchApprovedNetworkDeviceCommand runs a queued network device command
+// once approved. Inline credentials are never queued, so the device's stored
+// credentials are used.
+func (s *Server) dispatchApprovedNetworkDeviceCommand(probeID string, cmd protocol.CommandPayload) error {
+	if s.networkDeviceStore == nil {
+		return fmt.Errorf("network devices unavailable")
+	}
+	deviceID := strings.TrimPrefix(strings.TrimSpace(probeID), networkDeviceApprovalProbePrefix)
+	device, err := s.networkDeviceStore.GetDevice(deviceID)
+	if err != nil {
+		return fmt.Errorf("load network device %s: %w", deviceID, err)
+	}
+	_, err = s.runNetworkDeviceCommand(context.Background(), *device, networkdevices.CredentialInput{}, cmd.Command, "approval")
+	return err
+}
+
+func networkDeviceApprovalProbeID(deviceID string) string {
+	return networkDeviceApprovalProbePrefix + strings.TrimSpace(deviceID)
+}
+
+func writeNetworkDeviceJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
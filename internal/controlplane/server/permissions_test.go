@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -667,3 +668,46 @@ func TestAuthorizationDenialsAreAudited(t *testing.T) {
 		t.Fatalf("expected required permission %q, got %q", auth.PermFleetWrite, detail["required_permission"])
 	}
 }
+
+func TestAPIKeyAuthenticationIsAudited(t *testing.T) {
+	srv := newAuthTestServer(t)
+	valid := createAPIKey(t, srv, "reader", auth.PermFleetRead)
+	invalid := valid[:12] + "0000000000000000"
+
+	for i := 0; i < 2; i++ {
+		rr := makeRequest(t, srv, http.MethodGet, "/api/v1/probes", invalid, "")
+		if rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 for invalid key, got %d", rr.Code)
+		}
+	}
+	failures := srv.queryAudit(audit.Filter{Type: audit.EventAuthnFailure})
+	if len(failures) != 1 {
+		t.Fatalf("expected API key failures to be sampled to 1 event, got %d", len(failures))
+	}
+	detail, _ := failures[0].Detail.(map[string]any)
+	if detail["method"] != auth.AuthnMethodAPIKey || detail["source_ip"] != "192.0.2.1" {
+		t.Fatalf("unexpected failure detail: %#v", detail)
+	}
+	if fmt.Sprint(detail["failed_attempts"]) != "1" {
+		t.Fatalf("unexpected failed_attempts: %#v", detail["failed_attempts"])
+	}
+	if got := srv.authnAudit.FailedAttempts(auth.AuthnMethodAPIKey, valid[:12]); got != 2 {
+		t.Fatalf("failed attempts = %d, want 2 (sampled failures are still counted)", got)
+	}
+
+	for i := 0; i < 3; i++ {
+		if rr := makeRequest(t, srv, http.MethodGet, "/api/v1/probes", valid, ""); rr.Code != http.StatusOK {
+			t.Fatalf("expected 200 for valid key, got %d", rr.Code)
+		}
+	}
+	successes := srv.queryAudit(audit.Filter{Type: audit.EventAuthnSuccess})
+	if len(successes) != 1 {
+		t.Fatalf("expected API key successes to be sampled to 1 event, got %d", len(successes))
+	}
+	if successes[0].Actor != "reader" {
+		t.Fatalf("success actor = %q, want reader", successes[0].Actor)
+	}
+	if got := srv.authnAudit.FailedAttempts(auth.AuthnMethodAPIKey, valid[:12]); got != 0 {
+		t.Fatalf("failed attempts after success = %d, want 0", got)
+	}
+}
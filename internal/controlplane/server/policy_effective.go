@@ -0,0 +1,218 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+// policyQueryTimeout bounds how long an effective-policy request waits for
+// the probe to answer.
+const policyQueryTimeout = 10 * time.Second
+
+// policyQueryRegistry routes policy reports back to the request that asked
+// for them.
+type policyQueryRegistry struct {
+	mu      sync.Mutex
+	pending map[string]pendingPolicyQuery // request_id -> waiter
+}
+
+type pendingPolicyQuery struct {
+	probeID string
+	ch      chan protocol.PolicyReportPayload
+}
+
+func (r *policyQueryRegistry) add(requestID, probeID string) <-chan protocol.PolicyReportPayload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending == nil {
+		r.pending = make(map[string]pendingPolicyQuery)
+	}
+	ch := make(chan protocol.PolicyReportPayload, 1)
+	r.pending[requestID] = pendingPolicyQuery{probeID: probeID, ch: ch}
+	return ch
+}
+
+func (r *policyQueryRegistry) remove(requestID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, requestID)
+}
+
+// deliver hands a report to its waiter. Reports for unknown requests, or
+// from a probe other than the one queried, are dropped.
+func (r *policyQueryRegistry) deliver(probeID string, report protocol.PolicyReportPayload) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	waiter, ok := r.pending[report.RequestID]
+	if !ok || waiter.probeID != probeID {
+		return false
+	}
+	delete(r.pending, report.RequestID)
+	waiter.ch <- report
+	return true
+}
+
+// handleProbeEffectivePolicy asks a connected probe for the policy it is
+// actually enforcing and compares it with the policy the control plane last
+// assigned, so drift (a stale push, a hand-edited config) is visible.
+func (s *Server) handleProbeEffectivePolicy(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+	id := r.PathValue("id")
+	ps, ok := s.probeForRequest(r, id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	if ps.Type == fleet.ProbeTypeRemote {
+		writeJSONError(w, http.StatusConflict, "unsupported_probe", "effective policy requires an agent probe")
+		return
+	}
+	if !s.hub.IsConnected(id) {
+		writeJSONError(w, http.StatusConflict, "probe_offline", "probe is not connected")
+		return
+	}
+
+	requestID := "policyq-" + uuid.NewString()
+	reports := s.policyQueries.add(requestID, id)
+	defer s.policyQueries.remove(requestID)
+
+	if err := s.hub.SendTo(id, protocol.MsgPolicyQuery, protocol.PolicyQueryPayload{RequestID: requestID}); err != nil {
+		writeJSONError(w, http.StatusBadGateway, "probe_unreachable", err.Error())
+		return
+	}
+
+	timer := time.NewTimer(policyQueryTimeout)
+	defer timer.Stop()
+	var report protocol.PolicyReportPayload
+	select {
+	case report = <-reports:
+	case <-timer.C:
+		writeJSONError(w, http.StatusGatewayTimeout, "probe_timeout", "probe did not report its policy in time")
+		return
+	case <-r.Context().Done():
+		return
+	}
+
+	recorded := s.recordedProbePolicy(ps)
+	differences := policyDifferences(recorded, &report.Policy)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"probe_id":    id,
+		"effective":   report.Policy,
+		"recorded":    recorded,
+		"drift":       len(differences) > 0,
+		"differences": differences,
+		"reported_at": time.Now().UTC(),
+	})
+}
+
+// recordedProbePolicy returns the policy the control plane expects the probe
+// to enforce: the template from its latest assignment, or just its level
+// when no template was ever assigned.
+func (s *Server) recordedProbePolicy(ps *fleet.ProbeState) *protocol.PolicyUpdatePayload {
+	if s.policyHistory != nil && s.policyStore != nil {
+		if latest := s.policyHistory.ListAssignments(ps.ID, 1); len(latest) == 1 {
+			if tpl, ok := s.policyStore.Get(latest[0].PolicyID); ok {
+				return tpl.ToPolicy()
+			}
+			s.logger.Debug("assigned policy template no longer exists",
+				zap.String("probe", ps.ID),
+				zap.String("policy_id", latest[0].PolicyID),
+			)
+		}
+	}
+	return &protocol.PolicyUpdatePayload{Level: ps.PolicyLevel}
+}
+
+// policyDifferences lists the fields where the probe's effective policy
+// diverges from the recorded one. Command and path lists compare as sets.
+// A recorded policy without an ID only pins the capability level.
+func policyDifferences(recorded, effective *protocol.PolicyUpdatePayload) []string {
+	diffs := []string{}
+	if recorded.Level != "" && recorded.Level != effective.Level {
+		diffs = append(diffs, fmt.Sprintf("level: recorded %s, effective %s", recorded.Level, effective.Level))
+	}
+	if recorded.PolicyID == "" {
+		return diffs
+	}
+	if recorded.PolicyID != effective.PolicyID {
+		diffs = append(diffs, fmt.Sprintf("policy_id: recorded %s, effective %s", recorded.PolicyID, orNone(effective.PolicyID)))
+	}
+	diffs = append(diffs, listDifferences("allowed", recorded.Allowed, effective.Allowed)...)
+	diffs = append(diffs, listDifferences("blocked", recorded.Blocked, effective.Blocked)...)
+	diffs = append(diffs, listDifferences("paths", recorded.Paths, effective.Paths)...)
+	diffs = append(diffs, listDifferences("work_dirs", recorded.WorkDirs, effective.WorkDirs)...)
+	diffs = append(diffs, listDifferences("env_keys", recorded.EnvKeys, effective.EnvKeys)...)
+	if recorded.MaxConcurrentCommands != effective.MaxConcurrentCommands {
+		diffs = append(diffs, fmt.Sprintf("max_concurrent_commands: recorded %d, effective %d", recorded.MaxConcurrentCommands, effective.MaxConcurrentCommands))
+	}
+	if recordedLimit, effectiveLimit := rateLimitString(recorded.RateLimit), rateLimitString(effective.RateLimit); recordedLimit != effectiveLimit {
+		diffs = append(diffs, fmt.Sprintf("rate_limit: recorded %s, effective %s", recordedLimit, effectiveLimit))
+	}
+	if recorded.HeartbeatIntervalSec != effective.HeartbeatIntervalSec {
+		diffs = append(diffs, fmt.Sprintf("heartbeat_interval_sec: recorded %d, effective %d", recorded.HeartbeatIntervalSec, effective.HeartbeatIntervalSec))
+	}
+	if recorded.InventoryIntervalSec != effective.InventoryIntervalSec {
+		diffs = append(diffs, fmt.Sprintf("inventory_interval_sec: recorded %d, effective %d", recorded.InventoryIntervalSec, effective.InventoryIntervalSec))
+	}
+	return diffs
+}
+
+func listDifferences(field string, recorded, effective []string) []string {
+	missing := setDifference(recorded, effective)
+	extra := setDifference(effective, recorded)
+	var diffs []string
+	if len(missing) > 0 {
+		diffs = append(diffs, fmt.Sprintf("%s: missing on probe %v", field, missing))
+	}
+	if len(extra) > 0 {
+		diffs = append(diffs, fmt.Sprintf("%s: not in recorded policy %v", field, extra))
+	}
+	return diffs
+}
+
+// setDifference returns the sorted entries of a that are not in b.
+func setDifference(a, b []string) []string {
+	in := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		in[v] = struct{}{}
+	}
+	var out []string
+	for _, v := range a {
+		if _, ok := in[v]; !ok {
+			out = append(out, v)
+			in[v] = struct{}{}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func orNone(v string) string {
+	if v == "" {
+		return "(none)"
+	}
+	return v
+}
+
+// rateLimitString renders a rate limit for comparison and diff messages.
+func rateLimitString(limit *protocol.CommandRateLimit) string {
+	if limit == nil || (limit.PerMinute == 0 && len(limit.Cooldowns) == 0) {
+		return "(none)"
+	}
+	data, _ := json.Marshal(limit)
+	return string(data)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestPolicyDifferences(t *testing.T) {
+	recorded := &protocol.PolicyUpdatePayload{
+		PolicyID: "diagnose",
+		Level:    protocol.CapDiagnose,
+		Allowed:  []string{"ls", "cat"},
+		Blocked:  []string{"rm"},
+	}
+
+	same := &protocol.PolicyUpdatePayload{PolicyID: "diagnose", Level: protocol.CapDiagnose, Allowed: []string{"cat", "ls"}, Blocked: []string{"rm"}}
+	if diffs := policyDifferences(recorded, same); len(diffs) != 0 {
+		t.Fatalf("expected no drift for reordered lists, got %v", diffs)
+	}
+
+	drifted := &protocol.PolicyUpdatePayload{Level: protocol.CapRemediate, Allowed: []string{"ls", "systemctl"}}
+	diffs := policyDifferences(recorded, drifted)
+	want := []string{
+		"level: recorded diagnose, effective remediate",
+		"policy_id: recorded diagnose, effective (none)",
+		"allowed: missing on probe [cat]",
+		"allowed: not in recorded policy [systemctl]",
+		"blocked: missing on probe [rm]",
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("got %v, want %v", diffs, want)
+	}
+	for i := range want {
+		if diffs[i] != want[i] {
+			t.Fatalf("got %v, want %v", diffs, want)
+		}
+	}
+
+	// Without an assigned template only the level is compared.
+	levelOnly := &protocol.PolicyUpdatePayload{Level: protocol.CapDiagnose}
+	if diffs := policyDifferences(levelOnly, drifted); len(diffs) != 1 {
+		t.Fatalf("expected only a level difference, got %v", diffs)
+	}
+}
+
+func TestPolicyQueryRegistryDeliver(t *testing.T) {
+	var reg policyQueryRegistry
+	reports := reg.add("policyq-1", "probe-a")
+
+	if reg.deliver("probe-b", protocol.PolicyReportPayload{RequestID: "policyq-1"}) {
+		t.Fatal("report from another probe must be dropped")
+	}
+	if reg.deliver("probe-a", protocol.PolicyReportPayload{RequestID: "policyq-unknown"}) {
+		t.Fatal("report for unknown request must be dropped")
+	}
+	if !reg.deliver("probe-a", protocol.PolicyReportPayload{RequestID: "policyq-1", Policy: protocol.PolicyUpdatePayload{Level: protocol.CapObserve}}) {
+		t.Fatal("expected report to be delivered")
+	}
+	if got := <-reports; got.Policy.Level != protocol.CapObserve {
+		t.Fatalf("unexpected report %+v", got)
+	}
+	if reg.deliver("probe-a", protocol.PolicyReportPayload{RequestID: "policyq-1"}) {
+		t.Fatal("a request is answered at most once")
+	}
+}
+
+func TestHandleProbeEffectivePolicyRequiresConnectedProbe(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-eff", "host", "linux", "amd64")
+
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/probes/"+id+"/policy/effective", nil)
+		req.SetPathValue("id", id)
+		rr := httptest.NewRecorder()
+		srv.handleProbeEffectivePolicy(rr, req)
+		return rr
+	}
+
+	if rr := get("probe-missing"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown probe, got %d", rr.Code)
+	}
+	if rr := get("probe-eff"); rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for disconnected probe, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	coreapprovalpolicy "github.com/marcus-qen/legator/internal/controlplane/core/approvalpolicy"
+	"github.com/marcus-qen/legator/internal/controlplane/policy"
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPolicyHistoryLimit = 50
+	maxPolicyHistoryLimit     = 200
+)
+
+// applyProbePolicy applies a policy template to a probe, records the
+// assignment (with the state it replaced) in the probe's policy history and
+// writes the HTTP response.
+func (s *Server) applyProbePolicy(w http.ResponseWriter, r *http.Request, probeID, policyID, action string) {
+	result, err := s.approvalCore.ApplyPolicyTemplate(probeID, policyID, s.pushPolicyUpdate)
+	if err != nil {
+		switch {
+		case errors.Is(err, coreapprovalpolicy.ErrProbeNotFound):
+			writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		case errors.Is(err, coreapprovalpolicy.ErrPolicyTemplateNotFound):
+			writeJSONError(w, http.StatusNotFound, "not_found", "policy template not found")
+		default:
+			writeJSONError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		}
+		return
+	}
+
+	actor := actorFromAuthContext(r.Context())
+	s.recordPolicyAssignment(probeID, action, actor, result)
+
+	if !result.Pushed {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"status": "applied_locally",
+			"note":   "probe offline, policy saved but not pushed",
+		})
+		return
+	}
+
+	summary := fmt.Sprintf("Policy %s (%s) applied", result.Template.Name, result.Template.ID)
+	if action == policy.AssignmentRollback {
+		summary = fmt.Sprintf("Policy rolled back to %s (%s)", result.Template.Name, result.Template.ID)
+	}
+	s.emitAudit(audit.EventPolicyChanged, probeID, actor, summary)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"status":    "applied",
+		"probe_id":  probeID,
+		"policy_id": result.Template.ID,
+		"level":     string(result.Template.Level),
+	})
+}
+
+// pushPolicyUpdate sends an applied policy to a connected probe.
+func (s *Server) pushPolicyUpdate(probeID string, pol *protocol.PolicyUpdatePayload) error {
+	if err := s.hub.SendTo(probeID, protocol.MsgPolicyUpdate, pol); err != nil {
+		return err
+	}
+	s.trackPushedHeartbeatInterval(probeID, pol.HeartbeatIntervalSec)
+	return nil
+}
+
+// recordPolicyAssignment appends an assignment to the probe's policy history.
+// The core only knows the previous template since start-up, so the latest
+// recorded assignment fills the gap after a restart.
+func (s *Server) recordPolicyAssignment(probeID, action, actor string, result *coreapprovalpolicy.PolicyApplyResult) {
+	if s.policyHistory == nil || result == nil || result.Template == nil {
+		return
+	}
+	previousPolicyID := result.PreviousPolicyID
+	if previousPolicyID == "" {
+		if latest := s.policyHistory.ListAssignments(probeID, 1); len(latest) == 1 {
+			previousPolicyID = latest[0].PolicyID
+		}
+	}
+	if _, err := s.policyHistory.RecordAssignment(policy.Assignment{
+		ProbeID:          probeID,
+		Action:           action,
+		PolicyID:         result.Template.ID,
+		PolicyName:       result.Template.Name,
+		Level:            result.Template.Level,
+		PreviousPolicyID: previousPolicyID,
+		PreviousLevel:    result.PreviousLevel,
+		Pushed:           result.Pushed,
+		Actor:            actor,
+	}); err != nil {
+		s.logger.Warn("failed to record policy assignment", zap.String("probe", probeID), zap.Error(err))
+	}
+}
+
+func (s *Server) handleProbePolicyHistory(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+	id := r.PathValue("id")
+	if _, ok := s.probeForRequest(r, id); !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	limit := defaultPolicyHistoryLimit
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxPolicyHistoryLimit {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("limit must be between 1 and %d", maxPolicyHistoryLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	history := []*policy.Assignment{}
+	if s.policyHistory != nil {
+		history = s.policyHistory.ListAssignments(id, limit)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"probe_id": id,
+		"history":  history,
+		"count":    len(history),
+	})
+}
+
+// handleProbePolicyRollback re-applies the policy template that was in place
+// before the probe's most recent assignment.
+func (s *Server) handleProbePolicyRollback(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetWrite) {
+		return
+	}
+	id := r.PathValue("id")
+	if _, ok := s.probeForRequest(r, id); !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	if s.policyHistory == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "policy history unavailable")
+		return
+	}
+	latest := s.policyHistory.ListAssignments(id, 1)
+	if len(latest) == 0 {
+		writeJSONError(w, http.StatusConflict, "no_policy_history", "probe has no policy assignments to roll back")
+		return
+	}
+	target := latest[0].PreviousPolicyID
+	if target == "" {
+		writeJSONError(w, http.StatusConflict, "no_previous_policy", "no previous policy template recorded for this probe")
+		return
+	}
+	if _, ok := s.policyStore.Get(target); !ok {
+		writeJSONError(w, http.StatusConflict, "previous_policy_missing", fmt.Sprintf("previous policy template %s no longer exists", target))
+		return
+	}
+	s.applyProbePolicy(w, r, id, target, policy.AssignmentRollback)
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/policy"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestProbePolicyHistoryAndRollback(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-hist", "host", "linux", "amd64")
+
+	apply := func(policyID string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/probes/probe-hist/apply-policy/"+policyID, nil)
+		req.SetPathValue("id", "probe-hist")
+		req.SetPathValue("policyId", policyID)
+		rr := httptest.NewRecorder()
+		srv.handleApplyPolicy(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("apply %s: status=%d body=%s", policyID, rr.Code, rr.Body.String())
+		}
+	}
+	rollback := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/probes/probe-hist/policy/rollback", nil)
+		req.SetPathValue("id", "probe-hist")
+		rr := httptest.NewRecorder()
+		srv.handleProbePolicyRollback(rr, req)
+		return rr
+	}
+
+	if rr := rollback(); rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 without history, got %d", rr.Code)
+	}
+
+	apply("diagnose")
+	if rr := rollback(); rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 when no previous template, got %d body=%s", rr.Code, rr.Body.String())
+	}
+
+	apply("full-remediate")
+	if ps, _ := srv.fleetMgr.Get("probe-hist"); ps.PolicyLevel != protocol.CapRemediate {
+		t.Fatalf("expected remediate before rollback, got %s", ps.PolicyLevel)
+	}
+	if rr := rollback(); rr.Code != http.StatusOK {
+		t.Fatalf("rollback status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	if ps, _ := srv.fleetMgr.Get("probe-hist"); ps.PolicyLevel != protocol.CapDiagnose {
+		t.Fatalf("expected rollback to diagnose, got %s", ps.PolicyLevel)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/probes/probe-hist/policy/history", nil)
+	req.SetPathValue("id", "probe-hist")
+	rr := httptest.NewRecorder()
+	srv.handleProbePolicyHistory(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("history status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	var got struct {
+		History []policy.Assignment `json:"history"`
+		Count   int                 `json:"count"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Count != 3 {
+		t.Fatalf("expected 3 history entries, got %d", got.Count)
+	}
+	newest := got.History[0]
+	if newest.Action != policy.AssignmentRollback || newest.PolicyID != "diagnose" || newest.PreviousPolicyID != "full-remediate" || newest.PreviousLevel != protocol.CapRemediate {
+		t.Fatalf("unexpected rollback entry %+v", newest)
+	}
+	first := got.History[2]
+	if first.PolicyID != "diagnose" || first.PreviousLevel != protocol.CapObserve || first.PreviousPolicyID != "" {
+		t.Fatalf("unexpected first entry %+v", first)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/probes/probe-hist/policy/history?limit=0", nil)
+	req.SetPathValue("id", "probe-hist")
+	rr = httptest.NewRecorder()
+	srv.handleProbePolicyHistory(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad limit, got %d", rr.Code)
+	}
+}
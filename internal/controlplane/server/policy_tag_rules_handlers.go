@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/controlplane/policy"
+	"go.uber.org/zap"
+)
+
+// ── Tag-based policy assignment rules ───────────────────────
+
+func (s *Server) handleListPolicyTagRules(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+	rules := s.policyTagRules.ListTagRules()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"rules": rules,
+		"total": len(rules),
+	})
+}
+
+func (s *Server) handleCreatePolicyTagRule(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetWrite) {
+		return
+	}
+	var body struct {
+		Name     string `json:"name"`
+		Tag      string `json:"tag"`
+		PolicyID string `json:"policy_id"`
+		Priority int    `json:"priority"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+		return
+	}
+
+	actor := actorFromAuthContext(r.Context())
+	rule, err := s.policyTagRules.AddTagRule(policy.TagRule{
+		Name:      body.Name,
+		Tag:       body.Tag,
+		PolicyID:  body.PolicyID,
+		Priority:  body.Priority,
+		CreatedBy: actor,
+	})
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	s.recordAudit(audit.Event{
+		Type:    audit.EventPolicyTagRuleChanged,
+		Actor:   actor,
+		Summary: fmt.Sprintf("Policy tag rule created: %s", rule.Name),
+		Detail: map[string]any{
+			"action":    "created",
+			"rule_id":   rule.ID,
+			"tag":       rule.Tag,
+			"policy_id": rule.PolicyID,
+			"priority":  rule.Priority,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(rule)
+}
+
+func (s *Server) handleDeletePolicyTagRule(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetWrite) {
+		return
+	}
+	id := r.PathValue("id")
+	rule, ok := s.policyTagRules.GetTagRule(id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "policy tag rule not found")
+		return
+	}
+	if err := s.policyTagRules.DeleteTagRule(id); err != nil {
+		writeJSONError(w, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+
+	s.recordAudit(audit.Event{
+		Type:    audit.EventPolicyTagRuleChanged,
+		Actor:   actorFromAuthContext(r.Context()),
+		Summary: fmt.Sprintf("Policy tag rule deleted: %s", rule.Name),
+		Detail: map[string]any{
+			"action":  "deleted",
+			"rule_id": rule.ID,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// assignPolicyByTags is the registration hook that applies the policy of the
+// first tag rule matching a new probe's tags. It returns the applied policy
+// ID, or "" when no rule matches or the rule's template is gone.
+func (s *Server) assignPolicyByTags(probeID string, tags []string) string {
+	if s.policyTagRules == nil || s.approvalCore == nil {
+		return ""
+	}
+	rule, ok := s.policyTagRules.MatchTagRule(tags)
+	if !ok {
+		return ""
+	}
+	result, err := s.approvalCore.ApplyPolicyTemplate(probeID, rule.PolicyID, s.pushPolicyUpdate)
+	if err != nil {
+		s.logger.Warn("tag rule policy not applied",
+			zap.String("probe", probeID),
+			zap.String("rule_id", rule.ID),
+			zap.String("policy_id", rule.PolicyID),
+			zap.Error(err),
+		)
+		return ""
+	}
+
+	s.recordPolicyAssignment(probeID, policy.AssignmentApply, "system", result)
+	s.recordAudit(audit.Event{
+		Type:    audit.EventPolicyChanged,
+		ProbeID: probeID,
+		Actor:   "system",
+		Summary: fmt.Sprintf("Policy %s (%s) applied by tag rule %s", result.Template.Name, result.Template.ID, rule.Name),
+		Detail: map[string]any{
+			"rule_id":   rule.ID,
+			"rule_name": rule.Name,
+			"tag":       rule.Tag,
+			"policy_id": result.Template.ID,
+			"level":     string(result.Template.Level),
+			"pushed":    result.Pushed,
+		},
+	})
+	return result.Template.ID
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/policy"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestRegisterAppliesPolicyFromTagRule(t *testing.T) {
+	srv := newTestServer(t)
+	tpl := srv.policyStore.Create("Database", "db hosts", protocol.CapDiagnose, nil, nil, nil, policy.TemplateOptions{})
+
+	resp := makeRequest(t, srv, http.MethodPost, "/api/v1/policy-tag-rules", "", `{"tag":"role=db","policy_id":"`+tpl.ID+`"}`)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("create rule: expected 201, got %d body=%s", resp.Code, resp.Body.String())
+	}
+	if resp := makeRequest(t, srv, http.MethodPost, "/api/v1/policy-tag-rules", "", `{"tag":"role=db","policy_id":"missing"}`); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown policy, got %d", resp.Code)
+	}
+
+	register := func(hostname, tags string) (string, string) {
+		t.Helper()
+		tok := srv.tokenStore.Generate()
+		body := `{"token":"` + tok.Value + `","hostname":"` + hostname + `","os":"linux","arch":"amd64","tags":` + tags + `}`
+		resp := makeRequest(t, srv, http.MethodPost, "/api/v1/register", "", body)
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("register: expected 201, got %d body=%s", resp.Code, resp.Body.String())
+		}
+		var out struct {
+			ProbeID  string `json:"probe_id"`
+			PolicyID string `json:"policy_id"`
+		}
+		_ = json.Unmarshal(resp.Body.Bytes(), &out)
+		return out.ProbeID, out.PolicyID
+	}
+
+	dbProbe, policyID := register("db-1", `["env=prod","ROLE=db"]`)
+	if policyID != tpl.ID {
+		t.Fatalf("expected tag rule policy %q, got %q", tpl.ID, policyID)
+	}
+	if ps, _ := srv.fleetMgr.Get(dbProbe); ps.PolicyLevel != protocol.CapDiagnose {
+		t.Fatalf("expected diagnose level from tag rule, got %q", ps.PolicyLevel)
+	}
+	if history := srv.policyHistory.ListAssignments(dbProbe, 1); len(history) != 1 || history[0].PolicyID != tpl.ID {
+		t.Fatalf("expected recorded assignment, got %+v", history)
+	}
+	evts := srv.queryAudit(audit.Filter{ProbeID: dbProbe, Type: audit.EventPolicyChanged, Limit: 10})
+	if len(evts) != 1 || !strings.Contains(evts[0].Summary, "tag rule") {
+		t.Fatalf("expected policy.changed audit event for tag rule, got %+v", evts)
+	}
+
+	webProbe, policyID := register("web-1", `["role=web"]`)
+	if policyID != "default-observe" {
+		t.Fatalf("expected default policy without a matching rule, got %q", policyID)
+	}
+	if ps, _ := srv.fleetMgr.Get(webProbe); ps.PolicyLevel != protocol.CapObserve {
+		t.Fatalf("expected observe level, got %q", ps.PolicyLevel)
+	}
+}
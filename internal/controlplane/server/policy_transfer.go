@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	controlpolicy "github.com/marcus-qen/legator/internal/controlplane/policy"
+)
+
+// ── Policy template export / import ─────────────────────────
+
+const (
+	policyExportVersion  = 1
+	maxPolicyImportSize  = 4 << 20
+	maxPolicyImportItems = 500
+	policyImportCreated  = "created"
+	policyImportUpdated  = "updated"
+	policyImportRejected = "invalid"
+)
+
+// policyExport is the document returned by export and accepted by import.
+type policyExport struct {
+	Version    int                       `json:"version"`
+	ExportedAt time.Time                 `json:"exported_at"`
+	Templates  []*controlpolicy.Template `json:"templates"`
+}
+
+// policyImportTemplate is one template in an import. Fields an export
+// carries but import ignores (created_at, updated_at) are dropped.
+type policyImportTemplate struct {
+	ID string `json:"id"`
+	policyTemplateRequest
+
+	RuntimeClass        string   `json:"runtime_class"`
+	CPUMillis           int      `json:"cpu_millis"`
+	MemoryMiB           int      `json:"memory_mib"`
+	AllowedCapabilities []string `json:"allowed_capabilities"`
+}
+
+// policyImportResult reports the outcome for one imported template.
+type policyImportResult struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// handleExportPolicies returns every policy template in a document that
+// import accepts unchanged, so templates can be version-controlled and
+// promoted between control planes.
+func (s *Server) handleExportPolicies(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+	templates := s.policyStore.List()
+	sort.Slice(templates, func(i, j int) bool { return templates[i].ID < templates[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="legator-policies-%s.json"`, time.Now().UTC().Format("20060102")))
+	_ = json.NewEncoder(w).Encode(policyExport{
+		Version:    policyExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Templates:  templates,
+	})
+}
+
+// handleImportPolicies creates or updates templates by ID. Each template is
+// validated on its own; invalid ones are reported and skipped while the
+// rest are applied.
+func (s *Server) handleImportPolicies(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetWrite) {
+		return
+	}
+	var body struct {
+		Version   int                    `json:"version"`
+		Templates []policyImportTemplate `json:"templates"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPolicyImportSize)).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+		return
+	}
+	if body.Version > policyExportVersion {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("unsupported export version %d", body.Version))
+		return
+	}
+	if len(body.Templates) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "templates required")
+		return
+	}
+	if len(body.Templates) > maxPolicyImportItems {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("at most %d templates can be imported at once", maxPolicyImportItems))
+		return
+	}
+
+	results := make([]policyImportResult, 0, len(body.Templates))
+	counts := map[string]int{}
+	seen := map[string]struct{}{}
+	for i, item := range body.Templates {
+		item.ID = strings.TrimSpace(item.ID)
+		result := policyImportResult{Index: i, ID: item.ID, Name: item.Name}
+		if err := validatePolicyImport(item, seen); err != nil {
+			result.Status = policyImportRejected
+			result.Error = err.Error()
+			results = append(results, result)
+			counts[result.Status]++
+			continue
+		}
+		opts, err := item.templateOptions()
+		if err != nil {
+			result.Status = policyImportRejected
+			result.Error = err.Error()
+			results = append(results, result)
+			counts[result.Status]++
+			continue
+		}
+		opts.RuntimeClass = item.RuntimeClass
+		opts.CPUMillis = item.CPUMillis
+		opts.MemoryMiB = item.MemoryMiB
+		opts.AllowedCapabilities = item.AllowedCapabilities
+
+		tpl, created := s.policyStore.Upsert(item.ID, item.Name, item.Description, item.Level, item.Allowed, item.Blocked, item.Paths, opts)
+		result.ID = tpl.ID
+		result.Status = policyImportUpdated
+		if created {
+			result.Status = policyImportCreated
+		}
+		results = append(results, result)
+		counts[result.Status]++
+	}
+
+	s.recordAudit(audit.Event{
+		Type:  audit.EventPolicyChanged,
+		Actor: actorFromAuthContext(r.Context()),
+		Summary: fmt.Sprintf("Policy templates imported: %d created, %d updated, %d invalid",
+			counts[policyImportCreated], counts[policyImportUpdated], counts[policyImportRejected]),
+		Detail: map[string]any{
+			"action":  "imported",
+			"results": results,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"created": counts[policyImportCreated],
+		"updated": counts[policyImportUpdated],
+		"invalid": counts[policyImportRejected],
+		"results": results,
+	})
+}
+
+// validatePolicyImport checks the fields create leaves to defaults, which an
+// import must state explicitly, and rejects IDs repeated within one import.
+func validatePolicyImport(item policyImportTemplate, seen map[string]struct{}) error {
+	if strings.TrimSpace(item.Name) == "" {
+		return fmt.Errorf("name required")
+	}
+	if err := controlpolicy.ValidateLevel(item.Level); err != nil {
+		return err
+	}
+	if item.ID == "" {
+		return nil
+	}
+	if err := controlpolicy.ValidateTemplateID(item.ID); err != nil {
+		return err
+	}
+	if _, dup := seen[item.ID]; dup {
+		return fmt.Errorf("duplicate template id %s in import", item.ID)
+	}
+	seen[item.ID] = struct{}{}
+	return nil
+}
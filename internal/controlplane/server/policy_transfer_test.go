@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/policy"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestPolicyExportImportRoundTrip(t *testing.T) {
+	staging := newTestServer(t)
+	staging.policyStore.Create("App servers", "promoted from staging", protocol.CapDiagnose,
+		[]string{"systemctl status"}, []string{"rm -rf"}, []string{"/etc"},
+		policy.TemplateOptions{MaxConcurrentCommands: 2, WorkDirs: []string{"/opt/app"}})
+
+	resp := makeRequest(t, staging, http.MethodGet, "/api/v1/policies/export", "", "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("export: expected 200, got %d body=%s", resp.Code, resp.Body.String())
+	}
+	exported := resp.Body.String()
+	var doc policyExport
+	if err := json.Unmarshal([]byte(exported), &doc); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if doc.Version != policyExportVersion || len(doc.Templates) != len(staging.policyStore.List()) {
+		t.Fatalf("unexpected export document: version=%d templates=%d", doc.Version, len(doc.Templates))
+	}
+
+	prod := newTestServer(t)
+	resp = makeRequest(t, prod, http.MethodPost, "/api/v1/policies/import", "", exported)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("import: expected 200, got %d body=%s", resp.Code, resp.Body.String())
+	}
+	var out struct {
+		Created int                  `json:"created"`
+		Updated int                  `json:"updated"`
+		Invalid int                  `json:"invalid"`
+		Results []policyImportResult `json:"results"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &out); err != nil {
+		t.Fatalf("decode import response: %v", err)
+	}
+	if out.Created != 1 || out.Invalid != 0 || out.Updated != len(doc.Templates)-1 {
+		t.Fatalf("expected 1 created and the builtins updated, got %+v", out)
+	}
+
+	var imported *policy.Template
+	for _, tpl := range prod.policyStore.List() {
+		if tpl.Name == "App servers" {
+			imported = tpl
+		}
+	}
+	if imported == nil {
+		t.Fatal("imported template not found")
+	}
+	if imported.Level != protocol.CapDiagnose || imported.MaxConcurrentCommands != 2 ||
+		len(imported.WorkDirs) != 1 || imported.WorkDirs[0] != "/opt/app" || len(imported.Blocked) != 1 {
+		t.Fatalf("template fields not carried over: %+v", imported)
+	}
+	if evts := prod.queryAudit(audit.Filter{Type: audit.EventPolicyChanged, Limit: 10}); len(evts) != 1 || !strings.Contains(evts[0].Summary, "1 created") {
+		t.Fatalf("expected import audit event, got %+v", evts)
+	}
+}
+
+func TestPolicyImportReportsInvalidTemplates(t *testing.T) {
+	srv := newTestServer(t)
+	body := `{"templates":[
+		{"id":"pol-900","name":"ok","level":"observe"},
+		{"id":"pol-901","name":"bad level","level":"root"},
+		{"id":"pol-902","name":"bad cadence","level":"observe","heartbeat_interval_sec":1},
+		{"id":"pol-900","name":"duplicate","level":"observe"},
+		{"id":"../etc","name":"bad id","level":"observe"},
+		{"name":"no id","level":"remediate","work_dirs":["relative/dir"]}
+	]}`
+	resp := makeRequest(t, srv, http.MethodPost, "/api/v1/policies/import", "", body)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("import: expected 200, got %d body=%s", resp.Code, resp.Body.String())
+	}
+	var out struct {
+		Created int                  `json:"created"`
+		Invalid int                  `json:"invalid"`
+		Results []policyImportResult `json:"results"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &out); err != nil {
+		t.Fatalf("decode import response: %v", err)
+	}
+	if out.Created != 1 || out.Invalid != 5 {
+		t.Fatalf("expected 1 created and 5 invalid, got %+v", out)
+	}
+	wantErrors := []string{"", "level must be", "heartbeat_interval_sec", "duplicate template id", "invalid template id", "must be an absolute path"}
+	for i, want := range wantErrors {
+		if got := out.Results[i]; (want == "" && got.Status != policyImportCreated) || !strings.Contains(got.Error, want) {
+			t.Errorf("result %d: got %+v, want error containing %q", i, got, want)
+		}
+	}
+	if _, ok := srv.policyStore.Get("pol-901"); ok {
+		t.Fatal("invalid template should not be stored")
+	}
+
+	if resp := makeRequest(t, srv, http.MethodPost, "/api/v1/policies/import", "", `{"version":2,"templates":[]}`); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported version, got %d", resp.Code)
+	}
+}
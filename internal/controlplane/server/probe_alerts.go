@@ -0,0 +1,58 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/events"
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+// handleProbeAlert feeds a probe-pushed condition to the alert engine and
+// records it. Duplicates of an alert that is already active are dropped
+// before they reach audit or the event bus.
+func (s *Server) handleProbeAlert(probeID string, alert protocol.AlertPayload) {
+	if s.alertEngine != nil {
+		result, err := s.alertEngine.IngestProbeAlert(probeID, alert)
+		if err != nil {
+			s.logger.Warn("probe alert rejected", zap.String("probe", probeID), zap.Error(err))
+			return
+		}
+		if result.Duplicate {
+			s.logger.Debug("duplicate probe alert ignored",
+				zap.String("probe", probeID),
+				zap.String("condition", alert.Condition),
+				zap.String("subject", alert.Subject),
+			)
+			return
+		}
+	}
+	if alert.Status == "" {
+		alert.Status = "firing"
+	}
+	if alert.ObservedAt.IsZero() {
+		alert.ObservedAt = time.Now().UTC()
+	}
+
+	summary := fmt.Sprintf("Probe alert %s: %s", alert.Status, strings.TrimSpace(alert.Condition+" "+alert.Subject))
+	if alert.Message != "" {
+		summary += " (" + alert.Message + ")"
+	}
+	s.recordAudit(audit.Event{
+		Type:    audit.EventProbeAlert,
+		ProbeID: probeID,
+		Actor:   probeID,
+		Summary: summary,
+		Detail:  alert,
+	})
+	s.publishEvent(events.ProbeAlert, probeID, summary, alert)
+	s.logger.Info("probe alert received",
+		zap.String("probe", probeID),
+		zap.String("condition", alert.Condition),
+		zap.String("subject", alert.Subject),
+		zap.String("status", alert.Status),
+	)
+}
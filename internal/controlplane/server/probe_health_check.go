@@ -0,0 +1,306 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	corecommanddispatch "github.com/marcus-qen/legator/internal/controlplane/core/commanddispatch"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+const (
+	healthCheckCommandTimeout = 15 * time.Second
+	maxHealthCheckServices    = 16
+)
+
+// healthCheckServiceName bounds service names to systemd unit characters so
+// they pass through as plain arguments.
+var healthCheckServiceName = regexp.MustCompile(`^[A-Za-z0-9@._:-]+$`)
+
+// probeHealthCheck is the response of an on-demand health check.
+type probeHealthCheck struct {
+	ProbeID   string            `json:"probe_id"`
+	CheckedAt time.Time         `json:"checked_at"`
+	Health    fleet.HealthScore `json:"health"`
+	Uptime    int64             `json:"uptime_seconds"`
+	Load      [3]float64        `json:"load_avg"`
+	MemUsed   uint64            `json:"mem_used_bytes"`
+	MemTotal  uint64            `json:"mem_total_bytes"`
+	DiskUsed  uint64            `json:"disk_used_bytes"`
+	DiskTotal uint64            `json:"disk_total_bytes"`
+	Services  map[string]string `json:"services,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// handleProbeHealthCheck runs a bundled set of observe-level diagnostics on
+// the probe and returns a freshly computed health score, which also becomes
+// the probe's current health.
+func (s *Server) handleProbeHealthCheck(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermCommandExec) {
+		return
+	}
+	id := r.PathValue("id")
+	ps, ok := s.probeForRequest(r, id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	if strings.EqualFold(ps.OS, "windows") {
+		writeJSONError(w, http.StatusConflict, "unsupported_probe", "health check requires a Linux probe")
+		return
+	}
+	if ps.Draining {
+		writeJSONError(w, http.StatusConflict, "probe_draining", "probe is draining; undrain it to run a health check")
+		return
+	}
+
+	var body struct {
+		Services []string `json:"services"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+		return
+	}
+	if len(body.Services) > maxHealthCheckServices {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("at most %d services can be checked", maxHealthCheckServices))
+		return
+	}
+	for _, name := range body.Services {
+		if !healthCheckServiceName.MatchString(name) {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("invalid service name %q", name))
+			return
+		}
+	}
+
+	check, err := s.runProbeHealthCheck(r.Context(), ps, body.Services)
+	if err != nil {
+		writeJSONError(w, http.StatusBadGateway, "bad_gateway", err.Error())
+		return
+	}
+
+	s.recordHealthSample(id)
+	s.recordAudit(audit.Event{
+		Type:    audit.EventCommandSent,
+		ProbeID: id,
+		Actor:   actorFromAuthContext(r.Context()),
+		Summary: fmt.Sprintf("On-demand health check: %s (score %d)", check.Health.Status, check.Health.Score),
+		Detail: map[string]any{
+			"score":    check.Health.Score,
+			"status":   check.Health.Status,
+			"warnings": check.Health.Warnings,
+			"services": check.Services,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(check)
+}
+
+// runProbeHealthCheck runs the diagnostic commands concurrently and scores
+// what they report. Resource readings are required; a failed service query
+// is reported in Errors without failing the check.
+func (s *Server) runProbeHealthCheck(ctx context.Context, ps *fleet.ProbeState, services []string) (*probeHealthCheck, error) {
+	commands := map[string]protocol.CommandPayload{
+		"system": {Command: "cat", Args: []string{"/proc/uptime", "/proc/loadavg", "/proc/meminfo"}},
+		"disk":   {Command: "df", Args: []string{"-P", "-k", "/"}},
+	}
+	if len(services) > 0 {
+		commands["services"] = protocol.CommandPayload{Command: "systemctl", Args: append([]string{"is-active"}, services...)}
+	}
+
+	type outcome struct {
+		result *protocol.CommandResultPayload
+		err    error
+	}
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		outcomes = make(map[string]outcome, len(commands))
+	)
+	for name, cmd := range commands {
+		cmd.RequestID = fmt.Sprintf("hc-%s-%s-%d", name, ps.ID, time.Now().UnixNano()%100000)
+		cmd.Level = protocol.CapObserve
+		cmd.Timeout = healthCheckCommandTimeout
+		wg.Add(1)
+		go func(name string, cmd protocol.CommandPayload) {
+			defer wg.Done()
+			result, err := s.runHealthCheckCommand(ctx, ps, cmd)
+			mu.Lock()
+			outcomes[name] = outcome{result: result, err: err}
+			mu.Unlock()
+		}(name, cmd)
+	}
+	wg.Wait()
+
+	check := &probeHealthCheck{ProbeID: ps.ID, CheckedAt: time.Now().UTC()}
+	hb := &protocol.HeartbeatPayload{ProbeID: ps.ID}
+	for _, name := range []string{"system", "disk"} {
+		o := outcomes[name]
+		if o.err != nil {
+			return nil, fmt.Errorf("health check %s: %w", name, o.err)
+		}
+		if o.result.ExitCode != 0 {
+			return nil, fmt.Errorf("health check %s: exit %d: %s", name, o.result.ExitCode, strings.TrimSpace(o.result.Stderr))
+		}
+	}
+	if err := parseHealthCheckSystem(outcomes["system"].result.Stdout, hb); err != nil {
+		return nil, err
+	}
+	if err := parseHealthCheckDisk(outcomes["disk"].result.Stdout, hb); err != nil {
+		return nil, err
+	}
+
+	var down []string
+	if len(services) > 0 {
+		if o := outcomes["services"]; o.err != nil {
+			check.Errors = map[string]string{"services": o.err.Error()}
+		} else {
+			// is-active prints one state per unit, in order, and exits
+			// non-zero when any is not active.
+			states := strings.Fields(o.result.Stdout)
+			check.Services = make(map[string]string, len(services))
+			for i, name := range services {
+				state := "unknown"
+				if i < len(states) {
+					state = states[i]
+				}
+				check.Services[name] = state
+				if state != "active" {
+					down = append(down, name)
+				}
+			}
+		}
+	}
+
+	health, err := s.fleetMgr.RecordHealthCheck(ps.ID, hb, down)
+	if err != nil {
+		return nil, err
+	}
+	s.recordResourceSample(ps.ID, hb)
+	check.Health = health
+	check.Uptime = hb.Uptime
+	check.Load = hb.Load
+	check.MemUsed, check.MemTotal = hb.MemUsed, hb.MemTotal
+	check.DiskUsed, check.DiskTotal = hb.DiskUsed, hb.DiskTotal
+	return check, nil
+}
+
+// runHealthCheckCommand runs one diagnostic command on an agent probe over
+// its WebSocket, or on a remote probe over SSH.
+func (s *Server) runHealthCheckCommand(ctx context.Context, ps *fleet.ProbeState, cmd protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+	if strings.EqualFold(ps.Type, fleet.ProbeTypeRemote) {
+		if s.remoteExecutor == nil {
+			return nil, fmt.Errorf("remote executor unavailable")
+		}
+		execCtx, cancel := context.WithTimeout(ctx, cmd.Timeout+5*time.Second)
+		defer cancel()
+		return s.remoteExecutor.Execute(execCtx, ps, cmd, nil)
+	}
+	envelope := s.dispatchCore.DispatchWithPolicy(ctx, ps.ID, cmd, corecommanddispatch.WaitPolicy(cmd.Timeout+5*time.Second))
+	if envelope == nil {
+		return nil, corecommanddispatch.ErrEmptyResult
+	}
+	if envelope.Err != nil {
+		return nil, envelope.Err
+	}
+	if envelope.Result == nil {
+		return nil, corecommanddispatch.ErrEmptyResult
+	}
+	return envelope.Result, nil
+}
+
+// parseHealthCheckSystem reads `cat /proc/uptime /proc/loadavg /proc/meminfo`
+// output: the uptime line, the loadavg line, then meminfo fields.
+func parseHealthCheckSystem(out string, hb *protocol.HeartbeatPayload) error {
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	var lines []string
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) < 3 {
+		return fmt.Errorf("health check: unexpected system output")
+	}
+
+	if fields := strings.Fields(lines[0]); len(fields) > 0 {
+		if up, err := strconv.ParseFloat(fields[0], 64); err == nil {
+			hb.Uptime = int64(up)
+		}
+	}
+	load := strings.Fields(lines[1])
+	if len(load) < 3 {
+		return fmt.Errorf("health check: unexpected loadavg %q", lines[1])
+	}
+	for i := 0; i < 3; i++ {
+		v, err := strconv.ParseFloat(load[i], 64)
+		if err != nil {
+			return fmt.Errorf("health check: unexpected loadavg %q", lines[1])
+		}
+		hb.Load[i] = v
+	}
+
+	meminfo := map[string]uint64{}
+	for _, line := range lines[2:] {
+		key, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		if kb, err := strconv.ParseUint(fields[0], 10, 64); err == nil {
+			meminfo[key] = kb * 1024
+		}
+	}
+	total := meminfo["MemTotal"]
+	if total == 0 {
+		return fmt.Errorf("health check: MemTotal missing from meminfo")
+	}
+	available, ok := meminfo["MemAvailable"]
+	if !ok {
+		available = meminfo["MemFree"] + meminfo["Buffers"] + meminfo["Cached"]
+	}
+	hb.MemTotal = total
+	if available < total {
+		hb.MemUsed = total - available
+	}
+	return nil
+}
+
+// parseHealthCheckDisk reads POSIX `df -P -k /` output.
+func parseHealthCheckDisk(out string, hb *protocol.HeartbeatPayload) error {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 2 {
+		return fmt.Errorf("health check: unexpected df output")
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return fmt.Errorf("health check: unexpected df output %q", lines[len(lines)-1])
+	}
+	total, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("health check: unexpected df output %q", lines[len(lines)-1])
+	}
+	used, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("health check: unexpected df output %q", lines[len(lines)-1])
+	}
+	hb.DiskTotal = total * 1024
+	hb.DiskUsed = used * 1024
+	return nil
+}
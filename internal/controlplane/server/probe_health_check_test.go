@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// healthCheckExecutor answers the health check commands with canned output.
+type healthCheckExecutor struct {
+	fakeRemoteExecutor
+	mu     sync.Mutex
+	output map[string]*protocol.CommandResultPayload // keyed by command
+	levels []protocol.CapabilityLevel
+}
+
+func (f *healthCheckExecutor) Execute(_ context.Context, _ *fleet.ProbeState, cmd protocol.CommandPayload, _ func(protocol.OutputChunkPayload)) (*protocol.CommandResultPayload, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.levels = append(f.levels, cmd.Level)
+	out := *f.output[cmd.Command]
+	out.RequestID = cmd.RequestID
+	return &out, nil
+}
+
+func TestProbeHealthCheckScoresFreshReadings(t *testing.T) {
+	srv := newTestServer(t)
+	registerRemoteProbe(t, srv, "remote-hc")
+	exec := &healthCheckExecutor{output: map[string]*protocol.CommandResultPayload{
+		"cat": {Stdout: "86400.12 170000.00\n0.50 0.40 0.30 1/200 4242\n" +
+			"MemTotal:       8000000 kB\nMemFree:         100000 kB\nMemAvailable:    200000 kB\n"},
+		"df":        {Stdout: "Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/sda1 1000000 500000 500000 50% /\n"},
+		"systemctl": {ExitCode: 3, Stdout: "active\nfailed\n"},
+	}}
+	srv.remoteExecutor = exec
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/probes/remote-hc/health/check", strings.NewReader(`{"services":["nginx","postgresql"]}`))
+	req.SetPathValue("id", "remote-hc")
+	rr := httptest.NewRecorder()
+	srv.handleProbeHealthCheck(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+	}
+
+	var check probeHealthCheck
+	if err := json.NewDecoder(rr.Body).Decode(&check); err != nil {
+		t.Fatal(err)
+	}
+	if check.Uptime != 86400 || check.Load[0] != 0.5 || check.DiskUsed != 500000*1024 || check.MemUsed != 7800000*1024 {
+		t.Fatalf("unexpected readings: %+v", check)
+	}
+	if check.Services["nginx"] != "active" || check.Services["postgresql"] != "failed" {
+		t.Fatalf("unexpected service states: %+v", check.Services)
+	}
+	// Memory at 97.5% (critical) and one failed service: 100 - 30 - 30.
+	if check.Health.Score != 40 || check.Health.Status != "degraded" {
+		t.Fatalf("unexpected health score: %+v", check.Health)
+	}
+	for _, level := range exec.levels {
+		if level != protocol.CapObserve {
+			t.Fatalf("expected observe-level diagnostics, got %q", level)
+		}
+	}
+
+	ps, _ := srv.fleetMgr.Get("remote-hc")
+	if ps.Health == nil || ps.Health.Score != 40 {
+		t.Fatalf("expected probe health to be updated, got %+v", ps.Health)
+	}
+
+	bad := httptest.NewRequest(http.MethodPost, "/api/v1/probes/remote-hc/health/check", strings.NewReader(`{"services":["nginx; reboot"]}`))
+	bad.SetPathValue("id", "remote-hc")
+	rr = httptest.NewRecorder()
+	srv.handleProbeHealthCheck(rr, bad)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid service name, got %d", rr.Code)
+	}
+}
@@ -37,6 +37,7 @@ func (s *Server) initProbeAuthentication() error {
 		CertVerifier:     certVerifier,
 		CertRegistry:     s.probeCertRegistry,
 		VerifyAPIKey:     s.validateProbeAPIKey,
+		VerifyToken:      s.verifyProbeToken,
 		AllowAPIFallback: mode != auth.ProbeAuthModeRequired,
 	})
 
@@ -74,16 +75,14 @@ func (s *Server) validateProbeAPIKey(probeID, bearerToken string) bool {
 	if !ok {
 		return false
 	}
-	if ps.APIKey == "" {
-		return false
-	}
-	return ps.APIKey == bearerToken
+	return ps.AcceptsAPIKey(bearerToken, time.Now())
 }
 
 func (s *Server) probeHandshakeAuthorizer() cpws.ProbeHandshakeAuthorizer {
 	return func(r *http.Request, probeID, bearerToken string) cpws.ProbeHandshakeDecision {
 		outcome := s.probeAuth.Authenticate(probeID, bearerToken, r.TLS)
 		s.recordProbeCertificateAuthAudit(probeID, outcome)
+		s.recordProbeAuthnAudit(r, probeID, outcome)
 		if outcome.Allowed {
 			return cpws.ProbeHandshakeDecision{Allowed: true}
 		}
@@ -95,6 +94,25 @@ func (s *Server) probeHandshakeAuthorizer() cpws.ProbeHandshakeAuthorizer {
 	}
 }
 
+// recordProbeAuthnAudit records the websocket handshake as an authn event,
+// whichever credential the probe presented.
+func (s *Server) recordProbeAuthnAudit(r *http.Request, probeID string, outcome auth.ProbeAuthOutcome) {
+	attempt := auth.AuthnAttempt{
+		Method:   auth.AuthnMethodProbe,
+		Identity: probeID,
+		Actor:    "probe",
+		ProbeID:  probeID,
+		SourceIP: auth.SourceIP(r),
+		Detail:   map[string]any{"probe_auth_method": outcome.Method},
+	}
+	if outcome.Allowed {
+		s.authnAudit.Succeeded(attempt)
+		return
+	}
+	attempt.Reason = outcome.Reason
+	s.authnAudit.Failed(attempt)
+}
+
 func (s *Server) recordProbeCertificateAuthAudit(probeID string, outcome auth.ProbeAuthOutcome) {
 	if outcome.Method != auth.ProbeAuthMethodMTLS {
 		return
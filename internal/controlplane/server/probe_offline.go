@@ -0,0 +1,112 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"go.uber.org/zap"
+)
+
+// probeOfflineThreshold returns the fleet default offline threshold from
+// config, falling back to defaultOfflineThreshold when unset or invalid.
+func (s *Server) probeOfflineThreshold() time.Duration {
+	raw := strings.TrimSpace(s.cfg.ProbeOfflineThreshold)
+	if raw == "" {
+		return defaultOfflineThreshold
+	}
+	threshold, err := parseHumanDuration(raw)
+	if err == nil {
+		err = fleet.ValidateOfflineThreshold(threshold)
+	}
+	if err != nil || threshold == 0 {
+		s.logger.Warn("invalid probe offline threshold; using default",
+			zap.String("probe_offline_threshold", raw),
+			zap.Duration("default", defaultOfflineThreshold),
+			zap.Error(err),
+		)
+		return defaultOfflineThreshold
+	}
+	return threshold
+}
+
+// offlineThresholdView is the effective offline threshold reported with a
+// probe, and whether it came from the probe, its heartbeat or the default.
+type offlineThresholdView struct {
+	EffectiveOfflineThresholdSec int64  `json:"effective_offline_threshold_sec"`
+	OfflineThresholdSource       string `json:"offline_threshold_source"`
+}
+
+func (s *Server) offlineThresholdFor(ps *fleet.ProbeState) offlineThresholdView {
+	threshold, source := ps.OfflineThreshold(s.probeOfflineThreshold())
+	return offlineThresholdView{
+		EffectiveOfflineThresholdSec: int64(threshold / time.Second),
+		OfflineThresholdSource:       source,
+	}
+}
+
+// handleSetOfflineThreshold sets or clears a probe's offline threshold. An
+// empty or zero threshold reverts to the derived or fleet default value.
+func (s *Server) handleSetOfflineThreshold(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetWrite) {
+		return
+	}
+	id := r.PathValue("id")
+	ps, ok := s.probeForRequest(r, id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+
+	var body struct {
+		OfflineThreshold string `json:"offline_threshold"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4<<10)).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+		return
+	}
+	var threshold time.Duration
+	if raw := strings.TrimSpace(body.OfflineThreshold); raw != "" && raw != "0" {
+		parsed, err := parseHumanDuration(raw)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("invalid offline_threshold: %v", err))
+			return
+		}
+		threshold = parsed
+	}
+	if err := fleet.ValidateOfflineThreshold(threshold); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	before := ps.OfflineThresholdSec
+	if err := s.fleetMgr.SetOfflineThreshold(id, threshold); err != nil {
+		writeJSONError(w, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+	summary := fmt.Sprintf("Offline threshold set: %s", threshold)
+	if threshold == 0 {
+		summary = "Offline threshold cleared"
+	}
+	s.recordAudit(audit.Event{
+		Type:    audit.EventPolicyChanged,
+		ProbeID: id,
+		Actor:   actorFromAuthContext(r.Context()),
+		Summary: summary,
+		Before:  map[string]int{"offline_threshold_sec": before},
+		After:   map[string]int{"offline_threshold_sec": int(threshold / time.Second)},
+	})
+
+	ps, _ = s.fleetMgr.Get(id)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		ProbeID             string `json:"probe_id"`
+		OfflineThresholdSec int    `json:"offline_threshold_sec"`
+		offlineThresholdView
+	}{id, ps.OfflineThresholdSec, s.offlineThresholdFor(ps)})
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProbeOfflineThresholdEndpoint(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-sat", "sat-01", "linux", "amd64")
+	_ = srv.fleetMgr.SetHeartbeatInterval("probe-sat", 20*time.Second)
+
+	type view struct {
+		OfflineThresholdSec          int    `json:"offline_threshold_sec"`
+		EffectiveOfflineThresholdSec int64  `json:"effective_offline_threshold_sec"`
+		OfflineThresholdSource       string `json:"offline_threshold_source"`
+	}
+	do := func(method, path, body string) (*httptest.ResponseRecorder, view) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rr, req)
+		var v view
+		if rr.Code == http.StatusOK {
+			_ = json.NewDecoder(rr.Body).Decode(&v)
+		}
+		return rr, v
+	}
+
+	if rr, v := do(http.MethodGet, "/api/v1/probes/probe-sat", ""); rr.Code != http.StatusOK || v.EffectiveOfflineThresholdSec != 60 || v.OfflineThresholdSource != "heartbeat" {
+		t.Fatalf("expected derived 60s threshold, got %d %+v", rr.Code, v)
+	}
+
+	rr, v := do(http.MethodPut, "/api/v1/probes/probe-sat/offline-threshold", `{"offline_threshold":"10m"}`)
+	if rr.Code != http.StatusOK || v.OfflineThresholdSec != 600 || v.EffectiveOfflineThresholdSec != 600 || v.OfflineThresholdSource != "probe" {
+		t.Fatalf("expected 10m probe threshold, got %d %+v", rr.Code, v)
+	}
+
+	if rr, _ := do(http.MethodPut, "/api/v1/probes/probe-sat/offline-threshold", `{"offline_threshold":"2s"}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for out-of-range threshold, got %d", rr.Code)
+	}
+	if rr, _ := do(http.MethodPut, "/api/v1/probes/missing/offline-threshold", `{"offline_threshold":"5m"}`); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown probe, got %d", rr.Code)
+	}
+
+	rr, v = do(http.MethodPut, "/api/v1/probes/probe-sat/offline-threshold", `{"offline_threshold":""}`)
+	if rr.Code != http.StatusOK || v.OfflineThresholdSec != 0 || v.OfflineThresholdSource != "heartbeat" {
+		t.Fatalf("expected cleared threshold to fall back to heartbeat, got %d %+v", rr.Code, v)
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"go.uber.org/zap"
+)
+
+// Bounds for the configured probe token lifetime.
+const (
+	minProbeTokenTTL = time.Minute
+	maxProbeTokenTTL = 24 * time.Hour
+)
+
+func (s *Server) probeTokenTTL() time.Duration {
+	raw := strings.TrimSpace(s.cfg.ProbeTokenTTL)
+	if raw == "" {
+		return auth.DefaultProbeTokenTTL
+	}
+	ttl, err := parseHumanDuration(raw)
+	if err != nil || ttl < minProbeTokenTTL || ttl > maxProbeTokenTTL {
+		s.logger.Warn("invalid probe token ttl; using default",
+			zap.String("probe_token_ttl", raw),
+			zap.Duration("default", auth.DefaultProbeTokenTTL),
+		)
+		return auth.DefaultProbeTokenTTL
+	}
+	return ttl
+}
+
+// verifyProbeToken validates a probe WebSocket JWT against the probe's
+// current API key, so tokens issued before a key rotation are rejected.
+func (s *Server) verifyProbeToken(probeID, token string) error {
+	if s.probeTokens == nil {
+		return auth.ErrProbeTokenInvalid
+	}
+	ps, ok := s.fleetMgr.Get(probeID)
+	if !ok {
+		return auth.ErrProbeTokenInvalid
+	}
+	_, err := s.probeTokens.Verify(token, probeID, ps.APIKey)
+	return err
+}
+
+// handleProbeTokenExchange trades a probe's long-lived API key for a
+// short-lived WebSocket token. Only the current key is accepted: a key kept
+// valid by a rotation grace window can still connect directly but cannot
+// mint tokens.
+func (s *Server) handleProbeTokenExchange(w http.ResponseWriter, r *http.Request) {
+	if s.probeTokens == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "probe tokens are not configured")
+		return
+	}
+	probeID := strings.TrimSpace(r.URL.Query().Get("id"))
+	if probeID == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "missing probe id")
+		return
+	}
+	key := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if key == "" {
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized", "missing authorization")
+		return
+	}
+	ps, ok := s.fleetMgr.Get(probeID)
+	if !ok || ps.APIKey == "" || auth.IsProbeToken(key) || subtle.ConstantTimeCompare([]byte(ps.APIKey), []byte(key)) != 1 {
+		s.logger.Warn("probe token exchange rejected",
+			zap.String("probe_id", probeID),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		writeJSONError(w, http.StatusForbidden, "forbidden", "invalid credentials")
+		return
+	}
+
+	token, expires, err := s.probeTokens.Issue(probeID, key)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "internal_error", "failed to issue token")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"token":      token,
+		"token_type": "Bearer",
+		"expires_at": expires,
+		"expires_in": int(time.Until(expires).Seconds()),
+	})
+}
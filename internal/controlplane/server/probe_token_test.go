@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProbeTokenExchangeAndRotation(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-jwt", "host", "linux", "amd64")
+	_ = srv.fleetMgr.SetAPIKey("probe-jwt", "lgk_current")
+
+	exchange := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/probe/token?id=probe-jwt", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		rr := httptest.NewRecorder()
+		srv.handleProbeTokenExchange(rr, req)
+		return rr
+	}
+
+	if rr := exchange("lgk_wrong"); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for wrong key, got %d", rr.Code)
+	}
+	rr := exchange("lgk_current")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Token     string `json:"token"`
+		ExpiresIn int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Token == "" || resp.ExpiresIn <= 0 {
+		t.Fatalf("unexpected token response %+v", resp)
+	}
+	if rr := exchange(resp.Token); rr.Code != http.StatusForbidden {
+		t.Fatalf("a token must not be exchanged for another token, got %d", rr.Code)
+	}
+
+	handshake := srv.probeHandshakeAuthorizer()
+	wsReq := httptest.NewRequest(http.MethodGet, "/ws/probe?id=probe-jwt", nil)
+	if d := handshake(wsReq, "probe-jwt", resp.Token); !d.Allowed {
+		t.Fatalf("expected token to authenticate the handshake, got %+v", d)
+	}
+	if d := handshake(wsReq, "probe-jwt", "lgk_current"); !d.Allowed {
+		t.Fatalf("expected static key to keep working, got %+v", d)
+	}
+
+	if err := srv.fleetMgr.RotateAPIKey("probe-jwt", "lgk_next", time.Minute); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if d := handshake(wsReq, "probe-jwt", resp.Token); d.Allowed || d.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected rotation to revoke the token, got %+v", d)
+	}
+	if rr := exchange("lgk_current"); rr.Code != http.StatusForbidden {
+		t.Fatalf("grace-period key must not mint tokens, got %d", rr.Code)
+	}
+}
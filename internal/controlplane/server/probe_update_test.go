@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/probe/agent"
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+// An update signed by sendProbeUpdate must verify on a probe configured with
+// the same hex signing key as the control plane.
+func TestSendProbeUpdateVerifiesOnAgentWithHexKey(t *testing.T) {
+	srv := newTestServer(t) // LEGATOR_SIGNING_KEY is 64 hex chars
+	const probeID = "probe-update-sig"
+	const apiKey = "probe-update-key"
+	srv.fleetMgr.Register(probeID, "host", "linux", "amd64")
+	_ = srv.fleetMgr.SetAPIKey(probeID, apiKey)
+
+	ws := httptest.NewServer(http.HandlerFunc(srv.hub.HandleProbeWS))
+	defer ws.Close()
+
+	// The agent only downloads once the update has passed verification.
+	// The download fails, so the probe binary is never replaced.
+	var downloads atomic.Int32
+	dl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer dl.Close()
+
+	a := agent.New(&agent.Config{
+		ServerURL:  ws.URL,
+		ProbeID:    probeID,
+		APIKey:     apiKey,
+		SigningKey: strings.Repeat("a", 64),
+		ConfigDir:  t.TempDir(),
+	}, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = a.Run(ctx) }()
+
+	waitFor(t, func() bool { return srv.hub.IsConnected(probeID) }, "probe to connect")
+
+	upd := protocol.UpdatePayload{
+		URL:      dl.URL + "/probe",
+		Version:  "v9.9.9",
+		Checksum: strings.Repeat("0", 64),
+	}
+	if _, err := srv.sendProbeUpdate(probeID, upd); err != nil {
+		t.Fatalf("send update: %v", err)
+	}
+	waitFor(t, func() bool { return downloads.Load() > 0 }, "agent to accept the update signature")
+}
+
+func waitFor(t *testing.T, cond func() bool, what string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -2,13 +2,17 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
 
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/events"
 	"github.com/marcus-qen/legator/internal/controlplane/modeldock"
 	"github.com/marcus-qen/legator/internal/controlplane/providerproxy"
+	"go.uber.org/zap"
 )
 
 func (s *Server) resolveProviderProxyCredentials(_ context.Context, _ string, requestedModel string) (providerproxy.ProviderCredentials, error) {
@@ -100,3 +104,44 @@ func (s *Server) handleProviderProxy(w http.ResponseWriter, r *http.Request) {
 	}
 	s.providerProxy.HandleHTTP(w, r, runID)
 }
+
+func (s *Server) handleProviderProxyBudget(w http.ResponseWriter, r *http.Request) {
+	if s.providerProxy == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "provider proxy unavailable")
+		return
+	}
+	status, err := s.providerProxy.BudgetStatus(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "internal_error", "failed to query spend")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// recordProviderBudgetThreshold audits and publishes a monthly provider budget
+// crossing so webhook subscribers are notified at 80% and 100%.
+func (s *Server) recordProviderBudgetThreshold(_ context.Context, alert providerproxy.BudgetAlert) {
+	summary := fmt.Sprintf("Provider spend reached %d%% of monthly budget (%s: $%.2f of $%.2f)",
+		alert.ThresholdPercent, alert.Month, alert.SpendUSD, alert.BudgetUSD)
+	detail := map[string]any{
+		"month":             alert.Month,
+		"threshold_percent": alert.ThresholdPercent,
+		"spend_usd":         alert.SpendUSD,
+		"budget_usd":        alert.BudgetUSD,
+		"run_id":            alert.RunID,
+	}
+	s.recordAudit(audit.Event{
+		Type:    audit.EventRunnerProviderBudget,
+		Actor:   "system",
+		Summary: summary,
+		Detail:  detail,
+	})
+	s.publishEvent(events.ProviderBudgetThreshold, "", summary, detail)
+	s.logger.Warn("provider monthly budget threshold crossed",
+		zap.String("month", alert.Month),
+		zap.Int("threshold_percent", alert.ThresholdPercent),
+		zap.Float64("spend_usd", alert.SpendUSD),
+		zap.Float64("budget_usd", alert.BudgetUSD),
+	)
+}
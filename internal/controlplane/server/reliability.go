@@ -20,9 +20,34 @@ func (s *Server) buildReliabilityScorecard(window time.Duration) reliability.Sco
 		window = reliabilityDefaultWindow
 	}
 
+	in := s.reliabilityInputs(now.Add(-window), now)
+	in.ProbeFleet = s.liveProbeConnectivity()
+	return reliability.BuildScorecard(in)
+}
+
+// buildReliabilityTrend scores equal buckets across window. Past buckets take
+// probe connectivity from the sampled history; the newest bucket uses live state.
+func (s *Server) buildReliabilityTrend(window time.Duration, buckets int) reliability.Trend {
+	now := time.Now().UTC()
+	return reliability.BuildTrend(now, window, buckets, func(from, to time.Time) reliability.Inputs {
+		in := s.reliabilityInputs(from, to)
+		if !to.Before(now) {
+			in.ProbeFleet = s.liveProbeConnectivity()
+		} else if avg, ok := s.reliabilityConnectivity.Average(from, to); ok {
+			in.ProbeFleet = avg
+		}
+		return in
+	})
+}
+
+// reliabilityInputs gathers request and command telemetry for [from, to).
+// Probe connectivity is left empty for the caller to fill.
+func (s *Server) reliabilityInputs(from, to time.Time) reliability.Inputs {
+	window := to.Sub(from)
+
 	requestStats := reliability.ControlPlaneInputs{}
 	if s.reliabilityTelemetry != nil {
-		snap := s.reliabilityTelemetry.Snapshot(window, now)
+		snap := s.reliabilityTelemetry.Snapshot(window, to)
 		requestStats = reliability.ControlPlaneInputs{
 			TotalRequests:       snap.TotalRequests,
 			SuccessfulRequests:  snap.SuccessfulRequests,
@@ -31,6 +56,20 @@ func (s *Server) buildReliabilityScorecard(window time.Duration) reliability.Sco
 		}
 	}
 
+	commandTotal, commandSuccess := s.commandResultStats(from, to)
+
+	return reliability.Inputs{
+		Now:          to,
+		Window:       window,
+		ControlPlane: requestStats,
+		Command: reliability.CommandInputs{
+			TotalResults:      commandTotal,
+			SuccessfulResults: commandSuccess,
+		},
+	}
+}
+
+func (s *Server) liveProbeConnectivity() reliability.ProbeFleetInputs {
 	probes := s.fleetMgr.List()
 	connected := 0
 	for _, probe := range probes {
@@ -39,32 +78,26 @@ func (s *Server) buildReliabilityScorecard(window time.Duration) reliability.Sco
 			connected++
 		}
 	}
-
-	commandTotal, commandSuccess := s.commandResultStats(now, window)
-
-	return reliability.BuildScorecard(reliability.Inputs{
-		Now:          now,
-		Window:       window,
-		ControlPlane: requestStats,
-		ProbeFleet: reliability.ProbeFleetInputs{
-			TotalProbes:     len(probes),
-			ConnectedProbes: connected,
-		},
-		Command: reliability.CommandInputs{
-			TotalResults:      commandTotal,
-			SuccessfulResults: commandSuccess,
-		},
-	})
+	return reliability.ProbeFleetInputs{
+		TotalProbes:     len(probes),
+		ConnectedProbes: connected,
+	}
 }
 
-func (s *Server) commandResultStats(now time.Time, window time.Duration) (total int, success int) {
-	if window <= 0 {
-		window = reliabilityDefaultWindow
+// recordReliabilityConnectivity samples live probe connectivity for trends.
+func (s *Server) recordReliabilityConnectivity(now time.Time) {
+	if s.reliabilityConnectivity == nil {
+		return
 	}
+	live := s.liveProbeConnectivity()
+	s.reliabilityConnectivity.Record(now, live.TotalProbes, live.ConnectedProbes)
+}
 
+func (s *Server) commandResultStats(from, to time.Time) (total int, success int) {
 	events := s.queryAudit(audit.Filter{
 		Type:  audit.EventCommandResult,
-		Since: now.Add(-window),
+		Since: from,
+		Until: to,
 		Limit: reliabilityAuditSampleLimit,
 	})
 	for _, evt := range events {
@@ -161,3 +194,36 @@ func (s *Server) handleReliabilityScorecard(w http.ResponseWriter, r *http.Reque
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(scorecard)
 }
+
+func (s *Server) handleReliabilityTrend(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+
+	window := reliabilityTelemetryMaxAge
+	if rawWindow := strings.TrimSpace(r.URL.Query().Get("window")); rawWindow != "" {
+		parsed, err := parseHumanDuration(rawWindow)
+		if err != nil || parsed <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid window duration")
+			return
+		}
+		window = parsed
+	}
+	if window > reliabilityTelemetryMaxAge {
+		window = reliabilityTelemetryMaxAge
+	}
+
+	buckets := 0
+	if rawBuckets := strings.TrimSpace(r.URL.Query().Get("buckets")); rawBuckets != "" {
+		parsed, err := strconv.Atoi(rawBuckets)
+		if err != nil || parsed <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", "buckets must be a positive integer")
+			return
+		}
+		buckets = parsed
+	}
+
+	trend := s.buildReliabilityTrend(window, buckets)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(trend)
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"go.uber.org/zap"
+)
+
+// trustRemoteHostKey pins the first host key seen for a TOFU remote probe
+// and audits the decision.
+func (s *Server) trustRemoteHostKey(probeID, fingerprint string) {
+	if err := s.fleetMgr.PinRemoteHostKey(probeID, fingerprint); err != nil {
+		s.logger.Warn("failed to pin remote probe host key",
+			zap.String("probe", probeID),
+			zap.String("fingerprint", fingerprint),
+			zap.Error(err),
+		)
+		return
+	}
+	s.recordAudit(audit.Event{
+		Type:    audit.EventProbeHostKeyTrusted,
+		ProbeID: probeID,
+		Actor:   "system",
+		Summary: fmt.Sprintf("Trusted SSH host key %s on first use", fingerprint),
+		Detail:  map[string]any{"fingerprint": fingerprint, "policy": fleet.HostKeyPolicyTOFU},
+	})
+}
+
+// auditRemoteHostKeyRejection records a remote command blocked by host key
+// verification. Other errors are ignored.
+func (s *Server) auditRemoteHostKeyRejection(probeID, requestID string, err error) {
+	var hkErr *fleet.HostKeyError
+	if !errors.As(err, &hkErr) {
+		return
+	}
+	reason := "host key mismatch"
+	if errors.Is(hkErr, fleet.ErrHostKeyUnknown) {
+		reason = "host key not pinned under strict policy"
+	}
+	s.logger.Warn("remote probe host key rejected",
+		zap.String("probe", probeID),
+		zap.String("expected", hkErr.Expected),
+		zap.String("actual", hkErr.Actual),
+	)
+	s.recordAudit(audit.Event{
+		Type:    audit.EventProbeHostKeyRejected,
+		ProbeID: probeID,
+		Actor:   "system",
+		Summary: fmt.Sprintf("Blocked remote command %s: %s", requestID, reason),
+		Detail: map[string]any{
+			"request_id": requestID,
+			"decision":   "blocked",
+			"reason":     reason,
+			"host":       hkErr.Host,
+			"expected":   hkErr.Expected,
+			"actual":     hkErr.Actual,
+		},
+	})
+}
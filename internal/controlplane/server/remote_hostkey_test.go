@@ -0,0 +1,45 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"go.uber.org/zap"
+)
+
+func TestRemoteHostKeyAudits(t *testing.T) {
+	mgr := fleet.NewManager(zap.NewNop())
+	if _, err := mgr.RegisterRemote(fleet.RemoteProbeRegistration{
+		ID:          "rpr-1",
+		Remote:      fleet.RemoteProbeConfig{Host: "10.0.0.9", Username: "root"},
+		Credentials: fleet.RemoteProbeCredentials{Password: "pw"},
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	srv := &Server{logger: zap.NewNop(), auditLog: audit.NewLog(100), fleetMgr: mgr}
+
+	const fp = "SHA256:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU"
+	srv.trustRemoteHostKey("rpr-1", fp)
+	ps, _ := mgr.Get("rpr-1")
+	if ps.Remote.HostKeyFingerprint != fp {
+		t.Fatalf("expected fingerprint to be pinned, got %q", ps.Remote.HostKeyFingerprint)
+	}
+	if got := srv.auditLog.Query(audit.Filter{Type: audit.EventProbeHostKeyTrusted}); len(got) != 1 {
+		t.Fatalf("expected one trusted audit event, got %d", len(got))
+	}
+
+	srv.auditRemoteHostKeyRejection("rpr-1", "req-1", fmt.Errorf("unrelated"))
+	hkErr := &fleet.HostKeyError{Host: "10.0.0.9:22", Expected: fp, Actual: "SHA256:other", Err: fleet.ErrHostKeyMismatch}
+	srv.auditRemoteHostKeyRejection("rpr-1", "req-2", fmt.Errorf("ssh: handshake failed: %w", hkErr))
+
+	rejected := srv.auditLog.Query(audit.Filter{Type: audit.EventProbeHostKeyRejected})
+	if len(rejected) != 1 {
+		t.Fatalf("expected one rejection audit event, got %d", len(rejected))
+	}
+	detail, _ := rejected[0].Detail.(map[string]any)
+	if detail["decision"] != "blocked" || detail["reason"] != "host key mismatch" || detail["request_id"] != "req-2" {
+		t.Fatalf("unexpected rejection detail %+v", detail)
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// recordResourceSample adds the heartbeat's readings to the probe's
+// short-term resource series; the series thins them to its interval.
+func (s *Server) recordResourceSample(probeID string, hb *protocol.HeartbeatPayload) {
+	if s.resourceSeries == nil {
+		return
+	}
+	cpus := 0
+	if ps, ok := s.fleetMgr.Get(probeID); ok && ps.Inventory != nil {
+		cpus = ps.Inventory.CPUs
+	}
+	s.resourceSeries.Record(probeID, hb, cpus, time.Now().UTC())
+}
+
+func (s *Server) handleProbeResourceSeries(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+	id := r.PathValue("id")
+	if _, ok := s.probeForRequest(r, id); !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
+	if s.resourceSeries == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "resource series unavailable")
+		return
+	}
+
+	window := s.resourceSeries.Window()
+	if raw := strings.TrimSpace(r.URL.Query().Get("window")); raw != "" {
+		parsed, err := parseHumanDuration(raw)
+		if err != nil || parsed <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid window duration")
+			return
+		}
+		// Nothing older than the configured window is kept.
+		window = min(parsed, window)
+	}
+
+	samples := s.resourceSeries.List(id, time.Now().UTC().Add(-window))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"probe_id": id,
+		"window":   window.String(),
+		"interval": s.resourceSeries.Interval().String(),
+		"samples":  samples,
+		"count":    len(samples),
+	})
+}
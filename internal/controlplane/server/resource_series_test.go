@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func TestProbeResourceSeriesFromHeartbeats(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-series", "host", "linux", "amd64")
+
+	srv.handleProbeMessage("probe-series", protocol.Envelope{
+		Type: protocol.MsgHeartbeat,
+		Payload: protocol.HeartbeatPayload{
+			ProbeID:   "probe-series",
+			Load:      [3]float64{1.5, 1, 0.5},
+			MemUsed:   512,
+			MemTotal:  1024,
+			DiskUsed:  1,
+			DiskTotal: 4,
+			CPUPct:    37.25,
+		},
+	})
+	// A second heartbeat inside the sample interval is dropped.
+	srv.handleProbeMessage("probe-series", protocol.Envelope{
+		Type:    protocol.MsgHeartbeat,
+		Payload: protocol.HeartbeatPayload{ProbeID: "probe-series", CPUPct: 99},
+	})
+
+	resp := makeRequest(t, srv, http.MethodGet, "/api/v1/probes/probe-series/metrics/series?window=10m", "", "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", resp.Code, resp.Body.String())
+	}
+	var body struct {
+		Window   string `json:"window"`
+		Interval string `json:"interval"`
+		Count    int    `json:"count"`
+		Samples  []struct {
+			CPUPct  *float64 `json:"cpu_pct"`
+			Load1   float64  `json:"load_1"`
+			MemPct  *float64 `json:"mem_pct"`
+			DiskPct *float64 `json:"disk_pct"`
+		} `json:"samples"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode series: %v", err)
+	}
+	if body.Window != "10m0s" || body.Interval != "30s" || body.Count != 1 {
+		t.Fatalf("unexpected series envelope: %+v", body)
+	}
+	got := body.Samples[0]
+	if got.CPUPct == nil || *got.CPUPct != 37.3 || got.Load1 != 1.5 || got.MemPct == nil || *got.MemPct != 50 || got.DiskPct == nil || *got.DiskPct != 25 {
+		t.Fatalf("unexpected sample: %+v", got)
+	}
+
+	// Windows longer than the configured one are clamped to it.
+	resp = makeRequest(t, srv, http.MethodGet, "/api/v1/probes/probe-series/metrics/series?window=7d", "", "")
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil || body.Window != "1h0m0s" {
+		t.Fatalf("expected window clamped to 1h, got %q (%v)", body.Window, err)
+	}
+
+	if resp := makeRequest(t, srv, http.MethodGet, "/api/v1/probes/probe-series/metrics/series?window=soon", "", ""); resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad window, got %d", resp.Code)
+	}
+	if resp := makeRequest(t, srv, http.MethodGet, "/api/v1/probes/missing/metrics/series", "", ""); resp.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown probe, got %d", resp.Code)
+	}
+
+	if resp := makeRequest(t, srv, http.MethodDelete, "/api/v1/probes/probe-series", "", ""); resp.Code != http.StatusOK {
+		t.Fatalf("delete probe: expected 200, got %d", resp.Code)
+	}
+	if samples := srv.resourceSeries.List("probe-series", time.Time{}); len(samples) != 0 {
+		t.Fatalf("expected series dropped with the probe, got %d samples", len(samples))
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/controlplane/events"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+func (s *Server) initRollouts() {
+	s.rolloutMgr = fleet.NewRolloutManager(s.fleetMgr, s.recordRolloutStage)
+}
+
+// recordRolloutStage audits and publishes each rollout stage transition.
+func (s *Server) recordRolloutStage(r fleet.Rollout, stage string) {
+	var (
+		auditType audit.EventType
+		eventType events.EventType
+		summary   string
+	)
+	switch stage {
+	case "started":
+		auditType, eventType = audit.EventProbeRolloutStarted, events.RolloutStarted
+		summary = fmt.Sprintf("Update rollout %s started: %s to %d probes (tag=%s, canary=%d)", r.ID, r.Version, r.Progress.Total, r.Tag, r.Progress.Canary)
+	case "canary_passed":
+		auditType, eventType = audit.EventProbeRolloutCanaryPassed, events.RolloutCanaryPassed
+		summary = fmt.Sprintf("Update rollout %s canary passed on %s; updating remaining probes", r.ID, r.Version)
+	case fleet.RolloutStatusCompleted:
+		auditType, eventType = audit.EventProbeRolloutCompleted, events.RolloutCompleted
+		summary = fmt.Sprintf("Update rollout %s completed: %d probes on %s", r.ID, r.Progress.Updated, r.Version)
+	default:
+		auditType, eventType = audit.EventProbeRolloutFailed, events.RolloutFailed
+		summary = fmt.Sprintf("Update rollout %s %s: %s", r.ID, r.Status, r.Error)
+	}
+
+	detail := map[string]any{
+		"rollout_id": r.ID,
+		"tag":        r.Tag,
+		"version":    r.Version,
+		"status":     r.Status,
+		"stage":      stage,
+		"progress":   r.Progress,
+	}
+	actor := r.Actor
+	if actor == "" {
+		actor = "api"
+	}
+	s.recordAudit(audit.Event{
+		Type:    auditType,
+		Actor:   actor,
+		Summary: summary,
+		Detail:  detail,
+	})
+	s.publishEvent(eventType, "", summary, detail)
+}
+
+func (s *Server) handleTagUpdate(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetWrite) {
+		return
+	}
+	tag := r.PathValue("tag")
+
+	var body struct {
+		protocol.UpdatePayload
+		CanaryPercent  int    `json:"canary_percent"`
+		ConfirmTimeout string `json:"confirm_timeout,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+		return
+	}
+	upd := body.UpdatePayload
+	if upd.URL == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "url is required")
+		return
+	}
+	upd.Version = strings.TrimSpace(upd.Version)
+	if upd.Version == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "version is required to confirm the rollout")
+		return
+	}
+	upd.Checksum = strings.ToLower(strings.TrimSpace(upd.Checksum))
+	if !isSHA256Hex(upd.Checksum) {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "checksum must be a sha256 hex digest")
+		return
+	}
+	if body.CanaryPercent < 0 || body.CanaryPercent > 100 {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "canary_percent must be between 0 and 100")
+		return
+	}
+	var confirmTimeout time.Duration
+	if strings.TrimSpace(body.ConfirmTimeout) != "" {
+		d, err := parseHumanDuration(body.ConfirmTimeout)
+		if err != nil || d <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", "confirm_timeout must be a positive duration (e.g. 10m)")
+			return
+		}
+		confirmTimeout = d
+	}
+
+	scopedSet := make(map[string]bool)
+	for _, ps := range s.probesForRequest(r) {
+		scopedSet[ps.ID] = true
+	}
+	probeIDs := make([]string, 0)
+	for _, ps := range s.fleetMgr.ListByTag(tag) {
+		if !scopedSet[ps.ID] || ps.Draining || strings.EqualFold(ps.Type, fleet.ProbeTypeRemote) {
+			continue
+		}
+		probeIDs = append(probeIDs, ps.ID)
+	}
+	sort.Strings(probeIDs)
+	if len(probeIDs) == 0 {
+		writeJSONError(w, http.StatusNotFound, "not_found", "no updatable probes with that tag")
+		return
+	}
+
+	rollout, err := s.rolloutMgr.Start(s.lifecycleContext(), fleet.RolloutSpec{
+		Tag:            tag,
+		Version:        upd.Version,
+		CanaryPercent:  body.CanaryPercent,
+		ConfirmTimeout: confirmTimeout,
+		Actor:          actorFromAuthContext(r.Context()),
+	}, probeIDs, func(probeID string) (string, error) {
+		return s.sendProbeUpdate(probeID, upd)
+	})
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(rollout)
+}
+
+func (s *Server) handleListRollouts(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"rollouts": s.rolloutMgr.List()})
+}
+
+func (s *Server) handleGetRollout(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+	rollout, ok := s.rolloutMgr.Get(r.PathValue("id"))
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "rollout not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(rollout)
+}
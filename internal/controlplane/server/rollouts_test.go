@@ -0,0 +1,42 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+)
+
+func TestTagRolloutStopsOnServerShutdown(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-roll", "host", "linux", "amd64")
+	if err := srv.fleetMgr.SetTags("probe-roll", []string{"web"}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+	_, cleanup := connectProbeWS(t, srv, "probe-roll")
+	defer cleanup()
+
+	body := `{"url":"https://dl.example.test/probe","version":"v9.9.9","checksum":"` + strings.Repeat("0", 64) + `","confirm_timeout":"1h"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/fleet/by-tag/web/update", bytes.NewBufferString(body))
+	req.SetPathValue("tag", "web")
+	rr := httptest.NewRecorder()
+	srv.handleTagUpdate(rr, req)
+	if rr.Code != http.StatusAccepted && rr.Code != http.StatusOK {
+		t.Fatalf("start rollout: %d %s", rr.Code, rr.Body.String())
+	}
+	var started fleet.Rollout
+	if err := json.Unmarshal(rr.Body.Bytes(), &started); err != nil {
+		t.Fatalf("decode rollout: %v", err)
+	}
+
+	// The probe never reports the new version; shutdown ends the wait.
+	srv.stopLifecycle()
+	waitFor(t, func() bool {
+		r, ok := srv.rolloutMgr.Get(started.ID)
+		return ok && len(r.Probes) == 1 && strings.Contains(r.Probes[0].Error, "shutting down")
+	}, "rollout to stop on shutdown")
+}
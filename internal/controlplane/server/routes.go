@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,22 +21,26 @@ import (
 	"github.com/marcus-qen/legator/internal/controlplane/audit"
 	"github.com/marcus-qen/legator/internal/controlplane/auth"
 	"github.com/marcus-qen/legator/internal/controlplane/cmdtracker"
+	"github.com/marcus-qen/legator/internal/controlplane/connectivity"
 	coreapprovalpolicy "github.com/marcus-qen/legator/internal/controlplane/core/approvalpolicy"
 	corecommanddispatch "github.com/marcus-qen/legator/internal/controlplane/core/commanddispatch"
 	"github.com/marcus-qen/legator/internal/controlplane/events"
 	"github.com/marcus-qen/legator/internal/controlplane/fleet"
 	"github.com/marcus-qen/legator/internal/controlplane/jobs"
+	"github.com/marcus-qen/legator/internal/controlplane/llm"
 	"github.com/marcus-qen/legator/internal/controlplane/metrics"
 	"github.com/marcus-qen/legator/internal/controlplane/modeldock"
 	controlpolicy "github.com/marcus-qen/legator/internal/controlplane/policy"
 	"github.com/marcus-qen/legator/internal/controlplane/tenant"
 	"github.com/marcus-qen/legator/internal/protocol"
+	"github.com/marcus-qen/legator/internal/shared/signing"
 	"go.uber.org/zap"
 )
 
 func (s *Server) registerRoutes(mux *http.ServeMux) {
 	// Health + version
 	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
 	mux.HandleFunc("GET /version", s.handleVersion)
 	// OpenAPI spec (public, no auth required)
 	mux.HandleFunc("GET /api/v1/openapi.yaml", s.handleOpenAPISpec)
@@ -48,7 +54,7 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 		mux.HandleFunc("GET /auth/oidc/callback", s.oidcProvider.HandleCallback(s.userStore, s.sessionCreator))
 	}
 	mux.HandleFunc("GET /login", auth.HandleLoginPage(filepath.Join("web", "templates"), loginOpts))
-	mux.HandleFunc("POST /login", auth.HandleLoginWithAudit(s.userAuth, s.sessionCreator, s.auditRecorder(), loginOpts))
+	mux.HandleFunc("POST /login", auth.HandleLoginWithAudit(s.userAuth, s.sessionCreator, s.authnAudit, s.loginLockout, loginOpts))
 	mux.HandleFunc("POST /logout", auth.HandleLogout(s.sessionDeleter))
 
 	// Current user + RBAC user management (Track 2 stubs)
@@ -56,13 +62,20 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/users", s.withPermission(auth.PermAdmin, s.handleListUsers))
 	mux.HandleFunc("POST /api/v1/users", s.withPermission(auth.PermAdmin, s.handleCreateUser))
 	mux.HandleFunc("DELETE /api/v1/users/{id}", s.withPermission(auth.PermAdmin, s.handleDeleteUser))
+	mux.HandleFunc("GET /api/v1/auth/lockouts", s.withPermission(auth.PermAdmin, s.handleListLoginLockouts))
+	mux.HandleFunc("DELETE /api/v1/auth/lockouts/{key}", s.withPermission(auth.PermAdmin, s.handleClearLoginLockout))
 
 	// Fleet API
 	mux.HandleFunc("POST /api/v1/probes", s.withPermission(auth.PermFleetWrite, s.withTenantScope(s.handleCreateProbe)))
 	mux.HandleFunc("GET /api/v1/probes", s.withPermission(auth.PermFleetRead, s.withTenantScope(s.handleListProbes)))
 	mux.HandleFunc("GET /api/v1/probes/{id}", s.withPermission(auth.PermFleetRead, s.withTenantScope(s.handleGetProbe)))
 	mux.HandleFunc("GET /api/v1/probes/{id}/health", s.withPermission(auth.PermFleetRead, s.handleProbeHealth))
+	mux.HandleFunc("GET /api/v1/probes/{id}/health/history", s.withPermission(auth.PermFleetRead, s.handleProbeHealthHistory))
+	mux.HandleFunc("DELETE /api/v1/probes/{id}/health/history", s.withPermission(auth.PermFleetWrite, s.handlePurgeProbeHealthHistory))
+	mux.HandleFunc("POST /api/v1/probes/{id}/health/check", s.withPermission(auth.PermFleetWrite, s.handleProbeHealthCheck))
+	mux.HandleFunc("GET /api/v1/probes/{id}/metrics/series", s.withPermission(auth.PermFleetRead, s.handleProbeResourceSeries))
 	mux.HandleFunc("POST /api/v1/probes/{id}/command", s.withPermission(auth.PermFleetWrite, s.handleDispatchCommand))
+	mux.HandleFunc("POST /api/v1/probes/{id}/batch", s.withPermission(auth.PermFleetWrite, s.handleCommandBatch))
 	mux.HandleFunc("POST /api/v1/probes/{id}/command/simulate", s.withPermission(auth.PermFleetWrite, s.handleSimulateCommandPolicy))
 	mux.HandleFunc("POST /api/v1/probes/{id}/rotate-key", s.withPermission(auth.PermFleetWrite, s.handleRotateKey))
 	mux.HandleFunc("GET /api/v1/probes/{id}/certificates", s.withPermission(auth.PermFleetRead, s.handleListProbeCertificates))
@@ -70,11 +83,25 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/v1/probes/{id}/certificates/issue", s.withPermission(auth.PermFleetWrite, s.handleIssueProbeCertificate))
 	mux.HandleFunc("POST /api/v1/probes/{id}/update", s.withPermission(auth.PermFleetWrite, s.handleProbeUpdate))
 	mux.HandleFunc("PUT /api/v1/probes/{id}/tags", s.withPermission(auth.PermFleetWrite, s.handleSetTags))
+	mux.HandleFunc("PUT /api/v1/probes/{id}/annotations", s.withPermission(auth.PermFleetWrite, s.handleSetAnnotations))
+	mux.HandleFunc("PUT /api/v1/probes/{id}/offline-threshold", s.withPermission(auth.PermFleetWrite, s.handleSetOfflineThreshold))
+	mux.HandleFunc("POST /api/v1/probes/{id}/drain", s.withPermission(auth.PermFleetWrite, s.handleDrainProbe))
+	mux.HandleFunc("POST /api/v1/probes/{id}/undrain", s.withPermission(auth.PermFleetWrite, s.handleUndrainProbe))
 	mux.HandleFunc("POST /api/v1/probes/{id}/apply-policy/{policyId}", s.withPermission(auth.PermFleetWrite, s.handleApplyPolicy))
+	mux.HandleFunc("GET /api/v1/probes/{id}/policy/history", s.withPermission(auth.PermFleetRead, s.handleProbePolicyHistory))
+	mux.HandleFunc("GET /api/v1/probes/{id}/policy/effective", s.withPermission(auth.PermFleetRead, s.handleProbeEffectivePolicy))
+	mux.HandleFunc("POST /api/v1/probes/{id}/policy/rollback", s.withPermission(auth.PermFleetWrite, s.handleProbePolicyRollback))
+	mux.HandleFunc("GET /api/v1/probes/{id}/local-schedule", s.withPermission(auth.PermFleetRead, s.handleGetLocalSchedule))
+	mux.HandleFunc("PUT /api/v1/probes/{id}/local-schedule", s.withPermission(auth.PermFleetWrite, s.handleSetLocalSchedule))
+	mux.HandleFunc("DELETE /api/v1/probes/{id}/local-schedule", s.withPermission(auth.PermFleetWrite, s.handleDeleteLocalSchedule))
 	mux.HandleFunc("POST /api/v1/probes/{id}/task", s.withPermission(auth.PermFleetWrite, s.handleTask))
+	mux.HandleFunc("POST /api/v1/connectivity/check", s.withPermission(auth.PermCommandExec, s.handleConnectivityCheck))
+	mux.HandleFunc("GET /api/v1/probes/{id}/task/stream", s.withPermission(auth.PermFleetWrite, s.handleTaskStream))
+	mux.HandleFunc("GET /api/v1/probes/{id}/logs/tail", s.withPermission(auth.PermFleetWrite, s.handleProbeLogTail))
 	mux.HandleFunc("DELETE /api/v1/probes/{id}", s.withPermission(auth.PermFleetWrite, s.handleDeleteProbe))
 	mux.HandleFunc("GET /api/v1/fleet/summary", s.withPermission(auth.PermFleetRead, s.handleFleetSummary))
 	mux.HandleFunc("GET /api/v1/reliability/scorecard", s.withPermission(auth.PermFleetRead, s.handleReliabilityScorecard))
+	mux.HandleFunc("GET /api/v1/reliability/trend", s.withPermission(auth.PermFleetRead, s.handleReliabilityTrend))
 
 	// Failure drills
 	if s.drillRunner != nil {
@@ -106,15 +133,22 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 		mux.HandleFunc("DELETE /api/v1/reliability/incidents/{id}", s.withPermission(auth.PermFleetWrite, s.handleIncidentsUnavailable))
 	}
 	mux.HandleFunc("GET /api/v1/fleet/inventory", s.withPermission(auth.PermFleetRead, s.handleFleetInventory))
+	mux.HandleFunc("GET /api/v1/fleet/inventory/export", s.withPermission(auth.PermFleetRead, s.handleFleetInventoryExport))
 	mux.HandleFunc("GET /api/v1/federation/inventory", s.withPermission(auth.PermFleetRead, s.handleFederationInventory))
 	mux.HandleFunc("GET /api/v1/federation/summary", s.withPermission(auth.PermFleetRead, s.handleFederationSummary))
 	mux.HandleFunc("GET /api/v1/fleet/tags", s.withPermission(auth.PermFleetRead, s.handleFleetTags))
 	mux.HandleFunc("GET /api/v1/fleet/by-tag/{tag}", s.withPermission(auth.PermFleetRead, s.handleListByTag))
 	mux.HandleFunc("POST /api/v1/fleet/by-tag/{tag}/command", s.withPermission(auth.PermFleetWrite, s.handleGroupCommand))
+	mux.HandleFunc("POST /api/v1/fleet/by-tag/{tag}/update", s.withPermission(auth.PermFleetWrite, s.handleTagUpdate))
+	mux.HandleFunc("GET /api/v1/fleet/search", s.withPermission(auth.PermFleetRead, s.handleFleetSearch))
+	mux.HandleFunc("POST /api/v1/fleet/command", s.withPermission(auth.PermFleetWrite, s.handleSelectorGroupCommand))
+	mux.HandleFunc("GET /api/v1/fleet/rollouts", s.withPermission(auth.PermFleetRead, s.handleListRollouts))
+	mux.HandleFunc("GET /api/v1/fleet/rollouts/{id}", s.withPermission(auth.PermFleetRead, s.handleGetRollout))
 	mux.HandleFunc("POST /api/v1/fleet/cleanup", s.withPermission(auth.PermFleetWrite, s.handleFleetCleanup))
 
 	// Registration
-	mux.HandleFunc("POST /api/v1/register", api.HandleRegisterWithAudit(s.tokenStore, s.fleetMgr, s.auditRecorder(), s.logger.Named("register")))
+	mux.HandleFunc("POST /api/v1/probe/token", s.handleProbeTokenExchange)
+	mux.HandleFunc("POST /api/v1/register", api.HandleRegisterWithPolicy(s.tokenStore, s.fleetMgr, s.auditRecorder(), s.assignPolicyByTags, s.logger.Named("register")))
 	mux.HandleFunc("POST /api/v1/tokens", s.withPermission(auth.PermFleetWrite, api.HandleGenerateTokenWithAudit(s.tokenStore, s.auditRecorder(), s.logger.Named("tokens"))))
 	mux.HandleFunc("GET /api/v1/tokens", s.withPermission(auth.PermAdmin, api.HandleListTokens(s.tokenStore)))
 
@@ -123,11 +157,13 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 		mux.HandleFunc("POST /api/v1/discovery/scan", s.withPermission(auth.PermFleetWrite, s.discoveryHandlers.HandleScan))
 		mux.HandleFunc("GET /api/v1/discovery/runs", s.withPermission(auth.PermFleetRead, s.discoveryHandlers.HandleListRuns))
 		mux.HandleFunc("GET /api/v1/discovery/runs/{id}", s.withPermission(auth.PermFleetRead, s.discoveryHandlers.HandleGetRun))
+		mux.HandleFunc("GET /api/v1/discovery/runs/{id}/diff", s.withPermission(auth.PermFleetRead, s.discoveryHandlers.HandleDiffRuns))
 		mux.HandleFunc("POST /api/v1/discovery/install-token", s.withPermission(auth.PermFleetWrite, s.discoveryHandlers.HandleInstallToken))
 	} else {
 		mux.HandleFunc("POST /api/v1/discovery/scan", s.withPermission(auth.PermFleetWrite, s.handleDiscoveryUnavailable))
 		mux.HandleFunc("GET /api/v1/discovery/runs", s.withPermission(auth.PermFleetRead, s.handleDiscoveryUnavailable))
 		mux.HandleFunc("GET /api/v1/discovery/runs/{id}", s.withPermission(auth.PermFleetRead, s.handleDiscoveryUnavailable))
+		mux.HandleFunc("GET /api/v1/discovery/runs/{id}/diff", s.withPermission(auth.PermFleetRead, s.handleDiscoveryUnavailable))
 		mux.HandleFunc("POST /api/v1/discovery/install-token", s.withPermission(auth.PermFleetWrite, s.handleDiscoveryUnavailable))
 	}
 	// Deployment candidate API (probe-deploys-probe lateral discovery)
@@ -173,12 +209,26 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 		s.asyncJobsScheduler,
 	)
 	s.webhookNotifier.SetDeliveryObserver(metricsCollector)
+	if s.auditFwd != nil {
+		metricsCollector.SetAuditForwarder(s.auditFwd)
+	}
 	mux.HandleFunc("GET /api/v1/metrics", s.withPermission(auth.PermFleetRead, metricsCollector.Handler()))
 
 	// Approvals
 	mux.HandleFunc("GET /api/v1/approvals", s.withPermission(auth.PermApprovalRead, s.handleListApprovals))
 	mux.HandleFunc("GET /api/v1/approvals/{id}", s.withPermission(auth.PermApprovalRead, s.handleGetApproval))
 	mux.HandleFunc("POST /api/v1/approvals/{id}/decide", s.withPermission(auth.PermApprovalWrite, s.handleDecideApproval))
+	mux.HandleFunc("POST /api/v1/approvals/decide-bulk", s.withPermission(auth.PermApprovalWrite, s.handleDecideApprovalsBulk))
+	mux.HandleFunc("GET /api/v1/approval-rules", s.withPermission(auth.PermApprovalRead, s.handleListApprovalRules))
+	mux.HandleFunc("POST /api/v1/approval-rules", s.withPermission(auth.PermAdmin, s.handleCreateApprovalRule))
+	mux.HandleFunc("DELETE /api/v1/approval-rules/{id}", s.withPermission(auth.PermAdmin, s.handleDeleteApprovalRule))
+	mux.HandleFunc("GET /api/v1/risk-rules", s.withPermission(auth.PermApprovalRead, s.handleListRiskRules))
+	mux.HandleFunc("POST /api/v1/risk-rules", s.withPermission(auth.PermAdmin, s.handleCreateRiskRule))
+	mux.HandleFunc("DELETE /api/v1/risk-rules/{id}", s.withPermission(auth.PermAdmin, s.handleDeleteRiskRule))
+	mux.HandleFunc("GET /api/v1/system/stores", s.withPermission(auth.PermAdmin, s.handleStoreHealth))
+	mux.HandleFunc("GET /api/v1/command-templates", s.withPermission(auth.PermCommandExec, s.handleListCommandTemplates))
+	mux.HandleFunc("POST /api/v1/command-templates", s.withPermission(auth.PermAdmin, s.handleCreateCommandTemplate))
+	mux.HandleFunc("DELETE /api/v1/command-templates/{id}", s.withPermission(auth.PermAdmin, s.handleDeleteCommandTemplate))
 
 	// Audit
 	mux.HandleFunc("GET /api/v1/audit", s.withPermission(auth.PermAuditRead, s.handleAuditLog))
@@ -210,11 +260,17 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/policies", s.withPermission(auth.PermFleetRead, s.handleListPolicies))
 	mux.HandleFunc("GET /api/v1/policies/{id}", s.withPermission(auth.PermFleetRead, s.handleGetPolicy))
 	mux.HandleFunc("POST /api/v1/policies", s.withPermission(auth.PermFleetWrite, s.handleCreatePolicy))
+	mux.HandleFunc("GET /api/v1/policies/export", s.withPermission(auth.PermFleetRead, s.handleExportPolicies))
+	mux.HandleFunc("POST /api/v1/policies/import", s.withPermission(auth.PermFleetWrite, s.handleImportPolicies))
 	mux.HandleFunc("DELETE /api/v1/policies/{id}", s.withPermission(auth.PermFleetWrite, s.handleDeletePolicy))
+	mux.HandleFunc("GET /api/v1/policy-tag-rules", s.withPermission(auth.PermFleetRead, s.handleListPolicyTagRules))
+	mux.HandleFunc("POST /api/v1/policy-tag-rules", s.withPermission(auth.PermFleetWrite, s.handleCreatePolicyTagRule))
+	mux.HandleFunc("DELETE /api/v1/policy-tag-rules/{id}", s.withPermission(auth.PermFleetWrite, s.handleDeletePolicyTagRule))
 
 	// Webhooks
 	mux.HandleFunc("GET /api/v1/webhooks", s.withPermission(auth.PermWebhookManage, s.webhookNotifier.ListWebhooks))
 	mux.HandleFunc("GET /api/v1/webhooks/deliveries", s.withPermission(auth.PermWebhookManage, s.webhookNotifier.ListDeliveries))
+	mux.HandleFunc("GET /api/v1/webhooks/dead-letters", s.withPermission(auth.PermWebhookManage, s.webhookNotifier.ListDeadLetters))
 	mux.HandleFunc("POST /api/v1/webhooks", s.withPermission(auth.PermWebhookManage, s.webhookNotifier.RegisterWebhook))
 	mux.HandleFunc("GET /api/v1/webhooks/{id}", s.withPermission(auth.PermWebhookManage, s.webhookNotifier.GetWebhook))
 	mux.HandleFunc("DELETE /api/v1/webhooks/{id}", s.withPermission(auth.PermWebhookManage, s.webhookNotifier.DeleteWebhook))
@@ -300,6 +356,7 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	// Async job approval — always registered (approval works regardless of scheduled jobs)
 	mux.HandleFunc("POST /api/v1/jobs/{id}/approve", s.withPermission(auth.PermApprovalWrite, s.handleApproveAsyncJob))
 	mux.HandleFunc("POST /api/v1/jobs/{id}/reject", s.withPermission(auth.PermApprovalWrite, s.handleRejectAsyncJob))
+	mux.HandleFunc("GET /api/v1/jobs/concurrency", s.withPermission(auth.PermFleetRead, s.handleJobConcurrency))
 
 	// Runner manager + ephemeral run token contract.
 	mux.HandleFunc("POST /api/v1/runners", s.withPermission(auth.PermCommandExec, s.handleCreateRunner))
@@ -308,7 +365,10 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("DELETE /api/v1/runners/{id}", s.withPermission(auth.PermCommandExec, s.handleDestroyRunner))
 	mux.HandleFunc("POST /api/v1/runs", s.withPermission(auth.PermCommandExec, s.handleIssueRunToken))
 	mux.HandleFunc("POST /api/v1/runs/{id}/artifacts/presign", s.withPermission(auth.PermCommandExec, s.handlePresignRunnerArtifact))
+	mux.HandleFunc("GET /api/v1/runs/{id}/artifacts", s.withPermission(auth.PermFleetRead, s.handleListRunArtifacts))
+	mux.HandleFunc("GET /api/v1/runs/{id}/artifacts/{artifact...}", s.withPermission(auth.PermFleetRead, s.handleGetRunArtifact))
 	mux.HandleFunc("POST /api/v1/runs/{id}/provider-proxy", s.withPermission(auth.PermCommandExec, s.handleProviderProxy))
+	mux.HandleFunc("GET /api/v1/provider-proxy/budget", s.withPermission(auth.PermFleetRead, s.handleProviderProxyBudget))
 
 	// Runner artifact transfers use presigned URLs and do not require API keys.
 	mux.HandleFunc("PUT /artifacts/runs/{id}/{path...}", s.handleUploadRunnerArtifact)
@@ -501,10 +561,10 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 		mux.HandleFunc("DELETE /api/v1/network/devices/{id}", s.withPermission(auth.PermFleetWrite, s.networkDeviceHandlers.HandleDeleteDevice))
 		mux.HandleFunc("POST /api/v1/network/devices/{id}/test", s.withPermission(auth.PermFleetWrite, s.networkDeviceHandlers.HandleTestDevice))
 		mux.HandleFunc("POST /api/v1/network/devices/{id}/inventory", s.withPermission(auth.PermFleetWrite, s.networkDeviceHandlers.HandleInventoryDevice))
-		mux.HandleFunc("POST /api/v1/network/devices/{id}/command", s.withPermission(auth.PermFleetWrite, s.networkDeviceHandlers.HandleCommandDevice))
+		mux.HandleFunc("POST /api/v1/network/devices/{id}/command", s.withPermission(auth.PermFleetWrite, s.handleNetworkDeviceCommand))
 		mux.HandleFunc("POST /api/v1/network/devices/{id}/scan", s.withPermission(auth.PermFleetWrite, s.networkDeviceHandlers.HandleScanDevice))
 		mux.HandleFunc("GET /api/v1/network/devices/{id}/inventory", s.withPermission(auth.PermFleetRead, s.networkDeviceHandlers.HandleGetInventory))
-		mux.HandleFunc("POST /api/v1/network-devices/{id}/command", s.withPermission(auth.PermFleetWrite, s.networkDeviceHandlers.HandleCommandDevice))
+		mux.HandleFunc("POST /api/v1/network-devices/{id}/command", s.withPermission(auth.PermFleetWrite, s.handleNetworkDeviceCommand))
 		mux.HandleFunc("POST /api/v1/network-devices/{id}/scan", s.withPermission(auth.PermFleetWrite, s.networkDeviceHandlers.HandleScanDevice))
 		mux.HandleFunc("GET /api/v1/network-devices/{id}/inventory", s.withPermission(auth.PermFleetRead, s.networkDeviceHandlers.HandleGetInventory))
 		mux.HandleFunc("POST /api/v1/network/devices/{id}/enrich", s.withPermission(auth.PermFleetWrite, s.networkDeviceHandlers.HandleEnrichDevice))
@@ -764,8 +824,16 @@ func (s *Server) handleGetProbe(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
 		return
 	}
+	var keyAge int64
+	if ps.APIKey != "" {
+		keyAge = int64(ps.KeyAge(time.Now().UTC()).Seconds())
+	}
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(ps)
+	_ = json.NewEncoder(w).Encode(struct {
+		*fleet.ProbeState
+		KeyAgeSeconds int64 `json:"key_age_seconds,omitempty"`
+		offlineThresholdView
+	}{ps, keyAge, s.offlineThresholdFor(ps)})
 }
 
 func (s *Server) handleCreateProbe(w http.ResponseWriter, r *http.Request) {
@@ -787,6 +855,9 @@ func (s *Server) handleCreateProbe(w http.ResponseWriter, r *http.Request) {
 			AuthMode   string `json:"auth_mode"`
 			Password   string `json:"password"`
 			PrivateKey string `json:"private_key"`
+
+			HostKeyFingerprint string `json:"host_key_fingerprint"`
+			HostKeyPolicy      string `json:"host_key_policy"`
 		} `json:"remote"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -815,6 +886,9 @@ func (s *Server) handleCreateProbe(w http.ResponseWriter, r *http.Request) {
 			Port:     body.Remote.Port,
 			Username: strings.TrimSpace(body.Remote.Username),
 			AuthMode: strings.TrimSpace(body.Remote.AuthMode),
+
+			HostKeyFingerprint: body.Remote.HostKeyFingerprint,
+			HostKeyPolicy:      body.Remote.HostKeyPolicy,
 		},
 		Credentials: fleet.RemoteProbeCredentials{
 			Password:   strings.TrimSpace(body.Remote.Password),
@@ -870,16 +944,66 @@ func (s *Server) handleDispatchCommand(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
 		return
 	}
+	if ps.Draining {
+		writeJSONError(w, http.StatusConflict, "probe_draining", "probe is draining; undrain it to dispatch commands")
+		return
+	}
 
 	var body struct {
 		protocol.CommandPayload
-		BreakglassReason string `json:"breakglass_reason,omitempty"`
-		BreakglassToken  string `json:"breakglass_token,omitempty"`
+		BreakglassReason string            `json:"breakglass_reason,omitempty"`
+		BreakglassToken  string            `json:"breakglass_token,omitempty"`
+		ExpiresIn        string            `json:"expires_in,omitempty"`
+		Template         string            `json:"template,omitempty"`
+		Params           map[string]string `json:"params,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
 		return
 	}
+	// Retries may carry a fresh request_id; everything else must match the
+	// request the idempotency key was first used with.
+	fingerprintBody := body
+	fingerprintBody.RequestID = ""
+	bodyFingerprint := idempotencyFingerprint(fingerprintBody)
+	if templateID := strings.TrimSpace(body.Template); templateID != "" {
+		if body.Command != "" {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", "template and command are mutually exclusive")
+			return
+		}
+		if !s.renderCommandTemplate(w, ps.PolicyLevel, templateID, body.Params, &body.CommandPayload) {
+			return
+		}
+	}
+	if body.MaxOutputBytes < 0 || body.MaxOutputBytes > protocol.MaxCommandOutputBytes {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("max_output_bytes must be between 0 and %d", protocol.MaxCommandOutputBytes))
+		return
+	}
+	if body.WorkDir != "" || len(body.Env) > 0 {
+		// The probe checks work_dir against its policy; keys it would refuse
+		// anyway are rejected here before approval.
+		if strings.EqualFold(ps.Type, fleet.ProbeTypeRemote) {
+			writeJSONError(w, http.StatusConflict, "unsupported_probe", "work_dir and env require an agent probe")
+			return
+		}
+		if err := protocol.ValidateCommandEnv(body.Env); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+	}
+	var approvalTTL time.Duration
+	if strings.TrimSpace(body.ExpiresIn) != "" {
+		ttl, err := parseHumanDuration(body.ExpiresIn)
+		if err != nil || ttl <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", "expires_in must be a positive duration (e.g. 30m, 4h, 2d)")
+			return
+		}
+		if maxTTL := s.approvalQueue.MaxTTL(); ttl > maxTTL {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("expires_in exceeds the maximum approval TTL (%s)", maxTTL))
+			return
+		}
+		approvalTTL = ttl
+	}
 	cmd := body.CommandPayload
 	wantWait := r.URL.Query().Get("wait") == "true" || r.URL.Query().Get("wait") == "1"
 	wantStream := r.URL.Query().Get("stream") == "true" || r.URL.Query().Get("stream") == "1"
@@ -891,7 +1015,31 @@ func (s *Server) handleDispatchCommand(w http.ResponseWriter, r *http.Request) {
 	}
 	cmd = invokeInput.Command
 
+	// A retried request carrying an Idempotency-Key already seen from this
+	// caller for this probe gets the original command back instead of a
+	// second dispatch. Reusing the key with a different body is an error.
+	// The key is released again unless the command goes out or is queued.
+	idempotencyKey := strings.TrimSpace(r.Header.Get(idempotencyKeyHeader))
+	idempotencyScope := cmdtracker.IdempotencyScope{ProbeID: id, Actor: actorFromAuthContext(r.Context()), Key: idempotencyKey}
+	keepIdempotencyKey := false
+	if idempotencyKey != "" {
+		if prior, claimed := s.cmdTracker.ClaimIdempotencyKey(idempotencyScope, bodyFingerprint, cmd.RequestID); !claimed {
+			if prior.Fingerprint != bodyFingerprint {
+				writeJSONError(w, http.StatusUnprocessableEntity, "idempotency_key_mismatch", "Idempotency-Key was already used with a different request body")
+				return
+			}
+			writeIdempotentReplay(w, prior)
+			return
+		}
+		defer func() {
+			if !keepIdempotencyKey {
+				s.cmdTracker.ReleaseIdempotencyKey(idempotencyScope)
+			}
+		}()
+	}
+
 	decision := s.approvalCore.EvaluateCommandPolicyForProbe(r.Context(), id, &cmd, ps.PolicyLevel)
+	s.approvalCore.ApplyAutoApproveRules(id, actorFromAuthContext(r.Context()), &cmd, &decision)
 	w.Header().Set("X-Legator-Policy-Decision", string(decision.Outcome))
 	w.Header().Set("X-Legator-Execution-Lane", string(decision.Lane))
 	w.Header().Set("X-Legator-Gate-Outcome", string(decision.GateOutcome))
@@ -989,7 +1137,18 @@ func (s *Server) handleDispatchCommand(w http.ResponseWriter, r *http.Request) {
 	switch decision.Outcome {
 	case coreapprovalpolicy.CommandPolicyDecisionDeny:
 		s.failAsyncJobByRequestID(cmd.RequestID, fmt.Sprintf("command denied by policy: %s", decision.ReasonCode), "", nil)
-		s.emitAudit(audit.EventAuthorizationDenied, id, "api", fmt.Sprintf("Command denied by policy: %s (%s)", cmd.Command, decision.ReasonCode))
+		s.recordAudit(audit.Event{
+			Type:    audit.EventAuthorizationDenied,
+			ProbeID: id,
+			Actor:   "api",
+			Summary: fmt.Sprintf("Command denied by policy: %s (%s)", cmd.Command, decision.ReasonCode),
+			Detail: map[string]any{
+				"request_id":  cmd.RequestID,
+				"command":     cmd.Command,
+				"reason_code": decision.ReasonCode,
+				"denial":      decision.Rationale.Denial,
+			},
+		})
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusTooManyRequests)
 		_ = json.NewEncoder(w).Encode(map[string]any{
@@ -1001,6 +1160,7 @@ func (s *Server) handleDispatchCommand(w http.ResponseWriter, r *http.Request) {
 			"risk_tier":        decision.RiskTier,
 			"reason_code":      decision.ReasonCode,
 			"policy_rationale": decision.Rationale,
+			"denial":           decision.Rationale.Denial,
 			"message":          "Command denied by policy.",
 		})
 		return
@@ -1019,7 +1179,7 @@ func (s *Server) handleDispatchCommand(w http.ResponseWriter, r *http.Request) {
 			"api",
 			string(decision.Outcome),
 			decision.Rationale,
-			approval.SubmissionOptions{RequireSecondApprover: requireSecondApprover},
+			approval.SubmissionOptions{RequireSecondApprover: requireSecondApprover, TTL: approvalTTL},
 		)
 		if err != nil {
 			s.failAsyncJobByRequestID(cmd.RequestID, fmt.Sprintf("approval queue: %s", err.Error()), "", nil)
@@ -1030,6 +1190,10 @@ func (s *Server) handleDispatchCommand(w http.ResponseWriter, r *http.Request) {
 		if asyncJob != nil {
 			s.markAsyncJobWaitingApproval(asyncJob.ID, req.ID, &req.ExpiresAt, "command waiting for approval")
 		}
+		if idempotencyKey != "" {
+			keepIdempotencyKey = true
+			s.cmdTracker.SetIdempotentApproval(idempotencyScope, req.ID)
+		}
 		s.appendCommandStreamMarker(cmd.RequestID, cmdtracker.StreamEventApproval, "pending_approval", map[string]any{
 			"approval_id": req.ID,
 			"risk_level":  req.RiskLevel,
@@ -1037,7 +1201,7 @@ func (s *Server) handleDispatchCommand(w http.ResponseWriter, r *http.Request) {
 			"lane":        decision.Lane,
 		})
 		s.emitAudit(audit.EventApprovalRequest, id, "api",
-			fmt.Sprintf("Approval required for: %s (risk: %s, lane: %s)", cmd.Command, req.RiskLevel, decision.Lane))
+			fmt.Sprintf("Approval required for: %s (risk: %s, lane: %s)", approval.CommandLine(&cmd), req.RiskLevel, decision.Lane))
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusAccepted)
 		_ = json.NewEncoder(w).Encode(map[string]any{
@@ -1051,6 +1215,7 @@ func (s *Server) handleDispatchCommand(w http.ResponseWriter, r *http.Request) {
 			"expires_at":       req.ExpiresAt,
 			"policy_decision":  decision.Outcome,
 			"policy_rationale": decision.Rationale,
+			"denial":           decision.Rationale.Denial,
 			"message":          "Command requires human approval. Use POST /api/v1/approvals/{id}/decide to approve or deny.",
 		})
 		return
@@ -1063,6 +1228,7 @@ func (s *Server) handleDispatchCommand(w http.ResponseWriter, r *http.Request) {
 			writeJSONError(w, http.StatusBadGateway, "bad_gateway", dispatchErr.Error())
 			return
 		}
+		keepIdempotencyKey = true
 		if dispatchResult.Outcome == jobs.AsyncDispatchOutcomeQueued {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusAccepted)
@@ -1094,6 +1260,10 @@ func (s *Server) handleDispatchCommand(w http.ResponseWriter, r *http.Request) {
 	if strings.EqualFold(ps.Type, fleet.ProbeTypeRemote) {
 		projection := s.invokeRemoteCommand(r.Context(), ps, cmd, wantWait, wantStream)
 		if projection != nil && projection.Envelope != nil && projection.Envelope.Dispatched {
+			keepIdempotencyKey = true
+			if idempotencyKey != "" {
+				s.cmdTracker.RecordIdempotentResult(cmd.RequestID, projection.Envelope.Result)
+			}
 			s.emitAudit(audit.EventCommandSent, id, "api", fmt.Sprintf("Command dispatched (remote): %s", cmd.Command))
 			s.publishEvent(events.CommandDispatched, id, fmt.Sprintf("Remote command dispatched: %s", cmd.Command),
 				map[string]string{"request_id": projection.RequestID, "command": cmd.Command})
@@ -1114,6 +1284,7 @@ func (s *Server) handleDispatchCommand(w http.ResponseWriter, r *http.Request) {
 
 	projection := corecommanddispatch.InvokeCommandForSurface(r.Context(), invokeInput, s.dispatchCore)
 	if projection != nil && projection.Envelope != nil && projection.Envelope.Dispatched {
+		keepIdempotencyKey = true
 		s.emitAudit(audit.EventCommandSent, id, "api", fmt.Sprintf("Command dispatched: %s", cmd.Command))
 		s.publishEvent(events.CommandDispatched, id, fmt.Sprintf("Command dispatched: %s", cmd.Command),
 			map[string]string{"request_id": projection.RequestID, "command": cmd.Command})
@@ -1248,7 +1419,10 @@ func (s *Server) invokeRemoteCommand(ctx context.Context, ps *fleet.ProbeState,
 		if err != nil {
 			if errors.Is(err, context.DeadlineExceeded) {
 				err = corecommanddispatch.ErrTimeout
+			} else if errors.Is(err, fleet.ErrRemoteConnectTimeout) || errors.Is(err, fleet.ErrRemoteCommandTimeout) {
+				err = fmt.Errorf("%w: %w", corecommanddispatch.ErrTimeout, err)
 			}
+			s.auditRemoteHostKeyRejection(ps.ID, cmd.RequestID, err)
 			s.failAsyncJobByRequestID(cmd.RequestID, err.Error(), "", nil)
 			return &corecommanddispatch.CommandResultEnvelope{
 				RequestID:  cmd.RequestID,
@@ -1312,29 +1486,12 @@ func (s *Server) handleRotateKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newKey, err := api.GenerateAPIKey()
+	newKey, err := s.rotateProbeKey(ps.ID, "api", "Probe API key rotated")
 	if err != nil {
-		writeJSONError(w, http.StatusInternalServerError, "internal_error", "failed to generate api key")
-		return
-	}
-
-	previousKey := ps.APIKey
-	if err := s.fleetMgr.SetAPIKey(id, newKey); err != nil {
-		writeJSONError(w, http.StatusNotFound, "not_found", err.Error())
-		return
-	}
-
-	rotation := protocol.KeyRotationPayload{
-		NewKey: newKey,
-	}
-	if err := s.hub.SendTo(id, protocol.MsgKeyRotation, rotation); err != nil {
-		_ = s.fleetMgr.SetAPIKey(id, previousKey)
 		writeJSONError(w, http.StatusBadGateway, "bad_gateway", err.Error())
 		return
 	}
 
-	s.emitAudit(audit.EventProbeKeyRotated, id, "api", "Probe API key rotated")
-
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]string{
 		"status":   "rotated",
@@ -1363,22 +1520,60 @@ func (s *Server) handleProbeUpdate(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, "invalid_request", "url is required")
 		return
 	}
-
-	if err := s.hub.SendTo(id, protocol.MsgUpdate, upd); err != nil {
+	upd.Checksum = strings.ToLower(strings.TrimSpace(upd.Checksum))
+	if !isSHA256Hex(upd.Checksum) {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "checksum must be a sha256 hex digest")
+		return
+	}
+	requestID, err := s.sendProbeUpdate(id, upd)
+	if err != nil {
+		if errors.Is(err, errSignUpdateManifest) {
+			writeJSONError(w, http.StatusInternalServerError, "internal_error", err.Error())
+			return
+		}
 		writeJSONError(w, http.StatusBadGateway, "bad_gateway", err.Error())
 		return
 	}
 
-	s.emitAudit(audit.EventCommandSent, id, "api",
-		fmt.Sprintf("Update dispatched: %s → %s", upd.Version, upd.URL))
-
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]string{
-		"status":  "dispatched",
-		"version": upd.Version,
+		"status":     "dispatched",
+		"version":    upd.Version,
+		"request_id": requestID,
 	})
 }
 
+var errSignUpdateManifest = errors.New("failed to sign update manifest")
+
+// sendProbeUpdate signs (when command signing is enabled) and pushes an
+// update payload to one probe, returning the correlating request ID.
+func (s *Server) sendProbeUpdate(id string, upd protocol.UpdatePayload) (string, error) {
+	upd.RequestID = "upd-" + uuid.New().String()[:8]
+	if len(s.signingKey) > 0 {
+		sig, err := signing.NewSigner(signing.DeriveProbeKey(s.signingKey, id)).Sign(upd.Version, upd.Manifest())
+		if err != nil {
+			return "", errSignUpdateManifest
+		}
+		upd.Signature = sig
+	}
+
+	if err := s.hub.SendTo(id, protocol.MsgUpdate, upd); err != nil {
+		return "", err
+	}
+
+	s.emitAudit(audit.EventCommandSent, id, "api",
+		fmt.Sprintf("Update dispatched: %s → %s", upd.Version, upd.URL))
+	return upd.RequestID, nil
+}
+
+func isSHA256Hex(v string) bool {
+	if len(v) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(v)
+	return err == nil
+}
+
 func (s *Server) handleSetTags(w http.ResponseWriter, r *http.Request) {
 	if !s.requirePermission(w, r, auth.PermFleetWrite) {
 		return
@@ -1392,6 +1587,10 @@ func (s *Server) handleSetTags(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := s.fleetMgr.SetTags(id, body.Tags); err != nil {
+		if errors.Is(err, fleet.ErrInvalidTag) {
+			writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
 		writeJSONError(w, http.StatusNotFound, "not_found", err.Error())
 		return
 	}
@@ -1401,80 +1600,118 @@ func (s *Server) handleSetTags(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(map[string]any{"probe_id": id, "tags": ps.Tags})
 }
 
-func (s *Server) handleApplyPolicy(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleSetAnnotations(w http.ResponseWriter, r *http.Request) {
 	if !s.requirePermission(w, r, auth.PermFleetWrite) {
 		return
 	}
-	probeID := r.PathValue("id")
-	policyID := r.PathValue("policyId")
+	id := r.PathValue("id")
+	ps, ok := s.probeForRequest(r, id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return
+	}
 
-	result, err := s.approvalCore.ApplyPolicyTemplate(probeID, policyID, func(targetProbeID string, pol *protocol.PolicyUpdatePayload) error {
-		return s.hub.SendTo(targetProbeID, protocol.MsgPolicyUpdate, pol)
-	})
-	if err != nil {
-		switch {
-		case errors.Is(err, coreapprovalpolicy.ErrProbeNotFound):
-			writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
-		case errors.Is(err, coreapprovalpolicy.ErrPolicyTemplateNotFound):
-			writeJSONError(w, http.StatusNotFound, "not_found", "policy template not found")
-		default:
-			writeJSONError(w, http.StatusInternalServerError, "internal_error", err.Error())
-		}
+	var body struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
 		return
 	}
-	if !result.Pushed {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"status": "applied_locally",
-			"note":   "probe offline, policy saved but not pushed",
-		})
+	annotations, err := fleet.NormalizeAnnotations(body.Annotations)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
 		return
 	}
 
-	s.emitAudit(audit.EventPolicyChanged, probeID, "api",
-		fmt.Sprintf("Policy %s (%s) applied", result.Template.Name, result.Template.ID))
+	before := ps.Annotations
+	if err := s.fleetMgr.SetAnnotations(id, annotations); err != nil {
+		writeJSONError(w, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+	s.recordAudit(audit.Event{
+		Type:    audit.EventProbeAnnotated,
+		ProbeID: id,
+		Actor:   actorFromAuthContext(r.Context()),
+		Summary: fmt.Sprintf("Annotations set: %d entries", len(annotations)),
+		Before:  before,
+		After:   annotations,
+	})
 
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{
-		"status":    "applied",
-		"probe_id":  probeID,
-		"policy_id": policyID,
-		"level":     string(result.Template.Level),
-	})
+	_ = json.NewEncoder(w).Encode(map[string]any{"probe_id": id, "annotations": annotations})
 }
 
-func (s *Server) handleTask(w http.ResponseWriter, r *http.Request) {
-	if !s.requirePermission(w, r, auth.PermCommandExec) {
+func (s *Server) handleDrainProbe(w http.ResponseWriter, r *http.Request) {
+	s.setProbeDraining(w, r, true)
+}
+
+func (s *Server) handleUndrainProbe(w http.ResponseWriter, r *http.Request) {
+	s.setProbeDraining(w, r, false)
+}
+
+// setProbeDraining toggles drain mode. A draining probe stays connected and
+// keeps heartbeating, but receives no new commands, group commands or job runs.
+func (s *Server) setProbeDraining(w http.ResponseWriter, r *http.Request, draining bool) {
+	if !s.requirePermission(w, r, auth.PermFleetWrite) {
 		return
 	}
-	if s.taskRunner == nil {
-		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "no active LLM provider configured. Set LEGATOR_LLM_* env vars or activate a model profile in Model Dock")
+	id := r.PathValue("id")
+	if err := s.fleetMgr.SetDraining(id, draining); err != nil {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
 		return
 	}
-	if s.taskRunner == s.managedTaskRunner && s.modelProviderMgr != nil && !s.modelProviderMgr.HasActiveProvider() {
-		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "no active LLM provider configured. Set LEGATOR_LLM_* env vars or activate a model profile in Model Dock")
+	if draining {
+		s.emitAudit(audit.EventProbeDrained, id, "api", "Probe drained: new command dispatch paused")
+	} else {
+		s.emitAudit(audit.EventProbeUndrained, id, "api", "Probe undrained: command dispatch resumed")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"probe_id": id, "draining": draining})
+}
+
+func (s *Server) handleApplyPolicy(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetWrite) {
 		return
 	}
+	s.applyProbePolicy(w, r, r.PathValue("id"), r.PathValue("policyId"), controlpolicy.AssignmentApply)
+}
 
-	id := r.PathValue("id")
-	ps, ok := s.fleetMgr.Get(id)
+func (s *Server) handleTask(w http.ResponseWriter, r *http.Request) {
+	ps, ok := s.taskProbeForRequest(w, r)
 	if !ok {
-		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
 		return
 	}
 
 	var req struct {
-		Task string `json:"task"`
+		Task              string                  `json:"task"`
+		Endpoints         []connectivity.Endpoint `json:"endpoints,omitempty"`
+		FailOnUnreachable bool                    `json:"fail_on_unreachable,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Task == "" {
 		writeJSONError(w, http.StatusBadRequest, "invalid_request", "task is required")
 		return
 	}
+	if err := connectivity.Validate(req.Endpoints); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	opts := llm.TaskOptions{Endpoints: req.Endpoints, FailOnUnreachable: req.FailOnUnreachable}
+
+	if r.URL.Query().Get("stream") == "true" {
+		s.streamTask(w, r, ps, req.Task, opts)
+		return
+	}
 
-	s.logger.Info("task submitted", zap.String("probe", id), zap.String("task", req.Task))
-	s.emitAudit(audit.EventCommandSent, id, "llm-task", fmt.Sprintf("Task submitted: %s", req.Task))
+	id := ps.ID
+	s.logger.Info("task submitted", zap.String("probe", id), zap.String("task", redactForProbe(ps, req.Task)))
+	s.emitAudit(audit.EventCommandSent, id, "llm-task", fmt.Sprintf("Task submitted: %s", redactForProbe(ps, req.Task)))
 
-	result, err := s.taskRunner.Run(r.Context(), id, req.Task, ps.Inventory, ps.PolicyLevel)
+	ctx := s.withTaskProgress(r.Context(), ps, req.Task, nil)
+	result, err := s.taskRunner.RunWithOptions(ctx, id, req.Task, ps.Inventory, ps.PolicyLevel, opts)
 	if err != nil {
 		s.logger.Warn("task execution error", zap.String("probe", id), zap.Error(err))
 		if errors.Is(err, modeldock.ErrNoActiveProvider) {
@@ -1503,6 +1740,40 @@ func (s *Server) handleFleetInventory(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(inv)
 }
 
+func (s *Server) handleFleetInventoryExport(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "format must be csv or json")
+		return
+	}
+
+	probes := fleet.SelectInventoryProbes(s.probesForRequest(r), fleet.InventoryFilter{
+		Tag:    r.URL.Query().Get("tag"),
+		Status: r.URL.Query().Get("status"),
+	})
+
+	filename := fmt.Sprintf("legator-inventory-%s.%s", time.Now().UTC().Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	var err error
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		err = fleet.StreamInventoryJSON(r.Context(), w, probes)
+	} else {
+		w.Header().Set("Content-Type", "text/csv")
+		err = fleet.StreamInventoryCSV(r.Context(), w, probes)
+	}
+	if err != nil {
+		s.logger.Warn("stream fleet inventory export failed", zap.String("format", format), zap.Error(err))
+	}
+}
+
 func buildInventoryFromProbes(probes []*fleet.ProbeState, filter fleet.InventoryFilter) fleet.FleetInventory {
 	statusFilter := strings.ToLower(strings.TrimSpace(filter.Status))
 	tagFilter := strings.ToLower(strings.TrimSpace(filter.Tag))
@@ -1541,6 +1812,7 @@ func buildInventoryFromProbes(probes []*fleet.ProbeState, filter fleet.Inventory
 			PolicyLevel: ps.PolicyLevel,
 			Tags:        append([]string(nil), ps.Tags...),
 			LastSeen:    ps.LastSeen,
+			Annotations: ps.Annotations,
 		}
 		if ps.Inventory != nil {
 			if summary.Hostname == "" {
@@ -1661,15 +1933,20 @@ func (s *Server) handleFleetSummary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	scorecard := s.buildReliabilityScorecard(reliabilityDefaultWindow)
+	probes := s.probesForRequest(r)
 	counts := map[string]int{}
-	for _, ps := range s.probesForRequest(r) {
+	for _, ps := range probes {
 		counts[ps.Status]++
 	}
+	breakdown := fleet.Breakdown(probes)
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]any{
 		"counts":            counts,
 		"connected":         counts["online"],
 		"pending_approvals": s.approvalQueue.PendingCount(),
+		"versions":          breakdown.Versions,
+		"by_os":             breakdown.ByOS,
+		"by_policy_level":   breakdown.ByPolicyLevel,
 		"reliability":       scorecard,
 	})
 }
@@ -1721,14 +1998,30 @@ func (s *Server) handleGroupCommand(w http.ResponseWriter, r *http.Request) {
 	for _, ps := range s.probesForRequest(r) {
 		scopedSet[ps.ID] = true
 	}
-	probes := make([]*fleet.ProbeState, 0, len(byTag))
+	targets := make([]*fleet.ProbeState, 0, len(byTag))
 	for _, ps := range byTag {
 		if scopedSet[ps.ID] {
-			probes = append(probes, ps)
+			targets = append(targets, ps)
+		}
+	}
+	s.dispatchGroupCommand(w, r, "tag", tag, targets)
+}
+
+// dispatchGroupCommand sends the request body's command to every target
+// probe, skipping draining ones. targetKind ("tag" or "selector") and target
+// label the response and audit entry.
+func (s *Server) dispatchGroupCommand(w http.ResponseWriter, r *http.Request, targetKind, target string, targets []*fleet.ProbeState) {
+	probes := make([]*fleet.ProbeState, 0, len(targets))
+	skipped := make([]groupCommandResult, 0)
+	for _, ps := range targets {
+		if ps.Draining {
+			skipped = append(skipped, groupCommandResult{ProbeID: ps.ID, Status: "skipped", Reason: "draining"})
+			continue
 		}
+		probes = append(probes, ps)
 	}
-	if len(probes) == 0 {
-		writeJSONError(w, http.StatusNotFound, "not_found", "no probes with that tag")
+	if len(probes) == 0 && len(skipped) == 0 {
+		writeJSONError(w, http.StatusNotFound, "not_found", fmt.Sprintf("no probes with that %s", targetKind))
 		return
 	}
 
@@ -1737,32 +2030,47 @@ func (s *Server) handleGroupCommand(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
 		return
 	}
+	wantWait := r.URL.Query().Get("wait") == "true" || r.URL.Query().Get("wait") == "1"
 
-	results := make([]map[string]string, 0, len(probes))
+	results := make([]groupCommandResult, 0, len(probes)+len(skipped))
+	pending := make(map[int]*cmdtracker.PendingCommand, len(probes))
 	for _, ps := range probes {
 		rid := fmt.Sprintf("grp-%s-%d", ps.ID[:8], time.Now().UnixNano()%100000)
 		c := cmd
 		c.RequestID = rid
-		if err := s.hub.SendTo(ps.ID, protocol.MsgCommand, c); err != nil {
-			results = append(results, map[string]string{
-				"probe_id": ps.ID, "status": "error", "error": err.Error(),
-			})
-		} else {
-			results = append(results, map[string]string{
-				"probe_id": ps.ID, "status": "dispatched", "request_id": rid,
-			})
+		if wantWait {
+			pc, err := s.dispatchCore.DispatchTracked(ps.ID, c)
+			if err != nil {
+				results = append(results, groupCommandResult{ProbeID: ps.ID, Status: "error", Error: err.Error()})
+				continue
+			}
+			pending[len(results)] = pc
+		} else if err := s.hub.SendTo(ps.ID, protocol.MsgCommand, c); err != nil {
+			results = append(results, groupCommandResult{ProbeID: ps.ID, Status: "error", Error: err.Error()})
+			continue
 		}
+		results = append(results, groupCommandResult{ProbeID: ps.ID, Status: "dispatched", RequestID: rid})
 	}
 
-	s.emitAudit(audit.EventCommandSent, tag, "api",
-		fmt.Sprintf("Group command to %d probes (tag=%s): %s", len(probes), tag, cmd.Command))
+	s.emitAudit(audit.EventCommandSent, target, "api",
+		fmt.Sprintf("Group command to %d probes (%s=%s, %d draining skipped): %s", len(probes), targetKind, target, len(skipped), cmd.Command))
+
+	if wantWait {
+		awaitGroupResults(r.Context(), results, pending, groupCommandWait(cmd.Timeout), s.cmdTracker.Cancel)
+	}
+	results = append(results, skipped...)
 
+	resp := map[string]any{
+		targetKind: target,
+		"total":    len(probes),
+		"skipped":  len(skipped),
+		"results":  results,
+	}
+	if wantWait {
+		resp["summary"] = summarizeGroupResults(results)
+	}
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"tag":     tag,
-		"total":   len(probes),
-		"results": results,
-	})
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 // ── Approvals ────────────────────────────────────────────────
@@ -1824,7 +2132,10 @@ func (s *Server) handleDecideApproval(w http.ResponseWriter, r *http.Request) {
 	}
 
 	projection := orchestrateDecideApprovalHTTP(r.Body, func(body *coreapprovalpolicy.DecideApprovalRequest) (*coreapprovalpolicy.ApprovalDecisionResult, error) {
-		return s.approvalCore.DecideAndDispatch(id, body.Decision, body.DecidedBy, s.dispatchApprovedCommand)
+		if body.Source == "" {
+			body.Source = approval.DecisionSourceAPI
+		}
+		return s.approvalCore.DecideAndDispatchWithDetails(id, body.Decision, body.DecidedBy, body.Details(), s.dispatchApprovedCommand)
 	})
 	renderDecideApprovalHTTP(w, projection)
 }
@@ -2037,6 +2348,7 @@ func auditFilterFromRequest(r *http.Request, allowUntil bool) (audit.Filter, err
 			filter.Until = until
 		}
 	}
+	filter.Query = strings.TrimSpace(r.URL.Query().Get("q"))
 	filter.Cursor = strings.TrimSpace(r.URL.Query().Get("cursor"))
 
 	return filter, nil
@@ -2097,6 +2409,13 @@ func (s *Server) handleSSEStream(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusInternalServerError, "internal_error", "streaming not supported")
 		return
 	}
+	release, ok := s.acquireSSEClient(w)
+	if !ok {
+		return
+	}
+	defer release()
+	keepalive, stopKeepalive := s.sseKeepalive()
+	defer stopKeepalive()
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -2110,6 +2429,8 @@ func (s *Server) handleSSEStream(w http.ResponseWriter, r *http.Request) {
 			select {
 			case <-r.Context().Done():
 				return
+			case <-keepalive:
+				writeSSEKeepalive(w, flusher)
 			case chunk := <-sub.Ch:
 				data, _ := json.Marshal(chunk)
 				fmt.Fprintf(w, "data: %s\n\n", data)
@@ -2155,6 +2476,8 @@ func (s *Server) handleSSEStream(w http.ResponseWriter, r *http.Request) {
 		select {
 		case <-r.Context().Done():
 			return
+		case <-keepalive:
+			writeSSEKeepalive(w, flusher)
 		case evt := <-sub.Ch:
 			if !writeCommandStreamSSEEvent(w, flusher, evt) {
 				return
@@ -2286,33 +2609,106 @@ func (s *Server) handleEventsSSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	query, replaying, err := eventReplayQueryFromRequest(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	release, ok := s.acquireSSEClient(w)
+	if !ok {
+		return
+	}
+	defer release()
+	keepalive, stopKeepalive := s.sseKeepalive()
+	defer stopKeepalive()
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	subID := fmt.Sprintf("sse-%d", time.Now().UnixNano())
-	ch := s.eventBus.Subscribe(subID)
+	replay, ch := s.eventBus.SubscribeWithReplay(subID, query)
 	defer s.eventBus.Unsubscribe(subID)
 
 	// Send initial keepalive
 	fmt.Fprintf(w, ": connected\n\n")
 	flusher.Flush()
 
+	scope := s.resolveTenantScope(r.Context())
+	write := func(evt events.Event) {
+		if !s.eventVisible(scope, evt) {
+			return
+		}
+		fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evt.ID, evt.Type, evt.JSON())
+		flusher.Flush()
+	}
+	if replaying {
+		for _, evt := range replay {
+			write(evt)
+		}
+	}
+
 	for {
 		select {
 		case <-r.Context().Done():
 			return
+		case <-keepalive:
+			writeSSEKeepalive(w, flusher)
 		case evt, ok := <-ch:
 			if !ok {
 				return
 			}
-			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, evt.JSON())
-			flusher.Flush()
+			write(evt)
 		}
 	}
 }
 
+// eventReplayQueryFromRequest reads the replay position from the since
+// query parameter (an event ID or RFC3339 timestamp) or the Last-Event-ID
+// header sent by reconnecting EventSource clients. replaying is false when
+// neither is present.
+func eventReplayQueryFromRequest(r *http.Request) (query events.ReplayQuery, replaying bool, err error) {
+	if raw := strings.TrimSpace(r.URL.Query().Get("since")); raw != "" {
+		if id, parseErr := strconv.ParseInt(raw, 10, 64); parseErr == nil {
+			if id < 0 {
+				return query, false, fmt.Errorf("since must be a non-negative event id or RFC3339 timestamp")
+			}
+			query.AfterID = id
+		} else {
+			since, parseErr := parseRFC3339(raw)
+			if parseErr != nil {
+				return query, false, fmt.Errorf("since must be a non-negative event id or RFC3339 timestamp")
+			}
+			query.Since = since
+		}
+		replaying = true
+	}
+	if raw := strings.TrimSpace(r.Header.Get("Last-Event-ID")); raw != "" {
+		if id, parseErr := strconv.ParseInt(raw, 10, 64); parseErr == nil && id >= 0 {
+			if id > query.AfterID {
+				query.AfterID = id
+			}
+			replaying = true
+		}
+	}
+	return query, replaying, nil
+}
+
+// eventVisible reports whether evt may be sent to a subscriber with scope.
+// Probe events follow the probe's tenant; fleet-wide events are visible to
+// every subscriber.
+func (s *Server) eventVisible(scope tenant.Scope, evt events.Event) bool {
+	if scope.IsAdmin || evt.ProbeID == "" {
+		return true
+	}
+	ps, ok := s.fleetMgr.Get(evt.ProbeID)
+	if !ok {
+		return false
+	}
+	return scope.AllowsTenant(ps.TenantID)
+}
+
 // ── Policy templates ─────────────────────────────────────────
 
 func (s *Server) handleListPolicies(w http.ResponseWriter, r *http.Request) {
@@ -2337,35 +2733,35 @@ func (s *Server) handleGetPolicy(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(tpl)
 }
 
-func (s *Server) handleCreatePolicy(w http.ResponseWriter, r *http.Request) {
-	if !s.requirePermission(w, r, auth.PermFleetWrite) {
-		return
-	}
-	var body struct {
-		Name        string                   `json:"name"`
-		Description string                   `json:"description"`
-		Level       protocol.CapabilityLevel `json:"level"`
-		Allowed     []string                 `json:"allowed"`
-		Blocked     []string                 `json:"blocked"`
-		Paths       []string                 `json:"paths"`
-
-		ExecutionClassRequired protocol.ExecutionClass   `json:"execution_class_required"`
-		SandboxRequired        *bool                     `json:"sandbox_required"`
-		ApprovalMode           protocol.ApprovalMode     `json:"approval_mode"`
-		RequireSecondApprover  *bool                     `json:"require_second_approver"`
-		Breakglass             protocol.BreakglassPolicy `json:"breakglass"`
-		MaxRuntimeSec          int                       `json:"max_runtime_sec"`
-		AllowedScopes          []string                  `json:"allowed_scopes"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
-		return
-	}
-	if body.Name == "" {
-		writeJSONError(w, http.StatusBadRequest, "invalid_request", "name required")
-		return
-	}
-
+// policyTemplateRequest is the body of a policy template create, and of
+// each template in an import.
+type policyTemplateRequest struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Level       protocol.CapabilityLevel `json:"level"`
+	Allowed     []string                 `json:"allowed"`
+	Blocked     []string                 `json:"blocked"`
+	Paths       []string                 `json:"paths"`
+
+	ExecutionClassRequired protocol.ExecutionClass    `json:"execution_class_required"`
+	SandboxRequired        *bool                      `json:"sandbox_required"`
+	ApprovalMode           protocol.ApprovalMode      `json:"approval_mode"`
+	RequireSecondApprover  *bool                      `json:"require_second_approver"`
+	Breakglass             protocol.BreakglassPolicy  `json:"breakglass"`
+	MaxRuntimeSec          int                        `json:"max_runtime_sec"`
+	AllowedScopes          []string                   `json:"allowed_scopes"`
+	AlertWatch             *protocol.AlertWatchConfig `json:"alert_watch"`
+	MaxConcurrentCommands  int                        `json:"max_concurrent_commands"`
+	RateLimit              *protocol.CommandRateLimit `json:"rate_limit"`
+	HeartbeatIntervalSec   int                        `json:"heartbeat_interval_sec"`
+	InventoryIntervalSec   int                        `json:"inventory_interval_sec"`
+	WorkDirs               []string                   `json:"work_dirs"`
+	EnvKeys                []string                   `json:"env_keys"`
+}
+
+// templateOptions validates the request's policy v2 fields and returns them
+// merged over the level defaults.
+func (body policyTemplateRequest) templateOptions() (controlpolicy.TemplateOptions, error) {
 	opts := controlpolicy.DefaultTemplateOptionsForLevel(body.Level)
 	if body.ExecutionClassRequired != "" {
 		opts.ExecutionClassRequired = body.ExecutionClassRequired
@@ -2389,25 +2785,66 @@ func (s *Server) handleCreatePolicy(w http.ResponseWriter, r *http.Request) {
 	if body.AllowedScopes != nil {
 		opts.AllowedScopes = body.AllowedScopes
 	}
+	opts.AlertWatch = body.AlertWatch
+	if err := controlpolicy.ValidateMaxConcurrentCommands(body.MaxConcurrentCommands); err != nil {
+		return opts, err
+	}
+	opts.MaxConcurrentCommands = body.MaxConcurrentCommands
+	if err := controlpolicy.ValidateRateLimit(body.RateLimit); err != nil {
+		return opts, err
+	}
+	opts.RateLimit = body.RateLimit
+	if err := controlpolicy.ValidateReportingCadence(body.HeartbeatIntervalSec, body.InventoryIntervalSec); err != nil {
+		return opts, err
+	}
+	opts.HeartbeatIntervalSec = body.HeartbeatIntervalSec
+	opts.InventoryIntervalSec = body.InventoryIntervalSec
+	if err := controlpolicy.ValidateWorkDirs(body.WorkDirs); err != nil {
+		return opts, err
+	}
+	opts.WorkDirs = body.WorkDirs
+	if err := controlpolicy.ValidateEnvKeys(body.EnvKeys); err != nil {
+		return opts, err
+	}
+	opts.EnvKeys = body.EnvKeys
 	opts = controlpolicy.NormalizeTemplateOptions(opts)
 
 	if err := controlpolicy.ValidateExecutionClass(opts.ExecutionClassRequired); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
-		return
+		return opts, err
 	}
 	if err := controlpolicy.ValidateApprovalMode(opts.ApprovalMode); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
-		return
+		return opts, err
 	}
 	if opts.MaxRuntimeSec < 0 || opts.MaxRuntimeSec > controlpolicy.MaxPolicyRuntimeSec {
-		writeJSONError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("max_runtime_sec must be between 0 and %d", controlpolicy.MaxPolicyRuntimeSec))
-		return
+		return opts, fmt.Errorf("max_runtime_sec must be between 0 and %d", controlpolicy.MaxPolicyRuntimeSec)
 	}
 	if err := controlpolicy.ValidateBreakglass(opts.Breakglass); err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
-		return
+		return opts, err
 	}
 	if err := controlpolicy.ValidateAllowedScopes(opts.AllowedScopes); err != nil {
+		return opts, err
+	}
+	if err := controlpolicy.ValidateAlertWatch(opts.AlertWatch); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+func (s *Server) handleCreatePolicy(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermFleetWrite) {
+		return
+	}
+	var body policyTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+		return
+	}
+	if body.Name == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "name required")
+		return
+	}
+	opts, err := body.templateOptions()
+	if err != nil {
 		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
 		return
 	}
@@ -2433,26 +2870,35 @@ func (s *Server) handleDeletePolicy(w http.ResponseWriter, r *http.Request) {
 // ── Downloads ────────────────────────────────────────────────
 
 func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
-	filename := r.PathValue("filename")
-	releasesDir := filepath.Join(s.cfg.DataDir, "releases")
-	filePath := filepath.Join(releasesDir, filepath.Base(filename))
+	filename := filepath.Base(r.PathValue("filename"))
+	filePath := filepath.Join(s.releasesDir(), filename)
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		if strings.HasSuffix(filename, checksumSuffix) {
+			s.serveReleaseChecksum(w, r, filename)
+			return
+		}
 		http.NotFound(w, r)
 		return
 	}
 	w.Header().Set("Content-Type", "application/octet-stream")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(filename)))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
 	http.ServeFile(w, r, filePath)
 }
 
 func (s *Server) handleInstallScript(w http.ResponseWriter, r *http.Request) {
 	installScript := filepath.Join("install", "install.sh")
-	if _, err := os.Stat(installScript); os.IsNotExist(err) {
+	script, err := os.ReadFile(installScript)
+	if os.IsNotExist(err) {
 		writeJSONError(w, http.StatusNotFound, "not_found", "install script not found")
 		return
 	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "internal_error", "cannot read install script")
+		return
+	}
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	http.ServeFile(w, r, installScript)
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(s.embedInstallChecksums(script))
 }
 
 // ── Web UI pages ─────────────────────────────────────────────
@@ -2838,6 +3284,10 @@ func (s *Server) handleDeleteProbe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.healthHistory != nil {
+		_, _ = s.healthHistory.DeleteProbe(id)
+	}
+	s.resourceSeries.Delete(id)
 	s.emitAudit(audit.EventProbeDeregistered, id, "api", fmt.Sprintf("probe %s deleted", id))
 	s.logger.Info("probe deleted", zap.String("id", id))
 
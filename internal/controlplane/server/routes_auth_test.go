@@ -6,7 +6,8 @@ import (
 )
 
 // TestRoutesAuthCoverage verifies that every /api/v1/ endpoint (except /api/v1/register,
-// which is legitimately public for probe self-registration) requires authentication.
+// which is legitimately public for probe self-registration, and /api/v1/probe/token,
+// which authenticates with the probe's API key) requires authentication.
 //
 // This test acts as a regression guard: if a new endpoint is added without a
 // withPermission wrapper, unauthenticated requests will succeed (200/other) rather
@@ -28,21 +29,41 @@ func TestRoutesAuthCoverage(t *testing.T) {
 		{http.MethodPost, "/api/v1/probes/some-probe/rotate-key"},
 		{http.MethodPost, "/api/v1/probes/some-probe/update"},
 		{http.MethodPut, "/api/v1/probes/some-probe/tags"},
+		{http.MethodPut, "/api/v1/probes/some-probe/annotations"},
+		{http.MethodPut, "/api/v1/probes/some-probe/offline-threshold"},
+		{http.MethodPost, "/api/v1/probes/some-probe/drain"},
+		{http.MethodPost, "/api/v1/probes/some-probe/undrain"},
 		{http.MethodPost, "/api/v1/probes/some-probe/apply-policy/some-policy"},
+		{http.MethodGet, "/api/v1/probes/some-probe/policy/history"},
+		{http.MethodGet, "/api/v1/probes/some-probe/policy/effective"},
+		{http.MethodPost, "/api/v1/probes/some-probe/policy/rollback"},
 		{http.MethodPost, "/api/v1/probes/some-probe/task"},
+		{http.MethodGet, "/api/v1/probes/some-probe/task/stream"},
 		{http.MethodDelete, "/api/v1/probes/some-probe"},
 		// Fleet summary/inventory/tags
 		{http.MethodGet, "/api/v1/fleet/summary"},
 		{http.MethodGet, "/api/v1/fleet/inventory"},
+		{http.MethodGet, "/api/v1/fleet/inventory/export"},
 		{http.MethodGet, "/api/v1/fleet/tags"},
 		{http.MethodGet, "/api/v1/fleet/by-tag/some-tag"},
 		{http.MethodPost, "/api/v1/fleet/by-tag/some-tag/command"},
+		{http.MethodPost, "/api/v1/fleet/by-tag/some-tag/update"},
+		{http.MethodGet, "/api/v1/fleet/search"},
+		{http.MethodPost, "/api/v1/fleet/command"},
+		{http.MethodGet, "/api/v1/fleet/rollouts"},
+		{http.MethodGet, "/api/v1/fleet/rollouts/rol-1"},
 		{http.MethodPost, "/api/v1/fleet/cleanup"},
+		{http.MethodGet, "/api/v1/probes/probe-1/health/history"},
+		{http.MethodDelete, "/api/v1/probes/probe-1/health/history"},
+		{http.MethodGet, "/api/v1/probes/probe-1/metrics/series"},
+		{http.MethodPost, "/api/v1/connectivity/check"},
+		{http.MethodPost, "/api/v1/probes/probe-1/health/check"},
 		// Federation
 		{http.MethodGet, "/api/v1/federation/inventory"},
 		{http.MethodGet, "/api/v1/federation/summary"},
 		// Reliability
 		{http.MethodGet, "/api/v1/reliability/scorecard"},
+		{http.MethodGet, "/api/v1/reliability/trend"},
 		{http.MethodGet, "/api/v1/reliability/drills"},
 		{http.MethodGet, "/api/v1/reliability/drills/history"},
 		{http.MethodPost, "/api/v1/reliability/drills/some-drill/run"},
@@ -57,6 +78,18 @@ func TestRoutesAuthCoverage(t *testing.T) {
 		{http.MethodGet, "/api/v1/approvals"},
 		{http.MethodGet, "/api/v1/approvals/some-id"},
 		{http.MethodPost, "/api/v1/approvals/some-id/decide"},
+		{http.MethodPost, "/api/v1/approvals/decide-bulk"},
+		{http.MethodGet, "/api/v1/approval-rules"},
+		{http.MethodPost, "/api/v1/approval-rules"},
+		{http.MethodDelete, "/api/v1/approval-rules/some-id"},
+		{http.MethodGet, "/api/v1/risk-rules"},
+		{http.MethodPost, "/api/v1/risk-rules"},
+		{http.MethodDelete, "/api/v1/risk-rules/some-id"},
+		{http.MethodGet, "/api/v1/system/stores"},
+		{http.MethodGet, "/api/v1/command-templates"},
+		{http.MethodPost, "/api/v1/command-templates"},
+		{http.MethodDelete, "/api/v1/command-templates/some-id"},
+		{http.MethodGet, "/api/v1/provider-proxy/budget"},
 		// Audit
 		{http.MethodGet, "/api/v1/audit"},
 		{http.MethodGet, "/api/v1/audit/verify"},
@@ -75,6 +108,11 @@ func TestRoutesAuthCoverage(t *testing.T) {
 		{http.MethodGet, "/api/v1/policies/some-id"},
 		{http.MethodPost, "/api/v1/policies"},
 		{http.MethodDelete, "/api/v1/policies/some-id"},
+		{http.MethodGet, "/api/v1/policies/export"},
+		{http.MethodPost, "/api/v1/policies/import"},
+		{http.MethodGet, "/api/v1/policy-tag-rules"},
+		{http.MethodPost, "/api/v1/policy-tag-rules"},
+		{http.MethodDelete, "/api/v1/policy-tag-rules/some-id"},
 		// Tokens
 		{http.MethodGet, "/api/v1/tokens"},
 		{http.MethodPost, "/api/v1/tokens"},
@@ -96,6 +134,7 @@ func TestRoutesAuthCoverage(t *testing.T) {
 		{http.MethodDelete, "/api/v1/webhooks/some-id"},
 		{http.MethodPost, "/api/v1/webhooks/some-id/test"},
 		{http.MethodGet, "/api/v1/webhooks/deliveries"},
+		{http.MethodGet, "/api/v1/webhooks/dead-letters"},
 		// Alerts
 		{http.MethodGet, "/api/v1/alerts"},
 		{http.MethodPost, "/api/v1/alerts"},
@@ -115,6 +154,7 @@ func TestRoutesAuthCoverage(t *testing.T) {
 		// Jobs
 		{http.MethodGet, "/api/v1/jobs"},
 		{http.MethodGet, "/api/v1/jobs/runs"},
+		{http.MethodGet, "/api/v1/jobs/concurrency"},
 		{http.MethodPost, "/api/v1/jobs"},
 		{http.MethodGet, "/api/v1/jobs/some-id"},
 		{http.MethodPut, "/api/v1/jobs/some-id"},
@@ -134,6 +174,8 @@ func TestRoutesAuthCoverage(t *testing.T) {
 		{http.MethodDelete, "/api/v1/runners/some-runner"},
 		{http.MethodPost, "/api/v1/runs"},
 		{http.MethodPost, "/api/v1/runs/some-run/artifacts/presign"},
+		{http.MethodGet, "/api/v1/runs/some-run/artifacts"},
+		{http.MethodGet, "/api/v1/runs/some-run/artifacts/report.md"},
 		// Auth keys
 		{http.MethodGet, "/api/v1/auth/keys"},
 		{http.MethodPost, "/api/v1/auth/keys"},
@@ -142,6 +184,7 @@ func TestRoutesAuthCoverage(t *testing.T) {
 		{http.MethodPost, "/api/v1/discovery/scan"},
 		{http.MethodGet, "/api/v1/discovery/runs"},
 		{http.MethodGet, "/api/v1/discovery/runs/some-id"},
+		{http.MethodGet, "/api/v1/discovery/runs/some-id/diff"},
 		{http.MethodPost, "/api/v1/discovery/install-token"},
 		// Model profiles
 		{http.MethodGet, "/api/v1/model-profiles"},
@@ -216,9 +259,12 @@ func TestPublicEndpointsAreAccessibleWithoutAuth(t *testing.T) {
 		path   string
 	}{
 		{http.MethodGet, "/healthz"},
+		{http.MethodGet, "/readyz"},
 		{http.MethodGet, "/version"},
 		// /api/v1/register is public (probe self-registration with token)
 		// NOTE: POST /api/v1/register is excluded from auth coverage by design
+		// POST /api/v1/probe/token checks the probe API key itself (400 without an id)
+		{http.MethodPost, "/api/v1/probe/token"},
 	}
 
 	for _, ep := range public {
@@ -7,14 +7,14 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
+	"path"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/marcus-qen/legator/internal/controlplane/artifacts"
 	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
 	"go.uber.org/zap"
 )
 
@@ -154,29 +154,11 @@ func (s *Server) handleRunnerArtifactTransfer(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	storagePath, err := s.runnerArtifactStoragePath(claims.RunID, claims.Path)
-	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
-		return
-	}
-
 	switch op {
 	case artifacts.OperationUpload:
-		if err := os.MkdirAll(filepath.Dir(storagePath), 0o750); err != nil {
-			writeJSONError(w, http.StatusInternalServerError, "internal_error", "failed to create artifact directory")
-			return
-		}
-
-		f, err := os.OpenFile(storagePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+		written, err := s.runnerArtifacts.Write(claims.RunID, claims.Path, r.Body)
 		if err != nil {
-			writeJSONError(w, http.StatusInternalServerError, "internal_error", "failed to open artifact target")
-			return
-		}
-		defer f.Close()
-
-		written, err := io.Copy(f, r.Body)
-		if err != nil {
-			writeJSONError(w, http.StatusInternalServerError, "internal_error", "failed to store artifact")
+			s.writeArtifactStoreError(w, err)
 			return
 		}
 
@@ -201,23 +183,13 @@ func (s *Server) handleRunnerArtifactTransfer(w http.ResponseWriter, r *http.Req
 			"bytes_written": written,
 		})
 	case artifacts.OperationDownload:
-		f, err := os.Open(storagePath)
+		f, stat, err := s.runnerArtifacts.Open(claims.RunID, claims.Path)
 		if err != nil {
-			if errors.Is(err, os.ErrNotExist) {
-				writeJSONError(w, http.StatusNotFound, "not_found", "artifact not found")
-				return
-			}
-			writeJSONError(w, http.StatusInternalServerError, "internal_error", "failed to open artifact")
+			s.writeArtifactStoreError(w, err)
 			return
 		}
 		defer f.Close()
 
-		stat, err := f.Stat()
-		if err != nil {
-			writeJSONError(w, http.StatusInternalServerError, "internal_error", "failed to stat artifact")
-			return
-		}
-
 		w.Header().Set("Content-Type", "application/octet-stream")
 		w.Header().Set("Content-Length", strconv.FormatInt(stat.Size(), 10))
 		w.Header().Set("X-Legator-Artifact-Path", claims.Path)
@@ -243,6 +215,92 @@ func (s *Server) handleRunnerArtifactTransfer(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// handleListRunArtifacts lists the artifacts attached to a run.
+func (s *Server) handleListRunArtifacts(w http.ResponseWriter, r *http.Request) {
+	runID, ok := s.runArtifactRequest(w, r)
+	if !ok {
+		return
+	}
+	items, err := s.runnerArtifacts.List(runID)
+	if err != nil {
+		s.writeArtifactStoreError(w, err)
+		return
+	}
+	var total int64
+	for _, it := range items {
+		total += it.Size
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"run_id":      runID,
+		"artifacts":   items,
+		"count":       len(items),
+		"total_bytes": total,
+		"max_bytes":   s.runnerArtifacts.MaxRunBytes(),
+	})
+}
+
+// handleGetRunArtifact downloads one run artifact with API credentials,
+// as opposed to the presigned transfer URLs handed to runners.
+func (s *Server) handleGetRunArtifact(w http.ResponseWriter, r *http.Request) {
+	runID, ok := s.runArtifactRequest(w, r)
+	if !ok {
+		return
+	}
+	artifactPath := strings.TrimSpace(r.PathValue("artifact"))
+	f, stat, err := s.runnerArtifacts.Open(runID, artifactPath)
+	if err != nil {
+		s.writeArtifactStoreError(w, err)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(stat.Size(), 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(artifactPath)))
+	w.Header().Set("X-Legator-Artifact-Path", artifactPath)
+	if _, err := io.Copy(w, f); err != nil {
+		s.logger.Warn("run artifact download copy failed", zap.String("run_id", runID), zap.String("path", artifactPath), zap.Error(err))
+		return
+	}
+
+	s.recordAudit(audit.Event{
+		Type:    audit.EventRunnerArtifactDownloaded,
+		Actor:   actorFromAuthContext(r.Context()),
+		Summary: fmt.Sprintf("Run artifact downloaded: %s", runID),
+		Detail: map[string]any{
+			"run_id": runID,
+			"path":   artifactPath,
+			"bytes":  stat.Size(),
+		},
+	})
+}
+
+func (s *Server) runArtifactRequest(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if !s.requirePermission(w, r, auth.PermFleetRead) {
+		return "", false
+	}
+	if s.runnerArtifacts == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "runner artifact store unavailable")
+		return "", false
+	}
+	runID := strings.TrimSpace(r.PathValue("id"))
+	if runID == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "run id required")
+		return "", false
+	}
+	if s.workspaceIsolationEnabled() {
+		runWorkspaceID := ""
+		if s.runnerManager != nil {
+			runWorkspaceID, _ = s.runnerManager.WorkspaceForRun(runID)
+		}
+		if !s.enforceWorkspaceMatch(w, r, runWorkspaceID) {
+			return "", false
+		}
+	}
+	return runID, true
+}
+
 func (s *Server) recordRunnerArtifactDenied(runID, artifactPath string, op artifacts.Operation, err error) {
 	runID = strings.TrimSpace(runID)
 	artifactPath = strings.TrimSpace(artifactPath)
@@ -303,34 +361,21 @@ func (s *Server) writeArtifactTransferError(w http.ResponseWriter, err error) {
 	}
 }
 
-func (s *Server) runnerArtifactStoragePath(runID, artifactPath string) (string, error) {
-	runID = strings.TrimSpace(runID)
-	if runID == "" {
-		return "", artifacts.ErrRunIDRequired
-	}
-	if strings.Contains(runID, "/") || strings.Contains(runID, "\\") || strings.Contains(runID, "..") {
-		return "", fmt.Errorf("run_id contains invalid characters")
-	}
-	artifactPath = strings.TrimSpace(strings.ReplaceAll(artifactPath, "\\", "/"))
-	if artifactPath == "" {
-		return "", artifacts.ErrPathRequired
-	}
-	for _, part := range strings.Split(artifactPath, "/") {
-		part = strings.TrimSpace(part)
-		if part == "" || part == "." {
-			continue
-		}
-		if part == ".." {
-			return "", artifacts.ErrPathInvalid
-		}
-	}
-
-	root := filepath.Clean(filepath.Join(s.runnerArtifactsDir, runID))
-	target := filepath.Clean(filepath.Join(root, filepath.FromSlash(artifactPath)))
-	if target != root && !strings.HasPrefix(target, root+string(os.PathSeparator)) {
-		return "", artifacts.ErrPathInvalid
+func (s *Server) writeArtifactStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, artifacts.ErrRunIDRequired),
+		errors.Is(err, artifacts.ErrRunIDInvalid),
+		errors.Is(err, artifacts.ErrPathRequired),
+		errors.Is(err, artifacts.ErrPathInvalid):
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", err.Error())
+	case errors.Is(err, artifacts.ErrArtifactNotFound):
+		writeJSONError(w, http.StatusNotFound, "not_found", "artifact not found")
+	case errors.Is(err, artifacts.ErrRunQuotaExceeded):
+		writeJSONError(w, http.StatusRequestEntityTooLarge, "artifact_quota_exceeded", err.Error())
+	default:
+		s.logger.Warn("runner artifact store error", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "internal_error", "artifact storage failed")
 	}
-	return target, nil
 }
 
 func (s *Server) buildRunnerArtifactURL(r *http.Request, runID, artifactPath, token string) string {
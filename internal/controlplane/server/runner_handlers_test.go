@@ -390,6 +390,31 @@ func TestRunnerArtifactPresignedUploadAndDownload(t *testing.T) {
 	if got := download.Body.String(); got != "hello-from-runner" {
 		t.Fatalf("unexpected artifact body: got %q", got)
 	}
+
+	list := makeSessionRequest(t, srv, http.MethodGet, "/api/v1/runs/run-42/artifacts", sess.ID, "")
+	if list.Code != http.StatusOK {
+		t.Fatalf("list artifacts: status=%d body=%s", list.Code, list.Body.String())
+	}
+	var listResp struct {
+		Artifacts []struct {
+			Path string `json:"path"`
+			Size int64  `json:"size"`
+		} `json:"artifacts"`
+	}
+	if err := json.Unmarshal(list.Body.Bytes(), &listResp); err != nil {
+		t.Fatalf("decode artifact list: %v", err)
+	}
+	if len(listResp.Artifacts) != 1 || listResp.Artifacts[0].Path != "workspace/run-42/stdout.log" || listResp.Artifacts[0].Size != int64(len("hello-from-runner")) {
+		t.Fatalf("unexpected artifact list %+v", listResp.Artifacts)
+	}
+
+	get := makeSessionRequest(t, srv, http.MethodGet, "/api/v1/runs/run-42/artifacts/workspace/run-42/stdout.log", sess.ID, "")
+	if get.Code != http.StatusOK || get.Body.String() != "hello-from-runner" {
+		t.Fatalf("get artifact: status=%d body=%q", get.Code, get.Body.String())
+	}
+	if missing := makeSessionRequest(t, srv, http.MethodGet, "/api/v1/runs/run-42/artifacts/nope.txt", sess.ID, ""); missing.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing artifact, got %d", missing.Code)
+	}
 }
 
 func TestRunnerArtifactPresignedOutOfScopeRejectedWithAudit(t *testing.T) {
@@ -6,6 +6,7 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,8 +29,10 @@ import (
 	"github.com/marcus-qen/legator/internal/controlplane/chat"
 	"github.com/marcus-qen/legator/internal/controlplane/cloudconnectors"
 	"github.com/marcus-qen/legator/internal/controlplane/cmdtracker"
+	"github.com/marcus-qen/legator/internal/controlplane/commandtemplates"
 	"github.com/marcus-qen/legator/internal/controlplane/compliance"
 	"github.com/marcus-qen/legator/internal/controlplane/config"
+	"github.com/marcus-qen/legator/internal/controlplane/connectivity"
 	coreapprovalpolicy "github.com/marcus-qen/legator/internal/controlplane/core/approvalpolicy"
 	corecommanddispatch "github.com/marcus-qen/legator/internal/controlplane/core/commanddispatch"
 	"github.com/marcus-qen/legator/internal/controlplane/discovery"
@@ -38,6 +42,7 @@ import (
 	"github.com/marcus-qen/legator/internal/controlplane/jobs"
 	"github.com/marcus-qen/legator/internal/controlplane/kubeflow"
 	"github.com/marcus-qen/legator/internal/controlplane/llm"
+	"github.com/marcus-qen/legator/internal/controlplane/localschedule"
 	"github.com/marcus-qen/legator/internal/controlplane/mcpclient"
 	"github.com/marcus-qen/legator/internal/controlplane/mcpserver"
 	"github.com/marcus-qen/legator/internal/controlplane/metrics"
@@ -56,6 +61,7 @@ import (
 	"github.com/marcus-qen/legator/internal/controlplane/webhook"
 	cpws "github.com/marcus-qen/legator/internal/controlplane/websocket"
 	"github.com/marcus-qen/legator/internal/protocol"
+	"github.com/marcus-qen/legator/internal/shared/security"
 	"github.com/marcus-qen/legator/internal/shared/signing"
 	"go.uber.org/zap"
 )
@@ -69,7 +75,7 @@ var (
 
 const (
 	probeOfflineCheckInterval  = 30 * time.Second
-	probeOfflineThreshold      = 90 * time.Second
+	defaultOfflineThreshold    = 90 * time.Second
 	reliabilityDefaultWindow   = 15 * time.Minute
 	reliabilityTelemetryMaxAge = 24 * time.Hour
 )
@@ -79,29 +85,60 @@ type Server struct {
 	cfg    config.Config
 	logger *zap.Logger
 
+	// lifecycleCtx outlives requests and is cancelled on shutdown, for
+	// background work that handlers start (see lifecycleContext).
+	lifecycleCtx  context.Context
+	stopLifecycle context.CancelFunc
+
 	// Core subsystems
 	fleetMgr          fleet.Fleet
 	fleetStore        *fleet.Store
+	healthHistory     *fleet.HealthHistoryStore
+	resourceSeries    *fleet.ResourceSeries
+	connectivity      *connectivity.CachedChecker
+	rolloutMgr        *fleet.RolloutManager
 	federationStore   *fleet.FederationStore
+	netboxSource      *fleet.NetboxSourceAdapter
+	tailscaleSource   *fleet.TailscaleSourceAdapter
 	remoteExecutor    fleet.RemoteProbeExecutor
 	remoteScanner     *fleet.RemoteScanner
 	tokenStore        *api.TokenStore
 	cmdTracker        *cmdtracker.Tracker
 	commandStreams    *cmdtracker.StreamRecorder
+	logTails          logTailRegistry
+	policyQueries     policyQueryRegistry
+	releaseChecksums  releaseChecksumCache
 	approvalQueue     *approval.Queue
 	approvalCore      *coreapprovalpolicy.Service
+	approvalRules     approval.RuleManager
+	riskRules         approval.RiskRuleManager
+	approvalRulesDB   *approval.PersistentRuleSet
+	commandTemplates  commandtemplates.Manager
+	commandTmplDB     *commandtemplates.PersistentLibrary
+	localSchedules    localschedule.Manager
+	localScheduleDB   *localschedule.PersistentBook
+	localRuns         *localRunSet
+	sseClients        *sseLimiter
 	dispatchCore      *corecommanddispatch.Service
 	hub               *cpws.Hub
+	signingKey        []byte // master key; per-probe keys derived via signing.DeriveProbeKey
 	probeAuth         *auth.ProbeAuthenticator
+	probeTokens       *auth.ProbeTokenSigner
 	probeCertRegistry *auth.ProbeCertificateRegistry
 	probeCertIssuer   *auth.ProbeCertificateIssuer
 
 	// Persistence (nil = in-memory fallback)
 	auditLog   *audit.Log
 	auditStore *audit.Store
+	auditFwd   *audit.SyslogForwarder // optional SIEM forwarder
 	chatMgr    *chat.Manager
 	chatStore  *chat.Store
 	authStore  *auth.KeyStore
+	keyLimiter *auth.KeyRateLimiter
+	authnAudit *auth.AuthnAuditor
+
+	// loginLockout is nil when lockout is disabled or there is no user store.
+	loginLockout *auth.LoginLockout
 
 	// Multi-user auth
 	userStore          *users.Store
@@ -117,6 +154,8 @@ type Server struct {
 	// Policy
 	policyStore      policy.PolicyManager
 	policyPersistent *policy.PersistentStore
+	policyHistory    policy.AssignmentHistory
+	policyTagRules   policy.TagRuleManager
 
 	// Webhook
 	webhookNotifier *webhook.Notifier
@@ -136,7 +175,7 @@ type Server struct {
 	runnerManager          *runner.Manager
 	runnerExecutionBackend runner.ExecutionBackend
 	artifactPresigner      *artifacts.Service
-	runnerArtifactsDir     string
+	runnerArtifacts        *artifacts.Store
 	providerProxy          *providerproxy.Proxy
 	providerProxySpend     *providerproxy.SpendStore
 
@@ -198,6 +237,8 @@ type Server struct {
 
 	// Reliability telemetry
 	reliabilityTelemetry *reliability.RequestTelemetry
+	// reliabilityConnectivity samples probe connectivity for trend buckets.
+	reliabilityConnectivity *reliability.ConnectivityHistory
 
 	// Failure drills
 	drillRunner   *reliability.DrillRunner
@@ -238,8 +279,14 @@ func New(cfg config.Config, logger *zap.Logger) (*Server, error) {
 		cfg:    cfg,
 		logger: logger,
 	}
+	s.lifecycleCtx, s.stopLifecycle = context.WithCancel(context.Background())
 
 	s.eventBus = events.NewBus(256)
+	if raw := os.Getenv("LEGATOR_EVENTS_REPLAY_SIZE"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n >= 0 {
+			s.eventBus.SetHistorySize(n)
+		}
+	}
 
 	if err := s.initFleet(); err != nil {
 		return nil, err
@@ -262,14 +309,27 @@ func New(cfg config.Config, logger *zap.Logger) (*Server, error) {
 	}
 
 	s.cmdTracker = cmdtracker.New(2 * time.Minute)
+	s.sseClients = newSSELimiter(s.cfg.SSE.ClientLimit())
 	s.initCommandStreams()
 	s.initAudit()
+	s.initAuditForwarder()
+	s.authnAudit = auth.NewAuthnAuditor(s.auditRecorder())
 	s.initApprovals()
 	s.initWebhooks()
+	s.initHealthHistory()
+	s.resourceSeries = fleet.NewResourceSeries(fleet.ResourceSeriesOptions{
+		Window:   s.cfg.ResourceSeries.WindowDuration(),
+		Interval: s.cfg.ResourceSeries.IntervalDuration(),
+	})
+	s.connectivity = connectivity.NewCachedChecker(newConnectivityManager(), envDuration("LEGATOR_CONNECTIVITY_CHECK_CACHE_TTL"))
+	s.initRollouts()
 	s.initAlerts()
 	s.initSandbox()
 	s.initChat()
 	s.initPolicy()
+	s.initApprovalRules()
+	s.initCommandTemplates()
+	s.initLocalSchedules()
 	s.initApprovalCore()
 	s.initModelDock()
 	s.initCloudConnectors()
@@ -300,7 +360,8 @@ func New(cfg config.Config, logger *zap.Logger) (*Server, error) {
 			s.cmdTracker,
 			s.logger,
 			func(id string, request *coreapprovalpolicy.DecideApprovalRequest) (*coreapprovalpolicy.ApprovalDecisionResult, error) {
-				return s.approvalCore.DecideAndDispatch(id, request.Decision, request.DecidedBy, s.dispatchApprovedCommand)
+				request.Source = approval.DecisionSourceMCP
+				return s.approvalCore.DecideAndDispatchWithDetails(id, request.Decision, request.DecidedBy, request.Details(), s.dispatchApprovedCommand)
 			},
 			mcpserver.WithKubeflowTools(s.mcpKubeflowRunStatus, s.mcpKubeflowSubmitRun, s.mcpKubeflowCancelRun),
 			mcpserver.WithGrafanaClient(s.grafanaClient),
@@ -333,8 +394,9 @@ func New(cfg config.Config, logger *zap.Logger) (*Server, error) {
 				Command:        srvCfg.Command,
 				Args:           srvCfg.Args,
 				Endpoint:       srvCfg.Endpoint,
-				ConnectTimeout: srvCfg.TimeoutDuration(),
+				ConnectTimeout: srvCfg.ConnectTimeoutDuration(),
 				CallTimeout:    srvCfg.TimeoutDuration(),
+				ToolTimeouts:   srvCfg.ToolTimeoutDurations(),
 				Env:            srvCfg.Env,
 			}
 			if err := s.mcpRegistry.Add(context.Background(), clientCfg); err != nil {
@@ -347,6 +409,7 @@ func New(cfg config.Config, logger *zap.Logger) (*Server, error) {
 	s.initAuth()
 	s.loadTemplates()
 	s.reliabilityTelemetry = reliability.NewRequestTelemetry(20000, reliabilityTelemetryMaxAge, time.Now().UTC())
+	s.reliabilityConnectivity = reliability.NewConnectivityHistory(reliabilityTelemetryMaxAge)
 
 	mux := http.NewServeMux()
 	s.registerRoutes(mux)
@@ -357,8 +420,10 @@ func New(cfg config.Config, logger *zap.Logger) (*Server, error) {
 	if s.authStore != nil || s.sessionValidator != nil {
 		authMiddleware := auth.NewMiddleware(s.authStore, []string{
 			"/healthz",
+			"/readyz",
 			"/version",
 			"/api/v1/register",
+			"/api/v1/probe/token",
 			"/api/v1/auth/permissions",
 			"/api/v1/openapi.yaml",
 			"/download/*",
@@ -373,6 +438,9 @@ func New(cfg config.Config, logger *zap.Logger) (*Server, error) {
 			"/site/*",
 		})
 		authMiddleware.SetSessionAuth(s.sessionValidator, s.permissionResolver)
+		authMiddleware.SetAuthnAuditor(s.authnAudit)
+		// Rate limiting runs inside auth so the API key is on the context.
+		handler = s.apiKeyRateLimitMiddleware(handler)
 		handler = authMiddleware.Wrap(handler)
 	}
 	if s.reliabilityTelemetry != nil {
@@ -407,10 +475,31 @@ func (s *Server) Run(ctx context.Context) error {
 	// Start offline checker
 	go s.offlineChecker(ctx)
 
+	if s.netboxSource != nil {
+		go s.netboxSource.Run(ctx)
+	}
+	if s.tailscaleSource != nil {
+		go s.tailscaleSource.Run(ctx)
+	}
 	if s.remoteScanner != nil {
 		go s.remoteScanner.Run(ctx)
 	}
 
+	if s.healthHistory != nil {
+		go s.healthHistory.PurgeLoop(ctx, time.Hour)
+	}
+	go s.resourceSeries.PruneLoop(ctx, s.resourceSeries.Window())
+	if s.runnerArtifacts != nil {
+		go s.runnerArtifacts.PurgeLoop(ctx, s.cfg.Jobs.RunnerArtifactRetentionDuration(), time.Hour, func(runIDs []string, err error) {
+			if err != nil {
+				s.logger.Warn("runner artifact purge failed", zap.Error(err))
+			}
+			if len(runIDs) > 0 {
+				s.logger.Info("purged expired runner artifacts", zap.Strings("run_ids", runIDs))
+			}
+		})
+	}
+
 	// Forward event bus events to webhooks
 	go s.webhookForwarder(ctx)
 
@@ -426,6 +515,19 @@ func (s *Server) Run(ctx context.Context) error {
 		go s.runApprovalTimeoutChecker(ctx)
 	}
 
+	if strings.TrimSpace(s.cfg.ProbeKeyMaxAge) != "" {
+		maxAge, err := parseHumanDuration(s.cfg.ProbeKeyMaxAge)
+		if err != nil || maxAge <= 0 {
+			s.logger.Warn("invalid probe key max age; scheduled key rotation disabled",
+				zap.String("probe_key_max_age", s.cfg.ProbeKeyMaxAge),
+				zap.Error(err),
+			)
+		} else {
+			go s.probeKeyRotator(ctx, maxAge)
+			s.logger.Info("scheduled probe key rotation enabled", zap.Duration("max_age", maxAge))
+		}
+	}
+
 	if s.auditStore != nil && strings.TrimSpace(s.cfg.AuditRetention) != "" {
 		retention, err := parseHumanDuration(s.cfg.AuditRetention)
 		if err != nil {
@@ -475,19 +577,35 @@ func (s *Server) Run(ctx context.Context) error {
 	}
 
 	s.logger.Info("shutting down...")
+	s.stopLifecycle()
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	return s.httpServer.Shutdown(shutdownCtx)
 }
 
+// lifecycleContext returns a context for work that must outlive the request
+// that started it but stop when the server shuts down.
+func (s *Server) lifecycleContext() context.Context {
+	if s.lifecycleCtx == nil {
+		return context.Background()
+	}
+	return s.lifecycleCtx
+}
+
 // Close releases all resources.
 func (s *Server) Close() {
+	if s.stopLifecycle != nil {
+		s.stopLifecycle()
+	}
 	if s.fleetStore != nil {
 		s.fleetStore.Close()
 	}
 	if s.tokenStore != nil {
 		s.tokenStore.Close()
 	}
+	if s.auditFwd != nil {
+		s.auditFwd.Close()
+	}
 	if s.auditStore != nil {
 		s.auditStore.Close()
 	}
@@ -536,6 +654,15 @@ func (s *Server) Close() {
 	if s.policyPersistent != nil {
 		s.policyPersistent.Close()
 	}
+	if s.approvalRulesDB != nil {
+		s.approvalRulesDB.Close()
+	}
+	if s.commandTmplDB != nil {
+		s.commandTmplDB.Close()
+	}
+	if s.localScheduleDB != nil {
+		s.localScheduleDB.Close()
+	}
 	if s.modelDockStore != nil {
 		s.modelDockStore.Close()
 	}
@@ -551,6 +678,9 @@ func (s *Server) Close() {
 	if s.discoveryStore != nil {
 		s.discoveryStore.Close()
 	}
+	if s.healthHistory != nil {
+		s.healthHistory.Close()
+	}
 	if s.complianceStore != nil {
 		s.complianceStore.Close()
 	}
@@ -594,6 +724,22 @@ func (s *Server) initFleet() error {
 			zap.String("dir", s.cfg.DataDir), zap.Error(err))
 		s.fleetMgr = fleet.NewManager(s.logger.Named("fleet"))
 	}
+
+	thresholds := fleet.HealthThresholds{
+		LoadHigh:      s.cfg.Health.LoadHigh,
+		LoadCritical:  s.cfg.Health.LoadCritical,
+		MemHighPct:    s.cfg.Health.MemoryHighPct,
+		MemCritPct:    s.cfg.Health.MemoryCriticalPct,
+		DiskHighPct:   s.cfg.Health.DiskHighPct,
+		DiskCritPct:   s.cfg.Health.DiskCriticalPct,
+		HysteresisPct: s.cfg.Health.HysteresisPct,
+	}
+	switch mgr := s.fleetMgr.(type) {
+	case *fleet.Store:
+		mgr.Manager().SetHealthThresholds(thresholds)
+	case *fleet.Manager:
+		mgr.SetHealthThresholds(thresholds)
+	}
 	return nil
 }
 
@@ -607,13 +753,78 @@ func (s *Server) initFederation() {
 			Site:    "local",
 		}),
 	)
+
+	s.initNetboxSource()
+	s.initTailscaleSource()
+}
+
+func (s *Server) initNetboxSource() {
+	nb := s.cfg.Netbox
+	if !nb.Enabled {
+		return
+	}
+	if strings.TrimSpace(nb.BaseURL) == "" {
+		s.logger.Warn("netbox source enabled without base_url; skipping")
+		return
+	}
+	s.netboxSource = fleet.NewNetboxSourceAdapter(fleet.NetboxSourceConfig{
+		BaseURL:       nb.BaseURL,
+		APIToken:      nb.APIToken,
+		Interval:      nb.SyncIntervalDuration(),
+		Timeout:       nb.TimeoutDuration(),
+		TLSSkipVerify: nb.TLSSkipVerify,
+		Logger:        s.logger.Named("netbox"),
+		StaleAfter:    nb.StaleAfterDuration(),
+	})
+	s.federationStore.RegisterSource(s.netboxSource)
+	s.logger.Info("netbox federation source enabled", zap.String("base_url", nb.BaseURL))
+}
+
+func (s *Server) initTailscaleSource() {
+	ts := s.cfg.Tailscale
+	if !ts.Enabled {
+		return
+	}
+	if strings.TrimSpace(ts.APIKey) == "" {
+		s.logger.Warn("tailscale source enabled without api_key; skipping")
+		return
+	}
+	s.tailscaleSource = fleet.NewTailscaleSourceAdapter(fleet.TailscaleSourceConfig{
+		BaseURL:    ts.BaseURL,
+		APIKey:     ts.APIKey,
+		Tailnet:    ts.Tailnet,
+		Interval:   ts.SyncIntervalDuration(),
+		Timeout:    ts.TimeoutDuration(),
+		Logger:     s.logger.Named("tailscale"),
+		StaleAfter: ts.StaleAfterDuration(),
+	})
+	s.federationStore.RegisterSource(s.tailscaleSource)
+	s.logger.Info("tailscale federation source enabled", zap.String("tailnet", ts.Tailnet))
 }
 
 func (s *Server) initRemoteProbes() {
-	s.remoteExecutor = fleet.NewRemoteExecutor()
+	executor := fleet.NewRemoteExecutor()
+	executor.SetTimeouts(envDuration("LEGATOR_REMOTE_CONNECT_TIMEOUT"), envDuration("LEGATOR_REMOTE_COMMAND_TIMEOUT"))
+	executor.SetHostKeyTrustHandler(s.trustRemoteHostKey)
+	timeouts := executor.Timeouts()
+	s.logger.Debug("remote probe timeouts",
+		zap.Duration("connect", timeouts.Connect),
+		zap.Duration("command", timeouts.Command),
+	)
+	s.remoteExecutor = executor
 	s.remoteScanner = fleet.NewRemoteScanner(s.fleetMgr, s.remoteExecutor, s.logger.Named("remote-scan"), 2*time.Minute)
 }
 
+// envDuration parses a positive duration from an environment variable,
+// returning 0 when it is unset or invalid.
+func envDuration(name string) time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(os.Getenv(name)))
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
 func (s *Server) initCommandStreams() {
 	streamDBPath := filepath.Join(s.cfg.DataDir, "command-stream.db")
 	if err := os.MkdirAll(s.cfg.DataDir, 0750); err != nil {
@@ -675,10 +886,76 @@ func (s *Server) initAudit() {
 	)
 }
 
+func (s *Server) initAuditForwarder() {
+	cfg := s.cfg.Audit.Syslog
+	if strings.TrimSpace(cfg.Address) == "" {
+		return
+	}
+
+	var tlsCfg *tls.Config
+	if strings.EqualFold(strings.TrimSpace(cfg.Protocol), "tls") {
+		tlsCfg = &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: cfg.TLSSkipVerify} //nolint:gosec // explicit operator opt-in
+		if cfg.CAPath != "" {
+			pem, err := os.ReadFile(cfg.CAPath)
+			if err != nil {
+				s.logger.Warn("cannot read audit syslog CA, forwarder disabled", zap.String("path", cfg.CAPath), zap.Error(err))
+				return
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				s.logger.Warn("audit syslog CA contains no certificates, forwarder disabled", zap.String("path", cfg.CAPath))
+				return
+			}
+			tlsCfg.RootCAs = pool
+		}
+	}
+
+	fwd, err := audit.NewSyslogForwarder(audit.SyslogConfig{
+		Address:    cfg.Address,
+		Protocol:   cfg.Protocol,
+		TLSConfig:  tlsCfg,
+		BufferSize: cfg.BufferSize,
+		AppName:    cfg.AppName,
+		Version:    Version,
+	}, s.logger.Named("audit-syslog"))
+	if err != nil {
+		s.logger.Warn("invalid audit syslog config, forwarder disabled", zap.Error(err))
+		return
+	}
+
+	s.auditFwd = fwd
+	if s.auditStore != nil {
+		s.auditStore.SetSink(fwd)
+	} else {
+		s.auditLog.SetSink(fwd)
+	}
+	s.logger.Info("audit syslog forwarder enabled",
+		zap.String("address", cfg.Address),
+		zap.String("protocol", cfg.Protocol),
+	)
+}
+
 func (s *Server) initApprovals() {
 	s.approvalQueue = approval.NewQueue(15*time.Minute, 500)
+	s.approvalQueue.SetMaxTTL(s.cfg.Approval.MaxTTLDuration())
+	reasons := make(map[string]approval.ReasonRequirement)
+	for level, raw := range s.cfg.Approval.ReasonRequirements() {
+		requirement := approval.ReasonRequirement(raw)
+		if !approval.ValidReasonRequirement(requirement) {
+			s.logger.Warn("ignoring unknown approval reason requirement",
+				zap.String("risk_level", level), zap.String("requirement", raw))
+			continue
+		}
+		reasons[level] = requirement
+	}
+	s.approvalQueue.SetReasonRequirements(reasons)
+	if fraction := s.cfg.Approval.EscalationFraction(); fraction > 0 {
+		s.approvalQueue.SetEscalation(fraction, s.escalateApproval)
+	}
 	// Reaper will be started when Run() is called via context
-	s.logger.Info("approval queue initialized", zap.Duration("ttl", 15*time.Minute))
+	s.logger.Info("approval queue initialized",
+		zap.Duration("ttl", 15*time.Minute),
+		zap.Duration("max_ttl", s.approvalQueue.MaxTTL()))
 }
 
 func (s *Server) initWebhooks() {
@@ -697,6 +974,23 @@ func (s *Server) initWebhooks() {
 	} else {
 		s.webhookNotifier = webhook.NewNotifier()
 	}
+	s.webhookNotifier.SetRetryPolicy(webhook.RetryPolicy{
+		MaxAttempts:    s.cfg.Webhooks.MaxAttemptsOrDefault(),
+		InitialBackoff: s.cfg.Webhooks.RetryBackoffDuration(),
+		MaxBackoff:     s.cfg.Webhooks.RetryMaxBackoffDuration(),
+	})
+}
+
+func (s *Server) initHealthHistory() {
+	healthDBPath := filepath.Join(s.cfg.DataDir, "health_history.db")
+	store, err := fleet.NewHealthHistoryStore(healthDBPath, fleet.HealthHistoryOptions{})
+	if err != nil {
+		s.logger.Warn("cannot open health history database, probe health history disabled",
+			zap.String("path", healthDBPath), zap.Error(err))
+		return
+	}
+	s.healthHistory = store
+	s.logger.Info("health history store opened", zap.String("path", healthDBPath))
 }
 
 func (s *Server) initAlerts() {
@@ -714,6 +1008,13 @@ func (s *Server) initAlerts() {
 
 	s.alertStore = store
 	s.alertEngine = alerts.NewEngine(store, s.fleetMgr, s.webhookNotifier, s.eventBus, s.logger.Named("alerts"))
+	s.alertEngine.SetExternalURL(s.cfg.ExternalURL)
+	if s.healthHistory != nil {
+		s.alertEngine.SetHealthHistory(s.healthHistory)
+	}
+	if s.federationStore != nil {
+		s.alertEngine.SetInventoryFreshness(s.federationStore)
+	}
 	s.alertEngine.SetNotificationAuditRecorder(alerts.NotificationAuditRecorderFunc(func(record alerts.NotificationAuditRecord) {
 		eventType := audit.EventNotificationDeliverySucceeded
 		if record.Kind == alerts.NotificationAuditTest {
@@ -819,6 +1120,8 @@ func (s *Server) initJobs() {
 		s.logger.Named("jobs"),
 		jobs.WithDefaultRetryPolicy(retryPolicy),
 		jobs.WithAdmissionEvaluator(jobs.JobAdmissionEvaluatorFunc(s.evaluateScheduledJobAdmission)),
+		jobs.WithDefaultRunTimeout(s.cfg.Jobs.RunTimeoutDuration()),
+		jobs.WithDependencyWaitTimeout(s.cfg.Jobs.DependencyWaitTimeoutDuration()),
 		jobs.WithLifecycleObserver(jobs.LifecycleObserverFunc(s.handleJobLifecycleEvent)),
 	)
 	s.jobsHandler = jobs.NewHandler(
@@ -861,7 +1164,7 @@ func (s *Server) initRunnerManager() {
 	}
 
 	s.runnerManager = runner.NewManager(runnerCfg)
-	s.runnerArtifactsDir = filepath.Join(s.cfg.DataDir, "runner-artifacts")
+	s.runnerArtifacts = artifacts.NewStore(filepath.Join(s.cfg.DataDir, "runner-artifacts"), s.cfg.Jobs.RunnerArtifactMaxRunBytesOrDefault())
 
 	artifactPresigner, err := artifacts.NewService(artifacts.Config{
 		SigningKey: s.runnerArtifactSigningKey(),
@@ -945,8 +1248,10 @@ func (s *Server) initRunnerManager() {
 					Detail:  detail,
 				})
 			}),
-			MaxTokensPerRun: s.cfg.ProviderProxy.MaxTokensPerRun,
-			MaxCostPerRun:   s.cfg.ProviderProxy.MaxCostPerRun,
+			MaxTokensPerRun:  s.cfg.ProviderProxy.MaxTokensPerRun,
+			MaxCostPerRun:    s.cfg.ProviderProxy.MaxCostPerRun,
+			MonthlyBudgetUSD: s.cfg.ProviderProxy.MonthlyBudgetUSD,
+			BudgetSink:       providerproxy.BudgetSinkFunc(s.recordProviderBudgetThreshold),
 		})
 		if proxyErr != nil {
 			s.logger.Warn("failed to initialize provider proxy", zap.Error(proxyErr))
@@ -957,6 +1262,7 @@ func (s *Server) initRunnerManager() {
 				zap.String("spend_path", spendPath),
 				zap.Int("max_tokens_per_run", s.cfg.ProviderProxy.MaxTokensPerRun),
 				zap.Float64("max_cost_per_run", s.cfg.ProviderProxy.MaxCostPerRun),
+				zap.Float64("monthly_budget_usd", s.cfg.ProviderProxy.MonthlyBudgetUSD),
 			)
 		}
 	}
@@ -967,7 +1273,9 @@ func (s *Server) initRunnerManager() {
 		zap.String("runner_sandbox_runtime", strings.TrimSpace(s.cfg.Jobs.RunnerSandboxRuntimeCommand)),
 		zap.String("runner_sandbox_image", strings.TrimSpace(s.cfg.Jobs.RunnerSandboxImage)),
 		zap.Duration("runner_sandbox_timeout", s.cfg.Jobs.RunnerSandboxTimeoutDuration()),
-		zap.String("runner_artifacts_dir", s.runnerArtifactsDir),
+		zap.String("runner_artifacts_dir", s.runnerArtifacts.Root()),
+		zap.Int64("runner_artifact_max_run_bytes", s.runnerArtifacts.MaxRunBytes()),
+		zap.Duration("runner_artifact_retention", s.cfg.Jobs.RunnerArtifactRetentionDuration()),
 	)
 }
 
@@ -1127,7 +1435,10 @@ func (a *sandboxEventAdapter) Publish(evt sandbox.BusEvent) {
 func (s *Server) initChat() {
 	chatDBPath := filepath.Join(s.cfg.DataDir, "chat.db")
 	if err := os.MkdirAll(s.cfg.DataDir, 0750); err == nil {
-		store, err := chat.NewStore(chatDBPath, s.logger.Named("chat"))
+		store, err := chat.NewStoreWithOptions(chatDBPath, s.logger.Named("chat"), chat.StoreOptions{
+			MaxMessagesPerProbe: s.cfg.Chat.MaxMessages(),
+			Retention:           s.cfg.Chat.RetentionDuration(),
+		})
 		if err != nil {
 			s.logger.Warn("cannot open chat database, falling back to in-memory",
 				zap.String("path", chatDBPath), zap.Error(err))
@@ -1135,7 +1446,11 @@ func (s *Server) initChat() {
 		} else {
 			s.chatStore = store
 			s.chatMgr = store.Manager()
-			s.logger.Info("chat store opened", zap.String("path", chatDBPath))
+			s.logger.Info("chat store opened",
+				zap.String("path", chatDBPath),
+				zap.Int("max_messages_per_probe", s.cfg.Chat.MaxMessages()),
+				zap.Duration("retention", s.cfg.Chat.RetentionDuration()),
+			)
 		}
 	} else {
 		s.chatMgr = chat.NewManager(s.logger.Named("chat"))
@@ -1147,14 +1462,62 @@ func (s *Server) initPolicy() {
 	if ps, err := policy.NewPersistentStore(policyDBPath); err != nil {
 		s.logger.Warn("cannot open policy database, falling back to in-memory",
 			zap.String("path", policyDBPath), zap.Error(err))
-		s.policyStore = policy.NewStore()
+		store := policy.NewStore()
+		s.policyStore = store
+		s.policyHistory = store
+		s.policyTagRules = store
 	} else {
 		s.policyPersistent = ps
 		s.policyStore = ps
+		s.policyHistory = ps
+		s.policyTagRules = ps
 		s.logger.Info("policy store opened", zap.String("path", policyDBPath))
 	}
 }
 
+func (s *Server) initApprovalRules() {
+	rulesDBPath := filepath.Join(s.cfg.DataDir, "approval_rules.db")
+	if rs, err := approval.NewPersistentRuleSet(rulesDBPath); err != nil {
+		s.logger.Warn("cannot open approval rules database, falling back to in-memory",
+			zap.String("path", rulesDBPath), zap.Error(err))
+		mem := approval.NewRuleSet()
+		s.approvalRules = mem
+		s.riskRules = mem
+	} else {
+		s.approvalRulesDB = rs
+		s.approvalRules = rs
+		s.riskRules = rs
+		s.logger.Info("approval rules store opened", zap.String("path", rulesDBPath), zap.Int("rules", len(rs.List())))
+	}
+}
+
+func (s *Server) initCommandTemplates() {
+	templatesDBPath := filepath.Join(s.cfg.DataDir, "command_templates.db")
+	if lib, err := commandtemplates.NewPersistentLibrary(templatesDBPath); err != nil {
+		s.logger.Warn("cannot open command templates database, falling back to in-memory",
+			zap.String("path", templatesDBPath), zap.Error(err))
+		s.commandTemplates = commandtemplates.NewLibrary()
+	} else {
+		s.commandTmplDB = lib
+		s.commandTemplates = lib
+		s.logger.Info("command templates store opened", zap.String("path", templatesDBPath), zap.Int("templates", len(lib.List())))
+	}
+}
+
+func (s *Server) initLocalSchedules() {
+	s.localRuns = newLocalRunSet(4096)
+	schedulesDBPath := filepath.Join(s.cfg.DataDir, "local_schedules.db")
+	if book, err := localschedule.NewPersistentBook(schedulesDBPath); err != nil {
+		s.logger.Warn("cannot open local schedules database, falling back to in-memory",
+			zap.String("path", schedulesDBPath), zap.Error(err))
+		s.localSchedules = localschedule.NewBook()
+	} else {
+		s.localScheduleDB = book
+		s.localSchedules = book
+		s.logger.Info("local schedules store opened", zap.String("path", schedulesDBPath))
+	}
+}
+
 func (s *Server) initApprovalCore() {
 	hooks := coreapprovalpolicy.DecisionHookFuncs{
 		OnDecisionRecordedFn: func(result *coreapprovalpolicy.ApprovalDecisionResult) error {
@@ -1200,6 +1563,16 @@ func (s *Server) initApprovalCore() {
 				detail["approval_actor"] = latestApproval.Actor
 				detail["approval_timestamp"] = latestApproval.Timestamp
 			}
+			reason, source := req.DecisionReason, req.DecisionSource
+			if req.Decision == approval.DecisionPending && hasLatestApproval {
+				reason, source = latestApproval.Reason, latestApproval.Source
+			}
+			if reason != "" {
+				detail["decision_reason"] = reason
+			}
+			if source != "" {
+				detail["decision_source"] = source
+			}
 
 			s.recordAudit(audit.Event{
 				Type:        audit.EventApprovalDecided,
@@ -1281,6 +1654,8 @@ func (s *Server) initApprovalCore() {
 		coreapprovalpolicy.WithDecisionHooks(hooks),
 		coreapprovalpolicy.WithCapacitySignalProvider(capacityProvider),
 		coreapprovalpolicy.WithTwoPersonMode(s.cfg.Approval.TwoPersonMode),
+		coreapprovalpolicy.WithAutoApproveRules(s.approvalRules, s.recordAutoApproval),
+		coreapprovalpolicy.WithRiskRules(s.riskRules),
 	)
 }
 
@@ -1296,6 +1671,7 @@ func (s *Server) initModelDock() {
 		Model:   os.Getenv("LEGATOR_LLM_MODEL"),
 	}
 	s.modelProviderMgr = modeldock.NewProviderManager(envCfg)
+	s.modelProviderMgr.SetPricing(modelDockPricing(s.cfg.LLM.Pricing))
 
 	modelDockDBPath := filepath.Join(s.cfg.DataDir, "modeldock.db")
 	if err := os.MkdirAll(s.cfg.DataDir, 0750); err != nil {
@@ -1319,6 +1695,15 @@ func (s *Server) initModelDock() {
 	s.logger.Info("model dock store opened", zap.String("path", modelDockDBPath))
 }
 
+// modelDockPricing converts configured LLM token prices for Model Dock.
+func modelDockPricing(pricing map[string]config.LLMTokenPrice) map[string]modeldock.TokenPrice {
+	out := make(map[string]modeldock.TokenPrice, len(pricing))
+	for model, price := range pricing {
+		out[model] = modeldock.TokenPrice{InputPerMillion: price.InputPerMillion, OutputPerMillion: price.OutputPerMillion}
+	}
+	return out
+}
+
 func (s *Server) initCloudConnectors() {
 	cloudDBPath := filepath.Join(s.cfg.DataDir, "cloud.db")
 	if err := os.MkdirAll(s.cfg.DataDir, 0750); err != nil {
@@ -1335,7 +1720,7 @@ func (s *Server) initCloudConnectors() {
 	}
 
 	s.cloudConnectorStore = store
-	s.cloudConnectorHandlers = cloudconnectors.NewHandler(store, cloudconnectors.NewCLIAdapter())
+	s.cloudConnectorHandlers = cloudconnectors.NewHandler(store, cloudconnectors.NewDefaultScanner())
 	s.logger.Info("cloud connector store opened", zap.String("path", cloudDBPath))
 }
 
@@ -1457,6 +1842,7 @@ func (s *Server) initDiscovery() {
 
 	s.discoveryStore = store
 	s.discoveryHandlers = discovery.NewHandler(store, discovery.NewScanner(), s.tokenStore)
+	s.discoveryHandlers.SetManagedHosts(s.discoveryManagedHosts)
 	s.logger.Info("discovery store opened", zap.String("path", discoveryDBPath))
 	if cs, err := store.OpenCandidateStore(); err == nil {
 		s.candidateHandlers = discovery.NewCandidateHandler(cs)
@@ -1465,6 +1851,32 @@ func (s *Server) initDiscovery() {
 	}
 }
 
+// discoveryManagedHosts lists registered probes by hostname and inventory
+// addresses so discovery can flag hosts the fleet already manages.
+func (s *Server) discoveryManagedHosts() []discovery.ManagedHost {
+	if s.fleetMgr == nil {
+		return nil
+	}
+	probes := s.fleetMgr.List()
+	hosts := make([]discovery.ManagedHost, 0, len(probes))
+	for _, ps := range probes {
+		host := discovery.ManagedHost{ProbeID: ps.ID, Hostname: ps.Hostname}
+		if ps.Inventory != nil {
+			if host.Hostname == "" {
+				host.Hostname = ps.Inventory.Hostname
+			}
+			for _, nic := range ps.Inventory.Interfaces {
+				host.Addrs = append(host.Addrs, nic.Addrs...)
+			}
+		}
+		if ps.Remote != nil && ps.Remote.Host != "" {
+			host.Addrs = append(host.Addrs, ps.Remote.Host)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
 func (s *Server) initCompliance() {
 	complianceDBPath := filepath.Join(s.cfg.DataDir, "compliance.db")
 	store, err := compliance.NewStore(complianceDBPath)
@@ -1496,6 +1908,7 @@ func (s *Server) initLLM() {
 			APIKey:  os.Getenv("LEGATOR_LLM_API_KEY"),
 			Model:   os.Getenv("LEGATOR_LLM_MODEL"),
 		})
+		s.modelProviderMgr.SetPricing(modelDockPricing(s.cfg.LLM.Pricing))
 	}
 
 	snapshot := s.modelProviderMgr.Snapshot()
@@ -1519,23 +1932,32 @@ func (s *Server) initLLM() {
 	taskProvider := s.modelProviderMgr.Provider(modeldock.FeatureTask, s.modelDockStore)
 
 	// dispatch is a closure that will be set after hub init
-	s.taskRunner = llm.NewTaskRunner(taskProvider, func(probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+	s.taskRunner = llm.NewTaskRunnerWithContext(taskProvider, func(ctx context.Context, probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
 		if ps, ok := s.fleetMgr.Get(probeID); ok {
-			result, err := s.approvalCore.SubmitCommandApprovalWithContext(context.Background(), probeID, cmd, ps.PolicyLevel, "LLM task command", "llm-task")
+			result, err := s.approvalCore.SubmitCommandApprovalWithContext(context.Background(), probeID, cmd, ps.PolicyLevel, "LLM task command", approval.RequesterLLMTask)
 			if err != nil {
 				return nil, fmt.Errorf("approval queue unavailable: %w", err)
 			}
 			if result != nil {
 				switch result.Decision.Outcome {
 				case coreapprovalpolicy.CommandPolicyDecisionDeny:
+					if denial := result.Decision.Rationale.Denial; denial != nil {
+						return nil, fmt.Errorf("command denied by policy (%s): %s; %s", result.Decision.ReasonCode, denial.Message, denial.Remediation)
+					}
 					return nil, fmt.Errorf("command denied by policy (%s): %s", result.Decision.ReasonCode, result.Decision.Rationale.Summary)
 				case coreapprovalpolicy.CommandPolicyDecisionQueue:
 					req := result.Request
 					if req == nil {
 						return nil, fmt.Errorf("approval queue unavailable: missing approval request")
 					}
-					s.emitAudit(audit.EventApprovalRequest, probeID, "llm-task",
-						fmt.Sprintf("LLM command pending approval: %s (risk: %s)", cmd.Command, req.RiskLevel))
+					s.emitAudit(audit.EventApprovalRequest, probeID, approval.RequesterLLMTask,
+						fmt.Sprintf("LLM command pending approval: %s (risk: %s)", approval.CommandLine(cmd), req.RiskLevel))
+					llm.EmitTaskEvent(ctx, llm.TaskEvent{
+						Type:       llm.TaskEventApprovalPending,
+						RequestID:  cmd.RequestID,
+						ApprovalID: req.ID,
+						RiskLevel:  req.RiskLevel,
+					})
 
 					decided, err := s.approvalCore.WaitForDecision(req.ID, approvalWait)
 					if err != nil {
@@ -1543,6 +1965,12 @@ func (s *Server) initLLM() {
 					}
 					s.emitAudit(audit.EventApprovalDecided, probeID, decided.DecidedBy,
 						fmt.Sprintf("LLM approval %s for: %s", decided.Decision, cmd.Command))
+					llm.EmitTaskEvent(ctx, llm.TaskEvent{
+						Type:       llm.TaskEventApprovalDecided,
+						RequestID:  cmd.RequestID,
+						ApprovalID: decided.ID,
+						Decision:   string(decided.Decision),
+					})
 					if decided.Decision != approval.DecisionApproved {
 						return nil, fmt.Errorf("command not approved (id=%s, decision=%s)", decided.ID, decided.Decision)
 					}
@@ -1552,9 +1980,64 @@ func (s *Server) initLLM() {
 
 		return s.dispatchAndWait(probeID, cmd)
 	}, s.logger.Named("task"))
+	if raw := os.Getenv("LEGATOR_TASK_LOOP_THRESHOLD"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n >= 0 {
+			s.taskRunner.SetLoopThreshold(n)
+		}
+	}
+	s.taskRunner.SetConnectivityManager(newConnectivityManager())
+	s.taskRunner.SetSecretSource(s.probeSecrets)
 	s.managedTaskRunner = s.taskRunner
 }
 
+// newConnectivityManager returns an endpoint checker with the
+// LEGATOR_TASK_PRECHECK_* limits applied.
+func newConnectivityManager() *connectivity.Manager {
+	m := connectivity.NewManager()
+	if raw := os.Getenv("LEGATOR_TASK_PRECHECK_PARALLELISM"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			m.Parallelism = n
+		}
+	}
+	if d := envDuration("LEGATOR_TASK_PRECHECK_ENDPOINT_TIMEOUT"); d > 0 {
+		m.EndpointTimeout = d
+	}
+	if d := envDuration("LEGATOR_TASK_PRECHECK_DEADLINE"); d > 0 {
+		m.Deadline = d
+	}
+	return m
+}
+
+// probeSecrets returns the credential values the control plane holds for a
+// probe, for redaction from task output and reports.
+func (s *Server) probeSecrets(probeID string) []string {
+	if s.fleetMgr == nil {
+		return nil
+	}
+	ps, ok := s.fleetMgr.Get(probeID)
+	if !ok {
+		return nil
+	}
+	return probeStateSecrets(ps)
+}
+
+func probeStateSecrets(ps *fleet.ProbeState) []string {
+	if ps == nil {
+		return nil
+	}
+	secrets := []string{ps.APIKey}
+	if creds := ps.RemoteCredentials; creds != nil {
+		secrets = append(secrets, creds.Password, creds.PrivateKey)
+	}
+	return secrets
+}
+
+// redactForProbe scrubs a probe's known secrets and common secret patterns
+// from text bound for logs or the audit trail.
+func redactForProbe(ps *fleet.ProbeState, text string) string {
+	return security.NewRedactor(probeStateSecrets(ps)...).Redact(text)
+}
+
 func (s *Server) initHub() {
 	s.hub = cpws.NewHub(s.logger.Named("ws"), func(probeID string, env protocol.Envelope) {
 		s.handleProbeMessage(probeID, env)
@@ -1583,6 +2066,12 @@ func (s *Server) initHub() {
 			s.publishEvent(events.ProbeReconnected, probeID, fmt.Sprintf("Probe %s reconnected", probeID), reconnectedDetail)
 		}
 		s.publishEvent(events.ProbeConnected, probeID, fmt.Sprintf("Probe %s connected", probeID), detail)
+
+		// Bring the probe's local schedule up to date; it may have changed
+		// while the probe was offline.
+		if sched, ok := s.localSchedules.Get(probeID); ok {
+			s.pushLocalSchedule(probeID, sched)
+		}
 	}, func(probeID string) {
 		now := time.Now().UTC()
 		s.publishEvent(events.ProbeDisconnected, probeID, fmt.Sprintf("Probe %s disconnected", probeID),
@@ -1600,7 +2089,7 @@ func (s *Server) initHub() {
 	var signingKey []byte
 	if signingKeyHex != "" {
 		var err error
-		signingKey, err = hex.DecodeString(signingKeyHex)
+		signingKey, err = signing.DecodeMasterKey(signingKeyHex)
 		if err != nil || len(signingKey) < 32 {
 			s.logger.Fatal("LEGATOR_SIGNING_KEY must be >= 64 hex chars (32 bytes)")
 		}
@@ -1613,7 +2102,9 @@ func (s *Server) initHub() {
 		s.logger.Info("command signing enabled (auto-generated key)",
 			zap.String("key_hex", hex.EncodeToString(signingKey)))
 	}
+	s.signingKey = signingKey
 	s.hub.SetSigner(signing.NewSigner(signingKey))
+	s.probeTokens = auth.NewProbeTokenSigner(signingKey, s.probeTokenTTL())
 }
 
 func (s *Server) wireChatLLM() {
@@ -1680,6 +2171,7 @@ func (s *Server) initAuth() {
 			zap.String("path", authDBPath), zap.Error(err))
 	} else {
 		s.authStore = store
+		s.keyLimiter = auth.NewKeyRateLimiter()
 		s.logger.Info("auth store opened", zap.String("path", authDBPath))
 	}
 
@@ -1694,6 +2186,18 @@ func (s *Server) initAuth() {
 	s.userStore = userStore
 	s.logger.Info("user store opened", zap.String("path", userDBPath))
 
+	s.authnAudit.SetFailureWindow(s.cfg.LoginLockout.WindowDuration())
+	if !s.cfg.LoginLockout.Disabled {
+		s.loginLockout = auth.NewLoginLockout(auth.LoginLockoutOptions{
+			MaxAttempts:   s.cfg.LoginLockout.MaxAttempts,
+			IPMaxAttempts: s.cfg.LoginLockout.IPMaxAttempts,
+			Duration:      s.cfg.LoginLockout.LockDuration(),
+		}, &lockoutStoreAdapter{store: userStore}, s.auditRecorder())
+		if err := s.loginLockout.Load(); err != nil {
+			s.logger.Warn("cannot load login lockouts", zap.Error(err))
+		}
+	}
+
 	// Bootstrap admin user on first run
 	if userStore.Count() == 0 {
 		password := generateBootstrapPassword()
@@ -1742,7 +2246,7 @@ func (s *Server) initAuth() {
 			s.logger.Warn("failed to initialize oidc provider", zap.Error(err))
 		} else {
 			s.oidcProvider = provider
-			s.oidcProvider.Auditor = s.auditRecorder()
+			s.oidcProvider.Auditor = s.authnAudit
 			s.logger.Info("oidc provider enabled", zap.String("provider", provider.ProviderName()))
 		}
 	}
@@ -1950,13 +2454,14 @@ func (s *Server) dispatchAndWait(probeID string, cmd *protocol.CommandPayload) (
 func (s *Server) offlineChecker(ctx context.Context) {
 	ticker := time.NewTicker(probeOfflineCheckInterval)
 	defer ticker.Stop()
+	threshold := s.probeOfflineThreshold()
 	lastOffline := map[string]bool{}
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			s.fleetMgr.MarkOffline(probeOfflineThreshold)
+			s.fleetMgr.MarkOffline(threshold)
 			for _, ps := range s.fleetMgr.List() {
 				if ps.Status == "offline" && !lastOffline[ps.ID] {
 					s.publishEvent(events.ProbeOffline, ps.ID,
@@ -1965,6 +2470,7 @@ func (s *Server) offlineChecker(ctx context.Context) {
 				}
 				lastOffline[ps.ID] = (ps.Status == "offline")
 			}
+			s.recordReliabilityConnectivity(time.Now().UTC())
 		}
 	}
 }
@@ -481,6 +481,42 @@ func TestHandleDispatchCommand_InvalidBody(t *testing.T) {
 	}
 }
 
+func TestHandleDispatchCommand_RejectsProtectedEnvAndRemoteWorkDir(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-env", "host", "linux", "amd64")
+	if _, err := srv.fleetMgr.RegisterRemote(fleet.RemoteProbeRegistration{
+		ID:          "rpr-env",
+		Hostname:    "edge-env",
+		Remote:      fleet.RemoteProbeConfig{Host: "10.10.0.7", Port: 22, Username: "root"},
+		Credentials: fleet.RemoteProbeCredentials{Password: "secret"},
+	}); err != nil {
+		t.Fatalf("register remote probe: %v", err)
+	}
+
+	tests := []struct {
+		probe string
+		body  protocol.CommandPayload
+		code  int
+		want  string
+	}{
+		{"probe-env", protocol.CommandPayload{Command: "ls", Env: map[string]string{"LD_PRELOAD": "/tmp/x.so"}}, http.StatusBadRequest, "LD_PRELOAD is protected"},
+		{"probe-env", protocol.CommandPayload{Command: "ls", Env: map[string]string{"BAD-KEY": "x"}}, http.StatusBadRequest, "invalid env key"},
+		{"rpr-env", protocol.CommandPayload{Command: "ls", WorkDir: "/opt/app"}, http.StatusConflict, "require an agent probe"},
+	}
+	for _, tc := range tests {
+		data, _ := json.Marshal(tc.body)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/probes/"+tc.probe+"/command", bytes.NewReader(data))
+		req.SetPathValue("id", tc.probe)
+		rr := httptest.NewRecorder()
+
+		srv.handleDispatchCommand(rr, req)
+
+		if rr.Code != tc.code || !strings.Contains(rr.Body.String(), tc.want) {
+			t.Fatalf("%s %+v: got %d %s, want %d containing %q", tc.probe, tc.body, rr.Code, rr.Body.String(), tc.code, tc.want)
+		}
+	}
+}
+
 func TestHandleDispatchCommand_PendingApproval(t *testing.T) {
 	srv := newTestServer(t)
 	srv.fleetMgr.Register("probe-approval", "host", "linux", "amd64")
@@ -568,9 +604,20 @@ func TestHandleDispatchCommand_UnknownMutationBlocked(t *testing.T) {
 	if payload["gate_outcome"] != "blocked" {
 		t.Fatalf("expected gate_outcome=blocked, got %v", payload["gate_outcome"])
 	}
+	denial, ok := payload["denial"].(map[string]any)
+	if !ok || denial["reason_code"] != "policy.lane_unmapped" || denial["matched_rule"] == "" || denial["remediation"] == "" {
+		t.Fatalf("expected structured denial, got %#v", payload["denial"])
+	}
 	if srv.approvalQueue.PendingCount() != 0 {
 		t.Fatalf("expected no queued approvals, got %d", srv.approvalQueue.PendingCount())
 	}
+	events := srv.queryAudit(audit.Filter{Type: audit.EventAuthorizationDenied, Limit: 1})
+	if len(events) != 1 {
+		t.Fatalf("expected denial audit event, got %d", len(events))
+	}
+	if detail, ok := events[0].Detail.(map[string]any); !ok || detail["denial"] == nil {
+		t.Fatalf("expected denial in audit detail, got %#v", events[0].Detail)
+	}
 }
 
 func TestSandboxEnforcementBlocksHostDirectMutationWithoutBreakglass(t *testing.T) {
@@ -1071,6 +1118,12 @@ func TestHandleFleetSummary(t *testing.T) {
 	srv := newTestServer(t)
 	srv.fleetMgr.Register("probe-1", "host-1", "linux", "amd64")
 	srv.fleetMgr.Register("probe-2", "host-2", "linux", "amd64")
+	srv.fleetMgr.Register("probe-3", "host-3", "windows", "amd64")
+	for id, version := range map[string]string{"probe-1": "v1.2.0", "probe-2": "v1.1.0", "probe-3": "v1.1.0"} {
+		if err := srv.fleetMgr.Heartbeat(id, &protocol.HeartbeatPayload{ProbeID: id, Version: version}); err != nil {
+			t.Fatalf("heartbeat %s: %v", id, err)
+		}
+	}
 
 	ps, _ := srv.fleetMgr.Get("probe-2")
 	ps.Status = "offline"
@@ -1092,9 +1145,22 @@ func TestHandleFleetSummary(t *testing.T) {
 	if !ok {
 		t.Fatalf("counts missing from summary: %#v", got)
 	}
-	if counts["online"] != float64(1) || counts["offline"] != float64(1) {
+	if counts["online"] != float64(2) || counts["offline"] != float64(1) {
 		t.Fatalf("unexpected counts: %#v", counts)
 	}
+	versions, ok := got["versions"].([]any)
+	if !ok || len(versions) != 2 {
+		t.Fatalf("expected 2 version buckets, got %#v", got["versions"])
+	}
+	if top := versions[0].(map[string]any); top["version"] != "v1.1.0" || top["count"] != float64(2) {
+		t.Fatalf("expected most common version first, got %#v", versions)
+	}
+	if byOS := got["by_os"].(map[string]any); byOS["linux"] != float64(2) || byOS["windows"] != float64(1) {
+		t.Fatalf("unexpected OS breakdown: %#v", byOS)
+	}
+	if byLevel := got["by_policy_level"].(map[string]any); byLevel["observe"] != float64(3) {
+		t.Fatalf("unexpected policy breakdown: %#v", byLevel)
+	}
 	if got["pending_approvals"] != float64(0) {
 		t.Fatalf("unexpected pending approvals: %#v", got)
 	}
@@ -1171,6 +1237,45 @@ func TestHandleReliabilityScorecard_InvalidWindow(t *testing.T) {
 	}
 }
 
+func TestHandleReliabilityTrend(t *testing.T) {
+	srv := newTestServer(t)
+	srv.fleetMgr.Register("probe-online", "host-1", "linux", "amd64")
+	srv.recordAudit(audit.Event{Type: audit.EventCommandResult, ProbeID: "probe-online", Detail: map[string]any{"exit_code": 0}})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reliability/trend?window=1h&buckets=6", nil)
+	rr := httptest.NewRecorder()
+	srv.handleReliabilityTrend(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var trend reliability.Trend
+	if err := json.NewDecoder(rr.Body).Decode(&trend); err != nil {
+		t.Fatalf("decode reliability trend: %v", err)
+	}
+	if len(trend.Points) != 6 || trend.BucketWidth != "10m0s" {
+		t.Fatalf("expected 6 x 10m buckets, got %d x %s", len(trend.Points), trend.BucketWidth)
+	}
+	last := trend.Points[len(trend.Points)-1]
+	for _, indicator := range last.Indicators {
+		if indicator.ID == "probe_fleet.command_success" && indicator.SampleSize != 1 {
+			t.Fatalf("expected latest bucket to include the command result, got %+v", indicator)
+		}
+		if indicator.ID == "probe_fleet.connectivity" && indicator.SampleSize != 1 {
+			t.Fatalf("expected latest bucket to use live connectivity, got %+v", indicator)
+		}
+	}
+
+	for _, query := range []string{"window=bogus", "buckets=0", "buckets=x"} {
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/reliability/trend?"+query, nil)
+		rr = httptest.NewRecorder()
+		srv.handleReliabilityTrend(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", query, rr.Code)
+		}
+	}
+}
+
 func TestHandleDeleteProbe(t *testing.T) {
 	srv := newTestServer(t)
 	srv.fleetMgr.Register("probe-delete", "host", "linux", "amd64")
@@ -1300,6 +1405,58 @@ func TestHandleListApprovals(t *testing.T) {
 	}
 }
 
+func TestHandleDecideApprovalRequiresReasonAndRecordsMetadata(t *testing.T) {
+	srv := newTestServer(t)
+	srv.approvalQueue.SetReasonRequirements(map[string]approval.ReasonRequirement{"high": approval.ReasonOnDeny})
+
+	req, err := srv.approvalQueue.Submit(
+		"probe-decide-reason",
+		&protocol.CommandPayload{RequestID: "req-decide-reason", Command: "systemctl restart nginx"},
+		"manual",
+		"high",
+		"api",
+	)
+	if err != nil {
+		t.Fatalf("submit approval: %v", err)
+	}
+
+	decide := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/approvals/"+req.ID+"/decide", strings.NewReader(body))
+		r.SetPathValue("id", req.ID)
+		rr := httptest.NewRecorder()
+		srv.handleDecideApproval(rr, r)
+		return rr
+	}
+
+	rr := decide(`{"decision":"denied","decided_by":"operator"}`)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "reason_required") {
+		t.Fatalf("expected 400 reason_required, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = decide(`{"decision":"denied","decided_by":"operator","reason":"x","source":"pager"}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown source, got %d", rr.Code)
+	}
+
+	rr = decide(`{"decision":"denied","decided_by":"operator","reason":"outside change window"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	updated, _ := srv.approvalQueue.Get(req.ID)
+	if updated.DecisionReason != "outside change window" || updated.DecisionSource != approval.DecisionSourceAPI {
+		t.Fatalf("expected reason and api source on approval, got %q/%q", updated.DecisionReason, updated.DecisionSource)
+	}
+
+	var detail map[string]any
+	for _, evt := range srv.queryAudit(audit.Filter{ProbeID: "probe-decide-reason", Limit: 20}) {
+		if evt.Type == audit.EventApprovalDecided {
+			detail, _ = evt.Detail.(map[string]any)
+		}
+	}
+	if detail["decision_reason"] != "outside change window" || detail["decision_source"] != approval.DecisionSourceAPI {
+		t.Fatalf("expected reason and source in audit detail, got %#v", detail)
+	}
+}
+
 func TestHandleDecideApproval(t *testing.T) {
 	srv := newTestServer(t)
 
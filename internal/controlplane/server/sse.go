@@ -0,0 +1,75 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// sseLimiter caps the concurrent clients of the SSE streams. A nil limiter
+// or a zero limit admits everyone.
+type sseLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	active int
+}
+
+func newSSELimiter(limit int) *sseLimiter {
+	return &sseLimiter{limit: limit}
+}
+
+func (l *sseLimiter) acquire() bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit > 0 && l.active >= l.limit {
+		return false
+	}
+	l.active++
+	return true
+}
+
+func (l *sseLimiter) release() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active > 0 {
+		l.active--
+	}
+}
+
+// acquireSSEClient reserves an SSE client slot, answering 503 when the
+// limit is reached. The caller must call release when the stream ends.
+func (s *Server) acquireSSEClient(w http.ResponseWriter) (release func(), ok bool) {
+	if !s.sseClients.acquire() {
+		w.Header().Set("Retry-After", "5")
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable",
+			fmt.Sprintf("too many concurrent event stream clients (limit %d); retry later", s.sseClients.limit))
+		return nil, false
+	}
+	return s.sseClients.release, true
+}
+
+// sseKeepalive returns a channel that ticks at the configured keepalive
+// interval, and a stop function. The channel is nil, and never ready, when
+// keepalives are disabled. Streams select on it alongside their events so
+// keepalives are written from the handler goroutine and stop with it.
+func (s *Server) sseKeepalive() (<-chan time.Time, func()) {
+	interval := s.cfg.SSE.KeepaliveDuration()
+	if interval <= 0 {
+		return nil, func() {}
+	}
+	ticker := time.NewTicker(interval)
+	return ticker.C, ticker.Stop
+}
+
+// writeSSEKeepalive writes an SSE comment line, which clients ignore.
+func writeSSEKeepalive(w http.ResponseWriter, flusher http.Flusher) {
+	fmt.Fprint(w, ": keepalive\n\n")
+	flusher.Flush()
+}
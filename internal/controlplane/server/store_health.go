@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/controlplane/migration"
+)
+
+// storeHealth is the write health of one SQLite store. Persistent is false
+// when the store could not be opened at startup and runs in memory only.
+type storeHealth struct {
+	migration.WriteStatus
+	Persistent bool `json:"persistent"`
+}
+
+// storeHealthReport returns the fleet and audit store status and whether all
+// persistent stores are accepting writes.
+func (s *Server) storeHealthReport() ([]storeHealth, bool) {
+	stores := make([]storeHealth, 0, 2)
+	if s.fleetStore != nil {
+		stores = append(stores, storeHealth{WriteStatus: s.fleetStore.WriteStatus(), Persistent: true})
+	} else {
+		stores = append(stores, storeHealth{WriteStatus: migration.WriteStatus{Store: "fleet", Healthy: true}})
+	}
+	if s.auditStore != nil {
+		stores = append(stores, storeHealth{WriteStatus: s.auditStore.WriteStatus(), Persistent: true})
+	} else {
+		stores = append(stores, storeHealth{WriteStatus: migration.WriteStatus{Store: "audit", Healthy: true}})
+	}
+
+	ready := true
+	for _, st := range stores {
+		if !st.Healthy {
+			ready = false
+		}
+	}
+	return stores, ready
+}
+
+// handleReadyz reports 503 while a store is failing writes, so load
+// balancers stop routing to a control plane that would drop data. It is
+// unauthenticated and omits error details.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	stores, ready := s.storeHealthReport()
+	type readyStore struct {
+		Store      string `json:"store"`
+		Healthy    bool   `json:"healthy"`
+		Persistent bool   `json:"persistent"`
+	}
+	out := make([]readyStore, 0, len(stores))
+	for _, st := range stores {
+		out = append(out, readyStore{Store: st.Store, Healthy: st.Healthy, Persistent: st.Persistent})
+	}
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "unready", http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status": status,
+		"stores": out,
+	})
+}
+
+// handleStoreHealth returns full write health for each store, including the
+// last write error.
+func (s *Server) handleStoreHealth(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePermission(w, r, auth.PermAdmin) {
+		return
+	}
+	stores, ready := s.storeHealthReport()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"ready":  ready,
+		"stores": stores,
+	})
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadyzFlipsWhenFleetStoreFailsWrites(t *testing.T) {
+	srv := newTestServer(t)
+	if srv.fleetStore == nil {
+		t.Fatal("expected persistent fleet store")
+	}
+
+	if resp := makeRequest(t, srv, http.MethodGet, "/readyz", "", ""); resp.Code != http.StatusOK {
+		t.Fatalf("expected ready, got %d body=%s", resp.Code, resp.Body.String())
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(srv.cfg.DataDir, "fleet.db"))
+	if err != nil {
+		t.Fatalf("open fleet db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TRIGGER fail_insert BEFORE INSERT ON probes BEGIN SELECT RAISE(ABORT, 'database or disk is full'); END`); err != nil {
+		t.Fatalf("create trigger: %v", err)
+	}
+	srv.fleetMgr.Register("probe-full", "host", "linux", "amd64")
+
+	resp := makeRequest(t, srv, http.MethodGet, "/readyz", "", "")
+	if resp.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while fleet writes fail, got %d", resp.Code)
+	}
+	var ready struct {
+		Status string `json:"status"`
+		Stores []struct {
+			Store      string `json:"store"`
+			Healthy    bool   `json:"healthy"`
+			Persistent bool   `json:"persistent"`
+			LastError  string `json:"last_error"`
+		} `json:"stores"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &ready); err != nil {
+		t.Fatalf("decode readyz: %v", err)
+	}
+	if ready.Status != "unready" || len(ready.Stores) != 2 || ready.Stores[0].Store != "fleet" || ready.Stores[0].Healthy {
+		t.Fatalf("unexpected readyz payload: %+v", ready)
+	}
+	if ready.Stores[0].LastError != "" {
+		t.Fatal("readyz must not expose write errors")
+	}
+
+	resp = makeRequest(t, srv, http.MethodGet, "/api/v1/system/stores", "", "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("system stores: expected 200, got %d", resp.Code)
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &ready); err != nil {
+		t.Fatalf("decode system stores: %v", err)
+	}
+	if ready.Stores[0].LastError == "" || !ready.Stores[0].Persistent {
+		t.Fatalf("expected last error in admin view, got %+v", ready.Stores[0])
+	}
+
+	if _, err := db.Exec(`DROP TRIGGER fail_insert`); err != nil {
+		t.Fatalf("drop trigger: %v", err)
+	}
+	srv.fleetMgr.Register("probe-ok", "host-2", "linux", "amd64")
+	if resp := makeRequest(t, srv, http.MethodGet, "/readyz", "", ""); resp.Code != http.StatusOK {
+		t.Fatalf("expected ready after recovery, got %d", resp.Code)
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/marcus-qen/legator/internal/controlplane/events"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"github.com/marcus-qen/legator/internal/controlplane/llm"
+)
+
+// taskProgressDetail is the detail of a task.progress bus event: one step of
+// a running task, tagged with the run it belongs to so live views can group
+// steps from concurrent runs.
+type taskProgressDetail struct {
+	TaskID string        `json:"task_id"`
+	Task   string        `json:"task"`
+	Event  llm.TaskEvent `json:"event"`
+}
+
+// withTaskProgress returns a context whose task observer publishes every
+// step of the run as a task.progress event, then passes it to next (if
+// any). Task text is redacted against the probe's secrets; step content is
+// already redacted by the runner.
+func (s *Server) withTaskProgress(ctx context.Context, ps *fleet.ProbeState, task string, next llm.TaskObserver) context.Context {
+	if s.eventBus == nil {
+		return llm.WithTaskObserver(ctx, next)
+	}
+	taskID := "task-" + uuid.NewString()
+	task = redactForProbe(ps, task)
+	return llm.WithTaskObserver(ctx, func(evt llm.TaskEvent) {
+		s.publishEvent(events.TaskProgress, ps.ID, taskProgressSummary(evt), taskProgressDetail{
+			TaskID: taskID,
+			Task:   task,
+			Event:  evt,
+		})
+		if next != nil {
+			next(evt)
+		}
+	})
+}
+
+func taskProgressSummary(evt llm.TaskEvent) string {
+	switch evt.Type {
+	case llm.TaskEventCommandDispatched:
+		if evt.Command != nil {
+			return fmt.Sprintf("Step %d: running %s", evt.Step, strings.TrimSpace(evt.Command.Command+" "+strings.Join(evt.Command.Args, " ")))
+		}
+	case llm.TaskEventCommandResult:
+		if evt.Result != nil {
+			return fmt.Sprintf("Step %d: command exited %d", evt.Step, evt.Result.ExitCode)
+		}
+	case llm.TaskEventApprovalPending:
+		return fmt.Sprintf("Step %d: waiting for approval %s", evt.Step, evt.ApprovalID)
+	case llm.TaskEventApprovalDecided:
+		return fmt.Sprintf("Step %d: approval %s %s", evt.Step, evt.ApprovalID, evt.Decision)
+	case llm.TaskEventReport:
+		if evt.Error != "" {
+			return "Task failed: " + evt.Error
+		}
+		return "Task completed"
+	}
+	return fmt.Sprintf("Step %d: %s", evt.Step, evt.Type)
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/auth"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"github.com/marcus-qen/legator/internal/controlplane/llm"
+	"github.com/marcus-qen/legator/internal/controlplane/modeldock"
+	"go.uber.org/zap"
+)
+
+// taskStreamKeepalive is how often a comment line is written to an idle task
+// stream, so proxies don't drop the connection during long approval waits.
+const taskStreamKeepalive = 15 * time.Second
+
+// taskProbeForRequest runs the checks shared by the blocking and streaming
+// task endpoints and returns the target probe. It writes the error response
+// and returns false when the task cannot be run.
+func (s *Server) taskProbeForRequest(w http.ResponseWriter, r *http.Request) (*fleet.ProbeState, bool) {
+	if !s.requirePermission(w, r, auth.PermCommandExec) {
+		return nil, false
+	}
+	if s.taskRunner == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "no active LLM provider configured. Set LEGATOR_LLM_* env vars or activate a model profile in Model Dock")
+		return nil, false
+	}
+	if s.taskRunner == s.managedTaskRunner && s.modelProviderMgr != nil && !s.modelProviderMgr.HasActiveProvider() {
+		writeJSONError(w, http.StatusServiceUnavailable, "service_unavailable", "no active LLM provider configured. Set LEGATOR_LLM_* env vars or activate a model profile in Model Dock")
+		return nil, false
+	}
+
+	ps, ok := s.fleetMgr.Get(r.PathValue("id"))
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "not_found", "probe not found")
+		return nil, false
+	}
+	if ps.Draining {
+		writeJSONError(w, http.StatusConflict, "probe_draining", "probe is draining; undrain it to dispatch tasks")
+		return nil, false
+	}
+	return ps, true
+}
+
+// handleTaskStream runs a task given by the task query parameter and streams
+// its progress as server-sent events.
+func (s *Server) handleTaskStream(w http.ResponseWriter, r *http.Request) {
+	ps, ok := s.taskProbeForRequest(w, r)
+	if !ok {
+		return
+	}
+	task := strings.TrimSpace(r.URL.Query().Get("task"))
+	if task == "" {
+		writeJSONError(w, http.StatusBadRequest, "invalid_request", "task is required")
+		return
+	}
+	s.streamTask(w, r, ps, task, llm.TaskOptions{})
+}
+
+// streamTask runs task against ps, writing each llm.TaskEvent as an SSE
+// event named after its type. The stream ends with a report event, or an
+// error event if the task could not run.
+func (s *Server) streamTask(w http.ResponseWriter, r *http.Request, ps *fleet.ProbeState, task string, opts llm.TaskOptions) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "internal_error", "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	var mu sync.Mutex
+	write := func(event string, payload any) {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		flusher.Flush()
+	}
+
+	mu.Lock()
+	fmt.Fprintf(w, ": connected\n\n")
+	flusher.Flush()
+	mu.Unlock()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(taskStreamKeepalive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+				mu.Lock()
+				fmt.Fprintf(w, ": keepalive\n\n")
+				flusher.Flush()
+				mu.Unlock()
+			}
+		}
+	}()
+
+	id := ps.ID
+	s.logger.Info("task submitted", zap.String("probe", id), zap.String("task", redactForProbe(ps, task)), zap.Bool("stream", true))
+	s.emitAudit(audit.EventCommandSent, id, "llm-task", fmt.Sprintf("Task submitted: %s", redactForProbe(ps, task)))
+
+	reported := false
+	ctx := s.withTaskProgress(r.Context(), ps, task, func(evt llm.TaskEvent) {
+		if evt.Type == llm.TaskEventReport {
+			reported = true
+		}
+		write(string(evt.Type), evt)
+	})
+
+	if _, err := s.taskRunner.RunWithOptions(ctx, id, task, ps.Inventory, ps.PolicyLevel, opts); err != nil {
+		s.logger.Warn("task execution error", zap.String("probe", id), zap.Error(err))
+		code := "llm_unavailable"
+		if errors.Is(err, modeldock.ErrNoActiveProvider) {
+			code = "service_unavailable"
+		}
+		write("error", map[string]string{"code": code, "message": err.Error()})
+		return
+	}
+	if !reported {
+		write("error", map[string]string{"code": "internal_error", "message": "task ended without a report"})
+	}
+}
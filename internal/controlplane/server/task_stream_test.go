@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/controlplane/audit"
+	"github.com/marcus-qen/legator/internal/controlplane/events"
+	"github.com/marcus-qen/legator/internal/controlplane/fleet"
+	"github.com/marcus-qen/legator/internal/controlplane/llm"
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+type scriptedProvider struct {
+	responses []string
+}
+
+func (p *scriptedProvider) Name() string { return "scripted" }
+
+func (p *scriptedProvider) Complete(context.Context, *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	content := p.responses[0]
+	p.responses = p.responses[1:]
+	return &llm.CompletionResponse{Content: content}, nil
+}
+
+func TestStreamTaskWritesEventsAndReport(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{
+		`{"command": "hostname", "args": [], "reason": "Check the hostname"}`,
+		"The hostname is web-01.",
+	}}
+	srv := &Server{
+		logger:   zap.NewNop(),
+		auditLog: audit.NewLog(100),
+		taskRunner: llm.NewTaskRunnerWithContext(provider, func(ctx context.Context, probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+			llm.EmitTaskEvent(ctx, llm.TaskEvent{Type: llm.TaskEventApprovalPending, RequestID: cmd.RequestID, ApprovalID: "apr-42", RiskLevel: "medium"})
+			return &protocol.CommandResultPayload{RequestID: cmd.RequestID, Stdout: "web-01"}, nil
+		}, zap.NewNop()),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/probes/probe-1/task/stream?task=hostname", nil)
+	rr := httptest.NewRecorder()
+	srv.streamTask(rr, req, &fleet.ProbeState{ID: "probe-1", PolicyLevel: protocol.CapObserve}, "what is the hostname?", llm.TaskOptions{})
+
+	if ct := rr.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+	body := rr.Body.String()
+	order := []string{
+		"event: model_step\n",
+		"event: command_dispatched\n",
+		"event: approval_pending\n",
+		"event: command_result\n",
+		"event: report\n",
+	}
+	pos := 0
+	for _, marker := range order {
+		idx := strings.Index(body[pos:], marker)
+		if idx < 0 {
+			t.Fatalf("missing %q after offset %d in stream:\n%s", marker, pos, body)
+		}
+		pos += idx + len(marker)
+	}
+	if !strings.Contains(body, `"approval_id":"apr-42"`) {
+		t.Fatalf("expected approval id in stream:\n%s", body)
+	}
+	if !strings.Contains(body, "The hostname is web-01.") {
+		t.Fatalf("expected final summary in stream:\n%s", body)
+	}
+	if strings.Contains(body, "event: error\n") {
+		t.Fatalf("unexpected error event:\n%s", body)
+	}
+}
+
+func TestStreamTaskPublishesProgressToEventBus(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{
+		`{"command": "hostname", "args": [], "reason": "Check the hostname"}`,
+		"The hostname is web-01.",
+	}}
+	srv := &Server{
+		logger:   zap.NewNop(),
+		auditLog: audit.NewLog(100),
+		eventBus: events.NewBus(64),
+		taskRunner: llm.NewTaskRunnerWithContext(provider, func(ctx context.Context, probeID string, cmd *protocol.CommandPayload) (*protocol.CommandResultPayload, error) {
+			return &protocol.CommandResultPayload{RequestID: cmd.RequestID, Stdout: "web-01"}, nil
+		}, zap.NewNop()),
+	}
+	ch := srv.eventBus.Subscribe("test")
+	defer srv.eventBus.Unsubscribe("test")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/probes/probe-1/task/stream?task=hostname", nil)
+	srv.streamTask(httptest.NewRecorder(), req, &fleet.ProbeState{ID: "probe-1", PolicyLevel: protocol.CapObserve}, "what is the hostname?", llm.TaskOptions{})
+
+	var got []taskProgressDetail
+	for len(ch) > 0 {
+		evt := <-ch
+		if evt.Type != events.TaskProgress {
+			continue
+		}
+		if evt.ProbeID != "probe-1" {
+			t.Fatalf("expected probe-1 progress, got %q", evt.ProbeID)
+		}
+		got = append(got, evt.Detail.(taskProgressDetail))
+	}
+
+	want := []llm.TaskEventType{llm.TaskEventModelStep, llm.TaskEventCommandDispatched, llm.TaskEventCommandResult, llm.TaskEventModelStep, llm.TaskEventReport}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d progress events, got %d: %+v", len(want), len(got), got)
+	}
+	for i, typ := range want {
+		if got[i].Event.Type != typ {
+			t.Errorf("progress %d: expected %s, got %s", i, typ, got[i].Event.Type)
+		}
+		if got[i].TaskID == "" || got[i].TaskID != got[0].TaskID {
+			t.Errorf("progress %d: expected shared task id, got %q", i, got[i].TaskID)
+		}
+		if got[i].Task != "what is the hostname?" {
+			t.Errorf("progress %d: unexpected task %q", i, got[i].Task)
+		}
+	}
+}
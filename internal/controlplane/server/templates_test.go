@@ -98,10 +98,13 @@ func TestProbeDetailTemplateIncludesIncrementalSSEAnchors(t *testing.T) {
 		`id="probe-conn-badge"`,
 		`id="probe-hostname-field"`,
 		`id="probe-memory-field"`,
+		`id="probe-annotations"`,
 		`new EventSource('/api/v1/events')`,
 		`['probe.connected', 'probe.disconnected', 'probe.offline']`,
 		`['command.completed', 'command.failed']`,
 		"fetch(`/api/v1/probes/${encodeURIComponent(PROBE_ID)}`",
+		`id="probe-series"`,
+		"fetch(`/api/v1/probes/${encodeURIComponent(PROBE_ID)}/metrics/series`",
 	}
 
 	for _, snippet := range required {
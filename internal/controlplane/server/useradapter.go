@@ -2,6 +2,7 @@ package server
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/marcus-qen/legator/internal/controlplane/auth"
 	"github.com/marcus-qen/legator/internal/controlplane/session"
@@ -85,3 +86,42 @@ func (r *roleResolver) PermissionsForRole(role string) []auth.Permission {
 	}
 	return []auth.Permission{}
 }
+
+// lockoutStoreAdapter bridges users.Store → auth.LockoutStore.
+type lockoutStoreAdapter struct {
+	store *users.Store
+}
+
+func (a *lockoutStoreAdapter) SaveLockout(l auth.Lockout) error {
+	return a.store.SaveLockout(users.Lockout{
+		Key:            l.Key,
+		Kind:           l.Kind,
+		Subject:        l.Subject,
+		FailedAttempts: l.FailedAttempts,
+		LockedAt:       l.LockedAt,
+		LockedUntil:    l.LockedUntil,
+	})
+}
+
+func (a *lockoutStoreAdapter) DeleteLockout(key string) error {
+	return a.store.DeleteLockout(key)
+}
+
+func (a *lockoutStoreAdapter) ListLockouts(now time.Time) ([]auth.Lockout, error) {
+	saved, err := a.store.ListLockouts(now)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]auth.Lockout, 0, len(saved))
+	for _, l := range saved {
+		out = append(out, auth.Lockout{
+			Key:            l.Key,
+			Kind:           l.Kind,
+			Subject:        l.Subject,
+			FailedAttempts: l.FailedAttempts,
+			LockedAt:       l.LockedAt,
+			LockedUntil:    l.LockedUntil,
+		})
+	}
+	return out, nil
+}
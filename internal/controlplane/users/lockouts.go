@@ -0,0 +1,74 @@
+package users
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lockout is a persisted temporary login lockout of a username or source IP.
+type Lockout struct {
+	Key            string
+	Kind           string
+	Subject        string
+	FailedAttempts int
+	LockedAt       time.Time
+	LockedUntil    time.Time
+}
+
+// SaveLockout creates or replaces the lockout with l.Key.
+func (s *Store) SaveLockout(l Lockout) error {
+	_, err := s.db.Exec(`INSERT INTO login_lockouts (key, kind, subject, failed_attempts, locked_at, locked_until)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET
+			kind = excluded.kind,
+			subject = excluded.subject,
+			failed_attempts = excluded.failed_attempts,
+			locked_at = excluded.locked_at,
+			locked_until = excluded.locked_until`,
+		l.Key, l.Kind, l.Subject, l.FailedAttempts,
+		l.LockedAt.UTC().Format(time.RFC3339Nano), l.LockedUntil.UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("save lockout: %w", err)
+	}
+	return nil
+}
+
+// DeleteLockout removes the lockout with key. Missing keys are not an error.
+func (s *Store) DeleteLockout(key string) error {
+	if _, err := s.db.Exec(`DELETE FROM login_lockouts WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("delete lockout: %w", err)
+	}
+	return nil
+}
+
+// ListLockouts returns lockouts that have not yet expired at now, and
+// deletes the rest.
+func (s *Store) ListLockouts(now time.Time) ([]Lockout, error) {
+	if _, err := s.db.Exec(`DELETE FROM login_lockouts WHERE locked_until <= ?`, now.UTC().Format(time.RFC3339Nano)); err != nil {
+		return nil, fmt.Errorf("prune lockouts: %w", err)
+	}
+	rows, err := s.db.Query(`SELECT key, kind, subject, failed_attempts, locked_at, locked_until FROM login_lockouts ORDER BY locked_at`)
+	if err != nil {
+		return nil, fmt.Errorf("list lockouts: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Lockout
+	for rows.Next() {
+		var (
+			l                 Lockout
+			lockedAt, expires string
+		)
+		if err := rows.Scan(&l.Key, &l.Kind, &l.Subject, &l.FailedAttempts, &lockedAt, &expires); err != nil {
+			return nil, fmt.Errorf("scan lockout: %w", err)
+		}
+		if l.LockedAt, err = time.Parse(time.RFC3339Nano, lockedAt); err != nil {
+			return nil, fmt.Errorf("parse locked_at: %w", err)
+		}
+		if l.LockedUntil, err = time.Parse(time.RFC3339Nano, expires); err != nil {
+			return nil, fmt.Errorf("parse locked_until: %w", err)
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
@@ -105,6 +105,21 @@ func NewStore(dbPath string) (*Store, error) {
 				return err
 			},
 		},
+		{
+			Version:     3,
+			Description: "login lockouts",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS login_lockouts (
+					key             TEXT PRIMARY KEY,
+					kind            TEXT NOT NULL,
+					subject         TEXT NOT NULL,
+					failed_attempts INTEGER NOT NULL DEFAULT 0,
+					locked_at       TEXT NOT NULL,
+					locked_until    TEXT NOT NULL
+				)`)
+				return err
+			},
+		},
 	})
 	if err := runner.Migrate(db); err != nil {
 		_ = db.Close()
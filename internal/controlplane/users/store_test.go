@@ -4,6 +4,7 @@ import (
 	"errors"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func tempDB(t *testing.T) string {
@@ -234,3 +235,46 @@ func TestCreateWithIDAndUpdateProfile(t *testing.T) {
 		t.Fatalf("unexpected updated profile: %+v", updated)
 	}
 }
+
+func TestLockoutsPersistAndExpire(t *testing.T) {
+	path := tempDB(t)
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	active := Lockout{Key: "user:alice", Kind: "user", Subject: "alice", FailedAttempts: 5, LockedAt: now, LockedUntil: now.Add(15 * time.Minute)}
+	expired := Lockout{Key: "ip:10.0.0.1", Kind: "ip", Subject: "10.0.0.1", FailedAttempts: 20, LockedAt: now.Add(-time.Hour), LockedUntil: now.Add(-time.Minute)}
+	for _, l := range []Lockout{active, expired} {
+		if err := store.SaveLockout(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+
+	store, err = NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	got, err := store.ListLockouts(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Key != "user:alice" || got[0].FailedAttempts != 5 || !got[0].LockedUntil.Equal(active.LockedUntil) {
+		t.Fatalf("unexpected lockouts after reopen: %+v", got)
+	}
+
+	if err := store.DeleteLockout("user:alice"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = store.ListLockouts(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no lockouts after delete, got %+v", got)
+	}
+}
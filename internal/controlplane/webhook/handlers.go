@@ -5,14 +5,19 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// ListWebhooks handles GET /api/v1/webhooks.
+// ListWebhooks handles GET /api/v1/webhooks. Secrets are never listed.
 func (n *Notifier) ListWebhooks(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, n.List())
+	webhooks := n.List()
+	for i := range webhooks {
+		webhooks[i] = webhooks[i].redacted()
+	}
+	writeJSON(w, http.StatusOK, webhooks)
 }
 
 // ListDeliveries handles GET /api/v1/webhooks/deliveries.
@@ -31,7 +36,24 @@ func (n *Notifier) ListDeliveries(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// RegisterWebhook handles POST /api/v1/webhooks.
+// ListDeadLetters handles GET /api/v1/webhooks/dead-letters.
+func (n *Notifier) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	deadLetters := n.DeadLetters(limit)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"dead_letters": deadLetters,
+		"count":        len(deadLetters),
+	})
+}
+
+// RegisterWebhook handles POST /api/v1/webhooks. A signing secret is
+// generated when none is supplied; the response is the only time it is shown.
 func (n *Notifier) RegisterWebhook(w http.ResponseWriter, r *http.Request) {
 	var cfg WebhookConfig
 	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
@@ -41,6 +63,15 @@ func (n *Notifier) RegisterWebhook(w http.ResponseWriter, r *http.Request) {
 	if cfg.ID == "" {
 		cfg.ID = uuid.NewString()
 	}
+	cfg.Secret = strings.TrimSpace(cfg.Secret)
+	if cfg.Secret == "" {
+		secret, err := GenerateSecret()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		cfg.Secret = secret
+	}
 
 	n.Register(cfg)
 	writeJSON(w, http.StatusCreated, cfg)
@@ -55,7 +86,7 @@ func (n *Notifier) GetWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, cfg)
+	writeJSON(w, http.StatusOK, cfg.redacted())
 }
 
 // DeleteWebhook handles DELETE /api/v1/webhooks/{id}.
@@ -87,7 +118,7 @@ func (n *Notifier) TestWebhook(w http.ResponseWriter, r *http.Request) {
 		Detail:    map[string]string{"id": cfg.ID},
 	}
 
-	if _, err := n.sendPayloadWithRetries(cfg, testPayload); err != nil {
+	if _, err := n.sendPayload(cfg, testPayload); err != nil {
 		writeError(w, http.StatusBadGateway, err.Error())
 		return
 	}
@@ -2,38 +2,103 @@ package webhook
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-const defaultDeliveryHistoryLimit = 100
+const (
+	defaultDeliveryHistoryLimit = 100
+	defaultDeadLetterLimit      = 500
+)
+
+// Delivery statuses reported in DeliveryRecord.Status.
+const (
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusRetrying  = "retrying"
+	DeliveryStatusFailed    = "failed"
+)
 
-// DeliveryObserver records webhook delivery outcomes.
+// DeliveryObserver records webhook delivery outcomes. It is called once per
+// attempt; exhausted is set on the final failed attempt of a delivery.
 type DeliveryObserver interface {
-	RecordWebhookDelivery(eventType string, statusCode int, duration time.Duration, err error)
+	RecordWebhookDelivery(eventType string, attempt, statusCode int, duration time.Duration, err error, exhausted bool)
+}
+
+// RetryPolicy controls redelivery of failed webhook deliveries. Attempts are
+// spaced by exponential backoff starting at InitialBackoff, capped at MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Minute,
+	}
+}
+
+// backoff returns the delay before the attempt following a failed attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+func (p RetryPolicy) normalized() RetryPolicy {
+	def := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = def.InitialBackoff
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = p.InitialBackoff
+	}
+	return p
 }
 
-// DeliveryRecord captures one webhook delivery attempt.
+// DeliveryRecord tracks one webhook delivery across its attempts.
 type DeliveryRecord struct {
-	Timestamp  time.Time `json:"timestamp"`
-	EventType  string    `json:"event_type"`
-	TargetURL  string    `json:"target_url"`
-	StatusCode int       `json:"status_code"`
-	DurationMS int64     `json:"duration_ms"`
-	Error      string    `json:"error,omitempty"`
+	ID          string     `json:"id"`
+	WebhookID   string     `json:"webhook_id"`
+	Timestamp   time.Time  `json:"timestamp"`
+	EventType   string     `json:"event_type"`
+	TargetURL   string     `json:"target_url"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	NextRetryAt *time.Time `json:"next_retry_at,omitempty"`
+	StatusCode  int        `json:"status_code"`
+	DurationMS  int64      `json:"duration_ms"`
+	Error       string     `json:"error,omitempty"`
 }
 
-// WebhookConfig holds a registered webhook endpoint.
+// DeadLetter is a delivery that failed every attempt, kept with its payload
+// so it can be inspected or replayed by hand.
+type DeadLetter struct {
+	DeliveryRecord
+	Payload WebhookPayload `json:"payload"`
+}
+
+// WebhookConfig holds a registered webhook endpoint. Secret signs every
+// delivery (see Sign); the API returns it only when the webhook is created.
 type WebhookConfig struct {
 	ID      string   `json:"id"`
 	URL     string   `json:"url"`
@@ -42,6 +107,12 @@ type WebhookConfig struct {
 	Enabled bool     `json:"enabled"`
 }
 
+// redacted returns a copy safe to return from list/get endpoints.
+func (c WebhookConfig) redacted() WebhookConfig {
+	c.Secret = ""
+	return c
+}
+
 // WebhookPayload is the JSON body sent to webhook endpoints.
 type WebhookPayload struct {
 	ID        string    `json:"id"`
@@ -58,9 +129,11 @@ type Notifier struct {
 	items      map[string]WebhookConfig
 	httpClient *http.Client
 	observer   DeliveryObserver
+	retry      RetryPolicy
 
-	deliveryMu sync.RWMutex
-	deliveries []DeliveryRecord
+	deliveryMu  sync.RWMutex
+	deliveries  []DeliveryRecord
+	deadLetters []DeadLetter
 }
 
 // NewNotifier creates a new notifier with sane defaults.
@@ -68,10 +141,18 @@ func NewNotifier() *Notifier {
 	return &Notifier{
 		items:      make(map[string]WebhookConfig),
 		httpClient: &http.Client{Timeout: 5 * time.Second},
+		retry:      DefaultRetryPolicy(),
 		deliveries: make([]DeliveryRecord, 0, defaultDeliveryHistoryLimit),
 	}
 }
 
+// SetRetryPolicy replaces the retry policy; zero fields fall back to defaults.
+func (n *Notifier) SetRetryPolicy(policy RetryPolicy) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.retry = policy.normalized()
+}
+
 // SetDeliveryObserver registers an optional delivery observer.
 func (n *Notifier) SetDeliveryObserver(observer DeliveryObserver) {
 	n.mu.Lock()
@@ -130,6 +211,22 @@ func (n *Notifier) Deliveries(limit int) []DeliveryRecord {
 	return out
 }
 
+// DeadLetters returns deliveries that exhausted their retries (newest first).
+func (n *Notifier) DeadLetters(limit int) []DeadLetter {
+	n.deliveryMu.RLock()
+	defer n.deliveryMu.RUnlock()
+
+	if limit <= 0 || limit > len(n.deadLetters) {
+		limit = len(n.deadLetters)
+	}
+
+	out := make([]DeadLetter, 0, limit)
+	for i := len(n.deadLetters) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, n.deadLetters[i])
+	}
+	return out
+}
+
 // Notify sends payloads to all enabled webhooks matching the event.
 func (n *Notifier) Notify(event, probeID, summary string, detail any) {
 	n.mu.RLock()
@@ -161,86 +258,145 @@ func (n *Notifier) Notify(event, probeID, summary string, detail any) {
 			Detail:    detail,
 		}
 		webhook := cfg
-		go func() {
-			started := time.Now()
-			statusCode, err := n.sendPayloadWithRetries(webhook, payload)
-			n.recordDelivery(payload.Event, webhook.URL, statusCode, time.Since(started), err)
-		}()
+		go n.deliver(webhook, payload)
 	}
 }
 
-// sendPayloadWithRetries posts a payload to one webhook endpoint, retrying once on failure.
-func (n *Notifier) sendPayloadWithRetries(cfg WebhookConfig, payload WebhookPayload) (int, error) {
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return 0, fmt.Errorf("marshal webhook payload: %w", err)
+// deliver posts a payload until it succeeds or the retry policy is exhausted,
+// then moves it to the dead-letter list. It blocks between attempts, so
+// callers run it in its own goroutine.
+func (n *Notifier) deliver(cfg WebhookConfig, payload WebhookPayload) {
+	policy := n.retryPolicy()
+	record := DeliveryRecord{
+		ID:        uuid.NewString(),
+		WebhookID: cfg.ID,
+		EventType: payload.Event,
+		TargetURL: maskTargetURL(cfg.URL),
 	}
 
-	httpClient := n.client()
-
-	var (
-		lastErr    error
-		statusCode int
-	)
-	for attempt := 1; attempt <= 2; attempt++ {
-		req, err := http.NewRequest(http.MethodPost, cfg.URL, bytes.NewReader(body))
+	for attempt := 1; ; attempt++ {
+		started := time.Now()
+		statusCode, err := n.sendPayload(cfg, payload)
+		duration := time.Since(started)
+		exhausted := err != nil && attempt >= policy.MaxAttempts
+
+		record.Timestamp = time.Now().UTC()
+		record.Attempts = attempt
+		record.StatusCode = statusCode
+		record.DurationMS = duration.Milliseconds()
+		record.NextRetryAt = nil
+		record.Error = ""
 		if err != nil {
-			return 0, fmt.Errorf("webhook request: %w", err)
-		}
-		req.Header.Set("Content-Type", "application/json")
-		if cfg.Secret != "" {
-			req.Header.Set("X-Legator-Signature", signature(cfg.Secret, body))
+			record.Error = err.Error()
 		}
 
-		resp, err := httpClient.Do(req)
-		if err == nil {
-			statusCode = resp.StatusCode
-			_, _ = io.Copy(io.Discard, resp.Body)
-			_ = resp.Body.Close()
-			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-				return statusCode, nil
-			}
-			lastErr = fmt.Errorf("webhook returned status %d", resp.StatusCode)
-		} else {
-			lastErr = err
+		switch {
+		case err == nil:
+			record.Status = DeliveryStatusDelivered
+		case exhausted:
+			record.Status = DeliveryStatusFailed
+		default:
+			record.Status = DeliveryStatusRetrying
+			next := record.Timestamp.Add(policy.backoff(attempt))
+			record.NextRetryAt = &next
+		}
+		n.recordDelivery(record, payload)
+		if observer := n.deliveryObserver(); observer != nil {
+			observer.RecordWebhookDelivery(payload.Event, attempt, statusCode, duration, err, exhausted)
+		}
+		if record.Status != DeliveryStatusRetrying {
+			return
 		}
 
-		if attempt == 1 {
-			// one retry after initial attempt
-			continue
+		time.Sleep(time.Until(*record.NextRetryAt))
+
+		// Pick up URL/secret changes between attempts, and stop retrying a
+		// webhook that was removed or disabled in the meantime.
+		current, ok := n.get(cfg.ID)
+		if !ok || !current.Enabled {
+			record.Status = DeliveryStatusFailed
+			record.NextRetryAt = nil
+			record.Error = "webhook removed or disabled before retry"
+			n.recordDelivery(record, payload)
+			return
 		}
+		cfg = current
 	}
-
-	return statusCode, lastErr
 }
 
-func (n *Notifier) recordDelivery(eventType, targetURL string, statusCode int, duration time.Duration, err error) {
-	record := DeliveryRecord{
-		Timestamp:  time.Now().UTC(),
-		EventType:  eventType,
-		TargetURL:  maskTargetURL(targetURL),
-		StatusCode: statusCode,
-		DurationMS: duration.Milliseconds(),
+// sendPayload posts a payload to one webhook endpoint once. Non-2xx
+// responses are returned as errors along with the status code.
+func (n *Notifier) sendPayload(cfg WebhookConfig, payload WebhookPayload) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("marshal webhook payload: %w", err)
 	}
+
+	req, err := http.NewRequest(http.MethodPost, cfg.URL, bytes.NewReader(body))
 	if err != nil {
-		record.Error = err.Error()
+		return 0, fmt.Errorf("webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.Secret != "" {
+		// Each attempt is signed with a fresh timestamp so retries are not
+		// rejected as stale by receivers checking the signature age.
+		now := time.Now()
+		req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set(SignatureHeader, Sign(cfg.Secret, now, body))
 	}
 
+	resp, err := n.client().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// recordDelivery upserts a delivery into the bounded history and, once it
+// has failed for good, appends it to the bounded dead-letter list.
+func (n *Notifier) recordDelivery(record DeliveryRecord, payload WebhookPayload) {
 	n.deliveryMu.Lock()
-	n.deliveries = append(n.deliveries, record)
-	if len(n.deliveries) > defaultDeliveryHistoryLimit {
-		offset := len(n.deliveries) - defaultDeliveryHistoryLimit
-		copy(n.deliveries, n.deliveries[offset:])
-		n.deliveries = n.deliveries[:defaultDeliveryHistoryLimit]
+	defer n.deliveryMu.Unlock()
+
+	updated := false
+	for i := len(n.deliveries) - 1; i >= 0; i-- {
+		if n.deliveries[i].ID == record.ID {
+			n.deliveries[i] = record
+			updated = true
+			break
+		}
+	}
+	if !updated {
+		n.deliveries = append(n.deliveries, record)
+		if len(n.deliveries) > defaultDeliveryHistoryLimit {
+			offset := len(n.deliveries) - defaultDeliveryHistoryLimit
+			copy(n.deliveries, n.deliveries[offset:])
+			n.deliveries = n.deliveries[:defaultDeliveryHistoryLimit]
+		}
 	}
-	n.deliveryMu.Unlock()
 
-	observer := n.deliveryObserver()
-	if observer != nil {
-		observer.RecordWebhookDelivery(eventType, statusCode, duration, err)
+	if record.Status != DeliveryStatusFailed {
+		return
+	}
+	n.deadLetters = append(n.deadLetters, DeadLetter{DeliveryRecord: record, Payload: payload})
+	if len(n.deadLetters) > defaultDeadLetterLimit {
+		offset := len(n.deadLetters) - defaultDeadLetterLimit
+		copy(n.deadLetters, n.deadLetters[offset:])
+		n.deadLetters = n.deadLetters[:defaultDeadLetterLimit]
 	}
 }
 
+func (n *Notifier) retryPolicy() RetryPolicy {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.retry.normalized()
+}
+
 func (n *Notifier) deliveryObserver() DeliveryObserver {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
@@ -288,9 +444,3 @@ func maskTargetURL(raw string) string {
 
 	return fmt.Sprintf("%s://%s/***", scheme, u.Host)
 }
-
-func signature(secret string, body []byte) string {
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(body)
-	return hex.EncodeToString(mac.Sum(nil))
-}
@@ -8,7 +8,10 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -68,11 +71,13 @@ func TestNotifier_NotifyHMACSignature(t *testing.T) {
 
 	payloads := make(chan []byte, 1)
 	sig := make(chan string, 1)
+	ts := make(chan string, 1)
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		body, _ := io.ReadAll(r.Body)
 		payloads <- body
-		sig <- r.Header.Get("X-Legator-Signature")
+		sig <- r.Header.Get(SignatureHeader)
+		ts <- r.Header.Get(TimestampHeader)
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer server.Close()
@@ -89,12 +94,21 @@ func TestNotifier_NotifyHMACSignature(t *testing.T) {
 		t.Fatal("timed out waiting for signature header")
 	}
 
+	var gotTS string
+	if !awaitSignalValue(t, ts, &gotTS, 2*time.Second) {
+		t.Fatal("timed out waiting for timestamp header")
+	}
+
 	target := hmac.New(sha256.New, []byte(secret))
+	target.Write([]byte(gotTS + "."))
 	target.Write(body)
-	expectedSig := hex.EncodeToString(target.Sum(nil))
+	expectedSig := "sha256=" + hex.EncodeToString(target.Sum(nil))
 	if gotSig != expectedSig {
 		t.Fatalf("signature = %q, want %q", gotSig, expectedSig)
 	}
+	if err := VerifySignature(secret, gotSig, gotTS, body, 0, time.Now()); err != nil {
+		t.Fatalf("verify delivered signature: %v", err)
+	}
 }
 
 func TestNotifier_NotifySkipsNonMatchingEvents(t *testing.T) {
@@ -169,6 +183,108 @@ func TestNotifier_NotifyRetriesOnFailure(t *testing.T) {
 	}
 }
 
+func TestNotifier_RetriesThenDeadLetters(t *testing.T) {
+	n := NewNotifier()
+	n.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond})
+	observer := &recordingObserver{}
+	n.SetDeliveryObserver(observer)
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	n.Register(WebhookConfig{ID: "dlq", URL: server.URL, Events: []string{"probe.offline"}, Enabled: true})
+	n.Notify("probe.offline", "probe-1", "summary", map[string]string{"status": "down"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(n.DeadLetters(0)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for dead letter, hits=%d", hits.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got := hits.Load(); got != 3 {
+		t.Fatalf("webhook hits = %d, want 3", got)
+	}
+	deliveries := n.Deliveries(0)
+	if len(deliveries) != 1 {
+		t.Fatalf("expected one tracked delivery, got %d", len(deliveries))
+	}
+	d := deliveries[0]
+	if d.Status != DeliveryStatusFailed || d.Attempts != 3 || d.NextRetryAt != nil || d.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected delivery record: %+v", d)
+	}
+
+	dead := n.DeadLetters(0)[0]
+	if dead.ID != d.ID || dead.Payload.Event != "probe.offline" || dead.Payload.ProbeID != "probe-1" {
+		t.Fatalf("unexpected dead letter: %+v", dead)
+	}
+
+	attempts, exhausted := observer.snapshot()
+	if strings.Join(attempts, ",") != "1,2,3" || exhausted != 1 {
+		t.Fatalf("observer attempts=%v exhausted=%d, want 1,2,3 and 1", attempts, exhausted)
+	}
+}
+
+func TestNotifier_TracksPendingRetry(t *testing.T) {
+	n := NewNotifier()
+	n.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Hour})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	n.Register(WebhookConfig{ID: "pending", URL: server.URL, Events: []string{"probe.offline"}, Enabled: true})
+	n.Notify("probe.offline", "probe-1", "summary", nil)
+	waitForDeliveries(t, n, 1, 2*time.Second)
+
+	d := n.Deliveries(1)[0]
+	if d.Status != DeliveryStatusRetrying || d.Attempts != 1 || d.NextRetryAt == nil {
+		t.Fatalf("expected a pending retry, got %+v", d)
+	}
+	if wait := time.Until(*d.NextRetryAt); wait < 59*time.Minute {
+		t.Fatalf("next retry should follow the backoff, got %s", wait)
+	}
+	if len(n.DeadLetters(0)) != 0 {
+		t.Fatal("retrying delivery must not be dead-lettered")
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 6, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w {
+			t.Fatalf("backoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
+
+func TestNotifier_HTTPHandlers_DeadLettersEndpoint(t *testing.T) {
+	n := NewNotifier()
+	n.recordDelivery(DeliveryRecord{ID: "d-1", WebhookID: "wh", EventType: "probe.offline", Status: DeliveryStatusFailed, Attempts: 5}, WebhookPayload{Event: "probe.offline"})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/dead-letters", nil)
+	resp := httptest.NewRecorder()
+	n.ListDeadLetters(resp, req)
+
+	var payload struct {
+		DeadLetters []DeadLetter `json:"dead_letters"`
+		Count       int          `json:"count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode dead letters: %v", err)
+	}
+	if payload.Count != 1 || payload.DeadLetters[0].ID != "d-1" || payload.DeadLetters[0].Attempts != 5 {
+		t.Fatalf("unexpected dead letters payload: %+v", payload)
+	}
+}
+
 func TestNotifier_HTTPHandlers_CRUD(t *testing.T) {
 	n := NewNotifier()
 
@@ -191,6 +307,9 @@ func TestNotifier_HTTPHandlers_CRUD(t *testing.T) {
 	if err := json.NewDecoder(regResp.Body).Decode(&created); err != nil {
 		t.Fatalf("decode created webhook: %v", err)
 	}
+	if !strings.HasPrefix(created.Secret, "whsec_") {
+		t.Fatalf("expected a generated secret on creation, got %q", created.Secret)
+	}
 
 	listReq := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil)
 	listResp := httptest.NewRecorder()
@@ -205,6 +324,9 @@ func TestNotifier_HTTPHandlers_CRUD(t *testing.T) {
 	if len(listed) != 1 {
 		t.Fatalf("list count = %d, want 1", len(listed))
 	}
+	if listed[0].Secret != "" {
+		t.Fatal("list must not expose webhook secrets")
+	}
 
 	getReq := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/"+created.ID, nil)
 	getResp := httptest.NewRecorder()
@@ -212,6 +334,9 @@ func TestNotifier_HTTPHandlers_CRUD(t *testing.T) {
 	if getResp.Code != http.StatusOK {
 		t.Fatalf("get status = %d, want %d", getResp.Code, http.StatusOK)
 	}
+	if strings.Contains(getResp.Body.String(), created.Secret) {
+		t.Fatal("get must not expose the webhook secret")
+	}
 
 	delReq := httptest.NewRequest(http.MethodDelete, "/api/v1/webhooks/"+created.ID, nil)
 	delResp := httptest.NewRecorder()
@@ -394,6 +519,27 @@ func waitForMetric(t *testing.T, c *metrics.Collector, needle string, timeout ti
 	t.Fatalf("timed out waiting for metric %q", needle)
 }
 
+type recordingObserver struct {
+	mu        sync.Mutex
+	attempts  []string
+	exhausted int
+}
+
+func (o *recordingObserver) RecordWebhookDelivery(eventType string, attempt, statusCode int, duration time.Duration, err error, exhausted bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.attempts = append(o.attempts, strconv.Itoa(attempt))
+	if exhausted {
+		o.exhausted++
+	}
+}
+
+func (o *recordingObserver) snapshot() ([]string, int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.attempts...), o.exhausted
+}
+
 type metricsTestFleet struct{}
 
 func (m *metricsTestFleet) Count() map[string]int     { return map[string]int{} }
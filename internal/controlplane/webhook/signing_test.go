@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("generate secret: %v", err)
+	}
+	body := []byte(`{"id":"wh-1","event":"probe.offline"}`)
+	sentAt := time.Unix(1767225600, 0)
+	ts := strconv.FormatInt(sentAt.Unix(), 10)
+	sig := Sign(secret, sentAt, body)
+
+	if err := VerifySignature(secret, sig, ts, body, time.Minute, sentAt.Add(30*time.Second)); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	cases := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      []byte
+		now       time.Time
+		want      error
+	}{
+		{"tampered body", secret, sig, ts, []byte(`{"id":"wh-1","event":"probe.online"}`), sentAt, ErrInvalidSignature},
+		{"wrong secret", "other", sig, ts, body, sentAt, ErrInvalidSignature},
+		{"shifted timestamp", secret, sig, strconv.FormatInt(sentAt.Unix()+1, 10), body, sentAt, ErrInvalidSignature},
+		{"missing prefix", secret, sig[len("sha256="):], ts, body, sentAt, ErrInvalidSignature},
+		{"replayed later", secret, sig, ts, body, sentAt.Add(2 * time.Minute), ErrStaleTimestamp},
+		{"missing signature", secret, "", ts, body, sentAt, ErrMissingSignature},
+		{"bad timestamp", secret, sig, "yesterday", body, sentAt, ErrMissingSignature},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifySignature(tc.secret, tc.signature, tc.timestamp, tc.body, time.Minute, tc.now)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestGenerateSecretIsUnique(t *testing.T) {
+	a, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b || len(a) != len("whsec_")+64 {
+		t.Fatalf("unexpected secrets %q %q", a, b)
+	}
+}
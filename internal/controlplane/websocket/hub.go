@@ -72,7 +72,8 @@ func NewHub(logger *zap.Logger, onMsg func(string, protocol.Envelope)) *Hub {
 	}
 }
 
-// SetSigner enables command signing on outgoing messages.
+// SetSigner enables command signing on outgoing messages. s holds the master
+// key; each message is signed with the recipient probe's derived key.
 func (h *Hub) SetSigner(s *signing.Signer) {
 	h.signer = s
 }
@@ -289,8 +290,13 @@ func (h *Hub) SendTo(probeID string, msgType protocol.MessageType, payload any)
 		Payload:   payload,
 	}
 
-	if h.signer != nil && msgType == protocol.MsgCommand {
-		sig, err := h.signer.Sign(env.ID, payload)
+	if h.signer != nil && (msgType == protocol.MsgCommand || msgType == protocol.MsgLogTailStart || msgType == protocol.MsgLocalSchedule) {
+		nonce, err := signing.NewNonce()
+		if err != nil {
+			return fmt.Errorf("generate nonce: %w", err)
+		}
+		env.Nonce = nonce
+		sig, err := h.signer.ForProbe(probeID).SignEnvelope(env.ID, env.Nonce, env.Timestamp, payload)
 		if err != nil {
 			return fmt.Errorf("sign command: %w", err)
 		}
@@ -307,6 +313,14 @@ func (h *Hub) SendTo(probeID string, msgType protocol.MessageType, payload any)
 	return pc.Conn.WriteMessage(websocket.TextMessage, data)
 }
 
+// IsConnected reports whether a probe currently has a live connection.
+func (h *Hub) IsConnected(probeID string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	_, ok := h.probes[probeID]
+	return ok
+}
+
 // Connected returns a list of connected probe IDs.
 func (h *Hub) Connected() []string {
 	h.mu.RLock()
@@ -3,7 +3,10 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/marcus-qen/legator/internal/probe/connection"
@@ -16,17 +19,33 @@ import (
 )
 
 const (
-	inventoryInterval = 15 * time.Minute
+	inventoryInterval = protocol.DefaultInventoryInterval
 )
 
+// Version is the running probe binary version, reported at registration and
+// on every heartbeat. cmd/probe sets it from its build-time version.
+var Version = "dev"
+
 // Agent is the main probe agent loop.
 type Agent struct {
 	config   *Config
 	client   *connection.Client
 	executor *executor.Executor
 	verifier *signing.Signer
+	replay   *signing.ReplayGuard
 	updater  *updater.Updater
+	watcher  *alertWatcher
+	local    *localScheduler
 	logger   *zap.Logger
+
+	mu          sync.Mutex
+	running     map[string]context.CancelFunc // request_id -> cancel for in-flight commands
+	commands    int                           // commands admitted and not yet finished
+	maxCommands int                           // 0 = unlimited
+	rateLimit   commandRateLimiter
+
+	inventoryEvery time.Duration
+	inventoryReset chan struct{}
 }
 
 // New creates a new probe agent.
@@ -40,6 +59,7 @@ func New(cfg *Config, logger *zap.Logger) *Agent {
 	}
 
 	client := connection.NewClient(wsURL, cfg.ProbeID, cfg.APIKey, logger.Named("ws"))
+	client.SetVersion(Version)
 	if cfg.MTLS.Enabled {
 		dialer, err := buildMTLSDialer(cfg.MTLS)
 		if err != nil {
@@ -49,34 +69,229 @@ func New(cfg *Config, logger *zap.Logger) *Agent {
 			logger.Info("probe websocket mTLS enabled")
 		}
 	}
+	if cfg.TokenAuth {
+		client.EnableTokenAuth()
+	}
 
 	policyLevel := cfg.PolicyLevel
 	if policyLevel == "" {
 		policyLevel = protocol.CapObserve
 	}
 	policy := executor.Policy{
-		Level:   policyLevel,
-		Allowed: append([]string(nil), cfg.PolicyAllowed...),
-		Blocked: append([]string(nil), cfg.PolicyBlocked...),
-		Paths:   append([]string(nil), cfg.PolicyPaths...),
+		Level:    policyLevel,
+		Allowed:  append([]string(nil), cfg.PolicyAllowed...),
+		Blocked:  append([]string(nil), cfg.PolicyBlocked...),
+		Paths:    append([]string(nil), cfg.PolicyPaths...),
+		WorkDirs: append([]string(nil), cfg.PolicyWorkDirs...),
+		EnvKeys:  append([]string(nil), cfg.PolicyEnvKeys...),
 	}
 	exec := executor.New(policy, logger.Named("exec"))
+	exec.SetOutputLimit(cfg.MaxOutputBytes)
 
+	// The control plane signs update manifests and command envelopes with
+	// this probe's key derived from the hex-decoded master key.
 	var verifier *signing.Signer
 	if cfg.SigningKey != "" {
-		key := signing.DeriveProbeKey([]byte(cfg.SigningKey), cfg.ProbeID)
+		master, err := signing.DecodeMasterKey(cfg.SigningKey)
+		if err != nil {
+			// The control plane only takes hex keys, so this key will not
+			// match it; keep verification on rather than accept unsigned.
+			logger.Warn("signing_key is not hex; signed messages from the control plane will be rejected", zap.Error(err))
+			master = []byte(cfg.SigningKey)
+		}
+		key := signing.DeriveProbeKey(master, cfg.ProbeID)
 		verifier = signing.NewSigner(key)
 		logger.Info("command signature verification enabled")
 	}
 
-	return &Agent{
+	watcher := newAlertWatcher(func(alert protocol.AlertPayload) error {
+		return client.Send(protocol.MsgAlert, alert)
+	}, logger.Named("alerts"))
+	watcher.configure(cfg.AlertWatch)
+
+	a := &Agent{
 		config:   cfg,
 		client:   client,
 		executor: exec,
 		verifier: verifier,
+		replay:   signing.NewReplayGuard(signing.DefaultMaxAge, signing.DefaultNonceCapacity),
 		updater:  updater.New(logger.Named("updater")),
+		watcher:  watcher,
 		logger:   logger,
+
+		inventoryEvery: inventoryInterval,
+		inventoryReset: make(chan struct{}, 1),
+	}
+	configDir := cfg.ConfigDir
+	if configDir == "" {
+		configDir = DefaultConfigDir
+	}
+	a.local = newLocalScheduler(filepath.Join(configDir, localScheduleResultsFile), logger.Named("local-schedule"))
+	a.local.exec = a.runLocalEntry
+	a.local.connected = client.Connected
+	a.local.send = func(results protocol.LocalScheduleResultsPayload) error {
+		return client.Send(protocol.MsgLocalScheduleResults, results)
+	}
+	a.local.configure(cfg.LocalSchedule)
+	a.maxCommands = cfg.PolicyMaxConcurrentCommands
+	a.rateLimit.configure(cfg.PolicyRateLimit)
+	a.applyCadence(cfg.PolicyHeartbeatIntervalSec, cfg.PolicyInventoryIntervalSec)
+	client.SetInFlightCounter(a.inFlightCommands)
+	client.SetResourceSampler((&resourceSampler{}).fill)
+	return a
+}
+
+// effectivePolicy reports what the probe actually enforces: the executor's
+// compiled rules plus the v2 policy fields persisted in config.
+func (a *Agent) effectivePolicy() protocol.PolicyUpdatePayload {
+	policy := a.executor.Policy()
+	return protocol.PolicyUpdatePayload{
+		PolicyID:               a.config.PolicyID,
+		Level:                  policy.Level,
+		Allowed:                policy.Allowed,
+		Blocked:                policy.Blocked,
+		Paths:                  policy.Paths,
+		WorkDirs:               policy.WorkDirs,
+		EnvKeys:                policy.EnvKeys,
+		ExecutionClassRequired: a.config.PolicyExecutionClassRequired,
+		SandboxRequired:        a.config.PolicySandboxRequired,
+		ApprovalMode:           a.config.PolicyApprovalMode,
+		Breakglass:             a.config.PolicyBreakglass,
+		MaxRuntimeSec:          a.config.PolicyMaxRuntimeSec,
+		AllowedScopes:          append([]string(nil), a.config.PolicyAllowedScopes...),
+		AlertWatch:             a.config.AlertWatch,
+		MaxConcurrentCommands:  a.config.PolicyMaxConcurrentCommands,
+		RateLimit:              a.config.PolicyRateLimit,
+		HeartbeatIntervalSec:   a.config.PolicyHeartbeatIntervalSec,
+		InventoryIntervalSec:   a.config.PolicyInventoryIntervalSec,
+	}
+}
+
+// verifyCommand checks the envelope signature, then rejects stale timestamps
+// and replayed nonces. It is a no-op when signing is not configured.
+func (a *Agent) verifyCommand(env protocol.Envelope, cmd protocol.CommandPayload) error {
+	return a.verifySigned(env, cmd, cmd.RequestID)
+}
+
+// verifySigned applies signature and replay checks to any payload the
+// control plane signs (commands and log tail starts).
+func (a *Agent) verifySigned(env protocol.Envelope, payload any, requestID string) error {
+	if a.verifier == nil {
+		return nil
+	}
+	if env.Signature == "" {
+		return fmt.Errorf("missing signature")
+	}
+	if err := a.verifier.VerifyEnvelope(env.ID, env.Nonce, env.Timestamp, payload, env.Signature); err != nil {
+		return fmt.Errorf("invalid signature")
+	}
+	if err := a.replay.Check(env.Nonce, env.Timestamp); err != nil {
+		return err
+	}
+	a.logger.Debug("command signature verified", zap.String("request_id", requestID))
+	return nil
+}
+
+// track registers a cancellable context for an in-flight request so
+// command_cancel and log_tail_stop can reach it. release must be called when
+// the request finishes.
+func (a *Agent) track(requestID string) (ctx context.Context, cancel context.CancelFunc, release func()) {
+	ctx, cancel = context.WithCancel(context.Background())
+	a.mu.Lock()
+	if a.running == nil {
+		a.running = make(map[string]context.CancelFunc)
+	}
+	a.running[requestID] = cancel
+	a.mu.Unlock()
+	return ctx, cancel, func() {
+		a.mu.Lock()
+		delete(a.running, requestID)
+		a.mu.Unlock()
+		cancel()
+	}
+}
+
+// admitCommand reserves a command slot. It reports false, with the current
+// count and limit, when the probe is already at its concurrency limit.
+func (a *Agent) admitCommand() (ok bool, inFlight, limit int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.maxCommands > 0 && a.commands >= a.maxCommands {
+		return false, a.commands, a.maxCommands
 	}
+	a.commands++
+	return true, a.commands, a.maxCommands
+}
+
+func (a *Agent) finishCommand() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.commands > 0 {
+		a.commands--
+	}
+}
+
+// inFlightCommands reports how many admitted commands are still running.
+func (a *Agent) inFlightCommands() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.commands
+}
+
+func (a *Agent) setMaxCommands(limit int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.maxCommands = limit
+}
+
+// rejectBusy answers a command refused at the concurrency limit. Streamed
+// commands get a final stderr chunk; others a busy command result.
+func (a *Agent) rejectBusy(cmd protocol.CommandPayload, inFlight, limit int) {
+	msg := fmt.Sprintf("probe busy: %d commands in flight (limit %d)", inFlight, limit)
+	a.logger.Warn("command rejected", zap.String("request_id", cmd.RequestID), zap.String("reason", msg))
+	if cmd.Stream {
+		_ = a.client.Send(protocol.MsgOutputChunk, protocol.OutputChunkPayload{
+			RequestID: cmd.RequestID, Stream: "stderr", Data: msg, Final: true, ExitCode: -1,
+		})
+		return
+	}
+	_ = a.client.Send(protocol.MsgCommandResult, &protocol.CommandResultPayload{
+		RequestID: cmd.RequestID, ExitCode: -1, Stderr: msg, Busy: true,
+	})
+}
+
+// runCommand executes an admitted command and frees its slot when done.
+func (a *Agent) runCommand(cmd protocol.CommandPayload) {
+	defer a.finishCommand()
+	ctx, _, release := a.track(cmd.RequestID)
+	defer release()
+
+	if cmd.Stream {
+		a.executor.ExecuteStream(ctx, &cmd, func(chunk protocol.OutputChunkPayload) {
+			if err := a.client.Send(protocol.MsgOutputChunk, chunk); err != nil {
+				a.logger.Error("failed to send output chunk", zap.Error(err))
+			}
+		})
+		return
+	}
+	result := a.executor.Execute(ctx, &cmd)
+	if err := result.CompressOutput(a.config.CompressOutputBytes); err != nil {
+		a.logger.Warn("sending command output uncompressed", zap.String("request_id", cmd.RequestID), zap.Error(err))
+	}
+	if err := a.client.Send(protocol.MsgCommandResult, result); err != nil {
+		a.logger.Error("failed to send result", zap.Error(err))
+	}
+}
+
+// cancelCommand kills an in-flight command. It reports whether one was found.
+func (a *Agent) cancelCommand(requestID string) bool {
+	a.mu.Lock()
+	cancel, ok := a.running[requestID]
+	a.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
 }
 
 // Run starts the agent loop. Blocks until ctx is cancelled.
@@ -102,6 +317,12 @@ func (a *Agent) Run(ctx context.Context) error {
 	// Start inventory refresh loop
 	go a.inventoryLoop(ctx)
 
+	// Watch local conditions configured by policy
+	go a.watcher.run(ctx)
+
+	// Run the local schedule, online or not
+	go a.local.run(ctx)
+
 	// Process incoming messages
 	for {
 		select {
@@ -124,22 +345,23 @@ func (a *Agent) handleMessage(env protocol.Envelope) {
 			return
 		}
 
-		if a.verifier != nil {
-			if env.Signature == "" {
-				a.logger.Warn("unsigned command rejected", zap.String("request_id", cmd.RequestID))
-				_ = a.client.Send(protocol.MsgCommandResult, &protocol.CommandResultPayload{
-					RequestID: cmd.RequestID, ExitCode: -1, Stderr: "command rejected: missing signature",
-				})
-				return
-			}
-			if err := a.verifier.Verify(env.ID, cmd, env.Signature); err != nil {
-				a.logger.Warn("invalid command signature", zap.String("request_id", cmd.RequestID), zap.Error(err))
-				_ = a.client.Send(protocol.MsgCommandResult, &protocol.CommandResultPayload{
-					RequestID: cmd.RequestID, ExitCode: -1, Stderr: "command rejected: invalid signature",
-				})
-				return
-			}
-			a.logger.Debug("command signature verified", zap.String("request_id", cmd.RequestID))
+		if err := a.verifyCommand(env, cmd); err != nil {
+			a.logger.Warn("command rejected", zap.String("request_id", cmd.RequestID), zap.Error(err))
+			_ = a.client.Send(protocol.MsgCommandResult, &protocol.CommandResultPayload{
+				RequestID: cmd.RequestID, ExitCode: -1, Stderr: "command rejected: " + err.Error(),
+			})
+			return
+		}
+
+		ok, inFlight, limit := a.admitCommand()
+		if !ok {
+			a.rejectBusy(cmd, inFlight, limit)
+			return
+		}
+		if ok, retryAfter, reason := a.rateLimit.admit(fullCommand(cmd), time.Now()); !ok {
+			a.finishCommand()
+			a.rejectRateLimited(cmd, retryAfter, reason)
+			return
 		}
 
 		a.logger.Info("executing command",
@@ -149,17 +371,53 @@ func (a *Agent) handleMessage(env protocol.Envelope) {
 			zap.Bool("stream", cmd.Stream),
 		)
 
-		if cmd.Stream {
-			a.executor.ExecuteStream(context.Background(), &cmd, func(chunk protocol.OutputChunkPayload) {
-				if err := a.client.Send(protocol.MsgOutputChunk, chunk); err != nil {
-					a.logger.Error("failed to send output chunk", zap.Error(err))
-				}
-			})
+		// Run off the inbox loop so a command_cancel can reach it.
+		go a.runCommand(cmd)
+
+	case protocol.MsgCommandCancel:
+		data, _ := json.Marshal(env.Payload)
+		var cancel protocol.CommandCancelPayload
+		if err := json.Unmarshal(data, &cancel); err != nil {
+			a.logger.Warn("invalid command cancel payload", zap.Error(err))
+			return
+		}
+		if a.cancelCommand(cancel.RequestID) {
+			a.logger.Info("command canceled by control plane",
+				zap.String("request_id", cancel.RequestID),
+				zap.String("reason", cancel.Reason),
+			)
 		} else {
-			result := a.executor.Execute(context.Background(), &cmd)
-			if err := a.client.Send(protocol.MsgCommandResult, result); err != nil {
-				a.logger.Error("failed to send result", zap.Error(err))
-			}
+			a.logger.Debug("cancel for unknown command ignored", zap.String("request_id", cancel.RequestID))
+		}
+
+	case protocol.MsgLogTailStart:
+		data, _ := json.Marshal(env.Payload)
+		var tail protocol.LogTailPayload
+		if err := json.Unmarshal(data, &tail); err != nil {
+			a.logger.Warn("invalid log tail payload", zap.Error(err))
+			return
+		}
+		if err := a.verifySigned(env, tail, tail.RequestID); err != nil {
+			a.logger.Warn("log tail rejected", zap.String("request_id", tail.RequestID), zap.Error(err))
+			_ = a.client.Send(protocol.MsgOutputChunk, protocol.OutputChunkPayload{
+				RequestID: tail.RequestID, Stream: "stderr", Data: "log tail rejected: " + err.Error(), Final: true, ExitCode: -1,
+			})
+			return
+		}
+		go a.runLogTail(tail)
+
+	case protocol.MsgLogTailStop:
+		data, _ := json.Marshal(env.Payload)
+		var stop protocol.LogTailStopPayload
+		if err := json.Unmarshal(data, &stop); err != nil {
+			a.logger.Warn("invalid log tail stop payload", zap.Error(err))
+			return
+		}
+		if a.cancelCommand(stop.RequestID) {
+			a.logger.Info("log tail stopped by control plane",
+				zap.String("request_id", stop.RequestID),
+				zap.String("reason", stop.Reason),
+			)
 		}
 
 	case protocol.MsgPolicyUpdate:
@@ -177,11 +435,14 @@ func (a *Agent) handleMessage(env protocol.Envelope) {
 
 		// Update executor policy
 		a.executor = executor.New(executor.Policy{
-			Level:   policy.Level,
-			Allowed: policy.Allowed,
-			Blocked: policy.Blocked,
-			Paths:   policy.Paths,
+			Level:    policy.Level,
+			Allowed:  policy.Allowed,
+			Blocked:  policy.Blocked,
+			Paths:    policy.Paths,
+			WorkDirs: policy.WorkDirs,
+			EnvKeys:  policy.EnvKeys,
 		}, a.logger.Named("exec"))
+		a.executor.SetOutputLimit(a.config.MaxOutputBytes)
 
 		// Persist policy to config for restart safety.
 		a.config.PolicyID = policy.PolicyID
@@ -189,16 +450,75 @@ func (a *Agent) handleMessage(env protocol.Envelope) {
 		a.config.PolicyAllowed = append([]string(nil), policy.Allowed...)
 		a.config.PolicyBlocked = append([]string(nil), policy.Blocked...)
 		a.config.PolicyPaths = append([]string(nil), policy.Paths...)
+		a.config.PolicyWorkDirs = append([]string(nil), policy.WorkDirs...)
+		a.config.PolicyEnvKeys = append([]string(nil), policy.EnvKeys...)
 		a.config.PolicyExecutionClassRequired = policy.ExecutionClassRequired
 		a.config.PolicySandboxRequired = policy.SandboxRequired
 		a.config.PolicyApprovalMode = policy.ApprovalMode
 		a.config.PolicyBreakglass = policy.Breakglass
 		a.config.PolicyMaxRuntimeSec = policy.MaxRuntimeSec
 		a.config.PolicyAllowedScopes = append([]string(nil), policy.AllowedScopes...)
+		a.config.AlertWatch = policy.AlertWatch
+		a.config.PolicyMaxConcurrentCommands = policy.MaxConcurrentCommands
+		a.config.PolicyRateLimit = policy.RateLimit
+		a.config.PolicyHeartbeatIntervalSec = policy.HeartbeatIntervalSec
+		a.config.PolicyInventoryIntervalSec = policy.InventoryIntervalSec
+		a.watcher.configure(policy.AlertWatch)
+		a.setMaxCommands(policy.MaxConcurrentCommands)
+		a.rateLimit.configure(policy.RateLimit)
+		a.applyCadence(policy.HeartbeatIntervalSec, policy.InventoryIntervalSec)
 		if err := a.config.Save(a.config.ConfigDir); err != nil {
 			a.logger.Error("failed to persist policy update", zap.Error(err))
 		}
 
+	case protocol.MsgLocalSchedule:
+		data, _ := json.Marshal(env.Payload)
+		var sched protocol.LocalSchedulePayload
+		if err := json.Unmarshal(data, &sched); err != nil {
+			a.logger.Warn("invalid local schedule payload", zap.Error(err))
+			return
+		}
+		if err := a.verifySigned(env, sched, env.ID); err != nil {
+			a.logger.Warn("local schedule rejected", zap.Error(err))
+			return
+		}
+		a.logger.Info("local schedule received",
+			zap.Int64("version", sched.Version),
+			zap.Int("entries", len(sched.Entries)),
+		)
+		a.config.LocalSchedule = nil
+		if len(sched.Entries) > 0 {
+			a.config.LocalSchedule = &sched
+		}
+		a.local.configure(a.config.LocalSchedule)
+		if err := a.config.Save(a.config.ConfigDir); err != nil {
+			a.logger.Error("failed to persist local schedule", zap.Error(err))
+		}
+		a.local.flush()
+
+	case protocol.MsgLocalScheduleAck:
+		data, _ := json.Marshal(env.Payload)
+		var ack protocol.LocalScheduleAckPayload
+		if err := json.Unmarshal(data, &ack); err != nil {
+			a.logger.Warn("invalid local schedule ack payload", zap.Error(err))
+			return
+		}
+		a.local.ack(ack.RunIDs)
+
+	case protocol.MsgPolicyQuery:
+		data, _ := json.Marshal(env.Payload)
+		var query protocol.PolicyQueryPayload
+		if err := json.Unmarshal(data, &query); err != nil {
+			a.logger.Warn("invalid policy query payload", zap.Error(err))
+			return
+		}
+		if err := a.client.Send(protocol.MsgPolicyReport, protocol.PolicyReportPayload{
+			RequestID: query.RequestID,
+			Policy:    a.effectivePolicy(),
+		}); err != nil {
+			a.logger.Warn("failed to send policy report", zap.String("request_id", query.RequestID), zap.Error(err))
+		}
+
 	case protocol.MsgUpdate:
 		data, _ := json.Marshal(env.Payload)
 		var upd protocol.UpdatePayload
@@ -206,13 +526,27 @@ func (a *Agent) handleMessage(env protocol.Envelope) {
 			a.logger.Warn("invalid update payload", zap.Error(err))
 			return
 		}
+		requestID := upd.RequestID
+		if requestID == "" {
+			requestID = env.ID
+		}
 		a.logger.Info("update command received",
+			zap.String("request_id", requestID),
 			zap.String("version", upd.Version),
 			zap.String("url", upd.URL),
 		)
+		if err := a.verifyUpdate(upd); err != nil {
+			a.logger.Warn("update rejected", zap.String("request_id", requestID), zap.Error(err))
+			_ = a.client.Send(protocol.MsgCommandResult, &protocol.CommandResultPayload{
+				RequestID: requestID,
+				ExitCode:  1,
+				Stderr:    "update rejected: " + err.Error(),
+			})
+			return
+		}
 		result := a.updater.Apply(upd.URL, upd.Checksum, upd.Version)
 		_ = a.client.Send(protocol.MsgCommandResult, &protocol.CommandResultPayload{
-			RequestID: env.ID,
+			RequestID: requestID,
 			ExitCode:  boolToExit(!result.Success),
 			Stdout:    result.Message,
 		})
@@ -243,6 +577,9 @@ func (a *Agent) handleMessage(env protocol.Envelope) {
 			return
 		}
 
+		// The live connection stays up; the new key is used from the next
+		// reconnect. The control plane accepts the old key for a short grace
+		// window in case that reconnect races this message.
 		a.client.SetAPIKey(rotation.NewKey)
 		a.logger.Info("probe API key rotated",
 			zap.Bool("expires_at_set", rotation.ExpiresAt != ""),
@@ -256,6 +593,25 @@ func (a *Agent) handleMessage(env protocol.Envelope) {
 	}
 }
 
+// verifyUpdate checks the update manifest before anything is downloaded.
+// The checksum is always required; the signature is required whenever
+// command signing is enabled on this probe.
+func (a *Agent) verifyUpdate(upd protocol.UpdatePayload) error {
+	if strings.TrimSpace(upd.Checksum) == "" {
+		return fmt.Errorf("missing checksum")
+	}
+	if a.verifier == nil {
+		return nil
+	}
+	if upd.Signature == "" {
+		return fmt.Errorf("missing signature")
+	}
+	if err := a.verifier.Verify(upd.Version, upd.Manifest(), upd.Signature); err != nil {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
 func (a *Agent) sendInventory() {
 	inv, err := inventory.Scan(a.config.ProbeID)
 	if err != nil {
@@ -277,13 +633,15 @@ func (a *Agent) sendInventory() {
 }
 
 func (a *Agent) inventoryLoop(ctx context.Context) {
-	ticker := time.NewTicker(inventoryInterval)
+	ticker := time.NewTicker(a.currentInventoryInterval())
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-a.inventoryReset:
+			ticker.Reset(a.currentInventoryInterval())
 		case <-ticker.C:
 			a.sendInventory()
 		}
@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"github.com/marcus-qen/legator/internal/shared/signing"
+	"go.uber.org/zap"
+)
+
+func TestHandleMessageCommandCancelKillsRunningCommand(t *testing.T) {
+	agent := New(&Config{
+		ServerURL:   "https://example.test",
+		ProbeID:     "probe-cancel",
+		APIKey:      "api-key",
+		ConfigDir:   t.TempDir(),
+		PolicyLevel: protocol.CapRemediate,
+	}, zap.NewNop())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		agent.runCommand(protocol.CommandPayload{
+			RequestID: "req-hang",
+			Command:   "sleep",
+			Args:      []string{"30"},
+			Timeout:   time.Minute,
+			Level:     protocol.CapObserve,
+		})
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		agent.mu.Lock()
+		_, running := agent.running["req-hang"]
+		agent.mu.Unlock()
+		if running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("command never registered as running")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	agent.handleMessage(protocol.Envelope{
+		Type:    protocol.MsgCommandCancel,
+		Payload: protocol.CommandCancelPayload{RequestID: "req-hang", Reason: "job run timed out"},
+	})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected command to be killed after cancel")
+	}
+	if agent.cancelCommand("req-hang") {
+		t.Fatal("expected canceled command to be unregistered")
+	}
+}
+
+func TestVerifyCommandRejectsReplayedEnvelope(t *testing.T) {
+	const master = "0123456789abcdef0123456789abcdef"
+	agent := New(&Config{
+		ServerURL:  "https://example.test",
+		ProbeID:    "probe-replay",
+		APIKey:     "api-key",
+		SigningKey: master,
+		ConfigDir:  t.TempDir(),
+	}, zap.NewNop())
+	masterKey, err := signing.DecodeMasterKey(master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := signing.NewSigner(signing.DeriveProbeKey(masterKey, "probe-replay"))
+
+	signed := func(id string, cmd protocol.CommandPayload) protocol.Envelope {
+		t.Helper()
+		nonce, err := signing.NewNonce()
+		if err != nil {
+			t.Fatal(err)
+		}
+		env := protocol.Envelope{ID: id, Type: protocol.MsgCommand, Timestamp: time.Now().UTC(), Nonce: nonce, Payload: cmd}
+		env.Signature, err = signer.SignEnvelope(env.ID, env.Nonce, env.Timestamp, cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return env
+	}
+
+	cmd := protocol.CommandPayload{RequestID: "req-1", Command: "systemctl", Args: []string{"restart", "nginx"}, Level: protocol.CapRemediate}
+	captured := signed("env-1", cmd)
+	if err := agent.verifyCommand(captured, cmd); err != nil {
+		t.Fatalf("expected fresh command accepted, got %v", err)
+	}
+	if err := agent.verifyCommand(captured, cmd); !errors.Is(err, signing.ErrReplayedNonce) {
+		t.Fatalf("expected replayed envelope rejected, got %v", err)
+	}
+
+	renonced := captured
+	renonced.Nonce = "forged-nonce"
+	if err := agent.verifyCommand(renonced, cmd); err == nil {
+		t.Fatal("expected re-nonced replay to fail signature verification")
+	}
+
+	if err := agent.verifyCommand(signed("env-2", cmd), cmd); err != nil {
+		t.Fatalf("expected second fresh command accepted, got %v", err)
+	}
+}
+
+func TestHandleMessageCommandBurstRespectsConcurrencyLimit(t *testing.T) {
+	agent := New(&Config{
+		ServerURL:   "https://example.test",
+		ProbeID:     "probe-burst",
+		APIKey:      "api-key",
+		ConfigDir:   t.TempDir(),
+		PolicyLevel: protocol.CapRemediate,
+	}, zap.NewNop())
+
+	agent.handleMessage(protocol.Envelope{
+		Type:    protocol.MsgPolicyUpdate,
+		Payload: protocol.PolicyUpdatePayload{PolicyID: "small-host", Level: protocol.CapRemediate, MaxConcurrentCommands: 2},
+	})
+	if got := agent.effectivePolicy().MaxConcurrentCommands; got != 2 {
+		t.Fatalf("expected pushed limit 2 in effective policy, got %d", got)
+	}
+
+	command := func(id string) protocol.Envelope {
+		return protocol.Envelope{
+			Type: protocol.MsgCommand,
+			Payload: protocol.CommandPayload{
+				RequestID: id, Command: "sleep", Args: []string{"30"}, Timeout: time.Minute, Level: protocol.CapObserve,
+			},
+		}
+	}
+	for i := 0; i < 5; i++ {
+		agent.handleMessage(command(fmt.Sprintf("req-%d", i)))
+	}
+	if got := agent.inFlightCommands(); got != 2 {
+		t.Fatalf("expected 2 commands admitted from a burst of 5, got %d", got)
+	}
+	if ok, inFlight, limit := agent.admitCommand(); ok || inFlight != 2 || limit != 2 {
+		t.Fatalf("expected probe to report busy at 2/2, got ok=%v %d/%d", ok, inFlight, limit)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !agent.cancelCommand("req-0") || !agent.cancelCommand("req-1") {
+		if time.Now().After(deadline) {
+			t.Fatal("admitted commands never registered as running")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for agent.inFlightCommands() != 0 {
+		if time.Now().After(deadline.Add(3 * time.Second)) {
+			t.Fatalf("expected slots freed after cancel, still %d in flight", agent.inFlightCommands())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	agent.handleMessage(command("req-after"))
+	if got := agent.inFlightCommands(); got != 1 {
+		t.Fatalf("expected a new command admitted once slots free up, got %d in flight", got)
+	}
+	agent.cancelCommand("req-after")
+}
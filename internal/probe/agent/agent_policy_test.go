@@ -2,6 +2,7 @@ package agent
 
 import (
 	"testing"
+	"time"
 
 	"github.com/marcus-qen/legator/internal/protocol"
 	"go.uber.org/zap"
@@ -55,3 +56,92 @@ func TestHandleMessagePolicyUpdatePersistsV2PolicyFields(t *testing.T) {
 		t.Fatalf("expected persisted allowed scopes, got %v", loaded.PolicyAllowedScopes)
 	}
 }
+
+func TestEffectivePolicyReflectsExecutorAfterUpdate(t *testing.T) {
+	cfg := &Config{
+		ServerURL:     "https://example.test",
+		ProbeID:       "probe-effective",
+		APIKey:        "api-key",
+		ConfigDir:     t.TempDir(),
+		PolicyAllowed: []string{"uptime"},
+	}
+	agent := New(cfg, zap.NewNop())
+
+	got := agent.effectivePolicy()
+	if got.Level != protocol.CapObserve || len(got.Allowed) != 1 || got.Allowed[0] != "uptime" {
+		t.Fatalf("expected default observe policy with config allowlist, got %+v", got)
+	}
+
+	agent.handleMessage(protocol.Envelope{
+		Type: protocol.MsgPolicyUpdate,
+		Payload: protocol.PolicyUpdatePayload{
+			PolicyID: "policy-drift",
+			Level:    protocol.CapRemediate,
+			Blocked:  []string{"rm -rf"},
+		},
+	})
+
+	got = agent.effectivePolicy()
+	if got.PolicyID != "policy-drift" || got.Level != protocol.CapRemediate {
+		t.Fatalf("expected updated policy id/level, got %+v", got)
+	}
+	if len(got.Allowed) != 0 || len(got.Blocked) != 1 || got.Blocked[0] != "rm -rf" {
+		t.Fatalf("expected executor allow/block lists, got %+v", got)
+	}
+}
+
+func TestPolicyUpdateAppliesReportingCadenceLive(t *testing.T) {
+	cfg := &Config{
+		ServerURL: "https://example.test",
+		ProbeID:   "probe-cadence",
+		APIKey:    "api-key",
+		ConfigDir: t.TempDir(),
+	}
+	agent := New(cfg, zap.NewNop())
+	if got := agent.client.HeartbeatInterval(); got != protocol.DefaultHeartbeatInterval {
+		t.Fatalf("expected default heartbeat interval, got %s", got)
+	}
+
+	agent.handleMessage(protocol.Envelope{
+		Type: protocol.MsgPolicyUpdate,
+		Payload: protocol.PolicyUpdatePayload{
+			PolicyID:             "policy-cadence",
+			Level:                protocol.CapObserve,
+			HeartbeatIntervalSec: 120,
+			InventoryIntervalSec: 2, // below the floor, clamped
+		},
+	})
+	if got := agent.client.HeartbeatInterval(); got != 2*time.Minute {
+		t.Fatalf("expected heartbeat interval 2m, got %s", got)
+	}
+	if got := agent.currentInventoryInterval(); got != protocol.MinInventoryInterval {
+		t.Fatalf("expected inventory interval clamped to %s, got %s", protocol.MinInventoryInterval, got)
+	}
+	select {
+	case <-agent.inventoryReset:
+	default:
+		t.Fatal("expected inventory loop to be signalled")
+	}
+	if got := agent.effectivePolicy(); got.HeartbeatIntervalSec != 120 {
+		t.Fatalf("expected effective policy to report heartbeat interval, got %d", got.HeartbeatIntervalSec)
+	}
+
+	loaded, err := LoadConfig(cfg.ConfigDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.PolicyHeartbeatIntervalSec != 120 {
+		t.Fatalf("expected persisted heartbeat interval, got %d", loaded.PolicyHeartbeatIntervalSec)
+	}
+
+	agent.handleMessage(protocol.Envelope{
+		Type:    protocol.MsgPolicyUpdate,
+		Payload: protocol.PolicyUpdatePayload{PolicyID: "policy-default", Level: protocol.CapObserve},
+	})
+	if got := agent.client.HeartbeatInterval(); got != protocol.DefaultHeartbeatInterval {
+		t.Fatalf("expected default heartbeat interval restored, got %s", got)
+	}
+	if got := agent.currentInventoryInterval(); got != protocol.DefaultInventoryInterval {
+		t.Fatalf("expected default inventory interval restored, got %s", got)
+	}
+}
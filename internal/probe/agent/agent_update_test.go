@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"github.com/marcus-qen/legator/internal/shared/signing"
+	"go.uber.org/zap"
+)
+
+const testUpdateChecksum = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func newUpdateTestAgent(t *testing.T, signingKey string) *Agent {
+	t.Helper()
+	return New(&Config{
+		ServerURL:  "https://example.test",
+		ProbeID:    "probe-update",
+		APIKey:     "api-key",
+		SigningKey: signingKey,
+		ConfigDir:  t.TempDir(),
+	}, zap.NewNop())
+}
+
+// signUpdate signs as the control plane does, from the hex-decoded master key.
+func signUpdate(t *testing.T, masterHex, probeID string, upd protocol.UpdatePayload) string {
+	t.Helper()
+	masterKey, err := signing.DecodeMasterKey(masterHex)
+	if err != nil {
+		t.Fatalf("decode master key: %v", err)
+	}
+	sig, err := signing.NewSigner(signing.DeriveProbeKey(masterKey, probeID)).Sign(upd.Version, upd.Manifest())
+	if err != nil {
+		t.Fatalf("sign manifest: %v", err)
+	}
+	return sig
+}
+
+func TestVerifyUpdateRequiresChecksum(t *testing.T) {
+	agent := newUpdateTestAgent(t, "")
+	err := agent.verifyUpdate(protocol.UpdatePayload{URL: "https://dl.example.test/probe", Version: "v2.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Fatalf("expected missing checksum error, got %v", err)
+	}
+}
+
+func TestVerifyUpdateWithoutSigningKeyAcceptsChecksum(t *testing.T) {
+	agent := newUpdateTestAgent(t, "")
+	upd := protocol.UpdatePayload{URL: "https://dl.example.test/probe", Version: "v2.0.0", Checksum: testUpdateChecksum}
+	if err := agent.verifyUpdate(upd); err != nil {
+		t.Fatalf("expected unsigned update to pass without signing key, got %v", err)
+	}
+}
+
+func TestVerifyUpdateSignature(t *testing.T) {
+	master := strings.Repeat("ab", 32)
+	agent := newUpdateTestAgent(t, master)
+
+	upd := protocol.UpdatePayload{URL: "https://dl.example.test/probe", Version: "v2.0.0", Checksum: testUpdateChecksum}
+	if err := agent.verifyUpdate(upd); err == nil || !strings.Contains(err.Error(), "missing signature") {
+		t.Fatalf("expected missing signature error, got %v", err)
+	}
+
+	upd.Signature = signUpdate(t, master, "probe-update", upd)
+	if err := agent.verifyUpdate(upd); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+
+	// Mirrors may serve the binary from a different URL.
+	mirrored := upd
+	mirrored.URL = "https://mirror.example.test/probe"
+	if err := agent.verifyUpdate(mirrored); err != nil {
+		t.Fatalf("expected signature to cover manifest only, got %v", err)
+	}
+
+	tampered := upd
+	tampered.Checksum = strings.Repeat("0", 64)
+	if err := agent.verifyUpdate(tampered); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Fatalf("expected invalid signature for tampered checksum, got %v", err)
+	}
+
+	otherProbe := upd
+	otherProbe.Signature = signUpdate(t, master, "probe-other", upd)
+	if err := agent.verifyUpdate(otherProbe); err == nil {
+		t.Fatal("expected signature for another probe to be rejected")
+	}
+}
@@ -0,0 +1,253 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAlertWatchInterval = 30 * time.Second
+	alertCheckTimeout         = 10 * time.Second
+)
+
+// alertWatcher checks local conditions from the watch config pushed with the
+// policy and sends an alert message whenever a condition starts, changes, or
+// clears. Conditions that stay the same are not re-sent.
+type alertWatcher struct {
+	send    func(protocol.AlertPayload) error
+	collect func(ctx context.Context, cfg protocol.AlertWatchConfig) []protocol.AlertPayload
+	logger  *zap.Logger
+
+	mu      sync.Mutex
+	cfg     *protocol.AlertWatchConfig
+	firing  map[string]protocol.AlertPayload // condition/subject -> last sent
+	changed chan struct{}
+	oom     oomKillTracker
+}
+
+func newAlertWatcher(send func(protocol.AlertPayload) error, logger *zap.Logger) *alertWatcher {
+	w := &alertWatcher{
+		send:    send,
+		logger:  logger,
+		firing:  make(map[string]protocol.AlertPayload),
+		changed: make(chan struct{}, 1),
+	}
+	w.collect = func(ctx context.Context, cfg protocol.AlertWatchConfig) []protocol.AlertPayload {
+		return collectLocalConditions(ctx, cfg, &w.oom)
+	}
+	return w
+}
+
+// configure swaps the watch config. Nil stops checking; conditions already
+// reported are resolved on the next pass.
+func (w *alertWatcher) configure(cfg *protocol.AlertWatchConfig) {
+	w.mu.Lock()
+	if cfg != nil {
+		c := *cfg
+		c.Units = append([]string(nil), cfg.Units...)
+		cfg = &c
+	}
+	w.cfg = cfg
+	w.mu.Unlock()
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (w *alertWatcher) config() (protocol.AlertWatchConfig, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cfg == nil {
+		return protocol.AlertWatchConfig{}, false
+	}
+	return *w.cfg, true
+}
+
+func (w *alertWatcher) run(ctx context.Context) {
+	for {
+		interval := defaultAlertWatchInterval
+		if cfg, ok := w.config(); ok && cfg.IntervalSec > 0 {
+			interval = time.Duration(cfg.IntervalSec) * time.Second
+		}
+		w.check(ctx)
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-w.changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// check runs one pass and reports transitions. A failed send leaves the
+// state untouched so the transition is retried on the next pass.
+func (w *alertWatcher) check(ctx context.Context) {
+	var current []protocol.AlertPayload
+	if cfg, ok := w.config(); ok {
+		checkCtx, cancel := context.WithTimeout(ctx, alertCheckTimeout)
+		current = w.collect(checkCtx, cfg)
+		cancel()
+	}
+
+	now := time.Now().UTC()
+	seen := make(map[string]struct{}, len(current))
+	for _, alert := range current {
+		key := alert.Condition + "/" + alert.Subject
+		seen[key] = struct{}{}
+		if prev, ok := w.firing[key]; ok && prev.Message == alert.Message && prev.Severity == alert.Severity {
+			continue
+		}
+		alert.Status = "firing"
+		alert.ObservedAt = now
+		if err := w.send(alert); err != nil {
+			w.logger.Debug("send alert failed", zap.String("condition", alert.Condition), zap.Error(err))
+			continue
+		}
+		w.firing[key] = alert
+	}
+	for key, prev := range w.firing {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		resolved := prev
+		resolved.Status = "resolved"
+		resolved.Message = fmt.Sprintf("%s cleared", strings.TrimSpace(prev.Condition+" "+prev.Subject))
+		resolved.ObservedAt = now
+		if err := w.send(resolved); err != nil {
+			w.logger.Debug("send alert resolve failed", zap.String("condition", prev.Condition), zap.Error(err))
+			continue
+		}
+		delete(w.firing, key)
+	}
+}
+
+// collectLocalConditions returns the conditions currently breaching the watch
+// config. Checks that can't run on this host are skipped.
+func collectLocalConditions(ctx context.Context, cfg protocol.AlertWatchConfig, oom *oomKillTracker) []protocol.AlertPayload {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	var out []protocol.AlertPayload
+	for _, unit := range cfg.Units {
+		if !logTailUnitPattern.MatchString(unit) {
+			continue
+		}
+		// is-failed exits 0 only when the unit is in the failed state.
+		if err := exec.CommandContext(ctx, "systemctl", "is-failed", "--quiet", unit).Run(); err == nil {
+			out = append(out, protocol.AlertPayload{
+				Condition: protocol.AlertUnitFailed,
+				Subject:   unit,
+				Severity:  "critical",
+				Message:   fmt.Sprintf("unit %s failed", unit),
+			})
+		}
+	}
+	if cfg.OOMKills {
+		if kills, ok := oomKillCount(); ok {
+			if alert, firing := oom.observe(kills); firing {
+				out = append(out, alert)
+			}
+		}
+	}
+	if cfg.DiskPercent > 0 {
+		if used, ok := rootDiskPercent(ctx); ok && used >= cfg.DiskPercent {
+			out = append(out, protocol.AlertPayload{
+				Condition: protocol.AlertDiskThreshold,
+				Subject:   "/",
+				Severity:  "warning",
+				Message:   fmt.Sprintf("root filesystem %.0f%% used (threshold %.0f%%)", used, cfg.DiskPercent),
+				Value:     used,
+			})
+		}
+	}
+	if cfg.LoadPerCPU > 0 {
+		if load, ok := loadAverage(); ok {
+			perCPU := load / float64(runtime.NumCPU())
+			if perCPU >= cfg.LoadPerCPU {
+				out = append(out, protocol.AlertPayload{
+					Condition: protocol.AlertLoadThreshold,
+					Severity:  "warning",
+					Message:   fmt.Sprintf("load %.2f per CPU (threshold %.2f)", perCPU, cfg.LoadPerCPU),
+					Value:     perCPU,
+				})
+			}
+		}
+	}
+	return out
+}
+
+// oomKillTracker turns the kernel's cumulative OOM kill counter into a
+// condition that fires for the pass in which new kills were seen.
+type oomKillTracker struct {
+	last int64
+	seen bool
+}
+
+func (t *oomKillTracker) observe(total int64) (protocol.AlertPayload, bool) {
+	prev, seen := t.last, t.seen
+	t.last, t.seen = total, true
+	if !seen || total <= prev {
+		return protocol.AlertPayload{}, false
+	}
+	return protocol.AlertPayload{
+		Condition: protocol.AlertOOMKill,
+		Severity:  "critical",
+		Message:   fmt.Sprintf("OOM killer ran %d time(s) since last check", total-prev),
+		Value:     float64(total - prev),
+	}, true
+}
+
+// oomKillCount reads the oom_kill counter from /proc/vmstat (Linux 4.13+).
+func oomKillCount() (int64, bool) {
+	data, err := os.ReadFile("/proc/vmstat")
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "oom_kill" {
+			n, err := strconv.ParseInt(fields[1], 10, 64)
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+func rootDiskPercent(ctx context.Context) (float64, bool) {
+	out, err := exec.CommandContext(ctx, "df", "--output=pcent", "/").Output()
+	if err != nil {
+		return 0, false
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	value := strings.TrimSuffix(strings.TrimSpace(lines[len(lines)-1]), "%")
+	n, err := strconv.ParseFloat(value, 64)
+	return n, err == nil
+}
+
+func loadAverage() (float64, bool) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(fields[0], 64)
+	return n, err == nil
+}
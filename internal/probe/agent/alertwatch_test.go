@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+func TestAlertWatcherSendsTransitionsOnly(t *testing.T) {
+	var sent []protocol.AlertPayload
+	sendErr := error(nil)
+	w := newAlertWatcher(func(alert protocol.AlertPayload) error {
+		if sendErr != nil {
+			return sendErr
+		}
+		sent = append(sent, alert)
+		return nil
+	}, zap.NewNop())
+
+	var current []protocol.AlertPayload
+	w.collect = func(context.Context, protocol.AlertWatchConfig) []protocol.AlertPayload {
+		return current
+	}
+
+	// Without a config nothing is collected.
+	current = []protocol.AlertPayload{{Condition: protocol.AlertUnitFailed, Subject: "nginx.service", Message: "unit nginx.service failed"}}
+	w.check(context.Background())
+	if len(sent) != 0 {
+		t.Fatalf("expected no alerts without a watch config, got %+v", sent)
+	}
+
+	w.configure(&protocol.AlertWatchConfig{Units: []string{"nginx.service"}})
+	w.check(context.Background())
+	w.check(context.Background())
+	if len(sent) != 1 || sent[0].Status != "firing" || sent[0].Subject != "nginx.service" {
+		t.Fatalf("expected one firing alert for a persisting condition, got %+v", sent)
+	}
+
+	// A failed send is retried on the next pass.
+	current = nil
+	sendErr = errors.New("disconnected")
+	w.check(context.Background())
+	sendErr = nil
+	w.check(context.Background())
+	if len(sent) != 2 || sent[1].Status != "resolved" || sent[1].Subject != "nginx.service" {
+		t.Fatalf("expected resolve after condition cleared, got %+v", sent)
+	}
+
+	w.check(context.Background())
+	if len(sent) != 2 {
+		t.Fatalf("expected no further alerts, got %+v", sent)
+	}
+}
+
+func TestOOMKillTrackerFiresOnIncrease(t *testing.T) {
+	var tracker oomKillTracker
+	if _, firing := tracker.observe(3); firing {
+		t.Fatal("first observation only sets the baseline")
+	}
+	if _, firing := tracker.observe(3); firing {
+		t.Fatal("unchanged counter must not fire")
+	}
+	alert, firing := tracker.observe(5)
+	if !firing || alert.Condition != protocol.AlertOOMKill || alert.Value != 2 {
+		t.Fatalf("expected oom_kill alert for 2 kills, got %+v (firing=%v)", alert, firing)
+	}
+}
@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// applyCadence sets the heartbeat and inventory intervals from policy
+// seconds, live. Zero restores the defaults.
+func (a *Agent) applyCadence(heartbeatSec, inventorySec int) {
+	a.client.SetHeartbeatInterval(time.Duration(heartbeatSec) * time.Second)
+	a.setInventoryInterval(time.Duration(inventorySec) * time.Second)
+}
+
+// setInventoryInterval changes the inventory refresh cadence, clamped to the
+// protocol bounds, and restarts the refresh timer if it changed.
+func (a *Agent) setInventoryInterval(d time.Duration) {
+	switch {
+	case d <= 0:
+		d = inventoryInterval
+	case d < protocol.MinInventoryInterval:
+		d = protocol.MinInventoryInterval
+	case d > protocol.MaxInventoryInterval:
+		d = protocol.MaxInventoryInterval
+	}
+	a.mu.Lock()
+	changed := a.inventoryEvery != d
+	a.inventoryEvery = d
+	a.mu.Unlock()
+	if !changed {
+		return
+	}
+	select {
+	case a.inventoryReset <- struct{}{}:
+	default:
+	}
+}
+
+// currentInventoryInterval returns the current inventory refresh cadence.
+func (a *Agent) currentInventoryInterval() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.inventoryEvery
+}
@@ -25,11 +25,28 @@ type Config struct {
 	SigningKey string     `yaml:"signing_key,omitempty"` // master signing key
 	MTLS       MTLSConfig `yaml:"mtls,omitempty"`
 
+	// MaxOutputBytes caps stdout and stderr per command; output past it is
+	// dropped and the result marked truncated. 0 uses the 1 MiB default.
+	MaxOutputBytes int `yaml:"max_output_bytes,omitempty"`
+
+	// CompressOutputBytes gzips a command result's stdout and stderr when
+	// together they reach this many bytes. 0 sends output uncompressed;
+	// the control plane must support compressed results.
+	CompressOutputBytes int `yaml:"compress_output_bytes,omitempty"`
+
+	// TokenAuth exchanges the API key for a short-lived token before each
+	// WebSocket connect instead of sending the key on the handshake.
+	TokenAuth bool `yaml:"token_auth,omitempty"`
+
 	// Last applied local policy (persisted for restart safety).
 	PolicyLevel   protocol.CapabilityLevel `yaml:"policy_level,omitempty"`
 	PolicyAllowed []string                 `yaml:"policy_allowed,omitempty"`
 	PolicyBlocked []string                 `yaml:"policy_blocked,omitempty"`
 	PolicyPaths   []string                 `yaml:"policy_paths,omitempty"`
+	// PolicyWorkDirs are the directories commands may set as work_dir.
+	PolicyWorkDirs []string `yaml:"policy_work_dirs,omitempty"`
+	// PolicyEnvKeys are the variables commands may set in env.
+	PolicyEnvKeys []string `yaml:"policy_env_keys,omitempty"`
 
 	PolicyExecutionClassRequired protocol.ExecutionClass   `yaml:"policy_execution_class_required,omitempty"`
 	PolicySandboxRequired        bool                      `yaml:"policy_sandbox_required,omitempty"`
@@ -37,6 +54,22 @@ type Config struct {
 	PolicyBreakglass             protocol.BreakglassPolicy `yaml:"policy_breakglass,omitempty"`
 	PolicyMaxRuntimeSec          int                       `yaml:"policy_max_runtime_sec,omitempty"`
 	PolicyAllowedScopes          []string                  `yaml:"policy_allowed_scopes,omitempty"`
+	// PolicyMaxConcurrentCommands caps commands run at once; 0 is unlimited.
+	PolicyMaxConcurrentCommands int `yaml:"policy_max_concurrent_commands,omitempty"`
+	// PolicyRateLimit is the command rate limit pushed with policy; nil is
+	// unlimited.
+	PolicyRateLimit *protocol.CommandRateLimit `yaml:"policy_rate_limit,omitempty"`
+	// PolicyHeartbeatIntervalSec and PolicyInventoryIntervalSec are the
+	// reporting cadence pushed with policy; 0 uses the defaults.
+	PolicyHeartbeatIntervalSec int `yaml:"policy_heartbeat_interval_sec,omitempty"`
+	PolicyInventoryIntervalSec int `yaml:"policy_inventory_interval_sec,omitempty"`
+
+	// AlertWatch is the local condition watcher config pushed with policy.
+	AlertWatch *protocol.AlertWatchConfig `yaml:"alert_watch,omitempty"`
+
+	// LocalSchedule is the probe-local cron schedule pushed by the control
+	// plane. It keeps running while the probe is offline.
+	LocalSchedule *protocol.LocalSchedulePayload `yaml:"local_schedule,omitempty"`
 
 	// WinRMTargets defines remote Windows hosts managed via WinRM (no probe binary required).
 	WinRMTargets []WinRMTargetConfig `yaml:"winrm_targets,omitempty"`
@@ -97,9 +130,16 @@ func (c *Config) Save(configDir string) error {
 		return fmt.Errorf("marshal config: %w", err)
 	}
 
+	// Write to a temp file and rename so a crash mid-write (e.g. during key
+	// rotation) never leaves a truncated config without a valid API key.
 	path := ConfigPath(configDir)
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
 		return fmt.Errorf("write config: %w", err)
 	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("replace config: %w", err)
+	}
 	return nil
 }
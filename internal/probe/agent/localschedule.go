@@ -0,0 +1,325 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcus-qen/legator/internal/protocol"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	localScheduleResultsFile = "local-schedule-results.json"
+	// maxLocalScheduleResults bounds the on-disk cache; the oldest runs are
+	// dropped first when a probe stays offline long enough to fill it.
+	maxLocalScheduleResults     = 500
+	maxLocalScheduleOutputBytes = 64 << 10
+	localScheduleBatchSize      = 50
+	// localScheduleFlushInterval is how often unacknowledged results are
+	// re-sent while connected.
+	localScheduleFlushInterval = 30 * time.Second
+)
+
+type localScheduleEntry struct {
+	entry protocol.LocalScheduleEntry
+	sched cron.Schedule
+	next  time.Time
+}
+
+// localScheduler runs the local schedule pushed by the control plane, online
+// or not, and keeps each run's result on disk until the control plane
+// acknowledges it.
+type localScheduler struct {
+	path      string
+	exec      func(ctx context.Context, entry protocol.LocalScheduleEntry, offline bool) protocol.LocalScheduleResult
+	send      func(protocol.LocalScheduleResultsPayload) error
+	connected func() bool
+	logger    *zap.Logger
+	now       func() time.Time
+
+	mu      sync.Mutex
+	version int64
+	entries []*localScheduleEntry
+	pending []protocol.LocalScheduleResult
+	changed chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newLocalScheduler(path string, logger *zap.Logger) *localScheduler {
+	s := &localScheduler{
+		path:      path,
+		connected: func() bool { return false },
+		logger:    logger,
+		now:       time.Now,
+		changed:   make(chan struct{}, 1),
+	}
+	if err := s.load(); err != nil {
+		logger.Warn("cannot load cached local schedule results", zap.String("path", path), zap.Error(err))
+	}
+	return s
+}
+
+// configure replaces the schedule. Entries whose schedule does not parse
+// are skipped; nil or empty clears it.
+func (s *localScheduler) configure(payload *protocol.LocalSchedulePayload) {
+	now := s.now()
+	var entries []*localScheduleEntry
+	var version int64
+	if payload != nil {
+		version = payload.Version
+		for _, e := range payload.Entries {
+			if len(entries) >= protocol.MaxLocalScheduleEntries {
+				break
+			}
+			sched, err := cron.ParseStandard(e.Schedule)
+			if err != nil || strings.TrimSpace(e.Command) == "" {
+				s.logger.Warn("skipping invalid local schedule entry", zap.String("id", e.ID), zap.Error(err))
+				continue
+			}
+			e.Args = append([]string(nil), e.Args...)
+			entries = append(entries, &localScheduleEntry{entry: e, sched: sched, next: sched.Next(now)})
+		}
+	}
+
+	s.mu.Lock()
+	s.version = version
+	s.entries = entries
+	s.mu.Unlock()
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (s *localScheduler) run(ctx context.Context) {
+	defer s.wg.Wait()
+	for {
+		wait := s.tick(ctx, s.now())
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// tick starts the entries due at now, re-sends unacknowledged results, and
+// returns how long to wait before the next tick.
+func (s *localScheduler) tick(ctx context.Context, now time.Time) time.Duration {
+	online := s.connected()
+	wait := localScheduleFlushInterval
+
+	s.mu.Lock()
+	var due []protocol.LocalScheduleEntry
+	for _, e := range s.entries {
+		if !now.Before(e.next) {
+			e.next = e.sched.Next(now)
+			if e.entry.OfflineOnly && online {
+				continue
+			}
+			due = append(due, e.entry)
+		}
+		if d := e.next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	s.mu.Unlock()
+
+	for _, entry := range due {
+		s.wg.Add(1)
+		go func(entry protocol.LocalScheduleEntry) {
+			defer s.wg.Done()
+			s.add(s.exec(ctx, entry, !online))
+			s.flush()
+		}(entry)
+	}
+	if len(due) == 0 {
+		s.flush()
+	}
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return wait
+}
+
+// add caches a result, dropping the oldest past the cache limit.
+func (s *localScheduler) add(result protocol.LocalScheduleResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, result)
+	if over := len(s.pending) - maxLocalScheduleResults; over > 0 {
+		s.logger.Warn("local schedule result cache full; dropping oldest results", zap.Int("dropped", over))
+		s.pending = append([]protocol.LocalScheduleResult(nil), s.pending[over:]...)
+	}
+	if err := s.saveLocked(); err != nil {
+		s.logger.Warn("cannot persist local schedule results", zap.Error(err))
+	}
+}
+
+// flush sends the oldest cached results when connected. They stay cached
+// until acknowledged, so a lost message is re-sent on a later tick.
+func (s *localScheduler) flush() {
+	if !s.connected() {
+		return
+	}
+	s.mu.Lock()
+	if len(s.pending) == 0 {
+		s.mu.Unlock()
+		return
+	}
+	batch := s.pending
+	if len(batch) > localScheduleBatchSize {
+		batch = batch[:localScheduleBatchSize]
+	}
+	payload := protocol.LocalScheduleResultsPayload{
+		ScheduleVersion: s.version,
+		Results:         append([]protocol.LocalScheduleResult(nil), batch...),
+	}
+	s.mu.Unlock()
+
+	if err := s.send(payload); err != nil {
+		s.logger.Debug("send local schedule results failed", zap.Error(err))
+	}
+}
+
+// ack drops acknowledged results and sends the next batch, if any.
+func (s *localScheduler) ack(runIDs []string) {
+	acked := make(map[string]struct{}, len(runIDs))
+	for _, id := range runIDs {
+		acked[id] = struct{}{}
+	}
+	s.mu.Lock()
+	kept := s.pending[:0]
+	for _, r := range s.pending {
+		if _, ok := acked[r.RunID]; !ok {
+			kept = append(kept, r)
+		}
+	}
+	removed := len(s.pending) - len(kept)
+	s.pending = kept
+	more := len(kept) > 0
+	if removed > 0 {
+		if err := s.saveLocked(); err != nil {
+			s.logger.Warn("cannot persist local schedule results", zap.Error(err))
+		}
+	}
+	s.mu.Unlock()
+	if removed > 0 && more {
+		s.flush()
+	}
+}
+
+func (s *localScheduler) pendingCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+func (s *localScheduler) load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var pending []protocol.LocalScheduleResult
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return fmt.Errorf("parse %s: %w", s.path, err)
+	}
+	s.pending = pending
+	return nil
+}
+
+func (s *localScheduler) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s.pending)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// runLocalEntry runs one local schedule entry under the probe's policy,
+// concurrency limit and rate limit, like a command from the control plane.
+func (a *Agent) runLocalEntry(ctx context.Context, entry protocol.LocalScheduleEntry, offline bool) protocol.LocalScheduleResult {
+	cmd := protocol.CommandPayload{
+		RequestID:      "local-" + uuid.NewString(),
+		Command:        entry.Command,
+		Args:           entry.Args,
+		Timeout:        localEntryTimeout(entry),
+		MaxOutputBytes: maxLocalScheduleOutputBytes,
+	}
+	result := protocol.LocalScheduleResult{
+		RunID:     cmd.RequestID,
+		EntryID:   entry.ID,
+		Command:   fullCommand(cmd),
+		StartedAt: time.Now().UTC(),
+		Offline:   offline,
+	}
+
+	ok, inFlight, limit := a.admitCommand()
+	if !ok {
+		result.ExitCode = -1
+		result.Stderr = fmt.Sprintf("skipped: probe busy: %d commands in flight (limit %d)", inFlight, limit)
+		return result
+	}
+	defer a.finishCommand()
+	if ok, retryAfter, reason := a.rateLimit.admit(result.Command, time.Now()); !ok {
+		result.ExitCode = -1
+		result.Stderr = fmt.Sprintf("skipped: probe rate limited: %s; retry after %s", reason, retryAfter.Round(100*time.Millisecond))
+		return result
+	}
+
+	runCtx, _, release := a.track(cmd.RequestID)
+	defer release()
+	stop := context.AfterFunc(ctx, release)
+	defer stop()
+
+	a.logger.Info("running local schedule entry",
+		zap.String("entry_id", entry.ID),
+		zap.String("run_id", cmd.RequestID),
+		zap.Bool("offline", offline),
+	)
+	res := a.executor.Execute(runCtx, &cmd)
+	result.DurationMs = res.Duration
+	result.ExitCode = res.ExitCode
+	result.Stdout = res.Stdout
+	result.Stderr = res.Stderr
+	result.Truncated = res.Truncated
+	return result
+}
+
+func localEntryTimeout(entry protocol.LocalScheduleEntry) time.Duration {
+	sec := entry.TimeoutSec
+	if sec <= 0 {
+		sec = protocol.DefaultLocalScheduleTimeoutSec
+	}
+	if sec > protocol.MaxLocalScheduleTimeoutSec {
+		sec = protocol.MaxLocalScheduleTimeoutSec
+	}
+	return time.Duration(sec) * time.Second
+}
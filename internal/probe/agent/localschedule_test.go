@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+type localScheduleHarness struct {
+	mu     sync.Mutex
+	online bool
+	ran    []string
+	sent   []protocol.LocalScheduleResultsPayload
+}
+
+func newTestLocalScheduler(t *testing.T, path string, h *localScheduleHarness) *localScheduler {
+	t.Helper()
+	s := newLocalScheduler(path, zap.NewNop())
+	s.exec = func(_ context.Context, entry protocol.LocalScheduleEntry, offline bool) protocol.LocalScheduleResult {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.ran = append(h.ran, entry.ID)
+		return protocol.LocalScheduleResult{
+			RunID:     "run-" + entry.ID + "-" + time.Now().Format(time.RFC3339Nano),
+			EntryID:   entry.ID,
+			StartedAt: time.Now().UTC(),
+			Offline:   offline,
+		}
+	}
+	s.connected = func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return h.online
+	}
+	s.send = func(p protocol.LocalScheduleResultsPayload) error {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if !h.online {
+			return errors.New("not connected")
+		}
+		h.sent = append(h.sent, p)
+		return nil
+	}
+	return s
+}
+
+func TestLocalSchedulerRunsDueEntriesAndCachesOffline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), localScheduleResultsFile)
+	h := &localScheduleHarness{}
+	s := newTestLocalScheduler(t, path, h)
+
+	start := time.Date(2026, 1, 1, 12, 0, 30, 0, time.UTC)
+	s.now = func() time.Time { return start }
+	s.configure(&protocol.LocalSchedulePayload{Version: 3, Entries: []protocol.LocalScheduleEntry{
+		{ID: "disk", Schedule: "* * * * *", Command: "df"},
+		{ID: "fallback", Schedule: "* * * * *", Command: "uptime", OfflineOnly: true},
+		{ID: "bad", Schedule: "not a schedule", Command: "true"},
+	}})
+
+	if wait := s.tick(context.Background(), start); wait != 30*time.Second {
+		t.Fatalf("wait before first run = %s, want 30s", wait)
+	}
+	s.tick(context.Background(), start.Add(30*time.Second))
+	s.wg.Wait()
+
+	if len(h.ran) != 2 {
+		t.Fatalf("expected both valid entries to run offline, ran %v", h.ran)
+	}
+	if got := s.pendingCount(); got != 2 {
+		t.Fatalf("pending = %d, want 2", got)
+	}
+	if len(h.sent) != 0 {
+		t.Fatalf("nothing should be sent while offline, sent %+v", h.sent)
+	}
+
+	// The cache survives a restart.
+	reloaded := newTestLocalScheduler(t, path, h)
+	if got := reloaded.pendingCount(); got != 2 {
+		t.Fatalf("reloaded pending = %d, want 2", got)
+	}
+
+	// Once connected, offline-only entries are skipped and cached results
+	// are replayed until acknowledged.
+	h.online = true
+	s.tick(context.Background(), start.Add(90*time.Second))
+	s.wg.Wait()
+	if len(h.ran) != 3 || h.ran[2] != "disk" {
+		t.Fatalf("expected only the disk entry to run online, ran %v", h.ran)
+	}
+	last := h.sent[len(h.sent)-1]
+	if last.ScheduleVersion != 3 || len(last.Results) != 3 {
+		t.Fatalf("expected all 3 results sent for version 3, got %+v", last)
+	}
+	if !last.Results[0].Offline || last.Results[2].Offline {
+		t.Fatalf("offline flags not preserved: %+v", last.Results)
+	}
+
+	s.ack([]string{last.Results[0].RunID, last.Results[1].RunID})
+	if got := s.pendingCount(); got != 1 {
+		t.Fatalf("pending after ack = %d, want 1", got)
+	}
+	if reloaded := newTestLocalScheduler(t, path, h); reloaded.pendingCount() != 1 {
+		t.Fatalf("ack was not persisted")
+	}
+}
+
+func TestLocalSchedulerCapsCache(t *testing.T) {
+	h := &localScheduleHarness{}
+	s := newTestLocalScheduler(t, filepath.Join(t.TempDir(), localScheduleResultsFile), h)
+	for i := 0; i < maxLocalScheduleResults+5; i++ {
+		s.add(protocol.LocalScheduleResult{RunID: time.Duration(i).String()})
+	}
+	if got := s.pendingCount(); got != maxLocalScheduleResults {
+		t.Fatalf("pending = %d, want %d", got, maxLocalScheduleResults)
+	}
+	if first := s.pending[0].RunID; first != time.Duration(5).String() {
+		t.Fatalf("oldest results should be dropped first, first = %s", first)
+	}
+
+	h.online = true
+	s.flush()
+	if len(h.sent) != 1 || len(h.sent[0].Results) != localScheduleBatchSize {
+		t.Fatalf("expected one batch of %d, got %d sends", localScheduleBatchSize, len(h.sent))
+	}
+}
+
+func TestLocalSchedulerConfigureClears(t *testing.T) {
+	h := &localScheduleHarness{}
+	s := newTestLocalScheduler(t, filepath.Join(t.TempDir(), localScheduleResultsFile), h)
+	s.configure(&protocol.LocalSchedulePayload{Version: 1, Entries: []protocol.LocalScheduleEntry{
+		{ID: "disk", Schedule: "@every 1m", Command: "df"},
+	}})
+	s.configure(nil)
+	s.tick(context.Background(), time.Now().Add(time.Hour))
+	s.wg.Wait()
+	if len(h.ran) != 0 {
+		t.Fatalf("cleared schedule still ran %v", h.ran)
+	}
+}
+
+func TestLocalEntryTimeout(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:     protocol.DefaultLocalScheduleTimeoutSec * time.Second,
+		5:     5 * time.Second,
+		99999: protocol.MaxLocalScheduleTimeoutSec * time.Second,
+	}
+	for sec, want := range cases {
+		if got := localEntryTimeout(protocol.LocalScheduleEntry{TimeoutSec: sec}); got != want {
+			t.Fatalf("timeout(%d) = %s, want %s", sec, got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultLogTailLines       = 50
+	maxLogTailLines           = 1000
+	defaultLogTailMaxDuration = 30 * time.Minute
+	maxLogTailMaxDuration     = 2 * time.Hour
+)
+
+var logTailUnitPattern = regexp.MustCompile(`^[A-Za-z0-9@._:\-]+$`)
+
+// logTailCommand turns a log tail request into the follow command it runs.
+// Units are followed with journalctl and files with tail -F; both go through
+// the executor's normal policy checks.
+func logTailCommand(tail protocol.LogTailPayload) (protocol.CommandPayload, error) {
+	if strings.TrimSpace(tail.RequestID) == "" {
+		return protocol.CommandPayload{}, fmt.Errorf("request_id is required")
+	}
+	if runtime.GOOS == "windows" {
+		return protocol.CommandPayload{}, fmt.Errorf("log tail is not supported on windows probes")
+	}
+
+	lines := tail.Lines
+	if lines <= 0 {
+		lines = defaultLogTailLines
+	}
+	if lines > maxLogTailLines {
+		lines = maxLogTailLines
+	}
+	maxDuration := tail.MaxDuration
+	if maxDuration <= 0 {
+		maxDuration = defaultLogTailMaxDuration
+	}
+	if maxDuration > maxLogTailMaxDuration {
+		maxDuration = maxLogTailMaxDuration
+	}
+
+	cmd := protocol.CommandPayload{
+		RequestID: tail.RequestID,
+		Timeout:   maxDuration,
+		Level:     protocol.CapObserve,
+		Stream:    true,
+	}
+
+	unit := strings.TrimSpace(tail.Unit)
+	path := strings.TrimSpace(tail.Path)
+	switch {
+	case unit != "":
+		if !logTailUnitPattern.MatchString(unit) {
+			return protocol.CommandPayload{}, fmt.Errorf("invalid unit name %q", unit)
+		}
+		cmd.Command = "journalctl"
+		cmd.Args = []string{"-f", "-u", unit, "-n", strconv.Itoa(lines), "--no-pager", "-o", "short-iso"}
+	case path != "":
+		if !filepath.IsAbs(path) {
+			return protocol.CommandPayload{}, fmt.Errorf("log path must be absolute")
+		}
+		cmd.Command = "tail"
+		cmd.Args = []string{"-n", strconv.Itoa(lines), "-F", filepath.Clean(path)}
+	default:
+		return protocol.CommandPayload{}, fmt.Errorf("unit or path is required")
+	}
+	return cmd, nil
+}
+
+// runLogTail follows a log until log_tail_stop, MaxDuration, or the first
+// failed send. Stopping on a failed send means a dropped connection never
+// leaves a journalctl/tail process running with nobody reading it.
+func (a *Agent) runLogTail(tail protocol.LogTailPayload) {
+	cmd, err := logTailCommand(tail)
+	if err != nil {
+		_ = a.client.Send(protocol.MsgOutputChunk, protocol.OutputChunkPayload{
+			RequestID: tail.RequestID, Stream: "stderr", Data: "log tail rejected: " + err.Error(), Final: true, ExitCode: -1,
+		})
+		return
+	}
+
+	ctx, cancel, release := a.track(cmd.RequestID)
+	defer release()
+
+	a.logger.Info("starting log tail",
+		zap.String("request_id", cmd.RequestID),
+		zap.String("command", cmd.Command),
+		zap.Strings("args", cmd.Args),
+	)
+	a.executor.ExecuteStream(ctx, &cmd, func(chunk protocol.OutputChunkPayload) {
+		if ctx.Err() != nil && !chunk.Final {
+			return
+		}
+		if err := a.client.Send(protocol.MsgOutputChunk, chunk); err != nil {
+			a.logger.Warn("log tail send failed; stopping", zap.String("request_id", cmd.RequestID), zap.Error(err))
+			cancel()
+		}
+	})
+}
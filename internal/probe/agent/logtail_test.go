@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+func TestLogTailCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("log tail is not supported on windows")
+	}
+
+	cmd, err := logTailCommand(protocol.LogTailPayload{RequestID: "tail-1", Unit: "nginx.service", Lines: 5000})
+	if err != nil {
+		t.Fatalf("unit tail: %v", err)
+	}
+	if cmd.Command != "journalctl" || !cmd.Stream || cmd.Level != protocol.CapObserve {
+		t.Fatalf("unexpected unit command %+v", cmd)
+	}
+	if got := strings.Join(cmd.Args, " "); got != "-f -u nginx.service -n 1000 --no-pager -o short-iso" {
+		t.Fatalf("unexpected journalctl args %q", got)
+	}
+	if cmd.Timeout != defaultLogTailMaxDuration {
+		t.Fatalf("expected default max duration, got %s", cmd.Timeout)
+	}
+
+	cmd, err = logTailCommand(protocol.LogTailPayload{RequestID: "tail-2", Path: "/var/log/../log/syslog", MaxDuration: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("path tail: %v", err)
+	}
+	if cmd.Command != "tail" || strings.Join(cmd.Args, " ") != "-n 50 -F /var/log/syslog" {
+		t.Fatalf("unexpected path command %+v", cmd)
+	}
+	if cmd.Timeout != maxLogTailMaxDuration {
+		t.Fatalf("expected max duration clamp, got %s", cmd.Timeout)
+	}
+
+	for _, bad := range []protocol.LogTailPayload{
+		{Unit: "nginx"},
+		{RequestID: "r"},
+		{RequestID: "r", Unit: "nginx; rm -rf /"},
+		{RequestID: "r", Path: "relative.log"},
+	} {
+		if _, err := logTailCommand(bad); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestRunLogTailStopsWhenSendFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("log tail is not supported on windows")
+	}
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(logPath, []byte("line one\nline two\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The client never connects, so the first chunk send fails and the tail
+	// must be killed rather than left running.
+	agent := New(&Config{
+		ServerURL: "https://example.test",
+		ProbeID:   "probe-tail",
+		APIKey:    "api-key",
+		ConfigDir: t.TempDir(),
+	}, zap.NewNop())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		agent.runLogTail(protocol.LogTailPayload{RequestID: "tail-orphan", Path: logPath, MaxDuration: time.Minute})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected log tail to stop after a failed send")
+	}
+	if agent.cancelCommand("tail-orphan") {
+		t.Fatal("expected stopped tail to be unregistered")
+	}
+}
+
+func TestHandleMessageLogTailStopCancelsTail(t *testing.T) {
+	agent := New(&Config{
+		ServerURL: "https://example.test",
+		ProbeID:   "probe-tail-stop",
+		APIKey:    "api-key",
+		ConfigDir: t.TempDir(),
+	}, zap.NewNop())
+
+	ctx, _, release := agent.track("tail-live")
+	defer release()
+
+	agent.handleMessage(protocol.Envelope{
+		Type:    protocol.MsgLogTailStop,
+		Payload: protocol.LogTailStopPayload{RequestID: "tail-live", Reason: "subscriber disconnected"},
+	})
+
+	if ctx.Err() == nil {
+		t.Fatal("expected log_tail_stop to cancel the running tail")
+	}
+}
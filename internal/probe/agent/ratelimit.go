@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+const rateLimitWindow = time.Minute
+
+// commandRateLimiter enforces the policy rate limit: a sliding per-minute
+// cap on admitted commands plus per-prefix cooldowns.
+type commandRateLimiter struct {
+	mu       sync.Mutex
+	limit    protocol.CommandRateLimit
+	admitted []time.Time          // admissions inside the last window, oldest first
+	lastRun  map[string]time.Time // lowercased cooldown prefix -> last admission
+}
+
+// configure replaces the limits. History is kept, so re-pushing the same
+// policy does not reset a cooldown in progress.
+func (l *commandRateLimiter) configure(limit *protocol.CommandRateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = protocol.CommandRateLimit{}
+	if limit != nil {
+		l.limit.PerMinute = limit.PerMinute
+		l.limit.Cooldowns = append([]protocol.CommandCooldown(nil), limit.Cooldowns...)
+	}
+}
+
+// admit records command as run at now, or reports how long until it would
+// be accepted and why.
+func (l *commandRateLimiter) admit(command string, now time.Time) (ok bool, retryAfter time.Duration, reason string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-rateLimitWindow)
+	drop := 0
+	for drop < len(l.admitted) && !l.admitted[drop].After(cutoff) {
+		drop++
+	}
+	l.admitted = l.admitted[drop:]
+
+	if l.limit.PerMinute > 0 && len(l.admitted) >= l.limit.PerMinute {
+		retryAfter = l.admitted[len(l.admitted)-l.limit.PerMinute].Add(rateLimitWindow).Sub(now)
+		reason = fmt.Sprintf("%d commands in the last minute (limit %d)", len(l.admitted), l.limit.PerMinute)
+	}
+
+	lower := strings.ToLower(command)
+	var matched []string
+	for _, cd := range l.limit.Cooldowns {
+		prefix := strings.ToLower(strings.TrimSpace(cd.Prefix))
+		if prefix == "" || cd.Seconds <= 0 || !strings.HasPrefix(lower, prefix) {
+			continue
+		}
+		matched = append(matched, prefix)
+		last, seen := l.lastRun[prefix]
+		if !seen {
+			continue
+		}
+		if wait := last.Add(time.Duration(cd.Seconds) * time.Second).Sub(now); wait > retryAfter {
+			retryAfter = wait
+			reason = fmt.Sprintf("%q is limited to one run every %ds", cd.Prefix, cd.Seconds)
+		}
+	}
+	if retryAfter > 0 {
+		return false, retryAfter, reason
+	}
+
+	l.admitted = append(l.admitted, now)
+	if len(matched) > 0 && l.lastRun == nil {
+		l.lastRun = make(map[string]time.Time)
+	}
+	for _, prefix := range matched {
+		l.lastRun[prefix] = now
+	}
+	return true, 0, ""
+}
+
+// rejectRateLimited answers a command refused under the rate limit with a
+// retry hint. Streamed commands get a final stderr chunk; others a
+// rate_limited command result.
+func (a *Agent) rejectRateLimited(cmd protocol.CommandPayload, retryAfter time.Duration, reason string) {
+	retryAfter = retryAfter.Round(time.Millisecond)
+	if retryAfter < time.Millisecond {
+		retryAfter = time.Millisecond
+	}
+	msg := fmt.Sprintf("probe rate limited: %s; retry after %s", reason, retryAfter.Round(100*time.Millisecond))
+	a.logger.Warn("command rejected", zap.String("request_id", cmd.RequestID), zap.String("reason", msg))
+	if cmd.Stream {
+		_ = a.client.Send(protocol.MsgOutputChunk, protocol.OutputChunkPayload{
+			RequestID: cmd.RequestID, Stream: "stderr", Data: msg, Final: true, ExitCode: -1,
+			RateLimited: true, RetryAfterMs: retryAfter.Milliseconds(),
+		})
+		return
+	}
+	_ = a.client.Send(protocol.MsgCommandResult, &protocol.CommandResultPayload{
+		RequestID: cmd.RequestID, ExitCode: -1, Stderr: msg,
+		RateLimited: true, RetryAfterMs: retryAfter.Milliseconds(),
+	})
+}
+
+// fullCommand joins a command and its arguments as policy prefixes see it.
+func fullCommand(cmd protocol.CommandPayload) string {
+	if len(cmd.Args) == 0 {
+		return cmd.Command
+	}
+	return cmd.Command + " " + strings.Join(cmd.Args, " ")
+}
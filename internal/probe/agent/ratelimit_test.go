@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"go.uber.org/zap"
+)
+
+func TestCommandRateLimiterPerMinuteBurst(t *testing.T) {
+	var l commandRateLimiter
+	l.configure(&protocol.CommandRateLimit{PerMinute: 3})
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	admitted := 0
+	var lastRetry time.Duration
+	for i := 0; i < 10; i++ {
+		ok, retry, reason := l.admit("uptime", start.Add(time.Duration(i)*time.Second))
+		if ok {
+			admitted++
+			continue
+		}
+		if reason == "" || retry <= 0 {
+			t.Fatalf("expected reason and retry hint, got %q %s", reason, retry)
+		}
+		lastRetry = retry
+	}
+	if admitted != 3 {
+		t.Fatalf("expected 3 of a 10 command burst admitted, got %d", admitted)
+	}
+	// The burst ends at start+9s; the first admission expires at start+60s.
+	if lastRetry != 51*time.Second {
+		t.Fatalf("expected retry after 51s, got %s", lastRetry)
+	}
+	if ok, _, _ := l.admit("uptime", start.Add(60*time.Second+time.Millisecond)); !ok {
+		t.Fatal("expected a command admitted once the oldest left the window")
+	}
+}
+
+func TestCommandRateLimiterCooldownPerPrefix(t *testing.T) {
+	var l commandRateLimiter
+	l.configure(&protocol.CommandRateLimit{Cooldowns: []protocol.CommandCooldown{{Prefix: "systemctl restart", Seconds: 30}}})
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if ok, _, _ := l.admit("systemctl restart nginx", start); !ok {
+		t.Fatal("expected first restart admitted")
+	}
+	ok, retry, _ := l.admit("SYSTEMCTL restart postgres", start.Add(10*time.Second))
+	if ok || retry != 20*time.Second {
+		t.Fatalf("expected second restart in cooldown for 20s, got ok=%v retry=%s", ok, retry)
+	}
+	if ok, _, _ := l.admit("systemctl status nginx", start.Add(10*time.Second)); !ok {
+		t.Fatal("expected unrelated command unaffected by the cooldown")
+	}
+	if ok, _, _ := l.admit("systemctl restart nginx", start.Add(30*time.Second)); !ok {
+		t.Fatal("expected restart admitted after the cooldown")
+	}
+
+	l.configure(nil)
+	if ok, _, _ := l.admit("systemctl restart nginx", start.Add(31*time.Second)); !ok {
+		t.Fatal("expected clearing the policy to lift the cooldown")
+	}
+}
+
+func TestHandleMessageCommandBurstIsRateLimited(t *testing.T) {
+	agent := New(&Config{
+		ServerURL:   "https://example.test",
+		ProbeID:     "probe-rate",
+		APIKey:      "api-key",
+		ConfigDir:   t.TempDir(),
+		PolicyLevel: protocol.CapRemediate,
+	}, zap.NewNop())
+
+	limit := &protocol.CommandRateLimit{PerMinute: 2}
+	agent.handleMessage(protocol.Envelope{
+		Type:    protocol.MsgPolicyUpdate,
+		Payload: protocol.PolicyUpdatePayload{PolicyID: "throttled", Level: protocol.CapRemediate, RateLimit: limit},
+	})
+	if got := agent.effectivePolicy().RateLimit; got == nil || got.PerMinute != 2 {
+		t.Fatalf("expected pushed rate limit in effective policy, got %+v", got)
+	}
+
+	for i := 0; i < 6; i++ {
+		agent.handleMessage(protocol.Envelope{
+			Type: protocol.MsgCommand,
+			Payload: protocol.CommandPayload{
+				RequestID: fmt.Sprintf("req-%d", i), Command: "sleep", Args: []string{"30"}, Timeout: time.Minute, Level: protocol.CapObserve,
+			},
+		})
+	}
+	if got := agent.inFlightCommands(); got != 2 {
+		t.Fatalf("expected 2 commands admitted from a burst of 6, got %d", got)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !agent.cancelCommand("req-0") || !agent.cancelCommand("req-1") {
+		if time.Now().After(deadline) {
+			t.Fatal("admitted commands never registered as running")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
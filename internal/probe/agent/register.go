@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/marcus-qen/legator/internal/probe/connection"
 	"go.uber.org/zap"
 )
 
@@ -21,6 +22,8 @@ type registerRequest struct {
 	Arch     string   `json:"arch"`
 	Version  string   `json:"version"`
 	Tags     []string `json:"tags,omitempty"`
+
+	HeartbeatIntervalSec int `json:"heartbeat_interval_sec,omitempty"`
 }
 
 type registerResponse struct {
@@ -52,8 +55,10 @@ func RegisterWithOptions(ctx context.Context, serverURL, token string, logger *z
 		Hostname: hostname,
 		OS:       runtime.GOOS,
 		Arch:     runtime.GOARCH,
-		Version:  "dev",
+		Version:  Version,
 		Tags:     normalizeTags(opts.Tags),
+
+		HeartbeatIntervalSec: int(connection.HeartbeatInterval / time.Second),
 	}
 
 	body, err := json.Marshal(req)
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// resourceSampler fills heartbeats with host load, memory, disk and CPU
+// readings. CPU utilisation is the busy share of /proc/stat time since the
+// previous heartbeat, so the first heartbeat after start reports none.
+type resourceSampler struct {
+	mu        sync.Mutex
+	prevBusy  uint64
+	prevTotal uint64
+}
+
+func (r *resourceSampler) fill(hb *protocol.HeartbeatPayload) {
+	if load, ok := readLoadAverages("/proc/loadavg"); ok {
+		hb.Load = load
+	}
+	if used, total, ok := readMemUsage("/proc/meminfo"); ok {
+		hb.MemUsed, hb.MemTotal = used, total
+	}
+	if used, total, ok := diskUsage("/"); ok {
+		hb.DiskUsed, hb.DiskTotal = used, total
+	}
+	if busy, total, ok := readCPUTimes("/proc/stat"); ok {
+		hb.CPUPct = r.cpuPercent(busy, total)
+	}
+}
+
+// cpuPercent returns utilisation between the previous and current counters.
+func (r *resourceSampler) cpuPercent(busy, total uint64) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prevBusy, prevTotal := r.prevBusy, r.prevTotal
+	r.prevBusy, r.prevTotal = busy, total
+	if prevTotal == 0 || total <= prevTotal || busy < prevBusy {
+		return 0
+	}
+	return float64(busy-prevBusy) / float64(total-prevTotal) * 100
+}
+
+func readLoadAverages(path string) ([3]float64, bool) {
+	var load [3]float64
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return load, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return load, false
+	}
+	for i := range load {
+		n, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return load, false
+		}
+		load[i] = n
+	}
+	return load, true
+}
+
+// readMemUsage returns used and total memory in bytes, counting reclaimable
+// cache as free (MemTotal - MemAvailable).
+func readMemUsage(path string) (used, total uint64, ok bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	defer f.Close()
+
+	var available uint64
+	var haveAvailable bool
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = kb * 1024
+		case "MemAvailable:":
+			available, haveAvailable = kb*1024, true
+		}
+	}
+	if total == 0 || !haveAvailable || available > total {
+		return 0, 0, false
+	}
+	return total - available, total, true
+}
+
+// readCPUTimes returns the busy and total jiffies from the aggregate cpu
+// line of /proc/stat. Idle and iowait count as not busy.
+func readCPUTimes(path string) (busy, total uint64, ok bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		var idle uint64
+		// Guest time is already included in user and nice, so stop at steal.
+		for i, field := range fields[1:min(len(fields), 9)] {
+			n, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, false
+			}
+			total += n
+			if i == 3 || i == 4 {
+				idle += n
+			}
+		}
+		return total - idle, total, true
+	}
+	return 0, 0, false
+}
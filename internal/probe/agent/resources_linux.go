@@ -0,0 +1,16 @@
+package agent
+
+import "syscall"
+
+func diskUsage(path string) (used, total uint64, ok bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, false
+	}
+	bsize := uint64(st.Bsize)
+	total = st.Blocks * bsize
+	if total == 0 {
+		return 0, 0, false
+	}
+	return total - st.Bfree*bsize, total, true
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package agent
+
+// diskUsage is only collected on Linux; other platforms report disk
+// totals through inventory instead.
+func diskUsage(string) (used, total uint64, ok bool) { return 0, 0, false }
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeProcFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "proc")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestReadResourceProcFiles(t *testing.T) {
+	load, ok := readLoadAverages(writeProcFile(t, "0.52 0.40 0.31 2/812 12345\n"))
+	if !ok || load != [3]float64{0.52, 0.40, 0.31} {
+		t.Fatalf("unexpected load %v ok=%v", load, ok)
+	}
+
+	used, total, ok := readMemUsage(writeProcFile(t, "MemTotal:       2048 kB\nMemFree:         100 kB\nMemAvailable:    512 kB\n"))
+	if !ok || total != 2048*1024 || used != 1536*1024 {
+		t.Fatalf("unexpected memory used=%d total=%d ok=%v", used, total, ok)
+	}
+	if _, _, ok := readMemUsage(writeProcFile(t, "MemTotal: 2048 kB\n")); ok {
+		t.Fatal("expected memory unreadable without MemAvailable")
+	}
+
+	busy, total, ok := readCPUTimes(writeProcFile(t, "cpu  100 0 50 800 50 0 0 0 10 0\ncpu0 100 0 50 800 50 0 0 0 10 0\n"))
+	if !ok || total != 1000 || busy != 150 {
+		t.Fatalf("unexpected cpu busy=%d total=%d ok=%v", busy, total, ok)
+	}
+}
+
+func TestResourceSamplerCPUPercent(t *testing.T) {
+	var r resourceSampler
+	if pct := r.cpuPercent(150, 1000); pct != 0 {
+		t.Fatalf("expected no reading on first sample, got %v", pct)
+	}
+	if pct := r.cpuPercent(250, 1200); pct != 50 {
+		t.Fatalf("expected 50%% busy, got %v", pct)
+	}
+	// Counters going backwards (e.g. a container restart) report nothing.
+	if pct := r.cpuPercent(10, 100); pct != 0 {
+		t.Fatalf("expected no reading after counter reset, got %v", pct)
+	}
+}
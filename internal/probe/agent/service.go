@@ -11,10 +11,180 @@ import (
 )
 
 const (
-	serviceName = "probe-agent"
-	unitPath    = "/etc/systemd/system/probe-agent.service"
+	serviceName    = "probe-agent"
+	unitPath       = "/etc/systemd/system/probe-agent.service"
+	initScriptPath = "/etc/init.d/probe-agent"
 )
 
+// serviceBackend installs and controls the probe service under one init
+// system.
+type serviceBackend interface {
+	install(probeBin, configDir string) error
+	start() error
+	stop() error
+	remove() error
+	status() error
+	// describe returns the lines printed after a successful install.
+	describe() []string
+}
+
+func backendFor(init InitSystem) (serviceBackend, error) {
+	switch init {
+	case InitSystemd:
+		return systemdBackend{}, nil
+	case InitOpenRC:
+		return openrcBackend{}, nil
+	case InitSysV:
+		return sysvBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported init system %q", init)
+	}
+}
+
+// DetectInitSystem reports the init system managing this host.
+func DetectInitSystem() (InitSystem, error) {
+	return detectInitSystem("/")
+}
+
+// detectInitSystem inspects the filesystem under root: a booted systemd
+// creates /run/systemd/system, OpenRC ships openrc-run, and anything else
+// with /etc/init.d gets the SysV fallback.
+func detectInitSystem(root string) (InitSystem, error) {
+	exists := func(path string) bool {
+		_, err := os.Stat(filepath.Join(root, path))
+		return err == nil
+	}
+	switch {
+	case exists("/run/systemd/system"):
+		return InitSystemd, nil
+	case exists("/run/openrc"), exists("/sbin/openrc-run"), exists("/usr/sbin/openrc-run"):
+		return InitOpenRC, nil
+	case exists("/etc/init.d"):
+		return InitSysV, nil
+	default:
+		return "", fmt.Errorf("no supported init system detected; pass --init-system systemd|openrc|sysv")
+	}
+}
+
+// installedInitSystem reports which init system the probe service was
+// installed into, from the files left behind, so remove and status find it
+// even if detection would now pick another.
+func installedInitSystem(root string) (InitSystem, bool) {
+	if _, err := os.Stat(filepath.Join(root, unitPath)); err == nil {
+		return InitSystemd, true
+	}
+	script, err := os.ReadFile(filepath.Join(root, initScriptPath))
+	if err != nil {
+		return "", false
+	}
+	if strings.HasPrefix(string(script), "#!/sbin/openrc-run") {
+		return InitOpenRC, true
+	}
+	return InitSysV, true
+}
+
+// resolveBackend picks the backend for init. Auto prefers an existing
+// installation, then detection.
+func resolveBackend(init InitSystem) (serviceBackend, error) {
+	if init == "" || init == InitAuto {
+		if installed, ok := installedInitSystem("/"); ok {
+			init = installed
+		} else {
+			detected, err := DetectInitSystem()
+			if err != nil {
+				return nil, err
+			}
+			init = detected
+		}
+	}
+	return backendFor(init)
+}
+
+// ServiceInstall writes the service definition for the init system, enables
+// it at boot and starts it.
+func ServiceInstall(configDir string, init InitSystem) error {
+	// Find the probe binary
+	probeBin, err := os.Executable()
+	if err != nil {
+		probeBin = "/usr/local/bin/probe"
+	}
+	probeBin, _ = filepath.Abs(probeBin)
+
+	if configDir == "" {
+		configDir = DefaultConfigDir
+	}
+
+	// Verify config exists
+	if _, err := os.Stat(ConfigPath(configDir)); os.IsNotExist(err) {
+		return fmt.Errorf("config not found at %s — run 'probe init' first", ConfigPath(configDir))
+	}
+
+	if init == "" || init == InitAuto {
+		if init, err = DetectInitSystem(); err != nil {
+			return err
+		}
+	}
+	backend, err := backendFor(init)
+	if err != nil {
+		return err
+	}
+	if err := backend.install(probeBin, configDir); err != nil {
+		return err
+	}
+	if err := backend.start(); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Service %s installed and started (%s)\n", serviceName, init)
+	for _, line := range backend.describe() {
+		fmt.Printf("   %s\n", line)
+	}
+	return nil
+}
+
+func ServiceStart(init InitSystem) error {
+	backend, err := resolveBackend(init)
+	if err != nil {
+		return err
+	}
+	return backend.start()
+}
+
+func ServiceStop(init InitSystem) error {
+	backend, err := resolveBackend(init)
+	if err != nil {
+		return err
+	}
+	return backend.stop()
+}
+
+// ServiceRemove stops and removes the service.
+func ServiceRemove(init InitSystem) error {
+	backend, err := resolveBackend(init)
+	if err != nil {
+		return err
+	}
+	_ = backend.stop()
+	if err := backend.remove(); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Service %s removed\n", serviceName)
+	return nil
+}
+
+// ServiceStatus shows the service status.
+func ServiceStatus(init InitSystem) error {
+	backend, err := resolveBackend(init)
+	if err != nil {
+		return err
+	}
+	return backend.status()
+}
+
+// systemdBackend manages a systemd unit.
+type systemdBackend struct{}
+
 // unitTemplate generates the systemd unit file content.
 func unitTemplate(probeBin, configDir string) string {
 	return fmt.Sprintf(`[Unit]
@@ -48,100 +218,84 @@ WantedBy=multi-user.target
 `, probeBin, configDir)
 }
 
-// ServiceInstall creates and enables the systemd service.
-func ServiceInstall(configDir string) error {
-	// Find the probe binary
-	probeBin, err := os.Executable()
-	if err != nil {
-		probeBin = "/usr/local/bin/probe"
-	}
-	probeBin, _ = filepath.Abs(probeBin)
-
-	if configDir == "" {
-		configDir = DefaultConfigDir
-	}
-
-	// Verify config exists
-	if _, err := os.Stat(ConfigPath(configDir)); os.IsNotExist(err) {
-		return fmt.Errorf("config not found at %s — run 'probe init' first", ConfigPath(configDir))
-	}
-
-	// Write unit file
+func (systemdBackend) install(probeBin, configDir string) error {
 	unit := unitTemplate(probeBin, configDir)
 	if err := os.WriteFile(unitPath, []byte(unit), 0644); err != nil {
 		return fmt.Errorf("write unit file: %w", err)
 	}
-
-	// Reload systemd
 	if err := systemctl("daemon-reload"); err != nil {
 		return fmt.Errorf("daemon-reload: %w", err)
 	}
-
-	// Enable and start
 	if err := systemctl("enable", serviceName); err != nil {
 		return fmt.Errorf("enable: %w", err)
 	}
-
-	if err := ServiceStart(); err != nil {
-		return err
-	}
-
-	fmt.Printf("✅ Service %s installed and started\n", serviceName)
-	fmt.Printf("   Unit: %s\n", unitPath)
-	fmt.Printf("   Status: systemctl status %s\n", serviceName)
-	fmt.Printf("   Logs: journalctl -u %s -f\n", serviceName)
 	return nil
 }
 
-func ServiceStart() error {
+func (systemdBackend) start() error {
 	if err := systemctl("start", serviceName); err != nil {
 		return fmt.Errorf("start: %w", err)
 	}
 	return nil
 }
 
-func ServiceStop() error {
+func (systemdBackend) stop() error {
 	if err := systemctl("stop", serviceName); err != nil {
 		return fmt.Errorf("stop: %w", err)
 	}
 	return nil
 }
 
-// ServiceRemove stops and removes the systemd service.
-func ServiceRemove() error {
-	_ = ServiceStop()
+func (systemdBackend) remove() error {
 	_ = systemctl("disable", serviceName)
-
 	if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("remove unit file: %w", err)
 	}
-
 	_ = systemctl("daemon-reload")
-
-	fmt.Printf("✅ Service %s removed\n", serviceName)
 	return nil
 }
 
-// ServiceStatus shows the systemd service status.
-func ServiceStatus() error {
-	cmd := exec.Command("systemctl", "status", serviceName, "--no-pager")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err := cmd.Run()
-	if err != nil {
-		// systemctl status returns non-zero for inactive services
-		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 3 {
-			return nil // inactive is valid, not an error
-		}
+func (systemdBackend) status() error {
+	// systemctl status exits 3 for inactive services
+	return runStatus(exec.Command("systemctl", "status", serviceName, "--no-pager"))
+}
+
+func (systemdBackend) describe() []string {
+	return []string{
+		"Unit: " + unitPath,
+		"Status: systemctl status " + serviceName,
+		"Logs: journalctl -u " + serviceName + " -f",
 	}
-	return err
 }
 
 func systemctl(args ...string) error {
-	cmd := exec.Command("systemctl", args...)
+	return runQuiet("systemctl", args...)
+}
+
+// runQuiet runs a service manager command, folding its output into the
+// error on failure.
+func runQuiet(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("%s: %s", strings.Join(args, " "), strings.TrimSpace(string(output)))
+		return fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), strings.TrimSpace(string(output)))
 	}
 	return nil
 }
+
+// runStatus runs a status command against the terminal. Exit code 3 is the
+// LSB "not running" status, which is a valid answer rather than an error.
+func runStatus(cmd *exec.Cmd) error {
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 3 {
+		return nil
+	}
+	return err
+}
+
+// shellQuote single-quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
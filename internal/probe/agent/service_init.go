@@ -0,0 +1,29 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InitSystem names the service manager `probe service` installs into.
+type InitSystem string
+
+const (
+	// InitAuto detects the init system of the running host.
+	InitAuto    InitSystem = "auto"
+	InitSystemd InitSystem = "systemd"
+	InitOpenRC  InitSystem = "openrc"
+	InitSysV    InitSystem = "sysv"
+)
+
+// ParseInitSystem validates an --init-system value. Empty means auto.
+func ParseInitSystem(raw string) (InitSystem, error) {
+	switch s := InitSystem(strings.ToLower(strings.TrimSpace(raw))); s {
+	case "", InitAuto:
+		return InitAuto, nil
+	case InitSystemd, InitOpenRC, InitSysV:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown init system %q (want auto, systemd, openrc or sysv)", raw)
+	}
+}
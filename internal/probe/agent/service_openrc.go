@@ -0,0 +1,84 @@
+//go:build !windows
+
+package agent
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// openrcBackend manages an OpenRC service, as used on Alpine.
+type openrcBackend struct{}
+
+// openrcScriptTemplate generates the /etc/init.d script. supervise-daemon
+// restarts the probe if it exits, like Restart=always under systemd.
+func openrcScriptTemplate(probeBin, configDir string) string {
+	return fmt.Sprintf(`#!/sbin/openrc-run
+
+name="Legator Probe Agent"
+description="Legator Probe Agent"
+supervisor=supervise-daemon
+command=%s
+command_args="run --config-dir %s"
+pidfile="/run/${RC_SVCNAME}.pid"
+respawn_delay=5
+respawn_max=0
+output_log="/var/log/legator/probe-agent.log"
+error_log="/var/log/legator/probe-agent.log"
+
+depend() {
+	need net
+	after firewall
+}
+
+start_pre() {
+	checkpath --directory /var/lib/legator /var/log/legator
+}
+`, shellQuote(probeBin), shellQuote(configDir))
+}
+
+func (openrcBackend) install(probeBin, configDir string) error {
+	script := openrcScriptTemplate(probeBin, configDir)
+	if err := os.WriteFile(initScriptPath, []byte(script), 0755); err != nil {
+		return fmt.Errorf("write init script: %w", err)
+	}
+	if err := runQuiet("rc-update", "add", serviceName, "default"); err != nil {
+		return fmt.Errorf("enable: %w", err)
+	}
+	return nil
+}
+
+func (openrcBackend) start() error {
+	if err := runQuiet("rc-service", serviceName, "start"); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	return nil
+}
+
+func (openrcBackend) stop() error {
+	if err := runQuiet("rc-service", serviceName, "stop"); err != nil {
+		return fmt.Errorf("stop: %w", err)
+	}
+	return nil
+}
+
+func (openrcBackend) remove() error {
+	_ = runQuiet("rc-update", "del", serviceName, "default")
+	if err := os.Remove(initScriptPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove init script: %w", err)
+	}
+	return nil
+}
+
+func (openrcBackend) status() error {
+	return runStatus(exec.Command("rc-service", serviceName, "status"))
+}
+
+func (openrcBackend) describe() []string {
+	return []string{
+		"Script: " + initScriptPath,
+		"Status: rc-service " + serviceName + " status",
+		"Logs: /var/log/legator/probe-agent.log",
+	}
+}
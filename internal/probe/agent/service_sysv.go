@@ -0,0 +1,135 @@
+//go:build !windows
+
+package agent
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// sysvBackend manages a plain LSB init script, the fallback for hosts with
+// neither systemd nor OpenRC. The script backgrounds the probe and tracks it
+// with a pid file; nothing restarts it if it exits.
+type sysvBackend struct{}
+
+// sysvScriptTemplate generates the /etc/init.d script.
+func sysvScriptTemplate(probeBin, configDir string) string {
+	return fmt.Sprintf(`#!/bin/sh
+### BEGIN INIT INFO
+# Provides:          probe-agent
+# Required-Start:    $network $remote_fs
+# Required-Stop:     $network $remote_fs
+# Default-Start:     2 3 4 5
+# Default-Stop:      0 1 6
+# Short-Description: Legator Probe Agent
+### END INIT INFO
+
+DAEMON=%s
+CONFIG_DIR=%s
+PIDFILE=/var/run/probe-agent.pid
+LOGFILE=/var/log/legator/probe-agent.log
+
+is_running() {
+	[ -f "$PIDFILE" ] && kill -0 "$(cat "$PIDFILE")" 2>/dev/null
+}
+
+case "$1" in
+	start)
+		if is_running; then
+			echo "probe-agent is already running"
+			exit 0
+		fi
+		mkdir -p /var/lib/legator /var/log/legator
+		nohup "$DAEMON" run --config-dir "$CONFIG_DIR" >>"$LOGFILE" 2>&1 &
+		echo $! >"$PIDFILE"
+		echo "probe-agent started"
+		;;
+	stop)
+		if is_running; then
+			kill "$(cat "$PIDFILE")"
+		fi
+		rm -f "$PIDFILE"
+		echo "probe-agent stopped"
+		;;
+	restart)
+		"$0" stop
+		sleep 1
+		"$0" start
+		;;
+	status)
+		if is_running; then
+			echo "probe-agent is running (pid $(cat "$PIDFILE"))"
+			exit 0
+		fi
+		echo "probe-agent is not running"
+		exit 3
+		;;
+	*)
+		echo "Usage: $0 {start|stop|restart|status}"
+		exit 2
+		;;
+esac
+`, shellQuote(probeBin), shellQuote(configDir))
+}
+
+func (sysvBackend) install(probeBin, configDir string) error {
+	script := sysvScriptTemplate(probeBin, configDir)
+	if err := os.WriteFile(initScriptPath, []byte(script), 0755); err != nil {
+		return fmt.Errorf("write init script: %w", err)
+	}
+	if err := sysvEnable(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s will not start at boot: %v\n", serviceName, err)
+	}
+	return nil
+}
+
+// sysvEnable registers the script for boot with whichever tool the
+// distribution provides.
+func sysvEnable() error {
+	if _, err := exec.LookPath("update-rc.d"); err == nil {
+		return runQuiet("update-rc.d", serviceName, "defaults")
+	}
+	if _, err := exec.LookPath("chkconfig"); err == nil {
+		return runQuiet("chkconfig", "--add", serviceName)
+	}
+	return fmt.Errorf("neither update-rc.d nor chkconfig found")
+}
+
+func (sysvBackend) start() error {
+	if err := runQuiet(initScriptPath, "start"); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	return nil
+}
+
+func (sysvBackend) stop() error {
+	if err := runQuiet(initScriptPath, "stop"); err != nil {
+		return fmt.Errorf("stop: %w", err)
+	}
+	return nil
+}
+
+func (sysvBackend) remove() error {
+	if _, err := exec.LookPath("update-rc.d"); err == nil {
+		_ = runQuiet("update-rc.d", "-f", serviceName, "remove")
+	} else if _, err := exec.LookPath("chkconfig"); err == nil {
+		_ = runQuiet("chkconfig", "--del", serviceName)
+	}
+	if err := os.Remove(initScriptPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove init script: %w", err)
+	}
+	return nil
+}
+
+func (sysvBackend) status() error {
+	return runStatus(exec.Command(initScriptPath, "status"))
+}
+
+func (sysvBackend) describe() []string {
+	return []string{
+		"Script: " + initScriptPath,
+		"Status: " + initScriptPath + " status",
+		"Logs: /var/log/legator/probe-agent.log",
+	}
+}
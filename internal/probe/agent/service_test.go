@@ -0,0 +1,88 @@
+//go:build !windows
+
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectInitSystem(t *testing.T) {
+	cases := []struct {
+		name  string
+		paths []string
+		want  InitSystem
+	}{
+		{name: "systemd", paths: []string{"run/systemd/system", "etc/init.d"}, want: InitSystemd},
+		{name: "openrc", paths: []string{"sbin/openrc-run", "etc/init.d"}, want: InitOpenRC},
+		{name: "sysv", paths: []string{"etc/init.d"}, want: InitSysV},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, p := range tc.paths {
+				if err := os.MkdirAll(filepath.Join(root, p), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := detectInitSystem(root)
+			if err != nil || got != tc.want {
+				t.Fatalf("detectInitSystem = %q, %v; want %q", got, err, tc.want)
+			}
+		})
+	}
+
+	if _, err := detectInitSystem(t.TempDir()); err == nil {
+		t.Fatal("expected error when no init system is present")
+	}
+}
+
+func TestInstalledInitSystemReadsScriptInterpreter(t *testing.T) {
+	root := t.TempDir()
+	if _, ok := installedInitSystem(root); ok {
+		t.Fatal("expected no installation in empty root")
+	}
+
+	script := filepath.Join(root, initScriptPath)
+	if err := os.MkdirAll(filepath.Dir(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte(openrcScriptTemplate("/usr/local/bin/probe", "/etc/probe")), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := installedInitSystem(root); got != InitOpenRC {
+		t.Fatalf("expected openrc, got %q", got)
+	}
+
+	if err := os.WriteFile(script, []byte(sysvScriptTemplate("/usr/local/bin/probe", "/etc/probe")), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := installedInitSystem(root); got != InitSysV {
+		t.Fatalf("expected sysv, got %q", got)
+	}
+}
+
+func TestInitScriptTemplatesQuoteConfigDir(t *testing.T) {
+	for name, script := range map[string]string{
+		"openrc": openrcScriptTemplate("/usr/local/bin/probe", "/etc/it's probe"),
+		"sysv":   sysvScriptTemplate("/usr/local/bin/probe", "/etc/it's probe"),
+	} {
+		if !strings.Contains(script, `'/etc/it'\''s probe'`) {
+			t.Fatalf("%s script does not quote config dir:\n%s", name, script)
+		}
+	}
+}
+
+func TestParseInitSystem(t *testing.T) {
+	for raw, want := range map[string]InitSystem{"": InitAuto, "auto": InitAuto, "OpenRC": InitOpenRC, " sysv ": InitSysV} {
+		got, err := ParseInitSystem(raw)
+		if err != nil || got != want {
+			t.Fatalf("ParseInitSystem(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseInitSystem("upstart"); err == nil {
+		t.Fatal("expected error for unsupported init system")
+	}
+}
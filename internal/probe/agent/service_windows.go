@@ -18,7 +18,19 @@ const (
 	serviceDisplayName = "Legator Probe Agent"
 )
 
-func ServiceInstall(configDir string) error {
+// checkWindowsInit rejects init systems other than the Windows service
+// manager.
+func checkWindowsInit(init InitSystem) error {
+	if init != "" && init != InitAuto {
+		return fmt.Errorf("init system %q is not supported on windows", init)
+	}
+	return nil
+}
+
+func ServiceInstall(configDir string, init InitSystem) error {
+	if err := checkWindowsInit(init); err != nil {
+		return err
+	}
 	probeBin, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("locate probe executable: %w", err)
@@ -69,7 +81,10 @@ func ServiceInstall(configDir string) error {
 	return nil
 }
 
-func ServiceStart() error {
+func ServiceStart(init InitSystem) error {
+	if err := checkWindowsInit(init); err != nil {
+		return err
+	}
 	m, err := mgr.Connect()
 	if err != nil {
 		return fmt.Errorf("connect service manager: %w", err)
@@ -96,7 +111,10 @@ func ServiceStart() error {
 	return waitForServiceState(s, svc.Running, 20*time.Second)
 }
 
-func ServiceStop() error {
+func ServiceStop(init InitSystem) error {
+	if err := checkWindowsInit(init); err != nil {
+		return err
+	}
 	m, err := mgr.Connect()
 	if err != nil {
 		return fmt.Errorf("connect service manager: %w", err)
@@ -123,8 +141,11 @@ func ServiceStop() error {
 	return waitForServiceState(s, svc.Stopped, 20*time.Second)
 }
 
-func ServiceRemove() error {
-	_ = ServiceStop()
+func ServiceRemove(init InitSystem) error {
+	if err := checkWindowsInit(init); err != nil {
+		return err
+	}
+	_ = ServiceStop(init)
 
 	m, err := mgr.Connect()
 	if err != nil {
@@ -146,7 +167,10 @@ func ServiceRemove() error {
 	return nil
 }
 
-func ServiceStatus() error {
+func ServiceStatus(init InitSystem) error {
+	if err := checkWindowsInit(init); err != nil {
+		return err
+	}
 	m, err := mgr.Connect()
 	if err != nil {
 		return fmt.Errorf("connect service manager: %w", err)
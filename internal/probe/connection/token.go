@@ -0,0 +1,112 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	tokenExchangeTimeout = 10 * time.Second
+	// tokenRefreshMargin is how long before expiry a cached token is replaced.
+	tokenRefreshMargin = time.Minute
+)
+
+// EnableTokenAuth makes the client exchange its API key for a short-lived
+// token before each connect and authenticate the WebSocket with the token.
+// If the exchange fails (for example against a control plane without token
+// support) the client falls back to the static key.
+func (c *Client) EnableTokenAuth() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenAuth = true
+}
+
+// bearer returns the credential for the next handshake: a cached or freshly
+// exchanged token when token auth is enabled, otherwise the API key.
+func (c *Client) bearer(ctx context.Context) string {
+	c.mu.Lock()
+	apiKey := c.apiKey
+	enabled := c.tokenAuth
+	token, expires := c.token, c.tokenExpires
+	c.mu.Unlock()
+
+	if !enabled {
+		return apiKey
+	}
+	if token != "" && time.Until(expires) > tokenRefreshMargin {
+		return token
+	}
+
+	token, expires, err := c.exchangeToken(ctx, apiKey)
+	if err != nil {
+		c.logger.Warn("probe token exchange failed; using API key", zap.Error(err))
+		return apiKey
+	}
+	c.mu.Lock()
+	c.token, c.tokenExpires = token, expires
+	c.mu.Unlock()
+	return token
+}
+
+// dropToken forgets the cached token so the next connect exchanges again.
+func (c *Client) dropToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = ""
+	c.tokenExpires = time.Time{}
+}
+
+func (c *Client) exchangeToken(ctx context.Context, apiKey string) (string, time.Time, error) {
+	base := c.serverURL
+	switch {
+	case strings.HasPrefix(base, "wss://"):
+		base = "https://" + strings.TrimPrefix(base, "wss://")
+	case strings.HasPrefix(base, "ws://"):
+		base = "http://" + strings.TrimPrefix(base, "ws://")
+	}
+	endpoint := fmt.Sprintf("%s/api/v1/probe/token?id=%s", base, url.QueryEscape(c.probeID))
+
+	ctx, cancel := context.WithTimeout(ctx, tokenExchangeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	c.mu.Lock()
+	dialer := c.dialer
+	c.mu.Unlock()
+	transport := http.DefaultTransport
+	if dialer != nil && dialer.TLSClientConfig != nil {
+		transport = &http.Transport{TLSClientConfig: dialer.TLSClientConfig}
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, authErrorBodyMaxLength))
+		return "", time.Time{}, fmt.Errorf("token exchange returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var out struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", time.Time{}, fmt.Errorf("decode token response: %w", err)
+	}
+	if out.Token == "" {
+		return "", time.Time{}, fmt.Errorf("token exchange returned no token")
+	}
+	return out.Token, out.ExpiresAt, nil
+}
@@ -19,13 +19,21 @@ import (
 	"go.uber.org/zap"
 )
 
+// HeartbeatInterval is how often the client sends heartbeats unless policy
+// sets another cadence. Probes advertise it at registration so the control
+// plane can size their offline threshold.
+const HeartbeatInterval = protocol.DefaultHeartbeatInterval
+
 const (
-	heartbeatInterval      = 30 * time.Second
+	heartbeatInterval = HeartbeatInterval
+	// keepaliveInterval paces websocket pings. It stays fixed whatever the
+	// heartbeat cadence so slow heartbeats cannot trip read deadlines.
+	keepaliveInterval      = 30 * time.Second
 	offlineThreshold       = 60 * time.Second
 	maxReconnectDelay      = 5 * time.Minute
 	authReconnectDelay     = 30 * time.Second
 	writeTimeout           = 10 * time.Second
-	pongWait               = 70 * time.Second // slightly longer than heartbeat
+	pongWait               = 70 * time.Second // slightly longer than keepalive
 	authErrorBodyMaxLength = 256
 )
 
@@ -34,6 +42,7 @@ type Client struct {
 	serverURL string
 	apiKey    string
 	probeID   string
+	version   string
 	logger    *zap.Logger
 
 	conn      *websocket.Conn
@@ -42,6 +51,16 @@ type Client struct {
 	connected bool
 	inbox     chan protocol.Envelope
 	closed    chan struct{}
+
+	tokenAuth    bool
+	token        string
+	tokenExpires time.Time
+
+	inFlight        func() int
+	sampleResources func(*protocol.HeartbeatPayload)
+
+	heartbeatEvery time.Duration
+	heartbeatReset chan struct{}
 }
 
 type authHandshakeError struct {
@@ -66,6 +85,9 @@ func NewClient(serverURL, probeID, apiKey string, logger *zap.Logger) *Client {
 		dialer:    websocket.DefaultDialer,
 		inbox:     make(chan protocol.Envelope, 64),
 		closed:    make(chan struct{}),
+
+		heartbeatEvery: heartbeatInterval,
+		heartbeatReset: make(chan struct{}, 1),
 	}
 }
 
@@ -81,6 +103,64 @@ func (c *Client) SetAPIKey(apiKey string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.apiKey = apiKey
+	// Tokens are bound to the key they were exchanged for.
+	c.token = ""
+	c.tokenExpires = time.Time{}
+}
+
+// SetVersion sets the binary version reported on every heartbeat.
+func (c *Client) SetVersion(version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version = version
+}
+
+// SetInFlightCounter sets the function that reports the probe's in-flight
+// command count on every heartbeat.
+func (c *Client) SetInFlightCounter(fn func() int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inFlight = fn
+}
+
+// SetResourceSampler sets the function that fills host resource readings
+// (load, memory, disk, CPU) into every heartbeat.
+func (c *Client) SetResourceSampler(fn func(*protocol.HeartbeatPayload)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sampleResources = fn
+}
+
+// SetHeartbeatInterval changes the heartbeat cadence, taking effect on the
+// running connection without reconnecting. Zero restores the default;
+// other values are clamped to the protocol bounds.
+func (c *Client) SetHeartbeatInterval(d time.Duration) {
+	switch {
+	case d <= 0:
+		d = heartbeatInterval
+	case d < protocol.MinHeartbeatInterval:
+		d = protocol.MinHeartbeatInterval
+	case d > protocol.MaxHeartbeatInterval:
+		d = protocol.MaxHeartbeatInterval
+	}
+	c.mu.Lock()
+	changed := c.heartbeatEvery != d
+	c.heartbeatEvery = d
+	c.mu.Unlock()
+	if !changed {
+		return
+	}
+	select {
+	case c.heartbeatReset <- struct{}{}:
+	default:
+	}
+}
+
+// HeartbeatInterval returns the current heartbeat cadence.
+func (c *Client) HeartbeatInterval() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.heartbeatEvery
 }
 
 // SetDialer overrides the websocket dialer used for future connections.
@@ -172,7 +252,7 @@ func jitter(d time.Duration) time.Duration {
 func (c *Client) connectAndServe(ctx context.Context) (bool, error) {
 	url := fmt.Sprintf("%s/ws/probe?id=%s", c.serverURL, c.probeID)
 	header := map[string][]string{
-		"Authorization": {fmt.Sprintf("Bearer %s", c.apiKey)},
+		"Authorization": {fmt.Sprintf("Bearer %s", c.bearer(ctx))},
 	}
 
 	c.mu.Lock()
@@ -186,6 +266,7 @@ func (c *Client) connectAndServe(ctx context.Context) (bool, error) {
 		if resp != nil {
 			defer resp.Body.Close()
 			if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+				c.dropToken()
 				body, _ := io.ReadAll(io.LimitReader(resp.Body, authErrorBodyMaxLength))
 				return false, &authHandshakeError{StatusCode: resp.StatusCode, Body: string(body)}
 			}
@@ -242,14 +323,23 @@ func (c *Client) connectAndServe(ctx context.Context) (bool, error) {
 }
 
 func (c *Client) heartbeatLoop(ctx context.Context) {
-	ticker := time.NewTicker(heartbeatInterval)
-	defer ticker.Stop()
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+	heartbeat := time.NewTicker(c.HeartbeatInterval())
+	defer heartbeat.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case <-c.heartbeatReset:
+			heartbeat.Reset(c.HeartbeatInterval())
+		case <-keepalive.C:
+			if err := c.sendPing(); err != nil {
+				c.logger.Warn("keepalive failed", zap.Error(err))
+				return
+			}
+		case <-heartbeat.C:
 			if err := c.sendHeartbeat(); err != nil {
 				c.logger.Warn("heartbeat failed", zap.Error(err))
 				return
@@ -258,22 +348,41 @@ func (c *Client) heartbeatLoop(ctx context.Context) {
 	}
 }
 
-func (c *Client) sendHeartbeat() error {
-	// Send WebSocket ping frame to keep connection alive.
-	// The server auto-responds with Pong, which resets our read deadline
-	// via the PongHandler.
+// sendPing writes a WebSocket ping frame to keep the connection alive. The
+// server auto-responds with Pong, which resets our read deadline via the
+// PongHandler.
+func (c *Client) sendPing() error {
 	c.mu.Lock()
 	conn := c.conn
 	c.mu.Unlock()
-	if conn != nil {
-		_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
-		if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-			return fmt.Errorf("ping: %w", err)
-		}
+	if conn == nil {
+		return nil
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+		return fmt.Errorf("ping: %w", err)
 	}
+	return nil
+}
+
+func (c *Client) sendHeartbeat() error {
+	c.mu.Lock()
+	version := c.version
+	inFlight := c.inFlight
+	sample := c.sampleResources
+	every := c.heartbeatEvery
+	c.mu.Unlock()
 
 	hb := protocol.HeartbeatPayload{
-		ProbeID: c.probeID,
+		ProbeID:              c.probeID,
+		Version:              version,
+		HeartbeatIntervalSec: int(every / time.Second),
+	}
+	if inFlight != nil {
+		hb.InFlightCommands = inFlight()
+	}
+	if sample != nil {
+		sample(&hb)
 	}
 	return c.Send(protocol.MsgHeartbeat, hb)
 }
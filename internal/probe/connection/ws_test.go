@@ -321,6 +321,54 @@ func TestRunUsesExtendedBackoffForAuthErrors(t *testing.T) {
 	}
 }
 
+func TestBearerExchangesTokenAndFallsBack(t *testing.T) {
+	var exchanges atomic.Int32
+	var available atomic.Bool
+	available.Store(true)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available.Load() {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/probe/token" || r.URL.Query().Get("id") != "probe-token" {
+			t.Errorf("unexpected exchange request %s %s", r.Method, r.URL)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer api-key" {
+			t.Errorf("exchange authorization = %q", got)
+		}
+		exchanges.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      "eyJ.token.sig",
+			"expires_at": time.Now().Add(15 * time.Minute),
+		})
+	}))
+	defer ts.Close()
+
+	c := NewClient(wsURL(ts.URL), "probe-token", "api-key", zap.NewNop())
+	ctx := context.Background()
+	if got := c.bearer(ctx); got != "api-key" {
+		t.Fatalf("bearer without token auth = %q, want API key", got)
+	}
+
+	c.EnableTokenAuth()
+	for i := 0; i < 2; i++ {
+		if got := c.bearer(ctx); got != "eyJ.token.sig" {
+			t.Fatalf("bearer = %q, want exchanged token", got)
+		}
+	}
+	if n := exchanges.Load(); n != 1 {
+		t.Fatalf("expected cached token to be reused, got %d exchanges", n)
+	}
+
+	// Rotating the key drops the token; a control plane without the
+	// exchange endpoint falls back to the static key.
+	c.SetAPIKey("api-key")
+	available.Store(false)
+	if got := c.bearer(ctx); got != "api-key" {
+		t.Fatalf("bearer after failed exchange = %q, want API key", got)
+	}
+}
+
 func wsURL(httpURL string) string {
 	return "ws" + strings.TrimPrefix(httpURL, "http")
 }
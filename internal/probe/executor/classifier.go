@@ -13,6 +13,8 @@ var observeCommands = []string{
 	"lsof", "file", "stat", "wc", "grep", "find",
 	"journalctl", "which", "type", "echo", "date", "env", "printenv",
 	"lsb_release", "arch", "nproc", "getent", "groups", "last", "w", "sleep", "true", "false",
+	// Windows
+	"ipconfig", "systeminfo", "tasklist", "ver", "dir", "where", "getmac", "vol",
 }
 
 // observePrefixes are command prefixes that are observe-level.
@@ -20,6 +22,7 @@ var observePrefixes = []string{
 	"ip addr", "ip route", "ip link", "ip neigh",
 	"systemctl status", "systemctl is-active", "systemctl is-enabled",
 	"systemctl list-units", "systemctl list-timers",
+	"route print", "sc query", "sc qc", "netsh interface show", "netsh interface ip show",
 	"docker ps", "docker images", "docker inspect",
 	"podman ps", "podman images", "podman inspect",
 }
@@ -58,6 +61,7 @@ var remediatePrefixes = []string{
 	"passwd ", "chpasswd",
 	"crontab ",
 	"kubeflow cancel",
+	"ipconfig /release", "ipconfig /renew", "ipconfig /flushdns", "ipconfig /registerdns",
 }
 
 // CommandClassification is a deterministic classification result for a command line.
@@ -77,6 +81,12 @@ func ClassifyCommand(command string, args []string) protocol.CapabilityLevel {
 // ClassifyCommandWithMetadata classifies command capability and whether the mutation signature is known.
 func ClassifyCommandWithMetadata(command string, args []string) CommandClassification {
 	fullLower, baseLower := normalizedCommand(command, args)
+	if isPowerShell(baseLower) {
+		return classifyPowerShell(wrappedArgs(command, args))
+	}
+	if isCmdShell(baseLower) {
+		return classifyCmdShell(wrappedArgs(command, args))
+	}
 
 	// Check remediate prefixes first (highest priority)
 	for _, p := range remediatePrefixes {
@@ -52,6 +52,28 @@ func TestClassifyCommand(t *testing.T) {
 		{"sed -i", "sed", []string{"-i", "s/old/new/", "/etc/config"}, protocol.CapRemediate},
 		{"useradd", "useradd", []string{"newuser"}, protocol.CapRemediate},
 		{"kubeflow cancel", "kubeflow cancel run/my-job", nil, protocol.CapRemediate},
+
+		// Windows
+		{"ipconfig", "ipconfig", []string{"/all"}, protocol.CapObserve},
+		{"ipconfig release", "ipconfig", []string{"/release"}, protocol.CapRemediate},
+		{"sc query", "sc", []string{"query", "wuauserv"}, protocol.CapObserve},
+		{"powershell get-service", "powershell", []string{"Get-Service"}, protocol.CapObserve},
+		{"powershell pipeline", "powershell", []string{"-NoProfile", "-Command", "Get-Process | Sort-Object CPU | Select-Object -First 5"}, protocol.CapObserve},
+		{"powershell full line", "pwsh -Command Get-ChildItem C:\\Logs", nil, protocol.CapObserve},
+		{"powershell network probe", "powershell", []string{"Test-NetConnection", "example.com", "-Port", "443"}, protocol.CapDiagnose},
+		{"powershell mutation", "powershell", []string{"Stop-Service", "wuauserv"}, protocol.CapRemediate},
+		{"powershell mutation in pipeline", "powershell", []string{"Get-Service wuauserv | Stop-Service"}, protocol.CapRemediate},
+		{"powershell statement chaining", "powershell", []string{"Get-Service; Remove-Item C:\\x"}, protocol.CapRemediate},
+		{"powershell script block", "powershell", []string{"Get-Service | ForEach-Object { $_.Stop() }"}, protocol.CapRemediate},
+		{"powershell grouping runs a mutation", "powershell", []string{"-Command", "Get-Item (Stop-Service x)"}, protocol.CapRemediate},
+		{"powershell grouping runs a delete", "powershell", []string{"Get-Item (Remove-Item C:\\x -Recurse)"}, protocol.CapRemediate},
+		{"powershell array argument", "powershell", []string{"Get-Item a,(Stop-Service x)"}, protocol.CapRemediate},
+		{"powershell static call", "powershell", []string{"Get-Item [IO.File]::Delete('C:\\x')"}, protocol.CapRemediate},
+		{"powershell splatting", "powershell", []string{"Get-Item @args"}, protocol.CapRemediate},
+		{"powershell encoded", "powershell", []string{"-EncodedCommand", "RwBlAHQALQBTAGUAcgB2AGkAYwBlAA=="}, protocol.CapRemediate},
+		{"cmd wrapped observe", "cmd", []string{"/c", "tasklist"}, protocol.CapObserve},
+		{"cmd chaining", "cmd", []string{"/c", "dir", "&", "del", "C:\\x"}, protocol.CapRemediate},
+		{"cmd wrapped mutation", "cmd.exe", []string{"/C", "shutdown", "/r"}, protocol.CapRemediate},
 	}
 
 	for _, tt := range tests {
@@ -90,4 +112,13 @@ func TestClassifyCommandWithMetadata(t *testing.T) {
 			t.Fatalf("unexpected reason code: %s", got.ReasonCode)
 		}
 	})
+
+	t.Run("powershell grouping hides a second command", func(t *testing.T) {
+		for _, script := range []string{"Get-Item (Stop-Service x)", "Get-Item (Remove-Item C:\\x -Recurse)"} {
+			got := ClassifyCommandWithMetadata("powershell", []string{"-Command", script})
+			if got.Level != protocol.CapRemediate || got.ReasonCode != "classifier.shell_chaining" {
+				t.Fatalf("%q: expected remediate shell_chaining, got %s %s", script, got.Level, got.ReasonCode)
+			}
+		}
+	})
 }
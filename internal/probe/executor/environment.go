@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// processContext resolves the working directory and environment a command
+// asks for. An empty dir and nil env leave the probe's own in place.
+func (e *Executor) processContext(cmd *protocol.CommandPayload) (dir string, env []string, err error) {
+	if err := protocol.ValidateCommandEnv(cmd.Env); err != nil {
+		return "", nil, fmt.Errorf("policy violation: %w", err)
+	}
+	if err := e.checkEnvKeys(cmd.Env); err != nil {
+		return "", nil, err
+	}
+	if cmd.WorkDir != "" {
+		dir, err = e.resolveWorkDir(cmd.WorkDir)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	if len(cmd.Env) > 0 {
+		keys := make([]string, 0, len(cmd.Env))
+		for key := range cmd.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		// os/exec keeps the last value of a duplicated key, so overrides
+		// appended after the inherited environment win.
+		env = os.Environ()
+		for _, key := range keys {
+			env = append(env, key+"="+cmd.Env[key])
+		}
+	}
+	return dir, env, nil
+}
+
+// checkEnvKeys refuses variables the policy's EnvKeys do not list. Keys
+// compare without case on Windows, as its environment does.
+func (e *Executor) checkEnvKeys(env map[string]string) error {
+	if len(env) == 0 {
+		return nil
+	}
+	if len(e.policy.EnvKeys) == 0 {
+		return fmt.Errorf("policy violation: env not allowed: policy sets no env_keys")
+	}
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !slices.ContainsFunc(e.policy.EnvKeys, func(allowed string) bool {
+			if runtime.GOOS == "windows" {
+				return strings.EqualFold(allowed, key)
+			}
+			return allowed == key
+		}) {
+			return fmt.Errorf("policy violation: env key %s is not in the policy's env_keys", key)
+		}
+	}
+	return nil
+}
+
+// resolveWorkDir returns the real path of workDir if it lies under one of
+// the policy's WorkDirs. Symlinks are resolved on both sides so a link
+// inside an allowed directory cannot point outside it.
+func (e *Executor) resolveWorkDir(workDir string) (string, error) {
+	if len(e.policy.WorkDirs) == 0 {
+		return "", fmt.Errorf("policy violation: working directory %s not allowed: policy sets no work_dirs", workDir)
+	}
+	if !filepath.IsAbs(workDir) {
+		return "", fmt.Errorf("policy violation: working directory %s must be an absolute path", workDir)
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(workDir))
+	if err != nil {
+		return "", fmt.Errorf("working directory %s: %w", workDir, err)
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", fmt.Errorf("working directory %s: %w", workDir, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("working directory %s is not a directory", workDir)
+	}
+	for _, root := range e.policy.WorkDirs {
+		if !filepath.IsAbs(root) {
+			continue
+		}
+		realRoot, err := filepath.EvalSymlinks(filepath.Clean(root))
+		if err != nil {
+			realRoot = filepath.Clean(root)
+		}
+		if withinDir(resolved, realRoot) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("policy violation: working directory %s is outside the policy's work_dirs", workDir)
+}
+
+// withinDir reports whether path is root or below it.
+func withinDir(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -2,10 +2,10 @@
 package executor
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"os/exec"
+	"runtime"
 	"strings"
 	"time"
 
@@ -14,7 +14,6 @@ import (
 )
 
 const (
-	maxOutputSize  = 1 << 20 // 1MB per stream
 	defaultTimeout = 30 * time.Second
 )
 
@@ -24,12 +23,20 @@ type Policy struct {
 	Allowed []string // command prefixes allowed (empty = all at level)
 	Blocked []string // command prefixes always blocked
 	Paths   []string // protected paths (no writes)
+	// WorkDirs are the directories, and their subdirectories, a command may
+	// run in. Empty refuses any command that sets a working directory.
+	WorkDirs []string
+	// EnvKeys are the variables a command may set. Empty refuses any
+	// command that sets environment variables.
+	EnvKeys []string
 }
 
 // Executor runs commands with policy enforcement.
 type Executor struct {
 	policy Policy
 	logger *zap.Logger
+	// outputLimit is the default per-stream output cap; see SetOutputLimit.
+	outputLimit int
 }
 
 // New creates an executor with the given policy.
@@ -40,19 +47,30 @@ func New(policy Policy, logger *zap.Logger) *Executor {
 	}
 }
 
+// Policy returns a copy of the policy the executor enforces.
+func (e *Executor) Policy() Policy {
+	return Policy{
+		Level:    e.policy.Level,
+		Allowed:  append([]string(nil), e.policy.Allowed...),
+		Blocked:  append([]string(nil), e.policy.Blocked...),
+		Paths:    append([]string(nil), e.policy.Paths...),
+		WorkDirs: append([]string(nil), e.policy.WorkDirs...),
+		EnvKeys:  append([]string(nil), e.policy.EnvKeys...),
+	}
+}
+
 // effectiveLevel returns the higher of the declared level and the classified level.
 // This prevents callers from bypassing policy by declaring a low level on a dangerous command.
 func (e *Executor) effectiveLevel(cmd *protocol.CommandPayload) protocol.CapabilityLevel {
 	declared := cmd.Level
 	classified := ClassifyCommand(cmd.Command, cmd.Args)
-
-	levels := map[protocol.CapabilityLevel]int{
-		protocol.CapObserve:   1,
-		protocol.CapDiagnose:  2,
-		protocol.CapRemediate: 3,
+	// On Windows buildExecSpec hands plain commands to cmd.exe /C, which
+	// would interpret chaining in the arguments.
+	if runtime.GOOS == "windows" && usesCmdMetacharacters(cmd.Command, cmd.Args) {
+		classified = protocol.CapRemediate
 	}
 
-	if levels[classified] > levels[declared] {
+	if capabilityRank[classified] > capabilityRank[declared] {
 		return classified
 	}
 	return declared
@@ -105,6 +123,18 @@ func (e *Executor) Execute(ctx context.Context, cmd *protocol.CommandPayload) *p
 		return result
 	}
 
+	dir, env, err := e.processContext(cmd)
+	if err != nil {
+		result.ExitCode = -1
+		result.Stderr = err.Error()
+		e.logger.Warn("command blocked",
+			zap.String("request_id", cmd.RequestID),
+			zap.String("work_dir", cmd.WorkDir),
+			zap.Error(err),
+		)
+		return result
+	}
+
 	// Set timeout
 	timeout := cmd.Timeout
 	if timeout == 0 {
@@ -122,19 +152,25 @@ func (e *Executor) Execute(ctx context.Context, cmd *protocol.CommandPayload) *p
 
 	// Execute
 	start := time.Now()
-	var stdout, stderr bytes.Buffer
+	limit := e.outputLimitFor(cmd)
+	stdout := &cappedBuffer{limit: limit}
+	stderr := &cappedBuffer{limit: limit}
 
 	c := exec.CommandContext(execCtx, spec.name, spec.args...)
-	c.Stdout = &stdout
-	c.Stderr = &stderr
+	c.Stdout = stdout
+	c.Stderr = stderr
+	c.Dir = dir
+	c.Env = env
 
 	err = c.Run()
 	result.Duration = time.Since(start).Milliseconds()
 
-	// Capture output (truncate if needed)
-	result.Stdout = truncate(stdout.String(), maxOutputSize)
-	result.Stderr = truncate(stderr.String(), maxOutputSize)
-	result.Truncated = stdout.Len() > maxOutputSize || stderr.Len() > maxOutputSize
+	// Capture output; anything past the cap was already dropped
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.StdoutBytes = stdout.total
+	result.StderrBytes = stderr.total
+	result.Truncated = stdout.truncated() || stderr.truncated()
 
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
@@ -150,6 +186,7 @@ func (e *Executor) Execute(ctx context.Context, cmd *protocol.CommandPayload) *p
 		zap.String("command", cmd.Command),
 		zap.Int("exit_code", result.ExitCode),
 		zap.Int64("duration_ms", result.Duration),
+		zap.Bool("truncated", result.Truncated),
 	)
 
 	return result
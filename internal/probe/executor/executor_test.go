@@ -2,6 +2,8 @@ package executor
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -134,6 +136,39 @@ func TestTruncate(t *testing.T) {
 	}
 }
 
+func TestExecute_TruncatesOutputAtCap(t *testing.T) {
+	e := New(Policy{Level: protocol.CapRemediate}, testLogger())
+	e.SetOutputLimit(16)
+
+	result := e.Execute(context.Background(), &protocol.CommandPayload{
+		RequestID: "cap-1",
+		Command:   "sh",
+		Args:      []string{"-c", "printf '%0100d' 0"},
+		Level:     protocol.CapObserve,
+	})
+	if !result.Truncated || result.StdoutBytes != 100 {
+		t.Fatalf("expected truncated 100-byte stdout, got truncated=%v bytes=%d", result.Truncated, result.StdoutBytes)
+	}
+	if want := strings.Repeat("0", 16) + truncationMarker(16, 100); result.Stdout != want {
+		t.Fatalf("unexpected stdout %q", result.Stdout)
+	}
+
+	// A per-command override raises the cap, bounded by the hard maximum.
+	result = e.Execute(context.Background(), &protocol.CommandPayload{
+		RequestID:      "cap-2",
+		Command:        "sh",
+		Args:           []string{"-c", "printf '%0100d' 0"},
+		Level:          protocol.CapObserve,
+		MaxOutputBytes: 200,
+	})
+	if result.Truncated || len(result.Stdout) != 100 {
+		t.Fatalf("expected full output with override, got truncated=%v len=%d", result.Truncated, len(result.Stdout))
+	}
+	if got := e.outputLimitFor(&protocol.CommandPayload{MaxOutputBytes: protocol.MaxCommandOutputBytes + 1}); got != protocol.MaxCommandOutputBytes {
+		t.Fatalf("expected override clamped to %d, got %d", protocol.MaxCommandOutputBytes, got)
+	}
+}
+
 func TestExecute_ClassifierOverridesDeclaredLevel(t *testing.T) {
 	// Probe is at observe level
 	e := New(Policy{Level: protocol.CapObserve}, testLogger())
@@ -173,3 +208,75 @@ func TestExecute_ClassifierAllowsLegitObserve(t *testing.T) {
 		t.Errorf("expected exit 0, got %d: %s", result.ExitCode, result.Stderr)
 	}
 }
+
+func TestExecute_WorkDirAndEnv(t *testing.T) {
+	root := t.TempDir()
+	appDir := filepath.Join(root, "app")
+	if err := os.Mkdir(appDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	realApp, err := filepath.EvalSymlinks(appDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New(Policy{Level: protocol.CapRemediate, WorkDirs: []string{root}, EnvKeys: []string{"APP_ENV"}}, testLogger())
+
+	result := e.Execute(context.Background(), &protocol.CommandPayload{
+		RequestID: "wd-1",
+		Command:   "pwd",
+		WorkDir:   appDir,
+		Timeout:   5 * time.Second,
+	})
+	if result.ExitCode != 0 || strings.TrimSpace(result.Stdout) != realApp {
+		t.Fatalf("pwd = %q (exit %d, stderr %q), want %q", result.Stdout, result.ExitCode, result.Stderr, realApp)
+	}
+
+	result = e.Execute(context.Background(), &protocol.CommandPayload{
+		RequestID: "wd-2",
+		Command:   "printenv",
+		Args:      []string{"APP_ENV"},
+		Env:       map[string]string{"APP_ENV": "staging"},
+		Timeout:   5 * time.Second,
+	})
+	if result.ExitCode != 0 || strings.TrimSpace(result.Stdout) != "staging" {
+		t.Fatalf("printenv = %q (exit %d, stderr %q)", result.Stdout, result.ExitCode, result.Stderr)
+	}
+}
+
+func TestExecute_WorkDirAndEnvBlockedByPolicy(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	link := filepath.Join(root, "escape")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		cmd    protocol.CommandPayload
+		want   string
+	}{
+		{"no work_dirs", Policy{Level: protocol.CapRemediate}, protocol.CommandPayload{WorkDir: root}, "policy sets no work_dirs"},
+		{"outside", Policy{Level: protocol.CapRemediate, WorkDirs: []string{root}}, protocol.CommandPayload{WorkDir: outside}, "outside the policy's work_dirs"},
+		{"symlink escape", Policy{Level: protocol.CapRemediate, WorkDirs: []string{root}}, protocol.CommandPayload{WorkDir: link}, "outside the policy's work_dirs"},
+		{"dot-dot escape", Policy{Level: protocol.CapRemediate, WorkDirs: []string{root}}, protocol.CommandPayload{WorkDir: root + "/../" + filepath.Base(outside)}, "outside the policy's work_dirs"},
+		{"relative", Policy{Level: protocol.CapRemediate, WorkDirs: []string{root}}, protocol.CommandPayload{WorkDir: "app"}, "must be an absolute path"},
+		{"protected env", Policy{Level: protocol.CapRemediate}, protocol.CommandPayload{Env: map[string]string{"LD_PRELOAD": "/tmp/x.so"}}, "LD_PRELOAD is protected"},
+		{"protected git env", Policy{Level: protocol.CapRemediate, EnvKeys: []string{"GIT_SSH_COMMAND"}}, protocol.CommandPayload{Env: map[string]string{"GIT_SSH_COMMAND": "sh -c id"}}, "GIT_SSH_COMMAND is protected"},
+		{"protected pager", Policy{Level: protocol.CapRemediate, EnvKeys: []string{"PAGER"}}, protocol.CommandPayload{Env: map[string]string{"PAGER": "sh"}}, "PAGER is protected"},
+		{"no env_keys", Policy{Level: protocol.CapRemediate}, protocol.CommandPayload{Env: map[string]string{"APP_ENV": "staging"}}, "policy sets no env_keys"},
+		{"unlisted env key", Policy{Level: protocol.CapRemediate, EnvKeys: []string{"APP_ENV"}}, protocol.CommandPayload{Env: map[string]string{"APP_ENV": "staging", "OTHER": "x"}}, "OTHER is not in the policy's env_keys"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := tc.cmd
+			cmd.RequestID = "wd-blocked"
+			cmd.Command = "pwd"
+			result := New(tc.policy, testLogger()).Execute(context.Background(), &cmd)
+			if result.ExitCode != -1 || !strings.Contains(result.Stderr, tc.want) {
+				t.Fatalf("got exit %d stderr %q, want blocked with %q", result.ExitCode, result.Stderr, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// SetOutputLimit sets the default per-stream output cap. Values outside
+// 1..protocol.MaxCommandOutputBytes fall back to the protocol default.
+func (e *Executor) SetOutputLimit(limit int) {
+	if limit <= 0 || limit > protocol.MaxCommandOutputBytes {
+		limit = protocol.DefaultCommandOutputBytes
+	}
+	e.outputLimit = limit
+}
+
+// OutputLimit returns the default per-stream output cap.
+func (e *Executor) OutputLimit() int {
+	if e.outputLimit <= 0 {
+		return protocol.DefaultCommandOutputBytes
+	}
+	return e.outputLimit
+}
+
+// outputLimitFor returns the cap for cmd: its own override, bounded by the
+// hard maximum, or the executor default.
+func (e *Executor) outputLimitFor(cmd *protocol.CommandPayload) int {
+	if cmd.MaxOutputBytes > 0 {
+		return min(cmd.MaxOutputBytes, protocol.MaxCommandOutputBytes)
+	}
+	return e.OutputLimit()
+}
+
+// truncationMarker is appended to output cut at limit bytes.
+func truncationMarker(limit int, total int64) string {
+	return fmt.Sprintf("\n[output truncated: showing %d of %d bytes]\n", limit, total)
+}
+
+// cappedBuffer keeps the first limit bytes written to it and counts the
+// rest, so a noisy command cannot grow probe memory without bound.
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+	total int64
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += int64(len(p))
+	if room := b.limit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(room, len(p))])
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) truncated() bool {
+	return b.total > int64(b.limit)
+}
+
+// String returns the kept output, with a truncation marker when bytes were
+// dropped.
+func (b *cappedBuffer) String() string {
+	if !b.truncated() {
+		return b.buf.String()
+	}
+	return b.buf.String() + truncationMarker(b.limit, b.total)
+}
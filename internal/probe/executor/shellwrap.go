@@ -0,0 +1,176 @@
+package executor
+
+import (
+	"strings"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+)
+
+// Windows probes run commands through cmd.exe or PowerShell (see
+// buildExecSpec), so the wrapper itself says nothing about risk. The helpers
+// here classify the wrapped command line instead, and refuse to call
+// anything observe- or diagnose-level when it uses shell syntax that could
+// chain a second command.
+
+// cmdMetacharacters chain, redirect or escape commands under cmd.exe.
+const cmdMetacharacters = "&|<>^\n\r"
+
+// powershellUnsafeSyntax can run or hide further commands inside an
+// otherwise read-only pipeline: statement separators, redirection, escapes,
+// grouping and subexpressions (any "("), script blocks and hashtables,
+// splatting, array arguments, and type casts or static calls ("[").
+var powershellUnsafeSyntax = []string{";", "&", ">", "<", "`", "(", "{", "@", ",", "[", "\n", "\r"}
+
+// powershellObserveVerbs are read-only cmdlet prefixes. Each pipeline stage
+// must match one (or be a known command) for the script to stay observe.
+var powershellObserveVerbs = []string{
+	"get-", "test-path", "select-", "where-", "sort-", "measure-",
+	"format-", "convertto-", "out-string", "group-object",
+}
+
+// powershellDiagnoseCmdlets probe the network.
+var powershellDiagnoseCmdlets = []string{
+	"test-netconnection", "test-connection", "resolve-dnsname",
+}
+
+var capabilityRank = map[protocol.CapabilityLevel]int{
+	protocol.CapObserve:   1,
+	protocol.CapDiagnose:  2,
+	protocol.CapRemediate: 3,
+}
+
+func isPowerShell(base string) bool {
+	switch base {
+	case "powershell", "powershell.exe", "pwsh", "pwsh.exe":
+		return true
+	}
+	return false
+}
+
+func isCmdShell(base string) bool {
+	return base == "cmd" || base == "cmd.exe"
+}
+
+func unknownMutation(reason string) CommandClassification {
+	return CommandClassification{
+		Level:          protocol.CapRemediate,
+		Category:       "mutation",
+		SignatureKnown: false,
+		ReasonCode:     reason,
+	}
+}
+
+// wrappedArgs returns the words following the shell name in a command line
+// that may carry them either in command or in args.
+func wrappedArgs(command string, args []string) []string {
+	words := strings.Fields(command)
+	if len(words) > 0 {
+		words = words[1:]
+	}
+	return append(words, args...)
+}
+
+// classifyPowerShell classifies `powershell [-NoProfile] [-NonInteractive]
+// [-Command] <script>` by its pipeline. Encoded commands, script files and
+// any other switches are opaque and stay remediate.
+func classifyPowerShell(words []string) CommandClassification {
+	var script string
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		if !strings.HasPrefix(lower, "-") {
+			script = strings.Join(words[i:], " ")
+			break
+		}
+		if lower == "-command" || lower == "-c" {
+			script = strings.Join(words[i+1:], " ")
+			break
+		}
+		if lower != "-noprofile" && lower != "-noninteractive" && lower != "-nologo" {
+			return unknownMutation("classifier.powershell_opaque_invocation")
+		}
+	}
+	script = strings.Trim(strings.TrimSpace(script), `"'`)
+	if script == "" {
+		return unknownMutation("classifier.powershell_opaque_invocation")
+	}
+	for _, token := range powershellUnsafeSyntax {
+		if strings.Contains(script, token) {
+			return unknownMutation("classifier.shell_chaining")
+		}
+	}
+
+	result := CommandClassification{
+		Level:          protocol.CapObserve,
+		Category:       "observe",
+		SignatureKnown: true,
+		ReasonCode:     "classifier.powershell_pipeline",
+	}
+	for _, stage := range strings.Split(script, "|") {
+		stageClass := classifyPowerShellStage(strings.TrimSpace(stage))
+		if capabilityRank[stageClass.Level] > capabilityRank[result.Level] {
+			result = stageClass
+		}
+	}
+	return result
+}
+
+func classifyPowerShellStage(stage string) CommandClassification {
+	fields := strings.Fields(strings.ToLower(stage))
+	if len(fields) == 0 {
+		return unknownMutation("classifier.powershell_opaque_invocation")
+	}
+	name := fields[0]
+	for _, cmdlet := range powershellDiagnoseCmdlets {
+		if name == cmdlet {
+			return CommandClassification{
+				Level:          protocol.CapDiagnose,
+				Category:       "diagnose",
+				SignatureKnown: true,
+				ReasonCode:     "classifier.powershell_pipeline",
+			}
+		}
+	}
+	for _, verb := range powershellObserveVerbs {
+		if strings.HasPrefix(name, verb) {
+			return CommandClassification{
+				Level:          protocol.CapObserve,
+				Category:       "observe",
+				SignatureKnown: true,
+				ReasonCode:     "classifier.powershell_pipeline",
+			}
+		}
+	}
+	return ClassifyCommandWithMetadata(stage, nil)
+}
+
+// classifyCmdShell classifies `cmd [/C|/K] <line>` by the wrapped line.
+func classifyCmdShell(words []string) CommandClassification {
+	if len(words) > 0 {
+		switch strings.ToLower(words[0]) {
+		case "/c", "/k":
+			words = words[1:]
+		}
+	}
+	line := strings.TrimSpace(strings.Join(words, " "))
+	if line == "" {
+		return unknownMutation("classifier.unknown_mutation_signature")
+	}
+	if strings.ContainsAny(line, cmdMetacharacters) {
+		return unknownMutation("classifier.shell_chaining")
+	}
+	return ClassifyCommandWithMetadata(line, nil)
+}
+
+// usesCmdMetacharacters reports whether a command that a Windows probe would
+// hand to cmd.exe /C could chain or redirect.
+func usesCmdMetacharacters(command string, args []string) bool {
+	if strings.ContainsAny(command, cmdMetacharacters) {
+		return true
+	}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, cmdMetacharacters) {
+			return true
+		}
+	}
+	return false
+}
@@ -63,6 +63,18 @@ func (e *Executor) ExecuteStream(ctx context.Context, cmd *protocol.CommandPaylo
 		return
 	}
 
+	dir, env, err := e.processContext(cmd)
+	if err != nil {
+		cb(protocol.OutputChunkPayload{
+			RequestID: cmd.RequestID,
+			Stream:    "stderr",
+			Data:      err.Error(),
+			Final:     true,
+			ExitCode:  -1,
+		})
+		return
+	}
+
 	timeout := cmd.Timeout
 	if timeout == 0 {
 		timeout = defaultTimeout
@@ -83,6 +95,8 @@ func (e *Executor) ExecuteStream(ctx context.Context, cmd *protocol.CommandPaylo
 	}
 
 	c := exec.CommandContext(execCtx, spec.name, spec.args...)
+	c.Dir = dir
+	c.Env = env
 
 	stdout, err := c.StdoutPipe()
 	if err != nil {
@@ -121,18 +135,43 @@ func (e *Executor) ExecuteStream(ctx context.Context, cmd *protocol.CommandPaylo
 	}
 
 	var seq atomic.Int32
+	var truncated atomic.Bool
 	var wg sync.WaitGroup
 	wg.Add(2)
 
+	// Each stream is emitted line by line until it reaches the output cap.
+	// Past the cap the pipe is still drained, so the command never blocks on
+	// a full pipe, but only a single truncation marker is sent.
+	limit := e.outputLimitFor(cmd)
 	streamPipe := func(r io.Reader, stream string) {
 		defer wg.Done()
-		scanner := bufio.NewScanner(r)
-		scanner.Buffer(make([]byte, 64*1024), maxOutputSize)
-		for scanner.Scan() {
+		reader := bufio.NewReaderSize(r, 64*1024)
+		var sent, total int64
+		for {
+			data, err := reader.ReadSlice('\n')
+			if len(data) > 0 {
+				total += int64(len(data))
+				if room := int64(limit) - sent; room > 0 {
+					line := truncate(string(data), int(min(room, int64(len(data)))))
+					sent += int64(len(line))
+					cb(protocol.OutputChunkPayload{
+						RequestID: cmd.RequestID,
+						Stream:    stream,
+						Data:      line,
+						Seq:       int(seq.Add(1)),
+					})
+				}
+			}
+			if err != nil && err != bufio.ErrBufferFull {
+				break
+			}
+		}
+		if total > int64(limit) {
+			truncated.Store(true)
 			cb(protocol.OutputChunkPayload{
 				RequestID: cmd.RequestID,
 				Stream:    stream,
-				Data:      scanner.Text() + "\n",
+				Data:      truncationMarker(limit, total),
 				Seq:       int(seq.Add(1)),
 			})
 		}
@@ -162,6 +201,7 @@ func (e *Executor) ExecuteStream(ctx context.Context, cmd *protocol.CommandPaylo
 		Seq:       int(seq.Add(1)),
 		Final:     true,
 		ExitCode:  exitCode,
+		Truncated: truncated.Load(),
 	})
 
 	e.logger.Info("streaming command completed",
@@ -169,5 +209,6 @@ func (e *Executor) ExecuteStream(ctx context.Context, cmd *protocol.CommandPaylo
 		zap.String("command", cmd.Command),
 		zap.Int("exit_code", exitCode),
 		zap.Int64("duration_ms", duration),
+		zap.Bool("truncated", truncated.Load()),
 	)
 }
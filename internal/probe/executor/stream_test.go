@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"testing"
 
@@ -190,3 +191,44 @@ func TestExecuteStream_NonZeroExit(t *testing.T) {
 		t.Fatalf("expected exit 42, got %d", last.ExitCode)
 	}
 }
+
+func TestExecuteStream_StopsEmittingPastCap(t *testing.T) {
+	e := New(Policy{Level: protocol.CapRemediate}, zap.NewNop())
+	e.SetOutputLimit(10)
+	cmd := &protocol.CommandPayload{
+		RequestID: "s7",
+		Command:   "sh",
+		Args:      []string{"-c", "for i in 1 2 3 4 5 6 7 8; do echo line$i; done"},
+		Level:     protocol.CapObserve,
+	}
+
+	var mu sync.Mutex
+	var chunks []protocol.OutputChunkPayload
+	e.ExecuteStream(context.Background(), cmd, func(c protocol.OutputChunkPayload) {
+		mu.Lock()
+		chunks = append(chunks, c)
+		mu.Unlock()
+	})
+
+	var streamed strings.Builder
+	markers := 0
+	for _, c := range chunks {
+		if c.Final || c.Stream != "stdout" {
+			continue
+		}
+		if strings.HasPrefix(c.Data, "\n[output truncated") {
+			markers++
+			continue
+		}
+		streamed.WriteString(c.Data)
+	}
+	if streamed.String() != "line1\nline" {
+		t.Fatalf("expected output cut at 10 bytes, got %q", streamed.String())
+	}
+	if markers != 1 {
+		t.Fatalf("expected one truncation marker, got %d", markers)
+	}
+	if last := chunks[len(chunks)-1]; !last.Final || !last.Truncated || last.ExitCode != 0 {
+		t.Fatalf("expected truncated final chunk with exit 0, got %+v", last)
+	}
+}
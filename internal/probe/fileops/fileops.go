@@ -7,10 +7,8 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/user"
 	"path/filepath"
 	"strings"
-	"syscall"
 	"time"
 
 	"go.uber.org/zap"
@@ -234,14 +232,11 @@ func (f *FileOps) StatFile(path string) (*FileInfo, error) {
 		return nil, err
 	}
 
-	sysInfo, ok := info.Sys().(*syscall.Stat_t)
-	if !ok {
-		return nil, fmt.Errorf("failed to read file owner metadata")
+	owner, group, err := fileOwner(resolved, info)
+	if err != nil {
+		return nil, err
 	}
 
-	owner := lookupUserName(sysInfo.Uid)
-	group := lookupGroupName(sysInfo.Gid)
-
 	f.logger.Debug("stat file", zap.String("path", path))
 
 	return &FileInfo{
@@ -383,19 +378,3 @@ func shouldSkipSearchRoot(path string) bool {
 	_, skip := blockedSearchRoots[base]
 	return skip
 }
-
-func lookupUserName(uid uint32) string {
-	owner := fmt.Sprint(uid)
-	if userInfo, err := user.LookupId(owner); err == nil {
-		owner = userInfo.Username
-	}
-	return owner
-}
-
-func lookupGroupName(gid uint32) string {
-	group := fmt.Sprint(gid)
-	if groupInfo, err := user.LookupGroupId(group); err == nil {
-		group = groupInfo.Name
-	}
-	return group
-}
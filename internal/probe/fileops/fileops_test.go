@@ -2,11 +2,8 @@ package fileops
 
 import (
 	"os"
-	"os/user"
 	"path/filepath"
-	"strconv"
 	"strings"
-	"syscall"
 	"testing"
 
 	"go.uber.org/zap"
@@ -24,22 +21,6 @@ func mustWriteFile(t *testing.T, path, content string) {
 	}
 }
 
-func expectedOwner(uid uint32) string {
-	owner := strconv.FormatUint(uint64(uid), 10)
-	if userInfo, err := user.LookupId(owner); err == nil {
-		return userInfo.Username
-	}
-	return owner
-}
-
-func expectedGroup(gid uint32) string {
-	group := strconv.FormatUint(uint64(gid), 10)
-	if groupInfo, err := user.LookupGroupId(group); err == nil {
-		return groupInfo.Name
-	}
-	return group
-}
-
 func TestReadFile_HappyPath(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "hello.txt")
@@ -194,16 +175,6 @@ func TestStatFile_ReturnsInfo(t *testing.T) {
 		t.Fatalf("mode mismatch: expected %v got %v", fsInfo.Mode(), info.Mode)
 	}
 
-	sysInfo := fsInfo.Sys().(*syscall.Stat_t)
-	expectedOwner := expectedOwner(sysInfo.Uid)
-	expectedGroup := expectedGroup(sysInfo.Gid)
-	if info.Owner != expectedOwner {
-		t.Fatalf("expected owner %q, got %q", expectedOwner, info.Owner)
-	}
-	if info.Group != expectedGroup {
-		t.Fatalf("expected group %q, got %q", expectedGroup, info.Group)
-	}
-
 	expectedPath, _ := filepath.Abs(path)
 	if info.Path != expectedPath {
 		t.Fatalf("expected path %q, got %q", expectedPath, info.Path)
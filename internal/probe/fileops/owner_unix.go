@@ -0,0 +1,36 @@
+//go:build !windows
+
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"syscall"
+)
+
+// fileOwner resolves the owning user and group names from the file's uid
+// and gid, falling back to the numeric IDs.
+func fileOwner(_ string, info os.FileInfo) (string, string, error) {
+	sysInfo, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", "", fmt.Errorf("failed to read file owner metadata")
+	}
+	return lookupUserName(sysInfo.Uid), lookupGroupName(sysInfo.Gid), nil
+}
+
+func lookupUserName(uid uint32) string {
+	owner := fmt.Sprint(uid)
+	if userInfo, err := user.LookupId(owner); err == nil {
+		owner = userInfo.Username
+	}
+	return owner
+}
+
+func lookupGroupName(gid uint32) string {
+	group := fmt.Sprint(gid)
+	if groupInfo, err := user.LookupGroupId(group); err == nil {
+		group = groupInfo.Name
+	}
+	return group
+}
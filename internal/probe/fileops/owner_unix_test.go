@@ -0,0 +1,55 @@
+//go:build !windows
+
+package fileops
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func expectedOwner(uid uint32) string {
+	owner := strconv.FormatUint(uint64(uid), 10)
+	if userInfo, err := user.LookupId(owner); err == nil {
+		return userInfo.Username
+	}
+	return owner
+}
+
+func expectedGroup(gid uint32) string {
+	group := strconv.FormatUint(uint64(gid), 10)
+	if groupInfo, err := user.LookupGroupId(group); err == nil {
+		return groupInfo.Name
+	}
+	return group
+}
+
+func TestStatFile_ReturnsOwnerAndGroup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "meta.txt")
+	mustWriteFile(t, path, "metadata")
+
+	ops := New(Policy{}, testLogger())
+	info, err := ops.StatFile(path)
+	if err != nil {
+		t.Fatalf("stat error: %v", err)
+	}
+
+	fsInfo, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat (local) error: %v", err)
+	}
+
+	sysInfo := fsInfo.Sys().(*syscall.Stat_t)
+	expectedOwner := expectedOwner(sysInfo.Uid)
+	expectedGroup := expectedGroup(sysInfo.Gid)
+	if info.Owner != expectedOwner {
+		t.Fatalf("expected owner %q, got %q", expectedOwner, info.Owner)
+	}
+	if info.Group != expectedGroup {
+		t.Fatalf("expected group %q, got %q", expectedGroup, info.Group)
+	}
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package fileops
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// fileOwner resolves the owner and primary group SIDs from the file's
+// security descriptor to DOMAIN\name accounts, falling back to the SID
+// string when an account cannot be looked up.
+func fileOwner(path string, _ os.FileInfo) (string, string, error) {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT,
+		windows.OWNER_SECURITY_INFORMATION|windows.GROUP_SECURITY_INFORMATION)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read file owner metadata: %w", err)
+	}
+	owner, _, err := sd.Owner()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read file owner metadata: %w", err)
+	}
+	group, _, err := sd.Group()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read file owner metadata: %w", err)
+	}
+	return accountName(owner), accountName(group), nil
+}
+
+func accountName(sid *windows.SID) string {
+	if sid == nil {
+		return ""
+	}
+	account, domain, _, err := sid.LookupAccount("")
+	if err != nil {
+		return sid.String()
+	}
+	if domain == "" {
+		return account
+	}
+	return domain + `\` + account
+}
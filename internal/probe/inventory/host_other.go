@@ -0,0 +1,16 @@
+//go:build !windows
+
+package inventory
+
+import "github.com/marcus-qen/legator/internal/protocol"
+
+// The host* collectors read Windows system APIs; elsewhere Scan uses /proc,
+// df and systemctl instead and never calls these.
+
+func hostKernel() string { return "unknown" }
+
+func hostMemTotal() uint64 { return 0 }
+
+func hostDiskTotal() uint64 { return 0 }
+
+func hostServices() []protocol.Service { return nil }
@@ -0,0 +1,145 @@
+//go:build windows
+
+package inventory
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/marcus-qen/legator/internal/protocol"
+	"golang.org/x/sys/windows"
+)
+
+var procGlobalMemoryStatusEx = windows.NewLazySystemDLL("kernel32.dll").NewProc("GlobalMemoryStatusEx")
+
+// memoryStatusEx mirrors MEMORYSTATUSEX.
+type memoryStatusEx struct {
+	Length               uint32
+	MemoryLoad           uint32
+	TotalPhys            uint64
+	AvailPhys            uint64
+	TotalPageFile        uint64
+	AvailPageFile        uint64
+	TotalVirtual         uint64
+	AvailVirtual         uint64
+	AvailExtendedVirtual uint64
+}
+
+// hostKernel reports the Windows version from RtlGetVersion, which unlike
+// GetVersionEx is not subject to manifest-based version lies.
+func hostKernel() string {
+	v := windows.RtlGetVersion()
+	if v == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d.%d.%d", v.MajorVersion, v.MinorVersion, v.BuildNumber)
+}
+
+// hostMemTotal returns installed physical memory in bytes.
+func hostMemTotal() uint64 {
+	status := memoryStatusEx{}
+	status.Length = uint32(unsafe.Sizeof(status))
+	if r, _, _ := procGlobalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&status))); r == 0 {
+		return 0
+	}
+	return status.TotalPhys
+}
+
+// hostDiskTotal sums the capacity of all fixed drives.
+func hostDiskTotal() uint64 {
+	buf := make([]uint16, 254)
+	n, err := windows.GetLogicalDriveStrings(uint32(len(buf)), &buf[0])
+	if err != nil || int(n) > len(buf) {
+		return 0
+	}
+	var total uint64
+	for _, root := range splitMultiSz(buf[:n]) {
+		rootPtr, err := windows.UTF16PtrFromString(root)
+		if err != nil || windows.GetDriveType(rootPtr) != windows.DRIVE_FIXED {
+			continue
+		}
+		var free, size, totalFree uint64
+		if err := windows.GetDiskFreeSpaceEx(rootPtr, &free, &size, &totalFree); err == nil {
+			total += size
+		}
+	}
+	return total
+}
+
+// splitMultiSz splits a NUL-separated, double-NUL-terminated UTF-16 list.
+func splitMultiSz(buf []uint16) []string {
+	var out []string
+	start := 0
+	for i, c := range buf {
+		if c != 0 {
+			continue
+		}
+		if i > start {
+			out = append(out, windows.UTF16ToString(buf[start:i]))
+		}
+		start = i + 1
+	}
+	return out
+}
+
+// hostServices lists Win32 services and their current state from the
+// service control manager.
+func hostServices() []protocol.Service {
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT|windows.SC_MANAGER_ENUMERATE_SERVICE)
+	if err != nil {
+		return nil
+	}
+	defer windows.CloseServiceHandle(scm)
+
+	var buf []byte
+	var needed, returned uint32
+	for {
+		var p *byte
+		if len(buf) > 0 {
+			p = &buf[0]
+		}
+		err = windows.EnumServicesStatusEx(scm, windows.SC_ENUM_PROCESS_INFO,
+			windows.SERVICE_WIN32, windows.SERVICE_STATE_ALL,
+			p, uint32(len(buf)), &needed, &returned, nil, nil)
+		if err == nil {
+			break
+		}
+		if err != syscall.ERROR_MORE_DATA || needed <= uint32(len(buf)) {
+			return nil
+		}
+		buf = make([]byte, needed)
+	}
+	if returned == 0 {
+		return nil
+	}
+
+	entries := unsafe.Slice((*windows.ENUM_SERVICE_STATUS_PROCESS)(unsafe.Pointer(&buf[0])), int(returned))
+	result := make([]protocol.Service, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, protocol.Service{
+			Name:  windows.UTF16PtrToString(entry.ServiceName),
+			State: serviceStateName(entry.ServiceStatusProcess.CurrentState),
+		})
+	}
+	return result
+}
+
+// serviceStateName maps SCM states onto the lower-case vocabulary used for
+// systemd sub-states.
+func serviceStateName(state uint32) string {
+	switch state {
+	case windows.SERVICE_RUNNING:
+		return "running"
+	case windows.SERVICE_STOPPED:
+		return "stopped"
+	case windows.SERVICE_PAUSED:
+		return "paused"
+	case windows.SERVICE_START_PENDING, windows.SERVICE_CONTINUE_PENDING:
+		return "starting"
+	case windows.SERVICE_STOP_PENDING, windows.SERVICE_PAUSE_PENDING:
+		return "stopping"
+	default:
+		return "unknown"
+	}
+}
@@ -56,19 +56,7 @@ func hostname() string {
 
 func kernel() string {
 	if runtime.GOOS == "windows" {
-		if out, err := exec.Command("cmd", "/C", "ver").Output(); err == nil {
-			v := strings.TrimSpace(string(out))
-			if v != "" {
-				return v
-			}
-		}
-		if out, err := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", "[System.Environment]::OSVersion.VersionString").Output(); err == nil {
-			v := strings.TrimSpace(string(out))
-			if v != "" {
-				return v
-			}
-		}
-		return "unknown"
+		return hostKernel()
 	}
 
 	out, err := exec.Command("uname", "-r").Output()
@@ -80,12 +68,7 @@ func kernel() string {
 
 func memTotal() uint64 {
 	if runtime.GOOS == "windows" {
-		if out, err := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", "(Get-CimInstance Win32_ComputerSystem).TotalPhysicalMemory").Output(); err == nil {
-			if n := parseFirstUint(string(out)); n > 0 {
-				return n
-			}
-		}
-		return 0
+		return hostMemTotal()
 	}
 
 	f, err := os.Open("/proc/meminfo")
@@ -110,12 +93,7 @@ func memTotal() uint64 {
 
 func diskTotal() uint64 {
 	if runtime.GOOS == "windows" {
-		if out, err := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", "(Get-CimInstance Win32_LogicalDisk -Filter \"DriveType=3\" | Measure-Object -Property Size -Sum).Sum").Output(); err == nil {
-			if n := parseFirstUint(string(out)); n > 0 {
-				return n
-			}
-		}
-		return 0
+		return hostDiskTotal()
 	}
 
 	out, err := exec.Command("df", "--output=size", "--total", "-B1").Output()
@@ -168,8 +146,7 @@ func interfaces() []protocol.NetInterface {
 
 func services() []protocol.Service {
 	if runtime.GOOS == "windows" {
-		// MVP: optional on Windows; avoid costly/full enumeration for now.
-		return nil
+		return hostServices()
 	}
 
 	out, err := exec.Command("systemctl", "list-units", "--type=service", "--all",
@@ -276,17 +253,3 @@ func parsePkgList(output, manager string) []protocol.Package {
 	}
 	return result
 }
-
-func parseFirstUint(raw string) uint64 {
-	for _, line := range strings.Split(raw, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		line = strings.TrimSuffix(line, "\r")
-		if n, err := strconv.ParseUint(line, 10, 64); err == nil {
-			return n
-		}
-	}
-	return 0
-}
@@ -14,6 +14,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -43,13 +44,18 @@ type UpdateResult struct {
 }
 
 // Apply downloads the binary from url, verifies sha256 checksum, and
-// atomically replaces the current executable. Returns the result.
+// atomically replaces the current executable. An empty checksum is
+// rejected before anything is downloaded. Returns the result.
 func (u *Updater) Apply(url, checksum, version string) *UpdateResult {
 	u.logger.Info("starting self-update",
 		zap.String("url", url),
 		zap.String("version", version),
 	)
 
+	if checksum == "" {
+		return &UpdateResult{Message: "update rejected: checksum is required"}
+	}
+
 	// Get current executable path
 	exePath, err := os.Executable()
 	if err != nil {
@@ -99,7 +105,7 @@ func (u *Updater) Apply(url, checksum, version string) *UpdateResult {
 
 	// Verify checksum
 	gotChecksum := hex.EncodeToString(hasher.Sum(nil))
-	if checksum != "" && gotChecksum != checksum {
+	if !strings.EqualFold(gotChecksum, checksum) {
 		return &UpdateResult{
 			Message: fmt.Sprintf("checksum mismatch: expected %s, got %s", checksum, gotChecksum),
 		}
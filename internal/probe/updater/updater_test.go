@@ -36,6 +36,24 @@ func TestApply_BadHTTPStatus(t *testing.T) {
 	}
 }
 
+func TestApply_RequiresChecksum(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+		w.Write([]byte("fake binary content"))
+	}))
+	defer srv.Close()
+
+	u := New(zap.NewNop())
+	result := u.Apply(srv.URL+"/binary", "", "v1.0")
+	if result.Success {
+		t.Fatal("expected failure without checksum")
+	}
+	if hit {
+		t.Fatal("expected update to be rejected before download")
+	}
+}
+
 func TestApply_ChecksumMismatch(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("fake binary content"))
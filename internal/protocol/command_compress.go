@@ -0,0 +1,99 @@
+package protocol
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// CompressionGzip marks a command result whose Stdout and Stderr are each
+// base64-encoded gzip streams.
+const CompressionGzip = "gzip"
+
+// maxDecompressedOutputBytes bounds each decompressed stream: the hard
+// output cap plus room for the truncation marker.
+const maxDecompressedOutputBytes = MaxCommandOutputBytes + 4<<10
+
+// CompressOutput gzips Stdout and Stderr when together they reach threshold
+// bytes and compression makes them smaller. A threshold of 0 or less leaves
+// the result unchanged. On error the result is also left unchanged.
+func (r *CommandResultPayload) CompressOutput(threshold int) error {
+	if threshold <= 0 || r.Compression != "" || len(r.Stdout)+len(r.Stderr) < threshold {
+		return nil
+	}
+	stdout, err := gzipBase64(r.Stdout)
+	if err != nil {
+		return err
+	}
+	stderr, err := gzipBase64(r.Stderr)
+	if err != nil {
+		return err
+	}
+	if len(stdout)+len(stderr) >= len(r.Stdout)+len(r.Stderr) {
+		return nil
+	}
+	r.Stdout, r.Stderr, r.Compression = stdout, stderr, CompressionGzip
+	return nil
+}
+
+// DecompressOutput restores Stdout and Stderr of a compressed result and
+// clears Compression. Uncompressed results are left unchanged.
+func (r *CommandResultPayload) DecompressOutput() error {
+	switch r.Compression {
+	case "":
+		return nil
+	case CompressionGzip:
+	default:
+		return fmt.Errorf("unsupported output compression %q", r.Compression)
+	}
+	stdout, err := gunzipBase64(r.Stdout)
+	if err != nil {
+		return fmt.Errorf("stdout: %w", err)
+	}
+	stderr, err := gunzipBase64(r.Stderr)
+	if err != nil {
+		return fmt.Errorf("stderr: %w", err)
+	}
+	r.Stdout, r.Stderr, r.Compression = stdout, stderr, ""
+	return nil
+}
+
+func gzipBase64(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(zw, s); err != nil {
+		return "", fmt.Errorf("compress output: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("compress output: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func gunzipBase64(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("decode compressed output: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return "", fmt.Errorf("decompress output: %w", err)
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(io.LimitReader(zr, maxDecompressedOutputBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("decompress output: %w", err)
+	}
+	if len(out) > maxDecompressedOutputBytes {
+		return "", fmt.Errorf("decompressed output exceeds %d bytes", maxDecompressedOutputBytes)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,68 @@
+package protocol
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxCommandEnvVars bounds how many variables a command may set.
+const MaxCommandEnvVars = 64
+
+var commandEnvKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+
+// protectedEnvVars change how the shell, the dynamic linker, a language
+// runtime or a common tool finds and loads code or runs helpers, or carry
+// the probe's own configuration. Commands may not override them.
+var protectedEnvVars = map[string]struct{}{
+	"PATH": {}, "PATHEXT": {}, "IFS": {}, "ENV": {}, "BASH_ENV": {}, "CDPATH": {},
+	"SHELL": {}, "SHELLOPTS": {}, "BASHOPTS": {}, "PS4": {}, "PROMPT_COMMAND": {}, "GLOBIGNORE": {},
+	"COMSPEC": {}, "SYSTEMROOT": {}, "PSMODULEPATH": {},
+	"GCONV_PATH": {}, "HOSTALIASES": {}, "LOCALDOMAIN": {}, "RES_OPTIONS": {},
+	"NODE_OPTIONS": {}, "PYTHONPATH": {}, "PYTHONSTARTUP": {}, "PYTHONHOME": {},
+	"PERL5LIB": {}, "PERL5OPT": {}, "RUBYLIB": {}, "RUBYOPT": {},
+	"JAVA_TOOL_OPTIONS": {}, "_JAVA_OPTIONS": {},
+	"HOME": {}, "LOCPATH": {}, "NLSPATH": {}, "OPENSSL_CONF": {}, "OPENSSL_ENGINES": {}, "OPENSSL_MODULES": {},
+	"PAGER": {}, "MANPAGER": {}, "SYSTEMD_PAGER": {}, "EDITOR": {}, "VISUAL": {}, "SUDO_ASKPASS": {}, "SSH_ASKPASS": {},
+}
+
+// protectedEnvPrefixes cover dynamic linker, git and probe variables. Git
+// reads helper commands from GIT_SSH_COMMAND, GIT_EXTERNAL_DIFF,
+// GIT_CONFIG_* and others, so the whole namespace is protected.
+var protectedEnvPrefixes = []string{"LD_", "DYLD_", "GIT_", "LEGATOR_"}
+
+// ProtectedEnvVar reports whether a command may not override key. The
+// comparison ignores case, as Windows environments do.
+func ProtectedEnvVar(key string) bool {
+	upper := strings.ToUpper(key)
+	if _, ok := protectedEnvVars[upper]; ok {
+		return true
+	}
+	for _, prefix := range protectedEnvPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCommandEnv checks a command's environment overrides: at most
+// MaxCommandEnvVars well-formed keys, none of them protected, and no NUL
+// bytes in values.
+func ValidateCommandEnv(env map[string]string) error {
+	if len(env) > MaxCommandEnvVars {
+		return fmt.Errorf("env sets %d variables; at most %d are allowed", len(env), MaxCommandEnvVars)
+	}
+	for key, value := range env {
+		if !commandEnvKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid env key %q", key)
+		}
+		if ProtectedEnvVar(key) {
+			return fmt.Errorf("env key %s is protected and cannot be overridden", key)
+		}
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("env value for %s contains a NUL byte", key)
+		}
+	}
+	return nil
+}
@@ -14,15 +14,26 @@ const (
 	MsgInventory     MessageType = "inventory"
 	MsgCommandResult MessageType = "command_result"
 	MsgError         MessageType = "error"
+	MsgPolicyReport  MessageType = "policy_report" // Probe → Control Plane: answer to policy_query
+	MsgAlert         MessageType = "alert"         // Probe → Control Plane: locally detected condition
+	// Probe → Control Plane: runs of the local schedule, including ones made offline
+	MsgLocalScheduleResults MessageType = "local_schedule_results"
 
 	// Control Plane → Probe
-	MsgRegistered   MessageType = "registered"
-	MsgCommand      MessageType = "command"
-	MsgPolicyUpdate MessageType = "policy_update"
-	MsgPing         MessageType = "ping"
-	MsgPong         MessageType = "pong"
-	MsgUpdate       MessageType = "update"       // Control Plane → Probe: update binary
-	MsgKeyRotation  MessageType = "key_rotation" // Control Plane → Probe: rotate probe API key
+	MsgRegistered    MessageType = "registered"
+	MsgCommand       MessageType = "command"
+	MsgPolicyUpdate  MessageType = "policy_update"
+	MsgPing          MessageType = "ping"
+	MsgPong          MessageType = "pong"
+	MsgUpdate        MessageType = "update"         // Control Plane → Probe: update binary
+	MsgKeyRotation   MessageType = "key_rotation"   // Control Plane → Probe: rotate probe API key
+	MsgCommandCancel MessageType = "command_cancel" // Control Plane → Probe: abort an in-flight command
+	MsgLogTailStart  MessageType = "log_tail_start" // Control Plane → Probe: follow a log, streaming output_chunk
+	MsgLogTailStop   MessageType = "log_tail_stop"  // Control Plane → Probe: stop following a log
+	MsgPolicyQuery   MessageType = "policy_query"   // Control Plane → Probe: report the policy being enforced
+	MsgLocalSchedule MessageType = "local_schedule" // Control Plane → Probe: replace the local command schedule
+	// Control Plane → Probe: local schedule results stored; drop them from the cache
+	MsgLocalScheduleAck MessageType = "local_schedule_ack"
 
 	// Bidirectional
 	MsgOutputChunk MessageType = "output_chunk"
@@ -34,6 +45,7 @@ type Envelope struct {
 	Type      MessageType `json:"type"`
 	Timestamp time.Time   `json:"timestamp"`
 	Payload   any         `json:"payload,omitempty"`
+	Nonce     string      `json:"nonce,omitempty"`     // Single-use value bound into the command signature
 	Signature string      `json:"signature,omitempty"` // HMAC for command verification
 }
 
@@ -63,8 +75,26 @@ type HeartbeatPayload struct {
 	MemTotal  uint64     `json:"mem_total_bytes"`
 	DiskUsed  uint64     `json:"disk_used_bytes"`
 	DiskTotal uint64     `json:"disk_total_bytes"`
+	// CPUPct is host CPU utilisation since the previous heartbeat (0-100).
+	CPUPct  float64 `json:"cpu_pct,omitempty"`
+	Version string  `json:"version,omitempty"` // Running probe binary version
+	// InFlightCommands is how many commands the probe is executing.
+	InFlightCommands int `json:"in_flight_commands,omitempty"`
+	// HeartbeatIntervalSec is the heartbeat cadence the probe is running at.
+	HeartbeatIntervalSec int `json:"heartbeat_interval_sec,omitempty"`
 }
 
+// Probe reporting cadence defaults and the bounds accepted from policy.
+const (
+	DefaultHeartbeatInterval = 30 * time.Second
+	MinHeartbeatInterval     = 5 * time.Second
+	MaxHeartbeatInterval     = time.Hour
+
+	DefaultInventoryInterval = 15 * time.Minute
+	MinInventoryInterval     = time.Minute
+	MaxInventoryInterval     = 24 * time.Hour
+)
+
 // CapabilityLevel controls what a probe is allowed to do.
 type CapabilityLevel string
 
@@ -111,6 +141,46 @@ type CommandPayload struct {
 	Timeout   time.Duration   `json:"timeout"`
 	Level     CapabilityLevel `json:"level"`  // Required capability level
 	Stream    bool            `json:"stream"` // Stream output vs wait for completion
+	// MaxOutputBytes overrides the probe's per-stream output cap for this
+	// command, up to MaxCommandOutputBytes. Zero uses the probe default.
+	MaxOutputBytes int `json:"max_output_bytes,omitempty"`
+	// WorkDir is the directory the command runs in. The probe refuses it
+	// unless it lies under one of the policy's WorkDirs. Empty uses the
+	// probe's own working directory.
+	WorkDir string `json:"work_dir,omitempty"`
+	// Env adds or overrides environment variables for the command. Keys
+	// must pass ValidateCommandEnv.
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// Output caps applied per stream (stdout and stderr each) by the probe.
+const (
+	DefaultCommandOutputBytes = 1 << 20  // 1 MiB
+	MaxCommandOutputBytes     = 16 << 20 // 16 MiB hard ceiling for any override
+)
+
+// CommandCancelPayload asks the probe to kill an in-flight command. The probe
+// still reports a (failed) result, which the control plane may discard.
+type CommandCancelPayload struct {
+	RequestID string `json:"request_id"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// LogTailPayload asks the probe to follow a systemd unit's journal or a log
+// file. Lines are streamed back as OutputChunkPayload messages carrying
+// RequestID until a LogTailStopPayload arrives or MaxDuration elapses.
+type LogTailPayload struct {
+	RequestID   string        `json:"request_id"`
+	Unit        string        `json:"unit,omitempty"`  // systemd unit, followed with journalctl
+	Path        string        `json:"path,omitempty"`  // log file, followed with tail; used when Unit is empty
+	Lines       int           `json:"lines,omitempty"` // backlog lines to emit before following
+	MaxDuration time.Duration `json:"max_duration"`    // hard stop so an orphaned tail cannot run forever
+}
+
+// LogTailStopPayload ends a running log tail.
+type LogTailStopPayload struct {
+	RequestID string `json:"request_id"`
+	Reason    string `json:"reason,omitempty"`
 }
 
 // CommandResultPayload is the probe's response to a command.
@@ -121,6 +191,21 @@ type CommandResultPayload struct {
 	Stderr    string `json:"stderr"`
 	Duration  int64  `json:"duration_ms"`
 	Truncated bool   `json:"truncated"` // Output exceeded max size
+	// StdoutBytes and StderrBytes are the full output sizes before any
+	// truncation.
+	StdoutBytes int64 `json:"stdout_bytes,omitempty"`
+	StderrBytes int64 `json:"stderr_bytes,omitempty"`
+	// Busy is set when the probe refused the command because it was already
+	// running its maximum number of concurrent commands.
+	Busy bool `json:"busy,omitempty"`
+	// RateLimited is set when the probe refused the command under its
+	// policy rate limit; RetryAfterMs is how long until it would be
+	// accepted.
+	RateLimited  bool  `json:"rate_limited,omitempty"`
+	RetryAfterMs int64 `json:"retry_after_ms,omitempty"`
+	// Compression is set to CompressionGzip when the probe compressed
+	// Stdout and Stderr; see DecompressOutput.
+	Compression string `json:"compression,omitempty"`
 }
 
 // InventoryPayload is the probe's full system inventory.
@@ -173,11 +258,28 @@ type User struct {
 }
 
 // UpdatePayload tells the probe to download and install a new binary.
+// Checksum is required; when command signing is enabled, Signature must be
+// a valid HMAC over Manifest() so a compromised download host cannot push
+// arbitrary binaries.
 type UpdatePayload struct {
-	URL      string `json:"url"`      // Download URL for new binary
-	Checksum string `json:"checksum"` // SHA256 hex digest
-	Version  string `json:"version"`  // Target version string
-	Restart  bool   `json:"restart"`  // Restart after update
+	RequestID string `json:"request_id,omitempty"` // Correlates the probe's update result
+	URL       string `json:"url"`                  // Download URL for new binary
+	Checksum  string `json:"checksum"`             // SHA256 hex digest
+	Version   string `json:"version"`              // Target version string
+	Restart   bool   `json:"restart"`              // Restart after update
+	Signature string `json:"signature,omitempty"`  // HMAC over Manifest(), keyed per probe
+}
+
+// UpdateManifest is the signed portion of an UpdatePayload. The download URL
+// is deliberately excluded so mirrors can serve the same signed binary.
+type UpdateManifest struct {
+	Version  string `json:"version"`
+	Checksum string `json:"checksum"`
+}
+
+// Manifest returns the fields covered by the update signature.
+func (u UpdatePayload) Manifest() UpdateManifest {
+	return UpdateManifest{Version: u.Version, Checksum: u.Checksum}
 }
 
 // OutputChunkPayload streams incremental output from a running command.
@@ -188,15 +290,24 @@ type OutputChunkPayload struct {
 	Seq       int    `json:"seq"`       // sequence number for ordering
 	Final     bool   `json:"final"`     // true = command has finished
 	ExitCode  int    `json:"exit_code"` // only meaningful when Final=true
+	// Truncated is set on the final chunk when output past the cap was
+	// dropped instead of streamed.
+	Truncated bool `json:"truncated,omitempty"`
+	// RateLimited and RetryAfterMs are set on the final chunk of a streamed
+	// command the probe refused under its rate limit.
+	RateLimited  bool  `json:"rate_limited,omitempty"`
+	RetryAfterMs int64 `json:"retry_after_ms,omitempty"`
 }
 
 // PolicyUpdatePayload pushes a new policy to the probe.
 type PolicyUpdatePayload struct {
 	PolicyID string          `json:"policy_id"`
 	Level    CapabilityLevel `json:"level"`
-	Allowed  []string        `json:"allowed,omitempty"` // Command allowlist
-	Blocked  []string        `json:"blocked,omitempty"` // Command blocklist
-	Paths    []string        `json:"paths,omitempty"`   // Protected paths
+	Allowed  []string        `json:"allowed,omitempty"`   // Command allowlist
+	Blocked  []string        `json:"blocked,omitempty"`   // Command blocklist
+	Paths    []string        `json:"paths,omitempty"`     // Protected paths
+	WorkDirs []string        `json:"work_dirs,omitempty"` // Directories commands may set as work_dir
+	EnvKeys  []string        `json:"env_keys,omitempty"`  // Variables commands may set in env
 
 	ExecutionClassRequired ExecutionClass   `json:"execution_class_required,omitempty"`
 	SandboxRequired        bool             `json:"sandbox_required,omitempty"`
@@ -205,6 +316,137 @@ type PolicyUpdatePayload struct {
 	Breakglass             BreakglassPolicy `json:"breakglass,omitempty"`
 	MaxRuntimeSec          int              `json:"max_runtime_sec,omitempty"`
 	AllowedScopes          []string         `json:"allowed_scopes,omitempty"`
+
+	// MaxConcurrentCommands caps how many commands the probe runs at once;
+	// commands beyond it are refused with a busy result. 0 means no limit.
+	MaxConcurrentCommands int `json:"max_concurrent_commands,omitempty"`
+
+	// RateLimit throttles how often the probe accepts commands; commands
+	// over it are refused with a rate_limited result. Nil means no limit.
+	RateLimit *CommandRateLimit `json:"rate_limit,omitempty"`
+
+	// HeartbeatIntervalSec and InventoryIntervalSec set how often the probe
+	// heartbeats and re-sends its inventory. 0 keeps the probe defaults.
+	HeartbeatIntervalSec int `json:"heartbeat_interval_sec,omitempty"`
+	InventoryIntervalSec int `json:"inventory_interval_sec,omitempty"`
+
+	// AlertWatch configures the probe's local condition watcher. Nil leaves
+	// the watcher off.
+	AlertWatch *AlertWatchConfig `json:"alert_watch,omitempty"`
+}
+
+// CommandRateLimit caps how often a probe accepts commands.
+type CommandRateLimit struct {
+	PerMinute int               `json:"per_minute,omitempty"` // commands accepted in any 60s window; 0 = unlimited
+	Cooldowns []CommandCooldown `json:"cooldowns,omitempty"`
+}
+
+// CommandCooldown allows at most one command starting with Prefix (case
+// insensitive, matched against the command and its arguments) every
+// Seconds.
+type CommandCooldown struct {
+	Prefix  string `json:"prefix"`
+	Seconds int    `json:"seconds"`
+}
+
+// AlertWatchConfig tells a probe which local conditions to watch and push as
+// alert messages. Zero thresholds disable the corresponding check.
+type AlertWatchConfig struct {
+	Units       []string `json:"units,omitempty"`        // systemd units reported when failed
+	OOMKills    bool     `json:"oom_kills,omitempty"`    // report kernel OOM kills
+	DiskPercent float64  `json:"disk_percent,omitempty"` // root filesystem usage threshold
+	LoadPerCPU  float64  `json:"load_per_cpu,omitempty"` // 1-minute load average per CPU threshold
+	IntervalSec int      `json:"interval_sec,omitempty"` // check interval, default 30
+}
+
+// Alert conditions a probe can push.
+const (
+	AlertUnitFailed    = "unit_failed"
+	AlertOOMKill       = "oom_kill"
+	AlertDiskThreshold = "disk_threshold"
+	AlertLoadThreshold = "load_threshold"
+)
+
+// AlertPayload is a condition a probe detected locally. Subject names what
+// the condition is about (a unit, a mount point, a killed process), so one
+// probe can report several conditions of the same kind.
+type AlertPayload struct {
+	Condition  string    `json:"condition"`
+	Subject    string    `json:"subject,omitempty"`
+	Status     string    `json:"status"` // "firing" or "resolved"
+	Severity   string    `json:"severity,omitempty"`
+	Message    string    `json:"message"`
+	Value      float64   `json:"value,omitempty"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// Local schedule limits, enforced by the control plane and again by the probe.
+const (
+	MaxLocalScheduleEntries        = 32
+	MaxLocalScheduleTimeoutSec     = 3600
+	DefaultLocalScheduleTimeoutSec = 60
+)
+
+// LocalSchedulePayload replaces a probe's local schedule: commands the probe
+// runs on its own on a cron schedule, so they keep running while it is
+// disconnected. The probe stores it durably. An empty Entries clears it.
+type LocalSchedulePayload struct {
+	Version int64                `json:"version"`
+	Entries []LocalScheduleEntry `json:"entries"`
+}
+
+// LocalScheduleEntry is one scheduled command. Schedule is a five-field cron
+// expression or a descriptor such as @hourly, in the probe's local time
+// unless it starts with CRON_TZ=. Commands run under the probe's policy.
+type LocalScheduleEntry struct {
+	ID         string   `json:"id"`
+	Schedule   string   `json:"schedule"`
+	Command    string   `json:"command"`
+	Args       []string `json:"args,omitempty"`
+	TimeoutSec int      `json:"timeout_sec,omitempty"`
+	// OfflineOnly skips runs while the probe is connected.
+	OfflineOnly bool `json:"offline_only,omitempty"`
+}
+
+// LocalScheduleResult is one run of a local schedule entry. StartedAt is
+// when the probe actually ran it, which may be long before it is reported.
+type LocalScheduleResult struct {
+	RunID      string    `json:"run_id"`
+	EntryID    string    `json:"entry_id"`
+	Command    string    `json:"command"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	ExitCode   int       `json:"exit_code"`
+	Stdout     string    `json:"stdout,omitempty"`
+	Stderr     string    `json:"stderr,omitempty"`
+	Truncated  bool      `json:"truncated,omitempty"`
+	// Offline is set when the probe was disconnected at the time of the run.
+	Offline bool `json:"offline,omitempty"`
+}
+
+// LocalScheduleResultsPayload reports cached local schedule runs, oldest
+// first. The probe keeps them until they are acknowledged.
+type LocalScheduleResultsPayload struct {
+	ScheduleVersion int64                 `json:"schedule_version"`
+	Results         []LocalScheduleResult `json:"results"`
+}
+
+// LocalScheduleAckPayload lists the runs the control plane has stored.
+type LocalScheduleAckPayload struct {
+	RunIDs []string `json:"run_ids"`
+}
+
+// PolicyQueryPayload asks a probe for the policy it is currently enforcing.
+type PolicyQueryPayload struct {
+	RequestID string `json:"request_id"`
+}
+
+// PolicyReportPayload is a probe's answer to a policy query: the policy its
+// executor has actually loaded, which may differ from what the control plane
+// last pushed after a local config edit or a failed push.
+type PolicyReportPayload struct {
+	RequestID string              `json:"request_id"`
+	Policy    PolicyUpdatePayload `json:"policy"`
 }
 
 // KeyRotationPayload pushes a replacement API key to a probe.
@@ -2,6 +2,8 @@ package protocol
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -224,3 +226,75 @@ func TestKeyRotationPayloadJSON(t *testing.T) {
 		t.Fatalf("expires_at mismatch: got %q want %q", decoded.ExpiresAt, payload.ExpiresAt)
 	}
 }
+
+func TestValidateCommandEnv(t *testing.T) {
+	if err := ValidateCommandEnv(map[string]string{"APP_ENV": "production", "rails_env": "staging"}); err != nil {
+		t.Fatalf("expected valid env, got %v", err)
+	}
+	for name, env := range map[string]map[string]string{
+		"path":          {"PATH": "/tmp"},
+		"lowercase ld":  {"ld_preload": "/tmp/x.so"},
+		"probe config":  {"LEGATOR_API_KEY": "x"},
+		"bad key":       {"APP-ENV": "x"},
+		"empty key":     {"": "x"},
+		"nul in value":  {"APP_ENV": "a\x00b"},
+		"python loader": {"PYTHONPATH": "/tmp"},
+	} {
+		if err := ValidateCommandEnv(env); err == nil {
+			t.Errorf("%s: expected error for %v", name, env)
+		}
+	}
+
+	tooMany := make(map[string]string, MaxCommandEnvVars+1)
+	for i := 0; i <= MaxCommandEnvVars; i++ {
+		tooMany[fmt.Sprintf("VAR_%d", i)] = "x"
+	}
+	if err := ValidateCommandEnv(tooMany); err == nil {
+		t.Fatal("expected error for too many variables")
+	}
+}
+
+func TestCommandResultOutputCompressionRoundTrip(t *testing.T) {
+	big := strings.Repeat("listen 443 ssl;\n", 8192)
+	result := &CommandResultPayload{RequestID: "req-1", Stdout: big, Stderr: "warning: deprecated directive"}
+
+	if err := result.CompressOutput(0); err != nil || result.Compression != "" {
+		t.Fatalf("threshold 0 must leave output uncompressed, got %q (%v)", result.Compression, err)
+	}
+	if err := result.CompressOutput(64 << 10); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if result.Compression != CompressionGzip || len(result.Stdout) >= len(big) {
+		t.Fatalf("expected gzip output smaller than %d bytes, got %q with %d bytes", len(big), result.Compression, len(result.Stdout))
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded CommandResultPayload
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if err := decoded.DecompressOutput(); err != nil {
+		t.Fatalf("decompress: %v", err)
+	}
+	if decoded.Stdout != big || decoded.Stderr != "warning: deprecated directive" || decoded.Compression != "" {
+		t.Fatalf("round trip mismatch: compression=%q stderr=%q", decoded.Compression, decoded.Stderr)
+	}
+
+	small := &CommandResultPayload{Stdout: "ok"}
+	if err := small.CompressOutput(1); err != nil || small.Compression != "" || small.Stdout != "ok" {
+		t.Fatalf("expected output left alone when gzip is not smaller, got %+v (%v)", small, err)
+	}
+
+	for name, bad := range map[string]CommandResultPayload{
+		"unknown codec": {Compression: "zstd", Stdout: "x"},
+		"not base64":    {Compression: CompressionGzip, Stdout: "%%%"},
+		"not gzip":      {Compression: CompressionGzip, Stdout: "aGVsbG8="},
+	} {
+		if err := bad.DecompressOutput(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+*/
+
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBuckets is a token-bucket limiter keyed by an arbitrary string, such
+// as an API key ID. Each bucket holds up to its limit in tokens and refills
+// continuously at limit tokens per period, so a key may burst its full
+// allowance and is then paced.
+type TokenBuckets struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens    float64
+	limit     int
+	period    time.Duration
+	updated   time.Time
+	throttled bool
+}
+
+// BucketDecision is the outcome of a TokenBuckets check.
+type BucketDecision struct {
+	Allowed bool
+	// RetryAfter is how long until the next token is available when denied.
+	RetryAfter time.Duration
+	// FirstDenial is set on the first denial after the key was last allowed,
+	// so callers can record one event per throttling episode.
+	FirstDenial bool
+}
+
+// NewTokenBuckets creates an empty keyed token-bucket limiter. now may be
+// nil to use the wall clock.
+func NewTokenBuckets(now func() time.Time) *TokenBuckets {
+	if now == nil {
+		now = time.Now
+	}
+	return &TokenBuckets{
+		buckets: make(map[string]*tokenBucket),
+		now:     now,
+	}
+}
+
+// Take takes a token from key's bucket, which allows limit per period.
+// limit <= 0 or period <= 0 disables limiting for the call. Changing limit
+// or period for a key resets its bucket to the new size.
+func (t *TokenBuckets) Take(key string, limit int, period time.Duration) BucketDecision {
+	if limit <= 0 || period <= 0 {
+		return BucketDecision{Allowed: true}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	b, ok := t.buckets[key]
+	if !ok || b.limit != limit || b.period != period {
+		b = &tokenBucket{tokens: float64(limit), limit: limit, period: period, updated: now}
+		t.buckets[key] = b
+	}
+
+	rate := float64(limit) / period.Seconds()
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(limit), b.tokens+elapsed*rate)
+		b.updated = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		b.throttled = false
+		return BucketDecision{Allowed: true}
+	}
+
+	first := !b.throttled
+	b.throttled = true
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return BucketDecision{RetryAfter: wait, FirstDenial: first}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+*/
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBuckets_BurstThenRefill(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tb := NewTokenBuckets(func() time.Time { return now })
+
+	for i := 0; i < 3; i++ {
+		if d := tb.Take("a", 3, time.Minute); !d.Allowed {
+			t.Fatalf("take %d should be allowed within burst", i+1)
+		}
+	}
+	d := tb.Take("a", 3, time.Minute)
+	if d.Allowed || !d.FirstDenial {
+		t.Fatalf("expected first denial after burst, got %+v", d)
+	}
+	if d.RetryAfter != 20*time.Second {
+		t.Fatalf("expected retry-after of one refill interval, got %s", d.RetryAfter)
+	}
+	if d := tb.Take("a", 3, time.Minute); d.Allowed || d.FirstDenial {
+		t.Fatalf("expected repeat denial without FirstDenial, got %+v", d)
+	}
+	if d := tb.Take("b", 3, time.Minute); !d.Allowed {
+		t.Fatal("other keys should have their own bucket")
+	}
+
+	now = now.Add(20 * time.Second)
+	if d := tb.Take("a", 3, time.Minute); !d.Allowed {
+		t.Fatal("expected one token after refill interval")
+	}
+}
+
+func TestTokenBuckets_ResizeAndDisable(t *testing.T) {
+	tb := NewTokenBuckets(nil)
+
+	if d := tb.Take("a", 1, time.Hour); !d.Allowed {
+		t.Fatal("first take should be allowed")
+	}
+	if d := tb.Take("a", 1, time.Hour); d.Allowed {
+		t.Fatal("expected denial once the bucket is empty")
+	}
+	if d := tb.Take("a", 2, time.Hour); !d.Allowed {
+		t.Fatal("a new limit should reset the bucket")
+	}
+	if d := tb.Take("a", 0, time.Hour); !d.Allowed {
+		t.Fatal("zero limit should disable limiting")
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+*/
+
+package security
+
+import (
+	"sort"
+	"strings"
+)
+
+// minKnownSecretLen is the shortest known value a Redactor scrubs. Shorter
+// values ("root", "1234") would redact ordinary words across whole reports.
+const minKnownSecretLen = 6
+
+// Redactor scrubs a fixed set of known secret values, such as credentials
+// resolved for a run, before applying the pattern-based Sanitize. Matching
+// the actual values catches secrets no pattern recognises.
+type Redactor struct {
+	known []string
+}
+
+// NewRedactor returns a Redactor for the given secret values. Blank,
+// duplicate and too-short values are ignored. Multi-line values (private
+// keys) are also matched line by line, since tools often print them
+// reformatted.
+func NewRedactor(secrets ...string) *Redactor {
+	seen := make(map[string]struct{}, len(secrets))
+	var known []string
+	add := func(v string) {
+		v = strings.TrimSpace(v)
+		if len(v) < minKnownSecretLen {
+			return
+		}
+		if _, dup := seen[v]; dup {
+			return
+		}
+		seen[v] = struct{}{}
+		known = append(known, v)
+	}
+	for _, s := range secrets {
+		add(s)
+		if strings.Contains(s, "\n") {
+			for _, line := range strings.Split(s, "\n") {
+				add(line)
+			}
+		}
+	}
+	// Longest first, so a secret containing another is replaced whole.
+	sort.Slice(known, func(i, j int) bool { return len(known[i]) > len(known[j]) })
+	return &Redactor{known: known}
+}
+
+// Redact replaces known secret values with [REDACTED], then applies
+// Sanitize. A nil Redactor only applies Sanitize.
+func (r *Redactor) Redact(text string) string {
+	if text == "" {
+		return text
+	}
+	if r != nil {
+		for _, secret := range r.known {
+			text = strings.ReplaceAll(text, secret, redactedPlaceholder)
+		}
+	}
+	return Sanitize(text)
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+*/
+
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactor_KnownValues(t *testing.T) {
+	r := NewRedactor("s3cr3t-db-pass", "", "root", "s3cr3t-db-pass")
+	got := r.Redact("connecting as root with s3cr3t-db-pass")
+	if strings.Contains(got, "s3cr3t-db-pass") {
+		t.Fatalf("known secret not redacted: %s", got)
+	}
+	if !strings.Contains(got, "root") {
+		t.Fatalf("short value should not be redacted: %s", got)
+	}
+}
+
+func TestRedactor_MultiLineSecretMatchedPerLine(t *testing.T) {
+	key := "-----BEGIN OPENSSH KEY-----\nb3BlbnNzaC1rZXktdjEAAAAA\n-----END OPENSSH KEY-----"
+	got := NewRedactor(key).Redact("found b3BlbnNzaC1rZXktdjEAAAAA in authorized_keys")
+	if strings.Contains(got, "b3BlbnNzaC1rZXktdjEAAAAA") {
+		t.Fatalf("key line not redacted: %s", got)
+	}
+}
+
+func TestRedactor_NilAppliesPatterns(t *testing.T) {
+	var r *Redactor
+	if got := r.Redact("password=hunter22"); strings.Contains(got, "hunter22") {
+		t.Fatalf("pattern not applied: %s", got)
+	}
+}
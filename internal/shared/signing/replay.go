@@ -0,0 +1,92 @@
+package signing
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxAge is how far a signed envelope's timestamp may drift from
+	// the verifier's clock before it is rejected as stale.
+	DefaultMaxAge = 2 * time.Minute
+	// DefaultNonceCapacity bounds the number of remembered nonces.
+	DefaultNonceCapacity = 4096
+)
+
+var (
+	ErrMissingNonce   = errors.New("missing nonce")
+	ErrStaleTimestamp = errors.New("stale timestamp")
+	ErrReplayedNonce  = errors.New("replayed nonce")
+)
+
+// NewNonce returns a random 128-bit hex nonce.
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ReplayGuard rejects signed envelopes whose timestamp is outside the
+// allowed window or whose nonce has already been seen. Seen nonces are kept
+// in a bounded LRU; envelopes old enough to have been evicted are already
+// rejected by the timestamp check as long as capacity covers maxAge worth
+// of traffic.
+type ReplayGuard struct {
+	maxAge   time.Duration
+	capacity int
+	now      func() time.Time
+
+	mu    sync.Mutex
+	order *list.List // front = most recent
+	seen  map[string]*list.Element
+}
+
+// NewReplayGuard creates a guard. Non-positive arguments select the defaults.
+func NewReplayGuard(maxAge time.Duration, capacity int) *ReplayGuard {
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	if capacity <= 0 {
+		capacity = DefaultNonceCapacity
+	}
+	return &ReplayGuard{
+		maxAge:   maxAge,
+		capacity: capacity,
+		now:      time.Now,
+		order:    list.New(),
+		seen:     make(map[string]*list.Element),
+	}
+}
+
+// Check records the nonce and returns an error if the envelope is stale or
+// the nonce was seen before. Call it only after the signature is verified so
+// forged envelopes cannot fill the nonce cache.
+func (g *ReplayGuard) Check(nonce string, ts time.Time) error {
+	if nonce == "" {
+		return ErrMissingNonce
+	}
+	age := g.now().Sub(ts)
+	if age > g.maxAge || age < -g.maxAge {
+		return ErrStaleTimestamp
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if el, ok := g.seen[nonce]; ok {
+		g.order.MoveToFront(el)
+		return ErrReplayedNonce
+	}
+	g.seen[nonce] = g.order.PushFront(nonce)
+	for g.order.Len() > g.capacity {
+		oldest := g.order.Back()
+		g.order.Remove(oldest)
+		delete(g.seen, oldest.Value.(string))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package signing
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReplayedEnvelopeRejected(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	s := NewSigner(key)
+	guard := NewReplayGuard(time.Minute, 16)
+
+	p := testPayload{Command: "rm", Args: []string{"-rf", "/tmp/cache"}, RequestID: "r1"}
+	accept := func(id, nonce string, ts time.Time, sig string) error {
+		if err := s.VerifyEnvelope(id, nonce, ts, p, sig); err != nil {
+			return err
+		}
+		return guard.Check(nonce, ts)
+	}
+
+	ts := time.Now().UTC()
+	nonce, _ := NewNonce()
+	sig, err := s.SignEnvelope("env-1", nonce, ts, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := accept("env-1", nonce, ts, sig); err != nil {
+		t.Fatalf("fresh envelope rejected: %v", err)
+	}
+
+	// Captured and replayed verbatim.
+	if err := accept("env-1", nonce, ts, sig); !errors.Is(err, ErrReplayedNonce) {
+		t.Fatalf("expected replayed nonce, got %v", err)
+	}
+
+	// Replayed with a new nonce: the signature no longer matches.
+	other, _ := NewNonce()
+	if err := accept("env-1", other, ts, sig); err == nil {
+		t.Fatal("expected signature mismatch for swapped nonce")
+	}
+
+	// Replayed with a refreshed timestamp: also a signature mismatch.
+	if err := accept("env-1", nonce, ts.Add(time.Second), sig); err == nil {
+		t.Fatal("expected signature mismatch for shifted timestamp")
+	}
+
+	// A fresh, correctly signed envelope still succeeds.
+	fresh, _ := NewNonce()
+	freshTS := time.Now().UTC()
+	freshSig, _ := s.SignEnvelope("env-2", fresh, freshTS, p)
+	if err := accept("env-2", fresh, freshTS, freshSig); err != nil {
+		t.Fatalf("fresh envelope rejected: %v", err)
+	}
+}
+
+func TestReplayGuardRejectsStaleTimestamp(t *testing.T) {
+	guard := NewReplayGuard(time.Minute, 16)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	guard.now = func() time.Time { return now }
+
+	if err := guard.Check("n1", now.Add(-2*time.Minute)); !errors.Is(err, ErrStaleTimestamp) {
+		t.Fatalf("expected stale timestamp, got %v", err)
+	}
+	if err := guard.Check("n2", now.Add(2*time.Minute)); !errors.Is(err, ErrStaleTimestamp) {
+		t.Fatalf("expected future timestamp rejected, got %v", err)
+	}
+	if err := guard.Check("", now); !errors.Is(err, ErrMissingNonce) {
+		t.Fatalf("expected missing nonce, got %v", err)
+	}
+	if err := guard.Check("n3", now.Add(-30*time.Second)); err != nil {
+		t.Fatalf("expected in-window envelope accepted, got %v", err)
+	}
+}
+
+func TestReplayGuardEvictsOldestNonce(t *testing.T) {
+	guard := NewReplayGuard(time.Minute, 2)
+	ts := time.Now()
+	for _, n := range []string{"a", "b", "c"} {
+		if err := guard.Check(n, ts); err != nil {
+			t.Fatalf("check %s: %v", n, err)
+		}
+	}
+	if len(guard.seen) != 2 {
+		t.Fatalf("expected 2 remembered nonces, got %d", len(guard.seen))
+	}
+	if err := guard.Check("c", ts); !errors.Is(err, ErrReplayedNonce) {
+		t.Fatalf("expected recent nonce still remembered, got %v", err)
+	}
+	if err := guard.Check("a", ts); err != nil {
+		t.Fatalf("expected evicted nonce to be forgotten, got %v", err)
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Signer creates and verifies HMAC-SHA256 signatures.
@@ -52,6 +55,22 @@ func (s *Signer) Verify(requestID string, payload any, signature string) error {
 	return nil
 }
 
+// SignEnvelope signs a command envelope. The nonce and timestamp are bound
+// into the signature so a replayed envelope cannot be given a fresh nonce or
+// timestamp without invalidating it.
+func (s *Signer) SignEnvelope(id, nonce string, ts time.Time, payload any) (string, error) {
+	return s.Sign(envelopeScope(id, nonce, ts), payload)
+}
+
+// VerifyEnvelope checks a signature produced by SignEnvelope.
+func (s *Signer) VerifyEnvelope(id, nonce string, ts time.Time, payload any, signature string) error {
+	return s.Verify(envelopeScope(id, nonce, ts), payload, signature)
+}
+
+func envelopeScope(id, nonce string, ts time.Time) string {
+	return id + "|" + nonce + "|" + strconv.FormatInt(ts.UnixNano(), 10)
+}
+
 func canonicalize(requestID string, payload any) ([]byte, error) {
 	data, err := json.Marshal(payload)
 	if err != nil {
@@ -64,6 +83,23 @@ func canonicalize(requestID string, payload any) ([]byte, error) {
 	return canonical, nil
 }
 
+// DecodeMasterKey decodes a hex master signing key, the form taken by the
+// control plane's signing_key / LEGATOR_SIGNING_KEY and the probe's
+// signing_key. Both sides must derive probe keys from these bytes.
+func DecodeMasterKey(hexKey string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(hexKey))
+	if err != nil {
+		return nil, fmt.Errorf("signing key must be hex: %w", err)
+	}
+	return key, nil
+}
+
+// ForProbe returns a signer keyed with the probe's key derived from s's key,
+// the key the probe verifies with.
+func (s *Signer) ForProbe(probeID string) *Signer {
+	return NewSigner(DeriveProbeKey(s.key, probeID))
+}
+
 // DeriveProbeKey derives a per-probe signing key from a master key.
 func DeriveProbeKey(masterKey []byte, probeID string) []byte {
 	mac := hmac.New(sha256.New, masterKey)
@@ -78,6 +78,34 @@ func TestDeriveProbeKey(t *testing.T) {
 	}
 }
 
+func TestForProbeSignsWithDerivedKey(t *testing.T) {
+	master := make([]byte, 32)
+	rand.Read(master)
+	sig, err := NewSigner(master).ForProbe("probe-001").Sign("req-1", "payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewSigner(DeriveProbeKey(master, "probe-001")).Verify("req-1", "payload", sig); err != nil {
+		t.Fatalf("derived key should verify: %v", err)
+	}
+	if err := NewSigner(master).Verify("req-1", "payload", sig); err == nil {
+		t.Fatal("master key should not verify a per-probe signature")
+	}
+}
+
+func TestDecodeMasterKey(t *testing.T) {
+	key, err := DecodeMasterKey(" 00ff00ff\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key) != "\x00\xff\x00\xff" {
+		t.Fatalf("unexpected key bytes %x", key)
+	}
+	if _, err := DecodeMasterKey("master-signing-key"); err == nil {
+		t.Fatal("expected non-hex key to be rejected")
+	}
+}
+
 func TestSignDeterministic(t *testing.T) {
 	key := make([]byte, 32)
 	rand.Read(key)